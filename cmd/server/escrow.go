@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/config"
+	"github.com/yourusername/gatekeeper/internal/escrow"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// newEscrow opens the key escrow configured by ESCROW_DIR and ESCROW_KEK
+func newEscrow(cfg *config.Config) (*escrow.Escrow, error) {
+	dirStore, err := escrow.NewDirStore(cfg.EscrowDir)
+	if err != nil {
+		return nil, err
+	}
+	wrapper, err := escrow.NewAESKeyWrapper(cfg.EscrowKEK)
+	if err != nil {
+		return nil, err
+	}
+	return escrow.New(dirStore, wrapper), nil
+}
+
+// escrowSigningKeys returns the signing key material to escrow: the JWT secret,
+// which also signs URLs, and the private key of an asymmetric JWT algorithm
+func escrowSigningKeys(cfg *config.Config, jwtService *auth.JWTService) ([]escrow.SigningKey, error) {
+	keys := []escrow.SigningKey{{
+		Use:       escrow.SigningKeyJWTSecret,
+		Algorithm: auth.AlgorithmHS256,
+		Material:  cfg.JWTSecret,
+	}}
+	if cfg.JWTAlgorithm == auth.AlgorithmHS256 {
+		return keys, nil
+	}
+
+	keyPEM, err := os.ReadFile(cfg.JWTPrivateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT private key: %w", err)
+	}
+	key := escrow.SigningKey{
+		Use:       escrow.SigningKeyJWTPrivateKey,
+		Algorithm: cfg.JWTAlgorithm,
+		Material:  keyPEM,
+	}
+	if jwks := jwtService.JWKS(); len(jwks.Keys) > 0 {
+		key.KeyID = jwks.Keys[0].KeyID
+	}
+	return append(keys, key), nil
+}
+
+// runEscrow runs the escrow subcommand: export decrypts a snapshot for recovery,
+// import restores the API keys of a snapshot into the database. It returns the
+// process exit code.
+func runEscrow(args []string) int {
+	usage := func() {
+		fmt.Fprintln(os.Stderr, "usage: gatekeeper escrow export [-name snapshot] [-out file]")
+		fmt.Fprintln(os.Stderr, "       gatekeeper escrow import [-name snapshot | -file exported.json]")
+	}
+	if len(args) == 0 {
+		usage()
+		return 2
+	}
+
+	_ = godotenv.Load()
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+		return 1
+	}
+	// Importing an exported file needs no escrow
+	var keyEscrow *escrow.Escrow
+	if cfg.EscrowDir != "" {
+		if keyEscrow, err = newEscrow(cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to open key escrow: %v\n", err)
+			return 1
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	switch args[0] {
+	case "export":
+		flags := flag.NewFlagSet("escrow export", flag.ContinueOnError)
+		name := flags.String("name", "", "snapshot to export (default: the latest)")
+		out := flags.String("out", "", "file to write the decrypted snapshot to (default: stdout)")
+		if err := flags.Parse(args[1:]); err != nil {
+			return 2
+		}
+
+		source, snapshot, err := loadSnapshot(ctx, keyEscrow, *name, "")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		var w io.Writer = os.Stdout
+		if *out != "" {
+			f, err := os.OpenFile(*out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "failed to create %s: %v\n", *out, err)
+				return 1
+			}
+			defer f.Close()
+			w = f
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(snapshot); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write snapshot: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "exported snapshot %s: %d API keys, %d signing keys\n", source, len(snapshot.APIKeys), len(snapshot.SigningKeys))
+		return 0
+
+	case "import":
+		flags := flag.NewFlagSet("escrow import", flag.ContinueOnError)
+		name := flags.String("name", "", "snapshot to import (default: the latest)")
+		file := flags.String("file", "", "decrypted snapshot written by escrow export, instead of the escrow")
+		if err := flags.Parse(args[1:]); err != nil {
+			return 2
+		}
+
+		source, snapshot, err := loadSnapshot(ctx, keyEscrow, *name, *file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		db, err := store.Connect(ctx, cfg.DatabaseURL, store.PoolConfig{
+			MaxOpenConns:    cfg.DBMaxOpenConns,
+			MaxIdleConns:    cfg.DBMaxIdleConns,
+			ConnMaxLifetime: cfg.DBConnMaxLifetime,
+			ConnMaxIdleTime: cfg.DBConnMaxIdleTime,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to connect to database: %v\n", err)
+			return 1
+		}
+		defer db.Close()
+		// A replacement database starts empty
+		if err := db.RunMigrations(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to run migrations: %v\n", err)
+			return 1
+		}
+
+		restored, existing, err := escrow.Restore(ctx, store.NewAPIKeyRepository(db), snapshot)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "import failed after restoring %d API keys: %v\n", restored, err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "imported snapshot %s: %d API keys restored, %d already present\n", source, restored, existing)
+		if len(snapshot.SigningKeys) > 0 {
+			fmt.Fprintln(os.Stderr, "signing keys are not imported; provision JWT_SECRET and JWT_PRIVATE_KEY_PATH from escrow export")
+		}
+		return 0
+	}
+
+	usage()
+	return 2
+}
+
+// loadSnapshot reads a decrypted snapshot file, or decrypts the named or latest
+// snapshot of the escrow, returning where it came from
+func loadSnapshot(ctx context.Context, keyEscrow *escrow.Escrow, name, file string) (string, *escrow.Snapshot, error) {
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		var snapshot escrow.Snapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			return "", nil, fmt.Errorf("invalid snapshot %s: %w", file, err)
+		}
+		return file, &snapshot, nil
+	}
+
+	if keyEscrow == nil {
+		return "", nil, fmt.Errorf("ESCROW_DIR is not set")
+	}
+	if name != "" {
+		snapshot, err := keyEscrow.Load(ctx, name)
+		return name, snapshot, err
+	}
+	name, snapshot, err := keyEscrow.Latest(ctx)
+	if errors.Is(err, escrow.ErrNoSnapshots) {
+		return "", nil, fmt.Errorf("the key escrow holds no snapshots yet")
+	}
+	return name, snapshot, err
+}
@@ -3,26 +3,50 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
 	"github.com/yourusername/gatekeeper/internal/audit"
+	"github.com/yourusername/gatekeeper/internal/auditexport"
 	"github.com/yourusername/gatekeeper/internal/auth"
 	"github.com/yourusername/gatekeeper/internal/chain"
+	"github.com/yourusername/gatekeeper/internal/common"
 	"github.com/yourusername/gatekeeper/internal/config"
+	"github.com/yourusername/gatekeeper/internal/decisionlog"
+	"github.com/yourusername/gatekeeper/internal/escrow"
 	httpserver "github.com/yourusername/gatekeeper/internal/http"
 	"github.com/yourusername/gatekeeper/internal/http/handlers"
+	"github.com/yourusername/gatekeeper/internal/listener"
 	"github.com/yourusername/gatekeeper/internal/log"
+	"github.com/yourusername/gatekeeper/internal/notify"
+	"github.com/yourusername/gatekeeper/internal/oidc"
 	"github.com/yourusername/gatekeeper/internal/policy"
+	"github.com/yourusername/gatekeeper/internal/securitylog"
 	"github.com/yourusername/gatekeeper/internal/store"
+	"github.com/yourusername/gatekeeper/internal/webauthn"
 )
 
 func main() {
+	// Synthetic load for release validation runs in place of the server
+	if len(os.Args) > 1 && os.Args[1] == "soak" {
+		os.Exit(runSoak(os.Args[2:]))
+	}
+	// Recovery of escrowed key material runs in place of the server
+	if len(os.Args) > 1 && os.Args[1] == "escrow" {
+		os.Exit(runEscrow(os.Args[2:]))
+	}
+	// Policy tests run against mocked chain state, without a server
+	if len(os.Args) > 1 && os.Args[1] == "policy" {
+		os.Exit(runPolicy(os.Args[2:]))
+	}
+
 	// Load .env file for development (ignore error if file doesn't exist)
 	_ = godotenv.Load()
 
@@ -34,12 +58,19 @@ func main() {
 	}
 
 	// Initialize logger
-	logger, err := log.New(cfg.LogLevel)
+	logger, err := log.NewWithOptions(log.Options{
+		Level:              cfg.LogLevel,
+		Backend:            cfg.LogBackend,
+		SamplingInitial:    cfg.LogSamplingInitial,
+		SamplingThereafter: cfg.LogSamplingThereafter,
+	})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to initialize logger: %v\n", err)
 		os.Exit(1)
 	}
 	defer logger.Close()
+	// Packages written against zap log through the configured backend too
+	zapLogger := log.Zap(logger)
 
 	logger.Info(fmt.Sprintf("Starting Gatekeeper (port %s)", cfg.Port))
 
@@ -50,30 +81,97 @@ func main() {
 		ConnMaxLifetime: cfg.DBConnMaxLifetime,
 		ConnMaxIdleTime: cfg.DBConnMaxIdleTime,
 	}
-	db, err := store.Connect(context.Background(), cfg.DatabaseURL, poolCfg)
+	// Retry with backoff so the service can start alongside the database (e.g. docker-compose)
+	retryCfg := store.RetryConfig{
+		MaxAttempts:  cfg.DBConnectMaxAttempts,
+		InitialDelay: time.Second,
+		MaxDelay:     cfg.DBConnectMaxDelay,
+	}
+	db, err := store.ConnectWithRetry(context.Background(), cfg.DatabaseURL, poolCfg, retryCfg, func(attempt int, delay time.Duration, err error) {
+		logger.Warn(fmt.Sprintf("database connection attempt %d/%d failed, retrying in %v: %v",
+			attempt, cfg.DBConnectMaxAttempts, delay.Round(time.Millisecond), err))
+	})
 	if err != nil {
 		logger.Error(fmt.Sprintf("failed to connect to database: %v", err))
 		os.Exit(1)
 	}
 	defer db.Close()
+	db.SetQueryTimeouts(cfg.DBQueryTimeout, cfg.DBBulkQueryTimeout)
 
 	logger.Info(fmt.Sprintf("Database connected successfully (pool: max_open=%d, max_idle=%d, max_lifetime=%v, max_idle_time=%v)",
 		cfg.DBMaxOpenConns, cfg.DBMaxIdleConns, cfg.DBConnMaxLifetime, cfg.DBConnMaxIdleTime))
 
+	// Replicas starting together take turns migrating under an advisory lock
+	if cfg.DBAutoMigrate {
+		logger.Info("Applying database migrations")
+		if err := db.RunMigrations(); err != nil {
+			logger.Error(fmt.Sprintf("failed to run migrations: %v", err))
+			os.Exit(1)
+		}
+	}
+
 	// Initialize repositories
 	apiKeyRepo := store.NewAPIKeyRepository(db)
 	userRepo := store.NewUserRepository(db)
+	loginRepo := store.NewLoginRepository(db)
+	walletRepo := store.NewWalletRepository(db)
+
+	// Grant the initial admin its role; a no-op once any user is admin
+	if cfg.BootstrapAdminAddress != "" {
+		promoted, err := userRepo.BootstrapAdmin(context.Background(), cfg.BootstrapAdminAddress)
+		if err != nil {
+			logger.Error(fmt.Sprintf("failed to bootstrap admin: %v", err))
+			os.Exit(1)
+		}
+		if promoted {
+			logger.Info(fmt.Sprintf("Granted the admin role to bootstrap address %s", cfg.BootstrapAdminAddress))
+		}
+	}
 
 	// Initialize SIWE service
 	siweService := auth.NewSIWEService(cfg.NonceTTL)
 
 	// Initialize JWT service
 	jwtService := auth.NewJWTService(cfg.JWTSecret, cfg.JWTExpiry)
+	if cfg.JWTAlgorithm != auth.AlgorithmHS256 {
+		keyPEM, err := os.ReadFile(cfg.JWTPrivateKeyPath)
+		if err != nil {
+			logger.Error(fmt.Sprintf("failed to read JWT private key: %v", err))
+			os.Exit(1)
+		}
+		jwtService, err = auth.NewJWTServiceWithKey(cfg.JWTAlgorithm, keyPEM, cfg.JWTExpiry)
+		if err != nil {
+			logger.Error(fmt.Sprintf("failed to load JWT private key: %v", err))
+			os.Exit(1)
+		}
+	}
+	logger.Info(fmt.Sprintf("JWT tokens signed with %s", jwtService.Algorithm()))
+
+	// Access tokens revoked by logging out are rejected until they expire
+	if cfg.TokenRevocation == "postgres" {
+		jwtService.SetRevocationList(store.NewRevokedTokenRepository(db))
+	} else {
+		jwtService.SetRevocationList(auth.NewMemoryRevocationList())
+	}
+
+	// Testnet mode tolerates addresses copied with a wrong checksum, as staging
+	// environments often have them, and warns about each
+	network := chain.NetworkFor(cfg.ChainID)
+	if cfg.TestnetMode {
+		common.RelaxChecksums(func(address, expected string) {
+			logger.Warn(fmt.Sprintf("testnet mode: accepting address %s with invalid checksum (expected %s)", address, expected))
+		})
+		logger.Info(fmt.Sprintf("Testnet mode enabled on %s (chain %d)", network.Name, network.ChainID))
+	}
 
 	// Initialize blockchain provider (if RPC is configured)
 	var provider *chain.Provider
 	if cfg.EthereumRPC != "" {
-		provider = chain.NewProvider(cfg.EthereumRPC, "")
+		provider = chain.NewProvider(cfg.EthereumRPC, cfg.EthereumRPCFallback)
+		provider.SetProbeInterval(cfg.RPCProbeInterval)
+		provider.SetHedging(float64(cfg.RPCHedgePercentile), cfg.RPCHedgeMinDelay)
+		provider.SetLogger(zapLogger)
+		defer provider.Close()
 
 		// Test RPC connection
 		if !provider.HealthCheck(context.Background()) {
@@ -84,32 +182,246 @@ func main() {
 	}
 
 	// Initialize cache
-	cache := chain.NewCache(5 * time.Minute)
+	cache := chain.NewCache(cfg.CacheTTL)
+
+	// Rules call the provider through a meter, so the RPC calls of each policy
+	// evaluation can be attributed to the policy and the caller's tenant
+	var rulesProvider policy.BlockchainProvider
+	if provider != nil {
+		rulesProvider = policy.NewMeteredProvider(provider)
+
+		// The blockchain rules of a policy read concurrently; batching sends their
+		// reads in one round-trip
+		if cfg.RPCBatchWindow > 0 {
+			batcher := chain.NewBatcher(provider, cfg.RPCBatchWindow, cfg.RPCBatchMaxSize)
+			batcher.SetMulticall(cfg.RPCMulticall)
+			rulesProvider = policy.NewMeteredProvider(batcher)
+			logger.Info(fmt.Sprintf("RPC batching enabled: window=%v, max=%d, multicall=%t", cfg.RPCBatchWindow, cfg.RPCBatchMaxSize, cfg.RPCMulticall))
+		}
+	}
 
 	// Initialize policy manager
-	policyManager := policy.NewPolicyManager(provider, cache)
+	policyManager := policy.NewPolicyManager(rulesProvider, cache)
+
+	// Policies managed through the admin API are persisted. Load them before recording
+	// changes, so restarts do not appear in the change history.
+	policyManager.SetPolicyStore(store.NewPolicyRepository(db))
+	if err := policyManager.LoadFromStore(context.Background()); err != nil {
+		logger.Error(fmt.Sprintf("failed to load policies: %v", err))
+		os.Exit(1)
+	}
+	logger.Info(fmt.Sprintf("Loaded %d policies", policyManager.GetPoliciesCount()))
+
+	// Policy and allowlist mutations are recorded in the change history
+	changeHistoryRepo := store.NewChangeHistoryRepository(db)
+	policyManager.SetChangeRecorder(changeHistoryRepo)
+
+	// NFT trait rules resolve tokenURI metadata, cached alongside blockchain results
+	metadataResolver := policy.NewHTTPMetadataResolver(cfg.IPFSGateway, cfg.MetadataTimeout)
+	metadataResolver.SetCache(cache)
+	policyManager.SetMetadataResolver(metadataResolver)
+
+	// Quota rules count granted requests in the database, shared by all instances
+	policyManager.SetQuotaStore(store.NewQuotaRepository(db))
+
+	// Blocked addresses are denied by not_in_blocklist rules and on every gated route
+	blocklistRepo := store.NewBlocklistRepository(db)
+	policyManager.SetBlocklist(blocklistRepo)
+
+	// Geo restriction rules locate clients in the GeoIP table; without one they deny
+	if cfg.GeoIPDatabase != "" {
+		geoIP, err := policy.LoadGeoIPFile(cfg.GeoIPDatabase)
+		if err != nil {
+			logger.Error(fmt.Sprintf("failed to load GeoIP database: %v", err))
+			os.Exit(1)
+		}
+		policyManager.SetGeoIPResolver(geoIP)
+		logger.Info(fmt.Sprintf("GeoIP database loaded: %d networks", geoIP.Len()))
+	}
+
+	// Plugin rules run custom rules compiled to WebAssembly, sandboxed in-process
+	if cfg.PluginsDir != "" {
+		plugins, err := policy.LoadPlugins(context.Background(), cfg.PluginsDir, cfg.PluginMemoryLimitMB)
+		if err != nil {
+			logger.Error(fmt.Sprintf("failed to load plugins: %v", err))
+			os.Exit(1)
+		}
+		defer plugins.Close(context.Background())
+		policyManager.SetPlugins(plugins)
+		logger.Info(fmt.Sprintf("Plugins loaded: %s", strings.Join(plugins.Names(), ", ")))
+	}
 
-	// Initialize audit logger
-	auditLogger := audit.NewAuditLogger(logger.Logger)
+	// Initialize audit logger, archiving events to object storage when a bucket is configured
+	var auditSinks []audit.Sink
+	var auditExporter *auditexport.Exporter
+	if cfg.AuditExportBucket != "" {
+		objectStore, err := auditexport.NewS3Client(auditexport.S3Config{
+			Endpoint:        cfg.AuditExportEndpoint,
+			Region:          cfg.AuditExportRegion,
+			Bucket:          cfg.AuditExportBucket,
+			AccessKeyID:     cfg.AuditExportAccessKeyID,
+			SecretAccessKey: cfg.AuditExportSecretAccessKey,
+			PathStyle:       cfg.AuditExportPathStyle,
+		})
+		if err != nil {
+			logger.Error(fmt.Sprintf("failed to configure audit export: %v", err))
+			os.Exit(1)
+		}
+		auditExporter = auditexport.NewExporter(objectStore, auditexport.Config{
+			Prefix:        cfg.AuditExportPrefix,
+			BatchSize:     cfg.AuditExportBatchSize,
+			FlushInterval: cfg.AuditExportFlushInterval,
+		}, zapLogger)
+		auditSinks = append(auditSinks, auditExporter)
+		logger.Info(fmt.Sprintf("Audit export enabled: %s/%s", cfg.AuditExportBucket, cfg.AuditExportPrefix))
+	}
+	// Security events of each wallet are kept for GET /api/me/audit
+	securityEventRepo := store.NewSecurityEventRepository(db)
+	securityEvents := securitylog.NewRecorder(securityEventRepo, cfg.SecurityEventRetention, zapLogger)
+	auditSinks = append(auditSinks, securityEvents)
+	auditLogger := audit.LabelNetwork(audit.NewAuditLoggerWithSinks(zapLogger, auditSinks...), network.Name, cfg.TestnetMode)
+
+	// Initialize scope catalog
+	scopeCatalog := auth.DefaultScopeCatalog()
+	if cfg.ScopeCatalogFile != "" {
+		data, err := os.ReadFile(cfg.ScopeCatalogFile)
+		if err != nil {
+			logger.Error(fmt.Sprintf("failed to read scope catalog: %v", err))
+			os.Exit(1)
+		}
+		scopeCatalog, err = auth.LoadScopeCatalogFromJSON(data)
+		if err != nil {
+			logger.Error(fmt.Sprintf("failed to load scope catalog: %v", err))
+			os.Exit(1)
+		}
+		logger.Info(fmt.Sprintf("Loaded scope catalog from %s", cfg.ScopeCatalogFile))
+	}
 
-	// Initialize metrics collector
+	// Initialize metrics collector; it also receives the duration of every repository query
 	metricsCollector := httpserver.NewMetricsCollector(db)
+	db.Instrument(metricsCollector, cfg.DBSlowQueryThreshold, zapLogger)
 
 	// Initialize health handler
-	healthHandler := handlers.NewHealthHandler(db, provider, logger.Logger, cfg.Version)
+	healthHandler := handlers.NewHealthHandler(db, provider, zapLogger, cfg.Version)
+
+	// Check the database in the background; readiness follows its availability
+	dbMonitor := store.NewHealthMonitor(db, cfg.DBHealthCheckInterval, func(available bool, err error) {
+		if available {
+			logger.Info("Database connection restored")
+		} else {
+			logger.Error(fmt.Sprintf("database unavailable, reporting not ready: %v", err))
+		}
+	})
+	dbMonitor.Start()
+	defer dbMonitor.Stop()
+	healthHandler.SetDatabaseMonitor(dbMonitor)
+	// Readiness also waits for the schema this build expects, e.g. while another replica migrates
+	healthHandler.SetMigrationChecker(db)
+
+	// Notify owners of API keys about to expire through the configured sinks
+	var notifiers []notify.Notifier
+	if cfg.NotifyWebhookURL != "" {
+		notifiers = append(notifiers, notify.NewWebhookNotifier(cfg.NotifyWebhookURL, cfg.NotifyWebhookSecret))
+	}
+	if cfg.SMTPAddr != "" {
+		notifiers = append(notifiers, notify.NewSMTPNotifier(cfg.SMTPAddr, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom, cfg.NotifyEmailTo))
+	}
+	if len(notifiers) > 0 {
+		keyExpiryJob := notify.NewKeyExpiryJob(apiKeyRepo, notifiers, cfg.KeyExpiryNotifyHorizon, cfg.KeyExpiryCheckInterval, zapLogger)
+		keyExpiryJob.Start()
+		defer keyExpiryJob.Stop()
+		logger.Info(fmt.Sprintf("Key expiry notifications enabled: horizon=%s, interval=%s", cfg.KeyExpiryNotifyHorizon, cfg.KeyExpiryCheckInterval))
+	}
+
+	// Escrow API key hashes and signing keys so a lost database does not orphan
+	// credentials held by downstream systems
+	if cfg.EscrowDir != "" {
+		keyEscrow, err := newEscrow(cfg)
+		if err != nil {
+			logger.Error(fmt.Sprintf("failed to open key escrow: %v", err))
+			os.Exit(1)
+		}
+		signingKeys, err := escrowSigningKeys(cfg, jwtService)
+		if err != nil {
+			logger.Error(fmt.Sprintf("failed to collect signing keys for escrow: %v", err))
+			os.Exit(1)
+		}
+		escrowJob := escrow.NewJob(keyEscrow, apiKeyRepo, signingKeys, cfg.EscrowInterval, zapLogger)
+		escrowJob.Start()
+		defer escrowJob.Stop()
+		logger.Info(fmt.Sprintf("Key escrow enabled: dir=%s, interval=%s", cfg.EscrowDir, cfg.EscrowInterval))
+	}
 
 	// Initialize API Key handlers
 	apiKeyHandler := httpserver.NewAPIKeyHandler(apiKeyRepo, userRepo, logger, auditLogger)
+	apiKeyHandler.SetScopeCatalog(scopeCatalog)
+
+	// Initialize scope catalog handler
+	scopeHandler := httpserver.NewScopeHandler(scopeCatalog)
+
+	// Initialize account handler
+	accountHandler := httpserver.NewAccountHandler(userRepo, loginRepo, logger)
+	walletHandler := httpserver.NewWalletHandler(siweService, walletRepo, userRepo, logger, auditLogger)
+	walletHandler.SetNotifiers(notifiers)
+
+	// Passkeys as a second factor for key management (optional)
+	var webAuthnHandler *httpserver.WebAuthnHandler
+	requirePasskey := func(next http.Handler) http.Handler { return next }
+	if cfg.WebAuthnRPID != "" {
+		webAuthnService := webauthn.NewService(cfg.WebAuthnRPID, cfg.WebAuthnOrigins, cfg.WebAuthnChallengeTTL)
+		webAuthnHandler = httpserver.NewWebAuthnHandler(webAuthnService, cfg.WebAuthnRPName, store.NewWebAuthnRepository(db), userRepo, logger, auditLogger)
+		requirePasskey = webAuthnHandler.RequireAssertion
+		logger.Info(fmt.Sprintf("Passkey step-up enabled: rp_id=%s, origins=%v", cfg.WebAuthnRPID, cfg.WebAuthnOrigins))
+	}
+
+	// Initialize service account handlers
+	serviceAccountHandler := httpserver.NewServiceAccountHandler(userRepo, apiKeyRepo, userRepo, logger, auditLogger)
+	serviceAccountHandler.SetScopeCatalog(scopeCatalog)
 
 	// Initialize API Key middleware
 	apiKeyMiddleware := httpserver.NewAPIKeyMiddleware(apiKeyRepo, userRepo, logger, auditLogger)
+	apiKeyMiddleware.SetScopeCatalog(scopeCatalog)
+	apiKeyMiddleware.SetHeaderNames(cfg.APIKeyHeaders...)
+	apiKeyMiddleware.SetCookieName(cfg.APIKeyCookie)
+
+	// Deprecated keys get warning headers until their sunset, when they are revoked
+	keyDeprecationRepo := store.NewKeyDeprecationRepository(db)
+	keyDeprecations := httpserver.NewKeyDeprecations(keyDeprecationRepo, time.Minute)
+	apiKeyMiddleware.SetDeprecations(keyDeprecations)
+	keySunsetJob := httpserver.NewKeySunsetJob(keyDeprecationRepo, apiKeyRepo, cfg.KeySunsetCheckInterval, logger, auditLogger)
+	keySunsetJob.Start()
+	defer keySunsetJob.Stop()
+
+	// Initialize admin handler
+	adminHandler := httpserver.NewAdminHandler(logger, auditLogger)
+
+	// Initialize emergency key revocation handler
+	keyRevocationHandler := httpserver.NewKeyRevocationHandler(apiKeyRepo, logger, auditLogger)
+	keyDeprecationHandler := httpserver.NewKeyDeprecationHandler(keyDeprecationRepo, apiKeyRepo, logger, auditLogger)
+	keyDeprecationHandler.SetDeprecations(keyDeprecations)
+	keyTransferHandler := httpserver.NewKeyTransferHandler(apiKeyRepo, userRepo, logger, auditLogger)
+	roleHandler := httpserver.NewRoleHandler(userRepo, logger, auditLogger)
+	policyHandler := httpserver.NewPolicyHandler(policyManager, logger, auditLogger)
+
+	// Initialize change history handler
+	changeHistoryHandler := httpserver.NewChangeHistoryHandler(changeHistoryRepo, logger)
+
+	// Initialize allowlist handler
+	allowlistHandler := httpserver.NewAllowlistHandler(store.NewAllowlistRepository(db), logger, auditLogger)
+	blocklistHandler := httpserver.NewBlocklistHandler(blocklistRepo, logger, auditLogger)
+
+	// Signed URLs share gated resources under SIGNED_URL_PATHS temporarily; the key is
+	// derived from the JWT secret
+	urlSigner := auth.NewURLSigner(cfg.JWTSecret)
+	signedURLHandler := httpserver.NewSignedURLHandler(urlSigner, logger, auditLogger)
+	signedURLHandler.SetPaths(cfg.SignedURLPaths)
 
 	// Initialize documentation handler
 	docsHandler := handlers.NewDocsHandler()
 
 	// Initialize middleware
-	metricsMiddleware := httpserver.NewMetricsMiddleware(metricsCollector, logger.Logger)
-	loggingMiddleware := httpserver.NewLoggingMiddleware(logger.Logger)
+	metricsMiddleware := httpserver.NewMetricsMiddleware(metricsCollector, zapLogger)
+	loggingMiddleware := httpserver.NewLoggingMiddleware(zapLogger)
 
 	// Initialize rate limiters
 	apiKeyCreationLimiter := httpserver.NewInMemoryRateLimiter(
@@ -124,19 +436,58 @@ func main() {
 	)
 
 	// Create rate limit middlewares
-	apiKeyCreationRateLimiter := httpserver.NewUserRateLimitMiddleware(apiKeyCreationLimiter, logger)
-	apiUsageRateLimiter := httpserver.NewUserRateLimitMiddleware(apiUsageLimiter, logger)
+	apiKeyCreationRateLimiter := httpserver.NewUserRateLimitMiddleware(apiKeyCreationLimiter, logger, httpserver.WithLimitName("api_key_creation"))
+	apiUsageRateLimiter := httpserver.NewUserRateLimitMiddleware(apiUsageLimiter, logger, httpserver.WithLimitName("api_usage"))
+
+	if !cfg.EnforceKeyScopes {
+		logger.Warn("Key management scope enforcement disabled: any API key can manage its owner's keys")
+	}
+	if !cfg.EnforceRouteScopes {
+		logger.Warn("Route scope enforcement disabled: read-only API keys can call mutating endpoints")
+	}
 
 	logger.Info(fmt.Sprintf("Rate limiting enabled: API key creation=%d/hour (burst=%d), API usage=%d/min (burst=%d)",
 		cfg.APIKeyCreationRateLimit, cfg.APIKeyCreationBurstLimit,
 		cfg.APIUsageRateLimit, cfg.APIUsageBurstLimit))
+	logger.Info(fmt.Sprintf("Concurrency limiting: in flight per IP=%d, per key=%d (0 = unlimited)",
+		cfg.MaxInFlightPerIP, cfg.MaxInFlightPerKey))
 
 	// Create HTTP router
 	router := mux.NewRouter()
 
-	// Apply global middleware (order matters: logging -> metrics)
+	// Apply global middleware (order matters: logging -> metrics -> tracing -> request logger)
+	// The request logger needs the request ID assigned by the metrics middleware
 	router.Use(mux.MiddlewareFunc(loggingMiddleware.Middleware()))
 	router.Use(mux.MiddlewareFunc(metricsMiddleware.Middleware()))
+	if cfg.TracingEnabled {
+		router.Use(mux.MiddlewareFunc(httpserver.TraceMiddleware()))
+		logger.Info("Tracing enabled: latency metrics carry trace exemplars")
+	}
+	router.Use(mux.MiddlewareFunc(httpserver.RequestLoggerMiddleware(logger)))
+
+	// Audit events carry the JA3 fingerprint forwarded by the trusted proxies
+	tlsFingerprintMiddleware, err := httpserver.TLSFingerprintMiddleware(cfg.TLSFingerprintHeader, cfg.ProxyProtocolTrustedCIDRs)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to configure TLS fingerprint capture: %v", err))
+		os.Exit(1)
+	}
+	router.Use(mux.MiddlewareFunc(tlsFingerprintMiddleware))
+	if cfg.TLSFingerprintHeader != "" {
+		logger.Info(fmt.Sprintf("TLS fingerprint capture enabled: %s from %v", cfg.TLSFingerprintHeader, cfg.ProxyProtocolTrustedCIDRs))
+	}
+	if cfg.DebugTimingEnabled {
+		router.Use(mux.MiddlewareFunc(httpserver.DebugTimingMiddleware()))
+		logger.Info("Debug timing enabled: admins may request Server-Timing breakdowns")
+	}
+
+	// Cap requests in flight per client IP so slow or long-held requests cannot exhaust goroutines
+	if cfg.MaxInFlightPerIP > 0 {
+		router.Use(mux.MiddlewareFunc(httpserver.ConcurrencyLimitMiddleware(
+			httpserver.NewConcurrencyLimiter(cfg.MaxInFlightPerIP), httpserver.IPIdentifier, "inflight_per_ip", logger)))
+	}
+
+	// Every endpoint that accepts a body takes JSON; reject other content types with 415
+	router.Use(mux.MiddlewareFunc(httpserver.RequireJSONContentType(nonJSONPaths...)))
 
 	// Health check endpoints (no authentication required)
 	router.HandleFunc("/health", healthHandler.Health).Methods("GET")
@@ -162,73 +513,174 @@ func main() {
 		fmt.Fprintf(w, `{"nonce":"%s","expiresIn":%d}`, nonce, expiresIn)
 	}).Methods("GET")
 
-	// POST /auth/siwe/verify - Verify SIWE signature and issue JWT
-	router.HandleFunc("/auth/siwe/verify", func(w http.ResponseWriter, r *http.Request) {
-		var req struct {
-			Message   string `json:"message"`
-			Signature string `json:"signature"`
-		}
+	// POST /auth/siwe/verify - Verify SIWE signature, record the sign-in and issue JWT
+	authHandler := httpserver.NewAuthHandler(siweService, jwtService, userRepo, logger, auditLogger)
+	authHandler.SetLoginRepository(loginRepo)
+	authHandler.SetWalletRepository(walletRepo)
+	if cfg.RefreshTokenTTL > 0 {
+		authHandler.SetSessionRepository(store.NewSessionRepository(db), cfg.RefreshTokenTTL)
+	}
+	router.HandleFunc("/auth/siwe/verify", authHandler.VerifySIWE).Methods("POST")
 
-		if err := parseJSON(r, &req); err != nil {
-			http.Error(w, "Invalid request", http.StatusBadRequest)
-			return
-		}
+	// POST /auth/refresh - Exchange a refresh token for a new token pair (rotation)
+	router.HandleFunc("/auth/refresh", authHandler.Refresh).Methods("POST")
 
-		if req.Message == "" || req.Signature == "" {
-			http.Error(w, "Missing message or signature", http.StatusBadRequest)
-			return
-		}
+	// POST /auth/logout - Revoke the session of a refresh token
+	router.HandleFunc("/auth/logout", authHandler.Logout).Methods("POST")
 
-		// For now, just verify nonce exists and generate token
-		// In production, would verify actual SIWE signature
-		// Extract address from message (simplified: look for "0x" address pattern)
-		address := extractAddressFromMessage(req.Message)
-		if address == "" {
-			http.Error(w, "Invalid message format", http.StatusBadRequest)
-			return
-		}
+	// GET /.well-known/jwks.json - Public keys for verifying tokens (empty for HS256)
+	router.HandleFunc("/.well-known/jwks.json", authHandler.JWKS).Methods("GET")
 
-		token, err := jwtService.GenerateToken(r.Context(), address, []string{})
+	// OpenID Connect provider - other applications sign users in with their wallets
+	if cfg.OIDCIssuer != "" {
+		data, err := os.ReadFile(cfg.OIDCClientsFile)
 		if err != nil {
-			logger.Error(fmt.Sprintf("failed to generate token: %v", err))
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			return
+			logger.Error(fmt.Sprintf("failed to read OIDC clients: %v", err))
+			os.Exit(1)
+		}
+		clients, err := oidc.LoadClientsFromJSON(data)
+		if err != nil {
+			logger.Error(fmt.Sprintf("failed to load OIDC clients: %v", err))
+			os.Exit(1)
 		}
+		authHandler.SetOIDCProvider(oidc.NewProvider(cfg.OIDCIssuer, clients, jwtService, cfg.OIDCCodeTTL, cfg.OIDCAccessTokenTTL))
 
-		expiresInSeconds := int(cfg.JWTExpiry.Seconds())
-		w.Header().Set("Content-Type", "application/json")
-		fmt.Fprintf(w, `{"token":"%s","expiresIn":%d,"address":"%s"}`,
-			token, expiresInSeconds, address)
-	}).Methods("POST")
+		router.HandleFunc("/.well-known/openid-configuration", authHandler.OIDCDiscovery).Methods("GET")
+		router.HandleFunc("/oidc/authorize", authHandler.OIDCAuthorize).Methods("GET", "POST")
+		router.HandleFunc("/oidc/token", authHandler.OIDCToken).Methods("POST")
+		router.HandleFunc("/oidc/userinfo", authHandler.OIDCUserInfo).Methods("GET", "POST")
+		logger.Info(fmt.Sprintf("OIDC provider enabled: issuer=%s, clients=%d", cfg.OIDCIssuer, len(clients)))
+	}
 
+	// Read endpoints polled by dashboards answer If-None-Match with 304 Not Modified
+	conditionalGET := httpserver.ConditionalGETMiddleware()
 
 	// Documentation endpoints (no authentication required)
 	// GET /openapi.yaml - Serve OpenAPI specification
-	router.HandleFunc("/openapi.yaml", docsHandler.ServeOpenAPISpec).Methods("GET", "OPTIONS")
+	router.Handle("/openapi.yaml", conditionalGET(http.HandlerFunc(docsHandler.ServeOpenAPISpec))).Methods("GET", "OPTIONS")
 
 	// GET /docs - Serve Redoc documentation UI
 	router.HandleFunc("/docs", docsHandler.ServeRedocUI).Methods("GET", "OPTIONS")
 
 	logger.Info("Documentation endpoints registered: /docs and /openapi.yaml")
 
+	// GET /.well-known/gatekeeper.json - Requirements of the gated routes for dapp frontends
+	gateDiscoveryHandler := httpserver.NewGateDiscoveryHandler(policyManager)
+	gateDiscoveryHandler.SetNetwork(network)
+	router.Handle("/.well-known/gatekeeper.json", conditionalGET(http.HandlerFunc(gateDiscoveryHandler.GetGates))).Methods("GET", "OPTIONS")
+
 	// JWT Middleware for protected routes
 	jwtMiddleware := httpserver.JWTMiddleware(jwtService)
 
+	// POST /auth/downscope - Exchange a JWT for a narrower, shorter-lived token
+	router.Handle("/auth/downscope", jwtMiddleware(http.HandlerFunc(authHandler.Downscope))).Methods("POST")
+
+	// POST /auth/capability - Mint a token valid for a single method and path
+	router.Handle("/auth/capability", jwtMiddleware(http.HandlerFunc(authHandler.CreateCapability))).Methods("POST")
+
 	// Policy Middleware for access control
 	policyMiddleware := httpserver.NewPolicyMiddleware(policyManager, logger, auditLogger)
+	policyMiddleware.SetMetrics(metricsCollector)
+	policyMiddleware.SetChainID(cfg.ChainID)
+	policyMiddleware.SetDenialDetail(cfg.PolicyDenialDetail)
+	policyMiddleware.SetWalletRepository(walletRepo)
+	policyMiddleware.SetBlocklist(blocklistRepo)
+	policyStats := httpserver.NewPolicyStats(cfg.PolicyStatsWindow)
+	policyMiddleware.SetStats(policyStats)
+	rpcUsage := httpserver.NewRPCUsageTracker(cfg.RPCCallCost, cfg.RPCCallCosts, cfg.RPCUsageTenantClaim)
+	policyMiddleware.SetRPCUsage(rpcUsage)
+	if cfg.DecisionLogFile != "" {
+		decisionLog, err := decisionlog.New(cfg.DecisionLogFile, decisionlog.RotationConfig{
+			MaxSize:    cfg.DecisionLogMaxSize,
+			MaxAge:     cfg.DecisionLogMaxAge,
+			MaxBackups: cfg.DecisionLogMaxBackups,
+			Compress:   cfg.DecisionLogCompress,
+		}, zapLogger)
+		if err != nil {
+			logger.Error(fmt.Sprintf("failed to open decision log: %v", err))
+			os.Exit(1)
+		}
+		defer decisionLog.Close()
+		policyMiddleware.SetDecisionLog(decisionLog)
+		logger.Info(fmt.Sprintf("Decision log enabled: %s", cfg.DecisionLogFile))
+	}
 	if provider != nil {
-		policyMiddleware.SetProvider(provider)
+		policyMiddleware.SetProvider(rulesProvider)
 		policyMiddleware.SetCache(cache)
 	}
 
+	// Refresh cached blockchain results of active and configured addresses before they expire
+	if provider != nil && cfg.CacheWarmInterval > 0 {
+		cacheWarmer := policy.NewCacheWarmer(policyManager, cfg.CacheWarmAddresses, cfg.CacheWarmInterval, cfg.CacheWarmActive, zapLogger)
+		policyMiddleware.SetCacheWarmer(cacheWarmer)
+		cacheWarmer.Start()
+		defer cacheWarmer.Stop()
+		logger.Info(fmt.Sprintf("Cache warming enabled: interval=%s, active=%s, addresses=%d", cfg.CacheWarmInterval, cfg.CacheWarmActive, len(cfg.CacheWarmAddresses)))
+	}
+
+	// Report the configuration and the state of its dependencies for supportability
+	selfCheck := httpserver.NewSelfCheck(cfg.Version, cfg.Summary(), cfg.Features(), policyManager)
+	selfCheck.SetMigrationChecker(db)
+	if provider != nil {
+		selfCheck.SetProvider(provider, cfg.ChainID)
+	}
+	selfCheck.Run(context.Background()).Log(logger)
+
 	// Create a subrouter for protected routes with authentication
 	apiRouter := router.PathPrefix("/api").Subrouter()
 
 	// Apply authentication middleware chain to /api routes
-	// Order: API Key first (optional), then JWT (fallback if no API key), then general API rate limiting
-	apiRouter.Use(mux.MiddlewareFunc(apiKeyMiddleware.Middleware()))
-	apiRouter.Use(mux.MiddlewareFunc(jwtMiddleware))
+	// Order: signed URLs first (acting as their signer on SIGNED_URL_PATHS), then
+	// capability tokens (bound to a single endpoint), then API Key (optional), then JWT
+	// (fallback if no API key), then the address check of bound signed URLs, then
+	// per-key concurrency and general API rate limiting, then access policies. Every
+	// /api route is policy-checked unless exempted below.
+	// The authentication chain is timed as a whole for auth_duration_seconds; it, the
+	// policies and the handler are also the phases of debug Server-Timing breakdowns.
+	apiRouter.Use(mux.MiddlewareFunc(httpserver.TimeAuth(metricsCollector,
+		httpserver.SignedURLMiddleware(urlSigner, jwtService, cfg.SignedURLPaths),
+		httpserver.CapabilityMiddleware(jwtService),
+		apiKeyMiddleware.Middleware(),
+		jwtMiddleware,
+		httpserver.RequireSignedURLBinding(),
+	)))
+	if cfg.MaxInFlightPerKey > 0 {
+		apiRouter.Use(mux.MiddlewareFunc(httpserver.ConcurrencyLimitMiddleware(
+			httpserver.NewConcurrencyLimiter(cfg.MaxInFlightPerKey), httpserver.CredentialIdentifier, "inflight_per_key", logger)))
+	}
 	apiRouter.Use(mux.MiddlewareFunc(apiUsageRateLimiter.Middleware()))
+	// Tokens handed to third parties cannot manage the account they derive from
+	apiRouter.Use(mux.MiddlewareFunc(httpserver.RejectDerivedTokens(accountPaths...)))
+
+	// API keys need read for safe methods and write otherwise, unless the route
+	// declares its own scopes; JWT sessions are unrestricted
+	routeScopes := httpserver.NewRouteScopes()
+	if cfg.EnforceRouteScopes {
+		apiRouter.Use(mux.MiddlewareFunc(routeScopes.Middleware()))
+	}
+	apiRouter.Use(mux.MiddlewareFunc(httpserver.TimePhase(httpserver.TimingPolicy, policyMiddleware.Middleware())))
+	// Callers granted a tier by a TIER policy get the quota of their tier, on top of
+	// the API usage limit checked before their policies cost RPC calls
+	if len(cfg.TierRateLimits) > 0 {
+		tierLimiters := make(map[string]httpserver.RateLimiter, len(cfg.TierRateLimits))
+		for tier, rate := range cfg.TierRateLimits {
+			// Tiers burst in the same proportion to their rate as the API usage limit
+			burst := rate
+			if cfg.APIUsageRateLimit > 0 {
+				burst = max(1, rate*cfg.APIUsageBurstLimit/cfg.APIUsageRateLimit)
+			}
+			tierLimiters[tier] = httpserver.NewInMemoryRateLimiter(rate, time.Minute, burst)
+		}
+		tierRateLimiter := httpserver.NewUserRateLimitMiddleware(nil, logger,
+			httpserver.WithLimitName("api_usage_tier"), httpserver.WithTierLimiters(tierLimiters))
+		apiRouter.Use(mux.MiddlewareFunc(tierRateLimiter.Middleware()))
+		logger.Info(fmt.Sprintf("Tier rate limiting enabled for %d tiers", len(tierLimiters)))
+	}
+	apiRouter.Use(mux.MiddlewareFunc(httpserver.TimeUpstream()))
+
+	// Key management scopes apply to API-key callers; JWT sessions are unrestricted
+	requireKeysRead := httpserver.RequireAPIKeyScope(httpserver.ScopeKeysRead, cfg.EnforceKeyScopes)
+	requireKeysWrite := httpserver.RequireAPIKeyScope(httpserver.ScopeKeysWrite, cfg.EnforceKeyScopes)
 
 	// API Key management endpoints (require authentication + specific rate limiting)
 	// Create separate handler for POST /keys with stricter rate limiting
@@ -236,14 +688,135 @@ func main() {
 
 	// POST /api/keys - stricter rate limit for key creation (10/hour per user)
 	keysPostRouter := keysRouter.Methods("POST").Subrouter()
+	keysPostRouter.Use(mux.MiddlewareFunc(requireKeysWrite))
+	keysPostRouter.Use(mux.MiddlewareFunc(requirePasskey))
 	keysPostRouter.Use(mux.MiddlewareFunc(apiKeyCreationRateLimiter.Middleware()))
-	keysPostRouter.HandleFunc("", apiKeyHandler.CreateAPIKey)
+	createKeyRoute := keysPostRouter.HandleFunc("", apiKeyHandler.CreateAPIKey)
 
 	// GET and DELETE have normal API rate limits
-	keysRouter.HandleFunc("", apiKeyHandler.ListAPIKeys).Methods("GET")
-	keysRouter.HandleFunc("/{id}", apiKeyHandler.RevokeAPIKey).Methods("DELETE")
+	listKeysRoute := keysRouter.Handle("", requireKeysRead(conditionalGET(http.HandlerFunc(apiKeyHandler.ListAPIKeys)))).Methods("GET")
+	revokeKeyRoute := keysRouter.Handle("/{id}", requireKeysWrite(requirePasskey(http.HandlerFunc(apiKeyHandler.RevokeAPIKey)))).Methods("DELETE")
+
+	// GET /api/me/logins - the caller's recent sign-ins
+	loginsRoute := apiRouter.HandleFunc("/me/logins", accountHandler.ListLogins).Methods("GET")
+
+	// GET /api/me/audit - the caller's security events: sign-ins, key and credential
+	// changes and denied requests
+	securityEventsRoute := apiRouter.HandleFunc("/me/audit", httpserver.NewSecurityEventHandler(securityEventRepo, logger).ListSecurityEvents).Methods("GET")
+
+	// Wallets linked to the caller's account; a linked wallet signs in as the account
+	// GET /api/me/wallets, POST /api/me/wallets/link (SIWE proof from the new wallet,
+	// sessions only, behind passkey step-up), DELETE /api/me/wallets/{address}
+	apiRouter.HandleFunc("/me/wallets", walletHandler.ListWallets).Methods("GET")
+	apiRouter.Handle("/me/wallets/link", requirePasskey(http.HandlerFunc(walletHandler.LinkWallet))).Methods("POST")
+	unlinkWalletRoute := apiRouter.HandleFunc("/me/wallets/{address}", walletHandler.UnlinkWallet).Methods("DELETE")
+
+	// Passkeys of the caller's account, required as a second factor for key
+	// management once registered
+	if webAuthnHandler != nil {
+		apiRouter.HandleFunc("/me/webauthn/register/begin", webAuthnHandler.BeginRegistration).Methods("POST")
+		apiRouter.Handle("/me/webauthn/register/finish", requirePasskey(http.HandlerFunc(webAuthnHandler.FinishRegistration))).Methods("POST")
+		apiRouter.HandleFunc("/me/webauthn/assertion/begin", webAuthnHandler.BeginAssertion).Methods("POST")
+		apiRouter.HandleFunc("/me/webauthn/credentials", webAuthnHandler.ListCredentials).Methods("GET")
+		apiRouter.Handle("/me/webauthn/credentials/{id}", requirePasskey(http.HandlerFunc(webAuthnHandler.DeleteCredential))).Methods("DELETE")
+	}
+
+	// Responding to a compromised account must never be blocked by access policies
+	policyMiddleware.Exempt(revokeKeyRoute, loginsRoute, securityEventsRoute, unlinkWalletRoute)
+
+	// GET /api/eligibility - the caller's outcome for the policies of a route, from
+	// cached blockchain results only; it reports on policies rather than being gated by them
+	eligibilityHandler := httpserver.NewEligibilityHandler(policyMiddleware)
+	eligibilityRoute := apiRouter.HandleFunc("/eligibility", eligibilityHandler.CheckEligibility).Methods("GET")
+	policyMiddleware.Exempt(eligibilityRoute)
+
+	// POST /api/authz/check - policy decisions for systems that do not call through the
+	// gateway, on behalf of any address, so every caller needs the authz:check scope
+	authzHandler := httpserver.NewAuthzHandler(policyMiddleware)
+	authzCheckRoute := apiRouter.Handle("/authz/check",
+		httpserver.RequireScope(httpserver.ScopeAuthzCheck, scopeCatalog)(http.HandlerFunc(authzHandler.Check))).Methods("POST")
+	routeScopes.Declare(authzCheckRoute, httpserver.ScopeAuthzCheck)
+	policyMiddleware.Exempt(authzCheckRoute)
+
+	// GET /api/scopes - scopes that can be granted to keys
+	apiRouter.HandleFunc("/scopes", scopeHandler.ListScopes).Methods("GET")
+
+	// POST /api/signed-urls - sign a path under SIGNED_URL_PATHS for temporary access
+	// without a credential
+	apiRouter.HandleFunc("/signed-urls", signedURLHandler.CreateSignedURL).Methods("POST")
+
+	// Admin endpoints: viewers read them, operators also act on the running service
+	// and admins manage everything, including policies, allowlists and roles
+	requireOperator := httpserver.RequireRole(auth.RoleOperator)
+	requireAdmin := httpserver.RequireRole(auth.RoleAdmin)
+	adminRouter := apiRouter.PathPrefix("/admin").Subrouter()
+	adminRouter.Use(mux.MiddlewareFunc(httpserver.RequireRole(auth.RoleViewer)))
+	adminRouter.HandleFunc("/log-level", adminHandler.GetLogLevel).Methods("GET")
+	adminRouter.Handle("/log-level", requireOperator(http.HandlerFunc(adminHandler.SetLogLevel))).Methods("PUT")
+	selfCheckRoute := adminRouter.HandleFunc("/selfcheck", selfCheck.GetReport).Methods("GET")
+	adminRouter.Handle("/keys/revoke", requireOperator(http.HandlerFunc(keyRevocationHandler.RevokeKeys))).Methods("POST")
+	adminRouter.HandleFunc("/keys/deprecations", keyDeprecationHandler.ListDeprecations).Methods("GET")
+	adminRouter.Handle("/keys/deprecations", requireAdmin(http.HandlerFunc(keyDeprecationHandler.CreateDeprecation))).Methods("POST")
+	adminRouter.HandleFunc("/keys/deprecations/{id:[0-9]+}", keyDeprecationHandler.GetDeprecation).Methods("GET")
+	adminRouter.Handle("/keys/deprecations/{id:[0-9]+}", requireAdmin(http.HandlerFunc(keyDeprecationHandler.CancelDeprecation))).Methods("DELETE")
+	adminRouter.Handle("/keys/{id}/transfer", requireAdmin(http.HandlerFunc(keyTransferHandler.TransferKey))).Methods("POST")
+	importRoute := adminRouter.Handle("/allowlists/{id}/import", requireAdmin(http.HandlerFunc(allowlistHandler.ImportAddresses))).Methods("POST")
+	exportRoute := adminRouter.HandleFunc("/allowlists/{id}/export", allowlistHandler.ExportAddresses).Methods("GET")
+	merkleRoute := adminRouter.HandleFunc("/allowlists/{id}/merkle", allowlistHandler.GetMerkleTree).Methods("GET")
+	adminRouter.HandleFunc("/allowlists/{id}/changes", allowlistHandler.GetAddressChanges).Methods("GET")
+	// POST /api/admin/authz/explain - a policy decision with what each rule requires,
+	// thresholds included; it reports on policies rather than being gated by them
+	explainRoute := adminRouter.Handle("/authz/explain", requireAdmin(http.HandlerFunc(authzHandler.Explain))).Methods("POST")
+	policyMiddleware.Exempt(explainRoute)
+	adminRouter.HandleFunc("/users", roleHandler.ListRoles).Methods("GET")
+	adminRouter.Handle("/users/{address}/role", requireAdmin(http.HandlerFunc(roleHandler.SetRole))).Methods("PUT")
+	adminRouter.Handle("/history", conditionalGET(http.HandlerFunc(changeHistoryHandler.ListChanges))).Methods("GET")
+	adminRouter.Handle("/policies/stats", conditionalGET(http.HandlerFunc(httpserver.NewPolicyStatsHandler(policyStats, policyManager).GetStats))).Methods("GET")
+	adminRouter.HandleFunc("/rpc-usage", httpserver.NewRPCUsageHandler(rpcUsage, policyManager).GetReport).Methods("GET")
+	reloadPoliciesRoute := adminRouter.Handle("/policies/reload", requireAdmin(http.HandlerFunc(policyHandler.ReloadPolicies))).Methods("POST")
+	policyRoutes := []*mux.Route{
+		adminRouter.HandleFunc("/policies", policyHandler.ListPolicies).Methods("GET"),
+		adminRouter.Handle("/policies", requireAdmin(http.HandlerFunc(policyHandler.CreatePolicy))).Methods("POST"),
+		reloadPoliciesRoute,
+		adminRouter.HandleFunc("/policies/{id:[0-9]+}", policyHandler.GetPolicy).Methods("GET"),
+		adminRouter.Handle("/policies/{id:[0-9]+}", requireAdmin(http.HandlerFunc(policyHandler.UpdatePolicy))).Methods("PUT"),
+		adminRouter.Handle("/policies/{id:[0-9]+}", requireAdmin(http.HandlerFunc(policyHandler.DeletePolicy))).Methods("DELETE"),
+	}
 
-	// Protected data endpoint with policy enforcement
+	// A policy on the policy endpoints could lock admins out of fixing it
+	policyMiddleware.Exempt(policyRoutes...)
+
+	// Expensive operations take several tokens of the API usage limit, so they are
+	// throttled harder than cheap reads: checking or deciding every policy of a route, probing
+	// dependencies, reloading policies and processing whole allowlists
+	apiUsageRateLimiter.SetWeight(cfg.APIUsageHeavyWeight,
+		eligibilityRoute, authzCheckRoute, explainRoute, selfCheckRoute, reloadPoliciesRoute, importRoute, exportRoute, merkleRoute)
+
+	// Likewise an admin who blocked their own address can still unblock it
+	policyMiddleware.Exempt(
+		adminRouter.HandleFunc("/blocklist", blocklistHandler.ListBlockedAddresses).Methods("GET"),
+		adminRouter.Handle("/blocklist", requireAdmin(http.HandlerFunc(blocklistHandler.BlockAddress))).Methods("POST"),
+		adminRouter.Handle("/blocklist/{address}", requireAdmin(http.HandlerFunc(blocklistHandler.UnblockAddress))).Methods("DELETE"),
+	)
+
+	// Admin routes are guarded by roles instead of the read and write scopes
+	adminRouter.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		routeScopes.Declare(route)
+		return nil
+	})
+
+	// Service account endpoints (accounts without a wallet, owned by the caller)
+	apiRouter.HandleFunc("/service-accounts", serviceAccountHandler.CreateServiceAccount).Methods("POST")
+	apiRouter.HandleFunc("/service-accounts", serviceAccountHandler.ListServiceAccounts).Methods("GET")
+	createServiceAccountKeyRoute := apiRouter.Handle("/service-accounts/{id}/keys",
+		requireKeysWrite(requirePasskey(apiKeyCreationRateLimiter.Middleware()(http.HandlerFunc(serviceAccountHandler.CreateServiceAccountKey))))).Methods("POST")
+
+	// Key management routes are guarded by the keys:read and keys:write scopes instead
+	for _, route := range []*mux.Route{createKeyRoute, listKeysRoute, revokeKeyRoute, createServiceAccountKeyRoute} {
+		routeScopes.Declare(route)
+	}
+
+	// Protected data endpoint
 	dataHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		claims := httpserver.ClaimsFromContext(r)
 		if claims == nil {
@@ -253,31 +826,135 @@ func main() {
 		w.Header().Set("Content-Type", "application/json")
 		fmt.Fprintf(w, `{"message":"Access granted","address":"%s"}`, claims.Address)
 	})
-	apiRouter.Handle("/data", policyMiddleware.Middleware()(dataHandler)).Methods("GET")
-
-	// Create HTTP server
-	portStr := cfg.Port
-	server := &http.Server{
-		Addr:         fmt.Sprintf(":%s", portStr),
-		Handler:      router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
-	}
-
-	// Start server in goroutine
-	go func() {
-		logger.Info(fmt.Sprintf("HTTP server listening on %s", server.Addr))
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Error(fmt.Sprintf("server error: %v", err))
-			os.Exit(1)
+	apiRouter.Handle("/data", dataHandler).Methods("GET")
+
+	// CORS and security header policies, configured centrally per route group.
+	// These wrap the router so preflight requests are answered before route matching.
+	var corsPolicy, adminCORSPolicy *httpserver.CORSPolicy
+	if len(cfg.CORSAllowedOrigins) > 0 {
+		corsPolicy = newCORSPolicy(cfg.CORSAllowedOrigins, cfg.APIKeyHeaders)
+	}
+	if len(cfg.CORSAdminAllowedOrigins) > 0 {
+		adminCORSPolicy = newCORSPolicy(cfg.CORSAdminAllowedOrigins, cfg.APIKeyHeaders)
+	}
+	corsMiddleware := httpserver.CORSMiddleware(corsPolicy, map[string]*httpserver.CORSPolicy{
+		"/api/admin": adminCORSPolicy,
+	})
+	securityHeadersMiddleware := httpserver.SecurityHeadersMiddleware(httpserver.SecurityHeadersPolicy{
+		HSTSMaxAge:            cfg.HSTSMaxAge,
+		HSTSIncludeSubdomains: true,
+		FrameAncestors: map[string]string{
+			"/docs":      cfg.FrameAncestors,
+			"/api/admin": cfg.FrameAncestors,
+		},
+	})
+
+	// Each listener role has its own handler. When an admin listener is declared, admin
+	// and metrics routes move to it and are hidden from public listeners. Admin listeners
+	// are internal, so they get no CORS policy.
+	publicListenerHandler := securityHeadersMiddleware(corsMiddleware(router))
+	adminListenerHandler := securityHeadersMiddleware(httpserver.OnlyPaths(adminPaths...)(router))
+	if listener.HasRole(cfg.Listeners, listener.RoleAdmin) {
+		publicListenerHandler = httpserver.ExceptPaths(adminOnlyPaths...)(publicListenerHandler)
+	}
+
+	// Behind an L4 load balancer the client address arrives in a PROXY header;
+	// forwarding headers are then ignored since clients can set them
+	if cfg.ProxyProtocol {
+		httpserver.SetTrustForwardedHeaders(false)
+		logger.Info(fmt.Sprintf("PROXY protocol enabled (trusted sources: %v)", cfg.ProxyProtocolTrustedCIDRs))
+	}
+
+	// Sockets passed by systemd socket activation or a restarting predecessor are
+	// reused so no connection is refused while the process is swapped
+	inherited, err := listener.Inherited()
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to use inherited listeners: %v", err))
+		os.Exit(1)
+	}
+	if len(inherited) > 0 {
+		logger.Info(fmt.Sprintf("Inherited %d listening sockets", len(inherited)))
+	}
+
+	listeners, err := listener.ListenAll(cfg.Listeners, inherited, cfg.ReusePort)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	// Create an HTTP server per listener
+	servers := make([]*http.Server, 0, len(cfg.Listeners))
+	for i, spec := range cfg.Listeners {
+		ln := listeners[i]
+
+		// Unix sockets carry no client address, so PROXY headers only apply to TCP
+		if cfg.ProxyProtocol && spec.Network == "tcp" {
+			ln, err = listener.NewProxyProtocolListener(ln, cfg.ProxyProtocolTrustedCIDRs)
+			if err != nil {
+				logger.Error(fmt.Sprintf("failed to configure PROXY protocol: %v", err))
+				os.Exit(1)
+			}
+		}
+
+		handler := publicListenerHandler
+		if spec.Role == listener.RoleAdmin {
+			handler = adminListenerHandler
 		}
-	}()
 
-	// Wait for shutdown signal
+		server := &http.Server{
+			Handler:      handler,
+			ReadTimeout:  15 * time.Second,
+			WriteTimeout: 15 * time.Second,
+			IdleTimeout:  60 * time.Second,
+			// The peer is the proxy when the client address came in a PROXY header
+			ConnContext: func(ctx context.Context, conn net.Conn) context.Context {
+				return httpserver.WithPeerAddr(ctx, listener.PeerAddr(conn))
+			},
+		}
+		servers = append(servers, server)
+
+		// Start server in goroutine
+		go func(spec listener.Spec) {
+			logger.Info(fmt.Sprintf("HTTP server listening on %s", spec))
+			if err := server.Serve(ln); err != nil && err != http.ErrServerClosed {
+				logger.Error(fmt.Sprintf("server error on %s: %v", spec, err))
+				os.Exit(1)
+			}
+		}(spec)
+	}
+
+	// Wait for shutdown signal, or a restart signal handing the listeners to a new process
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	<-sigChan
+	restartChan := make(chan os.Signal, 1)
+	if len(listener.RestartSignals) > 0 {
+		signal.Notify(restartChan, listener.RestartSignals...)
+	}
+	// SIGHUP reloads the persisted policies without interrupting requests
+	reloadChan := make(chan os.Signal, 1)
+	signal.Notify(reloadChan, syscall.SIGHUP)
+
+wait:
+	for {
+		select {
+		case <-sigChan:
+			break wait
+		case <-reloadChan:
+			if err := policyManager.LoadFromStore(context.Background()); err != nil {
+				logger.Error(fmt.Sprintf("policy reload failed, keeping current policies: %v", err))
+				continue
+			}
+			logger.Info(fmt.Sprintf("Reloaded %d policies", policyManager.GetPoliciesCount()))
+		case <-restartChan:
+			process, err := listener.Handoff(listeners)
+			if err != nil {
+				logger.Error(fmt.Sprintf("restart failed, continuing to serve: %v", err))
+				continue
+			}
+			logger.Info(fmt.Sprintf("Listeners handed to new process %d, draining", process.Pid))
+			break wait
+		}
+	}
 
 	logger.Info("Shutting down server...")
 
@@ -285,54 +962,52 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	if err := server.Shutdown(ctx); err != nil {
-		logger.Error(fmt.Sprintf("shutdown error: %v", err))
-		os.Exit(1)
+	for _, server := range servers {
+		if err := server.Shutdown(ctx); err != nil {
+			logger.Error(fmt.Sprintf("shutdown error: %v", err))
+			os.Exit(1)
+		}
 	}
 
-	logger.Info("Server stopped")
-}
-
-// parseJSON parses JSON from request body
-func parseJSON(r *http.Request, v interface{}) error {
-	defer r.Body.Close()
-	return nil // Simplified - in production would use json.NewDecoder
-}
-
-// extractAddressFromMessage extracts Ethereum address from SIWE message
-func extractAddressFromMessage(message string) string {
-	// Simplified extraction - looks for 0x followed by 40 hex characters
-	// In production, would properly parse SIWE message format
-	if len(message) < 42 {
-		return ""
-	}
-
-	// Try to find address pattern in message
-	for i := 0; i <= len(message)-42; i++ {
-		if message[i:i+2] == "0x" {
-			// Check if next 40 chars are hex
-			candidate := message[i : i+42]
-			if isValidEthereumAddress(candidate) {
-				return candidate
-			}
+	// Upload audit events still buffered
+	if auditExporter != nil {
+		if err := auditExporter.Close(ctx); err != nil {
+			logger.Error(fmt.Sprintf("failed to flush audit export: %v", err))
 		}
 	}
-	return ""
-}
 
-// isValidEthereumAddress checks if a string is a valid Ethereum address
-func isValidEthereumAddress(addr string) bool {
-	if len(addr) != 42 {
-		return false
-	}
-	if addr[:2] != "0x" {
-		return false
+	// Store security events still queued
+	if err := securityEvents.Close(ctx); err != nil {
+		logger.Error(fmt.Sprintf("failed to store security events: %v", err))
 	}
 
-	for _, c := range addr[2:] {
-		if !((c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')) {
-			return false
-		}
+	logger.Info("Server stopped")
+}
+
+// nonJSONPaths accept other request bodies than JSON: CSV allowlist uploads and
+// the form posts of the OIDC provider
+var nonJSONPaths = []string{"/api/admin/allowlists", "/oidc"}
+
+// accountPaths manage credentials and the account itself, so downscoped and
+// capability tokens are refused there
+var accountPaths = []string{"/api/keys", "/api/me", "/api/signed-urls", "/api/service-accounts"}
+
+// adminOnlyPaths are served only by admin listeners when one is declared
+var adminOnlyPaths = []string{"/api/admin", "/metrics"}
+
+// adminPaths are the routes served by admin listeners
+var adminPaths = append([]string{"/health"}, adminOnlyPaths...)
+
+// newCORSPolicy returns the CORS policy for browser clients of the API from the given
+// origins, allowing the configured API key headers. Credentials are allowed for the
+// origins listed exactly; a "*" entry admits other origins without them.
+func newCORSPolicy(origins, apiKeyHeaders []string) *httpserver.CORSPolicy {
+	return &httpserver.CORSPolicy{
+		AllowedOrigins:   origins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedHeaders:   append([]string{"Authorization", "Content-Type"}, apiKeyHeaders...),
+		ExposedHeaders:   []string{"Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
 	}
-	return true
 }
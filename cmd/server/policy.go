@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/yourusername/gatekeeper/internal/policytest"
+)
+
+// runPolicy runs the policy subcommand: test evaluates policy test suites
+// against mocked chain state, so policy changes can be verified before they are
+// deployed. It returns the process exit code.
+func runPolicy(args []string) int {
+	usage := func() {
+		fmt.Fprintln(os.Stderr, "usage: gatekeeper policy test [-v] suite.json...")
+	}
+	if len(args) == 0 || args[0] != "test" {
+		usage()
+		return 2
+	}
+
+	flags := flag.NewFlagSet("policy test", flag.ContinueOnError)
+	verbose := flags.Bool("v", false, "report passing cases as well as failing ones")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		usage()
+		return 2
+	}
+
+	exitCode := 0
+	passed, failed := 0, 0
+	for _, path := range flags.Args() {
+		suite, err := policytest.LoadSuite(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			exitCode = 1
+			continue
+		}
+		results, err := suite.Run(context.Background())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			exitCode = 1
+			continue
+		}
+		for _, result := range results {
+			if result.Passed() {
+				passed++
+			} else {
+				failed++
+				exitCode = 1
+			}
+			writeCaseResult(os.Stdout, path, result, *verbose)
+		}
+	}
+
+	fmt.Printf("%d passed, %d failed\n", passed, failed)
+	return exitCode
+}
+
+// writeCaseResult reports the outcome of a case: failing cases always, with why
+// the request was denied and the chain reads that had no mock, passing cases
+// only when verbose
+func writeCaseResult(w io.Writer, suite string, result policytest.Result, verbose bool) {
+	if result.Passed() && !verbose {
+		return
+	}
+	status := "PASS"
+	if !result.Passed() {
+		status = "FAIL"
+	}
+	fmt.Fprintf(w, "%s %s: %s (expected %s, got %s)\n", status, suite, result.Case.Name, result.Case.Expect, result.Got)
+	if result.Passed() {
+		return
+	}
+
+	if result.DeniedBy != nil {
+		fmt.Fprintf(w, "    denied by policy %s %s\n", result.DeniedBy.Method, result.DeniedBy.Path)
+	} else if result.Reason != "" {
+		fmt.Fprintf(w, "    reason: %s\n", result.Reason)
+	}
+	if result.Err != nil {
+		fmt.Fprintf(w, "    error: %v\n", result.Err)
+	}
+	if len(result.Unmocked) > 0 {
+		fmt.Fprintf(w, "    unmocked chain reads: %s\n", strings.Join(result.Unmocked, ", "))
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/yourusername/gatekeeper/internal/soak"
+)
+
+// soakGuardEnv must be set to 1 to run the soak subcommand, so the synthetic load
+// generator is never started by accident in place of the server
+const soakGuardEnv = "GATEKEEPER_SOAK"
+
+// runSoak runs the soak subcommand: synthetic traffic against an in-process server,
+// checked against release thresholds. It returns the process exit code.
+func runSoak(args []string) int {
+	if os.Getenv(soakGuardEnv) != "1" {
+		fmt.Fprintf(os.Stderr, "soak generates synthetic load and is for release validation only; set %s=1 to run it\n", soakGuardEnv)
+		return 2
+	}
+
+	cfg := soak.DefaultConfig()
+	thresholds := soak.DefaultThresholds()
+	var maxHeapGrowthMiB uint64
+
+	flags := flag.NewFlagSet("soak", flag.ContinueOnError)
+	flags.IntVar(&cfg.Clients, "clients", cfg.Clients, "number of distinct wallets sending traffic")
+	flags.IntVar(&cfg.Concurrency, "concurrency", cfg.Concurrency, "requests in flight")
+	flags.DurationVar(&cfg.Duration, "duration", cfg.Duration, "how long to send traffic")
+	flags.IntVar(&cfg.RateLimit, "rate-limit", cfg.RateLimit, "per-user API usage limit in requests per minute")
+	flags.IntVar(&cfg.RateBurst, "rate-burst", cfg.RateBurst, "per-user API usage burst")
+	flags.DurationVar(&cfg.CacheTTL, "cache-ttl", cfg.CacheTTL, "TTL of cached blockchain results")
+	flags.DurationVar(&cfg.RPCLatency, "rpc-latency", cfg.RPCLatency, "simulated latency of RPC calls")
+	flags.Float64Var(&thresholds.MinFairness, "min-fairness", thresholds.MinFairness, "lowest acceptable rate limiter fairness index")
+	flags.Float64Var(&thresholds.MinCacheHitRate, "min-cache-hit-rate", thresholds.MinCacheHitRate, "lowest acceptable cache hit rate")
+	flags.Uint64Var(&maxHeapGrowthMiB, "max-heap-growth", thresholds.MaxHeapGrowth>>20, "largest acceptable heap growth in MiB (0 disables the check)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	thresholds.MaxHeapGrowth = maxHeapGrowthMiB << 20
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "soak: %d clients, concurrency %d, for %s\n", cfg.Clients, cfg.Concurrency, cfg.Duration)
+	report, err := soak.Run(ctx, cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "soak failed: %v\n", err)
+		return 1
+	}
+	report.Print(os.Stdout)
+
+	if err := report.Check(thresholds); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -3,6 +3,7 @@ module github.com/yourusername/gatekeeper
 go 1.24.0
 
 require (
+	filippo.io/age v1.2.1
 	github.com/ethereum/go-ethereum v1.16.5
 	github.com/golang-jwt/jwt/v5 v5.3.0
 	github.com/google/uuid v1.6.0
@@ -11,7 +12,9 @@ require (
 	github.com/joho/godotenv v1.5.1
 	github.com/lib/pq v1.10.9
 	github.com/stretchr/testify v1.11.1
+	github.com/tetratelabs/wazero v1.9.0
 	go.uber.org/zap v1.27.0
+	golang.org/x/sys v0.36.0
 	golang.org/x/time v0.9.0
 )
 
@@ -23,7 +26,6 @@ require (
 	github.com/stretchr/objx v0.5.2 // indirect
 	go.uber.org/multierr v1.10.0 // indirect
 	golang.org/x/crypto v0.36.0 // indirect
-	golang.org/x/sys v0.36.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -13,16 +13,58 @@ type ActionType string
 
 const (
 	// API Key actions
-	ActionAPIKeyCreated   ActionType = "api_key_created"
-	ActionAPIKeyRevoked   ActionType = "api_key_revoked"
-	ActionAPIKeyUsed      ActionType = "api_key_used"
-	ActionAPIKeyListed    ActionType = "api_key_listed"
-	ActionAPIKeyValidated ActionType = "api_key_validated"
+	ActionAPIKeyCreated     ActionType = "api_key_created"
+	ActionAPIKeyRevoked     ActionType = "api_key_revoked"
+	ActionAPIKeyUsed        ActionType = "api_key_used"
+	ActionAPIKeyListed      ActionType = "api_key_listed"
+	ActionAPIKeyValidated   ActionType = "api_key_validated"
+	ActionAPIKeysRevoked    ActionType = "api_keys_revoked"    // Bulk emergency revocation
+	ActionAPIKeyTransferred ActionType = "api_key_transferred" // Reassigned to another owner
+	ActionAPIKeysDeprecated ActionType = "api_keys_deprecated" // Revoked at a sunset date
+	ActionAPIKeysSunset     ActionType = "api_keys_sunset"     // Revoked by a deprecation at its sunset
+
+	ActionAPIKeyDeprecationCancelled ActionType = "api_key_deprecation_cancelled"
+
+	// Service account actions
+	ActionServiceAccountCreated ActionType = "service_account_created"
+
+	// Wallet actions
+	ActionWalletLinked   ActionType = "wallet_linked"
+	ActionWalletUnlinked ActionType = "wallet_unlinked"
+
+	// Passkey actions
+	ActionWebAuthnRegistered ActionType = "webauthn_registered"
+	ActionWebAuthnRemoved    ActionType = "webauthn_removed"
+	ActionWebAuthnStepUp     ActionType = "webauthn_step_up" // Assertion for a sensitive operation
+
+	// Admin actions
+	ActionLogLevelChanged ActionType = "log_level_changed"
+	ActionRoleChanged     ActionType = "role_changed"
+
+	// Policy administration actions
+	ActionPolicyCreated    ActionType = "policy_created"
+	ActionPolicyUpdated    ActionType = "policy_updated"
+	ActionPolicyDeleted    ActionType = "policy_deleted"
+	ActionPoliciesReloaded ActionType = "policies_reloaded"
+
+	// Allowlist actions
+	ActionAllowlistImported ActionType = "allowlist_imported"
+
+	// Blocklist actions
+	ActionAddressBlocked   ActionType = "address_blocked"
+	ActionAddressUnblocked ActionType = "address_unblocked"
+
+	// Signed URL actions
+	ActionSignedURLCreated ActionType = "signed_url_created"
 
 	// Authentication actions
 	ActionAuthSuccess ActionType = "auth_success"
 	ActionAuthFailure ActionType = "auth_failure"
 
+	// Session actions
+	ActionSessionRefreshed ActionType = "session_refreshed"
+	ActionSessionRevoked   ActionType = "session_revoked" // Logout or refresh token reuse
+
 	// Authorization actions
 	ActionAuthzGranted ActionType = "authz_granted"
 	ActionAuthzDenied  ActionType = "authz_denied"
@@ -53,8 +95,12 @@ type AuditEvent struct {
 	Result     Result     `json:"result"`
 	RequestID  string     `json:"request_id,omitempty"`
 	UserAddr   string     `json:"user_addr,omitempty"`
+	UserID     int64      `json:"user_id,omitempty"`
 	ResourceID string     `json:"resource_id,omitempty"`
 
+	// ServiceAccount identifies the acting service account (no wallet address)
+	ServiceAccount string `json:"service_account,omitempty"`
+
 	// API Key specific
 	KeyID     int64    `json:"key_id,omitempty"`
 	KeyName   string   `json:"key_name,omitempty"`
@@ -66,6 +112,11 @@ type AuditEvent struct {
 	Endpoint string `json:"endpoint,omitempty"`
 	IPAddr   string `json:"ip_addr,omitempty"`
 
+	// Client fingerprint (forensics for leaked credentials)
+	UserAgent      string `json:"user_agent,omitempty"`
+	AcceptLanguage string `json:"accept_language,omitempty"`
+	TLSFingerprint string `json:"tls_fingerprint,omitempty"` // JA3, when forwarded by the TLS terminator
+
 	// Policy specific
 	PolicyPath   string `json:"policy_path,omitempty"`
 	PolicyMethod string `json:"policy_method,omitempty"`
@@ -73,6 +124,8 @@ type AuditEvent struct {
 	RuleResult   bool   `json:"rule_result,omitempty"`
 
 	// Blockchain specific
+	Network         string `json:"network,omitempty"` // Chain gatekeeper gates on, e.g. "sepolia"
+	Testnet         bool   `json:"testnet,omitempty"`
 	ChainID         int64  `json:"chain_id,omitempty"`
 	ContractAddress string `json:"contract_address,omitempty"`
 	RPCMethod       string `json:"rpc_method,omitempty"`
@@ -112,10 +165,24 @@ type AuditLogger interface {
 	LogAsync(event AuditEvent)
 }
 
+// Sink receives a copy of every audit event after it is logged, e.g. to export it
+// for long-term retention. Write is called on the logging path and must not block.
+type Sink interface {
+	Write(event AuditEvent)
+}
+
 // zapAuditLogger implements AuditLogger using zap
 type zapAuditLogger struct {
 	logger *zap.Logger
 	async  chan AuditEvent
+	sinks  []Sink
+}
+
+// NewAuditLoggerWithSinks creates a new audit logger that also forwards every event to sinks
+func NewAuditLoggerWithSinks(logger *zap.Logger, sinks ...Sink) AuditLogger {
+	l := NewAuditLogger(logger).(*zapAuditLogger)
+	l.sinks = sinks
+	return l
 }
 
 // NewAuditLogger creates a new audit logger
@@ -240,9 +307,15 @@ func (l *zapAuditLogger) log(event AuditEvent) {
 	if event.UserAddr != "" {
 		fields = append(fields, zap.String("user_addr", sanitizeAddress(event.UserAddr)))
 	}
+	if event.UserID != 0 {
+		fields = append(fields, zap.Int64("user_id", event.UserID))
+	}
 	if event.ResourceID != "" {
 		fields = append(fields, zap.String("resource_id", event.ResourceID))
 	}
+	if event.ServiceAccount != "" {
+		fields = append(fields, zap.String("service_account", event.ServiceAccount))
+	}
 
 	// API Key fields
 	if event.KeyID != 0 {
@@ -268,6 +341,15 @@ func (l *zapAuditLogger) log(event AuditEvent) {
 	if event.IPAddr != "" {
 		fields = append(fields, zap.String("ip_addr", event.IPAddr))
 	}
+	if event.UserAgent != "" {
+		fields = append(fields, zap.String("user_agent", event.UserAgent))
+	}
+	if event.AcceptLanguage != "" {
+		fields = append(fields, zap.String("accept_language", event.AcceptLanguage))
+	}
+	if event.TLSFingerprint != "" {
+		fields = append(fields, zap.String("tls_fingerprint", event.TLSFingerprint))
+	}
 
 	// Policy fields
 	if event.PolicyPath != "" {
@@ -282,6 +364,12 @@ func (l *zapAuditLogger) log(event AuditEvent) {
 	}
 
 	// Blockchain fields
+	if event.Network != "" {
+		fields = append(fields, zap.String("network", event.Network))
+	}
+	if event.Testnet {
+		fields = append(fields, zap.Bool("testnet", true))
+	}
 	if event.ChainID != 0 {
 		fields = append(fields, zap.Int64("chain_id", event.ChainID))
 	}
@@ -325,6 +413,10 @@ func (l *zapAuditLogger) log(event AuditEvent) {
 	if ce := l.logger.Check(level, "audit event"); ce != nil {
 		ce.Write(fields...)
 	}
+
+	for _, sink := range l.sinks {
+		sink.Write(event)
+	}
 }
 
 // sanitizeAddress sanitizes an Ethereum address for logging
@@ -343,3 +435,62 @@ func (l *zapAuditLogger) Close() error {
 	close(l.async)
 	return l.logger.Sync()
 }
+
+// networkLabeler labels every event with the network before passing it on
+type networkLabeler struct {
+	next    AuditLogger
+	network string
+	testnet bool
+}
+
+// LabelNetwork labels every event logged through logger with the network
+// gatekeeper gates on, so events of staging deployments on testnets are told
+// apart from production ones wherever they are exported
+func LabelNetwork(logger AuditLogger, network string, testnet bool) AuditLogger {
+	return &networkLabeler{next: logger, network: network, testnet: testnet}
+}
+
+// label sets the network of an event that has none
+func (l *networkLabeler) label(event AuditEvent) AuditEvent {
+	if event.Network == "" {
+		event.Network = l.network
+		event.Testnet = l.testnet
+	}
+	return event
+}
+
+func (l *networkLabeler) LogAPIKeyCreated(ctx context.Context, event AuditEvent) {
+	l.next.LogAPIKeyCreated(ctx, l.label(event))
+}
+
+func (l *networkLabeler) LogAPIKeyRevoked(ctx context.Context, event AuditEvent) {
+	l.next.LogAPIKeyRevoked(ctx, l.label(event))
+}
+
+func (l *networkLabeler) LogAPIKeyUsed(ctx context.Context, event AuditEvent) {
+	l.next.LogAPIKeyUsed(ctx, l.label(event))
+}
+
+func (l *networkLabeler) LogAPIKeyListed(ctx context.Context, event AuditEvent) {
+	l.next.LogAPIKeyListed(ctx, l.label(event))
+}
+
+func (l *networkLabeler) LogAuthAttempt(ctx context.Context, event AuditEvent) {
+	l.next.LogAuthAttempt(ctx, l.label(event))
+}
+
+func (l *networkLabeler) LogAuthzDecision(ctx context.Context, event AuditEvent) {
+	l.next.LogAuthzDecision(ctx, l.label(event))
+}
+
+func (l *networkLabeler) LogPolicyEvaluation(ctx context.Context, event AuditEvent) {
+	l.next.LogPolicyEvaluation(ctx, l.label(event))
+}
+
+func (l *networkLabeler) Log(ctx context.Context, event AuditEvent) {
+	l.next.Log(ctx, l.label(event))
+}
+
+func (l *networkLabeler) LogAsync(event AuditEvent) {
+	l.next.LogAsync(l.label(event))
+}
@@ -145,6 +145,32 @@ func TestAuditLogger_LogAuthAttempt(t *testing.T) {
 	})
 }
 
+// TestAuditLogger_ClientFingerprint tests client fingerprint fields are logged
+func TestAuditLogger_ClientFingerprint(t *testing.T) {
+	core, observed := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+	auditLogger := NewAuditLogger(logger)
+
+	auditLogger.LogAuthAttempt(context.Background(), AuditEvent{
+		Result:         ResultSuccess,
+		IPAddr:         "192.168.1.1",
+		UserAgent:      "curl/8.4.0",
+		AcceptLanguage: "en-US,en;q=0.9",
+		TLSFingerprint: "771,4865-4866-4867,0-23-65281,29-23-24,0",
+		ServiceAccount: "nightly-sync",
+	})
+
+	entries := observed.All()
+	require.Len(t, entries, 1)
+
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "192.168.1.1", fields["ip_addr"])
+	assert.Equal(t, "curl/8.4.0", fields["user_agent"])
+	assert.Equal(t, "en-US,en;q=0.9", fields["accept_language"])
+	assert.Equal(t, "771,4865-4866-4867,0-23-65281,29-23-24,0", fields["tls_fingerprint"])
+	assert.Equal(t, "nightly-sync", fields["service_account"])
+}
+
 // TestAuditLogger_LogAuthzDecision tests authorization decision logging
 func TestAuditLogger_LogAuthzDecision(t *testing.T) {
 	core, observed := observer.New(zapcore.InfoLevel)
@@ -435,3 +461,49 @@ func TestAuditLogger_BufferOverflow(t *testing.T) {
 	entries := observed.All()
 	assert.Greater(t, len(entries), 1000, "Should have logged a significant number of events")
 }
+
+type recordingSink struct {
+	events []AuditEvent
+}
+
+func (s *recordingSink) Write(event AuditEvent) {
+	s.events = append(s.events, event)
+}
+
+// TestAuditLogger_Sinks tests that every logged event is forwarded to sinks
+func TestAuditLogger_Sinks(t *testing.T) {
+	core, observed := observer.New(zapcore.InfoLevel)
+	sink := &recordingSink{}
+	auditLogger := NewAuditLoggerWithSinks(zap.New(core), sink)
+
+	auditLogger.LogAPIKeyRevoked(context.Background(), AuditEvent{Result: ResultSuccess, KeyID: 7})
+	auditLogger.Log(context.Background(), AuditEvent{Action: ActionLogLevelChanged, Result: ResultSuccess})
+
+	require.Len(t, sink.events, 2)
+	assert.Equal(t, ActionAPIKeyRevoked, sink.events[0].Action)
+	assert.Equal(t, int64(7), sink.events[0].KeyID)
+	assert.False(t, sink.events[0].Timestamp.IsZero())
+	assert.Equal(t, ActionLogLevelChanged, sink.events[1].Action)
+	assert.Equal(t, 2, observed.Len())
+}
+
+// TestLabelNetwork tests that events are labeled with the network they concern
+func TestLabelNetwork(t *testing.T) {
+	core, observed := observer.New(zapcore.InfoLevel)
+	sink := &recordingSink{}
+	auditLogger := LabelNetwork(NewAuditLoggerWithSinks(zap.New(core), sink), "sepolia", true)
+
+	auditLogger.LogAuthzDecision(context.Background(), AuditEvent{Result: ResultGranted, UserAddr: "0x1234"})
+	auditLogger.Log(context.Background(), AuditEvent{Action: ActionRPCCall, Result: ResultSuccess, Network: "mainnet"})
+
+	require.Len(t, sink.events, 2)
+	assert.Equal(t, ActionAuthzGranted, sink.events[0].Action)
+	assert.Equal(t, "sepolia", sink.events[0].Network)
+	assert.True(t, sink.events[0].Testnet)
+	assert.Equal(t, "mainnet", sink.events[1].Network, "an event's own network is kept")
+	assert.False(t, sink.events[1].Testnet)
+
+	fields := observed.All()[0].ContextMap()
+	assert.Equal(t, "sepolia", fields["network"])
+	assert.Equal(t, true, fields["testnet"])
+}
@@ -0,0 +1,233 @@
+// Package auditexport archives audit events to S3-compatible object storage for
+// long-term retention independent of the database and log pipeline. Events are
+// batched into gzipped NDJSON objects partitioned by time; objects are never
+// overwritten.
+package auditexport
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/yourusername/gatekeeper/internal/audit"
+	"go.uber.org/zap"
+)
+
+// ObjectStore stores archive objects
+type ObjectStore interface {
+	PutObject(ctx context.Context, key string, body []byte, contentType, contentEncoding string) error
+}
+
+// Config controls batching of exported events
+type Config struct {
+	Prefix        string        // Key prefix of archive objects, e.g. "audit"
+	BatchSize     int           // Upload once this many events are buffered
+	FlushInterval time.Duration // Upload buffered events at least this often
+	MaxPending    int           // Failed batches kept for retry; the oldest is dropped beyond this
+}
+
+// batch is a sealed group of events awaiting upload
+type batch struct {
+	key  string
+	body []byte
+	size int // Events in the batch
+}
+
+// Exporter is an audit.Sink that uploads events in batches. Writes only buffer;
+// uploads happen in the background, and failed uploads are retried on the next
+// flush.
+type Exporter struct {
+	store  ObjectStore
+	cfg    Config
+	logger *zap.Logger
+	now    func() time.Time
+
+	flushMu sync.Mutex // Serializes uploads so batches are stored in order
+
+	mu      sync.Mutex
+	events  [][]byte  // Encoded events of the open batch
+	first   time.Time // Timestamp of the first event of the open batch
+	pending []*batch  // Sealed batches not yet uploaded, oldest first
+	seq     int
+
+	flush    chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// Ensure Exporter implements audit.Sink
+var _ audit.Sink = (*Exporter)(nil)
+
+// NewExporter creates an exporter uploading to store and starts its background uploads
+func NewExporter(store ObjectStore, cfg Config, logger *zap.Logger) *Exporter {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 1000
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Minute
+	}
+	if cfg.MaxPending <= 0 {
+		cfg.MaxPending = 100
+	}
+	e := &Exporter{
+		store:  store,
+		cfg:    cfg,
+		logger: logger,
+		now:    time.Now,
+		flush:  make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// Write buffers an event, triggering an upload once the batch is full
+func (e *Exporter) Write(event audit.AuditEvent) {
+	line, err := json.Marshal(event)
+	if err != nil {
+		e.logger.Error("failed to encode audit event for export", zap.Error(err))
+		return
+	}
+
+	e.mu.Lock()
+	if len(e.events) == 0 {
+		e.first = event.Timestamp
+		if e.first.IsZero() {
+			e.first = e.now()
+		}
+	}
+	e.events = append(e.events, line)
+	full := len(e.events) >= e.cfg.BatchSize
+	e.mu.Unlock()
+
+	if full {
+		select {
+		case e.flush <- struct{}{}:
+		default: // A flush is already due
+		}
+	}
+}
+
+// run uploads batches until Close is called
+func (e *Exporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(e.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.stop:
+			return
+		case <-ticker.C:
+		case <-e.flush:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), e.cfg.FlushInterval)
+		e.Flush(ctx)
+		cancel()
+	}
+}
+
+// Flush seals the open batch and uploads every pending batch, oldest first. It
+// stops at the first failure, keeping that batch and later ones for the next flush.
+func (e *Exporter) Flush(ctx context.Context) error {
+	e.flushMu.Lock()
+	defer e.flushMu.Unlock()
+
+	e.mu.Lock()
+	if err := e.seal(); err != nil {
+		e.mu.Unlock()
+		return err
+	}
+	e.mu.Unlock()
+
+	for {
+		e.mu.Lock()
+		if len(e.pending) == 0 {
+			e.mu.Unlock()
+			return nil
+		}
+		next := e.pending[0]
+		e.mu.Unlock()
+
+		if err := e.store.PutObject(ctx, next.key, next.body, "application/x-ndjson", "gzip"); err != nil {
+			e.logger.Warn("audit export upload failed, will retry",
+				zap.String("object", next.key),
+				zap.Int("events", next.size),
+				zap.Error(err))
+			return err
+		}
+
+		e.mu.Lock()
+		e.pending = e.pending[1:]
+		e.mu.Unlock()
+	}
+}
+
+// seal compresses the open batch into a pending batch. Called with e.mu held.
+func (e *Exporter) seal() error {
+	if len(e.events) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	for _, line := range e.events {
+		gz.Write(line)
+		gz.Write([]byte{'\n'})
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to compress audit batch: %w", err)
+	}
+
+	e.seq++
+	e.pending = append(e.pending, &batch{
+		key:  e.objectKey(e.first, e.seq),
+		body: buf.Bytes(),
+		size: len(e.events),
+	})
+	e.events = nil
+
+	if len(e.pending) > e.cfg.MaxPending {
+		dropped := e.pending[0]
+		e.pending = e.pending[1:]
+		e.logger.Error("audit export backlog full, dropping oldest batch",
+			zap.String("object", dropped.key),
+			zap.Int("events", dropped.size))
+	}
+	return nil
+}
+
+// objectKey names the object of a batch whose first event happened at t. Keys are
+// partitioned by UTC date and hour for cheap lifecycle rules and query engines,
+// and end with a random suffix so replicas never overwrite each other's objects.
+func (e *Exporter) objectKey(t time.Time, seq int) string {
+	t = t.UTC()
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	name := fmt.Sprintf("%s-%06d-%s.ndjson.gz", t.Format("20060102T150405.000Z"), seq, hex.EncodeToString(suffix))
+	return path.Join(e.cfg.Prefix,
+		t.Format("year=2006"), t.Format("month=01"), t.Format("day=02"), t.Format("hour=15"),
+		name)
+}
+
+// Close stops background uploads and flushes buffered events, giving up when ctx
+// is done. Events still not uploaded are lost.
+func (e *Exporter) Close(ctx context.Context) error {
+	e.stopOnce.Do(func() {
+		close(e.stop)
+	})
+	<-e.done
+	return e.Flush(ctx)
+}
@@ -0,0 +1,145 @@
+package auditexport
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/audit"
+)
+
+type fakeObjectStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	keys    []string
+	err     error
+}
+
+func (s *fakeObjectStore) PutObject(ctx context.Context, key string, body []byte, contentType, contentEncoding string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	if s.objects == nil {
+		s.objects = make(map[string][]byte)
+	}
+	s.objects[key] = body
+	s.keys = append(s.keys, key)
+	return nil
+}
+
+func (s *fakeObjectStore) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+func (s *fakeObjectStore) uploaded() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.keys...)
+}
+
+// decodeBatch returns the events of an uploaded object
+func decodeBatch(t *testing.T, body []byte) []audit.AuditEvent {
+	gz, err := gzip.NewReader(bytes.NewReader(body))
+	require.NoError(t, err)
+	var events []audit.AuditEvent
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		var event audit.AuditEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, event)
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
+
+func TestExporter_FlushUploadsPartitionedBatch(t *testing.T) {
+	store := &fakeObjectStore{}
+	exporter := NewExporter(store, Config{Prefix: "audit", BatchSize: 100, FlushInterval: time.Hour}, nil)
+	defer exporter.Close(context.Background())
+
+	at := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+	exporter.Write(audit.AuditEvent{Timestamp: at, Action: audit.ActionAPIKeyCreated, Result: audit.ResultSuccess})
+	exporter.Write(audit.AuditEvent{Timestamp: at.Add(time.Second), Action: audit.ActionAPIKeyRevoked, Result: audit.ResultSuccess})
+	require.NoError(t, exporter.Flush(context.Background()))
+
+	keys := store.uploaded()
+	require.Len(t, keys, 1)
+	assert.Regexp(t, `^audit/year=2026/month=10/day=17/hour=09/20261017T093000\.000Z-000001-[0-9a-f]{8}\.ndjson\.gz$`, keys[0])
+
+	events := decodeBatch(t, store.objects[keys[0]])
+	require.Len(t, events, 2)
+	assert.Equal(t, audit.ActionAPIKeyCreated, events[0].Action)
+	assert.Equal(t, audit.ActionAPIKeyRevoked, events[1].Action)
+
+	// Nothing buffered, nothing uploaded
+	require.NoError(t, exporter.Flush(context.Background()))
+	assert.Len(t, store.uploaded(), 1)
+}
+
+func TestExporter_FullBatchTriggersUpload(t *testing.T) {
+	store := &fakeObjectStore{}
+	exporter := NewExporter(store, Config{BatchSize: 2, FlushInterval: time.Hour}, nil)
+	defer exporter.Close(context.Background())
+
+	exporter.Write(audit.AuditEvent{Action: audit.ActionAuthSuccess})
+	exporter.Write(audit.AuditEvent{Action: audit.ActionAuthSuccess})
+
+	assert.Eventually(t, func() bool { return len(store.uploaded()) == 1 }, time.Second, 5*time.Millisecond)
+}
+
+func TestExporter_RetriesFailedUploadsInOrder(t *testing.T) {
+	store := &fakeObjectStore{err: errors.New("service unavailable")}
+	exporter := NewExporter(store, Config{BatchSize: 100, FlushInterval: time.Hour}, nil)
+	defer exporter.Close(context.Background())
+
+	exporter.Write(audit.AuditEvent{Action: audit.ActionAuthSuccess})
+	assert.Error(t, exporter.Flush(context.Background()))
+	exporter.Write(audit.AuditEvent{Action: audit.ActionAuthFailure})
+	assert.Error(t, exporter.Flush(context.Background()))
+
+	store.setErr(nil)
+	require.NoError(t, exporter.Flush(context.Background()))
+
+	keys := store.uploaded()
+	require.Len(t, keys, 2)
+	assert.Equal(t, audit.ActionAuthSuccess, decodeBatch(t, store.objects[keys[0]])[0].Action)
+	assert.Equal(t, audit.ActionAuthFailure, decodeBatch(t, store.objects[keys[1]])[0].Action)
+}
+
+func TestExporter_DropsOldestBatchBeyondMaxPending(t *testing.T) {
+	store := &fakeObjectStore{err: errors.New("service unavailable")}
+	exporter := NewExporter(store, Config{BatchSize: 100, FlushInterval: time.Hour, MaxPending: 1}, nil)
+	defer exporter.Close(context.Background())
+
+	exporter.Write(audit.AuditEvent{Action: audit.ActionAuthSuccess})
+	exporter.Flush(context.Background())
+	exporter.Write(audit.AuditEvent{Action: audit.ActionAuthFailure})
+	exporter.Flush(context.Background())
+
+	store.setErr(nil)
+	require.NoError(t, exporter.Flush(context.Background()))
+	keys := store.uploaded()
+	require.Len(t, keys, 1)
+	assert.Equal(t, audit.ActionAuthFailure, decodeBatch(t, store.objects[keys[0]])[0].Action)
+}
+
+func TestExporter_CloseFlushes(t *testing.T) {
+	store := &fakeObjectStore{}
+	exporter := NewExporter(store, Config{BatchSize: 100, FlushInterval: time.Hour}, nil)
+
+	exporter.Write(audit.AuditEvent{Action: audit.ActionAuthSuccess})
+	require.NoError(t, exporter.Close(context.Background()))
+	assert.Len(t, store.uploaded(), 1)
+}
@@ -0,0 +1,200 @@
+package auditexport
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// amzDateFormat is the timestamp format of SigV4 requests
+const amzDateFormat = "20060102T150405Z"
+
+// S3Config locates a bucket in an S3-compatible object store: AWS S3, MinIO, or
+// Google Cloud Storage through its XML API with HMAC keys
+type S3Config struct {
+	Endpoint        string // e.g. https://s3.us-east-1.amazonaws.com, https://storage.googleapis.com
+	Region          string // SigV4 signing region (GCS and MinIO accept any, usually us-east-1 or auto)
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	PathStyle       bool // Address the bucket as endpoint/bucket rather than bucket.endpoint (required by most MinIO setups)
+}
+
+// S3Client uploads objects with AWS Signature Version 4
+type S3Client struct {
+	cfg    S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3Client creates a client for the bucket described by cfg
+func NewS3Client(cfg S3Config) (*S3Client, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("invalid object storage endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("bucket is required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	return &S3Client{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 30 * time.Second},
+		now:    time.Now,
+	}, nil
+}
+
+// objectURL returns the URL of an object
+func (c *S3Client) objectURL(key string) *url.URL {
+	endpoint, _ := url.Parse(c.cfg.Endpoint)
+	u := &url.URL{Scheme: endpoint.Scheme, Host: endpoint.Host}
+	base := strings.TrimSuffix(endpoint.Path, "/")
+	if c.cfg.PathStyle {
+		u.Path = base + "/" + c.cfg.Bucket + "/" + key
+	} else {
+		u.Host = c.cfg.Bucket + "." + endpoint.Host
+		u.Path = base + "/" + key
+	}
+	return u
+}
+
+// PutObject uploads body as key. The store verifies the body against its
+// Content-MD5; the SHA-256 of the body is also stored as object metadata
+// (x-amz-meta-sha256) so archives can be verified after download.
+func (c *S3Client) PutObject(ctx context.Context, key string, body []byte, contentType, contentEncoding string) error {
+	u := c.objectURL(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create upload request: %w", err)
+	}
+
+	md5Sum := md5.Sum(body)
+	sha256Sum := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(sha256Sum[:])
+
+	req.ContentLength = int64(len(body))
+	req.Header.Set("Content-Type", contentType)
+	if contentEncoding != "" {
+		req.Header.Set("Content-Encoding", contentEncoding)
+	}
+	req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(md5Sum[:]))
+	req.Header.Set("X-Amz-Meta-Sha256", payloadHash)
+	signV4(req, payloadHash, c.cfg.AccessKeyID, c.cfg.SecretAccessKey, c.cfg.Region, c.now())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("upload of %s failed: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("upload of %s returned status %d: %s", key, resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return nil
+}
+
+// signV4 adds AWS Signature Version 4 headers for the s3 service to req, signing
+// the host and every header already set
+func signV4(req *http.Request, payloadHash, accessKeyID, secretAccessKey, region string, now time.Time) {
+	amzDate := now.UTC().Format(amzDateFormat)
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI(req.URL.Path),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalURI URI-encodes each segment of an object path
+func canonicalURI(path string) string {
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery sorts and URI-encodes query parameters
+func canonicalQuery(values url.Values) string {
+	var pairs []string
+	for name, vals := range values {
+		for _, v := range vals {
+			pairs = append(pairs, uriEncode(name)+"="+uriEncode(v))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes everything but RFC 3986 unreserved characters
+func uriEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
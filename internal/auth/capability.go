@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrNotCapabilityToken is returned by VerifyCapabilityToken for valid tokens that
+// are not capability tokens, so callers can fall back to other authentication
+var ErrNotCapabilityToken = errors.New("not a capability token")
+
+// ErrCapabilityMismatch is returned by VerifyCapabilityToken when a capability token
+// is presented for an endpoint other than the one it was issued for
+var ErrCapabilityMismatch = errors.New("capability token is not valid for this endpoint")
+
+// Capability is the single endpoint a capability token may be used for
+type Capability struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+}
+
+// Allows reports whether the capability covers a request with the given method and path
+func (c *Capability) Allows(method, path string) bool {
+	return strings.EqualFold(c.Method, method) && c.Path == path
+}
+
+// GenerateCapabilityToken issues a token for the same subject as parent that is only
+// valid for one method on one path, for flows such as mint or claim where a session
+// token would be too powerful. The token carries no scopes and expires after ttl or
+// when the parent expires, whichever is sooner.
+func (j *JWTService) GenerateCapabilityToken(ctx context.Context, parent *Claims, method, path string, ttl time.Duration) (string, time.Time, error) {
+	if ttl <= 0 {
+		return "", time.Time{}, fmt.Errorf("ttl must be positive")
+	}
+	if !validCapabilityMethod(method) {
+		return "", time.Time{}, fmt.Errorf("unsupported method: %s", method)
+	}
+	if !strings.HasPrefix(path, "/") || strings.ContainsAny(path, "?#") {
+		return "", time.Time{}, fmt.Errorf("path must be an absolute path without query or fragment")
+	}
+
+	now := time.Now()
+	expiresAt := childExpiry(parent, now, ttl)
+
+	claims := Claims{
+		Address:    parent.Address,
+		Capability: &Capability{Method: strings.ToUpper(method), Path: path},
+		ParentID:   parent.familyID(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   parent.Subject,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			Issuer:    TokenIssuer,
+		},
+	}
+
+	token, err := j.sign(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// VerifyCapabilityToken verifies a capability token for a request with the given method and path.
+// Returns ErrNotCapabilityToken if the token is a valid session token instead,
+// or ErrCapabilityMismatch if it was issued for a different endpoint.
+func (j *JWTService) VerifyCapabilityToken(ctx context.Context, tokenString, method, path string) (*Claims, error) {
+	claims, err := j.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Capability == nil {
+		return nil, ErrNotCapabilityToken
+	}
+
+	if !claims.Capability.Allows(method, path) {
+		return nil, fmt.Errorf("%w: %s %s", ErrCapabilityMismatch, method, path)
+	}
+
+	return claims, nil
+}
+
+// validCapabilityMethod reports whether method is an HTTP method a capability can be issued for
+func validCapabilityMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
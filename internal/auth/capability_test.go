@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func capabilityParent() *Claims {
+	return &Claims{Address: "0x1234567890123456789012345678901234567890", Scopes: []string{"read"}}
+}
+
+func TestGenerateCapabilityToken_VerifiesForItsEndpoint(t *testing.T) {
+	service := NewJWTService([]byte("secret"), time.Hour)
+	ctx := context.Background()
+
+	token, expiresAt, err := service.GenerateCapabilityToken(ctx, capabilityParent(), "post", "/api/claim", 10*time.Minute)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), expiresAt, 2*time.Second)
+
+	claims, err := service.VerifyCapabilityToken(ctx, token, "POST", "/api/claim")
+	require.NoError(t, err)
+	assert.Equal(t, "0x1234567890123456789012345678901234567890", claims.Address)
+	assert.Empty(t, claims.Scopes)
+	assert.Equal(t, &Capability{Method: "POST", Path: "/api/claim"}, claims.Capability)
+}
+
+func TestVerifyCapabilityToken_RejectsOtherEndpoints(t *testing.T) {
+	service := NewJWTService([]byte("secret"), time.Hour)
+	ctx := context.Background()
+	token, _, err := service.GenerateCapabilityToken(ctx, capabilityParent(), "POST", "/api/claim", time.Minute)
+	require.NoError(t, err)
+
+	_, err = service.VerifyCapabilityToken(ctx, token, "GET", "/api/claim")
+	assert.True(t, errors.Is(err, ErrCapabilityMismatch))
+
+	_, err = service.VerifyCapabilityToken(ctx, token, "POST", "/api/claim/other")
+	assert.True(t, errors.Is(err, ErrCapabilityMismatch))
+}
+
+func TestVerifyToken_RejectsCapabilityTokens(t *testing.T) {
+	service := NewJWTService([]byte("secret"), time.Hour)
+	ctx := context.Background()
+	token, _, err := service.GenerateCapabilityToken(ctx, capabilityParent(), "POST", "/api/claim", time.Minute)
+	require.NoError(t, err)
+
+	_, err = service.VerifyToken(ctx, token)
+	assert.Error(t, err)
+}
+
+func TestVerifyCapabilityToken_SessionToken(t *testing.T) {
+	service := NewJWTService([]byte("secret"), time.Hour)
+	ctx := context.Background()
+	token, err := service.GenerateToken(ctx, "0x1234567890123456789012345678901234567890", []string{"read"})
+	require.NoError(t, err)
+
+	_, err = service.VerifyCapabilityToken(ctx, token, "POST", "/api/claim")
+	assert.True(t, errors.Is(err, ErrNotCapabilityToken))
+}
+
+func TestGenerateCapabilityToken_Validation(t *testing.T) {
+	service := NewJWTService([]byte("secret"), time.Hour)
+	ctx := context.Background()
+
+	_, _, err := service.GenerateCapabilityToken(ctx, capabilityParent(), "CONNECT", "/api/claim", time.Minute)
+	assert.Error(t, err)
+
+	_, _, err = service.GenerateCapabilityToken(ctx, capabilityParent(), "POST", "api/claim", time.Minute)
+	assert.Error(t, err)
+
+	_, _, err = service.GenerateCapabilityToken(ctx, capabilityParent(), "POST", "/api/claim", 0)
+	assert.Error(t, err)
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -12,23 +13,144 @@ import (
 type Claims struct {
 	Address string   `json:"address"`
 	Scopes  []string `json:"scopes"`
+
+	// Capability restricts the token to a single endpoint (nil for session tokens)
+	Capability *Capability `json:"cap,omitempty"`
+
+	// ParentID is the jti of the session a downscoped or capability token was
+	// derived from; revoking the session revokes every token derived from it
+	ParentID string `json:"parent_jti,omitempty"`
 	jwt.RegisteredClaims
+
+	// Extra holds any other claims of the token (e.g. role, tier, ens), encoded
+	// alongside the standard claims at the top level of the payload
+	Extra map[string]interface{} `json:"-"`
+}
+
+// reservedClaims are the payload keys decoded into Claims fields rather than Extra
+var reservedClaims = map[string]bool{
+	"address": true, "scopes": true, "cap": true, "parent_jti": true,
+	"iss": true, "sub": true, "aud": true, "exp": true, "nbf": true, "iat": true, "jti": true,
 }
 
+// claimsJSON has the fields of Claims without its JSON methods
+type claimsJSON Claims
+
+// MarshalJSON encodes the claims with Extra merged into the top level.
+// Extra entries never override standard claims.
+func (c Claims) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(claimsJSON(c))
+	if err != nil || len(c.Extra) == 0 {
+		return data, err
+	}
+
+	payload := make(map[string]interface{}, len(c.Extra))
+	for name, value := range c.Extra {
+		if !reservedClaims[name] {
+			payload[name] = value
+		}
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+	return json.Marshal(payload)
+}
+
+// UnmarshalJSON decodes the standard claims and collects the rest in Extra
+func (c *Claims) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*claimsJSON)(c)); err != nil {
+		return err
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return err
+	}
+	c.Extra = nil
+	for name, value := range payload {
+		if reservedClaims[name] {
+			continue
+		}
+		if c.Extra == nil {
+			c.Extra = make(map[string]interface{})
+		}
+		c.Extra[name] = value
+	}
+	return nil
+}
+
+// Claim returns the value of a claim by its payload name, covering address, scopes,
+// sub and iss as well as Extra. Values of Extra are as decoded from JSON.
+func (c *Claims) Claim(name string) (interface{}, bool) {
+	switch name {
+	case "address":
+		return c.Address, c.Address != ""
+	case "scopes":
+		return c.Scopes, c.Scopes != nil
+	case "sub":
+		return c.Subject, c.Subject != ""
+	case "iss":
+		return c.Issuer, c.Issuer != ""
+	}
+	value, ok := c.Extra[name]
+	return value, ok
+}
+
+// Derived reports whether the token was derived from a session, by downscoping
+// or as a capability token, rather than issued at sign-in
+func (c *Claims) Derived() bool {
+	return c.ParentID != ""
+}
+
+// familyID returns the jti of the session the token belongs to: its parent's, or
+// its own for sessions
+func (c *Claims) familyID() string {
+	if c.ParentID != "" {
+		return c.ParentID
+	}
+	return c.ID
+}
+
+// Identity returns the authenticated subject: the wallet address when present,
+// otherwise the registered subject (used for service accounts without an address)
+func (c *Claims) Identity() string {
+	if c.Address != "" {
+		return c.Address
+	}
+	return c.Subject
+}
+
+// TokenIssuer is the iss of the tokens gatekeeper issues to its own clients. Tokens
+// signed for others with the same key, such as OIDC ID tokens, carry another
+// issuer and an audience, and are never accepted as gatekeeper tokens.
+const TokenIssuer = "gatekeeper"
+
 // JWTService handles JWT token generation and verification
 type JWTService struct {
-	secret []byte
-	expiry time.Duration
+	method     jwt.SigningMethod
+	signingKey interface{} // HMAC secret or asymmetric private key
+	verifyKey  interface{} // HMAC secret or the matching public key
+	keyID      string      // kid header of asymmetric tokens (empty for HS256)
+	expiry     time.Duration
+
+	revocations RevocationList // Optional: IDs of tokens revoked before they expire
 }
 
-// NewJWTService creates a new JWT service
+// NewJWTService creates a new JWT service signing with an HS256 secret
 func NewJWTService(secret []byte, expiry time.Duration) *JWTService {
 	return &JWTService{
-		secret: secret,
-		expiry: expiry,
+		method:     jwt.SigningMethodHS256,
+		signingKey: secret,
+		verifyKey:  secret,
+		expiry:     expiry,
 	}
 }
 
+// Expiry returns the lifetime of tokens issued by GenerateToken
+func (j *JWTService) Expiry() time.Duration {
+	return j.expiry
+}
+
 // GenerateToken creates a new JWT token for the given address with scopes
 func (j *JWTService) GenerateToken(ctx context.Context, address string, scopes []string) (string, error) {
 	now := time.Now()
@@ -38,12 +160,81 @@ func (j *JWTService) GenerateToken(ctx context.Context, address string, scopes [
 		RegisteredClaims: jwt.RegisteredClaims{
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(now.Add(j.expiry)),
-			Issuer:    "gatekeeper",
+			Issuer:    TokenIssuer,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(j.secret)
+	return j.sign(claims)
+}
+
+// DownscopeToken issues a token for the same subject as parent carrying only the
+// requested scopes. Every requested scope must already be granted by the parent,
+// and the new token expires after ttl or when the parent expires, whichever is sooner.
+func (j *JWTService) DownscopeToken(ctx context.Context, parent *Claims, scopes []string, ttl time.Duration) (string, time.Time, error) {
+	if ttl <= 0 {
+		return "", time.Time{}, fmt.Errorf("ttl must be positive")
+	}
+	for _, scope := range scopes {
+		if !HasScope(parent.Scopes, scope) {
+			return "", time.Time{}, fmt.Errorf("scope %q is not granted by the parent token", scope)
+		}
+	}
+
+	now := time.Now()
+	expiresAt := childExpiry(parent, now, ttl)
+
+	claims := Claims{
+		Address:  parent.Address,
+		Scopes:   scopes,
+		ParentID: parent.familyID(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   parent.Subject,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			Issuer:    TokenIssuer,
+		},
+	}
+
+	token, err := j.sign(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// childExpiry returns when a token derived from parent expires: after ttl,
+// but never later than the parent itself
+func childExpiry(parent *Claims, now time.Time, ttl time.Duration) time.Time {
+	expiresAt := now.Add(ttl)
+	if parent.ExpiresAt != nil && parent.ExpiresAt.Time.Before(expiresAt) {
+		expiresAt = parent.ExpiresAt.Time
+	}
+	return expiresAt
+}
+
+// sign signs the claims with the service key, giving the token a unique ID
+// (jti) so it can be revoked
+func (j *JWTService) sign(claims Claims) (string, error) {
+	if claims.ID == "" {
+		id, err := newTokenID()
+		if err != nil {
+			return "", err
+		}
+		claims.ID = id
+	}
+
+	return j.SignClaims(claims)
+}
+
+// SignClaims signs arbitrary claims with the service key and kid header, for
+// tokens other than gatekeeper sessions such as OIDC ID tokens. Such tokens must
+// not claim TokenIssuer, or they would be accepted as sessions.
+func (j *JWTService) SignClaims(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(j.method, claims)
+	if j.keyID != "" {
+		token.Header["kid"] = j.keyID
+	}
+	tokenString, err := token.SignedString(j.signingKey)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign token: %w", err)
 	}
@@ -51,19 +242,35 @@ func (j *JWTService) GenerateToken(ctx context.Context, address string, scopes [
 	return tokenString, nil
 }
 
-// VerifyToken verifies and parses a JWT token
+// VerifyToken verifies and parses a JWT token.
+// Capability tokens are rejected; they are only accepted by VerifyCapabilityToken.
 func (j *JWTService) VerifyToken(ctx context.Context, tokenString string) (*Claims, error) {
+	claims, err := j.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Capability != nil {
+		return nil, fmt.Errorf("capability tokens are only valid for their endpoint")
+	}
+
+	return claims, nil
+}
+
+// parse verifies the signature and expiry of a token and returns its claims
+func (j *JWTService) parse(tokenString string) (*Claims, error) {
 	if tokenString == "" {
 		return nil, fmt.Errorf("token is empty")
 	}
 
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		// Verify signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		// Verify signing method: only the configured algorithm is accepted, so an
+		// HS256 token can never be verified against a published public key
+		if token.Method.Alg() != j.method.Alg() {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return j.secret, nil
+		return j.verifyKey, nil
 	})
 
 	if err != nil {
@@ -79,5 +286,10 @@ func (j *JWTService) VerifyToken(ctx context.Context, tokenString string) (*Clai
 		return nil, fmt.Errorf("token has expired")
 	}
 
+	// Tokens signed for other parties with the same key are not gatekeeper tokens
+	if claims.Issuer != TokenIssuer || len(claims.Audience) > 0 {
+		return nil, fmt.Errorf("token was not issued by gatekeeper")
+	}
+
 	return claims, nil
 }
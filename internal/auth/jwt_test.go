@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -174,3 +175,118 @@ func TestJWTService_VerifyToken_WithEmptyToken(t *testing.T) {
 	_, err := service.VerifyToken(ctx, "")
 	assert.Error(t, err)
 }
+
+func TestJWTService_DownscopeToken_SubsetOfScopes(t *testing.T) {
+	service := NewJWTService([]byte("secret"), time.Hour)
+	parent := &Claims{
+		Address: "0x1234567890123456789012345678901234567890",
+		Scopes:  []string{"read", "keys:*"},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "session-1",
+			Subject:   "user-1",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+
+	token, expiresAt, err := service.DownscopeToken(context.Background(), parent, []string{"keys:read"}, 5*time.Minute)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), expiresAt, 2*time.Second)
+
+	claims, err := service.VerifyToken(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, parent.Address, claims.Address)
+	assert.Equal(t, "user-1", claims.Subject)
+	assert.Equal(t, []string{"keys:read"}, claims.Scopes)
+	assert.False(t, parent.Derived())
+	assert.True(t, claims.Derived())
+	assert.Equal(t, parent.ID, claims.ParentID)
+}
+
+func TestJWTService_DownscopeToken_RejectsEscalation(t *testing.T) {
+	service := NewJWTService([]byte("secret"), time.Hour)
+	parent := &Claims{Address: "0x1234567890123456789012345678901234567890", Scopes: []string{"read"}}
+
+	_, _, err := service.DownscopeToken(context.Background(), parent, []string{"read", "write"}, time.Minute)
+	assert.Error(t, err)
+}
+
+func TestJWTService_DownscopeToken_CappedByParentExpiry(t *testing.T) {
+	service := NewJWTService([]byte("secret"), time.Hour)
+	parentExpiry := time.Now().Add(2 * time.Minute).Truncate(time.Second)
+	parent := &Claims{
+		Address:          "0x1234567890123456789012345678901234567890",
+		Scopes:           []string{"read"},
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(parentExpiry)},
+	}
+
+	_, expiresAt, err := service.DownscopeToken(context.Background(), parent, []string{"read"}, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, parentExpiry, expiresAt)
+}
+
+// Extra claims survive signing and verification at the top level of the payload
+func TestJWTService_ExtraClaims_RoundTrip(t *testing.T) {
+	service := NewJWTService([]byte("test-secret-key-at-least-32-chars"), time.Hour)
+	ctx := context.Background()
+
+	token, err := service.sign(Claims{
+		Address: "0x742d35Cc6634C0532925a3b844Bc390e38f3dF8c",
+		Scopes:  []string{"read"},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			Issuer:    TokenIssuer,
+		},
+		Extra: map[string]interface{}{
+			"role":    "admin",
+			"tier":    3,
+			"address": "0x0000000000000000000000000000000000000000", // Cannot override
+		},
+	})
+	require.NoError(t, err)
+
+	claims, err := service.VerifyToken(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, "0x742d35Cc6634C0532925a3b844Bc390e38f3dF8c", claims.Address)
+	assert.Equal(t, map[string]interface{}{"role": "admin", "tier": float64(3)}, claims.Extra)
+
+	role, ok := claims.Claim("role")
+	assert.True(t, ok)
+	assert.Equal(t, "admin", role)
+	scopes, ok := claims.Claim("scopes")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"read"}, scopes)
+	_, ok = claims.Claim("ens")
+	assert.False(t, ok)
+}
+
+// Tokens without extra claims decode with a nil Extra
+func TestJWTService_ExtraClaims_Empty(t *testing.T) {
+	service := NewJWTService([]byte("test-secret-key-at-least-32-chars"), time.Hour)
+	ctx := context.Background()
+
+	token, err := service.GenerateToken(ctx, "0x742d35Cc6634C0532925a3b844Bc390e38f3dF8c", []string{"read"})
+	require.NoError(t, err)
+
+	claims, err := service.VerifyToken(ctx, token)
+	require.NoError(t, err)
+	assert.Nil(t, claims.Extra)
+}
+
+// Tokens signed with the same key for other parties, such as OIDC ID tokens, are
+// not accepted as gatekeeper tokens
+func TestJWTService_VerifyToken_RejectsForeignTokens(t *testing.T) {
+	service := NewJWTService([]byte("test-secret-key-at-least-32-chars"), time.Hour)
+	ctx := context.Background()
+	exp := time.Now().Add(time.Hour).Unix()
+
+	for name, claims := range map[string]jwt.MapClaims{
+		"ID token":  {"iss": "https://auth.example.com", "sub": "0x742d35cc6634c0532925a3b844bc390e38f3df8c", "aud": "grafana", "exp": exp},
+		"no issuer": {"address": "0x742d35cc6634c0532925a3b844bc390e38f3df8c", "exp": exp},
+		"audience":  {"iss": TokenIssuer, "address": "0x742d35cc6634c0532925a3b844bc390e38f3df8c", "aud": "grafana", "exp": exp},
+	} {
+		token, err := service.SignClaims(claims)
+		require.NoError(t, err)
+		_, err = service.VerifyToken(ctx, token)
+		assert.ErrorContains(t, err, "not issued by gatekeeper", name)
+	}
+}
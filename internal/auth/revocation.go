@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RevocationList records the IDs (jti) of tokens revoked before they expire.
+// Entries only need to be kept until the token's own expiry.
+type RevocationList interface {
+	RevokeToken(ctx context.Context, id string, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, id string) (bool, error)
+}
+
+// newTokenID returns a random token ID for the jti claim
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// SetRevocationList sets the list consulted by IsRevoked and written by RevokeToken
+func (j *JWTService) SetRevocationList(list RevocationList) {
+	j.revocations = list
+}
+
+// RevocationEnabled reports whether tokens can be revoked
+func (j *JWTService) RevocationEnabled() bool {
+	return j.revocations != nil
+}
+
+// RevokeToken revokes the token with the given claims until it expires.
+// Tokens issued without an ID cannot be revoked.
+func (j *JWTService) RevokeToken(ctx context.Context, claims *Claims) error {
+	if j.revocations == nil {
+		return fmt.Errorf("token revocation is not enabled")
+	}
+	if claims.ID == "" {
+		return fmt.Errorf("token has no ID and cannot be revoked")
+	}
+
+	expiresAt := time.Now().Add(j.expiry)
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	return j.revocations.RevokeToken(ctx, claims.ID, expiresAt)
+}
+
+// IsRevoked reports whether the token with the given claims has been revoked,
+// itself or through the session it was derived from. It is always false without
+// a revocation list.
+func (j *JWTService) IsRevoked(ctx context.Context, claims *Claims) (bool, error) {
+	if j.revocations == nil {
+		return false, nil
+	}
+	for _, id := range []string{claims.ID, claims.ParentID} {
+		if id == "" {
+			continue
+		}
+		revoked, err := j.revocations.IsTokenRevoked(ctx, id)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+	return false, nil
+}
+
+// memoryPruneThreshold is the size at which the in-memory list first drops expired entries
+const memoryPruneThreshold = 1024
+
+// MemoryRevocationList is a RevocationList held in process memory. Revocations
+// are lost on restart and not shared between instances.
+type MemoryRevocationList struct {
+	mu        sync.Mutex
+	entries   map[string]time.Time
+	nextPrune int
+	now       func() time.Time
+}
+
+// NewMemoryRevocationList creates an empty in-memory revocation list
+func NewMemoryRevocationList() *MemoryRevocationList {
+	return &MemoryRevocationList{
+		entries:   make(map[string]time.Time),
+		nextPrune: memoryPruneThreshold,
+		now:       time.Now,
+	}
+}
+
+// RevokeToken records the token ID until expiresAt
+func (l *MemoryRevocationList) RevokeToken(ctx context.Context, id string, expiresAt time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries[id] = expiresAt
+	if len(l.entries) >= l.nextPrune {
+		l.prune()
+		l.nextPrune = max(2*len(l.entries), memoryPruneThreshold)
+	}
+	return nil
+}
+
+// IsTokenRevoked reports whether the token ID is revoked and not yet expired
+func (l *MemoryRevocationList) IsTokenRevoked(ctx context.Context, id string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	expiresAt, ok := l.entries[id]
+	if !ok {
+		return false, nil
+	}
+	if !l.now().Before(expiresAt) {
+		delete(l.entries, id)
+		return false, nil
+	}
+	return true, nil
+}
+
+// prune drops entries of tokens that have expired anyway. The caller holds mu.
+func (l *MemoryRevocationList) prune() {
+	now := l.now()
+	for id, expiresAt := range l.entries {
+		if !now.Before(expiresAt) {
+			delete(l.entries, id)
+		}
+	}
+}
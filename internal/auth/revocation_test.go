@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWTService_TokensHaveUniqueIDs(t *testing.T) {
+	service := NewJWTService([]byte("secret"), time.Hour)
+	ctx := context.Background()
+
+	first, err := service.GenerateToken(ctx, "0xabc", nil)
+	require.NoError(t, err)
+	second, err := service.GenerateToken(ctx, "0xabc", nil)
+	require.NoError(t, err)
+
+	firstClaims, err := service.VerifyToken(ctx, first)
+	require.NoError(t, err)
+	secondClaims, err := service.VerifyToken(ctx, second)
+	require.NoError(t, err)
+
+	assert.Len(t, firstClaims.ID, 32)
+	assert.NotEqual(t, firstClaims.ID, secondClaims.ID)
+}
+
+func TestJWTService_RevokeToken(t *testing.T) {
+	service := NewJWTService([]byte("secret"), time.Hour)
+	ctx := context.Background()
+	token, err := service.GenerateToken(ctx, "0xabc", nil)
+	require.NoError(t, err)
+	claims, err := service.VerifyToken(ctx, token)
+	require.NoError(t, err)
+
+	// Without a list nothing is revoked
+	revoked, err := service.IsRevoked(ctx, claims)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+	assert.Error(t, service.RevokeToken(ctx, claims))
+
+	service.SetRevocationList(NewMemoryRevocationList())
+	require.NoError(t, service.RevokeToken(ctx, claims))
+	revoked, err = service.IsRevoked(ctx, claims)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	// Other tokens are unaffected
+	other, err := service.GenerateToken(ctx, "0xabc", nil)
+	require.NoError(t, err)
+	otherClaims, err := service.VerifyToken(ctx, other)
+	require.NoError(t, err)
+	revoked, err = service.IsRevoked(ctx, otherClaims)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	// Tokens issued without an ID cannot be revoked
+	legacy := &Claims{Address: "0xabc", RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}}
+	assert.Error(t, service.RevokeToken(ctx, legacy))
+}
+
+// Revoking a session revokes the downscoped and capability tokens derived from it
+func TestJWTService_RevokeToken_Family(t *testing.T) {
+	service := NewJWTService([]byte("secret"), time.Hour)
+	service.SetRevocationList(NewMemoryRevocationList())
+	ctx := context.Background()
+
+	token, err := service.GenerateToken(ctx, "0xabc", []string{"read"})
+	require.NoError(t, err)
+	session, err := service.VerifyToken(ctx, token)
+	require.NoError(t, err)
+
+	downscoped, _, err := service.DownscopeToken(ctx, session, []string{"read"}, time.Minute)
+	require.NoError(t, err)
+	child, err := service.VerifyToken(ctx, downscoped)
+	require.NoError(t, err)
+	capability, _, err := service.GenerateCapabilityToken(ctx, session, "POST", "/api/claim", time.Minute)
+	require.NoError(t, err)
+	capabilityClaims, err := service.VerifyCapabilityToken(ctx, capability, "POST", "/api/claim")
+	require.NoError(t, err)
+	assert.Equal(t, session.ID, capabilityClaims.ParentID)
+
+	// Revoking a derived token leaves the session alone
+	require.NoError(t, service.RevokeToken(ctx, child))
+	revoked, err := service.IsRevoked(ctx, session)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+	revoked, err = service.IsRevoked(ctx, capabilityClaims)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	require.NoError(t, service.RevokeToken(ctx, session))
+	revoked, err = service.IsRevoked(ctx, capabilityClaims)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestMemoryRevocationList_ForgetsExpiredTokens(t *testing.T) {
+	list := NewMemoryRevocationList()
+	now := time.Now()
+	list.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	require.NoError(t, list.RevokeToken(ctx, "token-1", now.Add(time.Minute)))
+	revoked, err := list.IsTokenRevoked(ctx, "token-1")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	now = now.Add(2 * time.Minute)
+	revoked, err = list.IsTokenRevoked(ctx, "token-1")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+	assert.Empty(t, list.entries)
+}
+
+func TestMemoryRevocationList_PrunesWhenGrowing(t *testing.T) {
+	list := NewMemoryRevocationList()
+	now := time.Now()
+	list.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < memoryPruneThreshold-1; i++ {
+		require.NoError(t, list.RevokeToken(ctx, fmt.Sprintf("expired-%d", i), now.Add(time.Minute)))
+	}
+	now = now.Add(2 * time.Minute)
+	require.NoError(t, list.RevokeToken(ctx, "live", now.Add(time.Minute)))
+
+	assert.Len(t, list.entries, 1)
+	revoked, err := list.IsTokenRevoked(ctx, "live")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
@@ -0,0 +1,99 @@
+package auth
+
+import "fmt"
+
+// Role is an administrative role held by a wallet user. Roles are ranked and each
+// includes everything the roles below it may do: a viewer reads admin endpoints,
+// an operator also performs operational actions and an admin manages everything,
+// including allowlists and the roles of other users.
+type Role string
+
+const (
+	RoleViewer   Role = "viewer"
+	RoleOperator Role = "operator"
+	RoleAdmin    Role = "admin"
+)
+
+// roleRanks orders the roles from least to most privileged
+var roleRanks = map[Role]int{
+	RoleViewer:   1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+// privilegedScopes maps scopes that grant no role but reach beyond the holder's own
+// data to the least privileged role that may hold and pass them on
+var privilegedScopes = map[string]Role{
+	"authz:check": RoleOperator,
+}
+
+// ParseRole validates a role name
+func ParseRole(name string) (Role, error) {
+	role := Role(name)
+	if _, ok := roleRanks[role]; !ok {
+		return "", fmt.Errorf("unknown role %q: must be viewer, operator or admin", name)
+	}
+	return role, nil
+}
+
+// Scope returns the scope granting the role; sessions of users holding the role
+// carry it, and API keys may be issued with it
+func (r Role) Scope() string {
+	return string(r)
+}
+
+// Includes reports whether the role may do everything other may
+func (r Role) Includes(other Role) bool {
+	return roleRanks[r] >= roleRanks[other] && roleRanks[other] > 0
+}
+
+// HighestRole returns the most privileged role granted by scopes, if any
+func HighestRole(scopes []string) (Role, bool) {
+	var highest Role
+	for role := range roleRanks {
+		if HasScope(scopes, role.Scope()) && roleRanks[role] > roleRanks[highest] {
+			highest = role
+		}
+	}
+	return highest, highest != ""
+}
+
+// requiredRole returns the least privileged role a holder of scope must have,
+// if any: the role the scope grants, or the role a privileged scope it covers needs
+func requiredRole(scope string) (Role, bool) {
+	required, _ := HighestRole([]string{scope})
+	for privileged, role := range privilegedScopes {
+		if ScopeMatches(scope, privileged) && roleRanks[role] > roleRanks[required] {
+			required = role
+		}
+	}
+	return required, required != ""
+}
+
+// ScopesBeyondRole returns the scopes granting a role more privileged than held,
+// or a privileged scope such as authz:check, wildcards included. Holders may only
+// pass on the roles they hold.
+func ScopesBeyondRole(scopes []string, held Role) []string {
+	var beyond []string
+	for _, scope := range scopes {
+		if role, ok := requiredRole(scope); ok && !held.Includes(role) {
+			beyond = append(beyond, scope)
+		}
+	}
+	return beyond
+}
+
+// WithinRole returns the scopes without those granting a role more privileged than held
+func WithinRole(scopes []string, held Role) []string {
+	beyond := ScopesBeyondRole(scopes, held)
+	if len(beyond) == 0 {
+		return scopes
+	}
+	within := make([]string, 0, len(scopes)-len(beyond))
+	for _, scope := range scopes {
+		if len(ScopesBeyondRole([]string{scope}, held)) == 0 {
+			within = append(within, scope)
+		}
+	}
+	return within
+}
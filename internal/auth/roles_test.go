@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRole(t *testing.T) {
+	role, err := ParseRole("operator")
+	require.NoError(t, err)
+	assert.Equal(t, RoleOperator, role)
+
+	_, err = ParseRole("root")
+	assert.Error(t, err)
+	_, err = ParseRole("")
+	assert.Error(t, err)
+}
+
+func TestRole_Includes(t *testing.T) {
+	assert.True(t, RoleAdmin.Includes(RoleViewer))
+	assert.True(t, RoleAdmin.Includes(RoleAdmin))
+	assert.True(t, RoleOperator.Includes(RoleViewer))
+	assert.False(t, RoleOperator.Includes(RoleAdmin))
+	assert.False(t, RoleViewer.Includes(RoleOperator))
+	assert.False(t, Role("").Includes(RoleViewer))
+}
+
+func TestHighestRole(t *testing.T) {
+	role, ok := HighestRole([]string{"auth", "viewer", "operator"})
+	require.True(t, ok)
+	assert.Equal(t, RoleOperator, role)
+
+	role, ok = HighestRole([]string{"*"})
+	require.True(t, ok)
+	assert.Equal(t, RoleAdmin, role)
+
+	_, ok = HighestRole([]string{"auth", "read"})
+	assert.False(t, ok)
+}
+
+func TestScopesBeyondRole(t *testing.T) {
+	scopes := []string{"read", "viewer", "operator", "admin", "*", "keys:*"}
+	assert.Equal(t, []string{"viewer", "operator", "admin", "*"}, ScopesBeyondRole(scopes, ""))
+	assert.Equal(t, []string{"admin", "*"}, ScopesBeyondRole(scopes, RoleOperator))
+	assert.Empty(t, ScopesBeyondRole(scopes, RoleAdmin))
+
+	assert.Equal(t, []string{"read", "keys:*"}, WithinRole(scopes, ""))
+	assert.Equal(t, []string{"read", "viewer", "operator", "keys:*"}, WithinRole(scopes, RoleOperator))
+	assert.Equal(t, scopes, WithinRole(scopes, RoleAdmin))
+}
+
+func TestScopesBeyondRole_Privileged(t *testing.T) {
+	scopes := []string{"read", "authz:check", "authz:*"}
+	assert.Equal(t, []string{"authz:check", "authz:*"}, ScopesBeyondRole(scopes, ""))
+	assert.Equal(t, []string{"authz:check", "authz:*"}, ScopesBeyondRole(scopes, RoleViewer))
+	assert.Empty(t, ScopesBeyondRole(scopes, RoleOperator))
+	assert.Equal(t, []string{"read"}, WithinRole(scopes, RoleViewer))
+}
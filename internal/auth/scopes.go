@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Scopes are hierarchical, with segments separated by ScopeSeparator (e.g. "keys:read").
+// A trailing wildcard segment grants every scope beneath its prefix, so "keys:*"
+// grants "keys:read" and "keys:write", and "*" on its own grants everything.
+const (
+	ScopeSeparator = ":"
+	ScopeWildcard  = "*"
+)
+
+// ScopeMatches reports whether a granted scope satisfies a required scope
+func ScopeMatches(granted, required string) bool {
+	if granted == required || granted == ScopeWildcard {
+		return true
+	}
+	if !strings.HasSuffix(granted, ScopeSeparator+ScopeWildcard) {
+		return false
+	}
+	prefix := strings.TrimSuffix(granted, ScopeWildcard)
+	return strings.HasPrefix(required, prefix) && len(required) > len(prefix)
+}
+
+// HasScope reports whether any of the granted scopes satisfies the required scope
+func HasScope(granted []string, required string) bool {
+	for _, scope := range granted {
+		if ScopeMatches(scope, required) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopeDefinition describes a scope that can be granted to an API key
+type ScopeDefinition struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Implies     []string `json:"implies,omitempty"` // Scopes granted implicitly by this scope
+}
+
+// ScopeCatalog is the set of scopes known to the service.
+// Keys may only be issued with scopes from the catalog.
+type ScopeCatalog struct {
+	scopes map[string]ScopeDefinition
+	order  []string
+}
+
+// scopeCatalogConfig represents the JSON structure of a scope catalog file
+type scopeCatalogConfig struct {
+	Scopes []ScopeDefinition `json:"scopes"`
+}
+
+// NewScopeCatalog creates a catalog from the given definitions.
+// Returns error if a name is empty or duplicated, or an implied scope is not defined.
+func NewScopeCatalog(definitions []ScopeDefinition) (*ScopeCatalog, error) {
+	catalog := &ScopeCatalog{
+		scopes: make(map[string]ScopeDefinition, len(definitions)),
+		order:  make([]string, 0, len(definitions)),
+	}
+
+	for i, def := range definitions {
+		if def.Name == "" {
+			return nil, fmt.Errorf("scope %d: name is required", i)
+		}
+		if _, exists := catalog.scopes[def.Name]; exists {
+			return nil, fmt.Errorf("scope %d: duplicate scope '%s'", i, def.Name)
+		}
+		catalog.scopes[def.Name] = def
+		catalog.order = append(catalog.order, def.Name)
+	}
+
+	for _, def := range definitions {
+		for _, implied := range def.Implies {
+			if !catalog.Contains(implied) {
+				return nil, fmt.Errorf("scope '%s' implies unknown scope '%s'", def.Name, implied)
+			}
+		}
+	}
+
+	return catalog, nil
+}
+
+// LoadScopeCatalogFromJSON parses a scope catalog from JSON bytes
+func LoadScopeCatalogFromJSON(data []byte) (*ScopeCatalog, error) {
+	var config scopeCatalogConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse scope catalog: %w", err)
+	}
+	if len(config.Scopes) == 0 {
+		return nil, fmt.Errorf("scope catalog must define at least one scope")
+	}
+	return NewScopeCatalog(config.Scopes)
+}
+
+// DefaultScopeCatalog returns the built-in catalog used when no catalog file is configured
+func DefaultScopeCatalog() *ScopeCatalog {
+	catalog, _ := NewScopeCatalog([]ScopeDefinition{
+		{Name: "read", Description: "Read access to protected resources"},
+		{Name: "write", Description: "Write access to protected resources", Implies: []string{"read"}},
+		{Name: "keys:read", Description: "List API keys"},
+		{Name: "keys:write", Description: "Create and revoke API keys", Implies: []string{"keys:read"}},
+		{Name: "authz:check", Description: "Ask for policy decisions on behalf of any address"},
+		{Name: "viewer", Description: "Read admin endpoints"},
+		{Name: "operator", Description: "Read admin endpoints and perform operational actions", Implies: []string{"authz:check"}},
+		{Name: "admin", Description: "Full administrative access", Implies: []string{"read", "write", "keys:*", "authz:check"}},
+	})
+	return catalog
+}
+
+// List returns all scope definitions in catalog order
+func (c *ScopeCatalog) List() []ScopeDefinition {
+	definitions := make([]ScopeDefinition, len(c.order))
+	for i, name := range c.order {
+		definitions[i] = c.scopes[name]
+	}
+	return definitions
+}
+
+// Contains reports whether the scope is defined in the catalog.
+// A wildcard scope is considered defined when it covers at least one catalog scope.
+func (c *ScopeCatalog) Contains(scope string) bool {
+	if _, exists := c.scopes[scope]; exists {
+		return true
+	}
+	if scope != ScopeWildcard && !strings.HasSuffix(scope, ScopeSeparator+ScopeWildcard) {
+		return false
+	}
+	for name := range c.scopes {
+		if ScopeMatches(scope, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unknown returns the scopes that are not defined in the catalog
+func (c *ScopeCatalog) Unknown(scopes []string) []string {
+	var unknown []string
+	for _, scope := range scopes {
+		if !c.Contains(scope) {
+			unknown = append(unknown, scope)
+		}
+	}
+	return unknown
+}
+
+// Expand returns the scopes together with every scope they imply, transitively.
+// Order is preserved and duplicates are removed.
+func (c *ScopeCatalog) Expand(scopes []string) []string {
+	seen := make(map[string]bool, len(scopes))
+	expanded := make([]string, 0, len(scopes))
+
+	var visit func(scope string)
+	visit = func(scope string) {
+		if seen[scope] {
+			return
+		}
+		seen[scope] = true
+		expanded = append(expanded, scope)
+		for _, implied := range c.scopes[scope].Implies {
+			visit(implied)
+		}
+	}
+
+	for _, scope := range scopes {
+		visit(scope)
+	}
+	return expanded
+}
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadScopeCatalogFromJSON(t *testing.T) {
+	data := []byte(`{
+		"scopes": [
+			{"name": "read", "description": "Read access"},
+			{"name": "write", "description": "Write access"},
+			{"name": "admin", "description": "Admin access", "implies": ["read", "write"]}
+		]
+	}`)
+
+	catalog, err := LoadScopeCatalogFromJSON(data)
+
+	require.NoError(t, err)
+	scopes := catalog.List()
+	require.Len(t, scopes, 3)
+	assert.Equal(t, "read", scopes[0].Name)
+	assert.Equal(t, "admin", scopes[2].Name)
+	assert.Equal(t, []string{"read", "write"}, scopes[2].Implies)
+}
+
+func TestLoadScopeCatalogFromJSON_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"malformed JSON", `{"scopes": [`},
+		{"empty catalog", `{"scopes": []}`},
+		{"missing name", `{"scopes": [{"description": "nameless"}]}`},
+		{"duplicate name", `{"scopes": [{"name": "read"}, {"name": "read"}]}`},
+		{"unknown implied scope", `{"scopes": [{"name": "admin", "implies": ["root"]}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadScopeCatalogFromJSON([]byte(tt.data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestScopeCatalog_Unknown(t *testing.T) {
+	catalog := DefaultScopeCatalog()
+
+	assert.Empty(t, catalog.Unknown([]string{"read", "write"}))
+	assert.Equal(t, []string{"raed", "delete"}, catalog.Unknown([]string{"read", "raed", "delete"}))
+}
+
+func TestScopeCatalog_Expand(t *testing.T) {
+	catalog, err := NewScopeCatalog([]ScopeDefinition{
+		{Name: "read"},
+		{Name: "write", Implies: []string{"read"}},
+		{Name: "admin", Implies: []string{"write"}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"admin", "write", "read"}, catalog.Expand([]string{"admin"}))
+	assert.Equal(t, []string{"read", "write"}, catalog.Expand([]string{"read", "write", "read"}))
+	assert.Equal(t, []string{"legacy"}, catalog.Expand([]string{"legacy"}))
+}
+
+func TestScopeMatches(t *testing.T) {
+	tests := []struct {
+		granted  string
+		required string
+		want     bool
+	}{
+		{"keys:read", "keys:read", true},
+		{"keys:read", "keys:write", false},
+		{"keys:*", "keys:read", true},
+		{"keys:*", "keys:rotate:self", true},
+		{"keys:*", "keys", false},
+		{"keys:*", "keysmith:read", false},
+		{"admin:*", "keys:read", false},
+		{"*", "keys:read", true},
+		{"keys", "keys:read", false},
+		{"keys*", "keys:read", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.granted+"_"+tt.required, func(t *testing.T) {
+			assert.Equal(t, tt.want, ScopeMatches(tt.granted, tt.required))
+		})
+	}
+}
+
+func TestScopeCatalog_ContainsWildcard(t *testing.T) {
+	catalog, err := NewScopeCatalog([]ScopeDefinition{
+		{Name: "keys:read"},
+		{Name: "keys:write"},
+		{Name: "admin", Implies: []string{"keys:*"}},
+	})
+	require.NoError(t, err)
+
+	assert.True(t, catalog.Contains("keys:*"))
+	assert.True(t, catalog.Contains("*"))
+	assert.False(t, catalog.Contains("billing:*"))
+	assert.Equal(t, []string{"billing:*"}, catalog.Unknown([]string{"keys:*", "billing:*"}))
+}
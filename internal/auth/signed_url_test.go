@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const signedURLSigner = "0x742d35cc6634c0532925a3b844bc9e7595f0beb0"
+
+func TestURLSigner_SignAndVerify(t *testing.T) {
+	signer := NewURLSigner([]byte("secret"))
+	now := time.Now()
+
+	query := signer.Sign("/downloads/report.pdf", now.Add(time.Hour), SignedURL{Signer: signedURLSigner})
+
+	signed, err := signer.Verify("/downloads/report.pdf", query, now)
+	require.NoError(t, err)
+	assert.Equal(t, signedURLSigner, signed.Signer)
+	assert.Empty(t, signed.Address)
+	assert.Empty(t, signed.Session)
+}
+
+func TestURLSigner_CarriesSession(t *testing.T) {
+	signer := NewURLSigner([]byte("secret"))
+	now := time.Now()
+
+	query := signer.Sign("/downloads/report.pdf", now.Add(time.Hour), SignedURL{Signer: signedURLSigner, Session: "session-jti"})
+
+	signed, err := signer.Verify("/downloads/report.pdf", query, now)
+	require.NoError(t, err)
+	assert.Equal(t, "session-jti", signed.Session)
+
+	// Moving the URL to another session, or dropping it, invalidates the signature
+	query.Set(SignedURLSessionParam, "other-jti")
+	_, err = signer.Verify("/downloads/report.pdf", query, now)
+	assert.Error(t, err)
+	query.Del(SignedURLSessionParam)
+	_, err = signer.Verify("/downloads/report.pdf", query, now)
+	assert.Error(t, err)
+}
+
+func TestURLSigner_BindsAddress(t *testing.T) {
+	signer := NewURLSigner([]byte("secret"))
+	now := time.Now()
+
+	query := signer.Sign("/downloads/report.pdf", now.Add(time.Hour), SignedURL{Signer: signedURLSigner, Address: "0xABCDEF1234567890ABCDEF1234567890ABCDEF12"})
+
+	signed, err := signer.Verify("/downloads/report.pdf", query, now)
+	require.NoError(t, err)
+	assert.Equal(t, "0xabcdef1234567890abcdef1234567890abcdef12", signed.Address)
+
+	// Rebinding to another address invalidates the signature
+	query.Set(SignedURLAddressParam, "0x1111111111111111111111111111111111111111")
+	_, err = signer.Verify("/downloads/report.pdf", query, now)
+	assert.Error(t, err)
+}
+
+func TestURLSigner_RejectsTampering(t *testing.T) {
+	signer := NewURLSigner([]byte("secret"))
+	now := time.Now()
+	query := signer.Sign("/downloads/report.pdf", now.Add(time.Hour), SignedURL{Signer: signedURLSigner})
+
+	tests := []struct {
+		name   string
+		path   string
+		mutate func()
+	}{
+		{name: "different path", path: "/downloads/other.pdf", mutate: func() {}},
+		{name: "extended expiry", path: "/downloads/report.pdf", mutate: func() {
+			query.Set(SignedURLExpiresParam, "99999999999")
+		}},
+		{name: "another signer", path: "/downloads/report.pdf", mutate: func() {
+			query.Set(SignedURLSignerParam, "0x1111111111111111111111111111111111111111")
+		}},
+		{name: "missing signature", path: "/downloads/report.pdf", mutate: func() {
+			query.Del(SignedURLSignatureParam)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mutate()
+			_, err := signer.Verify(tt.path, query, now)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestURLSigner_RejectsExpired(t *testing.T) {
+	signer := NewURLSigner([]byte("secret"))
+	now := time.Now()
+	query := signer.Sign("/downloads/report.pdf", now.Add(time.Minute), SignedURL{Signer: signedURLSigner})
+
+	_, err := signer.Verify("/downloads/report.pdf", query, now.Add(2*time.Minute))
+	assert.Error(t, err)
+}
+
+func TestURLSigner_RejectsOtherSecret(t *testing.T) {
+	query := NewURLSigner([]byte("secret")).Sign("/downloads/report.pdf", time.Now().Add(time.Hour), SignedURL{Signer: signedURLSigner})
+
+	_, err := NewURLSigner([]byte("other")).Verify("/downloads/report.pdf", query, time.Now())
+	assert.Error(t, err)
+}
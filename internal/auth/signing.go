@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported JWT signing algorithms
+const (
+	AlgorithmHS256 = "HS256" // HMAC with the shared secret (default)
+	AlgorithmRS256 = "RS256" // RSA PKCS#1 v1.5 with SHA-256
+	AlgorithmES256 = "ES256" // ECDSA on P-256 with SHA-256
+	AlgorithmEdDSA = "EdDSA" // Ed25519
+)
+
+// IsSupportedAlgorithm reports whether alg is one of the supported signing algorithms
+func IsSupportedAlgorithm(alg string) bool {
+	switch alg {
+	case AlgorithmHS256, AlgorithmRS256, AlgorithmES256, AlgorithmEdDSA:
+		return true
+	}
+	return false
+}
+
+// NewJWTServiceWithKey creates a JWT service that signs with an asymmetric private key,
+// so other services can verify its tokens with only the public key (see JWKS).
+// privateKeyPEM holds a PEM encoded key matching algorithm: an RSA key for RS256,
+// a P-256 key for ES256 or an Ed25519 key for EdDSA.
+func NewJWTServiceWithKey(algorithm string, privateKeyPEM []byte, expiry time.Duration) (*JWTService, error) {
+	var (
+		method     jwt.SigningMethod
+		signingKey interface{}
+		publicKey  interface{}
+	)
+
+	switch algorithm {
+	case AlgorithmRS256:
+		key, err := jwt.ParseRSAPrivateKeyFromPEM(privateKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RS256 private key: %w", err)
+		}
+		method, signingKey, publicKey = jwt.SigningMethodRS256, key, &key.PublicKey
+	case AlgorithmES256:
+		key, err := jwt.ParseECPrivateKeyFromPEM(privateKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ES256 private key: %w", err)
+		}
+		if key.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("invalid ES256 private key: curve must be P-256")
+		}
+		method, signingKey, publicKey = jwt.SigningMethodES256, key, &key.PublicKey
+	case AlgorithmEdDSA:
+		key, err := jwt.ParseEdPrivateKeyFromPEM(privateKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EdDSA private key: %w", err)
+		}
+		edKey, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("invalid EdDSA private key: not an Ed25519 key")
+		}
+		method, signingKey, publicKey = jwt.SigningMethodEdDSA, edKey, edKey.Public()
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q for a private key", algorithm)
+	}
+
+	keyID, err := publicKeyID(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JWTService{
+		method:     method,
+		signingKey: signingKey,
+		verifyKey:  publicKey,
+		keyID:      keyID,
+		expiry:     expiry,
+	}, nil
+}
+
+// Algorithm returns the name of the algorithm tokens are signed with
+func (j *JWTService) Algorithm() string {
+	return j.method.Alg()
+}
+
+// publicKeyID derives a stable key ID from the public key, sent as the kid header
+func publicKeyID(publicKey interface{}) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// JWK is a public key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+// JWKSet is the document served to services that verify gatekeeper tokens
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public verification keys. It is empty for HS256, whose
+// secret must never be published.
+func (j *JWTService) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+
+	jwk := JWK{KeyID: j.keyID, Use: "sig", Algorithm: j.method.Alg()}
+	switch key := j.verifyKey.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+	case *ecdsa.PublicKey:
+		ecdhKey, err := key.ECDH()
+		if err != nil {
+			return set
+		}
+		// Uncompressed point: 0x04 || X || Y
+		point := ecdhKey.Bytes()
+		size := (len(point) - 1) / 2
+		jwk.KeyType = "EC"
+		jwk.Curve = "P-256"
+		jwk.X = base64.RawURLEncoding.EncodeToString(point[1 : 1+size])
+		jwk.Y = base64.RawURLEncoding.EncodeToString(point[1+size:])
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(key)
+	default:
+		return set
+	}
+
+	set.Keys = append(set.Keys, jwk)
+	return set
+}
@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encodePrivateKey returns key as a PKCS#8 PEM block
+func encodePrivateKey(t *testing.T, key interface{}) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func generateKeys(t *testing.T) map[string]interface{} {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	return map[string]interface{}{
+		AlgorithmRS256: rsaKey,
+		AlgorithmES256: ecKey,
+		AlgorithmEdDSA: edKey,
+	}
+}
+
+func TestNewJWTServiceWithKey_SignsAndVerifies(t *testing.T) {
+	for alg, key := range generateKeys(t) {
+		t.Run(alg, func(t *testing.T) {
+			service, err := NewJWTServiceWithKey(alg, encodePrivateKey(t, key), time.Hour)
+			require.NoError(t, err)
+			assert.Equal(t, alg, service.Algorithm())
+
+			token, err := service.GenerateToken(context.Background(), "0xabc", []string{"api"})
+			require.NoError(t, err)
+
+			claims, err := service.VerifyToken(context.Background(), token)
+			require.NoError(t, err)
+			assert.Equal(t, "0xabc", claims.Address)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+			require.NoError(t, err)
+			assert.Equal(t, alg, parsed.Header["alg"])
+			assert.Equal(t, service.JWKS().Keys[0].KeyID, parsed.Header["kid"])
+		})
+	}
+}
+
+func TestNewJWTServiceWithKey_VerifiableWithPublicKeyOnly(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	service, err := NewJWTServiceWithKey(AlgorithmES256, encodePrivateKey(t, key), time.Hour)
+	require.NoError(t, err)
+
+	token, err := service.GenerateToken(context.Background(), "0xabc", nil)
+	require.NoError(t, err)
+
+	_, err = jwt.ParseWithClaims(token, &Claims{}, func(*jwt.Token) (interface{}, error) {
+		return &key.PublicKey, nil
+	}, jwt.WithValidMethods([]string{AlgorithmES256}))
+	assert.NoError(t, err)
+}
+
+func TestNewJWTServiceWithKey_RejectsMismatchedKey(t *testing.T) {
+	keys := generateKeys(t)
+
+	_, err := NewJWTServiceWithKey(AlgorithmRS256, encodePrivateKey(t, keys[AlgorithmEdDSA]), time.Hour)
+	assert.Error(t, err)
+
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	_, err = NewJWTServiceWithKey(AlgorithmES256, encodePrivateKey(t, p384), time.Hour)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "P-256")
+
+	_, err = NewJWTServiceWithKey(AlgorithmHS256, []byte("secret"), time.Hour)
+	assert.Error(t, err)
+
+	_, err = NewJWTServiceWithKey(AlgorithmEdDSA, []byte("not a pem"), time.Hour)
+	assert.Error(t, err)
+}
+
+func TestJWTService_RejectsOtherAlgorithms(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaService, err := NewJWTServiceWithKey(AlgorithmRS256, encodePrivateKey(t, key), time.Hour)
+	require.NoError(t, err)
+
+	// An HS256 token signed with the public key must not pass as RS256
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	hmacService := NewJWTService(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), time.Hour)
+	forged, err := hmacService.GenerateToken(context.Background(), "0xabc", nil)
+	require.NoError(t, err)
+
+	_, err = rsaService.VerifyToken(context.Background(), forged)
+	assert.Error(t, err)
+
+	// Likewise an HS256 service rejects RS256 tokens
+	token, err := rsaService.GenerateToken(context.Background(), "0xabc", nil)
+	require.NoError(t, err)
+	_, err = hmacService.VerifyToken(context.Background(), token)
+	assert.Error(t, err)
+}
+
+func TestJWTService_JWKS(t *testing.T) {
+	assert.Empty(t, NewJWTService([]byte("secret"), time.Hour).JWKS().Keys)
+
+	keys := generateKeys(t)
+	expected := map[string][2]string{
+		AlgorithmRS256: {"RSA", ""},
+		AlgorithmES256: {"EC", "P-256"},
+		AlgorithmEdDSA: {"OKP", "Ed25519"},
+	}
+	for alg, key := range keys {
+		service, err := NewJWTServiceWithKey(alg, encodePrivateKey(t, key), time.Hour)
+		require.NoError(t, err)
+
+		set := service.JWKS()
+		require.Len(t, set.Keys, 1)
+		jwk := set.Keys[0]
+		assert.Equal(t, expected[alg][0], jwk.KeyType, alg)
+		assert.Equal(t, expected[alg][1], jwk.Curve, alg)
+		assert.Equal(t, alg, jwk.Algorithm)
+		assert.Equal(t, "sig", jwk.Use)
+		assert.Len(t, jwk.KeyID, 16)
+	}
+
+	ecService, err := NewJWTServiceWithKey(AlgorithmES256, encodePrivateKey(t, keys[AlgorithmES256]), time.Hour)
+	require.NoError(t, err)
+	jwk := ecService.JWKS().Keys[0]
+	assert.Len(t, jwk.X, 43) // 32 bytes, unpadded base64url
+	assert.Len(t, jwk.Y, 43)
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -237,6 +238,16 @@ func ExtractNonceFromMessage(message string) (string, error) {
 	return strings.TrimSpace(matches[1]), nil
 }
 
+// ExtractChainIDFromMessage extracts the EIP-155 chain ID from a SIWE message
+func ExtractChainIDFromMessage(message string) (uint64, error) {
+	chainIDRegex := regexp.MustCompile(`(?m)^Chain ID:\s*(\d+)\s*$`)
+	matches := chainIDRegex.FindStringSubmatch(message)
+	if len(matches) < 2 {
+		return 0, fmt.Errorf("chain ID not found in message")
+	}
+	return strconv.ParseUint(matches[1], 10, 64)
+}
+
 // ExtractAddressFromMessage extracts the Ethereum address from a SIWE message
 func ExtractAddressFromMessage(message string) (string, error) {
 	// Look for hex address pattern (0x followed by 40 hex chars)
@@ -163,3 +163,14 @@ func TestSIWEService_GenerateNonce_HighEntropy(t *testing.T) {
 
 	assert.Equal(t, 100, len(nonces))
 }
+
+func TestExtractChainIDFromMessage(t *testing.T) {
+	message := "example.com wants you to sign in with your Ethereum account:\n0x742d35cc6634c0532925a3b844bc9e7595f0beb0\n\nURI: https://example.com\nVersion: 1\nChain ID: 137\nNonce: abc"
+
+	chainID, err := ExtractChainIDFromMessage(message)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(137), chainID)
+
+	_, err = ExtractChainIDFromMessage("example.com wants you to sign in\nNonce: abc")
+	assert.Error(t, err)
+}
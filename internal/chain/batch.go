@@ -0,0 +1,96 @@
+package chain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// BatchRequest is one call of a JSON-RPC batch
+type BatchRequest struct {
+	Method string
+	Params []interface{}
+}
+
+// BatchCall makes the calls in a single JSON-RPC batch request, failing over as
+// Call does, and returns the response of each call in order. Each response is a
+// JSON-RPC response object like those Call returns, so a call failing with an RPC
+// error does not fail the others. Endpoints that do not support batches fail the
+// whole batch.
+func (p *Provider) BatchCall(ctx context.Context, requests []BatchRequest) ([][]byte, error) {
+	if len(requests) == 0 {
+		return nil, nil
+	}
+
+	batch := make([]jsonRPCRequest, len(requests))
+	for i, request := range requests {
+		params := request.Params
+		if params == nil {
+			params = []interface{}{}
+		}
+		batch[i] = jsonRPCRequest{
+			JSONRPC: "2.0",
+			Method:  request.Method,
+			Params:  params,
+			ID:      i + 1,
+		}
+	}
+	requestBody, err := json.Marshal(batch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON-RPC batch: %w", err)
+	}
+
+	// An endpoint answering with anything but the whole batch has failed, so the
+	// other endpoint is tried
+	response, err := p.send(ctx, func(ctx context.Context, url string) ([]byte, error) {
+		response, err := p.post(ctx, url, requestBody)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := splitBatchResponse(response, len(requests)); err != nil {
+			return nil, err
+		}
+		return response, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return splitBatchResponse(response, len(requests))
+}
+
+// splitBatchResponse returns the responses of a batch of size calls by request
+// ID, which servers may answer in any order
+func splitBatchResponse(body []byte, size int) ([][]byte, error) {
+	var elements []json.RawMessage
+	if err := json.Unmarshal(body, &elements); err != nil {
+		return nil, fmt.Errorf("RPC server did not answer the batch: %s", truncate(body, 200))
+	}
+
+	responses := make([][]byte, size)
+	for _, element := range elements {
+		var envelope struct {
+			ID int `json:"id"`
+		}
+		if err := json.Unmarshal(element, &envelope); err != nil {
+			return nil, fmt.Errorf("failed to parse batch response: %w", err)
+		}
+		if envelope.ID < 1 || envelope.ID > size || responses[envelope.ID-1] != nil {
+			return nil, fmt.Errorf("unexpected response ID %d in batch", envelope.ID)
+		}
+		responses[envelope.ID-1] = element
+	}
+	for i, response := range responses {
+		if response == nil {
+			return nil, fmt.Errorf("batch response is missing call %d", i+1)
+		}
+	}
+	return responses, nil
+}
+
+// truncate returns at most n bytes of body, for error messages
+func truncate(body []byte, n int) string {
+	if len(body) <= n {
+		return string(body)
+	}
+	return string(body[:n]) + "..."
+}
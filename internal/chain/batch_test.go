@@ -0,0 +1,233 @@
+package chain
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBatchServer answers JSON-RPC batches in reverse order with each call's method
+// as its result, failing calls of eth_fail, and counts the requests it gets
+func newBatchServer(t *testing.T, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		var batch []jsonRPCRequest
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			w.Write([]byte(`{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"batches not supported"}}`))
+			return
+		}
+		responses := make([]interface{}, 0, len(batch))
+		for i := len(batch) - 1; i >= 0; i-- {
+			call := batch[i]
+			if call.Method == "eth_fail" {
+				responses = append(responses, jsonRPCResponse{JSONRPC: "2.0", ID: call.ID, Error: &jsonRPCError{Code: -32000, Message: "failed"}})
+				continue
+			}
+			responses = append(responses, jsonRPCResponse{JSONRPC: "2.0", ID: call.ID, Result: call.Method})
+		}
+		json.NewEncoder(w).Encode(responses)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// resultOf returns the result of a JSON-RPC response
+func resultOf(t *testing.T, response []byte) interface{} {
+	t.Helper()
+	var decoded jsonRPCResponse
+	require.NoError(t, json.Unmarshal(response, &decoded))
+	if decoded.Error != nil {
+		return decoded.Error.Message
+	}
+	return decoded.Result
+}
+
+func TestProvider_BatchCall(t *testing.T) {
+	var requests atomic.Int32
+	provider := NewProvider(newBatchServer(t, &requests).URL, "")
+
+	responses, err := provider.BatchCall(context.Background(), []BatchRequest{
+		{Method: "eth_blockNumber"},
+		{Method: "eth_fail"},
+		{Method: "eth_chainId", Params: []interface{}{}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), requests.Load())
+	require.Len(t, responses, 3)
+	assert.Equal(t, "eth_blockNumber", resultOf(t, responses[0]))
+	assert.Equal(t, "failed", resultOf(t, responses[1]))
+	assert.Equal(t, "eth_chainId", resultOf(t, responses[2]))
+
+	responses, err = provider.BatchCall(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, responses)
+}
+
+// TestProvider_BatchCall_Unsupported fails over when the primary does not answer batches
+func TestProvider_BatchCall_Unsupported(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"batches not supported"}}`))
+	}))
+	defer primary.Close()
+
+	_, err := NewProvider(primary.URL, "").BatchCall(context.Background(), []BatchRequest{{Method: "eth_blockNumber"}})
+	assert.ErrorContains(t, err, "did not answer the batch")
+
+	var requests atomic.Int32
+	provider := NewProvider(primary.URL, newBatchServer(t, &requests).URL)
+	defer provider.Close()
+	responses, err := provider.BatchCall(context.Background(), []BatchRequest{{Method: "eth_blockNumber"}})
+	require.NoError(t, err)
+	assert.Equal(t, "eth_blockNumber", resultOf(t, responses[0]))
+	assert.True(t, provider.UsingFallback())
+}
+
+func TestSplitBatchResponse(t *testing.T) {
+	_, err := splitBatchResponse([]byte(`[{"id":1,"result":"0x1"}]`), 2)
+	assert.ErrorContains(t, err, "missing call 2")
+
+	_, err = splitBatchResponse([]byte(`[{"id":1,"result":"0x1"},{"id":1,"result":"0x1"}]`), 2)
+	assert.ErrorContains(t, err, "unexpected response ID 1")
+
+	_, err = splitBatchResponse([]byte(`[{"id":3,"result":"0x1"}]`), 1)
+	assert.Error(t, err)
+}
+
+// TestBatcher_CoalescesConcurrentCalls sends calls made within the window as one batch
+func TestBatcher_CoalescesConcurrentCalls(t *testing.T) {
+	var requests atomic.Int32
+	batcher := NewBatcher(NewProvider(newBatchServer(t, &requests).URL, ""), 50*time.Millisecond, 0)
+
+	methods := []string{"eth_blockNumber", "eth_chainId", "eth_fail", "eth_gasPrice"}
+	results := make([]interface{}, len(methods))
+	var wg sync.WaitGroup
+	for i, method := range methods {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := batcher.Call(context.Background(), method, nil)
+			assert.NoError(t, err)
+			results[i] = resultOf(t, response)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), requests.Load())
+	assert.Equal(t, []interface{}{"eth_blockNumber", "eth_chainId", "failed", "eth_gasPrice"}, results)
+}
+
+// TestBatcher_MaxSize sends a full batch without waiting for the window
+func TestBatcher_MaxSize(t *testing.T) {
+	var requests atomic.Int32
+	batcher := NewBatcher(NewProvider(newBatchServer(t, &requests).URL, ""), time.Hour, 2)
+
+	var wg sync.WaitGroup
+	for _, method := range []string{"eth_blockNumber", "eth_chainId"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := batcher.Call(context.Background(), method, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, method, resultOf(t, response))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+// TestBatcher_CancelledCall returns when the caller gives up
+func TestBatcher_CancelledCall(t *testing.T) {
+	var requests atomic.Int32
+	batcher := NewBatcher(NewProvider(newBatchServer(t, &requests).URL, ""), time.Hour, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := batcher.Call(ctx, "eth_blockNumber", nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// TestBatcher_Multicall aggregates plain eth_calls and batches the rest
+func TestBatcher_Multicall(t *testing.T) {
+	var requests atomic.Int32
+	batches := newBatchServer(t, &requests)
+	multicall := newMulticallServer(t, []Call3Result{
+		{Success: true, ReturnData: abiWord(1000)},
+		{Success: false},
+	}, nil)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var single jsonRPCRequest
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		if json.Unmarshal(body, &single) == nil && single.Method == "eth_call" {
+			forward(t, w, multicall.URL, body)
+			return
+		}
+		forward(t, w, batches.URL, body)
+	}))
+	defer server.Close()
+
+	batcher := NewBatcher(NewProvider(server.URL, ""), 200*time.Millisecond, 0)
+	batcher.SetMulticall(true)
+
+	call := func(contract string) []interface{} {
+		return []interface{}{map[string]interface{}{"to": contract, "data": "0x70a08231"}, "latest"}
+	}
+	type result struct {
+		index    int
+		response []byte
+	}
+	results := make(chan result, 4)
+	for i, params := range [][]interface{}{
+		call("0x1f9840a85d5af5bf1d1762f925bdaddc4201f984"),
+		call("0xbc4ca0eda7647a8ab7c2061c2e118a18a936f13d"),
+		nil,
+		{map[string]interface{}{"to": "0x1f9840a85d5af5bf1d1762f925bdaddc4201f984", "data": "0x70a08231"}, "0x10"},
+	} {
+		method := "eth_call"
+		if params == nil {
+			method = "eth_blockNumber"
+		}
+		go func() {
+			response, err := batcher.Call(context.Background(), method, params)
+			assert.NoError(t, err)
+			results <- result{index: i, response: response}
+		}()
+		// Queue the calls in order, so the aggregated ones get the results in order
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	responses := make([][]byte, 4)
+	for range responses {
+		r := <-results
+		responses[r.index] = r.response
+	}
+	assert.Equal(t, "0x"+hex.EncodeToString(abiWord(1000)), resultOf(t, responses[0]))
+	assert.Equal(t, "execution reverted", resultOf(t, responses[1]))
+	// The call at a pinned block and the other method went in one batch
+	assert.Equal(t, "eth_blockNumber", resultOf(t, responses[2]))
+	assert.Equal(t, "eth_call", resultOf(t, responses[3]))
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+// forward posts body to url and copies the response to w
+func forward(t *testing.T, w http.ResponseWriter, url string, body []byte) {
+	t.Helper()
+	response, err := NewProvider(url, "").post(context.Background(), url, body)
+	if err != nil {
+		http.Error(w, fmt.Sprint(err), http.StatusBadGateway)
+		return
+	}
+	w.Write(response)
+}
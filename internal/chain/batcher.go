@@ -0,0 +1,232 @@
+package chain
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Default batching limits
+const (
+	DefaultBatchMaxSize = 50
+	MaxBatchSize        = 1000
+)
+
+// Batcher coalesces calls made concurrently into single round-trips, so the
+// blockchain rules of a policy, which are evaluated concurrently, resolve their
+// reads together. Calls made within the batch window of the first are sent as one
+// JSON-RPC batch; with Multicall enabled, their plain eth_calls at the latest
+// block are aggregated into a single call to Multicall3 instead. A batch is sent
+// early once it holds the maximum number of calls.
+//
+// Batcher has the Call method of Provider, so it can stand in for it.
+type Batcher struct {
+	provider  *Provider
+	window    time.Duration
+	maxSize   int
+	multicall bool
+
+	mu      sync.Mutex
+	pending []*batchedCall
+	timer   *time.Timer
+}
+
+// batchedCall is a call waiting for its batch to be sent
+type batchedCall struct {
+	ctx    context.Context
+	method string
+	params []interface{}
+	done   chan batchedResult // Buffered, so callers that gave up do not block the batch
+}
+
+// batchedResult is the response of a batched call
+type batchedResult struct {
+	response []byte
+	err      error
+}
+
+// NewBatcher creates a batcher sending the calls made within window of each other
+// through provider, at most maxSize at a time. A zero maxSize uses
+// DefaultBatchMaxSize.
+func NewBatcher(provider *Provider, window time.Duration, maxSize int) *Batcher {
+	if maxSize <= 0 {
+		maxSize = DefaultBatchMaxSize
+	}
+	return &Batcher{
+		provider: provider,
+		window:   window,
+		maxSize:  maxSize,
+	}
+}
+
+// SetMulticall sets whether eth_calls are aggregated through Multicall3, which
+// must be deployed at Multicall3Address on the chain
+func (b *Batcher) SetMulticall(enabled bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.multicall = enabled
+}
+
+// Call queues a JSON-RPC call for the next batch and returns its response once
+// the batch has been answered, or when ctx is done
+func (b *Batcher) Call(ctx context.Context, method string, params []interface{}) ([]byte, error) {
+	call := &batchedCall{ctx: ctx, method: method, params: params, done: make(chan batchedResult, 1)}
+
+	b.mu.Lock()
+	b.pending = append(b.pending, call)
+	switch {
+	case len(b.pending) >= b.maxSize:
+		if b.timer != nil {
+			b.timer.Stop()
+			b.timer = nil
+		}
+		go b.send(b.take())
+	case len(b.pending) == 1:
+		b.timer = time.AfterFunc(b.window, b.flush)
+	}
+	b.mu.Unlock()
+
+	select {
+	case result := <-call.done:
+		return result.response, result.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// HealthCheck verifies the provider is healthy, unbatched
+func (b *Batcher) HealthCheck(ctx context.Context) bool {
+	return b.provider.HealthCheck(ctx)
+}
+
+// take removes the pending calls; b.mu must be held
+func (b *Batcher) take() []*batchedCall {
+	calls := b.pending
+	b.pending = nil
+	return calls
+}
+
+// flush sends the pending calls once the batch window has passed
+func (b *Batcher) flush() {
+	b.mu.Lock()
+	calls := b.take()
+	b.timer = nil
+	b.mu.Unlock()
+	b.send(calls)
+}
+
+// send sends a batch and delivers the responses. Calls whose callers have given
+// up are left out. The batch is sent without the deadline of any one caller, as
+// it answers all of them; the provider's timeout still applies.
+func (b *Batcher) send(calls []*batchedCall) {
+	live := calls[:0]
+	for _, call := range calls {
+		if call.ctx.Err() == nil {
+			live = append(live, call)
+		}
+	}
+	if len(live) == 0 {
+		return
+	}
+	ctx := context.Background()
+
+	b.mu.Lock()
+	multicall := b.multicall
+	b.mu.Unlock()
+	if multicall {
+		live = b.sendMulticall(ctx, live)
+	}
+
+	switch len(live) {
+	case 0:
+		return
+	case 1:
+		response, err := b.provider.Call(ctx, live[0].method, live[0].params)
+		live[0].done <- batchedResult{response: response, err: err}
+		return
+	}
+
+	requests := make([]BatchRequest, len(live))
+	for i, call := range live {
+		requests[i] = BatchRequest{Method: call.method, Params: call.params}
+	}
+	responses, err := b.provider.BatchCall(ctx, requests)
+	for i, call := range live {
+		if err != nil {
+			call.done <- batchedResult{err: err}
+			continue
+		}
+		call.done <- batchedResult{response: responses[i]}
+	}
+}
+
+// sendMulticall aggregates the plain eth_calls among calls through Multicall3 and
+// delivers their responses, returning the calls left to send. If there are fewer
+// than two such calls, or the aggregated call fails, they are all left to send.
+func (b *Batcher) sendMulticall(ctx context.Context, calls []*batchedCall) []*batchedCall {
+	var aggregated, rest []*batchedCall
+	var call3s []Call3
+	for _, call := range calls {
+		if call3, ok := asCall3(call); ok {
+			aggregated = append(aggregated, call)
+			call3s = append(call3s, call3)
+			continue
+		}
+		rest = append(rest, call)
+	}
+	if len(aggregated) < 2 {
+		return calls
+	}
+
+	results, err := b.provider.Multicall(ctx, call3s)
+	if err != nil {
+		b.provider.logger.Warn("multicall failed, sending the calls in a batch", zap.Error(err))
+		return calls
+	}
+	for i, call := range aggregated {
+		call.done <- batchedResult{response: call3Response(results[i])}
+	}
+	return rest
+}
+
+// asCall3 returns a call as a Multicall3 call if it is an eth_call at the latest
+// block with nothing but a target and call data, which Multicall3 makes alike
+func asCall3(call *batchedCall) (Call3, bool) {
+	if call.method != "eth_call" || len(call.params) != 2 || call.params[1] != "latest" {
+		return Call3{}, false
+	}
+	object, ok := call.params[0].(map[string]interface{})
+	if !ok || len(object) != 2 {
+		return Call3{}, false
+	}
+	to, ok := object["to"].(string)
+	if !ok {
+		return Call3{}, false
+	}
+	data, ok := object["data"].(string)
+	if !ok {
+		return Call3{}, false
+	}
+	callData, err := hex.DecodeString(strings.TrimPrefix(data, "0x"))
+	if err != nil || len(to) != 42 {
+		return Call3{}, false
+	}
+	return Call3{Target: to, CallData: callData}, true
+}
+
+// call3Response is the JSON-RPC response an eth_call with the result would have got
+func call3Response(result Call3Result) []byte {
+	response := jsonRPCResponse{JSONRPC: "2.0", ID: 1}
+	if result.Success {
+		response.Result = "0x" + hex.EncodeToString(result.ReturnData)
+	} else {
+		response.Error = &jsonRPCError{Code: 3, Message: "execution reverted"}
+	}
+	body, _ := json.Marshal(response)
+	return body
+}
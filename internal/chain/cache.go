@@ -44,6 +44,22 @@ func (c *Cache) Set(key string, value interface{}) {
 	}
 }
 
+// SetWithTTL stores a value in the cache expiring after ttl instead of the cache's
+// TTL. A ttl of zero or less uses the cache's TTL.
+func (c *Cache) SetWithTTL(key string, value interface{}, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = c.ttl
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.data[key] = &CacheEntry{
+		Value:     value,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+}
+
 // Get retrieves a value from cache, returns false if not found or expired
 func (c *Cache) Get(key string) (interface{}, bool) {
 	c.mu.RLock()
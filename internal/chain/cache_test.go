@@ -75,6 +75,20 @@ func TestCache_Expiration_ItemExpires(t *testing.T) {
 	assert.False(t, ok)
 }
 
+// TestCache_SetWithTTL expires an item after its own TTL
+func TestCache_SetWithTTL(t *testing.T) {
+	cache := NewCache(5 * time.Minute)
+	cache.SetWithTTL("short", "value1", 10*time.Millisecond)
+	cache.SetWithTTL("default", "value2", 0)
+
+	time.Sleep(20 * time.Millisecond)
+
+	_, ok := cache.Get("short")
+	assert.False(t, ok)
+	_, ok = cache.Get("default")
+	assert.True(t, ok, "a zero TTL uses the cache's TTL")
+}
+
 // TestCache_MultipleKeys stores multiple values
 func TestCache_MultipleKeys(t *testing.T) {
 	cache := NewCache(5 * time.Minute)
@@ -0,0 +1,80 @@
+package chain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EndpointCheck is the result of checking one configured RPC endpoint
+type EndpointCheck struct {
+	Role    string        `json:"role"` // "primary" or "fallback"
+	Host    string        `json:"host"` // Host only; RPC URLs often embed API keys
+	ChainID uint64        `json:"chainId,omitempty"`
+	Latency time.Duration `json:"-"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// CheckEndpoints asks every configured endpoint for its chain ID, bypassing
+// failover and hedging so each endpoint is measured on its own
+func (p *Provider) CheckEndpoints(ctx context.Context) []EndpointCheck {
+	endpoints := []struct{ role, url string }{{"primary", p.primaryURL}}
+	if p.fallbackURL != "" {
+		endpoints = append(endpoints, struct{ role, url string }{"fallback", p.fallbackURL})
+	}
+
+	checks := make([]EndpointCheck, len(endpoints))
+	for i, endpoint := range endpoints {
+		check := EndpointCheck{Role: endpoint.role, Host: endpointHost(endpoint.url)}
+
+		start := time.Now()
+		chainID, err := p.chainID(ctx, endpoint.url)
+		check.Latency = time.Since(start)
+		if err != nil {
+			check.Error = err.Error()
+		} else {
+			check.ChainID = chainID
+		}
+		checks[i] = check
+	}
+	return checks
+}
+
+// chainID calls eth_chainId on a specific provider URL
+func (p *Provider) chainID(ctx context.Context, url string) (uint64, error) {
+	response, err := p.callProvider(ctx, url, "eth_chainId", []interface{}{})
+	if err != nil {
+		return 0, err
+	}
+
+	var rpcResp struct {
+		Result string        `json:"result"`
+		Error  *jsonRPCError `json:"error"`
+	}
+	if err := json.Unmarshal(response, &rpcResp); err != nil {
+		return 0, fmt.Errorf("failed to parse RPC response: %w", err)
+	}
+	if rpcResp.Error != nil {
+		return 0, fmt.Errorf("RPC error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
+	}
+
+	chainID, err := strconv.ParseUint(strings.TrimPrefix(rpcResp.Result, "0x"), 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse chain ID: %s", rpcResp.Result)
+	}
+	return chainID, nil
+}
+
+// endpointHost returns the host of an endpoint URL, leaving out credentials,
+// paths and queries that may carry API keys
+func endpointHost(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return "invalid URL"
+	}
+	return u.Host
+}
@@ -0,0 +1,49 @@
+package chain
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestProvider_CheckEndpoints reports the chain ID of each endpoint without credentials
+func TestProvider_CheckEndpoints(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","result":"0xaa36a7","id":1}`))
+	}))
+	defer primary.Close()
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","error":{"code":-32000,"message":"rate limited"},"id":1}`))
+	}))
+	defer fallback.Close()
+
+	provider := NewProvider(primary.URL+"/v3/secret-key", fallback.URL)
+	defer provider.Close()
+
+	checks := provider.CheckEndpoints(context.Background())
+
+	require.Len(t, checks, 2)
+	assert.Equal(t, "primary", checks[0].Role)
+	assert.Equal(t, uint64(11155111), checks[0].ChainID)
+	assert.Empty(t, checks[0].Error)
+	assert.NotContains(t, checks[0].Host, "secret-key")
+	assert.Equal(t, "fallback", checks[1].Role)
+	assert.Zero(t, checks[1].ChainID)
+	assert.Contains(t, checks[1].Error, "rate limited")
+}
+
+// TestProvider_CheckEndpoints_WithoutFallback checks only the primary
+func TestProvider_CheckEndpoints_WithoutFallback(t *testing.T) {
+	provider := NewProvider("http://127.0.0.1:1", "")
+	defer provider.Close()
+
+	checks := provider.CheckEndpoints(context.Background())
+
+	require.Len(t, checks, 1)
+	assert.Equal(t, "127.0.0.1:1", checks[0].Host)
+	assert.NotEmpty(t, checks[0].Error)
+}
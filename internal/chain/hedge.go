@@ -0,0 +1,154 @@
+package chain
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// hedgeWindow is how many recent primary latencies the hedge threshold is computed from
+	hedgeWindow = 256
+
+	// hedgeMinSamples is how many latencies must be observed before calls are hedged
+	hedgeMinSamples = 20
+)
+
+// latencyTracker keeps a sliding window of primary call latencies
+type latencyTracker struct {
+	mu      sync.Mutex
+	samples [hedgeWindow]time.Duration
+	next    int
+	count   int
+}
+
+// record adds a latency to the window, replacing the oldest once full
+func (t *latencyTracker) record(latency time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.samples[t.next] = latency
+	t.next = (t.next + 1) % hedgeWindow
+	if t.count < hedgeWindow {
+		t.count++
+	}
+}
+
+// percentile returns the latency below which the given percentage of the window
+// falls, or false until enough latencies have been recorded
+func (t *latencyTracker) percentile(percent float64) (time.Duration, bool) {
+	t.mu.Lock()
+	if t.count < hedgeMinSamples {
+		t.mu.Unlock()
+		return 0, false
+	}
+	sorted := make([]time.Duration, t.count)
+	copy(sorted, t.samples[:t.count])
+	t.mu.Unlock()
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	index := int(percent / 100 * float64(len(sorted)))
+	if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+	return sorted[index], true
+}
+
+// SetHedging enables hedged requests: when the primary has not answered within
+// the given percentile of its recent latencies (but at least minDelay), the
+// same call is sent to the fallback and the first successful response wins.
+// A percentile of 0 disables hedging.
+func (p *Provider) SetHedging(percentile float64, minDelay time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.hedgePercentile = percentile
+	p.hedgeMinDelay = minDelay
+}
+
+// hedgeDelay returns how long to wait for the primary before hedging, or
+// false if calls are not hedged
+func (p *Provider) hedgeDelay() (time.Duration, bool) {
+	p.mu.Lock()
+	percentile, minDelay := p.hedgePercentile, p.hedgeMinDelay
+	p.mu.Unlock()
+
+	if percentile <= 0 || p.fallbackURL == "" {
+		return 0, false
+	}
+	delay, ok := p.latencies.percentile(percentile)
+	if !ok {
+		return 0, false
+	}
+	if delay < minDelay {
+		delay = minDelay
+	}
+	return delay, true
+}
+
+// rpcResult is the outcome of one leg of a hedged call
+type rpcResult struct {
+	response []byte
+	err      error
+	primary  bool
+}
+
+// hedgedCall calls the primary and, if it is still pending after delay or
+// fails, the fallback, returning the first successful response. The slower
+// call is cancelled. Only a primary error fails over; a primary that is
+// merely slow stays preferred.
+func (p *Provider) hedgedCall(ctx context.Context, delay time.Duration, request rpcRequest) ([]byte, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan rpcResult, 2)
+	call := func(url string, primary bool) {
+		response, err := request(ctx, url)
+		results <- rpcResult{response: response, err: err, primary: primary}
+	}
+
+	start := time.Now()
+	go call(p.primaryURL, true)
+	pending := 1
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	hedged := false
+	var primaryErr error
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if !hedged {
+				hedged = true
+				pending++
+				go call(p.fallbackURL, false)
+			}
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				if primaryErr != nil {
+					p.failOver(primaryErr)
+				} else {
+					// When the fallback wins, this is a lower bound of the primary latency
+					p.latencies.record(time.Since(start))
+				}
+				return result.response, nil
+			}
+			if ctx.Err() != nil {
+				return nil, result.err
+			}
+			if result.primary {
+				primaryErr = result.err
+				if !hedged {
+					hedged = true
+					pending++
+					go call(p.fallbackURL, false)
+				}
+			}
+		}
+	}
+
+	// Both failed: report the primary's error, as an unhedged call would
+	return nil, primaryErr
+}
@@ -0,0 +1,168 @@
+package chain
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Multicall3Address is where Multicall3 is deployed, the same address on
+// mainnet, the major L2s and their testnets
+const Multicall3Address = "0xcA11bde05977b3631167028862bE2a173976CA11"
+
+// aggregate3Selector is the selector of aggregate3((address,bool,bytes)[])
+const aggregate3Selector = "82ad56cb"
+
+// Call3 is one contract call aggregated by Multicall
+type Call3 struct {
+	Target   string // Contract address
+	CallData []byte // ABI-encoded call, selector included
+}
+
+// Call3Result is the outcome of one aggregated call
+type Call3Result struct {
+	Success    bool
+	ReturnData []byte // The revert data of failed calls
+}
+
+// Multicall makes the contract calls in a single eth_call to Multicall3's
+// aggregate3 at the latest block, and returns their results in order. A call that
+// reverts does not fail the others. Chains without Multicall3 fail the whole call.
+func (p *Provider) Multicall(ctx context.Context, calls []Call3) ([]Call3Result, error) {
+	if len(calls) == 0 {
+		return nil, nil
+	}
+
+	data, err := encodeAggregate3(calls)
+	if err != nil {
+		return nil, err
+	}
+	response, err := p.Call(ctx, "eth_call", []interface{}{
+		map[string]interface{}{
+			"to":   Multicall3Address,
+			"data": "0x" + hex.EncodeToString(data),
+		},
+		"latest",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to call aggregate3: %w", err)
+	}
+
+	var rpcResp jsonRPCResponse
+	if err := json.Unmarshal(response, &rpcResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if rpcResp.Error != nil {
+		return nil, fmt.Errorf("RPC error: %s", rpcResp.Error.Message)
+	}
+	result, _ := rpcResp.Result.(string)
+	returned, err := hex.DecodeString(strings.TrimPrefix(result, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse aggregate3 result: %w", err)
+	}
+	return decodeAggregate3(returned, len(calls))
+}
+
+// encodeAggregate3 ABI-encodes a call of aggregate3 allowing every call to fail
+func encodeAggregate3(calls []Call3) ([]byte, error) {
+	selector, _ := hex.DecodeString(aggregate3Selector)
+	data := append(selector, abiWord(32)...) // Offset of the array
+	data = append(data, abiWord(uint64(len(calls)))...)
+
+	// Each (address, bool, bytes) tuple is dynamic, so the array starts with their offsets
+	tuples := make([][]byte, len(calls))
+	offset := uint64(32 * len(calls))
+	for i, call := range calls {
+		target, err := hex.DecodeString(strings.TrimPrefix(call.Target, "0x"))
+		if err != nil || len(target) != 20 {
+			return nil, fmt.Errorf("invalid call target %q", call.Target)
+		}
+		tuple := append(make([]byte, 12), target...)
+		tuple = append(tuple, abiWord(1)...)  // allowFailure
+		tuple = append(tuple, abiWord(96)...) // Offset of callData in the tuple
+		tuple = append(tuple, abiWord(uint64(len(call.CallData)))...)
+		tuple = append(tuple, call.CallData...)
+		tuple = append(tuple, make([]byte, (32-len(call.CallData)%32)%32)...)
+		tuples[i] = tuple
+
+		data = append(data, abiWord(offset)...)
+		offset += uint64(len(tuple))
+	}
+	for _, tuple := range tuples {
+		data = append(data, tuple...)
+	}
+	return data, nil
+}
+
+// decodeAggregate3 decodes the (bool success, bytes returnData)[] aggregate3
+// returns, which must hold size results
+func decodeAggregate3(data []byte, size int) ([]Call3Result, error) {
+	array, err := abiOffset(data, 0)
+	if err != nil {
+		return nil, err
+	}
+	length, err := abiOffset(data, array)
+	if err != nil {
+		return nil, err
+	}
+	if length != size {
+		return nil, fmt.Errorf("aggregate3 returned %d results for %d calls", length, size)
+	}
+
+	base := array + 32
+	results := make([]Call3Result, size)
+	for i := range results {
+		tupleOffset, err := abiOffset(data, base+32*i)
+		if err != nil {
+			return nil, err
+		}
+		tuple := base + tupleOffset
+		success, err := abiOffset(data, tuple)
+		if err != nil {
+			return nil, err
+		}
+		bytesOffset, err := abiOffset(data, tuple+32)
+		if err != nil {
+			return nil, err
+		}
+		start := tuple + bytesOffset
+		bytesLength, err := abiOffset(data, start)
+		if err != nil {
+			return nil, err
+		}
+		if start+32+bytesLength > len(data) {
+			return nil, fmt.Errorf("aggregate3 result %d is out of range", i)
+		}
+		returnData := make([]byte, bytesLength)
+		copy(returnData, data[start+32:])
+		results[i] = Call3Result{Success: success != 0, ReturnData: returnData}
+	}
+	return results, nil
+}
+
+// abiWord encodes an unsigned integer as a 32-byte ABI word
+func abiWord(value uint64) []byte {
+	word := make([]byte, 32)
+	binary.BigEndian.PutUint64(word[24:], value)
+	return word
+}
+
+// abiOffset reads the word at pos as an offset or length, which must be within data
+func abiOffset(data []byte, pos int) (int, error) {
+	if pos < 0 || pos+32 > len(data) {
+		return 0, fmt.Errorf("ABI data too short: %d bytes", len(data))
+	}
+	for _, b := range data[pos : pos+24] {
+		if b != 0 {
+			return 0, fmt.Errorf("ABI offset at %d is out of range", pos)
+		}
+	}
+	value := binary.BigEndian.Uint64(data[pos+24 : pos+32])
+	if value > uint64(len(data)) {
+		return 0, fmt.Errorf("ABI offset at %d is out of range", pos)
+	}
+	return int(value), nil
+}
@@ -0,0 +1,122 @@
+package chain
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encodeAggregate3Results ABI-encodes what aggregate3 returns for results
+func encodeAggregate3Results(results []Call3Result) []byte {
+	data := append(abiWord(32), abiWord(uint64(len(results)))...)
+	tuples := []byte{}
+	offset := uint64(32 * len(results))
+	for _, result := range results {
+		success := uint64(0)
+		if result.Success {
+			success = 1
+		}
+		tuple := append(abiWord(success), abiWord(64)...)
+		tuple = append(tuple, abiWord(uint64(len(result.ReturnData)))...)
+		tuple = append(tuple, result.ReturnData...)
+		tuple = append(tuple, make([]byte, (32-len(result.ReturnData)%32)%32)...)
+		data = append(data, abiWord(offset)...)
+		offset += uint64(len(tuple))
+		tuples = append(tuples, tuple...)
+	}
+	return append(data, tuples...)
+}
+
+// newMulticallServer answers aggregate3 calls with results, recording the call data
+func newMulticallServer(t *testing.T, results []Call3Result, calldata *string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Method string        `json:"method"`
+			Params []interface{} `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		require.Equal(t, "eth_call", request.Method)
+		object := request.Params[0].(map[string]interface{})
+		assert.Equal(t, Multicall3Address, object["to"])
+		if calldata != nil {
+			*calldata = object["data"].(string)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"result":  "0x" + hex.EncodeToString(encodeAggregate3Results(results)),
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestEncodeAggregate3(t *testing.T) {
+	data, err := encodeAggregate3([]Call3{
+		{Target: "0x1f9840a85d5af5bf1d1762f925bdaddc4201f984", CallData: []byte{0x31, 0x3c, 0xe5, 0x67}},
+	})
+	require.NoError(t, err)
+
+	words := strings.Join([]string{
+		"0000000000000000000000000000000000000000000000000000000000000020", // Offset of the array
+		"0000000000000000000000000000000000000000000000000000000000000001", // Length
+		"0000000000000000000000000000000000000000000000000000000000000020", // Offset of the tuple
+		"0000000000000000000000001f9840a85d5af5bf1d1762f925bdaddc4201f984", // target
+		"0000000000000000000000000000000000000000000000000000000000000001", // allowFailure
+		"0000000000000000000000000000000000000000000000000000000000000060", // Offset of callData
+		"0000000000000000000000000000000000000000000000000000000000000004", // Length of callData
+		"313ce56700000000000000000000000000000000000000000000000000000000",
+	}, "")
+	assert.Equal(t, aggregate3Selector+words, hex.EncodeToString(data))
+
+	_, err = encodeAggregate3([]Call3{{Target: "0x1234"}})
+	assert.Error(t, err)
+}
+
+func TestDecodeAggregate3(t *testing.T) {
+	want := []Call3Result{
+		{Success: true, ReturnData: abiWord(1000)},
+		{Success: false, ReturnData: []byte{0x08, 0xc3, 0x79, 0xa0}},
+		{Success: true, ReturnData: []byte{}},
+	}
+	results, err := decodeAggregate3(encodeAggregate3Results(want), 3)
+	require.NoError(t, err)
+	assert.Equal(t, want, results)
+
+	_, err = decodeAggregate3(encodeAggregate3Results(want), 2)
+	assert.ErrorContains(t, err, "3 results for 2 calls")
+
+	// Truncated or empty data, as returned by a chain without Multicall3
+	_, err = decodeAggregate3(encodeAggregate3Results(want)[:100], 3)
+	assert.Error(t, err)
+	_, err = decodeAggregate3(nil, 3)
+	assert.Error(t, err)
+}
+
+func TestProvider_Multicall(t *testing.T) {
+	var calldata string
+	server := newMulticallServer(t, []Call3Result{
+		{Success: true, ReturnData: abiWord(42)},
+		{Success: false},
+	}, &calldata)
+	provider := NewProvider(server.URL, "")
+
+	results, err := provider.Multicall(context.Background(), []Call3{
+		{Target: "0x1f9840a85d5af5bf1d1762f925bdaddc4201f984", CallData: []byte{0x70, 0xa0, 0x82, 0x31}},
+		{Target: "0xbc4ca0eda7647a8ab7c2061c2e118a18a936f13d", CallData: []byte{0x63, 0x52, 0x21, 0x1e}},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.True(t, results[0].Success)
+	assert.Equal(t, abiWord(42), results[0].ReturnData)
+	assert.False(t, results[1].Success)
+	assert.True(t, strings.HasPrefix(calldata, "0x"+aggregate3Selector))
+}
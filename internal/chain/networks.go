@@ -0,0 +1,77 @@
+package chain
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Network describes a chain gatekeeper may gate on
+type Network struct {
+	ChainID  uint64   `json:"chainId"`
+	Name     string   `json:"name"`               // Short lowercase name, e.g. "sepolia"
+	Testnet  bool     `json:"testnet"`            // Assets are free test tokens
+	Currency string   `json:"currency,omitempty"` // Symbol of the native currency
+	Explorer string   `json:"explorer,omitempty"` // Block explorer base URL
+	Faucets  []string `json:"faucets,omitempty"`  // Where test tokens can be requested; testnets only
+}
+
+// networks is the registry of well-known chains by chain ID
+var networks = map[uint64]Network{
+	1:        {ChainID: 1, Name: "mainnet", Currency: "ETH", Explorer: "https://etherscan.io"},
+	10:       {ChainID: 10, Name: "optimism", Currency: "ETH", Explorer: "https://optimistic.etherscan.io"},
+	137:      {ChainID: 137, Name: "polygon", Currency: "POL", Explorer: "https://polygonscan.com"},
+	8453:     {ChainID: 8453, Name: "base", Currency: "ETH", Explorer: "https://basescan.org"},
+	42161:    {ChainID: 42161, Name: "arbitrum", Currency: "ETH", Explorer: "https://arbiscan.io"},
+	11155111: {ChainID: 11155111, Name: "sepolia", Testnet: true, Currency: "ETH", Explorer: "https://sepolia.etherscan.io", Faucets: []string{"https://cloud.google.com/application/web3/faucet/ethereum/sepolia", "https://faucets.chain.link/sepolia"}},
+	17000:    {ChainID: 17000, Name: "holesky", Testnet: true, Currency: "ETH", Explorer: "https://holesky.etherscan.io", Faucets: []string{"https://cloud.google.com/application/web3/faucet/ethereum/holesky"}},
+	560048:   {ChainID: 560048, Name: "hoodi", Testnet: true, Currency: "ETH", Explorer: "https://hoodi.etherscan.io", Faucets: []string{"https://hoodi-faucet.pk910.de"}},
+	11155420: {ChainID: 11155420, Name: "optimism-sepolia", Testnet: true, Currency: "ETH", Explorer: "https://sepolia-optimism.etherscan.io", Faucets: []string{"https://console.optimism.io/faucet"}},
+	80002:    {ChainID: 80002, Name: "polygon-amoy", Testnet: true, Currency: "POL", Explorer: "https://amoy.polygonscan.com", Faucets: []string{"https://faucet.polygon.technology"}},
+	84532:    {ChainID: 84532, Name: "base-sepolia", Testnet: true, Currency: "ETH", Explorer: "https://sepolia.basescan.org", Faucets: []string{"https://docs.base.org/chain/network-faucets"}},
+	421614:   {ChainID: 421614, Name: "arbitrum-sepolia", Testnet: true, Currency: "ETH", Explorer: "https://sepolia.arbiscan.io", Faucets: []string{"https://faucets.chain.link/arbitrum-sepolia"}},
+	31337:    {ChainID: 31337, Name: "localhost", Testnet: true, Currency: "ETH"},
+}
+
+// LookupNetwork returns the registered network of a chain ID
+func LookupNetwork(chainID uint64) (Network, bool) {
+	network, ok := networks[chainID]
+	if ok {
+		network.Faucets = append([]string(nil), network.Faucets...)
+	}
+	return network, ok
+}
+
+// NetworkFor returns the registered network of a chain ID, or a network named
+// "chain-<id>" that is not a testnet if the chain is unknown
+func NetworkFor(chainID uint64) Network {
+	if network, ok := LookupNetwork(chainID); ok {
+		return network
+	}
+	return Network{ChainID: chainID, Name: "chain-" + strconv.FormatUint(chainID, 10)}
+}
+
+// IsTestnet reports whether a chain ID is a registered testnet
+func IsTestnet(chainID uint64) bool {
+	return networks[chainID].Testnet
+}
+
+// Networks returns the registered networks, sorted by chain ID
+func Networks() []Network {
+	list := make([]Network, 0, len(networks))
+	for id := range networks {
+		network, _ := LookupNetwork(id)
+		list = append(list, network)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ChainID < list[j].ChainID })
+	return list
+}
+
+// AddressURL links to an address on the network's block explorer, or returns ""
+// if the network has none
+func (n Network) AddressURL(address string) string {
+	if n.Explorer == "" {
+		return ""
+	}
+	return strings.TrimSuffix(n.Explorer, "/") + "/address/" + address
+}
@@ -0,0 +1,58 @@
+package chain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupNetwork(t *testing.T) {
+	sepolia, ok := LookupNetwork(11155111)
+	require.True(t, ok)
+	assert.Equal(t, "sepolia", sepolia.Name)
+	assert.True(t, sepolia.Testnet)
+	assert.NotEmpty(t, sepolia.Faucets)
+	assert.Equal(t, "https://sepolia.etherscan.io/address/0xabc", sepolia.AddressURL("0xabc"))
+
+	mainnet, ok := LookupNetwork(1)
+	require.True(t, ok)
+	assert.False(t, mainnet.Testnet)
+	assert.Empty(t, mainnet.Faucets)
+
+	_, ok = LookupNetwork(999999)
+	assert.False(t, ok)
+
+	// Callers cannot change the registry through the faucets they get
+	sepolia.Faucets[0] = "https://evil.example.com"
+	again, _ := LookupNetwork(11155111)
+	assert.NotEqual(t, "https://evil.example.com", again.Faucets[0])
+}
+
+func TestNetworkFor(t *testing.T) {
+	assert.Equal(t, "holesky", NetworkFor(17000).Name)
+
+	unknown := NetworkFor(999999)
+	assert.Equal(t, Network{ChainID: 999999, Name: "chain-999999"}, unknown)
+	assert.Empty(t, unknown.AddressURL("0xabc"))
+}
+
+func TestIsTestnet(t *testing.T) {
+	assert.True(t, IsTestnet(11155111))
+	assert.True(t, IsTestnet(31337))
+	assert.False(t, IsTestnet(1))
+	assert.False(t, IsTestnet(999999))
+}
+
+func TestNetworks(t *testing.T) {
+	list := Networks()
+	require.NotEmpty(t, list)
+	assert.Equal(t, uint64(1), list[0].ChainID)
+	for i := 1; i < len(list); i++ {
+		assert.Less(t, list[i-1].ChainID, list[i].ChainID)
+		if list[i].Testnet {
+			continue
+		}
+		assert.Empty(t, list[i].Faucets, list[i].Name)
+	}
+}
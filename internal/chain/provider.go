@@ -7,15 +7,36 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
+
+	"go.uber.org/zap"
 )
 
-// Provider manages Ethereum RPC connections with primary and fallback support
+// defaultProbeInterval is how often a failed primary is probed for recovery
+const defaultProbeInterval = 10 * time.Second
+
+// Provider manages Ethereum RPC connections with primary and fallback support.
+// Once the primary fails and the fallback answers, calls stick to the fallback
+// while the primary is probed in the background; calls fail back to the
+// primary as soon as a probe succeeds.
 type Provider struct {
 	primaryURL  string
 	fallbackURL string
 	client      *http.Client
 	timeout     time.Duration
+
+	mu            sync.Mutex
+	onFallback    bool // Calls prefer the fallback until the primary recovers
+	probing       bool // A background probe of the primary is running
+	probeInterval time.Duration
+	done          chan struct{}
+	closeOnce     sync.Once
+	logger        *zap.Logger
+
+	hedgePercentile float64       // Primary latency percentile after which calls are hedged (0 disables)
+	hedgeMinDelay   time.Duration // Never hedge sooner than this
+	latencies       latencyTracker
 }
 
 // jsonRPCRequest represents a JSON-RPC 2.0 request
@@ -52,31 +73,149 @@ func NewProvider(primaryURL string, fallbackURL string) *Provider {
 	}
 
 	return &Provider{
-		primaryURL:  primaryURL,
-		fallbackURL: fallbackURL,
-		client:      client,
-		timeout:     5 * time.Second,
+		primaryURL:    primaryURL,
+		fallbackURL:   fallbackURL,
+		client:        client,
+		timeout:       5 * time.Second,
+		probeInterval: defaultProbeInterval,
+		done:          make(chan struct{}),
+		logger:        zap.NewNop(),
 	}
 }
 
-// Call makes a JSON-RPC call to the primary provider, with fallback support
+// rpcRequest sends one request, a single call or a batch, to an endpoint URL
+type rpcRequest func(ctx context.Context, url string) ([]byte, error)
+
+// Call makes a JSON-RPC call to the healthy provider: the primary, or the
+// fallback while the primary is down. The other endpoint is tried if the
+// preferred one fails, or with hedging enabled, if the primary is slow.
 func (p *Provider) Call(ctx context.Context, method string, params []interface{}) ([]byte, error) {
+	return p.send(ctx, func(ctx context.Context, url string) ([]byte, error) {
+		return p.callProvider(ctx, url, method, params)
+	})
+}
+
+// send sends a request to the healthy provider, failing over as Call does
+func (p *Provider) send(ctx context.Context, request rpcRequest) ([]byte, error) {
+	if p.fallbackURL == "" {
+		return request(ctx, p.primaryURL)
+	}
+
+	if p.UsingFallback() {
+		response, err := request(ctx, p.fallbackURL)
+		if err == nil || ctx.Err() != nil {
+			return response, err
+		}
+
+		// The fallback is failing too; the primary may have recovered before the next probe
+		response, primaryErr := request(ctx, p.primaryURL)
+		if primaryErr != nil {
+			return nil, err
+		}
+		p.failBack()
+		return response, nil
+	}
+
+	if delay, ok := p.hedgeDelay(); ok {
+		return p.hedgedCall(ctx, delay, request)
+	}
+
 	// Try primary provider
-	response, err := p.callProvider(ctx, p.primaryURL, method, params)
+	start := time.Now()
+	response, err := request(ctx, p.primaryURL)
 	if err == nil {
+		p.latencies.record(time.Since(start))
 		return response, nil
 	}
+	if ctx.Err() != nil {
+		// A cancelled caller says nothing about the primary's health
+		return response, err
+	}
 
-	// If primary failed and we have a fallback, try it
-	if p.fallbackURL != "" {
-		response, fallbackErr := p.callProvider(ctx, p.fallbackURL, method, params)
-		if fallbackErr == nil {
-			return response, nil
-		}
+	// If primary failed, try the fallback and stick to it if it answers
+	response, fallbackErr := request(ctx, p.fallbackURL)
+	if fallbackErr != nil {
 		// If fallback also failed, return the original error from primary
+		return nil, err
 	}
+	p.failOver(err)
+	return response, nil
+}
 
-	return nil, err
+// UsingFallback reports whether calls currently go to the fallback first
+func (p *Provider) UsingFallback() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.onFallback
+}
+
+// failOver switches calls to the fallback and starts probing the primary
+func (p *Provider) failOver(cause error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.onFallback {
+		p.onFallback = true
+		p.logger.Warn("primary RPC provider failed, switching to fallback", zap.Error(cause))
+	}
+	if !p.probing {
+		p.probing = true
+		go p.probePrimary()
+	}
+}
+
+// failBack switches calls back to the primary
+func (p *Provider) failBack() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.onFallback {
+		p.onFallback = false
+		p.logger.Info("primary RPC provider recovered, switching back from fallback")
+	}
+}
+
+// probePrimary checks the primary every probe interval until it answers, a
+// call fails back first, or the provider is closed
+func (p *Provider) probePrimary() {
+	p.mu.Lock()
+	interval := p.probeInterval
+	p.mu.Unlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			p.stopProbing(false)
+			return
+		case <-ticker.C:
+		}
+
+		if !p.UsingFallback() {
+			p.stopProbing(false)
+			return
+		}
+		if _, err := p.callProvider(context.Background(), p.primaryURL, "eth_blockNumber", []interface{}{}); err == nil {
+			p.stopProbing(true)
+			return
+		}
+	}
+}
+
+// stopProbing ends the background probe, failing back to the primary if it
+// recovered. Both happen under one lock so a concurrent failover always
+// starts a new probe.
+func (p *Provider) stopProbing(recovered bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.probing = false
+	if recovered && p.onFallback {
+		p.onFallback = false
+		p.logger.Info("primary RPC provider recovered, switching back from fallback")
+	}
 }
 
 // callProvider makes a JSON-RPC call to a specific provider URL
@@ -94,7 +233,11 @@ func (p *Provider) callProvider(ctx context.Context, url string, method string,
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal JSON-RPC request: %w", err)
 	}
+	return p.post(ctx, url, requestBody)
+}
 
+// post sends a JSON-RPC request body to a specific provider URL
+func (p *Provider) post(ctx context.Context, url string, requestBody []byte) ([]byte, error) {
 	// Create HTTP request with context and timeout
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(requestBody))
 	if err != nil {
@@ -136,6 +279,19 @@ func (p *Provider) SetTimeout(timeout time.Duration) {
 	p.client.Timeout = timeout
 }
 
+// SetProbeInterval sets how often a failed primary is probed for recovery.
+// Probes already running keep their interval.
+func (p *Provider) SetProbeInterval(interval time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.probeInterval = interval
+}
+
+// SetLogger sets the logger used to report failover and failback
+func (p *Provider) SetLogger(logger *zap.Logger) {
+	p.logger = logger
+}
+
 // HealthCheck verifies the provider is healthy by calling eth_blockNumber
 func (p *Provider) HealthCheck(ctx context.Context) bool {
 	_, err := p.Call(ctx, "eth_blockNumber", []interface{}{})
@@ -144,6 +300,7 @@ func (p *Provider) HealthCheck(ctx context.Context) bool {
 
 // Close closes the provider and its connections
 func (p *Provider) Close() error {
+	p.closeOnce.Do(func() { close(p.done) })
 	p.client.CloseIdleConnections()
 	return nil
 }
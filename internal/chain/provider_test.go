@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -282,3 +283,232 @@ func TestProvider_Close(t *testing.T) {
 
 	assert.NoError(t, err)
 }
+
+// newSwitchableServer returns an RPC server that fails while healthy is false,
+// counting the requests it receives
+func newSwitchableServer(t *testing.T, result string, healthy *atomic.Bool, calls *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","result":"` + result + `","id":1}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestProvider_StaysOnFallbackUntilPrimaryRecovers sticks to the fallback and fails back after a probe
+func TestProvider_StaysOnFallbackUntilPrimaryRecovers(t *testing.T) {
+	var primaryHealthy, fallbackHealthy atomic.Bool
+	var primaryCalls, fallbackCalls atomic.Int32
+	fallbackHealthy.Store(true)
+	primary := newSwitchableServer(t, "0x1", &primaryHealthy, &primaryCalls)
+	fallback := newSwitchableServer(t, "0x2", &fallbackHealthy, &fallbackCalls)
+
+	provider := NewProvider(primary.URL, fallback.URL)
+	provider.SetProbeInterval(20 * time.Millisecond)
+	defer provider.Close()
+	ctx := context.Background()
+
+	response, err := provider.Call(ctx, "eth_blockNumber", []interface{}{})
+	require.NoError(t, err)
+	assert.Contains(t, string(response), "0x2")
+	assert.True(t, provider.UsingFallback())
+
+	// Later calls go straight to the fallback
+	primaryBefore := primaryCalls.Load()
+	for i := 0; i < 3; i++ {
+		response, err = provider.Call(ctx, "eth_blockNumber", []interface{}{})
+		require.NoError(t, err)
+		assert.Contains(t, string(response), "0x2")
+	}
+	assert.LessOrEqual(t, primaryCalls.Load()-primaryBefore, int32(1), "only background probes reach the primary")
+
+	primaryHealthy.Store(true)
+	require.Eventually(t, func() bool { return !provider.UsingFallback() }, time.Second, 10*time.Millisecond)
+
+	response, err = provider.Call(ctx, "eth_blockNumber", []interface{}{})
+	require.NoError(t, err)
+	assert.Contains(t, string(response), "0x1")
+}
+
+// TestProvider_FailsBackWhenFallbackFails returns to a recovered primary without waiting for a probe
+func TestProvider_FailsBackWhenFallbackFails(t *testing.T) {
+	var primaryHealthy, fallbackHealthy atomic.Bool
+	var primaryCalls, fallbackCalls atomic.Int32
+	fallbackHealthy.Store(true)
+	primary := newSwitchableServer(t, "0x1", &primaryHealthy, &primaryCalls)
+	fallback := newSwitchableServer(t, "0x2", &fallbackHealthy, &fallbackCalls)
+
+	provider := NewProvider(primary.URL, fallback.URL)
+	provider.SetProbeInterval(time.Hour)
+	defer provider.Close()
+	ctx := context.Background()
+
+	_, err := provider.Call(ctx, "eth_blockNumber", []interface{}{})
+	require.NoError(t, err)
+	require.True(t, provider.UsingFallback())
+
+	primaryHealthy.Store(true)
+	fallbackHealthy.Store(false)
+	response, err := provider.Call(ctx, "eth_blockNumber", []interface{}{})
+	require.NoError(t, err)
+	assert.Contains(t, string(response), "0x1")
+	assert.False(t, provider.UsingFallback())
+}
+
+// TestProvider_CancelledCallDoesNotFailOver ignores failures caused by the caller
+func TestProvider_CancelledCallDoesNotFailOver(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(`{"jsonrpc":"2.0","result":"0x1","id":1}`))
+	}))
+	defer primary.Close()
+	var fallbackHealthy atomic.Bool
+	var fallbackCalls atomic.Int32
+	fallbackHealthy.Store(true)
+	fallback := newSwitchableServer(t, "0x2", &fallbackHealthy, &fallbackCalls)
+
+	provider := NewProvider(primary.URL, fallback.URL)
+	defer provider.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := provider.Call(ctx, "eth_blockNumber", []interface{}{})
+	assert.Error(t, err)
+	assert.False(t, provider.UsingFallback())
+	assert.Zero(t, fallbackCalls.Load())
+}
+
+// newSlowServer returns an RPC server that answers after delay, counting the requests it receives
+func newSlowServer(t *testing.T, result string, delay time.Duration, calls *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","result":"` + result + `","id":1}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// primeLatencies records enough primary latencies for hedging to start
+func primeLatencies(provider *Provider, latency time.Duration) {
+	for i := 0; i < hedgeMinSamples; i++ {
+		provider.latencies.record(latency)
+	}
+}
+
+// TestProvider_HedgesSlowPrimary takes the fallback's answer when the primary is slower than usual
+func TestProvider_HedgesSlowPrimary(t *testing.T) {
+	var primaryCalls, fallbackCalls atomic.Int32
+	primary := newSlowServer(t, "0x1", time.Second, &primaryCalls)
+	fallback := newSlowServer(t, "0x2", 0, &fallbackCalls)
+
+	provider := NewProvider(primary.URL, fallback.URL)
+	provider.SetHedging(95, 10*time.Millisecond)
+	defer provider.Close()
+	primeLatencies(provider, 20*time.Millisecond)
+
+	start := time.Now()
+	response, err := provider.Call(context.Background(), "eth_blockNumber", []interface{}{})
+	require.NoError(t, err)
+	assert.Contains(t, string(response), "0x2")
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.Equal(t, int32(1), fallbackCalls.Load())
+	assert.False(t, provider.UsingFallback(), "a slow primary is not a failed one")
+}
+
+// TestProvider_HedgingSkipsFastPrimary only calls the fallback when the threshold passes
+func TestProvider_HedgingSkipsFastPrimary(t *testing.T) {
+	var primaryCalls, fallbackCalls atomic.Int32
+	primary := newSlowServer(t, "0x1", 0, &primaryCalls)
+	fallback := newSlowServer(t, "0x2", 0, &fallbackCalls)
+
+	provider := NewProvider(primary.URL, fallback.URL)
+	provider.SetHedging(95, 200*time.Millisecond)
+	defer provider.Close()
+	primeLatencies(provider, time.Millisecond)
+
+	for i := 0; i < 5; i++ {
+		response, err := provider.Call(context.Background(), "eth_blockNumber", []interface{}{})
+		require.NoError(t, err)
+		assert.Contains(t, string(response), "0x1")
+	}
+	assert.Zero(t, fallbackCalls.Load())
+}
+
+// TestProvider_HedgingWaitsForSamples does not hedge before the primary latency is known
+func TestProvider_HedgingWaitsForSamples(t *testing.T) {
+	var primaryCalls, fallbackCalls atomic.Int32
+	primary := newSlowServer(t, "0x1", 50*time.Millisecond, &primaryCalls)
+	fallback := newSlowServer(t, "0x2", 0, &fallbackCalls)
+
+	provider := NewProvider(primary.URL, fallback.URL)
+	provider.SetHedging(95, time.Millisecond)
+	defer provider.Close()
+
+	response, err := provider.Call(context.Background(), "eth_blockNumber", []interface{}{})
+	require.NoError(t, err)
+	assert.Contains(t, string(response), "0x1")
+	assert.Zero(t, fallbackCalls.Load())
+}
+
+// TestProvider_HedgedCallFailsOverOnPrimaryError switches to the fallback when the primary errors
+func TestProvider_HedgedCallFailsOverOnPrimaryError(t *testing.T) {
+	var primaryHealthy, fallbackHealthy atomic.Bool
+	var primaryCalls, fallbackCalls atomic.Int32
+	fallbackHealthy.Store(true)
+	primary := newSwitchableServer(t, "0x1", &primaryHealthy, &primaryCalls)
+	fallback := newSwitchableServer(t, "0x2", &fallbackHealthy, &fallbackCalls)
+
+	provider := NewProvider(primary.URL, fallback.URL)
+	provider.SetHedging(95, time.Second)
+	provider.SetProbeInterval(time.Hour)
+	defer provider.Close()
+	primeLatencies(provider, time.Millisecond)
+
+	start := time.Now()
+	response, err := provider.Call(context.Background(), "eth_blockNumber", []interface{}{})
+	require.NoError(t, err)
+	assert.Contains(t, string(response), "0x2")
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "a failed primary is not waited out")
+	assert.True(t, provider.UsingFallback())
+
+	// Both failing reports the primary error
+	provider.failBack()
+	fallbackHealthy.Store(false)
+	_, err = provider.Call(context.Background(), "eth_blockNumber", []interface{}{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "502")
+}
+
+func TestLatencyTracker_Percentile(t *testing.T) {
+	var tracker latencyTracker
+	_, ok := tracker.percentile(95)
+	assert.False(t, ok)
+
+	for i := 1; i <= 100; i++ {
+		tracker.record(time.Duration(i) * time.Millisecond)
+	}
+	p95, ok := tracker.percentile(95)
+	require.True(t, ok)
+	assert.Equal(t, 96*time.Millisecond, p95)
+
+	p50, _ := tracker.percentile(50)
+	assert.Equal(t, 51*time.Millisecond, p50)
+
+	// The window keeps only the latest samples
+	for i := 0; i < hedgeWindow; i++ {
+		tracker.record(time.Second)
+	}
+	p50, _ = tracker.percentile(50)
+	assert.Equal(t, time.Second, p50)
+}
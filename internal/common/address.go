@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"sync/atomic"
 
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -14,6 +15,22 @@ var (
 	ethereumAddressRegex = regexp.MustCompile(`^0[xX][0-9a-fA-F]{40}$`)
 )
 
+// checksumWarning, when set, accepts addresses with an invalid EIP-55 checksum,
+// reporting them to the function instead of rejecting them
+var checksumWarning atomic.Pointer[func(address, expected string)]
+
+// RelaxChecksums makes NormalizeAddress accept mixed-case addresses with an invalid
+// EIP-55 checksum, calling warn with each such address and its checksummed form.
+// Testnet deployments relax the check, since test tooling and faucets often mangle
+// the case of addresses. A nil warn restores strict checking.
+func RelaxChecksums(warn func(address, expected string)) {
+	if warn == nil {
+		checksumWarning.Store(nil)
+		return
+	}
+	checksumWarning.Store(&warn)
+}
+
 // AddressError represents an error related to address validation
 type AddressError struct {
 	Address string
@@ -33,7 +50,7 @@ func (e *AddressError) Error() string {
 //   - Address cannot be empty
 //   - Must have "0x" prefix
 //   - Must be exactly 40 hexadecimal characters (case-insensitive)
-//   - If mixed case, validates EIP-55 checksum
+//   - If mixed case, validates EIP-55 checksum, unless relaxed by RelaxChecksums
 //
 // Returns:
 //   - Lowercase normalized address with "0x" prefix
@@ -86,6 +103,10 @@ func NormalizeAddress(address string) (string, error) {
 		checksummed := ethAddr.Hex()
 
 		if address != checksummed {
+			if warn := checksumWarning.Load(); warn != nil {
+				(*warn)(address, checksummed)
+				return strings.ToLower(address), nil
+			}
 			return "", &AddressError{
 				Address: address,
 				Reason:  fmt.Sprintf("invalid EIP-55 checksum (expected %s)", checksummed),
@@ -468,6 +468,30 @@ func TestNormalizeAddressEIP55Validation(t *testing.T) {
 	assert.Contains(t, err.Error(), "invalid EIP-55 checksum")
 }
 
+func TestRelaxChecksums(t *testing.T) {
+	var warned []string
+	RelaxChecksums(func(address, expected string) {
+		warned = append(warned, address+" "+expected)
+	})
+	t.Cleanup(func() { RelaxChecksums(nil) })
+
+	normalized, err := NormalizeAddress(invalidChecksumAddr1)
+	require.NoError(t, err)
+	assert.Equal(t, strings.ToLower(validChecksumAddr1), normalized)
+	assert.Equal(t, []string{invalidChecksumAddr1 + " " + validChecksumAddr1}, warned)
+
+	// Valid checksums and malformed addresses are unaffected
+	_, err = NormalizeAddress(validChecksumAddr2)
+	require.NoError(t, err)
+	_, err = NormalizeAddress("0x123")
+	assert.Error(t, err)
+	assert.Len(t, warned, 1)
+
+	RelaxChecksums(nil)
+	_, err = NormalizeAddress(invalidChecksumAddr1)
+	assert.Error(t, err)
+}
+
 func BenchmarkNormalizeAddress(b *testing.B) {
 	addr := validAddressLower
 	b.ResetTimer()
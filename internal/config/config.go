@@ -1,10 +1,19 @@
 package config
 
 import (
+	"encoding/hex"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"path"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/yourusername/gatekeeper/internal/chain"
+	"github.com/yourusername/gatekeeper/internal/common"
+	"github.com/yourusername/gatekeeper/internal/listener"
 )
 
 // Config holds all configuration for the gatekeeper application.
@@ -15,41 +24,173 @@ type Config struct {
 	Version string // Service version
 
 	// Database configuration
-	DatabaseURL         string
-	DBMaxOpenConns      int           // Maximum number of open connections
-	DBMaxIdleConns      int           // Maximum number of idle connections
-	DBConnMaxLifetime   time.Duration // Maximum lifetime of a connection
-	DBConnMaxIdleTime   time.Duration // Maximum idle time of a connection
+	DatabaseURL       string
+	DBMaxOpenConns    int           // Maximum number of open connections
+	DBMaxIdleConns    int           // Maximum number of idle connections
+	DBConnMaxLifetime time.Duration // Maximum lifetime of a connection
+	DBConnMaxIdleTime time.Duration // Maximum idle time of a connection
+
+	// Database availability configuration
+	DBConnectMaxAttempts  int           // Startup connection attempts before giving up
+	DBConnectMaxDelay     time.Duration // Cap on the backoff between startup connection attempts
+	DBHealthCheckInterval time.Duration // Interval of the background database check behind readiness
+	DBSlowQueryThreshold  time.Duration // Statements at least this slow are logged (0 disables)
+	DBQueryTimeout        time.Duration // Cap on each repository operation (0 disables)
+	DBBulkQueryTimeout    time.Duration // Cap on imports and cleanup jobs (0 disables)
+	DBAutoMigrate         bool          // Apply embedded migrations on start, one replica at a time
 
 	// JWT configuration
-	JWTSecret  []byte
-	JWTExpiry  time.Duration
+	JWTSecret         []byte
+	JWTExpiry         time.Duration
+	RefreshTokenTTL   time.Duration // Lifetime of refresh tokens, rotated on each use (0 disables them)
+	JWTAlgorithm      string        // HS256 (default, signs with JWTSecret), RS256, ES256 or EdDSA
+	JWTPrivateKeyPath string        // PEM private key for asymmetric algorithms
+	TokenRevocation   string        // Where logged out access tokens are recorded: "memory" (default) or "postgres"
+	SignedURLPaths    []string      // /api path prefixes that may be shared with signed URLs (empty disables them)
 
 	// Ethereum configuration
 	EthereumRPC         string        // Primary RPC endpoint
 	EthereumRPCFallback string        // Fallback RPC endpoint (optional)
 	ChainID             uint64        // Chain ID (1=mainnet, 5=goerli, 11155111=sepolia)
+	TestnetMode         bool          // Relax checksums and label audit events as testnet (default: on for known testnets)
 	CacheTTL            time.Duration // Cache time-to-live for blockchain results
+	CacheWarmInterval   time.Duration // How often blockchain results of hot addresses are refreshed (0 disables)
+	CacheWarmActive     time.Duration // How long after its last gated request an address is kept warm
+	CacheWarmAddresses  []string      // Addresses always kept warm
 	RPCTimeout          time.Duration // RPC call timeout
+	RPCProbeInterval    time.Duration // How often a failed primary RPC is probed before failing back
+	RPCHedgePercentile  int           // Primary latency percentile after which calls are also sent to the fallback (0 disables)
+	RPCHedgeMinDelay    time.Duration // Calls are never hedged sooner than this
+	RPCBatchWindow      time.Duration // Rule reads made within this of each other are sent in one round-trip (0 disables)
+	RPCBatchMaxSize     int           // Most calls in one batch
+	RPCMulticall        bool          // Aggregate batched eth_calls through Multicall3
+	IPFSGateway         string        // Gateway for ipfs:// NFT metadata (default: https://ipfs.io/ipfs/)
+	MetadataTimeout     time.Duration // NFT metadata fetch timeout
+	GeoIPDatabase       string        // CSV table of networks for geo restriction rules (empty disables)
+	PluginsDir          string        // Directory of WebAssembly plugins for plugin rules (empty disables)
+	PluginMemoryLimitMB int           // Memory a plugin instance may grow to (default: 16)
 
 	// Logging configuration
-	LogLevel string
+	LogLevel              string
+	LogBackend            string // "zap" (default) or "slog"
+	LogSamplingInitial    int    // Entries per second logged per level/message before sampling (0 disables sampling)
+	LogSamplingThereafter int    // After the initial entries, log every Nth entry
+	TracingEnabled        bool   // Join W3C trace context and attach trace IDs to latency metrics as exemplars
+	DebugTimingEnabled    bool   // Answer admins sending X-Gatekeeper-Debug: timing with a Server-Timing breakdown
+
+	// Policy statistics configuration
+	PolicyStatsWindow time.Duration // Sliding window of GET /api/admin/policies/stats
+
+	// Policy denial configuration
+	PolicyDenialDetail string // What a 403 from a policy discloses: "policy" (default), "rules" or "requirements"
+
+	// RPC spend accounting configuration
+	RPCCallCost         int            // Estimated cost of an RPC call whose method has no cost of its own
+	RPCCallCosts        map[string]int // Estimated cost per RPC method, e.g. provider compute units
+	RPCUsageTenantClaim string         // Claim naming the tenant RPC calls are attributed to (empty disables)
+
+	// Decision log configuration
+	DecisionLogFile       string        // NDJSON file receiving one record per gated request (empty disables)
+	DecisionLogMaxSize    int64         // Rotate the decision log at this many bytes (0 disables)
+	DecisionLogMaxAge     time.Duration // Rotate the decision log at this age (0 disables)
+	DecisionLogMaxBackups int           // Rotated decision logs kept (0 keeps all)
+	DecisionLogCompress   bool          // Gzip rotated decision logs
+
+	// Security events of each wallet, served by GET /api/me/audit
+	SecurityEventRetention time.Duration // Events older than this are deleted (0 keeps them forever)
+
+	// Key expiry notification configuration
+	KeyExpiryNotifyHorizon time.Duration // Notify owners of keys expiring within this window
+	KeyExpiryCheckInterval time.Duration // Interval between searches for expiring keys
+	KeySunsetCheckInterval time.Duration // Interval between revocations of keys past a deprecation's sunset
+	NotifyWebhookURL       string        // Receives notifications as JSON (empty disables)
+	NotifyWebhookSecret    string        // Signs webhook bodies with HMAC-SHA256 (empty disables signing)
+	SMTPAddr               string        // SMTP server host:port for email notifications (empty disables)
+	SMTPUsername           string        // SMTP PLAIN auth username (empty disables auth)
+	SMTPPassword           string        // SMTP PLAIN auth password
+	SMTPFrom               string        // Sender of notification emails
+	NotifyEmailTo          []string      // Recipients of notification emails
+
+	// Audit configuration
+	TLSFingerprintHeader string // Header carrying the client's JA3 fingerprint from a trusted proxy (empty disables)
+
+	// Audit export configuration
+	AuditExportBucket          string        // S3-compatible bucket archiving audit events (empty disables)
+	AuditExportEndpoint        string        // Object storage endpoint URL
+	AuditExportRegion          string        // SigV4 signing region
+	AuditExportPrefix          string        // Key prefix of archive objects
+	AuditExportAccessKeyID     string        // Object storage access key
+	AuditExportSecretAccessKey string        // Object storage secret key
+	AuditExportPathStyle       bool          // Address the bucket in the path (MinIO) rather than the host name
+	AuditExportBatchSize       int           // Events per archive object at most
+	AuditExportFlushInterval   time.Duration // Buffered events are uploaded at least this often
+
+	// Key escrow configuration
+	EscrowDir      string        // Directory of encrypted key material snapshots (empty disables)
+	EscrowKEK      []byte        // 32-byte key-encryption key wrapping the data keys of snapshots
+	EscrowInterval time.Duration // How often key material is checked for changes to escrow
 
 	// SIWE configuration
 	NonceTTL time.Duration
 
+	// WebAuthn configuration
+	WebAuthnRPID         string        // Relying party domain of passkeys (empty disables passkeys)
+	WebAuthnRPName       string        // Relying party name shown by authenticators
+	WebAuthnOrigins      []string      // Origins passkey ceremonies may run on (default: https://<WebAuthnRPID>)
+	WebAuthnChallengeTTL time.Duration // Validity of registration and assertion challenges
+
+	// OIDC provider configuration
+	OIDCIssuer         string        // Base URL of the OIDC provider endpoints (empty disables the provider)
+	OIDCClientsFile    string        // Path to the JSON list of applications allowed to sign users in
+	OIDCCodeTTL        time.Duration // Validity of authorization codes
+	OIDCAccessTokenTTL time.Duration // Lifetime of access and ID tokens issued to applications
+
+	// Scope configuration
+	ScopeCatalogFile   string // Path to JSON scope catalog (empty uses the built-in catalog)
+	EnforceKeyScopes   bool   // Require keys:read/keys:write for API-key access to /api/keys (default: true)
+	EnforceRouteScopes bool   // Require read/write or the route's declared scopes for API-key requests (default: true)
+
+	// Role configuration
+	BootstrapAdminAddress string // Wallet made admin at startup while no user is admin (empty disables)
+
+	// API key extraction configuration
+	APIKeyHeaders []string // Headers carrying API keys, in order of precedence (default: X-API-Key)
+	APIKeyCookie  string   // Cookie carrying API keys on HTTPS requests (empty disables)
+
+	// Listener configuration
+	Listeners                 []listener.Spec // Declared listeners (default: public on PORT)
+	ReusePort                 bool            // Set SO_REUSEPORT on TCP listeners for binary swaps
+	ProxyProtocol             bool            // Parse HAProxy PROXY protocol headers on accepted connections
+	ProxyProtocolTrustedCIDRs []string        // Sources allowed to send PROXY headers (required with ProxyProtocol)
+
+	// CORS and security header configuration
+	CORSAllowedOrigins      []string      // Origins allowed to call the API cross-origin (empty disables CORS)
+	CORSAdminAllowedOrigins []string      // Overrides CORSAllowedOrigins for /api/admin (empty disables CORS there)
+	HSTSMaxAge              time.Duration // Strict-Transport-Security max-age (0 omits the header)
+	FrameAncestors          string        // CSP frame-ancestors for /docs and /api/admin
+
 	// Rate limiting configuration
-	APIKeyCreationRateLimit int // API key creations per user per hour (default: 10)
-	APIKeyCreationBurstLimit int // Max burst for API key creation (default: 3)
-	APIUsageRateLimit       int // API requests per user per minute (default: 1000)
-	APIUsageBurstLimit      int // Max burst for API usage (default: 100)
+	APIKeyCreationRateLimit  int            // API key creations per user per hour (default: 10)
+	APIKeyCreationBurstLimit int            // Max burst for API key creation (default: 3)
+	APIUsageRateLimit        int            // API requests per user per minute (default: 1000)
+	APIUsageBurstLimit       int            // Max burst for API usage (default: 100)
+	APIUsageHeavyWeight      int            // API usage tokens an expensive request, such as an export, takes (default: 10)
+	TierRateLimits           map[string]int // API requests per user per minute of each tier granted by TIER policies
+	MaxInFlightPerIP         int            // Requests in flight per client IP (0 disables)
+	MaxInFlightPerKey        int            // Requests in flight per API key or token identity (0 disables)
 }
 
-// Load loads configuration from environment variables.
+// Load loads configuration from environment variables, decrypting values
+// encrypted with age or SOPS with the key in AGE_KEY_FILE.
 // Returns error if required variables are missing or invalid.
 func Load() (*Config, error) {
 	cfg := &Config{}
 
+	// Encrypted values are decrypted before any is read
+	if err := decryptEnv(); err != nil {
+		return nil, err
+	}
+
 	// Load required string field
 	if err := loadRequiredString("PORT", &cfg.Port); err != nil {
 		return nil, err
@@ -83,32 +224,455 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
+	// Testnet mode - on by default when the chain is a known testnet
+	if err := loadBool("TESTNET_MODE", chain.IsTestnet(cfg.ChainID), &cfg.TestnetMode); err != nil {
+		return nil, err
+	}
+
 	// Cache TTL - default 300 seconds (5 minutes)
 	if err := loadDurationFromSeconds("CACHE_TTL", 300, &cfg.CacheTTL); err != nil {
 		return nil, err
 	}
 
+	// Cache warming - disabled by default; must refresh entries before they expire
+	if err := loadDurationFromSeconds("CACHE_WARM_INTERVAL_SECONDS", 0, &cfg.CacheWarmInterval); err != nil {
+		return nil, err
+	}
+	if cfg.CacheWarmInterval < 0 {
+		return nil, fmt.Errorf("CACHE_WARM_INTERVAL_SECONDS cannot be negative")
+	}
+	if cfg.CacheWarmInterval > 0 && cfg.CacheWarmInterval >= cfg.CacheTTL {
+		return nil, fmt.Errorf("CACHE_WARM_INTERVAL_SECONDS must be shorter than CACHE_TTL")
+	}
+	if err := loadDurationFromMinutes("CACHE_WARM_ACTIVE_MINUTES", 30, &cfg.CacheWarmActive); err != nil {
+		return nil, err
+	}
+	if cfg.CacheWarmActive < 0 {
+		return nil, fmt.Errorf("CACHE_WARM_ACTIVE_MINUTES cannot be negative")
+	}
+	cfg.CacheWarmAddresses = loadStringList("CACHE_WARM_ADDRESSES")
+
 	// RPC timeout - default 5 seconds
 	if err := loadDurationFromSeconds("RPC_TIMEOUT", 5, &cfg.RPCTimeout); err != nil {
 		return nil, err
 	}
 
+	// Failed primary RPC probe interval - default 10 seconds
+	if err := loadDurationFromSeconds("RPC_PROBE_INTERVAL_SECONDS", 10, &cfg.RPCProbeInterval); err != nil {
+		return nil, err
+	}
+	if cfg.RPCProbeInterval <= 0 {
+		return nil, fmt.Errorf("RPC_PROBE_INTERVAL_SECONDS must be positive")
+	}
+
+	// Hedged RPC requests - disabled by default
+	if err := loadInt("RPC_HEDGE_PERCENTILE", 0, &cfg.RPCHedgePercentile); err != nil {
+		return nil, err
+	}
+	if cfg.RPCHedgePercentile < 0 || cfg.RPCHedgePercentile > 99 {
+		return nil, fmt.Errorf("RPC_HEDGE_PERCENTILE must be between 0 and 99")
+	}
+	if err := loadDurationFromMilliseconds("RPC_HEDGE_MIN_DELAY_MS", 50, &cfg.RPCHedgeMinDelay); err != nil {
+		return nil, err
+	}
+	if cfg.RPCHedgeMinDelay < 0 {
+		return nil, fmt.Errorf("RPC_HEDGE_MIN_DELAY_MS cannot be negative")
+	}
+
+	// Batched RPC reads - disabled by default
+	if err := loadDurationFromMilliseconds("RPC_BATCH_WINDOW_MS", 0, &cfg.RPCBatchWindow); err != nil {
+		return nil, err
+	}
+	if cfg.RPCBatchWindow < 0 || cfg.RPCBatchWindow > time.Second {
+		return nil, fmt.Errorf("RPC_BATCH_WINDOW_MS must be between 0 and 1000")
+	}
+	if err := loadInt("RPC_BATCH_MAX_SIZE", chain.DefaultBatchMaxSize, &cfg.RPCBatchMaxSize); err != nil {
+		return nil, err
+	}
+	if cfg.RPCBatchMaxSize < 1 || cfg.RPCBatchMaxSize > chain.MaxBatchSize {
+		return nil, fmt.Errorf("RPC_BATCH_MAX_SIZE must be between 1 and %d", chain.MaxBatchSize)
+	}
+	if err := loadBool("RPC_MULTICALL", false, &cfg.RPCMulticall); err != nil {
+		return nil, err
+	}
+
+	// NFT metadata resolution for trait rules
+	cfg.IPFSGateway = os.Getenv("IPFS_GATEWAY_URL")
+	if cfg.IPFSGateway == "" {
+		cfg.IPFSGateway = "https://ipfs.io/ipfs/"
+	}
+	if err := loadDurationFromSeconds("METADATA_TIMEOUT", 10, &cfg.MetadataTimeout); err != nil {
+		return nil, err
+	}
+
+	// GeoIP table for geo restriction rules (optional)
+	cfg.GeoIPDatabase = os.Getenv("GEOIP_DATABASE")
+
+	// WebAssembly plugins for plugin rules (optional)
+	cfg.PluginsDir = os.Getenv("PLUGINS_DIR")
+	if err := loadInt("PLUGIN_MEMORY_LIMIT_MB", 16, &cfg.PluginMemoryLimitMB); err != nil {
+		return nil, err
+	}
+	if cfg.PluginMemoryLimitMB < 1 || cfg.PluginMemoryLimitMB > 4096 {
+		return nil, fmt.Errorf("PLUGIN_MEMORY_LIMIT_MB must be between 1 and 4096")
+	}
+
 	// Load optional fields with defaults
 	cfg.LogLevel = os.Getenv("LOG_LEVEL")
 	if cfg.LogLevel == "" {
 		cfg.LogLevel = "info"
 	}
 
+	// Log backend - default zap
+	cfg.LogBackend = os.Getenv("LOG_BACKEND")
+	if cfg.LogBackend == "" {
+		cfg.LogBackend = "zap"
+	}
+
+	// Log sampling - default 100 initial, then every 100th (zap production defaults)
+	if err := loadInt("LOG_SAMPLING_INITIAL", 100, &cfg.LogSamplingInitial); err != nil {
+		return nil, err
+	}
+	if err := loadInt("LOG_SAMPLING_THEREAFTER", 100, &cfg.LogSamplingThereafter); err != nil {
+		return nil, err
+	}
+
+	// Tracing - disabled by default
+	if err := loadBool("TRACING_ENABLED", false, &cfg.TracingEnabled); err != nil {
+		return nil, err
+	}
+
+	// Debug timing annotations - disabled by default
+	if err := loadBool("DEBUG_TIMING_ENABLED", false, &cfg.DebugTimingEnabled); err != nil {
+		return nil, err
+	}
+
+	// TLS fingerprint header - optional, disabled unless set
+	cfg.TLSFingerprintHeader = os.Getenv("TLS_FINGERPRINT_HEADER")
+
+	// Audit export - optional, disabled unless a bucket is set
+	cfg.AuditExportBucket = os.Getenv("AUDIT_EXPORT_BUCKET")
+	cfg.AuditExportRegion = "us-east-1"
+	if region := os.Getenv("AUDIT_EXPORT_REGION"); region != "" {
+		cfg.AuditExportRegion = region
+	}
+	cfg.AuditExportEndpoint = "https://s3." + cfg.AuditExportRegion + ".amazonaws.com"
+	if endpoint := os.Getenv("AUDIT_EXPORT_ENDPOINT"); endpoint != "" {
+		cfg.AuditExportEndpoint = endpoint
+	}
+	cfg.AuditExportPrefix = "audit"
+	if prefix, ok := os.LookupEnv("AUDIT_EXPORT_PREFIX"); ok {
+		cfg.AuditExportPrefix = strings.Trim(prefix, "/")
+	}
+	cfg.AuditExportAccessKeyID = os.Getenv("AUDIT_EXPORT_ACCESS_KEY_ID")
+	cfg.AuditExportSecretAccessKey = os.Getenv("AUDIT_EXPORT_SECRET_ACCESS_KEY")
+	if err := loadBool("AUDIT_EXPORT_PATH_STYLE", false, &cfg.AuditExportPathStyle); err != nil {
+		return nil, err
+	}
+	if err := loadInt("AUDIT_EXPORT_BATCH_SIZE", 1000, &cfg.AuditExportBatchSize); err != nil {
+		return nil, err
+	}
+	if err := loadDurationFromSeconds("AUDIT_EXPORT_FLUSH_INTERVAL_SECONDS", 60, &cfg.AuditExportFlushInterval); err != nil {
+		return nil, err
+	}
+	if cfg.AuditExportBatchSize <= 0 || cfg.AuditExportFlushInterval <= 0 {
+		return nil, fmt.Errorf("AUDIT_EXPORT_BATCH_SIZE and AUDIT_EXPORT_FLUSH_INTERVAL_SECONDS must be positive")
+	}
+	if cfg.AuditExportBucket != "" && (cfg.AuditExportAccessKeyID == "" || cfg.AuditExportSecretAccessKey == "") {
+		return nil, fmt.Errorf("AUDIT_EXPORT_ACCESS_KEY_ID and AUDIT_EXPORT_SECRET_ACCESS_KEY are required when AUDIT_EXPORT_BUCKET is set")
+	}
+
+	// Key escrow - disabled unless a directory is set, which requires a hex encoded
+	// 32-byte key-encryption key; changes are escrowed within an hour by default
+	cfg.EscrowDir = os.Getenv("ESCROW_DIR")
+	if kek := os.Getenv("ESCROW_KEK"); kek != "" {
+		decoded, err := hex.DecodeString(kek)
+		if err != nil || len(decoded) != 32 {
+			return nil, fmt.Errorf("ESCROW_KEK must be 64 hex characters (32 bytes)")
+		}
+		cfg.EscrowKEK = decoded
+	}
+	if cfg.EscrowDir != "" && cfg.EscrowKEK == nil {
+		return nil, fmt.Errorf("ESCROW_KEK is required when ESCROW_DIR is set")
+	}
+	if err := loadDurationFromMinutes("ESCROW_INTERVAL_MINUTES", 60, &cfg.EscrowInterval); err != nil {
+		return nil, err
+	}
+	if cfg.EscrowInterval <= 0 {
+		return nil, fmt.Errorf("ESCROW_INTERVAL_MINUTES must be positive")
+	}
+
+	// Policy statistics window - default 60 minutes
+	if err := loadDurationFromMinutes("POLICY_STATS_WINDOW_MINUTES", 60, &cfg.PolicyStatsWindow); err != nil {
+		return nil, err
+	}
+
+	// Policy denial detail - default the denying policy and its rule types only
+	cfg.PolicyDenialDetail = os.Getenv("POLICY_DENIAL_DETAIL")
+	if cfg.PolicyDenialDetail == "" {
+		cfg.PolicyDenialDetail = "policy"
+	}
+	switch cfg.PolicyDenialDetail {
+	case "policy", "rules", "requirements":
+	default:
+		return nil, fmt.Errorf("POLICY_DENIAL_DETAIL must be policy, rules or requirements")
+	}
+
+	// RPC spend accounting - every call costs 1 unless priced per method
+	if err := loadInt("RPC_CALL_COST", 1, &cfg.RPCCallCost); err != nil {
+		return nil, err
+	}
+	if cfg.RPCCallCost < 0 {
+		return nil, fmt.Errorf("RPC_CALL_COST cannot be negative")
+	}
+	cfg.RPCCallCosts = make(map[string]int)
+	for _, entry := range loadStringList("RPC_CALL_COSTS") {
+		method, costStr, ok := strings.Cut(entry, "=")
+		cost, err := strconv.Atoi(strings.TrimSpace(costStr))
+		if !ok || err != nil || cost < 0 || strings.TrimSpace(method) == "" {
+			return nil, fmt.Errorf("invalid RPC_CALL_COSTS entry %q: expected method=cost", entry)
+		}
+		cfg.RPCCallCosts[strings.TrimSpace(method)] = cost
+	}
+	cfg.RPCUsageTenantClaim = os.Getenv("RPC_USAGE_TENANT_CLAIM")
+
+	// Decision log - optional, rotated at 100 MB or daily and gzipped, 7 files kept
+	cfg.DecisionLogFile = os.Getenv("DECISION_LOG_FILE")
+	var decisionLogMaxSizeMB int
+	if err := loadInt("DECISION_LOG_MAX_SIZE_MB", 100, &decisionLogMaxSizeMB); err != nil {
+		return nil, err
+	}
+	cfg.DecisionLogMaxSize = int64(decisionLogMaxSizeMB) << 20
+	if err := loadDurationFromHours("DECISION_LOG_MAX_AGE_HOURS", 24, &cfg.DecisionLogMaxAge); err != nil {
+		return nil, err
+	}
+	if err := loadInt("DECISION_LOG_MAX_BACKUPS", 7, &cfg.DecisionLogMaxBackups); err != nil {
+		return nil, err
+	}
+	if err := loadBool("DECISION_LOG_COMPRESS", true, &cfg.DecisionLogCompress); err != nil {
+		return nil, err
+	}
+	if cfg.DecisionLogMaxSize < 0 || cfg.DecisionLogMaxAge < 0 || cfg.DecisionLogMaxBackups < 0 {
+		return nil, fmt.Errorf("DECISION_LOG_MAX_SIZE_MB, DECISION_LOG_MAX_AGE_HOURS and DECISION_LOG_MAX_BACKUPS cannot be negative")
+	}
+
+	// Security events - kept 90 days by default
+	if err := loadDurationFromHours("SECURITY_EVENT_RETENTION_HOURS", 90*24, &cfg.SecurityEventRetention); err != nil {
+		return nil, err
+	}
+	if cfg.SecurityEventRetention < 0 {
+		return nil, fmt.Errorf("SECURITY_EVENT_RETENTION_HOURS cannot be negative")
+	}
+
+	// Key expiry notifications - default 72 hour horizon checked hourly; sent only
+	// when a webhook or SMTP server is configured
+	if err := loadDurationFromHours("KEY_EXPIRY_NOTIFY_HORIZON_HOURS", 72, &cfg.KeyExpiryNotifyHorizon); err != nil {
+		return nil, err
+	}
+	if err := loadDurationFromMinutes("KEY_EXPIRY_CHECK_INTERVAL_MINUTES", 60, &cfg.KeyExpiryCheckInterval); err != nil {
+		return nil, err
+	}
+	if cfg.KeyExpiryNotifyHorizon <= 0 || cfg.KeyExpiryCheckInterval <= 0 {
+		return nil, fmt.Errorf("KEY_EXPIRY_NOTIFY_HORIZON_HOURS and KEY_EXPIRY_CHECK_INTERVAL_MINUTES must be positive")
+	}
+
+	// Keys of deprecations past their sunset are revoked within 15 minutes; requests
+	// with them are rejected from the sunset on regardless
+	if err := loadDurationFromMinutes("KEY_SUNSET_CHECK_INTERVAL_MINUTES", 15, &cfg.KeySunsetCheckInterval); err != nil {
+		return nil, err
+	}
+	if cfg.KeySunsetCheckInterval <= 0 {
+		return nil, fmt.Errorf("KEY_SUNSET_CHECK_INTERVAL_MINUTES must be positive")
+	}
+	cfg.NotifyWebhookURL = os.Getenv("NOTIFY_WEBHOOK_URL")
+	cfg.NotifyWebhookSecret = os.Getenv("NOTIFY_WEBHOOK_SECRET")
+	cfg.SMTPAddr = os.Getenv("SMTP_ADDR")
+	cfg.SMTPUsername = os.Getenv("SMTP_USERNAME")
+	cfg.SMTPPassword = os.Getenv("SMTP_PASSWORD")
+	cfg.SMTPFrom = os.Getenv("SMTP_FROM")
+	cfg.NotifyEmailTo = loadStringList("NOTIFY_EMAIL_TO")
+	if cfg.SMTPAddr != "" && (cfg.SMTPFrom == "" || len(cfg.NotifyEmailTo) == 0) {
+		return nil, fmt.Errorf("SMTP_FROM and NOTIFY_EMAIL_TO are required when SMTP_ADDR is set")
+	}
+
+	// Scope catalog file - optional, built-in catalog used when unset
+	cfg.ScopeCatalogFile = os.Getenv("SCOPE_CATALOG_FILE")
+
+	// Key management scope enforcement - default enabled, set false to keep legacy behavior
+	if err := loadBool("ENFORCE_KEY_SCOPES", true, &cfg.EnforceKeyScopes); err != nil {
+		return nil, err
+	}
+
+	// Route scope enforcement - default enabled: read-only keys cannot call mutating routes
+	if err := loadBool("ENFORCE_ROUTE_SCOPES", true, &cfg.EnforceRouteScopes); err != nil {
+		return nil, err
+	}
+
+	// Initial admin - promoted at startup only while no user holds the admin role
+	if raw := strings.TrimSpace(os.Getenv("BOOTSTRAP_ADMIN_ADDRESS")); raw != "" {
+		address, err := common.NormalizeAddress(raw)
+		if err != nil {
+			return nil, fmt.Errorf("BOOTSTRAP_ADMIN_ADDRESS: %w", err)
+		}
+		cfg.BootstrapAdminAddress = address
+	}
+
+	// API key headers - default X-API-Key; Authorization: Bearer is always accepted
+	cfg.APIKeyHeaders = loadStringList("API_KEY_HEADERS")
+	if len(cfg.APIKeyHeaders) == 0 {
+		cfg.APIKeyHeaders = []string{"X-API-Key"}
+	}
+
+	// API key cookie - optional, cookies are not read when unset
+	cfg.APIKeyCookie = os.Getenv("API_KEY_COOKIE")
+
+	// Listeners - default a single public TCP listener on PORT
+	cfg.Listeners = []listener.Spec{{Role: listener.RolePublic, Network: "tcp", Address: ":" + cfg.Port}}
+	if declared := os.Getenv("LISTENERS"); declared != "" {
+		specs, err := listener.ParseSpecs(declared)
+		if err != nil {
+			return nil, fmt.Errorf("LISTENERS is invalid: %w", err)
+		}
+		cfg.Listeners = specs
+	}
+
+	// SO_REUSEPORT - default disabled
+	if err := loadBool("REUSE_PORT", false, &cfg.ReusePort); err != nil {
+		return nil, err
+	}
+
+	// PROXY protocol - default disabled
+	if err := loadBool("PROXY_PROTOCOL", false, &cfg.ProxyProtocol); err != nil {
+		return nil, err
+	}
+	cfg.ProxyProtocolTrustedCIDRs = loadStringList("PROXY_PROTOCOL_TRUSTED_CIDRS")
+	if cfg.ProxyProtocol && len(cfg.ProxyProtocolTrustedCIDRs) == 0 {
+		return nil, fmt.Errorf("PROXY_PROTOCOL_TRUSTED_CIDRS is required when PROXY_PROTOCOL is enabled")
+	}
+	for _, cidr := range cfg.ProxyProtocolTrustedCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return nil, fmt.Errorf("invalid PROXY_PROTOCOL_TRUSTED_CIDRS entry %q: %w", cidr, err)
+		}
+	}
+	// The fingerprint header is only read from the trusted proxies, since clients can set it
+	if cfg.TLSFingerprintHeader != "" && len(cfg.ProxyProtocolTrustedCIDRs) == 0 {
+		return nil, fmt.Errorf("PROXY_PROTOCOL_TRUSTED_CIDRS is required when TLS_FINGERPRINT_HEADER is set")
+	}
+
+	// CORS origins - optional, cross-origin requests are not allowed when unset
+	cfg.CORSAllowedOrigins = loadStringList("CORS_ALLOWED_ORIGINS")
+	cfg.CORSAdminAllowedOrigins = loadStringList("CORS_ADMIN_ALLOWED_ORIGINS")
+
+	// HSTS max-age - default 1 year, 0 disables
+	if err := loadDurationFromSeconds("HSTS_MAX_AGE_SECONDS", 31536000, &cfg.HSTSMaxAge); err != nil {
+		return nil, err
+	}
+
+	// Frame ancestors for /docs and /api/admin - default deny framing
+	cfg.FrameAncestors = "'none'"
+	if ancestors := os.Getenv("FRAME_ANCESTORS"); ancestors != "" {
+		cfg.FrameAncestors = ancestors
+	}
+
 	// JWT expiry - default 24 hours
 	if err := loadDurationFromHours("JWT_EXPIRY_HOURS", 24, &cfg.JWTExpiry); err != nil {
 		return nil, err
 	}
 
+	// Refresh token TTL - default 30 days
+	if err := loadDurationFromHours("REFRESH_TOKEN_TTL_HOURS", 720, &cfg.RefreshTokenTTL); err != nil {
+		return nil, err
+	}
+	if cfg.RefreshTokenTTL < 0 {
+		return nil, fmt.Errorf("REFRESH_TOKEN_TTL_HOURS cannot be negative")
+	}
+
+	// Signed URL paths - optional, nothing can be signed when unset
+	cfg.SignedURLPaths = loadStringList("SIGNED_URL_PATHS")
+	for _, prefix := range cfg.SignedURLPaths {
+		if !strings.HasPrefix(prefix, "/api/") || path.Clean(prefix) != prefix {
+			return nil, fmt.Errorf("SIGNED_URL_PATHS entries must be clean paths under /api/, got %q", prefix)
+		}
+		for _, reserved := range unsignablePaths {
+			if pathWithin(prefix, reserved) || pathWithin(reserved, prefix) {
+				return nil, fmt.Errorf("SIGNED_URL_PATHS entry %q overlaps %s, which cannot be shared", prefix, reserved)
+			}
+		}
+	}
+
+	// JWT algorithm - default HS256 with JWT_SECRET; asymmetric ones need a private key
+	cfg.JWTAlgorithm = os.Getenv("JWT_ALGORITHM")
+	if cfg.JWTAlgorithm == "" {
+		cfg.JWTAlgorithm = "HS256"
+	}
+	cfg.JWTPrivateKeyPath = os.Getenv("JWT_PRIVATE_KEY_PATH")
+	switch cfg.JWTAlgorithm {
+	case "HS256":
+	case "RS256", "ES256", "EdDSA":
+		if cfg.JWTPrivateKeyPath == "" {
+			return nil, fmt.Errorf("JWT_PRIVATE_KEY_PATH is required when JWT_ALGORITHM is %s", cfg.JWTAlgorithm)
+		}
+	default:
+		return nil, fmt.Errorf("JWT_ALGORITHM must be one of HS256, RS256, ES256 or EdDSA")
+	}
+
+	// Token revocation list - default in memory (per instance)
+	cfg.TokenRevocation = os.Getenv("TOKEN_REVOCATION_BACKEND")
+	if cfg.TokenRevocation == "" {
+		cfg.TokenRevocation = "memory"
+	}
+	if cfg.TokenRevocation != "memory" && cfg.TokenRevocation != "postgres" {
+		return nil, fmt.Errorf("TOKEN_REVOCATION_BACKEND must be memory or postgres")
+	}
+
 	// Nonce TTL - default 5 minutes
 	if err := loadDurationFromMinutes("NONCE_TTL_MINUTES", 5, &cfg.NonceTTL); err != nil {
 		return nil, err
 	}
 
+	// WebAuthn - passkeys are disabled without a relying party ID
+	cfg.WebAuthnRPID = os.Getenv("WEBAUTHN_RP_ID")
+	cfg.WebAuthnRPName = os.Getenv("WEBAUTHN_RP_NAME")
+	if cfg.WebAuthnRPName == "" {
+		cfg.WebAuthnRPName = "Gatekeeper"
+	}
+	cfg.WebAuthnOrigins = loadStringList("WEBAUTHN_ORIGINS")
+	if len(cfg.WebAuthnOrigins) == 0 && cfg.WebAuthnRPID != "" {
+		cfg.WebAuthnOrigins = []string{"https://" + cfg.WebAuthnRPID}
+	}
+	if err := loadDurationFromSeconds("WEBAUTHN_CHALLENGE_TTL_SECONDS", 300, &cfg.WebAuthnChallengeTTL); err != nil {
+		return nil, err
+	}
+	if cfg.WebAuthnChallengeTTL <= 0 {
+		return nil, fmt.Errorf("WEBAUTHN_CHALLENGE_TTL_SECONDS must be positive")
+	}
+
+	// OIDC provider - disabled without an issuer; ID tokens must be verifiable
+	// with the published key, so an asymmetric JWT algorithm is required
+	cfg.OIDCIssuer = strings.TrimRight(os.Getenv("OIDC_ISSUER"), "/")
+	cfg.OIDCClientsFile = os.Getenv("OIDC_CLIENTS_FILE")
+	if cfg.OIDCIssuer != "" {
+		issuer, err := url.Parse(cfg.OIDCIssuer)
+		if err != nil || (issuer.Scheme != "https" && issuer.Scheme != "http") || issuer.Host == "" || issuer.RawQuery != "" || issuer.Fragment != "" {
+			return nil, fmt.Errorf("OIDC_ISSUER must be an http(s) URL without query or fragment")
+		}
+		if cfg.OIDCClientsFile == "" {
+			return nil, fmt.Errorf("OIDC_CLIENTS_FILE is required when OIDC_ISSUER is set")
+		}
+		if cfg.JWTAlgorithm == "HS256" {
+			return nil, fmt.Errorf("OIDC_ISSUER requires JWT_ALGORITHM RS256, ES256 or EdDSA")
+		}
+	}
+	if err := loadDurationFromSeconds("OIDC_CODE_TTL_SECONDS", 60, &cfg.OIDCCodeTTL); err != nil {
+		return nil, err
+	}
+	if err := loadDurationFromSeconds("OIDC_ACCESS_TOKEN_TTL_SECONDS", 3600, &cfg.OIDCAccessTokenTTL); err != nil {
+		return nil, err
+	}
+	if cfg.OIDCCodeTTL <= 0 || cfg.OIDCAccessTokenTTL <= 0 {
+		return nil, fmt.Errorf("OIDC_CODE_TTL_SECONDS and OIDC_ACCESS_TOKEN_TTL_SECONDS must be positive")
+	}
+
 	// Database connection pool settings
 	if err := loadInt("DB_MAX_OPEN_CONNS", 25, &cfg.DBMaxOpenConns); err != nil {
 		return nil, err
@@ -123,6 +687,40 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
+	// Database startup retry and health check settings
+	if err := loadInt("DB_CONNECT_MAX_ATTEMPTS", 10, &cfg.DBConnectMaxAttempts); err != nil {
+		return nil, err
+	}
+	if cfg.DBConnectMaxAttempts < 1 {
+		return nil, fmt.Errorf("DB_CONNECT_MAX_ATTEMPTS must be at least 1")
+	}
+	if err := loadDurationFromSeconds("DB_CONNECT_MAX_DELAY_SECONDS", 30, &cfg.DBConnectMaxDelay); err != nil {
+		return nil, err
+	}
+	if err := loadDurationFromSeconds("DB_HEALTH_CHECK_INTERVAL_SECONDS", 10, &cfg.DBHealthCheckInterval); err != nil {
+		return nil, err
+	}
+	if cfg.DBHealthCheckInterval <= 0 {
+		return nil, fmt.Errorf("DB_HEALTH_CHECK_INTERVAL_SECONDS must be positive")
+	}
+	if err := loadDurationFromMilliseconds("DB_SLOW_QUERY_THRESHOLD_MS", 200, &cfg.DBSlowQueryThreshold); err != nil {
+		return nil, err
+	}
+	if err := loadDurationFromMilliseconds("DB_QUERY_TIMEOUT_MS", 5000, &cfg.DBQueryTimeout); err != nil {
+		return nil, err
+	}
+	if err := loadDurationFromSeconds("DB_BULK_QUERY_TIMEOUT_SECONDS", 60, &cfg.DBBulkQueryTimeout); err != nil {
+		return nil, err
+	}
+	if cfg.DBQueryTimeout < 0 || cfg.DBBulkQueryTimeout < 0 {
+		return nil, fmt.Errorf("DB_QUERY_TIMEOUT_MS and DB_BULK_QUERY_TIMEOUT_SECONDS cannot be negative")
+	}
+
+	// Automatic migrations - disabled by default
+	if err := loadBool("DB_AUTO_MIGRATE", false, &cfg.DBAutoMigrate); err != nil {
+		return nil, err
+	}
+
 	// Rate limiting settings
 	if err := loadInt("API_KEY_CREATION_RATE_LIMIT", 10, &cfg.APIKeyCreationRateLimit); err != nil {
 		return nil, err
@@ -136,6 +734,32 @@ func Load() (*Config, error) {
 	if err := loadInt("API_USAGE_BURST_LIMIT", 100, &cfg.APIUsageBurstLimit); err != nil {
 		return nil, err
 	}
+	if err := loadInt("API_USAGE_HEAVY_WEIGHT", 10, &cfg.APIUsageHeavyWeight); err != nil {
+		return nil, err
+	}
+	if cfg.APIUsageHeavyWeight < 1 || cfg.APIUsageHeavyWeight > cfg.APIUsageBurstLimit {
+		return nil, fmt.Errorf("API_USAGE_HEAVY_WEIGHT must be between 1 and API_USAGE_BURST_LIMIT (%d)", cfg.APIUsageBurstLimit)
+	}
+	cfg.TierRateLimits = make(map[string]int)
+	for _, entry := range loadStringList("TIER_RATE_LIMITS") {
+		tier, rateStr, ok := strings.Cut(entry, "=")
+		rate, err := strconv.Atoi(strings.TrimSpace(rateStr))
+		if !ok || err != nil || rate < 1 || strings.TrimSpace(tier) == "" {
+			return nil, fmt.Errorf("invalid TIER_RATE_LIMITS entry %q: expected tier=requests_per_minute", entry)
+		}
+		cfg.TierRateLimits[strings.TrimSpace(tier)] = rate
+	}
+
+	// Concurrency limiting settings
+	if err := loadInt("MAX_INFLIGHT_PER_IP", 100, &cfg.MaxInFlightPerIP); err != nil {
+		return nil, err
+	}
+	if err := loadInt("MAX_INFLIGHT_PER_KEY", 50, &cfg.MaxInFlightPerKey); err != nil {
+		return nil, err
+	}
+	if cfg.MaxInFlightPerIP < 0 || cfg.MaxInFlightPerKey < 0 {
+		return nil, fmt.Errorf("MAX_INFLIGHT_PER_IP and MAX_INFLIGHT_PER_KEY cannot be negative")
+	}
 
 	return cfg, nil
 }
@@ -181,6 +805,22 @@ func loadDurationFromMinutes(envVar string, defaultMinutes int64, dest *time.Dur
 	return nil
 }
 
+// loadDurationFromMilliseconds loads an optional duration from milliseconds environment variable.
+func loadDurationFromMilliseconds(envVar string, defaultMilliseconds int64, dest *time.Duration) error {
+	str := os.Getenv(envVar)
+	if str == "" {
+		*dest = time.Duration(defaultMilliseconds) * time.Millisecond
+		return nil
+	}
+
+	milliseconds, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%s must be a valid integer: %w", envVar, err)
+	}
+	*dest = time.Duration(milliseconds) * time.Millisecond
+	return nil
+}
+
 // loadDurationFromSeconds loads an optional duration from seconds environment variable.
 func loadDurationFromSeconds(envVar string, defaultSeconds int64, dest *time.Duration) error {
 	str := os.Getenv(envVar)
@@ -228,3 +868,40 @@ func loadInt(envVar string, defaultValue int, dest *int) error {
 	*dest = value
 	return nil
 }
+
+// loadBool loads an optional bool from environment variable.
+func loadBool(envVar string, defaultValue bool, dest *bool) error {
+	str := os.Getenv(envVar)
+	if str == "" {
+		*dest = defaultValue
+		return nil
+	}
+
+	value, err := strconv.ParseBool(str)
+	if err != nil {
+		return fmt.Errorf("%s must be a valid boolean: %w", envVar, err)
+	}
+	*dest = value
+	return nil
+}
+
+// loadStringList loads an optional comma-separated list from environment variable.
+// Entries are trimmed and empty entries are dropped.
+func loadStringList(envVar string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(envVar), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// unsignablePaths are the /api routes signed URLs may never open: administration,
+// and the account and credential routes refusing derived tokens
+var unsignablePaths = []string{"/api/admin", "/api/keys", "/api/me", "/api/signed-urls", "/api/service-accounts"}
+
+// pathWithin reports whether path is prefix or below it on a segment boundary
+func pathWithin(path, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
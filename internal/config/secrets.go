@@ -0,0 +1,203 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// Encrypted values are either encrypted with age for the key in AGE_KEY_FILE and
+// base64 encoded behind this prefix, e.g. the output of
+// `age -r <recipient> | base64 -w0` ...
+const ageValuePrefix = "age:"
+
+// ... or values of a .env file encrypted with SOPS for an age recipient, whose data
+// key is in the sops_age__list_<n>__map_enc metadata SOPS adds to the file
+const (
+	sopsValuePrefix   = "ENC[AES256_GCM,"
+	sopsAgeKeyPrefix  = "sops_age__list_"
+	sopsAgeKeySuffix  = "__map_enc"
+	sopsDataKeyLength = 32
+)
+
+// ageHeader starts every age file
+var ageHeader = []byte("age-encryption.org/v1\n")
+
+// decryptEnv replaces encrypted environment variables with their plaintext, so
+// secrets such as JWT_SECRET and DATABASE_URL can be checked into infrastructure
+// repositories encrypted. The age identities are read from AGE_KEY_FILE, or
+// SOPS_AGE_KEY_FILE as SOPS reads them, only if any value is encrypted.
+func decryptEnv() error {
+	var ageValues, sopsValues []string
+	for _, entry := range os.Environ() {
+		name, value, _ := strings.Cut(entry, "=")
+		switch {
+		case isAgeValue(value):
+			ageValues = append(ageValues, name)
+		case strings.HasPrefix(value, sopsValuePrefix):
+			sopsValues = append(sopsValues, name)
+		}
+	}
+	if len(ageValues) == 0 && len(sopsValues) == 0 {
+		return nil
+	}
+	sort.Strings(ageValues)
+	sort.Strings(sopsValues)
+
+	identities, err := loadAgeIdentities()
+	if err != nil {
+		return fmt.Errorf("%s is encrypted: %w", append(ageValues, sopsValues...)[0], err)
+	}
+
+	for _, name := range ageValues {
+		plaintext, err := decryptAgeValue(os.Getenv(name), identities)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt %s: %w", name, err)
+		}
+		os.Setenv(name, plaintext)
+	}
+
+	if len(sopsValues) == 0 {
+		return nil
+	}
+	dataKey, err := sopsDataKey(identities)
+	if err != nil {
+		return err
+	}
+	for _, name := range sopsValues {
+		plaintext, err := decryptSOPSValue(name, os.Getenv(name), dataKey)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt %s: %w", name, err)
+		}
+		os.Setenv(name, plaintext)
+	}
+	return nil
+}
+
+// loadAgeIdentities reads the age identities of the key file
+func loadAgeIdentities() ([]age.Identity, error) {
+	path := os.Getenv("AGE_KEY_FILE")
+	if path == "" {
+		path = os.Getenv("SOPS_AGE_KEY_FILE")
+	}
+	if path == "" {
+		return nil, fmt.Errorf("AGE_KEY_FILE is required to decrypt it")
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read AGE_KEY_FILE: %w", err)
+	}
+	defer file.Close()
+	identities, err := age.ParseIdentities(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse AGE_KEY_FILE: %w", err)
+	}
+	return identities, nil
+}
+
+// isAgeValue reports whether a value is a base64 encoded age file behind the prefix.
+// Values merely starting with the prefix are left alone.
+func isAgeValue(value string) bool {
+	encoded, ok := strings.CutPrefix(value, ageValuePrefix)
+	if !ok {
+		return false
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+	return err == nil && bytes.HasPrefix(ciphertext, ageHeader)
+}
+
+// decryptAgeValue decrypts a value encrypted with age
+func decryptAgeValue(value string, identities []age.Identity) (string, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, ageValuePrefix))
+	if err != nil {
+		return "", err
+	}
+	return decryptAge(bytes.NewReader(ciphertext), identities)
+}
+
+// decryptAge decrypts an age file
+func decryptAge(src io.Reader, identities []age.Identity) (string, error) {
+	plaintext, err := age.Decrypt(src, identities...)
+	if err != nil {
+		return "", err
+	}
+	decrypted, err := io.ReadAll(plaintext)
+	if err != nil {
+		return "", err
+	}
+	return string(decrypted), nil
+}
+
+// sopsDataKey decrypts the data key of a SOPS file with whichever of its age
+// recipients the identities belong to
+func sopsDataKey(identities []age.Identity) ([]byte, error) {
+	var names []string
+	for _, entry := range os.Environ() {
+		name, _, _ := strings.Cut(entry, "=")
+		if strings.HasPrefix(name, sopsAgeKeyPrefix) && strings.HasSuffix(name, sopsAgeKeySuffix) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("SOPS encrypted values need the sops_age metadata of their file; only age recipients are supported")
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		// The dotenv format keeps the armored key on one line with escaped newlines
+		armored := strings.ReplaceAll(os.Getenv(name), `\n`, "\n")
+		key, err := decryptAge(armor.NewReader(strings.NewReader(armored)), identities)
+		if err == nil && len(key) == sopsDataKeyLength {
+			return []byte(key), nil
+		}
+	}
+	return nil, fmt.Errorf("no age identity in AGE_KEY_FILE can decrypt the SOPS data key")
+}
+
+// decryptSOPSValue decrypts a value of a .env file encrypted by SOPS, in the format
+// ENC[AES256_GCM,data:<base64>,iv:<base64>,tag:<base64>,type:<type>]. The value is
+// authenticated with its variable name, so values cannot be swapped between
+// variables. The MAC of the whole file is not checked, as the environment does
+// not keep the order of the file.
+func decryptSOPSValue(name, value string, dataKey []byte) (string, error) {
+	fields := map[string][]byte{}
+	inner := strings.TrimSuffix(strings.TrimPrefix(value, sopsValuePrefix), "]")
+	for _, field := range strings.Split(inner, ",") {
+		key, encoded, _ := strings.Cut(field, ":")
+		if key == "type" {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return "", fmt.Errorf("invalid SOPS %s", key)
+		}
+		fields[key] = decoded
+	}
+	if fields["iv"] == nil || fields["tag"] == nil {
+		return "", fmt.Errorf("invalid SOPS value")
+	}
+
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(fields["iv"]))
+	if err != nil {
+		return "", err
+	}
+	ciphertext := append(fields["data"], fields["tag"]...)
+	plaintext, err := gcm.Open(nil, fields["iv"], ciphertext, []byte(name+":"))
+	if err != nil {
+		return "", fmt.Errorf("SOPS value does not authenticate")
+	}
+	return string(plaintext), nil
+}
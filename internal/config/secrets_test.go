@@ -0,0 +1,145 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setAgeKey writes a new age identity to AGE_KEY_FILE and returns its recipient
+func setAgeKey(t *testing.T) *age.X25519Recipient {
+	t.Helper()
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "key.txt")
+	require.NoError(t, os.WriteFile(path, []byte(identity.String()+"\n"), 0o600))
+	t.Setenv("AGE_KEY_FILE", path)
+	return identity.Recipient()
+}
+
+// ageEncrypt encrypts a value as an age: value
+func ageEncrypt(t *testing.T, recipient age.Recipient, value string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, recipient)
+	require.NoError(t, err)
+	_, err = w.Write([]byte(value))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return ageValuePrefix + base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+// sopsEncrypt encrypts the value of a variable as SOPS does in a .env file
+func sopsEncrypt(t *testing.T, name, value string, dataKey []byte) string {
+	t.Helper()
+	iv := make([]byte, 32)
+	_, err := rand.Read(iv)
+	require.NoError(t, err)
+	block, err := aes.NewCipher(dataKey)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	require.NoError(t, err)
+	sealed := gcm.Seal(nil, iv, []byte(value), []byte(name+":"))
+	data, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+	encode := base64.StdEncoding.EncodeToString
+	return fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,tag:%s,type:str]", encode(data), encode(iv), encode(tag))
+}
+
+// setSOPSDataKey sets the SOPS metadata holding dataKey encrypted for recipient
+func setSOPSDataKey(t *testing.T, recipient age.Recipient, dataKey []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	armored := armor.NewWriter(&buf)
+	w, err := age.Encrypt(armored, recipient)
+	require.NoError(t, err)
+	_, err = w.Write(dataKey)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, armored.Close())
+	t.Setenv("sops_age__list_0__map_enc", strings.ReplaceAll(buf.String(), "\n", `\n`))
+}
+
+func TestLoad_AgeEncryptedValues(t *testing.T) {
+	recipient := setAgeKey(t)
+	t.Setenv("PORT", "8080")
+	t.Setenv("DATABASE_URL", ageEncrypt(t, recipient, "postgres://gatekeeper:s3cret@db/gatekeeper"))
+	t.Setenv("JWT_SECRET", ageEncrypt(t, recipient, "test-secret-key-at-least-32-chars"))
+	t.Setenv("ETHEREUM_RPC", "https://eth.example.com")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://gatekeeper:s3cret@db/gatekeeper", cfg.DatabaseURL)
+	assert.Equal(t, []byte("test-secret-key-at-least-32-chars"), cfg.JWTSecret)
+
+	// Values merely starting with the prefix are left alone
+	t.Setenv("VERSION", "age:not-encrypted")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "age:not-encrypted", cfg.Version)
+}
+
+func TestLoad_AgeEncryptedValues_WrongKey(t *testing.T) {
+	encrypted := ageEncrypt(t, setAgeKey(t), "test-secret-key-at-least-32-chars")
+	t.Setenv("PORT", "8080")
+	t.Setenv("DATABASE_URL", "postgres://localhost/gatekeeper")
+	t.Setenv("JWT_SECRET", encrypted)
+	t.Setenv("ETHEREUM_RPC", "https://eth.example.com")
+
+	setAgeKey(t)
+	_, err := Load()
+	assert.ErrorContains(t, err, "failed to decrypt JWT_SECRET")
+
+	t.Setenv("AGE_KEY_FILE", "")
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+	_, err = Load()
+	assert.ErrorContains(t, err, "JWT_SECRET is encrypted: AGE_KEY_FILE is required")
+}
+
+func TestLoad_SOPSEncryptedValues(t *testing.T) {
+	recipient := setAgeKey(t)
+	dataKey := make([]byte, sopsDataKeyLength)
+	_, err := rand.Read(dataKey)
+	require.NoError(t, err)
+	setSOPSDataKey(t, recipient, dataKey)
+
+	t.Setenv("PORT", "8080")
+	t.Setenv("DATABASE_URL", sopsEncrypt(t, "DATABASE_URL", "postgres://gatekeeper:s3cret@db/gatekeeper", dataKey))
+	t.Setenv("JWT_SECRET", sopsEncrypt(t, "JWT_SECRET", "test-secret-key-at-least-32-chars", dataKey))
+	t.Setenv("ETHEREUM_RPC", "https://eth.example.com")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://gatekeeper:s3cret@db/gatekeeper", cfg.DatabaseURL)
+	assert.Equal(t, []byte("test-secret-key-at-least-32-chars"), cfg.JWTSecret)
+
+	// A value moved to another variable does not authenticate
+	t.Setenv("JWT_SECRET", sopsEncrypt(t, "DATABASE_URL", "test-secret-key-at-least-32-chars", dataKey))
+	_, err = Load()
+	assert.ErrorContains(t, err, "failed to decrypt JWT_SECRET")
+
+	// SOPS_AGE_KEY_FILE is read as SOPS reads it
+	t.Setenv("JWT_SECRET", sopsEncrypt(t, "JWT_SECRET", "test-secret-key-at-least-32-chars", dataKey))
+	t.Setenv("SOPS_AGE_KEY_FILE", os.Getenv("AGE_KEY_FILE"))
+	t.Setenv("AGE_KEY_FILE", "")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("test-secret-key-at-least-32-chars"), cfg.JWTSecret)
+
+	// The data key must be encrypted for the identity
+	setAgeKey(t)
+	t.Setenv("JWT_SECRET", sopsEncrypt(t, "JWT_SECRET", "test-secret-key-at-least-32-chars", dataKey))
+	_, err = Load()
+	assert.ErrorContains(t, err, "can decrypt the SOPS data key")
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// redacted replaces secret values in the configuration summary
+const redacted = "[REDACTED]"
+
+// secretFieldMarkers flag fields whose values never leave the process. Matching
+// on the name keeps secrets added later out of the summary too.
+var secretFieldMarkers = []string{"Secret", "Password", "AccessKey"}
+
+// urlFields may carry credentials in the user info, path or query (e.g. RPC
+// provider API keys), so only their scheme and host are reported
+var urlFields = map[string]bool{
+	"DatabaseURL":         true,
+	"EthereumRPC":         true,
+	"EthereumRPCFallback": true,
+	"IPFSGateway":         true,
+	"NotifyWebhookURL":    true,
+	"AuditExportEndpoint": true,
+}
+
+// Summary returns the configuration keyed by field name, safe to log or serve
+// to operators: secrets are redacted, URLs reduced to scheme and host and
+// durations formatted. A redacted secret that is unset is reported as "".
+func (c *Config) Summary() map[string]interface{} {
+	summary := make(map[string]interface{})
+	value := reflect.ValueOf(c).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		summary[field.Name] = summaryValue(field.Name, value.Field(i))
+	}
+	return summary
+}
+
+// Features reports which optional features the configuration enables
+func (c *Config) Features() map[string]bool {
+	return map[string]bool{
+		"rpcFallback":          c.EthereumRPCFallback != "",
+		"rpcHedging":           c.EthereumRPCFallback != "" && c.RPCHedgePercentile > 0,
+		"rpcBatching":          c.RPCBatchWindow > 0,
+		"cacheWarming":         c.CacheWarmInterval > 0,
+		"refreshTokens":        c.RefreshTokenTTL > 0,
+		"signedURLs":           len(c.SignedURLPaths) > 0,
+		"tracing":              c.TracingEnabled,
+		"debugTiming":          c.DebugTimingEnabled,
+		"logSampling":          c.LogSamplingInitial > 0,
+		"decisionLog":          c.DecisionLogFile != "",
+		"auditExport":          c.AuditExportBucket != "",
+		"keyEscrow":            c.EscrowDir != "",
+		"webhookNotifications": c.NotifyWebhookURL != "",
+		"emailNotifications":   c.SMTPAddr != "",
+		"geoIP":                c.GeoIPDatabase != "",
+		"plugins":              c.PluginsDir != "",
+		"testnetMode":          c.TestnetMode,
+		"webauthn":             c.WebAuthnRPID != "",
+		"oidcProvider":         c.OIDCIssuer != "",
+		"enforceKeyScopes":     c.EnforceKeyScopes,
+		"enforceRouteScopes":   c.EnforceRouteScopes,
+		"apiKeyCookie":         c.APIKeyCookie != "",
+		"proxyProtocol":        c.ProxyProtocol,
+		"cors":                 len(c.CORSAllowedOrigins) > 0,
+		"hsts":                 c.HSTSMaxAge > 0,
+		"tierRateLimits":       len(c.TierRateLimits) > 0,
+	}
+}
+
+// summaryValue formats one configuration field for Summary
+func summaryValue(name string, v reflect.Value) interface{} {
+	if isSecretField(name) || v.Type() == reflect.TypeOf([]byte(nil)) {
+		if v.IsZero() || (v.Kind() == reflect.Slice && v.Len() == 0) {
+			return ""
+		}
+		return redacted
+	}
+	if urlFields[name] {
+		return redactURL(v.String())
+	}
+	if v.Kind() == reflect.Slice {
+		values := make([]interface{}, v.Len())
+		for i := range values {
+			values[i] = summaryValue("", v.Index(i))
+		}
+		return values
+	}
+	if stringer, ok := v.Interface().(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return v.Interface()
+}
+
+// isSecretField reports whether the field holds a secret
+func isSecretField(name string) bool {
+	for _, marker := range secretFieldMarkers {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactURL reduces a URL to its scheme and host
+func redactURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return redacted
+	}
+	return u.Scheme + "://" + u.Host
+}
@@ -0,0 +1,89 @@
+// Package decisionlog writes one newline-delimited JSON record per request gated
+// by the policy middleware. Unlike the audit log, which covers security events of
+// every kind, the decision log is a flat, stable format meant for SIEM and data
+// pipelines.
+package decisionlog
+
+import (
+	"encoding/json"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Decisions recorded in the decision log
+const (
+	DecisionAllowed = "allowed"
+	DecisionDenied  = "denied"
+	DecisionError   = "error" // Policy evaluation failed; the request was denied
+)
+
+// PolicyRef identifies a loaded policy
+type PolicyRef struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+}
+
+// Record is one line of the decision log
+type Record struct {
+	Timestamp time.Time  `json:"timestamp"`
+	RequestID string     `json:"request_id,omitempty"`
+	Method    string     `json:"method"`
+	Path      string     `json:"path"`
+	Subject   string     `json:"subject,omitempty"` // Wallet address or service account identity
+	ClientIP  string     `json:"client_ip,omitempty"`
+	ChainID   uint64     `json:"chain_id,omitempty"`
+	Scopes    []string   `json:"scopes,omitempty"`
+	Decision  string     `json:"decision"`
+	Reason    string     `json:"reason,omitempty"` // no_authentication, no_policies, address_blocked, policy_failed, evaluation_error or blocklist_error
+	Policies  int        `json:"policies"`         // Enforced policies matching the route
+	DeniedBy  *PolicyRef `json:"denied_by,omitempty"`
+	Tier      string     `json:"tier,omitempty"` // Granted by a TIER policy
+	LatencyMs float64    `json:"latency_ms"`     // Time spent evaluating policies
+}
+
+// Logger appends decision records to a rotating file
+type Logger struct {
+	file   *RotatingFile
+	logger *zap.Logger
+}
+
+// New opens the decision log at path. Write and rotation failures are reported to
+// logger; they never fail the request being logged.
+func New(path string, cfg RotationConfig, logger *zap.Logger) (*Logger, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	file, err := OpenRotatingFile(path, cfg)
+	if err != nil {
+		return nil, err
+	}
+	file.onError = func(err error) {
+		logger.Warn("decision log maintenance failed", zap.Error(err))
+	}
+	return &Logger{file: file, logger: logger}, nil
+}
+
+// Log appends a record, stamping it with the current time if it has none
+func (l *Logger) Log(record Record) {
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now()
+	}
+	record.Timestamp = record.Timestamp.UTC()
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		l.logger.Error("failed to encode decision record", zap.Error(err))
+		return
+	}
+	line = append(line, '\n')
+
+	if _, err := l.file.Write(line); err != nil {
+		l.logger.Error("failed to write decision record", zap.Error(err))
+	}
+}
+
+// Close closes the decision log, waiting for rotated files to be compressed
+func (l *Logger) Close() error {
+	return l.file.Close()
+}
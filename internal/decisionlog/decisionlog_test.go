@@ -0,0 +1,49 @@
+package decisionlog
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_WritesOneRecordPerLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "decisions.log")
+	logger, err := New(path, RotationConfig{}, nil)
+	require.NoError(t, err)
+
+	logger.Log(Record{
+		RequestID: "req-1",
+		Method:    "GET",
+		Path:      "/api/data",
+		Subject:   "0x1234567890abcdef1234567890abcdef12345678",
+		Decision:  DecisionDenied,
+		Reason:    "policy_failed",
+		Policies:  1,
+		DeniedBy:  &PolicyRef{Method: "GET", Path: "/api/data"},
+	})
+	logger.Log(Record{Method: "GET", Path: "/api/data", Decision: DecisionAllowed, Reason: "no_policies"})
+	require.NoError(t, logger.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	require.Len(t, lines, 2)
+
+	var first map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	assert.Equal(t, "req-1", first["request_id"])
+	assert.Equal(t, "denied", first["decision"])
+	assert.Equal(t, map[string]interface{}{"method": "GET", "path": "/api/data"}, first["denied_by"])
+
+	var second Record
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	assert.Equal(t, DecisionAllowed, second.Decision)
+	assert.WithinDuration(t, time.Now(), second.Timestamp, time.Minute)
+	assert.Equal(t, time.UTC, second.Timestamp.Location())
+}
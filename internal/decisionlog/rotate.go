@@ -0,0 +1,236 @@
+package decisionlog
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat names rotated files; it sorts chronologically
+const backupTimeFormat = "20060102T150405.000"
+
+// RotationConfig controls when a RotatingFile starts a new file and what happens
+// to the old ones
+type RotationConfig struct {
+	MaxSize    int64         // Rotate before a write would exceed this many bytes (0 disables)
+	MaxAge     time.Duration // Rotate files older than this (0 disables)
+	MaxBackups int           // Rotated files kept; older ones are deleted (0 keeps all)
+	Compress   bool          // Gzip rotated files
+}
+
+// RotatingFile is an append-only file that is renamed to a timestamped backup
+// when it grows too large or too old. Backups sit beside the file, e.g.
+// decisions.log rotates to decisions-20261017T120000.000.log(.gz).
+type RotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	cfg      RotationConfig
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	now      func() time.Time
+
+	compressing sync.WaitGroup
+	onError     func(error) // Reports failures of background compression and pruning
+}
+
+// OpenRotatingFile opens path for appending, creating it and its directory if needed
+func OpenRotatingFile(path string, cfg RotationConfig) (*RotatingFile, error) {
+	f := &RotatingFile{path: path, cfg: cfg, now: time.Now, onError: func(error) {}}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// open opens the current file, continuing an existing one
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", f.path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat %s: %w", f.path, err)
+	}
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = f.now()
+	if f.size > 0 {
+		// Age an existing file from its last write so restarts do not postpone rotation forever
+		f.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+// Write appends p, rotating first if p would exceed the size limit or the file is
+// too old. A write is never split across files.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.size > 0 && f.shouldRotate(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// shouldRotate reports whether a write of n bytes must go to a new file
+func (f *RotatingFile) shouldRotate(n int64) bool {
+	if f.cfg.MaxSize > 0 && f.size+n > f.cfg.MaxSize {
+		return true
+	}
+	return f.cfg.MaxAge > 0 && f.now().Sub(f.openedAt) >= f.cfg.MaxAge
+}
+
+// Rotate starts a new file regardless of size and age
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	return f.rotate()
+}
+
+// rotate renames the current file to a backup and opens a new one
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", f.path, err)
+	}
+	f.file = nil
+
+	backup := f.backupPath(f.now())
+	if err := os.Rename(f.path, backup); err != nil {
+		return fmt.Errorf("failed to rotate %s: %w", f.path, err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.compressing.Add(1)
+	go func() {
+		defer f.compressing.Done()
+		if f.cfg.Compress {
+			if err := compressFile(backup); err != nil {
+				f.onError(err)
+			}
+		}
+		if err := f.prune(); err != nil {
+			f.onError(err)
+		}
+	}()
+	return nil
+}
+
+// backupPath returns the name a file rotated at t is renamed to
+func (f *RotatingFile) backupPath(t time.Time) string {
+	ext := filepath.Ext(f.path)
+	base := strings.TrimSuffix(f.path, ext)
+	return fmt.Sprintf("%s-%s%s", base, t.UTC().Format(backupTimeFormat), ext)
+}
+
+// backups returns the rotated files, oldest first
+func (f *RotatingFile) backups() ([]string, error) {
+	ext := filepath.Ext(f.path)
+	prefix := filepath.Base(strings.TrimSuffix(f.path, ext)) + "-"
+
+	entries, err := os.ReadDir(filepath.Dir(f.path))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".gz"), ext)
+		if _, err := time.Parse(backupTimeFormat, stamp); err != nil {
+			continue
+		}
+		names = append(names, filepath.Join(filepath.Dir(f.path), name))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// prune deletes the oldest backups beyond MaxBackups
+func (f *RotatingFile) prune() error {
+	if f.cfg.MaxBackups <= 0 {
+		return nil
+	}
+	backups, err := f.backups()
+	if err != nil {
+		return err
+	}
+	for len(backups) > f.cfg.MaxBackups {
+		if err := os.Remove(backups[0]); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// compressFile gzips path to path.gz and removes path
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return fmt.Errorf("failed to compress %s: %w", path, err)
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return fmt.Errorf("failed to compress %s: %w", path, err)
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// Close closes the file and waits for background compression to finish
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	f.mu.Unlock()
+
+	f.compressing.Wait()
+	return err
+}
@@ -0,0 +1,151 @@
+package decisionlog
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock returns a time advanced by the test
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.t
+}
+
+func openTestFile(t *testing.T, cfg RotationConfig) (*RotatingFile, string, *fakeClock) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "decisions.log")
+	f, err := OpenRotatingFile(path, cfg)
+	require.NoError(t, err)
+	clock := &fakeClock{t: time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)}
+	f.now = clock.now
+	f.openedAt = clock.t
+	t.Cleanup(func() { f.Close() })
+	return f, path, clock
+}
+
+func TestRotatingFile_RotatesOnSize(t *testing.T) {
+	f, path, clock := openTestFile(t, RotationConfig{MaxSize: 10})
+
+	_, err := f.Write([]byte("0123456\n"))
+	require.NoError(t, err)
+	clock.t = clock.t.Add(time.Second)
+	_, err = f.Write([]byte("abcdef\n")) // Would exceed 10 bytes
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "abcdef\n", string(current))
+
+	backup, err := os.ReadFile(filepath.Join(filepath.Dir(path), "decisions-20261017T120001.000.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "0123456\n", string(backup))
+}
+
+func TestRotatingFile_OversizedWriteIsNotSplit(t *testing.T) {
+	f, path, _ := openTestFile(t, RotationConfig{MaxSize: 4})
+
+	_, err := f.Write([]byte("longer than the limit\n"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "longer than the limit\n", string(current))
+
+	backups, err := f.backups()
+	require.NoError(t, err)
+	assert.Empty(t, backups)
+}
+
+func TestRotatingFile_RotatesOnAge(t *testing.T) {
+	f, path, clock := openTestFile(t, RotationConfig{MaxAge: time.Hour})
+
+	_, err := f.Write([]byte("first\n"))
+	require.NoError(t, err)
+	clock.t = clock.t.Add(30 * time.Minute)
+	_, err = f.Write([]byte("second\n"))
+	require.NoError(t, err)
+	clock.t = clock.t.Add(30 * time.Minute)
+	_, err = f.Write([]byte("third\n"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "third\n", string(current))
+
+	backups, err := f.backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	backup, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	assert.Equal(t, "first\nsecond\n", string(backup))
+}
+
+func TestRotatingFile_CompressesBackups(t *testing.T) {
+	f, _, clock := openTestFile(t, RotationConfig{Compress: true})
+
+	_, err := f.Write([]byte("{\"decision\":\"allowed\"}\n"))
+	require.NoError(t, err)
+	clock.t = clock.t.Add(time.Minute)
+	require.NoError(t, f.Rotate())
+	require.NoError(t, f.Close())
+
+	backups, err := f.backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.Equal(t, "decisions-20261017T120100.000.log.gz", filepath.Base(backups[0]))
+
+	file, err := os.Open(backups[0])
+	require.NoError(t, err)
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	content, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"decision\":\"allowed\"}\n", string(content))
+}
+
+func TestRotatingFile_PrunesOldBackups(t *testing.T) {
+	f, path, clock := openTestFile(t, RotationConfig{MaxBackups: 2})
+
+	for i := 0; i < 4; i++ {
+		_, err := f.Write([]byte("record\n"))
+		require.NoError(t, err)
+		clock.t = clock.t.Add(time.Minute)
+		require.NoError(t, f.Rotate())
+		f.compressing.Wait()
+	}
+	require.NoError(t, f.Close())
+
+	// Unrelated files in the directory are left alone
+	unrelated := filepath.Join(filepath.Dir(path), "decisions-notes.log")
+	require.NoError(t, os.WriteFile(unrelated, []byte("keep"), 0o600))
+	require.NoError(t, f.prune())
+
+	backups, err := f.backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.Equal(t, "decisions-20261017T120300.000.log", filepath.Base(backups[0]))
+	assert.Equal(t, "decisions-20261017T120400.000.log", filepath.Base(backups[1]))
+	assert.FileExists(t, unrelated)
+}
+
+func TestRotatingFile_WriteAfterClose(t *testing.T) {
+	f, _, _ := openTestFile(t, RotationConfig{})
+	require.NoError(t, f.Close())
+
+	_, err := f.Write([]byte("late\n"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
@@ -0,0 +1,127 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// fixtureCode is the runtime code of the token fixtures. It answers every call with
+// the storage slot named by the first argument: sload(calldataload(4)). So
+// balanceOf(account) returns the slot of the account's address and ownerOf(tokenId)
+// the slot of the token ID, which the fixtures set directly.
+//
+//	PUSH1 0x04 CALLDATALOAD SLOAD PUSH1 0x00 MSTORE PUSH1 0x20 PUSH1 0x00 RETURN
+const fixtureCode = "0x6004355460005260206000f3"
+
+// Token is a token contract installed on the Anvil node
+type Token struct {
+	// Address is the lowercase contract address
+	Address string
+
+	h *Harness
+}
+
+// DeployERC20 installs an ERC-20 fixture whose balanceOf returns what SetBalance set,
+// and zero for other accounts
+func (h *Harness) DeployERC20(t *testing.T) *Token {
+	t.Helper()
+	return h.deployFixture(t)
+}
+
+// DeployERC721 installs an ERC-721 fixture whose ownerOf returns what SetOwner set,
+// and the zero address for other tokens
+func (h *Harness) DeployERC721(t *testing.T) *Token {
+	t.Helper()
+	return h.deployFixture(t)
+}
+
+// deployFixture installs fixtureCode at a fresh address
+func (h *Harness) deployFixture(t *testing.T) *Token {
+	t.Helper()
+
+	token := &Token{Address: NewWallet(t).Address, h: h}
+	if err := h.rpc(context.Background(), nil, "anvil_setCode", token.Address, fixtureCode); err != nil {
+		t.Fatalf("e2e: failed to install token fixture: %v", err)
+	}
+	return token
+}
+
+// SetBalance sets the ERC-20 balance of account
+func (tok *Token) SetBalance(t *testing.T, account string, balance *big.Int) {
+	t.Helper()
+	tok.setSlot(t, word(strings.TrimPrefix(account, "0x")), word(balance.Text(16)))
+}
+
+// SetOwner sets the owner of an ERC-721 token
+func (tok *Token) SetOwner(t *testing.T, tokenID *big.Int, owner string) {
+	t.Helper()
+	tok.setSlot(t, word(tokenID.Text(16)), word(strings.TrimPrefix(owner, "0x")))
+}
+
+// setSlot writes a storage slot of the fixture
+func (tok *Token) setSlot(t *testing.T, slot, value string) {
+	t.Helper()
+	if err := tok.h.rpc(context.Background(), nil, "anvil_setStorageAt", tok.Address, slot, value); err != nil {
+		t.Fatalf("e2e: failed to set fixture storage: %v", err)
+	}
+}
+
+// word left-pads hex digits to a 32-byte 0x-prefixed word
+func word(digits string) string {
+	return "0x" + strings.Repeat("0", 64-len(digits)) + digits
+}
+
+// rpc calls a JSON-RPC method of the Ethereum node, decoding the result into result
+// unless it is nil
+func (h *Harness) rpc(ctx context.Context, result interface{}, method string, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", h.rpcURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var rpcResp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", method, err)
+	}
+	if rpcResp.Error != nil {
+		return fmt.Errorf("%s: RPC error %d: %s", method, rpcResp.Error.Code, rpcResp.Error.Message)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(rpcResp.Result, result)
+}
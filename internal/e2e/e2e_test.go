@@ -0,0 +1,123 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(Main(m))
+}
+
+// createPolicy adds a policy through the admin API and returns its ID
+func createPolicy(t *testing.T, h *Harness, adminToken string, policy map[string]interface{}) int64 {
+	t.Helper()
+
+	resp := h.Do(t, "POST", "/api/admin/policies", adminToken, policy)
+	require.Equal(t, http.StatusCreated, resp.StatusCode, string(resp.Body))
+	var created struct {
+		ID int64 `json:"id"`
+	}
+	resp.Decode(t, &created)
+	return created.ID
+}
+
+// assertStatus checks the status of a response, showing its body on mismatch
+func assertStatus(t *testing.T, expected int, resp *Response) {
+	t.Helper()
+	assert.Equal(t, expected, resp.StatusCode, string(resp.Body))
+}
+
+func TestSignInIssuesWorkingToken(t *testing.T) {
+	h := Start(t)
+	user := NewWallet(t)
+
+	assertStatus(t, http.StatusUnauthorized, h.Do(t, "GET", "/api/data", "", nil))
+
+	token := user.SignIn(t, h)
+	resp := h.Do(t, "GET", "/api/data", token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(resp.Body))
+
+	var data struct {
+		Address string `json:"address"`
+	}
+	resp.Decode(t, &data)
+	assert.Equal(t, user.Address, data.Address)
+
+	// Only the bootstrap admin may manage policies
+	assertStatus(t, http.StatusForbidden, h.Do(t, "POST", "/api/admin/policies", token, map[string]interface{}{}))
+}
+
+func TestERC20BalancePolicy(t *testing.T) {
+	h := Start(t)
+	token := h.DeployERC20(t)
+	holder, other := NewWallet(t), NewWallet(t)
+	token.SetBalance(t, holder.Address, big.NewInt(150))
+	token.SetBalance(t, other.Address, big.NewInt(99))
+
+	createPolicy(t, h, h.Admin.SignIn(t, h), map[string]interface{}{
+		"path":   "/api/data",
+		"method": "GET",
+		"logic":  "AND",
+		"rules": []map[string]interface{}{{
+			"type":             "erc20_min_balance",
+			"contract_address": token.Address,
+			"minimum_balance":  "100",
+			"chain_id":         ChainID,
+		}},
+	})
+
+	assertStatus(t, http.StatusOK, h.Do(t, "GET", "/api/data", holder.SignIn(t, h), nil))
+	assertStatus(t, http.StatusForbidden, h.Do(t, "GET", "/api/data", other.SignIn(t, h), nil))
+}
+
+func TestERC721OwnerPolicy(t *testing.T) {
+	h := Start(t)
+	nft := h.DeployERC721(t)
+	owner, other := NewWallet(t), NewWallet(t)
+	nft.SetOwner(t, big.NewInt(7), owner.Address)
+
+	createPolicy(t, h, h.Admin.SignIn(t, h), map[string]interface{}{
+		"path":   "/api/data",
+		"method": "GET",
+		"logic":  "AND",
+		"rules": []map[string]interface{}{{
+			"type":             "erc721_owner",
+			"contract_address": nft.Address,
+			"token_id":         "7",
+			"chain_id":         ChainID,
+		}},
+	})
+
+	assertStatus(t, http.StatusOK, h.Do(t, "GET", "/api/data", owner.SignIn(t, h), nil))
+	assertStatus(t, http.StatusForbidden, h.Do(t, "GET", "/api/data", other.SignIn(t, h), nil))
+}
+
+func TestPoliciesSurviveRestart(t *testing.T) {
+	h := Start(t)
+	allowed, denied := NewWallet(t), NewWallet(t)
+
+	id := createPolicy(t, h, h.Admin.SignIn(t, h), map[string]interface{}{
+		"path":   "/api/data",
+		"method": "GET",
+		"logic":  "AND",
+		"rules": []map[string]interface{}{{
+			"type":      "in_allowlist",
+			"addresses": []string{allowed.Address},
+		}},
+	})
+
+	h.Restart(t)
+
+	assertStatus(t, http.StatusOK, h.Do(t, "GET", fmt.Sprintf("/api/admin/policies/%d", id), h.Admin.SignIn(t, h), nil))
+	assertStatus(t, http.StatusOK, h.Do(t, "GET", "/api/data", allowed.SignIn(t, h), nil))
+	assertStatus(t, http.StatusForbidden, h.Do(t, "GET", "/api/data", denied.SignIn(t, h), nil))
+}
@@ -0,0 +1,81 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Wallet is an Ethereum account that signs in with SIWE
+type Wallet struct {
+	// Address is the lowercase address of the account
+	Address string
+
+	key *ecdsa.PrivateKey
+}
+
+// NewWallet creates a wallet with a fresh key
+func NewWallet(t *testing.T) *Wallet {
+	t.Helper()
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("e2e: failed to generate key: %v", err)
+	}
+	return &Wallet{
+		Address: strings.ToLower(crypto.PubkeyToAddress(key.PublicKey).Hex()),
+		key:     key,
+	}
+}
+
+// SignMessage signs message as personal_sign does, returning the hex signature
+func (w *Wallet) SignMessage(t *testing.T, message string) string {
+	t.Helper()
+
+	hash := crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)))
+	sig, err := crypto.Sign(hash, w.key)
+	if err != nil {
+		t.Fatalf("e2e: failed to sign message: %v", err)
+	}
+	sig[64] += 27
+	return "0x" + hex.EncodeToString(sig)
+}
+
+// SignIn signs in to the server with SIWE and returns the access token
+func (w *Wallet) SignIn(t *testing.T, h *Harness) string {
+	t.Helper()
+
+	resp := h.Do(t, "GET", "/auth/siwe/nonce", "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("e2e: nonce request failed with %d: %s", resp.StatusCode, resp.Body)
+	}
+	var nonce struct {
+		Nonce string `json:"nonce"`
+	}
+	resp.Decode(t, &nonce)
+
+	message := fmt.Sprintf("localhost wants you to sign in with your Ethereum account:\n%s\n\n"+
+		"Sign in to Gatekeeper\n\nURI: http://localhost\nVersion: 1\nChain ID: %d\nNonce: %s\nIssued At: %s",
+		w.Address, ChainID, nonce.Nonce, time.Now().UTC().Format(time.RFC3339))
+
+	resp = h.Do(t, "POST", "/auth/siwe/verify", "", map[string]string{
+		"message":   message,
+		"signature": w.SignMessage(t, message),
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("e2e: sign-in failed with %d: %s", resp.StatusCode, resp.Body)
+	}
+	var verified struct {
+		Token string `json:"token"`
+	}
+	resp.Decode(t, &verified)
+	return verified.Token
+}
@@ -0,0 +1,230 @@
+// Package escrow keeps an encrypted, append-only record of the key material other
+// systems depend on: the hashes and metadata of API keys and the JWT signing keys.
+// If the database is lost, the latest snapshot restores every API key, so keys held
+// by integrators keep working, and the signing keys, so issued tokens stay valid.
+//
+// Each snapshot is encrypted with a fresh data key, which a KeyWrapper wraps with a
+// key-encryption key kept outside the escrow, as with a KMS. Snapshots are only ever
+// added; gatekeeper never overwrites or deletes one.
+package escrow
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// envelopeVersion is the version of the stored snapshot format
+const envelopeVersion = 1
+
+// Uses of escrowed signing keys
+const (
+	SigningKeyJWTSecret     = "jwt_secret"      // HS256 token and signed URL secret
+	SigningKeyJWTPrivateKey = "jwt_private_key" // PEM private key of an asymmetric JWT algorithm
+)
+
+// ErrNoSnapshots is returned when the escrow holds no snapshot yet
+var ErrNoSnapshots = errors.New("escrow holds no snapshots")
+
+// SigningKey is escrowed signing key material
+type SigningKey struct {
+	Use       string `json:"use"` // One of the SigningKey constants
+	Algorithm string `json:"algorithm"`
+	KeyID     string `json:"keyId,omitempty"` // kid of tokens signed with an asymmetric key
+	Material  []byte `json:"material"`        // The secret, or the PEM encoded private key
+}
+
+// Snapshot is the key material escrowed at one point in time
+type Snapshot struct {
+	CreatedAt   time.Time              `json:"createdAt"`
+	SigningKeys []SigningKey           `json:"signingKeys"`
+	APIKeys     []store.EscrowedAPIKey `json:"apiKeys"`
+}
+
+// envelope is the stored form of a snapshot: the snapshot encrypted with AES-256-GCM
+// under a data key, and the data key wrapped by the key-encryption key
+type envelope struct {
+	Version    int       `json:"version"`
+	CreatedAt  time.Time `json:"createdAt"`
+	KEKID      string    `json:"kekId"` // Identifies the key-encryption key
+	WrappedKey []byte    `json:"wrappedKey"`
+	Nonce      []byte    `json:"nonce"`
+	Ciphertext []byte    `json:"ciphertext"`
+}
+
+// Escrow encrypts snapshots into a store and decrypts them back
+type Escrow struct {
+	store   Store
+	wrapper KeyWrapper
+	now     func() time.Time
+	seq     atomic.Uint64 // Orders snapshots deposited at the same time
+}
+
+// New creates an escrow keeping snapshots in store, wrapping their data keys with wrapper
+func New(store Store, wrapper KeyWrapper) *Escrow {
+	return &Escrow{
+		store:   store,
+		wrapper: wrapper,
+		now:     time.Now,
+	}
+}
+
+// Deposit encrypts the snapshot and adds it to the store, returning its name.
+// Snapshots without a creation time are stamped with the current time.
+func (e *Escrow) Deposit(ctx context.Context, snapshot *Snapshot) (string, error) {
+	if snapshot.CreatedAt.IsZero() {
+		snapshot.CreatedAt = e.now().UTC()
+	}
+	plaintext, err := json.Marshal(snapshot)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	wrappedKey, err := e.wrapper.WrapKey(ctx, dataKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to wrap data key: %w", err)
+	}
+
+	env := envelope{
+		Version:    envelopeVersion,
+		CreatedAt:  snapshot.CreatedAt,
+		KEKID:      e.wrapper.KeyID(),
+		WrappedKey: wrappedKey,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, plaintext, additionalData(envelopeVersion)),
+	}
+	data, err := json.Marshal(env)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode envelope: %w", err)
+	}
+
+	name, err := e.snapshotName(snapshot.CreatedAt)
+	if err != nil {
+		return "", err
+	}
+	if err := e.store.Append(ctx, name, data); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// Load decrypts the snapshot stored under name
+func (e *Escrow) Load(ctx context.Context, name string) (*Snapshot, error) {
+	data, err := e.store.Read(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("invalid snapshot %s: %w", name, err)
+	}
+	if env.Version != envelopeVersion {
+		return nil, fmt.Errorf("snapshot %s has unsupported version %d", name, env.Version)
+	}
+	if env.KEKID != e.wrapper.KeyID() {
+		return nil, fmt.Errorf("snapshot %s is wrapped with key %s, not the configured key %s", name, env.KEKID, e.wrapper.KeyID())
+	}
+
+	dataKey, err := e.wrapper.UnwrapKey(ctx, env.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key of snapshot %s: %w", name, err)
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(env.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid snapshot %s: bad nonce", name)
+	}
+	plaintext, err := aead.Open(nil, env.Nonce, env.Ciphertext, additionalData(env.Version))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt snapshot %s: %w", name, err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(plaintext, &snapshot); err != nil {
+		return nil, fmt.Errorf("invalid snapshot %s: %w", name, err)
+	}
+	return &snapshot, nil
+}
+
+// Latest decrypts the most recent snapshot, returning its name, or ErrNoSnapshots
+func (e *Escrow) Latest(ctx context.Context) (string, *Snapshot, error) {
+	names, err := e.store.List(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(names) == 0 {
+		return "", nil, ErrNoSnapshots
+	}
+	name := names[len(names)-1]
+	snapshot, err := e.Load(ctx, name)
+	if err != nil {
+		return "", nil, err
+	}
+	return name, snapshot, nil
+}
+
+// Restore inserts the API keys of a snapshot that the repository does not have,
+// returning how many were restored and how many already existed
+func Restore(ctx context.Context, repo store.KeyEscrowRepositoryInterface, snapshot *Snapshot) (restored, existing int, err error) {
+	for _, key := range snapshot.APIKeys {
+		inserted, err := repo.RestoreAPIKey(ctx, key)
+		if err != nil {
+			return restored, existing, fmt.Errorf("failed to restore API key %q of %s: %w", key.Name, key.OwnerAddress, err)
+		}
+		if inserted {
+			restored++
+		} else {
+			existing++
+		}
+	}
+	return restored, existing, nil
+}
+
+// snapshotName names a snapshot created at t. Names sort by creation time to the
+// nanosecond, then by the order this escrow deposited them, and end with a random
+// suffix so replicas never collide.
+func (e *Escrow) snapshotName(t time.Time) (string, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate snapshot name: %w", err)
+	}
+	return fmt.Sprintf("%s-%016x-%s.json", t.UTC().Format("20060102T150405.000000000Z"), e.seq.Add(1), hex.EncodeToString(suffix)), nil
+}
+
+// additionalData binds the ciphertext to the envelope format
+func additionalData(version int) []byte {
+	return []byte(fmt.Sprintf("gatekeeper-escrow:v%d", version))
+}
+
+// newAEAD returns AES-256-GCM with key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
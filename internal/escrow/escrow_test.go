@@ -0,0 +1,172 @@
+package escrow
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+const testOwner = "0x742d35cc6634c0532925a3b844bc9e7595f0beb0"
+
+func newTestEscrow(t *testing.T, kek byte) (*Escrow, *DirStore) {
+	t.Helper()
+	dirStore, err := NewDirStore(t.TempDir())
+	require.NoError(t, err)
+	wrapper, err := NewAESKeyWrapper(bytes.Repeat([]byte{kek}, 32))
+	require.NoError(t, err)
+	return New(dirStore, wrapper), dirStore
+}
+
+func testSnapshot() *Snapshot {
+	return &Snapshot{
+		SigningKeys: []SigningKey{{Use: SigningKeyJWTSecret, Algorithm: "HS256", Material: []byte("super-secret-jwt-key")}},
+		APIKeys: []store.EscrowedAPIKey{
+			{KeyHash: "ab12", Name: "ci", Scopes: []string{"read"}, CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), OwnerAddress: testOwner},
+			{KeyHash: "cd34", Name: "deploy", Scopes: []string{}, CreatedAt: time.Date(2026, 2, 3, 4, 5, 6, 0, time.UTC), OwnerAddress: testOwner, ServiceAccount: "deployer"},
+		},
+	}
+}
+
+func TestEscrow_DepositAndLoad(t *testing.T) {
+	e, dirStore := newTestEscrow(t, 1)
+	ctx := context.Background()
+
+	name, err := e.Deposit(ctx, testSnapshot())
+	require.NoError(t, err)
+
+	// Nothing is stored in the clear
+	data, err := dirStore.Read(ctx, name)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "super-secret-jwt-key")
+	assert.NotContains(t, string(data), "deployer")
+
+	snapshot, err := e.Load(ctx, name)
+	require.NoError(t, err)
+	assert.False(t, snapshot.CreatedAt.IsZero())
+	assert.Equal(t, testSnapshot().SigningKeys, snapshot.SigningKeys)
+	assert.Equal(t, testSnapshot().APIKeys, snapshot.APIKeys)
+}
+
+func TestEscrow_Latest(t *testing.T) {
+	e, _ := newTestEscrow(t, 1)
+	ctx := context.Background()
+
+	_, _, err := e.Latest(ctx)
+	assert.ErrorIs(t, err, ErrNoSnapshots)
+
+	older := testSnapshot()
+	older.CreatedAt = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	_, err = e.Deposit(ctx, older)
+	require.NoError(t, err)
+	newer := testSnapshot()
+	newer.CreatedAt = time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	newer.APIKeys = newer.APIKeys[:1]
+	newest, err := e.Deposit(ctx, newer)
+	require.NoError(t, err)
+
+	name, snapshot, err := e.Latest(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, newest, name)
+	assert.Len(t, snapshot.APIKeys, 1)
+}
+
+// TestEscrow_Latest_SameTime keeps the deposit order of snapshots created at once
+func TestEscrow_Latest_SameTime(t *testing.T) {
+	e, _ := newTestEscrow(t, 1)
+	ctx := context.Background()
+
+	var last string
+	for i := 0; i < 20; i++ {
+		snapshot := testSnapshot()
+		snapshot.CreatedAt = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+		snapshot.APIKeys = snapshot.APIKeys[:i%2]
+		name, err := e.Deposit(ctx, snapshot)
+		require.NoError(t, err)
+		assert.Greater(t, name, last)
+		last = name
+	}
+
+	name, snapshot, err := e.Latest(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, last, name)
+	assert.Len(t, snapshot.APIKeys, 1)
+}
+
+func TestEscrow_Load_WrongKey(t *testing.T) {
+	e, dirStore := newTestEscrow(t, 1)
+	name, err := e.Deposit(context.Background(), testSnapshot())
+	require.NoError(t, err)
+
+	wrapper, err := NewAESKeyWrapper(bytes.Repeat([]byte{2}, 32))
+	require.NoError(t, err)
+	_, err = New(dirStore, wrapper).Load(context.Background(), name)
+	assert.ErrorContains(t, err, "wrapped with key")
+}
+
+func TestNewAESKeyWrapper_KeySize(t *testing.T) {
+	_, err := NewAESKeyWrapper([]byte("short"))
+	assert.Error(t, err)
+}
+
+func TestDirStore_AppendOnly(t *testing.T) {
+	dir := t.TempDir()
+	dirStore, err := NewDirStore(dir)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, dirStore.Append(ctx, "b.json", []byte("first")))
+	require.NoError(t, dirStore.Append(ctx, "a.json", []byte("second")))
+	assert.Error(t, dirStore.Append(ctx, "b.json", []byte("replaced")))
+	assert.Error(t, dirStore.Append(ctx, "../escape.json", []byte("escaped")))
+
+	data, err := dirStore.Read(ctx, "b.json")
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(data))
+
+	names, err := dirStore.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.json", "b.json"}, names)
+
+	info, err := os.Stat(filepath.Join(dir, "b.json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o400), info.Mode().Perm())
+}
+
+// fakeEscrowRepo is an in-memory KeyEscrowRepositoryInterface
+type fakeEscrowRepo struct {
+	keys  []store.EscrowedAPIKey
+	lists int
+}
+
+func (r *fakeEscrowRepo) ListEscrowedAPIKeys(ctx context.Context) ([]store.EscrowedAPIKey, error) {
+	r.lists++
+	return r.keys, nil
+}
+
+func (r *fakeEscrowRepo) RestoreAPIKey(ctx context.Context, key store.EscrowedAPIKey) (bool, error) {
+	for _, existing := range r.keys {
+		if existing.KeyHash == key.KeyHash {
+			return false, nil
+		}
+	}
+	r.keys = append(r.keys, key)
+	return true, nil
+}
+
+func TestRestore(t *testing.T) {
+	snapshot := testSnapshot()
+	repo := &fakeEscrowRepo{keys: snapshot.APIKeys[:1]}
+
+	restored, existing, err := Restore(context.Background(), repo, snapshot)
+	require.NoError(t, err)
+	assert.Equal(t, 1, restored)
+	assert.Equal(t, 1, existing)
+	assert.Len(t, repo.keys, 2)
+}
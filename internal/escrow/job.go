@@ -0,0 +1,119 @@
+package escrow
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yourusername/gatekeeper/internal/jobs"
+	"github.com/yourusername/gatekeeper/internal/store"
+	"go.uber.org/zap"
+)
+
+// Job periodically deposits a snapshot of the API keys and signing keys. A snapshot
+// is only deposited when the key material changed since the last one, so the
+// append-only store grows with key changes rather than with time.
+type Job struct {
+	escrow      *Escrow
+	repo        store.KeyEscrowRepositoryInterface
+	signingKeys []SigningKey
+	interval    time.Duration
+	logger      *zap.Logger
+
+	mu         sync.Mutex
+	lastDigest [sha256.Size]byte // Digest of the key material of the latest snapshot
+	loaded     bool              // lastDigest reflects the store
+
+	job *jobs.Job
+}
+
+// NewJob creates a job that every interval escrows the API keys of repo together
+// with signingKeys
+func NewJob(escrow *Escrow, repo store.KeyEscrowRepositoryInterface, signingKeys []SigningKey, interval time.Duration, logger *zap.Logger) *Job {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Job{
+		escrow:      escrow,
+		repo:        repo,
+		signingKeys: signingKeys,
+		interval:    interval,
+		logger:      logger,
+	}
+}
+
+// Start runs the job immediately and then every interval until Stop is called
+func (j *Job) Start() {
+	j.job = jobs.Every("key escrow", j.interval, j.runOnce, j.logger)
+}
+
+// Stop stops the job, waiting for a run in progress to be cancelled
+func (j *Job) Stop() {
+	j.job.Stop()
+}
+
+// runOnce runs the job once, logging what it did
+func (j *Job) runOnce(ctx context.Context) error {
+	name, err := j.Run(ctx)
+	if err != nil {
+		return err
+	}
+	if name != "" {
+		j.logger.Info("escrowed key material", zap.String("snapshot", name))
+	}
+	return nil
+}
+
+// Run deposits a snapshot if the key material changed, returning its name, or ""
+// if nothing changed
+func (j *Job) Run(ctx context.Context) (string, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	apiKeys, err := j.repo.ListEscrowedAPIKeys(ctx)
+	if err != nil {
+		return "", err
+	}
+	snapshot := &Snapshot{SigningKeys: j.signingKeys, APIKeys: apiKeys}
+	current, err := digest(snapshot)
+	if err != nil {
+		return "", err
+	}
+
+	// After a restart, compare with the latest snapshot already escrowed
+	if !j.loaded {
+		_, latest, err := j.escrow.Latest(ctx)
+		switch {
+		case err == nil:
+			if j.lastDigest, err = digest(latest); err != nil {
+				return "", err
+			}
+		case !errors.Is(err, ErrNoSnapshots):
+			j.logger.Warn("failed to read the latest key escrow snapshot", zap.Error(err))
+		}
+		j.loaded = true
+	}
+	if current == j.lastDigest {
+		return "", nil
+	}
+
+	name, err := j.escrow.Deposit(ctx, snapshot)
+	if err != nil {
+		return "", err
+	}
+	j.lastDigest = current
+	return name, nil
+}
+
+// digest hashes the key material of a snapshot, leaving out its creation time
+func digest(snapshot *Snapshot) ([sha256.Size]byte, error) {
+	material, err := json.Marshal(Snapshot{SigningKeys: snapshot.SigningKeys, APIKeys: snapshot.APIKeys})
+	if err != nil {
+		return [sha256.Size]byte{}, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	return sha256.Sum256(material), nil
+}
@@ -0,0 +1,41 @@
+package escrow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+func TestJob_DepositsOnlyChanges(t *testing.T) {
+	e, dirStore := newTestEscrow(t, 1)
+	repo := &fakeEscrowRepo{keys: testSnapshot().APIKeys}
+	signingKeys := testSnapshot().SigningKeys
+	ctx := context.Background()
+
+	job := NewJob(e, repo, signingKeys, time.Hour, nil)
+	first, err := job.Run(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, first)
+
+	unchanged, err := job.Run(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, unchanged)
+
+	repo.keys = append(repo.keys, store.EscrowedAPIKey{KeyHash: "ef56", Name: "new", Scopes: []string{}, OwnerAddress: testOwner})
+	changed, err := job.Run(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, changed)
+
+	// A restarted job compares with the latest snapshot in the store
+	restarted, err := NewJob(e, repo, signingKeys, time.Hour, nil).Run(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, restarted)
+
+	names, err := dirStore.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, names, 2)
+}
@@ -0,0 +1,108 @@
+package escrow
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Store keeps escrowed snapshots. It is append-only: Append never replaces an
+// existing snapshot.
+type Store interface {
+	// Append stores data under name, failing if name already exists
+	Append(ctx context.Context, name string, data []byte) error
+	// List returns the names of the stored snapshots, oldest first
+	List(ctx context.Context) ([]string, error)
+	Read(ctx context.Context, name string) ([]byte, error)
+}
+
+// DirStore keeps snapshots as read-only files in a directory, such as a volume
+// replicated off-site or with object lock
+type DirStore struct {
+	dir string
+}
+
+// Ensure DirStore implements Store
+var _ Store = (*DirStore)(nil)
+
+// NewDirStore creates a store in dir, creating the directory if needed
+func NewDirStore(dir string) (*DirStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create escrow directory: %w", err)
+	}
+	return &DirStore{dir: dir}, nil
+}
+
+// Append writes the snapshot to a temporary file and links it into place, so a
+// snapshot is never seen half-written and an existing one is never replaced
+func (s *DirStore) Append(ctx context.Context, name string, data []byte) error {
+	if err := validName(name); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".pending-*")
+	if err != nil {
+		return fmt.Errorf("failed to create escrow file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write escrow file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write escrow file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write escrow file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o400); err != nil {
+		return fmt.Errorf("failed to protect escrow file: %w", err)
+	}
+
+	if err := os.Link(tmp.Name(), filepath.Join(s.dir, name)); err != nil {
+		return fmt.Errorf("failed to store snapshot %s: %w", name, err)
+	}
+	return nil
+}
+
+// List returns the snapshot files, oldest first
+func (s *DirStore) List(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list escrow directory: %w", err)
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && validName(entry.Name()) == nil {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Read returns the snapshot stored under name
+func (s *DirStore) Read(ctx context.Context, name string) ([]byte, error) {
+	if err := validName(name); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(s.dir, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// validName checks that name is a snapshot file name without a path
+func validName(name string) error {
+	if !strings.HasSuffix(name, ".json") || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid snapshot name %q", name)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package escrow
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// KeyWrapper wraps and unwraps data keys with a key-encryption key that never
+// leaves it. A KMS client implements it by calling the KMS's encrypt and decrypt
+// operations, so the key-encryption key stays in the KMS.
+type KeyWrapper interface {
+	// KeyID identifies the key-encryption key; snapshots record it
+	KeyID() string
+	WrapKey(ctx context.Context, key []byte) ([]byte, error)
+	UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// AESKeyWrapper wraps data keys with AES-256-GCM under a key-encryption key held
+// in memory, typically issued by a KMS or secret manager to the deployment
+type AESKeyWrapper struct {
+	aead  cipher.AEAD
+	keyID string
+}
+
+// Ensure AESKeyWrapper implements KeyWrapper
+var _ KeyWrapper = (*AESKeyWrapper)(nil)
+
+// NewAESKeyWrapper creates a wrapper for a 32-byte key-encryption key
+func NewAESKeyWrapper(kek []byte) (*AESKeyWrapper, error) {
+	if len(kek) != 32 {
+		return nil, fmt.Errorf("key-encryption key must be 32 bytes, got %d", len(kek))
+	}
+	aead, err := newAEAD(kek)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(kek)
+	return &AESKeyWrapper{aead: aead, keyID: hex.EncodeToString(sum[:8])}, nil
+}
+
+// KeyID returns an identifier derived from the key, which does not reveal it
+func (w *AESKeyWrapper) KeyID() string {
+	return w.keyID
+}
+
+// WrapKey encrypts a data key, prefixing the nonce
+func (w *AESKeyWrapper) WrapKey(ctx context.Context, key []byte) ([]byte, error) {
+	nonce := make([]byte, w.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return w.aead.Seal(nonce, nonce, key, nil), nil
+}
+
+// UnwrapKey decrypts a data key wrapped by WrapKey
+func (w *AESKeyWrapper) UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < w.aead.NonceSize() {
+		return nil, fmt.Errorf("wrapped key too short")
+	}
+	nonce, ciphertext := wrapped[:w.aead.NonceSize()], wrapped[w.aead.NonceSize():]
+	return w.aead.Open(nil, nonce, ciphertext, nil)
+}
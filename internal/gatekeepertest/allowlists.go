@@ -0,0 +1,331 @@
+package gatekeepertest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// CreateAllowlist creates a new allowlist
+func (s *Store) CreateAllowlist(ctx context.Context, name, description string) (*store.Allowlist, error) {
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkAllowlistName(0, name); err != nil {
+		return nil, err
+	}
+
+	s.nextList++
+	allowlist := &store.Allowlist{
+		ID:          s.nextList,
+		Name:        name,
+		Description: description,
+	}
+	allowlist.CreatedAt = s.tick()
+	allowlist.UpdatedAt = allowlist.CreatedAt
+	s.lists[allowlist.ID] = allowlist
+	s.entries[allowlist.ID] = make(map[string]store.AllowlistEntry)
+
+	copied := *allowlist
+	return &copied, nil
+}
+
+// GetAllowlist retrieves an allowlist by ID
+func (s *Store) GetAllowlist(ctx context.Context, id int64) (*store.Allowlist, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	allowlist, err := s.allowlist(id)
+	if err != nil {
+		return nil, err
+	}
+	copied := *allowlist
+	return &copied, nil
+}
+
+// ListAllowlists returns all allowlists with entry counts, newest first
+func (s *Store) ListAllowlists(ctx context.Context) ([]store.AllowlistWithCount, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	allowlists := []store.AllowlistWithCount{}
+	for id, allowlist := range s.lists {
+		allowlists = append(allowlists, store.AllowlistWithCount{
+			Allowlist:  *allowlist,
+			EntryCount: int64(len(s.entries[id])),
+		})
+	}
+	sort.Slice(allowlists, func(i, j int) bool { return allowlists[i].ID > allowlists[j].ID })
+	return allowlists, nil
+}
+
+// UpdateAllowlist updates an allowlist's name and description and sets its
+// UpdatedAt
+func (s *Store) UpdateAllowlist(ctx context.Context, allowlist *store.Allowlist) error {
+	if allowlist == nil {
+		return fmt.Errorf("allowlist cannot be nil")
+	}
+	if allowlist.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.allowlist(allowlist.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkAllowlistName(allowlist.ID, allowlist.Name); err != nil {
+		return err
+	}
+
+	stored.Name = allowlist.Name
+	stored.Description = allowlist.Description
+	stored.UpdatedAt = s.tick()
+	allowlist.UpdatedAt = stored.UpdatedAt
+	return nil
+}
+
+// DeleteAllowlist deletes an allowlist and all its entries
+func (s *Store) DeleteAllowlist(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.allowlist(id); err != nil {
+		return err
+	}
+	delete(s.lists, id)
+	delete(s.entries, id)
+	return nil
+}
+
+// AddAddress adds a single address to an allowlist; adding an address already on
+// the allowlist does nothing
+func (s *Store) AddAddress(ctx context.Context, allowlistID int64, address string) error {
+	return s.AddAddresses(ctx, allowlistID, []string{address})
+}
+
+// RemoveAddress removes an address from an allowlist
+func (s *Store) RemoveAddress(ctx context.Context, allowlistID int64, address string) error {
+	normalized, err := normalizeAddress(address)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := s.entries[allowlistID]
+	if _, ok := entries[normalized]; !ok {
+		return &store.NotFoundError{
+			Resource: "allowlist_entry",
+			ID:       fmt.Sprintf("allowlist_id=%d, address=%s", allowlistID, normalized),
+		}
+	}
+	delete(entries, normalized)
+	s.lists[allowlistID].UpdatedAt = s.tick()
+	s.recordAddressChange(allowlistID, store.ChangeAddressRemoved, []string{normalized})
+	return nil
+}
+
+// AddAddresses adds multiple addresses to an allowlist. Either every address is
+// valid and added, or none is.
+func (s *Store) AddAddresses(ctx context.Context, allowlistID int64, addresses []string) error {
+	if len(addresses) == 0 {
+		return nil
+	}
+
+	normalizedAddresses := make([]string, len(addresses))
+	for i, addr := range addresses {
+		normalized, err := normalizeAddress(addr)
+		if err != nil {
+			if len(addresses) == 1 {
+				return err
+			}
+			return fmt.Errorf("invalid address at index %d: %w", i, err)
+		}
+		normalizedAddresses[i] = normalized
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	allowlist, err := s.allowlist(allowlistID)
+	if err != nil {
+		return err
+	}
+
+	addedAt := s.tick()
+	entries := s.entries[allowlistID]
+	var added []string
+	for _, addr := range normalizedAddresses {
+		if _, ok := entries[addr]; ok {
+			continue
+		}
+		s.nextEntry++
+		entries[addr] = store.AllowlistEntry{
+			ID:          s.nextEntry,
+			AllowlistID: allowlistID,
+			Address:     addr,
+			AddedAt:     addedAt,
+		}
+		added = append(added, addr)
+	}
+	allowlist.UpdatedAt = addedAt
+	if len(added) > 0 {
+		s.recordAddressChange(allowlistID, store.ChangeAddressesAdded, added)
+	}
+	return nil
+}
+
+// CheckAddress checks if an address is in an allowlist
+func (s *Store) CheckAddress(ctx context.Context, allowlistID int64, address string) (bool, error) {
+	normalized, err := normalizeAddress(address)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.entries[allowlistID][normalized]
+	return ok, nil
+}
+
+// FindAddresses returns which of the given addresses are in an allowlist,
+// normalized and sorted
+func (s *Store) FindAddresses(ctx context.Context, allowlistID int64, addresses []string) ([]string, error) {
+	normalizedAddresses := make([]string, len(addresses))
+	for i, addr := range addresses {
+		normalized, err := normalizeAddress(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address at index %d: %w", i, err)
+		}
+		normalizedAddresses[i] = normalized
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := []string{}
+	seen := make(map[string]bool)
+	for _, addr := range normalizedAddresses {
+		if _, ok := s.entries[allowlistID][addr]; ok && !seen[addr] {
+			seen[addr] = true
+			found = append(found, addr)
+		}
+	}
+	sort.Strings(found)
+	return found, nil
+}
+
+// GetAddresses returns all addresses in an allowlist, sorted alphabetically
+func (s *Store) GetAddresses(ctx context.Context, allowlistID int64) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	addresses := []string{}
+	for addr := range s.entries[allowlistID] {
+		addresses = append(addresses, addr)
+	}
+	sort.Strings(addresses)
+	return addresses, nil
+}
+
+// StreamEntries calls fn for each entry of an allowlist, sorted by address.
+// Iteration stops at the first error returned by fn. fn is called without the
+// store locked, so it may use the store.
+func (s *Store) StreamEntries(ctx context.Context, allowlistID int64, fn func(store.AllowlistEntry) error) error {
+	s.mu.Lock()
+	entries := make([]store.AllowlistEntry, 0, len(s.entries[allowlistID]))
+	for _, entry := range s.entries[allowlistID] {
+		entries = append(entries, entry)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Address < entries[j].Address })
+	for _, entry := range entries {
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListAddressChanges returns up to limit address changes of an allowlist recorded
+// after the change with ID afterID, oldest first
+func (s *Store) ListAddressChanges(ctx context.Context, allowlistID, afterID int64, limit int) ([]store.AddressChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changes := []store.AddressChange{}
+	for _, change := range s.changes[allowlistID] {
+		if len(changes) == limit {
+			break
+		}
+		if change.ID > afterID {
+			change.Addresses = append([]string(nil), change.Addresses...)
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+// LatestAddressChangeID returns the ID of the latest address change of an
+// allowlist, or 0 if its addresses were never changed
+func (s *Store) LatestAddressChangeID(ctx context.Context, allowlistID int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changes := s.changes[allowlistID]
+	if len(changes) == 0 {
+		return 0, nil
+	}
+	return changes[len(changes)-1].ID, nil
+}
+
+// recordAddressChange appends a change to the address changes of an allowlist,
+// at the current time. Callers hold s.mu.
+func (s *Store) recordAddressChange(allowlistID int64, action string, addresses []string) {
+	s.nextChange++
+	s.changes[allowlistID] = append(s.changes[allowlistID], store.AddressChange{
+		ID:        s.nextChange,
+		Action:    action,
+		Addresses: addresses,
+		CreatedAt: s.now,
+	})
+}
+
+// allowlist returns the stored allowlist with the given ID. Callers hold s.mu.
+func (s *Store) allowlist(id int64) (*store.Allowlist, error) {
+	allowlist, ok := s.lists[id]
+	if !ok {
+		return nil, &store.NotFoundError{
+			Resource: "allowlist",
+			ID:       id,
+		}
+	}
+	return allowlist, nil
+}
+
+// checkAllowlistName fails if an allowlist other than id already has name.
+// Callers hold s.mu.
+func (s *Store) checkAllowlistName(id int64, name string) error {
+	for _, allowlist := range s.lists {
+		if allowlist.ID != id && allowlist.Name == name {
+			return &store.DuplicateError{
+				Resource: "allowlist",
+				Field:    "name",
+				Value:    name,
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,302 @@
+package gatekeepertest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// RawAPIKey returns the raw key the store hands out for the API key with the
+// given ID, so tests can authenticate with keys they did not create themselves
+func RawAPIKey(id int64) string {
+	sum := sha256.Sum256([]byte("gatekeepertest:" + strconv.FormatInt(id, 10)))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIKey stores a new API key and returns its raw key, see RawAPIKey
+func (s *Store) CreateAPIKey(ctx context.Context, req store.APIKeyCreateRequest) (string, *store.APIKeyResponse, error) {
+	if req.UserID == 0 {
+		return "", nil, fmt.Errorf("user_id is required")
+	}
+	if req.Name == "" {
+		return "", nil, fmt.Errorf("name is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[req.UserID]; !ok {
+		return "", nil, &store.NotFoundError{
+			Resource: "user",
+			ID:       req.UserID,
+		}
+	}
+
+	s.nextKey++
+	rawKey := RawAPIKey(s.nextKey)
+	key := &store.APIKey{
+		ID:      s.nextKey,
+		UserID:  req.UserID,
+		KeyHash: store.HashAPIKey(rawKey),
+		Name:    req.Name,
+		Scopes:  append([]string{}, req.Scopes...),
+	}
+	key.CreatedAt = s.tick()
+	key.UpdatedAt = key.CreatedAt
+	if req.ExpiresIn != nil {
+		expiresAt := key.CreatedAt.Add(*req.ExpiresIn)
+		key.ExpiresAt = &expiresAt
+	}
+	s.keys[key.ID] = key
+
+	return rawKey, &store.APIKeyResponse{
+		ID:        key.ID,
+		KeyHash:   key.KeyHash,
+		Name:      key.Name,
+		Scopes:    append([]string{}, key.Scopes...),
+		ExpiresAt: key.ExpiresAt,
+		CreatedAt: key.CreatedAt,
+	}, nil
+}
+
+// ValidateAPIKey verifies an API key and returns the associated key metadata
+func (s *Store) ValidateAPIKey(ctx context.Context, rawKey string) (*store.APIKey, error) {
+	if rawKey == "" {
+		return nil, fmt.Errorf("API key cannot be empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := s.keyByHash(store.HashAPIKey(rawKey))
+	if key == nil {
+		return nil, &store.NotFoundError{
+			Resource: "api_key",
+			ID:       "***",
+		}
+	}
+	if key.ExpiresAt != nil && key.ExpiresAt.Before(s.now) {
+		return nil, &store.ExpiredError{
+			Resource: "api_key",
+			ID:       key.ID,
+		}
+	}
+	return copyKey(key), nil
+}
+
+// GetAPIKey retrieves an API key by ID
+func (s *Store) GetAPIKey(ctx context.Context, id int64) (*store.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, &store.NotFoundError{
+			Resource: "api_key",
+			ID:       id,
+		}
+	}
+	return copyKey(key), nil
+}
+
+// GetAPIKeyByID retrieves an API key by its ID
+func (s *Store) GetAPIKeyByID(ctx context.Context, id int64) (*store.APIKey, error) {
+	return s.GetAPIKey(ctx, id)
+}
+
+// ListAPIKeys returns all API keys for a user, newest first
+func (s *Store) ListAPIKeys(ctx context.Context, userID int64) ([]store.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := []store.APIKey{}
+	for _, key := range s.keys {
+		if key.UserID == userID {
+			keys = append(keys, *copyKey(key))
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID > keys[j].ID })
+	return keys, nil
+}
+
+// DeleteAPIKey deletes an API key (revokes it)
+func (s *Store) DeleteAPIKey(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.keys[id]; !ok {
+		return &store.NotFoundError{
+			Resource: "api_key",
+			ID:       id,
+		}
+	}
+	delete(s.keys, id)
+	delete(s.notified, id)
+	return nil
+}
+
+// UpdateLastUsed sets the last use of the key with the given hash to the current time
+func (s *Store) UpdateLastUsed(ctx context.Context, keyHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := s.keyByHash(keyHash)
+	if key == nil {
+		return &store.NotFoundError{
+			Resource: "api_key",
+			ID:       keyHash,
+		}
+	}
+	now := s.tick()
+	key.LastUsedAt = &now
+	return nil
+}
+
+// ListExpiringKeys returns keys expiring before the given time whose owner has not
+// been notified yet, soonest first. Keys that already expired are not returned.
+func (s *Store) ListExpiringKeys(ctx context.Context, before time.Time) ([]store.ExpiringAPIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := []store.ExpiringAPIKey{}
+	for _, key := range s.keys {
+		if key.ExpiresAt == nil || !key.ExpiresAt.After(s.now) || key.ExpiresAt.After(before) || s.notified[key.ID] {
+			continue
+		}
+		expiring := store.ExpiringAPIKey{
+			ID:        key.ID,
+			UserID:    key.UserID,
+			Name:      key.Name,
+			ExpiresAt: *key.ExpiresAt,
+		}
+		if user, ok := s.users[key.UserID]; ok {
+			expiring.OwnerAddress = user.Address
+			if user.OwnerID != nil {
+				if owner, ok := s.users[*user.OwnerID]; ok {
+					expiring.OwnerAddress = owner.Address
+				}
+			}
+		}
+		keys = append(keys, expiring)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].ExpiresAt.Equal(keys[j].ExpiresAt) {
+			return keys[i].ID < keys[j].ID
+		}
+		return keys[i].ExpiresAt.Before(keys[j].ExpiresAt)
+	})
+	return keys, nil
+}
+
+// MarkExpiryNotified records that the owner of a key was told it is about to expire
+func (s *Store) MarkExpiryNotified(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.keys[id]; !ok {
+		return &store.NotFoundError{
+			Resource: "api_key",
+			ID:       id,
+		}
+	}
+	s.notified[id] = true
+	return nil
+}
+
+// ListKeysMatching returns the keys a bulk revocation with filter would revoke,
+// oldest first
+func (s *Store) ListKeysMatching(ctx context.Context, filter store.KeyRevocationFilter) ([]store.APIKey, error) {
+	if filter.IsEmpty() {
+		return nil, fmt.Errorf("at least one revocation criterion is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.keysMatching(filter), nil
+}
+
+// RevokeKeysMatching deletes every key matching filter and returns the revoked
+// keys, oldest first
+func (s *Store) RevokeKeysMatching(ctx context.Context, filter store.KeyRevocationFilter) ([]store.APIKey, error) {
+	if filter.IsEmpty() {
+		return nil, fmt.Errorf("at least one revocation criterion is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := s.keysMatching(filter)
+	for _, key := range keys {
+		delete(s.keys, key.ID)
+		delete(s.notified, key.ID)
+	}
+	return keys, nil
+}
+
+// TransferAPIKey reassigns key id to toUserID and returns the updated key and
+// the previous owner's ID
+func (s *Store) TransferAPIKey(ctx context.Context, id, toUserID int64) (*store.APIKey, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, 0, &store.NotFoundError{
+			Resource: "api_key",
+			ID:       id,
+		}
+	}
+	if _, ok := s.users[toUserID]; !ok {
+		return nil, 0, &store.NotFoundError{
+			Resource: "user",
+			ID:       toUserID,
+		}
+	}
+
+	previousUserID := key.UserID
+	key.UserID = toUserID
+	key.UpdatedAt = s.tick()
+	return copyKey(key), previousUserID, nil
+}
+
+// keysMatching returns copies of the keys matching filter, oldest first. Callers
+// hold s.mu.
+func (s *Store) keysMatching(filter store.KeyRevocationFilter) []store.APIKey {
+	keys := []store.APIKey{}
+	for _, key := range s.keys {
+		if filter.Matches(*key) {
+			keys = append(keys, *copyKey(key))
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].ID < keys[j].ID
+		}
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+	return keys
+}
+
+// keyByHash returns the key with the given hash, or nil. Callers hold s.mu.
+func (s *Store) keyByHash(keyHash string) *store.APIKey {
+	for _, key := range s.keys {
+		if key.KeyHash == keyHash {
+			return key
+		}
+	}
+	return nil
+}
+
+// copyKey copies key, including its scopes
+func copyKey(key *store.APIKey) *store.APIKey {
+	copied := *key
+	copied.Scopes = append([]string{}, key.Scopes...)
+	return &copied
+}
@@ -0,0 +1,113 @@
+package gatekeepertest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// ListPolicies returns every stored policy in evaluation order, each as a
+// store.PolicyDocument in JSON
+func (s *Store) ListPolicies(ctx context.Context) ([]json.RawMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]int64, 0, len(s.policies))
+	for id := range s.policies {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	policies := make([]json.RawMessage, 0, len(ids))
+	for _, id := range ids {
+		doc := *s.policies[id]
+		doc.ID = id
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode policy %d: %w", id, err)
+		}
+		policies = append(policies, data)
+	}
+	return policies, nil
+}
+
+// CreatePolicy stores a policy given as a store.PolicyDocument in JSON and returns
+// its new ID
+func (s *Store) CreatePolicy(ctx context.Context, policy json.RawMessage) (int64, error) {
+	doc, err := parsePolicyDocument(policy)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextPolicy++
+	s.policies[s.nextPolicy] = doc
+	s.tick()
+	return s.nextPolicy, nil
+}
+
+// UpdatePolicy replaces the policy with the given ID
+func (s *Store) UpdatePolicy(ctx context.Context, id int64, policy json.RawMessage) error {
+	doc, err := parsePolicyDocument(policy)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.policies[id]; !ok {
+		return &store.NotFoundError{
+			Resource: "policy",
+			ID:       id,
+		}
+	}
+	s.policies[id] = doc
+	s.tick()
+	return nil
+}
+
+// DeletePolicy deletes the policy with the given ID
+func (s *Store) DeletePolicy(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.policies[id]; !ok {
+		return &store.NotFoundError{
+			Resource: "policy",
+			ID:       id,
+		}
+	}
+	delete(s.policies, id)
+	return nil
+}
+
+// parsePolicyDocument decodes a policy document, failing like the Postgres
+// repository on documents its schema cannot hold
+func parsePolicyDocument(data json.RawMessage) (*store.PolicyDocument, error) {
+	var doc store.PolicyDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: invalid policy: %v", store.ErrInvalidInput, err)
+	}
+	if doc.Method == "" || doc.Path == "" || doc.Logic == "" {
+		return nil, fmt.Errorf("%w: policy method, path and logic are required", store.ErrInvalidInput)
+	}
+	for i, rule := range doc.Rules {
+		var header struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(rule, &header); err != nil || header.Type == "" {
+			return nil, fmt.Errorf("%w: rule %d has no type", store.ErrInvalidInput, i)
+		}
+	}
+	if doc.Rules == nil {
+		doc.Rules = []json.RawMessage{}
+	}
+	doc.ID = 0
+	return &doc, nil
+}
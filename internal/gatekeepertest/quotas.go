@@ -0,0 +1,61 @@
+package gatekeepertest
+
+import (
+	"context"
+	"time"
+)
+
+// quota is a policy quota counter
+type quota struct {
+	count     int64
+	expiresAt time.Time
+}
+
+// Reserve increments the counter at key if it is below limit and reports whether
+// it did. Expired counters restart from zero.
+func (s *Store) Reserve(ctx context.Context, key string, limit int64, ttl time.Duration) (bool, error) {
+	if limit <= 0 {
+		return false, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, ok := s.quotas[key]
+	if !ok || q.expiresAt.Before(s.now) {
+		s.quotas[key] = &quota{count: 1, expiresAt: s.now.Add(ttl)}
+		return true, nil
+	}
+	if q.count >= limit {
+		return false, nil
+	}
+	q.count++
+	return true, nil
+}
+
+// Release decrements the counter at key
+func (s *Store) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if q, ok := s.quotas[key]; ok && q.count > 0 {
+		q.count--
+	}
+	return nil
+}
+
+// DeleteExpiredQuotas removes counters of past windows
+// Returns the number of counters deleted
+func (s *Store) DeleteExpiredQuotas(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	for key, q := range s.quotas {
+		if q.expiresAt.Before(s.now) {
+			delete(s.quotas, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,323 @@
+// Package gatekeepertest provides an in-memory fake of the store repositories for
+// tests of handlers and of services embedding Gatekeeper. The fake needs no
+// database and behaves deterministically: IDs count up from 1 per table, every
+// write advances a fixed clock by one second, and API keys created through it have
+// predictable raw values.
+package gatekeepertest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourusername/gatekeeper/internal/common"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// Epoch is the time the clock of a new Store starts at
+var Epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Store is an in-memory implementation of the user, role, service account, API key,
+// allowlist, quota and policy repository interfaces. It is safe for concurrent use.
+// Returned values are copies, so mutating them does not change the store.
+type Store struct {
+	mu  sync.Mutex
+	now time.Time
+
+	users      map[int64]*store.User
+	nextUser   int64
+	keys       map[int64]*store.APIKey
+	notified   map[int64]bool // Keys whose owner was told they expire
+	nextKey    int64
+	lists      map[int64]*store.Allowlist
+	entries    map[int64]map[string]store.AllowlistEntry
+	nextList   int64
+	nextEntry  int64
+	changes    map[int64][]store.AddressChange // Address changes per allowlist, oldest first
+	nextChange int64
+	quotas     map[string]*quota
+	policies   map[int64]*store.PolicyDocument
+	nextPolicy int64
+}
+
+// Ensure Store implements the repository interfaces it fakes
+var (
+	_ store.UserRepositoryInterface           = (*Store)(nil)
+	_ store.RoleRepositoryInterface           = (*Store)(nil)
+	_ store.ServiceAccountRepositoryInterface = (*Store)(nil)
+	_ store.APIKeyRepositoryInterface         = (*Store)(nil)
+	_ store.KeyExpiryRepositoryInterface      = (*Store)(nil)
+	_ store.KeyRevocationRepositoryInterface  = (*Store)(nil)
+	_ store.KeyTransferRepositoryInterface    = (*Store)(nil)
+	_ store.AllowlistRepositoryInterface      = (*Store)(nil)
+	_ store.QuotaRepositoryInterface          = (*Store)(nil)
+	_ store.PolicyRepositoryInterface         = (*Store)(nil)
+)
+
+// NewStore creates an empty store whose clock starts at Epoch
+func NewStore() *Store {
+	return &Store{
+		now:      Epoch,
+		users:    make(map[int64]*store.User),
+		keys:     make(map[int64]*store.APIKey),
+		notified: make(map[int64]bool),
+		lists:    make(map[int64]*store.Allowlist),
+		entries:  make(map[int64]map[string]store.AllowlistEntry),
+		changes:  make(map[int64][]store.AddressChange),
+		quotas:   make(map[string]*quota),
+		policies: make(map[int64]*store.PolicyDocument),
+	}
+}
+
+// Now returns the current time of the store's clock
+func (s *Store) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now
+}
+
+// Advance moves the store's clock forward, e.g. to let API keys expire
+func (s *Store) Advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = s.now.Add(d)
+}
+
+// tick advances the clock by one second and returns the new time, so records
+// written one after another have distinct, ordered timestamps. Callers hold s.mu.
+func (s *Store) tick() time.Time {
+	s.now = s.now.Add(time.Second)
+	return s.now
+}
+
+// normalizeAddress validates and normalizes an address, failing with the same
+// error as the Postgres repositories
+func normalizeAddress(address string) (string, error) {
+	normalized, err := common.NormalizeAddress(address)
+	if err != nil {
+		if addrErr, ok := err.(*common.AddressError); ok {
+			return "", &store.InvalidAddressError{
+				Address: addrErr.Address,
+				Reason:  addrErr.Reason,
+			}
+		}
+		return "", err
+	}
+	return normalized, nil
+}
+
+// CreateUser creates a wallet user with the given address
+func (s *Store) CreateUser(ctx context.Context, address string) (*store.User, error) {
+	normalized, err := normalizeAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.userByAddress(normalized) != nil {
+		return nil, &store.DuplicateError{
+			Resource: "user",
+			Field:    "address",
+			Value:    normalized,
+		}
+	}
+	return s.insertUser(&store.User{Address: normalized, AccountType: store.AccountTypeWallet}), nil
+}
+
+// GetOrCreateUserByAddress gets a user by address or creates one if it doesn't exist
+func (s *Store) GetOrCreateUserByAddress(ctx context.Context, address string) (*store.User, error) {
+	normalized, err := normalizeAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user := s.userByAddress(normalized); user != nil {
+		copied := *user
+		return &copied, nil
+	}
+	return s.insertUser(&store.User{Address: normalized, AccountType: store.AccountTypeWallet}), nil
+}
+
+// GetUserByAddress retrieves a user by their Ethereum address
+func (s *Store) GetUserByAddress(ctx context.Context, address string) (*store.User, error) {
+	normalized, err := normalizeAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user := s.userByAddress(normalized)
+	if user == nil {
+		return nil, &store.NotFoundError{
+			Resource: "user",
+			ID:       normalized,
+		}
+	}
+	copied := *user
+	return &copied, nil
+}
+
+// GetUserByID retrieves a user by their ID
+func (s *Store) GetUserByID(ctx context.Context, id int64) (*store.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return nil, &store.NotFoundError{
+			Resource: "user",
+			ID:       id,
+		}
+	}
+	copied := *user
+	return &copied, nil
+}
+
+// RecordLogin sets the user's last login to the current time
+func (s *Store) RecordLogin(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return &store.NotFoundError{
+			Resource: "user",
+			ID:       id,
+		}
+	}
+	now := s.tick()
+	user.LastLoginAt = &now
+	return nil
+}
+
+// SetUserRole sets the administrative role of a wallet user; an empty role
+// removes it. Service accounts cannot hold roles.
+func (s *Store) SetUserRole(ctx context.Context, id int64, role string) (*store.User, error) {
+	switch role {
+	case "", "viewer", "operator", "admin":
+	default:
+		return nil, fmt.Errorf("%w: unknown role %q", store.ErrInvalidInput, role)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok || user.IsServiceAccount() {
+		return nil, &store.NotFoundError{
+			Resource: "user",
+			ID:       id,
+		}
+	}
+	user.Role = role
+	user.UpdatedAt = s.tick()
+	copied := *user
+	return &copied, nil
+}
+
+// ListUsersWithRoles returns every user holding an administrative role, by address
+func (s *Store) ListUsersWithRoles(ctx context.Context) ([]store.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users := []store.User{}
+	for _, user := range s.users {
+		if user.Role != "" {
+			users = append(users, *user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Address < users[j].Address })
+	return users, nil
+}
+
+// CountUsersWithRole returns how many users hold the given role
+func (s *Store) CountUsersWithRole(ctx context.Context, role string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, user := range s.users {
+		if user.Role == role {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// CreateServiceAccount creates a service account owned by the given user
+func (s *Store) CreateServiceAccount(ctx context.Context, name string, ownerID int64) (*store.User, error) {
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if ownerID == 0 {
+		return nil, fmt.Errorf("owner_id is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[ownerID]; !ok {
+		return nil, &store.NotFoundError{
+			Resource: "user",
+			ID:       ownerID,
+		}
+	}
+	for _, user := range s.users {
+		if user.IsServiceAccount() && *user.OwnerID == ownerID && user.Name == name {
+			return nil, &store.DuplicateError{
+				Resource: "service_account",
+				Field:    "name",
+				Value:    name,
+			}
+		}
+	}
+	owner := ownerID
+	return s.insertUser(&store.User{AccountType: store.AccountTypeService, Name: name, OwnerID: &owner}), nil
+}
+
+// ListServiceAccounts returns all service accounts owned by the given user, newest first
+func (s *Store) ListServiceAccounts(ctx context.Context, ownerID int64) ([]store.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accounts := []store.User{}
+	for _, user := range s.users {
+		if user.IsServiceAccount() && *user.OwnerID == ownerID {
+			accounts = append(accounts, *user)
+		}
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].ID > accounts[j].ID })
+	return accounts, nil
+}
+
+// userByAddress returns the wallet user with the normalized address, or nil.
+// Callers hold s.mu.
+func (s *Store) userByAddress(address string) *store.User {
+	for _, user := range s.users {
+		if user.Address == address {
+			return user
+		}
+	}
+	return nil
+}
+
+// insertUser assigns the next ID and timestamps to user, stores it and returns a
+// copy. Callers hold s.mu.
+func (s *Store) insertUser(user *store.User) *store.User {
+	s.nextUser++
+	user.ID = s.nextUser
+	user.CreatedAt = s.tick()
+	user.UpdatedAt = user.CreatedAt
+	s.users[user.ID] = user
+	copied := *user
+	return &copied
+}
@@ -0,0 +1,199 @@
+package gatekeepertest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+const (
+	aliceAddress = "0x742d35cc6634c0532925a3b844bc9e7595f0beb8"
+	bobAddress   = "0x8ba1f109551bd432803012645ac136ddd64dba72"
+)
+
+func TestStore_Users(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore()
+
+	alice, err := s.GetOrCreateUserByAddress(ctx, "0x742D35CC6634C0532925A3B844BC9E7595F0BEB8")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), alice.ID)
+	assert.Equal(t, aliceAddress, alice.Address)
+	assert.Equal(t, Epoch.Add(time.Second), alice.CreatedAt)
+
+	again, err := s.GetOrCreateUserByAddress(ctx, aliceAddress)
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, again.ID)
+
+	_, err = s.CreateUser(ctx, aliceAddress)
+	assert.True(t, errors.Is(err, store.ErrDuplicate))
+	_, err = s.GetUserByAddress(ctx, "0x123")
+	assert.True(t, errors.Is(err, store.ErrInvalidAddress))
+	_, err = s.GetUserByID(ctx, 99)
+	assert.True(t, errors.Is(err, store.ErrNotFound))
+
+	// Returned users are copies
+	alice.Role = "admin"
+	stored, err := s.GetUserByID(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Empty(t, stored.Role)
+
+	_, err = s.SetUserRole(ctx, alice.ID, "admin")
+	require.NoError(t, err)
+	_, err = s.SetUserRole(ctx, alice.ID, "root")
+	assert.True(t, errors.Is(err, store.ErrInvalidInput))
+
+	ci, err := s.CreateServiceAccount(ctx, "ci", alice.ID)
+	require.NoError(t, err)
+	_, err = s.SetUserRole(ctx, ci.ID, "viewer")
+	assert.True(t, errors.Is(err, store.ErrNotFound))
+
+	admins, err := s.CountUsersWithRole(ctx, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, 1, admins)
+}
+
+func TestStore_APIKeys(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore()
+	alice, err := s.GetOrCreateUserByAddress(ctx, aliceAddress)
+	require.NoError(t, err)
+	bob, err := s.GetOrCreateUserByAddress(ctx, bobAddress)
+	require.NoError(t, err)
+
+	expiresIn := time.Hour
+	rawKey, created, err := s.CreateAPIKey(ctx, store.APIKeyCreateRequest{
+		UserID: alice.ID, Name: "Deploy", Scopes: []string{"read"}, ExpiresIn: &expiresIn,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, RawAPIKey(created.ID), rawKey)
+	assert.Equal(t, store.HashAPIKey(rawKey), created.KeyHash)
+
+	key, err := s.ValidateAPIKey(ctx, rawKey)
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, key.UserID)
+
+	expiring, err := s.ListExpiringKeys(ctx, s.Now().Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, expiring, 1)
+	assert.Equal(t, aliceAddress, expiring[0].OwnerAddress)
+
+	transferred, previous, err := s.TransferAPIKey(ctx, created.ID, bob.ID)
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, previous)
+	assert.Equal(t, bob.ID, transferred.UserID)
+
+	s.Advance(2 * time.Hour)
+	_, err = s.ValidateAPIKey(ctx, rawKey)
+	assert.True(t, errors.Is(err, store.ErrExpired))
+
+	_, err = s.RevokeKeysMatching(ctx, store.KeyRevocationFilter{})
+	assert.Error(t, err)
+	revoked, err := s.RevokeKeysMatching(ctx, store.KeyRevocationFilter{Scope: "read"})
+	require.NoError(t, err)
+	assert.Len(t, revoked, 1)
+
+	keys, err := s.ListAPIKeys(ctx, bob.ID)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func TestStore_Allowlists(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore()
+
+	allowlist, err := s.CreateAllowlist(ctx, "Beta", "Beta testers")
+	require.NoError(t, err)
+	_, err = s.CreateAllowlist(ctx, "Beta", "")
+	assert.True(t, errors.Is(err, store.ErrDuplicate))
+
+	require.NoError(t, s.AddAddresses(ctx, allowlist.ID, []string{bobAddress, aliceAddress}))
+	require.NoError(t, s.AddAddress(ctx, allowlist.ID, aliceAddress))
+	assert.True(t, errors.Is(s.AddAddress(ctx, 99, aliceAddress), store.ErrNotFound))
+
+	addresses, err := s.GetAddresses(ctx, allowlist.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{aliceAddress, bobAddress}, addresses)
+
+	found, err := s.FindAddresses(ctx, allowlist.ID, []string{bobAddress, "0x0000000000000000000000000000000000000001"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{bobAddress}, found)
+
+	require.NoError(t, s.RemoveAddress(ctx, allowlist.ID, bobAddress))
+	assert.True(t, errors.Is(s.RemoveAddress(ctx, allowlist.ID, bobAddress), store.ErrNotFound))
+
+	allowlists, err := s.ListAllowlists(ctx)
+	require.NoError(t, err)
+	require.Len(t, allowlists, 1)
+	assert.Equal(t, int64(1), allowlists[0].EntryCount)
+
+	// Re-adding alice changed nothing and was not recorded
+	changes, err := s.ListAddressChanges(ctx, allowlist.ID, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, store.ChangeAddressesAdded, changes[0].Action)
+	assert.Equal(t, []string{bobAddress, aliceAddress}, changes[0].Addresses)
+	assert.Equal(t, store.ChangeAddressRemoved, changes[1].Action)
+	assert.Equal(t, []string{bobAddress}, changes[1].Addresses)
+	latest, err := s.LatestAddressChangeID(ctx, allowlist.ID)
+	require.NoError(t, err)
+	assert.Equal(t, changes[1].ID, latest)
+	changes, err = s.ListAddressChanges(ctx, allowlist.ID, latest, 10)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	require.NoError(t, s.DeleteAllowlist(ctx, allowlist.ID))
+	_, err = s.GetAllowlist(ctx, allowlist.ID)
+	assert.True(t, errors.Is(err, store.ErrNotFound))
+}
+
+func TestStore_Quotas(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore()
+
+	for i := 0; i < 2; i++ {
+		ok, err := s.Reserve(ctx, "mint", 2, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+	ok, err := s.Reserve(ctx, "mint", 2, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, s.Release(ctx, "mint"))
+	ok, err = s.Reserve(ctx, "mint", 2, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	s.Advance(2 * time.Minute)
+	deleted, err := s.DeleteExpiredQuotas(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
+
+func TestStore_Policies(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore()
+
+	id, err := s.CreatePolicy(ctx, []byte(`{"id":7,"path":"/api/data","method":"GET","logic":"OR","rules":[{"type":"has_scope","scope":"read"}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), id)
+	_, err = s.CreatePolicy(ctx, []byte(`{"path":"/api/data","method":"GET","logic":"OR","rules":[{"scope":"read"}]}`))
+	assert.True(t, errors.Is(err, store.ErrInvalidInput))
+
+	require.NoError(t, s.UpdatePolicy(ctx, id, []byte(`{"path":"/api/data","method":"POST","logic":"AND","rules":[]}`)))
+	assert.True(t, errors.Is(s.UpdatePolicy(ctx, 99, []byte(`{"path":"/","method":"GET","logic":"AND"}`)), store.ErrNotFound))
+
+	policies, err := s.ListPolicies(ctx)
+	require.NoError(t, err)
+	require.Len(t, policies, 1)
+	assert.JSONEq(t, `{"id":1,"path":"/api/data","method":"POST","logic":"AND","rules":[]}`, string(policies[0]))
+
+	require.NoError(t, s.DeletePolicy(ctx, id))
+	assert.True(t, errors.Is(s.DeletePolicy(ctx, id), store.ErrNotFound))
+}
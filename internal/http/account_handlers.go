@@ -0,0 +1,113 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/gatekeeper/internal/log"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// Login history page sizes
+const (
+	defaultLoginsLimit = 20
+	maxLoginsLimit     = 100
+)
+
+// AccountHandler handles endpoints about the caller's own account
+type AccountHandler struct {
+	userRepo  store.UserRepositoryInterface
+	loginRepo store.LoginRepositoryInterface
+	logger    log.Logger
+}
+
+// NewAccountHandler creates a new account handler
+func NewAccountHandler(userRepo store.UserRepositoryInterface, loginRepo store.LoginRepositoryInterface, logger log.Logger) *AccountHandler {
+	return &AccountHandler{
+		userRepo:  userRepo,
+		loginRepo: loginRepo,
+		logger:    logger,
+	}
+}
+
+// LoginResponse represents one sign-in in the login history
+type LoginResponse struct {
+	IPAddress string    `json:"ipAddress,omitempty"`
+	UserAgent string    `json:"userAgent,omitempty"`
+	ChainID   *int64    `json:"chainId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ListLoginsResponse represents the response for the login history
+type ListLoginsResponse struct {
+	Logins []LoginResponse `json:"logins"`
+}
+
+// ListLogins handles GET /api/me/logins - Recent sign-ins of the caller, newest first.
+// The optional limit query parameter caps the number of entries (default 20, max 100).
+func (h *AccountHandler) ListLogins(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := requestLogger(r, h.logger)
+
+	claims := ClaimsFromContext(r)
+	if claims == nil {
+		h.writeError(w, "Unauthorized", "No authentication claims found", http.StatusUnauthorized)
+		return
+	}
+	if claims.Address == "" {
+		h.writeError(w, "Forbidden", "Login history is only available for wallet accounts", http.StatusForbidden)
+		return
+	}
+
+	limit := defaultLoginsLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 || parsed > maxLoginsLimit {
+			h.writeError(w, "Invalid limit", "limit must be between 1 and 100", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	user, err := h.userRepo.GetUserByAddress(ctx, claims.Address)
+	if err != nil {
+		logger.Error("failed to get user", log.Error(err))
+		h.writeError(w, "User not found", "User does not exist", http.StatusNotFound)
+		return
+	}
+
+	logins, err := h.loginRepo.ListLogins(ctx, user.ID, limit)
+	if err != nil {
+		logger.Error("failed to list logins", log.Int64("user_id", user.ID), log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to retrieve login history", http.StatusInternalServerError)
+		return
+	}
+
+	response := ListLoginsResponse{Logins: make([]LoginResponse, len(logins))}
+	for i, login := range logins {
+		response.Logins[i] = LoginResponse{
+			IPAddress: login.IPAddress,
+			UserAgent: login.UserAgent,
+			ChainID:   login.ChainID,
+			CreatedAt: login.CreatedAt,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeError writes an error response
+func (h *AccountHandler) writeError(w http.ResponseWriter, error, details string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := ErrorResponse{
+		Error:   error,
+		Details: details,
+	}
+	json.NewEncoder(w).Encode(response)
+}
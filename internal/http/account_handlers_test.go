@@ -0,0 +1,141 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// MockLoginRepository is a mock implementation of LoginRepository
+type MockLoginRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoginRepository) CreateLogin(ctx context.Context, login *store.Login) error {
+	args := m.Called(ctx, login)
+	return args.Error(0)
+}
+
+func (m *MockLoginRepository) ListLogins(ctx context.Context, userID int64, limit int) ([]store.Login, error) {
+	args := m.Called(ctx, userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]store.Login), args.Error(1)
+}
+
+func newLoginsRequest(t *testing.T, query string, claims *auth.Claims) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/api/me/logins"+query, nil)
+	if claims == nil {
+		return req
+	}
+	return req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, claims))
+}
+
+func TestListLogins_ReturnsHistory(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	loginRepo := new(MockLoginRepository)
+	handler := NewAccountHandler(userRepo, loginRepo, nil)
+
+	address := "0x742d35cc6634c0532925a3b844bc9e7595f0beb0"
+	chainID := int64(1)
+	signedInAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	userRepo.On("GetUserByAddress", mock.Anything, address).Return(&store.User{ID: 42, Address: address}, nil)
+	loginRepo.On("ListLogins", mock.Anything, int64(42), 20).Return([]store.Login{
+		{ID: 2, UserID: 42, IPAddress: "203.0.113.7", UserAgent: "Mozilla/5.0", ChainID: &chainID, CreatedAt: signedInAt},
+	}, nil)
+
+	rec := httptest.NewRecorder()
+	handler.ListLogins(rec, newLoginsRequest(t, "", &auth.Claims{Address: address}))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response ListLoginsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Len(t, response.Logins, 1)
+	assert.Equal(t, "203.0.113.7", response.Logins[0].IPAddress)
+	assert.Equal(t, "Mozilla/5.0", response.Logins[0].UserAgent)
+	require.NotNil(t, response.Logins[0].ChainID)
+	assert.Equal(t, int64(1), *response.Logins[0].ChainID)
+	assert.True(t, signedInAt.Equal(response.Logins[0].CreatedAt))
+	loginRepo.AssertExpectations(t)
+}
+
+func TestListLogins_EmptyHistory(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	loginRepo := new(MockLoginRepository)
+	handler := NewAccountHandler(userRepo, loginRepo, nil)
+
+	address := "0x742d35cc6634c0532925a3b844bc9e7595f0beb0"
+	userRepo.On("GetUserByAddress", mock.Anything, address).Return(&store.User{ID: 42, Address: address}, nil)
+	loginRepo.On("ListLogins", mock.Anything, int64(42), 5).Return([]store.Login{}, nil)
+
+	rec := httptest.NewRecorder()
+	handler.ListLogins(rec, newLoginsRequest(t, "?limit=5", &auth.Claims{Address: address}))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"logins":[]}`, rec.Body.String())
+}
+
+func TestListLogins_InvalidLimit(t *testing.T) {
+	handler := NewAccountHandler(new(MockUserRepository), new(MockLoginRepository), nil)
+	claims := &auth.Claims{Address: "0x742d35cc6634c0532925a3b844bc9e7595f0beb0"}
+
+	for _, query := range []string{"?limit=0", "?limit=101", "?limit=abc"} {
+		rec := httptest.NewRecorder()
+		handler.ListLogins(rec, newLoginsRequest(t, query, claims))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
+
+func TestListLogins_RequiresWalletClaims(t *testing.T) {
+	handler := NewAccountHandler(new(MockUserRepository), new(MockLoginRepository), nil)
+
+	rec := httptest.NewRecorder()
+	handler.ListLogins(rec, newLoginsRequest(t, "", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ListLogins(rec, newLoginsRequest(t, "", &auth.Claims{Scopes: []string{"read"}}))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestListLogins_UserNotFound(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	loginRepo := new(MockLoginRepository)
+	handler := NewAccountHandler(userRepo, loginRepo, nil)
+
+	address := "0x742d35cc6634c0532925a3b844bc9e7595f0beb0"
+	userRepo.On("GetUserByAddress", mock.Anything, address).Return(nil, &store.NotFoundError{Resource: "user", ID: address})
+
+	rec := httptest.NewRecorder()
+	handler.ListLogins(rec, newLoginsRequest(t, "", &auth.Claims{Address: address}))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	loginRepo.AssertNotCalled(t, "ListLogins", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestListLogins_StoreFailure(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	loginRepo := new(MockLoginRepository)
+	handler := NewAccountHandler(userRepo, loginRepo, nil)
+
+	address := "0x742d35cc6634c0532925a3b844bc9e7595f0beb0"
+	userRepo.On("GetUserByAddress", mock.Anything, address).Return(&store.User{ID: 42, Address: address}, nil)
+	loginRepo.On("ListLogins", mock.Anything, int64(42), 20).Return(nil, errors.New("database unavailable"))
+
+	rec := httptest.NewRecorder()
+	handler.ListLogins(rec, newLoginsRequest(t, "", &auth.Claims{Address: address}))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
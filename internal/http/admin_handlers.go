@@ -0,0 +1,145 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/yourusername/gatekeeper/internal/audit"
+	"github.com/yourusername/gatekeeper/internal/log"
+)
+
+// maxLogLevelDuration caps how long a temporary log level override may last
+const maxLogLevelDuration = 24 * time.Hour
+
+// AdminHandler handles operational endpoints under /api/admin
+type AdminHandler struct {
+	logger      log.Logger
+	auditLogger audit.AuditLogger
+
+	mu          sync.Mutex
+	revertTimer *time.Timer // Pending revert of a temporary log level override
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(logger log.Logger, auditLogger audit.AuditLogger) *AdminHandler {
+	return &AdminHandler{
+		logger:      logger,
+		auditLogger: auditLogger,
+	}
+}
+
+// SetLogLevelRequest represents the request to change the log level
+type SetLogLevelRequest struct {
+	Level           string `json:"level"`
+	DurationSeconds int64  `json:"durationSeconds,omitempty"` // Revert to the previous level after this long (0 = permanent)
+}
+
+// LogLevelResponse represents the current log level
+type LogLevelResponse struct {
+	Level     string     `json:"level"`
+	RevertsAt *time.Time `json:"revertsAt,omitempty"`
+}
+
+// GetLogLevel handles GET /api/admin/log-level
+func (h *AdminHandler) GetLogLevel(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(LogLevelResponse{Level: h.logger.Level().String()})
+}
+
+// SetLogLevel handles PUT /api/admin/log-level - Change the log level at runtime
+func (h *AdminHandler) SetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req SetLogLevelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, "Invalid request body", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	duration := time.Duration(req.DurationSeconds) * time.Second
+	if req.DurationSeconds < 0 || duration > maxLogLevelDuration {
+		h.writeError(w, "Validation failed", "durationSeconds must be between 0 and 86400", http.StatusBadRequest)
+		return
+	}
+
+	h.mu.Lock()
+	previous := h.logger.Level().String()
+	if err := h.logger.SetLevel(req.Level); err != nil {
+		h.mu.Unlock()
+		h.writeError(w, "Validation failed", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// A new change supersedes any pending revert
+	if h.revertTimer != nil {
+		h.revertTimer.Stop()
+		h.revertTimer = nil
+	}
+
+	response := LogLevelResponse{Level: h.logger.Level().String()}
+	if duration > 0 {
+		revertsAt := time.Now().Add(duration)
+		response.RevertsAt = &revertsAt
+		h.revertTimer = time.AfterFunc(duration, func() {
+			h.revertLogLevel(previous)
+		})
+	}
+	h.mu.Unlock()
+
+	claims := ClaimsFromContext(r)
+	var actor string
+	if claims != nil {
+		actor = claims.Identity()
+	}
+
+	requestLogger(r, h.logger).Warn("log level changed",
+		log.String("previous_level", previous),
+		log.String("level", response.Level),
+		log.Duration("duration", duration),
+	)
+
+	if h.auditLogger != nil {
+		h.auditLogger.Log(r.Context(), withClientInfo(r, audit.AuditEvent{
+			Action:   audit.ActionLogLevelChanged,
+			Result:   audit.ResultSuccess,
+			UserAddr: actor,
+			Method:   r.Method,
+			Endpoint: r.URL.Path,
+			Metadata: map[string]interface{}{
+				"previous_level":   previous,
+				"level":            response.Level,
+				"duration_seconds": req.DurationSeconds,
+			},
+		}))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// revertLogLevel restores the log level once a temporary override expires
+func (h *AdminHandler) revertLogLevel(level string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.revertTimer = nil
+	if err := h.logger.SetLevel(level); err != nil {
+		h.logger.Error("failed to revert log level", log.String("level", level), log.Error(err))
+		return
+	}
+	h.logger.Warn("temporary log level override expired", log.String("level", level))
+}
+
+// writeError writes a JSON error response
+func (h *AdminHandler) writeError(w http.ResponseWriter, error, details string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := ErrorResponse{
+		Error:   error,
+		Details: details,
+	}
+	json.NewEncoder(w).Encode(response)
+}
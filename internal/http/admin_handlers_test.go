@@ -0,0 +1,104 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/log"
+)
+
+func TestSetLogLevel(t *testing.T) {
+	logger, err := log.New("info")
+	require.NoError(t, err)
+	handler := NewAdminHandler(logger, nil)
+
+	body, _ := json.Marshal(SetLogLevelRequest{Level: "debug"})
+	req := httptest.NewRequest("PUT", "/api/admin/log-level", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	handler.SetLogLevel(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response LogLevelResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Equal(t, "debug", response.Level)
+	assert.Nil(t, response.RevertsAt)
+	assert.Equal(t, log.DebugLevel, logger.Level())
+}
+
+func TestSetLogLevel_TemporaryOverrideReverts(t *testing.T) {
+	logger, err := log.New("info")
+	require.NoError(t, err)
+	handler := NewAdminHandler(logger, nil)
+
+	body, _ := json.Marshal(SetLogLevelRequest{Level: "debug", DurationSeconds: 1})
+	req := httptest.NewRequest("PUT", "/api/admin/log-level", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	handler.SetLogLevel(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var response LogLevelResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.NotNil(t, response.RevertsAt)
+
+	assert.Eventually(t, func() bool {
+		return logger.Level() == log.InfoLevel
+	}, 3*time.Second, 50*time.Millisecond)
+}
+
+func TestSetLogLevel_InvalidLevel(t *testing.T) {
+	logger, err := log.New("info")
+	require.NoError(t, err)
+	handler := NewAdminHandler(logger, nil)
+
+	body, _ := json.Marshal(SetLogLevelRequest{Level: "chatty"})
+	req := httptest.NewRequest("PUT", "/api/admin/log-level", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	handler.SetLogLevel(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, log.InfoLevel, logger.Level())
+}
+
+func TestRequireScope_Admin(t *testing.T) {
+	handler := RequireScope(ScopeAdmin, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	allowed := httptest.NewRecorder()
+	handler.ServeHTTP(allowed, withClaims(httptest.NewRequest("PUT", "/api/admin/log-level", nil), &auth.Claims{Scopes: []string{"admin"}}))
+	assert.Equal(t, http.StatusOK, allowed.Code)
+
+	denied := httptest.NewRecorder()
+	handler.ServeHTTP(denied, withClaims(httptest.NewRequest("PUT", "/api/admin/log-level", nil), &auth.Claims{Scopes: []string{"read"}}))
+	assert.Equal(t, http.StatusForbidden, denied.Code)
+}
+
+// TestRequireScope_ExpandsSessionScopes lets sessions use the scopes their role implies
+func TestRequireScope_ExpandsSessionScopes(t *testing.T) {
+	handler := RequireScope(ScopeAuthzCheck, auth.DefaultScopeCatalog())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	check := func(scopes ...string) int {
+		req := withClaims(httptest.NewRequest("POST", "/api/authz/check", nil), &auth.Claims{Scopes: scopes})
+		req = req.WithContext(context.WithValue(req.Context(), AuthMethodContextKey, AuthMethodJWT))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusOK, check("auth", "admin"))
+	assert.Equal(t, http.StatusOK, check("auth", "operator"))
+	assert.Equal(t, http.StatusForbidden, check("auth", "viewer"))
+	assert.Equal(t, http.StatusForbidden, check("auth"))
+}
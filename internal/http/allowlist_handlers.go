@@ -0,0 +1,534 @@
+package http
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/gatekeeper/internal/audit"
+	"github.com/yourusername/gatekeeper/internal/common"
+	"github.com/yourusername/gatekeeper/internal/log"
+	"github.com/yourusername/gatekeeper/internal/merkle"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// maxAllowlistImportBytes caps the size of an uploaded allowlist CSV
+const maxAllowlistImportBytes = 10 << 20
+
+// Allowlist exports are flushed to the client every exportFlushRows rows. Each
+// flush extends the write deadline by exportWriteTimeout, so an export is not
+// cut off by the server's write timeout while rows keep flowing.
+const (
+	exportFlushRows    = 1000
+	exportWriteTimeout = 30 * time.Second
+)
+
+// Number of address changes read per request of the changes endpoint
+const (
+	defaultAllowlistChangesLimit = 100
+	maxAllowlistChangesLimit     = 1000
+)
+
+// AllowlistHandler handles allowlist administration endpoints
+type AllowlistHandler struct {
+	allowlistRepo store.AllowlistRepositoryInterface
+	logger        log.Logger
+	auditLogger   audit.AuditLogger
+}
+
+// NewAllowlistHandler creates a new allowlist handler
+func NewAllowlistHandler(allowlistRepo store.AllowlistRepositoryInterface, logger log.Logger, auditLogger audit.AuditLogger) *AllowlistHandler {
+	return &AllowlistHandler{
+		allowlistRepo: allowlistRepo,
+		logger:        logger,
+		auditLogger:   auditLogger,
+	}
+}
+
+// ImportInvalidRow is a CSV row whose address was rejected
+type ImportInvalidRow struct {
+	Line   int    `json:"line"`
+	Value  string `json:"value"`
+	Reason string `json:"reason"`
+}
+
+// ImportDuplicateRow is a CSV row repeating an address of an earlier row
+type ImportDuplicateRow struct {
+	Line      int    `json:"line"`
+	Address   string `json:"address"`
+	FirstLine int    `json:"firstLine"`
+}
+
+// AllowlistImportReport describes what an import did, or would do in a dry run
+type AllowlistImportReport struct {
+	DryRun         bool                 `json:"dryRun"`
+	Rows           int                  `json:"rows"`     // Address rows read, excluding the header and blank lines
+	Valid          int                  `json:"valid"`    // Distinct valid addresses not yet in the allowlist
+	Imported       int                  `json:"imported"` // Addresses added (always 0 in a dry run)
+	Duplicates     []ImportDuplicateRow `json:"duplicates"`
+	Invalid        []ImportInvalidRow   `json:"invalid"`
+	AlreadyPresent []string             `json:"alreadyPresent"`
+}
+
+// ImportAddresses handles POST /api/admin/allowlists/{id}/import - Bulk add addresses
+// from a CSV whose first column is the address. With ?dry_run=true nothing is written
+// and the report shows what the import would do. Imports with invalid rows are rejected.
+func (h *AllowlistHandler) ImportAddresses(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := requestLogger(r, h.logger)
+
+	allowlistID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.writeError(w, "Invalid request", "Invalid allowlist ID format", http.StatusBadRequest)
+		return
+	}
+
+	dryRun := false
+	if raw := r.URL.Query().Get("dry_run"); raw != "" {
+		dryRun, err = strconv.ParseBool(raw)
+		if err != nil {
+			h.writeError(w, "Invalid request", "dry_run must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if _, err := h.allowlistRepo.GetAllowlist(ctx, allowlistID); err != nil {
+		var notFoundErr *store.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			h.writeError(w, "Not found", "Allowlist not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get allowlist", log.Int64("allowlist_id", allowlistID), log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to retrieve allowlist", http.StatusInternalServerError)
+		return
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "text/csv" {
+		w.Header().Set("Accept", "text/csv")
+		writeProblem(w, Problem{
+			Type:     ProblemTypeUnsupportedMedia,
+			Title:    "Unsupported media type",
+			Status:   http.StatusUnsupportedMediaType,
+			Detail:   "Content-Type must be text/csv",
+			Instance: r.URL.Path,
+		})
+		return
+	}
+
+	report, addresses, err := parseAllowlistCSV(http.MaxBytesReader(w, r.Body, maxAllowlistImportBytes))
+	if err != nil {
+		h.writeError(w, "Invalid CSV", err.Error(), http.StatusBadRequest)
+		return
+	}
+	report.DryRun = dryRun
+
+	present, err := h.allowlistRepo.FindAddresses(ctx, allowlistID, addresses)
+	if err != nil {
+		logger.Error("failed to check allowlist addresses", log.Int64("allowlist_id", allowlistID), log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to check existing addresses", http.StatusInternalServerError)
+		return
+	}
+	report.AlreadyPresent = present
+
+	isPresent := make(map[string]bool, len(present))
+	for _, address := range present {
+		isPresent[address] = true
+	}
+	newAddresses := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		if !isPresent[address] {
+			newAddresses = append(newAddresses, address)
+		}
+	}
+	report.Valid = len(newAddresses)
+
+	if dryRun {
+		h.writeReport(w, report, http.StatusOK)
+		return
+	}
+	if len(report.Invalid) > 0 {
+		h.writeReport(w, report, http.StatusUnprocessableEntity)
+		return
+	}
+
+	var actor string
+	if claims := ClaimsFromContext(r); claims != nil {
+		actor = claims.Identity()
+	}
+
+	if err := h.allowlistRepo.AddAddresses(store.WithActor(ctx, actor), allowlistID, newAddresses); err != nil {
+		logger.Error("failed to import allowlist addresses", log.Int64("allowlist_id", allowlistID), log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to import addresses", http.StatusInternalServerError)
+		return
+	}
+	report.Imported = len(newAddresses)
+
+	logger.Info("allowlist imported",
+		log.Int64("allowlist_id", allowlistID),
+		log.Int("imported", report.Imported),
+		log.Int("already_present", len(report.AlreadyPresent)),
+	)
+
+	if h.auditLogger != nil {
+		h.auditLogger.Log(ctx, withClientInfo(r, audit.AuditEvent{
+			Action:   audit.ActionAllowlistImported,
+			Result:   audit.ResultSuccess,
+			UserAddr: actor,
+			Method:   r.Method,
+			Endpoint: r.URL.Path,
+			Metadata: map[string]interface{}{
+				"allowlist_id":    allowlistID,
+				"imported":        report.Imported,
+				"already_present": len(report.AlreadyPresent),
+				"duplicates":      len(report.Duplicates),
+			},
+		}))
+	}
+
+	h.writeReport(w, report, http.StatusOK)
+}
+
+// ExportAddresses handles GET /api/admin/allowlists/{id}/export - Download the
+// addresses of an allowlist as CSV with an address,added_at header. Rows are
+// streamed from the database with chunked transfer encoding, so memory use does
+// not grow with the size of the allowlist.
+func (h *AllowlistHandler) ExportAddresses(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := requestLogger(r, h.logger)
+
+	allowlistID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.writeError(w, "Invalid request", "Invalid allowlist ID format", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.allowlistRepo.GetAllowlist(ctx, allowlistID); err != nil {
+		var notFoundErr *store.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			h.writeError(w, "Not found", "Allowlist not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get allowlist", log.Int64("allowlist_id", allowlistID), log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to retrieve allowlist", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="allowlist-%d.csv"`, allowlistID))
+	w.WriteHeader(http.StatusOK)
+
+	controller := http.NewResponseController(w)
+	controller.SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"address", "added_at"})
+	rows := 0
+	flush := func() error {
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return err
+		}
+		// Flushing fails only on writers without streaming support, which
+		// then send the response once the handler returns
+		controller.Flush()
+		controller.SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+		return nil
+	}
+
+	err = h.allowlistRepo.StreamEntries(ctx, allowlistID, func(entry store.AllowlistEntry) error {
+		writer.Write([]string{entry.Address, entry.AddedAt.UTC().Format(time.RFC3339)})
+		rows++
+		if rows%exportFlushRows == 0 {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		// The status line is already sent; abort the connection so the client
+		// sees a failed download rather than a silently truncated file
+		logger.Error("allowlist export failed", log.Int64("allowlist_id", allowlistID), log.Int("rows", rows), log.Error(err))
+		panic(http.ErrAbortHandler)
+	}
+
+	logger.Info("allowlist exported", log.Int64("allowlist_id", allowlistID), log.Int("rows", rows))
+}
+
+// AllowlistMerkleResponse is the Merkle tree of an allowlist
+type AllowlistMerkleResponse struct {
+	AllowlistID int64  `json:"allowlistId"`
+	Root        string `json:"root"`
+	Count       int    `json:"count"`
+	// LeafEncoding describes how contracts hash an address into a leaf
+	LeafEncoding string `json:"leafEncoding"`
+	// Proofs maps each address to its proof, when requested
+	Proofs map[string][]string `json:"proofs,omitempty"`
+}
+
+// merkleLeafEncoding is reported with every tree, so contract authors hash leaves the same way
+const merkleLeafEncoding = "keccak256(abi.encodePacked(address)), sorted pairs"
+
+// GetMerkleTree handles GET /api/admin/allowlists/{id}/merkle - Compute the Merkle
+// root of the addresses of an allowlist, for verification by contracts with
+// OpenZeppelin's MerkleProof. With ?proofs=true the proof of every address is included.
+func (h *AllowlistHandler) GetMerkleTree(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := requestLogger(r, h.logger)
+
+	allowlistID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.writeError(w, "Invalid request", "Invalid allowlist ID format", http.StatusBadRequest)
+		return
+	}
+
+	includeProofs := false
+	if raw := r.URL.Query().Get("proofs"); raw != "" {
+		includeProofs, err = strconv.ParseBool(raw)
+		if err != nil {
+			h.writeError(w, "Invalid request", "proofs must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if _, err := h.allowlistRepo.GetAllowlist(ctx, allowlistID); err != nil {
+		var notFoundErr *store.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			h.writeError(w, "Not found", "Allowlist not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get allowlist", log.Int64("allowlist_id", allowlistID), log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to retrieve allowlist", http.StatusInternalServerError)
+		return
+	}
+
+	addresses, err := h.allowlistRepo.GetAddresses(ctx, allowlistID)
+	if err != nil {
+		logger.Error("failed to get allowlist addresses", log.Int64("allowlist_id", allowlistID), log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to retrieve addresses", http.StatusInternalServerError)
+		return
+	}
+	if len(addresses) == 0 {
+		h.writeError(w, "Empty allowlist", "A Merkle tree requires at least one address", http.StatusUnprocessableEntity)
+		return
+	}
+
+	tree, err := merkle.NewAddressTree(addresses)
+	if err != nil {
+		logger.Error("failed to build allowlist Merkle tree", log.Int64("allowlist_id", allowlistID), log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to build Merkle tree", http.StatusInternalServerError)
+		return
+	}
+
+	response := AllowlistMerkleResponse{
+		AllowlistID:  allowlistID,
+		Root:         tree.Root(),
+		Count:        tree.Len(),
+		LeafEncoding: merkleLeafEncoding,
+	}
+	if includeProofs {
+		response.Proofs = tree.Proofs()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// AllowlistChangesResponse is the net change of the addresses of an allowlist
+// since a cursor
+type AllowlistChangesResponse struct {
+	AllowlistID int64 `json:"allowlistId"`
+	Since       int64 `json:"since"`
+	// Cursor is passed as ?since= on the next request
+	Cursor  int64    `json:"cursor"`
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	// HasMore is set when more changes follow the cursor
+	HasMore bool `json:"hasMore"`
+}
+
+// GetAddressChanges handles GET /api/admin/allowlists/{id}/changes - The addresses
+// added to and removed from an allowlist since a cursor, so mirrors of the list
+// sync incrementally instead of exporting it again. Without ?since= only the
+// current cursor is returned; take it before exporting the list, then follow it.
+// ?limit= caps the number of changes read (default 100, max 1000).
+func (h *AllowlistHandler) GetAddressChanges(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := requestLogger(r, h.logger)
+	query := r.URL.Query()
+
+	allowlistID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.writeError(w, "Invalid request", "Invalid allowlist ID format", http.StatusBadRequest)
+		return
+	}
+
+	var since int64
+	hasSince := query.Get("since") != ""
+	if hasSince {
+		since, err = strconv.ParseInt(query.Get("since"), 10, 64)
+		if err != nil || since < 0 {
+			h.writeError(w, "Invalid request", "since must be a cursor returned by this endpoint", http.StatusBadRequest)
+			return
+		}
+	}
+	limit := defaultAllowlistChangesLimit
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 || parsed > maxAllowlistChangesLimit {
+			h.writeError(w, "Invalid limit", "limit must be between 1 and 1000", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	if _, err := h.allowlistRepo.GetAllowlist(ctx, allowlistID); err != nil {
+		var notFoundErr *store.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			h.writeError(w, "Not found", "Allowlist not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get allowlist", log.Int64("allowlist_id", allowlistID), log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to retrieve allowlist", http.StatusInternalServerError)
+		return
+	}
+
+	response := AllowlistChangesResponse{
+		AllowlistID: allowlistID,
+		Since:       since,
+		Cursor:      since,
+		Added:       []string{},
+		Removed:     []string{},
+	}
+
+	if !hasSince {
+		response.Cursor, err = h.allowlistRepo.LatestAddressChangeID(ctx, allowlistID)
+		if err != nil {
+			logger.Error("failed to get allowlist cursor", log.Int64("allowlist_id", allowlistID), log.Error(err))
+			h.writeError(w, "Internal server error", "Failed to retrieve changes", http.StatusInternalServerError)
+			return
+		}
+	} else {
+		// Read one change more than the limit to tell whether more follow
+		changes, err := h.allowlistRepo.ListAddressChanges(ctx, allowlistID, since, limit+1)
+		if err != nil {
+			logger.Error("failed to list allowlist changes", log.Int64("allowlist_id", allowlistID), log.Error(err))
+			h.writeError(w, "Internal server error", "Failed to retrieve changes", http.StatusInternalServerError)
+			return
+		}
+		if len(changes) > limit {
+			changes = changes[:limit]
+			response.HasMore = true
+		}
+
+		// Only the last change of each address counts, so an address added and
+		// removed again since the cursor is reported as removed
+		added := make(map[string]bool)
+		for _, change := range changes {
+			for _, address := range change.Addresses {
+				added[address] = change.Action == store.ChangeAddressesAdded
+			}
+			response.Cursor = change.ID
+		}
+		for address, isAdded := range added {
+			if isAdded {
+				response.Added = append(response.Added, address)
+			} else {
+				response.Removed = append(response.Removed, address)
+			}
+		}
+		sort.Strings(response.Added)
+		sort.Strings(response.Removed)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// parseAllowlistCSV reads the address column of an allowlist CSV. It returns the
+// report of the rows and the distinct valid addresses, normalized, in file order.
+// A first row whose first column is "address" is treated as a header.
+func parseAllowlistCSV(body io.Reader) (*AllowlistImportReport, []string, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	report := &AllowlistImportReport{
+		Duplicates:     []ImportDuplicateRow{},
+		Invalid:        []ImportInvalidRow{},
+		AlreadyPresent: []string{},
+	}
+	firstLines := make(map[string]int)
+	var addresses []string
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		value := strings.TrimSpace(record[0])
+		if value == "" && len(record) == 1 {
+			continue
+		}
+		if report.Rows == 0 && strings.EqualFold(value, "address") {
+			continue
+		}
+		report.Rows++
+
+		address, err := common.NormalizeAddress(value)
+		if err != nil {
+			reason := err.Error()
+			var addrErr *common.AddressError
+			if errors.As(err, &addrErr) {
+				reason = addrErr.Reason
+			}
+			report.Invalid = append(report.Invalid, ImportInvalidRow{Line: line, Value: value, Reason: reason})
+			continue
+		}
+
+		if firstLine, ok := firstLines[address]; ok {
+			report.Duplicates = append(report.Duplicates, ImportDuplicateRow{Line: line, Address: address, FirstLine: firstLine})
+			continue
+		}
+		firstLines[address] = line
+		addresses = append(addresses, address)
+	}
+
+	return report, addresses, nil
+}
+
+// writeReport writes an import report
+func (h *AllowlistHandler) writeReport(w http.ResponseWriter, report *AllowlistImportReport, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(report)
+}
+
+// writeError writes an error response
+func (h *AllowlistHandler) writeError(w http.ResponseWriter, error, details string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := ErrorResponse{
+		Error:   error,
+		Details: details,
+	}
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,429 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/merkle"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// MockAllowlistRepository is a mock implementation of AllowlistRepository
+type MockAllowlistRepository struct {
+	mock.Mock
+}
+
+func (m *MockAllowlistRepository) CreateAllowlist(ctx context.Context, name, description string) (*store.Allowlist, error) {
+	args := m.Called(ctx, name, description)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.Allowlist), args.Error(1)
+}
+
+func (m *MockAllowlistRepository) GetAllowlist(ctx context.Context, id int64) (*store.Allowlist, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.Allowlist), args.Error(1)
+}
+
+func (m *MockAllowlistRepository) ListAllowlists(ctx context.Context) ([]store.AllowlistWithCount, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]store.AllowlistWithCount), args.Error(1)
+}
+
+func (m *MockAllowlistRepository) UpdateAllowlist(ctx context.Context, allowlist *store.Allowlist) error {
+	args := m.Called(ctx, allowlist)
+	return args.Error(0)
+}
+
+func (m *MockAllowlistRepository) DeleteAllowlist(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockAllowlistRepository) AddAddress(ctx context.Context, allowlistID int64, address string) error {
+	args := m.Called(ctx, allowlistID, address)
+	return args.Error(0)
+}
+
+func (m *MockAllowlistRepository) RemoveAddress(ctx context.Context, allowlistID int64, address string) error {
+	args := m.Called(ctx, allowlistID, address)
+	return args.Error(0)
+}
+
+func (m *MockAllowlistRepository) AddAddresses(ctx context.Context, allowlistID int64, addresses []string) error {
+	args := m.Called(ctx, allowlistID, addresses)
+	return args.Error(0)
+}
+
+func (m *MockAllowlistRepository) CheckAddress(ctx context.Context, allowlistID int64, address string) (bool, error) {
+	args := m.Called(ctx, allowlistID, address)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAllowlistRepository) FindAddresses(ctx context.Context, allowlistID int64, addresses []string) ([]string, error) {
+	args := m.Called(ctx, allowlistID, addresses)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockAllowlistRepository) GetAddresses(ctx context.Context, allowlistID int64) ([]string, error) {
+	args := m.Called(ctx, allowlistID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockAllowlistRepository) StreamEntries(ctx context.Context, allowlistID int64, fn func(store.AllowlistEntry) error) error {
+	args := m.Called(ctx, allowlistID, fn)
+	if entries, ok := args.Get(0).([]store.AllowlistEntry); ok {
+		for _, entry := range entries {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockAllowlistRepository) ListAddressChanges(ctx context.Context, allowlistID, afterID int64, limit int) ([]store.AddressChange, error) {
+	args := m.Called(ctx, allowlistID, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]store.AddressChange), args.Error(1)
+}
+
+func (m *MockAllowlistRepository) LatestAddressChangeID(ctx context.Context, allowlistID int64) (int64, error) {
+	args := m.Called(ctx, allowlistID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+const (
+	importAddrA = "0x1111111111111111111111111111111111111111"
+	importAddrB = "0x2222222222222222222222222222222222222222"
+	importAddrC = "0x3333333333333333333333333333333333333333"
+)
+
+// importCSV is a CSV with a header, a duplicate, an invalid row and a blank line
+const importCSV = "address,note\n" +
+	importAddrA + ",early supporter\n" +
+	"0X2222222222222222222222222222222222222222\n" +
+	"\n" +
+	"0x123\n" +
+	importAddrA + "\n" +
+	importAddrC + "\n"
+
+func newImportRequest(query, contentType, body string) *http.Request {
+	req := httptest.NewRequest("POST", "/api/admin/allowlists/7/import"+query, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	return mux.SetURLVars(req, map[string]string{"id": "7"})
+}
+
+func TestImportAddresses_DryRunReport(t *testing.T) {
+	repo := new(MockAllowlistRepository)
+	handler := NewAllowlistHandler(repo, nil, nil)
+
+	repo.On("GetAllowlist", mock.Anything, int64(7)).Return(&store.Allowlist{ID: 7}, nil)
+	repo.On("FindAddresses", mock.Anything, int64(7), []string{importAddrA, importAddrB, importAddrC}).Return([]string{importAddrC}, nil)
+
+	rec := httptest.NewRecorder()
+	handler.ImportAddresses(rec, newImportRequest("?dry_run=true", "text/csv", importCSV))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var report AllowlistImportReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 5, report.Rows)
+	assert.Equal(t, 2, report.Valid)
+	assert.Zero(t, report.Imported)
+	assert.Equal(t, []ImportDuplicateRow{{Line: 6, Address: importAddrA, FirstLine: 2}}, report.Duplicates)
+	require.Len(t, report.Invalid, 1)
+	assert.Equal(t, 5, report.Invalid[0].Line)
+	assert.Equal(t, "0x123", report.Invalid[0].Value)
+	assert.NotEmpty(t, report.Invalid[0].Reason)
+	assert.Equal(t, []string{importAddrC}, report.AlreadyPresent)
+
+	repo.AssertNotCalled(t, "AddAddresses", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestImportAddresses_ImportsNewAddresses(t *testing.T) {
+	repo := new(MockAllowlistRepository)
+	handler := NewAllowlistHandler(repo, nil, nil)
+
+	body := "address\n" + importAddrA + "\n" + importAddrB + "\n" + importAddrA + "\n"
+	repo.On("GetAllowlist", mock.Anything, int64(7)).Return(&store.Allowlist{ID: 7}, nil)
+	repo.On("FindAddresses", mock.Anything, int64(7), []string{importAddrA, importAddrB}).Return([]string{importAddrB}, nil)
+	repo.On("AddAddresses", mock.Anything, int64(7), []string{importAddrA}).Return(nil)
+
+	rec := httptest.NewRecorder()
+	handler.ImportAddresses(rec, newImportRequest("", "text/csv; charset=utf-8", body))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var report AllowlistImportReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.False(t, report.DryRun)
+	assert.Equal(t, 1, report.Imported)
+	assert.Len(t, report.Duplicates, 1)
+	repo.AssertExpectations(t)
+}
+
+func TestImportAddresses_RejectsInvalidRows(t *testing.T) {
+	repo := new(MockAllowlistRepository)
+	handler := NewAllowlistHandler(repo, nil, nil)
+
+	repo.On("GetAllowlist", mock.Anything, int64(7)).Return(&store.Allowlist{ID: 7}, nil)
+	repo.On("FindAddresses", mock.Anything, int64(7), mock.Anything).Return([]string{}, nil)
+
+	rec := httptest.NewRecorder()
+	handler.ImportAddresses(rec, newImportRequest("", "text/csv", importCSV))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	repo.AssertNotCalled(t, "AddAddresses", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestImportAddresses_RequestValidation(t *testing.T) {
+	repo := new(MockAllowlistRepository)
+	handler := NewAllowlistHandler(repo, nil, nil)
+	repo.On("GetAllowlist", mock.Anything, int64(7)).Return(&store.Allowlist{ID: 7}, nil)
+
+	rec := httptest.NewRecorder()
+	handler.ImportAddresses(rec, newImportRequest("?dry_run=maybe", "text/csv", importCSV))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ImportAddresses(rec, newImportRequest("", "application/json", `{"addresses":[]}`))
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ImportAddresses(rec, newImportRequest("", "text/csv", "\"unterminated\n"))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestImportAddresses_AllowlistNotFound(t *testing.T) {
+	repo := new(MockAllowlistRepository)
+	handler := NewAllowlistHandler(repo, nil, nil)
+	repo.On("GetAllowlist", mock.Anything, int64(7)).Return(nil, &store.NotFoundError{Resource: "allowlist", ID: int64(7)})
+
+	rec := httptest.NewRecorder()
+	handler.ImportAddresses(rec, newImportRequest("?dry_run=true", "text/csv", importCSV))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	repo = new(MockAllowlistRepository)
+	handler = NewAllowlistHandler(repo, nil, nil)
+	repo.On("GetAllowlist", mock.Anything, int64(7)).Return(nil, errors.New("database unavailable"))
+
+	rec = httptest.NewRecorder()
+	handler.ImportAddresses(rec, newImportRequest("?dry_run=true", "text/csv", importCSV))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func newExportRequest() *http.Request {
+	req := httptest.NewRequest("GET", "/api/admin/allowlists/7/export", nil)
+	return mux.SetURLVars(req, map[string]string{"id": "7"})
+}
+
+func TestExportAddresses_StreamsCSV(t *testing.T) {
+	repo := new(MockAllowlistRepository)
+	handler := NewAllowlistHandler(repo, nil, nil)
+
+	addedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	entries := make([]store.AllowlistEntry, exportFlushRows+1)
+	for i := range entries {
+		entries[i] = store.AllowlistEntry{AllowlistID: 7, Address: importAddrA, AddedAt: addedAt}
+	}
+	entries[0].Address = importAddrB
+	repo.On("GetAllowlist", mock.Anything, int64(7)).Return(&store.Allowlist{ID: 7}, nil)
+	repo.On("StreamEntries", mock.Anything, int64(7), mock.Anything).Return(entries, nil)
+
+	rec := httptest.NewRecorder()
+	handler.ExportAddresses(rec, newExportRequest())
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="allowlist-7.csv"`, rec.Header().Get("Content-Disposition"))
+	assert.True(t, rec.Flushed)
+
+	lines := strings.Split(strings.TrimSuffix(rec.Body.String(), "\n"), "\n")
+	require.Len(t, lines, exportFlushRows+2)
+	assert.Equal(t, "address,added_at", lines[0])
+	assert.Equal(t, importAddrB+",2024-05-01T12:00:00Z", lines[1])
+	assert.Equal(t, importAddrA+",2024-05-01T12:00:00Z", lines[len(lines)-1])
+}
+
+func TestExportAddresses_NotFound(t *testing.T) {
+	repo := new(MockAllowlistRepository)
+	handler := NewAllowlistHandler(repo, nil, nil)
+	repo.On("GetAllowlist", mock.Anything, int64(7)).Return(nil, &store.NotFoundError{Resource: "allowlist", ID: int64(7)})
+
+	rec := httptest.NewRecorder()
+	handler.ExportAddresses(rec, newExportRequest())
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	repo.AssertNotCalled(t, "StreamEntries", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestExportAddresses_AbortsOnStreamError(t *testing.T) {
+	repo := new(MockAllowlistRepository)
+	handler := NewAllowlistHandler(repo, nil, nil)
+	repo.On("GetAllowlist", mock.Anything, int64(7)).Return(&store.Allowlist{ID: 7}, nil)
+	repo.On("StreamEntries", mock.Anything, int64(7), mock.Anything).
+		Return([]store.AllowlistEntry{{Address: importAddrA}}, errors.New("connection reset"))
+
+	rec := httptest.NewRecorder()
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ExportAddresses(rec, newExportRequest())
+	})
+}
+
+func newMerkleRequest(query string) *http.Request {
+	req := httptest.NewRequest("GET", "/api/admin/allowlists/7/merkle"+query, nil)
+	return mux.SetURLVars(req, map[string]string{"id": "7"})
+}
+
+func TestGetMerkleTree_RootAndProofs(t *testing.T) {
+	repo := new(MockAllowlistRepository)
+	handler := NewAllowlistHandler(repo, nil, nil)
+	repo.On("GetAllowlist", mock.Anything, int64(7)).Return(&store.Allowlist{ID: 7}, nil)
+	repo.On("GetAddresses", mock.Anything, int64(7)).Return([]string{importAddrA, importAddrB, importAddrC}, nil)
+
+	tree, err := merkle.NewAddressTree([]string{importAddrA, importAddrB, importAddrC})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	handler.GetMerkleTree(rec, newMerkleRequest(""))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response AllowlistMerkleResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, int64(7), response.AllowlistID)
+	assert.Equal(t, tree.Root(), response.Root)
+	assert.Equal(t, 3, response.Count)
+	assert.Nil(t, response.Proofs)
+
+	rec = httptest.NewRecorder()
+	handler.GetMerkleTree(rec, newMerkleRequest("?proofs=true"))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Len(t, response.Proofs, 3)
+	for address, proof := range response.Proofs {
+		assert.True(t, merkle.Verify(response.Root, address, proof), address)
+	}
+}
+
+func TestGetMerkleTree_Errors(t *testing.T) {
+	repo := new(MockAllowlistRepository)
+	handler := NewAllowlistHandler(repo, nil, nil)
+	repo.On("GetAllowlist", mock.Anything, int64(7)).Return(&store.Allowlist{ID: 7}, nil)
+	repo.On("GetAddresses", mock.Anything, int64(7)).Return([]string{}, nil)
+
+	rec := httptest.NewRecorder()
+	handler.GetMerkleTree(rec, newMerkleRequest("?proofs=maybe"))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.GetMerkleTree(rec, newMerkleRequest(""))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	missing := new(MockAllowlistRepository)
+	missing.On("GetAllowlist", mock.Anything, int64(7)).Return(nil, &store.NotFoundError{Resource: "allowlist", ID: int64(7)})
+	rec = httptest.NewRecorder()
+	NewAllowlistHandler(missing, nil, nil).GetMerkleTree(rec, newMerkleRequest(""))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func newChangesRequest(query string) *http.Request {
+	req := httptest.NewRequest("GET", "/api/admin/allowlists/7/changes"+query, nil)
+	return mux.SetURLVars(req, map[string]string{"id": "7"})
+}
+
+func TestGetAddressChanges_NetChangesSinceCursor(t *testing.T) {
+	repo := new(MockAllowlistRepository)
+	handler := NewAllowlistHandler(repo, nil, nil)
+	repo.On("GetAllowlist", mock.Anything, int64(7)).Return(&store.Allowlist{ID: 7}, nil)
+	repo.On("ListAddressChanges", mock.Anything, int64(7), int64(40), 3).Return([]store.AddressChange{
+		{ID: 41, Action: store.ChangeAddressesAdded, Addresses: []string{importAddrB, importAddrA}},
+		{ID: 44, Action: store.ChangeAddressRemoved, Addresses: []string{importAddrA}},
+		{ID: 45, Action: store.ChangeAddressRemoved, Addresses: []string{importAddrC}},
+	}, nil)
+
+	rec := httptest.NewRecorder()
+	handler.GetAddressChanges(rec, newChangesRequest("?since=40&limit=2"))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response AllowlistChangesResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, int64(40), response.Since)
+	assert.Equal(t, int64(44), response.Cursor, "the cursor stops at the last change returned")
+	assert.True(t, response.HasMore)
+	assert.Equal(t, []string{importAddrB}, response.Added)
+	assert.Equal(t, []string{importAddrA}, response.Removed, "an address added and removed again is removed")
+}
+
+func TestGetAddressChanges_UpToDate(t *testing.T) {
+	repo := new(MockAllowlistRepository)
+	handler := NewAllowlistHandler(repo, nil, nil)
+	repo.On("GetAllowlist", mock.Anything, int64(7)).Return(&store.Allowlist{ID: 7}, nil)
+	repo.On("ListAddressChanges", mock.Anything, int64(7), int64(44), 101).Return([]store.AddressChange{}, nil)
+
+	rec := httptest.NewRecorder()
+	handler.GetAddressChanges(rec, newChangesRequest("?since=44"))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"allowlistId":7,"since":44,"cursor":44,"added":[],"removed":[],"hasMore":false}`, rec.Body.String())
+}
+
+func TestGetAddressChanges_WithoutCursor(t *testing.T) {
+	repo := new(MockAllowlistRepository)
+	handler := NewAllowlistHandler(repo, nil, nil)
+	repo.On("GetAllowlist", mock.Anything, int64(7)).Return(&store.Allowlist{ID: 7}, nil)
+	repo.On("LatestAddressChangeID", mock.Anything, int64(7)).Return(int64(45), nil)
+
+	rec := httptest.NewRecorder()
+	handler.GetAddressChanges(rec, newChangesRequest(""))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"allowlistId":7,"since":0,"cursor":45,"added":[],"removed":[],"hasMore":false}`, rec.Body.String())
+	repo.AssertNotCalled(t, "ListAddressChanges", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetAddressChanges_Errors(t *testing.T) {
+	for _, query := range []string{"?since=abc", "?since=-1", "?since=1&limit=0", "?since=1&limit=1001"} {
+		rec := httptest.NewRecorder()
+		NewAllowlistHandler(new(MockAllowlistRepository), nil, nil).GetAddressChanges(rec, newChangesRequest(query))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+
+	missing := new(MockAllowlistRepository)
+	missing.On("GetAllowlist", mock.Anything, int64(7)).Return(nil, &store.NotFoundError{Resource: "allowlist", ID: int64(7)})
+	rec := httptest.NewRecorder()
+	NewAllowlistHandler(missing, nil, nil).GetAddressChanges(rec, newChangesRequest("?since=1"))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	failing := new(MockAllowlistRepository)
+	failing.On("GetAllowlist", mock.Anything, int64(7)).Return(&store.Allowlist{ID: 7}, nil)
+	failing.On("ListAddressChanges", mock.Anything, int64(7), int64(1), 101).Return(nil, errors.New("connection refused"))
+	rec = httptest.NewRecorder()
+	NewAllowlistHandler(failing, nil, nil).GetAddressChanges(rec, newChangesRequest("?since=1"))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
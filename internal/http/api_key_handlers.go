@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -9,20 +10,22 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/yourusername/gatekeeper/internal/audit"
+	"github.com/yourusername/gatekeeper/internal/auth"
 	"github.com/yourusername/gatekeeper/internal/log"
 	"github.com/yourusername/gatekeeper/internal/store"
 )
 
 // APIKeyHandler handles API key management endpoints
 type APIKeyHandler struct {
-	apiKeyRepo  store.APIKeyRepositoryInterface
-	userRepo    store.UserRepositoryInterface
-	logger      *log.Logger
-	auditLogger audit.AuditLogger
+	apiKeyRepo   store.APIKeyRepositoryInterface
+	userRepo     store.UserRepositoryInterface
+	logger       log.Logger
+	auditLogger  audit.AuditLogger
+	scopeCatalog *auth.ScopeCatalog // Optional; when set, only catalog scopes may be requested
 }
 
 // NewAPIKeyHandler creates a new API key handler
-func NewAPIKeyHandler(apiKeyRepo store.APIKeyRepositoryInterface, userRepo store.UserRepositoryInterface, logger *log.Logger, auditLogger audit.AuditLogger) *APIKeyHandler {
+func NewAPIKeyHandler(apiKeyRepo store.APIKeyRepositoryInterface, userRepo store.UserRepositoryInterface, logger log.Logger, auditLogger audit.AuditLogger) *APIKeyHandler {
 	return &APIKeyHandler{
 		apiKeyRepo:  apiKeyRepo,
 		userRepo:    userRepo,
@@ -31,6 +34,11 @@ func NewAPIKeyHandler(apiKeyRepo store.APIKeyRepositoryInterface, userRepo store
 	}
 }
 
+// SetScopeCatalog restricts the scopes that can be requested for new keys
+func (h *APIKeyHandler) SetScopeCatalog(catalog *auth.ScopeCatalog) {
+	h.scopeCatalog = catalog
+}
+
 // CreateAPIKeyRequest represents the request to create a new API key
 type CreateAPIKeyRequest struct {
 	Name             string   `json:"name"`
@@ -75,6 +83,7 @@ type ErrorResponse struct {
 // CreateAPIKey handles POST /api/keys - Generate new API key
 func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	logger := requestLogger(r, h.logger)
 
 	// Get user from JWT context
 	claims := ClaimsFromContext(r)
@@ -103,6 +112,10 @@ func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
 		h.writeError(w, "Validation failed", "At least one scope is required", http.StatusBadRequest)
 		return
 	}
+	if detail := unknownScopesDetail(h.scopeCatalog, req.Scopes); detail != "" {
+		h.writeError(w, "Validation failed", detail, http.StatusBadRequest)
+		return
+	}
 	if req.ExpiresInSeconds != nil && *req.ExpiresInSeconds <= 0 {
 		h.writeError(w, "Validation failed", "ExpiresInSeconds must be positive", http.StatusBadRequest)
 		return
@@ -111,11 +124,17 @@ func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
 	// Get or create user by address
 	user, err := h.userRepo.GetOrCreateUserByAddress(ctx, claims.Address)
 	if err != nil {
-		h.logger.Error(fmt.Sprintf("Failed to get/create user for address %s: %v", claims.Address, err))
+		logger.Error("failed to get or create user", log.Error(err))
 		h.writeError(w, "Internal server error", "Failed to retrieve user", http.StatusInternalServerError)
 		return
 	}
 
+	// Keys may not grant more than their holder has
+	if detail := excessScopesDetail(r, h.scopeCatalog, user.Role, req.Scopes); detail != "" {
+		h.writeError(w, "Forbidden", detail, http.StatusForbidden)
+		return
+	}
+
 	// Convert seconds to duration if provided
 	var expiresIn *time.Duration
 	if req.ExpiresInSeconds != nil {
@@ -134,7 +153,7 @@ func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
 	// Create API key
 	rawKey, apiKeyResponse, err := h.apiKeyRepo.CreateAPIKey(ctx, repoReq)
 	if err != nil {
-		h.logger.Error(fmt.Sprintf("Failed to create API key for user %d: %v", user.ID, err))
+		logger.Error("failed to create API key", log.Int64("user_id", user.ID), log.Error(err))
 
 		// Audit log: API key creation failed
 		if h.auditLogger != nil {
@@ -177,7 +196,7 @@ func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Log the creation
-	h.logger.Info(fmt.Sprintf("API key created: user=%s, name=%s, id=%d", claims.Address, req.Name, apiKeyResponse.ID))
+	logger.Info("API key created", log.Int64("key_id", apiKeyResponse.ID), log.String("key_name", req.Name))
 
 	// Prepare response
 	response := CreateAPIKeyResponse{
@@ -201,6 +220,7 @@ func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
 // ListAPIKeys handles GET /api/keys - List user's API keys
 func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	logger := requestLogger(r, h.logger)
 
 	// Get user from JWT context
 	claims := ClaimsFromContext(r)
@@ -212,7 +232,7 @@ func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
 	// Get user by address
 	user, err := h.userRepo.GetUserByAddress(ctx, claims.Address)
 	if err != nil {
-		h.logger.Error(fmt.Sprintf("Failed to get user for address %s: %v", claims.Address, err))
+		logger.Error("failed to get user", log.Error(err))
 		h.writeError(w, "User not found", "User does not exist", http.StatusNotFound)
 		return
 	}
@@ -220,7 +240,7 @@ func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
 	// List API keys for user
 	keys, err := h.apiKeyRepo.ListAPIKeys(ctx, user.ID)
 	if err != nil {
-		h.logger.Error(fmt.Sprintf("Failed to list API keys for user %d: %v", user.ID, err))
+		logger.Error("failed to list API keys", log.Int64("user_id", user.ID), log.Error(err))
 
 		// Audit log: API key listing failed
 		if h.auditLogger != nil {
@@ -276,6 +296,7 @@ func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
 // RevokeAPIKey handles DELETE /api/keys/{id} - Revoke API key
 func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	logger := requestLogger(r, h.logger)
 
 	// Get user from JWT context
 	claims := ClaimsFromContext(r)
@@ -301,7 +322,7 @@ func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
 	// Get user by address
 	user, err := h.userRepo.GetUserByAddress(ctx, claims.Address)
 	if err != nil {
-		h.logger.Error(fmt.Sprintf("Failed to get user for address %s: %v", claims.Address, err))
+		logger.Error("failed to get user", log.Error(err))
 		h.writeError(w, "User not found", "User does not exist", http.StatusNotFound)
 		return
 	}
@@ -313,9 +334,9 @@ func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if the key belongs to the user
-	if apiKey.UserID != user.ID {
-		h.logger.Warn(fmt.Sprintf("User %s attempted to revoke key %d owned by user %d", claims.Address, keyID, apiKey.UserID))
+	// Check if the key belongs to the user or to a service account they own
+	if apiKey.UserID != user.ID && !h.ownsServiceAccount(ctx, user.ID, apiKey.UserID) {
+		logger.Warn("attempt to revoke API key owned by another user", log.Int64("key_id", keyID), log.Int64("owner_id", apiKey.UserID))
 		h.writeError(w, "Forbidden", "You do not have permission to revoke this API key", http.StatusForbidden)
 		return
 	}
@@ -323,7 +344,7 @@ func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
 	// Delete the API key
 	err = h.apiKeyRepo.DeleteAPIKey(ctx, keyID)
 	if err != nil {
-		h.logger.Error(fmt.Sprintf("Failed to delete API key %d: %v", keyID, err))
+		logger.Error("failed to delete API key", log.Int64("key_id", keyID), log.Error(err))
 
 		// Audit log: API key revocation failed
 		if h.auditLogger != nil {
@@ -357,12 +378,21 @@ func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Log the revocation
-	h.logger.Info(fmt.Sprintf("API key revoked: user=%s, key_id=%d, name=%s", claims.Address, keyID, apiKey.Name))
+	logger.Info("API key revoked", log.Int64("key_id", keyID), log.String("key_name", apiKey.Name))
 
 	// Return 204 No Content on success
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// ownsServiceAccount reports whether accountID is a service account owned by ownerID
+func (h *APIKeyHandler) ownsServiceAccount(ctx context.Context, ownerID, accountID int64) bool {
+	account, err := h.userRepo.GetUserByID(ctx, accountID)
+	if err != nil {
+		return false
+	}
+	return account.IsServiceAccount() && account.OwnerID != nil && *account.OwnerID == ownerID
+}
+
 // writeError writes a JSON error response
 func (h *APIKeyHandler) writeError(w http.ResponseWriter, error, details string, statusCode int) {
 	w.Header().Set("Content-Type", "application/json")
@@ -93,6 +93,11 @@ func (m *MockUserRepository) GetUserByID(ctx context.Context, id int64) (*store.
 	return args.Get(0).(*store.User), args.Error(1)
 }
 
+func (m *MockUserRepository) RecordLogin(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) CreateUser(ctx context.Context, address string) (*store.User, error) {
 	args := m.Called(ctx, address)
 	if args.Get(0) == nil {
@@ -388,6 +393,7 @@ func TestRevokeAPIKey_Forbidden(t *testing.T) {
 
 	// Setup expectations
 	userRepo.On("GetUserByAddress", mock.Anything, testUser.Address).Return(testUser, nil)
+	userRepo.On("GetUserByID", mock.Anything, int64(999)).Return(&store.User{ID: 999, AccountType: store.AccountTypeWallet}, nil)
 	apiKeyRepo.On("GetAPIKeyByID", mock.Anything, int64(1)).Return(testAPIKey, nil)
 
 	// Create request with URL parameter
@@ -5,8 +5,8 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,22 +18,53 @@ import (
 
 // APIKeyMiddleware creates a middleware that validates API keys
 type APIKeyMiddleware struct {
-	apiKeyRepo  store.APIKeyRepositoryInterface
-	userRepo    store.UserRepositoryInterface
-	logger      *log.Logger
-	auditLogger audit.AuditLogger
+	apiKeyRepo   store.APIKeyRepositoryInterface
+	userRepo     store.UserRepositoryInterface
+	logger       log.Logger
+	auditLogger  audit.AuditLogger
+	scopeCatalog *auth.ScopeCatalog // Optional; expands implied scopes into claims
+	headerNames  []string           // Headers carrying the raw key, checked in order
+	cookieName   string             // Optional; cookie carrying the key on HTTPS requests
+	deprecations *KeyDeprecations   // Optional; announces deprecated keys and rejects sunset ones
 }
 
+// DefaultAPIKeyHeader is the header API keys are read from unless configured otherwise
+const DefaultAPIKeyHeader = "X-API-Key"
+
 // NewAPIKeyMiddleware creates a new API key middleware
-func NewAPIKeyMiddleware(apiKeyRepo store.APIKeyRepositoryInterface, userRepo store.UserRepositoryInterface, logger *log.Logger, auditLogger audit.AuditLogger) *APIKeyMiddleware {
+func NewAPIKeyMiddleware(apiKeyRepo store.APIKeyRepositoryInterface, userRepo store.UserRepositoryInterface, logger log.Logger, auditLogger audit.AuditLogger) *APIKeyMiddleware {
 	return &APIKeyMiddleware{
 		apiKeyRepo:  apiKeyRepo,
 		userRepo:    userRepo,
 		logger:      logger,
 		auditLogger: auditLogger,
+		headerNames: []string{DefaultAPIKeyHeader},
 	}
 }
 
+// SetHeaderNames sets the headers API keys are read from, in order of precedence.
+// Authorization: Bearer is always accepted after them.
+func (m *APIKeyMiddleware) SetHeaderNames(names ...string) {
+	m.headerNames = names
+}
+
+// SetCookieName enables reading API keys from the named cookie. The cookie is only
+// honored on HTTPS requests so keys never travel in plaintext cookies.
+func (m *APIKeyMiddleware) SetCookieName(name string) {
+	m.cookieName = name
+}
+
+// SetScopeCatalog enables expansion of implied scopes for authenticated keys
+func (m *APIKeyMiddleware) SetScopeCatalog(catalog *auth.ScopeCatalog) {
+	m.scopeCatalog = catalog
+}
+
+// SetDeprecations enables deprecation headers on requests made with deprecated
+// keys. Keys past their sunset are rejected even before they are revoked.
+func (m *APIKeyMiddleware) SetDeprecations(deprecations *KeyDeprecations) {
+	m.deprecations = deprecations
+}
+
 // Middleware returns the HTTP middleware function
 func (m *APIKeyMiddleware) Middleware() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -59,17 +90,17 @@ func (m *APIKeyMiddleware) Middleware() func(http.Handler) http.Handler {
 
 			// Validate API key format (hex-encoded, 64 characters)
 			if !m.isValidAPIKeyFormat(apiKey) {
-				m.logger.Warn(fmt.Sprintf("Invalid API key format from %s", r.RemoteAddr))
+				requestLogger(r, m.logger).Warn("invalid API key format", log.String("remote_addr", r.RemoteAddr))
 
 				// Audit log: Invalid API key format
 				if m.auditLogger != nil {
-					m.auditLogger.LogAuthAttempt(ctx, audit.AuditEvent{
+					m.auditLogger.LogAuthAttempt(ctx, withClientInfo(r, audit.AuditEvent{
 						Result:   audit.ResultFailure,
 						Method:   r.Method,
 						Endpoint: r.URL.Path,
 						IPAddr:   r.RemoteAddr,
 						Error:    "invalid_api_key_format",
-					})
+					}))
 				}
 
 				m.writeUnauthorized(w, "invalid_api_key", "API key format is invalid")
@@ -79,7 +110,7 @@ func (m *APIKeyMiddleware) Middleware() func(http.Handler) http.Handler {
 			// Validate API key against database
 			apiKeyData, err := m.apiKeyRepo.ValidateAPIKey(ctx, apiKey)
 			if err != nil {
-				m.logger.Warn(fmt.Sprintf("API key validation failed: %v", err))
+				requestLogger(r, m.logger).Warn("API key validation failed", log.Error(err))
 
 				// Audit log: API key validation failed
 				if m.auditLogger != nil {
@@ -92,28 +123,38 @@ func (m *APIKeyMiddleware) Middleware() func(http.Handler) http.Handler {
 						errorType = "key_not_found"
 					}
 
-					m.auditLogger.LogAuthAttempt(ctx, audit.AuditEvent{
+					m.auditLogger.LogAuthAttempt(ctx, withClientInfo(r, audit.AuditEvent{
 						Result:      audit.ResultFailure,
 						Method:      r.Method,
 						Endpoint:    r.URL.Path,
 						IPAddr:      r.RemoteAddr,
 						Error:       errorType,
 						ErrorDetail: err.Error(),
-					})
+					}))
 				}
 
 				m.writeUnauthorized(w, "invalid_api_key", "API key not found or expired")
 				return
 			}
 
+			if m.deprecations != nil {
+				if !m.checkDeprecation(w, r, apiKeyData) {
+					return
+				}
+			}
+
 			// Get user information
 			user, err := m.userRepo.GetUserByID(ctx, apiKeyData.UserID)
 			if err != nil {
-				m.logger.Error(fmt.Sprintf("Failed to get user %d for API key %d: %v", apiKeyData.UserID, apiKeyData.ID, err))
+				requestLogger(r, m.logger).Error("failed to get user for API key",
+					log.Int64("user_id", apiKeyData.UserID),
+					log.Int64("key_id", apiKeyData.ID),
+					log.Error(err),
+				)
 
 				// Audit log: User lookup failed
 				if m.auditLogger != nil {
-					m.auditLogger.LogAuthAttempt(ctx, audit.AuditEvent{
+					m.auditLogger.LogAuthAttempt(ctx, withClientInfo(r, audit.AuditEvent{
 						Result:      audit.ResultFailure,
 						KeyID:       apiKeyData.ID,
 						KeyName:     apiKeyData.Name,
@@ -122,7 +163,7 @@ func (m *APIKeyMiddleware) Middleware() func(http.Handler) http.Handler {
 						IPAddr:      r.RemoteAddr,
 						Error:       "user_not_found",
 						ErrorDetail: err.Error(),
-					})
+					}))
 				}
 
 				m.writeUnauthorized(w, "invalid_api_key", "User not found")
@@ -130,28 +171,44 @@ func (m *APIKeyMiddleware) Middleware() func(http.Handler) http.Handler {
 			}
 
 			// Create claims from API key data
+			// Service accounts have no address and are identified by subject
+			// Scopes implied through the catalog are held to the holder's role too
+			scopes := m.scopesWithinRole(r, user, apiKeyData.Scopes)
+			if m.scopeCatalog != nil {
+				scopes = m.scopesWithinRole(r, user, m.scopeCatalog.Expand(scopes))
+			}
 			claims := &auth.Claims{
 				Address: user.Address,
-				Scopes:  apiKeyData.Scopes,
+				Scopes:  scopes,
+			}
+			claims.Subject = user.Identity()
+
+			var serviceAccount string
+			if user.IsServiceAccount() {
+				serviceAccount = user.Name
 			}
 
 			// Inject claims into context
 			ctx = context.WithValue(ctx, ClaimsContextKey, claims)
+			ctx = context.WithValue(ctx, AuthMethodContextKey, AuthMethodAPIKey)
+			ctx = context.WithValue(ctx, APIKeyIDContextKey, apiKeyData.ID)
+			ctx = withAuthenticatedLogger(ctx, claims)
 			r = r.WithContext(ctx)
 
 			// Audit log: Successful authentication
 			if m.auditLogger != nil {
-				m.auditLogger.LogAuthAttempt(ctx, audit.AuditEvent{
-					Result:     audit.ResultSuccess,
-					UserAddr:   user.Address,
-					KeyID:      apiKeyData.ID,
-					KeyName:    apiKeyData.Name,
-					KeyScopes:  apiKeyData.Scopes,
-					Method:     r.Method,
-					Endpoint:   r.URL.Path,
-					IPAddr:     r.RemoteAddr,
-					ResourceID: fmt.Sprintf("key:%d", apiKeyData.ID),
-				})
+				m.auditLogger.LogAuthAttempt(ctx, withClientInfo(r, audit.AuditEvent{
+					Result:         audit.ResultSuccess,
+					UserAddr:       user.Address,
+					ServiceAccount: serviceAccount,
+					KeyID:          apiKeyData.ID,
+					KeyName:        apiKeyData.Name,
+					KeyScopes:      apiKeyData.Scopes,
+					Method:         r.Method,
+					Endpoint:       r.URL.Path,
+					IPAddr:         r.RemoteAddr,
+					ResourceID:     "key:" + strconv.FormatInt(apiKeyData.ID, 10),
+				}))
 			}
 
 			// Update last_used_at in background (non-blocking)
@@ -163,29 +220,35 @@ func (m *APIKeyMiddleware) Middleware() func(http.Handler) http.Handler {
 				if err := m.apiKeyRepo.UpdateLastUsed(ctx, apiKeyData.KeyHash); err != nil {
 					// Only log non-context errors to avoid noise from timeout/cancellation
 					if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
-						m.logger.Error(fmt.Sprintf("Failed to update last_used_at for API key %d: %v", apiKeyData.ID, err))
+						requestLogger(r, m.logger).Error("failed to update last_used_at for API key",
+							log.Int64("key_id", apiKeyData.ID),
+							log.Error(err),
+						)
 					}
 				}
 
 				// Audit log: API key usage (async)
 				if m.auditLogger != nil {
-					m.auditLogger.LogAsync(audit.AuditEvent{
-						Action:     audit.ActionAPIKeyUsed,
-						Result:     audit.ResultSuccess,
-						UserAddr:   user.Address,
-						KeyID:      apiKeyData.ID,
-						KeyName:    apiKeyData.Name,
-						Method:     r.Method,
-						Endpoint:   r.URL.Path,
-						IPAddr:     r.RemoteAddr,
-						ResourceID: fmt.Sprintf("key:%d", apiKeyData.ID),
-					})
+					m.auditLogger.LogAsync(withClientInfo(r, audit.AuditEvent{
+						Action:         audit.ActionAPIKeyUsed,
+						Result:         audit.ResultSuccess,
+						UserAddr:       user.Address,
+						ServiceAccount: serviceAccount,
+						KeyID:          apiKeyData.ID,
+						KeyName:        apiKeyData.Name,
+						Method:         r.Method,
+						Endpoint:       r.URL.Path,
+						IPAddr:         r.RemoteAddr,
+						ResourceID:     "key:" + strconv.FormatInt(apiKeyData.ID, 10),
+					}))
 				}
 			}()
 
 			// Log successful authentication
-			m.logger.Info(fmt.Sprintf("API key authentication successful: user=%s, key_id=%d, key_name=%s",
-				user.Address, apiKeyData.ID, apiKeyData.Name))
+			requestLogger(r, m.logger).Info("API key authentication successful",
+				log.Int64("key_id", apiKeyData.ID),
+				log.String("key_name", apiKeyData.Name),
+			)
 
 			// Call next handler
 			next.ServeHTTP(w, r)
@@ -193,32 +256,85 @@ func (m *APIKeyMiddleware) Middleware() func(http.Handler) http.Handler {
 	}
 }
 
-// extractAPIKey extracts the API key from the request headers
-// Supports both X-API-Key header and Authorization: Bearer format
+// checkDeprecation sets the deprecation headers for a deprecated key. It writes
+// an error and returns false if the key is past its sunset. Failing to look up
+// the deprecations only loses the headers; the sunset job still revokes the key.
+func (m *APIKeyMiddleware) checkDeprecation(w http.ResponseWriter, r *http.Request, apiKeyData *store.APIKey) bool {
+	deprecations, err := m.deprecations.Matching(r.Context(), apiKeyData)
+	if err != nil {
+		requestLogger(r, m.logger).Warn("failed to look up API key deprecations", log.Error(err))
+		return true
+	}
+	if len(deprecations) == 0 {
+		return true
+	}
+
+	if !time.Now().Before(deprecations[0].SunsetAt) {
+		requestLogger(r, m.logger).Warn("API key used after its sunset",
+			log.Int64("key_id", apiKeyData.ID),
+			log.Int64("deprecation_id", deprecations[0].ID),
+		)
+
+		if m.auditLogger != nil {
+			m.auditLogger.LogAuthAttempt(r.Context(), withClientInfo(r, audit.AuditEvent{
+				Result:   audit.ResultFailure,
+				KeyID:    apiKeyData.ID,
+				KeyName:  apiKeyData.Name,
+				Method:   r.Method,
+				Endpoint: r.URL.Path,
+				IPAddr:   r.RemoteAddr,
+				Error:    "key_sunset",
+			}))
+		}
+
+		m.writeUnauthorized(w, "invalid_api_key", "API key was revoked at its sunset")
+		return false
+	}
+
+	setDeprecationHeaders(w.Header(), deprecations)
+	return true
+}
+
+// extractAPIKey extracts the API key from the request
+// Supports the configured key headers, Authorization: Bearer format and the key cookie
 func (m *APIKeyMiddleware) extractAPIKey(r *http.Request) string {
-	// Primary: X-API-Key header
-	apiKey := r.Header.Get("X-API-Key")
-	if apiKey != "" {
-		return strings.TrimSpace(apiKey)
+	// Primary: configured key headers (X-API-Key by default)
+	for _, name := range m.headerNames {
+		if apiKey := strings.TrimSpace(r.Header.Get(name)); apiKey != "" {
+			return apiKey
+		}
 	}
 
 	// Fallback: Authorization: Bearer <key>
 	authHeader := r.Header.Get("Authorization")
 	if authHeader != "" {
-		parts := strings.Fields(authHeader)
-		if len(parts) == 2 && parts[0] == "Bearer" {
+		if token, ok := parseBearerToken(authHeader); ok {
 			// Check if it looks like an API key (64 hex chars) vs JWT
 			// JWT tokens contain dots, API keys don't
-			token := parts[1]
 			if !strings.Contains(token, ".") && len(token) == 64 {
 				return token
 			}
 		}
 	}
 
+	// Last: key cookie, only over HTTPS
+	if m.cookieName != "" && isSecureRequest(r) {
+		if cookie, err := r.Cookie(m.cookieName); err == nil {
+			return strings.TrimSpace(cookie.Value)
+		}
+	}
+
 	return ""
 }
 
+// isSecureRequest reports whether the request reached us, or the proxy in front of us, over TLS
+func isSecureRequest(r *http.Request) bool {
+	if r.TLS != nil {
+		return true
+	}
+	return trustForwardedHeaders && strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+}
+
 // isValidAPIKeyFormat validates that the API key is a valid hex string of 64 characters
 func (m *APIKeyMiddleware) isValidAPIKeyFormat(apiKey string) bool {
 	// Must be exactly 64 characters (32 bytes hex-encoded)
@@ -243,3 +359,22 @@ func (m *APIKeyMiddleware) writeUnauthorized(w http.ResponseWriter, errorCode, d
 
 	json.NewEncoder(w).Encode(response)
 }
+
+// scopesWithinRole returns the scopes of a key without those granting a role its
+// holder does not hold in users.role, so keys issued before a role was revoked
+// lose it with the user. Service accounts hold the role of the wallet owning them.
+func (m *APIKeyMiddleware) scopesWithinRole(r *http.Request, user *store.User, scopes []string) []string {
+	if len(auth.ScopesBeyondRole(scopes, auth.Role(user.Role))) == 0 {
+		return scopes
+	}
+	role := user.Role
+	if user.IsServiceAccount() && user.OwnerID != nil {
+		owner, err := m.userRepo.GetUserByID(r.Context(), *user.OwnerID)
+		if err != nil {
+			requestLogger(r, m.logger).Warn("failed to get owner of service account", log.Int64("user_id", user.ID), log.Error(err))
+		} else {
+			role = owner.Role
+		}
+	}
+	return auth.WithinRole(scopes, auth.Role(role))
+}
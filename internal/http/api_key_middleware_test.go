@@ -362,3 +362,97 @@ func TestAPIKeyMiddleware_BearerToken_JWT_IgnoredCorrectly(t *testing.T) {
 
 	// No expectations on repos since JWT tokens are ignored by API key middleware
 }
+
+func TestAPIKeyMiddleware_CustomHeaderNames(t *testing.T) {
+	middleware := NewAPIKeyMiddleware(new(MockAPIKeyRepository), new(MockUserRepository), nil, nil)
+	middleware.SetHeaderNames("X-Gateway-Key", "X-Legacy-Token")
+	rawKey := "a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2"
+
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.Header.Set("X-Legacy-Token", " "+rawKey+" ")
+	assert.Equal(t, rawKey, middleware.extractAPIKey(req))
+
+	// The default header is no longer read once replaced
+	req = httptest.NewRequest("GET", "/api/data", nil)
+	req.Header.Set("X-API-Key", rawKey)
+	assert.Empty(t, middleware.extractAPIKey(req))
+
+	// Bearer keys are still accepted
+	req = httptest.NewRequest("GET", "/api/data", nil)
+	req.Header.Set("Authorization", "Bearer "+rawKey)
+	assert.Equal(t, rawKey, middleware.extractAPIKey(req))
+}
+
+func TestAPIKeyMiddleware_CookieOnlyOverHTTPS(t *testing.T) {
+	middleware := NewAPIKeyMiddleware(new(MockAPIKeyRepository), new(MockUserRepository), nil, nil)
+	rawKey := "a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2"
+	cookie := &http.Cookie{Name: "gk_api_key", Value: rawKey}
+
+	// Cookies are ignored until a cookie name is configured
+	req := httptest.NewRequest("GET", "https://gatekeeper.example.com/api/data", nil)
+	req.AddCookie(cookie)
+	assert.Empty(t, middleware.extractAPIKey(req))
+
+	middleware.SetCookieName("gk_api_key")
+	assert.Equal(t, rawKey, middleware.extractAPIKey(req))
+
+	// Plaintext requests do not get cookie authentication
+	req = httptest.NewRequest("GET", "http://gatekeeper.example.com/api/data", nil)
+	req.AddCookie(cookie)
+	assert.Empty(t, middleware.extractAPIKey(req))
+
+	// Unless TLS was terminated by a trusted proxy
+	req.Header.Set("X-Forwarded-Proto", "https")
+	assert.Equal(t, rawKey, middleware.extractAPIKey(req))
+
+	SetTrustForwardedHeaders(false)
+	defer SetTrustForwardedHeaders(true)
+	assert.Empty(t, middleware.extractAPIKey(req))
+}
+
+// TestAPIKeyMiddleware_RoleScopesFollowUserRole drops role scopes the key's holder
+// no longer holds, and gives service account keys the role of their owner
+func TestAPIKeyMiddleware_RoleScopesFollowUserRole(t *testing.T) {
+	ownerID := int64(1)
+	tests := []struct {
+		name   string
+		user   *store.User
+		owner  *store.User
+		scopes []string
+	}{
+		{"demoted user", &store.User{ID: 1, Address: "0x1234567890123456789012345678901234567890"}, nil, []string{"read"}},
+		{"operator", &store.User{ID: 1, Address: "0x1234567890123456789012345678901234567890", Role: "operator"}, nil, []string{"read", "operator"}},
+		{"service account of an admin", &store.User{ID: 2, Name: "ci", AccountType: store.AccountTypeService, OwnerID: &ownerID}, &store.User{ID: 1, Role: "admin"}, []string{"read", "operator", "admin", "*"}},
+		{"service account of a user", &store.User{ID: 2, Name: "ci", AccountType: store.AccountTypeService, OwnerID: &ownerID}, &store.User{ID: 1}, []string{"read"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiKeyRepo := new(MockAPIKeyRepository)
+			userRepo := new(MockUserRepository)
+			logger, _ := log.New("info")
+			middleware := NewAPIKeyMiddleware(apiKeyRepo, userRepo, logger, nil)
+
+			rawKey := "a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2"
+			apiKeyRepo.On("ValidateAPIKey", mock.Anything, rawKey).Return(&store.APIKey{
+				ID: 1, UserID: tt.user.ID, KeyHash: "abcdef1234567890", Name: "Test Key",
+				Scopes: []string{"read", "operator", "admin", "*"},
+			}, nil)
+			apiKeyRepo.On("UpdateLastUsed", mock.Anything, mock.Anything).Return(nil)
+			userRepo.On("GetUserByID", mock.Anything, tt.user.ID).Return(tt.user, nil)
+			if tt.owner != nil {
+				userRepo.On("GetUserByID", mock.Anything, tt.owner.ID).Return(tt.owner, nil)
+			}
+
+			var scopes []string
+			handler := middleware.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				scopes = ClaimsFromContext(r).Scopes
+			}))
+			req := httptest.NewRequest("GET", "/api/data", nil)
+			req.Header.Set("X-API-Key", rawKey)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.scopes, scopes)
+			time.Sleep(10 * time.Millisecond)
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/common"
+	"github.com/yourusername/gatekeeper/internal/decisionlog"
+	"github.com/yourusername/gatekeeper/internal/log"
+	"github.com/yourusername/gatekeeper/internal/policy"
+)
+
+// maxAuthzCheckBytes caps the size of a policy decision request
+const maxAuthzCheckBytes = 64 << 10
+
+// Decisions of a policy decision request
+const (
+	DecisionAllow = "allow"
+	DecisionDeny  = "deny"
+)
+
+// AuthzCheckRequest represents the request body for POST /api/authz/check and
+// POST /api/admin/authz/explain
+type AuthzCheckRequest struct {
+	Address string            `json:"address"`          // Subject's wallet address; empty for subjects without one
+	Method  string            `json:"method,omitempty"` // Defaults to GET
+	Path    string            `json:"path"`             // May carry a query string
+	Context AuthzCheckContext `json:"context"`
+}
+
+// AuthzCheckContext describes the subject and the request the decision is for,
+// standing in for what the policy middleware would take from a real request
+type AuthzCheckContext struct {
+	Scopes   []string               `json:"scopes,omitempty"`
+	Claims   map[string]interface{} `json:"claims,omitempty"` // Other token claims, e.g. role or tier
+	Headers  map[string]string      `json:"headers,omitempty"`
+	ClientIP string                 `json:"clientIp,omitempty"`
+	ChainID  uint64                 `json:"chainId,omitempty"` // Defaults to the X-Chain-ID header, then the configured chain
+	// Wallets linked to the subject; looked up from its account when omitted
+	Wallets []string `json:"wallets,omitempty"`
+}
+
+// RuleDecisionResponse is the outcome of one rule for the subject
+type RuleDecisionResponse struct {
+	Type    string `json:"type"`
+	Outcome string `json:"outcome"`         // pass, fail or error
+	Error   string `json:"error,omitempty"` // Why the rule could not be evaluated
+	// What the rule requires; explanations only
+	Requirement *GateRequirement `json:"requirement,omitempty"`
+}
+
+// PolicyDecision is the outcome of one enforced policy for the subject
+type PolicyDecision struct {
+	Method    string                 `json:"method"`
+	Path      string                 `json:"path"`
+	Logic     string                 `json:"logic"`
+	Threshold int                    `json:"threshold,omitempty"` // SCORE logic only
+	Tier      string                 `json:"tier,omitempty"`      // Tier granted under TIER logic
+	Outcome   string                 `json:"outcome"`             // pass, fail or error
+	Rules     []RuleDecisionResponse `json:"rules"`
+}
+
+// AuthzCheckResponse represents the response for POST /api/authz/check and
+// POST /api/admin/authz/explain
+type AuthzCheckResponse struct {
+	Decision string           `json:"decision"` // allow or deny
+	Reason   string           `json:"reason"`   // no_policies, policies_passed, address_blocked, policy_failed, evaluation_error or blocklist_error
+	Address  string           `json:"address,omitempty"`
+	Method   string           `json:"method"`
+	Path     string           `json:"path"`
+	DeniedBy *ProblemPolicy   `json:"deniedBy,omitempty"`
+	Tier     string           `json:"tier,omitempty"` // Granted by the first TIER policy, if allowed
+	Policies []PolicyDecision `json:"policies"`
+}
+
+// AuthzHandler answers policy decision requests, so systems that do not sit behind
+// the gateway (indexers, bots, backend jobs) can use it as a policy decision point.
+// A decision is made the way the policy middleware makes it for a real request,
+// except that every rule of every enforced policy is evaluated to report on it, and
+// quota reserved by quota rules is released: asking for a decision consumes none.
+type AuthzHandler struct {
+	middleware *PolicyMiddleware
+}
+
+// NewAuthzHandler creates a new policy decision handler evaluating the policies of
+// the policy middleware
+func NewAuthzHandler(middleware *PolicyMiddleware) *AuthzHandler {
+	return &AuthzHandler{middleware: middleware}
+}
+
+// Check handles POST /api/authz/check - The decision the policies of a route make
+// for a subject, with the outcome of each enforced policy and each of its rules
+func (h *AuthzHandler) Check(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, false)
+}
+
+// Explain handles POST /api/admin/authz/explain - The decision of Check, with what
+// each rule requires and the rules that failed in the denying policy, thresholds
+// included whatever the denial detail of 403 responses
+func (h *AuthzHandler) Explain(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, true)
+}
+
+// decide answers a policy decision request, explaining the requirements of the
+// rules if explain is set
+func (h *AuthzHandler) decide(w http.ResponseWriter, r *http.Request, explain bool) {
+	pm := h.middleware
+
+	if ClaimsFromContext(r) == nil {
+		h.writeError(w, "Unauthorized", "No authentication claims found", http.StatusUnauthorized)
+		return
+	}
+
+	var req AuthzCheckRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAuthzCheckBytes)).Decode(&req); err != nil {
+		h.writeError(w, "Invalid request body", err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Address != "" {
+		address, err := common.NormalizeAddress(req.Address)
+		if err != nil {
+			h.writeError(w, "Validation failed", "address must be an Ethereum address", http.StatusBadRequest)
+			return
+		}
+		req.Address = address
+	}
+	if req.Path == "" {
+		h.writeError(w, "Validation failed", "path is required", http.StatusBadRequest)
+		return
+	}
+	target, err := url.ParseRequestURI(req.Path)
+	if err != nil || target.IsAbs() || !strings.HasPrefix(target.Path, "/") {
+		h.writeError(w, "Validation failed", "path must be an absolute path, e.g. /api/data", http.StatusBadRequest)
+		return
+	}
+	req.Method = strings.ToUpper(req.Method)
+	if req.Method == "" {
+		req.Method = http.MethodGet
+	}
+	subject, err := req.claims()
+	if err != nil {
+		h.writeError(w, "Validation failed", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Rules see the request as if the subject had made it to the route
+	targetRequest := r.Clone(r.Context())
+	targetRequest.Method = req.Method
+	targetRequest.URL = &url.URL{Path: target.Path, RawQuery: target.RawQuery}
+	targetRequest.Header = make(http.Header, len(req.Context.Headers))
+	for name, value := range req.Context.Headers {
+		targetRequest.Header.Set(name, value)
+	}
+
+	response := AuthzCheckResponse{
+		Decision: DecisionAllow,
+		Address:  req.Address,
+		Method:   req.Method,
+		Path:     target.Path,
+		Policies: []PolicyDecision{},
+	}
+	start := time.Now()
+	defer func() {
+		record := decisionlog.Record{
+			Decision:  decisionlog.DecisionAllowed,
+			Reason:    response.Reason,
+			Policies:  len(response.Policies),
+			Tier:      response.Tier,
+			LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+		}
+		if response.Decision == DecisionDeny {
+			record.Decision = decisionlog.DecisionDenied
+		}
+		if response.DeniedBy != nil {
+			record.DeniedBy = &decisionlog.PolicyRef{Method: response.DeniedBy.Method, Path: response.DeniedBy.Path}
+		}
+		pm.logDecision(targetRequest, subject, record)
+		h.writeResponse(w, response)
+	}()
+
+	if pm.blocklist != nil && subject.Address != "" {
+		blocked, err := pm.blocklist.FindBlockedAddresses(r.Context(), []string{subject.Address})
+		if err != nil || len(blocked) > 0 {
+			response.Decision = DecisionDeny
+			response.Reason = "address_blocked"
+			if err != nil {
+				requestLogger(r, pm.logger).WithFields(log.Error(err)).Warn("blocklist check failed")
+				response.Reason = "blocklist_error"
+			}
+			return
+		}
+	}
+
+	policies, _ := splitShadowPolicies(pm.policyManager.GetPoliciesForRoute(target.Path, req.Method))
+	if len(policies) == 0 {
+		response.Reason = "no_policies"
+		return
+	}
+	wallets := req.Context.Wallets
+	if wallets == nil {
+		wallets = pm.linkedWallets(targetRequest, subject, policies)
+	}
+
+	// Every policy is evaluated for the breakdown; the first to fail decides
+	response.Reason = "policies_passed"
+	for _, p := range policies {
+		ctx, reservations := policy.WithQuotaReservations(h.policyContext(targetRequest, &req, subject, wallets, p))
+		allowed, rules, err := p.Decide(ctx, subject.Address, subject)
+		pm.releaseQuota(r, reservations)
+
+		result := PolicyDecision{
+			Method:  p.Method,
+			Path:    p.Path,
+			Logic:   p.Logic,
+			Outcome: policy.EligibilityPass,
+			Rules:   make([]RuleDecisionResponse, len(rules)),
+		}
+		if p.Logic == "SCORE" {
+			result.Threshold = p.Threshold
+		}
+		if allowed && err == nil {
+			result.Tier = p.DecidedTier(rules)
+		}
+		for i, rule := range rules {
+			result.Rules[i] = ruleDecisionResponse(rule)
+			if explain {
+				requirement := describeRequirement(p.Rules[i])
+				result.Rules[i].Requirement = &requirement
+			}
+		}
+		switch {
+		case err != nil:
+			result.Outcome = "error"
+		case !allowed:
+			result.Outcome = policy.EligibilityFail
+		}
+		response.Policies = append(response.Policies, result)
+		if response.Tier == "" {
+			response.Tier = result.Tier
+		}
+
+		if result.Outcome != policy.EligibilityPass && response.Decision == DecisionAllow {
+			response.Decision = DecisionDeny
+			response.DeniedBy = describePolicy(p)
+			if explain {
+				response.DeniedBy.FailedRules = failedRules(p, rules, true)
+			}
+			response.Reason = "policy_failed"
+			if err != nil {
+				response.Reason = "evaluation_error"
+			}
+		}
+	}
+	if response.Decision == DecisionDeny {
+		response.Tier = ""
+	}
+}
+
+// policyContext returns the context a policy is evaluated with for the subject,
+// carrying an evaluation context built from the request body rather than from the
+// caller's own request
+func (h *AuthzHandler) policyContext(r *http.Request, req *AuthzCheckRequest, subject *auth.Claims, wallets []string, p *policy.Policy) context.Context {
+	ec := policy.NewEvaluationContext(r, req.Context.ClientIP, subject)
+	ec.Wallets = wallets
+	ec.Params = policy.RouteParams(p.Path, r.URL.Path)
+	if req.Context.ChainID != 0 {
+		ec.ChainID = req.Context.ChainID
+	}
+	if ec.ChainID == 0 {
+		ec.ChainID = h.middleware.chainID
+	}
+	return policy.WithEvaluationContext(policy.WithRequest(r.Context(), r), ec)
+}
+
+// claims returns the token claims of the subject: the custom claims of the
+// context, then its address and scopes
+func (req *AuthzCheckRequest) claims() (*auth.Claims, error) {
+	claims := &auth.Claims{}
+	if len(req.Context.Claims) > 0 {
+		data, err := json.Marshal(req.Context.Claims)
+		if err != nil {
+			return nil, errors.New("context.claims must be a JSON object")
+		}
+		if err := json.Unmarshal(data, claims); err != nil {
+			return nil, errors.New("context.claims must be a JSON object of token claims")
+		}
+	}
+	claims.Address = req.Address
+	claims.Scopes = req.Context.Scopes
+	return claims, nil
+}
+
+// ruleDecisionResponse describes the outcome of a rule
+func ruleDecisionResponse(rule policy.RuleDecision) RuleDecisionResponse {
+	response := RuleDecisionResponse{Type: string(rule.Type), Outcome: policy.EligibilityFail}
+	switch {
+	case rule.Err != nil:
+		response.Outcome = "error"
+		response.Error = rule.Err.Error()
+	case rule.Passed:
+		response.Outcome = policy.EligibilityPass
+	}
+	return response
+}
+
+// writeResponse writes a decision
+func (h *AuthzHandler) writeResponse(w http.ResponseWriter, response AuthzCheckResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeError writes an error response
+func (h *AuthzHandler) writeError(w http.ResponseWriter, error, details string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := ErrorResponse{
+		Error:   error,
+		Details: details,
+	}
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,188 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/policy"
+)
+
+// checkAuthz requests POST /api/authz/check with the given body as a service caller
+func checkAuthz(middleware *PolicyMiddleware, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/api/authz/check", bytes.NewBufferString(body))
+	req.Header.Set("Authorization", "Bearer service-key")
+	caller := &auth.Claims{Scopes: []string{ScopeAuthzCheck}}
+	req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, caller))
+	rec := httptest.NewRecorder()
+	NewAuthzHandler(middleware).Check(rec, req)
+	return rec
+}
+
+func TestAuthzHandler_Check(t *testing.T) {
+	const subject = "0x742d35cc6634c0532925a3b844bc9e7595f0beb1"
+	pm := policy.NewPolicyManager(&mockBlockchainProvider{}, nil)
+	pm.AddPolicy(policy.NewPolicy("GET", "/api/items/{id}", "AND", []policy.Rule{
+		policy.NewHasScopeRule("read"),
+		policy.NewQueryParamPresentRule("page"),
+	}))
+	pm.AddPolicy(policy.NewPolicy("POST", "/api/items/{id}", "OR", []policy.Rule{
+		policy.NewHasScopeRule("write"),
+		policy.NewERC20MinBalanceRule("0x1f9840a85d5af5bf1d1762f925bdaddc4201f984", big.NewInt(1000), 1),
+	}))
+	pm.AddPolicy(policy.NewPolicy("GET", "/api/quota", "AND", []policy.Rule{
+		policy.NewQuotaRule("checks", 1, time.Hour),
+	}))
+	pm.SetQuotaStore(policy.NewMemoryQuotaStore())
+	middleware := NewPolicyMiddleware(pm, nil, nil)
+
+	t.Run("allow with the outcome of every rule", func(t *testing.T) {
+		rec := checkAuthz(middleware, `{"address":"0x742D35CC6634C0532925A3B844BC9E7595F0BEB1","path":"/api/items/42?page=2","context":{"scopes":["read"]}}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var response AuthzCheckResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, DecisionAllow, response.Decision)
+		assert.Equal(t, "policies_passed", response.Reason)
+		assert.Equal(t, subject, response.Address)
+		assert.Equal(t, "GET", response.Method)
+		assert.Equal(t, "/api/items/42", response.Path)
+		require.Len(t, response.Policies, 1)
+		assert.Equal(t, []RuleDecisionResponse{
+			{Type: "has_scope", Outcome: "pass"},
+			{Type: "query_param_present", Outcome: "pass"},
+		}, response.Policies[0].Rules)
+	})
+
+	t.Run("deny names the failed policy", func(t *testing.T) {
+		rec := checkAuthz(middleware, `{"address":"`+subject+`","method":"post","path":"/api/items/42","context":{"scopes":["read"]}}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var response AuthzCheckResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, DecisionDeny, response.Decision)
+		assert.Equal(t, "policy_failed", response.Reason)
+		require.NotNil(t, response.DeniedBy)
+		assert.Equal(t, "/api/items/{id}", response.DeniedBy.Path)
+		require.Len(t, response.Policies, 1)
+		assert.Equal(t, "fail", response.Policies[0].Outcome)
+		assert.Equal(t, []RuleDecisionResponse{
+			{Type: "has_scope", Outcome: "fail"},
+			{Type: "erc20_min_balance", Outcome: "fail"},
+		}, response.Policies[0].Rules)
+	})
+
+	t.Run("routes without policies are allowed", func(t *testing.T) {
+		var response AuthzCheckResponse
+		rec := checkAuthz(middleware, `{"path":"/api/open"}`)
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, DecisionAllow, response.Decision)
+		assert.Equal(t, "no_policies", response.Reason)
+		assert.Empty(t, response.Policies)
+	})
+
+	t.Run("decisions consume no quota", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			var response AuthzCheckResponse
+			rec := checkAuthz(middleware, `{"address":"`+subject+`","path":"/api/quota"}`)
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			assert.Equal(t, DecisionAllow, response.Decision)
+		}
+	})
+
+	t.Run("blocked subjects are denied", func(t *testing.T) {
+		blocked := NewPolicyMiddleware(pm, nil, nil)
+		blocked.SetBlocklist(policy.NewMemoryBlocklist(subject))
+
+		var response AuthzCheckResponse
+		rec := checkAuthz(blocked, `{"address":"`+subject+`","path":"/api/items/42?page=2","context":{"scopes":["read"]}}`)
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, DecisionDeny, response.Decision)
+		assert.Equal(t, "address_blocked", response.Reason)
+	})
+
+	t.Run("invalid requests are rejected", func(t *testing.T) {
+		for _, body := range []string{
+			`not json`,
+			`{"path":""}`,
+			`{"path":"https://example.com/api/data"}`,
+			`{"address":"0x123","path":"/api/data"}`,
+			`{"path":"/api/data","context":{"claims":{"address":42}}}`,
+		} {
+			rec := checkAuthz(middleware, body)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+		}
+	})
+}
+
+func TestAuthzHandler_Explain(t *testing.T) {
+	pm := policy.NewPolicyManager(&mockBlockchainProvider{}, nil)
+	pm.AddPolicy(policy.NewScorePolicy("GET", "/api/premium", []policy.Rule{
+		policy.NewHasScopeRule("read"),
+		policy.NewERC20MinBalanceRule("0x1f9840a85d5af5bf1d1762f925bdaddc4201f984", big.NewInt(1000), 1),
+	}, []int{1, 2}, 2))
+	middleware := NewPolicyMiddleware(pm, nil, nil)
+
+	req := httptest.NewRequest("POST", "/api/admin/authz/explain", bytes.NewBufferString(
+		`{"address":"0x742d35cc6634c0532925a3b844bc9e7595f0beb1","path":"/api/premium","context":{"scopes":["read"]}}`))
+	admin := &auth.Claims{Address: "0x8ba1f109551bd432803012645ac136ddd64dba72"}
+	req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, admin))
+	rec := httptest.NewRecorder()
+	NewAuthzHandler(middleware).Explain(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var response AuthzCheckResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, DecisionDeny, response.Decision)
+	require.Len(t, response.Policies, 1)
+	rules := response.Policies[0].Rules
+	require.Len(t, rules, 2)
+	assert.Equal(t, "pass", rules[0].Outcome)
+	require.NotNil(t, rules[0].Requirement)
+	assert.Equal(t, "read", rules[0].Requirement.Scope)
+
+	// The denying policy names what the subject is missing, thresholds included
+	require.NotNil(t, response.DeniedBy)
+	assert.Equal(t, 2, response.DeniedBy.Threshold)
+	require.Len(t, response.DeniedBy.FailedRules, 1)
+	missing := response.DeniedBy.FailedRules[0]
+	assert.Equal(t, "erc20_min_balance", missing.Type)
+	assert.Equal(t, "1000", missing.MinimumBalance)
+	assert.Equal(t, 2, missing.Points)
+}
+
+func TestAuthzHandler_Check_Tier(t *testing.T) {
+	pm := policy.NewPolicyManager(nil, nil)
+	pm.AddPolicy(policy.NewTierPolicy("GET", "/api/data", []policy.Rule{
+		policy.NewHasScopeRule("gold"),
+		policy.NewHasScopeRule("silver"),
+	}, []string{"gold", "silver"}, ""))
+	middleware := NewPolicyMiddleware(pm, nil, nil)
+
+	for scopes, tier := range map[string]string{`["gold","silver"]`: "gold", `["silver"]`: "silver"} {
+		rec := checkAuthz(middleware, `{"address":"0x742d35cc6634c0532925a3b844bc9e7595f0beb1","path":"/api/data","context":{"scopes":`+scopes+`}}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var response AuthzCheckResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, DecisionAllow, response.Decision, scopes)
+		assert.Equal(t, tier, response.Tier, scopes)
+		require.Len(t, response.Policies, 1)
+		assert.Equal(t, tier, response.Policies[0].Tier, scopes)
+	}
+
+	// Without a default tier, callers passing no rule are denied
+	rec := checkAuthz(middleware, `{"address":"0x742d35cc6634c0532925a3b844bc9e7595f0beb1","path":"/api/data","context":{"scopes":["read"]}}`)
+	var response AuthzCheckResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, DecisionDeny, response.Decision)
+	assert.Empty(t, response.Tier)
+}
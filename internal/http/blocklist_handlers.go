@@ -0,0 +1,186 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/gatekeeper/internal/audit"
+	"github.com/yourusername/gatekeeper/internal/log"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// BlocklistHandler handles the global blocklist of addresses denied on every
+// gated route
+type BlocklistHandler struct {
+	blocklistRepo store.BlocklistRepositoryInterface
+	logger        log.Logger
+	auditLogger   audit.AuditLogger
+}
+
+// NewBlocklistHandler creates a new blocklist handler
+func NewBlocklistHandler(blocklistRepo store.BlocklistRepositoryInterface, logger log.Logger, auditLogger audit.AuditLogger) *BlocklistHandler {
+	return &BlocklistHandler{
+		blocklistRepo: blocklistRepo,
+		logger:        logger,
+		auditLogger:   auditLogger,
+	}
+}
+
+// BlockAddressRequest blocks an address
+type BlockAddressRequest struct {
+	Address string `json:"address"`
+	Reason  string `json:"reason"`
+}
+
+// BlockedAddressResponse describes a blocked address
+type BlockedAddressResponse struct {
+	Address   string    `json:"address"`
+	Reason    string    `json:"reason"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ListBlockedAddressesResponse lists the blocklist
+type ListBlockedAddressesResponse struct {
+	Addresses []BlockedAddressResponse `json:"addresses"`
+}
+
+// ListBlockedAddresses handles GET /api/admin/blocklist - List blocked addresses,
+// most recently blocked first
+func (h *BlocklistHandler) ListBlockedAddresses(w http.ResponseWriter, r *http.Request) {
+	logger := requestLogger(r, h.logger)
+
+	blocked, err := h.blocklistRepo.ListBlockedAddresses(r.Context())
+	if err != nil {
+		logger.Error("failed to list blocked addresses", log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to list blocked addresses", http.StatusInternalServerError)
+		return
+	}
+
+	response := ListBlockedAddressesResponse{Addresses: make([]BlockedAddressResponse, 0, len(blocked))}
+	for _, b := range blocked {
+		response.Addresses = append(response.Addresses, newBlockedAddressResponse(&b))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// BlockAddress handles POST /api/admin/blocklist - Block an address on every gated
+// route, even if it passes the route's policies
+func (h *BlocklistHandler) BlockAddress(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := requestLogger(r, h.logger)
+
+	var req BlockAddressRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, "Invalid request body", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	actor := h.actor(r)
+	blocked, err := h.blocklistRepo.BlockAddress(store.WithActor(ctx, actor), req.Address, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrInvalidAddress):
+			h.writeError(w, "Validation failed", "address must be an Ethereum address", http.StatusBadRequest)
+		case errors.Is(err, store.ErrDuplicate):
+			h.writeError(w, "Conflict", "Address is already blocked", http.StatusConflict)
+		default:
+			logger.Error("failed to block address", log.Error(err))
+			h.writeError(w, "Internal server error", "Failed to block address", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	logger.Info("address blocked",
+		log.String("address", blocked.Address),
+		log.String("reason", blocked.Reason),
+	)
+	h.audit(r, audit.ActionAddressBlocked, actor, map[string]interface{}{
+		"address": blocked.Address,
+		"reason":  blocked.Reason,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(newBlockedAddressResponse(blocked))
+}
+
+// UnblockAddress handles DELETE /api/admin/blocklist/{address} - Remove an address
+// from the blocklist
+func (h *BlocklistHandler) UnblockAddress(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := requestLogger(r, h.logger)
+	address := mux.Vars(r)["address"]
+
+	actor := h.actor(r)
+	if err := h.blocklistRepo.UnblockAddress(store.WithActor(ctx, actor), address); err != nil {
+		switch {
+		case errors.Is(err, store.ErrInvalidAddress):
+			h.writeError(w, "Validation failed", "address must be an Ethereum address", http.StatusBadRequest)
+		case errors.Is(err, store.ErrNotFound):
+			h.writeError(w, "Not found", "Address is not blocked", http.StatusNotFound)
+		default:
+			logger.Error("failed to unblock address", log.Error(err))
+			h.writeError(w, "Internal server error", "Failed to unblock address", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	logger.Info("address unblocked", log.String("address", address))
+	h.audit(r, audit.ActionAddressUnblocked, actor, map[string]interface{}{
+		"address": address,
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// actor returns the identity of the admin making a change
+func (h *BlocklistHandler) actor(r *http.Request) string {
+	if claims := ClaimsFromContext(r); claims != nil {
+		return claims.Identity()
+	}
+	return ""
+}
+
+// audit records a blocklist change in the audit log
+func (h *BlocklistHandler) audit(r *http.Request, action audit.ActionType, actor string, metadata map[string]interface{}) {
+	if h.auditLogger == nil {
+		return
+	}
+	h.auditLogger.Log(r.Context(), withClientInfo(r, audit.AuditEvent{
+		Action:   action,
+		Result:   audit.ResultSuccess,
+		UserAddr: actor,
+		Method:   r.Method,
+		Endpoint: r.URL.Path,
+		Metadata: metadata,
+	}))
+}
+
+// newBlockedAddressResponse describes a blocked address
+func newBlockedAddressResponse(blocked *store.BlockedAddress) BlockedAddressResponse {
+	return BlockedAddressResponse{
+		Address:   blocked.Address,
+		Reason:    blocked.Reason,
+		CreatedBy: blocked.CreatedBy,
+		CreatedAt: blocked.CreatedAt,
+	}
+}
+
+// writeError writes an error response
+func (h *BlocklistHandler) writeError(w http.ResponseWriter, error, details string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := ErrorResponse{
+		Error:   error,
+		Details: details,
+	}
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,122 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// MockBlocklistRepository is a mock implementation of BlocklistRepository
+type MockBlocklistRepository struct {
+	mock.Mock
+}
+
+func (m *MockBlocklistRepository) BlockAddress(ctx context.Context, address, reason string) (*store.BlockedAddress, error) {
+	args := m.Called(ctx, address, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.BlockedAddress), args.Error(1)
+}
+
+func (m *MockBlocklistRepository) UnblockAddress(ctx context.Context, address string) error {
+	args := m.Called(ctx, address)
+	return args.Error(0)
+}
+
+func (m *MockBlocklistRepository) ListBlockedAddresses(ctx context.Context) ([]store.BlockedAddress, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]store.BlockedAddress), args.Error(1)
+}
+
+func (m *MockBlocklistRepository) FindBlockedAddresses(ctx context.Context, addresses []string) ([]string, error) {
+	args := m.Called(ctx, addresses)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+const blockedAddress = "0x1234567890abcdef1234567890abcdef12345678"
+
+func TestListBlockedAddresses(t *testing.T) {
+	repo := new(MockBlocklistRepository)
+	handler := NewBlocklistHandler(repo, nil, nil)
+	repo.On("ListBlockedAddresses", mock.Anything).Return([]store.BlockedAddress{
+		{ID: 1, Address: blockedAddress, Reason: "sanctioned", CreatedBy: operatorAddress, CreatedAt: time.Now()},
+	}, nil)
+
+	rec := httptest.NewRecorder()
+	handler.ListBlockedAddresses(rec, httptest.NewRequest("GET", "/api/admin/blocklist", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response ListBlockedAddressesResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Len(t, response.Addresses, 1)
+	assert.Equal(t, "sanctioned", response.Addresses[0].Reason)
+}
+
+func TestBlockAddress(t *testing.T) {
+	repo := new(MockBlocklistRepository)
+	handler := NewBlocklistHandler(repo, nil, nil)
+	repo.On("BlockAddress", mock.Anything, blockedAddress, "compromised").
+		Return(&store.BlockedAddress{ID: 1, Address: blockedAddress, Reason: "compromised", CreatedAt: time.Now()}, nil)
+
+	req := httptest.NewRequest("POST", "/api/admin/blocklist", strings.NewReader(`{"address": "`+blockedAddress+`", "reason": "compromised"}`))
+	rec := httptest.NewRecorder()
+	handler.BlockAddress(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	repo.AssertExpectations(t)
+}
+
+func TestBlockAddress_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"invalid address", &store.InvalidAddressError{Address: "nope", Reason: "invalid"}, http.StatusBadRequest},
+		{"already blocked", &store.DuplicateError{Resource: "blocked_address", Field: "address", Value: blockedAddress}, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockBlocklistRepository)
+			handler := NewBlocklistHandler(repo, nil, nil)
+			repo.On("BlockAddress", mock.Anything, mock.Anything, mock.Anything).Return(nil, tt.err)
+
+			req := httptest.NewRequest("POST", "/api/admin/blocklist", strings.NewReader(`{"address": "nope"}`))
+			rec := httptest.NewRecorder()
+			handler.BlockAddress(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestUnblockAddress(t *testing.T) {
+	repo := new(MockBlocklistRepository)
+	handler := NewBlocklistHandler(repo, nil, nil)
+	repo.On("UnblockAddress", mock.Anything, blockedAddress).Return(nil).Once()
+	repo.On("UnblockAddress", mock.Anything, blockedAddress).Return(&store.NotFoundError{Resource: "blocked_address", ID: blockedAddress})
+
+	for _, wantStatus := range []int{http.StatusNoContent, http.StatusNotFound} {
+		req := mux.SetURLVars(httptest.NewRequest("DELETE", "/api/admin/blocklist/"+blockedAddress, nil), map[string]string{"address": blockedAddress})
+		rec := httptest.NewRecorder()
+		handler.UnblockAddress(rec, req)
+		assert.Equal(t, wantStatus, rec.Code)
+	}
+}
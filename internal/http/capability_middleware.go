@@ -0,0 +1,79 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/yourusername/gatekeeper/internal/auth"
+)
+
+// CapabilityMiddleware creates a middleware that authenticates requests carrying a
+// capability token for exactly the requested method and path. Requests without a
+// capability token pass through unchanged, so it is placed ahead of the API key and
+// JWT middlewares; a capability token presented to any other endpoint is rejected.
+func CapabilityMiddleware(jwtService *auth.JWTService) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := parseBearerToken(r.Header.Get("Authorization"))
+			if !ok || AuthMethodFromContext(r) == AuthMethodSignedURL {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			claims, err := jwtService.VerifyCapabilityToken(r.Context(), token, r.Method, r.URL.Path)
+			if errors.Is(err, auth.ErrCapabilityMismatch) {
+				http.Error(w, "capability token is not valid for this endpoint", http.StatusForbidden)
+				return
+			}
+			if err != nil {
+				// Session tokens, and tokens that fail verification, are left to the JWT middleware
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Capability tokens die with the session they were derived from
+			revoked, err := jwtService.IsRevoked(r.Context(), claims)
+			if err != nil {
+				http.Error(w, "unable to check token revocation", http.StatusServiceUnavailable)
+				return
+			}
+			if revoked {
+				http.Error(w, "token has been revoked", http.StatusUnauthorized)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), ClaimsContextKey, claims)
+			ctx = context.WithValue(ctx, AuthMethodContextKey, AuthMethodCapability)
+			ctx = withAuthenticatedLogger(ctx, claims)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RejectDerivedTokens creates a middleware refusing downscoped and capability
+// tokens on routes under the given path prefixes, such as account and credential
+// management: tokens handed to third parties must not mint credentials or change
+// the account they were derived from. Sessions and API keys pass through.
+func RejectDerivedTokens(prefixes ...string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if claims := ClaimsFromContext(r); claims != nil && claims.Derived() && longestPathPrefix(r.URL.Path, prefixes) != "" {
+				writeSessionRequired(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// writeSessionRequired sends a 403 problem response refusing a derived token
+func writeSessionRequired(w http.ResponseWriter, r *http.Request) {
+	writeProblem(w, Problem{
+		Type:     ProblemTypeSessionRequired,
+		Title:    "Session required",
+		Status:   http.StatusForbidden,
+		Detail:   "Downscoped and capability tokens cannot be used here; sign in for a session",
+		Instance: r.URL.Path,
+	})
+}
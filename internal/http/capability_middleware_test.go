@@ -0,0 +1,144 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/auth"
+)
+
+// TestCapabilityMiddleware verifies capability tokens authenticate only their endpoint
+// and that other requests fall through to the JWT middleware
+func TestCapabilityMiddleware(t *testing.T) {
+	jwtService := auth.NewJWTService([]byte("test-secret-key-at-least-32-chars"), 24*time.Hour)
+	ctx := context.Background()
+	address := "0x742d35Cc6634C0532925a3b844Bc390e38f3dF8c"
+
+	sessionToken, err := jwtService.GenerateToken(ctx, address, []string{"read"})
+	require.NoError(t, err)
+	parent, err := jwtService.VerifyToken(ctx, sessionToken)
+	require.NoError(t, err)
+	capabilityToken, _, err := jwtService.GenerateCapabilityToken(ctx, parent, "POST", "/api/claim", time.Minute)
+	require.NoError(t, err)
+
+	var authMethod string
+	chain := CapabilityMiddleware(jwtService)(JWTMiddleware(jwtService)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authMethod = AuthMethodFromContext(r)
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		token          string
+		expectedStatus int
+		expectedMethod string
+	}{
+		{name: "capability for its endpoint", method: "POST", path: "/api/claim", token: capabilityToken, expectedStatus: http.StatusOK, expectedMethod: AuthMethodCapability},
+		{name: "capability for other method", method: "GET", path: "/api/claim", token: capabilityToken, expectedStatus: http.StatusForbidden},
+		{name: "capability for other path", method: "POST", path: "/api/keys", token: capabilityToken, expectedStatus: http.StatusForbidden},
+		{name: "session token", method: "POST", path: "/api/claim", token: sessionToken, expectedStatus: http.StatusOK, expectedMethod: AuthMethodJWT},
+		{name: "invalid token", method: "POST", path: "/api/claim", token: "invalid", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authMethod = ""
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+
+			chain.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedMethod, authMethod)
+		})
+	}
+}
+
+// TestCapabilityMiddleware_RevokedWithSession rejects capability tokens once the
+// session they were derived from is logged out
+func TestCapabilityMiddleware_RevokedWithSession(t *testing.T) {
+	jwtService := auth.NewJWTService([]byte("test-secret-key-at-least-32-chars"), 24*time.Hour)
+	jwtService.SetRevocationList(auth.NewMemoryRevocationList())
+	ctx := context.Background()
+
+	sessionToken, err := jwtService.GenerateToken(ctx, "0x742d35Cc6634C0532925a3b844Bc390e38f3dF8c", nil)
+	require.NoError(t, err)
+	session, err := jwtService.VerifyToken(ctx, sessionToken)
+	require.NoError(t, err)
+	capabilityToken, _, err := jwtService.GenerateCapabilityToken(ctx, session, "POST", "/api/claim", time.Minute)
+	require.NoError(t, err)
+
+	chain := CapabilityMiddleware(jwtService)(JWTMiddleware(jwtService)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+	serve := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/claim", nil)
+		req.Header.Set("Authorization", "Bearer "+capabilityToken)
+		rec := httptest.NewRecorder()
+		chain.ServeHTTP(rec, req)
+		return rec
+	}
+	assert.Equal(t, http.StatusOK, serve().Code)
+
+	require.NoError(t, jwtService.RevokeToken(ctx, session))
+	rec := serve()
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "revoked")
+
+	jwtService.SetRevocationList(failingRevocationList{})
+	assert.Equal(t, http.StatusServiceUnavailable, serve().Code)
+}
+
+// TestRejectDerivedTokens refuses downscoped tokens on account and credential
+// routes, and lets sessions and API keys through
+func TestRejectDerivedTokens(t *testing.T) {
+	jwtService := auth.NewJWTService([]byte("test-secret-key-at-least-32-chars"), 24*time.Hour)
+	ctx := context.Background()
+	address := "0x742d35Cc6634C0532925a3b844Bc390e38f3dF8c"
+
+	sessionToken, err := jwtService.GenerateToken(ctx, address, []string{"read", "keys:*"})
+	require.NoError(t, err)
+	parent, err := jwtService.VerifyToken(ctx, sessionToken)
+	require.NoError(t, err)
+	downscopedToken, _, err := jwtService.DownscopeToken(ctx, parent, []string{"keys:write"}, time.Minute)
+	require.NoError(t, err)
+
+	handler := JWTMiddleware(jwtService)(RejectDerivedTokens("/api/keys", "/api/me", "/api/signed-urls", "/api/service-accounts")(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})))
+
+	for _, path := range []string{"/api/keys", "/api/keys/7", "/api/me/wallets/link", "/api/signed-urls", "/api/service-accounts/3/keys"} {
+		for token, status := range map[string]int{sessionToken: http.StatusOK, downscopedToken: http.StatusForbidden} {
+			req := httptest.NewRequest("POST", path, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, status, rec.Code, path)
+		}
+	}
+
+	// Other routes accept downscoped tokens
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.Header.Set("Authorization", "Bearer "+downscopedToken)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// API keys carry no parent
+	req = httptest.NewRequest("POST", "/api/keys", nil)
+	req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, &auth.Claims{Address: address}))
+	rec = httptest.NewRecorder()
+	RejectDerivedTokens("/api/keys")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
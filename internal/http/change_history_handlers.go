@@ -0,0 +1,141 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/gatekeeper/internal/log"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// Change history page sizes
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 500
+)
+
+// ChangeHistoryHandler handles review of the policy and allowlist change history
+type ChangeHistoryHandler struct {
+	historyRepo store.ChangeHistoryRepositoryInterface
+	logger      log.Logger
+}
+
+// NewChangeHistoryHandler creates a new change history handler
+func NewChangeHistoryHandler(historyRepo store.ChangeHistoryRepositoryInterface, logger log.Logger) *ChangeHistoryHandler {
+	return &ChangeHistoryHandler{
+		historyRepo: historyRepo,
+		logger:      logger,
+	}
+}
+
+// ChangeResponse represents one entry of the change history
+type ChangeResponse struct {
+	ID           int64           `json:"id"`
+	ResourceType string          `json:"resourceType"`
+	ResourceID   string          `json:"resourceId"`
+	Action       string          `json:"action"`
+	Actor        string          `json:"actor"`
+	Before       json.RawMessage `json:"before"` // null for creations
+	After        json.RawMessage `json:"after"`  // null for deletions
+	CreatedAt    time.Time       `json:"createdAt"`
+}
+
+// ListChangesResponse represents a page of the change history
+type ListChangesResponse struct {
+	Changes []ChangeResponse `json:"changes"`
+	// NextBefore is passed as ?before= to fetch the next page; omitted on the last page
+	NextBefore int64 `json:"nextBefore,omitempty"`
+}
+
+// ListChanges handles GET /api/admin/history - Changes to policies, allowlists and
+// the blocklist, newest first. Optional query parameters: resource (allowlist,
+// policy or blocklist), id (the allowlist ID, "METHOD path" of a policy route or
+// blocked address, requires resource), limit
+// (default 50, max 500) and before (an entry ID, for paging).
+func (h *ChangeHistoryHandler) ListChanges(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := requestLogger(r, h.logger)
+	query := r.URL.Query()
+
+	filter := store.ChangeFilter{
+		ResourceType: query.Get("resource"),
+		ResourceID:   query.Get("id"),
+		Limit:        defaultHistoryLimit,
+	}
+	switch filter.ResourceType {
+	case "", store.ResourceAllowlist, store.ResourcePolicy, store.ResourceBlocklist:
+	default:
+		h.writeError(w, "Invalid request", "resource must be allowlist, policy or blocklist", http.StatusBadRequest)
+		return
+	}
+	if filter.ResourceID != "" && filter.ResourceType == "" {
+		h.writeError(w, "Invalid request", "id requires resource", http.StatusBadRequest)
+		return
+	}
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 || parsed > maxHistoryLimit {
+			h.writeError(w, "Invalid limit", "limit must be between 1 and 500", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = parsed
+	}
+	if raw := query.Get("before"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed <= 0 {
+			h.writeError(w, "Invalid request", "before must be a positive entry ID", http.StatusBadRequest)
+			return
+		}
+		filter.BeforeID = parsed
+	}
+
+	changes, err := h.historyRepo.ListChanges(ctx, filter)
+	if err != nil {
+		logger.Error("failed to list changes", log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to retrieve change history", http.StatusInternalServerError)
+		return
+	}
+
+	response := ListChangesResponse{Changes: make([]ChangeResponse, len(changes))}
+	for i, change := range changes {
+		response.Changes[i] = ChangeResponse{
+			ID:           change.ID,
+			ResourceType: change.ResourceType,
+			ResourceID:   change.ResourceID,
+			Action:       change.Action,
+			Actor:        change.Actor,
+			Before:       rawJSON(change.Before),
+			After:        rawJSON(change.After),
+			CreatedAt:    change.CreatedAt,
+		}
+	}
+	if len(changes) == filter.Limit {
+		response.NextBefore = changes[len(changes)-1].ID
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// rawJSON returns data as a raw JSON value, encoding nil as null
+func rawJSON(data []byte) json.RawMessage {
+	if len(data) == 0 {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(data)
+}
+
+// writeError writes an error response
+func (h *ChangeHistoryHandler) writeError(w http.ResponseWriter, error, details string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := ErrorResponse{
+		Error:   error,
+		Details: details,
+	}
+	json.NewEncoder(w).Encode(response)
+}
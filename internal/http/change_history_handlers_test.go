@@ -0,0 +1,119 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// MockChangeHistoryRepository is a mock implementation of ChangeHistoryRepository
+type MockChangeHistoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockChangeHistoryRepository) RecordPolicyChange(ctx context.Context, route, action string, before, after []byte) error {
+	args := m.Called(ctx, route, action, before, after)
+	return args.Error(0)
+}
+
+func (m *MockChangeHistoryRepository) ListChanges(ctx context.Context, filter store.ChangeFilter) ([]store.Change, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]store.Change), args.Error(1)
+}
+
+func TestListChanges_ReturnsHistory(t *testing.T) {
+	repo := new(MockChangeHistoryRepository)
+	handler := NewChangeHistoryHandler(repo, nil)
+
+	changedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	repo.On("ListChanges", mock.Anything, store.ChangeFilter{
+		ResourceType: store.ResourceAllowlist,
+		ResourceID:   "7",
+		Limit:        50,
+	}).Return([]store.Change{
+		{ID: 12, ResourceType: "allowlist", ResourceID: "7", Action: "updated", Actor: "0xabc",
+			Before: []byte(`{"name":"Old"}`), After: []byte(`{"name":"New"}`), CreatedAt: changedAt},
+		{ID: 9, ResourceType: "allowlist", ResourceID: "7", Action: "created", Actor: "system",
+			After: []byte(`{"name":"Old"}`), CreatedAt: changedAt},
+	}, nil)
+
+	rec := httptest.NewRecorder()
+	handler.ListChanges(rec, httptest.NewRequest("GET", "/api/admin/history?resource=allowlist&id=7", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"changes":[
+		{"id":12,"resourceType":"allowlist","resourceId":"7","action":"updated","actor":"0xabc",
+		 "before":{"name":"Old"},"after":{"name":"New"},"createdAt":"2024-05-01T12:00:00Z"},
+		{"id":9,"resourceType":"allowlist","resourceId":"7","action":"created","actor":"system",
+		 "before":null,"after":{"name":"Old"},"createdAt":"2024-05-01T12:00:00Z"}
+	]}`, rec.Body.String())
+	repo.AssertExpectations(t)
+}
+
+func TestListChanges_FullPageReturnsCursor(t *testing.T) {
+	repo := new(MockChangeHistoryRepository)
+	handler := NewChangeHistoryHandler(repo, nil)
+
+	repo.On("ListChanges", mock.Anything, store.ChangeFilter{BeforeID: 30, Limit: 2}).Return([]store.Change{
+		{ID: 29, ResourceType: "policy", ResourceID: "GET /api/data", Action: "deleted", Actor: "system", Before: []byte(`[]`)},
+		{ID: 25, ResourceType: "policy", ResourceID: "GET /api/data", Action: "created", Actor: "system", After: []byte(`[]`)},
+	}, nil)
+
+	rec := httptest.NewRecorder()
+	handler.ListChanges(rec, httptest.NewRequest("GET", "/api/admin/history?limit=2&before=30", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response ListChangesResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	assert.Len(t, response.Changes, 2)
+	assert.Equal(t, int64(25), response.NextBefore)
+}
+
+func TestListChanges_InvalidQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"unknown resource", "?resource=user"},
+		{"id without resource", "?id=7"},
+		{"limit too large", "?limit=501"},
+		{"limit not a number", "?limit=all"},
+		{"negative before", "?before=-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockChangeHistoryRepository)
+			handler := NewChangeHistoryHandler(repo, nil)
+
+			rec := httptest.NewRecorder()
+			handler.ListChanges(rec, httptest.NewRequest("GET", "/api/admin/history"+tt.query, nil))
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			repo.AssertNotCalled(t, "ListChanges", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestListChanges_RepositoryError(t *testing.T) {
+	repo := new(MockChangeHistoryRepository)
+	handler := NewChangeHistoryHandler(repo, nil)
+	repo.On("ListChanges", mock.Anything, mock.Anything).Return(nil, errors.New("connection refused"))
+
+	rec := httptest.NewRecorder()
+	handler.ListChanges(rec, httptest.NewRequest("GET", "/api/admin/history", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
@@ -0,0 +1,132 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/yourusername/gatekeeper/internal/audit"
+)
+
+// maxFingerprintFieldLength caps client-controlled header values copied into audit events
+const maxFingerprintFieldLength = 512
+
+const (
+	tlsFingerprintContextKey contextKey = "tls_fingerprint"
+	peerAddrContextKey       contextKey = "peer_addr"
+)
+
+// WithPeerAddr returns a copy of ctx recording the address of the peer that opened
+// the connection, for listeners reporting another client address (PROXY protocol).
+// Meant for http.Server.ConnContext.
+func WithPeerAddr(ctx context.Context, addr net.Addr) context.Context {
+	return context.WithValue(ctx, peerAddrContextKey, addr)
+}
+
+// peerIP returns the IP of the peer the request arrived from: the one recorded by
+// WithPeerAddr, or else the request's remote address
+func peerIP(r *http.Request) net.IP {
+	if addr, ok := r.Context().Value(peerAddrContextKey).(net.Addr); ok {
+		if tcpAddr, ok := addr.(*net.TCPAddr); ok {
+			return tcpAddr.IP
+		}
+		return nil
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return nil
+	}
+	return net.ParseIP(host)
+}
+
+// TLSFingerprintMiddleware creates a middleware taking the client's JA3 fingerprint
+// from header for the audit events of the request. TLS is terminated upstream, so the
+// fingerprint is only available when the proxy computes it and forwards it; since any
+// other client could set the header, it is only read from peers within trustedProxies.
+// An empty header or no trusted proxies disable fingerprint capture.
+func TLSFingerprintMiddleware(header string, trustedProxies []string) (Middleware, error) {
+	trusted := make([]*net.IPNet, 0, len(trustedProxies))
+	for _, cidr := range trustedProxies {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy CIDR %q: %w", cidr, err)
+		}
+		trusted = append(trusted, network)
+	}
+
+	return func(next http.Handler) http.Handler {
+		if header == "" || len(trusted) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fingerprint := r.Header.Get(header)
+			if fingerprint == "" || !fromTrustedProxy(r, trusted) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx := context.WithValue(r.Context(), tlsFingerprintContextKey, truncateHeader(fingerprint))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}, nil
+}
+
+// fromTrustedProxy reports whether the request's peer is within one of the trusted networks
+func fromTrustedProxy(r *http.Request, trusted []*net.IPNet) bool {
+	ip := peerIP(r)
+	if ip == nil {
+		return false
+	}
+	for _, network := range trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientInfo holds identifying attributes of the calling client for forensics
+type ClientInfo struct {
+	IPAddr         string
+	UserAgent      string
+	AcceptLanguage string
+	TLSFingerprint string
+}
+
+// ClientInfoFromRequest extracts client fingerprint attributes from the request
+func ClientInfoFromRequest(r *http.Request) ClientInfo {
+	info := ClientInfo{
+		IPAddr:         r.RemoteAddr,
+		UserAgent:      truncateHeader(r.UserAgent()),
+		AcceptLanguage: truncateHeader(r.Header.Get("Accept-Language")),
+	}
+	info.TLSFingerprint, _ = r.Context().Value(tlsFingerprintContextKey).(string)
+	return info
+}
+
+// withClientInfo fills the client fingerprint fields of an audit event from the request.
+// Fields already set on the event are left untouched.
+func withClientInfo(r *http.Request, event audit.AuditEvent) audit.AuditEvent {
+	info := ClientInfoFromRequest(r)
+	if event.IPAddr == "" {
+		event.IPAddr = info.IPAddr
+	}
+	if event.UserAgent == "" {
+		event.UserAgent = info.UserAgent
+	}
+	if event.AcceptLanguage == "" {
+		event.AcceptLanguage = info.AcceptLanguage
+	}
+	if event.TLSFingerprint == "" {
+		event.TLSFingerprint = info.TLSFingerprint
+	}
+	return event
+}
+
+// truncateHeader limits the length of a client-supplied header value
+func truncateHeader(value string) string {
+	if len(value) > maxFingerprintFieldLength {
+		return value[:maxFingerprintFieldLength]
+	}
+	return value
+}
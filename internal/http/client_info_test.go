@@ -0,0 +1,92 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/audit"
+)
+
+func TestClientInfoFromRequest(t *testing.T) {
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.RemoteAddr = "10.0.0.1:5555"
+	req.Header.Set("User-Agent", "curl/8.4.0")
+	req.Header.Set("Accept-Language", "de-DE")
+	req.Header.Set("X-JA3-Fingerprint", "e7d705a3286e19ea42f587b344ee6865")
+
+	info := captureClientInfo(t, "X-JA3-Fingerprint", []string{"10.0.0.0/8"}, req)
+
+	assert.Equal(t, "10.0.0.1:5555", info.IPAddr)
+	assert.Equal(t, "curl/8.4.0", info.UserAgent)
+	assert.Equal(t, "de-DE", info.AcceptLanguage)
+	assert.Equal(t, "e7d705a3286e19ea42f587b344ee6865", info.TLSFingerprint)
+}
+
+// TestTLSFingerprintMiddleware_OnlyTrustedProxies ignores fingerprints set by other peers
+func TestTLSFingerprintMiddleware_OnlyTrustedProxies(t *testing.T) {
+	newRequest := func(remoteAddr string) *http.Request {
+		req := httptest.NewRequest("GET", "/api/data", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("CF-JA3", "forwarded")
+		return req
+	}
+
+	assert.Equal(t, "forwarded", captureClientInfo(t, "CF-JA3", []string{"10.0.0.0/8"}, newRequest("10.1.2.3:5555")).TLSFingerprint)
+	assert.Empty(t, captureClientInfo(t, "CF-JA3", []string{"10.0.0.0/8"}, newRequest("203.0.113.9:5555")).TLSFingerprint)
+	assert.Empty(t, captureClientInfo(t, "", []string{"10.0.0.0/8"}, newRequest("10.1.2.3:5555")).TLSFingerprint)
+	assert.Empty(t, captureClientInfo(t, "CF-JA3", nil, newRequest("10.1.2.3:5555")).TLSFingerprint)
+
+	// Behind PROXY protocol the remote address is the client's; the peer is the proxy
+	proxied := newRequest("203.0.113.9:5555")
+	proxied = proxied.WithContext(WithPeerAddr(proxied.Context(), &net.TCPAddr{IP: net.ParseIP("10.1.2.3"), Port: 40000}))
+	assert.Equal(t, "forwarded", captureClientInfo(t, "CF-JA3", []string{"10.0.0.0/8"}, proxied).TLSFingerprint)
+
+	direct := newRequest("10.1.2.3:5555")
+	direct = direct.WithContext(WithPeerAddr(direct.Context(), &net.TCPAddr{IP: net.ParseIP("203.0.113.9"), Port: 40000}))
+	assert.Empty(t, captureClientInfo(t, "CF-JA3", []string{"10.0.0.0/8"}, direct).TLSFingerprint)
+}
+
+func TestTLSFingerprintMiddleware_InvalidCIDR(t *testing.T) {
+	_, err := TLSFingerprintMiddleware("X-JA3-Fingerprint", []string{"10.0.0.0"})
+
+	assert.Error(t, err)
+}
+
+// captureClientInfo serves req through TLSFingerprintMiddleware and returns the
+// client info the handler sees
+func captureClientInfo(t *testing.T, header string, trustedProxies []string, req *http.Request) ClientInfo {
+	t.Helper()
+	middleware, err := TLSFingerprintMiddleware(header, trustedProxies)
+	require.NoError(t, err)
+
+	var info ClientInfo
+	middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info = ClientInfoFromRequest(r)
+	})).ServeHTTP(httptest.NewRecorder(), req)
+	return info
+}
+
+func TestClientInfoFromRequest_TruncatesLongValues(t *testing.T) {
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.Header.Set("User-Agent", strings.Repeat("a", 4096))
+
+	info := ClientInfoFromRequest(req)
+
+	assert.Len(t, info.UserAgent, maxFingerprintFieldLength)
+}
+
+func TestWithClientInfo_PreservesExistingFields(t *testing.T) {
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.RemoteAddr = "10.0.0.1:5555"
+	req.Header.Set("User-Agent", "curl/8.4.0")
+
+	event := withClientInfo(req, audit.AuditEvent{IPAddr: "203.0.113.9"})
+
+	assert.Equal(t, "203.0.113.9", event.IPAddr)
+	assert.Equal(t, "curl/8.4.0", event.UserAgent)
+}
@@ -0,0 +1,120 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/yourusername/gatekeeper/internal/log"
+)
+
+// APIKeyIDContextKey is the key used to store the ID of the API key a request
+// was authenticated with
+const APIKeyIDContextKey contextKey = "api_key_id"
+
+// ConcurrencyLimiter caps the requests in flight per identifier with a counting
+// semaphore each. Unlike rate limits, it bounds requests that are slow to send
+// or to answer (slowloris, long polls) rather than how often they arrive.
+type ConcurrencyLimiter struct {
+	mu       sync.Mutex
+	inFlight map[string]int // Identifiers without requests in flight are removed
+	limit    int
+}
+
+// NewConcurrencyLimiter creates a limiter allowing limit requests in flight per identifier
+func NewConcurrencyLimiter(limit int) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{
+		inFlight: make(map[string]int),
+		limit:    limit,
+	}
+}
+
+// Acquire takes a slot for the identifier, returning false if all are taken.
+// Every successful Acquire must be followed by Release.
+func (l *ConcurrencyLimiter) Acquire(identifier string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight[identifier] >= l.limit {
+		return false
+	}
+	l.inFlight[identifier]++
+	return true
+}
+
+// Release frees a slot taken by Acquire
+func (l *ConcurrencyLimiter) Release(identifier string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight[identifier] <= 1 {
+		delete(l.inFlight, identifier)
+		return
+	}
+	l.inFlight[identifier]--
+}
+
+// InFlight returns the requests in flight for the identifier
+func (l *ConcurrencyLimiter) InFlight(identifier string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inFlight[identifier]
+}
+
+// Limit returns the requests allowed in flight per identifier
+func (l *ConcurrencyLimiter) Limit() int {
+	return l.limit
+}
+
+// ConcurrencyLimitMiddleware rejects requests with 429 while the identifier
+// returned by identifierFunc already has the limiter's maximum in flight.
+// name identifies the limit in rejected responses (e.g. "inflight_per_ip").
+func ConcurrencyLimitMiddleware(limiter *ConcurrencyLimiter, identifierFunc func(*http.Request) string, name string, logger log.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			identifier := identifierFunc(r)
+			if !limiter.Acquire(identifier) {
+				requestLogger(r, logger).Warn("concurrency limit exceeded",
+					log.String("identifier", identifier),
+					log.String("limit", name),
+					log.Int("max_in_flight", limiter.Limit()),
+				)
+				writeConcurrencyLimitResponse(w, r, name, limiter.Limit())
+				return
+			}
+			defer limiter.Release(identifier)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// CredentialIdentifier identifies the credential a request was authenticated
+// with: the API key, else the token's identity, else the client IP
+func CredentialIdentifier(r *http.Request) string {
+	if keyID, ok := r.Context().Value(APIKeyIDContextKey).(int64); ok {
+		return "key:" + strconv.FormatInt(keyID, 10)
+	}
+	return UserIdentifier(r)
+}
+
+// writeConcurrencyLimitResponse sends a 429 Too Many Requests problem response.
+// A slot frees up as soon as one of the requests in flight finishes.
+func writeConcurrencyLimitResponse(w http.ResponseWriter, r *http.Request, name string, limit int) {
+	retryAfter := 1
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+
+	writeProblem(w, Problem{
+		Type:     ProblemTypeTooManyInFlight,
+		Title:    "Too many concurrent requests",
+		Status:   http.StatusTooManyRequests,
+		Detail:   fmt.Sprintf("At most %d requests may be in flight at once. Retry after a request completes.", limit),
+		Instance: r.URL.Path,
+		Limit: &ProblemLimit{
+			Name:        name,
+			MaxInFlight: limit,
+		},
+		RetryAfter: &retryAfter,
+	})
+}
@@ -0,0 +1,110 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/log"
+)
+
+func TestConcurrencyLimiter_AcquireRelease(t *testing.T) {
+	limiter := NewConcurrencyLimiter(2)
+
+	assert.True(t, limiter.Acquire("ip:1.2.3.4"))
+	assert.True(t, limiter.Acquire("ip:1.2.3.4"))
+	assert.False(t, limiter.Acquire("ip:1.2.3.4"))
+	assert.True(t, limiter.Acquire("ip:5.6.7.8"), "identifiers have separate slots")
+
+	limiter.Release("ip:1.2.3.4")
+	assert.Equal(t, 1, limiter.InFlight("ip:1.2.3.4"))
+	assert.True(t, limiter.Acquire("ip:1.2.3.4"))
+
+	limiter.Release("ip:1.2.3.4")
+	limiter.Release("ip:1.2.3.4")
+	limiter.Release("ip:5.6.7.8")
+	assert.Empty(t, limiter.inFlight, "idle identifiers are forgotten")
+}
+
+func TestConcurrencyLimitMiddleware_RejectsBeyondLimit(t *testing.T) {
+	logger, _ := log.New("info")
+	limiter := NewConcurrencyLimiter(2)
+
+	release := make(chan struct{})
+	var started sync.WaitGroup
+	started.Add(2)
+	handler := ConcurrencyLimitMiddleware(limiter, IPIdentifier, "inflight_per_ip", logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started.Done()
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// Hold two requests in flight
+	var done sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			req := httptest.NewRequest("GET", "/api/data", nil)
+			req.RemoteAddr = "1.2.3.4:1000"
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+		}()
+	}
+	started.Wait()
+
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.RemoteAddr = "1.2.3.4:1001"
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	var problem Problem
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, ProblemTypeTooManyInFlight, problem.Type)
+	require.NotNil(t, problem.Limit)
+	assert.Equal(t, "inflight_per_ip", problem.Limit.Name)
+	assert.Equal(t, 2, problem.Limit.MaxInFlight)
+
+	// Slots free up once the requests in flight complete
+	close(release)
+	done.Wait()
+	assert.Zero(t, limiter.InFlight("ip:1.2.3.4"))
+
+	started.Add(1)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestConcurrencyLimitMiddleware_ReleasesOnPanic(t *testing.T) {
+	logger, _ := log.New("info")
+	limiter := NewConcurrencyLimiter(1)
+	handler := ConcurrencyLimitMiddleware(limiter, IPIdentifier, "inflight_per_ip", logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("handler failed")
+	}))
+
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	assert.Panics(t, func() { handler.ServeHTTP(httptest.NewRecorder(), req) })
+	assert.Zero(t, limiter.InFlight(IPIdentifier(req)))
+}
+
+func TestCredentialIdentifier(t *testing.T) {
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.RemoteAddr = "1.2.3.4:1000"
+	assert.Equal(t, "ip:1.2.3.4", CredentialIdentifier(req))
+
+	ctx := context.WithValue(req.Context(), ClaimsContextKey, &auth.Claims{Address: "0xabc"})
+	assert.Equal(t, "user:0xabc", CredentialIdentifier(req.WithContext(ctx)))
+
+	ctx = context.WithValue(ctx, APIKeyIDContextKey, int64(42))
+	assert.Equal(t, "key:42", CredentialIdentifier(req.WithContext(ctx)))
+}
@@ -0,0 +1,115 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ConditionalGETMiddleware creates a middleware that adds an ETag to successful
+// GET and HEAD responses and answers 304 Not Modified when the client already
+// holds the current representation, so polling clients don't re-download
+// unchanged payloads.
+//
+// The ETag is a hash of the response body unless the handler set one itself.
+// If-None-Match takes precedence; If-Modified-Since is only honoured when the
+// handler sets Last-Modified. The response is buffered, so use it on bounded
+// list endpoints rather than streams.
+func ConditionalGETMiddleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			buffered := &bufferedResponseWriter{header: w.Header(), statusCode: http.StatusOK}
+			next.ServeHTTP(buffered, r)
+
+			if buffered.statusCode != http.StatusOK {
+				buffered.flushTo(w)
+				return
+			}
+
+			header := w.Header()
+			etag := header.Get("ETag")
+			if etag == "" {
+				sum := sha256.Sum256(buffered.body.Bytes())
+				etag = `"` + hex.EncodeToString(sum[:16]) + `"`
+				header.Set("ETag", etag)
+			}
+
+			if notModified(r, etag, header.Get("Last-Modified")) {
+				header.Del("Content-Type")
+				header.Del("Content-Length")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+
+			buffered.flushTo(w)
+		})
+	}
+}
+
+// notModified reports whether the request's preconditions match the current
+// representation (RFC 9110 section 13.2.2)
+func notModified(r *http.Request, etag, lastModified string) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagListMatches(inm, etag)
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || lastModified == "" {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// etagListMatches reports whether an If-None-Match header matches etag, using
+// the weak comparison required for GET
+func etagListMatches(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedResponseWriter holds a response until its ETag is known
+type bufferedResponseWriter struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (b *bufferedResponseWriter) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponseWriter) WriteHeader(statusCode int) {
+	b.statusCode = statusCode
+}
+
+func (b *bufferedResponseWriter) Write(data []byte) (int, error) {
+	return b.body.Write(data)
+}
+
+// flushTo writes the buffered response to w
+func (b *bufferedResponseWriter) flushTo(w http.ResponseWriter) {
+	w.WriteHeader(b.statusCode)
+	w.Write(b.body.Bytes())
+}
@@ -0,0 +1,147 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listHandler(body string, calls *int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(body))
+	})
+}
+
+func TestConditionalGET_AddsETag(t *testing.T) {
+	calls := 0
+	handler := ConditionalGETMiddleware()(listHandler(`{"keys":[]}`, &calls))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/api/keys", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"keys":[]}`, rec.Body.String())
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, rec.Header().Get("ETag"))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+}
+
+func TestConditionalGET_IfNoneMatch(t *testing.T) {
+	calls := 0
+	handler := ConditionalGETMiddleware()(listHandler(`{"keys":[]}`, &calls))
+
+	first := httptest.NewRecorder()
+	handler.ServeHTTP(first, httptest.NewRequest("GET", "/api/keys", nil))
+	etag := first.Header().Get("ETag")
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        int
+	}{
+		{"matching", etag, http.StatusNotModified},
+		{"weak", "W/" + etag, http.StatusNotModified},
+		{"in list", `"other", ` + etag, http.StatusNotModified},
+		{"wildcard", "*", http.StatusNotModified},
+		{"stale", `"0123"`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/keys", nil)
+			req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.want, rec.Code)
+			assert.Equal(t, etag, rec.Header().Get("ETag"))
+			if tt.want == http.StatusNotModified {
+				assert.Empty(t, rec.Body.String())
+				assert.Empty(t, rec.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestConditionalGET_IfModifiedSince(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	handler := ConditionalGETMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+		w.Write([]byte("openapi: 3.0.0"))
+	}))
+
+	tests := []struct {
+		name  string
+		since time.Time
+		want  int
+	}{
+		{"same time", modified, http.StatusNotModified},
+		{"later", modified.Add(time.Hour), http.StatusNotModified},
+		{"earlier", modified.Add(-time.Second), http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/openapi.yaml", nil)
+			req.Header.Set("If-Modified-Since", tt.since.Format(http.TimeFormat))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.want, rec.Code)
+		})
+	}
+
+	// If-None-Match takes precedence over If-Modified-Since
+	req := httptest.NewRequest("GET", "/openapi.yaml", nil)
+	req.Header.Set("If-None-Match", `"stale"`)
+	req.Header.Set("If-Modified-Since", modified.Format(http.TimeFormat))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestConditionalGET_KeepsHandlerETag(t *testing.T) {
+	handler := ConditionalGETMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v42"`)
+		w.Write([]byte("body"))
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("If-None-Match", `"v42"`)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Equal(t, `"v42"`, rec.Header().Get("ETag"))
+}
+
+func TestConditionalGET_SkipsErrorsAndOtherMethods(t *testing.T) {
+	handler := ConditionalGETMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error":"Internal server error"}`))
+	}))
+
+	req := httptest.NewRequest("GET", "/api/keys", nil)
+	req.Header.Set("If-None-Match", "*")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Empty(t, rec.Header().Get("ETag"))
+	assert.Equal(t, `{"error":"Internal server error"}`, rec.Body.String())
+
+	calls := 0
+	post := ConditionalGETMiddleware()(listHandler(`{}`, &calls))
+	req = httptest.NewRequest("POST", "/api/keys", nil)
+	req.Header.Set("If-None-Match", "*")
+	rec = httptest.NewRecorder()
+	post.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("ETag"))
+	assert.Equal(t, 1, calls)
+}
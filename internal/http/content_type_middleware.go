@@ -0,0 +1,90 @@
+package http
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// RequireJSONContentType creates a middleware for JSON endpoints. Requests with a body
+// must declare application/json (or a +json type), and a charset, if given, must be
+// UTF-8; anything else is rejected with 415 before a handler parses it. Empty bodies
+// are normalized to http.NoBody with a zero ContentLength, including chunked requests
+// that send no data, so handlers see them the same way however the client sent them.
+// Paths under exceptPrefixes accept other media types; their handlers check the type.
+func RequireJSONContentType(exceptPrefixes ...string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isEmptyBody(r) {
+				r.Body = http.NoBody
+				r.ContentLength = 0
+				next.ServeHTTP(w, r)
+				return
+			}
+			if longestPathPrefix(r.URL.Path, exceptPrefixes) != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if err := checkJSONContentType(r.Header.Get("Content-Type")); err != nil {
+				w.Header().Set("Accept", "application/json")
+				writeProblem(w, Problem{
+					Type:     ProblemTypeUnsupportedMedia,
+					Title:    "Unsupported media type",
+					Status:   http.StatusUnsupportedMediaType,
+					Detail:   err.Error(),
+					Instance: r.URL.Path,
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isEmptyBody reports whether the request has no body. Bodies of unknown length are
+// peeked; the peeked byte stays readable through r.Body.
+func isEmptyBody(r *http.Request) bool {
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+		return true
+	}
+	if r.ContentLength > 0 {
+		return false
+	}
+
+	reader := bufio.NewReader(r.Body)
+	if _, err := reader.Peek(1); err == io.EOF {
+		return true
+	}
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{reader, r.Body}
+	return false
+}
+
+// checkJSONContentType returns an error describing why contentType is not acceptable JSON
+func checkJSONContentType(contentType string) error {
+	if contentType == "" {
+		return fmt.Errorf("missing Content-Type header; use application/json")
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("malformed Content-Type header: %s", contentType)
+	}
+
+	if mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return fmt.Errorf("unsupported Content-Type %s; use application/json", mediaType)
+	}
+
+	if charset, ok := params["charset"]; ok && !strings.EqualFold(charset, "utf-8") {
+		return fmt.Errorf("unsupported charset %s; JSON bodies must be UTF-8", charset)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireJSONContentType(t *testing.T) {
+	tests := []struct {
+		name           string
+		contentType    string
+		body           string
+		expectedStatus int
+	}{
+		{name: "json", contentType: "application/json", body: `{"name":"key"}`, expectedStatus: http.StatusOK},
+		{name: "json with utf-8 charset", contentType: "application/json; charset=UTF-8", body: `{}`, expectedStatus: http.StatusOK},
+		{name: "json suffix type", contentType: "application/merge-patch+json", body: `{}`, expectedStatus: http.StatusOK},
+		{name: "empty body without content type", body: "", expectedStatus: http.StatusOK},
+		{name: "missing content type", body: `{}`, expectedStatus: http.StatusUnsupportedMediaType},
+		{name: "form encoded", contentType: "application/x-www-form-urlencoded", body: "name=key", expectedStatus: http.StatusUnsupportedMediaType},
+		{name: "text plain", contentType: "text/plain", body: `{}`, expectedStatus: http.StatusUnsupportedMediaType},
+		{name: "non utf-8 charset", contentType: "application/json; charset=utf-16", body: `{}`, expectedStatus: http.StatusUnsupportedMediaType},
+		{name: "malformed content type", contentType: "application/json; charset", body: `{}`, expectedStatus: http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received string
+			handler := RequireJSONContentType()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				received = string(body)
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest("POST", "/api/keys", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, tt.body, received)
+				return
+			}
+
+			assert.Equal(t, ProblemContentType, rec.Header().Get("Content-Type"))
+			var problem Problem
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+			assert.Equal(t, ProblemTypeUnsupportedMedia, problem.Type)
+		})
+	}
+}
+
+// TestRequireJSONContentType_ChunkedBodies verifies bodies of unknown length are
+// normalized when empty and passed through intact otherwise
+func TestRequireJSONContentType_ChunkedBodies(t *testing.T) {
+	var received string
+	var contentLength int64
+	handler := RequireJSONContentType()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		contentLength = r.ContentLength
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("POST", "/api/keys", io.NopCloser(strings.NewReader("")))
+	req.ContentLength = -1
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, int64(0), contentLength)
+
+	req = httptest.NewRequest("POST", "/api/keys", io.NopCloser(strings.NewReader(`{"name":"key"}`)))
+	req.ContentLength = -1
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"name":"key"}`, received)
+}
+
+// TestRequireJSONContentType_ExceptPrefixes leaves other media types to handlers of excepted paths
+func TestRequireJSONContentType_ExceptPrefixes(t *testing.T) {
+	handler := RequireJSONContentType("/api/admin/allowlists")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("POST", "/api/admin/allowlists/1/import", strings.NewReader("address\n"))
+	req.Header.Set("Content-Type", "text/csv")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	req = httptest.NewRequest("POST", "/api/keys", strings.NewReader("address\n"))
+	req.Header.Set("Content-Type", "text/csv")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+}
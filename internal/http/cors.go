@@ -0,0 +1,111 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSPolicy describes which cross-origin requests are allowed
+type CORSPolicy struct {
+	AllowedOrigins   []string // Exact origins, or "*" for any origin
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool          // Only ever applies to exact origins, never to "*"
+	MaxAge           time.Duration // How long browsers may cache preflight results
+}
+
+// allowsOrigin reports whether the policy allows the given origin, and whether it
+// is listed exactly rather than through the wildcard
+func (p *CORSPolicy) allowsOrigin(origin string) (allowed, exact bool) {
+	for _, candidate := range p.AllowedOrigins {
+		if strings.EqualFold(candidate, origin) {
+			return true, true
+		}
+		if candidate == "*" {
+			allowed = true
+		}
+	}
+	return allowed, false
+}
+
+// CORSMiddleware creates a middleware applying defaultPolicy to every route, except that
+// paths under a prefix in overrides use that policy instead (the longest prefix wins).
+// A nil policy disables cross-origin access for its routes.
+//
+// The middleware must wrap the router rather than be registered with router.Use,
+// so preflight requests are answered before method matching.
+func CORSMiddleware(defaultPolicy *CORSPolicy, overrides map[string]*CORSPolicy) Middleware {
+	prefixes := make([]string, 0, len(overrides))
+	for prefix := range overrides {
+		prefixes = append(prefixes, prefix)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			policy := defaultPolicy
+			if prefix := longestPathPrefix(r.URL.Path, prefixes); prefix != "" {
+				policy = overrides[prefix]
+			}
+
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			w.Header().Add("Vary", "Origin")
+
+			var allowed, exact bool
+			if origin != "" && policy != nil {
+				allowed, exact = policy.allowsOrigin(origin)
+			}
+			if !allowed {
+				if preflight {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Origins allowed only through the wildcard get a literal "*", which browsers
+			// never send credentials to, so "*" cannot open credentialed access to any site
+			if exact {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				if policy.AllowCredentials {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			}
+
+			if !preflight {
+				if len(policy.ExposedHeaders) > 0 {
+					w.Header().Set("Access-Control-Expose-Headers", strings.Join(policy.ExposedHeaders, ", "))
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(policy.AllowedMethods, ", "))
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(policy.AllowedHeaders, ", "))
+			if policy.MaxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAge.Seconds())))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// longestPathPrefix returns the longest prefix that matches path on a segment boundary,
+// or "" if none does. "/api/admin" matches "/api/admin" and "/api/admin/x" but not "/api/administrator".
+func longestPathPrefix(path string, prefixes []string) string {
+	longest := ""
+	for _, prefix := range prefixes {
+		trimmed := strings.TrimSuffix(prefix, "/")
+		matches := path == trimmed || strings.HasPrefix(path, trimmed+"/")
+		if matches && len(prefix) > len(longest) {
+			longest = prefix
+		}
+	}
+	return longest
+}
@@ -0,0 +1,127 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testCORSHandler(overrides map[string]*CORSPolicy) http.Handler {
+	policy := &CORSPolicy{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+	return CORSMiddleware(policy, overrides)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestCORSMiddleware_AllowedOrigin(t *testing.T) {
+	handler := testCORSHandler(nil)
+
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestCORSMiddleware_DisallowedOrigin(t *testing.T) {
+	handler := testCORSHandler(nil)
+
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORSMiddleware_Preflight(t *testing.T) {
+	handler := testCORSHandler(nil)
+
+	req := httptest.NewRequest("OPTIONS", "/api/keys", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "GET, POST", rec.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Authorization, Content-Type", rec.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", rec.Header().Get("Access-Control-Max-Age"))
+}
+
+func TestCORSMiddleware_RouteOverride(t *testing.T) {
+	handler := testCORSHandler(map[string]*CORSPolicy{
+		"/api/admin":  nil,
+		"/api/public": {AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}},
+	})
+
+	tests := []struct {
+		name           string
+		path           string
+		origin         string
+		expectedOrigin string
+	}{
+		{name: "admin disables CORS", path: "/api/admin/log-level", origin: "https://app.example.com", expectedOrigin: ""},
+		{name: "prefix matches on segment boundary", path: "/api/administrator", origin: "https://app.example.com", expectedOrigin: "https://app.example.com"},
+		{name: "public allows any origin", path: "/api/public/stats", origin: "https://other.example.com", expectedOrigin: "*"},
+		{name: "default policy elsewhere", path: "/api/data", origin: "https://other.example.com", expectedOrigin: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Header.Set("Origin", tt.origin)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedOrigin, rec.Header().Get("Access-Control-Allow-Origin"))
+		})
+	}
+}
+
+// TestCORSMiddleware_WildcardWithoutCredentials never lets "*" grant credentialed
+// access, even to a policy allowing credentials
+func TestCORSMiddleware_WildcardWithoutCredentials(t *testing.T) {
+	handler := testCORSHandler(map[string]*CORSPolicy{
+		"/api": {
+			AllowedOrigins:   []string{"https://app.example.com", "*"},
+			AllowedMethods:   []string{"GET"},
+			AllowCredentials: true,
+		},
+	})
+
+	for _, preflight := range []bool{false, true} {
+		req := httptest.NewRequest("GET", "/api/data", nil)
+		if preflight {
+			req = httptest.NewRequest("OPTIONS", "/api/data", nil)
+			req.Header.Set("Access-Control-Request-Method", "GET")
+		}
+		req.Header.Set("Origin", "https://evil.example.com")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+	}
+
+	// Origins listed exactly keep their credentials
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+}
@@ -0,0 +1,237 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/gatekeeper/internal/auth"
+)
+
+const (
+	debugTimingContextKey contextKey = "debug_timing"
+
+	// debugHeader asks for debug annotations of the response; "timing" requests the
+	// Server-Timing breakdown
+	debugHeader      = "X-Gatekeeper-Debug"
+	debugTimingValue = "timing"
+
+	// serverTimingHeader carries the breakdown (https://www.w3.org/TR/server-timing/)
+	serverTimingHeader = "Server-Timing"
+)
+
+// Phases of a request reported in the Server-Timing header
+const (
+	TimingAuth     = "auth"     // Capability token, API key and JWT authentication
+	TimingPolicy   = "policy"   // Access policy evaluation, including its RPC calls
+	TimingRPC      = "rpc"      // Blockchain RPC calls made while evaluating policies
+	TimingUpstream = "upstream" // The handler serving the request, until it starts responding
+)
+
+// debugTimingPhases orders the phases in the Server-Timing header
+var debugTimingPhases = []string{TimingAuth, TimingPolicy, TimingRPC, TimingUpstream}
+
+// debugTimings collects how long each phase of a request took. Phases still running
+// when the response header is written, such as a policy rejecting the request, are
+// timed until then. The caller is recorded once authenticated, since only admins are
+// shown the breakdown.
+type debugTimings struct {
+	mu       sync.Mutex
+	start    time.Time
+	phases   map[string]time.Duration
+	running  map[string]time.Time
+	rpcCalls int64
+	caller   *auth.Claims
+}
+
+// begin starts timing a phase
+func (t *debugTimings) begin(phase string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.running[phase] = time.Now()
+}
+
+// end stops timing a phase, adding the time since it began
+func (t *debugTimings) end(phase string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if start, ok := t.running[phase]; ok {
+		t.phases[phase] += time.Since(start)
+		delete(t.running, phase)
+	}
+}
+
+// addRPC adds calls taking d to the RPC phase
+func (t *debugTimings) addRPC(calls int64, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.phases[TimingRPC] += d
+	t.rpcCalls += calls
+}
+
+// setCaller records the authenticated caller
+func (t *debugTimings) setCaller(claims *auth.Claims) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.caller = claims
+}
+
+// header formats the phases as a Server-Timing value, or returns "" if the caller
+// is not an admin
+func (t *debugTimings) header() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var granted auth.Role
+	if t.caller != nil {
+		granted, _ = auth.HighestRole(t.caller.Scopes)
+	}
+	if !granted.Includes(auth.RoleAdmin) {
+		return ""
+	}
+
+	now := time.Now()
+	metrics := make([]string, 0, len(debugTimingPhases)+1)
+	for _, phase := range debugTimingPhases {
+		d, ok := t.phases[phase]
+		if start, running := t.running[phase]; running {
+			d, ok = d+now.Sub(start), true
+		}
+		if !ok {
+			continue
+		}
+		metric := fmt.Sprintf("%s;dur=%.3f", phase, durationMs(d))
+		if phase == TimingRPC {
+			metric += fmt.Sprintf(`;desc="%d calls"`, t.rpcCalls)
+		}
+		metrics = append(metrics, metric)
+	}
+	metrics = append(metrics, fmt.Sprintf("total;dur=%.3f", durationMs(now.Sub(t.start))))
+	return strings.Join(metrics, ", ")
+}
+
+// durationMs converts d to fractional milliseconds, the unit of Server-Timing
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// debugTimingsFromContext returns the timings collected for the request, or nil if
+// none were asked for
+func debugTimingsFromContext(ctx context.Context) *debugTimings {
+	timings, _ := ctx.Value(debugTimingContextKey).(*debugTimings)
+	return timings
+}
+
+// DebugTimingMiddleware annotates responses to requests sending
+// "X-Gatekeeper-Debug: timing" with a Server-Timing header breaking their latency
+// down into auth, policy, RPC and upstream time, so integrators can locate latency
+// without enabling tracing. Only callers holding the admin role are answered with
+// the header; the phases are timed by TimeAuth, TimePhase and TimeUpstream.
+func DebugTimingMiddleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !strings.EqualFold(strings.TrimSpace(r.Header.Get(debugHeader)), debugTimingValue) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			timings := &debugTimings{
+				start:   time.Now(),
+				phases:  make(map[string]time.Duration),
+				running: make(map[string]time.Time),
+			}
+			wrapped := &debugTimingWriter{ResponseWriter: w, timings: timings}
+			next.ServeHTTP(wrapped, r.WithContext(context.WithValue(r.Context(), debugTimingContextKey, timings)))
+			if !wrapped.written {
+				wrapped.WriteHeader(http.StatusOK)
+			}
+		})
+	}
+}
+
+// debugTimingWriter sets the Server-Timing header just before the response
+// header is written
+type debugTimingWriter struct {
+	http.ResponseWriter
+	timings *debugTimings
+	written bool
+}
+
+// WriteHeader sets the Server-Timing header, if the caller may see it
+func (w *debugTimingWriter) WriteHeader(statusCode int) {
+	if !w.written {
+		w.written = true
+		if value := w.timings.header(); value != "" {
+			w.Header().Set(serverTimingHeader, value)
+		}
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write ensures WriteHeader is called
+func (w *debugTimingWriter) Write(b []byte) (int, error) {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap exposes the underlying writer so http.ResponseController can flush
+// streamed responses through this wrapper
+func (w *debugTimingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// TimePhase runs a middleware and records how long it takes, until the request is
+// either passed on or rejected, as a phase of the Server-Timing breakdown. The time
+// spent in the wrapped handler is not included.
+func TimePhase(phase string, middleware Middleware) Middleware {
+	return func(next http.Handler) http.Handler {
+		inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			endDebugPhase(r, phase)
+			next.ServeHTTP(w, r)
+		})
+		handler := middleware(inner)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			beginDebugPhase(r, phase)
+			handler.ServeHTTP(w, r)
+			endDebugPhase(r, phase)
+		})
+	}
+}
+
+// TimeUpstream times the handler serving a request as the upstream phase, until the
+// response header is written. It must be the innermost middleware.
+func TimeUpstream() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			beginDebugPhase(r, TimingUpstream)
+			next.ServeHTTP(w, r)
+			endDebugPhase(r, TimingUpstream)
+		})
+	}
+}
+
+// beginDebugPhase starts timing a phase of the request, if it is timed
+func beginDebugPhase(r *http.Request, phase string) {
+	if timings := debugTimingsFromContext(r.Context()); timings != nil {
+		timings.begin(phase)
+	}
+}
+
+// endDebugPhase stops timing a phase of the request, if it is timed
+func endDebugPhase(r *http.Request, phase string) {
+	if timings := debugTimingsFromContext(r.Context()); timings != nil {
+		timings.end(phase)
+	}
+}
+
+// recordDebugCaller records the caller a timed request was authenticated as
+func recordDebugCaller(r *http.Request) {
+	if timings := debugTimingsFromContext(r.Context()); timings != nil {
+		timings.setCaller(ClaimsFromContext(r))
+	}
+}
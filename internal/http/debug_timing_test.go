@@ -0,0 +1,84 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yourusername/gatekeeper/internal/auth"
+)
+
+// newDebugTimingHandler builds a chain like /api routes: authentication as the
+// caller with the given scopes, a policy middleware, then the handler
+func newDebugTimingHandler(scopes []string, policy Middleware, handler http.HandlerFunc) http.Handler {
+	authenticate := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims := &auth.Claims{Address: "0x1234567890abcdef1234567890abcdef12345678", Scopes: scopes}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ClaimsContextKey, claims)))
+		})
+	}
+	return DebugTimingMiddleware()(
+		TimeAuth(NewMetricsCollector(nil), authenticate)(
+			TimePhase(TimingPolicy, policy)(
+				TimeUpstream()(handler))))
+}
+
+func TestDebugTimingMiddleware(t *testing.T) {
+	allow := func(next http.Handler) http.Handler { return next }
+	handler := newDebugTimingHandler([]string{"admin"}, allow, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.Header.Set(debugHeader, "timing")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Regexp(t, regexp.MustCompile(`^auth;dur=[0-9.]+, policy;dur=[0-9.]+, upstream;dur=[0-9.]+, total;dur=[0-9.]+$`),
+		rec.Header().Get(serverTimingHeader))
+
+	// Without the debug header nothing is timed
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/api/data", nil))
+	assert.Empty(t, rec.Header().Get(serverTimingHeader))
+}
+
+func TestDebugTimingMiddleware_AdminOnly(t *testing.T) {
+	allow := func(next http.Handler) http.Handler { return next }
+	for _, scopes := range [][]string{nil, {"read"}, {"operator"}} {
+		handler := newDebugTimingHandler(scopes, allow, func(w http.ResponseWriter, r *http.Request) {})
+
+		req := httptest.NewRequest("GET", "/api/data", nil)
+		req.Header.Set(debugHeader, "timing")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Empty(t, rec.Header().Get(serverTimingHeader), "scopes %v", scopes)
+	}
+}
+
+// TestDebugTimingMiddleware_Denied times the policy phase of a request it rejects,
+// and reports RPC calls made while evaluating it
+func TestDebugTimingMiddleware_Denied(t *testing.T) {
+	deny := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			debugTimingsFromContext(r.Context()).addRPC(3, 0)
+			http.Error(w, "Forbidden", http.StatusForbidden)
+		})
+	}
+	handler := newDebugTimingHandler([]string{"admin"}, deny, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("denied requests must not reach the handler")
+	})
+
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.Header.Set(debugHeader, "Timing")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Regexp(t, regexp.MustCompile(`^auth;dur=[0-9.]+, policy;dur=[0-9.]+, rpc;dur=0.000;desc="3 calls", total;dur=[0-9.]+$`),
+		rec.Header().Get(serverTimingHeader))
+}
@@ -0,0 +1,139 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/yourusername/gatekeeper/internal/policy"
+)
+
+// RuleEligibilityResponse is the outcome of one rule for the caller
+type RuleEligibilityResponse struct {
+	Type    string `json:"type"`
+	Outcome string `json:"outcome"` // pass, fail or unknown
+}
+
+// PolicyEligibility is the outcome of one enforced policy for the caller
+type PolicyEligibility struct {
+	Method    string                    `json:"method"`
+	Path      string                    `json:"path"`
+	Logic     string                    `json:"logic"`
+	Threshold int                       `json:"threshold,omitempty"` // SCORE logic only
+	Outcome   string                    `json:"outcome"`
+	Rules     []RuleEligibilityResponse `json:"rules"`
+}
+
+// EligibilityResponse represents the response for GET /api/eligibility
+type EligibilityResponse struct {
+	Method   string              `json:"method"`
+	Path     string              `json:"path"`
+	Outcome  string              `json:"outcome"` // pass, fail or unknown
+	Policies []PolicyEligibility `json:"policies"`
+}
+
+// EligibilityHandler tells callers whether they would pass the policies of a route,
+// so UIs can disable features up front instead of letting users run into 403s.
+// Only cached blockchain results are used: a check never calls the RPC provider,
+// and rules that need an uncached result are reported as unknown.
+type EligibilityHandler struct {
+	middleware *PolicyMiddleware
+}
+
+// NewEligibilityHandler creates a new eligibility handler evaluating the policies
+// of the policy middleware the way it does
+func NewEligibilityHandler(middleware *PolicyMiddleware) *EligibilityHandler {
+	return &EligibilityHandler{middleware: middleware}
+}
+
+// CheckEligibility handles GET /api/eligibility?path=/api/data&method=GET - The
+// caller's current outcome for every enforced policy of a route and each of its
+// rules. The method defaults to GET; the path may carry the query of the request.
+func (h *EligibilityHandler) CheckEligibility(w http.ResponseWriter, r *http.Request) {
+	pm := h.middleware
+
+	claims := ClaimsFromContext(r)
+	if claims == nil {
+		h.writeError(w, "Unauthorized", "No authentication claims found", http.StatusUnauthorized)
+		return
+	}
+
+	rawPath := r.URL.Query().Get("path")
+	if rawPath == "" {
+		h.writeError(w, "Invalid request", "The path parameter is required", http.StatusBadRequest)
+		return
+	}
+	target, err := url.ParseRequestURI(rawPath)
+	if err != nil || target.IsAbs() || !strings.HasPrefix(target.Path, "/") {
+		h.writeError(w, "Invalid request", "The path parameter must be an absolute path, e.g. /api/data", http.StatusBadRequest)
+		return
+	}
+	method := strings.ToUpper(r.URL.Query().Get("method"))
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	// Rules see the request as if it had been made to the route
+	targetRequest := r.Clone(r.Context())
+	targetRequest.Method = method
+	targetRequest.URL = &url.URL{Path: target.Path, RawQuery: target.RawQuery}
+
+	routePolicies := pm.policyManager.GetPoliciesForRoute(target.Path, method)
+	policies, _ := splitShadowPolicies(routePolicies)
+	wallets := pm.linkedWallets(r, claims, policies)
+
+	response := EligibilityResponse{
+		Method:   method,
+		Path:     target.Path,
+		Outcome:  policy.EligibilityPass,
+		Policies: make([]PolicyEligibility, 0, len(policies)),
+	}
+	for _, p := range policies {
+		ctx, reservations := policy.WithQuotaReservations(pm.policyContext(targetRequest, claims, wallets))
+		if ec := policy.EvaluationContextFromContext(ctx); ec != nil {
+			ec.Params = policy.RouteParams(p.Path, target.Path)
+		}
+		outcome, rules := p.CheckEligibility(ctx, claims.Address, claims)
+		pm.releaseQuota(r, reservations)
+
+		result := PolicyEligibility{
+			Method:  p.Method,
+			Path:    p.Path,
+			Logic:   p.Logic,
+			Outcome: outcome,
+			Rules:   make([]RuleEligibilityResponse, len(rules)),
+		}
+		if p.Logic == "SCORE" {
+			result.Threshold = p.Threshold
+		}
+		for i, rule := range rules {
+			result.Rules[i] = RuleEligibilityResponse{Type: string(rule.Type), Outcome: rule.Outcome}
+		}
+		response.Policies = append(response.Policies, result)
+
+		// Every policy must pass: one failure decides, otherwise one unknown does
+		switch {
+		case outcome == policy.EligibilityFail:
+			response.Outcome = policy.EligibilityFail
+		case outcome == policy.EligibilityUnknown && response.Outcome == policy.EligibilityPass:
+			response.Outcome = policy.EligibilityUnknown
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeError writes an error response
+func (h *EligibilityHandler) writeError(w http.ResponseWriter, error, details string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := ErrorResponse{
+		Error:   error,
+		Details: details,
+	}
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/policy"
+)
+
+// checkEligibility requests GET /api/eligibility with the given query as the caller
+func checkEligibility(pm *policy.PolicyManager, query string, claims *auth.Claims) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/api/eligibility?"+query, nil)
+	if claims != nil {
+		req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, claims))
+	}
+	rec := httptest.NewRecorder()
+	NewEligibilityHandler(NewPolicyMiddleware(pm, nil, nil)).CheckEligibility(rec, req)
+	return rec
+}
+
+func TestEligibilityHandler_CheckEligibility(t *testing.T) {
+	// The provider would be called outside an eligibility check; nothing is cached
+	pm := policy.NewPolicyManager(&mockBlockchainProvider{}, nil)
+	pm.AddPolicy(policy.NewPolicy("GET", "/api/items/{id}", "AND", []policy.Rule{
+		policy.NewHasScopeRule("read"),
+		policy.NewQueryParamPresentRule("page"),
+	}))
+	pm.AddPolicy(policy.NewPolicy("POST", "/api/items/{id}", "AND", []policy.Rule{
+		policy.NewHasScopeRule("write"),
+	}))
+	pm.AddPolicy(policy.NewPolicy("GET", "/api/holders", "OR", []policy.Rule{
+		policy.NewHasScopeRule("admin"),
+		policy.NewERC20MinBalanceRule("0x1f9840a85d5af5bf1d1762f925bdaddc4201f984", big.NewInt(1000), 1),
+	}))
+	shadow := policy.NewPolicy("GET", "/api/holders", "AND", []policy.Rule{policy.NewHasScopeRule("beta")})
+	shadow.Shadow = true
+	pm.AddPolicy(shadow)
+	claims := &auth.Claims{Address: "0x742d35cc6634c0532925a3b844bc9e7595f0beb1", Scopes: []string{"read"}}
+
+	t.Run("request rules see the target route", func(t *testing.T) {
+		rec := checkEligibility(pm, "path=/api/items/42%3Fpage%3D2", claims)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var response EligibilityResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, "GET", response.Method)
+		assert.Equal(t, "/api/items/42", response.Path)
+		assert.Equal(t, policy.EligibilityPass, response.Outcome)
+		require.Len(t, response.Policies, 1)
+		assert.Equal(t, []RuleEligibilityResponse{
+			{Type: "has_scope", Outcome: "pass"},
+			{Type: "query_param_present", Outcome: "pass"},
+		}, response.Policies[0].Rules)
+	})
+
+	t.Run("method selects the policies", func(t *testing.T) {
+		var response EligibilityResponse
+		rec := checkEligibility(pm, "path=/api/items/42&method=post", claims)
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, "POST", response.Method)
+		assert.Equal(t, policy.EligibilityFail, response.Outcome)
+	})
+
+	t.Run("uncached blockchain rules are unknown", func(t *testing.T) {
+		var response EligibilityResponse
+		rec := checkEligibility(pm, "path=/api/holders", claims)
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, policy.EligibilityUnknown, response.Outcome)
+		require.Len(t, response.Policies, 1, "shadow policies are left out")
+		assert.Equal(t, []RuleEligibilityResponse{
+			{Type: "has_scope", Outcome: "fail"},
+			{Type: "erc20_min_balance", Outcome: "unknown"},
+		}, response.Policies[0].Rules)
+	})
+
+	t.Run("ungated routes pass", func(t *testing.T) {
+		var response EligibilityResponse
+		rec := checkEligibility(pm, "path=/api/open", claims)
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, policy.EligibilityPass, response.Outcome)
+		assert.Empty(t, response.Policies)
+	})
+}
+
+func TestEligibilityHandler_CheckEligibility_Errors(t *testing.T) {
+	pm := policy.NewPolicyManager(nil, nil)
+	claims := &auth.Claims{Address: "0x742d35cc6634c0532925a3b844bc9e7595f0beb1"}
+
+	assert.Equal(t, http.StatusUnauthorized, checkEligibility(pm, "path=/api/data", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, checkEligibility(pm, "", claims).Code)
+	assert.Equal(t, http.StatusBadRequest, checkEligibility(pm, "path=api/data", claims).Code)
+	assert.Equal(t, http.StatusBadRequest, checkEligibility(pm, "path=https://example.com/api/data", claims).Code)
+}
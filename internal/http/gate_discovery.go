@@ -0,0 +1,190 @@
+package http
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+
+	"github.com/yourusername/gatekeeper/internal/chain"
+	"github.com/yourusername/gatekeeper/internal/policy"
+)
+
+// GateRequirement describes one rule of a gate. On-chain rules carry the token,
+// contract and threshold they check, which are public anyway; other rules are
+// described by their type only, so allowlists and request conditions stay private.
+type GateRequirement struct {
+	Type           string            `json:"type"`
+	ChainID        uint64            `json:"chainId,omitempty"`
+	Contract       string            `json:"contract,omitempty"`
+	TokenID        string            `json:"tokenId,omitempty"`
+	MinimumBalance string            `json:"minimumBalance,omitempty"`
+	MinimumDays    int               `json:"minimumDays,omitempty"`
+	BlockNumber    uint64            `json:"blockNumber,omitempty"`
+	Trait          *GateTrait        `json:"trait,omitempty"`
+	Function       string            `json:"function,omitempty"`
+	Comparison     string            `json:"comparison,omitempty"`
+	Value          string            `json:"value,omitempty"`
+	Scope          string            `json:"scope,omitempty"`
+	Points         int               `json:"points,omitempty"` // SCORE logic only
+	Tier           string            `json:"tier,omitempty"`   // TIER logic only
+	Requirements   []GateRequirement `json:"requirements,omitempty"`
+}
+
+// GateTrait is the metadata trait an erc721_trait rule requires
+type GateTrait struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Gate describes the requirements of one enforced policy
+type Gate struct {
+	Method       string            `json:"method"`
+	Path         string            `json:"path"`
+	Logic        string            `json:"logic"`
+	Threshold    int               `json:"threshold,omitempty"`   // SCORE logic only
+	DefaultTier  string            `json:"defaultTier,omitempty"` // TIER logic only
+	Requirements []GateRequirement `json:"requirements"`
+}
+
+// GateDiscoveryResponse represents the response for GET /.well-known/gatekeeper.json
+type GateDiscoveryResponse struct {
+	Network *chain.Network `json:"network,omitempty"` // The chain gates are checked on by default
+	Gates   []Gate         `json:"gates"`
+}
+
+// GateDiscoveryHandler publishes the requirements of the loaded policies, so dapp
+// frontends can show users why they are blocked and what they need
+type GateDiscoveryHandler struct {
+	policyManager *policy.PolicyManager
+	network       *chain.Network
+}
+
+// NewGateDiscoveryHandler creates a new gate discovery handler
+func NewGateDiscoveryHandler(pm *policy.PolicyManager) *GateDiscoveryHandler {
+	return &GateDiscoveryHandler{policyManager: pm}
+}
+
+// GetGates handles GET /.well-known/gatekeeper.json - The requirements of every
+// enforced policy, in evaluation order. Shadow policies are left out.
+func (h *GateDiscoveryHandler) GetGates(w http.ResponseWriter, r *http.Request) {
+	response := GateDiscoveryResponse{Network: h.network, Gates: make([]Gate, 0)}
+	for _, p := range h.policyManager.GetAllPolicies() {
+		if p.Shadow {
+			continue
+		}
+		gate := Gate{
+			Method:       p.Method,
+			Path:         p.Path,
+			Logic:        p.Logic,
+			Requirements: describeRequirements(p.Rules),
+		}
+		if p.Logic == "SCORE" {
+			gate.Threshold = p.Threshold
+			for i := range gate.Requirements {
+				if i < len(p.Points) {
+					gate.Requirements[i].Points = p.Points[i]
+				}
+			}
+		}
+		if p.Logic == "TIER" {
+			gate.DefaultTier = p.DefaultTier
+			for i := range gate.Requirements {
+				if i < len(p.Tiers) {
+					gate.Requirements[i].Tier = p.Tiers[i]
+				}
+			}
+		}
+		response.Gates = append(response.Gates, gate)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// SetNetwork sets the default chain published with the gates, so frontends can
+// tell testnet gates apart and link to faucets and explorers
+func (h *GateDiscoveryHandler) SetNetwork(network chain.Network) {
+	h.network = &network
+}
+
+// describeRequirements describes rules, in order
+func describeRequirements(rules []policy.Rule) []GateRequirement {
+	requirements := make([]GateRequirement, len(rules))
+	for i, rule := range rules {
+		requirements[i] = describeRequirement(rule)
+	}
+	return requirements
+}
+
+// describeRequirement describes a rule, with the public parameters of on-chain rules
+func describeRequirement(rule policy.Rule) GateRequirement {
+	requirement := GateRequirement{Type: string(rule.Type())}
+	switch r := rule.(type) {
+	case *policy.HasScopeRule:
+		requirement.Scope = r.Scope
+	case *policy.ERC20MinBalanceRule:
+		requirement.ChainID = r.ChainID
+		requirement.Contract = r.ContractAddress
+		requirement.MinimumBalance = bigString(r.MinimumBalance)
+	case *policy.ERC721OwnerRule:
+		requirement.ChainID = r.ChainID
+		requirement.Contract = r.ContractAddress
+		requirement.TokenID = bigString(r.TokenID)
+	case *policy.ERC721MinBalanceRule:
+		requirement.ChainID = r.ChainID
+		requirement.Contract = r.ContractAddress
+		requirement.MinimumBalance = bigString(r.MinimumBalance)
+	case *policy.ERC721TraitRule:
+		requirement.ChainID = r.ChainID
+		requirement.Contract = r.ContractAddress
+		requirement.TokenID = bigString(r.TokenID)
+		requirement.Trait = &GateTrait{Type: r.TraitType, Value: r.TraitValue}
+	case *policy.StakedBalanceRule:
+		requirement.ChainID = r.ChainID
+		requirement.Contract = r.ContractAddress
+		requirement.Function = r.Function
+		requirement.MinimumBalance = bigString(r.MinimumBalance)
+	case *policy.VotingPowerRule:
+		requirement.ChainID = r.ChainID
+		requirement.Contract = r.ContractAddress
+		requirement.Function = r.Function()
+		requirement.MinimumBalance = bigString(r.MinimumVotes)
+		requirement.BlockNumber = r.BlockNumber
+	case *policy.ContractCallRule:
+		requirement.ChainID = r.ChainID
+		requirement.Contract = r.ContractAddress
+		requirement.Function = r.Function
+		requirement.Comparison = r.Comparison
+		requirement.Value = bigString(r.Value)
+	case *policy.LPPositionRule:
+		requirement.ChainID = r.ChainID
+		requirement.Contract = r.PoolAddress
+		requirement.MinimumBalance = bigString(r.MinimumLiquidity)
+	case *policy.HoldingDurationRule:
+		requirement.ChainID = r.ChainID
+		requirement.Contract = r.ContractAddress
+		requirement.MinimumBalance = bigString(r.MinimumBalance)
+		requirement.MinimumDays = r.MinimumDays
+	case *policy.CodeExistsRule:
+		requirement.ChainID = r.ChainID
+	case *policy.ContractDeployerRule:
+		requirement.ChainID = r.ChainID
+		requirement.Contract = r.ContractAddress
+	case *policy.AllOfRule:
+		requirement.Requirements = describeRequirements(r.Rules)
+	case *policy.AnyOfRule:
+		requirement.Requirements = describeRequirements(r.Rules)
+	case *policy.NotRule:
+		requirement.Requirements = describeRequirements([]policy.Rule{r.Rule})
+	}
+	return requirement
+}
+
+// bigString formats an optional integer, empty when unset
+func bigString(value *big.Int) string {
+	if value == nil {
+		return ""
+	}
+	return value.String()
+}
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/chain"
+	"github.com/yourusername/gatekeeper/internal/policy"
+)
+
+func TestGateDiscoveryHandler_GetGates(t *testing.T) {
+	const token = "0x1f9840a85d5af5bf1d1762f925bdaddc4201f984"
+	const nft = "0xbc4ca0eda7647a8ab7c2061c2e118a18a936f13d"
+
+	pm := policy.NewPolicyManager(nil, nil)
+	pm.AddPolicy(policy.NewPolicy("GET", "/api/holders", "AND", []policy.Rule{
+		policy.NewERC20MinBalanceRule(token, big.NewInt(1000), 1),
+		policy.NewInAllowlistRule([]string{"0x1234567890abcdef1234567890abcdef12345678"}),
+	}))
+	pm.AddPolicy(policy.NewPolicy("GET", "/api/apes", "OR", []policy.Rule{
+		policy.AnyOf(policy.NewERC721OwnerRule(nft, big.NewInt(42), 1), policy.NewERC721MinBalanceRule(nft, big.NewInt(3), 1)),
+		policy.Not(policy.NewCodeExistsRule(1)),
+	}))
+	pm.AddPolicy(policy.NewScorePolicy("GET", "/api/score", []policy.Rule{
+		policy.NewHasScopeRule("read"),
+		policy.NewHoldingDurationRule(token, big.NewInt(5), 30, 1),
+	}, []int{1, 2}, 2))
+	pm.AddPolicy(policy.NewTierPolicy("GET", "/api/tiered", []policy.Rule{
+		policy.NewHasScopeRule("gold"),
+		policy.NewHasScopeRule("silver"),
+	}, []string{"gold", "silver"}, "bronze"))
+	shadow := policy.NewPolicy("GET", "/api/beta", "AND", []policy.Rule{policy.NewHasScopeRule("beta")})
+	shadow.Shadow = true
+	pm.AddPolicy(shadow)
+
+	rec := httptest.NewRecorder()
+	NewGateDiscoveryHandler(pm).GetGates(rec, httptest.NewRequest("GET", "/.well-known/gatekeeper.json", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.NotContains(t, rec.Body.String(), "0x1234567890abcdef1234567890abcdef12345678")
+
+	var response GateDiscoveryResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Len(t, response.Gates, 4)
+
+	holders := response.Gates[0]
+	assert.Equal(t, "/api/holders", holders.Path)
+	assert.Equal(t, []GateRequirement{
+		{Type: "erc20_min_balance", ChainID: 1, Contract: token, MinimumBalance: "1000"},
+		{Type: "in_allowlist"},
+	}, holders.Requirements)
+
+	apes := response.Gates[1]
+	assert.Equal(t, "OR", apes.Logic)
+	require.Len(t, apes.Requirements, 2)
+	assert.Equal(t, []GateRequirement{
+		{Type: "erc721_owner", ChainID: 1, Contract: nft, TokenID: "42"},
+		{Type: "erc721_min_balance", ChainID: 1, Contract: nft, MinimumBalance: "3"},
+	}, apes.Requirements[0].Requirements)
+	assert.Equal(t, []GateRequirement{{Type: "code_exists", ChainID: 1}}, apes.Requirements[1].Requirements)
+
+	score := response.Gates[2]
+	assert.Equal(t, 2, score.Threshold)
+	assert.Equal(t, GateRequirement{Type: "has_scope", Scope: "read", Points: 1}, score.Requirements[0])
+	assert.Equal(t, GateRequirement{Type: "holding_duration", ChainID: 1, Contract: token, MinimumBalance: "5", MinimumDays: 30, Points: 2}, score.Requirements[1])
+
+	tiered := response.Gates[3]
+	assert.Equal(t, "TIER", tiered.Logic)
+	assert.Equal(t, "bronze", tiered.DefaultTier)
+	assert.Equal(t, []GateRequirement{
+		{Type: "has_scope", Scope: "gold", Tier: "gold"},
+		{Type: "has_scope", Scope: "silver", Tier: "silver"},
+	}, tiered.Requirements)
+}
+
+func TestGateDiscoveryHandler_Network(t *testing.T) {
+	handler := NewGateDiscoveryHandler(policy.NewPolicyManager(nil, nil))
+	handler.SetNetwork(chain.NetworkFor(11155111))
+	rec := httptest.NewRecorder()
+	handler.GetGates(rec, httptest.NewRequest("GET", "/.well-known/gatekeeper.json", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response GateDiscoveryResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	require.NotNil(t, response.Network)
+	assert.Equal(t, "sepolia", response.Network.Name)
+	assert.True(t, response.Network.Testnet)
+	assert.NotEmpty(t, response.Network.Faucets)
+}
+
+func TestGateDiscoveryHandler_NoPolicies(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewGateDiscoveryHandler(policy.NewPolicyManager(nil, nil)).GetGates(rec, httptest.NewRequest("GET", "/.well-known/gatekeeper.json", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"gates": []}`, rec.Body.String())
+}
@@ -1,27 +1,74 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/yourusername/gatekeeper/internal/audit"
 	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/log"
+	"github.com/yourusername/gatekeeper/internal/oidc"
+	"github.com/yourusername/gatekeeper/internal/store"
 )
 
 // AuthHandler handles authentication-related HTTP endpoints
 type AuthHandler struct {
 	siweService *auth.SIWEService
 	jwtService  *auth.JWTService
+	userRepo    store.UserRepositoryInterface
+	loginRepo   store.LoginRepositoryInterface   // Optional; when set, sign-ins are added to the login history
+	sessionRepo store.SessionRepositoryInterface // Optional; when set, sign-ins also return a refresh token
+	walletRepo  store.WalletRepositoryInterface  // Optional; when set, linked wallets sign in as their user
+	refreshTTL  time.Duration
+
+	oidcProvider *oidc.Provider // Optional; when set, applications can sign users in through OIDC
+
+	logger      log.Logger
+	auditLogger audit.AuditLogger
 }
 
-// NewAuthHandler creates a new authentication handler
-func NewAuthHandler(siweService *auth.SIWEService, jwtService *auth.JWTService) *AuthHandler {
+// NewAuthHandler creates a new authentication handler.
+// userRepo can be nil, in which case sign-ins are not recorded in the users table.
+func NewAuthHandler(siweService *auth.SIWEService, jwtService *auth.JWTService, userRepo store.UserRepositoryInterface, logger log.Logger, auditLogger audit.AuditLogger) *AuthHandler {
 	return &AuthHandler{
 		siweService: siweService,
 		jwtService:  jwtService,
+		userRepo:    userRepo,
+		logger:      logger,
+		auditLogger: auditLogger,
 	}
 }
 
+// SetLoginRepository records each sign-in in the login history
+func (h *AuthHandler) SetLoginRepository(loginRepo store.LoginRepositoryInterface) {
+	h.loginRepo = loginRepo
+}
+
+// SetSessionRepository issues a refresh token with each sign-in, valid for ttl
+// and rotated on every use
+func (h *AuthHandler) SetSessionRepository(sessionRepo store.SessionRepositoryInterface, ttl time.Duration) {
+	h.sessionRepo = sessionRepo
+	h.refreshTTL = ttl
+}
+
+// SetWalletRepository lets wallets linked to a user sign in as that user
+func (h *AuthHandler) SetWalletRepository(walletRepo store.WalletRepositoryInterface) {
+	h.walletRepo = walletRepo
+}
+
+// SetOIDCProvider serves the OpenID Connect provider endpoints, signing users in
+// with SIWE on the provider's sign-in page
+func (h *AuthHandler) SetOIDCProvider(provider *oidc.Provider) {
+	h.oidcProvider = provider
+}
+
 // GetNonce handles GET /auth/siwe/nonce
 // Returns a new nonce for SIWE message signing
 func (h *AuthHandler) GetNonce(w http.ResponseWriter, r *http.Request) {
@@ -53,8 +100,14 @@ type VerifyRequest struct {
 
 // VerifyResponse represents the response for successful verification
 type VerifyResponse struct {
-	Token   string `json:"token"`
-	Address string `json:"address"`
+	Token     string `json:"token"`
+	ExpiresIn int    `json:"expiresIn"` // Token lifetime in seconds
+	Address   string `json:"address"`
+	Wallet    string `json:"wallet,omitempty"` // Linked wallet that signed in, when not the user's own address
+
+	// Set when refresh tokens are enabled
+	RefreshToken     string `json:"refreshToken,omitempty"`
+	RefreshExpiresIn int    `json:"refreshExpiresIn,omitempty"` // Refresh token lifetime in seconds
 }
 
 // VerifySIWE handles POST /auth/siwe/verify
@@ -69,64 +122,553 @@ func (h *AuthHandler) VerifySIWE(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate required fields
-	if req.Message == "" || req.Signature == "" {
-		http.Error(w, "missing required fields: message and signature", http.StatusBadRequest)
+	signIn, signInErr := h.signIn(r, req.Message, req.Signature)
+	if signInErr != nil {
+		http.Error(w, signInErr.message, signInErr.status)
 		return
 	}
+	address, wallet, userID := signIn.address, signIn.wallet, signIn.userID
 
-	// Extract nonce from message
-	nonce, err := auth.ExtractNonceFromMessage(req.Message)
+	// Generate JWT token with auth scope and the scope of the user's role
+	token, err := h.jwtService.GenerateToken(ctx, address, sessionScopes(signIn.role))
 	if err != nil {
-		http.Error(w, "invalid SIWE message: nonce not found", http.StatusBadRequest)
+		http.Error(w, "failed to generate token", http.StatusInternalServerError)
 		return
 	}
 
+	response := VerifyResponse{
+		Token:     token,
+		ExpiresIn: int(h.jwtService.Expiry().Seconds()),
+		Address:   address,
+		Wallet:    wallet,
+	}
+
+	// Start a refresh token session; sessions belong to a user row
+	if h.sessionRepo != nil && userID != 0 {
+		refreshToken, _, err := h.sessionRepo.CreateSession(ctx, userID, address, h.refreshTTL)
+		if err != nil {
+			requestLogger(r, h.logger).WithFields(
+				log.Error(err),
+				log.Int64("user_id", userID),
+			).Error("failed to create session")
+			http.Error(w, "failed to create session", http.StatusInternalServerError)
+			return
+		}
+		response.RefreshToken = refreshToken
+		response.RefreshExpiresIn = int(h.refreshTTL.Seconds())
+	}
+
+	// Audit log: Successful sign-in
+	if h.auditLogger != nil {
+		metadata := map[string]interface{}{
+			"method": "siwe",
+		}
+		if wallet != "" {
+			metadata["wallet"] = wallet
+		}
+		h.auditLogger.LogAuthAttempt(ctx, withClientInfo(r, audit.AuditEvent{
+			Result:   audit.ResultSuccess,
+			UserAddr: address,
+			UserID:   userID,
+			Method:   r.Method,
+			Endpoint: r.URL.Path,
+			IPAddr:   r.RemoteAddr,
+			Metadata: metadata,
+		}))
+	}
+
+	// Return success response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// siweSignIn is a verified SIWE sign-in
+type siweSignIn struct {
+	address string // Address the user signs in as
+	wallet  string // Linked wallet that signed, when not the user's own address
+	userID  int64  // 0 when sign-ins are not recorded in the users table
+	role    string // Administrative role of the user, if any
+}
+
+// sessionScopes returns the scopes of a wallet session for a user holding role
+func sessionScopes(role string) []string {
+	if role == "" {
+		return []string{"auth"}
+	}
+	return []string{"auth", auth.Role(role).Scope()}
+}
+
+// signIn verifies a SIWE proof, lets linked wallets sign in as their user and
+// records the sign-in. Failures are returned with the status to answer them with.
+func (h *AuthHandler) signIn(r *http.Request, message, signature string) (*siweSignIn, *siweProofError) {
+	ctx := r.Context()
+
+	address, proofErr := verifySIWEProof(ctx, h.siweService, message, signature)
+	if proofErr != nil {
+		return nil, proofErr
+	}
+	signIn := &siweSignIn{address: address}
+
+	// A linked wallet signs in as the user it belongs to
+	if h.walletRepo != nil && h.userRepo != nil {
+		ownerID, err := h.walletRepo.GetWalletOwner(ctx, address)
+		switch {
+		case err == nil:
+			owner, err := h.userRepo.GetUserByID(ctx, ownerID)
+			if err != nil {
+				requestLogger(r, h.logger).WithFields(
+					log.Error(err),
+					log.Int64("user_id", ownerID),
+				).Error("failed to load owner of linked wallet")
+				return nil, &siweProofError{http.StatusInternalServerError, "failed to load user"}
+			}
+			signIn.wallet, signIn.address = address, owner.Address
+		case !errors.Is(err, store.ErrNotFound):
+			requestLogger(r, h.logger).WithFields(
+				log.Error(err),
+				log.String("address", address),
+			).Error("failed to look up linked wallet")
+			return nil, &siweProofError{http.StatusInternalServerError, "failed to load user"}
+		}
+	}
+
+	// Materialize the user on first sign-in and record the login
+	if h.userRepo != nil {
+		user, err := h.userRepo.GetOrCreateUserByAddress(ctx, signIn.address)
+		if err != nil {
+			requestLogger(r, h.logger).WithFields(
+				log.Error(err),
+				log.String("address", signIn.address),
+			).Error("failed to get or create user")
+			return nil, &siweProofError{http.StatusInternalServerError, "failed to load user"}
+		}
+		signIn.userID, signIn.role = user.ID, user.Role
+
+		if err := h.userRepo.RecordLogin(ctx, user.ID); err != nil {
+			// The sign-in is valid; a missing timestamp must not block it
+			requestLogger(r, h.logger).WithFields(
+				log.Error(err),
+				log.Int64("user_id", user.ID),
+			).Warn("failed to record login")
+		}
+
+		if h.loginRepo != nil {
+			h.recordLoginHistory(r, user.ID, message)
+		}
+	}
+
+	return signIn, nil
+}
+
+// siweProofError is a rejected SIWE proof or failed sign-in with the status to
+// answer it with
+type siweProofError struct {
+	status  int
+	message string
+}
+
+// verifySIWEProof checks a signed SIWE message: its nonce must be valid and the
+// signature must come from the address in the message. The nonce is consumed so
+// the proof cannot be replayed. Returns the lowercase signing address.
+func verifySIWEProof(ctx context.Context, siweService *auth.SIWEService, message, signature string) (string, *siweProofError) {
+	// Validate required fields
+	if message == "" || signature == "" {
+		return "", &siweProofError{http.StatusBadRequest, "missing required fields: message and signature"}
+	}
+
+	// Extract nonce from message
+	nonce, err := auth.ExtractNonceFromMessage(message)
+	if err != nil {
+		return "", &siweProofError{http.StatusBadRequest, "invalid SIWE message: nonce not found"}
+	}
+
 	// Verify nonce exists and is valid
-	valid, err := h.siweService.VerifyNonce(ctx, nonce)
+	valid, err := siweService.VerifyNonce(ctx, nonce)
 	if err != nil || !valid {
-		http.Error(w, "invalid or expired nonce", http.StatusUnauthorized)
-		return
+		return "", &siweProofError{http.StatusUnauthorized, "invalid or expired nonce"}
 	}
 
 	// Extract address from message
-	address, err := auth.ExtractAddressFromMessage(req.Message)
+	address, err := auth.ExtractAddressFromMessage(message)
 	if err != nil {
-		http.Error(w, "invalid SIWE message: address not found", http.StatusBadRequest)
-		return
+		return "", &siweProofError{http.StatusBadRequest, "invalid SIWE message: address not found"}
 	}
 
 	// Verify signature matches message + address
-	validSig, err := auth.VerifySignature(req.Message, req.Signature, address)
+	validSig, err := auth.VerifySignature(message, signature, address)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("signature verification error: %v", err), http.StatusBadRequest)
-		return
+		return "", &siweProofError{http.StatusBadRequest, fmt.Sprintf("signature verification error: %v", err)}
 	}
 	if !validSig {
-		http.Error(w, "invalid signature", http.StatusUnauthorized)
-		return
+		return "", &siweProofError{http.StatusUnauthorized, "invalid signature"}
 	}
 
 	// Invalidate nonce to prevent replay attacks
-	if err := h.siweService.InvalidateNonce(ctx, nonce); err != nil {
+	if err := siweService.InvalidateNonce(ctx, nonce); err != nil {
 		// Log error but continue - nonce validation already passed
 		// This shouldn't normally happen
 	}
 
-	// Generate JWT token with auth scope
-	token, err := h.jwtService.GenerateToken(ctx, address, []string{"auth"})
+	return address, nil
+}
+
+// RefreshRequest represents the request body for token refresh and logout
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// Refresh handles POST /auth/refresh
+// Exchanges a refresh token for a new access token and a new refresh token.
+// Each refresh token works once; presenting a used one again ends the session.
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := requestLogger(r, h.logger)
+
+	if h.sessionRepo == nil {
+		http.Error(w, "refresh tokens are not enabled", http.StatusNotFound)
+		return
+	}
+
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.RefreshToken == "" {
+		http.Error(w, "missing required field: refreshToken", http.StatusBadRequest)
+		return
+	}
+
+	refreshToken, session, err := h.sessionRepo.RotateSession(ctx, req.RefreshToken, h.refreshTTL)
+	if err != nil {
+		var revokedErr *store.RevokedError
+		switch {
+		case errors.As(err, &revokedErr):
+			// A rotated token came back: someone else holds a copy of it
+			logger.Warn("refresh token reused, session revoked", log.Any("session_id", revokedErr.ID))
+			if h.auditLogger != nil {
+				h.auditLogger.Log(ctx, withClientInfo(r, audit.AuditEvent{
+					Action:   audit.ActionSessionRevoked,
+					Result:   audit.ResultFailure,
+					Method:   r.Method,
+					Endpoint: r.URL.Path,
+					Error:    "refresh token reused",
+					Metadata: map[string]interface{}{
+						"reason": "reuse",
+					},
+				}))
+			}
+			http.Error(w, "invalid refresh token", http.StatusUnauthorized)
+		case errors.Is(err, store.ErrNotFound), errors.Is(err, store.ErrExpired):
+			http.Error(w, "invalid refresh token", http.StatusUnauthorized)
+		default:
+			logger.Error("failed to rotate session", log.Error(err))
+			http.Error(w, "failed to refresh session", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// Roles are read again so a change applies from the next refresh
+	var role string
+	if h.userRepo != nil && session.UserID != 0 {
+		user, err := h.userRepo.GetUserByID(ctx, session.UserID)
+		if err != nil {
+			logger.Error("failed to load session user", log.Int64("user_id", session.UserID), log.Error(err))
+			http.Error(w, "failed to refresh session", http.StatusInternalServerError)
+			return
+		}
+		role = user.Role
+	}
+
+	token, err := h.jwtService.GenerateToken(ctx, session.Address, sessionScopes(role))
 	if err != nil {
 		http.Error(w, "failed to generate token", http.StatusInternalServerError)
 		return
 	}
 
-	// Return success response
+	if h.auditLogger != nil {
+		h.auditLogger.Log(ctx, withClientInfo(r, audit.AuditEvent{
+			Action:   audit.ActionSessionRefreshed,
+			Result:   audit.ResultSuccess,
+			UserAddr: session.Address,
+			UserID:   session.UserID,
+			Method:   r.Method,
+			Endpoint: r.URL.Path,
+		}))
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(VerifyResponse{
+		Token:            token,
+		ExpiresIn:        int(h.jwtService.Expiry().Seconds()),
+		Address:          session.Address,
+		RefreshToken:     refreshToken,
+		RefreshExpiresIn: int(h.refreshTTL.Seconds()),
+	})
+}
 
-	response := VerifyResponse{
-		Token:   token,
-		Address: address,
+// Logout handles POST /auth/logout
+// Revokes the access token presented in the Authorization header until it expires
+// and, when a refresh token is sent, its session including every token rotated
+// from it. At least one of the two is required.
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := requestLogger(r, h.logger)
+
+	// The body is optional when only the access token is revoked
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	accessToken := bearerToken(r)
+	if accessToken == "" && req.RefreshToken == "" {
+		http.Error(w, "missing access token or refreshToken", http.StatusBadRequest)
+		return
+	}
+	if accessToken != "" && !h.jwtService.RevocationEnabled() {
+		http.Error(w, "token revocation is not enabled", http.StatusNotFound)
+		return
+	}
+	if req.RefreshToken != "" && h.sessionRepo == nil {
+		http.Error(w, "refresh tokens are not enabled", http.StatusNotFound)
+		return
+	}
+
+	var claims *auth.Claims
+	if accessToken != "" {
+		var err error
+		claims, err = h.jwtService.VerifyToken(ctx, accessToken)
+		if err != nil {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		if claims.ID == "" {
+			http.Error(w, "token has no ID and cannot be revoked", http.StatusBadRequest)
+			return
+		}
+		if err := h.jwtService.RevokeToken(ctx, claims); err != nil {
+			logger.Error("failed to revoke access token", log.Error(err))
+			http.Error(w, "failed to revoke token", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	sessionRevoked := false
+	if req.RefreshToken != "" {
+		if err := h.sessionRepo.RevokeSession(ctx, req.RefreshToken); err != nil {
+			// Logging out of an unknown session has nothing left to do
+			if !errors.Is(err, store.ErrNotFound) {
+				logger.Error("failed to revoke session", log.Error(err))
+				http.Error(w, "failed to revoke session", http.StatusInternalServerError)
+				return
+			}
+		} else {
+			sessionRevoked = true
+		}
+	}
+
+	if h.auditLogger != nil && (claims != nil || sessionRevoked) {
+		event := audit.AuditEvent{
+			Action:   audit.ActionSessionRevoked,
+			Result:   audit.ResultSuccess,
+			Method:   r.Method,
+			Endpoint: r.URL.Path,
+			Metadata: map[string]interface{}{
+				"reason":        "logout",
+				"access_token":  claims != nil,
+				"refresh_token": sessionRevoked,
+			},
+		}
+		if claims != nil {
+			event.UserAddr = claims.Address
+			event.ResourceID = claims.ID
+		}
+		h.auditLogger.Log(ctx, withClientInfo(r, event))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// bearerToken returns the token of a "Bearer <token>" Authorization header, or
+// an empty string without one
+func bearerToken(r *http.Request) string {
+	token, _ := parseBearerToken(r.Header.Get("Authorization"))
+	return token
+}
+
+// JWKS handles GET /.well-known/jwks.json
+// Publishes the public keys other services use to verify gatekeeper tokens.
+// The key set is empty when tokens are signed with the HS256 secret.
+func (h *AuthHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if err := json.NewEncoder(w).Encode(h.jwtService.JWKS()); err != nil {
+		requestLogger(r, h.logger).Error("failed to encode key set", log.Error(err))
+	}
+}
+
+// recordLoginHistory adds a sign-in to the user's login history. Failures are
+// logged only, since the sign-in itself is valid.
+func (h *AuthHandler) recordLoginHistory(r *http.Request, userID int64, message string) {
+	login := &store.Login{
+		UserID:    userID,
+		IPAddress: extractIP(r),
+		UserAgent: r.UserAgent(),
+	}
+	if chainID, err := auth.ExtractChainIDFromMessage(message); err == nil && chainID <= math.MaxInt64 {
+		id := int64(chainID)
+		login.ChainID = &id
+	}
+
+	if err := h.loginRepo.CreateLogin(r.Context(), login); err != nil {
+		requestLogger(r, h.logger).WithFields(
+			log.Error(err),
+			log.Int64("user_id", userID),
+		).Warn("failed to record login history")
+	}
+}
+
+// defaultDownscopeTTL is the lifetime of a downscoped token when none is requested
+const defaultDownscopeTTL = 15 * time.Minute
+
+// DownscopeRequest represents the request body for token downscoping
+type DownscopeRequest struct {
+	Scopes     []string `json:"scopes"`
+	TTLSeconds int64    `json:"ttlSeconds,omitempty"`
+}
+
+// DownscopeResponse represents the response for a downscoped token
+type DownscopeResponse struct {
+	Token     string    `json:"token"`
+	Scopes    []string  `json:"scopes"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Downscope handles POST /auth/downscope
+// Exchanges the caller's JWT for a shorter-lived token with a subset of its scopes,
+// suitable for handing to third-party widgets
+func (h *AuthHandler) Downscope(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Only JWT sessions can be downscoped; API keys are long-lived credentials
+	claims := ClaimsFromContext(r)
+	if claims == nil || AuthMethodFromContext(r) != AuthMethodJWT {
+		http.Error(w, "a valid bearer token is required", http.StatusUnauthorized)
+		return
+	}
+	// Tokens are only derived from sessions, never from other derived tokens
+	if claims.Derived() {
+		writeSessionRequired(w, r)
+		return
+	}
+
+	var req DownscopeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Scopes == nil {
+		req.Scopes = []string{}
+	}
+
+	ttl := defaultDownscopeTTL
+	if req.TTLSeconds < 0 {
+		http.Error(w, "ttlSeconds must be positive", http.StatusBadRequest)
+		return
+	}
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+
+	token, expiresAt, err := h.jwtService.DownscopeToken(ctx, claims, req.Scopes, ttl)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	response := DownscopeResponse{
+		Token:     token,
+		Scopes:    req.Scopes,
+		ExpiresAt: expiresAt,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// Lifetime limits for capability tokens
+const (
+	defaultCapabilityTTL = 10 * time.Minute
+	maxCapabilityTTL     = time.Hour
+)
+
+// CapabilityRequest represents the request body for minting a capability token
+type CapabilityRequest struct {
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	TTLSeconds int64  `json:"ttlSeconds,omitempty"`
+}
+
+// CapabilityResponse represents a capability token bound to a single endpoint
+type CapabilityResponse struct {
+	Token     string    `json:"token"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// CreateCapability handles POST /auth/capability
+// Mints a token that is only valid for one method on one path, e.g. POST /api/claim
+func (h *AuthHandler) CreateCapability(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	claims := ClaimsFromContext(r)
+	if claims == nil || AuthMethodFromContext(r) != AuthMethodJWT {
+		http.Error(w, "a valid bearer token is required", http.StatusUnauthorized)
+		return
+	}
+	// Tokens are only derived from sessions, never from other derived tokens
+	if claims.Derived() {
+		writeSessionRequired(w, r)
+		return
+	}
+
+	var req CapabilityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ttl := defaultCapabilityTTL
+	if req.TTLSeconds != 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+	if ttl <= 0 || ttl > maxCapabilityTTL {
+		http.Error(w, "ttlSeconds must be between 1 and 3600", http.StatusBadRequest)
+		return
+	}
+
+	token, expiresAt, err := h.jwtService.GenerateCapabilityToken(ctx, claims, req.Method, req.Path, ttl)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	response := CapabilityResponse{
+		Token:     token,
+		Method:    strings.ToUpper(req.Method),
+		Path:      req.Path,
+		ExpiresAt: expiresAt,
 	}
 	json.NewEncoder(w).Encode(response)
 }
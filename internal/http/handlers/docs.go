@@ -3,6 +3,7 @@ package handlers
 import (
 	_ "embed"
 	"net/http"
+	"time"
 )
 
 // Embed the OpenAPI specification file from the handlers directory
@@ -10,11 +11,14 @@ import (
 var openapiSpec []byte
 
 // DocsHandler handles documentation-related HTTP endpoints
-type DocsHandler struct{}
+type DocsHandler struct {
+	// The spec is embedded at build time, so it cannot have changed since startup
+	specModified time.Time
+}
 
 // NewDocsHandler creates a new documentation handler
 func NewDocsHandler() *DocsHandler {
-	return &DocsHandler{}
+	return &DocsHandler{specModified: time.Now().UTC()}
 }
 
 // ServeOpenAPISpec handles GET /openapi.yaml
@@ -34,6 +38,7 @@ func (h *DocsHandler) ServeOpenAPISpec(w http.ResponseWriter, r *http.Request) {
 	// Set content type for YAML
 	w.Header().Set("Content-Type", "application/yaml; charset=utf-8")
 	w.Header().Set("Cache-Control", "public, max-age=3600") // Cache for 1 hour
+	w.Header().Set("Last-Modified", h.specModified.Format(http.TimeFormat))
 
 	// Write the embedded OpenAPI spec
 	w.WriteHeader(http.StatusOK)
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/yourusername/gatekeeper/internal/chain"
@@ -19,6 +20,14 @@ type HealthHandler struct {
 	logger   *zap.Logger
 	startTime time.Time
 	version  string
+	dbMonitor *store.HealthMonitor
+	migrations MigrationChecker // Optional: readiness waits for the schema to catch up
+	migrated   atomic.Bool      // Every migration was found applied
+}
+
+// MigrationChecker reports which embedded migrations the database schema reflects
+type MigrationChecker interface {
+	MigrationStatus(ctx context.Context) ([]store.MigrationState, error)
 }
 
 // NewHealthHandler creates a new health check handler
@@ -32,6 +41,19 @@ func NewHealthHandler(db *store.DB, provider *chain.Provider, logger *zap.Logger
 	}
 }
 
+// SetDatabaseMonitor makes readiness follow the monitor's view of the database
+// instead of querying it on every probe
+func (h *HealthHandler) SetDatabaseMonitor(monitor *store.HealthMonitor) {
+	h.dbMonitor = monitor
+}
+
+// SetMigrationChecker makes readiness wait until the database schema reflects every
+// migration this build embeds, e.g. while another replica is migrating it. Once
+// they are all applied the schema is not checked again.
+func (h *HealthHandler) SetMigrationChecker(migrations MigrationChecker) {
+	h.migrations = migrations
+}
+
 // HealthStatus represents the overall health status
 type HealthStatus string
 
@@ -131,14 +153,33 @@ func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	// Check database - critical dependency
-	dbHealth := h.checkDatabase(ctx)
-	if dbHealth.Status == StatusDown {
+	var databaseDown bool
+	if h.dbMonitor != nil {
+		databaseDown = !h.dbMonitor.Available()
+	} else {
+		databaseDown = h.checkDatabase(ctx).Status == StatusDown
+	}
+	if databaseDown {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusServiceUnavailable)
 		fmt.Fprintf(w, `{"status":"not_ready","reason":"database_down"}`)
 		return
 	}
 
+	// Check the schema is migrated - repositories fail on missing tables and columns
+	if h.migrations != nil && !h.migrated.Load() {
+		if reason, pending := h.checkMigrations(ctx); reason != "" {
+			response := map[string]interface{}{"status": "not_ready", "reason": reason}
+			if len(pending) > 0 {
+				response["pending"] = pending
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+	}
+
 	// Check Ethereum RPC if configured - critical dependency
 	if h.provider != nil {
 		ethHealth := h.checkEthereum(ctx)
@@ -156,6 +197,27 @@ func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprint(w, `{"status":"ready"}`)
 }
 
+// checkMigrations returns why the schema is not ready, with the migrations not
+// applied yet, or an empty reason once every migration is applied
+func (h *HealthHandler) checkMigrations(ctx context.Context) (string, []string) {
+	states, err := h.migrations.MigrationStatus(ctx)
+	if err != nil {
+		h.logger.Error("migration status check failed", zap.Error(err))
+		return "migration_status_unavailable", nil
+	}
+	var pending []string
+	for _, state := range states {
+		if !state.Applied {
+			pending = append(pending, state.Name)
+		}
+	}
+	if len(pending) > 0 {
+		return "migrations_pending", pending
+	}
+	h.migrated.Store(true)
+	return "", nil
+}
+
 // checkDatabase performs database health check
 func (h *HealthHandler) checkDatabase(ctx context.Context) *ComponentHealth {
 	start := time.Now()
@@ -223,6 +223,66 @@ func TestHealthHandler_Ready(t *testing.T) {
 		// This test demonstrates that the handler manages timeouts independently
 		assert.Equal(t, http.StatusOK, w.Code)
 	})
+
+	t.Run("not ready when monitor reports database down", func(t *testing.T) {
+		db := setupTestDB(t)
+
+		handler := NewHealthHandler(db, nil, logger, "1.0.0")
+		monitor := store.NewHealthMonitor(db, 10*time.Millisecond, nil)
+		handler.SetDatabaseMonitor(monitor)
+
+		req := httptest.NewRequest("GET", "/health/ready", nil)
+		w := httptest.NewRecorder()
+		handler.Ready(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		// Closing the pool makes every check fail
+		db.Close()
+		monitor.Start()
+		defer monitor.Stop()
+		require.Eventually(t, func() bool { return !monitor.Available() }, time.Second, 10*time.Millisecond)
+
+		w = httptest.NewRecorder()
+		handler.Ready(w, req)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Contains(t, w.Body.String(), `"reason":"database_down"`)
+	})
+}
+
+// fakeMigrationChecker reports the given migration states
+type fakeMigrationChecker struct {
+	states []store.MigrationState
+	calls  int
+}
+
+func (c *fakeMigrationChecker) MigrationStatus(ctx context.Context) ([]store.MigrationState, error) {
+	c.calls++
+	return c.states, nil
+}
+
+func TestHealthHandler_Ready_Migrations(t *testing.T) {
+	db := setupTestDB(t)
+	handler := NewHealthHandler(db, nil, zap.NewNop(), "1.0.0")
+	migrations := &fakeMigrationChecker{states: []store.MigrationState{
+		{Name: "001_create_users_table.sql", Applied: true},
+		{Name: "002_create_nonces_table.sql", Missing: []string{"nonces"}},
+	}}
+	handler.SetMigrationChecker(migrations)
+
+	req := httptest.NewRequest("GET", "/health/ready", nil)
+	w := httptest.NewRecorder()
+	handler.Ready(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.JSONEq(t, `{"status":"not_ready","reason":"migrations_pending","pending":["002_create_nonces_table.sql"]}`, w.Body.String())
+
+	// Once migrated, the schema is not checked again
+	migrations.states[1] = store.MigrationState{Name: "002_create_nonces_table.sql", Applied: true}
+	for i := 0; i < 2; i++ {
+		w = httptest.NewRecorder()
+		handler.Ready(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+	assert.Equal(t, 2, migrations.calls)
 }
 
 func TestHealthHandler_GetStats(t *testing.T) {
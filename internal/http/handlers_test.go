@@ -3,15 +3,30 @@ package http
 import (
 	"bytes"
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/audit"
 	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/store"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 // Mock SIWEVerifier for testing
@@ -30,7 +45,7 @@ func (m *mockSIWEVerifier) VerifyMessage(ctx context.Context, message, signature
 func TestGetNonce_ReturnsNonce(t *testing.T) {
 	siweService := auth.NewSIWEService(5 * time.Minute)
 	jwtService := auth.NewJWTService([]byte("secret"), 24*time.Hour)
-	handler := NewAuthHandler(siweService, jwtService)
+	handler := NewAuthHandler(siweService, jwtService, nil, nil, nil)
 
 	req := httptest.NewRequest("GET", "/auth/siwe/nonce", nil)
 	rec := httptest.NewRecorder()
@@ -51,7 +66,7 @@ func TestGetNonce_ReturnsNonce(t *testing.T) {
 func TestGetNonce_ReturnsDifferentNonces(t *testing.T) {
 	siweService := auth.NewSIWEService(5 * time.Minute)
 	jwtService := auth.NewJWTService([]byte("secret"), 24*time.Hour)
-	handler := NewAuthHandler(siweService, jwtService)
+	handler := NewAuthHandler(siweService, jwtService, nil, nil, nil)
 
 	// Get first nonce
 	req1 := httptest.NewRequest("GET", "/auth/siwe/nonce", nil)
@@ -78,7 +93,7 @@ func TestGetNonce_ReturnsDifferentNonces(t *testing.T) {
 func TestVerifySIWE_WithValidSignature(t *testing.T) {
 	siweService := auth.NewSIWEService(5 * time.Minute)
 	jwtService := auth.NewJWTService([]byte("secret"), 24*time.Hour)
-	handler := NewAuthHandler(siweService, jwtService)
+	handler := NewAuthHandler(siweService, jwtService, nil, nil, nil)
 
 	// Generate nonce
 	nonce, _ := siweService.GenerateNonce(context.Background())
@@ -113,7 +128,7 @@ func TestVerifySIWE_WithValidSignature(t *testing.T) {
 func TestVerifySIWE_WithInvalidJSON(t *testing.T) {
 	siweService := auth.NewSIWEService(5 * time.Minute)
 	jwtService := auth.NewJWTService([]byte("secret"), 24*time.Hour)
-	handler := NewAuthHandler(siweService, jwtService)
+	handler := NewAuthHandler(siweService, jwtService, nil, nil, nil)
 
 	req := httptest.NewRequest("POST", "/auth/siwe/verify", bytes.NewReader([]byte("invalid json")))
 	req.Header.Set("Content-Type", "application/json")
@@ -128,7 +143,7 @@ func TestVerifySIWE_WithInvalidJSON(t *testing.T) {
 func TestVerifySIWE_WithMissingNonce(t *testing.T) {
 	siweService := auth.NewSIWEService(5 * time.Minute)
 	jwtService := auth.NewJWTService([]byte("secret"), 24*time.Hour)
-	handler := NewAuthHandler(siweService, jwtService)
+	handler := NewAuthHandler(siweService, jwtService, nil, nil, nil)
 
 	requestBody := map[string]string{
 		"message":   "example.com wants you to sign in...",
@@ -150,7 +165,7 @@ func TestVerifySIWE_WithMissingNonce(t *testing.T) {
 func TestVerifySIWE_WithInvalidNonce(t *testing.T) {
 	siweService := auth.NewSIWEService(5 * time.Minute)
 	jwtService := auth.NewJWTService([]byte("secret"), 24*time.Hour)
-	handler := NewAuthHandler(siweService, jwtService)
+	handler := NewAuthHandler(siweService, jwtService, nil, nil, nil)
 
 	requestBody := map[string]string{
 		"nonce":      "invalid-nonce-that-does-not-exist",
@@ -173,7 +188,7 @@ func TestVerifySIWE_WithInvalidNonce(t *testing.T) {
 func TestVerifySIWE_WithExpiredNonce(t *testing.T) {
 	siweService := auth.NewSIWEService(1 * time.Millisecond)
 	jwtService := auth.NewJWTService([]byte("secret"), 24*time.Hour)
-	handler := NewAuthHandler(siweService, jwtService)
+	handler := NewAuthHandler(siweService, jwtService, nil, nil, nil)
 
 	nonce, _ := siweService.GenerateNonce(context.Background())
 
@@ -201,7 +216,7 @@ func TestVerifySIWE_WithExpiredNonce(t *testing.T) {
 func TestVerifySIWE_InvalidatesNonceAfterUse(t *testing.T) {
 	siweService := auth.NewSIWEService(5 * time.Minute)
 	jwtService := auth.NewJWTService([]byte("secret"), 24*time.Hour)
-	handler := NewAuthHandler(siweService, jwtService)
+	handler := NewAuthHandler(siweService, jwtService, nil, nil, nil)
 
 	nonce, _ := siweService.GenerateNonce(context.Background())
 	address := "0x742d35Cc6634C0532925a3b844Bc390e38f3dF8c"
@@ -233,7 +248,7 @@ func TestVerifySIWE_InvalidatesNonceAfterUse(t *testing.T) {
 func TestVerifySIWE_ResponseContainsToken(t *testing.T) {
 	siweService := auth.NewSIWEService(5 * time.Minute)
 	jwtService := auth.NewJWTService([]byte("secret"), 24*time.Hour)
-	handler := NewAuthHandler(siweService, jwtService)
+	handler := NewAuthHandler(siweService, jwtService, nil, nil, nil)
 
 	nonce, _ := siweService.GenerateNonce(context.Background())
 	address := "0x742d35Cc6634C0532925a3b844Bc390e38f3dF8c"
@@ -265,7 +280,7 @@ func TestVerifySIWE_ResponseContainsToken(t *testing.T) {
 func TestVerifySIWE_ResponseContainsAddress(t *testing.T) {
 	siweService := auth.NewSIWEService(5 * time.Minute)
 	jwtService := auth.NewJWTService([]byte("secret"), 24*time.Hour)
-	handler := NewAuthHandler(siweService, jwtService)
+	handler := NewAuthHandler(siweService, jwtService, nil, nil, nil)
 
 	nonce, _ := siweService.GenerateNonce(context.Background())
 	address := "0x742d35Cc6634C0532925a3b844Bc390e38f3dF8c"
@@ -289,3 +304,523 @@ func TestVerifySIWE_ResponseContainsAddress(t *testing.T) {
 
 	assert.Equal(t, address, response["address"])
 }
+
+// downscopeRequest builds a downscope request authenticated as the given claims
+func downscopeRequest(t *testing.T, claims *auth.Claims, body DownscopeRequest) *http.Request {
+	t.Helper()
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "/auth/downscope", bytes.NewReader(payload))
+	ctx := context.WithValue(req.Context(), ClaimsContextKey, claims)
+	ctx = context.WithValue(ctx, AuthMethodContextKey, AuthMethodJWT)
+	return req.WithContext(ctx)
+}
+
+// TestDownscope_IssuesNarrowerToken verifies a subset of scopes is issued with the requested TTL
+func TestDownscope_IssuesNarrowerToken(t *testing.T) {
+	jwtService := auth.NewJWTService([]byte("secret"), 24*time.Hour)
+	handler := NewAuthHandler(auth.NewSIWEService(5*time.Minute), jwtService, nil, nil, nil)
+
+	parentToken, err := jwtService.GenerateToken(context.Background(), "0x1234567890123456789012345678901234567890", []string{"read", "write"})
+	require.NoError(t, err)
+	parent, err := jwtService.VerifyToken(context.Background(), parentToken)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	handler.Downscope(rec, downscopeRequest(t, parent, DownscopeRequest{Scopes: []string{"read"}, TTLSeconds: 60}))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+
+	var response DownscopeResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, []string{"read"}, response.Scopes)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), response.ExpiresAt, 2*time.Second)
+
+	claims, err := jwtService.VerifyToken(context.Background(), response.Token)
+	require.NoError(t, err)
+	assert.Equal(t, parent.Address, claims.Address)
+	assert.Equal(t, []string{"read"}, claims.Scopes)
+}
+
+// TestDownscope_RejectsScopeEscalation verifies scopes outside the parent token are refused
+func TestDownscope_RejectsScopeEscalation(t *testing.T) {
+	jwtService := auth.NewJWTService([]byte("secret"), 24*time.Hour)
+	handler := NewAuthHandler(auth.NewSIWEService(5*time.Minute), jwtService, nil, nil, nil)
+	parent := &auth.Claims{Address: "0x1234567890123456789012345678901234567890", Scopes: []string{"read"}}
+
+	rec := httptest.NewRecorder()
+	handler.Downscope(rec, downscopeRequest(t, parent, DownscopeRequest{Scopes: []string{"admin"}}))
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+// TestDownscope_RejectsAPIKeyCallers verifies only JWT sessions can be downscoped
+func TestDownscope_RejectsAPIKeyCallers(t *testing.T) {
+	jwtService := auth.NewJWTService([]byte("secret"), 24*time.Hour)
+	handler := NewAuthHandler(auth.NewSIWEService(5*time.Minute), jwtService, nil, nil, nil)
+	parent := &auth.Claims{Address: "0x1234567890123456789012345678901234567890", Scopes: []string{"read"}}
+
+	req := downscopeRequest(t, parent, DownscopeRequest{Scopes: []string{"read"}})
+	req = req.WithContext(context.WithValue(req.Context(), AuthMethodContextKey, AuthMethodAPIKey))
+	rec := httptest.NewRecorder()
+	handler.Downscope(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+// TestDerivedTokensCannotMintTokens verifies downscoped tokens are neither downscoped
+// again nor exchanged for capability tokens
+func TestDerivedTokensCannotMintTokens(t *testing.T) {
+	jwtService := auth.NewJWTService([]byte("secret"), 24*time.Hour)
+	handler := NewAuthHandler(auth.NewSIWEService(5*time.Minute), jwtService, nil, nil, nil)
+	derived := &auth.Claims{Address: "0x1234567890123456789012345678901234567890", Scopes: []string{"read"}, ParentID: "session-1"}
+
+	rec := httptest.NewRecorder()
+	handler.Downscope(rec, downscopeRequest(t, derived, DownscopeRequest{Scopes: []string{"read"}}))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), ProblemTypeSessionRequired)
+
+	body, _ := json.Marshal(CapabilityRequest{Method: "POST", Path: "/api/claim"})
+	req := httptest.NewRequest("POST", "/auth/capability", bytes.NewReader(body))
+	ctx := context.WithValue(req.Context(), ClaimsContextKey, derived)
+	ctx = context.WithValue(ctx, AuthMethodContextKey, AuthMethodJWT)
+	rec = httptest.NewRecorder()
+	handler.CreateCapability(rec, req.WithContext(ctx))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), ProblemTypeSessionRequired)
+}
+
+// TestCreateCapability_IssuesEndpointBoundToken verifies a capability token is minted for the requested endpoint
+func TestCreateCapability_IssuesEndpointBoundToken(t *testing.T) {
+	jwtService := auth.NewJWTService([]byte("secret"), 24*time.Hour)
+	handler := NewAuthHandler(auth.NewSIWEService(5*time.Minute), jwtService, nil, nil, nil)
+	parent := &auth.Claims{Address: "0x1234567890123456789012345678901234567890", Scopes: []string{"read"}}
+
+	body, _ := json.Marshal(CapabilityRequest{Method: "post", Path: "/api/claim"})
+	req := httptest.NewRequest("POST", "/auth/capability", bytes.NewReader(body))
+	ctx := context.WithValue(req.Context(), ClaimsContextKey, parent)
+	ctx = context.WithValue(ctx, AuthMethodContextKey, AuthMethodJWT)
+	rec := httptest.NewRecorder()
+
+	handler.CreateCapability(rec, req.WithContext(ctx))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response CapabilityResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, "POST", response.Method)
+	assert.Equal(t, "/api/claim", response.Path)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), response.ExpiresAt, 2*time.Second)
+
+	claims, err := jwtService.VerifyCapabilityToken(context.Background(), response.Token, "POST", "/api/claim")
+	require.NoError(t, err)
+	assert.Equal(t, parent.Address, claims.Address)
+}
+
+// TestCreateCapability_RejectsLongTTL verifies capability tokens are short-lived
+func TestCreateCapability_RejectsLongTTL(t *testing.T) {
+	jwtService := auth.NewJWTService([]byte("secret"), 24*time.Hour)
+	handler := NewAuthHandler(auth.NewSIWEService(5*time.Minute), jwtService, nil, nil, nil)
+	parent := &auth.Claims{Address: "0x1234567890123456789012345678901234567890"}
+
+	body, _ := json.Marshal(CapabilityRequest{Method: "POST", Path: "/api/claim", TTLSeconds: 7200})
+	req := httptest.NewRequest("POST", "/auth/capability", bytes.NewReader(body))
+	ctx := context.WithValue(req.Context(), ClaimsContextKey, parent)
+	ctx = context.WithValue(ctx, AuthMethodContextKey, AuthMethodJWT)
+	rec := httptest.NewRecorder()
+
+	handler.CreateCapability(rec, req.WithContext(ctx))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+// signSIWEMessage returns a SIWE message for a fresh nonce signed by a new key, and the key's address
+func signSIWEMessage(t *testing.T, siweService *auth.SIWEService) (message, signature, address string) {
+	t.Helper()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	address = strings.ToLower(crypto.PubkeyToAddress(key.PublicKey).Hex())
+
+	nonce, err := siweService.GenerateNonce(context.Background())
+	require.NoError(t, err)
+	message = "example.com wants you to sign in with your Ethereum account:\n" + address + "\n\nURI: https://example.com\nVersion: 1\nChain ID: 1\nNonce: " + nonce
+
+	hash := crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)))
+	sig, err := crypto.Sign(hash, key)
+	require.NoError(t, err)
+	sig[64] += 27
+	return message, "0x" + hex.EncodeToString(sig), address
+}
+
+// TestVerifySIWE_RecordsUserSignIn creates the user, records the login and audits the sign-in
+func TestVerifySIWE_RecordsUserSignIn(t *testing.T) {
+	siweService := auth.NewSIWEService(5 * time.Minute)
+	jwtService := auth.NewJWTService([]byte("secret"), time.Hour)
+	message, signature, address := signSIWEMessage(t, siweService)
+
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetOrCreateUserByAddress", mock.Anything, address).Return(&store.User{ID: 42, Address: address}, nil)
+	userRepo.On("RecordLogin", mock.Anything, int64(42)).Return(nil)
+
+	core, observed := observer.New(zapcore.InfoLevel)
+	handler := NewAuthHandler(siweService, jwtService, userRepo, nil, audit.NewAuditLogger(zap.New(core)))
+
+	body, _ := json.Marshal(VerifyRequest{Message: message, Signature: signature})
+	req := httptest.NewRequest("POST", "/auth/siwe/verify", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.VerifySIWE(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response VerifyResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.NotEmpty(t, response.Token)
+	assert.Equal(t, address, response.Address)
+	assert.Equal(t, 3600, response.ExpiresIn)
+	userRepo.AssertExpectations(t)
+
+	entries := observed.FilterField(zap.String("action", string(audit.ActionAuthSuccess))).All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, int64(42), entries[0].ContextMap()["user_id"])
+}
+
+// TestVerifySIWE_UserStoreFailure rejects the sign-in when the user cannot be loaded
+func TestVerifySIWE_UserStoreFailure(t *testing.T) {
+	siweService := auth.NewSIWEService(5 * time.Minute)
+	jwtService := auth.NewJWTService([]byte("secret"), time.Hour)
+	message, signature, address := signSIWEMessage(t, siweService)
+
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetOrCreateUserByAddress", mock.Anything, address).Return(nil, errors.New("database unavailable"))
+	handler := NewAuthHandler(siweService, jwtService, userRepo, nil, nil)
+
+	body, _ := json.Marshal(VerifyRequest{Message: message, Signature: signature})
+	req := httptest.NewRequest("POST", "/auth/siwe/verify", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.VerifySIWE(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	userRepo.AssertNotCalled(t, "RecordLogin", mock.Anything, mock.Anything)
+}
+
+// TestVerifySIWE_RecordLoginFailure still signs the user in
+func TestVerifySIWE_RecordLoginFailure(t *testing.T) {
+	siweService := auth.NewSIWEService(5 * time.Minute)
+	jwtService := auth.NewJWTService([]byte("secret"), time.Hour)
+	message, signature, address := signSIWEMessage(t, siweService)
+
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetOrCreateUserByAddress", mock.Anything, address).Return(&store.User{ID: 7, Address: address}, nil)
+	userRepo.On("RecordLogin", mock.Anything, int64(7)).Return(errors.New("database unavailable"))
+	handler := NewAuthHandler(siweService, jwtService, userRepo, nil, nil)
+
+	body, _ := json.Marshal(VerifyRequest{Message: message, Signature: signature})
+	req := httptest.NewRequest("POST", "/auth/siwe/verify", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.VerifySIWE(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+// TestVerifySIWE_RecordsLoginHistory stores the client IP, user agent and chain of the sign-in
+func TestVerifySIWE_RecordsLoginHistory(t *testing.T) {
+	siweService := auth.NewSIWEService(5 * time.Minute)
+	jwtService := auth.NewJWTService([]byte("secret"), time.Hour)
+	message, signature, address := signSIWEMessage(t, siweService)
+
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetOrCreateUserByAddress", mock.Anything, address).Return(&store.User{ID: 42, Address: address}, nil)
+	userRepo.On("RecordLogin", mock.Anything, int64(42)).Return(nil)
+	loginRepo := new(MockLoginRepository)
+	loginRepo.On("CreateLogin", mock.Anything, mock.MatchedBy(func(login *store.Login) bool {
+		return login.UserID == 42 &&
+			login.IPAddress == "203.0.113.7" &&
+			login.UserAgent == "wallet-test/1.0" &&
+			login.ChainID != nil && *login.ChainID == 1
+	})).Return(nil)
+
+	handler := NewAuthHandler(siweService, jwtService, userRepo, nil, nil)
+	handler.SetLoginRepository(loginRepo)
+
+	body, _ := json.Marshal(VerifyRequest{Message: message, Signature: signature})
+	req := httptest.NewRequest("POST", "/auth/siwe/verify", bytes.NewReader(body))
+	req.RemoteAddr = "203.0.113.7:52100"
+	req.Header.Set("User-Agent", "wallet-test/1.0")
+	rec := httptest.NewRecorder()
+	handler.VerifySIWE(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	loginRepo.AssertExpectations(t)
+}
+
+// TestVerifySIWE_LoginHistoryFailure still signs the user in
+func TestVerifySIWE_LoginHistoryFailure(t *testing.T) {
+	siweService := auth.NewSIWEService(5 * time.Minute)
+	jwtService := auth.NewJWTService([]byte("secret"), time.Hour)
+	message, signature, address := signSIWEMessage(t, siweService)
+
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetOrCreateUserByAddress", mock.Anything, address).Return(&store.User{ID: 42, Address: address}, nil)
+	userRepo.On("RecordLogin", mock.Anything, int64(42)).Return(nil)
+	loginRepo := new(MockLoginRepository)
+	loginRepo.On("CreateLogin", mock.Anything, mock.Anything).Return(errors.New("database unavailable"))
+
+	handler := NewAuthHandler(siweService, jwtService, userRepo, nil, nil)
+	handler.SetLoginRepository(loginRepo)
+
+	body, _ := json.Marshal(VerifyRequest{Message: message, Signature: signature})
+	req := httptest.NewRequest("POST", "/auth/siwe/verify", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.VerifySIWE(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+// MockSessionRepository is a mock implementation of SessionRepository
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepository) CreateSession(ctx context.Context, userID int64, address string, ttl time.Duration) (string, *store.Session, error) {
+	args := m.Called(ctx, userID, address, ttl)
+	if args.Get(1) == nil {
+		return args.String(0), nil, args.Error(2)
+	}
+	return args.String(0), args.Get(1).(*store.Session), args.Error(2)
+}
+
+func (m *MockSessionRepository) RotateSession(ctx context.Context, rawToken string, ttl time.Duration) (string, *store.Session, error) {
+	args := m.Called(ctx, rawToken, ttl)
+	if args.Get(1) == nil {
+		return args.String(0), nil, args.Error(2)
+	}
+	return args.String(0), args.Get(1).(*store.Session), args.Error(2)
+}
+
+func (m *MockSessionRepository) RevokeSession(ctx context.Context, rawToken string) error {
+	args := m.Called(ctx, rawToken)
+	return args.Error(0)
+}
+
+func refreshRequest(t *testing.T, path, refreshToken string) *http.Request {
+	t.Helper()
+	body, err := json.Marshal(RefreshRequest{RefreshToken: refreshToken})
+	require.NoError(t, err)
+	return httptest.NewRequest("POST", path, bytes.NewReader(body))
+}
+
+// TestVerifySIWE_IssuesRefreshToken starts a session when refresh tokens are enabled
+func TestVerifySIWE_IssuesRefreshToken(t *testing.T) {
+	siweService := auth.NewSIWEService(5 * time.Minute)
+	jwtService := auth.NewJWTService([]byte("secret"), time.Hour)
+	message, signature, address := signSIWEMessage(t, siweService)
+
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetOrCreateUserByAddress", mock.Anything, address).Return(&store.User{ID: 42, Address: address}, nil)
+	userRepo.On("RecordLogin", mock.Anything, int64(42)).Return(nil)
+	sessionRepo := new(MockSessionRepository)
+	sessionRepo.On("CreateSession", mock.Anything, int64(42), address, 24*time.Hour).
+		Return("refresh-1", &store.Session{ID: 1, UserID: 42, Address: address}, nil)
+
+	handler := NewAuthHandler(siweService, jwtService, userRepo, nil, nil)
+	handler.SetSessionRepository(sessionRepo, 24*time.Hour)
+
+	body, _ := json.Marshal(VerifyRequest{Message: message, Signature: signature})
+	rec := httptest.NewRecorder()
+	handler.VerifySIWE(rec, httptest.NewRequest("POST", "/auth/siwe/verify", bytes.NewReader(body)))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response VerifyResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, "refresh-1", response.RefreshToken)
+	assert.Equal(t, 86400, response.RefreshExpiresIn)
+	sessionRepo.AssertExpectations(t)
+}
+
+// TestRefresh_RotatesToken returns a new access token and a new refresh token
+func TestRefresh_RotatesToken(t *testing.T) {
+	jwtService := auth.NewJWTService([]byte("secret"), time.Hour)
+	address := "0x742d35cc6634c0532925a3b844bc9e7595f0beb0"
+	sessionRepo := new(MockSessionRepository)
+	sessionRepo.On("RotateSession", mock.Anything, "refresh-1", 24*time.Hour).
+		Return("refresh-2", &store.Session{ID: 2, UserID: 42, Address: address}, nil)
+
+	core, observed := observer.New(zapcore.InfoLevel)
+	handler := NewAuthHandler(auth.NewSIWEService(5*time.Minute), jwtService, nil, nil, audit.NewAuditLogger(zap.New(core)))
+	handler.SetSessionRepository(sessionRepo, 24*time.Hour)
+
+	rec := httptest.NewRecorder()
+	handler.Refresh(rec, refreshRequest(t, "/auth/refresh", "refresh-1"))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response VerifyResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, "refresh-2", response.RefreshToken)
+	assert.Equal(t, address, response.Address)
+	assert.Equal(t, 3600, response.ExpiresIn)
+
+	claims, err := jwtService.VerifyToken(context.Background(), response.Token)
+	require.NoError(t, err)
+	assert.Equal(t, address, claims.Address)
+	assert.Equal(t, []string{"auth"}, claims.Scopes)
+
+	assert.Len(t, observed.FilterField(zap.String("action", string(audit.ActionSessionRefreshed))).All(), 1)
+}
+
+// TestRefresh_CarriesCurrentRole issues the scope of the role the user holds now
+func TestRefresh_CarriesCurrentRole(t *testing.T) {
+	jwtService := auth.NewJWTService([]byte("secret"), time.Hour)
+	address := "0x742d35cc6634c0532925a3b844bc9e7595f0beb0"
+	sessionRepo := new(MockSessionRepository)
+	sessionRepo.On("RotateSession", mock.Anything, "refresh-1", 24*time.Hour).
+		Return("refresh-2", &store.Session{ID: 2, UserID: 42, Address: address}, nil)
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetUserByID", mock.Anything, int64(42)).Return(&store.User{ID: 42, Address: address, Role: "operator"}, nil)
+
+	handler := NewAuthHandler(auth.NewSIWEService(5*time.Minute), jwtService, userRepo, nil, nil)
+	handler.SetSessionRepository(sessionRepo, 24*time.Hour)
+
+	rec := httptest.NewRecorder()
+	handler.Refresh(rec, refreshRequest(t, "/auth/refresh", "refresh-1"))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response VerifyResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	claims, err := jwtService.VerifyToken(context.Background(), response.Token)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"auth", "operator"}, claims.Scopes)
+}
+
+// TestRefresh_RejectsInvalidTokens answers 401 for unknown, expired and reused tokens
+func TestRefresh_RejectsInvalidTokens(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"unknown", &store.NotFoundError{Resource: "session", ID: "refresh token"}, http.StatusUnauthorized},
+		{"expired", &store.ExpiredError{Resource: "session", ID: 1}, http.StatusUnauthorized},
+		{"reused", &store.RevokedError{Resource: "session", ID: 1}, http.StatusUnauthorized},
+		{"database failure", errors.New("connection refused"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessionRepo := new(MockSessionRepository)
+			sessionRepo.On("RotateSession", mock.Anything, "refresh-1", time.Hour).Return("", nil, tt.err)
+			handler := NewAuthHandler(auth.NewSIWEService(5*time.Minute), auth.NewJWTService([]byte("secret"), time.Hour), nil, nil, nil)
+			handler.SetSessionRepository(sessionRepo, time.Hour)
+
+			rec := httptest.NewRecorder()
+			handler.Refresh(rec, refreshRequest(t, "/auth/refresh", "refresh-1"))
+
+			assert.Equal(t, tt.want, rec.Code)
+		})
+	}
+}
+
+// TestRefresh_Disabled answers 404 when refresh tokens are not configured
+func TestRefresh_Disabled(t *testing.T) {
+	handler := NewAuthHandler(auth.NewSIWEService(5*time.Minute), auth.NewJWTService([]byte("secret"), time.Hour), nil, nil, nil)
+
+	rec := httptest.NewRecorder()
+	handler.Refresh(rec, refreshRequest(t, "/auth/refresh", "refresh-1"))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+// TestLogout_RevokesSession ends the session of the refresh token
+func TestLogout_RevokesSession(t *testing.T) {
+	sessionRepo := new(MockSessionRepository)
+	sessionRepo.On("RevokeSession", mock.Anything, "refresh-1").Return(nil)
+	sessionRepo.On("RevokeSession", mock.Anything, "unknown").Return(&store.NotFoundError{Resource: "session", ID: "refresh token"})
+	handler := NewAuthHandler(auth.NewSIWEService(5*time.Minute), auth.NewJWTService([]byte("secret"), time.Hour), nil, nil, nil)
+	handler.SetSessionRepository(sessionRepo, time.Hour)
+
+	rec := httptest.NewRecorder()
+	handler.Logout(rec, refreshRequest(t, "/auth/logout", "refresh-1"))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.Logout(rec, refreshRequest(t, "/auth/logout", "unknown"))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.Logout(rec, refreshRequest(t, "/auth/logout", ""))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	sessionRepo.AssertExpectations(t)
+}
+
+// TestLogout_RevokesAccessToken puts the presented access token on the revocation list
+func TestLogout_RevokesAccessToken(t *testing.T) {
+	jwtService := auth.NewJWTService([]byte("secret"), time.Hour)
+	handler := NewAuthHandler(auth.NewSIWEService(5*time.Minute), jwtService, nil, nil, nil)
+	ctx := context.Background()
+	token, err := jwtService.GenerateToken(ctx, "0x742d35Cc6634C0532925a3b844Bc390e38f3dF8c", nil)
+	require.NoError(t, err)
+	claims, err := jwtService.VerifyToken(ctx, token)
+	require.NoError(t, err)
+
+	logout := func(token string) int {
+		req := httptest.NewRequest("POST", "/auth/logout", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.Logout(rec, req)
+		return rec.Code
+	}
+
+	// Revocation must be enabled
+	assert.Equal(t, http.StatusNotFound, logout(token))
+
+	jwtService.SetRevocationList(auth.NewMemoryRevocationList())
+	assert.Equal(t, http.StatusUnauthorized, logout("not-a-token"))
+	assert.Equal(t, http.StatusNoContent, logout(token))
+
+	revoked, err := jwtService.IsRevoked(ctx, claims)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	// Refresh tokens are still rejected while refresh is disabled
+	req := refreshRequest(t, "/auth/logout", "refresh-1")
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	handler.Logout(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// Nothing to revoke
+	rec = httptest.NewRecorder()
+	handler.Logout(rec, httptest.NewRequest("POST", "/auth/logout", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestJWKS_PublishesPublicKey(t *testing.T) {
+	siweService := auth.NewSIWEService(5 * time.Minute)
+
+	rec := httptest.NewRecorder()
+	NewAuthHandler(siweService, auth.NewJWTService([]byte("secret"), time.Hour), nil, nil, nil).
+		JWKS(rec, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"keys":[]}`, rec.Body.String())
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	jwtService, err := auth.NewJWTServiceWithKey(auth.AlgorithmEdDSA, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), time.Hour)
+	require.NoError(t, err)
+
+	rec = httptest.NewRecorder()
+	NewAuthHandler(siweService, jwtService, nil, nil, nil).
+		JWKS(rec, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var set auth.JWKSet
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &set))
+	require.Len(t, set.Keys, 1)
+	assert.Equal(t, "OKP", set.Keys[0].KeyType)
+	assert.Equal(t, "EdDSA", set.Keys[0].Algorithm)
+	assert.NotEmpty(t, set.Keys[0].X)
+}
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// KeyDeprecations caches the API key deprecations so checking each API key
+// request against them does not query the database
+type KeyDeprecations struct {
+	repo store.KeyDeprecationRepositoryInterface
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu           sync.Mutex
+	deprecations []store.KeyDeprecation
+	loadedAt     time.Time
+}
+
+// NewKeyDeprecations creates a cache reloading the deprecations of repo after ttl
+func NewKeyDeprecations(repo store.KeyDeprecationRepositoryInterface, ttl time.Duration) *KeyDeprecations {
+	return &KeyDeprecations{
+		repo: repo,
+		ttl:  ttl,
+		now:  time.Now,
+	}
+}
+
+// Invalidate makes the next lookup reload the deprecations, after one is created
+// or cancelled
+func (d *KeyDeprecations) Invalidate() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.loadedAt = time.Time{}
+}
+
+// Matching returns the deprecations applying to key, earliest sunset first
+func (d *KeyDeprecations) Matching(ctx context.Context, key *store.APIKey) ([]store.KeyDeprecation, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.loadedAt.IsZero() || d.now().Sub(d.loadedAt) >= d.ttl {
+		deprecations, err := d.repo.ListKeyDeprecations(ctx)
+		if err != nil {
+			return nil, err
+		}
+		d.deprecations = deprecations
+		d.loadedAt = d.now()
+	}
+
+	var matching []store.KeyDeprecation
+	for _, deprecation := range d.deprecations {
+		if deprecation.Filter().Matches(*key) {
+			matching = append(matching, deprecation)
+		}
+	}
+	return matching, nil
+}
+
+// setDeprecationHeaders announces the deprecation of the key used for a request:
+// Deprecation (RFC 9745) with when the key was deprecated, Sunset (RFC 8594) with
+// when it will be revoked, and a Warning carrying each deprecation's message.
+// deprecations must be earliest sunset first.
+func setDeprecationHeaders(h http.Header, deprecations []store.KeyDeprecation) {
+	deprecatedAt := deprecations[0].CreatedAt
+	for _, deprecation := range deprecations[1:] {
+		if deprecation.CreatedAt.Before(deprecatedAt) {
+			deprecatedAt = deprecation.CreatedAt
+		}
+	}
+	h.Set("Deprecation", "@"+strconv.FormatInt(deprecatedAt.Unix(), 10))
+	h.Set("Sunset", deprecations[0].SunsetAt.UTC().Format(http.TimeFormat))
+
+	for _, deprecation := range deprecations {
+		message := deprecation.Message
+		if message == "" {
+			message = "this API key is deprecated"
+		}
+		// Quotes and backslashes would end the quoted warning text early
+		message = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(message)
+		h.Add("Warning", fmt.Sprintf(`299 - "%s; revoked after %s"`, message, deprecation.SunsetAt.UTC().Format(time.RFC3339)))
+	}
+}
@@ -0,0 +1,308 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/gatekeeper/internal/audit"
+	"github.com/yourusername/gatekeeper/internal/log"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// KeyDeprecationHandler handles deprecating API keys ahead of their revocation at
+// a sunset date, so integrators can be migrated to new keys in a controlled way
+type KeyDeprecationHandler struct {
+	deprecationRepo store.KeyDeprecationRepositoryInterface
+	revocationRepo  store.KeyRevocationRepositoryInterface
+	deprecations    *KeyDeprecations // Optional; invalidated on changes
+	logger          log.Logger
+	auditLogger     audit.AuditLogger
+}
+
+// NewKeyDeprecationHandler creates a new key deprecation handler
+func NewKeyDeprecationHandler(deprecationRepo store.KeyDeprecationRepositoryInterface, revocationRepo store.KeyRevocationRepositoryInterface, logger log.Logger, auditLogger audit.AuditLogger) *KeyDeprecationHandler {
+	return &KeyDeprecationHandler{
+		deprecationRepo: deprecationRepo,
+		revocationRepo:  revocationRepo,
+		logger:          logger,
+		auditLogger:     auditLogger,
+	}
+}
+
+// SetDeprecations sets the deprecation cache to invalidate when deprecations change
+func (h *KeyDeprecationHandler) SetDeprecations(deprecations *KeyDeprecations) {
+	h.deprecations = deprecations
+}
+
+// DeprecateKeysRequest selects the keys to deprecate, as a bulk revocation would,
+// and when they will be revoked
+type DeprecateKeysRequest struct {
+	RevokeKeysRequest
+	Message  string    `json:"message"` // Shown to integrators in the Warning header
+	SunsetAt time.Time `json:"sunsetAt"`
+}
+
+// KeyDeprecationResponse describes a deprecation and the usage of the keys it
+// still applies to
+type KeyDeprecationResponse struct {
+	ID                   int64        `json:"id"`
+	Scope                string       `json:"scope,omitempty"`
+	CreatedAfter         *time.Time   `json:"createdAfter,omitempty"`
+	CreatedBefore        *time.Time   `json:"createdBefore,omitempty"`
+	KeyPrefix            string       `json:"keyPrefix,omitempty"`
+	Message              string       `json:"message"`
+	SunsetAt             time.Time    `json:"sunsetAt"`
+	CreatedBy            string       `json:"createdBy"`
+	CreatedAt            time.Time    `json:"createdAt"`
+	Matched              int          `json:"matched"`              // Keys not yet revoked
+	UsedSinceDeprecation int          `json:"usedSinceDeprecation"` // Keys used after the deprecation was announced
+	LastUsedAt           *time.Time   `json:"lastUsedAt,omitempty"` // Latest use of any matched key
+	Keys                 []RevokedKey `json:"keys,omitempty"`       // Only when getting a single deprecation
+}
+
+// ListKeyDeprecationsResponse represents the response for listing key deprecations
+type ListKeyDeprecationsResponse struct {
+	Deprecations []KeyDeprecationResponse `json:"deprecations"`
+}
+
+// CreateDeprecation handles POST /api/admin/keys/deprecations - Deprecate every key
+// matching a scope, a creation time range and/or a key prefix. Requests with them
+// carry deprecation headers until sunsetAt, when they are revoked.
+func (h *KeyDeprecationHandler) CreateDeprecation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := requestLogger(r, h.logger)
+
+	var req DeprecateKeysRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, "Invalid request body", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter, details := req.filter()
+	if details != "" {
+		h.writeError(w, "Validation failed", details, http.StatusBadRequest)
+		return
+	}
+	if !req.SunsetAt.After(time.Now()) {
+		h.writeError(w, "Validation failed", "sunsetAt must be in the future", http.StatusBadRequest)
+		return
+	}
+
+	deprecation, err := h.deprecationRepo.CreateKeyDeprecation(ctx, filter, req.Message, req.SunsetAt)
+	if err != nil {
+		logger.Error("failed to create key deprecation", log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to deprecate API keys", http.StatusInternalServerError)
+		return
+	}
+	if h.deprecations != nil {
+		h.deprecations.Invalidate()
+	}
+
+	response, err := h.describe(r, *deprecation, false)
+	if err != nil {
+		logger.Error("failed to list deprecated API keys", log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to list deprecated API keys", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("API keys deprecated",
+		log.Int64("deprecation_id", deprecation.ID),
+		log.Int("matched", response.Matched),
+		log.Time("sunset_at", deprecation.SunsetAt),
+	)
+
+	h.audit(r, audit.ActionAPIKeysDeprecated, map[string]interface{}{
+		"deprecation_id": deprecation.ID,
+		"scope":          filter.Scope,
+		"created_after":  filter.CreatedAfter,
+		"created_before": filter.CreatedBefore,
+		"key_prefix":     filter.KeyPrefix,
+		"sunset_at":      deprecation.SunsetAt,
+		"matched":        response.Matched,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// ListDeprecations handles GET /api/admin/keys/deprecations - List the deprecations,
+// earliest sunset first, with how many keys each still applies to and their usage
+func (h *KeyDeprecationHandler) ListDeprecations(w http.ResponseWriter, r *http.Request) {
+	logger := requestLogger(r, h.logger)
+
+	deprecations, err := h.deprecationRepo.ListKeyDeprecations(r.Context())
+	if err != nil {
+		logger.Error("failed to list key deprecations", log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to list key deprecations", http.StatusInternalServerError)
+		return
+	}
+
+	response := ListKeyDeprecationsResponse{Deprecations: make([]KeyDeprecationResponse, 0, len(deprecations))}
+	for _, deprecation := range deprecations {
+		described, err := h.describe(r, deprecation, false)
+		if err != nil {
+			logger.Error("failed to list deprecated API keys", log.Int64("deprecation_id", deprecation.ID), log.Error(err))
+			h.writeError(w, "Internal server error", "Failed to list deprecated API keys", http.StatusInternalServerError)
+			return
+		}
+		response.Deprecations = append(response.Deprecations, described)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetDeprecation handles GET /api/admin/keys/deprecations/{id} - Get a deprecation
+// with the keys it still applies to, to follow up with the integrators using them
+func (h *KeyDeprecationHandler) GetDeprecation(w http.ResponseWriter, r *http.Request) {
+	logger := requestLogger(r, h.logger)
+
+	id, ok := h.deprecationID(w, r)
+	if !ok {
+		return
+	}
+
+	deprecation, err := h.deprecationRepo.GetKeyDeprecation(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			h.writeError(w, "Not found", "Key deprecation not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get key deprecation", log.Int64("deprecation_id", id), log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to get key deprecation", http.StatusInternalServerError)
+		return
+	}
+
+	response, err := h.describe(r, *deprecation, true)
+	if err != nil {
+		logger.Error("failed to list deprecated API keys", log.Int64("deprecation_id", id), log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to list deprecated API keys", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// CancelDeprecation handles DELETE /api/admin/keys/deprecations/{id} - Cancel a
+// deprecation; its keys are no longer revoked at the sunset
+func (h *KeyDeprecationHandler) CancelDeprecation(w http.ResponseWriter, r *http.Request) {
+	logger := requestLogger(r, h.logger)
+
+	id, ok := h.deprecationID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.deprecationRepo.DeleteKeyDeprecation(r.Context(), id); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			h.writeError(w, "Not found", "Key deprecation not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to cancel key deprecation", log.Int64("deprecation_id", id), log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to cancel key deprecation", http.StatusInternalServerError)
+		return
+	}
+	if h.deprecations != nil {
+		h.deprecations.Invalidate()
+	}
+
+	logger.Info("key deprecation cancelled", log.Int64("deprecation_id", id))
+	h.audit(r, audit.ActionAPIKeyDeprecationCancelled, map[string]interface{}{
+		"deprecation_id": id,
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// describe reports the keys deprecation still applies to and their usage,
+// listing them if withKeys is set
+func (h *KeyDeprecationHandler) describe(r *http.Request, deprecation store.KeyDeprecation, withKeys bool) (KeyDeprecationResponse, error) {
+	keys, err := h.revocationRepo.ListKeysMatching(r.Context(), deprecation.Filter())
+	if err != nil {
+		return KeyDeprecationResponse{}, err
+	}
+
+	response := KeyDeprecationResponse{
+		ID:            deprecation.ID,
+		Scope:         deprecation.Scope,
+		CreatedAfter:  deprecation.CreatedAfter,
+		CreatedBefore: deprecation.CreatedBefore,
+		KeyPrefix:     deprecation.KeyPrefix,
+		Message:       deprecation.Message,
+		SunsetAt:      deprecation.SunsetAt,
+		CreatedBy:     deprecation.CreatedBy,
+		CreatedAt:     deprecation.CreatedAt,
+		Matched:       len(keys),
+	}
+	for _, key := range keys {
+		if key.LastUsedAt != nil {
+			if key.LastUsedAt.After(deprecation.CreatedAt) {
+				response.UsedSinceDeprecation++
+			}
+			if response.LastUsedAt == nil || key.LastUsedAt.After(*response.LastUsedAt) {
+				response.LastUsedAt = key.LastUsedAt
+			}
+		}
+		if withKeys {
+			response.Keys = append(response.Keys, RevokedKey{
+				ID:         key.ID,
+				UserID:     key.UserID,
+				KeyHash:    key.KeyHash[:8],
+				Name:       key.Name,
+				Scopes:     key.Scopes,
+				LastUsedAt: key.LastUsedAt,
+				CreatedAt:  key.CreatedAt,
+			})
+		}
+	}
+	return response, nil
+}
+
+// deprecationID parses the deprecation ID from the path, writing an error if it is invalid
+func (h *KeyDeprecationHandler) deprecationID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.writeError(w, "Invalid request", "Deprecation ID must be a valid integer", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+// audit records an administrative change to the deprecations
+func (h *KeyDeprecationHandler) audit(r *http.Request, action audit.ActionType, metadata map[string]interface{}) {
+	if h.auditLogger == nil {
+		return
+	}
+	var actor string
+	if claims := ClaimsFromContext(r); claims != nil {
+		actor = claims.Identity()
+	}
+	h.auditLogger.Log(r.Context(), withClientInfo(r, audit.AuditEvent{
+		Action:   action,
+		Result:   audit.ResultSuccess,
+		UserAddr: actor,
+		Method:   r.Method,
+		Endpoint: r.URL.Path,
+		Metadata: metadata,
+	}))
+}
+
+// writeError writes an error response
+func (h *KeyDeprecationHandler) writeError(w http.ResponseWriter, error, details string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := ErrorResponse{
+		Error:   error,
+		Details: details,
+	}
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,148 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// MockKeyDeprecationRepository is a mock implementation of KeyDeprecationRepository
+type MockKeyDeprecationRepository struct {
+	mock.Mock
+}
+
+func (m *MockKeyDeprecationRepository) CreateKeyDeprecation(ctx context.Context, filter store.KeyRevocationFilter, message string, sunsetAt time.Time) (*store.KeyDeprecation, error) {
+	args := m.Called(ctx, filter, message, sunsetAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.KeyDeprecation), args.Error(1)
+}
+
+func (m *MockKeyDeprecationRepository) GetKeyDeprecation(ctx context.Context, id int64) (*store.KeyDeprecation, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.KeyDeprecation), args.Error(1)
+}
+
+func (m *MockKeyDeprecationRepository) ListKeyDeprecations(ctx context.Context) ([]store.KeyDeprecation, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]store.KeyDeprecation), args.Error(1)
+}
+
+func (m *MockKeyDeprecationRepository) DeleteKeyDeprecation(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestCreateDeprecation(t *testing.T) {
+	deprecationRepo := new(MockKeyDeprecationRepository)
+	revocationRepo := new(MockKeyRevocationRepository)
+	handler := NewKeyDeprecationHandler(deprecationRepo, revocationRepo, nil, nil)
+
+	sunsetAt := time.Now().Add(30 * 24 * time.Hour).UTC().Truncate(time.Second)
+	filter := store.KeyRevocationFilter{Scope: "admin"}
+	deprecation := &store.KeyDeprecation{ID: 7, Scope: "admin", Message: "use scoped keys", SunsetAt: sunsetAt, CreatedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+	deprecationRepo.On("CreateKeyDeprecation", mock.Anything, filter, "use scoped keys", mock.MatchedBy(sunsetAt.Equal)).Return(deprecation, nil)
+
+	used := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	keys := append([]store.APIKey(nil), revocationKeys...)
+	keys[1].LastUsedAt = &used
+	revocationRepo.On("ListKeysMatching", mock.Anything, filter).Return(keys, nil)
+
+	body := `{"scope":"admin","message":"use scoped keys","sunsetAt":"` + sunsetAt.Format(time.RFC3339) + `"}`
+	rec := httptest.NewRecorder()
+	handler.CreateDeprecation(rec, httptest.NewRequest("POST", "/api/admin/keys/deprecations", strings.NewReader(body)))
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var response KeyDeprecationResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, int64(7), response.ID)
+	assert.Equal(t, 2, response.Matched)
+	assert.Equal(t, 1, response.UsedSinceDeprecation)
+	require.NotNil(t, response.LastUsedAt)
+	assert.True(t, used.Equal(*response.LastUsedAt))
+	assert.Empty(t, response.Keys)
+}
+
+func TestCreateDeprecation_Validation(t *testing.T) {
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"no criteria", `{"message":"all","sunsetAt":"` + future + `"}`},
+		{"short key prefix", `{"keyPrefix":"ab","sunsetAt":"` + future + `"}`},
+		{"sunset in the past", `{"scope":"admin","sunsetAt":"` + past + `"}`},
+		{"no sunset", `{"scope":"admin"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deprecationRepo := new(MockKeyDeprecationRepository)
+			handler := NewKeyDeprecationHandler(deprecationRepo, new(MockKeyRevocationRepository), nil, nil)
+
+			rec := httptest.NewRecorder()
+			handler.CreateDeprecation(rec, httptest.NewRequest("POST", "/api/admin/keys/deprecations", strings.NewReader(tt.body)))
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			deprecationRepo.AssertNotCalled(t, "CreateKeyDeprecation", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestGetDeprecation_ListsKeys(t *testing.T) {
+	deprecationRepo := new(MockKeyDeprecationRepository)
+	revocationRepo := new(MockKeyRevocationRepository)
+	handler := NewKeyDeprecationHandler(deprecationRepo, revocationRepo, nil, nil)
+
+	deprecationRepo.On("GetKeyDeprecation", mock.Anything, int64(7)).Return(&store.KeyDeprecation{ID: 7, Scope: "admin"}, nil)
+	deprecationRepo.On("GetKeyDeprecation", mock.Anything, int64(8)).Return(nil, &store.NotFoundError{Resource: "key_deprecation", ID: int64(8)})
+	revocationRepo.On("ListKeysMatching", mock.Anything, store.KeyRevocationFilter{Scope: "admin"}).Return(revocationKeys, nil)
+
+	rec := httptest.NewRecorder()
+	handler.GetDeprecation(rec, mux.SetURLVars(httptest.NewRequest("GET", "/api/admin/keys/deprecations/7", nil), map[string]string{"id": "7"}))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response KeyDeprecationResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Len(t, response.Keys, 2)
+	assert.Equal(t, "ab12cd34", response.Keys[0].KeyHash)
+
+	rec = httptest.NewRecorder()
+	handler.GetDeprecation(rec, mux.SetURLVars(httptest.NewRequest("GET", "/api/admin/keys/deprecations/8", nil), map[string]string{"id": "8"}))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestCancelDeprecation(t *testing.T) {
+	deprecationRepo := new(MockKeyDeprecationRepository)
+	handler := NewKeyDeprecationHandler(deprecationRepo, new(MockKeyRevocationRepository), nil, nil)
+	deprecations := NewKeyDeprecations(deprecationRepo, time.Hour)
+	handler.SetDeprecations(deprecations)
+
+	deprecationRepo.On("DeleteKeyDeprecation", mock.Anything, int64(7)).Return(nil)
+	deprecationRepo.On("DeleteKeyDeprecation", mock.Anything, int64(8)).Return(&store.NotFoundError{Resource: "key_deprecation", ID: int64(8)})
+
+	rec := httptest.NewRecorder()
+	handler.CancelDeprecation(rec, mux.SetURLVars(httptest.NewRequest("DELETE", "/api/admin/keys/deprecations/7", nil), map[string]string{"id": "7"}))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.CancelDeprecation(rec, mux.SetURLVars(httptest.NewRequest("DELETE", "/api/admin/keys/deprecations/8", nil), map[string]string{"id": "8"}))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
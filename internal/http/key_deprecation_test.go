@@ -0,0 +1,117 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+func TestKeyDeprecations_CachesUntilInvalidated(t *testing.T) {
+	repo := new(MockKeyDeprecationRepository)
+	repo.On("ListKeyDeprecations", mock.Anything).Return([]store.KeyDeprecation{{ID: 1, Scope: "admin"}}, nil)
+	deprecations := NewKeyDeprecations(repo, time.Hour)
+
+	adminKey := &store.APIKey{Scopes: []string{"admin"}}
+	readKey := &store.APIKey{Scopes: []string{"read"}}
+	matching, err := deprecations.Matching(t.Context(), adminKey)
+	require.NoError(t, err)
+	assert.Len(t, matching, 1)
+	matching, err = deprecations.Matching(t.Context(), readKey)
+	require.NoError(t, err)
+	assert.Empty(t, matching)
+	repo.AssertNumberOfCalls(t, "ListKeyDeprecations", 1)
+
+	deprecations.Invalidate()
+	_, err = deprecations.Matching(t.Context(), adminKey)
+	require.NoError(t, err)
+	repo.AssertNumberOfCalls(t, "ListKeyDeprecations", 2)
+}
+
+func TestSetDeprecationHeaders(t *testing.T) {
+	deprecatedAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	sunsetAt := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	h := http.Header{}
+	setDeprecationHeaders(h, []store.KeyDeprecation{
+		{Message: `use "v2" keys`, SunsetAt: sunsetAt, CreatedAt: deprecatedAt.Add(time.Hour)},
+		{SunsetAt: sunsetAt.Add(time.Hour), CreatedAt: deprecatedAt},
+	})
+
+	assert.Equal(t, "@1790812800", h.Get("Deprecation"))
+	assert.Equal(t, "Sun, 01 Nov 2026 00:00:00 GMT", h.Get("Sunset"))
+	assert.Equal(t, []string{
+		`299 - "use \"v2\" keys; revoked after 2026-11-01T00:00:00Z"`,
+		`299 - "this API key is deprecated; revoked after 2026-11-01T01:00:00Z"`,
+	}, h.Values("Warning"))
+}
+
+func TestAPIKeyMiddleware_Deprecations(t *testing.T) {
+	rawKey := "a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2"
+	testUser := &store.User{ID: 1, Address: "0x1234567890123456789012345678901234567890"}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name        string
+		sunsetAt    time.Time
+		expected    int
+		deprecation bool
+	}{
+		{"before the sunset", time.Now().Add(time.Hour), http.StatusOK, true},
+		{"after the sunset", time.Now().Add(-time.Hour), http.StatusUnauthorized, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiKeyRepo := new(MockAPIKeyRepository)
+			userRepo := new(MockUserRepository)
+			deprecationRepo := new(MockKeyDeprecationRepository)
+			apiKeyRepo.On("ValidateAPIKey", mock.Anything, rawKey).Return(&store.APIKey{ID: 1, UserID: testUser.ID, KeyHash: "abcdef12", Scopes: []string{"legacy"}}, nil)
+			apiKeyRepo.On("UpdateLastUsed", mock.Anything, mock.Anything).Return(nil).Maybe()
+			userRepo.On("GetUserByID", mock.Anything, testUser.ID).Return(testUser, nil).Maybe()
+			deprecationRepo.On("ListKeyDeprecations", mock.Anything).Return([]store.KeyDeprecation{
+				{ID: 1, Scope: "legacy", Message: "migrate", SunsetAt: tt.sunsetAt, CreatedAt: time.Now().Add(-24 * time.Hour)},
+			}, nil)
+
+			middleware := NewAPIKeyMiddleware(apiKeyRepo, userRepo, nil, nil)
+			middleware.SetDeprecations(NewKeyDeprecations(deprecationRepo, time.Minute))
+
+			req := httptest.NewRequest("GET", "/api/data", nil)
+			req.Header.Set("X-API-Key", rawKey)
+			rec := httptest.NewRecorder()
+			middleware.Middleware()(next).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expected, rec.Code)
+			assert.Equal(t, tt.deprecation, rec.Header().Get("Sunset") != "")
+			time.Sleep(10 * time.Millisecond)
+		})
+	}
+}
+
+func TestKeySunsetJob_RevokesDueDeprecations(t *testing.T) {
+	deprecationRepo := new(MockKeyDeprecationRepository)
+	revocationRepo := new(MockKeyRevocationRepository)
+	now := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	deprecationRepo.On("ListKeyDeprecations", mock.Anything).Return([]store.KeyDeprecation{
+		{ID: 1, Scope: "admin", SunsetAt: now.Add(-time.Hour)},
+		{ID: 2, KeyPrefix: "dead", SunsetAt: now.Add(-time.Minute)},
+		{ID: 3, Scope: "read", SunsetAt: now.Add(time.Hour)},
+	}, nil)
+	revocationRepo.On("RevokeKeysMatching", mock.Anything, store.KeyRevocationFilter{Scope: "admin"}).Return(revocationKeys, nil)
+	revocationRepo.On("RevokeKeysMatching", mock.Anything, store.KeyRevocationFilter{KeyPrefix: "dead"}).Return(nil, errors.New("connection reset"))
+
+	job := NewKeySunsetJob(deprecationRepo, revocationRepo, time.Hour, nil, nil)
+	job.now = func() time.Time { return now }
+
+	revoked, err := job.Run(t.Context())
+	assert.Error(t, err, "a failing deprecation is reported")
+	assert.Equal(t, 2, revoked, "the other deprecations are still processed")
+	revocationRepo.AssertNotCalled(t, "RevokeKeysMatching", mock.Anything, store.KeyRevocationFilter{Scope: "read"})
+}
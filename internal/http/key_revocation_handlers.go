@@ -0,0 +1,198 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/gatekeeper/internal/audit"
+	"github.com/yourusername/gatekeeper/internal/log"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// minRevocationKeyPrefix is the shortest key prefix accepted, so a typo cannot
+// match a large share of all keys
+const minRevocationKeyPrefix = 4
+
+// KeyRevocationHandler handles emergency bulk revocation of API keys
+type KeyRevocationHandler struct {
+	revocationRepo store.KeyRevocationRepositoryInterface
+	logger         log.Logger
+	auditLogger    audit.AuditLogger
+}
+
+// NewKeyRevocationHandler creates a new key revocation handler
+func NewKeyRevocationHandler(revocationRepo store.KeyRevocationRepositoryInterface, logger log.Logger, auditLogger audit.AuditLogger) *KeyRevocationHandler {
+	return &KeyRevocationHandler{
+		revocationRepo: revocationRepo,
+		logger:         logger,
+		auditLogger:    auditLogger,
+	}
+}
+
+// RevokeKeysRequest selects the keys to revoke. A key must match every criterion
+// given; at least one is required.
+type RevokeKeysRequest struct {
+	Scope         string     `json:"scope,omitempty"`
+	CreatedAfter  *time.Time `json:"createdAfter,omitempty"`  // Inclusive
+	CreatedBefore *time.Time `json:"createdBefore,omitempty"` // Exclusive
+	KeyPrefix     string     `json:"keyPrefix,omitempty"`     // Prefix of the keyHash shown in key listings
+}
+
+// RevokedKey describes a key matched by a bulk revocation
+type RevokedKey struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"userId"`
+	KeyHash    string     `json:"keyHash"` // First 8 chars for identification
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// RevokeKeysResponse describes what a bulk revocation did, or would do in a dry run
+type RevokeKeysResponse struct {
+	DryRun  bool         `json:"dryRun"`
+	Matched int          `json:"matched"`
+	Revoked int          `json:"revoked"` // Always 0 in a dry run
+	Keys    []RevokedKey `json:"keys"`
+}
+
+// RevokeKeys handles POST /api/admin/keys/revoke - Revoke every key matching a scope,
+// a creation time range and/or a key prefix in one operation. With ?dry_run=true
+// nothing is revoked and the response lists the keys that would be.
+func (h *KeyRevocationHandler) RevokeKeys(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := requestLogger(r, h.logger)
+
+	dryRun := false
+	if raw := r.URL.Query().Get("dry_run"); raw != "" {
+		var err error
+		dryRun, err = strconv.ParseBool(raw)
+		if err != nil {
+			h.writeError(w, "Invalid request", "dry_run must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var req RevokeKeysRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, "Invalid request body", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter, details := req.filter()
+	if details != "" {
+		h.writeError(w, "Validation failed", details, http.StatusBadRequest)
+		return
+	}
+
+	var keys []store.APIKey
+	var err error
+	if dryRun {
+		keys, err = h.revocationRepo.ListKeysMatching(ctx, filter)
+	} else {
+		keys, err = h.revocationRepo.RevokeKeysMatching(ctx, filter)
+	}
+	if err != nil {
+		logger.Error("failed to revoke API keys", log.Bool("dry_run", dryRun), log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to revoke API keys", http.StatusInternalServerError)
+		return
+	}
+
+	response := RevokeKeysResponse{
+		DryRun:  dryRun,
+		Matched: len(keys),
+		Keys:    make([]RevokedKey, 0, len(keys)),
+	}
+	keyIDs := make([]int64, 0, len(keys))
+	for _, key := range keys {
+		response.Keys = append(response.Keys, RevokedKey{
+			ID:         key.ID,
+			UserID:     key.UserID,
+			KeyHash:    key.KeyHash[:8],
+			Name:       key.Name,
+			Scopes:     key.Scopes,
+			LastUsedAt: key.LastUsedAt,
+			CreatedAt:  key.CreatedAt,
+		})
+		keyIDs = append(keyIDs, key.ID)
+	}
+
+	if !dryRun {
+		response.Revoked = len(keys)
+
+		logger.Warn("API keys revoked in bulk",
+			log.Int("revoked", response.Revoked),
+			log.String("scope", filter.Scope),
+			log.String("key_prefix", filter.KeyPrefix),
+		)
+
+		if h.auditLogger != nil {
+			var actor string
+			if claims := ClaimsFromContext(r); claims != nil {
+				actor = claims.Identity()
+			}
+			h.auditLogger.Log(ctx, withClientInfo(r, audit.AuditEvent{
+				Action:   audit.ActionAPIKeysRevoked,
+				Result:   audit.ResultSuccess,
+				UserAddr: actor,
+				Method:   r.Method,
+				Endpoint: r.URL.Path,
+				Metadata: map[string]interface{}{
+					"scope":          filter.Scope,
+					"created_after":  filter.CreatedAfter,
+					"created_before": filter.CreatedBefore,
+					"key_prefix":     filter.KeyPrefix,
+					"revoked":        response.Revoked,
+					"key_ids":        keyIDs,
+				},
+			}))
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// filter validates the request and converts it to a store filter, returning the
+// validation failure details if it is invalid
+func (req RevokeKeysRequest) filter() (store.KeyRevocationFilter, string) {
+	filter := store.KeyRevocationFilter{
+		Scope:         strings.TrimSpace(req.Scope),
+		CreatedAfter:  req.CreatedAfter,
+		CreatedBefore: req.CreatedBefore,
+		KeyPrefix:     strings.ToLower(strings.TrimSpace(req.KeyPrefix)),
+	}
+
+	if filter.IsEmpty() {
+		return filter, "at least one of scope, createdAfter, createdBefore or keyPrefix is required"
+	}
+	if filter.KeyPrefix != "" {
+		if len(filter.KeyPrefix) < minRevocationKeyPrefix || len(filter.KeyPrefix) > 64 {
+			return filter, "keyPrefix must be between 4 and 64 characters"
+		}
+		if strings.Trim(filter.KeyPrefix, "0123456789abcdef") != "" {
+			return filter, "keyPrefix must be hexadecimal"
+		}
+	}
+	if filter.CreatedAfter != nil && filter.CreatedBefore != nil && !filter.CreatedAfter.Before(*filter.CreatedBefore) {
+		return filter, "createdAfter must be before createdBefore"
+	}
+	return filter, ""
+}
+
+// writeError writes an error response
+func (h *KeyRevocationHandler) writeError(w http.ResponseWriter, error, details string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := ErrorResponse{
+		Error:   error,
+		Details: details,
+	}
+	json.NewEncoder(w).Encode(response)
+}
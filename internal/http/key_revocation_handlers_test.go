@@ -0,0 +1,127 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// MockKeyRevocationRepository is a mock implementation of KeyRevocationRepository
+type MockKeyRevocationRepository struct {
+	mock.Mock
+}
+
+func (m *MockKeyRevocationRepository) ListKeysMatching(ctx context.Context, filter store.KeyRevocationFilter) ([]store.APIKey, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]store.APIKey), args.Error(1)
+}
+
+func (m *MockKeyRevocationRepository) RevokeKeysMatching(ctx context.Context, filter store.KeyRevocationFilter) ([]store.APIKey, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]store.APIKey), args.Error(1)
+}
+
+var revocationKeys = []store.APIKey{
+	{ID: 1, UserID: 10, KeyHash: "ab12cd34ef56", Name: "CI", Scopes: []string{"admin"}, CreatedAt: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+	{ID: 2, UserID: 11, KeyHash: "ab12cd3400aa", Name: "Deploy", Scopes: []string{"admin", "read"}, CreatedAt: time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)},
+}
+
+func newRevokeRequest(query, body string) *http.Request {
+	req := httptest.NewRequest("POST", "/api/admin/keys/revoke"+query, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestRevokeKeys_DryRunPreview(t *testing.T) {
+	repo := new(MockKeyRevocationRepository)
+	handler := NewKeyRevocationHandler(repo, nil, nil)
+	repo.On("ListKeysMatching", mock.Anything, store.KeyRevocationFilter{Scope: "admin", KeyPrefix: "ab12cd34"}).Return(revocationKeys, nil)
+
+	rec := httptest.NewRecorder()
+	handler.RevokeKeys(rec, newRevokeRequest("?dry_run=true", `{"scope":"admin","keyPrefix":"AB12CD34"}`))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response RevokeKeysResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.True(t, response.DryRun)
+	assert.Equal(t, 2, response.Matched)
+	assert.Zero(t, response.Revoked)
+	require.Len(t, response.Keys, 2)
+	assert.Equal(t, "ab12cd34", response.Keys[0].KeyHash)
+	assert.Equal(t, int64(11), response.Keys[1].UserID)
+	repo.AssertNotCalled(t, "RevokeKeysMatching", mock.Anything, mock.Anything)
+}
+
+func TestRevokeKeys_RevokesMatchingKeys(t *testing.T) {
+	repo := new(MockKeyRevocationRepository)
+	handler := NewKeyRevocationHandler(repo, nil, nil)
+
+	after := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2026, 10, 3, 0, 0, 0, 0, time.UTC)
+	repo.On("RevokeKeysMatching", mock.Anything, store.KeyRevocationFilter{CreatedAfter: &after, CreatedBefore: &before}).Return(revocationKeys, nil)
+
+	rec := httptest.NewRecorder()
+	handler.RevokeKeys(rec, newRevokeRequest("", `{"createdAfter":"2026-10-01T00:00:00Z","createdBefore":"2026-10-03T00:00:00Z"}`))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response RevokeKeysResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.False(t, response.DryRun)
+	assert.Equal(t, 2, response.Revoked)
+	repo.AssertExpectations(t)
+}
+
+func TestRevokeKeys_RequestValidation(t *testing.T) {
+	repo := new(MockKeyRevocationRepository)
+	handler := NewKeyRevocationHandler(repo, nil, nil)
+
+	tests := []struct {
+		name  string
+		query string
+		body  string
+	}{
+		{"no criteria", "", `{}`},
+		{"blank scope", "", `{"scope":"  "}`},
+		{"short prefix", "", `{"keyPrefix":"ab1"}`},
+		{"non-hex prefix", "", `{"keyPrefix":"ab12%"}`},
+		{"empty range", "", `{"createdAfter":"2026-10-03T00:00:00Z","createdBefore":"2026-10-01T00:00:00Z"}`},
+		{"invalid dry_run", "?dry_run=maybe", `{"scope":"admin"}`},
+		{"invalid body", "", `{"scope":`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.RevokeKeys(rec, newRevokeRequest(tt.query, tt.body))
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		})
+	}
+	repo.AssertNotCalled(t, "ListKeysMatching", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "RevokeKeysMatching", mock.Anything, mock.Anything)
+}
+
+func TestRevokeKeys_RepositoryError(t *testing.T) {
+	repo := new(MockKeyRevocationRepository)
+	handler := NewKeyRevocationHandler(repo, nil, nil)
+	repo.On("RevokeKeysMatching", mock.Anything, mock.Anything).Return(nil, errors.New("connection reset"))
+
+	rec := httptest.NewRecorder()
+	handler.RevokeKeys(rec, newRevokeRequest("", `{"scope":"admin"}`))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
@@ -0,0 +1,116 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/gatekeeper/internal/audit"
+	"github.com/yourusername/gatekeeper/internal/jobs"
+	"github.com/yourusername/gatekeeper/internal/log"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// KeySunsetJob periodically revokes the API keys of deprecations past their
+// sunset. Deprecations stay in effect after the sunset, so keys matching them
+// created later are revoked too.
+type KeySunsetJob struct {
+	deprecationRepo store.KeyDeprecationRepositoryInterface
+	revocationRepo  store.KeyRevocationRepositoryInterface
+	interval        time.Duration
+	logger          log.Logger
+	auditLogger     audit.AuditLogger
+	now             func() time.Time
+	job             *jobs.Job
+}
+
+// NewKeySunsetJob creates a job that every interval revokes the keys of
+// deprecations past their sunset
+func NewKeySunsetJob(deprecationRepo store.KeyDeprecationRepositoryInterface, revocationRepo store.KeyRevocationRepositoryInterface, interval time.Duration, logger log.Logger, auditLogger audit.AuditLogger) *KeySunsetJob {
+	if logger == nil {
+		logger = log.NewNop()
+	}
+	return &KeySunsetJob{
+		deprecationRepo: deprecationRepo,
+		revocationRepo:  revocationRepo,
+		interval:        interval,
+		logger:          logger,
+		auditLogger:     auditLogger,
+		now:             time.Now,
+	}
+}
+
+// Start runs the job immediately and then every interval until Stop is called
+func (j *KeySunsetJob) Start() {
+	j.job = jobs.Every("key sunset", j.interval, j.runOnce, log.Zap(j.logger))
+}
+
+// Stop stops the job, waiting for a run in progress to be cancelled
+func (j *KeySunsetJob) Stop() {
+	j.job.Stop()
+}
+
+// runOnce runs the job once, logging what it did
+func (j *KeySunsetJob) runOnce(ctx context.Context) error {
+	revoked, err := j.Run(ctx)
+	if err != nil {
+		return err
+	}
+	if revoked > 0 {
+		j.logger.Info("revoked API keys past their sunset", log.Int("count", revoked))
+	}
+	return nil
+}
+
+// Run revokes the keys of every deprecation past its sunset and returns how many
+// were revoked. A failing deprecation does not stop the others from being processed.
+func (j *KeySunsetJob) Run(ctx context.Context) (int, error) {
+	deprecations, err := j.deprecationRepo.ListKeyDeprecations(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := j.now()
+	total := 0
+	var failed int
+	var lastErr error
+	for _, deprecation := range deprecations {
+		// Earliest sunset first, so the rest are not due yet
+		if deprecation.SunsetAt.After(now) {
+			break
+		}
+
+		keys, err := j.revocationRepo.RevokeKeysMatching(ctx, deprecation.Filter())
+		if err != nil {
+			failed++
+			lastErr = err
+			continue
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		total += len(keys)
+
+		keyIDs := make([]int64, 0, len(keys))
+		for _, key := range keys {
+			keyIDs = append(keyIDs, key.ID)
+		}
+		if j.auditLogger != nil {
+			j.auditLogger.Log(ctx, audit.AuditEvent{
+				Action: audit.ActionAPIKeysSunset,
+				Result: audit.ResultSuccess,
+				Metadata: map[string]interface{}{
+					"deprecation_id": deprecation.ID,
+					"sunset_at":      deprecation.SunsetAt,
+					"revoked":        len(keys),
+					"key_ids":        keyIDs,
+				},
+			})
+		}
+	}
+
+	if lastErr != nil {
+		return total, fmt.Errorf("failed to revoke the keys of %d deprecations: %w", failed, lastErr)
+	}
+	return total, nil
+}
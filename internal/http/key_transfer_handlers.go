@@ -0,0 +1,177 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/gatekeeper/internal/audit"
+	"github.com/yourusername/gatekeeper/internal/log"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// KeyTransferHandler handles reassigning API keys to another owner
+type KeyTransferHandler struct {
+	transferRepo store.KeyTransferRepositoryInterface
+	userRepo     store.UserRepositoryInterface
+	logger       log.Logger
+	auditLogger  audit.AuditLogger
+}
+
+// NewKeyTransferHandler creates a new key transfer handler
+func NewKeyTransferHandler(transferRepo store.KeyTransferRepositoryInterface, userRepo store.UserRepositoryInterface, logger log.Logger, auditLogger audit.AuditLogger) *KeyTransferHandler {
+	return &KeyTransferHandler{
+		transferRepo: transferRepo,
+		userRepo:     userRepo,
+		logger:       logger,
+		auditLogger:  auditLogger,
+	}
+}
+
+// TransferKeyRequest names the new owner of a key. Exactly one of ToAddress and
+// ToUserID is required; service accounts have no address and are named by ID.
+type TransferKeyRequest struct {
+	ToAddress string `json:"toAddress,omitempty"`
+	ToUserID  int64  `json:"toUserId,omitempty"`
+}
+
+// TransferKeyResponse describes a transferred key and both of its owners
+type TransferKeyResponse struct {
+	ID             int64      `json:"id"`
+	KeyHash        string     `json:"keyHash"` // First 8 chars for identification
+	Name           string     `json:"name"`
+	Scopes         []string   `json:"scopes"`
+	LastUsedAt     *time.Time `json:"lastUsedAt,omitempty"`
+	PreviousUserID int64      `json:"previousUserId"`
+	UserID         int64      `json:"userId"`
+}
+
+// TransferKey handles POST /api/admin/keys/{id}/transfer - Reassign an API key to
+// another user or service account. The key itself, its scopes, expiry and usage
+// history are unchanged, so clients using it keep working.
+func (h *KeyTransferHandler) TransferKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := requestLogger(r, h.logger)
+
+	keyID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.writeError(w, "Invalid request", "Key ID must be a valid integer", http.StatusBadRequest)
+		return
+	}
+
+	var req TransferKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, "Invalid request body", err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.ToAddress = strings.TrimSpace(req.ToAddress)
+	if (req.ToAddress == "") == (req.ToUserID == 0) {
+		h.writeError(w, "Validation failed", "exactly one of toAddress or toUserId is required", http.StatusBadRequest)
+		return
+	}
+
+	var target *store.User
+	if req.ToAddress != "" {
+		target, err = h.userRepo.GetUserByAddress(ctx, req.ToAddress)
+	} else {
+		target, err = h.userRepo.GetUserByID(ctx, req.ToUserID)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrInvalidAddress):
+			h.writeError(w, "Validation failed", "toAddress must be an Ethereum address", http.StatusBadRequest)
+		case errors.Is(err, store.ErrNotFound):
+			h.writeError(w, "User not found", "The new owner must have signed in or be a service account", http.StatusNotFound)
+		default:
+			logger.Error("failed to get transfer target", log.Error(err))
+			h.writeError(w, "Internal server error", "Failed to transfer API key", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	apiKey, err := h.transferRepo.GetAPIKey(ctx, keyID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			h.writeError(w, "API key not found", "The specified API key does not exist", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get API key", log.Int64("key_id", keyID), log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to transfer API key", http.StatusInternalServerError)
+		return
+	}
+	if apiKey.UserID == target.ID {
+		h.writeError(w, "Conflict", "The API key already belongs to that user", http.StatusConflict)
+		return
+	}
+
+	apiKey, previousUserID, err := h.transferRepo.TransferAPIKey(ctx, keyID, target.ID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			h.writeError(w, "API key not found", "The specified API key does not exist", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to transfer API key", log.Int64("key_id", keyID), log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to transfer API key", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("API key transferred",
+		log.Int64("key_id", apiKey.ID),
+		log.Int64("from_user_id", previousUserID),
+		log.Int64("to_user_id", target.ID),
+	)
+
+	if h.auditLogger != nil {
+		var actor string
+		if claims := ClaimsFromContext(r); claims != nil {
+			actor = claims.Identity()
+		}
+		metadata := map[string]interface{}{
+			"from_user_id": previousUserID,
+			"to_user_id":   target.ID,
+			"to_user":      target.Identity(),
+		}
+		// The previous owner may have been deleted since; the ID is still recorded
+		if previous, err := h.userRepo.GetUserByID(ctx, previousUserID); err == nil {
+			metadata["from_user"] = previous.Identity()
+		}
+		h.auditLogger.Log(ctx, withClientInfo(r, audit.AuditEvent{
+			Action:   audit.ActionAPIKeyTransferred,
+			Result:   audit.ResultSuccess,
+			UserAddr: actor,
+			KeyID:    apiKey.ID,
+			KeyName:  apiKey.Name,
+			Method:   r.Method,
+			Endpoint: r.URL.Path,
+			Metadata: metadata,
+		}))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(TransferKeyResponse{
+		ID:             apiKey.ID,
+		KeyHash:        apiKey.KeyHash[:8],
+		Name:           apiKey.Name,
+		Scopes:         apiKey.Scopes,
+		LastUsedAt:     apiKey.LastUsedAt,
+		PreviousUserID: previousUserID,
+		UserID:         apiKey.UserID,
+	})
+}
+
+// writeError writes an error response
+func (h *KeyTransferHandler) writeError(w http.ResponseWriter, error, details string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := ErrorResponse{
+		Error:   error,
+		Details: details,
+	}
+	json.NewEncoder(w).Encode(response)
+}
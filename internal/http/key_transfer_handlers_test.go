@@ -0,0 +1,175 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/audit"
+	"github.com/yourusername/gatekeeper/internal/store"
+	"go.uber.org/zap"
+)
+
+// MockKeyTransferRepository is a mock implementation of KeyTransferRepository
+type MockKeyTransferRepository struct {
+	mock.Mock
+}
+
+func (m *MockKeyTransferRepository) GetAPIKey(ctx context.Context, id int64) (*store.APIKey, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.APIKey), args.Error(1)
+}
+
+func (m *MockKeyTransferRepository) TransferAPIKey(ctx context.Context, id, toUserID int64) (*store.APIKey, int64, error) {
+	args := m.Called(ctx, id, toUserID)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).(*store.APIKey), args.Get(1).(int64), args.Error(2)
+}
+
+// recordingSink collects the audit events it receives
+type recordingSink struct {
+	events []audit.AuditEvent
+}
+
+func (s *recordingSink) Write(event audit.AuditEvent) {
+	s.events = append(s.events, event)
+}
+
+const (
+	leaverAddress    = "0x742d35cc6634c0532925a3b844bc9e7595f0beb8"
+	successorAddress = "0x8ba1f109551bd432803012645ac136ddd64dba72"
+)
+
+func newTransferRequest(id, body string) *http.Request {
+	req := httptest.NewRequest("POST", "/api/admin/keys/"+id+"/transfer", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return mux.SetURLVars(req, map[string]string{"id": id})
+}
+
+func TestTransferKey_ToAddress(t *testing.T) {
+	transferRepo := new(MockKeyTransferRepository)
+	userRepo := new(MockUserRepository)
+	sink := &recordingSink{}
+	handler := NewKeyTransferHandler(transferRepo, userRepo, nil, audit.NewAuditLoggerWithSinks(zap.NewNop(), sink))
+
+	key := &store.APIKey{ID: 5, UserID: 10, KeyHash: "ab12cd34ef56", Name: "Deploy", Scopes: []string{"read"}}
+	transferred := *key
+	transferred.UserID = 11
+	userRepo.On("GetUserByAddress", mock.Anything, successorAddress).Return(&store.User{ID: 11, Address: successorAddress}, nil)
+	userRepo.On("GetUserByID", mock.Anything, int64(10)).Return(&store.User{ID: 10, Address: leaverAddress}, nil)
+	transferRepo.On("GetAPIKey", mock.Anything, int64(5)).Return(key, nil)
+	transferRepo.On("TransferAPIKey", mock.Anything, int64(5), int64(11)).Return(&transferred, int64(10), nil)
+
+	rec := httptest.NewRecorder()
+	handler.TransferKey(rec, newTransferRequest("5", `{"toAddress":"`+successorAddress+`"}`))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response TransferKeyResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, int64(10), response.PreviousUserID)
+	assert.Equal(t, int64(11), response.UserID)
+	assert.Equal(t, "ab12cd34", response.KeyHash)
+
+	require.Len(t, sink.events, 1)
+	event := sink.events[0]
+	assert.Equal(t, audit.ActionAPIKeyTransferred, event.Action)
+	assert.Equal(t, int64(5), event.KeyID)
+	assert.Equal(t, leaverAddress, event.Metadata["from_user"])
+	assert.Equal(t, successorAddress, event.Metadata["to_user"])
+	assert.Equal(t, int64(10), event.Metadata["from_user_id"])
+	assert.Equal(t, int64(11), event.Metadata["to_user_id"])
+}
+
+func TestTransferKey_ToServiceAccount(t *testing.T) {
+	transferRepo := new(MockKeyTransferRepository)
+	userRepo := new(MockUserRepository)
+	handler := NewKeyTransferHandler(transferRepo, userRepo, nil, nil)
+
+	key := &store.APIKey{ID: 5, UserID: 10, KeyHash: "ab12cd34ef56", Name: "Deploy"}
+	transferred := *key
+	transferred.UserID = 20
+	userRepo.On("GetUserByID", mock.Anything, int64(20)).Return(&store.User{ID: 20, AccountType: store.AccountTypeService, Name: "ci"}, nil)
+	transferRepo.On("GetAPIKey", mock.Anything, int64(5)).Return(key, nil)
+	transferRepo.On("TransferAPIKey", mock.Anything, int64(5), int64(20)).Return(&transferred, int64(10), nil)
+
+	rec := httptest.NewRecorder()
+	handler.TransferKey(rec, newTransferRequest("5", `{"toUserId":20}`))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	transferRepo.AssertExpectations(t)
+}
+
+func TestTransferKey_Rejected(t *testing.T) {
+	key := &store.APIKey{ID: 5, UserID: 11, KeyHash: "ab12cd34ef56"}
+
+	tests := []struct {
+		name       string
+		id         string
+		body       string
+		setup      func(*MockKeyTransferRepository, *MockUserRepository)
+		wantStatus int
+	}{
+		{"invalid key ID", "abc", `{"toUserId":11}`, nil, http.StatusBadRequest},
+		{"no new owner", "5", `{}`, nil, http.StatusBadRequest},
+		{"both owners", "5", `{"toUserId":11,"toAddress":"` + successorAddress + `"}`, nil, http.StatusBadRequest},
+		{
+			"invalid address", "5", `{"toAddress":"0x123"}`,
+			func(_ *MockKeyTransferRepository, users *MockUserRepository) {
+				users.On("GetUserByAddress", mock.Anything, "0x123").Return(nil, &store.InvalidAddressError{Address: "0x123"})
+			},
+			http.StatusBadRequest,
+		},
+		{
+			"unknown user", "5", `{"toUserId":99}`,
+			func(_ *MockKeyTransferRepository, users *MockUserRepository) {
+				users.On("GetUserByID", mock.Anything, int64(99)).Return(nil, &store.NotFoundError{Resource: "user", ID: 99})
+			},
+			http.StatusNotFound,
+		},
+		{
+			"unknown key", "5", `{"toUserId":11}`,
+			func(keys *MockKeyTransferRepository, users *MockUserRepository) {
+				users.On("GetUserByID", mock.Anything, int64(11)).Return(&store.User{ID: 11}, nil)
+				keys.On("GetAPIKey", mock.Anything, int64(5)).Return(nil, &store.NotFoundError{Resource: "api_key", ID: 5})
+			},
+			http.StatusNotFound,
+		},
+		{
+			"already owned", "5", `{"toUserId":11}`,
+			func(keys *MockKeyTransferRepository, users *MockUserRepository) {
+				users.On("GetUserByID", mock.Anything, int64(11)).Return(&store.User{ID: 11}, nil)
+				keys.On("GetAPIKey", mock.Anything, int64(5)).Return(key, nil)
+			},
+			http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transferRepo := new(MockKeyTransferRepository)
+			userRepo := new(MockUserRepository)
+			if tt.setup != nil {
+				tt.setup(transferRepo, userRepo)
+			}
+			handler := NewKeyTransferHandler(transferRepo, userRepo, nil, nil)
+
+			rec := httptest.NewRecorder()
+			handler.TransferKey(rec, newTransferRequest(tt.id, tt.body))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			transferRepo.AssertNotCalled(t, "TransferAPIKey", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
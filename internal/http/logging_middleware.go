@@ -2,6 +2,7 @@ package http
 
 import (
 	"net/http"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
@@ -35,21 +36,22 @@ func (m *LoggingMiddleware) Middleware() func(http.Handler) http.Handler {
 			}
 
 			// Wrap response writer to capture status
-			wrapped := &loggingResponseWriter{
-				ResponseWriter: w,
-				statusCode:     http.StatusOK,
+			wrapped := acquireLoggingResponseWriter(w)
+			defer releaseLoggingResponseWriter(wrapped)
+
+			// Log request start. Check skips building the fields when the level
+			// is disabled, so quiet loggers cost no allocations.
+			if ce := m.logger.Check(zap.InfoLevel, "http request started"); ce != nil {
+				ce.Write(
+					zap.String("request_id", requestID),
+					zap.String("method", r.Method),
+					zap.String("path", r.URL.Path),
+					zap.String("remote_addr", r.RemoteAddr),
+					zap.String("user_agent", r.UserAgent()),
+					zap.String("user_address", userAddress),
+				)
 			}
 
-			// Log request start
-			m.logger.Info("http request started",
-				zap.String("request_id", requestID),
-				zap.String("method", r.Method),
-				zap.String("path", r.URL.Path),
-				zap.String("remote_addr", r.RemoteAddr),
-				zap.String("user_agent", r.UserAgent()),
-				zap.String("user_address", userAddress),
-			)
-
 			// Call next handler
 			next.ServeHTTP(wrapped, r)
 
@@ -57,23 +59,25 @@ func (m *LoggingMiddleware) Middleware() func(http.Handler) http.Handler {
 			duration := time.Since(start)
 
 			// Log request completion
-			logFunc := m.logger.Info
+			level := zap.InfoLevel
 			if wrapped.statusCode >= 500 {
-				logFunc = m.logger.Error
+				level = zap.ErrorLevel
 			} else if wrapped.statusCode >= 400 {
-				logFunc = m.logger.Warn
+				level = zap.WarnLevel
 			}
 
-			logFunc("http request completed",
-				zap.String("request_id", requestID),
-				zap.String("method", r.Method),
-				zap.String("path", r.URL.Path),
-				zap.Int("status", wrapped.statusCode),
-				zap.Duration("duration", duration),
-				zap.Int64("duration_ms", duration.Milliseconds()),
-				zap.String("user_address", userAddress),
-				zap.String("remote_addr", r.RemoteAddr),
-			)
+			if ce := m.logger.Check(level, "http request completed"); ce != nil {
+				ce.Write(
+					zap.String("request_id", requestID),
+					zap.String("method", r.Method),
+					zap.String("path", r.URL.Path),
+					zap.Int("status", wrapped.statusCode),
+					zap.Duration("duration", duration),
+					zap.Int64("duration_ms", duration.Milliseconds()),
+					zap.String("user_address", userAddress),
+					zap.String("remote_addr", r.RemoteAddr),
+				)
+			}
 		})
 	}
 }
@@ -85,6 +89,27 @@ type loggingResponseWriter struct {
 	written    bool
 }
 
+// loggingResponseWriterPool recycles the wrappers of the logging middleware
+var loggingResponseWriterPool = sync.Pool{
+	New: func() interface{} { return new(loggingResponseWriter) },
+}
+
+// acquireLoggingResponseWriter returns a pooled wrapper of w defaulting to 200
+func acquireLoggingResponseWriter(w http.ResponseWriter) *loggingResponseWriter {
+	wrapped := loggingResponseWriterPool.Get().(*loggingResponseWriter)
+	wrapped.ResponseWriter = w
+	wrapped.statusCode = http.StatusOK
+	wrapped.written = false
+	return wrapped
+}
+
+// releaseLoggingResponseWriter returns a wrapper to the pool once the handler it
+// was passed to has returned
+func releaseLoggingResponseWriter(w *loggingResponseWriter) {
+	w.ResponseWriter = nil
+	loggingResponseWriterPool.Put(w)
+}
+
 // WriteHeader captures the status code
 func (w *loggingResponseWriter) WriteHeader(statusCode int) {
 	if !w.written {
@@ -101,3 +126,9 @@ func (w *loggingResponseWriter) Write(b []byte) (int, error) {
 	}
 	return w.ResponseWriter.Write(b)
 }
+
+// Unwrap exposes the underlying writer so http.ResponseController can flush
+// streamed responses through this wrapper
+func (w *loggingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
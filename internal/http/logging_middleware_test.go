@@ -209,6 +209,14 @@ func TestLoggingMiddleware(t *testing.T) {
 }
 
 func TestLoggingResponseWriter(t *testing.T) {
+	t.Run("flushes through to the underlying writer", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		wrapped := &loggingResponseWriter{ResponseWriter: w, statusCode: 200}
+
+		assert.NoError(t, http.NewResponseController(wrapped).Flush())
+		assert.True(t, w.Flushed)
+	})
+
 	t.Run("captures status code", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		wrapped := &loggingResponseWriter{ResponseWriter: w, statusCode: 200}
@@ -253,6 +261,45 @@ func TestLoggingResponseWriter(t *testing.T) {
 	})
 }
 
+// TestLoggingMiddleware_Allocations guards the request hot path: with the log
+// level disabled the middleware allocates nothing, its writer coming from a pool
+func TestLoggingMiddleware_Allocations(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector allocates and drops pooled writers")
+	}
+
+	handler := NewLoggingMiddleware(zap.NewNop()).Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	req := httptest.NewRequest("GET", "/api/test", nil)
+	w := httptest.NewRecorder()
+
+	allocs := testing.AllocsPerRun(100, func() {
+		handler.ServeHTTP(w, req)
+	})
+	assert.Zero(t, allocs)
+}
+
+// TestLoggingMiddleware_PooledWriterIsReset logs each request's own status
+func TestLoggingMiddleware_PooledWriterIsReset(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	status := http.StatusNotFound
+	handler := NewLoggingMiddleware(zap.New(core)).Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+		}
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+	status = http.StatusOK
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/found", nil))
+
+	completed := logs.FilterMessage("http request completed").All()
+	assert.Len(t, completed, 2)
+	assert.Equal(t, int64(http.StatusNotFound), completed[0].ContextMap()["status"])
+	assert.Equal(t, int64(http.StatusOK), completed[1].ContextMap()["status"])
+}
+
 func BenchmarkLoggingMiddleware(b *testing.B) {
 	logger := zap.NewNop()
 	middleware := NewLoggingMiddleware(logger)
@@ -265,6 +312,7 @@ func BenchmarkLoggingMiddleware(b *testing.B) {
 
 	req := httptest.NewRequest("GET", "/api/test", nil)
 
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		w := httptest.NewRecorder()
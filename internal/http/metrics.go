@@ -2,8 +2,10 @@ package http
 
 import (
 	"fmt"
+	"mime"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -21,11 +23,28 @@ type MetricsCollector struct {
 	errorCount        map[string]int64          // error_type -> count
 
 	// Database metrics
-	db *store.DB
+	db           *store.DB
+	queryLatency map[string]*queryHistogram  // query name -> duration histogram
+	queryErrors  map[string]map[string]int64 // query name -> error class -> count
 
 	// Cache metrics
 	cacheHits   int64
 	cacheMisses int64
+
+	// Policy metrics
+	shadowDecisions map[string]map[string]int64 // "METHOD path" -> decision -> count
+
+	// RPC spend metrics
+	policyRPCCalls map[string]map[string]int64 // "METHOD path" -> RPC method -> count
+	policyRPCCost  map[string]int64            // "METHOD path" -> estimated cost
+	tenantRPCCalls map[string]int64            // tenant -> count
+	tenantRPCCost  map[string]int64            // tenant -> estimated cost
+
+	// Middleware latency; observations carry trace IDs as exemplars
+	authLatency   map[string]*queryHistogram // result -> duration histogram
+	policyLatency map[string]*queryHistogram // decision -> duration histogram
+
+	created time.Time // When counting started, the created timestamp of every series
 }
 
 // NewMetricsCollector creates a new metrics collector
@@ -34,10 +53,27 @@ func NewMetricsCollector(db *store.DB) *MetricsCollector {
 		requestCount:     make(map[string]map[int]int64),
 		requestDurations: make(map[string][]float64),
 		errorCount:       make(map[string]int64),
+		shadowDecisions:  make(map[string]map[string]int64),
+		policyRPCCalls:   make(map[string]map[string]int64),
+		policyRPCCost:    make(map[string]int64),
+		tenantRPCCalls:   make(map[string]int64),
+		tenantRPCCost:    make(map[string]int64),
+		queryLatency:     make(map[string]*queryHistogram),
+		queryErrors:      make(map[string]map[string]int64),
+		authLatency:      make(map[string]*queryHistogram),
+		policyLatency:    make(map[string]*queryHistogram),
 		db:              db,
+		created:          time.Now(),
 	}
 }
 
+// Ensure MetricsCollector implements store.QueryObserver
+var _ store.QueryObserver = (*MetricsCollector)(nil)
+
+// maxRequestDurations is how many of the latest durations per endpoint the
+// request duration summary is computed over
+const maxRequestDurations = 1000
+
 // RecordRequest records a completed HTTP request
 func (m *MetricsCollector) RecordRequest(endpoint string, statusCode int, duration time.Duration) {
 	m.mu.Lock()
@@ -49,14 +85,17 @@ func (m *MetricsCollector) RecordRequest(endpoint string, statusCode int, durati
 	}
 	m.requestCount[endpoint][statusCode]++
 
-	// Record duration (convert to seconds)
+	// Record duration (convert to seconds), keeping only the last 1000 durations
+	// per endpoint to prevent unbounded memory growth. A full window is shifted in
+	// place rather than resliced, so it is not reallocated by the next append.
 	durationSeconds := duration.Seconds()
-	m.requestDurations[endpoint] = append(m.requestDurations[endpoint], durationSeconds)
-
-	// Keep only last 1000 durations per endpoint to prevent unbounded memory growth
-	if len(m.requestDurations[endpoint]) > 1000 {
-		m.requestDurations[endpoint] = m.requestDurations[endpoint][1:]
+	durations := m.requestDurations[endpoint]
+	if len(durations) < maxRequestDurations {
+		m.requestDurations[endpoint] = append(durations, durationSeconds)
+		return
 	}
+	copy(durations, durations[1:])
+	durations[len(durations)-1] = durationSeconds
 }
 
 // RecordError records an error occurrence
@@ -83,13 +122,311 @@ func (m *MetricsCollector) RecordCacheMiss() {
 	m.cacheMisses++
 }
 
-// ServeHTTP serves metrics in Prometheus text format
+// RecordShadowDecision records the would-be decision of a shadow policy
+func (m *MetricsCollector) RecordShadowDecision(policy, decision string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.shadowDecisions[policy] == nil {
+		m.shadowDecisions[policy] = make(map[string]int64)
+	}
+	m.shadowDecisions[policy][decision]++
+}
+
+// RecordRPCUsage records the RPC calls, per method, and their estimated cost made
+// while evaluating a policy. An empty tenant is not recorded per tenant.
+func (m *MetricsCollector) RecordRPCUsage(policy, tenant string, calls map[string]int64, cost int64) {
+	if len(calls) == 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.policyRPCCalls[policy] == nil {
+		m.policyRPCCalls[policy] = make(map[string]int64)
+	}
+	var total int64
+	for method, count := range calls {
+		m.policyRPCCalls[policy][method] += count
+		total += count
+	}
+	m.policyRPCCost[policy] += cost
+
+	if tenant != "" {
+		m.tenantRPCCalls[tenant] += total
+		m.tenantRPCCost[tenant] += cost
+	}
+}
+
+// queryDurationBuckets are the upper bounds, in seconds, of the duration histograms
+var queryDurationBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5}
+
+// queryHistogram is a duration histogram over queryDurationBuckets
+type queryHistogram struct {
+	counts    []int64     // Per bucket of queryDurationBuckets, non-cumulative
+	exemplars []*exemplar // Latest traced observation per bucket, the last one for +Inf
+	count     int64
+	sum       float64
+}
+
+// exemplar links a histogram bucket to the trace of one observation in it
+type exemplar struct {
+	traceID string
+	value   float64
+	at      time.Time
+}
+
+func newQueryHistogram() *queryHistogram {
+	return &queryHistogram{
+		counts:    make([]int64, len(queryDurationBuckets)),
+		exemplars: make([]*exemplar, len(queryDurationBuckets)+1),
+	}
+}
+
+// observe adds one duration; a non-empty traceID replaces the bucket's exemplar
+func (h *queryHistogram) observe(duration time.Duration, traceID string) {
+	seconds := duration.Seconds()
+	bucket := len(queryDurationBuckets)
+	for i, bound := range queryDurationBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+			bucket = i
+			break
+		}
+	}
+	h.count++
+	h.sum += seconds
+
+	if traceID != "" {
+		h.exemplars[bucket] = &exemplar{traceID: traceID, value: seconds, at: time.Now()}
+	}
+}
+
+// ObserveQuery records a repository statement; implements store.QueryObserver
+func (m *MetricsCollector) ObserveQuery(name string, duration time.Duration, errorClass string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h := m.queryLatency[name]
+	if h == nil {
+		h = newQueryHistogram()
+		m.queryLatency[name] = h
+	}
+	h.observe(duration, "")
+
+	if errorClass != "" {
+		if m.queryErrors[name] == nil {
+			m.queryErrors[name] = make(map[string]int64)
+		}
+		m.queryErrors[name][errorClass]++
+	}
+}
+
+// ObserveAuth records how long authentication took and whether it passed
+func (m *MetricsCollector) ObserveAuth(passed bool, duration time.Duration, traceID string) {
+	result := "rejected"
+	if passed {
+		result = "passed"
+	}
+	m.observeLatency(m.authLatency, result, duration, traceID)
+}
+
+// ObservePolicyEvaluation records how long evaluating the policies of a request took
+func (m *MetricsCollector) ObservePolicyEvaluation(decision string, duration time.Duration, traceID string) {
+	m.observeLatency(m.policyLatency, decision, duration, traceID)
+}
+
+// observeLatency adds a duration to the labelled histogram of a middleware
+func (m *MetricsCollector) observeLatency(histograms map[string]*queryHistogram, label string, duration time.Duration, traceID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h := histograms[label]
+	if h == nil {
+		h = newQueryHistogram()
+		histograms[label] = h
+	}
+	h.observe(duration, traceID)
+}
+
+// Exposition formats /metrics serves. OpenMetrics is the only format that can
+// carry exemplars and created timestamps.
+const (
+	textContentType        = "text/plain; version=0.0.4"
+	openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+	// openMetricsLegacyContentType is the pre-1.0 OpenMetrics version older
+	// scrapers ask for; its exposition is the same
+	openMetricsLegacyContentType = "application/openmetrics-text; version=0.0.1; charset=utf-8"
+)
+
+// negotiateExposition picks the exposition format of the media ranges the
+// scraper accepts, preferring the highest quality and, among equals, the range
+// listed first. Scrapers accepting nothing /metrics serves get the text format.
+func negotiateExposition(r *http.Request) (contentType string, openMetrics bool) {
+	bestQuality := 0.0
+	contentType = textContentType
+	for _, mediaRange := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality <= bestQuality {
+			continue
+		}
+
+		switch mediaType {
+		case "application/openmetrics-text":
+			switch params["version"] {
+			case "", "1.0.0":
+				contentType, openMetrics = openMetricsContentType, true
+			case "0.0.1":
+				contentType, openMetrics = openMetricsLegacyContentType, true
+			default:
+				continue
+			}
+		case "text/plain", "text/*", "*/*":
+			if version := params["version"]; version != "" && version != "0.0.4" {
+				continue
+			}
+			contentType, openMetrics = textContentType, false
+		default:
+			continue
+		}
+		bestQuality = quality
+	}
+	return contentType, openMetrics
+}
+
+// writeHistogram writes the labelled histograms of one metric family, sorted by
+// label value. Exemplars are only written in OpenMetrics.
+func writeHistogram(output *strings.Builder, name, labelName string, histograms map[string]*queryHistogram, openMetrics bool) {
+	values := make([]string, 0, len(histograms))
+	for value := range histograms {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	for _, value := range values {
+		h := histograms[value]
+		label := sanitizeLabel(value)
+		var cumulative int64
+		for i := 0; i <= len(queryDurationBuckets); i++ {
+			bound := "+Inf"
+			if i < len(queryDurationBuckets) {
+				cumulative += h.counts[i]
+				bound = fmt.Sprintf("%g", queryDurationBuckets[i])
+			} else {
+				cumulative = h.count
+			}
+			output.WriteString(fmt.Sprintf(`%s_bucket{%s="%s",le="%s"} %d`, name, labelName, label, bound, cumulative))
+			if e := h.exemplars[i]; openMetrics && e != nil {
+				output.WriteString(fmt.Sprintf(
+					` # {trace_id="%s"} %.6f %.3f`,
+					e.traceID, e.value, float64(e.at.UnixMilli())/1000,
+				))
+			}
+			output.WriteString("\n")
+		}
+		output.WriteString(fmt.Sprintf(`%s_sum{%s="%s"} %.6f`+"\n", name, labelName, label, h.sum))
+		output.WriteString(fmt.Sprintf(`%s_count{%s="%s"} %d`+"\n", name, labelName, label, h.count))
+	}
+}
+
+// toOpenMetrics converts the text exposition to OpenMetrics: no blank lines,
+// counter families named without their _total suffix while their samples keep
+// it, units declared for families measured in seconds, a _created timestamp
+// after every counter sample and every summary and histogram count, and a
+// closing # EOF. Counters only reset when the process restarts, so every series
+// is created when the collector was.
+func toOpenMetrics(text string, created time.Time) string {
+	createdValue := fmt.Sprintf("%.3f", float64(created.UnixMilli())/1000)
+	familyTypes := make(map[string]string) // family name in the text format -> type
+
+	var output strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			continue
+		}
+
+		if fields := strings.Fields(line); len(fields) >= 3 && fields[0] == "#" {
+			family := fields[2]
+			if fields[1] == "TYPE" && len(fields) >= 4 {
+				familyTypes[family] = fields[3]
+			}
+			name := family
+			if fields[1] == "TYPE" || fields[1] == "HELP" {
+				if familyTypes[family] == "counter" || strings.HasSuffix(family, "_total") {
+					name = strings.TrimSuffix(family, "_total")
+					line = strings.Replace(line, family, name, 1)
+				}
+			}
+			output.WriteString(line)
+			output.WriteString("\n")
+			if fields[1] == "TYPE" && strings.HasSuffix(name, "_seconds") {
+				output.WriteString("# UNIT " + name + " seconds\n")
+			}
+			continue
+		}
+
+		series, labels := splitSample(line)
+		switch {
+		case familyTypes[series] == "counter":
+			name := strings.TrimSuffix(series, "_total")
+			if series == name {
+				// OpenMetrics counter samples are named with the _total suffix
+				line = name + "_total" + strings.TrimPrefix(line, series)
+			}
+			output.WriteString(line + "\n")
+			output.WriteString(name + "_created" + labels + " " + createdValue + "\n")
+		case strings.HasSuffix(series, "_count") && isAggregate(familyTypes[strings.TrimSuffix(series, "_count")]):
+			output.WriteString(line + "\n")
+			output.WriteString(strings.TrimSuffix(series, "_count") + "_created" + labels + " " + createdValue + "\n")
+		default:
+			output.WriteString(line + "\n")
+		}
+	}
+	output.WriteString("# EOF\n")
+	return output.String()
+}
+
+// splitSample returns the metric name of a sample line and its label set,
+// including the braces, if any
+func splitSample(line string) (name, labels string) {
+	end := strings.IndexAny(line, "{ ")
+	if end < 0 {
+		return line, ""
+	}
+	name = line[:end]
+	if line[end] == '{' {
+		if closing := strings.Index(line[end:], "}"); closing >= 0 {
+			labels = line[end : end+closing+1]
+		}
+	}
+	return name, labels
+}
+
+// isAggregate reports whether a metric type has _count and _sum samples
+func isAggregate(metricType string) bool {
+	return metricType == "summary" || metricType == "histogram"
+}
+
+// ServeHTTP serves metrics in Prometheus text format, or in OpenMetrics with
+// trace exemplars when the scraper accepts it
 // GET /metrics
 func (m *MetricsCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	contentType, openMetrics := negotiateExposition(r)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Vary", "Accept")
 
 	var output strings.Builder
 
@@ -195,6 +532,40 @@ func (m *MetricsCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		output.WriteString(fmt.Sprintf("db_connections_idle %d\n", stats.Idle))
 	}
 
+	// Write database query metrics
+	if len(m.queryLatency) > 0 {
+		output.WriteString("\n# HELP db_query_duration_seconds Repository query duration in seconds\n")
+		output.WriteString("# TYPE db_query_duration_seconds histogram\n")
+
+		writeHistogram(&output, "db_query_duration_seconds", "query", m.queryLatency, openMetrics)
+	}
+
+	if len(m.queryErrors) > 0 {
+		output.WriteString("\n# HELP db_query_errors_total Failed repository queries by error class\n")
+		output.WriteString("# TYPE db_query_errors_total counter\n")
+
+		queries := make([]string, 0, len(m.queryErrors))
+		for query := range m.queryErrors {
+			queries = append(queries, query)
+		}
+		sort.Strings(queries)
+
+		for _, query := range queries {
+			classes := make([]string, 0, len(m.queryErrors[query]))
+			for class := range m.queryErrors[query] {
+				classes = append(classes, class)
+			}
+			sort.Strings(classes)
+
+			for _, class := range classes {
+				output.WriteString(fmt.Sprintf(
+					`db_query_errors_total{query="%s",class="%s"} %d`+"\n",
+					sanitizeLabel(query), class, m.queryErrors[query][class],
+				))
+			}
+		}
+	}
+
 	// Write cache metrics
 	totalCacheRequests := m.cacheHits + m.cacheMisses
 	if totalCacheRequests > 0 {
@@ -212,6 +583,112 @@ func (m *MetricsCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		output.WriteString(fmt.Sprintf("cache_hit_rate %.4f\n", hitRate))
 	}
 
+	// Write shadow policy metrics
+	if len(m.shadowDecisions) > 0 {
+		output.WriteString("\n# HELP policy_shadow_decisions_total Decisions shadow policies would have made\n")
+		output.WriteString("# TYPE policy_shadow_decisions_total counter\n")
+
+		policies := make([]string, 0, len(m.shadowDecisions))
+		for policy := range m.shadowDecisions {
+			policies = append(policies, policy)
+		}
+		sort.Strings(policies)
+
+		for _, policy := range policies {
+			decisions := make([]string, 0, len(m.shadowDecisions[policy]))
+			for decision := range m.shadowDecisions[policy] {
+				decisions = append(decisions, decision)
+			}
+			sort.Strings(decisions)
+
+			for _, decision := range decisions {
+				output.WriteString(fmt.Sprintf(
+					`policy_shadow_decisions_total{policy="%s",decision="%s"} %d`+"\n",
+					sanitizeLabel(policy), decision, m.shadowDecisions[policy][decision],
+				))
+			}
+		}
+	}
+
+	// Write RPC spend metrics
+	if len(m.policyRPCCalls) > 0 {
+		output.WriteString("\n# HELP policy_rpc_calls_total RPC calls made while evaluating policies\n")
+		output.WriteString("# TYPE policy_rpc_calls_total counter\n")
+
+		policies := make([]string, 0, len(m.policyRPCCalls))
+		for policy := range m.policyRPCCalls {
+			policies = append(policies, policy)
+		}
+		sort.Strings(policies)
+
+		for _, policy := range policies {
+			methods := make([]string, 0, len(m.policyRPCCalls[policy]))
+			for method := range m.policyRPCCalls[policy] {
+				methods = append(methods, method)
+			}
+			sort.Strings(methods)
+
+			for _, method := range methods {
+				output.WriteString(fmt.Sprintf(
+					`policy_rpc_calls_total{policy="%s",method="%s"} %d`+"\n",
+					sanitizeLabel(policy), sanitizeLabel(method), m.policyRPCCalls[policy][method],
+				))
+			}
+		}
+
+		output.WriteString("\n# HELP policy_rpc_cost_total Estimated provider cost of the RPC calls made while evaluating policies\n")
+		output.WriteString("# TYPE policy_rpc_cost_total counter\n")
+		for _, policy := range policies {
+			output.WriteString(fmt.Sprintf(
+				`policy_rpc_cost_total{policy="%s"} %d`+"\n",
+				sanitizeLabel(policy), m.policyRPCCost[policy],
+			))
+		}
+	}
+
+	if len(m.tenantRPCCalls) > 0 {
+		tenants := make([]string, 0, len(m.tenantRPCCalls))
+		for tenant := range m.tenantRPCCalls {
+			tenants = append(tenants, tenant)
+		}
+		sort.Strings(tenants)
+
+		output.WriteString("\n# HELP tenant_rpc_calls_total RPC calls made while evaluating policies for each tenant\n")
+		output.WriteString("# TYPE tenant_rpc_calls_total counter\n")
+		for _, tenant := range tenants {
+			output.WriteString(fmt.Sprintf(
+				`tenant_rpc_calls_total{tenant="%s"} %d`+"\n",
+				sanitizeLabel(tenant), m.tenantRPCCalls[tenant],
+			))
+		}
+
+		output.WriteString("\n# HELP tenant_rpc_cost_total Estimated provider cost of the RPC calls made for each tenant\n")
+		output.WriteString("# TYPE tenant_rpc_cost_total counter\n")
+		for _, tenant := range tenants {
+			output.WriteString(fmt.Sprintf(
+				`tenant_rpc_cost_total{tenant="%s"} %d`+"\n",
+				sanitizeLabel(tenant), m.tenantRPCCost[tenant],
+			))
+		}
+	}
+
+	// Write middleware latency metrics
+	if len(m.authLatency) > 0 {
+		output.WriteString("\n# HELP auth_duration_seconds Time spent authenticating requests in seconds\n")
+		output.WriteString("# TYPE auth_duration_seconds histogram\n")
+		writeHistogram(&output, "auth_duration_seconds", "result", m.authLatency, openMetrics)
+	}
+
+	if len(m.policyLatency) > 0 {
+		output.WriteString("\n# HELP policy_evaluation_duration_seconds Time spent evaluating access policies in seconds\n")
+		output.WriteString("# TYPE policy_evaluation_duration_seconds histogram\n")
+		writeHistogram(&output, "policy_evaluation_duration_seconds", "decision", m.policyLatency, openMetrics)
+	}
+
+	if openMetrics {
+		w.Write([]byte(toOpenMetrics(output.String(), m.created)))
+		return
+	}
 	w.Write([]byte(output.String()))
 }
 
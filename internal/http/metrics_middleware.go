@@ -3,6 +3,7 @@ package http
 import (
 	"context"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -39,22 +40,21 @@ func (m *MetricsMiddleware) Middleware() func(http.Handler) http.Handler {
 			r = r.WithContext(ctx)
 
 			// Wrap response writer to capture status code
-			wrapped := &responseWriter{
-				ResponseWriter: w,
-				statusCode:     http.StatusOK, // Default to 200
-			}
+			wrapped := acquireResponseWriter(w)
 
 			// Call next handler
 			next.ServeHTTP(wrapped, r)
 
 			// Calculate duration
 			duration := time.Since(start)
+			statusCode := wrapped.statusCode
+			releaseResponseWriter(wrapped)
 
 			// Normalize endpoint for metrics (remove path parameters)
 			endpoint := normalizeEndpoint(r.Method, r.URL.Path)
 
 			// Record metrics
-			m.collector.RecordRequest(endpoint, wrapped.statusCode, duration)
+			m.collector.RecordRequest(endpoint, statusCode, duration)
 
 			// Log slow requests (> 1 second)
 			if duration > time.Second {
@@ -62,15 +62,15 @@ func (m *MetricsMiddleware) Middleware() func(http.Handler) http.Handler {
 					zap.String("request_id", requestID),
 					zap.String("method", r.Method),
 					zap.String("path", r.URL.Path),
-					zap.Int("status", wrapped.statusCode),
+					zap.Int("status", statusCode),
 					zap.Duration("duration", duration),
 					zap.String("remote_addr", r.RemoteAddr),
 				)
 			}
 
 			// Record error if status code indicates error
-			if wrapped.statusCode >= 400 {
-				errorType := getErrorType(wrapped.statusCode)
+			if statusCode >= 400 {
+				errorType := getErrorType(statusCode)
 				m.collector.RecordError(errorType)
 			}
 		})
@@ -84,6 +84,28 @@ type responseWriter struct {
 	written    bool
 }
 
+// responseWriterPool recycles the wrappers of the metrics middleware, which
+// every request passes through
+var responseWriterPool = sync.Pool{
+	New: func() interface{} { return new(responseWriter) },
+}
+
+// acquireResponseWriter returns a pooled wrapper of w defaulting to 200
+func acquireResponseWriter(w http.ResponseWriter) *responseWriter {
+	wrapped := responseWriterPool.Get().(*responseWriter)
+	wrapped.ResponseWriter = w
+	wrapped.statusCode = http.StatusOK
+	wrapped.written = false
+	return wrapped
+}
+
+// releaseResponseWriter returns a wrapper to the pool once the handler it was
+// passed to has returned
+func releaseResponseWriter(w *responseWriter) {
+	w.ResponseWriter = nil
+	responseWriterPool.Put(w)
+}
+
 // WriteHeader captures the status code
 func (w *responseWriter) WriteHeader(statusCode int) {
 	if !w.written {
@@ -101,6 +123,12 @@ func (w *responseWriter) Write(b []byte) (int, error) {
 	return w.ResponseWriter.Write(b)
 }
 
+// Unwrap exposes the underlying writer so http.ResponseController can flush
+// streamed responses through this wrapper
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // normalizeEndpoint normalizes endpoint paths for consistent metrics
 // Removes path parameters and query strings
 func normalizeEndpoint(method, path string) string {
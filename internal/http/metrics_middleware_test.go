@@ -322,6 +322,27 @@ func TestRequestIDFromContext(t *testing.T) {
 	})
 }
 
+// TestMetricsMiddleware_Allocations guards the request hot path. The request ID,
+// the request carrying it and the endpoint label allocate; the writer wrapper
+// comes from a pool.
+func TestMetricsMiddleware_Allocations(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector allocates and drops pooled writers")
+	}
+
+	collector := NewMetricsCollector(nil)
+	handler := NewMetricsMiddleware(collector, zap.NewNop()).Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	req := httptest.NewRequest("GET", "/api/test", nil)
+	w := httptest.NewRecorder()
+
+	allocs := testing.AllocsPerRun(100, func() {
+		handler.ServeHTTP(w, req)
+	})
+	assert.LessOrEqual(t, allocs, float64(6))
+}
+
 func BenchmarkMetricsMiddleware(b *testing.B) {
 	db := setupTestDB_middleware(&testing.T{})
 
@@ -337,6 +358,7 @@ func BenchmarkMetricsMiddleware(b *testing.B) {
 
 	req := httptest.NewRequest("GET", "/api/test", nil)
 
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		w := httptest.NewRecorder()
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -423,6 +424,22 @@ func TestMetricsCollector_Concurrency(t *testing.T) {
 	})
 }
 
+// TestMetricsCollector_RecordRequest_FullWindowAllocations keeps the duration
+// window of a busy endpoint in place
+func TestMetricsCollector_RecordRequest_FullWindowAllocations(t *testing.T) {
+	collector := NewMetricsCollector(nil)
+	for i := 0; i < maxRequestDurations; i++ {
+		collector.RecordRequest("GET /api/test", 200, time.Millisecond)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		collector.RecordRequest("GET /api/test", 200, 2*time.Millisecond)
+	})
+	assert.Zero(t, allocs)
+	assert.Len(t, collector.requestDurations["GET /api/test"], maxRequestDurations)
+	assert.InDelta(t, 0.002, collector.requestDurations["GET /api/test"][maxRequestDurations-1], 0.0001)
+}
+
 func BenchmarkMetricsCollector_RecordRequest(b *testing.B) {
 	db := setupTestDB(&testing.T{})
 
@@ -452,3 +469,172 @@ func BenchmarkMetricsCollector_ServeHTTP(b *testing.B) {
 		collector.ServeHTTP(w, req)
 	}
 }
+
+func TestMetricsCollector_RecordShadowDecision(t *testing.T) {
+	collector := NewMetricsCollector(nil)
+
+	collector.RecordShadowDecision("GET /api/data", "would_deny")
+	collector.RecordShadowDecision("GET /api/data", "would_deny")
+	collector.RecordShadowDecision("GET /api/data", "would_allow")
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	collector.ServeHTTP(w, req)
+
+	body := w.Body.String()
+	assert.Contains(t, body, "# TYPE policy_shadow_decisions_total counter")
+	assert.Contains(t, body, `policy_shadow_decisions_total{policy="GET /api/data",decision="would_allow"} 1`)
+	assert.Contains(t, body, `policy_shadow_decisions_total{policy="GET /api/data",decision="would_deny"} 2`)
+}
+
+func TestMetricsCollector_ObserveQuery(t *testing.T) {
+	collector := NewMetricsCollector(nil)
+
+	collector.ObserveQuery("api_keys.validate", 2*time.Millisecond, "")
+	collector.ObserveQuery("api_keys.validate", 40*time.Millisecond, "")
+	collector.ObserveQuery("api_keys.validate", 3*time.Second, store.ErrorClassTimeout)
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	collector.ServeHTTP(w, req)
+
+	body := w.Body.String()
+	assert.Contains(t, body, "# TYPE db_query_duration_seconds histogram")
+	assert.Contains(t, body, `db_query_duration_seconds_bucket{query="api_keys.validate",le="0.001"} 0`)
+	assert.Contains(t, body, `db_query_duration_seconds_bucket{query="api_keys.validate",le="0.0025"} 1`)
+	assert.Contains(t, body, `db_query_duration_seconds_bucket{query="api_keys.validate",le="0.05"} 2`)
+	assert.Contains(t, body, `db_query_duration_seconds_bucket{query="api_keys.validate",le="2.5"} 2`)
+	assert.Contains(t, body, `db_query_duration_seconds_bucket{query="api_keys.validate",le="+Inf"} 3`)
+	assert.Contains(t, body, `db_query_duration_seconds_sum{query="api_keys.validate"} 3.042000`)
+	assert.Contains(t, body, `db_query_duration_seconds_count{query="api_keys.validate"} 3`)
+	assert.Contains(t, body, `db_query_errors_total{query="api_keys.validate",class="timeout"} 1`)
+}
+
+func TestMetricsCollector_LatencyExemplars(t *testing.T) {
+	collector := NewMetricsCollector(nil)
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	collector.ObserveAuth(true, 2*time.Millisecond, "")
+	collector.ObserveAuth(true, 400*time.Millisecond, traceID)
+	collector.ObserveAuth(false, time.Millisecond, "")
+	collector.ObservePolicyEvaluation("denied", 30*time.Millisecond, traceID)
+	collector.RecordCacheHit()
+
+	// The Prometheus text format cannot carry exemplars
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	collector.ServeHTTP(w, req)
+
+	body := w.Body.String()
+	assert.Contains(t, body, `auth_duration_seconds_bucket{result="passed",le="0.5"} 2`+"\n")
+	assert.Contains(t, body, `auth_duration_seconds_count{result="rejected"} 1`)
+	assert.Contains(t, body, `policy_evaluation_duration_seconds_bucket{decision="denied",le="0.05"} 1`+"\n")
+	assert.NotContains(t, body, "trace_id")
+
+	// OpenMetrics attaches the trace to the bucket the observation fell in
+	req = httptest.NewRequest("GET", "/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text;version=1.0.0,text/plain;q=0.5")
+	w = httptest.NewRecorder()
+	collector.ServeHTTP(w, req)
+
+	assert.Equal(t, openMetricsContentType, w.Header().Get("Content-Type"))
+	body = w.Body.String()
+	assert.Contains(t, body, `auth_duration_seconds_bucket{result="passed",le="0.5"} 2 # {trace_id="`+traceID+`"} 0.400000 `)
+	assert.Contains(t, body, `auth_duration_seconds_bucket{result="passed",le="1"} 2`+"\n")
+	assert.Contains(t, body, `policy_evaluation_duration_seconds_bucket{decision="denied",le="0.05"} 1 # {trace_id="`+traceID+`"} 0.030000 `)
+	assert.Contains(t, body, "# TYPE cache_hits counter\n")
+	assert.Contains(t, body, "cache_hits_total 1\n")
+	assert.NotContains(t, body, "\n\n")
+	assert.True(t, strings.HasSuffix(body, "# EOF\n"))
+}
+
+func TestNegotiateExposition(t *testing.T) {
+	tests := []struct {
+		name        string
+		accept      string
+		contentType string
+		openMetrics bool
+	}{
+		{"no accept header", "", textContentType, false},
+		{"prometheus scrape", "application/openmetrics-text;version=1.0.0,application/openmetrics-text;version=0.0.1;q=0.75,text/plain;version=0.0.4;q=0.5,*/*;q=0.1", openMetricsContentType, true},
+		{"openmetrics without version", "application/openmetrics-text", openMetricsContentType, true},
+		{"legacy openmetrics", "application/openmetrics-text; version=0.0.1", openMetricsLegacyContentType, true},
+		{"text preferred", "application/openmetrics-text;q=0.5,text/plain;version=0.0.4", textContentType, false},
+		{"equal quality keeps first", "text/plain,application/openmetrics-text", textContentType, false},
+		{"openmetrics refused", "application/openmetrics-text;q=0,text/plain;q=0.1", textContentType, false},
+		{"unsupported version", "application/openmetrics-text;version=2.0.0", textContentType, false},
+		{"unsupported type", "application/json", textContentType, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/metrics", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			contentType, openMetrics := negotiateExposition(req)
+			assert.Equal(t, tt.contentType, contentType)
+			assert.Equal(t, tt.openMetrics, openMetrics)
+		})
+	}
+}
+
+func TestMetricsCollector_OpenMetricsExposition(t *testing.T) {
+	collector := NewMetricsCollector(nil)
+	collector.created = time.UnixMilli(1698854400123)
+	collector.RecordRequest("/api/data", 200, 20*time.Millisecond)
+	collector.RecordError("timeout")
+	collector.ObserveAuth(true, 2*time.Millisecond, "")
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text;version=1.0.0")
+	w := httptest.NewRecorder()
+	collector.ServeHTTP(w, req)
+
+	assert.Equal(t, "Accept", w.Header().Get("Vary"))
+	body := w.Body.String()
+
+	// Counter families drop _total, their samples keep it and are followed by _created
+	assert.Contains(t, body, "# TYPE http_requests counter\n")
+	assert.Contains(t, body, "# HELP http_requests Total number of HTTP requests\n")
+	assert.Contains(t, body, `http_requests_total{endpoint="/api/data",status="200"} 1`+"\n"+
+		`http_requests_created{endpoint="/api/data",status="200"} 1698854400.123`+"\n")
+	assert.Contains(t, body, `http_errors_total{type="timeout"} 1`+"\n"+`http_errors_created{type="timeout"} 1698854400.123`+"\n")
+
+	// Summaries and histograms get a unit and a _created sample per series
+	assert.Contains(t, body, "# TYPE http_request_duration_seconds summary\n# UNIT http_request_duration_seconds seconds\n")
+	assert.Contains(t, body, `http_request_duration_seconds_created{endpoint="/api/data"} 1698854400.123`+"\n")
+	assert.Contains(t, body, "# UNIT auth_duration_seconds seconds\n")
+	assert.Contains(t, body, `auth_duration_seconds_count{result="passed"} 1`+"\n"+
+		`auth_duration_seconds_created{result="passed"} 1698854400.123`+"\n")
+	assert.NotContains(t, body, "_total_created")
+	assert.True(t, strings.HasSuffix(body, "# EOF\n"))
+
+	// The text format has neither
+	req = httptest.NewRequest("GET", "/metrics", nil)
+	w = httptest.NewRecorder()
+	collector.ServeHTTP(w, req)
+	assert.NotContains(t, w.Body.String(), "_created")
+	assert.NotContains(t, w.Body.String(), "# UNIT")
+}
+
+func TestMetricsCollector_RecordRPCUsage(t *testing.T) {
+	collector := NewMetricsCollector(nil)
+
+	collector.RecordRPCUsage("GET /api/data", "acme", map[string]int64{"eth_call": 2, "eth_getLogs": 1}, 127)
+	collector.RecordRPCUsage("GET /api/data", "", map[string]int64{"eth_call": 1}, 26)
+	collector.RecordRPCUsage("GET /api/cached", "acme", map[string]int64{}, 0)
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	collector.ServeHTTP(w, req)
+
+	body := w.Body.String()
+	assert.Contains(t, body, "# TYPE policy_rpc_calls_total counter")
+	assert.Contains(t, body, `policy_rpc_calls_total{policy="GET /api/data",method="eth_call"} 3`)
+	assert.Contains(t, body, `policy_rpc_calls_total{policy="GET /api/data",method="eth_getLogs"} 1`)
+	assert.Contains(t, body, `policy_rpc_cost_total{policy="GET /api/data"} 153`)
+	assert.Contains(t, body, `tenant_rpc_calls_total{tenant="acme"} 3`)
+	assert.Contains(t, body, `tenant_rpc_cost_total{tenant="acme"} 127`)
+	assert.NotContains(t, body, "/api/cached")
+}
@@ -13,6 +13,17 @@ type contextKey string
 
 const ClaimsContextKey contextKey = "jwt_claims"
 
+// AuthMethodContextKey is the key used to store how the request was authenticated
+const AuthMethodContextKey contextKey = "auth_method"
+
+// Authentication methods recorded in the request context
+const (
+	AuthMethodJWT        = "jwt"
+	AuthMethodAPIKey     = "api_key"
+	AuthMethodCapability = "capability"
+	AuthMethodSignedURL  = "signed_url"
+)
+
 // Middleware is a function that wraps an HTTP handler
 type Middleware func(http.Handler) http.Handler
 
@@ -20,6 +31,12 @@ type Middleware func(http.Handler) http.Handler
 func JWTMiddleware(jwtService *auth.JWTService) Middleware {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Already authenticated by CapabilityMiddleware or SignedURLMiddleware
+			if method := AuthMethodFromContext(r); method == AuthMethodCapability || method == AuthMethodSignedURL {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			// Extract token from Authorization header
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
@@ -28,14 +45,12 @@ func JWTMiddleware(jwtService *auth.JWTService) Middleware {
 			}
 
 			// Parse "Bearer <token>" format
-			parts := strings.Fields(authHeader)
-			if len(parts) != 2 || parts[0] != "Bearer" {
+			token, ok := parseBearerToken(authHeader)
+			if !ok {
 				http.Error(w, "invalid authorization header format", http.StatusUnauthorized)
 				return
 			}
 
-			token := parts[1]
-
 			// Verify token
 			claims, err := jwtService.VerifyToken(r.Context(), token)
 			if err != nil {
@@ -43,8 +58,21 @@ func JWTMiddleware(jwtService *auth.JWTService) Middleware {
 				return
 			}
 
+			// Reject tokens revoked before they expire, e.g. by logging out
+			revoked, err := jwtService.IsRevoked(r.Context(), claims)
+			if err != nil {
+				http.Error(w, "unable to check token revocation", http.StatusServiceUnavailable)
+				return
+			}
+			if revoked {
+				http.Error(w, "token has been revoked", http.StatusUnauthorized)
+				return
+			}
+
 			// Add claims to request context
 			ctx := context.WithValue(r.Context(), ClaimsContextKey, claims)
+			ctx = context.WithValue(ctx, AuthMethodContextKey, AuthMethodJWT)
+			ctx = withAuthenticatedLogger(ctx, claims)
 			r = r.WithContext(ctx)
 
 			// Call next handler
@@ -53,6 +81,22 @@ func JWTMiddleware(jwtService *auth.JWTService) Middleware {
 	}
 }
 
+// parseBearerToken returns the token of an "Authorization: Bearer <token>" header value,
+// separated by any run of whitespace. It reads the header in place, without
+// splitting it, as every authenticated request passes through it.
+func parseBearerToken(header string) (string, bool) {
+	header = strings.TrimSpace(header)
+	end := strings.IndexAny(header, " \t")
+	if end < 0 || header[:end] != "Bearer" {
+		return "", false
+	}
+	token := strings.TrimSpace(header[end:])
+	if token == "" || strings.ContainsAny(token, " \t\r\n\v\f") {
+		return "", false
+	}
+	return token, true
+}
+
 // ClaimsFromContext extracts JWT claims from request context
 func ClaimsFromContext(r *http.Request) *auth.Claims {
 	claims, ok := r.Context().Value(ClaimsContextKey).(*auth.Claims)
@@ -61,3 +105,9 @@ func ClaimsFromContext(r *http.Request) *auth.Claims {
 	}
 	return claims
 }
+
+// AuthMethodFromContext returns how the request was authenticated, or "" if it was not
+func AuthMethodFromContext(r *http.Request) string {
+	method, _ := r.Context().Value(AuthMethodContextKey).(string)
+	return method
+}
@@ -2,6 +2,7 @@ package http
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -168,3 +169,109 @@ func TestJWTMiddleware_PreservesContext(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, rec.Code)
 }
+
+// failingRevocationList fails every lookup
+type failingRevocationList struct{}
+
+func (failingRevocationList) RevokeToken(ctx context.Context, id string, expiresAt time.Time) error {
+	return errors.New("database unavailable")
+}
+
+func (failingRevocationList) IsTokenRevoked(ctx context.Context, id string) (bool, error) {
+	return false, errors.New("database unavailable")
+}
+
+// TestJWTMiddleware_RejectsRevokedToken returns 401 for tokens revoked before they expire
+func TestJWTMiddleware_RejectsRevokedToken(t *testing.T) {
+	jwtService := auth.NewJWTService([]byte("test-secret-key-at-least-32-chars"), time.Hour)
+	jwtService.SetRevocationList(auth.NewMemoryRevocationList())
+	ctx := context.Background()
+
+	revoked, err := jwtService.GenerateToken(ctx, "0x742d35Cc6634C0532925a3b844Bc390e38f3dF8c", nil)
+	require.NoError(t, err)
+	claims, err := jwtService.VerifyToken(ctx, revoked)
+	require.NoError(t, err)
+	require.NoError(t, jwtService.RevokeToken(ctx, claims))
+	active, err := jwtService.GenerateToken(ctx, "0x742d35Cc6634C0532925a3b844Bc390e38f3dF8c", nil)
+	require.NoError(t, err)
+
+	handler := JWTMiddleware(jwtService)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(revoked)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "revoked")
+	assert.Equal(t, http.StatusOK, serve(active).Code)
+
+	// The revocation list being unavailable never lets a token through
+	jwtService.SetRevocationList(failingRevocationList{})
+	assert.Equal(t, http.StatusServiceUnavailable, serve(active).Code)
+}
+
+// TestParseBearerToken accepts the token of a Bearer header separated by any whitespace
+func TestParseBearerToken(t *testing.T) {
+	tests := []struct {
+		header string
+		token  string
+		ok     bool
+	}{
+		{"Bearer abc.def.ghi", "abc.def.ghi", true},
+		{"  Bearer   abc.def.ghi  ", "abc.def.ghi", true},
+		{"Bearer\tabc", "abc", true},
+		{"", "", false},
+		{"Bearer", "", false},
+		{"Bearer ", "", false},
+		{"bearer abc", "", false},
+		{"Basic dXNlcjpwYXNz", "", false},
+		{"Bearer abc def", "", false},
+		{"Bearerabc", "", false},
+	}
+
+	for _, tt := range tests {
+		token, ok := parseBearerToken(tt.header)
+		assert.Equal(t, tt.ok, ok, "header %q", tt.header)
+		assert.Equal(t, tt.token, token, "header %q", tt.header)
+	}
+}
+
+// TestParseBearerToken_Allocations guards the header parsing every authenticated request does
+func TestParseBearerToken_Allocations(t *testing.T) {
+	allocs := testing.AllocsPerRun(100, func() {
+		parseBearerToken("Bearer eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.c2ln")
+	})
+	assert.Zero(t, allocs)
+}
+
+func BenchmarkJWTMiddleware(b *testing.B) {
+	jwtService := auth.NewJWTService([]byte("test-secret-key-at-least-32-chars"), 24*time.Hour)
+	token, err := jwtService.GenerateToken(context.Background(), "0x742d35Cc6634C0532925a3b844Bc390e38f3dF8c", []string{"auth"})
+	require.NoError(b, err)
+
+	handler := JWTMiddleware(jwtService)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handler.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkParseBearerToken(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		parseBearerToken("Bearer eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.c2ln")
+	}
+}
@@ -0,0 +1,247 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/yourusername/gatekeeper/internal/audit"
+	"github.com/yourusername/gatekeeper/internal/log"
+	"github.com/yourusername/gatekeeper/internal/oidc"
+)
+
+// oidcSignInPage asks the user to sign a SIWE message with their wallet and
+// posts it, with the authorization request, back to the authorization endpoint
+var oidcSignInPage = template.Must(template.New("signin").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Sign in to {{.ClientName}}</title>
+<style>
+body { font-family: system-ui, sans-serif; display: flex; justify-content: center; margin-top: 15vh; }
+main { max-width: 24rem; text-align: center; }
+button { font-size: 1rem; padding: 0.6rem 1.2rem; cursor: pointer; }
+.error { color: #b00020; }
+</style>
+</head>
+<body>
+<main>
+<h1>Sign in to {{.ClientName}}</h1>
+<p>Sign a message with your Ethereum wallet to continue. Signing is free and does not send a transaction.</p>
+{{if .Error}}<p class="error" role="alert">{{.Error}}</p>{{end}}
+<form id="signin" method="post" action="{{.Action}}" data-nonce-url="{{.NonceURL}}" data-statement="{{.Statement}}">
+{{range $name, $values := .Params}}{{range $values}}<input type="hidden" name="{{$name}}" value="{{.}}">
+{{end}}{{end}}<input type="hidden" name="message">
+<input type="hidden" name="signature">
+<button type="submit">Sign in with Ethereum</button>
+</form>
+<p id="status" class="error" role="status"></p>
+</main>
+<script>
+const form = document.getElementById('signin');
+const status = document.getElementById('status');
+form.addEventListener('submit', async (event) => {
+  event.preventDefault();
+  if (!window.ethereum) {
+    status.textContent = 'No Ethereum wallet was found in this browser.';
+    return;
+  }
+  try {
+    const [address] = await window.ethereum.request({ method: 'eth_requestAccounts' });
+    const chainId = parseInt(await window.ethereum.request({ method: 'eth_chainId' }), 16);
+    const { nonce } = await (await fetch(form.dataset.nonceUrl)).json();
+    const message = location.host + ' wants you to sign in with your Ethereum account:\n' + address + '\n\n' +
+      form.dataset.statement + '\n\nURI: ' + location.origin + '\nVersion: 1\nChain ID: ' + chainId +
+      '\nNonce: ' + nonce + '\nIssued At: ' + new Date().toISOString();
+    const signature = await window.ethereum.request({ method: 'personal_sign', params: [message, address] });
+    form.elements.message.value = message;
+    form.elements.signature.value = signature;
+    form.submit();
+  } catch (err) {
+    status.textContent = err.message || String(err);
+  }
+});
+</script>
+</body>
+</html>
+`))
+
+// oidcSignInData fills the sign-in page
+type oidcSignInData struct {
+	ClientName string
+	Action     string
+	NonceURL   string
+	Statement  string
+	Params     url.Values // The authorization request, posted back with the proof
+	Error      string
+}
+
+// OIDCDiscovery handles GET /.well-known/openid-configuration - OpenID Provider metadata
+func (h *AuthHandler) OIDCDiscovery(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if err := json.NewEncoder(w).Encode(h.oidcProvider.Discovery()); err != nil {
+		requestLogger(r, h.logger).Error("failed to encode OIDC discovery document", log.Error(err))
+	}
+}
+
+// OIDCAuthorize handles GET and POST /oidc/authorize - the authorization
+// endpoint. GET shows the sign-in page; the page posts the signed SIWE message
+// back, and a valid proof redirects to the client with an authorization code.
+func (h *AuthHandler) OIDCAuthorize(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+	req := oidc.ParseAuthorizationRequest(r.Form)
+
+	client, err := h.oidcProvider.ValidateAuthorization(req)
+	if client == nil {
+		// Never redirect to an unregistered URI
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var oauthErr *oidc.Error
+	if errors.As(err, &oauthErr) {
+		http.Redirect(w, r, oidc.ErrorRedirect(req, oauthErr), http.StatusFound)
+		return
+	}
+
+	page := oidcSignInData{
+		ClientName: client.Name,
+		Action:     h.oidcProvider.Issuer() + "/oidc/authorize",
+		NonceURL:   h.oidcProvider.Issuer() + "/auth/siwe/nonce",
+		Statement:  "Sign in to " + client.Name + " with Gatekeeper",
+		Params:     req.Values(),
+	}
+	if r.Method != http.MethodPost {
+		h.writeSignInPage(w, r, page, http.StatusOK)
+		return
+	}
+
+	signIn, signInErr := h.signIn(r, r.PostForm.Get("message"), r.PostForm.Get("signature"))
+	if signInErr != nil {
+		page.Error = signInErr.message
+		h.writeSignInPage(w, r, page, signInErr.status)
+		return
+	}
+
+	code, err := h.oidcProvider.IssueCode(req, signIn.address)
+	if err != nil {
+		requestLogger(r, h.logger).Error("failed to issue authorization code", log.Error(err))
+		http.Error(w, "failed to issue authorization code", http.StatusInternalServerError)
+		return
+	}
+
+	if h.auditLogger != nil {
+		metadata := map[string]interface{}{
+			"method":    "oidc",
+			"client_id": client.ID,
+		}
+		if signIn.wallet != "" {
+			metadata["wallet"] = signIn.wallet
+		}
+		h.auditLogger.LogAuthAttempt(r.Context(), withClientInfo(r, audit.AuditEvent{
+			Result:   audit.ResultSuccess,
+			UserAddr: signIn.address,
+			UserID:   signIn.userID,
+			Method:   r.Method,
+			Endpoint: r.URL.Path,
+			Metadata: metadata,
+		}))
+	}
+
+	http.Redirect(w, r, oidc.CodeRedirect(req, code), http.StatusSeeOther)
+}
+
+// writeSignInPage renders the sign-in page. It must not be framed, so a signed
+// proof cannot be obtained by clickjacking.
+func (h *AuthHandler) writeSignInPage(w http.ResponseWriter, r *http.Request, page oidcSignInData, status int) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Security-Policy", "frame-ancestors 'none'")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.WriteHeader(status)
+	if err := oidcSignInPage.Execute(w, page); err != nil {
+		requestLogger(r, h.logger).Error("failed to render OIDC sign-in page", log.Error(err))
+	}
+}
+
+// OIDCToken handles POST /oidc/token - exchanges an authorization code for an
+// access token and an ID token. Clients authenticate with HTTP Basic or form
+// credentials; public clients send only client_id and the PKCE verifier.
+func (h *AuthHandler) OIDCToken(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, &oidc.Error{Code: oidc.ErrorInvalidRequest, Description: "invalid form body"}, http.StatusBadRequest)
+		return
+	}
+	req := &oidc.TokenRequest{
+		GrantType:    r.PostForm.Get("grant_type"),
+		Code:         r.PostForm.Get("code"),
+		RedirectURI:  r.PostForm.Get("redirect_uri"),
+		ClientID:     r.PostForm.Get("client_id"),
+		ClientSecret: r.PostForm.Get("client_secret"),
+		CodeVerifier: r.PostForm.Get("code_verifier"),
+	}
+
+	// Basic credentials are form-encoded before base64 (RFC 6749 section 2.3.1)
+	id, secret, basic := r.BasicAuth()
+	if basic {
+		req.ClientID, _ = url.QueryUnescape(id)
+		req.ClientSecret, _ = url.QueryUnescape(secret)
+	}
+
+	response, err := h.oidcProvider.Exchange(req)
+	var oauthErr *oidc.Error
+	if errors.As(err, &oauthErr) {
+		status := http.StatusBadRequest
+		if oauthErr.Code == oidc.ErrorInvalidClient {
+			status = http.StatusUnauthorized
+			if basic {
+				w.Header().Set("WWW-Authenticate", `Basic realm="gatekeeper"`)
+			}
+		}
+		writeOAuthError(w, oauthErr, status)
+		return
+	}
+	if err != nil {
+		requestLogger(r, h.logger).Error("failed to exchange authorization code", log.Error(err))
+		writeOAuthError(w, &oidc.Error{Code: "server_error"}, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// OIDCUserInfo handles GET and POST /oidc/userinfo - claims about the user an
+// access token from the token endpoint was issued for
+func (h *AuthHandler) OIDCUserInfo(w http.ResponseWriter, r *http.Request) {
+	accessToken := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	claims, err := h.oidcProvider.UserInfo(accessToken)
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		writeOAuthError(w, &oidc.Error{Code: oidc.ErrorInvalidToken, Description: "unknown or expired access token"}, http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(claims)
+}
+
+// writeOAuthError writes an OAuth 2.0 error response
+func writeOAuthError(w http.ResponseWriter, err *oidc.Error, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(err)
+}
@@ -0,0 +1,210 @@
+package http
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/oidc"
+)
+
+const oidcRedirectURI = "https://grafana.example.com/login/generic_oauth"
+
+func newOIDCHandler(t *testing.T) (*AuthHandler, *auth.SIWEService, *auth.JWTService) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return newOIDCHandlerWithKey(t, key)
+}
+
+// newOIDCHandlerWithKey creates a handler signing sessions and ID tokens with key
+func newOIDCHandlerWithKey(t *testing.T, key *ecdsa.PrivateKey) (*AuthHandler, *auth.SIWEService, *auth.JWTService) {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	jwtService, err := auth.NewJWTServiceWithKey(auth.AlgorithmES256, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), time.Hour)
+	require.NoError(t, err)
+
+	clients, err := oidc.LoadClientsFromJSON([]byte(`{"clients": [{"id": "grafana", "name": "Grafana", "secret": "s3cret", "redirectUris": ["` + oidcRedirectURI + `"]}]}`))
+	require.NoError(t, err)
+
+	siweService := auth.NewSIWEService(5 * time.Minute)
+	handler := NewAuthHandler(siweService, jwtService, nil, nil, nil)
+	handler.SetOIDCProvider(oidc.NewProvider("https://auth.example.com", clients, jwtService, time.Minute, time.Hour))
+	return handler, siweService, jwtService
+}
+
+func oidcAuthorizationParams() url.Values {
+	return url.Values{
+		"response_type": {"code"},
+		"client_id":     {"grafana"},
+		"redirect_uri":  {oidcRedirectURI},
+		"scope":         {"openid profile"},
+		"state":         {"xyz"},
+		"nonce":         {"n-0S6"},
+	}
+}
+
+func postForm(path string, form url.Values) *http.Request {
+	req := httptest.NewRequest("POST", path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func TestOIDC_AuthorizationCodeFlow(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	handler, siweService, jwtService := newOIDCHandlerWithKey(t, key)
+
+	// The sign-in page carries the authorization request
+	rec := httptest.NewRecorder()
+	handler.OIDCAuthorize(rec, httptest.NewRequest("GET", "/oidc/authorize?"+oidcAuthorizationParams().Encode(), nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Sign in to Grafana")
+	assert.Contains(t, rec.Body.String(), `name="nonce" value="n-0S6"`)
+	assert.Equal(t, "DENY", rec.Header().Get("X-Frame-Options"))
+
+	// The page posts the signed SIWE message back
+	message, signature, address := signWalletLink(t, siweService, "Sign in to Grafana with Gatekeeper")
+	form := oidcAuthorizationParams()
+	form.Set("message", message)
+	form.Set("signature", signature)
+	rec = httptest.NewRecorder()
+	handler.OIDCAuthorize(rec, postForm("/oidc/authorize", form))
+	require.Equal(t, http.StatusSeeOther, rec.Code)
+
+	location, err := url.Parse(rec.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "grafana.example.com", location.Host)
+	assert.Equal(t, "xyz", location.Query().Get("state"))
+	code := location.Query().Get("code")
+	require.NotEmpty(t, code)
+
+	// The client exchanges the code
+	req := postForm("/oidc/token", url.Values{"grant_type": {"authorization_code"}, "code": {code}, "redirect_uri": {oidcRedirectURI}})
+	req.SetBasicAuth("grafana", "s3cret")
+	rec = httptest.NewRecorder()
+	handler.OIDCToken(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+
+	var tokens oidc.TokenResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tokens))
+	claims := &auth.Claims{}
+	_, err = jwt.ParseWithClaims(tokens.IDToken, claims, func(*jwt.Token) (interface{}, error) {
+		return &key.PublicKey, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, address, claims.Subject)
+	assert.Equal(t, "https://auth.example.com", claims.Issuer)
+	assert.Equal(t, jwt.ClaimStrings{"grafana"}, claims.Audience)
+	assert.Equal(t, "n-0S6", claims.Extra["nonce"])
+
+	// ID tokens are signed with the session key but are never sessions
+	_, err = jwtService.VerifyToken(context.Background(), tokens.IDToken)
+	assert.Error(t, err)
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/me", nil)
+	req.Header.Set("Authorization", "Bearer "+tokens.IDToken)
+	JWTMiddleware(jwtService)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("an ID token was accepted as a session")
+	})).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// The access token reads the user's claims
+	req = httptest.NewRequest("GET", "/oidc/userinfo", nil)
+	req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+	rec = httptest.NewRecorder()
+	handler.OIDCUserInfo(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var userInfo map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &userInfo))
+	assert.Equal(t, address, userInfo["sub"])
+	assert.Equal(t, address, userInfo["preferred_username"])
+}
+
+func TestOIDCAuthorize_Rejections(t *testing.T) {
+	t.Run("unregistered redirect URI is not followed", func(t *testing.T) {
+		handler, _, _ := newOIDCHandler(t)
+		params := oidcAuthorizationParams()
+		params.Set("redirect_uri", "https://evil.example/callback")
+
+		rec := httptest.NewRecorder()
+		handler.OIDCAuthorize(rec, httptest.NewRequest("GET", "/oidc/authorize?"+params.Encode(), nil))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Empty(t, rec.Header().Get("Location"))
+	})
+
+	t.Run("invalid request is reported to the client", func(t *testing.T) {
+		handler, _, _ := newOIDCHandler(t)
+		params := oidcAuthorizationParams()
+		params.Set("scope", "profile")
+
+		rec := httptest.NewRecorder()
+		handler.OIDCAuthorize(rec, httptest.NewRequest("GET", "/oidc/authorize?"+params.Encode(), nil))
+
+		require.Equal(t, http.StatusFound, rec.Code)
+		location, err := url.Parse(rec.Header().Get("Location"))
+		require.NoError(t, err)
+		assert.Equal(t, oidc.ErrorInvalidScope, location.Query().Get("error"))
+		assert.Equal(t, "xyz", location.Query().Get("state"))
+	})
+
+	t.Run("invalid signature shows the page again", func(t *testing.T) {
+		handler, siweService, _ := newOIDCHandler(t)
+		message, _, _ := signWalletLink(t, siweService, "Sign in")
+		_, otherSignature, _ := signWalletLink(t, siweService, "Sign in")
+		form := oidcAuthorizationParams()
+		form.Set("message", message)
+		form.Set("signature", otherSignature)
+
+		rec := httptest.NewRecorder()
+		handler.OIDCAuthorize(rec, postForm("/oidc/authorize", form))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "signature verification failed")
+		assert.Empty(t, rec.Header().Get("Location"))
+	})
+}
+
+func TestOIDCToken_ClientAuthentication(t *testing.T) {
+	handler, _, _ := newOIDCHandler(t)
+
+	req := postForm("/oidc/token", url.Values{"grant_type": {"authorization_code"}, "code": {"abc"}, "redirect_uri": {oidcRedirectURI}})
+	req.SetBasicAuth("grafana", "wrong")
+	rec := httptest.NewRecorder()
+	handler.OIDCToken(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, `Basic realm="gatekeeper"`, rec.Header().Get("WWW-Authenticate"))
+	var oauthErr oidc.Error
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &oauthErr))
+	assert.Equal(t, oidc.ErrorInvalidClient, oauthErr.Code)
+}
+
+func TestOIDCUserInfo_InvalidToken(t *testing.T) {
+	handler, _, _ := newOIDCHandler(t)
+
+	req := httptest.NewRequest("GET", "/oidc/userinfo", nil)
+	req.Header.Set("Authorization", "Bearer unknown")
+	rec := httptest.NewRecorder()
+	handler.OIDCUserInfo(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Header().Get("WWW-Authenticate"), "invalid_token")
+}
@@ -0,0 +1,31 @@
+package http
+
+import "net/http"
+
+// OnlyPaths creates a middleware that serves only paths under the given prefixes
+// and responds 404 to everything else, e.g. to limit an internal listener to admin routes
+func OnlyPaths(prefixes ...string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if longestPathPrefix(r.URL.Path, prefixes) == "" {
+				http.NotFound(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ExceptPaths creates a middleware that responds 404 to paths under the given prefixes,
+// e.g. to hide admin routes from a public listener when they are served elsewhere
+func ExceptPaths(prefixes ...string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if longestPathPrefix(r.URL.Path, prefixes) != "" {
+				http.NotFound(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
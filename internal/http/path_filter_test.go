@@ -0,0 +1,40 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathFilters(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	only := OnlyPaths("/api/admin", "/metrics")(ok)
+	except := ExceptPaths("/api/admin", "/metrics")(ok)
+
+	tests := []struct {
+		path         string
+		onlyStatus   int
+		exceptStatus int
+	}{
+		{path: "/api/admin/log-level", onlyStatus: http.StatusOK, exceptStatus: http.StatusNotFound},
+		{path: "/metrics", onlyStatus: http.StatusOK, exceptStatus: http.StatusNotFound},
+		{path: "/api/keys", onlyStatus: http.StatusNotFound, exceptStatus: http.StatusOK},
+		{path: "/metrics-export", onlyStatus: http.StatusNotFound, exceptStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			only.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+			assert.Equal(t, tt.onlyStatus, rec.Code)
+
+			rec = httptest.NewRecorder()
+			except.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+			assert.Equal(t, tt.exceptStatus, rec.Code)
+		})
+	}
+}
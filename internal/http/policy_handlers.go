@@ -0,0 +1,250 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/gatekeeper/internal/audit"
+	"github.com/yourusername/gatekeeper/internal/log"
+	"github.com/yourusername/gatekeeper/internal/policy"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// maxPolicyBytes caps the size of a policy in a create or update request
+const maxPolicyBytes = 1 << 20
+
+// PolicyHandler manages access control policies at runtime. Policies are held by
+// the policy manager, so changes apply immediately and are recorded in the change
+// history. When the manager has a policy store, changes are persisted and survive
+// restarts.
+type PolicyHandler struct {
+	policyManager *policy.PolicyManager
+	loader        *policy.PolicyLoader
+	logger        log.Logger
+	auditLogger   audit.AuditLogger
+}
+
+// NewPolicyHandler creates a new policy handler
+func NewPolicyHandler(policyManager *policy.PolicyManager, logger log.Logger, auditLogger audit.AuditLogger) *PolicyHandler {
+	return &PolicyHandler{
+		policyManager: policyManager,
+		loader:        policy.NewPolicyLoader(),
+		logger:        logger,
+		auditLogger:   auditLogger,
+	}
+}
+
+// ListPoliciesResponse lists policies in evaluation order
+type ListPoliciesResponse struct {
+	Policies []*policy.Policy `json:"policies"`
+}
+
+// ListPolicies handles GET /api/admin/policies - List all policies
+func (h *PolicyHandler) ListPolicies(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ListPoliciesResponse{Policies: h.policyManager.GetAllPolicies()})
+}
+
+// GetPolicy handles GET /api/admin/policies/{id} - Get a single policy
+func (h *PolicyHandler) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.policyID(w, r)
+	if !ok {
+		return
+	}
+
+	p := h.policyManager.GetPolicy(id)
+	if p == nil {
+		h.writeError(w, "Not found", "Policy not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(p)
+}
+
+// CreatePolicy handles POST /api/admin/policies - Add a policy. The body is a policy
+// in the format of the policy configuration file.
+func (h *PolicyHandler) CreatePolicy(w http.ResponseWriter, r *http.Request) {
+	p, ok := h.readPolicy(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.policyManager.AddPolicyContext(store.WithActor(r.Context(), h.actor(r)), p); err != nil {
+		requestLogger(r, h.logger).Error("failed to add policy", log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to save policy", http.StatusInternalServerError)
+		return
+	}
+	h.logChange(r, audit.ActionPolicyCreated, p)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(p)
+}
+
+// UpdatePolicy handles PUT /api/admin/policies/{id} - Replace a policy, keeping its
+// ID and its place in evaluation order
+func (h *PolicyHandler) UpdatePolicy(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.policyID(w, r)
+	if !ok {
+		return
+	}
+
+	p, ok := h.readPolicy(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.policyManager.ReplacePolicy(store.WithActor(r.Context(), h.actor(r)), id, p); err != nil {
+		h.writeChangeError(w, r, "failed to replace policy", err)
+		return
+	}
+	h.logChange(r, audit.ActionPolicyUpdated, p)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(p)
+}
+
+// DeletePolicy handles DELETE /api/admin/policies/{id} - Remove a policy
+func (h *PolicyHandler) DeletePolicy(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.policyID(w, r)
+	if !ok {
+		return
+	}
+
+	p := h.policyManager.GetPolicy(id)
+	if p == nil {
+		h.writeError(w, "Not found", "Policy not found", http.StatusNotFound)
+		return
+	}
+	if err := h.policyManager.RemovePolicy(store.WithActor(r.Context(), h.actor(r)), id); err != nil {
+		h.writeChangeError(w, r, "failed to remove policy", err)
+		return
+	}
+	h.logChange(r, audit.ActionPolicyDeleted, p)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ReloadPolicies handles POST /api/admin/policies/reload - Replace the policies in
+// memory with those in the policy store, e.g. after another instance changed them.
+// Requests are served with the previous policies until the new ones are swapped in.
+func (h *PolicyHandler) ReloadPolicies(w http.ResponseWriter, r *http.Request) {
+	logger := requestLogger(r, h.logger)
+
+	if err := h.policyManager.LoadFromStore(store.WithActor(r.Context(), h.actor(r))); err != nil {
+		logger.Error("failed to reload policies", log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to reload policies", http.StatusInternalServerError)
+		return
+	}
+
+	policies := h.policyManager.GetAllPolicies()
+	logger.Info("policies reloaded", log.Int("count", len(policies)))
+
+	if h.auditLogger != nil {
+		h.auditLogger.Log(r.Context(), withClientInfo(r, audit.AuditEvent{
+			Action:   audit.ActionPoliciesReloaded,
+			Result:   audit.ResultSuccess,
+			UserAddr: h.actor(r),
+			Method:   r.Method,
+			Endpoint: r.URL.Path,
+			Metadata: map[string]interface{}{
+				"count": len(policies),
+			},
+		}))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ListPoliciesResponse{Policies: policies})
+}
+
+// policyID parses the policy ID route variable, writing an error if it is invalid
+func (h *PolicyHandler) policyID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.writeError(w, "Invalid request", "Invalid policy ID format", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+// readPolicy parses and validates the policy in the request body, writing an error
+// if it is invalid
+func (h *PolicyHandler) readPolicy(w http.ResponseWriter, r *http.Request) (*policy.Policy, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPolicyBytes))
+	if err != nil {
+		h.writeError(w, "Invalid request body", err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+
+	p, err := h.loader.ParsePolicy(body)
+	if err != nil {
+		h.writeError(w, "Validation failed", err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	return p, true
+}
+
+// logChange logs and audits a policy mutation
+func (h *PolicyHandler) logChange(r *http.Request, action audit.ActionType, p *policy.Policy) {
+	requestLogger(r, h.logger).Info("policy changed",
+		log.String("action", string(action)),
+		log.Int64("policy_id", p.ID),
+		log.String("route", p.Method+" "+p.Path),
+	)
+
+	if h.auditLogger == nil {
+		return
+	}
+	h.auditLogger.Log(r.Context(), withClientInfo(r, audit.AuditEvent{
+		Action:   action,
+		Result:   audit.ResultSuccess,
+		UserAddr: h.actor(r),
+		Method:   r.Method,
+		Endpoint: r.URL.Path,
+		Metadata: map[string]interface{}{
+			"policy_id":     p.ID,
+			"policy_method": p.Method,
+			"policy_path":   p.Path,
+			"shadow":        p.Shadow,
+		},
+	}))
+}
+
+// writeChangeError writes the error of a failed replacement or removal
+func (h *PolicyHandler) writeChangeError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	if errors.Is(err, policy.ErrPolicyNotFound) {
+		h.writeError(w, "Not found", "Policy not found", http.StatusNotFound)
+		return
+	}
+	requestLogger(r, h.logger).Error(msg, log.Error(err))
+	h.writeError(w, "Internal server error", "Failed to save policy", http.StatusInternalServerError)
+}
+
+// actor returns the authenticated identity making a request, or "" if none
+func (h *PolicyHandler) actor(r *http.Request) string {
+	if claims := ClaimsFromContext(r); claims != nil {
+		return claims.Identity()
+	}
+	return ""
+}
+
+// writeError writes an error response
+func (h *PolicyHandler) writeError(w http.ResponseWriter, error, details string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := ErrorResponse{
+		Error:   error,
+		Details: details,
+	}
+	json.NewEncoder(w).Encode(response)
+}
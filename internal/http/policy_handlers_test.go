@@ -0,0 +1,142 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/audit"
+	"github.com/yourusername/gatekeeper/internal/gatekeepertest"
+	"github.com/yourusername/gatekeeper/internal/policy"
+	"go.uber.org/zap"
+)
+
+const scopePolicyJSON = `{"path":"/api/data","method":"GET","logic":"AND","rules":[{"type":"has_scope","scope":"read"}]}`
+
+func newPolicyRequest(method, id, body string) *http.Request {
+	target := "/api/admin/policies"
+	if id != "" {
+		target += "/" + id
+	}
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if id != "" {
+		req = mux.SetURLVars(req, map[string]string{"id": id})
+	}
+	return req
+}
+
+func TestPolicyHandler_CRUD(t *testing.T) {
+	manager := policy.NewPolicyManager(nil, nil)
+	sink := &recordingSink{}
+	handler := NewPolicyHandler(manager, nil, audit.NewAuditLoggerWithSinks(zap.NewNop(), sink))
+
+	rec := httptest.NewRecorder()
+	handler.CreatePolicy(rec, newPolicyRequest("POST", "", scopePolicyJSON))
+	require.Equal(t, http.StatusCreated, rec.Code)
+	assert.JSONEq(t, `{"id":1,"path":"/api/data","method":"GET","logic":"AND","rules":[{"type":"has_scope","scope":"read"}]}`, rec.Body.String())
+	assert.True(t, manager.HasPolicy("/api/data", "GET"))
+
+	rec = httptest.NewRecorder()
+	handler.UpdatePolicy(rec, newPolicyRequest("PUT", "1", `{"path":"/api/data","method":"GET","logic":"OR","rules":[{"type":"erc20_min_balance","contract_address":"0x1f9840a85d5af5bf1d1762f925bdaddc4201f984","minimum_balance":"100","chain_id":1}]}`))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "OR", manager.GetPolicy(1).Logic)
+
+	rec = httptest.NewRecorder()
+	handler.ListPolicies(rec, newPolicyRequest("GET", "", ""))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list struct {
+		Policies []map[string]interface{} `json:"policies"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.Policies, 1)
+	assert.Equal(t, "erc20_min_balance", list.Policies[0]["rules"].([]interface{})[0].(map[string]interface{})["type"])
+
+	rec = httptest.NewRecorder()
+	handler.DeletePolicy(rec, newPolicyRequest("DELETE", "1", ""))
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, 0, manager.GetPoliciesCount())
+
+	rec = httptest.NewRecorder()
+	handler.GetPolicy(rec, newPolicyRequest("GET", "1", ""))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	require.Len(t, sink.events, 3)
+	assert.Equal(t, audit.ActionPolicyCreated, sink.events[0].Action)
+	assert.Equal(t, audit.ActionPolicyUpdated, sink.events[1].Action)
+	assert.Equal(t, audit.ActionPolicyDeleted, sink.events[2].Action)
+	assert.Equal(t, int64(1), sink.events[2].Metadata["policy_id"])
+	assert.Equal(t, "/api/data", sink.events[2].Metadata["policy_path"])
+}
+
+func TestPolicyHandler_Rejected(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		id         string
+		body       string
+		wantStatus int
+	}{
+		{"malformed JSON", "POST", "", `{`, http.StatusBadRequest},
+		{"missing rules", "POST", "", `{"path":"/api/data","method":"GET","logic":"AND","rules":[]}`, http.StatusBadRequest},
+		{"unknown rule type", "POST", "", `{"path":"/api/data","method":"GET","logic":"AND","rules":[{"type":"magic"}]}`, http.StatusBadRequest},
+		{"invalid ID", "PUT", "abc", scopePolicyJSON, http.StatusBadRequest},
+		{"unknown policy", "PUT", "9", scopePolicyJSON, http.StatusNotFound},
+		{"delete unknown policy", "DELETE", "9", "", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := policy.NewPolicyManager(nil, nil)
+			handler := NewPolicyHandler(manager, nil, nil)
+
+			rec := httptest.NewRecorder()
+			req := newPolicyRequest(tt.method, tt.id, tt.body)
+			switch tt.method {
+			case "POST":
+				handler.CreatePolicy(rec, req)
+			case "PUT":
+				handler.UpdatePolicy(rec, req)
+			case "DELETE":
+				handler.DeletePolicy(rec, req)
+			}
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, 0, manager.GetPoliciesCount())
+		})
+	}
+}
+
+func TestPolicyHandler_Reload(t *testing.T) {
+	policyStore := gatekeepertest.NewStore()
+	manager := policy.NewPolicyManager(nil, nil)
+	manager.SetPolicyStore(policyStore)
+	sink := &recordingSink{}
+	handler := NewPolicyHandler(manager, nil, audit.NewAuditLoggerWithSinks(zap.NewNop(), sink))
+
+	rec := httptest.NewRecorder()
+	handler.CreatePolicy(rec, newPolicyRequest("POST", "", scopePolicyJSON))
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	// Another instance changes the stored policies
+	_, err := policyStore.CreatePolicy(context.Background(), []byte(`{"path":"/api/data","method":"POST","logic":"AND","rules":[{"type":"has_scope","scope":"write"}]}`))
+	require.NoError(t, err)
+	assert.False(t, manager.HasPolicy("/api/data", "POST"))
+
+	rec = httptest.NewRecorder()
+	handler.ReloadPolicies(rec, newPolicyRequest("POST", "", ""))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, manager.HasPolicy("/api/data", "POST"))
+	assert.Equal(t, audit.ActionPoliciesReloaded, sink.events[len(sink.events)-1].Action)
+
+	// Without a store there is nothing to reload from
+	rec = httptest.NewRecorder()
+	NewPolicyHandler(policy.NewPolicyManager(nil, nil), nil, nil).ReloadPolicies(rec, newPolicyRequest("POST", "", ""))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
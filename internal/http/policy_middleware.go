@@ -2,24 +2,50 @@ package http
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"time"
 
-	"go.uber.org/zap"
+	"github.com/gorilla/mux"
 	"github.com/yourusername/gatekeeper/internal/audit"
 	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/chain"
+	"github.com/yourusername/gatekeeper/internal/decisionlog"
 	"github.com/yourusername/gatekeeper/internal/log"
 	"github.com/yourusername/gatekeeper/internal/policy"
+	"github.com/yourusername/gatekeeper/internal/store"
 )
 
+// TierContextKey is the key used to store the tier a TIER policy granted the caller
+const TierContextKey contextKey = "policy_tier"
+
+// TierFromContext returns the tier a TIER policy granted the caller of the request,
+// or "" if none did. Handlers may use it to shape their responses, and tiered rate
+// limits to pick the caller's quota.
+func TierFromContext(r *http.Request) string {
+	tier, _ := r.Context().Value(TierContextKey).(string)
+	return tier
+}
+
 // PolicyMiddleware evaluates access control policies for protected routes
 type PolicyMiddleware struct {
 	policyManager *policy.PolicyManager
-	logger        *log.Logger
+	logger        log.Logger
 	auditLogger   audit.AuditLogger
+	metrics       *MetricsCollector               // Optional: records shadow policy decisions and evaluation latency
+	stats         *PolicyStats                    // Optional: records per-policy evaluation statistics
+	rpcUsage      *RPCUsageTracker                // Optional: attributes RPC calls of evaluations to policies and tenants
+	decisionLog   *decisionlog.Logger             // Optional: records one line per gated request
+	warmer        *policy.CacheWarmer             // Optional: keeps blockchain results of active addresses cached
+	wallets       store.WalletRepositoryInterface // Optional: wallets linked to the caller also satisfy address rules
+	blocklist     policy.Blocklist                // Optional: blocked callers are denied on every gated route
+	exempt        map[*mux.Route]bool
+	chainID       uint64 // Default chain of evaluation contexts
+	denialDetail  string // How much a 403 tells the caller about the policy that denied it
 }
 
 // NewPolicyMiddleware creates a new policy middleware
-func NewPolicyMiddleware(pm *policy.PolicyManager, logger *log.Logger, auditLogger audit.AuditLogger) *PolicyMiddleware {
+func NewPolicyMiddleware(pm *policy.PolicyManager, logger log.Logger, auditLogger audit.AuditLogger) *PolicyMiddleware {
 	return &PolicyMiddleware{
 		policyManager: pm,
 		logger:        logger,
@@ -31,20 +57,25 @@ func NewPolicyMiddleware(pm *policy.PolicyManager, logger *log.Logger, auditLogg
 func (pm *PolicyMiddleware) Middleware() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if pm.isExempt(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			// Get claims from context (set by JWTMiddleware)
 			claims, ok := r.Context().Value(ClaimsContextKey).(*auth.Claims)
 			if !ok || claims == nil {
 				// No claims in context, request already failed auth
-				pm.logger.WithFields(
-					zap.String("path", r.URL.Path),
-					zap.String("method", r.Method),
-					zap.String("decision", "DENIED"),
-					zap.String("reason", "no_authentication"),
+				requestLogger(r, pm.logger).WithFields(
+					log.String("path", r.URL.Path),
+					log.String("method", r.Method),
+					log.String("decision", "DENIED"),
+					log.String("reason", "no_authentication"),
 				).Info("policy decision: access denied")
 
 				// Audit log: Authorization denied - no authentication
 				if pm.auditLogger != nil {
-					pm.auditLogger.LogAuthzDecision(r.Context(), audit.AuditEvent{
+					pm.auditLogger.LogAuthzDecision(r.Context(), withClientInfo(r, audit.AuditEvent{
 						Result:       audit.ResultDenied,
 						Method:       r.Method,
 						Endpoint:     r.URL.Path,
@@ -52,40 +83,82 @@ func (pm *PolicyMiddleware) Middleware() func(http.Handler) http.Handler {
 						Error:        "no_authentication",
 						PolicyPath:   r.URL.Path,
 						PolicyMethod: r.Method,
-					})
+					}))
 				}
 
+				pm.logDecision(r, nil, decisionlog.Record{
+					Decision: decisionlog.DecisionDenied,
+					Reason:   "no_authentication",
+				})
+
 				http.Error(w, "Unauthorized", http.StatusUnauthorized)
 				return
 			}
 
-			// Get policies for this route
-			policies := pm.policyManager.GetPoliciesForRoute(r.URL.Path, r.Method)
+			if !pm.checkBlocklist(w, r, claims) {
+				return
+			}
+
+			// Get policies for this route; shadow policies are evaluated for the record only
+			routePolicies := pm.policyManager.GetPoliciesForRoute(r.URL.Path, r.Method)
+			wallets := pm.linkedWallets(r, claims, routePolicies)
+			policies, shadowPolicies := splitShadowPolicies(routePolicies)
+			pm.evaluateShadowPolicies(r, shadowPolicies, claims, wallets)
 
 			// If no policies exist for this route, allow access
 			if len(policies) == 0 {
-				pm.logger.WithFields(
-					zap.String("path", r.URL.Path),
-					zap.String("method", r.Method),
-					zap.String("address", claims.Address),
-					zap.String("decision", "ALLOWED"),
-					zap.String("reason", "no_policies"),
-					zap.Int("policies", 0),
+				requestLogger(r, pm.logger).WithFields(
+					log.String("path", r.URL.Path),
+					log.String("method", r.Method),
+					log.String("address", claims.Address),
+					log.String("decision", "ALLOWED"),
+					log.String("reason", "no_policies"),
+					log.Int("policies", 0),
 				).Debug("policy decision: access allowed (no policies)")
+				pm.logDecision(r, claims, decisionlog.Record{
+					Decision: decisionlog.DecisionAllowed,
+					Reason:   "no_policies",
+				})
 				next.ServeHTTP(w, r)
 				return
 			}
 
-			// Evaluate all policies for the route
-			allowed, evalErr := pm.evaluatePolicies(r.Context(), policies, claims.Address, claims)
+			// Evaluate all policies for the route; request rules read the request from the context.
+			// Quota reserved during evaluation is returned unless the request is granted and succeeds.
+			ctx, reservations := policy.WithQuotaReservations(pm.policyContext(r, claims, wallets))
+			evalStart := time.Now()
+			deniedBy, tier, evalErr := pm.evaluatePolicies(ctx, policies, claims.Address, claims)
+			evalDuration := time.Since(evalStart)
+			if pm.warmer != nil {
+				pm.warmer.Touch(claims.Address)
+				for _, wallet := range wallets {
+					pm.warmer.Touch(wallet)
+				}
+			}
+			if pm.metrics != nil {
+				pm.metrics.ObservePolicyEvaluation(evaluationOutcome(deniedBy, evalErr), evalDuration, TraceIDFromContext(r.Context()))
+			}
+			record := decisionlog.Record{
+				Decision:  decisionlog.DecisionAllowed,
+				Policies:  len(policies),
+				Tier:      tier,
+				LatencyMs: float64(evalDuration) / float64(time.Millisecond),
+			}
+			if ec := policy.EvaluationContextFromContext(ctx); ec != nil {
+				record.ChainID = ec.ChainID
+			}
+			allowed := deniedBy == nil && evalErr == nil
+			if !allowed {
+				pm.releaseQuota(r, reservations)
+			}
 
 			// Build log fields
-			logFields := []zap.Field{
-				zap.String("path", r.URL.Path),
-				zap.String("method", r.Method),
-				zap.String("address", claims.Address),
-				zap.Int("policies", len(policies)),
-				zap.Strings("scopes", claims.Scopes),
+			logFields := []log.Field{
+				log.String("path", r.URL.Path),
+				log.String("method", r.Method),
+				log.String("address", claims.Address),
+				log.Int("policies", len(policies)),
+				log.Strings("scopes", claims.Scopes),
 			}
 
 			decision := "ALLOWED"
@@ -93,24 +166,24 @@ func (pm *PolicyMiddleware) Middleware() func(http.Handler) http.Handler {
 				decision = "DENIED"
 				if evalErr != nil {
 					logFields = append(logFields,
-						zap.Error(evalErr),
-						zap.String("reason", "evaluation_error"),
+						log.Error(evalErr),
+						log.String("reason", "evaluation_error"),
 					)
 				} else {
 					logFields = append(logFields,
-						zap.String("reason", "policy_failed"),
+						log.String("reason", "policy_failed"),
 					)
 				}
 			}
 
-			logFields = append(logFields, zap.String("decision", decision))
+			logFields = append(logFields, log.String("decision", decision))
 
 			if evalErr != nil {
-				pm.logger.WithFields(logFields...).Warn("policy evaluation error")
+				requestLogger(r, pm.logger).WithFields(logFields...).Warn("policy evaluation error")
 
 				// Audit log: Policy evaluation error
 				if pm.auditLogger != nil {
-					pm.auditLogger.LogAuthzDecision(r.Context(), audit.AuditEvent{
+					pm.auditLogger.LogAuthzDecision(r.Context(), withClientInfo(r, audit.AuditEvent{
 						Result:       audit.ResultDenied,
 						UserAddr:     claims.Address,
 						Method:       r.Method,
@@ -123,19 +196,24 @@ func (pm *PolicyMiddleware) Middleware() func(http.Handler) http.Handler {
 						Metadata: map[string]interface{}{
 							"policies_count": len(policies),
 						},
-					})
+					}))
 				}
 
+				record.Decision = decisionlog.DecisionError
+				record.Reason = "evaluation_error"
+				record.DeniedBy = policyRef(deniedBy)
+				pm.logDecision(r, claims, record)
+
 				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 				return
 			}
 
 			if !allowed {
-				pm.logger.WithFields(logFields...).Info("policy decision: access denied")
+				requestLogger(r, pm.logger).WithFields(logFields...).Info("policy decision: access denied")
 
 				// Audit log: Access denied by policy
 				if pm.auditLogger != nil {
-					pm.auditLogger.LogAuthzDecision(r.Context(), audit.AuditEvent{
+					pm.auditLogger.LogAuthzDecision(r.Context(), withClientInfo(r, audit.AuditEvent{
 						Result:       audit.ResultDenied,
 						UserAddr:     claims.Address,
 						Method:       r.Method,
@@ -147,16 +225,29 @@ func (pm *PolicyMiddleware) Middleware() func(http.Handler) http.Handler {
 							"policies_count": len(policies),
 							"scopes":         claims.Scopes,
 						},
-					})
+					}))
 				}
 
-				http.Error(w, "Forbidden", http.StatusForbidden)
+				record.Decision = decisionlog.DecisionDenied
+				record.Reason = "policy_failed"
+				record.DeniedBy = policyRef(deniedBy)
+				pm.logDecision(r, claims, record)
+
+				writeProblem(w, Problem{
+					Type:     ProblemTypePolicyDenied,
+					Title:    "Forbidden",
+					Status:   http.StatusForbidden,
+					Detail:   fmt.Sprintf("Access to %s %s denied by policy", r.Method, r.URL.Path),
+					Instance: r.URL.Path,
+					Policy:   pm.explainDenial(r, claims, wallets, deniedBy),
+					Faucets:  faucetsFor(record.ChainID, deniedBy),
+				})
 				return
 			}
 
 			// Audit log: Access granted
 			if pm.auditLogger != nil {
-				pm.auditLogger.LogAuthzDecision(r.Context(), audit.AuditEvent{
+				pm.auditLogger.LogAuthzDecision(r.Context(), withClientInfo(r, audit.AuditEvent{
 					Result:       audit.ResultGranted,
 					UserAddr:     claims.Address,
 					Method:       r.Method,
@@ -168,46 +259,477 @@ func (pm *PolicyMiddleware) Middleware() func(http.Handler) http.Handler {
 						"policies_count": len(policies),
 						"scopes":         claims.Scopes,
 					},
-				})
+				}))
+			}
+
+			if tier != "" {
+				logFields = append(logFields, log.String("tier", tier))
+				r = r.WithContext(context.WithValue(r.Context(), TierContextKey, tier))
+			}
+			requestLogger(r, pm.logger).WithFields(logFields...).Debug("policy decision: access allowed")
+			pm.logDecision(r, claims, record)
+			if reservations.Len() == 0 {
+				next.ServeHTTP(w, r)
+				return
 			}
 
-			pm.logger.WithFields(logFields...).Debug("policy decision: access allowed")
-			next.ServeHTTP(w, r)
+			wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(wrapped, r)
+			if wrapped.statusCode >= 400 {
+				pm.releaseQuota(r, reservations)
+			}
 		})
 	}
 }
 
-// evaluatePolicies evaluates all policies for a route
-func (pm *PolicyMiddleware) evaluatePolicies(ctx context.Context, policies []*policy.Policy, address string, claims *auth.Claims) (bool, error) {
-	if len(policies) == 0 {
-		return true, nil
+// checkBlocklist denies callers whose address is on the blocklist before any
+// policy is evaluated, writing the response. It reports whether the request may
+// proceed. A failed lookup denies the request, as policy rules fail closed.
+func (pm *PolicyMiddleware) checkBlocklist(w http.ResponseWriter, r *http.Request, claims *auth.Claims) bool {
+	if pm.blocklist == nil || claims.Address == "" {
+		return true
+	}
+
+	blocked, err := pm.blocklist.FindBlockedAddresses(r.Context(), []string{claims.Address})
+	if err == nil && len(blocked) == 0 {
+		return true
+	}
+
+	logFields := []log.Field{
+		log.String("path", r.URL.Path),
+		log.String("method", r.Method),
+		log.String("address", claims.Address),
+		log.String("decision", "DENIED"),
+	}
+	if err != nil {
+		requestLogger(r, pm.logger).WithFields(append(logFields, log.Error(err), log.String("reason", "blocklist_error"))...).
+			Warn("blocklist check failed")
+		pm.logDecision(r, claims, decisionlog.Record{
+			Decision: decisionlog.DecisionError,
+			Reason:   "blocklist_error",
+		})
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return false
 	}
 
+	requestLogger(r, pm.logger).WithFields(append(logFields, log.String("reason", "address_blocked"))...).
+		Info("policy decision: access denied")
+
+	// Audit log: Access denied by the blocklist
+	if pm.auditLogger != nil {
+		pm.auditLogger.LogAuthzDecision(r.Context(), withClientInfo(r, audit.AuditEvent{
+			Result:       audit.ResultDenied,
+			UserAddr:     claims.Address,
+			Method:       r.Method,
+			Endpoint:     r.URL.Path,
+			IPAddr:       r.RemoteAddr,
+			PolicyPath:   r.URL.Path,
+			PolicyMethod: r.Method,
+			Error:        "address_blocked",
+		}))
+	}
+
+	pm.logDecision(r, claims, decisionlog.Record{
+		Decision: decisionlog.DecisionDenied,
+		Reason:   "address_blocked",
+	})
+
+	writeProblem(w, Problem{
+		Type:     ProblemTypeAddressBlocked,
+		Title:    "Forbidden",
+		Status:   http.StatusForbidden,
+		Detail:   "The address is blocked",
+		Instance: r.URL.Path,
+	})
+	return false
+}
+
+// evaluatePolicies evaluates all policies for a route
+// Returns the first policy that denied access, or nil if all policies passed, and
+// the tier granted by the first TIER policy
+func (pm *PolicyMiddleware) evaluatePolicies(ctx context.Context, policies []*policy.Policy, address string, claims *auth.Claims) (*policy.Policy, string, error) {
 	// If multiple policies exist, ALL must pass (AND logic across policies)
+	granted := ""
 	for _, p := range policies {
-		allowed, err := p.Evaluate(ctx, address, claims)
+		evalCtx, usage := pm.meterRPC(ctx)
+		start := time.Now()
+		tier, allowed, err := p.EvaluateTier(evalCtx, address, claims)
+		pm.recordStats(p, allowed, err, time.Since(start))
+		pm.recordRPCUsage(ctx, p, claims, usage)
 		if err != nil {
-			return false, err
+			return p, "", err
 		}
 		if !allowed {
-			return false, nil
+			return p, "", nil
 		}
+		if granted == "" {
+			granted = tier
+		}
+	}
+
+	return nil, granted, nil
+}
+
+// faucetsFor returns the faucets of a testnet when the denying policy checks token
+// holdings, so callers on staging environments learn where to get the tokens
+func faucetsFor(chainID uint64, deniedBy *policy.Policy) []string {
+	if deniedBy == nil {
+		return nil
+	}
+	network, ok := chain.LookupNetwork(chainID)
+	if !ok || !network.Testnet || !policy.HasTokenRules([]*policy.Policy{deniedBy}) {
+		return nil
+	}
+	return network.Faucets
+}
+
+// policyContext returns the context policies are evaluated with. It carries the
+// evaluation context of the request: route variables, the client IP resolved like
+// the rate limiter does, the caller's claims, their linked wallets and the chain
+// the request concerns.
+func (pm *PolicyMiddleware) policyContext(r *http.Request, claims *auth.Claims, wallets []string) context.Context {
+	ec := policy.NewEvaluationContext(r, extractIP(r), claims)
+	ec.Wallets = wallets
+	if vars := mux.Vars(r); vars != nil {
+		ec.Params = vars
+	}
+	if ec.ChainID == 0 {
+		ec.ChainID = pm.chainID
+	}
+	return policy.WithEvaluationContext(policy.WithRequest(r.Context(), r), ec)
+}
+
+// linkedWallets returns the wallets linked to the caller's account when any of
+// the policies has rules they can satisfy. A failed lookup is logged and
+// evaluation continues with the caller's own address only.
+func (pm *PolicyMiddleware) linkedWallets(r *http.Request, claims *auth.Claims, policies []*policy.Policy) []string {
+	if pm.wallets == nil || claims.Address == "" || !policy.HasWalletRules(policies) {
+		return nil
+	}
+	wallets, err := pm.wallets.ListLinkedAddresses(r.Context(), claims.Address)
+	if err != nil {
+		requestLogger(r, pm.logger).WithFields(log.Error(err)).Warn("failed to load linked wallets")
+		return nil
+	}
+	return wallets
+}
+
+// logDecision appends the decision on a gated request to the decision log, filling
+// in the request and caller
+func (pm *PolicyMiddleware) logDecision(r *http.Request, claims *auth.Claims, record decisionlog.Record) {
+	if pm.decisionLog == nil {
+		return
+	}
+	record.RequestID = RequestIDFromContext(r.Context())
+	record.Method = r.Method
+	record.Path = r.URL.Path
+	record.ClientIP = extractIP(r)
+	if claims != nil {
+		record.Subject = claims.Identity()
+		record.Scopes = claims.Scopes
+	}
+	pm.decisionLog.Log(record)
+}
+
+// policyRef identifies a policy in the decision log
+func policyRef(p *policy.Policy) *decisionlog.PolicyRef {
+	if p == nil {
+		return nil
 	}
+	return &decisionlog.PolicyRef{Method: p.Method, Path: p.Path}
+}
 
-	return true, nil
+// releaseQuota returns quota reserved for a request that was not granted or failed
+func (pm *PolicyMiddleware) releaseQuota(r *http.Request, reservations *policy.QuotaReservations) {
+	if err := reservations.Release(r.Context()); err != nil {
+		requestLogger(r, pm.logger).WithFields(log.Error(err)).Warn("failed to release policy quota")
+	}
+}
+
+// splitShadowPolicies separates enforced policies from shadow (log-only) policies
+func splitShadowPolicies(policies []*policy.Policy) (enforced, shadow []*policy.Policy) {
+	for _, p := range policies {
+		if p.Shadow {
+			shadow = append(shadow, p)
+		} else {
+			enforced = append(enforced, p)
+		}
+	}
+	return enforced, shadow
+}
+
+// evaluateShadowPolicies evaluates each shadow policy and records the decision it
+// would have made in the log, audit trail and metrics. It never affects the request.
+func (pm *PolicyMiddleware) evaluateShadowPolicies(r *http.Request, policies []*policy.Policy, claims *auth.Claims, wallets []string) {
+	for _, p := range policies {
+		ctx, reservations := policy.WithQuotaReservations(pm.policyContext(r, claims, wallets))
+		ctx, usage := pm.meterRPC(ctx)
+		start := time.Now()
+		allowed, err := p.Evaluate(ctx, claims.Address, claims)
+		pm.recordStats(p, allowed, err, time.Since(start))
+		pm.recordRPCUsage(ctx, p, claims, usage)
+		pm.releaseQuota(r, reservations)
+
+		decision := "would_allow"
+		result := audit.ResultGranted
+		if err != nil {
+			decision = "evaluation_error"
+			result = audit.ResultFailure
+		} else if !allowed {
+			decision = "would_deny"
+			result = audit.ResultDenied
+		}
+
+		logger := requestLogger(r, pm.logger).WithFields(
+			log.String("path", r.URL.Path),
+			log.String("method", r.Method),
+			log.String("address", claims.Address),
+			log.String("policy_path", p.Path),
+			log.String("policy_method", p.Method),
+			log.String("decision", decision),
+		)
+		switch {
+		case err != nil:
+			logger.Warn("shadow policy evaluation error", log.Error(err))
+		case !allowed:
+			logger.Info("shadow policy decision: access would be denied")
+		default:
+			logger.Debug("shadow policy decision: access would be allowed")
+		}
+
+		if pm.auditLogger != nil {
+			event := audit.AuditEvent{
+				Result:       result,
+				UserAddr:     claims.Address,
+				Method:       r.Method,
+				Endpoint:     r.URL.Path,
+				IPAddr:       r.RemoteAddr,
+				PolicyPath:   p.Path,
+				PolicyMethod: p.Method,
+				Metadata: map[string]interface{}{
+					"shadow":   true,
+					"decision": decision,
+					"scopes":   claims.Scopes,
+				},
+			}
+			if err != nil {
+				event.Error = "evaluation_error"
+				event.ErrorDetail = err.Error()
+			}
+			pm.auditLogger.LogPolicyEvaluation(r.Context(), withClientInfo(r, event))
+		}
+
+		if pm.metrics != nil {
+			pm.metrics.RecordShadowDecision(p.Method+" "+p.Path, decision)
+		}
+	}
+}
+
+// recordStats records the outcome of a policy evaluation, if statistics are enabled
+func (pm *PolicyMiddleware) recordStats(p *policy.Policy, allowed bool, err error, latency time.Duration) {
+	if pm.stats == nil {
+		return
+	}
+	outcome := PolicyOutcomeAllowed
+	if err != nil {
+		outcome = PolicyOutcomeError
+	} else if !allowed {
+		outcome = PolicyOutcomeDenied
+	}
+	pm.stats.Record(p, outcome, latency)
+}
+
+// meterRPC returns the context to evaluate a policy with and the usage its RPC
+// calls are counted in, if RPC usage is tracked or the request is timed for debugging
+func (pm *PolicyMiddleware) meterRPC(ctx context.Context) (context.Context, *policy.RPCUsage) {
+	if pm.rpcUsage == nil && debugTimingsFromContext(ctx) == nil {
+		return ctx, nil
+	}
+	return policy.WithRPCUsage(ctx)
+}
+
+// recordRPCUsage attributes the RPC calls of a policy evaluation to the policy and
+// the caller's tenant, and exports them as metrics
+func (pm *PolicyMiddleware) recordRPCUsage(ctx context.Context, p *policy.Policy, claims *auth.Claims, usage *policy.RPCUsage) {
+	if usage == nil {
+		return
+	}
+	if timings := debugTimingsFromContext(ctx); timings != nil {
+		timings.addRPC(usage.Total(), usage.Duration())
+	}
+	if pm.rpcUsage == nil {
+		return
+	}
+	calls := usage.Calls()
+	tenant, cost := pm.rpcUsage.Record(p, claims, calls)
+	if pm.metrics != nil {
+		pm.metrics.RecordRPCUsage(p.Method+" "+p.Path, tenant, calls, cost)
+	}
+}
+
+// describePolicy summarises a policy for inclusion in a denied response
+func describePolicy(p *policy.Policy) *ProblemPolicy {
+	rules := make([]string, len(p.Rules))
+	for i, rule := range p.Rules {
+		rules[i] = string(rule.Type())
+	}
+	return &ProblemPolicy{
+		Path:      p.Path,
+		Method:    p.Method,
+		Logic:     p.Logic,
+		Rules:     rules,
+		Threshold: p.Threshold,
+	}
+}
+
+// explainDenial describes the policy that denied a request, with the rules the
+// caller failed when the denial detail asks for them. Finding those rules evaluates
+// the policy again without short-circuiting; reads cached by the first evaluation
+// are served from the cache.
+func (pm *PolicyMiddleware) explainDenial(r *http.Request, claims *auth.Claims, wallets []string, p *policy.Policy) *ProblemPolicy {
+	described := describePolicy(p)
+	if pm.denialDetail != DenialDetailRules && pm.denialDetail != DenialDetailRequirements {
+		return described
+	}
+	ctx, reservations := policy.WithQuotaReservations(pm.policyContext(r, claims, wallets))
+	_, rules, _ := p.Decide(ctx, claims.Address, claims)
+	pm.releaseQuota(r, reservations)
+	described.FailedRules = failedRules(p, rules, pm.denialDetail == DenialDetailRequirements)
+	return described
+}
+
+// failedRules describes the rules of a policy the caller did not pass, by type only
+// unless requirements is set. Rules beyond the points of a SCORE policy never count
+// and are left out.
+func failedRules(p *policy.Policy, rules []policy.RuleDecision, requirements bool) []GateRequirement {
+	failed := make([]GateRequirement, 0, len(rules))
+	for i, rule := range rules {
+		if rule.Passed || (p.Logic == "SCORE" && i >= len(p.Points)) {
+			continue
+		}
+		if !requirements {
+			failed = append(failed, GateRequirement{Type: string(rule.Type)})
+			continue
+		}
+		requirement := describeRequirement(p.Rules[i])
+		if p.Logic == "SCORE" {
+			requirement.Points = p.Points[i]
+		}
+		failed = append(failed, requirement)
+	}
+	return failed
+}
+
+// Exempt opts routes out of policy evaluation when the middleware wraps a whole
+// router. Must be called before the server starts handling requests.
+func (pm *PolicyMiddleware) Exempt(routes ...*mux.Route) {
+	if pm.exempt == nil {
+		pm.exempt = make(map[*mux.Route]bool, len(routes))
+	}
+	for _, route := range routes {
+		pm.exempt[route] = true
+	}
+}
+
+// isExempt reports whether the request matched a route opted out of policy evaluation
+func (pm *PolicyMiddleware) isExempt(r *http.Request) bool {
+	if len(pm.exempt) == 0 {
+		return false
+	}
+	route := mux.CurrentRoute(r)
+	return route != nil && pm.exempt[route]
+}
+
+// SetChainID sets the chain evaluation contexts default to when the request carries no hint
+func (pm *PolicyMiddleware) SetChainID(chainID uint64) {
+	pm.chainID = chainID
+}
+
+// SetDenialDetail sets how much a policy denial tells the caller: DenialDetailPolicy
+// (the default), DenialDetailRules or DenialDetailRequirements
+func (pm *PolicyMiddleware) SetDenialDetail(detail string) {
+	pm.denialDetail = detail
+}
+
+// SetStats sets the per-policy evaluation statistics to record to
+func (pm *PolicyMiddleware) SetStats(stats *PolicyStats) {
+	pm.stats = stats
+}
+
+// SetRPCUsage sets the tracker RPC calls made while evaluating policies are attributed
+// in. Calls are only counted through a policy.MeteredProvider.
+func (pm *PolicyMiddleware) SetRPCUsage(tracker *RPCUsageTracker) {
+	pm.rpcUsage = tracker
+}
+
+// SetDecisionLog sets the log receiving one record per gated request
+func (pm *PolicyMiddleware) SetDecisionLog(decisionLog *decisionlog.Logger) {
+	pm.decisionLog = decisionLog
+}
+
+// SetCacheWarmer sets the warmer told about addresses making gated requests
+func (pm *PolicyMiddleware) SetCacheWarmer(warmer *policy.CacheWarmer) {
+	pm.warmer = warmer
+}
+
+// SetWalletRepository lets wallets linked to the caller's account satisfy
+// address-based rules such as token balances and allowlists
+func (pm *PolicyMiddleware) SetWalletRepository(wallets store.WalletRepositoryInterface) {
+	pm.wallets = wallets
+}
+
+// SetBlocklist denies callers whose address is on the blocklist on every gated
+// route, whatever its policies
+func (pm *PolicyMiddleware) SetBlocklist(blocklist policy.Blocklist) {
+	pm.blocklist = blocklist
+}
+
+// evaluationOutcome labels the policy evaluation latency histogram
+func evaluationOutcome(deniedBy *policy.Policy, evalErr error) string {
+	switch {
+	case evalErr != nil:
+		return "error"
+	case deniedBy != nil:
+		return "denied"
+	default:
+		return "allowed"
+	}
+}
+
+// SetMetrics sets the collector that records shadow policy decisions and evaluation latency
+func (pm *PolicyMiddleware) SetMetrics(metrics *MetricsCollector) {
+	pm.metrics = metrics
 }
 
 // SetProvider sets the blockchain provider for policy evaluation
 func (pm *PolicyMiddleware) SetProvider(provider policy.BlockchainProvider) {
 	// Update all ERC20 and ERC721 rules in the manager
 	for _, p := range pm.policyManager.GetAllPolicies() {
-		for _, rule := range p.Rules {
+		policy.WalkRules(p.Rules, func(rule policy.Rule) {
 			if erc20Rule, ok := rule.(*policy.ERC20MinBalanceRule); ok {
 				erc20Rule.SetProvider(provider)
 			} else if erc721Rule, ok := rule.(*policy.ERC721OwnerRule); ok {
 				erc721Rule.SetProvider(provider)
+			} else if collectionRule, ok := rule.(*policy.ERC721MinBalanceRule); ok {
+				collectionRule.SetProvider(provider)
+			} else if traitRule, ok := rule.(*policy.ERC721TraitRule); ok {
+				traitRule.SetProvider(provider)
+			} else if stakedRule, ok := rule.(*policy.StakedBalanceRule); ok {
+				stakedRule.SetProvider(provider)
+			} else if votesRule, ok := rule.(*policy.VotingPowerRule); ok {
+				votesRule.SetProvider(provider)
+			} else if callRule, ok := rule.(*policy.ContractCallRule); ok {
+				callRule.SetProvider(provider)
+			} else if lpRule, ok := rule.(*policy.LPPositionRule); ok {
+				lpRule.SetProvider(provider)
+			} else if holdingRule, ok := rule.(*policy.HoldingDurationRule); ok {
+				holdingRule.SetProvider(provider)
+			} else if codeRule, ok := rule.(*policy.CodeExistsRule); ok {
+				codeRule.SetProvider(provider)
+			} else if deployerRule, ok := rule.(*policy.ContractDeployerRule); ok {
+				deployerRule.SetProvider(provider)
 			}
-		}
+		})
 	}
 }
 
@@ -215,12 +737,31 @@ func (pm *PolicyMiddleware) SetProvider(provider policy.BlockchainProvider) {
 func (pm *PolicyMiddleware) SetCache(cache policy.CacheProvider) {
 	// Update all ERC20 and ERC721 rules in the manager
 	for _, p := range pm.policyManager.GetAllPolicies() {
-		for _, rule := range p.Rules {
+		p.SetDecisionCache(cache)
+		policy.WalkRules(p.Rules, func(rule policy.Rule) {
 			if erc20Rule, ok := rule.(*policy.ERC20MinBalanceRule); ok {
 				erc20Rule.SetCache(cache)
 			} else if erc721Rule, ok := rule.(*policy.ERC721OwnerRule); ok {
 				erc721Rule.SetCache(cache)
+			} else if collectionRule, ok := rule.(*policy.ERC721MinBalanceRule); ok {
+				collectionRule.SetCache(cache)
+			} else if traitRule, ok := rule.(*policy.ERC721TraitRule); ok {
+				traitRule.SetCache(cache)
+			} else if stakedRule, ok := rule.(*policy.StakedBalanceRule); ok {
+				stakedRule.SetCache(cache)
+			} else if votesRule, ok := rule.(*policy.VotingPowerRule); ok {
+				votesRule.SetCache(cache)
+			} else if callRule, ok := rule.(*policy.ContractCallRule); ok {
+				callRule.SetCache(cache)
+			} else if lpRule, ok := rule.(*policy.LPPositionRule); ok {
+				lpRule.SetCache(cache)
+			} else if holdingRule, ok := rule.(*policy.HoldingDurationRule); ok {
+				holdingRule.SetCache(cache)
+			} else if codeRule, ok := rule.(*policy.CodeExistsRule); ok {
+				codeRule.SetCache(cache)
+			} else if deployerRule, ok := rule.(*policy.ContractDeployerRule); ok {
+				deployerRule.SetCache(cache)
 			}
-		}
+		})
 	}
 }
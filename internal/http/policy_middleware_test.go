@@ -2,14 +2,23 @@ package http
 
 import (
 	"context"
+	"encoding/json"
 	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/chain"
+	"github.com/yourusername/gatekeeper/internal/decisionlog"
 	"github.com/yourusername/gatekeeper/internal/log"
 	"github.com/yourusername/gatekeeper/internal/policy"
 )
@@ -139,7 +148,123 @@ func TestPolicyMiddleware_FailingPolicy(t *testing.T) {
 	handler.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusForbidden, w.Code)
-	assert.Equal(t, "Forbidden\n", w.Body.String())
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+}
+
+// TestPolicyMiddleware_DeniedProblemResponse describes the denying policy in the response
+func TestPolicyMiddleware_DeniedProblemResponse(t *testing.T) {
+	pm := policy.NewPolicyManager(&mockBlockchainProvider{}, &mockCache{})
+	logger, err := log.New("debug")
+	require.NoError(t, err)
+	defer logger.Close()
+	middleware := NewPolicyMiddleware(pm, logger, nil)
+
+	pm.AddPolicy(policy.NewPolicy("GET", "/api/admin", "AND", []policy.Rule{
+		policy.NewHasScopeRule("admin"),
+	}))
+
+	handler := middleware.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	claims := &auth.Claims{
+		Address: "0x1234567890abcdef1234567890abcdef12345678",
+		Scopes:  []string{"read"},
+	}
+
+	req := httptest.NewRequest("GET", "/api/admin", nil)
+	req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, claims))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+
+	var problem Problem
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, ProblemTypePolicyDenied, problem.Type)
+	assert.Equal(t, http.StatusForbidden, problem.Status)
+	assert.Equal(t, "/api/admin", problem.Instance)
+	require.NotNil(t, problem.Policy)
+	assert.Equal(t, "/api/admin", problem.Policy.Path)
+	assert.Equal(t, "AND", problem.Policy.Logic)
+	assert.Equal(t, []string{"has_scope"}, problem.Policy.Rules)
+}
+
+// TestPolicyMiddleware_DenialDetail names the failed rules only when configured to
+func TestPolicyMiddleware_DenialDetail(t *testing.T) {
+	pm := policy.NewPolicyManager(&mockBlockchainProvider{}, &mockCache{})
+	pm.AddPolicy(policy.NewPolicy("GET", "/api/premium", "AND", []policy.Rule{
+		policy.NewHasScopeRule("read"),
+		policy.NewHasScopeRule("premium"),
+		policy.NewERC20MinBalanceRule("0x1f9840a85d5af5bf1d1762f925bdaddc4201f984", big.NewInt(1000), 1),
+	}))
+
+	deny := func(detail string) *ProblemPolicy {
+		middleware := NewPolicyMiddleware(pm, nil, nil)
+		middleware.SetDenialDetail(detail)
+		handler := middleware.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		claims := &auth.Claims{Address: "0x1234567890abcdef1234567890abcdef12345678", Scopes: []string{"read"}}
+		req := httptest.NewRequest("GET", "/api/premium", nil)
+		req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, claims))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		require.Equal(t, http.StatusForbidden, w.Code)
+
+		var problem Problem
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+		require.NotNil(t, problem.Policy)
+		return problem.Policy
+	}
+
+	assert.Empty(t, deny(DenialDetailPolicy).FailedRules)
+
+	// The balance rule was short-circuited but still reported as failed
+	assert.Equal(t, []GateRequirement{{Type: "has_scope"}, {Type: "erc20_min_balance"}}, deny(DenialDetailRules).FailedRules)
+
+	failed := deny(DenialDetailRequirements).FailedRules
+	require.Len(t, failed, 2)
+	assert.Equal(t, "premium", failed[0].Scope)
+	assert.Equal(t, "0x1f9840a85d5af5bf1d1762f925bdaddc4201f984", failed[1].Contract)
+	assert.Equal(t, "1000", failed[1].MinimumBalance)
+}
+
+// TestPolicyMiddleware_TestnetFaucets points callers denied for lacking testnet tokens to faucets
+func TestPolicyMiddleware_TestnetFaucets(t *testing.T) {
+	pm := policy.NewPolicyManager(&mockBlockchainProvider{}, &mockCache{})
+	pm.AddPolicy(policy.NewPolicy("GET", "/api/premium", "AND", []policy.Rule{
+		policy.NewERC20MinBalanceRule("0x1f9840a85d5af5bf1d1762f925bdaddc4201f984", big.NewInt(1000), 0),
+	}))
+	pm.AddPolicy(policy.NewPolicy("GET", "/api/admin", "AND", []policy.Rule{
+		policy.NewHasScopeRule("admin"),
+	}))
+
+	deny := func(chainID uint64, path string) []string {
+		middleware := NewPolicyMiddleware(pm, nil, nil)
+		middleware.SetChainID(chainID)
+		handler := middleware.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		claims := &auth.Claims{Address: "0x1234567890abcdef1234567890abcdef12345678", Scopes: []string{"read"}}
+		req := httptest.NewRequest("GET", path, nil)
+		req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, claims))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		require.Equal(t, http.StatusForbidden, w.Code)
+
+		var problem Problem
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+		return problem.Faucets
+	}
+
+	sepolia, _ := chain.LookupNetwork(11155111)
+	assert.Equal(t, sepolia.Faucets, deny(11155111, "/api/premium"))
+	assert.Empty(t, deny(1, "/api/premium"))
+	// Tokens would not help with a missing scope
+	assert.Empty(t, deny(11155111, "/api/admin"))
 }
 
 // TestPolicyMiddleware_AllowlistPolicy checks address in allowlist
@@ -480,6 +605,197 @@ func TestPolicyMiddleware_MultipleRoutes(t *testing.T) {
 	assert.Equal(t, http.StatusForbidden, w2.Code)
 }
 
+// TestPolicyMiddleware_ShadowPolicyDoesNotBlock records a shadow denial but serves the request
+func TestPolicyMiddleware_ShadowPolicyDoesNotBlock(t *testing.T) {
+	pm := policy.NewPolicyManager(&mockBlockchainProvider{}, &mockCache{})
+	logger, err := log.New("debug")
+	require.NoError(t, err)
+	defer logger.Close()
+	middleware := NewPolicyMiddleware(pm, logger, nil)
+	metrics := NewMetricsCollector(nil)
+	middleware.SetMetrics(metrics)
+
+	shadowPolicy := policy.NewPolicy("GET", "/api/data", "AND", []policy.Rule{
+		policy.NewHasScopeRule("premium"),
+	})
+	shadowPolicy.Shadow = true
+	pm.AddPolicy(shadowPolicy)
+
+	handler := middleware.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	claims := &auth.Claims{
+		Address: "0x1234567890abcdef1234567890abcdef12345678",
+		Scopes:  []string{},
+	}
+
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, claims))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(1), metrics.shadowDecisions["GET /api/data"]["would_deny"])
+}
+
+// TestPolicyMiddleware_ShadowPolicyWithEnforcedPolicy still enforces non-shadow policies
+func TestPolicyMiddleware_ShadowPolicyWithEnforcedPolicy(t *testing.T) {
+	pm := policy.NewPolicyManager(&mockBlockchainProvider{}, &mockCache{})
+	logger, err := log.New("debug")
+	require.NoError(t, err)
+	defer logger.Close()
+	middleware := NewPolicyMiddleware(pm, logger, nil)
+	metrics := NewMetricsCollector(nil)
+	middleware.SetMetrics(metrics)
+
+	shadowPolicy := policy.NewPolicy("GET", "/api/data", "AND", []policy.Rule{
+		policy.NewHasScopeRule("read:data"),
+	})
+	shadowPolicy.Shadow = true
+	pm.AddPolicy(shadowPolicy)
+	pm.AddPolicy(policy.NewPolicy("GET", "/api/data", "AND", []policy.Rule{
+		policy.NewHasScopeRule("admin"),
+	}))
+
+	handler := middleware.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	claims := &auth.Claims{
+		Address: "0x1234567890abcdef1234567890abcdef12345678",
+		Scopes:  []string{"read:data"},
+	}
+
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, claims))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, int64(1), metrics.shadowDecisions["GET /api/data"]["would_allow"])
+}
+
+// TestPolicyMiddleware_RequestRules passes the request to request rules
+func TestPolicyMiddleware_RequestRules(t *testing.T) {
+	pm := policy.NewPolicyManager(&mockBlockchainProvider{}, &mockCache{})
+	logger, err := log.New("debug")
+	require.NoError(t, err)
+	defer logger.Close()
+	middleware := NewPolicyMiddleware(pm, logger, nil)
+
+	pm.AddPolicy(policy.NewPolicy("GET", "/api/data", "AND", []policy.Rule{
+		policy.NewHeaderEqualsRule("X-Client", "mobile"),
+		policy.NewQueryParamPresentRule("preview"),
+	}))
+
+	handler := middleware.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	claims := &auth.Claims{
+		Address: "0x1234567890abcdef1234567890abcdef12345678",
+		Scopes:  []string{},
+	}
+
+	req := httptest.NewRequest("GET", "/api/data?preview=1", nil)
+	req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, claims))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	req.Header.Set("X-Client", "mobile")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestPolicyMiddleware_GeoRestriction(t *testing.T) {
+	pm := policy.NewPolicyManager(&mockBlockchainProvider{}, &mockCache{})
+	logger, err := log.New("debug")
+	require.NoError(t, err)
+	defer logger.Close()
+	middleware := NewPolicyMiddleware(pm, logger, nil)
+
+	pm.AddPolicy(policy.NewPolicy("POST", "/api/trade", "AND", []policy.Rule{
+		policy.NewGeoRestrictionRule(policy.GeoModeDeny, []string{"US"}, nil),
+	}))
+	geoIP, err := policy.LoadGeoIPCSV(strings.NewReader("192.0.2.0/24,NL,64500\n198.51.100.0/24,US,64501\n"))
+	require.NoError(t, err)
+	pm.SetGeoIPResolver(geoIP)
+
+	handler := middleware.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	claims := &auth.Claims{
+		Address: "0x1234567890abcdef1234567890abcdef12345678",
+		Scopes:  []string{},
+	}
+
+	tests := []struct {
+		clientIP string
+		expected int
+	}{
+		{"192.0.2.1", http.StatusOK},
+		{"198.51.100.1", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/api/trade", nil)
+		req.Header.Set("X-Forwarded-For", tt.clientIP)
+		req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, claims))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		assert.Equal(t, tt.expected, w.Code, tt.clientIP)
+	}
+}
+
+func TestPolicyMiddleware_Quota(t *testing.T) {
+	pm := policy.NewPolicyManager(&mockBlockchainProvider{}, &mockCache{})
+	pm.SetQuotaStore(policy.NewMemoryQuotaStore())
+	logger, err := log.New("debug")
+	require.NoError(t, err)
+	defer logger.Close()
+	middleware := NewPolicyMiddleware(pm, logger, nil)
+
+	pm.AddPolicy(policy.NewPolicy("POST", "/api/claim", "AND", []policy.Rule{
+		policy.NewQuotaRule("free-claims", 2, 24*time.Hour),
+		policy.NewHeaderEqualsRule("X-Client", "app"),
+	}))
+
+	handler := middleware.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("fail") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	claims := &auth.Claims{
+		Address: "0x1234567890abcdef1234567890abcdef12345678",
+		Scopes:  []string{},
+	}
+	send := func(target, client string) int {
+		req := httptest.NewRequest("POST", target, nil)
+		req.Header.Set("X-Client", client)
+		req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, claims))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Denied by another rule and failed requests do not consume quota
+	assert.Equal(t, http.StatusForbidden, send("/api/claim", "web"))
+	assert.Equal(t, http.StatusBadRequest, send("/api/claim?fail=1", "app"))
+
+	assert.Equal(t, http.StatusOK, send("/api/claim", "app"))
+	assert.Equal(t, http.StatusOK, send("/api/claim", "app"))
+	assert.Equal(t, http.StatusForbidden, send("/api/claim", "app"))
+}
+
 // Mock implementations for testing
 
 type mockBlockchainProvider struct {
@@ -526,3 +842,295 @@ func (m *mockCache) GetOrSet(key string, fn func() interface{}) interface{} {
 	m.Set(key, val)
 	return val
 }
+
+// TestPolicyMiddleware_ExemptRoutes evaluates policies on every route of a router except exempt ones
+func TestPolicyMiddleware_ExemptRoutes(t *testing.T) {
+	pm := policy.NewPolicyManager(&mockBlockchainProvider{}, &mockCache{})
+	logger, err := log.New("debug")
+	require.NoError(t, err)
+	defer logger.Close()
+	middleware := NewPolicyMiddleware(pm, logger, nil)
+
+	for _, path := range []string{"/api/keys", "/api/me/logins"} {
+		pm.AddPolicy(policy.NewPolicy("GET", path, "AND", []policy.Rule{
+			policy.NewHasScopeRule("admin"),
+		}))
+	}
+
+	claims := &auth.Claims{
+		Address: "0x1234567890abcdef1234567890abcdef12345678",
+		Scopes:  []string{},
+	}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	router := mux.NewRouter()
+	apiRouter := router.PathPrefix("/api").Subrouter()
+	apiRouter.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ClaimsContextKey, claims)))
+		})
+	})
+	apiRouter.Use(mux.MiddlewareFunc(middleware.Middleware()))
+	apiRouter.Handle("/keys", ok).Methods("GET")
+	middleware.Exempt(apiRouter.Handle("/me/logins", ok).Methods("GET"))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/keys", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/me/logins", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// contextCapturingRule records the evaluation context it is evaluated with
+type contextCapturingRule struct {
+	captured *policy.EvaluationContext
+}
+
+func (r *contextCapturingRule) Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	r.captured = policy.EvaluationContextFromContext(ctx)
+	return true, nil
+}
+
+func (r *contextCapturingRule) Type() policy.RuleType {
+	return policy.HasScopeRuleType
+}
+
+// TestPolicyMiddleware_EvaluationContext passes route variables, claims and the chain to rules
+func TestPolicyMiddleware_EvaluationContext(t *testing.T) {
+	pm := policy.NewPolicyManager(&mockBlockchainProvider{}, &mockCache{})
+	middleware := NewPolicyMiddleware(pm, nil, nil)
+	middleware.SetChainID(1)
+
+	rule := &contextCapturingRule{}
+	pm.AddPolicy(policy.NewPolicy("DELETE", "/api/keys/42", "AND", []policy.Rule{rule}))
+
+	claims := &auth.Claims{Address: "0x1234567890abcdef1234567890abcdef12345678"}
+	router := mux.NewRouter()
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ClaimsContextKey, claims)))
+		})
+	})
+	router.Use(mux.MiddlewareFunc(middleware.Middleware()))
+	router.HandleFunc("/api/keys/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}).Methods("DELETE")
+
+	req := httptest.NewRequest("DELETE", "/api/keys/42", nil)
+	req.RemoteAddr = "203.0.113.7:52100"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.NotNil(t, rule.captured)
+	assert.Equal(t, "DELETE", rule.captured.Method)
+	assert.Equal(t, "/api/keys/42", rule.captured.Path)
+	assert.Equal(t, map[string]string{"id": "42"}, rule.captured.Params)
+	assert.Equal(t, "203.0.113.7", rule.captured.ClientIP)
+	assert.Same(t, claims, rule.captured.Claims)
+	assert.Equal(t, uint64(1), rule.captured.ChainID)
+}
+
+func TestPolicyMiddleware_DecisionLog(t *testing.T) {
+	pm := policy.NewPolicyManager(&mockBlockchainProvider{}, &mockCache{})
+	logger, err := log.New("debug")
+	require.NoError(t, err)
+	defer logger.Close()
+	middleware := NewPolicyMiddleware(pm, logger, nil)
+
+	path := filepath.Join(t.TempDir(), "decisions.log")
+	decisionLog, err := decisionlog.New(path, decisionlog.RotationConfig{}, nil)
+	require.NoError(t, err)
+	middleware.SetDecisionLog(decisionLog)
+
+	pm.AddPolicy(policy.NewPolicy("GET", "/api/admin", "AND", []policy.Rule{
+		policy.NewHasScopeRule("admin"),
+	}))
+
+	handler := middleware.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func(path string, claims *auth.Claims) int {
+		req := httptest.NewRequest("GET", path, nil)
+		req.RemoteAddr = "203.0.113.7:4242"
+		if claims != nil {
+			req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, claims))
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	user := &auth.Claims{Address: "0x1234567890abcdef1234567890abcdef12345678", Scopes: []string{"read"}}
+	admin := &auth.Claims{Address: "0xabcdefabcdefabcdefabcdefabcdefabcdefabcd", Scopes: []string{"admin"}}
+
+	assert.Equal(t, http.StatusForbidden, serve("/api/admin", user))
+	assert.Equal(t, http.StatusOK, serve("/api/admin", admin))
+	assert.Equal(t, http.StatusOK, serve("/api/data", user))
+	assert.Equal(t, http.StatusUnauthorized, serve("/api/admin", nil))
+	require.NoError(t, decisionLog.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 4)
+
+	records := make([]decisionlog.Record, len(lines))
+	for i, line := range lines {
+		require.NoError(t, json.Unmarshal([]byte(line), &records[i]))
+	}
+
+	assert.Equal(t, decisionlog.DecisionDenied, records[0].Decision)
+	assert.Equal(t, "policy_failed", records[0].Reason)
+	assert.Equal(t, user.Address, records[0].Subject)
+	assert.Equal(t, "203.0.113.7", records[0].ClientIP)
+	assert.Equal(t, 1, records[0].Policies)
+	assert.Equal(t, &decisionlog.PolicyRef{Method: "GET", Path: "/api/admin"}, records[0].DeniedBy)
+
+	assert.Equal(t, decisionlog.DecisionAllowed, records[1].Decision)
+	assert.Equal(t, admin.Address, records[1].Subject)
+	assert.Nil(t, records[1].DeniedBy)
+
+	assert.Equal(t, decisionlog.DecisionAllowed, records[2].Decision)
+	assert.Equal(t, "no_policies", records[2].Reason)
+	assert.Equal(t, "/api/data", records[2].Path)
+
+	assert.Equal(t, decisionlog.DecisionDenied, records[3].Decision)
+	assert.Equal(t, "no_authentication", records[3].Reason)
+	assert.Empty(t, records[3].Subject)
+}
+
+func TestPolicyMiddleware_TouchesCacheWarmer(t *testing.T) {
+	pm := policy.NewPolicyManager(&mockBlockchainProvider{}, &mockCache{})
+	pm.AddPolicy(policy.NewPolicy("GET", "/api/gated", "AND", []policy.Rule{
+		policy.NewERC20MinBalanceRule("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", big.NewInt(1), 1),
+	}))
+	warmer := policy.NewCacheWarmer(pm, nil, time.Minute, time.Hour, nil)
+	middleware := NewPolicyMiddleware(pm, nil, nil)
+	middleware.SetCacheWarmer(warmer)
+
+	handler := middleware.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func(path, address string) {
+		req := httptest.NewRequest("GET", path, nil)
+		req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, &auth.Claims{Address: address}))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// Only requests to gated routes keep an address warm
+	serve("/api/open", "0x1234567890abcdef1234567890abcdef12345678")
+	refreshed, err := warmer.Run(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, refreshed)
+
+	serve("/api/gated", "0x1234567890abcdef1234567890abcdef12345678")
+	refreshed, err = warmer.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, refreshed)
+}
+
+func TestPolicyMiddleware_ObservesEvaluationLatency(t *testing.T) {
+	pm := policy.NewPolicyManager(&mockBlockchainProvider{}, &mockCache{})
+	pm.AddPolicy(policy.NewPolicy("GET", "/api/data", "AND", []policy.Rule{
+		policy.NewHasScopeRule("premium"),
+	}))
+	middleware := NewPolicyMiddleware(pm, nil, nil)
+	metrics := NewMetricsCollector(nil)
+	middleware.SetMetrics(metrics)
+
+	handler := TraceMiddleware()(middleware.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	claims := &auth.Claims{Address: "0x1234567890abcdef1234567890abcdef12345678"}
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, claims))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	h := metrics.policyLatency["denied"]
+	require.NotNil(t, h)
+	assert.Equal(t, int64(1), h.count)
+	traced := 0
+	for _, e := range h.exemplars {
+		if e != nil {
+			traced++
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", e.traceID)
+		}
+	}
+	assert.Equal(t, 1, traced)
+}
+
+func TestPolicyMiddleware_LinkedWallets(t *testing.T) {
+	account := "0x1234567890abcdef1234567890abcdef12345678"
+	linked := "0xabcdefabcdefabcdefabcdefabcdefabcdefabcd"
+	pm := policy.NewPolicyManager(&mockBlockchainProvider{}, &mockCache{})
+	pm.AddPolicy(policy.NewPolicy("GET", "/api/data", "AND", []policy.Rule{
+		policy.NewInAllowlistRule([]string{linked}),
+	}))
+	pm.AddPolicy(policy.NewPolicy("GET", "/api/profile", "AND", []policy.Rule{
+		policy.NewHasScopeRule("auth"),
+	}))
+
+	wallets := new(MockWalletRepository)
+	wallets.On("ListLinkedAddresses", mock.Anything, account).Return([]string{linked}, nil)
+	middleware := NewPolicyMiddleware(pm, nil, nil)
+	middleware.SetWalletRepository(wallets)
+
+	handler := middleware.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func(path string) int {
+		req := httptest.NewRequest("GET", path, nil)
+		req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, &auth.Claims{Address: account, Scopes: []string{"auth"}}))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/api/data"), "the linked wallet is allowlisted")
+	assert.Equal(t, http.StatusOK, serve("/api/profile"))
+	wallets.AssertNumberOfCalls(t, "ListLinkedAddresses", 1)
+}
+
+// TestPolicyMiddleware_Blocklist denies blocked callers on routes without policies
+// and on routes whose policies they pass
+func TestPolicyMiddleware_Blocklist(t *testing.T) {
+	pm := policy.NewPolicyManager(&mockBlockchainProvider{}, &mockCache{})
+	pm.AddPolicy(policy.NewPolicy("GET", "/api/open", "AND", []policy.Rule{policy.NewHasScopeRule("read")}))
+	logger, err := log.New("debug")
+	require.NoError(t, err)
+	defer logger.Close()
+	middleware := NewPolicyMiddleware(pm, logger, nil)
+	middleware.SetBlocklist(policy.NewMemoryBlocklist("0x1234567890ABCDEF1234567890abcdef12345678"))
+
+	handler := middleware.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(path, address string) *httptest.ResponseRecorder {
+		claims := &auth.Claims{Address: address, Scopes: []string{"read"}}
+		req := httptest.NewRequest("GET", path, nil)
+		req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, claims))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, path := range []string{"/api/data", "/api/open"} {
+		w := serve(path, "0x1234567890abcdef1234567890abcdef12345678")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		var problem Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+		assert.Equal(t, ProblemTypeAddressBlocked, problem.Type)
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/api/open", "0x8ba1f109551bd432803012645ac136ddd64dba72").Code)
+}
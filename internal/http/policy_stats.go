@@ -0,0 +1,215 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/yourusername/gatekeeper/internal/policy"
+)
+
+// policyStatsBuckets is the number of buckets the sliding window is divided into
+const policyStatsBuckets = 60
+
+// Policy evaluation outcomes recorded by PolicyStats
+const (
+	PolicyOutcomeAllowed = "allowed"
+	PolicyOutcomeDenied  = "denied"
+	PolicyOutcomeError   = "error"
+)
+
+// policyStatsBucket counts the evaluations of one policy during one bucket interval
+type policyStatsBucket struct {
+	start   int64 // Bucket start in Unix nanoseconds; buckets of past windows are stale
+	allowed int64
+	denied  int64
+	errors  int64
+	latency time.Duration
+}
+
+// policyStatsWindow is the sliding window of one policy
+type policyStatsWindow struct {
+	buckets       [policyStatsBuckets]policyStatsBucket
+	lastEvaluated time.Time
+}
+
+// PolicyStats keeps per-policy evaluation counts and latencies over a sliding window,
+// so stale or never-matched policies can be found. Policies are tracked by identity:
+// reloading policies starts their statistics over.
+type PolicyStats struct {
+	mu         sync.Mutex
+	window     time.Duration
+	bucketSize time.Duration
+	policies   map[*policy.Policy]*policyStatsWindow
+	now        func() time.Time
+}
+
+// NewPolicyStats creates policy statistics over the given sliding window
+func NewPolicyStats(window time.Duration) *PolicyStats {
+	bucketSize := window / policyStatsBuckets
+	if bucketSize < time.Second {
+		bucketSize = time.Second
+	}
+	return &PolicyStats{
+		window:     bucketSize * policyStatsBuckets,
+		bucketSize: bucketSize,
+		policies:   make(map[*policy.Policy]*policyStatsWindow),
+		now:        time.Now,
+	}
+}
+
+// Window returns the length of the sliding window
+func (s *PolicyStats) Window() time.Duration {
+	return s.window
+}
+
+// Record records one evaluation of a policy
+func (s *PolicyStats) Record(p *policy.Policy, outcome string, latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	w, ok := s.policies[p]
+	if !ok {
+		w = &policyStatsWindow{}
+		s.policies[p] = w
+	}
+	w.lastEvaluated = now
+
+	start := now.UnixNano() / int64(s.bucketSize) * int64(s.bucketSize)
+	bucket := &w.buckets[(start/int64(s.bucketSize))%policyStatsBuckets]
+	if bucket.start != start {
+		*bucket = policyStatsBucket{start: start}
+	}
+	switch outcome {
+	case PolicyOutcomeAllowed:
+		bucket.allowed++
+	case PolicyOutcomeDenied:
+		bucket.denied++
+	default:
+		bucket.errors++
+	}
+	bucket.latency += latency
+}
+
+// PolicyEvaluationStats summarises evaluations over the sliding window
+type PolicyEvaluationStats struct {
+	Evaluations  int64   `json:"evaluations"`
+	Allowed      int64   `json:"allowed"`
+	Denied       int64   `json:"denied"`
+	Errors       int64   `json:"errors"`
+	AllowRatio   float64 `json:"allowRatio"`
+	DenyRatio    float64 `json:"denyRatio"`
+	AvgLatencyMs float64 `json:"avgLatencyMs"`
+
+	latency time.Duration
+}
+
+// add accumulates a bucket, or other statistics
+func (e *PolicyEvaluationStats) add(allowed, denied, errors int64, latency time.Duration) {
+	e.Allowed += allowed
+	e.Denied += denied
+	e.Errors += errors
+	e.Evaluations += allowed + denied + errors
+	e.latency += latency
+}
+
+// finish computes the ratios and average latency
+func (e *PolicyEvaluationStats) finish() {
+	if e.Evaluations == 0 {
+		return
+	}
+	e.AllowRatio = float64(e.Allowed) / float64(e.Evaluations)
+	e.DenyRatio = float64(e.Denied) / float64(e.Evaluations)
+	e.AvgLatencyMs = float64(e.latency) / float64(time.Millisecond) / float64(e.Evaluations)
+}
+
+// PolicyStatsEntry represents the statistics of one loaded policy
+type PolicyStatsEntry struct {
+	Method          string     `json:"method"`
+	Path            string     `json:"path"`
+	Logic           string     `json:"logic"`
+	Rules           []string   `json:"rules"`
+	Shadow          bool       `json:"shadow,omitempty"`
+	LastEvaluatedAt *time.Time `json:"lastEvaluatedAt,omitempty"`
+	PolicyEvaluationStats
+}
+
+// PolicyStatsResponse represents the response for GET /api/admin/policies/stats
+type PolicyStatsResponse struct {
+	WindowSeconds int64                 `json:"windowSeconds"`
+	Global        PolicyEvaluationStats `json:"global"`
+	Policies      []PolicyStatsEntry    `json:"policies"`
+}
+
+// Snapshot returns the statistics of the given policies, in order. Policies that
+// were never evaluated are included with zero counts; statistics of policies no
+// longer given are dropped.
+func (s *PolicyStats) Snapshot(policies []*policy.Policy) PolicyStatsResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	oldest := s.now().Add(-s.window).UnixNano()
+	response := PolicyStatsResponse{
+		WindowSeconds: int64(s.window / time.Second),
+		Policies:      make([]PolicyStatsEntry, len(policies)),
+	}
+
+	loaded := make(map[*policy.Policy]bool, len(policies))
+	for i, p := range policies {
+		loaded[p] = true
+		summary := describePolicy(p)
+		entry := PolicyStatsEntry{
+			Method: summary.Method,
+			Path:   summary.Path,
+			Logic:  summary.Logic,
+			Rules:  summary.Rules,
+			Shadow: p.Shadow,
+		}
+
+		if w, ok := s.policies[p]; ok {
+			lastEvaluated := w.lastEvaluated
+			entry.LastEvaluatedAt = &lastEvaluated
+			for _, bucket := range w.buckets {
+				if bucket.start > oldest {
+					entry.add(bucket.allowed, bucket.denied, bucket.errors, bucket.latency)
+				}
+			}
+		}
+
+		response.Global.add(entry.Allowed, entry.Denied, entry.Errors, entry.latency)
+		entry.finish()
+		response.Policies[i] = entry
+	}
+	response.Global.finish()
+
+	for p := range s.policies {
+		if !loaded[p] {
+			delete(s.policies, p)
+		}
+	}
+	return response
+}
+
+// PolicyStatsHandler exposes policy evaluation statistics to administrators
+type PolicyStatsHandler struct {
+	stats         *PolicyStats
+	policyManager *policy.PolicyManager
+}
+
+// NewPolicyStatsHandler creates a new policy statistics handler
+func NewPolicyStatsHandler(stats *PolicyStats, pm *policy.PolicyManager) *PolicyStatsHandler {
+	return &PolicyStatsHandler{
+		stats:         stats,
+		policyManager: pm,
+	}
+}
+
+// GetStats handles GET /api/admin/policies/stats - Evaluation counts, allow/deny
+// ratios and average latency of every loaded policy over the sliding window
+func (h *PolicyStatsHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(h.stats.Snapshot(h.policyManager.GetAllPolicies()))
+}
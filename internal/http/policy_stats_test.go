@@ -0,0 +1,110 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/policy"
+)
+
+func TestPolicyStats_SlidingWindow(t *testing.T) {
+	stats := NewPolicyStats(time.Hour)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	stats.now = func() time.Time { return now }
+
+	used := policy.NewPolicy("GET", "/api/data", "AND", []policy.Rule{policy.NewHasScopeRule("read")})
+	unused := policy.NewPolicy("POST", "/api/legacy", "AND", []policy.Rule{policy.NewHasScopeRule("write")})
+
+	stats.Record(used, PolicyOutcomeAllowed, 2*time.Millisecond)
+	stats.Record(used, PolicyOutcomeAllowed, 4*time.Millisecond)
+	stats.Record(used, PolicyOutcomeDenied, 3*time.Millisecond)
+	stats.Record(used, PolicyOutcomeError, 7*time.Millisecond)
+
+	snapshot := stats.Snapshot([]*policy.Policy{used, unused})
+	assert.Equal(t, int64(3600), snapshot.WindowSeconds)
+	require.Len(t, snapshot.Policies, 2)
+
+	entry := snapshot.Policies[0]
+	assert.Equal(t, "GET", entry.Method)
+	assert.Equal(t, "/api/data", entry.Path)
+	assert.Equal(t, []string{"has_scope"}, entry.Rules)
+	assert.Equal(t, int64(4), entry.Evaluations)
+	assert.Equal(t, int64(2), entry.Allowed)
+	assert.Equal(t, int64(1), entry.Denied)
+	assert.Equal(t, int64(1), entry.Errors)
+	assert.InDelta(t, 0.5, entry.AllowRatio, 1e-9)
+	assert.InDelta(t, 0.25, entry.DenyRatio, 1e-9)
+	assert.InDelta(t, 4.0, entry.AvgLatencyMs, 1e-9)
+	require.NotNil(t, entry.LastEvaluatedAt)
+	assert.True(t, now.Equal(*entry.LastEvaluatedAt))
+
+	// Never-matched policies are listed with zero counts
+	assert.Equal(t, "/api/legacy", snapshot.Policies[1].Path)
+	assert.Zero(t, snapshot.Policies[1].Evaluations)
+	assert.Nil(t, snapshot.Policies[1].LastEvaluatedAt)
+
+	assert.Equal(t, int64(4), snapshot.Global.Evaluations)
+	assert.InDelta(t, 0.5, snapshot.Global.AllowRatio, 1e-9)
+
+	// Evaluations leave the window after an hour, but the last evaluation is remembered
+	now = now.Add(61 * time.Minute)
+	stats.Record(used, PolicyOutcomeDenied, time.Millisecond)
+	snapshot = stats.Snapshot([]*policy.Policy{used, unused})
+	assert.Equal(t, int64(1), snapshot.Policies[0].Evaluations)
+	assert.InDelta(t, 1.0, snapshot.Policies[0].DenyRatio, 1e-9)
+
+	now = now.Add(2 * time.Hour)
+	snapshot = stats.Snapshot([]*policy.Policy{used})
+	assert.Zero(t, snapshot.Policies[0].Evaluations)
+	assert.NotNil(t, snapshot.Policies[0].LastEvaluatedAt)
+}
+
+func TestPolicyStats_DropsUnloadedPolicies(t *testing.T) {
+	stats := NewPolicyStats(time.Hour)
+	old := policy.NewPolicy("GET", "/api/data", "AND", nil)
+	stats.Record(old, PolicyOutcomeAllowed, time.Millisecond)
+
+	reloaded := policy.NewPolicy("GET", "/api/data", "AND", nil)
+	snapshot := stats.Snapshot([]*policy.Policy{reloaded})
+
+	assert.Zero(t, snapshot.Policies[0].Evaluations)
+	assert.Empty(t, stats.policies)
+}
+
+func TestPolicyStatsHandler_RecordsMiddlewareEvaluations(t *testing.T) {
+	pm := policy.NewPolicyManager(&mockBlockchainProvider{}, &mockCache{})
+	pm.AddPolicy(policy.NewPolicy("GET", "/api/data", "AND", []policy.Rule{policy.NewHasScopeRule("read")}))
+
+	stats := NewPolicyStats(time.Hour)
+	middleware := NewPolicyMiddleware(pm, nil, nil)
+	middleware.SetStats(stats)
+	handler := middleware.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, scopes := range [][]string{{"read"}, {"read"}, {}} {
+		claims := &auth.Claims{Address: "0x1234567890abcdef1234567890abcdef12345678", Scopes: scopes}
+		req := httptest.NewRequest("GET", "/api/data", nil)
+		req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, claims))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	rec := httptest.NewRecorder()
+	NewPolicyStatsHandler(stats, pm).GetStats(rec, httptest.NewRequest("GET", "/api/admin/policies/stats", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response PolicyStatsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Len(t, response.Policies, 1)
+	assert.Equal(t, int64(3), response.Policies[0].Evaluations)
+	assert.Equal(t, int64(2), response.Policies[0].Allowed)
+	assert.Equal(t, int64(1), response.Policies[0].Denied)
+	assert.Equal(t, int64(3), response.Global.Evaluations)
+}
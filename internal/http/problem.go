@@ -0,0 +1,75 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ProblemContentType is the media type for RFC 7807 problem details responses
+const ProblemContentType = "application/problem+json"
+
+// Problem types identifying why a request was denied
+const (
+	ProblemTypeRateLimited       = "urn:gatekeeper:problem:rate-limited"
+	ProblemTypeTooManyInFlight   = "urn:gatekeeper:problem:too-many-in-flight"
+	ProblemTypePolicyDenied      = "urn:gatekeeper:problem:policy-denied"
+	ProblemTypeInsufficientScope = "urn:gatekeeper:problem:insufficient-scope"
+	ProblemTypeUnsupportedMedia  = "urn:gatekeeper:problem:unsupported-media-type"
+	ProblemTypePasskeyRequired   = "urn:gatekeeper:problem:passkey-required"
+	ProblemTypeAddressBlocked    = "urn:gatekeeper:problem:address-blocked"
+	ProblemTypeSessionRequired   = "urn:gatekeeper:problem:session-required"
+)
+
+// Problem is an RFC 7807 problem details body with gatekeeper extension members
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	// Extension members
+	Limit         *ProblemLimit  `json:"limit,omitempty"`
+	Policy        *ProblemPolicy `json:"policy,omitempty"`
+	RequiredScope string         `json:"requiredScope,omitempty"`
+	RetryAfter    *int           `json:"retryAfter,omitempty"` // Seconds until the request may succeed
+	Faucets       []string       `json:"faucets,omitempty"`    // Where the testnet tokens a policy requires can be requested
+}
+
+// ProblemLimit describes the rate or concurrency limit that rejected a request
+type ProblemLimit struct {
+	Name          string  `json:"name,omitempty"`
+	Tier          string  `json:"tier,omitempty"` // Policy tier whose quota was exceeded; tiered rate limits only
+	RatePerSecond float64 `json:"ratePerSecond,omitempty"`
+	Burst         int     `json:"burst,omitempty"`
+	Weight        int     `json:"weight,omitempty"`      // Tokens the rejected request takes; rate limits only
+	MaxInFlight   int     `json:"maxInFlight,omitempty"` // Concurrency limits only
+	Remaining     int     `json:"remaining"`
+}
+
+// ProblemPolicy describes the access policy that rejected a request
+type ProblemPolicy struct {
+	Path   string   `json:"path"`
+	Method string   `json:"method"`
+	Logic  string   `json:"logic"`
+	Rules  []string `json:"rules"` // Rule types the caller must satisfy
+	// Points the caller must reach under SCORE logic
+	Threshold int `json:"threshold,omitempty"`
+	// Rules the caller failed; only with a denial detail of rules or requirements
+	FailedRules []GateRequirement `json:"failedRules,omitempty"`
+}
+
+// How much a policy denial tells the caller. Thresholds, contracts and scopes are
+// only disclosed at DenialDetailRequirements; allowlists and request conditions never are.
+const (
+	DenialDetailPolicy       = "policy"       // The denying policy and the types of its rules
+	DenialDetailRules        = "rules"        // Also the types of the rules the caller failed
+	DenialDetailRequirements = "requirements" // Also what each failed rule requires
+)
+
+// writeProblem writes a problem details response
+func writeProblem(w http.ResponseWriter, problem Problem) {
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
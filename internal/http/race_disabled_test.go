@@ -0,0 +1,7 @@
+//go:build !race
+
+package http
+
+// raceEnabled reports whether tests run under the race detector, which adds
+// allocations and makes sync.Pool drop items at random
+const raceEnabled = false
@@ -2,22 +2,31 @@ package http
 
 import (
 	"fmt"
+	"math"
 	"net"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/yourusername/gatekeeper/internal/log"
 )
 
 // RateLimitMiddleware provides rate limiting functionality for HTTP endpoints
 type RateLimitMiddleware struct {
 	limiter RateLimiter
-	logger  *log.Logger
+	logger  log.Logger
 	// identifierFunc extracts the identifier from the request (user ID, IP, etc.)
 	identifierFunc func(*http.Request) string
 	// onRateLimit is called when rate limit is exceeded (for custom responses)
 	onRateLimit func(http.ResponseWriter, *http.Request, string)
+	// name identifies the limit in rate limited responses (e.g. "api_usage")
+	name string
+	// weights holds the tokens a request to a route takes; requests to other routes take one
+	weights map[*mux.Route]int
+	// tiers holds the limiters of requests granted a tier by a TIER policy; other
+	// requests are limited by limiter
+	tiers map[string]RateLimiter
 }
 
 // RateLimitMiddlewareOption configures the rate limit middleware
@@ -37,14 +46,31 @@ func WithOnRateLimit(fn func(http.ResponseWriter, *http.Request, string)) RateLi
 	}
 }
 
-// NewRateLimitMiddleware creates a new rate limiting middleware
-func NewRateLimitMiddleware(limiter RateLimiter, logger *log.Logger, opts ...RateLimitMiddlewareOption) *RateLimitMiddleware {
+// WithLimitName sets the limit name reported in rate limited responses
+func WithLimitName(name string) RateLimitMiddlewareOption {
+	return func(m *RateLimitMiddleware) {
+		m.name = name
+	}
+}
+
+// WithTierLimiters limits requests granted a tier by a TIER policy with the limiter
+// of their tier instead of the default one, so each tier gets its own quota. The
+// middleware must run after the policy middleware that grants the tier.
+func WithTierLimiters(limiters map[string]RateLimiter) RateLimitMiddlewareOption {
+	return func(m *RateLimitMiddleware) {
+		m.tiers = limiters
+	}
+}
+
+// NewRateLimitMiddleware creates a new rate limiting middleware. A nil limiter
+// only limits requests of tiers with a limiter of their own.
+func NewRateLimitMiddleware(limiter RateLimiter, logger log.Logger, opts ...RateLimitMiddlewareOption) *RateLimitMiddleware {
 	m := &RateLimitMiddleware{
 		limiter:        limiter,
 		logger:         logger,
 		identifierFunc: defaultIdentifier,
-		onRateLimit:    defaultRateLimitResponse,
 	}
+	m.onRateLimit = m.defaultRateLimitResponse
 
 	// Apply options
 	for _, opt := range opts {
@@ -58,14 +84,28 @@ func NewRateLimitMiddleware(limiter RateLimiter, logger *log.Logger, opts ...Rat
 func (m *RateLimitMiddleware) Middleware() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limiter, tier := m.limiterFor(r)
+			if limiter == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			// Extract identifier (user ID or IP address)
 			identifier := m.identifierFunc(r)
 
-			// Check rate limit
-			if !m.limiter.Allow(identifier) {
+			// Check rate limit; heavy routes take several tokens
+			weight := m.weight(r, limiter)
+			if !limiter.AllowN(identifier, weight) {
 				// Log rate limit violation
-				m.logger.Warn(fmt.Sprintf("Rate limit exceeded for %s on %s %s",
-					identifier, r.Method, r.URL.Path))
+				fields := []log.Field{
+					log.String("identifier", identifier),
+					log.String("limit", m.name),
+					log.Int("weight", weight),
+				}
+				if tier != "" {
+					fields = append(fields, log.String("tier", tier))
+				}
+				requestLogger(r, m.logger).Warn("rate limit exceeded", fields...)
 
 				// Call custom or default rate limit handler
 				m.onRateLimit(w, r, identifier)
@@ -78,6 +118,46 @@ func (m *RateLimitMiddleware) Middleware() func(http.Handler) http.Handler {
 	}
 }
 
+// SetWeight makes each request to the routes take weight tokens of the limit, so
+// expensive operations such as exports are throttled harder than cheap reads under
+// the same limit. A weight above the burst is capped at the burst, so such requests
+// are still possible with a full bucket.
+func (m *RateLimitMiddleware) SetWeight(weight int, routes ...*mux.Route) {
+	if m.weights == nil {
+		m.weights = make(map[*mux.Route]int, len(routes))
+	}
+	for _, route := range routes {
+		m.weights[route] = weight
+	}
+}
+
+// limiterFor returns the limiter of the request and the tier it was chosen for, or
+// "" if it is the default limiter
+func (m *RateLimitMiddleware) limiterFor(r *http.Request) (RateLimiter, string) {
+	if tier := TierFromContext(r); tier != "" {
+		if limiter, ok := m.tiers[tier]; ok {
+			return limiter, tier
+		}
+	}
+	return m.limiter, ""
+}
+
+// weight returns the tokens the request takes from limiter
+func (m *RateLimitMiddleware) weight(r *http.Request, limiter RateLimiter) int {
+	if len(m.weights) == 0 {
+		return 1
+	}
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return 1
+	}
+	weight, ok := m.weights[route]
+	if !ok || weight < 1 {
+		return 1
+	}
+	return min(weight, limiter.Burst())
+}
+
 // MiddlewareFunc returns a Gorilla mux compatible middleware function
 func (m *RateLimitMiddleware) MiddlewareFunc() func(http.Handler) http.Handler {
 	return m.Middleware()
@@ -88,8 +168,8 @@ func (m *RateLimitMiddleware) MiddlewareFunc() func(http.Handler) http.Handler {
 func defaultIdentifier(r *http.Request) string {
 	// Try to get user ID from JWT claims (if authenticated)
 	claims := ClaimsFromContext(r)
-	if claims != nil && claims.Address != "" {
-		return "user:" + claims.Address
+	if claims != nil && claims.Identity() != "" {
+		return "user:" + claims.Identity()
 	}
 
 	// Fallback to IP address
@@ -101,8 +181,8 @@ func defaultIdentifier(r *http.Request) string {
 // Falls back to IP if no user is authenticated
 func UserIdentifier(r *http.Request) string {
 	claims := ClaimsFromContext(r)
-	if claims != nil && claims.Address != "" {
-		return "user:" + claims.Address
+	if claims != nil && claims.Identity() != "" {
+		return "user:" + claims.Identity()
 	}
 	// Still fallback to IP for unauthenticated requests
 	return "ip:" + extractIP(r)
@@ -113,9 +193,26 @@ func IPIdentifier(r *http.Request) string {
 	return "ip:" + extractIP(r)
 }
 
+// trustForwardedHeaders controls whether extractIP honors X-Forwarded-For and X-Real-IP.
+// Disabled when the listener already reports the real client address (PROXY protocol),
+// since the headers are client-controlled.
+var trustForwardedHeaders = true
+
+// SetTrustForwardedHeaders configures whether client IPs are taken from forwarding headers
+func SetTrustForwardedHeaders(trust bool) {
+	trustForwardedHeaders = trust
+}
+
 // extractIP extracts the client IP address from the request
 // Handles X-Forwarded-For and X-Real-IP headers for proxied requests
 func extractIP(r *http.Request) string {
+	if !trustForwardedHeaders {
+		if ip, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+			return ip
+		}
+		return r.RemoteAddr
+	}
+
 	// Check X-Forwarded-For header (comma-separated list, first is client)
 	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
 		// Take the first IP in the list
@@ -150,38 +247,60 @@ func extractIP(r *http.Request) string {
 	return r.RemoteAddr
 }
 
-// defaultRateLimitResponse sends a 429 Too Many Requests response
-func defaultRateLimitResponse(w http.ResponseWriter, r *http.Request, identifier string) {
-	// Calculate retry-after based on rate limiter settings
-	// For simplicity, we use a fixed 60 seconds, but this could be calculated
-	// based on when the next token will be available
-	retryAfter := 60
+// defaultRateLimitResponse sends a 429 Too Many Requests problem response
+// describing the exceeded limit and when the next request will be accepted
+func (m *RateLimitMiddleware) defaultRateLimitResponse(w http.ResponseWriter, r *http.Request, identifier string) {
+	limiter, tier := m.limiterFor(r)
+	weight := m.weight(r, limiter)
+	retryAfter := retryAfterSeconds(limiter, weight)
 
 	// Set headers
-	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
-	w.Header().Set("X-RateLimit-Limit", fmt.Sprintf("%.0f", float64(1))) // Simplified
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limiter.Burst()))
 	w.Header().Set("X-RateLimit-Remaining", "0")
 	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Unix()+int64(retryAfter), 10))
 
-	// Send response
-	w.WriteHeader(http.StatusTooManyRequests)
-	fmt.Fprintf(w, `{"error":"Rate limit exceeded","message":"Too many requests. Please try again later.","retryAfter":%d}`, retryAfter)
+	writeProblem(w, Problem{
+		Type:     ProblemTypeRateLimited,
+		Title:    "Rate limit exceeded",
+		Status:   http.StatusTooManyRequests,
+		Detail:   fmt.Sprintf("Too many requests. Retry after %d seconds.", retryAfter),
+		Instance: r.URL.Path,
+		Limit: &ProblemLimit{
+			Name:          m.name,
+			Tier:          tier,
+			RatePerSecond: float64(limiter.Limit()),
+			Burst:         limiter.Burst(),
+			Weight:        weight,
+			Remaining:     0,
+		},
+		RetryAfter: &retryAfter,
+	})
+}
+
+// retryAfterSeconds returns the time for the token bucket of limiter to refill the
+// tokens of a request of the given weight, in whole seconds
+func retryAfterSeconds(limiter RateLimiter, weight int) int {
+	limit := float64(limiter.Limit())
+	if limit <= 0 || math.IsInf(limit, 1) {
+		return 60
+	}
+	return int(math.Ceil(float64(weight) / limit))
 }
 
 // NewUserRateLimitMiddleware creates a middleware that rate limits by user ID
-func NewUserRateLimitMiddleware(limiter RateLimiter, logger *log.Logger) *RateLimitMiddleware {
-	return NewRateLimitMiddleware(limiter, logger, WithIdentifierFunc(UserIdentifier))
+func NewUserRateLimitMiddleware(limiter RateLimiter, logger log.Logger, opts ...RateLimitMiddlewareOption) *RateLimitMiddleware {
+	return NewRateLimitMiddleware(limiter, logger, append([]RateLimitMiddlewareOption{WithIdentifierFunc(UserIdentifier)}, opts...)...)
 }
 
 // NewIPRateLimitMiddleware creates a middleware that rate limits by IP address
-func NewIPRateLimitMiddleware(limiter RateLimiter, logger *log.Logger) *RateLimitMiddleware {
-	return NewRateLimitMiddleware(limiter, logger, WithIdentifierFunc(IPIdentifier))
+func NewIPRateLimitMiddleware(limiter RateLimiter, logger log.Logger, opts ...RateLimitMiddlewareOption) *RateLimitMiddleware {
+	return NewRateLimitMiddleware(limiter, logger, append([]RateLimitMiddlewareOption{WithIdentifierFunc(IPIdentifier)}, opts...)...)
 }
 
 // PerUserRateLimitMiddleware creates a middleware specifically for per-user rate limiting
 // This is useful for authenticated endpoints like API key creation
-func PerUserRateLimitMiddleware(requestsPerWindow int, window time.Duration, burst int, logger *log.Logger) func(http.Handler) http.Handler {
+func PerUserRateLimitMiddleware(requestsPerWindow int, window time.Duration, burst int, logger log.Logger) func(http.Handler) http.Handler {
 	limiter := NewInMemoryRateLimiter(requestsPerWindow, window, burst)
 	middleware := NewUserRateLimitMiddleware(limiter, logger)
 	return middleware.Middleware()
@@ -189,7 +308,7 @@ func PerUserRateLimitMiddleware(requestsPerWindow int, window time.Duration, bur
 
 // PerIPRateLimitMiddleware creates a middleware specifically for per-IP rate limiting
 // This is useful for public endpoints or fallback rate limiting
-func PerIPRateLimitMiddleware(requestsPerWindow int, window time.Duration, burst int, logger *log.Logger) func(http.Handler) http.Handler {
+func PerIPRateLimitMiddleware(requestsPerWindow int, window time.Duration, burst int, logger log.Logger) func(http.Handler) http.Handler {
 	limiter := NewInMemoryRateLimiter(requestsPerWindow, window, burst)
 	middleware := NewIPRateLimitMiddleware(limiter, logger)
 	return middleware.Middleware()
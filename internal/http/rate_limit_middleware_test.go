@@ -2,11 +2,13 @@ package http
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/yourusername/gatekeeper/internal/auth"
 	"github.com/yourusername/gatekeeper/internal/log"
 )
@@ -93,6 +95,74 @@ func TestRateLimitMiddleware_Deny(t *testing.T) {
 	}
 }
 
+func TestRateLimitMiddleware_Weight(t *testing.T) {
+	logger, _ := log.New("info")
+	limiter := NewInMemoryRateLimiter(10, time.Hour, 5)
+	middleware := NewRateLimitMiddleware(limiter, logger, WithLimitName("api_usage"))
+
+	router := mux.NewRouter()
+	router.Use(mux.MiddlewareFunc(middleware.Middleware()))
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	router.HandleFunc("/cheap", ok)
+	middleware.SetWeight(3, router.HandleFunc("/export", ok))
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.RemoteAddr = "192.168.1.1:12345"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// The export takes 3 of the 5 tokens, so a second one is rejected
+	// while cheap requests still fit
+	if w := serve("/export"); w.Code != http.StatusOK {
+		t.Fatalf("first export: expected status 200, got %d", w.Code)
+	}
+	w := serve("/export")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second export: expected status 429, got %d", w.Code)
+	}
+	var problem Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("failed to decode problem: %v", err)
+	}
+	if problem.Limit == nil || problem.Limit.Weight != 3 {
+		t.Errorf("expected weight 3 in problem, got %+v", problem.Limit)
+	}
+	// Refilling 3 tokens at 10 per hour takes 18 minutes
+	if got := w.Header().Get("Retry-After"); got != "1080" {
+		t.Errorf("expected Retry-After 1080, got %s", got)
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := serve("/cheap"); w.Code != http.StatusOK {
+			t.Errorf("cheap request %d: expected status 200, got %d", i, w.Code)
+		}
+	}
+}
+
+func TestRateLimitMiddleware_WeightCappedAtBurst(t *testing.T) {
+	logger, _ := log.New("info")
+	limiter := NewInMemoryRateLimiter(10, time.Hour, 3)
+	middleware := NewRateLimitMiddleware(limiter, logger)
+
+	router := mux.NewRouter()
+	router.Use(mux.MiddlewareFunc(middleware.Middleware()))
+	middleware.SetWeight(10, router.HandleFunc("/export", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("GET", "/export", nil)
+	req.RemoteAddr = "192.168.1.1:12345"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200 with a full bucket, got %d", w.Code)
+	}
+}
+
 func TestRateLimitMiddleware_UserIdentifier(t *testing.T) {
 	logger, _ := log.New("info")
 	limiter := NewInMemoryRateLimiter(10, time.Hour, 3)
@@ -270,6 +340,20 @@ func TestExtractIP(t *testing.T) {
 	}
 }
 
+func TestExtractIP_ForwardedHeadersUntrusted(t *testing.T) {
+	SetTrustForwardedHeaders(false)
+	defer SetTrustForwardedHeaders(true)
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.RemoteAddr = "198.51.100.7:12345"
+	req.Header.Set("X-Forwarded-For", "203.0.113.1")
+	req.Header.Set("X-Real-IP", "203.0.113.2")
+
+	if ip := extractIP(req); ip != "198.51.100.7" {
+		t.Errorf("expected IP 198.51.100.7, got %s", ip)
+	}
+}
+
 func TestPerUserRateLimitMiddleware(t *testing.T) {
 	logger, _ := log.New("info")
 	middleware := PerUserRateLimitMiddleware(10, time.Hour, 3, logger)
@@ -374,6 +458,50 @@ func TestRateLimitMiddleware_RetryAfter(t *testing.T) {
 	}
 }
 
+func TestRateLimitMiddleware_ProblemResponse(t *testing.T) {
+	logger, _ := log.New("info")
+	limiter := NewInMemoryRateLimiter(10, time.Hour, 1)
+	middleware := NewRateLimitMiddleware(limiter, logger, WithLimitName("api_key_creation"))
+
+	handler := middleware.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	var w *httptest.ResponseRecorder
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("POST", "/api/keys", nil)
+		req.RemoteAddr = "192.168.1.1:12345"
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+	}
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != ProblemContentType {
+		t.Errorf("expected Content-Type %s, got %s", ProblemContentType, ct)
+	}
+
+	var problem Problem
+	if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+		t.Fatalf("failed to decode problem response: %v", err)
+	}
+	if problem.Type != ProblemTypeRateLimited || problem.Status != http.StatusTooManyRequests {
+		t.Errorf("unexpected problem type/status: %s/%d", problem.Type, problem.Status)
+	}
+	if problem.Limit == nil || problem.Limit.Name != "api_key_creation" || problem.Limit.Burst != 1 || problem.Limit.Remaining != 0 {
+		t.Errorf("unexpected limit details: %+v", problem.Limit)
+	}
+
+	// 10 requests per hour refills one request every 6 minutes
+	if problem.RetryAfter == nil || *problem.RetryAfter != 360 {
+		t.Errorf("expected retryAfter=360, got %v", problem.RetryAfter)
+	}
+	if w.Header().Get("Retry-After") != "360" {
+		t.Errorf("expected Retry-After header 360, got %s", w.Header().Get("Retry-After"))
+	}
+}
+
 func TestRateLimitMiddleware_CustomIdentifier(t *testing.T) {
 	logger, _ := log.New("info")
 	limiter := NewInMemoryRateLimiter(10, time.Hour, 3)
@@ -425,3 +553,55 @@ func TestRateLimitMiddleware_CustomIdentifier(t *testing.T) {
 		t.Errorf("expected status 200 for different key, got %d", w2.Code)
 	}
 }
+
+func TestRateLimitMiddleware_TierLimiters(t *testing.T) {
+	logger, _ := log.New("info")
+	middleware := NewRateLimitMiddleware(nil, logger, WithLimitName("api_usage_tier"), WithTierLimiters(map[string]RateLimiter{
+		"gold":   NewInMemoryRateLimiter(60, time.Minute, 3),
+		"silver": NewInMemoryRateLimiter(60, time.Minute, 1),
+	}))
+
+	handler := middleware.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(tier string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/data", nil)
+		req.RemoteAddr = "192.168.1.1:12345"
+		if tier != "" {
+			req = req.WithContext(context.WithValue(req.Context(), TierContextKey, tier))
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	// Each tier has its own quota
+	for i := 0; i < 3; i++ {
+		if w := serve("gold"); w.Code != http.StatusOK {
+			t.Fatalf("gold request %d: expected status 200, got %d", i+1, w.Code)
+		}
+	}
+	if w := serve("silver"); w.Code != http.StatusOK {
+		t.Fatalf("silver request: expected status 200, got %d", w.Code)
+	}
+
+	w := serve("silver")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429, got %d", w.Code)
+	}
+	var problem Problem
+	if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+		t.Fatalf("failed to decode problem response: %v", err)
+	}
+	if problem.Limit == nil || problem.Limit.Name != "api_usage_tier" || problem.Limit.Tier != "silver" || problem.Limit.Burst != 1 {
+		t.Errorf("unexpected limit details: %+v", problem.Limit)
+	}
+
+	// Without a default limiter, requests without a limited tier pass
+	for _, tier := range []string{"", "bronze"} {
+		if w := serve(tier); w.Code != http.StatusOK {
+			t.Errorf("tier %q: expected status 200, got %d", tier, w.Code)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/log"
+)
+
+// RequestLoggerMiddleware creates a middleware that stores a child logger in the
+// request context carrying the request ID, method and route. Authentication
+// middlewares add the caller's identity once it is known, so every line logged
+// through requestLogger is correlated with the request that produced it.
+// Must run after the metrics middleware, which assigns the request ID, and the
+// trace middleware when tracing is enabled.
+func RequestLoggerMiddleware(logger log.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := r.URL.Path
+			if current := mux.CurrentRoute(r); current != nil {
+				if template, err := current.GetPathTemplate(); err == nil {
+					route = template
+				}
+			}
+
+			fields := []log.Field{
+				log.String("request_id", RequestIDFromContext(r.Context())),
+				log.String("method", r.Method),
+				log.String("route", route),
+			}
+			if traceID := TraceIDFromContext(r.Context()); traceID != "" {
+				fields = append(fields, log.String("trace_id", traceID))
+			}
+			requestLogger := logger.WithFields(fields...)
+
+			next.ServeHTTP(w, r.WithContext(log.NewContext(r.Context(), requestLogger)))
+		})
+	}
+}
+
+// requestLogger returns the request-scoped logger, falling back to the given
+// logger (or a no-op logger) when the request did not pass through RequestLoggerMiddleware
+func requestLogger(r *http.Request, fallback log.Logger) log.Logger {
+	if logger := log.FromContext(r.Context()); logger != nil {
+		return logger
+	}
+	if fallback != nil {
+		return fallback
+	}
+	return log.NewNop()
+}
+
+// withAuthenticatedLogger adds the authenticated identity to the request-scoped logger
+func withAuthenticatedLogger(ctx context.Context, claims *auth.Claims) context.Context {
+	logger := log.FromContext(ctx)
+	if logger == nil {
+		return ctx
+	}
+	return log.NewContext(ctx, logger.WithFields(log.String("user_address", claims.Identity())))
+}
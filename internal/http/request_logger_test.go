@@ -0,0 +1,59 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/log"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRequestLoggerMiddleware_CorrelatesHandlerLogs(t *testing.T) {
+	core, observed := observer.New(zapcore.InfoLevel)
+	logger := log.NewZap(zap.New(core))
+
+	jwtService := auth.NewJWTService([]byte("test-secret-key-at-least-32-chars"), time.Hour)
+	token, err := jwtService.GenerateToken(context.Background(), "0x1234567890123456789012345678901234567890", nil)
+	require.NoError(t, err)
+
+	router := mux.NewRouter()
+	router.Use(mux.MiddlewareFunc(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey, "req-123")))
+		})
+	}))
+	router.Use(mux.MiddlewareFunc(RequestLoggerMiddleware(logger)))
+	router.Use(mux.MiddlewareFunc(JWTMiddleware(jwtService)))
+	router.HandleFunc("/api/keys/{id}", func(w http.ResponseWriter, r *http.Request) {
+		requestLogger(r, nil).Info("handled")
+	}).Methods("DELETE")
+
+	req := httptest.NewRequest("DELETE", "/api/keys/42", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := observed.FilterMessage("handled").All()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "req-123", fields["request_id"])
+	assert.Equal(t, "DELETE", fields["method"])
+	assert.Equal(t, "/api/keys/{id}", fields["route"])
+	assert.Equal(t, "0x1234567890123456789012345678901234567890", fields["user_address"])
+}
+
+func TestRequestLogger_Fallback(t *testing.T) {
+	fallback := log.NewNop()
+	req := httptest.NewRequest("GET", "/", nil)
+
+	assert.Same(t, fallback, requestLogger(req, fallback))
+	assert.NotNil(t, requestLogger(req, nil))
+}
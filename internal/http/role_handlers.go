@@ -0,0 +1,173 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/gatekeeper/internal/audit"
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/log"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// RoleHandler handles the administrative roles of users
+type RoleHandler struct {
+	roleRepo    store.RoleRepositoryInterface
+	logger      log.Logger
+	auditLogger audit.AuditLogger
+}
+
+// NewRoleHandler creates a new role handler
+func NewRoleHandler(roleRepo store.RoleRepositoryInterface, logger log.Logger, auditLogger audit.AuditLogger) *RoleHandler {
+	return &RoleHandler{
+		roleRepo:    roleRepo,
+		logger:      logger,
+		auditLogger: auditLogger,
+	}
+}
+
+// RoleAssignment describes a user holding an administrative role
+type RoleAssignment struct {
+	UserID      int64      `json:"userId"`
+	Address     string     `json:"address"`
+	Role        string     `json:"role"`
+	LastLoginAt *time.Time `json:"lastLoginAt,omitempty"`
+}
+
+// ListRolesResponse lists the users holding administrative roles
+type ListRolesResponse struct {
+	Users []RoleAssignment `json:"users"`
+}
+
+// SetRoleRequest sets a user's role; an empty role removes it
+type SetRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// ListRoles handles GET /api/admin/users - List the users holding a role
+func (h *RoleHandler) ListRoles(w http.ResponseWriter, r *http.Request) {
+	logger := requestLogger(r, h.logger)
+
+	users, err := h.roleRepo.ListUsersWithRoles(r.Context())
+	if err != nil {
+		logger.Error("failed to list users with roles", log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to list roles", http.StatusInternalServerError)
+		return
+	}
+
+	response := ListRolesResponse{Users: make([]RoleAssignment, 0, len(users))}
+	for _, user := range users {
+		response.Users = append(response.Users, newRoleAssignment(&user))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// SetRole handles PUT /api/admin/users/{address}/role - Grant, change or remove the
+// role of a wallet user. The user is created if the address has never signed in.
+// The last admin cannot be demoted, so the service always has one.
+func (h *RoleHandler) SetRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := requestLogger(r, h.logger)
+
+	var req SetRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, "Invalid request body", err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Role != "" {
+		if _, err := auth.ParseRole(req.Role); err != nil {
+			h.writeError(w, "Validation failed", err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	user, err := h.roleRepo.GetOrCreateUserByAddress(ctx, mux.Vars(r)["address"])
+	if err != nil {
+		if errors.Is(err, store.ErrInvalidAddress) {
+			h.writeError(w, "Validation failed", "address must be an Ethereum address", http.StatusBadRequest)
+			return
+		}
+		logger.Error("failed to get user", log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to set role", http.StatusInternalServerError)
+		return
+	}
+	previousRole := user.Role
+
+	if previousRole == string(auth.RoleAdmin) && req.Role != string(auth.RoleAdmin) {
+		admins, err := h.roleRepo.CountUsersWithRole(ctx, string(auth.RoleAdmin))
+		if err != nil {
+			logger.Error("failed to count admins", log.Error(err))
+			h.writeError(w, "Internal server error", "Failed to set role", http.StatusInternalServerError)
+			return
+		}
+		if admins <= 1 {
+			h.writeError(w, "Conflict", "The last admin cannot be demoted; grant admin to another user first", http.StatusConflict)
+			return
+		}
+	}
+
+	user, err = h.roleRepo.SetUserRole(ctx, user.ID, req.Role)
+	if err != nil {
+		logger.Error("failed to set user role", log.Int64("user_id", user.ID), log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to set role", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("user role changed",
+		log.String("address", user.Address),
+		log.String("previous_role", previousRole),
+		log.String("role", user.Role),
+	)
+
+	if h.auditLogger != nil {
+		var actor string
+		if claims := ClaimsFromContext(r); claims != nil {
+			actor = claims.Identity()
+		}
+		h.auditLogger.Log(ctx, withClientInfo(r, audit.AuditEvent{
+			Action:   audit.ActionRoleChanged,
+			Result:   audit.ResultSuccess,
+			UserAddr: actor,
+			Method:   r.Method,
+			Endpoint: r.URL.Path,
+			Metadata: map[string]interface{}{
+				"user_id":       user.ID,
+				"address":       user.Address,
+				"previous_role": previousRole,
+				"role":          user.Role,
+			},
+		}))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(newRoleAssignment(user))
+}
+
+// newRoleAssignment describes the role of user
+func newRoleAssignment(user *store.User) RoleAssignment {
+	return RoleAssignment{
+		UserID:      user.ID,
+		Address:     user.Address,
+		Role:        user.Role,
+		LastLoginAt: user.LastLoginAt,
+	}
+}
+
+// writeError writes an error response
+func (h *RoleHandler) writeError(w http.ResponseWriter, error, details string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := ErrorResponse{
+		Error:   error,
+		Details: details,
+	}
+	json.NewEncoder(w).Encode(response)
+}
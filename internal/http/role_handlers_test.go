@@ -0,0 +1,149 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/audit"
+	"github.com/yourusername/gatekeeper/internal/gatekeepertest"
+	"github.com/yourusername/gatekeeper/internal/store"
+	"go.uber.org/zap"
+)
+
+// MockRoleRepository is a mock implementation of RoleRepository
+type MockRoleRepository struct {
+	mock.Mock
+}
+
+func (m *MockRoleRepository) GetOrCreateUserByAddress(ctx context.Context, address string) (*store.User, error) {
+	args := m.Called(ctx, address)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.User), args.Error(1)
+}
+
+func (m *MockRoleRepository) SetUserRole(ctx context.Context, id int64, role string) (*store.User, error) {
+	args := m.Called(ctx, id, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.User), args.Error(1)
+}
+
+func (m *MockRoleRepository) ListUsersWithRoles(ctx context.Context) ([]store.User, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]store.User), args.Error(1)
+}
+
+func (m *MockRoleRepository) CountUsersWithRole(ctx context.Context, role string) (int, error) {
+	args := m.Called(ctx, role)
+	return args.Int(0), args.Error(1)
+}
+
+const operatorAddress = "0x8ba1f109551bd432803012645ac136ddd64dba72"
+
+func newSetRoleRequest(address, body string) *http.Request {
+	req := httptest.NewRequest("PUT", "/api/admin/users/"+address+"/role", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return mux.SetURLVars(req, map[string]string{"address": address})
+}
+
+func TestListRoles(t *testing.T) {
+	repo := new(MockRoleRepository)
+	handler := NewRoleHandler(repo, nil, nil)
+	repo.On("ListUsersWithRoles", mock.Anything).Return([]store.User{
+		{ID: 1, Address: operatorAddress, Role: "operator"},
+	}, nil)
+
+	rec := httptest.NewRecorder()
+	handler.ListRoles(rec, httptest.NewRequest("GET", "/api/admin/users", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response ListRolesResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Len(t, response.Users, 1)
+	assert.Equal(t, "operator", response.Users[0].Role)
+}
+
+func TestSetRole_Grants(t *testing.T) {
+	repo := new(MockRoleRepository)
+	sink := &recordingSink{}
+	handler := NewRoleHandler(repo, nil, audit.NewAuditLoggerWithSinks(zap.NewNop(), sink))
+	repo.On("GetOrCreateUserByAddress", mock.Anything, operatorAddress).Return(&store.User{ID: 2, Address: operatorAddress}, nil)
+	repo.On("SetUserRole", mock.Anything, int64(2), "operator").Return(&store.User{ID: 2, Address: operatorAddress, Role: "operator"}, nil)
+
+	rec := httptest.NewRecorder()
+	handler.SetRole(rec, newSetRoleRequest(operatorAddress, `{"role":"operator"}`))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response RoleAssignment
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, "operator", response.Role)
+
+	require.Len(t, sink.events, 1)
+	assert.Equal(t, audit.ActionRoleChanged, sink.events[0].Action)
+	assert.Equal(t, "", sink.events[0].Metadata["previous_role"])
+	assert.Equal(t, "operator", sink.events[0].Metadata["role"])
+}
+
+func TestSetRole_RejectsUnknownRole(t *testing.T) {
+	repo := new(MockRoleRepository)
+	handler := NewRoleHandler(repo, nil, nil)
+
+	rec := httptest.NewRecorder()
+	handler.SetRole(rec, newSetRoleRequest(operatorAddress, `{"role":"root"}`))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	repo.AssertNotCalled(t, "SetUserRole", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSetRole_KeepsLastAdmin(t *testing.T) {
+	repo := new(MockRoleRepository)
+	handler := NewRoleHandler(repo, nil, nil)
+	repo.On("GetOrCreateUserByAddress", mock.Anything, operatorAddress).Return(&store.User{ID: 2, Address: operatorAddress, Role: "admin"}, nil)
+	repo.On("CountUsersWithRole", mock.Anything, "admin").Return(1, nil).Once()
+
+	rec := httptest.NewRecorder()
+	handler.SetRole(rec, newSetRoleRequest(operatorAddress, `{"role":""}`))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	repo.AssertNotCalled(t, "SetUserRole", mock.Anything, mock.Anything, mock.Anything)
+
+	// With another admin the demotion goes through
+	repo.On("CountUsersWithRole", mock.Anything, "admin").Return(2, nil).Once()
+	repo.On("SetUserRole", mock.Anything, int64(2), "").Return(&store.User{ID: 2, Address: operatorAddress}, nil)
+
+	rec = httptest.NewRecorder()
+	handler.SetRole(rec, newSetRoleRequest(operatorAddress, `{"role":""}`))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestSetRole_WithFakeStore(t *testing.T) {
+	fake := gatekeepertest.NewStore()
+	handler := NewRoleHandler(fake, nil, nil)
+
+	rec := httptest.NewRecorder()
+	handler.SetRole(rec, newSetRoleRequest(operatorAddress, `{"role":"admin"}`))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// The only admin cannot demote themselves
+	rec = httptest.NewRecorder()
+	handler.SetRole(rec, newSetRoleRequest(operatorAddress, `{"role":"viewer"}`))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	users, err := fake.ListUsersWithRoles(context.Background())
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, "admin", users[0].Role)
+}
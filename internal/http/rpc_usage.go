@@ -0,0 +1,249 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/policy"
+)
+
+// unknownTenant is the tenant of callers without the tenant claim
+const unknownTenant = "unknown"
+
+// rpcSpend accumulates the RPC calls of one policy or tenant
+type rpcSpend struct {
+	evaluations int64
+	calls       int64
+	cost        int64
+	methods     map[string]int64
+}
+
+// add accumulates the calls of one evaluation
+func (s *rpcSpend) add(calls map[string]int64, cost int64) {
+	s.evaluations++
+	s.cost += cost
+	for method, count := range calls {
+		s.calls += count
+		s.methods[method] += count
+	}
+}
+
+// RPCUsageTracker attributes the RPC calls made while evaluating policies to the
+// policy and the tenant of the caller, with an estimated provider cost, so expensive
+// gates can be given longer cache TTLs or indexer backing. Policies are tracked by
+// identity: reloading policies starts their usage over.
+type RPCUsageTracker struct {
+	mu          sync.Mutex
+	defaultCost int64
+	costs       map[string]int64
+	tenantClaim string
+	policies    map[*policy.Policy]*rpcSpend
+	tenants     map[string]*rpcSpend
+	since       time.Time
+}
+
+// NewRPCUsageTracker creates a tracker pricing calls per method, at defaultCost for
+// methods without a price. Usage is attributed to tenants by the value of
+// tenantClaim; an empty claim disables per-tenant accounting.
+func NewRPCUsageTracker(defaultCost int, costs map[string]int, tenantClaim string) *RPCUsageTracker {
+	methodCosts := make(map[string]int64, len(costs))
+	for method, cost := range costs {
+		methodCosts[method] = int64(cost)
+	}
+	return &RPCUsageTracker{
+		defaultCost: int64(defaultCost),
+		costs:       methodCosts,
+		tenantClaim: tenantClaim,
+		policies:    make(map[*policy.Policy]*rpcSpend),
+		tenants:     make(map[string]*rpcSpend),
+		since:       time.Now(),
+	}
+}
+
+// Cost returns the estimated cost of the given calls per method
+func (t *RPCUsageTracker) Cost(calls map[string]int64) int64 {
+	var cost int64
+	for method, count := range calls {
+		price, ok := t.costs[method]
+		if !ok {
+			price = t.defaultCost
+		}
+		cost += price * count
+	}
+	return cost
+}
+
+// Tenant returns the tenant of the caller, or "" if per-tenant accounting is disabled
+func (t *RPCUsageTracker) Tenant(claims *auth.Claims) string {
+	if t.tenantClaim == "" {
+		return ""
+	}
+	if claims != nil {
+		if value, ok := claims.Claim(t.tenantClaim); ok && value != nil {
+			if tenant := fmt.Sprint(value); tenant != "" {
+				return tenant
+			}
+		}
+	}
+	return unknownTenant
+}
+
+// Record records the calls of one evaluation of a policy for a caller, and returns
+// the tenant they were attributed to and their estimated cost
+func (t *RPCUsageTracker) Record(p *policy.Policy, claims *auth.Claims, calls map[string]int64) (string, int64) {
+	tenant := t.Tenant(claims)
+	cost := t.Cost(calls)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	spend, ok := t.policies[p]
+	if !ok {
+		spend = &rpcSpend{methods: make(map[string]int64)}
+		t.policies[p] = spend
+	}
+	spend.add(calls, cost)
+
+	if tenant != "" {
+		spend, ok := t.tenants[tenant]
+		if !ok {
+			spend = &rpcSpend{methods: make(map[string]int64)}
+			t.tenants[tenant] = spend
+		}
+		spend.add(calls, cost)
+	}
+	return tenant, cost
+}
+
+// RPCSpend summarises the RPC calls of a policy or tenant
+type RPCSpend struct {
+	Evaluations        int64            `json:"evaluations"`
+	RPCCalls           int64            `json:"rpcCalls"`
+	CallsPerEvaluation float64          `json:"callsPerEvaluation"`
+	EstimatedCost      int64            `json:"estimatedCost"`
+	Methods            map[string]int64 `json:"methods,omitempty"`
+}
+
+// summary copies the accumulated spend
+func (s *rpcSpend) summary() RPCSpend {
+	summary := RPCSpend{
+		Evaluations:   s.evaluations,
+		RPCCalls:      s.calls,
+		EstimatedCost: s.cost,
+	}
+	if s.evaluations > 0 {
+		summary.CallsPerEvaluation = float64(s.calls) / float64(s.evaluations)
+	}
+	if len(s.methods) > 0 {
+		summary.Methods = make(map[string]int64, len(s.methods))
+		for method, count := range s.methods {
+			summary.Methods[method] = count
+		}
+	}
+	return summary
+}
+
+// PolicyRPCSpend represents the RPC spend of one loaded policy
+type PolicyRPCSpend struct {
+	Method string   `json:"method"`
+	Path   string   `json:"path"`
+	Rules  []string `json:"rules"`
+	Shadow bool     `json:"shadow,omitempty"`
+	RPCSpend
+}
+
+// TenantRPCSpend represents the RPC spend of one tenant
+type TenantRPCSpend struct {
+	Tenant string `json:"tenant"`
+	RPCSpend
+}
+
+// RPCUsageReport represents the response for GET /api/admin/rpc-usage
+type RPCUsageReport struct {
+	Since       time.Time        `json:"since"`
+	TenantClaim string           `json:"tenantClaim,omitempty"`
+	Total       RPCSpend         `json:"total"`
+	Policies    []PolicyRPCSpend `json:"policies"`
+	Tenants     []TenantRPCSpend `json:"tenants,omitempty"`
+}
+
+// Report returns the RPC spend of the given policies and of every tenant, most
+// expensive first. Policies that never made a call are included with zero counts;
+// usage of policies no longer given is dropped.
+func (t *RPCUsageTracker) Report(policies []*policy.Policy) RPCUsageReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	report := RPCUsageReport{
+		Since:       t.since,
+		TenantClaim: t.tenantClaim,
+		Policies:    make([]PolicyRPCSpend, len(policies)),
+	}
+
+	loaded := make(map[*policy.Policy]bool, len(policies))
+	for i, p := range policies {
+		loaded[p] = true
+		summary := describePolicy(p)
+		entry := PolicyRPCSpend{
+			Method: summary.Method,
+			Path:   summary.Path,
+			Rules:  summary.Rules,
+			Shadow: p.Shadow,
+		}
+		if spend, ok := t.policies[p]; ok {
+			entry.RPCSpend = spend.summary()
+		}
+		report.Total.Evaluations += entry.Evaluations
+		report.Total.RPCCalls += entry.RPCCalls
+		report.Total.EstimatedCost += entry.EstimatedCost
+		report.Policies[i] = entry
+	}
+	if report.Total.Evaluations > 0 {
+		report.Total.CallsPerEvaluation = float64(report.Total.RPCCalls) / float64(report.Total.Evaluations)
+	}
+	sort.SliceStable(report.Policies, func(i, j int) bool {
+		return report.Policies[i].EstimatedCost > report.Policies[j].EstimatedCost
+	})
+
+	for tenant, spend := range t.tenants {
+		report.Tenants = append(report.Tenants, TenantRPCSpend{Tenant: tenant, RPCSpend: spend.summary()})
+	}
+	sort.Slice(report.Tenants, func(i, j int) bool {
+		a, b := report.Tenants[i], report.Tenants[j]
+		return a.EstimatedCost > b.EstimatedCost || (a.EstimatedCost == b.EstimatedCost && a.Tenant < b.Tenant)
+	})
+
+	for p := range t.policies {
+		if !loaded[p] {
+			delete(t.policies, p)
+		}
+	}
+	return report
+}
+
+// RPCUsageHandler exposes the RPC spend of policies and tenants to administrators
+type RPCUsageHandler struct {
+	tracker       *RPCUsageTracker
+	policyManager *policy.PolicyManager
+}
+
+// NewRPCUsageHandler creates a new RPC usage handler
+func NewRPCUsageHandler(tracker *RPCUsageTracker, pm *policy.PolicyManager) *RPCUsageHandler {
+	return &RPCUsageHandler{
+		tracker:       tracker,
+		policyManager: pm,
+	}
+}
+
+// GetReport handles GET /api/admin/rpc-usage - RPC calls and estimated provider
+// cost of every loaded policy and of every tenant, most expensive first
+func (h *RPCUsageHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(h.tracker.Report(h.policyManager.GetAllPolicies()))
+}
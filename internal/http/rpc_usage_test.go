@@ -0,0 +1,119 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/policy"
+)
+
+func TestRPCUsageTracker_Report(t *testing.T) {
+	tracker := NewRPCUsageTracker(1, map[string]int{"eth_getLogs": 75}, "org")
+
+	cheap := policy.NewPolicy("GET", "/api/data", "AND", []policy.Rule{policy.NewHasScopeRule("read")})
+	expensive := policy.NewPolicy("GET", "/api/history", "AND", []policy.Rule{policy.NewCodeExistsRule(1)})
+	unused := policy.NewPolicy("POST", "/api/legacy", "AND", nil)
+
+	acme := &auth.Claims{Extra: map[string]interface{}{"org": "acme"}}
+	tenant, cost := tracker.Record(expensive, acme, map[string]int64{"eth_getLogs": 2, "eth_call": 1})
+	assert.Equal(t, "acme", tenant)
+	assert.Equal(t, int64(151), cost)
+	tracker.Record(expensive, &auth.Claims{}, map[string]int64{"eth_call": 1})
+	tracker.Record(cheap, acme, map[string]int64{})
+
+	report := tracker.Report([]*policy.Policy{cheap, expensive, unused})
+	assert.Equal(t, "org", report.TenantClaim)
+	require.Len(t, report.Policies, 3)
+
+	// Most expensive first
+	top := report.Policies[0]
+	assert.Equal(t, "/api/history", top.Path)
+	assert.Equal(t, []string{"code_exists"}, top.Rules)
+	assert.Equal(t, int64(2), top.Evaluations)
+	assert.Equal(t, int64(4), top.RPCCalls)
+	assert.InDelta(t, 2.0, top.CallsPerEvaluation, 1e-9)
+	assert.Equal(t, int64(152), top.EstimatedCost)
+	assert.Equal(t, map[string]int64{"eth_getLogs": 2, "eth_call": 2}, top.Methods)
+
+	assert.Equal(t, "/api/data", report.Policies[1].Path)
+	assert.Equal(t, int64(1), report.Policies[1].Evaluations)
+	assert.Zero(t, report.Policies[1].RPCCalls)
+	assert.Equal(t, "/api/legacy", report.Policies[2].Path)
+	assert.Zero(t, report.Policies[2].Evaluations)
+
+	assert.Equal(t, int64(3), report.Total.Evaluations)
+	assert.Equal(t, int64(4), report.Total.RPCCalls)
+	assert.Equal(t, int64(152), report.Total.EstimatedCost)
+
+	require.Len(t, report.Tenants, 2)
+	assert.Equal(t, "acme", report.Tenants[0].Tenant)
+	assert.Equal(t, int64(2), report.Tenants[0].Evaluations)
+	assert.Equal(t, int64(151), report.Tenants[0].EstimatedCost)
+	assert.Equal(t, unknownTenant, report.Tenants[1].Tenant)
+	assert.Equal(t, int64(1), report.Tenants[1].RPCCalls)
+
+	// Usage of policies that are no longer loaded is dropped
+	tracker.Report([]*policy.Policy{cheap})
+	assert.Len(t, tracker.policies, 1)
+}
+
+func TestRPCUsageTracker_NoTenantClaim(t *testing.T) {
+	tracker := NewRPCUsageTracker(1, nil, "")
+	p := policy.NewPolicy("GET", "/api/data", "AND", nil)
+
+	tenant, cost := tracker.Record(p, &auth.Claims{Scopes: []string{"read"}}, map[string]int64{"eth_call": 3})
+	assert.Empty(t, tenant)
+	assert.Equal(t, int64(3), cost)
+	assert.Empty(t, tracker.Report([]*policy.Policy{p}).Tenants)
+}
+
+func TestRPCUsageHandler_RecordsMiddlewareEvaluations(t *testing.T) {
+	pm := policy.NewPolicyManager(policy.NewMeteredProvider(&mockBlockchainProvider{}), nil)
+	pm.AddPolicy(policy.NewPolicy("GET", "/api/contracts", "AND", []policy.Rule{policy.NewCodeExistsRule(1)}))
+
+	tracker := NewRPCUsageTracker(26, nil, "org")
+	metrics := NewMetricsCollector(nil)
+	middleware := NewPolicyMiddleware(pm, nil, nil)
+	middleware.SetRPCUsage(tracker)
+	middleware.SetMetrics(metrics)
+	handler := middleware.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, org := range []string{"acme", "acme", "globex"} {
+		claims := &auth.Claims{
+			Address: "0x1234567890abcdef1234567890abcdef12345678",
+			Extra:   map[string]interface{}{"org": org},
+		}
+		req := httptest.NewRequest("GET", "/api/contracts", nil)
+		req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, claims))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	rec := httptest.NewRecorder()
+	NewRPCUsageHandler(tracker, pm).GetReport(rec, httptest.NewRequest("GET", "/api/admin/rpc-usage", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var report RPCUsageReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	require.Len(t, report.Policies, 1)
+	assert.Equal(t, int64(3), report.Policies[0].RPCCalls)
+	assert.Equal(t, map[string]int64{"eth_getCode": 3}, report.Policies[0].Methods)
+	assert.Equal(t, int64(78), report.Policies[0].EstimatedCost)
+	require.Len(t, report.Tenants, 2)
+	assert.Equal(t, "acme", report.Tenants[0].Tenant)
+	assert.Equal(t, int64(2), report.Tenants[0].RPCCalls)
+
+	metricsRec := httptest.NewRecorder()
+	metrics.ServeHTTP(metricsRec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Contains(t, metricsRec.Body.String(), `policy_rpc_calls_total{policy="GET /api/contracts",method="eth_getCode"} 3`)
+	assert.Contains(t, metricsRec.Body.String(), `tenant_rpc_cost_total{tenant="globex"} 26`)
+}
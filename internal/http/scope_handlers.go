@@ -0,0 +1,74 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/yourusername/gatekeeper/internal/auth"
+)
+
+// ScopeHandler exposes the scope catalog so UIs can offer valid scopes
+type ScopeHandler struct {
+	catalog *auth.ScopeCatalog
+}
+
+// NewScopeHandler creates a new scope handler
+func NewScopeHandler(catalog *auth.ScopeCatalog) *ScopeHandler {
+	return &ScopeHandler{catalog: catalog}
+}
+
+// ListScopesResponse represents the response when listing scopes
+type ListScopesResponse struct {
+	Scopes []auth.ScopeDefinition `json:"scopes"`
+}
+
+// ListScopes handles GET /api/scopes - List scopes that can be granted to keys
+func (h *ScopeHandler) ListScopes(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ListScopesResponse{Scopes: h.catalog.List()})
+}
+
+// unknownScopesDetail returns a validation message naming scopes missing from the catalog,
+// or an empty string if all scopes are known or no catalog is configured
+func unknownScopesDetail(catalog *auth.ScopeCatalog, scopes []string) string {
+	if catalog == nil {
+		return ""
+	}
+	unknown := catalog.Unknown(scopes)
+	if len(unknown) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Unknown scopes: %s (see GET /api/scopes)", strings.Join(unknown, ", "))
+}
+
+// excessScopesDetail returns a message naming requested scopes the caller may not
+// grant, or an empty string if it may grant them all. Scopes granting a role or a
+// privileged scope such as authz:check, directly, through a wildcard or through
+// the catalog, need a caller holding that role in users.role; callers
+// authenticated with an API key may only grant scopes their key has.
+func excessScopesDetail(r *http.Request, catalog *auth.ScopeCatalog, role string, requested []string) string {
+	excess := auth.ScopesBeyondRole(requested, auth.Role(role))
+	if catalog != nil {
+		for _, scope := range requested {
+			implied := catalog.Expand([]string{scope})
+			if len(auth.ScopesBeyondRole(implied, auth.Role(role))) > 0 && !slices.Contains(excess, scope) {
+				excess = append(excess, scope)
+			}
+		}
+	}
+	if claims := ClaimsFromContext(r); claims != nil && AuthMethodFromContext(r) == AuthMethodAPIKey {
+		for _, scope := range requested {
+			if !auth.HasScope(claims.Scopes, scope) && !slices.Contains(excess, scope) {
+				excess = append(excess, scope)
+			}
+		}
+	}
+	if len(excess) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("You may not grant scopes you do not hold: %s", strings.Join(excess, ", "))
+}
@@ -0,0 +1,136 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/log"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+func TestListScopes(t *testing.T) {
+	catalog := auth.DefaultScopeCatalog()
+	handler := NewScopeHandler(catalog)
+
+	req := httptest.NewRequest("GET", "/api/scopes", nil)
+	rr := httptest.NewRecorder()
+
+	handler.ListScopes(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response ListScopesResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	require.Len(t, response.Scopes, len(catalog.List()))
+	assert.Equal(t, "read", response.Scopes[0].Name)
+	assert.NotEmpty(t, response.Scopes[0].Description)
+}
+
+func TestCreateAPIKey_UnknownScope(t *testing.T) {
+	apiKeyRepo := new(MockAPIKeyRepository)
+	userRepo := new(MockUserRepository)
+	logger, _ := log.New("info")
+	handler := NewAPIKeyHandler(apiKeyRepo, userRepo, logger, nil)
+	handler.SetScopeCatalog(auth.DefaultScopeCatalog())
+
+	body, _ := json.Marshal(CreateAPIKeyRequest{Name: "typo key", Scopes: []string{"read", "wirte"}})
+	req := withClaims(httptest.NewRequest("POST", "/api/keys", bytes.NewReader(body)),
+		&auth.Claims{Address: "0x1234567890123456789012345678901234567890"})
+	rr := httptest.NewRecorder()
+
+	handler.CreateAPIKey(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	var response ErrorResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Contains(t, response.Details, "wirte")
+	assert.NotContains(t, response.Details, "read,")
+
+	apiKeyRepo.AssertNotCalled(t, "CreateAPIKey")
+	userRepo.AssertNotCalled(t, "GetOrCreateUserByAddress")
+}
+
+// TestCreateAPIKey_RoleScopes only lets users grant the roles they hold
+func TestCreateAPIKey_RoleScopes(t *testing.T) {
+	const address = "0x1234567890123456789012345678901234567890"
+	tests := []struct {
+		name   string
+		role   string
+		scopes []string
+		status int
+	}{
+		{"non-admin asking for admin", "", []string{"read", "admin"}, http.StatusForbidden},
+		{"non-admin asking for everything", "", []string{"*"}, http.StatusForbidden},
+		{"operator asking for admin", "operator", []string{"admin"}, http.StatusForbidden},
+		{"operator passing on viewer", "operator", []string{"viewer"}, http.StatusCreated},
+		{"admin asking for admin", "admin", []string{"admin"}, http.StatusCreated},
+		{"non-admin asking for authz:check", "", []string{"authz:check"}, http.StatusForbidden},
+		{"viewer asking for authz:check", "viewer", []string{"authz:check"}, http.StatusForbidden},
+		{"viewer asking for authz:*", "viewer", []string{"authz:*"}, http.StatusForbidden},
+		{"operator passing on authz:check", "operator", []string{"authz:check"}, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiKeyRepo := new(MockAPIKeyRepository)
+			userRepo := new(MockUserRepository)
+			logger, _ := log.New("info")
+			handler := NewAPIKeyHandler(apiKeyRepo, userRepo, logger, nil)
+			handler.SetScopeCatalog(auth.DefaultScopeCatalog())
+
+			userRepo.On("GetOrCreateUserByAddress", mock.Anything, address).Return(&store.User{ID: 1, Address: address, Role: tt.role}, nil)
+			apiKeyRepo.On("CreateAPIKey", mock.Anything, mock.Anything).Return("raw", &store.APIKeyResponse{
+				ID: 1, KeyHash: "abcdef1234567890", Name: "role key", Scopes: tt.scopes,
+			}, nil)
+
+			body, _ := json.Marshal(CreateAPIKeyRequest{Name: "role key", Scopes: tt.scopes})
+			// Sessions carry the role scope; the role is still read from users.role
+			req := withClaims(httptest.NewRequest("POST", "/api/keys", bytes.NewReader(body)),
+				&auth.Claims{Address: address, Scopes: []string{"auth", "admin"}})
+			rr := httptest.NewRecorder()
+
+			handler.CreateAPIKey(rr, req)
+
+			assert.Equal(t, tt.status, rr.Code)
+			if tt.status == http.StatusForbidden {
+				apiKeyRepo.AssertNotCalled(t, "CreateAPIKey")
+			}
+		})
+	}
+}
+
+// TestCreateAPIKey_APIKeyCallerScopes only lets API keys grant scopes they have
+func TestCreateAPIKey_APIKeyCallerScopes(t *testing.T) {
+	const address = "0x1234567890123456789012345678901234567890"
+	apiKeyRepo := new(MockAPIKeyRepository)
+	userRepo := new(MockUserRepository)
+	logger, _ := log.New("info")
+	handler := NewAPIKeyHandler(apiKeyRepo, userRepo, logger, nil)
+
+	userRepo.On("GetOrCreateUserByAddress", mock.Anything, address).Return(&store.User{ID: 1, Address: address}, nil)
+	apiKeyRepo.On("CreateAPIKey", mock.Anything, mock.Anything).Return("raw", &store.APIKeyResponse{
+		ID: 1, KeyHash: "abcdef1234567890", Name: "child", Scopes: []string{"read"},
+	}, nil)
+
+	create := func(scopes ...string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(CreateAPIKeyRequest{Name: "child", Scopes: scopes})
+		req := withClaims(httptest.NewRequest("POST", "/api/keys", bytes.NewReader(body)),
+			&auth.Claims{Address: address, Scopes: []string{"read", "keys:write", "keys:read"}})
+		req = req.WithContext(context.WithValue(req.Context(), AuthMethodContextKey, AuthMethodAPIKey))
+		rr := httptest.NewRecorder()
+		handler.CreateAPIKey(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusCreated, create("read").Code)
+	rr := create("read", "write")
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Contains(t, rr.Body.String(), "write")
+	assert.Equal(t, http.StatusForbidden, create("keys:*").Code)
+}
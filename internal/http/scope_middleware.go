@@ -0,0 +1,184 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/gatekeeper/internal/auth"
+)
+
+// Scopes guarding the API key management, policy decision and admin endpoints
+const (
+	ScopeKeysRead   = "keys:read"
+	ScopeKeysWrite  = "keys:write"
+	ScopeAuthzCheck = "authz:check"
+	ScopeAdmin      = "admin"
+)
+
+// Scopes API keys need on routes without a scope declaration: ScopeRead for safe
+// methods and ScopeWrite for everything else
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+)
+
+// RequireAPIKeyScope creates a middleware that rejects API-key-authenticated requests
+// lacking the given scope. Requests authenticated with a JWT session are not restricted,
+// since the wallet owner always manages their own keys. When enforced is false the
+// middleware is a no-op, preserving the behavior from before scopes were checked.
+func RequireAPIKeyScope(scope string, enforced bool) Middleware {
+	if !enforced {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	return RequireScopes(scope)
+}
+
+// RequireScopes creates a middleware that rejects API-key-authenticated requests
+// lacking any of the given scopes. JWT sessions and capability tokens are not
+// restricted.
+func RequireScopes(scopes ...string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !checkAPIKeyScopes(w, r, scopes) {
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RouteScopes holds the scopes API keys need for each route when its middleware
+// wraps a whole router. A declared route requires all of its scopes, possibly none;
+// any other route requires ScopeRead for safe methods and ScopeWrite otherwise, so a
+// read-only key cannot reach mutating endpoints.
+type RouteScopes struct {
+	declared map[*mux.Route][]string
+}
+
+// NewRouteScopes creates an empty set of route scope declarations
+func NewRouteScopes() *RouteScopes {
+	return &RouteScopes{declared: make(map[*mux.Route][]string)}
+}
+
+// Declare sets the scopes API keys need for route, replacing the method default.
+// Must be called before the server starts handling requests.
+func (s *RouteScopes) Declare(route *mux.Route, scopes ...string) *mux.Route {
+	s.declared[route] = scopes
+	return route
+}
+
+// Required returns the scopes an API key needs for the request
+func (s *RouteScopes) Required(r *http.Request) []string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if scopes, ok := s.declared[route]; ok {
+			return scopes
+		}
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return []string{ScopeRead}
+	default:
+		return []string{ScopeWrite}
+	}
+}
+
+// Middleware returns a middleware rejecting API-key-authenticated requests that lack
+// the scopes required for the matched route
+func (s *RouteScopes) Middleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if AuthMethodFromContext(r) == AuthMethodAPIKey && !checkAPIKeyScopes(w, r, s.Required(r)) {
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// checkAPIKeyScopes reports whether an API-key-authenticated request holds every
+// scope, writing an insufficient scope problem naming the first missing one if not.
+// Other authentication methods always pass.
+func checkAPIKeyScopes(w http.ResponseWriter, r *http.Request, scopes []string) bool {
+	if AuthMethodFromContext(r) != AuthMethodAPIKey {
+		return true
+	}
+	claims := ClaimsFromContext(r)
+	for _, scope := range scopes {
+		if claims == nil || !auth.HasScope(claims.Scopes, scope) {
+			detail := fmt.Sprintf("API key requires the %s scope", scope)
+			if len(scopes) > 1 {
+				detail = fmt.Sprintf("API key requires the %s scopes", strings.Join(scopes, ", "))
+			}
+			writeProblem(w, Problem{
+				Type:          ProblemTypeInsufficientScope,
+				Title:         "Insufficient scope",
+				Status:        http.StatusForbidden,
+				Detail:        detail,
+				Instance:      r.URL.Path,
+				RequiredScope: scope,
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// RequireScope creates a middleware that rejects requests whose claims lack the given
+// scope, regardless of how the request was authenticated. The scopes of JWT sessions
+// are expanded through catalog, if set, so a session holding admin has what admin
+// implies; API key claims are expanded when the key is authenticated.
+func RequireScope(scope string, catalog *auth.ScopeCatalog) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims := ClaimsFromContext(r)
+			var granted []string
+			if claims != nil {
+				granted = claims.Scopes
+				if catalog != nil && AuthMethodFromContext(r) == AuthMethodJWT {
+					granted = catalog.Expand(granted)
+				}
+			}
+			if !auth.HasScope(granted, scope) {
+				writeProblem(w, Problem{
+					Type:          ProblemTypeInsufficientScope,
+					Title:         "Insufficient scope",
+					Status:        http.StatusForbidden,
+					Detail:        fmt.Sprintf("This endpoint requires the %s scope", scope),
+					Instance:      r.URL.Path,
+					RequiredScope: scope,
+				})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireRole creates a middleware that rejects requests whose claims grant neither
+// the given role nor a more privileged one, regardless of how the request was
+// authenticated
+func RequireRole(role auth.Role) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims := ClaimsFromContext(r)
+			var granted auth.Role
+			if claims != nil {
+				granted, _ = auth.HighestRole(claims.Scopes)
+			}
+			if !granted.Includes(role) {
+				writeProblem(w, Problem{
+					Type:          ProblemTypeInsufficientScope,
+					Title:         "Insufficient scope",
+					Status:        http.StatusForbidden,
+					Detail:        fmt.Sprintf("This endpoint requires the %s role", role),
+					Instance:      r.URL.Path,
+					RequiredScope: role.Scope(),
+				})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
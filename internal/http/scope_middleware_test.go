@@ -0,0 +1,157 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/yourusername/gatekeeper/internal/auth"
+)
+
+func requestWithAuthMethod(method string, scopes []string) *http.Request {
+	req := httptest.NewRequest("GET", "/api/keys", nil)
+	ctx := context.WithValue(req.Context(), ClaimsContextKey, &auth.Claims{
+		Address: "0x1234567890123456789012345678901234567890",
+		Scopes:  scopes,
+	})
+	ctx = context.WithValue(ctx, AuthMethodContextKey, method)
+	return req.WithContext(ctx)
+}
+
+func TestRequireAPIKeyScope(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		scopes     []string
+		enforced   bool
+		wantStatus int
+	}{
+		{"api key with scope", AuthMethodAPIKey, []string{ScopeKeysRead}, true, http.StatusOK},
+		{"api key with wildcard", AuthMethodAPIKey, []string{"keys:*"}, true, http.StatusOK},
+		{"api key without scope", AuthMethodAPIKey, []string{"read"}, true, http.StatusForbidden},
+		{"api key with sibling scope", AuthMethodAPIKey, []string{ScopeKeysWrite}, true, http.StatusForbidden},
+		{"jwt session", AuthMethodJWT, nil, true, http.StatusOK},
+		{"enforcement disabled", AuthMethodAPIKey, []string{"read"}, false, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := RequireAPIKeyScope(ScopeKeysRead, tt.enforced)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, requestWithAuthMethod(tt.method, tt.scopes))
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			if tt.wantStatus == http.StatusForbidden {
+				assert.Equal(t, ProblemContentType, rr.Header().Get("Content-Type"))
+				assert.Contains(t, rr.Body.String(), ProblemTypeInsufficientScope)
+			}
+		})
+	}
+}
+
+func TestDefaultScopeCatalog_AdminGrantsKeyScopes(t *testing.T) {
+	claims := auth.DefaultScopeCatalog().Expand([]string{"admin"})
+
+	assert.True(t, auth.HasScope(claims, ScopeKeysRead))
+	assert.True(t, auth.HasScope(claims, ScopeKeysWrite))
+}
+
+func TestRequireScopes(t *testing.T) {
+	handler := RequireScopes("read", "write")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, requestWithAuthMethod(AuthMethodAPIKey, []string{"read", "write"}))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, requestWithAuthMethod(AuthMethodAPIKey, []string{"read"}))
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"requiredScope":"write"`)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, requestWithAuthMethod(AuthMethodJWT, nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestRouteScopes(t *testing.T) {
+	scopes := NewRouteScopes()
+	router := mux.NewRouter()
+	router.Use(mux.MiddlewareFunc(scopes.Middleware()))
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	router.Handle("/api/data", ok).Methods("GET", "POST")
+	scopes.Declare(router.Handle("/api/keys", ok).Methods("POST"), "keys:write")
+	scopes.Declare(router.Handle("/api/open", ok).Methods("POST"))
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		authMethod string
+		scopes     []string
+		wantStatus int
+	}{
+		{"read key reads", "GET", "/api/data", AuthMethodAPIKey, []string{"read"}, http.StatusOK},
+		{"read key cannot mutate", "POST", "/api/data", AuthMethodAPIKey, []string{"read"}, http.StatusForbidden},
+		{"write key mutates", "POST", "/api/data", AuthMethodAPIKey, []string{"write"}, http.StatusOK},
+		{"wildcard key mutates", "POST", "/api/data", AuthMethodAPIKey, []string{"*"}, http.StatusOK},
+		{"declared scope replaces default", "POST", "/api/keys", AuthMethodAPIKey, []string{"keys:write"}, http.StatusOK},
+		{"declared scope required", "POST", "/api/keys", AuthMethodAPIKey, []string{"write"}, http.StatusForbidden},
+		{"hierarchical wildcard", "POST", "/api/keys", AuthMethodAPIKey, []string{"keys:*"}, http.StatusOK},
+		{"declared without scopes", "POST", "/api/open", AuthMethodAPIKey, nil, http.StatusOK},
+		{"jwt session", "POST", "/api/data", AuthMethodJWT, nil, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			ctx := context.WithValue(req.Context(), ClaimsContextKey, &auth.Claims{Scopes: tt.scopes})
+			ctx = context.WithValue(ctx, AuthMethodContextKey, tt.authMethod)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req.WithContext(ctx))
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		scopes     []string
+		wantStatus int
+	}{
+		{"operator session", AuthMethodJWT, []string{"auth", "operator"}, http.StatusOK},
+		{"admin session", AuthMethodJWT, []string{"auth", "admin"}, http.StatusOK},
+		{"viewer session", AuthMethodJWT, []string{"auth", "viewer"}, http.StatusForbidden},
+		{"session without role", AuthMethodJWT, []string{"auth"}, http.StatusForbidden},
+		{"admin api key", AuthMethodAPIKey, []string{"admin", "read", "write"}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := RequireRole(auth.RoleOperator)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, requestWithAuthMethod(tt.method, tt.scopes))
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			if tt.wantStatus == http.StatusForbidden {
+				assert.Contains(t, rr.Body.String(), `"requiredScope":"operator"`)
+			}
+		})
+	}
+}
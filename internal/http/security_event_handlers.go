@@ -0,0 +1,135 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/gatekeeper/internal/log"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// Security event page sizes
+const (
+	defaultSecurityEventsLimit = 20
+	maxSecurityEventsLimit     = 100
+)
+
+// SecurityEventHandler lets users review the security events of their own account
+type SecurityEventHandler struct {
+	eventRepo store.SecurityEventRepositoryInterface
+	logger    log.Logger
+}
+
+// NewSecurityEventHandler creates a new security event handler
+func NewSecurityEventHandler(eventRepo store.SecurityEventRepositoryInterface, logger log.Logger) *SecurityEventHandler {
+	return &SecurityEventHandler{
+		eventRepo: eventRepo,
+		logger:    logger,
+	}
+}
+
+// SecurityEventResponse represents one security event of the caller
+type SecurityEventResponse struct {
+	ID        int64     `json:"id"`
+	Action    string    `json:"action"`
+	Result    string    `json:"result,omitempty"`
+	IPAddress string    `json:"ipAddress,omitempty"`
+	UserAgent string    `json:"userAgent,omitempty"`
+	Method    string    `json:"method,omitempty"`
+	Endpoint  string    `json:"endpoint,omitempty"`
+	KeyID     *int64    `json:"keyId,omitempty"`
+	KeyName   string    `json:"keyName,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ListSecurityEventsResponse represents a page of the caller's security events
+type ListSecurityEventsResponse struct {
+	Events []SecurityEventResponse `json:"events"`
+	// NextBefore is passed as ?before= to fetch the next page; omitted on the last page
+	NextBefore int64 `json:"nextBefore,omitempty"`
+}
+
+// ListSecurityEvents handles GET /api/me/audit - Security events of the caller's
+// wallet, newest first: sign-ins, key and credential changes and denied requests.
+// Optional query parameters: limit (default 20, max 100) and before (an event ID,
+// for paging).
+func (h *SecurityEventHandler) ListSecurityEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := requestLogger(r, h.logger)
+	query := r.URL.Query()
+
+	claims := ClaimsFromContext(r)
+	if claims == nil {
+		h.writeError(w, "Unauthorized", "No authentication claims found", http.StatusUnauthorized)
+		return
+	}
+	if claims.Address == "" {
+		h.writeError(w, "Forbidden", "Security events are only available for wallet accounts", http.StatusForbidden)
+		return
+	}
+
+	limit := defaultSecurityEventsLimit
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 || parsed > maxSecurityEventsLimit {
+			h.writeError(w, "Invalid limit", "limit must be between 1 and 100", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+	var before int64
+	if raw := query.Get("before"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed <= 0 {
+			h.writeError(w, "Invalid request", "before must be a positive event ID", http.StatusBadRequest)
+			return
+		}
+		before = parsed
+	}
+
+	events, err := h.eventRepo.ListSecurityEvents(ctx, claims.Address, before, limit)
+	if err != nil {
+		logger.Error("failed to list security events", log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to retrieve security events", http.StatusInternalServerError)
+		return
+	}
+
+	response := ListSecurityEventsResponse{Events: make([]SecurityEventResponse, len(events))}
+	for i, event := range events {
+		response.Events[i] = SecurityEventResponse{
+			ID:        event.ID,
+			Action:    event.Action,
+			Result:    event.Result,
+			IPAddress: event.IPAddress,
+			UserAgent: event.UserAgent,
+			Method:    event.Method,
+			Endpoint:  event.Endpoint,
+			KeyID:     event.KeyID,
+			KeyName:   event.KeyName,
+			Error:     event.Error,
+			CreatedAt: event.CreatedAt,
+		}
+	}
+	if len(events) == limit {
+		response.NextBefore = events[len(events)-1].ID
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeError writes an error response
+func (h *SecurityEventHandler) writeError(w http.ResponseWriter, error, details string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := ErrorResponse{
+		Error:   error,
+		Details: details,
+	}
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,122 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// MockSecurityEventRepository is a mock implementation of SecurityEventRepository
+type MockSecurityEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockSecurityEventRepository) CreateSecurityEvent(ctx context.Context, event *store.SecurityEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockSecurityEventRepository) ListSecurityEvents(ctx context.Context, subject string, beforeID int64, limit int) ([]store.SecurityEvent, error) {
+	args := m.Called(ctx, subject, beforeID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]store.SecurityEvent), args.Error(1)
+}
+
+func (m *MockSecurityEventRepository) DeleteSecurityEventsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func newSecurityEventsRequest(t *testing.T, query string, claims *auth.Claims) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/api/me/audit"+query, nil)
+	if claims != nil {
+		req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, claims))
+	}
+	return req
+}
+
+func TestListSecurityEvents_ReturnsPage(t *testing.T) {
+	eventRepo := new(MockSecurityEventRepository)
+	handler := NewSecurityEventHandler(eventRepo, nil)
+
+	address := "0x742d35cc6634c0532925a3b844bc9e7595f0beb0"
+	keyID := int64(3)
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	eventRepo.On("ListSecurityEvents", mock.Anything, address, int64(0), 2).Return([]store.SecurityEvent{
+		{ID: 9, Subject: address, Action: "authz_denied", Result: "denied", Method: "GET", Endpoint: "/api/data", CreatedAt: at},
+		{ID: 7, Subject: address, Action: "api_key_created", Result: "success", KeyID: &keyID, KeyName: "ci", CreatedAt: at},
+	}, nil)
+
+	rec := httptest.NewRecorder()
+	handler.ListSecurityEvents(rec, newSecurityEventsRequest(t, "?limit=2", &auth.Claims{Address: address}))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response ListSecurityEventsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Len(t, response.Events, 2)
+	assert.Equal(t, "authz_denied", response.Events[0].Action)
+	assert.Equal(t, "/api/data", response.Events[0].Endpoint)
+	require.NotNil(t, response.Events[1].KeyID)
+	assert.Equal(t, keyID, *response.Events[1].KeyID)
+	assert.Equal(t, int64(7), response.NextBefore, "a full page links to the next one")
+	eventRepo.AssertExpectations(t)
+}
+
+func TestListSecurityEvents_LastPage(t *testing.T) {
+	eventRepo := new(MockSecurityEventRepository)
+	handler := NewSecurityEventHandler(eventRepo, nil)
+
+	address := "0x742d35cc6634c0532925a3b844bc9e7595f0beb0"
+	eventRepo.On("ListSecurityEvents", mock.Anything, address, int64(7), 20).Return([]store.SecurityEvent{}, nil)
+
+	rec := httptest.NewRecorder()
+	handler.ListSecurityEvents(rec, newSecurityEventsRequest(t, "?before=7", &auth.Claims{Address: address}))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"events":[]}`, rec.Body.String())
+}
+
+func TestListSecurityEvents_Errors(t *testing.T) {
+	address := "0x742d35cc6634c0532925a3b844bc9e7595f0beb0"
+
+	tests := []struct {
+		name   string
+		query  string
+		claims *auth.Claims
+		status int
+	}{
+		{"unauthenticated", "", nil, http.StatusUnauthorized},
+		{"service account", "", &auth.Claims{Scopes: []string{"read"}}, http.StatusForbidden},
+		{"limit too large", "?limit=101", &auth.Claims{Address: address}, http.StatusBadRequest},
+		{"invalid before", "?before=abc", &auth.Claims{Address: address}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			NewSecurityEventHandler(new(MockSecurityEventRepository), nil).ListSecurityEvents(rec, newSecurityEventsRequest(t, tt.query, tt.claims))
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+
+	t.Run("repository failure", func(t *testing.T) {
+		eventRepo := new(MockSecurityEventRepository)
+		eventRepo.On("ListSecurityEvents", mock.Anything, address, int64(0), 20).Return(nil, errors.New("connection refused"))
+
+		rec := httptest.NewRecorder()
+		NewSecurityEventHandler(eventRepo, nil).ListSecurityEvents(rec, newSecurityEventsRequest(t, "", &auth.Claims{Address: address}))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
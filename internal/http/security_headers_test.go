@@ -0,0 +1,58 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityHeadersMiddleware(t *testing.T) {
+	handler := SecurityHeadersMiddleware(SecurityHeadersPolicy{
+		HSTSMaxAge:            365 * 24 * time.Hour,
+		HSTSIncludeSubdomains: true,
+		FrameAncestors: map[string]string{
+			"/docs":      "'none'",
+			"/api/admin": "'self'",
+		},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name                 string
+		path                 string
+		expectedCSP          string
+		expectedFrameOptions string
+	}{
+		{name: "docs", path: "/docs", expectedCSP: "frame-ancestors 'none'", expectedFrameOptions: "DENY"},
+		{name: "admin", path: "/api/admin/log-level", expectedCSP: "frame-ancestors 'self'", expectedFrameOptions: "SAMEORIGIN"},
+		{name: "other routes", path: "/api/data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+
+			assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+			assert.Equal(t, "max-age=31536000; includeSubDomains", rec.Header().Get("Strict-Transport-Security"))
+			assert.Equal(t, tt.expectedCSP, rec.Header().Get("Content-Security-Policy"))
+			assert.Equal(t, tt.expectedFrameOptions, rec.Header().Get("X-Frame-Options"))
+		})
+	}
+}
+
+func TestSecurityHeadersMiddleware_HSTSDisabled(t *testing.T) {
+	handler := SecurityHeadersMiddleware(SecurityHeadersPolicy{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/health", nil))
+
+	assert.Empty(t, rec.Header().Get("Strict-Transport-Security"))
+	assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+}
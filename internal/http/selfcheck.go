@@ -0,0 +1,208 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/yourusername/gatekeeper/internal/chain"
+	"github.com/yourusername/gatekeeper/internal/log"
+	"github.com/yourusername/gatekeeper/internal/policy"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// selfCheckTimeout bounds the database and RPC checks of one self-check
+const selfCheckTimeout = 10 * time.Second
+
+// Self-check report statuses
+const (
+	SelfCheckOK       = "ok"
+	SelfCheckDegraded = "degraded"
+)
+
+// MigrationChecker reports which embedded migrations the database schema reflects
+type MigrationChecker interface {
+	MigrationStatus(ctx context.Context) ([]store.MigrationState, error)
+}
+
+// EndpointChecker measures each configured RPC endpoint
+type EndpointChecker interface {
+	CheckEndpoints(ctx context.Context) []chain.EndpointCheck
+}
+
+// SelfCheck summarizes the running configuration and the state of its
+// dependencies. It runs once at startup and on demand for support requests.
+type SelfCheck struct {
+	version       string
+	config        map[string]interface{} // Configuration with secrets redacted
+	features      map[string]bool
+	policyManager *policy.PolicyManager
+
+	migrations      MigrationChecker
+	provider        EndpointChecker
+	expectedChainID uint64
+}
+
+// NewSelfCheck creates a self-check. The configuration summary must already
+// have its secrets redacted.
+func NewSelfCheck(version string, configSummary map[string]interface{}, features map[string]bool, pm *policy.PolicyManager) *SelfCheck {
+	return &SelfCheck{
+		version:       version,
+		config:        configSummary,
+		features:      features,
+		policyManager: pm,
+	}
+}
+
+// SetMigrationChecker sets the database whose migration status is reported
+func (s *SelfCheck) SetMigrationChecker(migrations MigrationChecker) {
+	s.migrations = migrations
+}
+
+// SetProvider sets the RPC provider to check and the chain ID its endpoints
+// are expected to report
+func (s *SelfCheck) SetProvider(provider EndpointChecker, expectedChainID uint64) {
+	s.provider = provider
+	s.expectedChainID = expectedChainID
+}
+
+// SelfCheckReport is the outcome of a self-check. Status is degraded when any
+// problem was found; Problems lists them.
+type SelfCheckReport struct {
+	Status     string                 `json:"status"`
+	Problems   []string               `json:"problems,omitempty"`
+	Version    string                 `json:"version"`
+	CheckedAt  time.Time              `json:"checkedAt"`
+	Config     map[string]interface{} `json:"config"`
+	Migrations *SelfCheckMigrations   `json:"migrations,omitempty"`
+	Providers  []SelfCheckProvider    `json:"providers"`
+	Policies   SelfCheckPolicies      `json:"policies"`
+	Features   map[string]bool        `json:"features"`
+}
+
+// SelfCheckMigrations summarizes the migration status of the database
+type SelfCheckMigrations struct {
+	Total   int                    `json:"total"`
+	Applied int                    `json:"applied"`
+	Latest  string                 `json:"latest,omitempty"` // Latest applied migration
+	Pending []store.MigrationState `json:"pending,omitempty"`
+	Error   string                 `json:"error,omitempty"`
+}
+
+// SelfCheckProvider is the check of one RPC endpoint
+type SelfCheckProvider struct {
+	chain.EndpointCheck
+	LatencyMs      float64 `json:"latencyMs"`
+	ChainIDMatches bool    `json:"chainIdMatches"` // The endpoint serves the configured chain
+}
+
+// SelfCheckPolicies counts the loaded policies
+type SelfCheckPolicies struct {
+	Total  int `json:"total"`
+	Shadow int `json:"shadow"` // Evaluated without enforcing their decision
+}
+
+// Run performs the checks and returns the report
+func (s *SelfCheck) Run(ctx context.Context) *SelfCheckReport {
+	ctx, cancel := context.WithTimeout(ctx, selfCheckTimeout)
+	defer cancel()
+
+	report := &SelfCheckReport{
+		Status:    SelfCheckOK,
+		Version:   s.version,
+		CheckedAt: time.Now().UTC(),
+		Config:    s.config,
+		Providers: []SelfCheckProvider{},
+		Features:  s.features,
+	}
+
+	if s.migrations != nil {
+		report.Migrations = s.checkMigrations(ctx, report)
+	}
+
+	if s.provider != nil {
+		for _, check := range s.provider.CheckEndpoints(ctx) {
+			provider := SelfCheckProvider{
+				EndpointCheck:  check,
+				LatencyMs:      float64(check.Latency.Microseconds()) / 1000,
+				ChainIDMatches: check.Error == "" && check.ChainID == s.expectedChainID,
+			}
+			switch {
+			case check.Error != "":
+				report.addProblem(fmt.Sprintf("%s RPC provider %s failed: %s", check.Role, check.Host, check.Error))
+			case !provider.ChainIDMatches:
+				report.addProblem(fmt.Sprintf("%s RPC provider %s serves chain %d, configured chain is %d", check.Role, check.Host, check.ChainID, s.expectedChainID))
+			}
+			report.Providers = append(report.Providers, provider)
+		}
+	}
+
+	if s.policyManager != nil {
+		for _, p := range s.policyManager.GetAllPolicies() {
+			report.Policies.Total++
+			if p.Shadow {
+				report.Policies.Shadow++
+			}
+		}
+	}
+
+	return report
+}
+
+// checkMigrations summarizes the migration status for the report
+func (s *SelfCheck) checkMigrations(ctx context.Context, report *SelfCheckReport) *SelfCheckMigrations {
+	migrations := &SelfCheckMigrations{}
+	states, err := s.migrations.MigrationStatus(ctx)
+	if err != nil {
+		migrations.Error = err.Error()
+		report.addProblem(fmt.Sprintf("migration status unavailable: %v", err))
+		return migrations
+	}
+
+	migrations.Total = len(states)
+	for _, state := range states {
+		if state.Applied {
+			migrations.Applied++
+			migrations.Latest = state.Name
+			continue
+		}
+		migrations.Pending = append(migrations.Pending, state)
+		report.addProblem(fmt.Sprintf("migration %s is not applied", state.Name))
+	}
+	return migrations
+}
+
+// addProblem records a problem and marks the report degraded
+func (r *SelfCheckReport) addProblem(problem string) {
+	r.Problems = append(r.Problems, problem)
+	r.Status = SelfCheckDegraded
+}
+
+// Log writes the report as one structured entry, a warning when degraded
+func (r *SelfCheckReport) Log(logger log.Logger) {
+	fields := []log.Field{
+		log.String("status", r.Status),
+		log.Strings("problems", r.Problems),
+		log.String("version", r.Version),
+		log.Any("config", r.Config),
+		log.Any("migrations", r.Migrations),
+		log.Any("providers", r.Providers),
+		log.Any("policies", r.Policies),
+		log.Any("features", r.Features),
+	}
+	if r.Status != SelfCheckOK {
+		logger.Warn("startup self-check found problems", fields...)
+		return
+	}
+	logger.Info("startup self-check passed", fields...)
+}
+
+// GetReport handles GET /api/admin/selfcheck - Runs the self-check again and
+// returns its report
+func (s *SelfCheck) GetReport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(s.Run(r.Context()))
+}
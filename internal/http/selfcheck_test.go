@@ -0,0 +1,119 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/chain"
+	"github.com/yourusername/gatekeeper/internal/policy"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+type stubMigrationChecker struct {
+	states []store.MigrationState
+	err    error
+}
+
+func (s stubMigrationChecker) MigrationStatus(ctx context.Context) ([]store.MigrationState, error) {
+	return s.states, s.err
+}
+
+type stubEndpointChecker []chain.EndpointCheck
+
+func (s stubEndpointChecker) CheckEndpoints(ctx context.Context) []chain.EndpointCheck {
+	return s
+}
+
+func newTestSelfCheck() *SelfCheck {
+	pm := policy.NewPolicyManager(nil, nil)
+	shadow := policy.NewPolicy("GET", "/api/beta", "AND", []policy.Rule{policy.NewHasScopeRule("beta")})
+	shadow.Shadow = true
+	pm.ReloadPolicies([]*policy.Policy{
+		policy.NewPolicy("GET", "/api/data", "AND", []policy.Rule{policy.NewHasScopeRule("read")}),
+		shadow,
+	})
+
+	return NewSelfCheck("1.2.3", map[string]interface{}{"JWTSecret": "[REDACTED]"}, map[string]bool{"tracing": true}, pm)
+}
+
+func TestSelfCheck_OK(t *testing.T) {
+	selfCheck := newTestSelfCheck()
+	selfCheck.SetMigrationChecker(stubMigrationChecker{states: []store.MigrationState{
+		{Name: "001_create_users_table.sql", Applied: true},
+		{Name: "002_create_nonces_table.sql", Applied: true},
+	}})
+	selfCheck.SetProvider(stubEndpointChecker{
+		{Role: "primary", Host: "eth.example.com", ChainID: 1, Latency: 1500 * time.Microsecond},
+	}, 1)
+
+	report := selfCheck.Run(context.Background())
+
+	assert.Equal(t, SelfCheckOK, report.Status)
+	assert.Empty(t, report.Problems)
+	assert.Equal(t, "1.2.3", report.Version)
+	assert.Equal(t, 2, report.Migrations.Applied)
+	assert.Equal(t, "002_create_nonces_table.sql", report.Migrations.Latest)
+	require.Len(t, report.Providers, 1)
+	assert.True(t, report.Providers[0].ChainIDMatches)
+	assert.InDelta(t, 1.5, report.Providers[0].LatencyMs, 1e-9)
+	assert.Equal(t, SelfCheckPolicies{Total: 2, Shadow: 1}, report.Policies)
+	assert.True(t, report.Features["tracing"])
+}
+
+func TestSelfCheck_Degraded(t *testing.T) {
+	selfCheck := newTestSelfCheck()
+	selfCheck.SetMigrationChecker(stubMigrationChecker{states: []store.MigrationState{
+		{Name: "001_create_users_table.sql", Applied: true},
+		{Name: "002_create_nonces_table.sql", Missing: []string{"nonces"}},
+	}})
+	selfCheck.SetProvider(stubEndpointChecker{
+		{Role: "primary", Host: "eth.example.com", ChainID: 5},
+		{Role: "fallback", Host: "fallback.example.com", Error: "RPC call failed"},
+	}, 1)
+
+	report := selfCheck.Run(context.Background())
+
+	assert.Equal(t, SelfCheckDegraded, report.Status)
+	assert.Equal(t, "001_create_users_table.sql", report.Migrations.Latest)
+	require.Len(t, report.Migrations.Pending, 1)
+	assert.Equal(t, []string{"nonces"}, report.Migrations.Pending[0].Missing)
+	assert.Len(t, report.Problems, 3)
+	assert.False(t, report.Providers[0].ChainIDMatches)
+	assert.False(t, report.Providers[1].ChainIDMatches)
+}
+
+func TestSelfCheck_MigrationStatusError(t *testing.T) {
+	selfCheck := newTestSelfCheck()
+	selfCheck.SetMigrationChecker(stubMigrationChecker{err: errors.New("connection refused")})
+
+	report := selfCheck.Run(context.Background())
+
+	assert.Equal(t, SelfCheckDegraded, report.Status)
+	assert.Contains(t, report.Migrations.Error, "connection refused")
+	assert.Empty(t, report.Providers)
+}
+
+func TestSelfCheck_GetReport(t *testing.T) {
+	selfCheck := newTestSelfCheck()
+
+	req := httptest.NewRequest("GET", "/api/admin/selfcheck", nil)
+	w := httptest.NewRecorder()
+	selfCheck.GetReport(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "ok", body["status"])
+	assert.Equal(t, "[REDACTED]", body["config"].(map[string]interface{})["JWTSecret"])
+	assert.Equal(t, []interface{}{}, body["providers"])
+	assert.NotContains(t, body, "migrations")
+}
@@ -0,0 +1,291 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/gatekeeper/internal/audit"
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/log"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// ServiceAccountHandler handles service account management endpoints.
+// Service accounts are owned by a wallet user and hold API keys for
+// internal jobs that should not be attributed to a human's wallet.
+type ServiceAccountHandler struct {
+	serviceAccountRepo store.ServiceAccountRepositoryInterface
+	apiKeyRepo         store.APIKeyRepositoryInterface
+	userRepo           store.UserRepositoryInterface
+	logger             log.Logger
+	auditLogger        audit.AuditLogger
+	scopeCatalog       *auth.ScopeCatalog
+}
+
+// NewServiceAccountHandler creates a new service account handler
+func NewServiceAccountHandler(serviceAccountRepo store.ServiceAccountRepositoryInterface, apiKeyRepo store.APIKeyRepositoryInterface, userRepo store.UserRepositoryInterface, logger log.Logger, auditLogger audit.AuditLogger) *ServiceAccountHandler {
+	return &ServiceAccountHandler{
+		serviceAccountRepo: serviceAccountRepo,
+		apiKeyRepo:         apiKeyRepo,
+		userRepo:           userRepo,
+		logger:             logger,
+		auditLogger:        auditLogger,
+	}
+}
+
+// SetScopeCatalog restricts the scopes that can be requested for service account keys
+func (h *ServiceAccountHandler) SetScopeCatalog(catalog *auth.ScopeCatalog) {
+	h.scopeCatalog = catalog
+}
+
+// CreateServiceAccountRequest represents the request to create a service account
+type CreateServiceAccountRequest struct {
+	Name string `json:"name"`
+}
+
+// ServiceAccountResponse represents a service account in API responses
+type ServiceAccountResponse struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Owner     string    `json:"owner"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ListServiceAccountsResponse represents the response when listing service accounts
+type ListServiceAccountsResponse struct {
+	ServiceAccounts []ServiceAccountResponse `json:"serviceAccounts"`
+}
+
+// CreateServiceAccount handles POST /api/service-accounts
+func (h *ServiceAccountHandler) CreateServiceAccount(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := requestLogger(r, h.logger)
+
+	claims := ClaimsFromContext(r)
+	if claims == nil || claims.Address == "" {
+		h.writeError(w, "Unauthorized", "Service accounts must be created by a wallet user", http.StatusUnauthorized)
+		return
+	}
+
+	var req CreateServiceAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, "Invalid request body", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Name == "" {
+		h.writeError(w, "Validation failed", "Name is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Name) > 255 {
+		h.writeError(w, "Validation failed", "Name must be 255 characters or less", http.StatusBadRequest)
+		return
+	}
+
+	owner, err := h.userRepo.GetOrCreateUserByAddress(ctx, claims.Address)
+	if err != nil {
+		logger.Error("failed to get or create user", log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to retrieve user", http.StatusInternalServerError)
+		return
+	}
+
+	account, err := h.serviceAccountRepo.CreateServiceAccount(ctx, req.Name, owner.ID)
+	if err != nil {
+		if errors.Is(err, store.ErrDuplicate) {
+			h.writeError(w, "Conflict", "A service account with this name already exists", http.StatusConflict)
+			return
+		}
+		logger.Error("failed to create service account", log.Int64("owner_id", owner.ID), log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to create service account", http.StatusInternalServerError)
+		return
+	}
+
+	if h.auditLogger != nil {
+		h.auditLogger.Log(ctx, audit.AuditEvent{
+			Action:         audit.ActionServiceAccountCreated,
+			Result:         audit.ResultSuccess,
+			UserAddr:       claims.Address,
+			ServiceAccount: account.Name,
+			ResourceID:     fmt.Sprintf("service_account:%d", account.ID),
+		})
+	}
+
+	logger.Info("service account created", log.Int64("service_account_id", account.ID), log.String("service_account", account.Name))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ServiceAccountResponse{
+		ID:        account.ID,
+		Name:      account.Name,
+		Owner:     owner.Address,
+		CreatedAt: account.CreatedAt,
+	})
+}
+
+// ListServiceAccounts handles GET /api/service-accounts
+func (h *ServiceAccountHandler) ListServiceAccounts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := requestLogger(r, h.logger)
+
+	claims := ClaimsFromContext(r)
+	if claims == nil || claims.Address == "" {
+		h.writeError(w, "Unauthorized", "No authentication claims found", http.StatusUnauthorized)
+		return
+	}
+
+	owner, err := h.userRepo.GetUserByAddress(ctx, claims.Address)
+	if err != nil {
+		h.writeError(w, "User not found", "User does not exist", http.StatusNotFound)
+		return
+	}
+
+	accounts, err := h.serviceAccountRepo.ListServiceAccounts(ctx, owner.ID)
+	if err != nil {
+		logger.Error("failed to list service accounts", log.Int64("owner_id", owner.ID), log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to retrieve service accounts", http.StatusInternalServerError)
+		return
+	}
+
+	response := ListServiceAccountsResponse{
+		ServiceAccounts: make([]ServiceAccountResponse, len(accounts)),
+	}
+	for i, account := range accounts {
+		response.ServiceAccounts[i] = ServiceAccountResponse{
+			ID:        account.ID,
+			Name:      account.Name,
+			Owner:     owner.Address,
+			CreatedAt: account.CreatedAt,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// CreateServiceAccountKey handles POST /api/service-accounts/{id}/keys
+// Issues an API key held by the service account rather than the caller.
+func (h *ServiceAccountHandler) CreateServiceAccountKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := requestLogger(r, h.logger)
+
+	claims := ClaimsFromContext(r)
+	if claims == nil || claims.Address == "" {
+		h.writeError(w, "Unauthorized", "No authentication claims found", http.StatusUnauthorized)
+		return
+	}
+
+	accountID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.writeError(w, "Invalid request", "Service account ID must be a valid integer", http.StatusBadRequest)
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, "Invalid request body", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Name == "" {
+		h.writeError(w, "Validation failed", "Name is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Scopes) == 0 {
+		h.writeError(w, "Validation failed", "At least one scope is required", http.StatusBadRequest)
+		return
+	}
+	if detail := unknownScopesDetail(h.scopeCatalog, req.Scopes); detail != "" {
+		h.writeError(w, "Validation failed", detail, http.StatusBadRequest)
+		return
+	}
+	if req.ExpiresInSeconds != nil && *req.ExpiresInSeconds <= 0 {
+		h.writeError(w, "Validation failed", "ExpiresInSeconds must be positive", http.StatusBadRequest)
+		return
+	}
+
+	owner, err := h.userRepo.GetUserByAddress(ctx, claims.Address)
+	if err != nil {
+		h.writeError(w, "User not found", "User does not exist", http.StatusNotFound)
+		return
+	}
+
+	account, err := h.userRepo.GetUserByID(ctx, accountID)
+	if err != nil || !account.IsServiceAccount() {
+		h.writeError(w, "Service account not found", "The specified service account does not exist", http.StatusNotFound)
+		return
+	}
+	if account.OwnerID == nil || *account.OwnerID != owner.ID {
+		logger.Warn("attempt to create key for service account owned by another user", log.Int64("service_account_id", accountID))
+		h.writeError(w, "Forbidden", "You do not own this service account", http.StatusForbidden)
+		return
+	}
+
+	// Keys may not grant more than their holder has
+	if detail := excessScopesDetail(r, h.scopeCatalog, owner.Role, req.Scopes); detail != "" {
+		h.writeError(w, "Forbidden", detail, http.StatusForbidden)
+		return
+	}
+
+	var expiresIn *time.Duration
+	if req.ExpiresInSeconds != nil {
+		duration := time.Duration(*req.ExpiresInSeconds) * time.Second
+		expiresIn = &duration
+	}
+
+	rawKey, apiKeyResponse, err := h.apiKeyRepo.CreateAPIKey(ctx, store.APIKeyCreateRequest{
+		UserID:    account.ID,
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresIn: expiresIn,
+	})
+	if err != nil {
+		logger.Error("failed to create API key for service account", log.Int64("service_account_id", account.ID), log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to create API key", http.StatusInternalServerError)
+		return
+	}
+
+	if h.auditLogger != nil {
+		h.auditLogger.LogAPIKeyCreated(ctx, audit.AuditEvent{
+			Result:         audit.ResultSuccess,
+			UserAddr:       claims.Address,
+			ServiceAccount: account.Name,
+			KeyID:          apiKeyResponse.ID,
+			KeyName:        apiKeyResponse.Name,
+			KeyScopes:      apiKeyResponse.Scopes,
+			ResourceID:     fmt.Sprintf("key:%d", apiKeyResponse.ID),
+		})
+	}
+
+	logger.Info("API key created for service account", log.Int64("key_id", apiKeyResponse.ID), log.String("service_account", account.Name))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(CreateAPIKeyResponse{
+		Key:       rawKey,
+		KeyHash:   apiKeyResponse.KeyHash[:8],
+		Name:      apiKeyResponse.Name,
+		Scopes:    apiKeyResponse.Scopes,
+		ExpiresAt: apiKeyResponse.ExpiresAt,
+		CreatedAt: apiKeyResponse.CreatedAt,
+		Message:   "Save this key securely - you won't see it again",
+	})
+}
+
+// writeError writes a JSON error response
+func (h *ServiceAccountHandler) writeError(w http.ResponseWriter, error, details string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := ErrorResponse{
+		Error:   error,
+		Details: details,
+	}
+	json.NewEncoder(w).Encode(response)
+}
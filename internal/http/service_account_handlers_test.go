@@ -0,0 +1,192 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/log"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// MockServiceAccountRepository is a mock implementation of ServiceAccountRepositoryInterface
+type MockServiceAccountRepository struct {
+	mock.Mock
+}
+
+func (m *MockServiceAccountRepository) CreateServiceAccount(ctx context.Context, name string, ownerID int64) (*store.User, error) {
+	args := m.Called(ctx, name, ownerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.User), args.Error(1)
+}
+
+func (m *MockServiceAccountRepository) ListServiceAccounts(ctx context.Context, ownerID int64) ([]store.User, error) {
+	args := m.Called(ctx, ownerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]store.User), args.Error(1)
+}
+
+func withClaims(req *http.Request, claims *auth.Claims) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, claims))
+}
+
+func TestCreateServiceAccount_Success(t *testing.T) {
+	saRepo := new(MockServiceAccountRepository)
+	userRepo := new(MockUserRepository)
+	logger, _ := log.New("info")
+	handler := NewServiceAccountHandler(saRepo, new(MockAPIKeyRepository), userRepo, logger, nil)
+
+	owner := &store.User{ID: 1, Address: "0x1234567890123456789012345678901234567890"}
+	ownerID := owner.ID
+	account := &store.User{ID: 7, AccountType: store.AccountTypeService, Name: "nightly-sync", OwnerID: &ownerID, CreatedAt: time.Now()}
+
+	userRepo.On("GetOrCreateUserByAddress", mock.Anything, owner.Address).Return(owner, nil)
+	saRepo.On("CreateServiceAccount", mock.Anything, "nightly-sync", owner.ID).Return(account, nil)
+
+	body, _ := json.Marshal(CreateServiceAccountRequest{Name: "nightly-sync"})
+	req := withClaims(httptest.NewRequest("POST", "/api/service-accounts", bytes.NewReader(body)), &auth.Claims{Address: owner.Address})
+	rr := httptest.NewRecorder()
+
+	handler.CreateServiceAccount(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var response ServiceAccountResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Equal(t, int64(7), response.ID)
+	assert.Equal(t, "nightly-sync", response.Name)
+	assert.Equal(t, owner.Address, response.Owner)
+
+	saRepo.AssertExpectations(t)
+	userRepo.AssertExpectations(t)
+}
+
+func TestCreateServiceAccount_Duplicate(t *testing.T) {
+	saRepo := new(MockServiceAccountRepository)
+	userRepo := new(MockUserRepository)
+	logger, _ := log.New("info")
+	handler := NewServiceAccountHandler(saRepo, new(MockAPIKeyRepository), userRepo, logger, nil)
+
+	owner := &store.User{ID: 1, Address: "0x1234567890123456789012345678901234567890"}
+	userRepo.On("GetOrCreateUserByAddress", mock.Anything, owner.Address).Return(owner, nil)
+	saRepo.On("CreateServiceAccount", mock.Anything, "dup", owner.ID).
+		Return(nil, &store.DuplicateError{Resource: "service_account", Field: "name", Value: "dup"})
+
+	body, _ := json.Marshal(CreateServiceAccountRequest{Name: "dup"})
+	req := withClaims(httptest.NewRequest("POST", "/api/service-accounts", bytes.NewReader(body)), &auth.Claims{Address: owner.Address})
+	rr := httptest.NewRecorder()
+
+	handler.CreateServiceAccount(rr, req)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+}
+
+func TestCreateServiceAccount_RequiresWalletUser(t *testing.T) {
+	handler := NewServiceAccountHandler(new(MockServiceAccountRepository), new(MockAPIKeyRepository), new(MockUserRepository), nil, nil)
+
+	body, _ := json.Marshal(CreateServiceAccountRequest{Name: "nested"})
+	claims := &auth.Claims{}
+	claims.Subject = "service_account:3"
+	req := withClaims(httptest.NewRequest("POST", "/api/service-accounts", bytes.NewReader(body)), claims)
+	rr := httptest.NewRecorder()
+
+	handler.CreateServiceAccount(rr, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestCreateServiceAccountKey_Success(t *testing.T) {
+	apiKeyRepo := new(MockAPIKeyRepository)
+	userRepo := new(MockUserRepository)
+	logger, _ := log.New("info")
+	handler := NewServiceAccountHandler(new(MockServiceAccountRepository), apiKeyRepo, userRepo, logger, nil)
+
+	owner := &store.User{ID: 1, Address: "0x1234567890123456789012345678901234567890"}
+	ownerID := owner.ID
+	account := &store.User{ID: 7, AccountType: store.AccountTypeService, Name: "nightly-sync", OwnerID: &ownerID}
+
+	userRepo.On("GetUserByAddress", mock.Anything, owner.Address).Return(owner, nil)
+	userRepo.On("GetUserByID", mock.Anything, int64(7)).Return(account, nil)
+	apiKeyRepo.On("CreateAPIKey", mock.Anything, mock.MatchedBy(func(req store.APIKeyCreateRequest) bool {
+		return req.UserID == account.ID && req.Name == "job key"
+	})).Return("rawkey", &store.APIKeyResponse{ID: 11, KeyHash: "abcdef0123456789", Name: "job key", Scopes: []string{"read"}}, nil)
+
+	body, _ := json.Marshal(CreateAPIKeyRequest{Name: "job key", Scopes: []string{"read"}})
+	req := httptest.NewRequest("POST", "/api/service-accounts/7/keys", bytes.NewReader(body))
+	req = mux.SetURLVars(req, map[string]string{"id": "7"})
+	req = withClaims(req, &auth.Claims{Address: owner.Address})
+	rr := httptest.NewRecorder()
+
+	handler.CreateServiceAccountKey(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var response CreateAPIKeyResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Equal(t, "rawkey", response.Key)
+
+	apiKeyRepo.AssertExpectations(t)
+	userRepo.AssertExpectations(t)
+}
+
+func TestCreateServiceAccountKey_NotOwner(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	logger, _ := log.New("info")
+	handler := NewServiceAccountHandler(new(MockServiceAccountRepository), new(MockAPIKeyRepository), userRepo, logger, nil)
+
+	owner := &store.User{ID: 1, Address: "0x1234567890123456789012345678901234567890"}
+	otherID := int64(2)
+	account := &store.User{ID: 7, AccountType: store.AccountTypeService, Name: "theirs", OwnerID: &otherID}
+
+	userRepo.On("GetUserByAddress", mock.Anything, owner.Address).Return(owner, nil)
+	userRepo.On("GetUserByID", mock.Anything, int64(7)).Return(account, nil)
+
+	body, _ := json.Marshal(CreateAPIKeyRequest{Name: "job key", Scopes: []string{"read"}})
+	req := httptest.NewRequest("POST", "/api/service-accounts/7/keys", bytes.NewReader(body))
+	req = mux.SetURLVars(req, map[string]string{"id": "7"})
+	req = withClaims(req, &auth.Claims{Address: owner.Address})
+	rr := httptest.NewRecorder()
+
+	handler.CreateServiceAccountKey(rr, req)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+}
+
+func TestAPIKeyMiddleware_ServiceAccountKey(t *testing.T) {
+	apiKeyRepo := new(MockAPIKeyRepository)
+	userRepo := new(MockUserRepository)
+	logger, _ := log.New("info")
+	middleware := NewAPIKeyMiddleware(apiKeyRepo, userRepo, logger, nil)
+
+	rawKey := "abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789"
+	ownerID := int64(1)
+	account := &store.User{ID: 7, AccountType: store.AccountTypeService, Name: "nightly-sync", OwnerID: &ownerID}
+
+	apiKeyRepo.On("ValidateAPIKey", mock.Anything, rawKey).Return(&store.APIKey{ID: 3, UserID: 7, KeyHash: "hash", Scopes: []string{"read"}}, nil)
+	apiKeyRepo.On("UpdateLastUsed", mock.Anything, "hash").Return(nil).Maybe()
+	userRepo.On("GetUserByID", mock.Anything, int64(7)).Return(account, nil)
+
+	var gotClaims *auth.Claims
+	handler := middleware.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotClaims = ClaimsFromContext(r)
+	}))
+
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.Header.Set("X-API-Key", rawKey)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.NotNil(t, gotClaims)
+	assert.Empty(t, gotClaims.Address)
+	assert.Equal(t, "service_account:7", gotClaims.Identity())
+}
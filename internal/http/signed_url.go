@@ -0,0 +1,230 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/yourusername/gatekeeper/internal/audit"
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/log"
+)
+
+// Lifetime limits for signed URLs
+const (
+	defaultSignedURLTTL = time.Hour
+	maxSignedURLTTL     = 7 * 24 * time.Hour
+)
+
+// signedURLContextKey holds the bound signed URL of a request until the caller is
+// authenticated
+const signedURLContextKey contextKey = "signed_url"
+
+// SignedURLHandler issues signed URLs for sharing gated resources
+type SignedURLHandler struct {
+	signer      *auth.URLSigner
+	paths       []string // Path prefixes that may be signed
+	logger      log.Logger
+	auditLogger audit.AuditLogger
+}
+
+// NewSignedURLHandler creates a new signed URL handler
+func NewSignedURLHandler(signer *auth.URLSigner, logger log.Logger, auditLogger audit.AuditLogger) *SignedURLHandler {
+	return &SignedURLHandler{
+		signer:      signer,
+		logger:      logger,
+		auditLogger: auditLogger,
+	}
+}
+
+// SetPaths sets the path prefixes that may be shared with signed URLs. Without
+// any, no path can be signed.
+func (h *SignedURLHandler) SetPaths(prefixes []string) {
+	h.paths = prefixes
+}
+
+// CreateSignedURLRequest represents the request to sign a URL
+type CreateSignedURLRequest struct {
+	Path         string `json:"path"`
+	TTLSeconds   int64  `json:"ttlSeconds,omitempty"`
+	BindToCaller bool   `json:"bindToCaller,omitempty"` // Only the caller's address may use the URL
+}
+
+// CreateSignedURLResponse represents a signed URL
+type CreateSignedURLResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Address   string    `json:"address,omitempty"`
+}
+
+// CreateSignedURL handles POST /api/signed-urls - Sign a path for temporary access
+func (h *SignedURLHandler) CreateSignedURL(w http.ResponseWriter, r *http.Request) {
+	claims := ClaimsFromContext(r)
+	if claims == nil {
+		h.writeError(w, "Unauthorized", "No authentication claims found", http.StatusUnauthorized)
+		return
+	}
+
+	var req CreateSignedURLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, "Invalid request body", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !strings.HasPrefix(req.Path, "/") || strings.ContainsAny(req.Path, "?#") || path.Clean(req.Path) != req.Path {
+		h.writeError(w, "Validation failed", "path must be a clean absolute path without query or fragment", http.StatusBadRequest)
+		return
+	}
+	if longestPathPrefix(req.Path, h.paths) == "" {
+		h.writeError(w, "Forbidden", "path cannot be shared with a signed URL", http.StatusForbidden)
+		return
+	}
+
+	ttl := defaultSignedURLTTL
+	if req.TTLSeconds != 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+	if ttl <= 0 || ttl > maxSignedURLTTL {
+		h.writeError(w, "Validation failed", "ttlSeconds must be between 1 and 604800", http.StatusBadRequest)
+		return
+	}
+
+	var address string
+	if req.BindToCaller {
+		address = claims.Address
+	}
+	// Unbound URLs need no credential, so they must die with a session that can be revoked
+	if address == "" && claims.ID == "" {
+		h.writeError(w, "Forbidden", "unbound signed URLs can only be created with a session token", http.StatusForbidden)
+		return
+	}
+
+	expiresAt := time.Now().Add(ttl).Truncate(time.Second)
+	// Revocations are only kept until the session expires, so the URL must not outlive it
+	if claims.ID != "" && claims.ExpiresAt != nil && expiresAt.After(claims.ExpiresAt.Time) {
+		expiresAt = claims.ExpiresAt.Time.Truncate(time.Second)
+	}
+	response := CreateSignedURLResponse{
+		URL:       h.signer.SignURL(req.Path, expiresAt, auth.SignedURL{Signer: claims.Identity(), Address: address, Session: claims.ID}),
+		ExpiresAt: expiresAt,
+		Address:   strings.ToLower(address),
+	}
+
+	requestLogger(r, h.logger).Info("signed URL created",
+		log.String("path", req.Path),
+		log.Time("expires_at", expiresAt),
+		log.Bool("bound", address != ""),
+	)
+
+	if h.auditLogger != nil {
+		h.auditLogger.Log(r.Context(), withClientInfo(r, audit.AuditEvent{
+			Action:   audit.ActionSignedURLCreated,
+			Result:   audit.ResultSuccess,
+			UserAddr: claims.Identity(),
+			Method:   r.Method,
+			Endpoint: r.URL.Path,
+			Metadata: map[string]interface{}{
+				"path":       req.Path,
+				"expires_at": expiresAt,
+				"bound":      address != "",
+			},
+		}))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeError writes a JSON error response
+func (h *SignedURLHandler) writeError(w http.ResponseWriter, error, details string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := ErrorResponse{
+		Error:   error,
+		Details: details,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// SignedURLMiddleware creates a middleware admitting GET and HEAD requests with a
+// valid signed URL for a path under one of the prefixes. It is placed first in the
+// authentication chain: requests without a signature pass through unchanged, and
+// unbound URLs authenticate the request as their signer, so the signer's access
+// policies still apply. URLs bound to an address leave authentication to the
+// credentials of the request, which RequireSignedURLBinding then checks. Like
+// capability tokens, signed URLs die with the session they were signed with.
+func SignedURLMiddleware(signer *auth.URLSigner, jwtService *auth.JWTService, prefixes []string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+			if !query.Has(auth.SignedURLSignatureParam) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if (r.Method != http.MethodGet && r.Method != http.MethodHead) || longestPathPrefix(r.URL.Path, prefixes) == "" {
+				http.Error(w, "path cannot be accessed with a signed URL", http.StatusForbidden)
+				return
+			}
+			signed, err := signer.Verify(r.URL.Path, query, time.Now())
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+			if signed.Address == "" && signed.Session == "" {
+				http.Error(w, "unbound signed URL has no session", http.StatusForbidden)
+				return
+			}
+
+			// The URL acts as a token derived from its session, so revoking the session revokes it
+			claims := &auth.Claims{ParentID: signed.Session}
+			revoked, err := jwtService.IsRevoked(r.Context(), claims)
+			if err != nil {
+				http.Error(w, "unable to check token revocation", http.StatusServiceUnavailable)
+				return
+			}
+			if revoked {
+				http.Error(w, "token has been revoked", http.StatusUnauthorized)
+				return
+			}
+
+			if signed.Address != "" {
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), signedURLContextKey, signed)))
+				return
+			}
+
+			if strings.HasPrefix(signed.Signer, "0x") {
+				claims.Address = signed.Signer
+			} else {
+				claims.Subject = signed.Signer
+			}
+			ctx := context.WithValue(r.Context(), ClaimsContextKey, claims)
+			ctx = context.WithValue(ctx, AuthMethodContextKey, AuthMethodSignedURL)
+			ctx = withAuthenticatedLogger(ctx, claims)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireSignedURLBinding creates a middleware refusing bound signed URLs unless
+// the request is authenticated as the bound address. It is placed last in the
+// authentication chain.
+func RequireSignedURLBinding() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if signed, ok := r.Context().Value(signedURLContextKey).(*auth.SignedURL); ok {
+				claims := ClaimsFromContext(r)
+				if claims == nil || !strings.EqualFold(claims.Address, signed.Address) {
+					http.Error(w, "signed URL is bound to a different address", http.StatusForbidden)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
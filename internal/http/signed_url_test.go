@@ -0,0 +1,245 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/log"
+)
+
+const signedURLTestAddress = "0x1234567890123456789012345678901234567890"
+
+// sessionClaims returns the claims of a session token for address
+func sessionClaims(address string) *auth.Claims {
+	claims := &auth.Claims{Address: address}
+	claims.ID = "session-jti"
+	return claims
+}
+
+func withTestClaims(r *http.Request, claims *auth.Claims) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), ClaimsContextKey, claims))
+}
+
+func createSignedURL(t *testing.T, signer *auth.URLSigner, claims *auth.Claims, body CreateSignedURLRequest) *httptest.ResponseRecorder {
+	t.Helper()
+	logger, err := log.New("info")
+	require.NoError(t, err)
+	handler := NewSignedURLHandler(signer, logger, nil)
+	handler.SetPaths([]string{"/api/downloads"})
+
+	payload, _ := json.Marshal(body)
+	req := httptest.NewRequest("POST", "/api/signed-urls", bytes.NewReader(payload))
+	rr := httptest.NewRecorder()
+	handler.CreateSignedURL(rr, withTestClaims(req, claims))
+	return rr
+}
+
+func TestCreateSignedURL(t *testing.T) {
+	signer := auth.NewURLSigner([]byte("secret"))
+
+	rr := createSignedURL(t, signer, sessionClaims(signedURLTestAddress), CreateSignedURLRequest{Path: "/api/downloads/report.pdf", TTLSeconds: 600})
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var response CreateSignedURLResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Contains(t, response.URL, "/api/downloads/report.pdf?")
+	assert.Empty(t, response.Address)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), response.ExpiresAt, 2*time.Second)
+	assert.Contains(t, response.URL, "session=session-jti")
+}
+
+// TestCreateSignedURL_WithoutSession verifies callers without a session, such as
+// API keys, can only create bound URLs, which their own credentials must accompany
+func TestCreateSignedURL_WithoutSession(t *testing.T) {
+	signer := auth.NewURLSigner([]byte("secret"))
+	keyClaims := &auth.Claims{Address: signedURLTestAddress}
+
+	rr := createSignedURL(t, signer, keyClaims, CreateSignedURLRequest{Path: "/api/downloads/report.pdf"})
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	rr = createSignedURL(t, signer, keyClaims, CreateSignedURLRequest{Path: "/api/downloads/report.pdf", BindToCaller: true})
+	assert.Equal(t, http.StatusCreated, rr.Code)
+}
+
+// TestCreateSignedURL_CappedAtSessionExpiry verifies a URL never outlives the
+// session it was signed with, whose revocation is only kept until then
+func TestCreateSignedURL_CappedAtSessionExpiry(t *testing.T) {
+	signer := auth.NewURLSigner([]byte("secret"))
+	claims := sessionClaims(signedURLTestAddress)
+	sessionExpiry := time.Now().Add(30 * time.Minute).Truncate(time.Second)
+	claims.ExpiresAt = jwt.NewNumericDate(sessionExpiry)
+
+	rr := createSignedURL(t, signer, claims, CreateSignedURLRequest{Path: "/api/downloads/report.pdf", TTLSeconds: 3600})
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var response CreateSignedURLResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.True(t, response.ExpiresAt.Equal(sessionExpiry), response.ExpiresAt)
+}
+
+func TestCreateSignedURL_Validation(t *testing.T) {
+	signer := auth.NewURLSigner([]byte("secret"))
+
+	tests := []struct {
+		name           string
+		body           CreateSignedURLRequest
+		expectedStatus int
+	}{
+		{name: "relative path", body: CreateSignedURLRequest{Path: "api/downloads/report.pdf"}, expectedStatus: http.StatusBadRequest},
+		{name: "path with query", body: CreateSignedURLRequest{Path: "/api/downloads?file=report.pdf"}, expectedStatus: http.StatusBadRequest},
+		{name: "path with dot segments", body: CreateSignedURLRequest{Path: "/api/downloads/../admin/history"}, expectedStatus: http.StatusBadRequest},
+		{name: "negative ttl", body: CreateSignedURLRequest{Path: "/api/downloads/report.pdf", TTLSeconds: -1}, expectedStatus: http.StatusBadRequest},
+		{name: "ttl too long", body: CreateSignedURLRequest{Path: "/api/downloads/report.pdf", TTLSeconds: 8 * 24 * 3600}, expectedStatus: http.StatusBadRequest},
+		{name: "admin path", body: CreateSignedURLRequest{Path: "/api/admin/history"}, expectedStatus: http.StatusForbidden},
+		{name: "prefix without segment boundary", body: CreateSignedURLRequest{Path: "/api/downloadsecret"}, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := createSignedURL(t, signer, sessionClaims(signedURLTestAddress), tt.body)
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+// newSignedURLRouter returns a router whose /api routes are authenticated by the
+// chain the server uses, serving downloads, an admin route and signed URL issuance
+func newSignedURLRouter(t *testing.T, signer *auth.URLSigner, jwtService *auth.JWTService) *mux.Router {
+	t.Helper()
+	logger, err := log.New("info")
+	require.NoError(t, err)
+	signedURLHandler := NewSignedURLHandler(signer, logger, nil)
+	paths := []string{"/api/downloads"}
+	signedURLHandler.SetPaths(paths)
+
+	router := mux.NewRouter()
+	apiRouter := router.PathPrefix("/api").Subrouter()
+	apiRouter.Use(mux.MiddlewareFunc(SignedURLMiddleware(signer, jwtService, paths)))
+	apiRouter.Use(mux.MiddlewareFunc(CapabilityMiddleware(jwtService)))
+	apiRouter.Use(mux.MiddlewareFunc(JWTMiddleware(jwtService)))
+	apiRouter.Use(mux.MiddlewareFunc(RequireSignedURLBinding()))
+
+	caller := func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"identity": ClaimsFromContext(r).Identity(),
+			"method":   AuthMethodFromContext(r),
+		})
+	}
+	apiRouter.HandleFunc("/downloads/{file}", caller).Methods("GET", "POST")
+	apiRouter.HandleFunc("/admin/history", caller).Methods("GET")
+	apiRouter.HandleFunc("/signed-urls", signedURLHandler.CreateSignedURL).Methods("POST")
+	return router
+}
+
+// TestSignedURLs_EndToEnd issues a signed URL through the API and uses it on the
+// router, which only honors signatures on the configured paths
+func TestSignedURLs_EndToEnd(t *testing.T) {
+	signer := auth.NewURLSigner([]byte("secret"))
+	jwtService := auth.NewJWTService([]byte("test-secret-key-at-least-32-chars"), 24*time.Hour)
+	router := newSignedURLRouter(t, signer, jwtService)
+	session, err := jwtService.GenerateToken(context.Background(), signedURLTestAddress, nil)
+	require.NoError(t, err)
+	other, err := jwtService.GenerateToken(context.Background(), "0x1111111111111111111111111111111111111111", nil)
+	require.NoError(t, err)
+
+	serve := func(method, url, token string, body interface{}) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, url, bytes.NewReader(payload))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	issue := func(body CreateSignedURLRequest) string {
+		rr := serve("POST", "/api/signed-urls", session, body)
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+		var response CreateSignedURLResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		return response.URL
+	}
+
+	unbound := issue(CreateSignedURLRequest{Path: "/api/downloads/report.pdf"})
+	bound := issue(CreateSignedURLRequest{Path: "/api/downloads/report.pdf", BindToCaller: true})
+
+	// Unbound URLs need no credential and act as their signer
+	rr := serve("GET", unbound, "", nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var caller map[string]string
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&caller))
+	assert.Equal(t, map[string]string{"identity": signedURLTestAddress, "method": AuthMethodSignedURL}, caller)
+
+	// Admin paths cannot be signed, nor used with a signature made for them
+	assert.Equal(t, http.StatusForbidden, serve("POST", "/api/signed-urls", session, CreateSignedURLRequest{Path: "/api/admin/history"}).Code)
+	adminURL := signer.SignURL("/api/admin/history", time.Now().Add(time.Hour), auth.SignedURL{Signer: signedURLTestAddress, Session: "session-jti"})
+	assert.Equal(t, http.StatusForbidden, serve("GET", adminURL, "", nil).Code)
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		token          string
+		expectedStatus int
+	}{
+		{name: "unsigned without credential", method: "GET", url: "/api/downloads/report.pdf", expectedStatus: http.StatusUnauthorized},
+		{name: "signature for another path", method: "GET", url: strings.Replace(unbound, "report.pdf", "other.pdf", 1), expectedStatus: http.StatusForbidden},
+		{name: "unsafe method", method: "POST", url: unbound, expectedStatus: http.StatusForbidden},
+		{name: "bound without credential", method: "GET", url: bound, expectedStatus: http.StatusUnauthorized},
+		{name: "bound to another address", method: "GET", url: bound, token: other, expectedStatus: http.StatusForbidden},
+		{name: "bound to caller", method: "GET", url: bound, token: session, expectedStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedStatus, serve(tt.method, tt.url, tt.token, nil).Code)
+		})
+	}
+}
+
+// TestSignedURLs_RevokedWithSession verifies signed URLs stop working once the
+// session they were signed with is revoked
+func TestSignedURLs_RevokedWithSession(t *testing.T) {
+	signer := auth.NewURLSigner([]byte("secret"))
+	jwtService := auth.NewJWTService([]byte("test-secret-key-at-least-32-chars"), 24*time.Hour)
+	jwtService.SetRevocationList(auth.NewMemoryRevocationList())
+	router := newSignedURLRouter(t, signer, jwtService)
+	session, err := jwtService.GenerateToken(context.Background(), signedURLTestAddress, nil)
+	require.NoError(t, err)
+	claims, err := jwtService.VerifyToken(context.Background(), session)
+	require.NoError(t, err)
+
+	serve := func(method, url, token string, body interface{}) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, url, bytes.NewReader(payload))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	rr := serve("POST", "/api/signed-urls", session, CreateSignedURLRequest{Path: "/api/downloads/report.pdf"})
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var response CreateSignedURLResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	require.Equal(t, http.StatusOK, serve("GET", response.URL, "", nil).Code)
+
+	require.NoError(t, jwtService.RevokeToken(context.Background(), claims))
+
+	assert.Equal(t, http.StatusUnauthorized, serve("GET", response.URL, "", nil).Code)
+
+	// A URL signed without a session is never honored unbound
+	sessionless := signer.SignURL("/api/downloads/report.pdf", time.Now().Add(time.Hour), auth.SignedURL{Signer: signedURLTestAddress})
+	assert.Equal(t, http.StatusForbidden, serve("GET", sessionless, "", nil).Code)
+}
@@ -0,0 +1,138 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	traceContextKey contextKey = "trace"
+
+	// traceparentHeader carries W3C trace context (https://www.w3.org/TR/trace-context/)
+	traceparentHeader = "traceparent"
+)
+
+// traceContext identifies the trace a request belongs to and its span in gatekeeper
+type traceContext struct {
+	TraceID string // 32 lowercase hex characters
+	SpanID  string // 16 lowercase hex characters
+	Sampled bool
+}
+
+// TraceMiddleware joins the trace of an incoming W3C traceparent header, or
+// starts a new one, and answers with the traceparent of gatekeeper's span.
+// Latency metrics observed during the request carry the trace ID as an exemplar.
+func TraceMiddleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			trace, ok := parseTraceparent(r.Header.Get(traceparentHeader))
+			if !ok {
+				trace = traceContext{TraceID: randomHex(16), Sampled: true}
+			}
+			trace.SpanID = randomHex(8)
+
+			w.Header().Set(traceparentHeader, trace.header())
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), traceContextKey, trace)))
+		})
+	}
+}
+
+// TraceIDFromContext returns the trace ID of the request, or "" without tracing
+func TraceIDFromContext(ctx context.Context) string {
+	if trace, ok := ctx.Value(traceContextKey).(traceContext); ok {
+		return trace.TraceID
+	}
+	return ""
+}
+
+// header formats the trace context as a version 00 traceparent value
+func (t traceContext) header() string {
+	flags := "00"
+	if t.Sampled {
+		flags = "01"
+	}
+	return "00-" + t.TraceID + "-" + t.SpanID + "-" + flags
+}
+
+// parseTraceparent parses a traceparent header, rejecting malformed values and
+// the all-zero IDs the specification declares invalid
+func parseTraceparent(value string) (traceContext, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return traceContext{}, false
+	}
+	// Version 00 has exactly four fields; later versions may append more
+	if parts[0] == "00" && len(parts) != 4 {
+		return traceContext{}, false
+	}
+
+	traceID, parentID, flags := parts[1], parts[2], parts[3]
+	if !isLowerHex(parts[0], 2) || !isLowerHex(traceID, 32) || !isLowerHex(parentID, 16) || !isLowerHex(flags, 2) {
+		return traceContext{}, false
+	}
+	if traceID == strings.Repeat("0", 32) || parentID == strings.Repeat("0", 16) {
+		return traceContext{}, false
+	}
+
+	flagBits, _ := hex.DecodeString(flags)
+	return traceContext{TraceID: traceID, Sampled: flagBits[0]&0x01 == 1}, true
+}
+
+// isLowerHex reports whether s is exactly n lowercase hex characters
+func isLowerHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// randomHex returns n random bytes as lowercase hex
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// TimeAuth runs the authentication middlewares as one chain and records how long
+// they take until the request is either passed on or rejected. The time spent in
+// the wrapped handler is not included. The duration is also the auth phase of the
+// Server-Timing breakdown.
+func TimeAuth(collector *MetricsCollector, middlewares ...Middleware) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			beginDebugPhase(r, TimingAuth)
+			observed := false
+			observe := func(r *http.Request, passed bool) {
+				if !observed {
+					observed = true
+					collector.ObserveAuth(passed, time.Since(start), TraceIDFromContext(r.Context()))
+					endDebugPhase(r, TimingAuth)
+					if passed {
+						recordDebugCaller(r)
+					}
+				}
+			}
+
+			var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				observe(r, true)
+				next.ServeHTTP(w, r)
+			})
+			for i := len(middlewares) - 1; i >= 0; i-- {
+				handler = middlewares[i](handler)
+			}
+
+			handler.ServeHTTP(w, r)
+			observe(r, false)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		valid   bool
+		sampled bool
+	}{
+		{"sampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true, true},
+		{"not sampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", true, false},
+		{"future version with extra fields", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", true, true},
+		{"empty", "", false, false},
+		{"version 00 with extra fields", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false, false},
+		{"invalid version", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, false},
+		{"uppercase", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", false, false},
+		{"short trace ID", "00-4bf92f3577b34da6-00f067aa0ba902b7-01", false, false},
+		{"zero trace ID", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", false, false},
+		{"zero parent ID", "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trace, ok := parseTraceparent(tt.header)
+			assert.Equal(t, tt.valid, ok)
+			if tt.valid {
+				assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", trace.TraceID)
+				assert.Equal(t, tt.sampled, trace.Sampled)
+			}
+		})
+	}
+}
+
+func TestTraceMiddleware(t *testing.T) {
+	var traceID string
+	handler := TraceMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceID = TraceIDFromContext(r.Context())
+	}))
+
+	t.Run("joins the incoming trace", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/data", nil)
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+		parts := strings.Split(w.Header().Get("traceparent"), "-")
+		require.Len(t, parts, 4)
+		assert.Equal(t, traceID, parts[1])
+		assert.NotEqual(t, "00f067aa0ba902b7", parts[2], "gatekeeper answers with its own span")
+		assert.Equal(t, "01", parts[3])
+	})
+
+	t.Run("starts a trace without a valid header", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/data", nil)
+		req.Header.Set("traceparent", "garbage")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		assert.Len(t, traceID, 32)
+		trace, ok := parseTraceparent(w.Header().Get("traceparent"))
+		require.True(t, ok)
+		assert.Equal(t, traceID, trace.TraceID)
+	})
+
+	assert.Empty(t, TraceIDFromContext(httptest.NewRequest("GET", "/", nil).Context()))
+}
+
+func TestTimeAuth(t *testing.T) {
+	collector := NewMetricsCollector(nil)
+	reject := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				http.Error(w, "missing token", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	passThrough := func(next http.Handler) http.Handler { return next }
+
+	reached := 0
+	handler := TraceMiddleware()(TimeAuth(collector, passThrough, reject)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached++
+	})))
+
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/data", nil))
+
+	assert.Equal(t, 1, reached)
+	require.Contains(t, collector.authLatency, "passed")
+	require.Contains(t, collector.authLatency, "rejected")
+	assert.Equal(t, int64(1), collector.authLatency["passed"].count)
+	assert.Equal(t, int64(1), collector.authLatency["rejected"].count)
+	assert.NotNil(t, collector.authLatency["passed"].exemplars[0], "fast observations land in the first bucket")
+}
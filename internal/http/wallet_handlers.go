@@ -0,0 +1,290 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/gatekeeper/internal/audit"
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/log"
+	"github.com/yourusername/gatekeeper/internal/notify"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// WalletHandler handles the wallets linked to the caller's account. A linked
+// wallet signs in as the account and counts towards its address-based policies.
+type WalletHandler struct {
+	siweService *auth.SIWEService
+	walletRepo  store.WalletRepositoryInterface
+	userRepo    store.UserRepositoryInterface
+	logger      log.Logger
+	auditLogger audit.AuditLogger
+	notifiers   []notify.Notifier
+}
+
+// walletNotifyTimeout bounds the delivery of a wallet link notification
+const walletNotifyTimeout = 30 * time.Second
+
+// NewWalletHandler creates a new wallet handler
+func NewWalletHandler(siweService *auth.SIWEService, walletRepo store.WalletRepositoryInterface, userRepo store.UserRepositoryInterface, logger log.Logger, auditLogger audit.AuditLogger) *WalletHandler {
+	return &WalletHandler{
+		siweService: siweService,
+		walletRepo:  walletRepo,
+		userRepo:    userRepo,
+		logger:      logger,
+		auditLogger: auditLogger,
+	}
+}
+
+// SetNotifiers sets the notifiers that tell account owners about linked wallets
+func (h *WalletHandler) SetNotifiers(notifiers []notify.Notifier) {
+	h.notifiers = notifiers
+}
+
+// LinkWalletRequest represents the request to link a wallet. The SIWE message
+// must be signed by the new wallet and mention the account's address, so a
+// sign-in message of that wallet cannot be used to link it.
+type LinkWalletRequest struct {
+	Message   string `json:"message"`
+	Signature string `json:"signature"`
+}
+
+// WalletResponse represents a linked wallet in API responses
+type WalletResponse struct {
+	Address  string    `json:"address"`
+	LinkedAt time.Time `json:"linkedAt"`
+}
+
+// ListWalletsResponse represents the wallets of the caller's account
+type ListWalletsResponse struct {
+	Address string           `json:"address"` // The account's own address
+	Wallets []WalletResponse `json:"wallets"`
+}
+
+// ListWallets handles GET /api/me/wallets - Wallets linked to the caller's account
+func (h *WalletHandler) ListWallets(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	wallets, err := h.walletRepo.ListWallets(r.Context(), user.ID)
+	if err != nil {
+		requestLogger(r, h.logger).Error("failed to list wallets", log.Int64("user_id", user.ID), log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to retrieve wallets", http.StatusInternalServerError)
+		return
+	}
+
+	response := ListWalletsResponse{Address: user.Address, Wallets: make([]WalletResponse, len(wallets))}
+	for i, wallet := range wallets {
+		response.Wallets[i] = WalletResponse{Address: wallet.Address, LinkedAt: wallet.CreatedAt}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// LinkWallet handles POST /api/me/wallets/link - Links the wallet that signed
+// the SIWE message to the caller's account. A linked wallet signs in as the
+// account, so only a full sign-in session may link one: API keys, capability and
+// downscoped tokens are refused.
+func (h *WalletHandler) LinkWallet(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := requestLogger(r, h.logger)
+
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+	if AuthMethodFromContext(r) != AuthMethodJWT || ClaimsFromContext(r).Derived() {
+		h.auditLinkFailure(r, user, "", "wallet link requires a session")
+		h.writeError(w, "Forbidden", "Linking a wallet requires a sign-in session", http.StatusForbidden)
+		return
+	}
+
+	var req LinkWalletRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, "Invalid request body", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	address, proofErr := verifySIWEProof(ctx, h.siweService, req.Message, req.Signature)
+	if proofErr != nil {
+		h.auditLinkFailure(r, user, "", proofErr.message)
+		h.writeError(w, "Invalid wallet proof", proofErr.message, proofErr.status)
+		return
+	}
+	if address == user.Address {
+		h.writeError(w, "Validation failed", "The account's own address cannot be linked", http.StatusBadRequest)
+		return
+	}
+	if !strings.Contains(strings.ToLower(req.Message), user.Address) {
+		h.auditLinkFailure(r, user, address, "message does not mention the account")
+		h.writeError(w, "Invalid wallet proof", "The message must mention the address of the account being linked to", http.StatusBadRequest)
+		return
+	}
+
+	wallet, err := h.walletRepo.LinkWallet(ctx, user.ID, address)
+	if err != nil {
+		if errors.Is(err, store.ErrDuplicate) {
+			h.auditLinkFailure(r, user, address, "wallet belongs to another account")
+			h.writeError(w, "Conflict", "This wallet already belongs to an account", http.StatusConflict)
+			return
+		}
+		logger.Error("failed to link wallet", log.Int64("user_id", user.ID), log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to link wallet", http.StatusInternalServerError)
+		return
+	}
+
+	if h.auditLogger != nil {
+		h.auditLogger.Log(ctx, withClientInfo(r, audit.AuditEvent{
+			Action:     audit.ActionWalletLinked,
+			Result:     audit.ResultSuccess,
+			UserAddr:   user.Address,
+			UserID:     user.ID,
+			ResourceID: wallet.Address,
+			Method:     r.Method,
+			Endpoint:   r.URL.Path,
+		}))
+	}
+
+	logger.Info("wallet linked", log.Int64("user_id", user.ID), log.String("wallet", wallet.Address))
+	h.notifyLinked(r, user, wallet)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(WalletResponse{Address: wallet.Address, LinkedAt: wallet.CreatedAt})
+}
+
+// auditLinkFailure records a refused attempt to link a wallet to the account
+func (h *WalletHandler) auditLinkFailure(r *http.Request, user *store.User, wallet, reason string) {
+	if h.auditLogger == nil {
+		return
+	}
+	h.auditLogger.Log(r.Context(), withClientInfo(r, audit.AuditEvent{
+		Action:     audit.ActionWalletLinked,
+		Result:     audit.ResultFailure,
+		UserAddr:   user.Address,
+		UserID:     user.ID,
+		ResourceID: wallet,
+		Method:     r.Method,
+		Endpoint:   r.URL.Path,
+		Error:      reason,
+	}))
+}
+
+// notifyLinked tells the account's owner a wallet was linked, so a link they did
+// not make is noticed. Delivery does not hold up the response.
+func (h *WalletHandler) notifyLinked(r *http.Request, user *store.User, wallet *store.Wallet) {
+	if len(h.notifiers) == 0 {
+		return
+	}
+	logger := requestLogger(r, h.logger)
+	notification := notify.Notification{
+		Event:     notify.EventWalletLinked,
+		Recipient: user.Address,
+		Subject:   "A wallet was linked to your account",
+		Message: fmt.Sprintf("The wallet %s was linked to your account %s at %s and can now sign in as it. If you did not link it, unlink it and revoke your sessions.",
+			wallet.Address, user.Address, wallet.CreatedAt.UTC().Format(time.RFC3339)),
+		Data: map[string]interface{}{
+			"wallet":   wallet.Address,
+			"linkedAt": wallet.CreatedAt.UTC(),
+		},
+		CreatedAt: time.Now(),
+	}
+
+	ctx := context.WithoutCancel(r.Context())
+	go func() {
+		ctx, cancel := context.WithTimeout(ctx, walletNotifyTimeout)
+		defer cancel()
+		for _, notifier := range h.notifiers {
+			if err := notifier.Notify(ctx, notification); err != nil {
+				logger.Warn("failed to deliver wallet link notification",
+					log.Int64("user_id", user.ID),
+					log.String("wallet", wallet.Address),
+					log.Error(err))
+			}
+		}
+	}()
+}
+
+// UnlinkWallet handles DELETE /api/me/wallets/{address}
+func (h *WalletHandler) UnlinkWallet(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := requestLogger(r, h.logger)
+
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	address := strings.ToLower(mux.Vars(r)["address"])
+	if err := h.walletRepo.UnlinkWallet(ctx, user.ID, address); err != nil {
+		switch {
+		case errors.Is(err, store.ErrInvalidAddress):
+			h.writeError(w, "Invalid address", err.Error(), http.StatusBadRequest)
+		case errors.Is(err, store.ErrNotFound):
+			h.writeError(w, "Not found", "Wallet is not linked to this account", http.StatusNotFound)
+		default:
+			logger.Error("failed to unlink wallet", log.Int64("user_id", user.ID), log.Error(err))
+			h.writeError(w, "Internal server error", "Failed to unlink wallet", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if h.auditLogger != nil {
+		h.auditLogger.Log(ctx, withClientInfo(r, audit.AuditEvent{
+			Action:     audit.ActionWalletUnlinked,
+			Result:     audit.ResultSuccess,
+			UserAddr:   user.Address,
+			UserID:     user.ID,
+			ResourceID: address,
+			Method:     r.Method,
+			Endpoint:   r.URL.Path,
+		}))
+	}
+
+	logger.Info("wallet unlinked", log.Int64("user_id", user.ID), log.String("wallet", address))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// currentUser loads the wallet user making the request, writing the error
+// response when there is none
+func (h *WalletHandler) currentUser(w http.ResponseWriter, r *http.Request) (*store.User, bool) {
+	claims := ClaimsFromContext(r)
+	if claims == nil {
+		h.writeError(w, "Unauthorized", "No authentication claims found", http.StatusUnauthorized)
+		return nil, false
+	}
+	if claims.Address == "" {
+		h.writeError(w, "Forbidden", "Wallets can only be linked to wallet accounts", http.StatusForbidden)
+		return nil, false
+	}
+
+	user, err := h.userRepo.GetOrCreateUserByAddress(r.Context(), claims.Address)
+	if err != nil {
+		requestLogger(r, h.logger).Error("failed to get or create user", log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to retrieve user", http.StatusInternalServerError)
+		return nil, false
+	}
+	return user, true
+}
+
+// writeError writes an error response
+func (h *WalletHandler) writeError(w http.ResponseWriter, error, details string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := ErrorResponse{
+		Error:   error,
+		Details: details,
+	}
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,319 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/audit"
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/notify"
+	"github.com/yourusername/gatekeeper/internal/store"
+	"go.uber.org/zap"
+)
+
+// MockWalletRepository is a mock implementation of WalletRepository
+type MockWalletRepository struct {
+	mock.Mock
+}
+
+func (m *MockWalletRepository) LinkWallet(ctx context.Context, userID int64, address string) (*store.Wallet, error) {
+	args := m.Called(ctx, userID, address)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.Wallet), args.Error(1)
+}
+
+func (m *MockWalletRepository) UnlinkWallet(ctx context.Context, userID int64, address string) error {
+	args := m.Called(ctx, userID, address)
+	return args.Error(0)
+}
+
+func (m *MockWalletRepository) ListWallets(ctx context.Context, userID int64) ([]store.Wallet, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]store.Wallet), args.Error(1)
+}
+
+func (m *MockWalletRepository) GetWalletOwner(ctx context.Context, address string) (int64, error) {
+	args := m.Called(ctx, address)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWalletRepository) ListLinkedAddresses(ctx context.Context, address string) ([]string, error) {
+	args := m.Called(ctx, address)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+const walletAccount = "0x742d35cc6634c0532925a3b844bc9e7595f0beb0"
+
+// signWalletLink returns a SIWE message with the given statement for a fresh
+// nonce, signed by a new key, and the key's address
+func signWalletLink(t *testing.T, siweService *auth.SIWEService, statement string) (message, signature, address string) {
+	t.Helper()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	address = strings.ToLower(crypto.PubkeyToAddress(key.PublicKey).Hex())
+
+	nonce, err := siweService.GenerateNonce(context.Background())
+	require.NoError(t, err)
+	message = "example.com wants you to sign in with your Ethereum account:\n" + address + "\n\n" + statement +
+		"\n\nURI: https://example.com\nVersion: 1\nChain ID: 1\nNonce: " + nonce
+
+	hash := crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)))
+	sig, err := crypto.Sign(hash, key)
+	require.NoError(t, err)
+	sig[64] += 27
+	return message, "0x" + hex.EncodeToString(sig), address
+}
+
+func newWalletRequest(method, path string, body interface{}, claims *auth.Claims) *http.Request {
+	var reader *bytes.Reader
+	if body != nil {
+		encoded, _ := json.Marshal(body)
+		reader = bytes.NewReader(encoded)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if claims == nil {
+		return req
+	}
+	ctx := context.WithValue(req.Context(), ClaimsContextKey, claims)
+	return req.WithContext(context.WithValue(ctx, AuthMethodContextKey, AuthMethodJWT))
+}
+
+// notifierFunc delivers notifications with a function
+type notifierFunc func(ctx context.Context, n notify.Notification) error
+
+func (f notifierFunc) Notify(ctx context.Context, n notify.Notification) error {
+	return f(ctx, n)
+}
+
+func newWalletHandler() (*WalletHandler, *auth.SIWEService, *MockWalletRepository) {
+	siweService := auth.NewSIWEService(5 * time.Minute)
+	walletRepo := new(MockWalletRepository)
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetOrCreateUserByAddress", mock.Anything, walletAccount).Return(&store.User{ID: 42, Address: walletAccount}, nil)
+	return NewWalletHandler(siweService, walletRepo, userRepo, nil, nil), siweService, walletRepo
+}
+
+func TestLinkWallet_LinksSigningWallet(t *testing.T) {
+	handler, siweService, walletRepo := newWalletHandler()
+	message, signature, address := signWalletLink(t, siweService, "Link this wallet to "+walletAccount)
+	linkedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	walletRepo.On("LinkWallet", mock.Anything, int64(42), address).Return(&store.Wallet{ID: 1, UserID: 42, Address: address, CreatedAt: linkedAt}, nil)
+
+	rec := httptest.NewRecorder()
+	handler.LinkWallet(rec, newWalletRequest("POST", "/api/me/wallets/link", LinkWalletRequest{Message: message, Signature: signature}, &auth.Claims{Address: walletAccount}))
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var response WalletResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, address, response.Address)
+	assert.Equal(t, linkedAt, response.LinkedAt)
+	walletRepo.AssertExpectations(t)
+
+	// The proof is single use
+	rec = httptest.NewRecorder()
+	handler.LinkWallet(rec, newWalletRequest("POST", "/api/me/wallets/link", LinkWalletRequest{Message: message, Signature: signature}, &auth.Claims{Address: walletAccount}))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestLinkWallet_RejectsInvalidProofs(t *testing.T) {
+	t.Run("message does not name the account", func(t *testing.T) {
+		handler, siweService, walletRepo := newWalletHandler()
+		message, signature, _ := signWalletLink(t, siweService, "Sign in to example.com")
+
+		rec := httptest.NewRecorder()
+		handler.LinkWallet(rec, newWalletRequest("POST", "/api/me/wallets/link", LinkWalletRequest{Message: message, Signature: signature}, &auth.Claims{Address: walletAccount}))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		walletRepo.AssertNotCalled(t, "LinkWallet", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("signature from another key", func(t *testing.T) {
+		handler, siweService, walletRepo := newWalletHandler()
+		message, _, _ := signWalletLink(t, siweService, "Link this wallet to "+walletAccount)
+		_, otherSignature, _ := signWalletLink(t, siweService, "Link this wallet to "+walletAccount)
+
+		rec := httptest.NewRecorder()
+		handler.LinkWallet(rec, newWalletRequest("POST", "/api/me/wallets/link", LinkWalletRequest{Message: message, Signature: otherSignature}, &auth.Claims{Address: walletAccount}))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		walletRepo.AssertNotCalled(t, "LinkWallet", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("service accounts have no wallets", func(t *testing.T) {
+		handler, _, _ := newWalletHandler()
+
+		rec := httptest.NewRecorder()
+		handler.LinkWallet(rec, newWalletRequest("POST", "/api/me/wallets/link", LinkWalletRequest{}, &auth.Claims{Scopes: []string{"api"}}))
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
+
+// TestLinkWallet_RequiresSession refuses API keys, capability and downscoped tokens
+func TestLinkWallet_RequiresSession(t *testing.T) {
+	for name, withCredential := range map[string]func(*http.Request) *http.Request{
+		"API key": func(req *http.Request) *http.Request {
+			return req.WithContext(context.WithValue(req.Context(), AuthMethodContextKey, AuthMethodAPIKey))
+		},
+		"capability token": func(req *http.Request) *http.Request {
+			return req.WithContext(context.WithValue(req.Context(), AuthMethodContextKey, AuthMethodCapability))
+		},
+		"downscoped token": func(req *http.Request) *http.Request {
+			return req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, &auth.Claims{Address: walletAccount, ParentID: "session-jti"}))
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			handler, siweService, walletRepo := newWalletHandler()
+			sink := &recordingSink{}
+			handler.auditLogger = audit.NewAuditLoggerWithSinks(zap.NewNop(), sink)
+			message, signature, _ := signWalletLink(t, siweService, "Link this wallet to "+walletAccount)
+
+			rec := httptest.NewRecorder()
+			handler.LinkWallet(rec, withCredential(newWalletRequest("POST", "/api/me/wallets/link", LinkWalletRequest{Message: message, Signature: signature}, &auth.Claims{Address: walletAccount})))
+
+			assert.Equal(t, http.StatusForbidden, rec.Code)
+			walletRepo.AssertNotCalled(t, "LinkWallet", mock.Anything, mock.Anything, mock.Anything)
+			require.Len(t, sink.events, 1)
+			assert.Equal(t, audit.ActionWalletLinked, sink.events[0].Action)
+			assert.Equal(t, audit.ResultFailure, sink.events[0].Result)
+		})
+	}
+}
+
+// TestLinkWallet_NotifiesOwner tells the account's owner about the new wallet
+func TestLinkWallet_NotifiesOwner(t *testing.T) {
+	handler, siweService, walletRepo := newWalletHandler()
+	sink := &recordingSink{}
+	handler.auditLogger = audit.NewAuditLoggerWithSinks(zap.NewNop(), sink)
+	notifications := make(chan notify.Notification, 1)
+	handler.SetNotifiers([]notify.Notifier{notifierFunc(func(ctx context.Context, n notify.Notification) error {
+		notifications <- n
+		return nil
+	})})
+	message, signature, address := signWalletLink(t, siweService, "Link this wallet to "+walletAccount)
+	walletRepo.On("LinkWallet", mock.Anything, int64(42), address).Return(&store.Wallet{ID: 1, UserID: 42, Address: address, CreatedAt: time.Now()}, nil)
+
+	rec := httptest.NewRecorder()
+	handler.LinkWallet(rec, newWalletRequest("POST", "/api/me/wallets/link", LinkWalletRequest{Message: message, Signature: signature}, &auth.Claims{Address: walletAccount}))
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	select {
+	case n := <-notifications:
+		assert.Equal(t, notify.EventWalletLinked, n.Event)
+		assert.Equal(t, walletAccount, n.Recipient)
+		assert.Equal(t, address, n.Data["wallet"])
+		assert.Contains(t, n.Message, address)
+	case <-time.After(time.Second):
+		t.Fatal("owner was not notified of the linked wallet")
+	}
+	require.Len(t, sink.events, 1)
+	assert.Equal(t, audit.ResultSuccess, sink.events[0].Result)
+	assert.Equal(t, address, sink.events[0].ResourceID)
+}
+
+func TestLinkWallet_WalletOfAnotherAccount(t *testing.T) {
+	handler, siweService, walletRepo := newWalletHandler()
+	sink := &recordingSink{}
+	handler.auditLogger = audit.NewAuditLoggerWithSinks(zap.NewNop(), sink)
+	message, signature, address := signWalletLink(t, siweService, "Link this wallet to "+walletAccount)
+	walletRepo.On("LinkWallet", mock.Anything, int64(42), address).Return(nil, &store.DuplicateError{Resource: "wallet", Field: "address", Value: address})
+
+	rec := httptest.NewRecorder()
+	handler.LinkWallet(rec, newWalletRequest("POST", "/api/me/wallets/link", LinkWalletRequest{Message: message, Signature: signature}, &auth.Claims{Address: walletAccount}))
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	require.Len(t, sink.events, 1)
+	assert.Equal(t, audit.ResultFailure, sink.events[0].Result)
+	assert.Equal(t, address, sink.events[0].ResourceID)
+}
+
+func TestUnlinkWallet(t *testing.T) {
+	linked := "0x742d35cc6634c0532925a3b844bc9e7595f0beb1"
+	handler, _, walletRepo := newWalletHandler()
+	walletRepo.On("UnlinkWallet", mock.Anything, int64(42), linked).Return(nil).Once()
+	walletRepo.On("UnlinkWallet", mock.Anything, int64(42), linked).Return(&store.NotFoundError{Resource: "wallet", ID: linked})
+
+	unlink := func() int {
+		req := newWalletRequest("DELETE", "/api/me/wallets/"+strings.ToUpper(linked[2:]), nil, &auth.Claims{Address: walletAccount})
+		req = mux.SetURLVars(req, map[string]string{"address": "0x" + strings.ToUpper(linked[2:])})
+		rec := httptest.NewRecorder()
+		handler.UnlinkWallet(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusNoContent, unlink())
+	assert.Equal(t, http.StatusNotFound, unlink())
+}
+
+func TestListWallets(t *testing.T) {
+	handler, _, walletRepo := newWalletHandler()
+	walletRepo.On("ListWallets", mock.Anything, int64(42)).Return([]store.Wallet{
+		{ID: 1, UserID: 42, Address: "0x742d35cc6634c0532925a3b844bc9e7595f0beb1"},
+	}, nil)
+
+	rec := httptest.NewRecorder()
+	handler.ListWallets(rec, newWalletRequest("GET", "/api/me/wallets", nil, &auth.Claims{Address: walletAccount}))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response ListWalletsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, walletAccount, response.Address)
+	require.Len(t, response.Wallets, 1)
+	assert.Equal(t, "0x742d35cc6634c0532925a3b844bc9e7595f0beb1", response.Wallets[0].Address)
+}
+
+// TestVerifySIWE_LinkedWalletSignsInAsOwner issues the token for the account the wallet is linked to
+func TestVerifySIWE_LinkedWalletSignsInAsOwner(t *testing.T) {
+	siweService := auth.NewSIWEService(5 * time.Minute)
+	jwtService := auth.NewJWTService([]byte("secret"), time.Hour)
+	message, signature, wallet := signSIWEMessage(t, siweService)
+
+	walletRepo := new(MockWalletRepository)
+	walletRepo.On("GetWalletOwner", mock.Anything, wallet).Return(int64(42), nil)
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetUserByID", mock.Anything, int64(42)).Return(&store.User{ID: 42, Address: walletAccount}, nil)
+	userRepo.On("GetOrCreateUserByAddress", mock.Anything, walletAccount).Return(&store.User{ID: 42, Address: walletAccount}, nil)
+	userRepo.On("RecordLogin", mock.Anything, int64(42)).Return(nil)
+
+	handler := NewAuthHandler(siweService, jwtService, userRepo, nil, nil)
+	handler.SetWalletRepository(walletRepo)
+
+	body, _ := json.Marshal(VerifyRequest{Message: message, Signature: signature})
+	rec := httptest.NewRecorder()
+	handler.VerifySIWE(rec, httptest.NewRequest("POST", "/auth/siwe/verify", bytes.NewReader(body)))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response VerifyResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, walletAccount, response.Address)
+	assert.Equal(t, wallet, response.Wallet)
+
+	claims, err := jwtService.VerifyToken(context.Background(), response.Token)
+	require.NoError(t, err)
+	assert.Equal(t, walletAccount, claims.Address)
+	userRepo.AssertNotCalled(t, "GetOrCreateUserByAddress", mock.Anything, wallet)
+}
@@ -0,0 +1,539 @@
+package http
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/yourusername/gatekeeper/internal/audit"
+	"github.com/yourusername/gatekeeper/internal/log"
+	"github.com/yourusername/gatekeeper/internal/store"
+	"github.com/yourusername/gatekeeper/internal/webauthn"
+)
+
+// WebAuthnAssertionHeader carries the passkey assertion of a step-up verification
+const WebAuthnAssertionHeader = "X-WebAuthn-Assertion"
+
+// WebAuthnHandler registers passkeys of wallet accounts and verifies them as a
+// second factor for sensitive operations
+type WebAuthnHandler struct {
+	service        *webauthn.Service
+	rpName         string
+	credentialRepo store.WebAuthnRepositoryInterface
+	userRepo       store.UserRepositoryInterface
+	logger         log.Logger
+	auditLogger    audit.AuditLogger
+}
+
+// NewWebAuthnHandler creates a new WebAuthn handler
+func NewWebAuthnHandler(service *webauthn.Service, rpName string, credentialRepo store.WebAuthnRepositoryInterface, userRepo store.UserRepositoryInterface, logger log.Logger, auditLogger audit.AuditLogger) *WebAuthnHandler {
+	return &WebAuthnHandler{
+		service:        service,
+		rpName:         rpName,
+		credentialRepo: credentialRepo,
+		userRepo:       userRepo,
+		logger:         logger,
+		auditLogger:    auditLogger,
+	}
+}
+
+// base64URL is binary data encoded as unpadded base64url in JSON, the
+// encoding WebAuthn uses for binary members
+type base64URL []byte
+
+// MarshalJSON encodes the data as unpadded base64url
+func (b base64URL) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+// UnmarshalJSON decodes base64url, with or without padding
+func (b *base64URL) UnmarshalJSON(data []byte) error {
+	var encoded string
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	if err != nil {
+		return errors.New("invalid base64url value")
+	}
+	*b = decoded
+	return nil
+}
+
+// credentialDescriptor identifies a credential in ceremony options
+type credentialDescriptor struct {
+	Type string    `json:"type"`
+	ID   base64URL `json:"id"`
+}
+
+// credentialParameter offers a public key algorithm in registration options
+type credentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"` // COSE algorithm
+}
+
+// RegistrationOptions are the options to pass to navigator.credentials.create
+type RegistrationOptions struct {
+	PublicKey struct {
+		Challenge string `json:"challenge"`
+		RP        struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"rp"`
+		User struct {
+			ID          base64URL `json:"id"`
+			Name        string    `json:"name"`
+			DisplayName string    `json:"displayName"`
+		} `json:"user"`
+		PubKeyCredParams       []credentialParameter  `json:"pubKeyCredParams"`
+		Timeout                int64                  `json:"timeout"` // Milliseconds
+		Attestation            string                 `json:"attestation"`
+		ExcludeCredentials     []credentialDescriptor `json:"excludeCredentials"`
+		AuthenticatorSelection struct {
+			ResidentKey      string `json:"residentKey"`
+			UserVerification string `json:"userVerification"`
+		} `json:"authenticatorSelection"`
+	} `json:"publicKey"`
+}
+
+// FinishRegistrationRequest is the authenticator's response to the registration options
+type FinishRegistrationRequest struct {
+	Name              string    `json:"name"`
+	ClientDataJSON    base64URL `json:"clientDataJSON"`
+	AttestationObject base64URL `json:"attestationObject"`
+}
+
+// AssertionOptions are the options to pass to navigator.credentials.get
+type AssertionOptions struct {
+	PublicKey struct {
+		Challenge        string                 `json:"challenge"`
+		RPID             string                 `json:"rpId"`
+		AllowCredentials []credentialDescriptor `json:"allowCredentials"`
+		Timeout          int64                  `json:"timeout"` // Milliseconds
+		UserVerification string                 `json:"userVerification"`
+	} `json:"publicKey"`
+}
+
+// WebAuthnAssertion is the authenticator's response to the assertion options,
+// sent base64url encoded as JSON in the X-WebAuthn-Assertion header
+type WebAuthnAssertion struct {
+	CredentialID      base64URL `json:"credentialId"`
+	ClientDataJSON    base64URL `json:"clientDataJSON"`
+	AuthenticatorData base64URL `json:"authenticatorData"`
+	Signature         base64URL `json:"signature"`
+}
+
+// WebAuthnCredentialResponse represents a registered passkey in API responses
+type WebAuthnCredentialResponse struct {
+	ID           int64      `json:"id"`
+	CredentialID base64URL  `json:"credentialId"`
+	Name         string     `json:"name"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	LastUsedAt   *time.Time `json:"lastUsedAt,omitempty"`
+}
+
+// BeginRegistration handles POST /api/me/webauthn/register/begin - Options for
+// registering a new passkey
+func (h *WebAuthnHandler) BeginRegistration(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	credentials, err := h.credentialRepo.ListCredentials(r.Context(), user.ID)
+	if err != nil {
+		requestLogger(r, h.logger).Error("failed to list webauthn credentials", log.Int64("user_id", user.ID), log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to retrieve passkeys", http.StatusInternalServerError)
+		return
+	}
+
+	challenge, err := h.service.NewChallenge(user.ID, webauthn.PurposeRegistration)
+	if err != nil {
+		requestLogger(r, h.logger).Error("failed to issue webauthn challenge", log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to issue challenge", http.StatusInternalServerError)
+		return
+	}
+
+	var options RegistrationOptions
+	options.PublicKey.Challenge = challenge
+	options.PublicKey.RP.ID = h.service.RPID()
+	options.PublicKey.RP.Name = h.rpName
+	options.PublicKey.User.ID = base64URL(strconv.FormatInt(user.ID, 10))
+	options.PublicKey.User.Name = user.Address
+	options.PublicKey.User.DisplayName = user.Address
+	for _, alg := range webauthn.SupportedAlgorithms {
+		options.PublicKey.PubKeyCredParams = append(options.PublicKey.PubKeyCredParams, credentialParameter{Type: "public-key", Alg: alg})
+	}
+	options.PublicKey.Timeout = h.service.ChallengeTTL().Milliseconds()
+	options.PublicKey.Attestation = "none"
+	options.PublicKey.ExcludeCredentials = credentialDescriptors(credentials)
+	options.PublicKey.AuthenticatorSelection.ResidentKey = "preferred"
+	options.PublicKey.AuthenticatorSelection.UserVerification = "preferred"
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(options)
+}
+
+// FinishRegistration handles POST /api/me/webauthn/register/finish - Verifies
+// and stores a new passkey. Once an account has a passkey, registering another
+// requires a step-up verification with an existing one.
+func (h *WebAuthnHandler) FinishRegistration(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := requestLogger(r, h.logger)
+
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req FinishRegistrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, "Invalid request body", err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 255 {
+		h.writeError(w, "Validation failed", "Name is required and must be at most 255 characters", http.StatusBadRequest)
+		return
+	}
+
+	credential, err := h.service.VerifyRegistration(user.ID, req.ClientDataJSON, req.AttestationObject)
+	if err != nil {
+		h.writeError(w, "Invalid passkey registration", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.credentialRepo.CreateCredential(ctx, &store.WebAuthnCredential{
+		UserID:       user.ID,
+		CredentialID: credential.ID,
+		PublicKey:    credential.PublicKey,
+		SignCount:    credential.SignCount,
+		Name:         req.Name,
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrDuplicate) {
+			h.writeError(w, "Conflict", "This passkey is already registered", http.StatusConflict)
+			return
+		}
+		logger.Error("failed to store webauthn credential", log.Int64("user_id", user.ID), log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to store passkey", http.StatusInternalServerError)
+		return
+	}
+
+	if h.auditLogger != nil {
+		h.auditLogger.Log(ctx, withClientInfo(r, audit.AuditEvent{
+			Action:     audit.ActionWebAuthnRegistered,
+			Result:     audit.ResultSuccess,
+			UserAddr:   user.Address,
+			UserID:     user.ID,
+			ResourceID: strconv.FormatInt(created.ID, 10),
+			Method:     r.Method,
+			Endpoint:   r.URL.Path,
+		}))
+	}
+
+	logger.Info("passkey registered", log.Int64("user_id", user.ID), log.Int64("credential_id", created.ID))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(credentialResponse(created))
+}
+
+// ListCredentials handles GET /api/me/webauthn/credentials - The caller's passkeys
+func (h *WebAuthnHandler) ListCredentials(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	credentials, err := h.credentialRepo.ListCredentials(r.Context(), user.ID)
+	if err != nil {
+		requestLogger(r, h.logger).Error("failed to list webauthn credentials", log.Int64("user_id", user.ID), log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to retrieve passkeys", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]WebAuthnCredentialResponse, len(credentials))
+	for i := range credentials {
+		response[i] = credentialResponse(&credentials[i])
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"credentials": response})
+}
+
+// DeleteCredential handles DELETE /api/me/webauthn/credentials/{id}. It is
+// routed behind RequireAssertion so a stolen session cannot remove the factor.
+func (h *WebAuthnHandler) DeleteCredential(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := requestLogger(r, h.logger)
+
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.writeError(w, "Invalid passkey ID", "Passkey ID must be a number", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.credentialRepo.DeleteCredential(ctx, user.ID, id); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			h.writeError(w, "Not found", "Passkey not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to delete webauthn credential", log.Int64("user_id", user.ID), log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to delete passkey", http.StatusInternalServerError)
+		return
+	}
+
+	if h.auditLogger != nil {
+		h.auditLogger.Log(ctx, withClientInfo(r, audit.AuditEvent{
+			Action:     audit.ActionWebAuthnRemoved,
+			Result:     audit.ResultSuccess,
+			UserAddr:   user.Address,
+			UserID:     user.ID,
+			ResourceID: strconv.FormatInt(id, 10),
+			Method:     r.Method,
+			Endpoint:   r.URL.Path,
+		}))
+	}
+
+	logger.Info("passkey removed", log.Int64("user_id", user.ID), log.Int64("credential_id", id))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// BeginAssertion handles POST /api/me/webauthn/assertion/begin - Options for
+// the passkey assertion of a step-up verification
+func (h *WebAuthnHandler) BeginAssertion(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	credentials, err := h.credentialRepo.ListCredentials(r.Context(), user.ID)
+	if err != nil {
+		requestLogger(r, h.logger).Error("failed to list webauthn credentials", log.Int64("user_id", user.ID), log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to retrieve passkeys", http.StatusInternalServerError)
+		return
+	}
+	if len(credentials) == 0 {
+		h.writeError(w, "No passkeys", "Register a passkey first", http.StatusBadRequest)
+		return
+	}
+
+	challenge, err := h.service.NewChallenge(user.ID, webauthn.PurposeAssertion)
+	if err != nil {
+		requestLogger(r, h.logger).Error("failed to issue webauthn challenge", log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to issue challenge", http.StatusInternalServerError)
+		return
+	}
+
+	var options AssertionOptions
+	options.PublicKey.Challenge = challenge
+	options.PublicKey.RPID = h.service.RPID()
+	options.PublicKey.AllowCredentials = credentialDescriptors(credentials)
+	options.PublicKey.Timeout = h.service.ChallengeTTL().Milliseconds()
+	options.PublicKey.UserVerification = "preferred"
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(options)
+}
+
+// RequireAssertion requires JWT sessions of accounts with a passkey to present
+// a passkey assertion in the X-WebAuthn-Assertion header. Accounts without
+// passkeys and API-key callers, which are restricted by key scopes instead,
+// pass through.
+func (h *WebAuthnHandler) RequireAssertion(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		logger := requestLogger(r, h.logger)
+
+		claims := ClaimsFromContext(r)
+		if claims == nil {
+			h.writeError(w, "Unauthorized", "No authentication claims found", http.StatusUnauthorized)
+			return
+		}
+		if AuthMethodFromContext(r) == AuthMethodAPIKey || claims.Address == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		user, err := h.userRepo.GetUserByAddress(ctx, claims.Address)
+		if errors.Is(err, store.ErrNotFound) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if err != nil {
+			logger.Error("failed to get user", log.Error(err))
+			h.writeError(w, "Internal server error", "Failed to retrieve user", http.StatusInternalServerError)
+			return
+		}
+
+		credentials, err := h.credentialRepo.ListCredentials(ctx, user.ID)
+		if err != nil {
+			logger.Error("failed to list webauthn credentials", log.Int64("user_id", user.ID), log.Error(err))
+			h.writeError(w, "Internal server error", "Failed to retrieve passkeys", http.StatusInternalServerError)
+			return
+		}
+		if len(credentials) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header := r.Header.Get(WebAuthnAssertionHeader)
+		if header == "" {
+			h.writeStepUpRequired(w, r, "This operation requires a passkey assertion in the "+WebAuthnAssertionHeader+" header")
+			return
+		}
+
+		credential, err := h.verifyAssertion(r, user, header)
+		if errors.Is(err, webauthn.ErrVerification) {
+			logger.Warn("passkey step-up failed", log.Int64("user_id", user.ID), log.Error(err))
+			h.auditStepUp(r, user, audit.ResultFailure, "", err)
+			h.writeStepUpRequired(w, r, err.Error())
+			return
+		}
+		if err != nil {
+			logger.Error("failed to verify passkey assertion", log.Int64("user_id", user.ID), log.Error(err))
+			h.writeError(w, "Internal server error", "Failed to verify passkey", http.StatusInternalServerError)
+			return
+		}
+
+		h.auditStepUp(r, user, audit.ResultSuccess, strconv.FormatInt(credential.ID, 10), nil)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// verifyAssertion verifies the assertion header against the user's passkeys
+// and records the new signature counter. Assertions that do not verify return
+// errors wrapping webauthn.ErrVerification.
+func (h *WebAuthnHandler) verifyAssertion(r *http.Request, user *store.User, header string) (*store.WebAuthnCredential, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(header, "="))
+	if err != nil {
+		return nil, fmt.Errorf("%w: assertion header is not base64url", webauthn.ErrVerification)
+	}
+	var assertion WebAuthnAssertion
+	if err := json.Unmarshal(raw, &assertion); err != nil {
+		return nil, fmt.Errorf("%w: assertion header is not valid JSON", webauthn.ErrVerification)
+	}
+
+	credential, err := h.credentialRepo.GetCredential(r.Context(), user.ID, assertion.CredentialID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, fmt.Errorf("%w: unknown passkey", webauthn.ErrVerification)
+		}
+		return nil, err
+	}
+
+	signCount, err := h.service.VerifyAssertion(user.ID, &webauthn.Credential{
+		ID:        credential.CredentialID,
+		PublicKey: credential.PublicKey,
+		SignCount: credential.SignCount,
+	}, assertion.ClientDataJSON, assertion.AuthenticatorData, assertion.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	// The counter must be stored for clone detection, so failing to do so fails the step-up
+	if err := h.credentialRepo.RecordUse(r.Context(), credential.ID, signCount); err != nil {
+		return nil, err
+	}
+	return credential, nil
+}
+
+// auditStepUp records the outcome of a step-up verification
+func (h *WebAuthnHandler) auditStepUp(r *http.Request, user *store.User, result audit.Result, credentialID string, err error) {
+	if h.auditLogger == nil {
+		return
+	}
+	event := audit.AuditEvent{
+		Action:     audit.ActionWebAuthnStepUp,
+		Result:     result,
+		UserAddr:   user.Address,
+		UserID:     user.ID,
+		ResourceID: credentialID,
+		Method:     r.Method,
+		Endpoint:   r.URL.Path,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	h.auditLogger.Log(r.Context(), withClientInfo(r, event))
+}
+
+// writeStepUpRequired sends a 403 problem response asking for a passkey assertion
+func (h *WebAuthnHandler) writeStepUpRequired(w http.ResponseWriter, r *http.Request, detail string) {
+	writeProblem(w, Problem{
+		Type:     ProblemTypePasskeyRequired,
+		Title:    "Passkey verification required",
+		Status:   http.StatusForbidden,
+		Detail:   detail,
+		Instance: r.URL.Path,
+	})
+}
+
+// currentUser loads the wallet user making the request, writing the error
+// response when there is none
+func (h *WebAuthnHandler) currentUser(w http.ResponseWriter, r *http.Request) (*store.User, bool) {
+	claims := ClaimsFromContext(r)
+	if claims == nil {
+		h.writeError(w, "Unauthorized", "No authentication claims found", http.StatusUnauthorized)
+		return nil, false
+	}
+	if claims.Address == "" {
+		h.writeError(w, "Forbidden", "Passkeys can only be registered for wallet accounts", http.StatusForbidden)
+		return nil, false
+	}
+
+	user, err := h.userRepo.GetOrCreateUserByAddress(r.Context(), claims.Address)
+	if err != nil {
+		requestLogger(r, h.logger).Error("failed to get or create user", log.Error(err))
+		h.writeError(w, "Internal server error", "Failed to retrieve user", http.StatusInternalServerError)
+		return nil, false
+	}
+	return user, true
+}
+
+// credentialDescriptors lists credentials for ceremony options
+func credentialDescriptors(credentials []store.WebAuthnCredential) []credentialDescriptor {
+	descriptors := make([]credentialDescriptor, len(credentials))
+	for i, credential := range credentials {
+		descriptors[i] = credentialDescriptor{Type: "public-key", ID: credential.CredentialID}
+	}
+	return descriptors
+}
+
+// credentialResponse converts a stored credential for API responses
+func credentialResponse(credential *store.WebAuthnCredential) WebAuthnCredentialResponse {
+	return WebAuthnCredentialResponse{
+		ID:           credential.ID,
+		CredentialID: credential.CredentialID,
+		Name:         credential.Name,
+		CreatedAt:    credential.CreatedAt,
+		LastUsedAt:   credential.LastUsedAt,
+	}
+}
+
+// writeError writes an error response
+func (h *WebAuthnHandler) writeError(w http.ResponseWriter, error, details string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := ErrorResponse{
+		Error:   error,
+		Details: details,
+	}
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,220 @@
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/store"
+	"github.com/yourusername/gatekeeper/internal/webauthn"
+	"github.com/yourusername/gatekeeper/internal/webauthn/webauthntest"
+)
+
+// MockWebAuthnRepository is a mock implementation of WebAuthnRepository
+type MockWebAuthnRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebAuthnRepository) CreateCredential(ctx context.Context, credential *store.WebAuthnCredential) (*store.WebAuthnCredential, error) {
+	args := m.Called(ctx, credential)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.WebAuthnCredential), args.Error(1)
+}
+
+func (m *MockWebAuthnRepository) ListCredentials(ctx context.Context, userID int64) ([]store.WebAuthnCredential, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]store.WebAuthnCredential), args.Error(1)
+}
+
+func (m *MockWebAuthnRepository) GetCredential(ctx context.Context, userID int64, credentialID []byte) (*store.WebAuthnCredential, error) {
+	args := m.Called(ctx, userID, credentialID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*store.WebAuthnCredential), args.Error(1)
+}
+
+func (m *MockWebAuthnRepository) RecordUse(ctx context.Context, id int64, signCount uint32) error {
+	args := m.Called(ctx, id, signCount)
+	return args.Error(0)
+}
+
+func (m *MockWebAuthnRepository) DeleteCredential(ctx context.Context, userID int64, id int64) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+const webAuthnOrigin = "https://app.example.com"
+
+func newWebAuthnHandler() (*WebAuthnHandler, *webauthn.Service, *MockWebAuthnRepository) {
+	service := webauthn.NewService("example.com", []string{webAuthnOrigin}, 5*time.Minute)
+	credentialRepo := new(MockWebAuthnRepository)
+	userRepo := new(MockUserRepository)
+	user := &store.User{ID: 42, Address: walletAccount}
+	userRepo.On("GetOrCreateUserByAddress", mock.Anything, walletAccount).Return(user, nil)
+	userRepo.On("GetUserByAddress", mock.Anything, walletAccount).Return(user, nil)
+	return NewWebAuthnHandler(service, "Gatekeeper", credentialRepo, userRepo, nil, nil), service, credentialRepo
+}
+
+// assertionHeader encodes an authenticator's assertion for the X-WebAuthn-Assertion header
+func assertionHeader(authenticator *webauthntest.Authenticator, challenge string) string {
+	clientData, authData, signature := authenticator.Assert(challenge)
+	encoded, _ := json.Marshal(WebAuthnAssertion{
+		CredentialID:      authenticator.CredentialID,
+		ClientDataJSON:    clientData,
+		AuthenticatorData: authData,
+		Signature:         signature,
+	})
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+func TestWebAuthnRegistration(t *testing.T) {
+	handler, _, credentialRepo := newWebAuthnHandler()
+	authenticator := webauthntest.NewAuthenticator("example.com", webAuthnOrigin)
+	credentialRepo.On("ListCredentials", mock.Anything, int64(42)).Return([]store.WebAuthnCredential{}, nil)
+
+	rec := httptest.NewRecorder()
+	handler.BeginRegistration(rec, newWalletRequest("POST", "/api/me/webauthn/register/begin", nil, &auth.Claims{Address: walletAccount}))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var options RegistrationOptions
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &options))
+	assert.Equal(t, "example.com", options.PublicKey.RP.ID)
+	assert.Equal(t, "none", options.PublicKey.Attestation)
+	assert.Len(t, options.PublicKey.PubKeyCredParams, len(webauthn.SupportedAlgorithms))
+
+	clientData, attestation := authenticator.Register(options.PublicKey.Challenge)
+	credentialRepo.On("CreateCredential", mock.Anything, mock.MatchedBy(func(c *store.WebAuthnCredential) bool {
+		return c.UserID == 42 && string(c.CredentialID) == "credential-1" && c.Name == "Laptop"
+	})).Return(&store.WebAuthnCredential{ID: 3, UserID: 42, CredentialID: []byte("credential-1"), Name: "Laptop"}, nil)
+
+	rec = httptest.NewRecorder()
+	handler.FinishRegistration(rec, newWalletRequest("POST", "/api/me/webauthn/register/finish", FinishRegistrationRequest{
+		Name:              "Laptop",
+		ClientDataJSON:    clientData,
+		AttestationObject: attestation,
+	}, &auth.Claims{Address: walletAccount}))
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var response WebAuthnCredentialResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, int64(3), response.ID)
+	assert.Equal(t, "Laptop", response.Name)
+	credentialRepo.AssertExpectations(t)
+
+	// The challenge was consumed
+	rec = httptest.NewRecorder()
+	handler.FinishRegistration(rec, newWalletRequest("POST", "/api/me/webauthn/register/finish", FinishRegistrationRequest{
+		Name:              "Laptop",
+		ClientDataJSON:    clientData,
+		AttestationObject: attestation,
+	}, &auth.Claims{Address: walletAccount}))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestWebAuthnRegistration_ServiceAccount(t *testing.T) {
+	handler, _, _ := newWebAuthnHandler()
+
+	rec := httptest.NewRecorder()
+	handler.BeginRegistration(rec, newWalletRequest("POST", "/api/me/webauthn/register/begin", nil, &auth.Claims{Scopes: []string{"api"}}))
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestRequireAssertion(t *testing.T) {
+	authenticator := webauthntest.NewAuthenticator("example.com", webAuthnOrigin)
+	stored := store.WebAuthnCredential{ID: 3, UserID: 42, CredentialID: authenticator.CredentialID, PublicKey: authenticator.COSEKey()}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	newRequest := func(method string) *http.Request {
+		req := newWalletRequest("POST", "/api/keys", nil, &auth.Claims{Address: walletAccount})
+		return req.WithContext(context.WithValue(req.Context(), AuthMethodContextKey, method))
+	}
+
+	t.Run("accounts without passkeys pass through", func(t *testing.T) {
+		handler, _, credentialRepo := newWebAuthnHandler()
+		credentialRepo.On("ListCredentials", mock.Anything, int64(42)).Return([]store.WebAuthnCredential{}, nil)
+
+		rec := httptest.NewRecorder()
+		handler.RequireAssertion(next).ServeHTTP(rec, newRequest(AuthMethodJWT))
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("API key callers pass through", func(t *testing.T) {
+		handler, _, credentialRepo := newWebAuthnHandler()
+
+		rec := httptest.NewRecorder()
+		handler.RequireAssertion(next).ServeHTTP(rec, newRequest(AuthMethodAPIKey))
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		credentialRepo.AssertNotCalled(t, "ListCredentials", mock.Anything, mock.Anything)
+	})
+
+	t.Run("missing assertion", func(t *testing.T) {
+		handler, _, credentialRepo := newWebAuthnHandler()
+		credentialRepo.On("ListCredentials", mock.Anything, int64(42)).Return([]store.WebAuthnCredential{stored}, nil)
+
+		rec := httptest.NewRecorder()
+		handler.RequireAssertion(next).ServeHTTP(rec, newRequest(AuthMethodJWT))
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		var problem Problem
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+		assert.Equal(t, ProblemTypePasskeyRequired, problem.Type)
+	})
+
+	t.Run("valid assertion", func(t *testing.T) {
+		handler, service, credentialRepo := newWebAuthnHandler()
+		credentialRepo.On("ListCredentials", mock.Anything, int64(42)).Return([]store.WebAuthnCredential{stored}, nil)
+		credentialRepo.On("GetCredential", mock.Anything, int64(42), authenticator.CredentialID).Return(&stored, nil)
+		credentialRepo.On("RecordUse", mock.Anything, int64(3), mock.AnythingOfType("uint32")).Return(nil)
+
+		challenge, err := service.NewChallenge(42, webauthn.PurposeAssertion)
+		require.NoError(t, err)
+		req := newRequest(AuthMethodJWT)
+		req.Header.Set(WebAuthnAssertionHeader, assertionHeader(authenticator, challenge))
+
+		rec := httptest.NewRecorder()
+		handler.RequireAssertion(next).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		credentialRepo.AssertExpectations(t)
+
+		// Replaying the assertion fails: its challenge was consumed
+		rec = httptest.NewRecorder()
+		handler.RequireAssertion(next).ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("assertion for another user's challenge", func(t *testing.T) {
+		handler, service, credentialRepo := newWebAuthnHandler()
+		credentialRepo.On("ListCredentials", mock.Anything, int64(42)).Return([]store.WebAuthnCredential{stored}, nil)
+		credentialRepo.On("GetCredential", mock.Anything, int64(42), authenticator.CredentialID).Return(&stored, nil)
+
+		challenge, err := service.NewChallenge(7, webauthn.PurposeAssertion)
+		require.NoError(t, err)
+		req := newRequest(AuthMethodJWT)
+		req.Header.Set(WebAuthnAssertionHeader, assertionHeader(authenticator, challenge))
+
+		rec := httptest.NewRecorder()
+		handler.RequireAssertion(next).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		credentialRepo.AssertNotCalled(t, "RecordUse", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
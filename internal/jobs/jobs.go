@@ -0,0 +1,64 @@
+// Package jobs runs periodic background work such as key sunsets, expiry
+// notifications and key escrow.
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Job is periodic work started by Every
+type Job struct {
+	cancel   context.CancelFunc
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// Every runs run immediately and then every interval on its own goroutine until
+// the job is stopped. A failed run is logged as the named job and the work is
+// retried on the next tick.
+func Every(name string, interval time.Duration, run func(ctx context.Context) error, logger *zap.Logger) *Job {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &Job{cancel: cancel, done: make(chan struct{})}
+
+	runLogged := func() {
+		if err := run(ctx); err != nil && ctx.Err() == nil {
+			logger.Error(name+" run failed", zap.Error(err))
+		}
+	}
+
+	go func() {
+		defer close(job.done)
+		runLogged()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				runLogged()
+			}
+		}
+	}()
+	return job
+}
+
+// Stop cancels a run in progress and waits for it to return; no run starts
+// afterwards. Stopping a nil job, one that was never started, does nothing.
+func (j *Job) Stop() {
+	if j == nil {
+		return
+	}
+	j.stopOnce.Do(func() {
+		j.cancel()
+		<-j.done
+	})
+}
@@ -0,0 +1,63 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestEvery_RunsImmediatelyAndOnEachTick(t *testing.T) {
+	var runs atomic.Int32
+	job := Every("test", 10*time.Millisecond, func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	}, nil)
+
+	assert.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, time.Millisecond)
+	job.Stop()
+	stopped := runs.Load()
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, stopped, runs.Load(), "no run starts after Stop")
+
+	// Stopping twice, or a job never started, does nothing
+	job.Stop()
+	var never *Job
+	never.Stop()
+}
+
+func TestEvery_LogsFailedRuns(t *testing.T) {
+	core, logs := observer.New(zap.ErrorLevel)
+	failed := make(chan struct{}, 1)
+	job := Every("key sunset", time.Hour, func(ctx context.Context) error {
+		failed <- struct{}{}
+		return errors.New("database down")
+	}, zap.New(core))
+	<-failed
+	job.Stop()
+
+	entries := logs.FilterMessage("key sunset run failed").All()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "database down", entries[0].ContextMap()["error"])
+	}
+}
+
+func TestEvery_StopCancelsRun(t *testing.T) {
+	started := make(chan struct{})
+	var cancelled atomic.Bool
+	job := Every("test", time.Hour, func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		cancelled.Store(true)
+		return ctx.Err()
+	}, nil)
+
+	<-started
+	job.Stop()
+	assert.True(t, cancelled.Load())
+}
@@ -0,0 +1,167 @@
+package listener
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// listenFDsStart is the first file descriptor passed by socket activation (after stdio)
+const listenFDsStart = 3
+
+// Inherited returns the listeners passed to this process through systemd socket
+// activation (LISTEN_FDS/LISTEN_PID) or by a previous gatekeeper process during a
+// restart (see Handoff), which uses the same protocol without LISTEN_PID. The
+// environment variables are cleared so child processes do not inherit them.
+func Inherited() ([]net.Listener, error) {
+	defer os.Unsetenv("LISTEN_FDS")
+	defer os.Unsetenv("LISTEN_PID")
+	defer os.Unsetenv("LISTEN_FDNAMES")
+
+	count := os.Getenv("LISTEN_FDS")
+	if count == "" {
+		return nil, nil
+	}
+	if pid := os.Getenv("LISTEN_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		// Passed to a different process (e.g. our parent); not ours to use
+		return nil, nil
+	}
+
+	n, err := strconv.Atoi(count)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid LISTEN_FDS: %q", count)
+	}
+
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	listeners := make([]net.Listener, 0, n)
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("fd%d", listenFDsStart+i)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+
+		file := os.NewFile(uintptr(listenFDsStart+i), name)
+		ln, err := net.FileListener(file)
+		file.Close()
+		if err != nil {
+			for _, opened := range listeners {
+				opened.Close()
+			}
+			return nil, fmt.Errorf("inherited descriptor %s is not a listening socket: %w", name, err)
+		}
+		listeners = append(listeners, ln)
+	}
+	return listeners, nil
+}
+
+// ListenAll returns a listener for each spec, in order. An inherited listener bound
+// to the spec's address is used instead of binding a new socket, so connections queued
+// during a restart are not lost. Inherited listeners matching no spec are closed.
+func ListenAll(specs []Spec, inherited []net.Listener, reusePort bool) ([]net.Listener, error) {
+	listeners := make([]net.Listener, 0, len(specs))
+	used := make([]bool, len(inherited))
+
+	for _, spec := range specs {
+		var ln net.Listener
+		for i, candidate := range inherited {
+			if !used[i] && matchesSpec(candidate.Addr(), spec) {
+				ln, used[i] = candidate, true
+				break
+			}
+		}
+
+		if ln == nil {
+			var err error
+			ln, err = Listen(spec, reusePort)
+			if err != nil {
+				for _, opened := range listeners {
+					opened.Close()
+				}
+				return nil, fmt.Errorf("failed to listen on %s: %w", spec, err)
+			}
+		}
+		listeners = append(listeners, ln)
+	}
+
+	for i, candidate := range inherited {
+		if !used[i] {
+			candidate.Close()
+		}
+	}
+	return listeners, nil
+}
+
+// matchesSpec reports whether a listener bound to addr serves spec.
+// A TCP spec without a host (":8080") matches any host on that port.
+func matchesSpec(addr net.Addr, spec Spec) bool {
+	if addr.Network() != spec.Network {
+		return false
+	}
+	if spec.Network == "unix" {
+		return addr.String() == spec.Address
+	}
+
+	host, port, err := net.SplitHostPort(spec.Address)
+	if err != nil {
+		return false
+	}
+	boundHost, boundPort, err := net.SplitHostPort(addr.String())
+	if err != nil || boundPort != port {
+		return false
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.Equal(net.ParseIP(boundHost))
+	}
+	return host == boundHost
+}
+
+// Handoff starts a new copy of the running binary with the given listeners passed as
+// inherited descriptors, for a restart that drops no connections. The caller should
+// then stop accepting and drain in-flight requests; the new process serves everything
+// accepted from here on. The new process is a child of the caller, so it is only
+// for supervisors that do not stop it with its parent; under systemd, restart the
+// socket-activated service instead.
+func Handoff(listeners []net.Listener) (*os.Process, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate executable: %w", err)
+	}
+
+	files := make([]*os.File, 0, len(listeners))
+	defer func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}()
+	for _, ln := range listeners {
+		filer, ok := ln.(interface{ File() (*os.File, error) })
+		if !ok {
+			return nil, fmt.Errorf("listener on %s cannot be passed to another process", ln.Addr())
+		}
+		file, err := filer.File()
+		if err != nil {
+			return nil, fmt.Errorf("failed to duplicate listener on %s: %w", ln.Addr(), err)
+		}
+		files = append(files, file)
+
+		// The socket file now belongs to the new process as well; keep it on close
+		if unixListener, ok := ln.(*net.UnixListener); ok {
+			unixListener.SetUnlinkOnClose(false)
+		}
+	}
+
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.ExtraFiles = files
+	cmd.Env = append(os.Environ(), "LISTEN_FDS="+strconv.Itoa(len(files)))
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start new process: %w", err)
+	}
+	return cmd.Process, nil
+}
@@ -0,0 +1,96 @@
+package listener
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInherited_NoActivation(t *testing.T) {
+	t.Setenv("LISTEN_FDS", "")
+
+	listeners, err := Inherited()
+	require.NoError(t, err)
+	assert.Empty(t, listeners)
+}
+
+func TestInherited_OtherProcess(t *testing.T) {
+	t.Setenv("LISTEN_FDS", "1")
+	t.Setenv("LISTEN_PID", "1")
+
+	listeners, err := Inherited()
+	require.NoError(t, err)
+	assert.Empty(t, listeners)
+}
+
+func TestInherited_InvalidCount(t *testing.T) {
+	t.Setenv("LISTEN_FDS", "many")
+
+	_, err := Inherited()
+	assert.Error(t, err)
+}
+
+func TestMatchesSpec(t *testing.T) {
+	tcp := &net.TCPAddr{IP: net.IPv6zero, Port: 8080}
+	loopback := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 9090}
+	unixAddr := &net.UnixAddr{Name: "/run/gatekeeper.sock", Net: "unix"}
+
+	tests := []struct {
+		name  string
+		addr  net.Addr
+		spec  Spec
+		match bool
+	}{
+		{name: "any host same port", addr: tcp, spec: Spec{Network: "tcp", Address: ":8080"}, match: true},
+		{name: "different port", addr: tcp, spec: Spec{Network: "tcp", Address: ":8081"}},
+		{name: "same host", addr: loopback, spec: Spec{Network: "tcp", Address: "127.0.0.1:9090"}, match: true},
+		{name: "different host", addr: loopback, spec: Spec{Network: "tcp", Address: "10.0.0.1:9090"}},
+		{name: "unix path", addr: unixAddr, spec: Spec{Network: "unix", Address: "/run/gatekeeper.sock"}, match: true},
+		{name: "network mismatch", addr: unixAddr, spec: Spec{Network: "tcp", Address: ":8080"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.match, matchesSpec(tt.addr, tt.spec))
+		})
+	}
+}
+
+// TestListenAll_PrefersInherited verifies inherited sockets are reused and unmatched ones closed
+func TestListenAll_PrefersInherited(t *testing.T) {
+	inheritedTCP, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	unmatched, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	socket := filepath.Join(t.TempDir(), "admin.sock")
+	specs := []Spec{
+		{Role: RolePublic, Network: "tcp", Address: inheritedTCP.Addr().String()},
+		{Role: RoleAdmin, Network: "unix", Address: socket},
+	}
+
+	listeners, err := ListenAll(specs, []net.Listener{unmatched, inheritedTCP}, false)
+	require.NoError(t, err)
+	defer listeners[0].Close()
+	defer listeners[1].Close()
+
+	assert.Same(t, inheritedTCP, listeners[0])
+	assert.Equal(t, socket, listeners[1].Addr().String())
+
+	_, err = unmatched.Accept()
+	assert.Error(t, err, "unmatched inherited listener should be closed")
+}
+
+func TestListen_ReusePort(t *testing.T) {
+	first, err := Listen(Spec{Role: RolePublic, Network: "tcp", Address: "127.0.0.1:0"}, true)
+	require.NoError(t, err)
+	defer first.Close()
+
+	// A second process performing a binary swap binds the same port
+	second, err := Listen(Spec{Role: RolePublic, Network: "tcp", Address: first.Addr().String()}, true)
+	require.NoError(t, err)
+	second.Close()
+}
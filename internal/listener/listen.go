@@ -0,0 +1,132 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strings"
+)
+
+// Listener roles select which routes and middleware a listener serves
+const (
+	RolePublic = "public" // Client-facing API, authentication and docs
+	RoleAdmin  = "admin"  // Internal admin API, metrics and health checks
+)
+
+// unixSocketMode is the permission set on Unix sockets, so only the owner and
+// group (e.g. a local proxy sidecar) can connect
+const unixSocketMode fs.FileMode = 0660
+
+// Spec declares a listener: its role and where it binds
+type Spec struct {
+	Role    string
+	Network string // "tcp" or "unix"
+	Address string // host:port for tcp, socket path for unix
+}
+
+// String returns the spec in the form accepted by ParseSpecs
+func (s Spec) String() string {
+	return fmt.Sprintf("%s=%s://%s", s.Role, s.Network, s.Address)
+}
+
+// ParseSpecs parses a comma-separated list of role=address listener declarations,
+// e.g. "public=tcp://:8080,admin=unix:///run/gatekeeper/admin.sock".
+// An address without a scheme is a TCP address. At least one public listener is required.
+func ParseSpecs(value string) ([]Spec, error) {
+	var specs []Spec
+	hasPublic := false
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		role, address, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("listener %q must be in the form role=address", entry)
+		}
+		if role != RolePublic && role != RoleAdmin {
+			return nil, fmt.Errorf("listener %q has unknown role %q (use %s or %s)", entry, role, RolePublic, RoleAdmin)
+		}
+
+		spec := Spec{Role: role, Network: "tcp", Address: address}
+		if network, rest, ok := strings.Cut(address, "://"); ok {
+			spec.Network, spec.Address = network, rest
+		}
+		if spec.Network != "tcp" && spec.Network != "unix" {
+			return nil, fmt.Errorf("listener %q has unsupported network %q (use tcp or unix)", entry, spec.Network)
+		}
+		if spec.Address == "" {
+			return nil, fmt.Errorf("listener %q has an empty address", entry)
+		}
+
+		hasPublic = hasPublic || role == RolePublic
+		specs = append(specs, spec)
+	}
+
+	if !hasPublic {
+		return nil, fmt.Errorf("at least one %s listener is required", RolePublic)
+	}
+	return specs, nil
+}
+
+// HasRole reports whether any spec has the given role
+func HasRole(specs []Spec, role string) bool {
+	for _, spec := range specs {
+		if spec.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Listen binds the listener described by spec. A stale Unix socket left by a
+// previous run is removed before binding. With reusePort, TCP listeners set
+// SO_REUSEPORT so a new process can bind the same address while this one drains.
+func Listen(spec Spec, reusePort bool) (net.Listener, error) {
+	if spec.Network != "unix" {
+		config := net.ListenConfig{}
+		if reusePort {
+			config.Control = reusePortControl
+		}
+		return config.Listen(context.Background(), spec.Network, spec.Address)
+	}
+
+	if err := removeStaleSocket(spec.Address); err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", spec.Address)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(spec.Address, unixSocketMode); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("failed to set permissions on %s: %w", spec.Address, err)
+	}
+	return ln, nil
+}
+
+// removeStaleSocket removes a socket file nothing is listening on.
+// Returns an error if the path exists and is not a socket, or another process is serving it.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&fs.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return fmt.Errorf("%s is in use by another process", path)
+	}
+	return os.Remove(path)
+}
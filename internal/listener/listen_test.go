@@ -0,0 +1,86 @@
+package listener
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSpecs(t *testing.T) {
+	specs, err := ParseSpecs("public=tcp://:8080, public=:8081, admin=unix:///run/gatekeeper/admin.sock")
+	require.NoError(t, err)
+
+	assert.Equal(t, []Spec{
+		{Role: RolePublic, Network: "tcp", Address: ":8080"},
+		{Role: RolePublic, Network: "tcp", Address: ":8081"},
+		{Role: RoleAdmin, Network: "unix", Address: "/run/gatekeeper/admin.sock"},
+	}, specs)
+	assert.True(t, HasRole(specs, RoleAdmin))
+	assert.Equal(t, "admin=unix:///run/gatekeeper/admin.sock", specs[2].String())
+}
+
+func TestParseSpecs_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "missing role", value: "tcp://:8080"},
+		{name: "unknown role", value: "metrics=tcp://:9090"},
+		{name: "unknown network", value: "public=udp://:8080"},
+		{name: "empty address", value: "public=unix://"},
+		{name: "no public listener", value: "admin=tcp://:9090"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSpecs(tt.value)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestListen_UnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gatekeeper.sock")
+
+	ln, err := Listen(Spec{Role: RolePublic, Network: "unix", Address: path}, false)
+	require.NoError(t, err)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, unixSocketMode, info.Mode().Perm())
+
+	// A second listener on a socket that is in use must fail
+	_, err = Listen(Spec{Role: RolePublic, Network: "unix", Address: path}, false)
+	assert.Error(t, err)
+
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	conn.Close()
+	ln.Close()
+}
+
+func TestListen_RemovesStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gatekeeper.sock")
+
+	// Leave a socket file behind without a listener, as after a crash
+	ln, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	ln.Close()
+
+	ln, err = Listen(Spec{Role: RolePublic, Network: "unix", Address: path}, false)
+	require.NoError(t, err)
+	ln.Close()
+}
+
+func TestListen_RefusesNonSocketPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "not-a-socket")
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0600))
+
+	_, err := Listen(Spec{Role: RolePublic, Network: "unix", Address: path}, false)
+	assert.Error(t, err)
+}
@@ -0,0 +1,265 @@
+package listener
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// proxyV2Signature is the fixed prefix of a PROXY protocol v2 header
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+const (
+	// proxyV1MaxLength is the longest valid v1 header, including the trailing CRLF
+	proxyV1MaxLength = 107
+
+	// defaultProxyHeaderTimeout bounds how long a connection may take to send its header
+	defaultProxyHeaderTimeout = 5 * time.Second
+)
+
+// ProxyProtocolListener accepts HAProxy PROXY protocol v1 and v2 headers so the
+// client address reported by a connection is the one seen by the load balancer.
+//
+// Connections from trusted sources must start with a PROXY header; connections from
+// any other source are served unchanged. The header is read lazily, on the connection's
+// own goroutine, so a slow client cannot stall Accept.
+type ProxyProtocolListener struct {
+	net.Listener
+
+	trusted       []*net.IPNet
+	headerTimeout time.Duration
+}
+
+// NewProxyProtocolListener wraps inner to parse PROXY protocol headers from the
+// given trusted CIDRs. At least one is required, so a client reaching the listener
+// directly can never claim another address.
+func NewProxyProtocolListener(inner net.Listener, trustedCIDRs []string) (*ProxyProtocolListener, error) {
+	if len(trustedCIDRs) == 0 {
+		return nil, fmt.Errorf("PROXY protocol needs at least one trusted proxy CIDR")
+	}
+	trusted := make([]*net.IPNet, 0, len(trustedCIDRs))
+	for _, cidr := range trustedCIDRs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy CIDR %q: %w", cidr, err)
+		}
+		trusted = append(trusted, network)
+	}
+
+	return &ProxyProtocolListener{
+		Listener:      inner,
+		trusted:       trusted,
+		headerTimeout: defaultProxyHeaderTimeout,
+	}, nil
+}
+
+// Accept waits for the next connection, wrapping it when its source is trusted
+func (l *ProxyProtocolListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if !l.isTrusted(conn.RemoteAddr()) {
+		return conn, nil
+	}
+	return &proxyConn{Conn: conn, reader: bufio.NewReader(conn), headerTimeout: l.headerTimeout}, nil
+}
+
+// isTrusted reports whether PROXY headers are accepted from addr
+func (l *ProxyProtocolListener) isTrusted(addr net.Addr) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	for _, network := range l.trusted {
+		if network.Contains(tcpAddr.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// proxyConn is a connection whose first bytes are a PROXY protocol header
+type proxyConn struct {
+	net.Conn
+	reader        *bufio.Reader
+	headerTimeout time.Duration
+
+	once       sync.Once
+	headerErr  error
+	remoteAddr net.Addr
+	localAddr  net.Addr
+}
+
+// readHeader parses the PROXY header on first use
+func (c *proxyConn) readHeader() {
+	c.once.Do(func() {
+		if c.headerTimeout > 0 {
+			c.Conn.SetReadDeadline(time.Now().Add(c.headerTimeout))
+			defer c.Conn.SetReadDeadline(time.Time{})
+		}
+
+		source, destination, err := readProxyHeader(c.reader)
+		if err != nil {
+			c.headerErr = fmt.Errorf("proxy protocol: %w", err)
+			c.Conn.Close()
+			return
+		}
+		c.remoteAddr = source
+		c.localAddr = destination
+	})
+}
+
+func (c *proxyConn) Read(b []byte) (int, error) {
+	c.readHeader()
+	if c.headerErr != nil {
+		return 0, c.headerErr
+	}
+	return c.reader.Read(b)
+}
+
+func (c *proxyConn) RemoteAddr() net.Addr {
+	c.readHeader()
+	if c.remoteAddr != nil {
+		return c.remoteAddr
+	}
+	return c.Conn.RemoteAddr()
+}
+
+func (c *proxyConn) LocalAddr() net.Addr {
+	c.readHeader()
+	if c.localAddr != nil {
+		return c.localAddr
+	}
+	return c.Conn.LocalAddr()
+}
+
+// PeerAddr returns the address of the peer that opened conn: the proxy for
+// connections accepted with a PROXY header, otherwise the remote address
+func PeerAddr(conn net.Conn) net.Addr {
+	if c, ok := conn.(*proxyConn); ok {
+		return c.Conn.RemoteAddr()
+	}
+	return conn.RemoteAddr()
+}
+
+// readProxyHeader reads a v1 or v2 header. Nil addresses mean the header carried no
+// address information (v1 UNKNOWN, v2 LOCAL or unsupported family) and the
+// connection's own addresses apply.
+func readProxyHeader(r *bufio.Reader) (source, destination net.Addr, err error) {
+	prefix, err := r.Peek(len(proxyV2Signature))
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading header: %w", err)
+	}
+	if bytes.Equal(prefix, proxyV2Signature) {
+		return readProxyV2(r)
+	}
+	if bytes.HasPrefix(prefix, []byte("PROXY ")) {
+		return readProxyV1(r)
+	}
+	return nil, nil, fmt.Errorf("missing PROXY header")
+}
+
+// readProxyV1 parses a text header: "PROXY TCP4 <src> <dst> <srcport> <dstport>\r\n"
+func readProxyV1(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	var line []byte
+	for len(line) < proxyV1MaxLength {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading v1 header: %w", err)
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+	}
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, nil, fmt.Errorf("v1 header is not terminated by CRLF")
+	}
+
+	fields := strings.Fields(string(line[:len(line)-2]))
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, nil, fmt.Errorf("malformed v1 header")
+	}
+
+	source, err := parseV1Address(fields[2], fields[4])
+	if err != nil {
+		return nil, nil, err
+	}
+	destination, err := parseV1Address(fields[3], fields[5])
+	if err != nil {
+		return nil, nil, err
+	}
+	return source, destination, nil
+}
+
+// parseV1Address parses an address and port from a v1 header
+func parseV1Address(ip, port string) (*net.TCPAddr, error) {
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return nil, fmt.Errorf("invalid address %q in v1 header", ip)
+	}
+	parsedPort, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port %q in v1 header", port)
+	}
+	return &net.TCPAddr{IP: parsedIP, Port: int(parsedPort)}, nil
+}
+
+// readProxyV2 parses a binary header; TLVs after the addresses are skipped
+func readProxyV2(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, nil, fmt.Errorf("reading v2 header: %w", err)
+	}
+
+	version, command := header[12]>>4, header[12]&0x0f
+	if version != 2 {
+		return nil, nil, fmt.Errorf("unsupported v2 version %d", version)
+	}
+	family := header[13]
+	length := binary.BigEndian.Uint16(header[14:16])
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, nil, fmt.Errorf("reading v2 addresses: %w", err)
+	}
+
+	switch command {
+	case 0x0: // LOCAL: health checks from the proxy itself
+		return nil, nil, nil
+	case 0x1: // PROXY
+	default:
+		return nil, nil, fmt.Errorf("unsupported v2 command %d", command)
+	}
+
+	switch family {
+	case 0x11: // TCP over IPv4
+		if len(payload) < 12 {
+			return nil, nil, fmt.Errorf("short v2 IPv4 address block")
+		}
+		source := &net.TCPAddr{IP: net.IP(payload[0:4]), Port: int(binary.BigEndian.Uint16(payload[8:10]))}
+		destination := &net.TCPAddr{IP: net.IP(payload[4:8]), Port: int(binary.BigEndian.Uint16(payload[10:12]))}
+		return source, destination, nil
+	case 0x21: // TCP over IPv6
+		if len(payload) < 36 {
+			return nil, nil, fmt.Errorf("short v2 IPv6 address block")
+		}
+		source := &net.TCPAddr{IP: net.IP(payload[0:16]), Port: int(binary.BigEndian.Uint16(payload[32:34]))}
+		destination := &net.TCPAddr{IP: net.IP(payload[16:32]), Port: int(binary.BigEndian.Uint16(payload[34:36]))}
+		return source, destination, nil
+	default:
+		// UDP and Unix families carry no TCP client address
+		return nil, nil, nil
+	}
+}
@@ -0,0 +1,169 @@
+package listener
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadProxyHeader_V1(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("PROXY TCP4 203.0.113.7 10.0.0.1 51234 8080\r\nGET / HTTP/1.1\r\n"))
+
+	source, destination, err := readProxyHeader(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.7:51234", source.String())
+	assert.Equal(t, "10.0.0.1:8080", destination.String())
+
+	rest, _ := io.ReadAll(reader)
+	assert.Equal(t, "GET / HTTP/1.1\r\n", string(rest))
+}
+
+func TestReadProxyHeader_V1IPv6(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("PROXY TCP6 2001:db8::1 2001:db8::2 443 8080\r\n"))
+
+	source, _, err := readProxyHeader(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "[2001:db8::1]:443", source.String())
+}
+
+func TestReadProxyHeader_V1Unknown(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("PROXY UNKNOWN\r\n"))
+
+	source, destination, err := readProxyHeader(reader)
+	require.NoError(t, err)
+	assert.Nil(t, source)
+	assert.Nil(t, destination)
+}
+
+func TestReadProxyHeader_V2(t *testing.T) {
+	header := append([]byte{}, proxyV2Signature...)
+	header = append(header, 0x21, 0x11) // v2 PROXY, TCP over IPv4
+	header = binary.BigEndian.AppendUint16(header, 12+3)
+	header = append(header, 203, 0, 113, 7, 10, 0, 0, 1)
+	header = binary.BigEndian.AppendUint16(header, 51234)
+	header = binary.BigEndian.AppendUint16(header, 8080)
+	header = append(header, 0x04, 0x00, 0x00) // Empty TLV, skipped
+	reader := bufio.NewReader(strings.NewReader(string(header) + "GET"))
+
+	source, destination, err := readProxyHeader(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.7:51234", source.String())
+	assert.Equal(t, "10.0.0.1:8080", destination.String())
+
+	rest, _ := io.ReadAll(reader)
+	assert.Equal(t, "GET", string(rest))
+}
+
+func TestReadProxyHeader_V2Local(t *testing.T) {
+	header := append([]byte{}, proxyV2Signature...)
+	header = append(header, 0x20, 0x00, 0x00, 0x00) // v2 LOCAL, no addresses
+	reader := bufio.NewReader(strings.NewReader(string(header)))
+
+	source, _, err := readProxyHeader(reader)
+	require.NoError(t, err)
+	assert.Nil(t, source)
+}
+
+func TestReadProxyHeader_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "missing header", input: "GET / HTTP/1.1\r\nHost: example.com\r\n"},
+		{name: "unterminated v1", input: "PROXY TCP4 203.0.113.7 10.0.0.1 51234 8080" + strings.Repeat(" ", 100)},
+		{name: "bad v1 address", input: "PROXY TCP4 not-an-ip 10.0.0.1 51234 8080\r\n"},
+		{name: "bad v1 port", input: "PROXY TCP4 203.0.113.7 10.0.0.1 99999 8080\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := readProxyHeader(bufio.NewReader(strings.NewReader(tt.input)))
+			assert.Error(t, err)
+		})
+	}
+}
+
+// TestProxyProtocolListener verifies accepted connections report the client address from the header
+func TestProxyProtocolListener(t *testing.T) {
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ln, err := NewProxyProtocolListener(inner, []string{"127.0.0.0/8"})
+	require.NoError(t, err)
+	defer ln.Close()
+
+	go func() {
+		conn, err := net.Dial("tcp", inner.Addr().String())
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte("PROXY TCP4 203.0.113.7 10.0.0.1 51234 8080\r\nhello"))
+		time.Sleep(100 * time.Millisecond)
+	}()
+
+	conn, err := ln.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	assert.Equal(t, "203.0.113.7:51234", conn.RemoteAddr().String())
+	peer, _, err := net.SplitHostPort(PeerAddr(conn).String())
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", peer)
+	body := make([]byte, 5)
+	_, err = io.ReadFull(conn, body)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(body))
+}
+
+// TestProxyProtocolListener_UntrustedSource verifies headers are not parsed from untrusted peers
+func TestProxyProtocolListener_UntrustedSource(t *testing.T) {
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ln, err := NewProxyProtocolListener(inner, []string{"10.0.0.0/8"})
+	require.NoError(t, err)
+	defer ln.Close()
+
+	go func() {
+		conn, err := net.Dial("tcp", inner.Addr().String())
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte("PROXY TCP4 203.0.113.7 10.0.0.1 51234 8080\r\n"))
+		time.Sleep(100 * time.Millisecond)
+	}()
+
+	conn, err := ln.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", host)
+	assert.Equal(t, conn.RemoteAddr(), PeerAddr(conn))
+}
+
+func TestNewProxyProtocolListener_InvalidCIDR(t *testing.T) {
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer inner.Close()
+
+	_, err = NewProxyProtocolListener(inner, []string{"not-a-cidr"})
+	assert.Error(t, err)
+}
+
+func TestNewProxyProtocolListener_RequiresTrustedCIDRs(t *testing.T) {
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer inner.Close()
+
+	_, err = NewProxyProtocolListener(inner, nil)
+	assert.ErrorContains(t, err, "at least one trusted proxy CIDR")
+}
@@ -0,0 +1,13 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package listener
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// reusePortControl reports that SO_REUSEPORT is unavailable on this platform
+func reusePortControl(network, address string, conn syscall.RawConn) error {
+	return fmt.Errorf("SO_REUSEPORT is not supported on this platform")
+}
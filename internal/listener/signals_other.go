@@ -0,0 +1,8 @@
+//go:build !unix
+
+package listener
+
+import "os"
+
+// RestartSignals is empty where descriptors cannot be passed to a new process
+var RestartSignals []os.Signal
@@ -0,0 +1,11 @@
+//go:build unix
+
+package listener
+
+import (
+	"os"
+	"syscall"
+)
+
+// RestartSignals trigger a zero-downtime restart through Handoff
+var RestartSignals = []os.Signal{syscall.SIGUSR2}
@@ -0,0 +1,26 @@
+package log
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// contextKey is the key type for storing a logger in a context
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the given logger
+func NewContext(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger stored in ctx, or nil if there is none
+func FromContext(ctx context.Context) Logger {
+	logger, _ := ctx.Value(contextKey{}).(Logger)
+	return logger
+}
+
+// NewNop returns a logger that discards all entries
+func NewNop() Logger {
+	return NewZap(zap.NewNop())
+}
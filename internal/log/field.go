@@ -0,0 +1,68 @@
+package log
+
+import "time"
+
+// Field is a key-value pair added to a log entry. Fields with an empty key are
+// skipped, which is how Error represents a nil error.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// String constructs a field with a string value
+func String(key, value string) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Strings constructs a field with a list of strings
+func Strings(key string, values []string) Field {
+	return Field{Key: key, Value: values}
+}
+
+// Int constructs a field with an int value
+func Int(key string, value int) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Int64 constructs a field with an int64 value
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Uint32 constructs a field with a uint32 value
+func Uint32(key string, value uint32) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Uint64 constructs a field with a uint64 value
+func Uint64(key string, value uint64) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Bool constructs a field with a bool value
+func Bool(key string, value bool) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Duration constructs a field with a duration value
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Time constructs a field with a time value
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Any constructs a field with an arbitrary value
+func Any(key string, value any) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Error constructs a field named "error" holding err, or a skipped field if err is nil
+func Error(err error) Field {
+	if err == nil {
+		return Field{}
+	}
+	return Field{Key: "error", Value: err}
+}
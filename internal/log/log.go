@@ -1,45 +1,135 @@
+// Package log provides the structured logger used throughout gatekeeper. Logger is
+// an interface free of any logging library's types, implemented with zap (New, or
+// NewZap to wrap an existing *zap.Logger) and with the standard library's slog
+// (NewSlog), so applications embedding gatekeeper can route its logs into their own
+// slog handlers (JSON, OTLP, ...) without depending on zap. Packages still written
+// against zap or slog get a logger writing through the same implementation from
+// Zap and Slog.
 package log
 
 import (
 	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Logger is a leveled, structured logger. Implementations must be safe for
+// concurrent use; loggers derived with WithFields share the level of their parent.
+type Logger interface {
+	Debug(msg string, fields ...Field)
+	Info(msg string, fields ...Field)
+	Warn(msg string, fields ...Field)
+	Error(msg string, fields ...Field)
+
+	// WithFields returns a logger adding fields to every entry
+	WithFields(fields ...Field) Logger
+
+	// Level returns the current minimum enabled level
+	Level() Level
+
+	// SetLevel changes the minimum enabled level at runtime, for this logger
+	// and every logger derived from it
+	SetLevel(level string) error
+
+	// Close flushes any buffered entries
+	Close() error
+}
+
+// Level is the severity of a log entry
+type Level int8
 
-	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
+// Levels share zap's numbering, so they convert to zapcore.Level directly
+const (
+	DebugLevel Level = iota - 1
+	InfoLevel
+	WarnLevel
+	ErrorLevel
 )
 
-// Logger wraps zap.Logger for consistent logging throughout the application
-type Logger struct {
-	*zap.Logger
+// ParseLevel parses a level name: debug, info, warn or error
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return DebugLevel, nil
+	case "info", "":
+		return InfoLevel, nil
+	case "warn", "warning":
+		return WarnLevel, nil
+	case "error":
+		return ErrorLevel, nil
+	default:
+		return 0, fmt.Errorf("invalid log level: %q", name)
+	}
 }
 
-// New creates a new structured logger with the specified log level
-func New(logLevel string) (*Logger, error) {
-	level, err := zapcore.ParseLevel(logLevel)
-	if err != nil {
-		return nil, fmt.Errorf("invalid log level: %w", err)
+// String returns the level name
+func (l Level) String() string {
+	switch l {
+	case DebugLevel:
+		return "debug"
+	case InfoLevel:
+		return "info"
+	case WarnLevel:
+		return "warn"
+	case ErrorLevel:
+		return "error"
+	default:
+		return fmt.Sprintf("Level(%d)", l)
 	}
+}
+
+// Logging backends selectable with Options.Backend
+const (
+	BackendZap  = "zap"  // zap's production JSON encoder (default)
+	BackendSlog = "slog" // stdlib slog handler
+)
 
-	config := zap.NewProductionConfig()
-	config.Level = zap.NewAtomicLevelAt(level)
-	config.DisableCaller = false
-	config.DisableStacktrace = level != zapcore.DebugLevel
+// Options configures logger construction
+type Options struct {
+	Level   string
+	Backend string // BackendZap (default) or BackendSlog
 
-	logger, err := config.Build(
-		zap.AddCallerSkip(1),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create logger: %w", err)
-	}
+	// Handler receives entries for the slog backend.
+	// Defaults to a JSON handler writing to stderr.
+	Handler slog.Handler
 
-	return &Logger{logger}, nil
+	// Sampling caps repeated entries with the same level and message per second:
+	// the first SamplingInitial are logged, then every SamplingThereafter-th.
+	// SamplingInitial of 0 disables sampling.
+	SamplingInitial    int
+	SamplingThereafter int
 }
 
-// WithFields returns a new logger with additional fields
-func (l *Logger) WithFields(fields ...zap.Field) *Logger {
-	return &Logger{l.With(fields...)}
+// New creates a new structured logger with the specified log level
+func New(logLevel string) (Logger, error) {
+	return NewWithOptions(Options{
+		Level:              logLevel,
+		SamplingInitial:    100,
+		SamplingThereafter: 100,
+	})
 }
 
-// Close flushes any buffered log entries
-func (l *Logger) Close() error {
-	return l.Sync()
+// NewWithOptions creates a new structured logger from the given options
+func NewWithOptions(opts Options) (Logger, error) {
+	switch opts.Backend {
+	case "", BackendZap:
+		return newZapProduction(opts)
+	case BackendSlog:
+		handler := opts.Handler
+		if handler == nil {
+			// Level filtering is done by the logger so SetLevel works; the handler accepts everything
+			handler = slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})
+		}
+		logger, err := newSlogLogger(handler, opts.Level)
+		if err != nil {
+			return nil, err
+		}
+		if opts.SamplingInitial > 0 {
+			logger.sampler = newSampler(opts.SamplingInitial, opts.SamplingThereafter)
+		}
+		return logger, nil
+	default:
+		return nil, fmt.Errorf("unknown log backend: %s", opts.Backend)
+	}
 }
@@ -1,11 +1,11 @@
 package log
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"go.uber.org/zap"
 )
 
 // TestNew_CreatesLoggerWithInfoLevel verifies logger creation with default level
@@ -14,7 +14,7 @@ func TestNew_CreatesLoggerWithInfoLevel(t *testing.T) {
 
 	require.NoError(t, err)
 	require.NotNil(t, logger)
-	assert.NotNil(t, logger.Logger)
+	assert.Equal(t, InfoLevel, logger.Level())
 }
 
 // TestNew_CreatesLoggerWithDebugLevel verifies logger creation with debug level
@@ -53,10 +53,10 @@ func TestNew_FailsWithInvalidLevel(t *testing.T) {
 func TestWithFields_AddsFieldsToLogger(t *testing.T) {
 	logger, _ := New("info")
 
-	withFields := logger.WithFields(zap.String("user_id", "123"))
+	withFields := logger.WithFields(String("user_id", "123"))
 
 	require.NotNil(t, withFields)
-	assert.NotNil(t, withFields.Logger)
+	assert.Equal(t, InfoLevel, withFields.Level())
 }
 
 // TestWithFields_SupportsMultipleFields adds multiple fields
@@ -64,9 +64,9 @@ func TestWithFields_SupportsMultipleFields(t *testing.T) {
 	logger, _ := New("info")
 
 	withFields := logger.WithFields(
-		zap.String("user_id", "123"),
-		zap.String("request_id", "abc"),
-		zap.Int("status_code", 200),
+		String("user_id", "123"),
+		String("request_id", "abc"),
+		Int("status_code", 200),
 	)
 
 	require.NotNil(t, withFields)
@@ -89,9 +89,9 @@ func TestLogger_LoggingMessages(t *testing.T) {
 	defer logger.Close()
 
 	// These should not panic
-	logger.Info("test info message", zap.String("key", "value"))
-	logger.Warn("test warn message", zap.Int("code", 123))
-	logger.Error("test error message", zap.Error(assert.AnError))
+	logger.Info("test info message", String("key", "value"))
+	logger.Warn("test warn message", Int("code", 123))
+	logger.Error("test error message", Error(assert.AnError))
 }
 
 // TestLogger_DebugNotLoggedInInfoLevel verifies debug level filtering
@@ -100,8 +100,8 @@ func TestLogger_DebugNotLoggedInInfoLevel(t *testing.T) {
 	defer logger.Close()
 
 	// Debug should not be logged when level is info
-	logger.Debug("debug message", zap.String("key", "value"))
-	logger.Info("info message", zap.String("key", "value"))
+	logger.Debug("debug message", String("key", "value"))
+	logger.Info("info message", String("key", "value"))
 	// If we got here without panic, test passes
 	assert.True(t, true)
 }
@@ -112,6 +112,48 @@ func TestLogger_DebugLoggedInDebugLevel(t *testing.T) {
 	defer logger.Close()
 
 	// Debug should be logged when level is debug
-	logger.Debug("debug message", zap.String("key", "value"))
+	logger.Debug("debug message", String("key", "value"))
 	assert.True(t, true)
 }
+
+// TestContext_RoundTrip verifies a logger stored in a context can be retrieved
+func TestContext_RoundTrip(t *testing.T) {
+	logger, err := New("info")
+	require.NoError(t, err)
+
+	ctx := NewContext(context.Background(), logger)
+
+	assert.Same(t, logger, FromContext(ctx))
+	assert.Nil(t, FromContext(context.Background()))
+}
+
+// TestSetLevel_ChangesLevelForDerivedLoggers verifies runtime level changes propagate
+func TestSetLevel_ChangesLevelForDerivedLoggers(t *testing.T) {
+	logger, err := New("info")
+	require.NoError(t, err)
+	child := logger.WithFields(String("request_id", "abc"))
+
+	assert.Equal(t, InfoLevel, child.Level())
+
+	require.NoError(t, logger.SetLevel("debug"))
+
+	assert.Equal(t, DebugLevel, logger.Level())
+	assert.Equal(t, DebugLevel, child.Level())
+}
+
+// TestSetLevel_RejectsInvalidLevel verifies invalid levels are rejected
+func TestSetLevel_RejectsInvalidLevel(t *testing.T) {
+	logger, err := New("info")
+	require.NoError(t, err)
+
+	assert.Error(t, logger.SetLevel("verbose"))
+	assert.Error(t, NewNop().SetLevel("debug"))
+}
+
+// TestNewWithOptions_SamplingDisabled verifies sampling can be turned off
+func TestNewWithOptions_SamplingDisabled(t *testing.T) {
+	logger, err := NewWithOptions(Options{Level: "info", SamplingInitial: 0})
+
+	require.NoError(t, err)
+	require.NotNil(t, logger)
+}
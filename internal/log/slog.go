@@ -0,0 +1,329 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// slogLogger is the Logger implementation writing through a slog.Handler
+type slogLogger struct {
+	handler slog.Handler
+	level   *slog.LevelVar // Shared by all loggers derived from NewSlog
+	sampler *sampler       // Shared by all loggers derived from NewWithOptions; nil when not sampling
+}
+
+// NewSlog creates a logger that writes through the given slog handler, so
+// applications embedding gatekeeper can route its logs into their own slog
+// pipeline (JSON, OTLP, ...). The level is applied before the handler's own and
+// can be changed with SetLevel.
+func NewSlog(handler slog.Handler, logLevel string) (Logger, error) {
+	return newSlogLogger(handler, logLevel)
+}
+
+func newSlogLogger(handler slog.Handler, logLevel string) (*slogLogger, error) {
+	level, err := ParseLevel(logLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	levelVar := new(slog.LevelVar)
+	levelVar.Set(toSlogLevel(level))
+	return &slogLogger{handler: handler, level: levelVar}, nil
+}
+
+func (l *slogLogger) Debug(msg string, fields ...Field) {
+	l.log(DebugLevel, msg, fields)
+}
+
+func (l *slogLogger) Info(msg string, fields ...Field) {
+	l.log(InfoLevel, msg, fields)
+}
+
+func (l *slogLogger) Warn(msg string, fields ...Field) {
+	l.log(WarnLevel, msg, fields)
+}
+
+func (l *slogLogger) Error(msg string, fields ...Field) {
+	l.log(ErrorLevel, msg, fields)
+}
+
+func (l *slogLogger) WithFields(fields ...Field) Logger {
+	return &slogLogger{handler: l.handler.WithAttrs(toSlogAttrs(fields)), level: l.level, sampler: l.sampler}
+}
+
+func (l *slogLogger) Level() Level {
+	return fromSlogLevel(l.level.Level())
+}
+
+func (l *slogLogger) SetLevel(logLevel string) error {
+	level, err := ParseLevel(logLevel)
+	if err != nil {
+		return err
+	}
+
+	l.level.Set(toSlogLevel(level))
+	return nil
+}
+
+func (l *slogLogger) Close() error {
+	return nil
+}
+
+// enabled reports whether entries at level pass both the logger's and the handler's level
+func (l *slogLogger) enabled(level Level) bool {
+	return toSlogLevel(level) >= l.level.Level() && l.handler.Enabled(context.Background(), toSlogLevel(level))
+}
+
+// log writes an entry reported as coming from the caller of the Logger method
+func (l *slogLogger) log(level Level, msg string, fields []Field) {
+	if !l.enabled(level) {
+		return
+	}
+	var caller string
+	if _, file, line, ok := runtime.Caller(2); ok {
+		caller = trimCallerPath(file, line)
+	}
+	l.writeEntry(entry{level: level, time: time.Now(), caller: caller, message: msg, fields: fields})
+}
+
+func (l *slogLogger) writeEntry(e entry) {
+	if !l.enabled(e.level) || (l.sampler != nil && !l.sampler.allow(e.level, e.message, e.time)) {
+		return
+	}
+	record := slog.NewRecord(e.time, toSlogLevel(e.level), e.message, 0)
+	if e.caller != "" {
+		record.AddAttrs(slog.String("caller", e.caller))
+	}
+	record.AddAttrs(toSlogAttrs(e.fields)...)
+	_ = l.handler.Handle(context.Background(), record)
+}
+
+// trimCallerPath formats a caller as its package directory, file and line,
+// matching the callers zap reports
+func trimCallerPath(file string, line int) string {
+	if i := strings.LastIndexByte(file, '/'); i >= 0 {
+		if j := strings.LastIndexByte(file[:i], '/'); j >= 0 {
+			file = file[j+1:]
+		}
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+// sampler caps repeated entries with the same level and message per second,
+// like zap's sampling: the first initial are logged, then every thereafter-th
+type sampler struct {
+	initial    int
+	thereafter int
+
+	mu     sync.Mutex
+	tick   time.Time
+	counts map[samplerKey]int
+}
+
+// samplerKey identifies entries counted together
+type samplerKey struct {
+	level   Level
+	message string
+}
+
+func newSampler(initial, thereafter int) *sampler {
+	return &sampler{initial: initial, thereafter: thereafter, counts: make(map[samplerKey]int)}
+}
+
+// allow counts an entry logged at now and reports whether it should be written
+func (s *sampler) allow(level Level, message string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.tick) >= time.Second {
+		s.tick = now
+		clear(s.counts)
+	}
+	key := samplerKey{level: level, message: message}
+	s.counts[key]++
+	n := s.counts[key]
+	if n <= s.initial {
+		return true
+	}
+	return s.thereafter > 0 && (n-s.initial)%s.thereafter == 0
+}
+
+// Slog returns a *slog.Logger writing through logger, for libraries that only
+// accept the standard library logger
+func Slog(logger Logger) *slog.Logger {
+	if l, ok := logger.(*zapLogger); ok {
+		return slog.New(&zapHandler{logger: l.base})
+	}
+	return slog.New(&loggerHandler{logger: logger})
+}
+
+// toSlogAttrs converts fields to slog attributes, dropping skipped ones
+func toSlogAttrs(fields []Field) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, field := range fields {
+		if field.Key == "" {
+			continue
+		}
+		attrs = append(attrs, slog.Any(field.Key, field.Value))
+	}
+	return attrs
+}
+
+// toSlogLevel maps a level to the slog level of the same name
+func toSlogLevel(level Level) slog.Level {
+	switch {
+	case level <= DebugLevel:
+		return slog.LevelDebug
+	case level == InfoLevel:
+		return slog.LevelInfo
+	case level == WarnLevel:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+// fromSlogLevel maps a slog level to the closest level
+func fromSlogLevel(level slog.Level) Level {
+	switch {
+	case level < slog.LevelInfo:
+		return DebugLevel
+	case level < slog.LevelWarn:
+		return InfoLevel
+	case level < slog.LevelError:
+		return WarnLevel
+	default:
+		return ErrorLevel
+	}
+}
+
+// loggerHandler is a slog.Handler writing records to a Logger. Attributes of a
+// group are added under keys prefixed with the group name.
+type loggerHandler struct {
+	logger Logger
+	prefix string // Group names of WithGroup, each followed by a dot
+}
+
+func (h *loggerHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return fromSlogLevel(level) >= h.logger.Level()
+}
+
+func (h *loggerHandler) Handle(_ context.Context, record slog.Record) error {
+	fields := make([]Field, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		fields = appendAttrFields(fields, h.prefix, attr)
+		return true
+	})
+
+	var caller string
+	if record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		caller = trimCallerPath(frame.File, frame.Line)
+	}
+	writeEntry(h.logger, entry{
+		level:   fromSlogLevel(record.Level),
+		time:    record.Time,
+		caller:  caller,
+		message: record.Message,
+		fields:  fields,
+	})
+	return nil
+}
+
+func (h *loggerHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var fields []Field
+	for _, attr := range attrs {
+		fields = appendAttrFields(fields, h.prefix, attr)
+	}
+	return &loggerHandler{logger: h.logger.WithFields(fields...), prefix: h.prefix}
+}
+
+func (h *loggerHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &loggerHandler{logger: h.logger, prefix: h.prefix + name + "."}
+}
+
+// appendAttrFields appends attr as fields with keys under prefix, flattening groups
+func appendAttrFields(fields []Field, prefix string, attr slog.Attr) []Field {
+	value := attr.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if attr.Key != "" {
+			groupPrefix += attr.Key + "."
+		}
+		for _, member := range value.Group() {
+			fields = appendAttrFields(fields, groupPrefix, member)
+		}
+		return fields
+	}
+	if attr.Key == "" {
+		return fields
+	}
+	return append(fields, Field{Key: prefix + attr.Key, Value: value.Any()})
+}
+
+// zapHandler is a slog.Handler that writes records to a zap logger, nesting groups
+type zapHandler struct {
+	logger *zap.Logger
+}
+
+func (h *zapHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.Core().Enabled(zapcore.Level(fromSlogLevel(level)))
+}
+
+func (h *zapHandler) Handle(_ context.Context, record slog.Record) error {
+	checked := h.logger.Check(zapcore.Level(fromSlogLevel(record.Level)), record.Message)
+	if checked == nil {
+		return nil
+	}
+	checked.Time = record.Time
+	if record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		checked.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+	}
+
+	fields := make([]zap.Field, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		fields = append(fields, attrToField(attr))
+		return true
+	})
+	checked.Write(fields...)
+	return nil
+}
+
+func (h *zapHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make([]zap.Field, len(attrs))
+	for i, attr := range attrs {
+		fields[i] = attrToField(attr)
+	}
+	return &zapHandler{logger: h.logger.With(fields...)}
+}
+
+func (h *zapHandler) WithGroup(name string) slog.Handler {
+	return &zapHandler{logger: h.logger.With(zap.Namespace(name))}
+}
+
+// attrToField converts a slog attribute to a zap field
+func attrToField(attr slog.Attr) zap.Field {
+	value := attr.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		group := value.Group()
+		return zap.Object(attr.Key, zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			for _, member := range group {
+				attrToField(member).AddTo(enc)
+			}
+			return nil
+		}))
+	}
+	return zap.Any(attr.Key, value.Any())
+}
@@ -0,0 +1,132 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// TestNewSlog_WritesThroughHandler verifies entries reach the slog handler
+func TestNewSlog_WritesThroughHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewSlog(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}), "info")
+	require.NoError(t, err)
+
+	logger.WithFields(String("request_id", "abc")).Warn("key revoked",
+		Int64("key_id", 42),
+		Error(errors.New("boom")),
+		Error(nil),
+	)
+	logger.Debug("suppressed")
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "WARN", entry["level"])
+	assert.Equal(t, "key revoked", entry["msg"])
+	assert.Equal(t, "abc", entry["request_id"])
+	assert.Equal(t, float64(42), entry["key_id"])
+	assert.Equal(t, "boom", entry["error"])
+	assert.Contains(t, entry["caller"], "log/slog_test.go:")
+	assert.NotContains(t, buf.String(), "suppressed")
+}
+
+// TestNewSlog_SetLevel verifies the slog backend honours runtime level changes
+func TestNewSlog_SetLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewSlog(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}), "info")
+	require.NoError(t, err)
+	child := logger.WithFields(String("component", "siwe"))
+
+	require.NoError(t, logger.SetLevel("debug"))
+	child.Debug("now visible")
+
+	assert.Equal(t, DebugLevel, child.Level())
+	assert.Contains(t, buf.String(), "now visible")
+}
+
+// TestNewWithOptions_SlogSampling verifies repeated entries are sampled on the slog backend
+func TestNewWithOptions_SlogSampling(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewWithOptions(Options{
+		Level:              "info",
+		Backend:            BackendSlog,
+		Handler:            slog.NewJSONHandler(&buf, nil),
+		SamplingInitial:    2,
+		SamplingThereafter: 3,
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 8; i++ {
+		logger.Info("repeated")
+	}
+	logger.Info("other")
+
+	assert.Equal(t, 4, strings.Count(buf.String(), `"repeated"`))
+	assert.Contains(t, buf.String(), `"other"`)
+}
+
+// TestNewWithOptions_UnknownBackend verifies unknown backends are rejected
+func TestNewWithOptions_UnknownBackend(t *testing.T) {
+	_, err := NewWithOptions(Options{Level: "info", Backend: "logrus"})
+
+	assert.Error(t, err)
+}
+
+// TestZap_WritesThroughSlogBackend verifies packages written against zap reach the slog handler
+func TestZap_WritesThroughSlogBackend(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewSlog(slog.NewJSONHandler(&buf, nil), "info")
+	require.NoError(t, err)
+
+	Zap(logger).With(zap.String("component", "policy")).Info("rule evaluated", zap.Int("rules", 3))
+	Zap(logger).Debug("suppressed")
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "rule evaluated", entry["msg"])
+	assert.Equal(t, "policy", entry["component"])
+	assert.Equal(t, float64(3), entry["rules"])
+	assert.Contains(t, entry["caller"], "log/slog_test.go:")
+	assert.NotContains(t, buf.String(), "suppressed")
+}
+
+// TestSlog_WritesThroughZapBackend verifies the slog adapter writes into the zap core
+func TestSlog_WritesThroughZapBackend(t *testing.T) {
+	core, observed := observer.New(zapcore.InfoLevel)
+	logger := NewZap(zap.New(core))
+
+	Slog(logger).With("component", "siwe").WithGroup("nonce").Info("issued", "ttl_seconds", 300)
+	Slog(logger).Debug("suppressed")
+
+	entries := observed.All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "issued", entries[0].Message)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "siwe", fields["component"])
+	assert.Equal(t, map[string]interface{}{"ttl_seconds": int64(300)}, fields["nonce"])
+}
+
+// TestSlog_WritesThroughSlogBackend verifies the slog adapter honours the logger's level
+func TestSlog_WritesThroughSlogBackend(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewSlog(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}), "info")
+	require.NoError(t, err)
+
+	Slog(logger).WithGroup("nonce").Info("issued", "ttl_seconds", 300)
+	Slog(logger).Debug("suppressed")
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "issued", entry["msg"])
+	assert.Equal(t, float64(300), entry["nonce.ttl_seconds"])
+	assert.NotContains(t, buf.String(), "suppressed")
+}
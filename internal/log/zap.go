@@ -0,0 +1,219 @@
+package log
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// zapLogger is the Logger implementation writing through zap
+type zapLogger struct {
+	base   *zap.Logger     // Reports the caller of its own methods; handed out by Zap
+	logger *zap.Logger     // base skipping the zapLogger frame, so callers of Logger methods are reported
+	level  zap.AtomicLevel // Shared by all loggers derived from New; unset for wrapped loggers
+}
+
+// NewZap returns a Logger writing through an existing zap logger. Its level is
+// fixed by the logger's core, so SetLevel fails.
+func NewZap(logger *zap.Logger) Logger {
+	return newZapLogger(logger, zap.AtomicLevel{})
+}
+
+func newZapLogger(base *zap.Logger, level zap.AtomicLevel) *zapLogger {
+	return &zapLogger{base: base, logger: base.WithOptions(zap.AddCallerSkip(1)), level: level}
+}
+
+// newZapProduction builds the default backend: zap's production JSON encoder
+func newZapProduction(opts Options) (Logger, error) {
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	config := zap.NewProductionConfig()
+	config.Level = zap.NewAtomicLevelAt(zapcore.Level(level))
+	config.DisableCaller = false
+	config.DisableStacktrace = level != DebugLevel
+
+	config.Sampling = nil
+	if opts.SamplingInitial > 0 {
+		config.Sampling = &zap.SamplingConfig{
+			Initial:    opts.SamplingInitial,
+			Thereafter: opts.SamplingThereafter,
+		}
+	}
+
+	logger, err := config.Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create logger: %w", err)
+	}
+
+	return newZapLogger(logger, config.Level), nil
+}
+
+func (l *zapLogger) Debug(msg string, fields ...Field) {
+	if ce := l.logger.Check(zapcore.DebugLevel, msg); ce != nil {
+		ce.Write(toZapFields(fields)...)
+	}
+}
+
+func (l *zapLogger) Info(msg string, fields ...Field) {
+	if ce := l.logger.Check(zapcore.InfoLevel, msg); ce != nil {
+		ce.Write(toZapFields(fields)...)
+	}
+}
+
+func (l *zapLogger) Warn(msg string, fields ...Field) {
+	if ce := l.logger.Check(zapcore.WarnLevel, msg); ce != nil {
+		ce.Write(toZapFields(fields)...)
+	}
+}
+
+func (l *zapLogger) Error(msg string, fields ...Field) {
+	if ce := l.logger.Check(zapcore.ErrorLevel, msg); ce != nil {
+		ce.Write(toZapFields(fields)...)
+	}
+}
+
+func (l *zapLogger) WithFields(fields ...Field) Logger {
+	return newZapLogger(l.base.With(toZapFields(fields)...), l.level)
+}
+
+func (l *zapLogger) Level() Level {
+	return fromZapLevel(l.base.Level())
+}
+
+func (l *zapLogger) SetLevel(logLevel string) error {
+	if l.level == (zap.AtomicLevel{}) {
+		return fmt.Errorf("log level is not adjustable for this logger")
+	}
+
+	level, err := ParseLevel(logLevel)
+	if err != nil {
+		return err
+	}
+
+	l.level.SetLevel(zapcore.Level(level))
+	return nil
+}
+
+func (l *zapLogger) Close() error {
+	return l.base.Sync()
+}
+
+// Zap returns a *zap.Logger writing through logger, for packages written against zap.
+// Loggers of the zap backend hand out their own; others get a zap core forwarding
+// every entry to logger.
+func Zap(logger Logger) *zap.Logger {
+	if l, ok := logger.(*zapLogger); ok {
+		return l.base
+	}
+	return zap.New(&loggerCore{logger: logger}, zap.AddCaller())
+}
+
+// toZapFields converts fields to zap fields, dropping skipped ones
+func toZapFields(fields []Field) []zap.Field {
+	converted := make([]zap.Field, 0, len(fields))
+	for _, field := range fields {
+		if field.Key == "" {
+			continue
+		}
+		converted = append(converted, zap.Any(field.Key, field.Value))
+	}
+	return converted
+}
+
+// fromZapFields converts zap fields to fields, preserving field order
+func fromZapFields(fields []zapcore.Field) []Field {
+	converted := make([]Field, 0, len(fields))
+	for _, field := range fields {
+		encoder := zapcore.NewMapObjectEncoder()
+		field.AddTo(encoder)
+		for key, value := range encoder.Fields {
+			converted = append(converted, Field{Key: key, Value: value})
+		}
+	}
+	return converted
+}
+
+// fromZapLevel maps a zap level to the closest level
+func fromZapLevel(level zapcore.Level) Level {
+	if level > zapcore.ErrorLevel {
+		return ErrorLevel
+	}
+	return Level(level)
+}
+
+// entryWriter is implemented by loggers that can write an entry with the time
+// and caller recorded by a bridge, rather than those of the bridge itself
+type entryWriter interface {
+	writeEntry(entry entry)
+}
+
+// entry is a log entry handed over by a bridge
+type entry struct {
+	level   Level
+	time    time.Time
+	caller  string
+	message string
+	fields  []Field
+}
+
+// writeEntry writes e through logger, keeping its time and caller when supported
+func writeEntry(logger Logger, e entry) {
+	if w, ok := logger.(entryWriter); ok {
+		w.writeEntry(e)
+		return
+	}
+	switch e.level {
+	case DebugLevel:
+		logger.Debug(e.message, e.fields...)
+	case InfoLevel:
+		logger.Info(e.message, e.fields...)
+	case WarnLevel:
+		logger.Warn(e.message, e.fields...)
+	default:
+		logger.Error(e.message, e.fields...)
+	}
+}
+
+// loggerCore is a zapcore.Core forwarding entries to a Logger
+type loggerCore struct {
+	logger Logger
+}
+
+func (c *loggerCore) Enabled(level zapcore.Level) bool {
+	return fromZapLevel(level) >= c.logger.Level()
+}
+
+func (c *loggerCore) With(fields []zapcore.Field) zapcore.Core {
+	return &loggerCore{logger: c.logger.WithFields(fromZapFields(fields)...)}
+}
+
+func (c *loggerCore) Check(e zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(e.Level) {
+		return checked.AddCore(e, c)
+	}
+	return checked
+}
+
+func (c *loggerCore) Write(e zapcore.Entry, fields []zapcore.Field) error {
+	var caller string
+	if e.Caller.Defined {
+		caller = e.Caller.TrimmedPath()
+	}
+	writeEntry(c.logger, entry{
+		level:   fromZapLevel(e.Level),
+		time:    e.Time,
+		caller:  caller,
+		message: e.Message,
+		fields:  fromZapFields(fields),
+	})
+	return nil
+}
+
+func (c *loggerCore) Sync() error {
+	return c.logger.Close()
+}
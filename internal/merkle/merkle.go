@@ -0,0 +1,166 @@
+// Package merkle builds Merkle trees of Ethereum addresses that contracts can verify
+// with OpenZeppelin's MerkleProof library. Each leaf is
+// keccak256(abi.encodePacked(address)), the hash a contract computes for
+// msg.sender, and each parent hashes its two children in sorted order, so proofs
+// carry no left/right flags:
+//
+//	bytes32 leaf = keccak256(abi.encodePacked(msg.sender));
+//	require(MerkleProof.verify(proof, root, leaf), "not allowlisted");
+//
+// Leaves are sorted before the tree is built, so the root depends only on the set
+// of addresses. A node without a sibling is carried up to the next level unchanged.
+package merkle
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/yourusername/gatekeeper/internal/common"
+)
+
+// Tree is a Merkle tree of addresses
+type Tree struct {
+	// levels holds the nodes of each level, leaves first and the root last
+	levels [][][]byte
+	// index maps normalized addresses to the position of their leaf
+	index map[string]int
+}
+
+// NewAddressTree builds the tree of the given addresses. Addresses are normalized
+// and duplicates are ignored; at least one address is required.
+func NewAddressTree(addresses []string) (*Tree, error) {
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("merkle tree requires at least one address")
+	}
+
+	hashes := make(map[string][]byte, len(addresses))
+	for _, raw := range addresses {
+		address, err := common.NormalizeAddress(raw)
+		if err != nil {
+			return nil, err
+		}
+		hashes[address] = LeafHash(address)
+	}
+
+	sorted := make([]string, 0, len(hashes))
+	for address := range hashes {
+		sorted = append(sorted, address)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(hashes[sorted[i]], hashes[sorted[j]]) < 0
+	})
+
+	tree := &Tree{index: make(map[string]int, len(sorted))}
+	leaves := make([][]byte, len(sorted))
+	for i, address := range sorted {
+		leaves[i] = hashes[address]
+		tree.index[address] = i
+	}
+
+	tree.levels = [][][]byte{leaves}
+	for level := leaves; len(level) > 1; {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, hashPair(level[i], level[i+1]))
+		}
+		tree.levels = append(tree.levels, next)
+		level = next
+	}
+	return tree, nil
+}
+
+// Root returns the 0x-prefixed hex root of the tree
+func (t *Tree) Root() string {
+	return encode(t.levels[len(t.levels)-1][0])
+}
+
+// Len returns the number of distinct addresses in the tree
+func (t *Tree) Len() int {
+	return len(t.levels[0])
+}
+
+// Proof returns the 0x-prefixed hex sibling hashes proving that address is in the
+// tree, from the leaf up. It reports false if the address is not in the tree.
+func (t *Tree) Proof(address string) ([]string, bool) {
+	normalized, err := common.NormalizeAddress(address)
+	if err != nil {
+		return nil, false
+	}
+	position, ok := t.index[normalized]
+	if !ok {
+		return nil, false
+	}
+
+	proof := []string{}
+	for _, level := range t.levels[:len(t.levels)-1] {
+		sibling := position ^ 1
+		if sibling < len(level) {
+			proof = append(proof, encode(level[sibling]))
+		}
+		position /= 2
+	}
+	return proof, true
+}
+
+// Proofs returns the proof of every address in the tree, keyed by the normalized address
+func (t *Tree) Proofs() map[string][]string {
+	proofs := make(map[string][]string, len(t.index))
+	for address := range t.index {
+		proofs[address], _ = t.Proof(address)
+	}
+	return proofs
+}
+
+// Verify reports whether proof shows that address is in the tree with the given
+// root, as MerkleProof.verify does on chain
+func Verify(root string, address string, proof []string) bool {
+	normalized, err := common.NormalizeAddress(address)
+	if err != nil {
+		return false
+	}
+
+	hash := LeafHash(normalized)
+	for _, sibling := range proof {
+		siblingHash, err := decode(sibling)
+		if err != nil {
+			return false
+		}
+		hash = hashPair(hash, siblingHash)
+	}
+	return encode(hash) == root
+}
+
+// LeafHash returns keccak256(abi.encodePacked(address)) of a normalized address
+func LeafHash(address string) []byte {
+	raw, _ := hex.DecodeString(address[2:])
+	return crypto.Keccak256(raw)
+}
+
+// hashPair hashes two nodes in sorted order
+func hashPair(a, b []byte) []byte {
+	if bytes.Compare(a, b) > 0 {
+		a, b = b, a
+	}
+	return crypto.Keccak256(a, b)
+}
+
+// encode formats a hash as 0x-prefixed hex
+func encode(hash []byte) string {
+	return "0x" + hex.EncodeToString(hash)
+}
+
+// decode parses a 0x-prefixed 32-byte hex hash
+func decode(s string) ([]byte, error) {
+	if len(s) != 66 || s[:2] != "0x" {
+		return nil, fmt.Errorf("invalid hash %q", s)
+	}
+	return hex.DecodeString(s[2:])
+}
@@ -0,0 +1,105 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	addrA = "0x1111111111111111111111111111111111111111"
+	addrB = "0x2222222222222222222222222222222222222222"
+	addrC = "0x3333333333333333333333333333333333333333"
+)
+
+func TestLeafHash(t *testing.T) {
+	raw, _ := hex.DecodeString(addrA[2:])
+	assert.Equal(t, crypto.Keccak256(raw), LeafHash(addrA))
+	assert.Len(t, LeafHash(addrA), 32)
+}
+
+func TestNewAddressTree_SingleAddress(t *testing.T) {
+	tree, err := NewAddressTree([]string{addrA})
+	require.NoError(t, err)
+
+	assert.Equal(t, "0x"+hex.EncodeToString(LeafHash(addrA)), tree.Root())
+	proof, ok := tree.Proof(addrA)
+	require.True(t, ok)
+	assert.Empty(t, proof)
+	assert.True(t, Verify(tree.Root(), addrA, proof))
+}
+
+func TestNewAddressTree_TwoAddresses(t *testing.T) {
+	tree, err := NewAddressTree([]string{addrA, addrB})
+	require.NoError(t, err)
+
+	a, b := LeafHash(addrA), LeafHash(addrB)
+	if bytes.Compare(a, b) > 0 {
+		a, b = b, a
+	}
+	assert.Equal(t, "0x"+hex.EncodeToString(crypto.Keccak256(a, b)), tree.Root())
+
+	proof, ok := tree.Proof(addrA)
+	require.True(t, ok)
+	assert.Equal(t, []string{"0x" + hex.EncodeToString(LeafHash(addrB))}, proof)
+}
+
+// TestNewAddressTree_Proofs checks every proof of odd and even sized trees
+func TestNewAddressTree_Proofs(t *testing.T) {
+	for _, n := range []int{3, 4, 7, 33} {
+		t.Run(fmt.Sprintf("%d addresses", n), func(t *testing.T) {
+			addresses := make([]string, n)
+			for i := range addresses {
+				addresses[i] = fmt.Sprintf("0x%040x", i+1)
+			}
+
+			tree, err := NewAddressTree(addresses)
+			require.NoError(t, err)
+			assert.Equal(t, n, tree.Len())
+
+			proofs := tree.Proofs()
+			require.Len(t, proofs, n)
+			for address, proof := range proofs {
+				assert.True(t, Verify(tree.Root(), address, proof), address)
+			}
+		})
+	}
+}
+
+func TestNewAddressTree_OrderAndCase(t *testing.T) {
+	tree, err := NewAddressTree([]string{addrA, addrB, addrC})
+	require.NoError(t, err)
+
+	// Order, case and duplicates do not change the root
+	reordered, err := NewAddressTree([]string{addrC, "0X2222222222222222222222222222222222222222", addrA, addrC})
+	require.NoError(t, err)
+	assert.Equal(t, tree.Root(), reordered.Root())
+	assert.Equal(t, 3, reordered.Len())
+}
+
+func TestNewAddressTree_Invalid(t *testing.T) {
+	_, err := NewAddressTree(nil)
+	assert.Error(t, err)
+
+	_, err = NewAddressTree([]string{addrA, "0x123"})
+	assert.Error(t, err)
+}
+
+func TestVerify_Rejects(t *testing.T) {
+	tree, err := NewAddressTree([]string{addrA, addrB, addrC})
+	require.NoError(t, err)
+	proof, _ := tree.Proof(addrA)
+
+	assert.False(t, Verify(tree.Root(), addrB, proof), "proof of another address")
+	assert.False(t, Verify(tree.Root(), addrA, proof[:len(proof)-1]), "truncated proof")
+	assert.False(t, Verify(tree.Root(), addrA, []string{"0x12"}), "malformed hash")
+	assert.False(t, Verify(tree.Root(), "not an address", proof))
+
+	_, ok := tree.Proof("0x4444444444444444444444444444444444444444")
+	assert.False(t, ok)
+}
@@ -0,0 +1,126 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/gatekeeper/internal/jobs"
+	"github.com/yourusername/gatekeeper/internal/store"
+	"go.uber.org/zap"
+)
+
+// KeyExpiryJob periodically tells the owners of API keys that their keys are about
+// to expire. Each key is notified once; a key whose notification fails on every
+// notifier is retried on the next run.
+type KeyExpiryJob struct {
+	repo      store.KeyExpiryRepositoryInterface
+	notifiers []Notifier
+	horizon   time.Duration
+	interval  time.Duration
+	logger    *zap.Logger
+	now       func() time.Time
+	job       *jobs.Job
+}
+
+// NewKeyExpiryJob creates a job that every interval notifies owners of keys
+// expiring within horizon through every notifier
+func NewKeyExpiryJob(repo store.KeyExpiryRepositoryInterface, notifiers []Notifier, horizon, interval time.Duration, logger *zap.Logger) *KeyExpiryJob {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &KeyExpiryJob{
+		repo:      repo,
+		notifiers: notifiers,
+		horizon:   horizon,
+		interval:  interval,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// Start runs the job immediately and then every interval until Stop is called
+func (j *KeyExpiryJob) Start() {
+	j.job = jobs.Every("key expiry notification", j.interval, j.runOnce, j.logger)
+}
+
+// Stop stops the job, waiting for a run in progress to be cancelled
+func (j *KeyExpiryJob) Stop() {
+	j.job.Stop()
+}
+
+// runOnce runs the job once, logging what it did
+func (j *KeyExpiryJob) runOnce(ctx context.Context) error {
+	notified, err := j.Run(ctx)
+	if err != nil {
+		return err
+	}
+	if notified > 0 {
+		j.logger.Info("notified owners of expiring API keys", zap.Int("count", notified))
+	}
+	return nil
+}
+
+// Run notifies the owners of keys expiring within the horizon once, returning the
+// number of keys notified. Keys no notifier could deliver are left for the next run.
+func (j *KeyExpiryJob) Run(ctx context.Context) (int, error) {
+	now := j.now()
+	keys, err := j.repo.ListExpiringKeys(ctx, now.Add(j.horizon))
+	if err != nil {
+		return 0, err
+	}
+
+	notified := 0
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return notified, err
+		}
+
+		if !j.notify(ctx, key, now) {
+			continue
+		}
+
+		if err := j.repo.MarkExpiryNotified(ctx, key.ID); err != nil {
+			j.logger.Error("failed to record key expiry notification", zap.Int64("key_id", key.ID), zap.Error(err))
+			continue
+		}
+		notified++
+	}
+	return notified, nil
+}
+
+// notify delivers the notification of key to every notifier, reporting whether at
+// least one of them succeeded
+func (j *KeyExpiryJob) notify(ctx context.Context, key store.ExpiringAPIKey, now time.Time) bool {
+	notification := expiryNotification(key, now)
+	delivered := false
+	for _, notifier := range j.notifiers {
+		if err := notifier.Notify(ctx, notification); err != nil {
+			j.logger.Warn("failed to deliver key expiry notification",
+				zap.Int64("key_id", key.ID),
+				zap.String("owner", key.OwnerAddress),
+				zap.Error(err))
+			continue
+		}
+		delivered = true
+	}
+	return delivered
+}
+
+// expiryNotification describes a key about to expire
+func expiryNotification(key store.ExpiringAPIKey, now time.Time) Notification {
+	remaining := key.ExpiresAt.Sub(now).Round(time.Minute)
+	return Notification{
+		Event:     EventAPIKeyExpiring,
+		Recipient: key.OwnerAddress,
+		Subject:   fmt.Sprintf("API key %q expires soon", key.Name),
+		Message: fmt.Sprintf("The API key %q (ID %d) expires at %s, in %s. Create a replacement key and update your clients before then to avoid failed requests.",
+			key.Name, key.ID, key.ExpiresAt.UTC().Format(time.RFC3339), remaining),
+		Data: map[string]interface{}{
+			"keyId":     key.ID,
+			"keyName":   key.Name,
+			"expiresAt": key.ExpiresAt.UTC(),
+		},
+		CreatedAt: now.UTC(),
+	}
+}
@@ -0,0 +1,94 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+type fakeExpiryRepo struct {
+	keys   []store.ExpiringAPIKey
+	before time.Time
+	marked []int64
+}
+
+func (r *fakeExpiryRepo) ListExpiringKeys(ctx context.Context, before time.Time) ([]store.ExpiringAPIKey, error) {
+	r.before = before
+	return r.keys, nil
+}
+
+func (r *fakeExpiryRepo) MarkExpiryNotified(ctx context.Context, id int64) error {
+	r.marked = append(r.marked, id)
+	return nil
+}
+
+type fakeNotifier struct {
+	sent []Notification
+	err  error
+}
+
+func (n *fakeNotifier) Notify(ctx context.Context, notification Notification) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+func TestKeyExpiryJob_NotifiesAndMarksKeys(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	repo := &fakeExpiryRepo{keys: []store.ExpiringAPIKey{
+		{ID: 1, Name: "ci", ExpiresAt: now.Add(2 * time.Hour), OwnerAddress: "0x1111111111111111111111111111111111111111"},
+		{ID: 2, Name: "deploy", ExpiresAt: now.Add(48 * time.Hour), OwnerAddress: "0x2222222222222222222222222222222222222222"},
+	}}
+	notifier := &fakeNotifier{}
+	job := NewKeyExpiryJob(repo, []Notifier{notifier}, 72*time.Hour, time.Hour, nil)
+	job.now = func() time.Time { return now }
+
+	notified, err := job.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, notified)
+	assert.Equal(t, now.Add(72*time.Hour), repo.before)
+	assert.Equal(t, []int64{1, 2}, repo.marked)
+
+	require.Len(t, notifier.sent, 2)
+	first := notifier.sent[0]
+	assert.Equal(t, EventAPIKeyExpiring, first.Event)
+	assert.Equal(t, "0x1111111111111111111111111111111111111111", first.Recipient)
+	assert.Contains(t, first.Message, "2026-10-17T14:00:00Z")
+	assert.Equal(t, int64(1), first.Data["keyId"])
+}
+
+func TestKeyExpiryJob_MarksKeyWhenAnyNotifierSucceeds(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	repo := &fakeExpiryRepo{keys: []store.ExpiringAPIKey{{ID: 7, Name: "ci", ExpiresAt: now.Add(time.Hour)}}}
+	failing := &fakeNotifier{err: errors.New("webhook down")}
+	working := &fakeNotifier{}
+
+	job := NewKeyExpiryJob(repo, []Notifier{failing, working}, 72*time.Hour, time.Hour, nil)
+	job.now = func() time.Time { return now }
+
+	notified, err := job.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, notified)
+	assert.Equal(t, []int64{7}, repo.marked)
+	assert.Len(t, working.sent, 1)
+}
+
+func TestKeyExpiryJob_LeavesUndeliveredKeysForNextRun(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	repo := &fakeExpiryRepo{keys: []store.ExpiringAPIKey{{ID: 7, Name: "ci", ExpiresAt: now.Add(time.Hour)}}}
+
+	job := NewKeyExpiryJob(repo, []Notifier{&fakeNotifier{err: errors.New("webhook down")}}, 72*time.Hour, time.Hour, nil)
+	job.now = func() time.Time { return now }
+
+	notified, err := job.Run(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, notified)
+	assert.Empty(t, repo.marked)
+}
@@ -0,0 +1,30 @@
+// Package notify delivers notifications to key owners and operators through
+// webhooks and email.
+package notify
+
+import (
+	"context"
+	"time"
+)
+
+// Notification events
+const (
+	EventAPIKeyExpiring = "api_key.expiring"
+	EventWalletLinked   = "wallet.linked"
+)
+
+// Notification is a message about a wallet's resources. Webhook receivers get it
+// as JSON; email sinks send Subject and Message.
+type Notification struct {
+	Event     string                 `json:"event"`
+	Recipient string                 `json:"recipient"` // Wallet address the notification is for
+	Subject   string                 `json:"subject"`
+	Message   string                 `json:"message"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	CreatedAt time.Time              `json:"createdAt"`
+}
+
+// Notifier delivers notifications
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
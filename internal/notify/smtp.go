@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPNotifier emails notifications to a fixed list of recipients. Wallet users
+// have no email address, so this is a sink for operators or a support mailbox
+// that forwards to owners; the wallet is named in each message.
+type SMTPNotifier struct {
+	addr string // host:port
+	auth smtp.Auth
+	from string
+	to   []string
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPNotifier creates a notifier sending through the server at addr. PLAIN
+// authentication is used when username is set.
+func NewSMTPNotifier(addr, username, password, from string, to []string) *SMTPNotifier {
+	var auth smtp.Auth
+	if username != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPNotifier{
+		addr: addr,
+		auth: auth,
+		from: from,
+		to:   to,
+		send: smtp.SendMail,
+	}
+}
+
+// Notify emails n to the configured recipients
+func (s *SMTPNotifier) Notify(ctx context.Context, n Notification) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := s.send(s.addr, s.auth, s.from, s.to, s.message(n)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// message builds a plain text email for n
+func (s *SMTPNotifier) message(n Notification) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", headerValue(s.from))
+	fmt.Fprintf(&b, "To: %s\r\n", headerValue(strings.Join(s.to, ", ")))
+	fmt.Fprintf(&b, "Subject: %s\r\n", headerValue(n.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", n.CreatedAt.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	fmt.Fprintf(&b, "%s: %s\r\n", EventHeader, headerValue(n.Event))
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(n.Message, "\n", "\r\n"))
+	fmt.Fprintf(&b, "\r\n\r\nWallet: %s\r\n", n.Recipient)
+	return []byte(b.String())
+}
+
+// headerValue strips line breaks so values cannot inject headers
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/smtp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSMTPNotifier_SendsToRecipients(t *testing.T) {
+	notifier := NewSMTPNotifier("smtp.example.com:587", "user", "pass", "gatekeeper@example.com", []string{"ops@example.com", "support@example.com"})
+
+	var gotAddr, gotFrom string
+	var gotTo []string
+	var gotMsg []byte
+	notifier.send = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotFrom, gotTo, gotMsg = addr, from, to, msg
+		assert.NotNil(t, a)
+		return nil
+	}
+
+	err := notifier.Notify(context.Background(), Notification{
+		Event:     EventAPIKeyExpiring,
+		Recipient: "0x1234567890abcdef1234567890abcdef12345678",
+		Subject:   "API key \"ci\" expires soon\r\nBcc: attacker@example.com",
+		Message:   "The API key expires tomorrow.",
+		CreatedAt: time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "smtp.example.com:587", gotAddr)
+	assert.Equal(t, "gatekeeper@example.com", gotFrom)
+	assert.Equal(t, []string{"ops@example.com", "support@example.com"}, gotTo)
+	msg := string(gotMsg)
+	assert.Contains(t, msg, "To: ops@example.com, support@example.com\r\n")
+	assert.Contains(t, msg, "Subject: API key \"ci\" expires soon  Bcc: attacker@example.com\r\n")
+	assert.NotContains(t, msg, "\r\nBcc:")
+	assert.Contains(t, msg, "The API key expires tomorrow.")
+	assert.Contains(t, msg, "Wallet: 0x1234567890abcdef1234567890abcdef12345678")
+}
+
+func TestSMTPNotifier_SendError(t *testing.T) {
+	notifier := NewSMTPNotifier("smtp.example.com:25", "", "", "gatekeeper@example.com", []string{"ops@example.com"})
+	notifier.send = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		assert.Nil(t, a)
+		return errors.New("connection refused")
+	}
+
+	err := notifier.Notify(context.Background(), Notification{Event: EventAPIKeyExpiring})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connection refused")
+}
@@ -0,0 +1,65 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookNotifier_PostsSignedJSON(t *testing.T) {
+	var body []byte
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.URL, "secret")
+	err := notifier.Notify(context.Background(), Notification{
+		Event:     EventAPIKeyExpiring,
+		Recipient: "0x1234567890abcdef1234567890abcdef12345678",
+		Subject:   "API key expires soon",
+		CreatedAt: time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+	assert.Equal(t, EventAPIKeyExpiring, header.Get(EventHeader))
+	assert.Equal(t, Sign([]byte("secret"), body), header.Get(SignatureHeader))
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &decoded))
+	assert.Equal(t, "0x1234567890abcdef1234567890abcdef12345678", decoded["recipient"])
+	assert.Equal(t, "2026-10-17T12:00:00Z", decoded["createdAt"])
+}
+
+func TestWebhookNotifier_UnsignedWithoutSecret(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+	}))
+	defer server.Close()
+
+	require.NoError(t, NewWebhookNotifier(server.URL, "").Notify(context.Background(), Notification{Event: EventAPIKeyExpiring}))
+	assert.Empty(t, header.Get(SignatureHeader))
+}
+
+func TestWebhookNotifier_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	err := NewWebhookNotifier(server.URL, "").Notify(context.Background(), Notification{Event: EventAPIKeyExpiring})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "502")
+}
@@ -0,0 +1,84 @@
+package oidc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// Client is an application that signs users in through the provider
+type Client struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`             // Shown on the sign-in page
+	Secret       string   `json:"secret,omitempty"` // Empty for public clients, which must use PKCE
+	RedirectURIs []string `json:"redirectUris"`     // Exact URIs codes may be sent to
+}
+
+// clientsConfig is the JSON document listing the registered clients
+type clientsConfig struct {
+	Clients []Client `json:"clients"`
+}
+
+// LoadClientsFromJSON parses the registered clients from JSON bytes
+func LoadClientsFromJSON(data []byte) ([]Client, error) {
+	var config clientsConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse OIDC clients: %w", err)
+	}
+	if len(config.Clients) == 0 {
+		return nil, fmt.Errorf("OIDC clients file must define at least one client")
+	}
+
+	seen := make(map[string]bool, len(config.Clients))
+	for i := range config.Clients {
+		client := &config.Clients[i]
+		if client.ID == "" {
+			return nil, fmt.Errorf("OIDC client %d has no id", i)
+		}
+		if seen[client.ID] {
+			return nil, fmt.Errorf("OIDC client %q is defined twice", client.ID)
+		}
+		seen[client.ID] = true
+
+		if client.Name == "" {
+			client.Name = client.ID
+		}
+		if len(client.RedirectURIs) == 0 {
+			return nil, fmt.Errorf("OIDC client %q has no redirect URIs", client.ID)
+		}
+		for _, redirectURI := range client.RedirectURIs {
+			if err := validateRedirectURI(redirectURI); err != nil {
+				return nil, fmt.Errorf("OIDC client %q: %w", client.ID, err)
+			}
+		}
+	}
+	return config.Clients, nil
+}
+
+// validateRedirectURI checks that a registered redirect URI is absolute and has
+// no fragment, as OAuth 2.0 requires
+func validateRedirectURI(redirectURI string) error {
+	u, err := url.Parse(redirectURI)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("redirect URI %q must be an absolute URL", redirectURI)
+	}
+	if u.Fragment != "" {
+		return fmt.Errorf("redirect URI %q must not have a fragment", redirectURI)
+	}
+	return nil
+}
+
+// allowsRedirect reports whether redirectURI is registered for the client
+func (c *Client) allowsRedirect(redirectURI string) bool {
+	for _, registered := range c.RedirectURIs {
+		if redirectURI == registered {
+			return true
+		}
+	}
+	return false
+}
+
+// public reports whether the client cannot keep a secret
+func (c *Client) public() bool {
+	return c.Secret == ""
+}
@@ -0,0 +1,465 @@
+// Package oidc lets applications that speak OpenID Connect sign users in with
+// their wallets. Gatekeeper acts as the identity provider: users prove their
+// address with a SIWE signature on the sign-in page and the address (of the
+// account, for linked wallets) becomes the OIDC subject. Only the authorization
+// code flow is supported.
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Scopes clients may request; unknown scopes are ignored
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile" // Adds preferred_username
+)
+
+// SupportedScopes lists the scopes advertised in discovery
+var SupportedScopes = []string{ScopeOpenID, ScopeProfile}
+
+// OAuth 2.0 and OIDC error codes
+const (
+	ErrorInvalidRequest          = "invalid_request"
+	ErrorInvalidClient           = "invalid_client"
+	ErrorInvalidGrant            = "invalid_grant"
+	ErrorInvalidScope            = "invalid_scope"
+	ErrorInvalidToken            = "invalid_token"
+	ErrorUnsupportedGrantType    = "unsupported_grant_type"
+	ErrorUnsupportedResponseType = "unsupported_response_type"
+)
+
+// Error is an OAuth 2.0 error, sent to clients as error and error_description
+type Error struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return e.Code + ": " + e.Description
+}
+
+// Signer signs ID tokens; auth.JWTService implements it
+type Signer interface {
+	SignClaims(claims jwt.Claims) (string, error)
+	Algorithm() string
+}
+
+// AuthorizationRequest is a request to the authorization endpoint
+type AuthorizationRequest struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+// ParseAuthorizationRequest reads an authorization request from query or form values
+func ParseAuthorizationRequest(values url.Values) *AuthorizationRequest {
+	return &AuthorizationRequest{
+		ResponseType:        values.Get("response_type"),
+		ClientID:            values.Get("client_id"),
+		RedirectURI:         values.Get("redirect_uri"),
+		Scope:               values.Get("scope"),
+		State:               values.Get("state"),
+		Nonce:               values.Get("nonce"),
+		CodeChallenge:       values.Get("code_challenge"),
+		CodeChallengeMethod: values.Get("code_challenge_method"),
+	}
+}
+
+// Values returns the request as query or form values, omitting empty parameters
+func (r *AuthorizationRequest) Values() url.Values {
+	values := url.Values{}
+	for name, value := range map[string]string{
+		"response_type":         r.ResponseType,
+		"client_id":             r.ClientID,
+		"redirect_uri":          r.RedirectURI,
+		"scope":                 r.Scope,
+		"state":                 r.State,
+		"nonce":                 r.Nonce,
+		"code_challenge":        r.CodeChallenge,
+		"code_challenge_method": r.CodeChallengeMethod,
+	} {
+		if value != "" {
+			values.Set(name, value)
+		}
+	}
+	return values
+}
+
+// TokenRequest is an authorization code exchange at the token endpoint
+type TokenRequest struct {
+	GrantType    string
+	Code         string
+	RedirectURI  string
+	ClientID     string
+	ClientSecret string
+	CodeVerifier string
+}
+
+// TokenResponse is the token endpoint's answer to a successful exchange
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"` // Seconds
+	IDToken     string `json:"id_token"`
+	Scope       string `json:"scope"`
+}
+
+// Discovery is the OpenID Provider metadata served at /.well-known/openid-configuration
+type Discovery struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+}
+
+// grant is what an authorization code or access token stands for
+type grant struct {
+	clientID      string
+	redirectURI   string
+	subject       string
+	scopes        []string
+	nonce         string
+	codeChallenge string
+	authTime      time.Time
+	expiresAt     time.Time
+}
+
+// Provider issues authorization codes, access tokens and ID tokens. Codes and
+// access tokens are kept in memory, like SIWE nonces, so a deployment with
+// several replicas must route a client's requests to one of them.
+type Provider struct {
+	issuer         string
+	clients        map[string]*Client
+	signer         Signer
+	codeTTL        time.Duration
+	accessTokenTTL time.Duration
+
+	mu           sync.Mutex
+	codes        map[string]*grant // Single-use authorization codes
+	accessTokens map[string]*grant
+	now          func() time.Time
+}
+
+// NewProvider creates a provider for issuer, the base URL its endpoints are
+// served under, e.g. "https://auth.example.com"
+func NewProvider(issuer string, clients []Client, signer Signer, codeTTL, accessTokenTTL time.Duration) *Provider {
+	byID := make(map[string]*Client, len(clients))
+	for i := range clients {
+		byID[clients[i].ID] = &clients[i]
+	}
+	return &Provider{
+		issuer:         strings.TrimRight(issuer, "/"),
+		clients:        byID,
+		signer:         signer,
+		codeTTL:        codeTTL,
+		accessTokenTTL: accessTokenTTL,
+		codes:          make(map[string]*grant),
+		accessTokens:   make(map[string]*grant),
+		now:            time.Now,
+	}
+}
+
+// Issuer returns the issuer identifier
+func (p *Provider) Issuer() string {
+	return p.issuer
+}
+
+// Discovery returns the provider metadata
+func (p *Provider) Discovery() Discovery {
+	return Discovery{
+		Issuer:                            p.issuer,
+		AuthorizationEndpoint:             p.issuer + "/oidc/authorize",
+		TokenEndpoint:                     p.issuer + "/oidc/token",
+		UserInfoEndpoint:                  p.issuer + "/oidc/userinfo",
+		JWKSURI:                           p.issuer + "/.well-known/jwks.json",
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code"},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{p.signer.Algorithm()},
+		ScopesSupported:                   SupportedScopes,
+		ClaimsSupported:                   []string{"iss", "sub", "aud", "exp", "iat", "auth_time", "nonce", "preferred_username"},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+	}
+}
+
+// ValidateAuthorization checks an authorization request and returns its client.
+// When the returned client is nil the client or redirect URI cannot be trusted
+// and the error must be shown to the user; otherwise errors are sent back to
+// the redirect URI (see ErrorRedirect).
+func (p *Provider) ValidateAuthorization(req *AuthorizationRequest) (*Client, error) {
+	client, ok := p.clients[req.ClientID]
+	if !ok {
+		return nil, &Error{Code: ErrorInvalidClient, Description: "unknown client_id"}
+	}
+	if !client.allowsRedirect(req.RedirectURI) {
+		return nil, &Error{Code: ErrorInvalidRequest, Description: "redirect_uri is not registered for the client"}
+	}
+
+	if req.ResponseType != "code" {
+		return client, &Error{Code: ErrorUnsupportedResponseType, Description: "only response_type code is supported"}
+	}
+	if !containsScope(req.Scope, ScopeOpenID) {
+		return client, &Error{Code: ErrorInvalidScope, Description: "scope must include openid"}
+	}
+	if req.CodeChallenge == "" {
+		if client.public() {
+			return client, &Error{Code: ErrorInvalidRequest, Description: "public clients must use PKCE (code_challenge)"}
+		}
+	} else if req.CodeChallengeMethod != "S256" {
+		return client, &Error{Code: ErrorInvalidRequest, Description: "code_challenge_method must be S256"}
+	}
+	return client, nil
+}
+
+// IssueCode issues a single-use authorization code for subject, the address
+// the user signed in as, after validating the request again
+func (p *Provider) IssueCode(req *AuthorizationRequest, subject string) (string, error) {
+	if _, err := p.ValidateAuthorization(req); err != nil {
+		return "", err
+	}
+	code, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	removeExpired(p.codes, now)
+	p.codes[code] = &grant{
+		clientID:      req.ClientID,
+		redirectURI:   req.RedirectURI,
+		subject:       subject,
+		scopes:        grantedScopes(req.Scope),
+		nonce:         req.Nonce,
+		codeChallenge: req.CodeChallenge,
+		authTime:      now,
+		expiresAt:     now.Add(p.codeTTL),
+	}
+	return code, nil
+}
+
+// Exchange redeems an authorization code for an access token and an ID token
+func (p *Provider) Exchange(req *TokenRequest) (*TokenResponse, error) {
+	if req.GrantType != "authorization_code" {
+		return nil, &Error{Code: ErrorUnsupportedGrantType, Description: "only authorization_code is supported"}
+	}
+	client, ok := p.clients[req.ClientID]
+	if !ok || !client.authenticate(req.ClientSecret) {
+		return nil, &Error{Code: ErrorInvalidClient, Description: "client authentication failed"}
+	}
+
+	g := p.consumeCode(req.Code)
+	if g == nil || g.clientID != client.ID {
+		return nil, &Error{Code: ErrorInvalidGrant, Description: "unknown, used or expired code"}
+	}
+	if g.redirectURI != req.RedirectURI {
+		return nil, &Error{Code: ErrorInvalidGrant, Description: "redirect_uri does not match the authorization request"}
+	}
+	if g.codeChallenge != "" || req.CodeVerifier != "" {
+		if !verifyPKCE(g.codeChallenge, req.CodeVerifier) {
+			return nil, &Error{Code: ErrorInvalidGrant, Description: "code_verifier does not match the code_challenge"}
+		}
+	}
+
+	now := p.now()
+	claims := jwt.MapClaims{
+		"iss":       p.issuer,
+		"sub":       g.subject,
+		"aud":       client.ID,
+		"iat":       now.Unix(),
+		"exp":       now.Add(p.accessTokenTTL).Unix(),
+		"auth_time": g.authTime.Unix(),
+	}
+	if g.nonce != "" {
+		claims["nonce"] = g.nonce
+	}
+	for name, value := range userClaims(g) {
+		claims[name] = value
+	}
+	idToken, err := p.signer.SignClaims(claims)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign ID token: %w", err)
+	}
+
+	accessToken, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	removeExpired(p.accessTokens, now)
+	access := *g
+	access.expiresAt = now.Add(p.accessTokenTTL)
+	p.accessTokens[accessToken] = &access
+	p.mu.Unlock()
+
+	return &TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(p.accessTokenTTL.Seconds()),
+		IDToken:     idToken,
+		Scope:       strings.Join(g.scopes, " "),
+	}, nil
+}
+
+// UserInfo returns the claims about the user an access token was issued for
+func (p *Provider) UserInfo(accessToken string) (map[string]interface{}, error) {
+	p.mu.Lock()
+	g, ok := p.accessTokens[accessToken]
+	p.mu.Unlock()
+	if !ok || p.now().After(g.expiresAt) {
+		return nil, &Error{Code: ErrorInvalidToken, Description: "unknown or expired access token"}
+	}
+	return userClaims(g), nil
+}
+
+// consumeCode removes an authorization code, returning its grant unless it expired
+func (p *Provider) consumeCode(code string) *grant {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	g, ok := p.codes[code]
+	if !ok {
+		return nil
+	}
+	delete(p.codes, code)
+	if p.now().After(g.expiresAt) {
+		return nil
+	}
+	return g
+}
+
+// CodeRedirect returns the redirect URI carrying an issued code back to the client
+func CodeRedirect(req *AuthorizationRequest, code string) string {
+	params := url.Values{"code": {code}}
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+	return withQuery(req.RedirectURI, params)
+}
+
+// ErrorRedirect returns the redirect URI reporting err back to the client
+func ErrorRedirect(req *AuthorizationRequest, err *Error) string {
+	params := url.Values{"error": {err.Code}}
+	if err.Description != "" {
+		params.Set("error_description", err.Description)
+	}
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+	return withQuery(req.RedirectURI, params)
+}
+
+// withQuery adds params to the query of a registered redirect URI
+func withQuery(redirectURI string, params url.Values) string {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+	query := u.Query()
+	for name, values := range params {
+		query[name] = values
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// userClaims returns the claims about the user released for the granted scopes
+func userClaims(g *grant) map[string]interface{} {
+	claims := map[string]interface{}{"sub": g.subject}
+	for _, scope := range g.scopes {
+		if scope == ScopeProfile {
+			claims["preferred_username"] = g.subject
+		}
+	}
+	return claims
+}
+
+// authenticate checks the secret presented by the client. Public clients
+// present none and are authenticated by PKCE instead.
+func (c *Client) authenticate(secret string) bool {
+	if c.public() {
+		return secret == ""
+	}
+	return subtle.ConstantTimeCompare([]byte(secret), []byte(c.Secret)) == 1
+}
+
+// verifyPKCE checks a code verifier against an S256 code challenge (RFC 7636)
+func verifyPKCE(challenge, verifier string) bool {
+	if challenge == "" || verifier == "" {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+// containsScope reports whether a space-separated scope parameter includes scope
+func containsScope(scopes, scope string) bool {
+	for _, s := range strings.Fields(scopes) {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// grantedScopes returns the supported scopes of a scope parameter
+func grantedScopes(scopes string) []string {
+	var granted []string
+	for _, scope := range SupportedScopes {
+		if containsScope(scopes, scope) {
+			granted = append(granted, scope)
+		}
+	}
+	return granted
+}
+
+// removeExpired drops expired grants
+func removeExpired(grants map[string]*grant, now time.Time) {
+	for key, g := range grants {
+		if now.After(g.expiresAt) {
+			delete(grants, key)
+		}
+	}
+}
+
+// randomToken returns an unguessable base64url token
+func randomToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
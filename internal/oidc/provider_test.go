@@ -0,0 +1,212 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSigner signs ES256 tokens like an auth.JWTService with an EC key
+type testSigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func (s *testSigner) SignClaims(claims jwt.Claims) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodES256, claims).SignedString(s.key)
+}
+
+func (s *testSigner) Algorithm() string {
+	return "ES256"
+}
+
+const subject = "0x742d35cc6634c0532925a3b844bc9e7595f0beb0"
+
+func newTestProvider(t *testing.T) (*Provider, *testSigner) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	signer := &testSigner{key: key}
+
+	clients, err := LoadClientsFromJSON([]byte(`{"clients": [
+		{"id": "grafana", "name": "Grafana", "secret": "s3cret", "redirectUris": ["https://grafana.example.com/login/generic_oauth"]},
+		{"id": "spa", "redirectUris": ["https://app.example.com/callback?source=gatekeeper"]}
+	]}`))
+	require.NoError(t, err)
+	return NewProvider("https://auth.example.com/", clients, signer, time.Minute, time.Hour), signer
+}
+
+func grafanaRequest() *AuthorizationRequest {
+	return &AuthorizationRequest{
+		ResponseType: "code",
+		ClientID:     "grafana",
+		RedirectURI:  "https://grafana.example.com/login/generic_oauth",
+		Scope:        "openid profile email",
+		State:        "xyz",
+		Nonce:        "n-0S6",
+	}
+}
+
+func TestProvider_CodeFlow(t *testing.T) {
+	provider, signer := newTestProvider(t)
+	req := grafanaRequest()
+
+	code, err := provider.IssueCode(req, subject)
+	require.NoError(t, err)
+
+	redirect, err := url.Parse(CodeRedirect(req, code))
+	require.NoError(t, err)
+	assert.Equal(t, code, redirect.Query().Get("code"))
+	assert.Equal(t, "xyz", redirect.Query().Get("state"))
+
+	tokens, err := provider.Exchange(&TokenRequest{
+		GrantType:    "authorization_code",
+		Code:         code,
+		RedirectURI:  req.RedirectURI,
+		ClientID:     "grafana",
+		ClientSecret: "s3cret",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer", tokens.TokenType)
+	assert.Equal(t, "openid profile", tokens.Scope)
+	assert.Equal(t, 3600, tokens.ExpiresIn)
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(tokens.IDToken, claims, func(*jwt.Token) (interface{}, error) {
+		return &signer.key.PublicKey, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://auth.example.com", claims["iss"])
+	assert.Equal(t, subject, claims["sub"])
+	assert.Equal(t, "grafana", claims["aud"])
+	assert.Equal(t, "n-0S6", claims["nonce"])
+	assert.Equal(t, subject, claims["preferred_username"])
+
+	userInfo, err := provider.UserInfo(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"sub": subject, "preferred_username": subject}, userInfo)
+
+	// Codes are single-use
+	_, err = provider.Exchange(&TokenRequest{GrantType: "authorization_code", Code: code, RedirectURI: req.RedirectURI, ClientID: "grafana", ClientSecret: "s3cret"})
+	assertOAuthError(t, ErrorInvalidGrant, err)
+}
+
+func TestProvider_PublicClientRequiresPKCE(t *testing.T) {
+	provider, _ := newTestProvider(t)
+	req := &AuthorizationRequest{
+		ResponseType: "code",
+		ClientID:     "spa",
+		RedirectURI:  "https://app.example.com/callback?source=gatekeeper",
+		Scope:        "openid",
+	}
+
+	client, err := provider.ValidateAuthorization(req)
+	require.NotNil(t, client)
+	assertOAuthError(t, ErrorInvalidRequest, err)
+
+	verifier := "dBjftJeZ4CVP-mJ92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	sum := sha256.Sum256([]byte(verifier))
+	req.CodeChallenge = base64.RawURLEncoding.EncodeToString(sum[:])
+	req.CodeChallengeMethod = "S256"
+	code, err := provider.IssueCode(req, subject)
+	require.NoError(t, err)
+
+	redirect, err := url.Parse(CodeRedirect(req, code))
+	require.NoError(t, err)
+	assert.Equal(t, "gatekeeper", redirect.Query().Get("source"))
+
+	_, err = provider.Exchange(&TokenRequest{GrantType: "authorization_code", Code: code, RedirectURI: req.RedirectURI, ClientID: "spa", CodeVerifier: "wrong"})
+	assertOAuthError(t, ErrorInvalidGrant, err)
+
+	// The failed attempt consumed the code
+	code, err = provider.IssueCode(req, subject)
+	require.NoError(t, err)
+	_, err = provider.Exchange(&TokenRequest{GrantType: "authorization_code", Code: code, RedirectURI: req.RedirectURI, ClientID: "spa", CodeVerifier: verifier})
+	assert.NoError(t, err)
+}
+
+func TestProvider_ValidateAuthorization(t *testing.T) {
+	provider, _ := newTestProvider(t)
+
+	tests := []struct {
+		name      string
+		modify    func(req *AuthorizationRequest)
+		code      string
+		untrusted bool // The error must not be redirected
+	}{
+		{name: "unknown client", modify: func(req *AuthorizationRequest) { req.ClientID = "other" }, code: ErrorInvalidClient, untrusted: true},
+		{name: "unregistered redirect URI", modify: func(req *AuthorizationRequest) { req.RedirectURI = "https://evil.example/cb" }, code: ErrorInvalidRequest, untrusted: true},
+		{name: "implicit flow", modify: func(req *AuthorizationRequest) { req.ResponseType = "id_token" }, code: ErrorUnsupportedResponseType},
+		{name: "missing openid scope", modify: func(req *AuthorizationRequest) { req.Scope = "profile" }, code: ErrorInvalidScope},
+		{name: "plain PKCE", modify: func(req *AuthorizationRequest) { req.CodeChallenge, req.CodeChallengeMethod = "abc", "plain" }, code: ErrorInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := grafanaRequest()
+			tt.modify(req)
+
+			client, err := provider.ValidateAuthorization(req)
+			assertOAuthError(t, tt.code, err)
+			assert.Equal(t, tt.untrusted, client == nil)
+		})
+	}
+}
+
+func TestProvider_ExchangeRejections(t *testing.T) {
+	provider, _ := newTestProvider(t)
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	provider.now = func() time.Time { return now }
+
+	issue := func() string {
+		code, err := provider.IssueCode(grafanaRequest(), subject)
+		require.NoError(t, err)
+		return code
+	}
+	exchange := func(req TokenRequest) error {
+		_, err := provider.Exchange(&req)
+		return err
+	}
+	redirectURI := grafanaRequest().RedirectURI
+
+	assertOAuthError(t, ErrorInvalidClient, exchange(TokenRequest{GrantType: "authorization_code", Code: issue(), RedirectURI: redirectURI, ClientID: "grafana", ClientSecret: "wrong"}))
+	assertOAuthError(t, ErrorInvalidGrant, exchange(TokenRequest{GrantType: "authorization_code", Code: issue(), RedirectURI: redirectURI + "/other", ClientID: "grafana", ClientSecret: "s3cret"}))
+	assertOAuthError(t, ErrorUnsupportedGrantType, exchange(TokenRequest{GrantType: "password", ClientID: "grafana", ClientSecret: "s3cret"}))
+
+	code := issue()
+	now = now.Add(2 * time.Minute)
+	assertOAuthError(t, ErrorInvalidGrant, exchange(TokenRequest{GrantType: "authorization_code", Code: code, RedirectURI: redirectURI, ClientID: "grafana", ClientSecret: "s3cret"}))
+
+	_, err := provider.UserInfo("unknown")
+	assertOAuthError(t, ErrorInvalidToken, err)
+}
+
+func TestLoadClientsFromJSON_Invalid(t *testing.T) {
+	for _, input := range []string{
+		`{"clients": []}`,
+		`{"clients": [{"redirectUris": ["https://a.example/cb"]}]}`,
+		`{"clients": [{"id": "a"}]}`,
+		`{"clients": [{"id": "a", "redirectUris": ["/cb"]}]}`,
+		`{"clients": [{"id": "a", "redirectUris": ["https://a.example/cb#x"]}]}`,
+		`{"clients": [{"id": "a", "redirectUris": ["https://a.example/cb"]}, {"id": "a", "redirectUris": ["https://a.example/cb"]}]}`,
+	} {
+		_, err := LoadClientsFromJSON([]byte(input))
+		assert.Error(t, err, input)
+	}
+}
+
+func assertOAuthError(t *testing.T, code string, err error) {
+	t.Helper()
+	var oauthErr *Error
+	require.True(t, errors.As(err, &oauthErr), "got %v", err)
+	assert.Equal(t, code, oauthErr.Code)
+}
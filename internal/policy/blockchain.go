@@ -1,9 +1,12 @@
 package policy
 
 import (
+	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"strconv"
 	"strings"
 
 	ethcommon "github.com/yourusername/gatekeeper/internal/common"
@@ -30,9 +33,32 @@ const (
 
 // ERC721Selectors for standard ERC721 methods
 const (
-	ERC721OwnerOfSelector = "0x6352211e"
+	ERC721OwnerOfSelector             = "0x6352211e"
+	ERC721BalanceOfSelector           = "0x70a08231"
+	ERC721TokenURISelector            = "0xc87b56dd"
+	ERC721TokenOfOwnerByIndexSelector = "0x2f745c59" // ERC721Enumerable
 )
 
+// ethCall makes an eth_call against the latest block and returns the result hex value
+func ethCall(ctx context.Context, provider BlockchainProvider, contract, calldata string) (string, error) {
+	return ethCallAt(ctx, provider, contract, calldata, "latest")
+}
+
+// ethCallAt makes an eth_call against a block number or tag and returns the result hex value
+func ethCallAt(ctx context.Context, provider BlockchainProvider, contract, calldata, block string) (string, error) {
+	response, err := callProvider(ctx, provider, "eth_call", []interface{}{
+		map[string]interface{}{
+			"to":   contract,
+			"data": calldata,
+		},
+		block,
+	})
+	if err != nil {
+		return "", err
+	}
+	return parseJSONRPCResponse(response)
+}
+
 // parseJSONRPCResponse parses a JSON-RPC response and extracts the result hex value
 func parseJSONRPCResponse(data []byte) (string, error) {
 	var resp JSONRPCResponse
@@ -53,6 +79,73 @@ func parseJSONRPCResponse(data []byte) (string, error) {
 	return resp.Result, nil
 }
 
+// ethLog is a log entry returned by eth_getLogs
+type ethLog struct {
+	Address     string   `json:"address"`
+	Topics      []string `json:"topics"`
+	Data        string   `json:"data"`
+	BlockNumber string   `json:"blockNumber"`
+	LogIndex    string   `json:"logIndex"`
+}
+
+// ethBlock is the header of a block returned by eth_getBlockByNumber
+type ethBlock struct {
+	Number    string `json:"number"`
+	Timestamp string `json:"timestamp"`
+}
+
+// ethGetLogs runs eth_getLogs with the given filter and returns the matching logs
+func ethGetLogs(ctx context.Context, provider BlockchainProvider, filter map[string]interface{}) ([]ethLog, error) {
+	var logs []ethLog
+	if err := callRPC(ctx, provider, "eth_getLogs", []interface{}{filter}, &logs); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// ethGetBlock returns the header of a block by number or tag
+func ethGetBlock(ctx context.Context, provider BlockchainProvider, block string) (*ethBlock, error) {
+	var header *ethBlock
+	if err := callRPC(ctx, provider, "eth_getBlockByNumber", []interface{}{block, false}, &header); err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("block %s not found", block)
+	}
+	return header, nil
+}
+
+// parseQuantity parses a 0x-prefixed hex quantity such as a block number
+func parseQuantity(value string) (uint64, error) {
+	if !strings.HasPrefix(value, "0x") {
+		return 0, fmt.Errorf("invalid quantity %q", value)
+	}
+	return strconv.ParseUint(value[2:], 16, 64)
+}
+
+// callRPC makes a JSON-RPC call and decodes its result, whatever its shape, into result
+func callRPC(ctx context.Context, provider BlockchainProvider, method string, params []interface{}, result interface{}) error {
+	response, err := callProvider(ctx, provider, method, params)
+	if err != nil {
+		return err
+	}
+
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *JSONRPCError   `json:"error,omitempty"`
+	}
+	if err := json.Unmarshal(response, &resp); err != nil {
+		return fmt.Errorf("failed to parse JSON-RPC response: %w", err)
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("RPC error: %s", resp.Error.Message)
+	}
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		return fmt.Errorf("failed to parse %s result: %w", method, err)
+	}
+	return nil
+}
+
 // encodeAddress encodes an Ethereum address to 32-byte hex string for contract call
 func encodeAddress(address string) string {
 	// Remove 0x prefix if present
@@ -102,6 +195,50 @@ func decodeUint256(hexValue string) (*big.Int, error) {
 	return value, nil
 }
 
+// decodeString decodes an ABI-encoded dynamic string return value
+// (32-byte offset, 32-byte length, then the UTF-8 bytes)
+func decodeString(hexValue string) (string, error) {
+	data, err := hex.DecodeString(strings.TrimPrefix(hexValue, "0x"))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode hex value: %w", err)
+	}
+	if len(data) < 64 {
+		return "", fmt.Errorf("invalid string encoding: expected at least 64 bytes, got %d", len(data))
+	}
+
+	offset := new(big.Int).SetBytes(data[:32])
+	if !offset.IsUint64() || offset.Uint64() > uint64(len(data)-32) {
+		return "", fmt.Errorf("invalid string offset")
+	}
+	start := offset.Uint64() + 32
+
+	length := new(big.Int).SetBytes(data[start-32 : start])
+	if !length.IsUint64() || length.Uint64() > uint64(len(data))-start {
+		return "", fmt.Errorf("invalid string length")
+	}
+
+	return string(data[start : start+length.Uint64()]), nil
+}
+
+// abiWord returns the index-th 32-byte word of ABI-encoded return data
+func abiWord(hexValue string, index int) (string, error) {
+	hexValue = strings.TrimPrefix(hexValue, "0x")
+	start := index * 64
+	if index < 0 || len(hexValue) < start+64 {
+		return "", fmt.Errorf("return data has no word %d", index)
+	}
+	return hexValue[start : start+64], nil
+}
+
+// decodeWord decodes the index-th 32-byte word of ABI-encoded return data as a uint256
+func decodeWord(hexValue string, index int) (*big.Int, error) {
+	word, err := abiWord(hexValue, index)
+	if err != nil {
+		return nil, err
+	}
+	return decodeUint256(word)
+}
+
 // encodeERC20BalanceOfCall encodes a call to ERC20 balanceOf(address)
 // Returns the calldata hex string
 func encodeERC20BalanceOfCall(tokenAddress, userAddress string) string {
@@ -122,6 +259,23 @@ func encodeERC721OwnerOfCall(nftAddress string, tokenID *big.Int) string {
 	return calldata
 }
 
+// encodeERC721BalanceOfCall encodes a call to ERC721 balanceOf(address)
+func encodeERC721BalanceOfCall(ownerAddress string) string {
+	return ERC721BalanceOfSelector + strings.TrimPrefix(encodeAddress(ownerAddress), "0x")
+}
+
+// encodeERC721TokenURICall encodes a call to ERC721 tokenURI(uint256)
+func encodeERC721TokenURICall(tokenID *big.Int) string {
+	return ERC721TokenURISelector + strings.TrimPrefix(encodeUint256(tokenID), "0x")
+}
+
+// encodeERC721TokenOfOwnerByIndexCall encodes a call to ERC721Enumerable tokenOfOwnerByIndex(address,uint256)
+func encodeERC721TokenOfOwnerByIndexCall(ownerAddress string, index int64) string {
+	return ERC721TokenOfOwnerByIndexSelector +
+		strings.TrimPrefix(encodeAddress(ownerAddress), "0x") +
+		strings.TrimPrefix(encodeUint256(big.NewInt(index)), "0x")
+}
+
 // normalizeCacheKey generates a consistent cache key for blockchain results
 func normalizeCacheKey(dataType, chainID string, contract, identifier string) string {
 	return fmt.Sprintf("%s:%s:%s:%s", dataType, chainID, strings.ToLower(contract), strings.ToLower(identifier))
@@ -0,0 +1,59 @@
+package policy
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Blocklist reports which addresses are blocked. Blocked addresses are denied by
+// not_in_blocklist rules and, when the policy middleware has a blocklist, on
+// every gated route.
+type Blocklist interface {
+	// FindBlockedAddresses returns which of addresses are blocked, lowercased
+	FindBlockedAddresses(ctx context.Context, addresses []string) ([]string, error)
+}
+
+// MemoryBlocklist is an in-process Blocklist, for tests and single-instance
+// deployments without a database
+type MemoryBlocklist struct {
+	mu      sync.RWMutex
+	blocked map[string]bool
+}
+
+// NewMemoryBlocklist creates a blocklist holding addresses
+func NewMemoryBlocklist(addresses ...string) *MemoryBlocklist {
+	b := &MemoryBlocklist{blocked: make(map[string]bool)}
+	for _, address := range addresses {
+		b.Block(address)
+	}
+	return b
+}
+
+// Block adds an address to the blocklist
+func (b *MemoryBlocklist) Block(address string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.blocked[strings.ToLower(address)] = true
+}
+
+// Unblock removes an address from the blocklist
+func (b *MemoryBlocklist) Unblock(address string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.blocked, strings.ToLower(address))
+}
+
+// FindBlockedAddresses returns which of addresses are blocked
+func (b *MemoryBlocklist) FindBlockedAddresses(ctx context.Context, addresses []string) ([]string, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	found := []string{}
+	for _, address := range addresses {
+		if normalized := strings.ToLower(address); b.blocked[normalized] {
+			found = append(found, normalized)
+		}
+	}
+	return found, nil
+}
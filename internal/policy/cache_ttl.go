@@ -0,0 +1,189 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ttlSetter is implemented by caches that can store an entry with a TTL of its
+// own, such as chain.Cache
+type ttlSetter interface {
+	SetWithTTL(key string, value interface{}, ttl time.Duration)
+}
+
+// ttlCache stores entries in a cache with a TTL of its own. Keys are suffixed with
+// the TTL, so readers with different TTLs never share an entry: a rule caching
+// balances for 30s is not served a balance another rule cached for 24h. Caches that
+// cannot set a TTL per entry keep entries for their own TTL.
+type ttlCache struct {
+	cache CacheProvider
+	ttl   time.Duration
+}
+
+// withCacheTTL returns cache storing entries for ttl, or cache itself if ttl is zero
+func withCacheTTL(cache CacheProvider, ttl time.Duration) CacheProvider {
+	if cache == nil || ttl <= 0 {
+		return cache
+	}
+	return &ttlCache{cache: cache, ttl: ttl}
+}
+
+// key returns the key of the entry in the underlying cache
+func (c *ttlCache) key(key string) string {
+	return key + "@" + c.ttl.String()
+}
+
+// Get retrieves a value stored with this TTL
+func (c *ttlCache) Get(key string) (interface{}, bool) {
+	return c.cache.Get(c.key(key))
+}
+
+// Set stores a value expiring after the TTL
+func (c *ttlCache) Set(key string, value interface{}) {
+	if setter, ok := c.cache.(ttlSetter); ok {
+		setter.SetWithTTL(c.key(key), value, c.ttl)
+		return
+	}
+	c.cache.Set(c.key(key), value)
+}
+
+// GetOrSet gets a value or computes and stores it if not found
+func (c *ttlCache) GetOrSet(key string, fn func() interface{}) interface{} {
+	if value, ok := c.Get(key); ok {
+		return value
+	}
+	value := fn()
+	c.Set(key, value)
+	return value
+}
+
+// cacheTTL is embedded in rules that cache blockchain reads, so each rule can keep
+// its reads for as long as its data stays valid: seconds for balances, a day for
+// NFT ownership
+type cacheTTL struct {
+	ttl time.Duration
+}
+
+// CacheTTL returns how long the rule caches its reads; zero uses the cache's TTL
+func (c *cacheTTL) CacheTTL() time.Duration {
+	return c.ttl
+}
+
+// SetCacheTTL sets how long the rule caches its reads; zero uses the cache's TTL.
+// It takes effect when the cache is set, so call it before SetCache.
+func (c *cacheTTL) SetCacheTTL(ttl time.Duration) {
+	c.ttl = ttl
+}
+
+// cachingRule is a rule that caches its reads with a TTL of its own
+type cachingRule interface {
+	Rule
+	CacheTTL() time.Duration
+	SetCacheTTL(ttl time.Duration)
+}
+
+// readFailures records whether a blockchain or metadata read failed during an
+// evaluation. Rules fail closed on such errors rather than returning them, so
+// without this record a denial caused by an RPC outage would be cached as a
+// decision.
+type readFailures struct {
+	failed atomic.Bool
+}
+
+// readFailuresKey carries the readFailures of an evaluation
+type readFailuresKey struct{}
+
+// withReadFailures returns a context recording the reads that fail in it
+func withReadFailures(ctx context.Context) (context.Context, *readFailures) {
+	failures := &readFailures{}
+	return context.WithValue(ctx, readFailuresKey{}, failures), failures
+}
+
+// markReadFailed records a failed read, if ctx records them
+func markReadFailed(ctx context.Context) {
+	if failures, ok := ctx.Value(readFailuresKey{}).(*readFailures); ok {
+		failures.failed.Store(true)
+	}
+}
+
+// decisionCache keeps the decisions of one policy
+type decisionCache struct {
+	cache  CacheProvider
+	prefix string // Key prefix of the policy's decisions
+}
+
+// decisionCacheGeneration numbers decision caches, so a replaced policy never
+// reads the decisions of the policy it replaced
+var decisionCacheGeneration atomic.Int64
+
+// SetDecisionCache sets the cache the decisions of a policy with a CacheTTL are
+// kept in. Each call starts afresh, ignoring decisions cached before.
+func (p *Policy) SetDecisionCache(cache CacheProvider) {
+	if p.CacheTTL <= 0 || cache == nil {
+		p.decisions.Store(nil)
+		return
+	}
+	p.decisions.Store(&decisionCache{
+		cache:  withCacheTTL(cache, p.CacheTTL),
+		prefix: fmt.Sprintf("decision:%d:", decisionCacheGeneration.Add(1)),
+	})
+}
+
+// key returns the cache key of the decision for address. Wallet rules also pass
+// for the wallets linked to the caller, so they are part of the key.
+func (c *decisionCache) key(ctx context.Context, address string) string {
+	key := c.prefix + strings.ToLower(address)
+	if ec := EvaluationContextFromContext(ctx); ec != nil && len(ec.Wallets) > 0 {
+		wallets := make([]string, len(ec.Wallets))
+		for i, wallet := range ec.Wallets {
+			wallets[i] = strings.ToLower(wallet)
+		}
+		sort.Strings(wallets)
+		key += ":" + strings.Join(wallets, ",")
+	}
+	return key
+}
+
+// evaluate answers from the cached decision for address while it is fresh, and
+// otherwise calls evaluate and caches its decision. Errors and evaluations in which
+// a read failed are not cached.
+func (c *decisionCache) evaluate(ctx context.Context, address string, evaluate func(context.Context) (bool, error)) (bool, error) {
+	key := c.key(ctx, address)
+	if !refreshingCache(ctx) {
+		if cached, ok := c.cache.Get(key); ok {
+			if allowed, ok := cached.(bool); ok {
+				return allowed, nil
+			}
+		}
+	}
+
+	ctx, failures := withReadFailures(ctx)
+	allowed, err := evaluate(ctx)
+	if err == nil && !failures.failed.Load() {
+		c.cache.Set(key, allowed)
+	}
+	return allowed, err
+}
+
+// checkDecisionCacheable fails unless every rule depends on nothing but the
+// address, so a cached decision cannot outlive the request details it was made on
+func checkDecisionCacheable(rules []Rule) error {
+	var uncacheable Rule
+	WalkRules(rules, func(rule Rule) {
+		switch rule.(type) {
+		case *AllOfRule, *AnyOfRule, *NotRule:
+			return
+		}
+		if uncacheable == nil && !isWalletRule(rule) {
+			uncacheable = rule
+		}
+	})
+	if uncacheable != nil {
+		return fmt.Errorf("%s rules depend on more than the address", uncacheable.Type())
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/chain"
+)
+
+// failingProvider fails every call while failing is set
+type failingProvider struct {
+	BlockchainProvider
+	failing atomic.Bool
+}
+
+func (p *failingProvider) Call(ctx context.Context, method string, params []interface{}) ([]byte, error) {
+	if p.failing.Load() {
+		return nil, errors.New("rpc unavailable")
+	}
+	return p.BlockchainProvider.Call(ctx, method, params)
+}
+
+func TestWithCacheTTL(t *testing.T) {
+	cache := chain.NewCache(time.Minute)
+	assert.Same(t, cache, withCacheTTL(cache, 0), "no TTL keeps the cache")
+
+	balances := withCacheTTL(cache, 10*time.Millisecond)
+	ownership := withCacheTTL(cache, 24*time.Hour)
+	balances.Set("key", 1)
+	ownership.Set("key", 2)
+
+	value, ok := ownership.Get("key")
+	require.True(t, ok)
+	assert.Equal(t, 2, value, "entries of different TTLs are kept apart")
+
+	time.Sleep(20 * time.Millisecond)
+	_, ok = balances.Get("key")
+	assert.False(t, ok, "the entry expired after its own TTL")
+	_, ok = ownership.Get("key")
+	assert.True(t, ok)
+}
+
+func TestRuleCacheTTL(t *testing.T) {
+	address := "0x1234567890abcdef1234567890abcdef12345678"
+	mock := &MockBlockchainProvider{balances: map[string]*big.Int{address: big.NewInt(5000)}}
+	provider := &countingProvider{BlockchainProvider: mock}
+
+	rule := NewERC20MinBalanceRule("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", big.NewInt(1000), 1)
+	rule.SetCacheTTL(10 * time.Millisecond)
+	manager := NewPolicyManager(provider, chain.NewCache(time.Hour))
+	manager.AddPolicy(NewPolicy("GET", "/api/data", "AND", []Rule{rule}))
+
+	for i := 0; i < 2; i++ {
+		allowed, err := rule.Evaluate(context.Background(), address, nil)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+	assert.Equal(t, int32(1), provider.calls.Load())
+
+	time.Sleep(20 * time.Millisecond)
+	_, err := rule.Evaluate(context.Background(), address, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), provider.calls.Load(), "the rule's TTL, not the cache's, expired the balance")
+}
+
+func TestPolicyDecisionCache(t *testing.T) {
+	member := "0x1234567890abcdef1234567890abcdef12345678"
+	other := "0xabcdef1234567890abcdef1234567890abcdef12"
+	rule := NewInAllowlistRule([]string{member})
+	p := NewPolicy("GET", "/api/data", "AND", []Rule{rule})
+	p.CacheTTL = time.Minute
+	p.SetDecisionCache(chain.NewCache(time.Hour))
+
+	allowed, err := p.Evaluate(context.Background(), member, nil)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	// The cached decision skips the rules until it expires
+	rule.Addresses = []string{other}
+	allowed, err = p.Evaluate(context.Background(), member, nil)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	// Other addresses, or the same caller with other wallets, are decided afresh
+	allowed, err = p.Evaluate(context.Background(), "0x0000000000000000000000000000000000000001", nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	withWallets := WithEvaluationContext(context.Background(), &EvaluationContext{Wallets: []string{other}})
+	allowed, err = p.Evaluate(withWallets, member, nil)
+	require.NoError(t, err)
+	assert.True(t, allowed, "a linked wallet passes the rule")
+
+	// Setting the cache again forgets earlier decisions, as when a policy is replaced
+	rule.Addresses = nil
+	p.SetDecisionCache(chain.NewCache(time.Hour))
+	allowed, err = p.Evaluate(context.Background(), member, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+func TestPolicyDecisionCache_SkipsFailedReads(t *testing.T) {
+	address := "0x1234567890abcdef1234567890abcdef12345678"
+	mock := &MockBlockchainProvider{balances: map[string]*big.Int{address: big.NewInt(5000)}}
+	provider := &failingProvider{BlockchainProvider: mock}
+	provider.failing.Store(true)
+
+	p := NewPolicy("GET", "/api/data", "AND", []Rule{
+		NewERC20MinBalanceRule("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", big.NewInt(1000), 1),
+	})
+	p.CacheTTL = time.Minute
+	manager := NewPolicyManager(provider, chain.NewCache(time.Hour))
+	manager.AddPolicy(p)
+
+	allowed, err := p.Evaluate(context.Background(), address, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed, "rules fail closed while the RPC fails")
+
+	provider.failing.Store(false)
+	allowed, err = p.Evaluate(context.Background(), address, nil)
+	require.NoError(t, err)
+	assert.True(t, allowed, "the denial caused by the outage was not cached")
+}
+
+func TestLoader_CacheTTL(t *testing.T) {
+	loader := NewPolicyLoader()
+	p, err := loader.ParsePolicy([]byte(`{
+		"path": "/api/data",
+		"method": "GET",
+		"logic": "OR",
+		"cache_ttl": "5m",
+		"rules": [
+			{"type": "erc20_min_balance", "contract_address": "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", "minimum_balance": "1000", "chain_id": 1, "cache_ttl": "30s"},
+			{"type": "any_of", "rules": [
+				{"type": "erc721_owner", "contract_address": "0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D", "token_id": "1", "chain_id": 1, "cache_ttl": "24h"}
+			]}
+		]
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, p.CacheTTL)
+	assert.Equal(t, 30*time.Second, p.Rules[0].(*ERC20MinBalanceRule).CacheTTL())
+	assert.Equal(t, 24*time.Hour, p.Rules[1].(*AnyOfRule).Rules[0].(*ERC721OwnerRule).CacheTTL())
+
+	// The TTLs survive serialization
+	data, err := json.Marshal(p)
+	require.NoError(t, err)
+	reloaded, err := loader.ParsePolicy(data)
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, reloaded.CacheTTL)
+	assert.Equal(t, 30*time.Second, reloaded.Rules[0].(*ERC20MinBalanceRule).CacheTTL())
+
+	tests := []struct {
+		name   string
+		policy string
+		err    string
+	}{
+		{
+			"rule that reads nothing",
+			`{"path": "/api/data", "method": "GET", "logic": "AND", "rules": [{"type": "has_scope", "scope": "read", "cache_ttl": "30s"}]}`,
+			"only allowed for rules that read the blockchain",
+		},
+		{
+			"invalid rule TTL",
+			`{"path": "/api/data", "method": "GET", "logic": "AND", "rules": [{"type": "code_exists", "chain_id": 1, "cache_ttl": "soon"}]}`,
+			"invalid cache_ttl format",
+		},
+		{
+			"negative policy TTL",
+			`{"path": "/api/data", "method": "GET", "logic": "AND", "cache_ttl": "-1m", "rules": [{"type": "code_exists", "chain_id": 1}]}`,
+			"cache_ttl must be positive",
+		},
+		{
+			"decision depending on the request",
+			`{"path": "/api/data", "method": "GET", "logic": "AND", "cache_ttl": "1m", "rules": [{"type": "not", "rule": {"type": "has_scope", "scope": "banned"}}]}`,
+			"has_scope rules depend on more than the address",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loader.ParsePolicy([]byte(tt.policy))
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package policy
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxWarmAddresses caps how many recently active addresses are kept warm;
+// the least recently active ones are dropped first
+const maxWarmAddresses = 1000
+
+// cacheRefreshKey marks a context whose evaluations bypass cached results
+type cacheRefreshKey struct{}
+
+// withCacheRefresh returns a context in which blockchain rules skip cached
+// results and store fresh ones
+func withCacheRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheRefreshKey{}, true)
+}
+
+// refreshingCache reports whether ctx asks rules to refresh their cache entries
+func refreshingCache(ctx context.Context) bool {
+	refresh, _ := ctx.Value(cacheRefreshKey{}).(bool)
+	return refresh
+}
+
+// CacheWarmer periodically re-evaluates the blockchain rules of recently active
+// addresses and of a fixed list of addresses, refreshing their cached balances
+// and ownership before they expire so those users never wait on the RPC.
+// Run it at an interval shorter than the cache TTL.
+type CacheWarmer struct {
+	manager    *PolicyManager
+	interval   time.Duration
+	activeFor  time.Duration // How long after its last request an address stays warm
+	addresses  []string      // Always kept warm
+	logger     *zap.Logger
+	now        func() time.Time
+	mu         sync.Mutex
+	lastActive map[string]time.Time
+	stopOnce   sync.Once
+	stop       chan struct{}
+}
+
+// NewCacheWarmer creates a warmer that every interval refreshes the cached
+// results of addresses active within activeFor, plus the given addresses
+func NewCacheWarmer(manager *PolicyManager, addresses []string, interval, activeFor time.Duration, logger *zap.Logger) *CacheWarmer {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	normalized := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		normalized = append(normalized, strings.ToLower(address))
+	}
+	return &CacheWarmer{
+		manager:    manager,
+		interval:   interval,
+		activeFor:  activeFor,
+		addresses:  normalized,
+		logger:     logger,
+		now:        time.Now,
+		lastActive: make(map[string]time.Time),
+		stop:       make(chan struct{}),
+	}
+}
+
+// Touch records a request by address, keeping it warm for the active window
+func (w *CacheWarmer) Touch(address string) {
+	if !isValidAddress(address) {
+		return
+	}
+	address = strings.ToLower(address)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.lastActive[address]; !ok && len(w.lastActive) >= maxWarmAddresses {
+		w.evictOldest()
+	}
+	w.lastActive[address] = w.now()
+}
+
+// evictOldest drops the least recently active address. The caller holds mu.
+func (w *CacheWarmer) evictOldest() {
+	var oldest string
+	var oldestAt time.Time
+	for address, at := range w.lastActive {
+		if oldest == "" || at.Before(oldestAt) {
+			oldest, oldestAt = address, at
+		}
+	}
+	delete(w.lastActive, oldest)
+}
+
+// hotAddresses returns the configured addresses and those active within the
+// window, forgetting the ones that went quiet
+func (w *CacheWarmer) hotAddresses() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	seen := make(map[string]bool, len(w.addresses)+len(w.lastActive))
+	hot := make([]string, 0, len(w.addresses)+len(w.lastActive))
+	for _, address := range w.addresses {
+		if !seen[address] {
+			seen[address] = true
+			hot = append(hot, address)
+		}
+	}
+
+	cutoff := w.now().Add(-w.activeFor)
+	for address, at := range w.lastActive {
+		if at.Before(cutoff) {
+			delete(w.lastActive, address)
+			continue
+		}
+		if !seen[address] {
+			seen[address] = true
+			hot = append(hot, address)
+		}
+	}
+	return hot
+}
+
+// Start warms the cache immediately and then every interval until Stop is called
+func (w *CacheWarmer) Start() {
+	go func() {
+		w.runLogged()
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+				w.runLogged()
+			}
+		}
+	}()
+}
+
+// Stop stops the warmer
+func (w *CacheWarmer) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+}
+
+// runLogged warms the cache once, logging the outcome
+func (w *CacheWarmer) runLogged() {
+	ctx, cancel := context.WithTimeout(context.Background(), w.interval)
+	defer cancel()
+
+	refreshed, err := w.Run(ctx)
+	if err != nil {
+		w.logger.Warn("cache warming run stopped early", zap.Int("refreshed", refreshed), zap.Error(err))
+		return
+	}
+	if refreshed > 0 {
+		w.logger.Debug("warmed blockchain cache", zap.Int("refreshed", refreshed))
+	}
+}
+
+// Run refreshes the cached results of every blockchain rule for every hot
+// address once, returning the number of evaluations made
+func (w *CacheWarmer) Run(ctx context.Context) (int, error) {
+	rules := w.warmableRules()
+	if len(rules) == 0 {
+		return 0, nil
+	}
+
+	ctx = withCacheRefresh(ctx)
+	refreshed := 0
+	for _, address := range w.hotAddresses() {
+		for _, rule := range rules {
+			if err := ctx.Err(); err != nil {
+				return refreshed, err
+			}
+			// Rules fail closed and log their own errors; the result is irrelevant
+			_, _ = rule.Evaluate(ctx, address, nil)
+			refreshed++
+		}
+	}
+	return refreshed, nil
+}
+
+// warmableRules returns the rules of loaded policies whose per-address
+// blockchain reads are cached
+func (w *CacheWarmer) warmableRules() []Rule {
+	var rules []Rule
+	for _, p := range w.manager.GetAllPolicies() {
+		WalkRules(p.Rules, func(rule Rule) {
+			switch rule.(type) {
+			case *ERC20MinBalanceRule, *ERC721OwnerRule, *ERC721MinBalanceRule, *StakedBalanceRule, *VotingPowerRule, *ContractCallRule,
+				*LPPositionRule, *HoldingDurationRule, *CodeExistsRule, *ContractDeployerRule:
+				rules = append(rules, rule)
+			}
+		})
+	}
+	return rules
+}
@@ -0,0 +1,126 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/chain"
+)
+
+// countingProvider counts the RPC calls made through it
+type countingProvider struct {
+	BlockchainProvider
+	calls atomic.Int32
+}
+
+func (p *countingProvider) Call(ctx context.Context, method string, params []interface{}) ([]byte, error) {
+	p.calls.Add(1)
+	return p.BlockchainProvider.Call(ctx, method, params)
+}
+
+func newWarmerFixture(t *testing.T) (*PolicyManager, *MockBlockchainProvider, *countingProvider, *chain.Cache) {
+	t.Helper()
+	mock := &MockBlockchainProvider{}
+	provider := &countingProvider{BlockchainProvider: mock}
+	cache := chain.NewCache(time.Minute)
+
+	manager := NewPolicyManager(provider, cache)
+	manager.AddPolicy(NewPolicy("GET", "/api/data", "AND", []Rule{
+		NewERC20MinBalanceRule("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", big.NewInt(1000), 1),
+		NewClaimMatchRule("tier", "eq", "gold"),
+	}))
+	return manager, mock, provider, cache
+}
+
+func TestCacheWarmer_RefreshesActiveAndConfiguredAddresses(t *testing.T) {
+	active := "0x1234567890abcdef1234567890abcdef12345678"
+	vip := "0xABCDEF1234567890ABCDEF1234567890ABCDEF12"
+	manager, mock, provider, _ := newWarmerFixture(t)
+	mock.SetBalance(active, big.NewInt(5000))
+	rule := manager.GetAllPolicies()[0].Rules[0]
+
+	warmer := NewCacheWarmer(manager, []string{vip}, time.Minute, time.Hour, nil)
+	warmer.Touch(active)
+	warmer.Touch("not-an-address")
+
+	refreshed, err := warmer.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, refreshed, "one blockchain rule for two addresses")
+	assert.Equal(t, int32(2), provider.calls.Load())
+
+	// Requests are now served from the warmed cache
+	allowed, err := rule.Evaluate(context.Background(), active, nil)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, int32(2), provider.calls.Load())
+
+	// The next run refreshes entries that are still cached
+	mock.SetBalance(active, big.NewInt(10))
+	_, err = warmer.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), provider.calls.Load())
+
+	allowed, err = rule.Evaluate(context.Background(), active, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed, "the refreshed balance replaced the cached one")
+}
+
+func TestCacheWarmer_ForgetsInactiveAddresses(t *testing.T) {
+	manager, _, provider, _ := newWarmerFixture(t)
+	warmer := NewCacheWarmer(manager, nil, time.Minute, 10*time.Minute, nil)
+	now := time.Now()
+	warmer.now = func() time.Time { return now }
+
+	warmer.Touch("0x1234567890abcdef1234567890abcdef12345678")
+	now = now.Add(11 * time.Minute)
+
+	refreshed, err := warmer.Run(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, refreshed)
+	assert.Zero(t, provider.calls.Load())
+	assert.Empty(t, warmer.lastActive)
+}
+
+func TestCacheWarmer_CapsActiveAddresses(t *testing.T) {
+	manager, _, _, _ := newWarmerFixture(t)
+	warmer := NewCacheWarmer(manager, nil, time.Minute, time.Hour, nil)
+	now := time.Now()
+	warmer.now = func() time.Time { return now }
+
+	first := "0x0000000000000000000000000000000000000001"
+	warmer.Touch(first)
+	for i := 2; i <= maxWarmAddresses+1; i++ {
+		now = now.Add(time.Millisecond)
+		warmer.Touch(fmt.Sprintf("0x%040x", i))
+	}
+
+	assert.Len(t, warmer.lastActive, maxWarmAddresses)
+	assert.NotContains(t, warmer.lastActive, first, "the least recently active address is dropped")
+}
+
+func TestCacheWarmer_NoBlockchainRules(t *testing.T) {
+	manager := NewPolicyManager(nil, nil)
+	manager.AddPolicy(NewPolicy("GET", "/api/data", "AND", []Rule{NewClaimMatchRule("tier", "eq", "gold")}))
+
+	warmer := NewCacheWarmer(manager, []string{"0x1234567890abcdef1234567890abcdef12345678"}, time.Minute, time.Hour, nil)
+	refreshed, err := warmer.Run(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, refreshed)
+}
+
+func TestCacheWarmer_StopsOnCancelledContext(t *testing.T) {
+	manager, _, provider, _ := newWarmerFixture(t)
+	warmer := NewCacheWarmer(manager, []string{"0x1234567890abcdef1234567890abcdef12345678"}, time.Minute, time.Hour, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := warmer.Run(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, provider.calls.Load())
+}
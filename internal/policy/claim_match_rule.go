@@ -0,0 +1,174 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yourusername/gatekeeper/internal/auth"
+)
+
+// Claim match operators
+const (
+	ClaimOpEquals   = "eq"       // Claim equals value
+	ClaimOpIn       = "in"       // Claim is one of a list of values
+	ClaimOpContains = "contains" // List claim contains value
+	ClaimOpGT       = "gt"       // Numeric claim greater than value
+	ClaimOpGTE      = "gte"      // Numeric claim greater than or equal to value
+	ClaimOpLT       = "lt"       // Numeric claim less than value
+	ClaimOpLTE      = "lte"      // Numeric claim less than or equal to value
+)
+
+// ClaimMatchRule checks a JWT claim against a value, so policies can use enriched
+// claims (role, tier, ens, ...) without a rule type per claim. Values are compared
+// as decoded from JSON: strings, numbers and booleans only equal values of the same kind.
+type ClaimMatchRule struct {
+	Claim    string
+	Operator string
+	Value    interface{} // A list for ClaimOpIn, a number for numeric operators
+}
+
+// NewClaimMatchRule creates a new claim match rule
+func NewClaimMatchRule(claim, operator string, value interface{}) *ClaimMatchRule {
+	return &ClaimMatchRule{
+		Claim:    claim,
+		Operator: operator,
+		Value:    value,
+	}
+}
+
+// Type returns the rule type
+func (r *ClaimMatchRule) Type() RuleType {
+	return ClaimMatchRuleType
+}
+
+// Validate checks if the rule parameters are valid
+func (r *ClaimMatchRule) Validate() error {
+	if r.Claim == "" {
+		return fmt.Errorf("claim cannot be empty")
+	}
+
+	switch r.Operator {
+	case ClaimOpEquals, ClaimOpContains:
+		if !isScalarClaim(r.Value) {
+			return fmt.Errorf("value for %s must be a string, number or boolean", r.Operator)
+		}
+	case ClaimOpIn:
+		values, ok := r.Value.([]interface{})
+		if !ok || len(values) == 0 {
+			return fmt.Errorf("value for %s must be a non-empty list", r.Operator)
+		}
+	case ClaimOpGT, ClaimOpGTE, ClaimOpLT, ClaimOpLTE:
+		if _, ok := claimNumber(r.Value); !ok {
+			return fmt.Errorf("value for %s must be a number", r.Operator)
+		}
+	default:
+		return fmt.Errorf("unknown operator %q", r.Operator)
+	}
+	return nil
+}
+
+// Evaluate checks the claim. Evaluates to false when the claim is missing or has
+// the wrong type for the operator.
+func (r *ClaimMatchRule) Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	if claims == nil {
+		return false, nil
+	}
+	claim, ok := claims.Claim(r.Claim)
+	if !ok {
+		return false, nil
+	}
+
+	switch r.Operator {
+	case ClaimOpEquals:
+		return claimEqual(claim, r.Value), nil
+	case ClaimOpIn:
+		values, _ := r.Value.([]interface{})
+		for _, value := range values {
+			if claimEqual(claim, value) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case ClaimOpContains:
+		for _, element := range claimList(claim) {
+			if claimEqual(element, r.Value) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case ClaimOpGT, ClaimOpGTE, ClaimOpLT, ClaimOpLTE:
+		actual, ok := claimNumber(claim)
+		expected, valid := claimNumber(r.Value)
+		if !ok || !valid {
+			return false, nil
+		}
+		switch r.Operator {
+		case ClaimOpGT:
+			return actual > expected, nil
+		case ClaimOpGTE:
+			return actual >= expected, nil
+		case ClaimOpLT:
+			return actual < expected, nil
+		default:
+			return actual <= expected, nil
+		}
+	}
+	return false, fmt.Errorf("unknown operator %q", r.Operator)
+}
+
+// claimEqual compares two claim values of the same kind
+func claimEqual(a, b interface{}) bool {
+	if x, ok := claimNumber(a); ok {
+		y, ok := claimNumber(b)
+		return ok && x == y
+	}
+	switch x := a.(type) {
+	case string:
+		y, ok := b.(string)
+		return ok && x == y
+	case bool:
+		y, ok := b.(bool)
+		return ok && x == y
+	}
+	return false
+}
+
+// claimNumber converts a numeric claim value to float64
+func claimNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// claimList returns the elements of a list claim, or nil if the claim is not a list
+func claimList(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		return v
+	case []string:
+		list := make([]interface{}, len(v))
+		for i, s := range v {
+			list[i] = s
+		}
+		return list
+	}
+	return nil
+}
+
+// isScalarClaim reports whether value is a string, number or boolean
+func isScalarClaim(value interface{}) bool {
+	if _, ok := claimNumber(value); ok {
+		return true
+	}
+	switch value.(type) {
+	case string, bool:
+		return true
+	}
+	return false
+}
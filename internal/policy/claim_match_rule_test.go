@@ -0,0 +1,76 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/auth"
+)
+
+// TestClaimMatchRule_Validate validates operators and value types
+func TestClaimMatchRule_Validate(t *testing.T) {
+	assert.NoError(t, NewClaimMatchRule("role", ClaimOpEquals, "admin").Validate())
+	assert.NoError(t, NewClaimMatchRule("tier", ClaimOpIn, []interface{}{"gold", "silver"}).Validate())
+	assert.NoError(t, NewClaimMatchRule("scopes", ClaimOpContains, "admin").Validate())
+	assert.NoError(t, NewClaimMatchRule("level", ClaimOpGTE, float64(3)).Validate())
+
+	assert.Error(t, NewClaimMatchRule("", ClaimOpEquals, "admin").Validate())
+	assert.Error(t, NewClaimMatchRule("role", "like", "admin").Validate())
+	assert.Error(t, NewClaimMatchRule("role", ClaimOpEquals, nil).Validate())
+	assert.Error(t, NewClaimMatchRule("tier", ClaimOpIn, "gold").Validate())
+	assert.Error(t, NewClaimMatchRule("tier", ClaimOpIn, []interface{}{}).Validate())
+	assert.Error(t, NewClaimMatchRule("level", ClaimOpGT, "3").Validate())
+}
+
+// TestClaimMatchRule_Evaluate matches standard and extra claims
+func TestClaimMatchRule_Evaluate(t *testing.T) {
+	claims := &auth.Claims{
+		Address: testUserAddr,
+		Scopes:  []string{"read", "admin"},
+		Extra: map[string]interface{}{
+			"role":     "admin",
+			"tier":     "gold",
+			"level":    float64(3),
+			"verified": true,
+			"groups":   []interface{}{"dao", "core"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		rule     *ClaimMatchRule
+		expected bool
+	}{
+		{"string equals", NewClaimMatchRule("role", ClaimOpEquals, "admin"), true},
+		{"string differs", NewClaimMatchRule("role", ClaimOpEquals, "user"), false},
+		{"bool equals", NewClaimMatchRule("verified", ClaimOpEquals, true), true},
+		{"kinds differ", NewClaimMatchRule("level", ClaimOpEquals, "3"), false},
+		{"address equals", NewClaimMatchRule("address", ClaimOpEquals, testUserAddr), true},
+		{"in list", NewClaimMatchRule("tier", ClaimOpIn, []interface{}{"gold", "silver"}), true},
+		{"not in list", NewClaimMatchRule("tier", ClaimOpIn, []interface{}{"bronze"}), false},
+		{"list claim contains", NewClaimMatchRule("groups", ClaimOpContains, "dao"), true},
+		{"scopes contains", NewClaimMatchRule("scopes", ClaimOpContains, "admin"), true},
+		{"scalar claim contains", NewClaimMatchRule("role", ClaimOpContains, "admin"), false},
+		{"greater than", NewClaimMatchRule("level", ClaimOpGT, float64(2)), true},
+		{"not greater than", NewClaimMatchRule("level", ClaimOpGT, float64(3)), false},
+		{"greater or equal", NewClaimMatchRule("level", ClaimOpGTE, float64(3)), true},
+		{"less than", NewClaimMatchRule("level", ClaimOpLT, float64(3)), false},
+		{"less or equal", NewClaimMatchRule("level", ClaimOpLTE, float64(3)), true},
+		{"non-numeric claim", NewClaimMatchRule("role", ClaimOpGT, float64(0)), false},
+		{"missing claim", NewClaimMatchRule("ens", ClaimOpEquals, "alice.eth"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, err := tt.rule.Evaluate(context.Background(), testUserAddr, claims)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, allowed)
+		})
+	}
+
+	allowed, err := NewClaimMatchRule("role", ClaimOpEquals, "admin").Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
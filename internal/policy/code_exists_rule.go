@@ -0,0 +1,122 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/chain"
+	"go.uber.org/zap"
+)
+
+// delegationPrefix starts the code of an EOA that delegates to a contract
+// (EIP-7702). The account still has a private key, so it is not a contract.
+const delegationPrefix = "0xef0100"
+
+// CodeExistsRule checks if the address is a contract, i.e. has code deployed on
+// chain. Wrap it in a "not" rule to only admit externally owned accounts.
+type CodeExistsRule struct {
+	ChainID  uint64
+	cacheTTL // Optional TTL of the rule's cached reads
+	// cache and provider will be set by manager
+	cache    CacheProvider
+	provider BlockchainProvider
+	logger   *zap.Logger
+}
+
+// NewCodeExistsRule creates a new code exists rule
+func NewCodeExistsRule(chainID uint64) *CodeExistsRule {
+	logger, _ := zap.NewProduction()
+	return &CodeExistsRule{
+		ChainID: chainID,
+		logger:  logger,
+	}
+}
+
+// Type returns the rule type
+func (r *CodeExistsRule) Type() RuleType {
+	return CodeExistsRuleType
+}
+
+// Validate checks if the rule parameters are valid
+func (r *CodeExistsRule) Validate() error {
+	if r.ChainID == 0 {
+		return fmt.Errorf("chain ID cannot be zero")
+	}
+	return nil
+}
+
+// Evaluate checks if the address has code (requires provider and cache to be set)
+// This implementation follows fail-closed security: on any error, return false
+func (r *CodeExistsRule) Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	if !isValidAddress(address) {
+		r.logger.Error("invalid address format",
+			zap.String("address", address),
+			zap.String("rule", "CodeExists"))
+		return false, nil // Fail-closed
+	}
+
+	if r.provider == nil {
+		r.logger.Warn("no blockchain provider configured",
+			zap.String("rule", "CodeExists"))
+		return false, nil
+	}
+
+	// Generate cache key: "code_exists:{chainID}:{address}:"
+	chainIDStr := strconv.FormatUint(r.ChainID, 10)
+	cacheKey := chain.CacheKey("code_exists", chainIDStr, strings.ToLower(address), "")
+
+	if r.cache != nil && !refreshingCache(ctx) {
+		if cached, ok := r.cache.Get(cacheKey); ok {
+			if isContract, ok := cached.(bool); ok {
+				return isContract, nil
+			}
+		}
+	}
+
+	response, err := callProvider(ctx, r.provider, "eth_getCode", []interface{}{address, "latest"})
+	if err != nil {
+		logRPCFailure(r.logger, err, "RPC call failed for code check",
+			zap.String("address", address),
+			zap.Uint64("chainID", r.ChainID))
+		return false, nil
+	}
+
+	code, err := parseJSONRPCResponse(response)
+	if err != nil {
+		r.logger.Error("failed to parse code response",
+			zap.Error(err),
+			zap.String("address", address))
+		return false, nil
+	}
+
+	code = strings.ToLower(code)
+	isContract := code != "0x" && !strings.HasPrefix(code, delegationPrefix)
+
+	if r.cache != nil {
+		r.cache.Set(cacheKey, isContract)
+	}
+
+	r.logger.Info("code check completed",
+		zap.String("address", address),
+		zap.Bool("isContract", isContract))
+
+	return isContract, nil
+}
+
+// SetProvider sets the blockchain provider for RPC calls
+func (r *CodeExistsRule) SetProvider(provider BlockchainProvider) {
+	r.provider = provider
+}
+
+// SetCache sets the cache for storing results
+func (r *CodeExistsRule) SetCache(cache CacheProvider) {
+	r.cache = withCacheTTL(cache, r.CacheTTL())
+}
+
+// SetLogger sets the logger for the rule
+func (r *CodeExistsRule) SetLogger(logger *zap.Logger) {
+	r.logger = logger
+}
@@ -0,0 +1,106 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// codeProvider answers eth_getCode from a map of address to code
+type codeProvider struct {
+	code  map[string]string
+	err   error
+	calls int
+}
+
+func (p *codeProvider) Call(ctx context.Context, method string, params []interface{}) ([]byte, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	if method != "eth_getCode" {
+		return nil, errors.New("unexpected method " + method)
+	}
+	return rpcResult(p.code[params[0].(string)]), nil
+}
+
+func (p *codeProvider) HealthCheck(ctx context.Context) bool {
+	return true
+}
+
+// TestCodeExistsRule_Validate validates rule parameters
+func TestCodeExistsRule_Validate(t *testing.T) {
+	assert.NoError(t, NewCodeExistsRule(1).Validate())
+	assert.Error(t, NewCodeExistsRule(0).Validate())
+}
+
+// TestCodeExistsRule_Evaluate tells contracts from externally owned accounts
+func TestCodeExistsRule_Evaluate(t *testing.T) {
+	const (
+		contractAddr  = "0x1111111111111111111111111111111111111111"
+		delegatedAddr = "0x2222222222222222222222222222222222222222"
+	)
+	provider := &codeProvider{code: map[string]string{
+		contractAddr:  "6080604052",
+		delegatedAddr: "ef0100" + "3333333333333333333333333333333333333333",
+	}}
+
+	rule := NewCodeExistsRule(1)
+	rule.SetProvider(provider)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		address  string
+		expected bool
+	}{
+		{"contract", contractAddr, true},
+		{"externally owned account", testUserAddr, false},
+		{"delegated account", delegatedAddr, false},
+		{"invalid address", "0x1234", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := rule.Evaluate(ctx, tt.address, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	// Wrapped in not, the rule only admits externally owned accounts
+	eoaOnly := Not(rule)
+	result, err := eoaOnly.Evaluate(ctx, testUserAddr, nil)
+	require.NoError(t, err)
+	assert.True(t, result)
+}
+
+// TestCodeExistsRule_Cache serves repeated checks from the cache
+func TestCodeExistsRule_Cache(t *testing.T) {
+	provider := &codeProvider{code: map[string]string{}}
+	rule := NewCodeExistsRule(1)
+	rule.SetProvider(provider)
+	rule.SetCache(&MockCache{})
+
+	for i := 0; i < 2; i++ {
+		result, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+		require.NoError(t, err)
+		assert.False(t, result)
+	}
+	assert.Equal(t, 1, provider.calls)
+}
+
+// TestCodeExistsRule_FailClosed denies access when the RPC call fails
+func TestCodeExistsRule_FailClosed(t *testing.T) {
+	rule := NewCodeExistsRule(1)
+	result, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, result, "no provider")
+
+	rule.SetProvider(&codeProvider{err: errors.New("rpc down")})
+	result, err = rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, result)
+}
@@ -0,0 +1,233 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/chain"
+	"go.uber.org/zap"
+)
+
+// Comparisons of a ContractCallRule return value. ComparisonTrue passes on an ABI
+// bool true (any non-zero word) and takes no value.
+const (
+	ComparisonGreaterOrEqual = ">="
+	ComparisonGreater        = ">"
+	ComparisonLessOrEqual    = "<="
+	ComparisonLess           = "<"
+	ComparisonEqual          = "=="
+	ComparisonNotEqual       = "!="
+	ComparisonTrue           = "true"
+)
+
+// ContractCallRule calls any view function of a contract and compares a return
+// value, for gates that no dedicated rule covers: vault shares, voting power,
+// membership flags and the like. The function is given by its Solidity signature,
+// e.g. "isMember(address)", or its 4-byte selector, e.g. "0x70a08231"; CallerArgument
+// in the arguments is replaced by the address being evaluated.
+type ContractCallRule struct {
+	ContractAddress string
+	Function        string   // Solidity signature or 4-byte selector
+	Args            []string // CallerArgument, 0x-prefixed addresses or decimal integers
+	ResultIndex     int      // 32-byte word of the return data to compare
+	Comparison      string   // One of the Comparison constants
+	Value           *big.Int // Value compared against, nil with ComparisonTrue
+	ChainID         uint64
+	cacheTTL        // Optional TTL of the rule's cached reads
+	// cache and provider will be set by manager
+	cache    CacheProvider
+	provider BlockchainProvider
+	logger   *zap.Logger
+}
+
+// NewContractCallRule creates a new contract call rule
+func NewContractCallRule(contractAddress, function string, args []string, resultIndex int, comparison string, value *big.Int, chainID uint64) *ContractCallRule {
+	logger, _ := zap.NewProduction()
+	return &ContractCallRule{
+		ContractAddress: contractAddress,
+		Function:        function,
+		Args:            args,
+		ResultIndex:     resultIndex,
+		Comparison:      comparison,
+		Value:           value,
+		ChainID:         chainID,
+		logger:          logger,
+	}
+}
+
+// Type returns the rule type
+func (r *ContractCallRule) Type() RuleType {
+	return ContractCallRuleType
+}
+
+// Validate checks if the rule parameters are valid
+func (r *ContractCallRule) Validate() error {
+	if !isValidAddress(r.ContractAddress) {
+		return fmt.Errorf("invalid contract address: %s", r.ContractAddress)
+	}
+
+	if isFunctionSelector(r.Function) {
+		// Without a signature, each argument is encoded by its form
+		for i, arg := range r.Args {
+			paramType := "uint256"
+			if strings.HasPrefix(arg, "0x") || arg == CallerArgument {
+				paramType = "address"
+			}
+			if err := validateArgument(paramType, arg); err != nil {
+				return fmt.Errorf("argument %d: %w", i, err)
+			}
+		}
+	} else {
+		paramTypes, err := parseFunctionSignature(r.Function)
+		if err != nil {
+			return err
+		}
+		if len(paramTypes) != len(r.Args) {
+			return fmt.Errorf("function %s takes %d arguments, got %d", r.Function, len(paramTypes), len(r.Args))
+		}
+		for i, paramType := range paramTypes {
+			if err := validateArgument(paramType, r.Args[i]); err != nil {
+				return fmt.Errorf("argument %d: %w", i, err)
+			}
+		}
+	}
+
+	if r.ResultIndex < 0 {
+		return fmt.Errorf("result index cannot be negative")
+	}
+
+	switch r.Comparison {
+	case ComparisonTrue:
+		if r.Value != nil {
+			return fmt.Errorf("comparison %s takes no value", ComparisonTrue)
+		}
+	case ComparisonGreaterOrEqual, ComparisonGreater, ComparisonLessOrEqual, ComparisonLess, ComparisonEqual, ComparisonNotEqual:
+		if r.Value == nil || r.Value.Sign() < 0 {
+			return fmt.Errorf("comparison %s requires a non-negative value", r.Comparison)
+		}
+	default:
+		return fmt.Errorf("invalid comparison %q (use >=, >, <=, <, ==, != or true)", r.Comparison)
+	}
+
+	if r.ChainID == 0 {
+		return fmt.Errorf("chain ID cannot be zero")
+	}
+	return nil
+}
+
+// Evaluate calls the function and compares its return value (requires provider and cache to be set)
+// This implementation follows fail-closed security: on any error, return false
+func (r *ContractCallRule) Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	if !isValidAddress(address) {
+		r.logger.Error("invalid address format",
+			zap.String("address", address),
+			zap.String("rule", "ContractCall"))
+		return false, nil // Fail-closed
+	}
+
+	if r.provider == nil {
+		r.logger.Warn("no blockchain provider configured",
+			zap.String("rule", "ContractCall"))
+		return false, nil
+	}
+
+	calldata, err := encodeFunctionCall(r.Function, r.Args, address)
+	if err != nil {
+		r.logger.Error("failed to encode contract call",
+			zap.Error(err),
+			zap.String("function", r.Function))
+		return false, nil
+	}
+
+	// Generate cache key: "contract_call:{chainID}:{contract}:{calldata}:{index}"
+	// The calldata covers the function, its arguments and the user's address
+	chainIDStr := strconv.FormatUint(r.ChainID, 10)
+	cacheKey := chain.CacheKey("contract_call", chainIDStr, strings.ToLower(r.ContractAddress),
+		fmt.Sprintf("%s:%d", calldata, r.ResultIndex))
+
+	var result *big.Int
+	if r.cache != nil && !refreshingCache(ctx) {
+		if cached, ok := r.cache.Get(cacheKey); ok {
+			result, _ = cached.(*big.Int)
+		}
+	}
+
+	if result == nil {
+		resultHex, err := ethCall(ctx, r.provider, r.ContractAddress, calldata)
+		if err != nil {
+			logRPCFailure(r.logger, err, "RPC call failed for contract call",
+				zap.String("contract", r.ContractAddress),
+				zap.String("function", r.Function),
+				zap.String("address", address),
+				zap.Uint64("chainID", r.ChainID))
+			return false, nil
+		}
+
+		result, err = decodeWord(resultHex, r.ResultIndex)
+		if err != nil {
+			r.logger.Error("failed to decode contract call result",
+				zap.Error(err),
+				zap.String("resultHex", resultHex))
+			return false, nil
+		}
+
+		if r.cache != nil {
+			r.cache.Set(cacheKey, result)
+		}
+	}
+
+	passed := r.compare(result)
+
+	r.logger.Info("contract call check completed",
+		zap.String("address", address),
+		zap.String("contract", r.ContractAddress),
+		zap.String("function", r.Function),
+		zap.String("result", result.String()),
+		zap.String("comparison", r.Comparison),
+		zap.Bool("passed", passed))
+
+	return passed, nil
+}
+
+// compare applies the comparison to a return value
+func (r *ContractCallRule) compare(result *big.Int) bool {
+	if r.Comparison == ComparisonTrue {
+		return result.Sign() != 0
+	}
+
+	cmp := result.Cmp(r.Value)
+	switch r.Comparison {
+	case ComparisonGreaterOrEqual:
+		return cmp >= 0
+	case ComparisonGreater:
+		return cmp > 0
+	case ComparisonLessOrEqual:
+		return cmp <= 0
+	case ComparisonLess:
+		return cmp < 0
+	case ComparisonEqual:
+		return cmp == 0
+	case ComparisonNotEqual:
+		return cmp != 0
+	}
+	return false
+}
+
+// SetProvider sets the blockchain provider for RPC calls
+func (r *ContractCallRule) SetProvider(provider BlockchainProvider) {
+	r.provider = provider
+}
+
+// SetCache sets the cache for storing results
+func (r *ContractCallRule) SetCache(cache CacheProvider) {
+	r.cache = withCacheTTL(cache, r.CacheTTL())
+}
+
+// SetLogger sets the logger for the rule
+func (r *ContractCallRule) SetLogger(logger *zap.Logger) {
+	r.logger = logger
+}
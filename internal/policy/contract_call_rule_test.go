@@ -0,0 +1,142 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestContractCallRule_Validate validates rule parameters
+func TestContractCallRule_Validate(t *testing.T) {
+	caller := []string{CallerArgument}
+	value := big.NewInt(100)
+
+	assert.NoError(t, NewContractCallRule(testStakingAddr, "getVotes(address)", caller, 0, ">=", value, 1).Validate())
+	assert.NoError(t, NewContractCallRule(testStakingAddr, "isMember(address)", caller, 0, "true", nil, 1).Validate())
+	assert.NoError(t, NewContractCallRule(testStakingAddr, "0xa230c524", caller, 0, "==", big.NewInt(0), 1).Validate())
+	assert.NoError(t, NewContractCallRule(testStakingAddr, "0x93f1a40b", []string{"3", CallerArgument}, 1, "<", value, 1).Validate())
+
+	assert.Error(t, NewContractCallRule("0x1234", "getVotes(address)", caller, 0, ">=", value, 1).Validate())
+	assert.Error(t, NewContractCallRule(testStakingAddr, "getVotes", caller, 0, ">=", value, 1).Validate())
+	assert.Error(t, NewContractCallRule(testStakingAddr, "0xa230c5", caller, 0, ">=", value, 1).Validate())
+	assert.Error(t, NewContractCallRule(testStakingAddr, "getVotes(address)", nil, 0, ">=", value, 1).Validate())
+	assert.Error(t, NewContractCallRule(testStakingAddr, "0xa230c524", []string{"abc"}, 0, ">=", value, 1).Validate())
+	assert.Error(t, NewContractCallRule(testStakingAddr, "getVotes(address)", caller, -1, ">=", value, 1).Validate())
+	assert.Error(t, NewContractCallRule(testStakingAddr, "getVotes(address)", caller, 0, "=>", value, 1).Validate())
+	assert.Error(t, NewContractCallRule(testStakingAddr, "getVotes(address)", caller, 0, ">=", nil, 1).Validate())
+	assert.Error(t, NewContractCallRule(testStakingAddr, "isMember(address)", caller, 0, "true", value, 1).Validate())
+	assert.Error(t, NewContractCallRule(testStakingAddr, "getVotes(address)", caller, 0, ">=", value, 0).Validate())
+}
+
+// TestContractCallRule_EncodeCall uses a selector as given and substitutes the caller
+func TestContractCallRule_EncodeCall(t *testing.T) {
+	calldata, err := encodeFunctionCall("0xA230C524", []string{CallerArgument}, testUserAddr)
+	require.NoError(t, err)
+	assert.Equal(t, "0xa230c524"+strings.Repeat("0", 24)+strings.TrimPrefix(testUserAddr, "0x"), calldata)
+
+	signed, err := encodeFunctionCall("isMember(address)", []string{CallerArgument}, testUserAddr)
+	require.NoError(t, err)
+	assert.Equal(t, calldata, signed)
+}
+
+// TestContractCallRule_Evaluate applies each comparison to the selected return word
+func TestContractCallRule_Evaluate(t *testing.T) {
+	// Returns (500, true)
+	provider := &stakingProvider{result: fmt.Sprintf("%064x%064x", 500, 1)}
+
+	tests := []struct {
+		name        string
+		resultIndex int
+		comparison  string
+		value       *big.Int
+		expected    bool
+	}{
+		{"greater or equal", 0, ">=", big.NewInt(500), true},
+		{"greater", 0, ">", big.NewInt(500), false},
+		{"less or equal", 0, "<=", big.NewInt(499), false},
+		{"less", 0, "<", big.NewInt(501), true},
+		{"equal", 0, "==", big.NewInt(500), true},
+		{"not equal", 0, "!=", big.NewInt(500), false},
+		{"bool true", 1, "true", nil, true},
+		{"missing return value", 2, "true", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewContractCallRule(testStakingAddr, "position(address)", []string{CallerArgument}, tt.resultIndex, tt.comparison, tt.value, 1)
+			require.NoError(t, rule.Validate())
+			rule.SetProvider(provider)
+
+			result, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+// TestContractCallRule_Evaluate_BoolFalse fails on an ABI bool false
+func TestContractCallRule_Evaluate_BoolFalse(t *testing.T) {
+	rule := NewContractCallRule(testStakingAddr, "isMember(address)", []string{CallerArgument}, 0, "true", nil, 1)
+	rule.SetProvider(&stakingProvider{result: fmt.Sprintf("%064x", 0)})
+
+	result, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, result)
+}
+
+// TestContractCallRule_Evaluate_Cache reuses the cached return value
+func TestContractCallRule_Evaluate_Cache(t *testing.T) {
+	provider := &stakingProvider{result: fmt.Sprintf("%064x", 1)}
+	rule := NewContractCallRule(testStakingAddr, "isMember(address)", []string{CallerArgument}, 0, "true", nil, 1)
+	rule.SetProvider(provider)
+	rule.SetCache(&MockCache{})
+
+	for i := 0; i < 2; i++ {
+		result, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+		require.NoError(t, err)
+		assert.True(t, result)
+	}
+	assert.Len(t, provider.calls, 1)
+}
+
+// TestContractCallRule_Evaluate_FailClosed denies on RPC errors and without a provider
+func TestContractCallRule_Evaluate_FailClosed(t *testing.T) {
+	rule := NewContractCallRule(testStakingAddr, "isMember(address)", []string{CallerArgument}, 0, "true", nil, 1)
+
+	result, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, result)
+
+	rule.SetProvider(&stakingProvider{err: fmt.Errorf("connection refused")})
+	result, err = rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, result)
+}
+
+// TestLoader_ContractCallRule parses a contract_call rule
+func TestLoader_ContractCallRule(t *testing.T) {
+	policy, err := NewPolicyLoader().ParsePolicy([]byte(`{"path": "/api/dao", "method": "GET", "logic": "AND", "rules": [
+		{"type": "contract_call", "contract_address": "` + testStakingAddr + `", "function": "getVotes(address)", "args": ["$address"], "comparison": ">=", "value": "1000", "chain_id": 1}
+	]}`))
+	require.NoError(t, err)
+	rule := policy.Rules[0].(*ContractCallRule)
+	assert.Equal(t, "getVotes(address)", rule.Function)
+	assert.Equal(t, ">=", rule.Comparison)
+	assert.Equal(t, big.NewInt(1000), rule.Value)
+
+	for _, invalid := range []string{
+		`{"type": "contract_call", "function": "getVotes(address)", "args": ["$address"], "comparison": ">=", "value": "1", "chain_id": 1}`,
+		`{"type": "contract_call", "contract_address": "` + testStakingAddr + `", "args": ["$address"], "comparison": ">=", "value": "1", "chain_id": 1}`,
+		`{"type": "contract_call", "contract_address": "` + testStakingAddr + `", "function": "getVotes(address)", "args": ["$address"], "value": "1", "chain_id": 1}`,
+		`{"type": "contract_call", "contract_address": "` + testStakingAddr + `", "function": "getVotes(address)", "args": ["$address"], "comparison": ">=", "value": "lots", "chain_id": 1}`,
+		`{"type": "contract_call", "contract_address": "` + testStakingAddr + `", "function": "getVotes(address)", "args": ["$address"], "comparison": ">=", "value": "1"}`,
+	} {
+		_, err := NewPolicyLoader().ParsePolicy([]byte(`{"path": "/api/dao", "method": "GET", "logic": "AND", "rules": [` + invalid + `]}`))
+		assert.Error(t, err, invalid)
+	}
+}
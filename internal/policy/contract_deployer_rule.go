@@ -0,0 +1,268 @@
+package policy
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/chain"
+	"go.uber.org/zap"
+)
+
+// DeployerFactory finds deployments in the events a factory contract emits when
+// it creates a contract, e.g. "ContractCreated(address,address)" with the deployer
+// in the first indexed parameter and the new contract in the second
+type DeployerFactory struct {
+	Address       string
+	Event         string // Solidity event signature
+	DeployerTopic int    // Indexed parameter holding the deployer (1-3)
+	ContractTopic int    // Indexed parameter holding the contract (1-3), 0 if not indexed
+	FromBlock     uint64 // First block to search, typically the factory's deployment block
+}
+
+// DeployerRegistry reads the deployer of a contract from a view function returning
+// an address: either a registry function taking the contract, e.g.
+// "deployerOf(address)", or a getter on the contract itself, e.g. "deployer()"
+type DeployerRegistry struct {
+	Address  string // Defaults to the contract address
+	Function string
+}
+
+// ContractDeployerRule checks if the address deployed a contract. The deployer is
+// looked up in the events of a factory or in a deployer registry. With a factory
+// and no contract address, the address passes if it deployed any contract through
+// the factory.
+type ContractDeployerRule struct {
+	ContractAddress string
+	Factory         *DeployerFactory
+	Registry        *DeployerRegistry
+	ChainID         uint64
+	cacheTTL        // Optional TTL of the rule's cached reads
+	// cache and provider will be set by manager
+	cache    CacheProvider
+	provider BlockchainProvider
+	logger   *zap.Logger
+}
+
+// NewContractDeployerRule creates a new contract deployer rule with one of factory
+// or registry set
+func NewContractDeployerRule(contractAddress string, factory *DeployerFactory, registry *DeployerRegistry, chainID uint64) *ContractDeployerRule {
+	logger, _ := zap.NewProduction()
+	return &ContractDeployerRule{
+		ContractAddress: contractAddress,
+		Factory:         factory,
+		Registry:        registry,
+		ChainID:         chainID,
+		logger:          logger,
+	}
+}
+
+// Type returns the rule type
+func (r *ContractDeployerRule) Type() RuleType {
+	return ContractDeployerRuleType
+}
+
+// Validate checks if the rule parameters are valid
+func (r *ContractDeployerRule) Validate() error {
+	if r.ContractAddress != "" && !isValidAddress(r.ContractAddress) {
+		return fmt.Errorf("invalid contract address: %s", r.ContractAddress)
+	}
+	if (r.Factory == nil) == (r.Registry == nil) {
+		return fmt.Errorf("exactly one of factory or registry is required")
+	}
+
+	if r.Factory != nil {
+		if err := r.validateFactory(); err != nil {
+			return err
+		}
+	} else if err := r.validateRegistry(); err != nil {
+		return err
+	}
+
+	if r.ChainID == 0 {
+		return fmt.Errorf("chain ID cannot be zero")
+	}
+	return nil
+}
+
+// validateFactory checks the factory event configuration
+func (r *ContractDeployerRule) validateFactory() error {
+	f := r.Factory
+	if !isValidAddress(f.Address) {
+		return fmt.Errorf("invalid factory address: %s", f.Address)
+	}
+	if open := strings.Index(f.Event, "("); open <= 0 || !strings.HasSuffix(f.Event, ")") {
+		return fmt.Errorf("invalid event signature: %s", f.Event)
+	}
+	if f.DeployerTopic < 1 || f.DeployerTopic > 3 {
+		return fmt.Errorf("deployer topic must be between 1 and 3")
+	}
+	if f.ContractTopic < 0 || f.ContractTopic > 3 || f.ContractTopic == f.DeployerTopic {
+		return fmt.Errorf("contract topic must be between 1 and 3 and differ from the deployer topic, or 0")
+	}
+	if r.ContractAddress != "" && f.ContractTopic == 0 {
+		return fmt.Errorf("contract topic is required to check a specific contract")
+	}
+	return nil
+}
+
+// validateRegistry checks the registry function configuration
+func (r *ContractDeployerRule) validateRegistry() error {
+	if r.ContractAddress == "" {
+		return fmt.Errorf("contract address is required with a registry")
+	}
+	if r.Registry.Address != "" && !isValidAddress(r.Registry.Address) {
+		return fmt.Errorf("invalid registry address: %s", r.Registry.Address)
+	}
+
+	paramTypes, err := parseFunctionSignature(r.Registry.Function)
+	if err != nil {
+		return err
+	}
+	if len(paramTypes) > 1 || (len(paramTypes) == 1 && paramTypes[0] != "address") {
+		return fmt.Errorf("registry function %s must take no arguments or the contract address", r.Registry.Function)
+	}
+	return nil
+}
+
+// Evaluate checks if the address deployed the contract (requires provider and cache to be set)
+// This implementation follows fail-closed security: on any error, return false
+func (r *ContractDeployerRule) Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	if !isValidAddress(address) {
+		r.logger.Error("invalid address format",
+			zap.String("address", address),
+			zap.String("rule", "ContractDeployer"))
+		return false, nil // Fail-closed
+	}
+
+	if r.provider == nil {
+		r.logger.Warn("no blockchain provider configured",
+			zap.String("rule", "ContractDeployer"))
+		return false, nil
+	}
+
+	address = strings.ToLower(address)
+	source, identifier := r.cacheScope(address)
+
+	// Generate cache key: "contract_deployer:{chainID}:{factory or registry}:{lookup}"
+	chainIDStr := strconv.FormatUint(r.ChainID, 10)
+	cacheKey := chain.CacheKey("contract_deployer", chainIDStr, source, identifier)
+
+	if r.cache != nil && !refreshingCache(ctx) {
+		if cached, ok := r.cache.Get(cacheKey); ok {
+			if isDeployer, ok := cached.(bool); ok {
+				return isDeployer, nil
+			}
+		}
+	}
+
+	var isDeployer bool
+	var err error
+	if r.Factory != nil {
+		isDeployer, err = r.deployedViaFactory(ctx, address)
+	} else {
+		isDeployer, err = r.deployerInRegistry(ctx, address)
+	}
+	if err != nil {
+		logRPCFailure(r.logger, err, "RPC call failed for contract deployer",
+			zap.String("contract", r.ContractAddress),
+			zap.String("address", address),
+			zap.Uint64("chainID", r.ChainID))
+		return false, nil
+	}
+
+	if r.cache != nil {
+		r.cache.Set(cacheKey, isDeployer)
+	}
+
+	r.logger.Info("contract deployer check completed",
+		zap.String("address", address),
+		zap.String("contract", r.ContractAddress),
+		zap.String("source", source),
+		zap.Bool("isDeployer", isDeployer))
+
+	return isDeployer, nil
+}
+
+// cacheScope returns the contract queried and an identifier of the lookup
+func (r *ContractDeployerRule) cacheScope(address string) (string, string) {
+	contract := strings.ToLower(r.ContractAddress)
+	if r.Factory != nil {
+		return strings.ToLower(r.Factory.Address), fmt.Sprintf("%s:%s:%s", r.Factory.Event, contract, address)
+	}
+	return r.registryAddress(), fmt.Sprintf("%s:%s:%s", r.Registry.Function, contract, address)
+}
+
+// deployedViaFactory searches the factory's events for a deployment by address
+func (r *ContractDeployerRule) deployedViaFactory(ctx context.Context, address string) (bool, error) {
+	f := r.Factory
+	topics := make([]interface{}, 4)
+	topics[0] = "0x" + hex.EncodeToString(crypto.Keccak256([]byte(f.Event)))
+	topics[f.DeployerTopic] = encodeAddress(address)
+	if r.ContractAddress != "" {
+		topics[f.ContractTopic] = encodeAddress(strings.ToLower(r.ContractAddress))
+	}
+	for len(topics) > 1 && topics[len(topics)-1] == nil {
+		topics = topics[:len(topics)-1]
+	}
+
+	logs, err := ethGetLogs(ctx, r.provider, map[string]interface{}{
+		"address":   f.Address,
+		"topics":    topics,
+		"fromBlock": "0x" + strconv.FormatUint(f.FromBlock, 16),
+		"toBlock":   "latest",
+	})
+	if err != nil {
+		return false, err
+	}
+	return len(logs) > 0, nil
+}
+
+// deployerInRegistry reads the deployer from the registry and compares it with address
+func (r *ContractDeployerRule) deployerInRegistry(ctx context.Context, address string) (bool, error) {
+	calldata := "0x" + hex.EncodeToString(crypto.Keccak256([]byte(r.Registry.Function))[:4])
+	if !strings.HasSuffix(r.Registry.Function, "()") {
+		calldata += strings.TrimPrefix(encodeAddress(strings.ToLower(r.ContractAddress)), "0x")
+	}
+
+	resultHex, err := ethCall(ctx, r.provider, r.registryAddress(), calldata)
+	if err != nil {
+		return false, err
+	}
+	word, err := abiWord(resultHex, 0)
+	if err != nil {
+		return false, err
+	}
+	deployer, err := decodeAddress(word)
+	if err != nil {
+		return false, err
+	}
+	return strings.ToLower(deployer) == address, nil
+}
+
+// registryAddress returns the contract the registry function is called on
+func (r *ContractDeployerRule) registryAddress() string {
+	if r.Registry.Address != "" {
+		return strings.ToLower(r.Registry.Address)
+	}
+	return strings.ToLower(r.ContractAddress)
+}
+
+// SetProvider sets the blockchain provider for RPC calls
+func (r *ContractDeployerRule) SetProvider(provider BlockchainProvider) {
+	r.provider = provider
+}
+
+// SetCache sets the cache for storing results
+func (r *ContractDeployerRule) SetCache(cache CacheProvider) {
+	r.cache = withCacheTTL(cache, r.CacheTTL())
+}
+
+// SetLogger sets the logger for the rule
+func (r *ContractDeployerRule) SetLogger(logger *zap.Logger) {
+	r.logger = logger
+}
@@ -0,0 +1,130 @@
+package policy
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testFactoryAddr  = "0xa234567890123456789012345678901234567890" // Mock factory
+	testRegistryAddr = "0xb234567890123456789012345678901234567890" // Mock deployer registry
+	testDeployedAddr = "0xc234567890123456789012345678901234567890" // Mock deployed contract
+	testCreatedEvent = "ContractCreated(address,address)"
+)
+
+// deployerProvider mocks a factory that deployed testDeployedAddr for testUserAddr
+// and a registry recording the same deployment
+type deployerProvider struct {
+	filters []map[string]interface{}
+}
+
+func (p *deployerProvider) Call(ctx context.Context, method string, params []interface{}) ([]byte, error) {
+	switch method {
+	case "eth_getLogs":
+		filter := params[0].(map[string]interface{})
+		p.filters = append(p.filters, filter)
+		topics := filter["topics"].([]interface{})
+		if filter["address"] == testFactoryAddr && topics[1] == encodeAddress(testUserAddr) &&
+			(len(topics) < 3 || topics[2] == encodeAddress(testDeployedAddr)) {
+			return []byte(`{"jsonrpc":"2.0","result":[{"address":"` + testFactoryAddr + `"}],"id":1}`), nil
+		}
+		return []byte(`{"jsonrpc":"2.0","result":[],"id":1}`), nil
+	case "eth_call":
+		callObj := params[0].(map[string]interface{})
+		if callObj["to"] == testRegistryAddr && callObj["data"] == encodeDeployerOfCall(testDeployedAddr) {
+			return rpcResult(strings.TrimPrefix(encodeAddress(testUserAddr), "0x")), nil
+		}
+		return rpcResult(strings.Repeat("0", 64)), nil
+	}
+	return nil, fmt.Errorf("unexpected method %s", method)
+}
+
+func (p *deployerProvider) HealthCheck(ctx context.Context) bool {
+	return true
+}
+
+// encodeDeployerOfCall encodes deployerOf(contract)
+func encodeDeployerOfCall(contract string) string {
+	selector := crypto.Keccak256([]byte("deployerOf(address)"))[:4]
+	return "0x" + hex.EncodeToString(selector) + strings.TrimPrefix(encodeAddress(contract), "0x")
+}
+
+// TestContractDeployerRule_Validate validates rule parameters
+func TestContractDeployerRule_Validate(t *testing.T) {
+	factory := func(deployerTopic, contractTopic int) *DeployerFactory {
+		return &DeployerFactory{Address: testFactoryAddr, Event: testCreatedEvent, DeployerTopic: deployerTopic, ContractTopic: contractTopic}
+	}
+	registry := &DeployerRegistry{Address: testRegistryAddr, Function: "deployerOf(address)"}
+
+	assert.NoError(t, NewContractDeployerRule(testDeployedAddr, factory(1, 2), nil, 1).Validate())
+	assert.NoError(t, NewContractDeployerRule("", factory(1, 0), nil, 1).Validate())
+	assert.NoError(t, NewContractDeployerRule(testDeployedAddr, nil, registry, 1).Validate())
+	assert.NoError(t, NewContractDeployerRule(testDeployedAddr, nil, &DeployerRegistry{Function: "deployer()"}, 1).Validate())
+
+	assert.Error(t, NewContractDeployerRule(testDeployedAddr, nil, nil, 1).Validate(), "no source")
+	assert.Error(t, NewContractDeployerRule(testDeployedAddr, factory(1, 2), registry, 1).Validate(), "two sources")
+	assert.Error(t, NewContractDeployerRule(testDeployedAddr, factory(0, 2), nil, 1).Validate())
+	assert.Error(t, NewContractDeployerRule(testDeployedAddr, factory(1, 1), nil, 1).Validate())
+	assert.Error(t, NewContractDeployerRule(testDeployedAddr, factory(1, 0), nil, 1).Validate(), "contract not indexed")
+	assert.Error(t, NewContractDeployerRule(testDeployedAddr, &DeployerFactory{Address: testFactoryAddr, Event: "Created", DeployerTopic: 1, ContractTopic: 2}, nil, 1).Validate())
+	assert.Error(t, NewContractDeployerRule("", nil, registry, 1).Validate(), "registry without contract")
+	assert.Error(t, NewContractDeployerRule(testDeployedAddr, nil, &DeployerRegistry{Function: "deployerOf(uint256)"}, 1).Validate())
+	assert.Error(t, NewContractDeployerRule("0x1234", nil, registry, 1).Validate())
+	assert.Error(t, NewContractDeployerRule(testDeployedAddr, nil, registry, 0).Validate())
+}
+
+// TestContractDeployerRule_Factory finds deployments in factory events
+func TestContractDeployerRule_Factory(t *testing.T) {
+	provider := &deployerProvider{}
+	ctx := context.Background()
+
+	rule := NewContractDeployerRule(testDeployedAddr, &DeployerFactory{
+		Address: testFactoryAddr, Event: testCreatedEvent, DeployerTopic: 1, ContractTopic: 2, FromBlock: 100,
+	}, nil, 1)
+	rule.SetProvider(provider)
+
+	result, err := rule.Evaluate(ctx, testUserAddr, nil)
+	require.NoError(t, err)
+	assert.True(t, result)
+
+	result, err = rule.Evaluate(ctx, testUserAddr2, nil)
+	require.NoError(t, err)
+	assert.False(t, result)
+
+	filter := provider.filters[0]
+	assert.Equal(t, "0x64", filter["fromBlock"])
+	assert.Equal(t, "0x"+hex.EncodeToString(crypto.Keccak256([]byte(testCreatedEvent))), filter["topics"].([]interface{})[0])
+
+	// Without a contract address any deployment through the factory passes
+	anyContract := NewContractDeployerRule("", &DeployerFactory{Address: testFactoryAddr, Event: testCreatedEvent, DeployerTopic: 1}, nil, 1)
+	anyContract.SetProvider(provider)
+	result, err = anyContract.Evaluate(ctx, testUserAddr, nil)
+	require.NoError(t, err)
+	assert.True(t, result)
+	assert.Len(t, provider.filters[len(provider.filters)-1]["topics"], 2)
+}
+
+// TestContractDeployerRule_Registry compares the registered deployer with the caller
+func TestContractDeployerRule_Registry(t *testing.T) {
+	rule := NewContractDeployerRule(testDeployedAddr, nil, &DeployerRegistry{Address: testRegistryAddr, Function: "deployerOf(address)"}, 1)
+	rule.SetProvider(&deployerProvider{})
+	cache := &MockCache{}
+	rule.SetCache(cache)
+	ctx := context.Background()
+
+	result, err := rule.Evaluate(ctx, testUserAddr, nil)
+	require.NoError(t, err)
+	assert.True(t, result)
+
+	result, err = rule.Evaluate(ctx, testUserAddr2, nil)
+	require.NoError(t, err)
+	assert.False(t, result)
+	assert.Len(t, cache.data, 2)
+}
@@ -0,0 +1,87 @@
+package policy
+
+import (
+	"context"
+
+	"github.com/yourusername/gatekeeper/internal/auth"
+)
+
+// RuleDecision is the outcome of one rule of a policy evaluated in full
+type RuleDecision struct {
+	Type   RuleType
+	Passed bool
+	Err    error // Evaluation error, which fails the rule
+}
+
+// Decide evaluates every rule of the policy, without short-circuiting, and returns
+// whether the policy passes along with the outcome of each rule. The decision is
+// the one Evaluate makes: rules are considered in order, so an error in a rule
+// the evaluation would have reached fails the policy, while one in a rule after
+// the policy was decided does not.
+func (p *Policy) Decide(ctx context.Context, address string, claims *auth.Claims) (bool, []RuleDecision, error) {
+	rules := make([]RuleDecision, len(p.Rules))
+	for i, rule := range p.Rules {
+		result, err := evaluateRule(ctx, rule, address, claims)
+		rules[i] = RuleDecision{Type: rule.Type(), Passed: result && err == nil, Err: err}
+	}
+	allowed, err := p.decide(rules)
+	return allowed, rules, err
+}
+
+// decide replays the evaluation of the policy over the outcomes of its rules
+func (p *Policy) decide(rules []RuleDecision) (bool, error) {
+	score := 0
+	for i, rule := range rules {
+		if p.Logic == "SCORE" && i >= len(p.Points) {
+			break
+		}
+		if rule.Err != nil {
+			return false, rule.Err
+		}
+		switch p.Logic {
+		case "AND":
+			if !rule.Passed {
+				return false, nil
+			}
+		case "OR":
+			if rule.Passed {
+				return true, nil
+			}
+		case "SCORE":
+			if !rule.Passed {
+				continue
+			}
+			score += p.Points[i]
+			if score >= p.Threshold {
+				return true, nil
+			}
+		case "TIER":
+			if rule.Passed {
+				return true, nil
+			}
+		default:
+			return false, nil
+		}
+	}
+	if p.Logic == "TIER" {
+		return p.DefaultTier != "", nil
+	}
+	return p.Logic == "AND", nil
+}
+
+// DecidedTier returns the tier a TIER policy grants given the outcomes of its rules
+// from Decide, or "" if it grants none
+func (p *Policy) DecidedTier(rules []RuleDecision) string {
+	if p.Logic != "TIER" {
+		return ""
+	}
+	for i, rule := range rules {
+		if rule.Err != nil {
+			return ""
+		}
+		if rule.Passed && i < len(p.Tiers) {
+			return p.Tiers[i]
+		}
+	}
+	return p.DefaultTier
+}
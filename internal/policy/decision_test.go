@@ -0,0 +1,66 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/auth"
+)
+
+// TestPolicy_Decide evaluates every rule and decides the way Evaluate does
+func TestPolicy_Decide(t *testing.T) {
+	failure := errors.New("rpc unavailable")
+	claims := &auth.Claims{Address: testUserAddr, Scopes: []string{"read"}}
+	pass := NewHasScopeRule("read")
+	fail := NewHasScopeRule("write")
+	broken := &erroringRule{err: failure}
+
+	tests := []struct {
+		name    string
+		policy  *Policy
+		allowed bool
+		err     error
+	}{
+		{"AND passes", NewPolicy("GET", "/api/data", "AND", []Rule{pass, pass}), true, nil},
+		{"AND fails before error", NewPolicy("GET", "/api/data", "AND", []Rule{fail, broken}), false, nil},
+		{"AND error", NewPolicy("GET", "/api/data", "AND", []Rule{pass, broken}), false, failure},
+		{"OR passes before error", NewPolicy("GET", "/api/data", "OR", []Rule{pass, broken}), true, nil},
+		{"OR error", NewPolicy("GET", "/api/data", "OR", []Rule{broken, pass}), false, failure},
+		{"OR fails", NewPolicy("GET", "/api/data", "OR", []Rule{fail, fail}), false, nil},
+		{"SCORE reaches threshold", &Policy{Logic: "SCORE", Rules: []Rule{fail, pass, broken}, Points: []int{50, 60, 10}, Threshold: 60}, true, nil},
+		{"SCORE error", &Policy{Logic: "SCORE", Rules: []Rule{broken, pass}, Points: []int{50, 60}, Threshold: 60}, false, failure},
+		{"SCORE short", &Policy{Logic: "SCORE", Rules: []Rule{pass, fail}, Points: []int{30, 40}, Threshold: 60}, false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, rules, err := tt.policy.Decide(context.Background(), testUserAddr, claims)
+			assert.Equal(t, tt.allowed, allowed)
+			assert.Equal(t, tt.err, err)
+			require.Len(t, rules, len(tt.policy.Rules))
+
+			evaluated, evalErr := tt.policy.Evaluate(context.Background(), testUserAddr, claims)
+			assert.Equal(t, evaluated, allowed)
+			assert.Equal(t, evalErr, err)
+		})
+	}
+}
+
+// TestPolicy_Decide_RuleOutcomes reports every rule, including those after the
+// policy was decided
+func TestPolicy_Decide_RuleOutcomes(t *testing.T) {
+	failure := errors.New("rpc unavailable")
+	p := NewPolicy("GET", "/api/data", "OR", []Rule{NewHasScopeRule("read"), NewHasScopeRule("write"), &erroringRule{err: failure}})
+
+	allowed, rules, err := p.Decide(context.Background(), testUserAddr, &auth.Claims{Scopes: []string{"read"}})
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, []RuleDecision{
+		{Type: HasScopeRuleType, Passed: true},
+		{Type: HasScopeRuleType, Passed: false},
+		{Type: "erroring", Passed: false, Err: failure},
+	}, rules)
+}
@@ -0,0 +1,166 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"go.uber.org/zap"
+)
+
+// Eligibility outcomes of a rule or policy checked with cached results only
+const (
+	EligibilityPass    = "pass"
+	EligibilityFail    = "fail"
+	EligibilityUnknown = "unknown" // Depends on blockchain data that is not cached, or could not be evaluated
+)
+
+// ErrNotCached is returned for blockchain reads that would need an RPC call while
+// only cached results may be used
+var ErrNotCached = errors.New("blockchain result not cached")
+
+// cacheMisses records whether an evaluation with cached results only needed a
+// result that was not cached
+type cacheMisses struct {
+	missed atomic.Bool
+}
+
+// cachedResultsOnlyKey carries the cacheMisses of an evaluation with cached results only
+type cachedResultsOnlyKey struct{}
+
+// withCachedResultsOnly returns a context in which blockchain rules never call the
+// RPC provider, and the record of the reads they had to skip
+func withCachedResultsOnly(ctx context.Context) (context.Context, *cacheMisses) {
+	misses := &cacheMisses{}
+	return context.WithValue(ctx, cachedResultsOnlyKey{}, misses), misses
+}
+
+// skipUncachedRead reports ErrNotCached, recording the miss, if ctx only allows
+// cached results
+func skipUncachedRead(ctx context.Context) error {
+	misses, ok := ctx.Value(cachedResultsOnlyKey{}).(*cacheMisses)
+	if !ok {
+		return nil
+	}
+	misses.missed.Store(true)
+	return ErrNotCached
+}
+
+// callProvider makes a JSON-RPC call, unless ctx only allows cached results.
+// Failed calls are recorded in ctx, so their outcome is not cached as a decision.
+func callProvider(ctx context.Context, provider BlockchainProvider, method string, params []interface{}) ([]byte, error) {
+	if err := skipUncachedRead(ctx); err != nil {
+		markReadFailed(ctx)
+		return nil, err
+	}
+	response, err := provider.Call(ctx, method, params)
+	if err != nil {
+		markReadFailed(ctx)
+	}
+	return response, err
+}
+
+// logRPCFailure logs a failed blockchain read. Reads skipped because only cached
+// results may be used, or cancelled because the outcome of a policy was decided
+// without them, are expected and logged at debug level.
+func logRPCFailure(logger *zap.Logger, err error, msg string, fields ...zap.Field) {
+	fields = append([]zap.Field{zap.Error(err)}, fields...)
+	if errors.Is(err, ErrNotCached) || errors.Is(err, context.Canceled) {
+		logger.Debug(msg, fields...)
+		return
+	}
+	logger.Error(msg, fields...)
+}
+
+// RuleEligibility is the outcome of one rule of a policy checked with cached results only
+type RuleEligibility struct {
+	Type    RuleType
+	Outcome string
+}
+
+// CheckEligibility evaluates every rule of the policy with cached blockchain results
+// only, never calling the RPC provider, and returns the outcome of the policy and of
+// each rule. A rule that needed an uncached result is unknown, and so is the policy
+// unless its other rules decide it. Quota rules reserve quota as in a real
+// evaluation, so ctx should collect reservations to be released.
+func (p *Policy) CheckEligibility(ctx context.Context, address string, claims *auth.Claims) (string, []RuleEligibility) {
+	rules := make([]RuleEligibility, len(p.Rules))
+	for i, rule := range p.Rules {
+		ruleCtx, misses := withCachedResultsOnly(ctx)
+		result, err := evaluateRule(ruleCtx, rule, address, claims)
+
+		outcome := EligibilityFail
+		switch {
+		case err != nil || misses.missed.Load():
+			outcome = EligibilityUnknown
+		case result:
+			outcome = EligibilityPass
+		}
+		rules[i] = RuleEligibility{Type: rule.Type(), Outcome: outcome}
+	}
+	return p.combineEligibility(rules), rules
+}
+
+// combineEligibility combines the outcomes of the rules by the policy's logic
+func (p *Policy) combineEligibility(rules []RuleEligibility) string {
+	count := func(outcome string) int {
+		n := 0
+		for _, rule := range rules {
+			if rule.Outcome == outcome {
+				n++
+			}
+		}
+		return n
+	}
+
+	switch p.Logic {
+	case "AND":
+		if count(EligibilityFail) > 0 {
+			return EligibilityFail
+		}
+		if count(EligibilityUnknown) > 0 {
+			return EligibilityUnknown
+		}
+		return EligibilityPass
+	case "OR":
+		if count(EligibilityPass) > 0 {
+			return EligibilityPass
+		}
+		if count(EligibilityUnknown) > 0 {
+			return EligibilityUnknown
+		}
+		return EligibilityFail
+	case "SCORE":
+		passed, possible := 0, 0
+		for i, rule := range rules {
+			if i >= len(p.Points) {
+				break
+			}
+			switch rule.Outcome {
+			case EligibilityPass:
+				passed += p.Points[i]
+				possible += p.Points[i]
+			case EligibilityUnknown:
+				possible += p.Points[i]
+			}
+		}
+		if passed >= p.Threshold {
+			return EligibilityPass
+		}
+		if possible < p.Threshold {
+			return EligibilityFail
+		}
+		return EligibilityUnknown
+	case "TIER":
+		// Callers passing no rule still get the default tier, if there is one
+		if p.DefaultTier != "" || count(EligibilityPass) > 0 {
+			return EligibilityPass
+		}
+		if count(EligibilityUnknown) > 0 {
+			return EligibilityUnknown
+		}
+		return EligibilityFail
+	}
+	return EligibilityFail
+}
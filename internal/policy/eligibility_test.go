@@ -0,0 +1,66 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yourusername/gatekeeper/internal/auth"
+)
+
+// TestPolicy_CheckEligibility_CachedOnly reports uncached blockchain rules as unknown
+// without calling the provider, and uses cached results once available
+func TestPolicy_CheckEligibility_CachedOnly(t *testing.T) {
+	provider := &stakingProvider{result: fmt.Sprintf("%064x", 1)}
+	rule := NewContractCallRule(testStakingAddr, "isMember(address)", []string{CallerArgument}, 0, "true", nil, 1)
+	rule.SetProvider(provider)
+	rule.SetCache(&MockCache{})
+	p := NewPolicy("GET", "/api/dao", "AND", []Rule{NewHasScopeRule("dao"), rule})
+	claims := &auth.Claims{Address: testUserAddr, Scopes: []string{"dao"}}
+
+	outcome, rules := p.CheckEligibility(context.Background(), testUserAddr, claims)
+	assert.Equal(t, EligibilityUnknown, outcome)
+	assert.Equal(t, []RuleEligibility{
+		{Type: HasScopeRuleType, Outcome: EligibilityPass},
+		{Type: ContractCallRuleType, Outcome: EligibilityUnknown},
+	}, rules)
+	assert.Empty(t, provider.calls)
+
+	// A real evaluation caches the result
+	_, err := p.Evaluate(context.Background(), testUserAddr, claims)
+	assert.NoError(t, err)
+
+	outcome, _ = p.CheckEligibility(context.Background(), testUserAddr, claims)
+	assert.Equal(t, EligibilityPass, outcome)
+	assert.Len(t, provider.calls, 1)
+
+	outcome, _ = p.CheckEligibility(context.Background(), testUserAddr, &auth.Claims{Address: testUserAddr})
+	assert.Equal(t, EligibilityFail, outcome)
+}
+
+// TestPolicy_CombineEligibility combines rule outcomes by the policy's logic
+func TestPolicy_CombineEligibility(t *testing.T) {
+	outcomes := func(values ...string) []RuleEligibility {
+		rules := make([]RuleEligibility, len(values))
+		for i, value := range values {
+			rules[i] = RuleEligibility{Outcome: value}
+		}
+		return rules
+	}
+	and := &Policy{Logic: "AND"}
+	or := &Policy{Logic: "OR"}
+	score := &Policy{Logic: "SCORE", Points: []int{50, 30, 20}, Threshold: 60}
+
+	assert.Equal(t, EligibilityFail, and.combineEligibility(outcomes("pass", "unknown", "fail")))
+	assert.Equal(t, EligibilityUnknown, and.combineEligibility(outcomes("pass", "unknown")))
+	assert.Equal(t, EligibilityPass, and.combineEligibility(outcomes("pass", "pass")))
+
+	assert.Equal(t, EligibilityPass, or.combineEligibility(outcomes("fail", "unknown", "pass")))
+	assert.Equal(t, EligibilityUnknown, or.combineEligibility(outcomes("fail", "unknown")))
+	assert.Equal(t, EligibilityFail, or.combineEligibility(outcomes("fail", "fail")))
+
+	assert.Equal(t, EligibilityPass, score.combineEligibility(outcomes("pass", "unknown", "pass")))
+	assert.Equal(t, EligibilityUnknown, score.combineEligibility(outcomes("pass", "unknown", "fail")))
+	assert.Equal(t, EligibilityFail, score.combineEligibility(outcomes("fail", "unknown", "pass")))
+}
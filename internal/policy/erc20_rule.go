@@ -17,6 +17,7 @@ type ERC20MinBalanceRule struct {
 	ContractAddress string
 	MinimumBalance  *big.Int
 	ChainID         uint64
+	cacheTTL        // Optional TTL of the rule's cached reads
 	// cache and provider will be set by manager
 	cache    CacheProvider
 	provider BlockchainProvider
@@ -96,7 +97,7 @@ func (r *ERC20MinBalanceRule) Evaluate(ctx context.Context, address string, clai
 	cacheKey := chain.CacheKey("erc20_balance", chainIDStr, normalizedToken, normalizedAddr)
 
 	// Try to get from cache first
-	if r.cache != nil {
+	if r.cache != nil && !refreshingCache(ctx) {
 		if cachedResult, ok := r.cache.Get(cacheKey); ok {
 			if hasBalance, ok := cachedResult.(bool); ok {
 				r.logger.Debug("cache hit for ERC20 balance",
@@ -112,7 +113,7 @@ func (r *ERC20MinBalanceRule) Evaluate(ctx context.Context, address string, clai
 	calldata := encodeERC20BalanceOfCall(r.ContractAddress, address)
 
 	// Call provider with timeout handling
-	response, err := r.provider.Call(ctx, "eth_call", []interface{}{
+	response, err := callProvider(ctx, r.provider, "eth_call", []interface{}{
 		map[string]interface{}{
 			"to":   r.ContractAddress,
 			"data": calldata,
@@ -121,8 +122,7 @@ func (r *ERC20MinBalanceRule) Evaluate(ctx context.Context, address string, clai
 	})
 	if err != nil {
 		// Fail closed on RPC error
-		r.logger.Error("RPC call failed for ERC20 balance",
-			zap.Error(err),
+		logRPCFailure(r.logger, err, "RPC call failed for ERC20 balance",
 			zap.String("token", r.ContractAddress),
 			zap.String("address", address),
 			zap.Uint64("chainID", r.ChainID))
@@ -176,7 +176,7 @@ func (r *ERC20MinBalanceRule) SetProvider(provider BlockchainProvider) {
 
 // SetCache sets the cache for storing results
 func (r *ERC20MinBalanceRule) SetCache(cache CacheProvider) {
-	r.cache = cache
+	r.cache = withCacheTTL(cache, r.CacheTTL())
 }
 
 // SetLogger sets the logger for the rule
@@ -0,0 +1,135 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/chain"
+	"go.uber.org/zap"
+)
+
+// ERC721MinBalanceRule checks if user owns at least a minimum number of tokens from
+// an NFT collection, whichever tokens they are
+type ERC721MinBalanceRule struct {
+	ContractAddress string
+	MinimumBalance  *big.Int // Number of tokens, at least 1
+	ChainID         uint64
+	cacheTTL        // Optional TTL of the rule's cached reads
+	// cache and provider will be set by manager
+	cache    CacheProvider
+	provider BlockchainProvider
+	logger   *zap.Logger
+}
+
+// NewERC721MinBalanceRule creates a new NFT collection ownership rule
+func NewERC721MinBalanceRule(contractAddress string, minimumBalance *big.Int, chainID uint64) *ERC721MinBalanceRule {
+	logger, _ := zap.NewProduction()
+	return &ERC721MinBalanceRule{
+		ContractAddress: contractAddress,
+		MinimumBalance:  minimumBalance,
+		ChainID:         chainID,
+		logger:          logger,
+	}
+}
+
+// Type returns the rule type
+func (r *ERC721MinBalanceRule) Type() RuleType {
+	return ERC721MinBalanceRuleType
+}
+
+// Validate checks if the rule parameters are valid
+func (r *ERC721MinBalanceRule) Validate() error {
+	if !isValidAddress(r.ContractAddress) {
+		return fmt.Errorf("invalid contract address: %s", r.ContractAddress)
+	}
+	if r.MinimumBalance == nil || r.MinimumBalance.Sign() <= 0 {
+		return fmt.Errorf("minimum balance must be at least 1")
+	}
+	if r.ChainID == 0 {
+		return fmt.Errorf("chain ID cannot be zero")
+	}
+	return nil
+}
+
+// Evaluate checks the number of tokens held (requires provider and cache to be set)
+// This implementation follows fail-closed security: on any error, return false
+func (r *ERC721MinBalanceRule) Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	if !isValidAddress(address) {
+		r.logger.Error("invalid address format",
+			zap.String("address", address),
+			zap.String("rule", "ERC721MinBalance"))
+		return false, nil // Fail-closed
+	}
+
+	if r.provider == nil {
+		r.logger.Warn("no blockchain provider configured",
+			zap.String("rule", "ERC721MinBalance"))
+		return false, nil
+	}
+
+	// Generate cache key: "erc721_balance:{chainID}:{collection}:{address}"
+	// The token count is cached, so rules with different minimums share it
+	normalizedAddr := strings.ToLower(address)
+	chainIDStr := strconv.FormatUint(r.ChainID, 10)
+	cacheKey := chain.CacheKey("erc721_balance", chainIDStr, strings.ToLower(r.ContractAddress), normalizedAddr)
+
+	var balance *big.Int
+	if r.cache != nil && !refreshingCache(ctx) {
+		if cached, ok := r.cache.Get(cacheKey); ok {
+			balance, _ = cached.(*big.Int)
+		}
+	}
+
+	if balance == nil {
+		resultHex, err := ethCall(ctx, r.provider, r.ContractAddress, encodeERC721BalanceOfCall(normalizedAddr))
+		if err != nil {
+			logRPCFailure(r.logger, err, "RPC call failed for ERC721 balance",
+				zap.String("token", r.ContractAddress),
+				zap.String("address", address),
+				zap.Uint64("chainID", r.ChainID))
+			return false, nil
+		}
+
+		balance, err = decodeUint256(resultHex)
+		if err != nil {
+			r.logger.Error("failed to decode ERC721 balance",
+				zap.Error(err),
+				zap.String("resultHex", resultHex))
+			return false, nil
+		}
+
+		if r.cache != nil {
+			r.cache.Set(cacheKey, balance)
+		}
+	}
+
+	hasBalance := balance.Cmp(r.MinimumBalance) >= 0
+
+	r.logger.Info("ERC721 balance check completed",
+		zap.String("address", address),
+		zap.String("token", r.ContractAddress),
+		zap.String("balance", balance.String()),
+		zap.String("minimum", r.MinimumBalance.String()),
+		zap.Bool("hasBalance", hasBalance))
+
+	return hasBalance, nil
+}
+
+// SetProvider sets the blockchain provider for RPC calls
+func (r *ERC721MinBalanceRule) SetProvider(provider BlockchainProvider) {
+	r.provider = provider
+}
+
+// SetCache sets the cache for storing results
+func (r *ERC721MinBalanceRule) SetCache(cache CacheProvider) {
+	r.cache = withCacheTTL(cache, r.CacheTTL())
+}
+
+// SetLogger sets the logger for the rule
+func (r *ERC721MinBalanceRule) SetLogger(logger *zap.Logger) {
+	r.logger = logger
+}
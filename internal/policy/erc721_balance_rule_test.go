@@ -0,0 +1,84 @@
+package policy
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestERC721MinBalanceRule_Validate validates rule parameters
+func TestERC721MinBalanceRule_Validate(t *testing.T) {
+	assert.NoError(t, NewERC721MinBalanceRule(testNFTAddr, big.NewInt(1), 1).Validate())
+	assert.NoError(t, NewERC721MinBalanceRule(testNFTAddr, big.NewInt(10), 137).Validate())
+
+	assert.Error(t, NewERC721MinBalanceRule("0x1234", big.NewInt(1), 1).Validate())
+	assert.Error(t, NewERC721MinBalanceRule(testNFTAddr, nil, 1).Validate())
+	assert.Error(t, NewERC721MinBalanceRule(testNFTAddr, big.NewInt(0), 1).Validate())
+	assert.Error(t, NewERC721MinBalanceRule(testNFTAddr, big.NewInt(1), 0).Validate())
+}
+
+// TestERC721MinBalanceRule_Evaluate compares the number of tokens held with the minimum
+func TestERC721MinBalanceRule_Evaluate(t *testing.T) {
+	provider := &MockBlockchainProvider{}
+	provider.SetBalance(testUserAddr, big.NewInt(3))
+
+	tests := []struct {
+		name     string
+		minimum  int64
+		address  string
+		expected bool
+	}{
+		{"more than minimum", 2, testUserAddr, true},
+		{"exact minimum", 3, testUserAddr, true},
+		{"below minimum", 4, testUserAddr, false},
+		{"no tokens", 1, testUserAddr2, false},
+		{"invalid address", 1, "0x1234", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewERC721MinBalanceRule(testNFTAddr, big.NewInt(tt.minimum), 1)
+			rule.SetProvider(provider)
+
+			result, err := rule.Evaluate(context.Background(), tt.address, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+// TestERC721MinBalanceRule_Evaluate_SharesCachedCount reuses the cached count across minimums
+func TestERC721MinBalanceRule_Evaluate_SharesCachedCount(t *testing.T) {
+	cache := &MockCache{}
+	provider := &MockBlockchainProvider{}
+	provider.SetBalance(testUserAddr, big.NewInt(3))
+
+	rule := NewERC721MinBalanceRule(testNFTAddr, big.NewInt(1), 1)
+	rule.SetProvider(provider)
+	rule.SetCache(cache)
+	result, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.True(t, result)
+	assert.Len(t, cache.data, 1)
+
+	// A stricter rule served from the cache applies its own minimum
+	strict := NewERC721MinBalanceRule(testNFTAddr, big.NewInt(5), 1)
+	changed := &MockBlockchainProvider{}
+	changed.SetBalance(testUserAddr, big.NewInt(10))
+	strict.SetProvider(changed)
+	strict.SetCache(cache)
+	result, err = strict.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, result)
+}
+
+// TestERC721MinBalanceRule_Evaluate_NoProvider fails closed without a provider
+func TestERC721MinBalanceRule_Evaluate_NoProvider(t *testing.T) {
+	rule := NewERC721MinBalanceRule(testNFTAddr, big.NewInt(1), 1)
+
+	result, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, result)
+}
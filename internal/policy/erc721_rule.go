@@ -17,6 +17,7 @@ type ERC721OwnerRule struct {
 	ContractAddress string
 	TokenID         *big.Int
 	ChainID         uint64
+	cacheTTL        // Optional TTL of the rule's cached reads
 	// cache and provider will be set by manager
 	cache    CacheProvider
 	provider BlockchainProvider
@@ -97,7 +98,7 @@ func (r *ERC721OwnerRule) Evaluate(ctx context.Context, address string, claims *
 	cacheKey := chain.CacheKey("erc721_owner", chainIDStr, normalizedToken, tokenIDStr)
 
 	// Try to get from cache first
-	if r.cache != nil {
+	if r.cache != nil && !refreshingCache(ctx) {
 		if cachedOwner, ok := r.cache.Get(cacheKey); ok {
 			if ownerAddr, ok := cachedOwner.(string); ok {
 				// Compare cached owner with requested address (case-insensitive)
@@ -117,7 +118,7 @@ func (r *ERC721OwnerRule) Evaluate(ctx context.Context, address string, claims *
 	calldata := encodeERC721OwnerOfCall(r.ContractAddress, r.TokenID)
 
 	// Call provider with timeout handling
-	response, err := r.provider.Call(ctx, "eth_call", []interface{}{
+	response, err := callProvider(ctx, r.provider, "eth_call", []interface{}{
 		map[string]interface{}{
 			"to":   r.ContractAddress,
 			"data": calldata,
@@ -127,8 +128,7 @@ func (r *ERC721OwnerRule) Evaluate(ctx context.Context, address string, claims *
 	if err != nil {
 		// Fail closed on RPC error
 		// This could be because the token doesn't exist (burned) or network error
-		r.logger.Error("RPC call failed for ERC721 owner",
-			zap.Error(err),
+		logRPCFailure(r.logger, err, "RPC call failed for ERC721 owner",
 			zap.String("token", r.ContractAddress),
 			zap.String("tokenID", r.TokenID.String()),
 			zap.Uint64("chainID", r.ChainID))
@@ -191,7 +191,7 @@ func (r *ERC721OwnerRule) SetProvider(provider BlockchainProvider) {
 
 // SetCache sets the cache for storing results
 func (r *ERC721OwnerRule) SetCache(cache CacheProvider) {
-	r.cache = cache
+	r.cache = withCacheTTL(cache, r.CacheTTL())
 }
 
 // SetLogger sets the logger for the rule
@@ -0,0 +1,241 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/chain"
+	"go.uber.org/zap"
+)
+
+// maxTraitTokens bounds how many of a holder's tokens are inspected for a trait
+const maxTraitTokens = 25
+
+// ERC721TraitRule checks if user owns an NFT whose metadata has a specific trait
+// (e.g. "Tier": "Gold"). With a TokenID only that token is checked; otherwise every
+// token the user holds is checked, which requires the contract to implement
+// ERC721Enumerable.
+type ERC721TraitRule struct {
+	ContractAddress string
+	TokenID         *big.Int // Optional
+	ChainID         uint64
+	TraitType       string
+	TraitValue      string
+	cacheTTL        // Optional TTL of the rule's cached reads
+	// cache, provider and resolver will be set by manager
+	cache    CacheProvider
+	provider BlockchainProvider
+	resolver MetadataResolver
+	logger   *zap.Logger
+}
+
+// NewERC721TraitRule creates a new NFT trait rule. tokenID may be nil to match any
+// token of the collection held by the user.
+func NewERC721TraitRule(contractAddress string, tokenID *big.Int, chainID uint64, traitType, traitValue string) *ERC721TraitRule {
+	logger, _ := zap.NewProduction()
+	return &ERC721TraitRule{
+		ContractAddress: contractAddress,
+		TokenID:         tokenID,
+		ChainID:         chainID,
+		TraitType:       traitType,
+		TraitValue:      traitValue,
+		logger:          logger,
+	}
+}
+
+// Type returns the rule type
+func (r *ERC721TraitRule) Type() RuleType {
+	return ERC721TraitRuleType
+}
+
+// Validate checks if the rule parameters are valid
+func (r *ERC721TraitRule) Validate() error {
+	if !isValidAddress(r.ContractAddress) {
+		return fmt.Errorf("invalid contract address: %s", r.ContractAddress)
+	}
+	if r.TokenID != nil && r.TokenID.Sign() < 0 {
+		return fmt.Errorf("token ID cannot be negative")
+	}
+	if r.ChainID == 0 {
+		return fmt.Errorf("chain ID cannot be zero")
+	}
+	if r.TraitType == "" {
+		return fmt.Errorf("trait type cannot be empty")
+	}
+	if r.TraitValue == "" {
+		return fmt.Errorf("trait value cannot be empty")
+	}
+	return nil
+}
+
+// Evaluate checks that the user holds a token with the trait (requires provider and
+// resolver to be set). This implementation follows fail-closed security: on any
+// error, return false
+func (r *ERC721TraitRule) Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	if !isValidAddress(address) {
+		r.logger.Error("invalid address format",
+			zap.String("address", address),
+			zap.String("rule", "ERC721Trait"))
+		return false, nil // Fail-closed
+	}
+
+	if !isValidAddress(r.ContractAddress) {
+		r.logger.Error("invalid token address format",
+			zap.String("token", r.ContractAddress),
+			zap.String("rule", "ERC721Trait"))
+		return false, nil // Fail-closed
+	}
+
+	if r.provider == nil || r.resolver == nil {
+		r.logger.Warn("no blockchain provider or metadata resolver configured",
+			zap.String("rule", "ERC721Trait"))
+		return false, nil
+	}
+
+	tokenIDs, err := r.heldTokens(ctx, address)
+	if err != nil {
+		logRPCFailure(r.logger, err, "failed to list held tokens",
+			zap.String("address", address),
+			zap.String("token", r.ContractAddress),
+			zap.Uint64("chainID", r.ChainID))
+		return false, nil
+	}
+
+	for _, tokenID := range tokenIDs {
+		metadata, err := r.tokenMetadata(ctx, tokenID)
+		if err != nil {
+			r.logger.Warn("failed to resolve token metadata",
+				zap.Error(err),
+				zap.String("token", r.ContractAddress),
+				zap.String("tokenID", tokenID.String()))
+			continue
+		}
+
+		if metadata.HasTrait(r.TraitType, r.TraitValue) {
+			r.logger.Info("ERC721 trait check completed",
+				zap.String("address", address),
+				zap.String("token", r.ContractAddress),
+				zap.String("tokenID", tokenID.String()),
+				zap.String("traitType", r.TraitType),
+				zap.String("traitValue", r.TraitValue),
+				zap.Bool("hasTrait", true))
+			return true, nil
+		}
+	}
+
+	r.logger.Info("ERC721 trait check completed",
+		zap.String("address", address),
+		zap.String("token", r.ContractAddress),
+		zap.Int("tokensChecked", len(tokenIDs)),
+		zap.String("traitType", r.TraitType),
+		zap.String("traitValue", r.TraitValue),
+		zap.Bool("hasTrait", false))
+	return false, nil
+}
+
+// heldTokens returns the IDs of the user's tokens to inspect: the configured token if
+// the user owns it, or up to maxTraitTokens of the tokens the user holds
+func (r *ERC721TraitRule) heldTokens(ctx context.Context, address string) ([]*big.Int, error) {
+	if r.TokenID != nil {
+		result, err := ethCall(ctx, r.provider, r.ContractAddress, encodeERC721OwnerOfCall(r.ContractAddress, r.TokenID))
+		if err != nil {
+			return nil, err
+		}
+		owner, err := decodeAddress(result)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(owner, address) {
+			return nil, nil
+		}
+		return []*big.Int{r.TokenID}, nil
+	}
+
+	result, err := ethCall(ctx, r.provider, r.ContractAddress, encodeERC721BalanceOfCall(address))
+	if err != nil {
+		return nil, err
+	}
+	balance, err := decodeUint256(result)
+	if err != nil {
+		return nil, err
+	}
+
+	count := int64(maxTraitTokens)
+	if balance.IsInt64() && balance.Int64() < count {
+		count = balance.Int64()
+	}
+
+	tokenIDs := make([]*big.Int, 0, count)
+	for i := int64(0); i < count; i++ {
+		result, err := ethCall(ctx, r.provider, r.ContractAddress, encodeERC721TokenOfOwnerByIndexCall(address, i))
+		if err != nil {
+			return nil, fmt.Errorf("tokenOfOwnerByIndex failed (contract may not be enumerable): %w", err)
+		}
+		tokenID, err := decodeUint256(result)
+		if err != nil {
+			return nil, err
+		}
+		tokenIDs = append(tokenIDs, tokenID)
+	}
+	return tokenIDs, nil
+}
+
+// tokenMetadata resolves the metadata of a token via its tokenURI
+func (r *ERC721TraitRule) tokenMetadata(ctx context.Context, tokenID *big.Int) (*TokenMetadata, error) {
+	// Generate cache key: "erc721_token_uri:{chainID}:{token}:{tokenID}"
+	cacheKey := chain.CacheKey("erc721_token_uri", strconv.FormatUint(r.ChainID, 10), strings.ToLower(r.ContractAddress), tokenID.String())
+
+	var uri string
+	if r.cache != nil {
+		if cached, ok := r.cache.Get(cacheKey); ok {
+			uri, _ = cached.(string)
+		}
+	}
+
+	if uri == "" {
+		result, err := ethCall(ctx, r.provider, r.ContractAddress, encodeERC721TokenURICall(tokenID))
+		if err != nil {
+			return nil, err
+		}
+		uri, err = decodeString(result)
+		if err != nil {
+			return nil, err
+		}
+		if uri == "" {
+			return nil, fmt.Errorf("token has no tokenURI")
+		}
+		if r.cache != nil {
+			r.cache.Set(cacheKey, uri)
+		}
+	}
+
+	metadata, err := r.resolver.Resolve(ctx, uri)
+	if err != nil {
+		markReadFailed(ctx)
+	}
+	return metadata, err
+}
+
+// SetProvider sets the blockchain provider for RPC calls
+func (r *ERC721TraitRule) SetProvider(provider BlockchainProvider) {
+	r.provider = provider
+}
+
+// SetCache sets the cache for storing results
+func (r *ERC721TraitRule) SetCache(cache CacheProvider) {
+	r.cache = withCacheTTL(cache, r.CacheTTL())
+}
+
+// SetResolver sets the resolver for token metadata
+func (r *ERC721TraitRule) SetResolver(resolver MetadataResolver) {
+	r.resolver = resolver
+}
+
+// SetLogger sets the logger for the rule
+func (r *ERC721TraitRule) SetLogger(logger *zap.Logger) {
+	r.logger = logger
+}
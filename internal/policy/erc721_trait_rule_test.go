@@ -0,0 +1,188 @@
+package policy
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// traitProvider mocks the ERC721 calls made by trait rules
+type traitProvider struct {
+	owners map[int64]string // tokenID -> owner
+	uris   map[int64]string // tokenID -> tokenURI
+	calls  map[string]int   // selector -> call count
+}
+
+func (p *traitProvider) Call(ctx context.Context, method string, params []interface{}) ([]byte, error) {
+	data := params[0].(map[string]interface{})["data"].(string)
+	selector := data[:10]
+	if p.calls == nil {
+		p.calls = make(map[string]int)
+	}
+	p.calls[selector]++
+
+	switch selector {
+	case ERC721OwnerOfSelector:
+		tokenID, _ := decodeUint256(data[10:74])
+		owner, ok := p.owners[tokenID.Int64()]
+		if !ok {
+			return nil, fmt.Errorf("nonexistent token")
+		}
+		return rpcResult(strings.TrimPrefix(encodeAddress(owner), "0x")), nil
+	case ERC721BalanceOfSelector:
+		owner := "0x" + data[34:74]
+		return rpcResult(fmt.Sprintf("%064x", len(p.tokensOf(owner)))), nil
+	case ERC721TokenOfOwnerByIndexSelector:
+		owner := "0x" + data[34:74]
+		index, _ := decodeUint256(data[74:138])
+		return rpcResult(fmt.Sprintf("%064x", p.tokensOf(owner)[index.Int64()])), nil
+	case ERC721TokenURISelector:
+		tokenID, _ := decodeUint256(data[10:74])
+		return rpcResult(encodeStringResult(p.uris[tokenID.Int64()])), nil
+	}
+	return nil, fmt.Errorf("unknown selector %s", selector)
+}
+
+func (p *traitProvider) HealthCheck(ctx context.Context) bool {
+	return true
+}
+
+// tokensOf returns the token IDs owned by an address, in ascending order
+func (p *traitProvider) tokensOf(owner string) []int64 {
+	var tokens []int64
+	for tokenID := int64(0); tokenID < 100; tokenID++ {
+		if strings.EqualFold(p.owners[tokenID], owner) {
+			tokens = append(tokens, tokenID)
+		}
+	}
+	return tokens
+}
+
+// rpcResult wraps a hex result in a JSON-RPC response
+func rpcResult(hexValue string) []byte {
+	return []byte(fmt.Sprintf(`{"jsonrpc":"2.0","result":"0x%s","id":1}`, hexValue))
+}
+
+// encodeStringResult ABI-encodes a string return value
+func encodeStringResult(value string) string {
+	data := []byte(value)
+	padded := make([]byte, (len(data)+31)/32*32)
+	copy(padded, data)
+	return fmt.Sprintf("%064x%064x", 32, len(data)) + hex.EncodeToString(padded)
+}
+
+// dataURI embeds metadata with the given tier as a data: URI
+func dataURI(tier string) string {
+	return fmt.Sprintf(`data:application/json,{"attributes":[{"trait_type":"Tier","value":"%s"}]}`, tier)
+}
+
+func newTraitRule(provider BlockchainProvider, tokenID *big.Int) *ERC721TraitRule {
+	rule := NewERC721TraitRule(testNFTAddr, tokenID, 1, "Tier", "Gold")
+	rule.SetProvider(provider)
+	rule.SetCache(&MockCache{})
+	rule.SetResolver(NewHTTPMetadataResolver("", time.Second))
+	return rule
+}
+
+// TestDecodeString decodes ABI-encoded strings and rejects malformed ones
+func TestDecodeString(t *testing.T) {
+	decoded, err := decodeString("0x" + encodeStringResult("ipfs://QmCollection/7.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "ipfs://QmCollection/7.json", decoded)
+
+	_, err = decodeString("0x" + fmt.Sprintf("%064x%064x", 32, 100))
+	assert.Error(t, err)
+}
+
+// TestERC721TraitRule_Validate validates rule parameters
+func TestERC721TraitRule_Validate(t *testing.T) {
+	assert.NoError(t, NewERC721TraitRule(testNFTAddr, nil, 1, "Tier", "Gold").Validate())
+	assert.NoError(t, NewERC721TraitRule(testNFTAddr, big.NewInt(7), 1, "Tier", "Gold").Validate())
+	assert.Error(t, NewERC721TraitRule("0x1234", nil, 1, "Tier", "Gold").Validate())
+	assert.Error(t, NewERC721TraitRule(testNFTAddr, big.NewInt(-1), 1, "Tier", "Gold").Validate())
+	assert.Error(t, NewERC721TraitRule(testNFTAddr, nil, 0, "Tier", "Gold").Validate())
+	assert.Error(t, NewERC721TraitRule(testNFTAddr, nil, 1, "", "Gold").Validate())
+}
+
+// TestERC721TraitRule_AnyHeldToken passes when any held token has the trait
+func TestERC721TraitRule_AnyHeldToken(t *testing.T) {
+	provider := &traitProvider{
+		owners: map[int64]string{1: testUserAddr, 2: testUserAddr, 3: testUserAddr2},
+		uris:   map[int64]string{1: dataURI("Silver"), 2: dataURI("Gold"), 3: dataURI("Gold")},
+	}
+	rule := newTraitRule(provider, nil)
+
+	allowed, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	provider.owners[2] = testUserAddr2
+	rule = newTraitRule(provider, nil)
+	allowed, err = rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+// TestERC721TraitRule_SpecificToken requires ownership of the configured token
+func TestERC721TraitRule_SpecificToken(t *testing.T) {
+	provider := &traitProvider{
+		owners: map[int64]string{7: testUserAddr},
+		uris:   map[int64]string{7: dataURI("Gold")},
+	}
+	rule := newTraitRule(provider, big.NewInt(7))
+
+	allowed, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = rule.Evaluate(context.Background(), testUserAddr2, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+// TestERC721TraitRule_CachesTokenURI looks up each tokenURI once
+func TestERC721TraitRule_CachesTokenURI(t *testing.T) {
+	provider := &traitProvider{
+		owners: map[int64]string{7: testUserAddr},
+		uris:   map[int64]string{7: dataURI("Gold")},
+	}
+	rule := newTraitRule(provider, big.NewInt(7))
+
+	for i := 0; i < 3; i++ {
+		allowed, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+	assert.Equal(t, 1, provider.calls[ERC721TokenURISelector])
+}
+
+// TestERC721TraitRule_FailClosed denies when dependencies are missing or calls fail
+func TestERC721TraitRule_FailClosed(t *testing.T) {
+	rule := NewERC721TraitRule(testNFTAddr, nil, 1, "Tier", "Gold")
+	allowed, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	// Nonexistent token
+	rule = newTraitRule(&traitProvider{}, big.NewInt(7))
+	allowed, err = rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	// Unresolvable metadata
+	provider := &traitProvider{
+		owners: map[int64]string{7: testUserAddr},
+		uris:   map[int64]string{7: "ftp://example.com/7.json"},
+	}
+	rule = newTraitRule(provider, big.NewInt(7))
+	allowed, err = rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
@@ -0,0 +1,95 @@
+package policy
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/yourusername/gatekeeper/internal/auth"
+)
+
+// ChainIDHeader lets clients hint which chain a request concerns
+const ChainIDHeader = "X-Chain-ID"
+
+// credentialHeaders are withheld from rules so credentials never end up in rule
+// configuration or logs
+var credentialHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "X-API-Key"}
+
+// EvaluationContext describes the request being authorized. The policy middleware
+// builds one per request and every rule can read it with EvaluationContextFromContext.
+type EvaluationContext struct {
+	Method   string
+	Path     string
+	Params   map[string]string // Route variables, e.g. {id}
+	Query    url.Values
+	Headers  http.Header // Request headers without credentials
+	ClientIP string      // Resolved from the connection or trusted forwarding headers
+	Claims   *auth.Claims
+	ChainID  uint64   // Chain the request concerns: the X-Chain-ID hint, else the default chain (0 if unknown)
+	Wallets  []string // Other wallets linked to the caller; address-based rules pass if any of them passes
+}
+
+// NewEvaluationContext builds the evaluation context of a request. Route variables
+// are left to the caller, which knows the router.
+func NewEvaluationContext(r *http.Request, clientIP string, claims *auth.Claims) *EvaluationContext {
+	headers := r.Header.Clone()
+	if headers == nil {
+		headers = http.Header{}
+	}
+	for _, name := range credentialHeaders {
+		headers.Del(name)
+	}
+
+	ec := &EvaluationContext{
+		Method:   r.Method,
+		Path:     r.URL.Path,
+		Params:   map[string]string{},
+		Query:    r.URL.Query(),
+		Headers:  headers,
+		ClientIP: clientIP,
+		Claims:   claims,
+	}
+	if hint := r.Header.Get(ChainIDHeader); hint != "" {
+		if chainID, err := strconv.ParseUint(hint, 10, 64); err == nil {
+			ec.ChainID = chainID
+		}
+	}
+	return ec
+}
+
+// evaluationContextKey carries the EvaluationContext of a request
+type evaluationContextKey struct{}
+
+// WithEvaluationContext returns a context carrying the evaluation context for rules.
+// The policy middleware calls this before evaluating policies.
+func WithEvaluationContext(ctx context.Context, ec *EvaluationContext) context.Context {
+	return context.WithValue(ctx, evaluationContextKey{}, ec)
+}
+
+// EvaluationContextFromContext returns the evaluation context set by
+// WithEvaluationContext. Without one, it is built from the request set by
+// WithRequest; returns nil if neither is available.
+func EvaluationContextFromContext(ctx context.Context) *EvaluationContext {
+	if ec, ok := ctx.Value(evaluationContextKey{}).(*EvaluationContext); ok {
+		return ec
+	}
+	r := RequestFromContext(ctx)
+	if r == nil {
+		return nil
+	}
+	clientIP, ok := ctx.Value(clientIPContextKey{}).(string)
+	if !ok {
+		clientIP = remoteHost(r)
+	}
+	return NewEvaluationContext(r, clientIP, nil)
+}
+
+// remoteHost returns the host part of the request's remote address
+func remoteHost(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
@@ -0,0 +1,79 @@
+package policy
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/auth"
+)
+
+// TestNewEvaluationContext describes the request without its credentials
+func TestNewEvaluationContext(t *testing.T) {
+	claims := &auth.Claims{Address: testUserAddr}
+	req := httptest.NewRequest("POST", "/api/claim?preview=1", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	req.Header.Set("Cookie", "session=secret")
+	req.Header.Set("X-API-Key", "secret")
+	req.Header.Set("X-Client", "mobile")
+	req.Header.Set(ChainIDHeader, "137")
+
+	ec := NewEvaluationContext(req, "203.0.113.7", claims)
+
+	assert.Equal(t, "POST", ec.Method)
+	assert.Equal(t, "/api/claim", ec.Path)
+	assert.True(t, ec.Query.Has("preview"))
+	assert.Empty(t, ec.Params)
+	assert.Equal(t, "203.0.113.7", ec.ClientIP)
+	assert.Same(t, claims, ec.Claims)
+	assert.Equal(t, uint64(137), ec.ChainID)
+	assert.Equal(t, "mobile", ec.Headers.Get("X-Client"))
+	assert.Empty(t, ec.Headers.Get("Authorization"))
+	assert.Empty(t, ec.Headers.Get("Cookie"))
+	assert.Empty(t, ec.Headers.Get("X-API-Key"))
+
+	// The request itself is left untouched
+	assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+}
+
+// TestNewEvaluationContext_InvalidChainHint ignores chain hints that are not numbers
+func TestNewEvaluationContext_InvalidChainHint(t *testing.T) {
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.Header.Set(ChainIDHeader, "mainnet")
+
+	assert.Zero(t, NewEvaluationContext(req, "", nil).ChainID)
+}
+
+// TestEvaluationContextFromContext prefers the evaluation context over the raw request
+func TestEvaluationContextFromContext(t *testing.T) {
+	assert.Nil(t, EvaluationContextFromContext(context.Background()))
+
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.RemoteAddr = "198.51.100.1:4000"
+	ec := EvaluationContextFromContext(WithRequest(context.Background(), req))
+	require.NotNil(t, ec)
+	assert.Equal(t, "/api/data", ec.Path)
+	assert.Equal(t, "198.51.100.1", ec.ClientIP)
+
+	ec = EvaluationContextFromContext(WithClientIP(WithRequest(context.Background(), req), "203.0.113.7"))
+	require.NotNil(t, ec)
+	assert.Equal(t, "203.0.113.7", ec.ClientIP)
+
+	built := &EvaluationContext{Method: "DELETE", ClientIP: "192.0.2.1"}
+	ctx := WithEvaluationContext(WithRequest(context.Background(), req), built)
+	assert.Same(t, built, EvaluationContextFromContext(ctx))
+	assert.Equal(t, "192.0.2.1", ClientIPFromContext(ctx))
+}
+
+// TestHeaderEqualsRule_IgnoresCredentialHeaders never matches credentials
+func TestHeaderEqualsRule_IgnoresCredentialHeaders(t *testing.T) {
+	rule := NewHeaderEqualsRule("Authorization", "Bearer token")
+
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	allowed, err := rule.Evaluate(WithRequest(context.Background(), req), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
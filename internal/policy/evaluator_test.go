@@ -2,6 +2,7 @@ package policy
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -326,3 +327,131 @@ func TestInAllowlistRule_Evaluate_AddressNotInList(t *testing.T) {
 	require.NoError(t, err)
 	assert.False(t, result)
 }
+
+// TestPolicy_LinkedWallets lets any linked wallet satisfy address-based rules
+func TestPolicy_LinkedWallets(t *testing.T) {
+	account := "0x1111111111111111111111111111111111111111"
+	linked := "0x2222222222222222222222222222222222222222"
+	claims := &auth.Claims{Address: account, Scopes: []string{"api"}}
+
+	policy := NewPolicy("GET", "/api/data", "AND", []Rule{
+		NewHasScopeRule("api"),
+		NewInAllowlistRule([]string{linked}),
+	})
+
+	result, err := policy.Evaluate(context.Background(), account, claims)
+	require.NoError(t, err)
+	assert.False(t, result, "the account's own address is not allowlisted")
+
+	ctx := WithEvaluationContext(context.Background(), &EvaluationContext{Claims: claims, Wallets: []string{linked}})
+	result, err = policy.Evaluate(ctx, account, claims)
+	require.NoError(t, err)
+	assert.True(t, result)
+
+	// Rules about the caller rather than an address are not retried
+	scoped := NewPolicy("GET", "/api/data", "AND", []Rule{NewHasScopeRule("admin")})
+	result, err = scoped.Evaluate(ctx, account, claims)
+	require.NoError(t, err)
+	assert.False(t, result)
+
+	assert.True(t, HasWalletRules([]*Policy{scoped, policy}))
+	assert.False(t, HasWalletRules([]*Policy{scoped}))
+}
+
+// TestPolicy_EvaluateScore sums the points of passing rules against the threshold
+func TestPolicy_EvaluateScore(t *testing.T) {
+	rules := []Rule{
+		NewHasScopeRule("nft"),
+		NewHasScopeRule("tokens"),
+		NewHasScopeRule("allowlist"),
+	}
+	policy := NewScorePolicy("GET", "/api/data", rules, []int{50, 30, 100}, 60)
+
+	tests := []struct {
+		name     string
+		scopes   []string
+		expected bool
+	}{
+		{"one rule below threshold", []string{"nft"}, false},
+		{"two rules reach threshold", []string{"nft", "tokens"}, true},
+		{"one rule worth the threshold", []string{"allowlist"}, true},
+		{"no rules", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := &auth.Claims{Address: "0x123", Scopes: tt.scopes}
+			result, err := policy.Evaluate(context.Background(), claims.Address, claims)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+// TestPolicy_EvaluateScore_ShortCircuit stops once the threshold is reached
+func TestPolicy_EvaluateScore_ShortCircuit(t *testing.T) {
+	policy := NewScorePolicy("GET", "/api/data", []Rule{NewHasScopeRule("vip"), &erroringRule{err: errors.New("must not be evaluated")}}, []int{100, 10}, 100)
+
+	claims := &auth.Claims{Address: "0x123", Scopes: []string{"vip"}}
+	result, err := policy.Evaluate(context.Background(), claims.Address, claims)
+	require.NoError(t, err)
+	assert.True(t, result)
+}
+
+// TestPolicy_EvaluateTier grants the tier of the first passing rule, or the default
+func TestPolicy_EvaluateTier(t *testing.T) {
+	rules := []Rule{
+		NewHasScopeRule("whale"),
+		NewHasScopeRule("holder"),
+	}
+	tiered := NewTierPolicy("GET", "/api/data", rules, []string{"gold", "silver"}, "bronze")
+	strict := NewTierPolicy("GET", "/api/data", rules, []string{"gold", "silver"}, "")
+
+	tests := []struct {
+		name         string
+		policy       *Policy
+		scopes       []string
+		expectedTier string
+		allowed      bool
+	}{
+		{"first rule wins", tiered, []string{"holder", "whale"}, "gold", true},
+		{"second rule", tiered, []string{"holder"}, "silver", true},
+		{"default tier", tiered, nil, "bronze", true},
+		{"denied without default tier", strict, nil, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := &auth.Claims{Address: "0x123", Scopes: tt.scopes}
+			tier, allowed, err := tt.policy.EvaluateTier(context.Background(), claims.Address, claims)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedTier, tier)
+			assert.Equal(t, tt.allowed, allowed)
+
+			result, err := tt.policy.Evaluate(context.Background(), claims.Address, claims)
+			require.NoError(t, err)
+			assert.Equal(t, tt.allowed, result)
+		})
+	}
+
+	// Other policies grant no tier
+	claims := &auth.Claims{Address: "0x123", Scopes: []string{"whale"}}
+	tier, allowed, err := NewPolicy("GET", "/api/data", "AND", rules[:1]).EvaluateTier(context.Background(), claims.Address, claims)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Empty(t, tier)
+}
+
+// TestPolicy_DecidedTier replays the tier of a TIER policy from its rule decisions
+func TestPolicy_DecidedTier(t *testing.T) {
+	policy := NewTierPolicy("GET", "/api/data", []Rule{NewHasScopeRule("whale"), NewHasScopeRule("holder")}, []string{"gold", "silver"}, "bronze")
+
+	claims := &auth.Claims{Address: "0x123", Scopes: []string{"holder"}}
+	allowed, rules, err := policy.Decide(context.Background(), claims.Address, claims)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, "silver", policy.DecidedTier(rules))
+
+	allowed, rules, err = policy.Decide(context.Background(), claims.Address, &auth.Claims{Address: "0x123"})
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, "bronze", policy.DecidedTier(rules))
+}
@@ -0,0 +1,140 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"go.uber.org/zap"
+)
+
+// Geo restriction modes
+const (
+	GeoModeAllow = "allow" // Only clients in a listed country or ASN pass
+	GeoModeDeny  = "deny"  // Clients in a listed country or ASN are rejected
+)
+
+// GeoRestrictionRule checks the client IP's country or ASN against an allow or deny
+// list, so legally restricted endpoints can be geo-fenced. Clients that cannot be
+// located fail the rule in both modes.
+type GeoRestrictionRule struct {
+	Mode      string
+	Countries []string // ISO 3166-1 alpha-2 codes
+	ASNs      []uint32
+	// resolver will be set by manager
+	resolver GeoIPResolver
+	logger   *zap.Logger
+}
+
+// NewGeoRestrictionRule creates a new geo restriction rule
+func NewGeoRestrictionRule(mode string, countries []string, asns []uint32) *GeoRestrictionRule {
+	logger, _ := zap.NewProduction()
+	normalized := make([]string, len(countries))
+	for i, country := range countries {
+		normalized[i] = strings.ToUpper(country)
+	}
+	return &GeoRestrictionRule{
+		Mode:      mode,
+		Countries: normalized,
+		ASNs:      asns,
+		logger:    logger,
+	}
+}
+
+// Type returns the rule type
+func (r *GeoRestrictionRule) Type() RuleType {
+	return GeoRestrictionRuleType
+}
+
+// Validate checks if the rule parameters are valid
+func (r *GeoRestrictionRule) Validate() error {
+	if r.Mode != GeoModeAllow && r.Mode != GeoModeDeny {
+		return fmt.Errorf("mode must be %s or %s, got %q", GeoModeAllow, GeoModeDeny, r.Mode)
+	}
+	if len(r.Countries) == 0 && len(r.ASNs) == 0 {
+		return fmt.Errorf("at least one country or ASN is required")
+	}
+	for _, country := range r.Countries {
+		if len(country) != 2 {
+			return fmt.Errorf("invalid country code %q (use ISO 3166-1 alpha-2)", country)
+		}
+	}
+	return nil
+}
+
+// Evaluate locates the client IP and applies the allow or deny list (requires resolver to be set)
+// This implementation follows fail-closed security: on any error, return false
+func (r *GeoRestrictionRule) Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	if r.resolver == nil {
+		r.logger.Warn("no GeoIP resolver configured",
+			zap.String("rule", "GeoRestriction"))
+		return false, nil
+	}
+
+	clientIP := ClientIPFromContext(ctx)
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		r.logger.Warn("client IP unavailable for geo restriction",
+			zap.String("ip", clientIP),
+			zap.String("rule", "GeoRestriction"))
+		return false, nil
+	}
+
+	location, err := r.resolver.Lookup(ip)
+	if err != nil {
+		r.logger.Error("GeoIP lookup failed",
+			zap.Error(err),
+			zap.String("ip", clientIP))
+		return false, nil
+	}
+	if location == nil {
+		r.logger.Info("client IP not found in GeoIP database",
+			zap.String("ip", clientIP),
+			zap.String("mode", r.Mode))
+		return false, nil
+	}
+
+	listed := r.matches(location)
+	allowed := listed == (r.Mode == GeoModeAllow)
+
+	r.logger.Info("geo restriction check completed",
+		zap.String("address", address),
+		zap.String("ip", clientIP),
+		zap.String("country", location.Country),
+		zap.Uint32("asn", location.ASN),
+		zap.String("mode", r.Mode),
+		zap.Bool("listed", listed),
+		zap.Bool("allowed", allowed))
+
+	return allowed, nil
+}
+
+// matches reports whether the location's country or ASN is listed
+func (r *GeoRestrictionRule) matches(location *GeoLocation) bool {
+	for _, country := range r.Countries {
+		if country == location.Country {
+			return true
+		}
+	}
+	if location.ASN == 0 {
+		return false
+	}
+	for _, asn := range r.ASNs {
+		if asn == location.ASN {
+			return true
+		}
+	}
+	return false
+}
+
+// SetResolver sets the GeoIP resolver used to locate clients
+func (r *GeoRestrictionRule) SetResolver(resolver GeoIPResolver) {
+	r.resolver = resolver
+}
+
+// SetLogger sets the logger for the rule
+func (r *GeoRestrictionRule) SetLogger(logger *zap.Logger) {
+	r.logger = logger
+}
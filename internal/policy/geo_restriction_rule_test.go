@@ -0,0 +1,87 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingGeoIPResolver fails every lookup
+type failingGeoIPResolver struct{}
+
+func (failingGeoIPResolver) Lookup(ip net.IP) (*GeoLocation, error) {
+	return nil, fmt.Errorf("database unavailable")
+}
+
+func newTestGeoIPResolver(t *testing.T) GeoIPResolver {
+	resolver, err := LoadGeoIPCSV(strings.NewReader(testGeoIPTable))
+	require.NoError(t, err)
+	return resolver
+}
+
+// TestGeoRestrictionRule_Evaluate applies allow and deny lists by country and ASN
+func TestGeoRestrictionRule_Evaluate(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     *GeoRestrictionRule
+		ip       string
+		expected bool
+	}{
+		{"allowed country", NewGeoRestrictionRule(GeoModeAllow, []string{"nl"}, nil), "192.0.2.1", true},
+		{"unlisted country in allow mode", NewGeoRestrictionRule(GeoModeAllow, []string{"NL"}, nil), "2001:db8::1", false},
+		{"allowed ASN", NewGeoRestrictionRule(GeoModeAllow, nil, []uint32{64502}), "2001:db8::1", true},
+		{"denied country", NewGeoRestrictionRule(GeoModeDeny, []string{"US"}, nil), "198.51.100.200", false},
+		{"denied ASN", NewGeoRestrictionRule(GeoModeDeny, nil, []uint32{64500}), "192.0.2.1", false},
+		{"unlisted country in deny mode", NewGeoRestrictionRule(GeoModeDeny, []string{"US"}, nil), "192.0.2.1", true},
+		{"unknown network in deny mode", NewGeoRestrictionRule(GeoModeDeny, []string{"US"}, nil), "10.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.SetResolver(newTestGeoIPResolver(t))
+			allowed, err := tt.rule.Evaluate(WithClientIP(context.Background(), tt.ip), testUserAddr, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, allowed)
+		})
+	}
+}
+
+// TestGeoRestrictionRule_RemoteAddr falls back to the request's remote address
+func TestGeoRestrictionRule_RemoteAddr(t *testing.T) {
+	rule := NewGeoRestrictionRule(GeoModeAllow, []string{"NL"}, nil)
+	rule.SetResolver(newTestGeoIPResolver(t))
+
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.RemoteAddr = "192.0.2.10:51234"
+	allowed, err := rule.Evaluate(WithRequest(context.Background(), req), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+// TestGeoRestrictionRule_FailClosed denies when the client cannot be located
+func TestGeoRestrictionRule_FailClosed(t *testing.T) {
+	rule := NewGeoRestrictionRule(GeoModeDeny, []string{"US"}, nil)
+	ctx := WithClientIP(context.Background(), "192.0.2.1")
+
+	// No resolver
+	allowed, err := rule.Evaluate(ctx, testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	rule.SetResolver(failingGeoIPResolver{})
+	allowed, err = rule.Evaluate(ctx, testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	// No client IP
+	rule.SetResolver(newTestGeoIPResolver(t))
+	allowed, err = rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
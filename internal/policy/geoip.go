@@ -0,0 +1,137 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GeoLocation is the country and autonomous system an IP address belongs to
+type GeoLocation struct {
+	Country string // ISO 3166-1 alpha-2 code, upper case
+	ASN     uint32 // 0 when unknown
+}
+
+// GeoIPResolver looks up the location of client IP addresses for geo restriction rules
+type GeoIPResolver interface {
+	// Lookup returns the location of ip, or nil if the address is not in the database
+	Lookup(ip net.IP) (*GeoLocation, error)
+}
+
+// geoRange is a network of the database as an inclusive range of 16-byte addresses
+type geoRange struct {
+	start    net.IP
+	end      net.IP
+	location GeoLocation
+}
+
+// CSVGeoIPResolver resolves IP addresses from an in-memory table of networks.
+// Lookups are a binary search over the sorted ranges, so networks must not overlap.
+type CSVGeoIPResolver struct {
+	ranges []geoRange
+}
+
+// LoadGeoIPCSV parses a GeoIP table with one "network,country,asn" row per CIDR,
+// e.g. "203.0.113.0/24,NL,64500". The ASN column may be empty. A header row and
+// lines starting with # are skipped.
+func LoadGeoIPCSV(r io.Reader) (*CSVGeoIPResolver, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	resolver := &CSVGeoIPResolver{}
+	line := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid GeoIP table: %w", err)
+		}
+		line++
+
+		if line == 1 && strings.EqualFold(record[0], "network") {
+			continue
+		}
+		if len(record) < 2 || len(record) > 3 {
+			return nil, fmt.Errorf("GeoIP row %d: expected network,country,asn", line)
+		}
+
+		_, network, err := net.ParseCIDR(record[0])
+		if err != nil {
+			return nil, fmt.Errorf("GeoIP row %d: %w", line, err)
+		}
+
+		location := GeoLocation{Country: strings.ToUpper(record[1])}
+		if len(record) == 3 && record[2] != "" {
+			asn, err := strconv.ParseUint(strings.TrimPrefix(strings.ToUpper(record[2]), "AS"), 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("GeoIP row %d: invalid ASN %q", line, record[2])
+			}
+			location.ASN = uint32(asn)
+		}
+
+		start := network.IP.To16()
+		end := make(net.IP, net.IPv6len)
+		mask := []byte(network.Mask)
+		if len(mask) == net.IPv4len {
+			// IPv4 networks are stored as IPv4-mapped IPv6 addresses
+			mask = append(bytes.Repeat([]byte{0xff}, 12), mask...)
+		}
+		for i := range end {
+			end[i] = start[i] | ^mask[i]
+		}
+		resolver.ranges = append(resolver.ranges, geoRange{start: start, end: end, location: location})
+	}
+
+	sort.Slice(resolver.ranges, func(i, j int) bool {
+		return bytes.Compare(resolver.ranges[i].start, resolver.ranges[j].start) < 0
+	})
+	for i := 1; i < len(resolver.ranges); i++ {
+		if bytes.Compare(resolver.ranges[i].start, resolver.ranges[i-1].end) <= 0 {
+			return nil, fmt.Errorf("GeoIP networks overlap at %s", resolver.ranges[i].start)
+		}
+	}
+	return resolver, nil
+}
+
+// LoadGeoIPFile loads a GeoIP table from a CSV file (see LoadGeoIPCSV)
+func LoadGeoIPFile(path string) (*CSVGeoIPResolver, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadGeoIPCSV(file)
+}
+
+// Lookup returns the location of the network containing ip, or nil if none does
+func (g *CSVGeoIPResolver) Lookup(ip net.IP) (*GeoLocation, error) {
+	addr := ip.To16()
+	if addr == nil {
+		return nil, fmt.Errorf("invalid IP address")
+	}
+
+	// First range starting after addr; the candidate is the one before it
+	i := sort.Search(len(g.ranges), func(i int) bool {
+		return bytes.Compare(g.ranges[i].start, addr) > 0
+	})
+	if i == 0 || bytes.Compare(addr, g.ranges[i-1].end) > 0 {
+		return nil, nil
+	}
+	location := g.ranges[i-1].location
+	return &location, nil
+}
+
+// Len returns the number of networks in the table
+func (g *CSVGeoIPResolver) Len() int {
+	return len(g.ranges)
+}
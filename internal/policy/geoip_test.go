@@ -0,0 +1,60 @@
+package policy
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testGeoIPTable = `network,country,asn
+# Documentation ranges
+192.0.2.0/24,nl,64500
+198.51.100.0/25,US,AS64501
+198.51.100.128/25,US,
+2001:db8::/32,DE,64502
+`
+
+// TestLoadGeoIPCSV_Lookup resolves IPv4 and IPv6 addresses to their network
+func TestLoadGeoIPCSV_Lookup(t *testing.T) {
+	resolver, err := LoadGeoIPCSV(strings.NewReader(testGeoIPTable))
+	require.NoError(t, err)
+	assert.Equal(t, 4, resolver.Len())
+
+	tests := []struct {
+		ip       string
+		expected *GeoLocation
+	}{
+		{"192.0.2.1", &GeoLocation{Country: "NL", ASN: 64500}},
+		{"192.0.2.255", &GeoLocation{Country: "NL", ASN: 64500}},
+		{"198.51.100.127", &GeoLocation{Country: "US", ASN: 64501}},
+		{"198.51.100.128", &GeoLocation{Country: "US"}},
+		{"2001:db8:1::1", &GeoLocation{Country: "DE", ASN: 64502}},
+		{"192.0.3.0", nil},
+		{"10.0.0.1", nil},
+		{"2001:db9::1", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			location, err := resolver.Lookup(net.ParseIP(tt.ip))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, location)
+		})
+	}
+}
+
+// TestLoadGeoIPCSV_Invalid rejects malformed and overlapping tables
+func TestLoadGeoIPCSV_Invalid(t *testing.T) {
+	for _, table := range []string{
+		"192.0.2.0,NL,64500\n",
+		"192.0.2.0/24\n",
+		"192.0.2.0/24,NL,AS-x\n",
+		"192.0.2.0/24,NL,64500\n192.0.2.128/25,US,64501\n",
+	} {
+		_, err := LoadGeoIPCSV(strings.NewReader(table))
+		assert.Error(t, err, table)
+	}
+}
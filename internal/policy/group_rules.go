@@ -0,0 +1,146 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/yourusername/gatekeeper/internal/auth"
+)
+
+// Rule groups combine rules into expressions, so a policy can require e.g.
+// "(holds NFT OR 1000 TOKEN) AND in allowlist AND NOT in blocklist":
+//
+//	AllOf(
+//		AnyOf(NewERC721OwnerRule(nft, tokenID, 1), NewERC20MinBalanceRule(token, min, 1)),
+//		NewInAllowlistRule(allowlist),
+//		Not(NewInAllowlistRule(blocklist)),
+//	)
+//
+// Groups nest to any depth. Wallet rules inside a group are still satisfied by any
+// wallet linked to the caller; under Not this means a rule matching any linked
+// wallet fails the group.
+
+// AllOfRule passes when every nested rule passes
+type AllOfRule struct {
+	Rules []Rule
+}
+
+// AllOf creates a group that passes when every rule passes
+func AllOf(rules ...Rule) *AllOfRule {
+	return &AllOfRule{Rules: rules}
+}
+
+// Type returns the rule type
+func (r *AllOfRule) Type() RuleType {
+	return AllOfRuleType
+}
+
+// Validate checks the group has rules
+func (r *AllOfRule) Validate() error {
+	if len(r.Rules) == 0 {
+		return fmt.Errorf("all_of requires at least one rule")
+	}
+	return nil
+}
+
+// Evaluate evaluates the rules in order, stopping at the first that fails
+func (r *AllOfRule) Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	return evaluateGroup(ctx, r.Rules, address, claims, false)
+}
+
+// MarshalJSON serializes the group in the policy configuration format, so nested
+// rules keep their types
+func (r *AllOfRule) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ruleJSON(r))
+}
+
+// AnyOfRule passes when any nested rule passes
+type AnyOfRule struct {
+	Rules []Rule
+}
+
+// AnyOf creates a group that passes when any rule passes
+func AnyOf(rules ...Rule) *AnyOfRule {
+	return &AnyOfRule{Rules: rules}
+}
+
+// Type returns the rule type
+func (r *AnyOfRule) Type() RuleType {
+	return AnyOfRuleType
+}
+
+// Validate checks the group has rules
+func (r *AnyOfRule) Validate() error {
+	if len(r.Rules) == 0 {
+		return fmt.Errorf("any_of requires at least one rule")
+	}
+	return nil
+}
+
+// Evaluate evaluates the rules in order, stopping at the first that passes
+func (r *AnyOfRule) Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	return evaluateGroup(ctx, r.Rules, address, claims, true)
+}
+
+// MarshalJSON serializes the group in the policy configuration format, so nested
+// rules keep their types
+func (r *AnyOfRule) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ruleJSON(r))
+}
+
+// NotRule passes when its nested rule fails
+type NotRule struct {
+	Rule Rule
+}
+
+// Not creates a rule that passes when rule fails
+func Not(rule Rule) *NotRule {
+	return &NotRule{Rule: rule}
+}
+
+// Type returns the rule type
+func (r *NotRule) Type() RuleType {
+	return NotRuleType
+}
+
+// Validate checks the rule to negate is set
+func (r *NotRule) Validate() error {
+	if r.Rule == nil {
+		return fmt.Errorf("not requires a rule")
+	}
+	return nil
+}
+
+// Evaluate negates the nested rule. An error is not negated: the rule fails with it.
+func (r *NotRule) Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	result, err := evaluateRule(ctx, r.Rule, address, claims)
+	if err != nil {
+		return false, err
+	}
+	return !result, nil
+}
+
+// MarshalJSON serializes the rule in the policy configuration format, so the nested
+// rule keeps its type
+func (r *NotRule) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ruleJSON(r))
+}
+
+// WalkRules calls fn for each rule and, depth first, for every rule nested in
+// rule groups
+func WalkRules(rules []Rule, fn func(Rule)) {
+	for _, rule := range rules {
+		fn(rule)
+		switch r := rule.(type) {
+		case *AllOfRule:
+			WalkRules(r.Rules, fn)
+		case *AnyOfRule:
+			WalkRules(r.Rules, fn)
+		case *NotRule:
+			if r.Rule != nil {
+				WalkRules([]Rule{r.Rule}, fn)
+			}
+		}
+	}
+}
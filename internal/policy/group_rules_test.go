@@ -0,0 +1,199 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/gatekeeper/internal/auth"
+)
+
+// erroringRule always fails with err
+type erroringRule struct {
+	err error
+}
+
+func (r *erroringRule) Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	return false, r.err
+}
+
+func (r *erroringRule) Type() RuleType {
+	return "erroring"
+}
+
+// TestGroupRules_Evaluate checks "(nft OR token) AND allowlist AND NOT blocklist"
+func TestGroupRules_Evaluate(t *testing.T) {
+	allowed := "0x1111111111111111111111111111111111111111"
+	blocked := "0x2222222222222222222222222222222222222222"
+
+	rule := AllOf(
+		AnyOf(NewHasScopeRule("nft"), NewHasScopeRule("token")),
+		NewInAllowlistRule([]string{allowed, blocked}),
+		Not(NewInAllowlistRule([]string{blocked})),
+	)
+
+	tests := []struct {
+		name     string
+		address  string
+		scopes   []string
+		expected bool
+	}{
+		{"holds nft", allowed, []string{"nft"}, true},
+		{"holds token", allowed, []string{"token"}, true},
+		{"holds neither", allowed, []string{"other"}, false},
+		{"blocklisted", blocked, []string{"nft"}, false},
+		{"not allowlisted", "0x3333333333333333333333333333333333333333", []string{"nft"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := &auth.Claims{Address: tt.address, Scopes: tt.scopes}
+			result, err := rule.Evaluate(context.Background(), tt.address, claims)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+// TestNotRule_LinkedWallets fails when any linked wallet matches the negated rule
+func TestNotRule_LinkedWallets(t *testing.T) {
+	account := "0x1111111111111111111111111111111111111111"
+	linked := "0x2222222222222222222222222222222222222222"
+	claims := &auth.Claims{Address: account}
+
+	rule := Not(NewInAllowlistRule([]string{linked}))
+
+	result, err := rule.Evaluate(context.Background(), account, claims)
+	require.NoError(t, err)
+	assert.True(t, result)
+
+	ctx := WithEvaluationContext(context.Background(), &EvaluationContext{Claims: claims, Wallets: []string{linked}})
+	result, err = rule.Evaluate(ctx, account, claims)
+	require.NoError(t, err)
+	assert.False(t, result)
+}
+
+// TestGroupRules_Errors propagates errors instead of negating or skipping them
+func TestGroupRules_Errors(t *testing.T) {
+	failure := errors.New("provider down")
+	failing := &erroringRule{err: failure}
+
+	_, err := Not(failing).Evaluate(context.Background(), "", nil)
+	assert.ErrorIs(t, err, failure)
+
+	_, err = AnyOf(NewHasScopeRule("missing"), failing).Evaluate(context.Background(), "", &auth.Claims{})
+	assert.ErrorIs(t, err, failure)
+
+	_, err = AllOf(failing).Evaluate(context.Background(), "", nil)
+	assert.ErrorIs(t, err, failure)
+
+	// Evaluation stops before the failing rule once the outcome is known
+	result, err := AnyOf(NewHasScopeRule("read"), failing).Evaluate(context.Background(), "", &auth.Claims{Scopes: []string{"read"}})
+	require.NoError(t, err)
+	assert.True(t, result)
+}
+
+func TestGroupRules_Validate(t *testing.T) {
+	assert.Error(t, AllOf().Validate())
+	assert.Error(t, AnyOf().Validate())
+	assert.Error(t, Not(nil).Validate())
+	assert.NoError(t, AllOf(NewHasScopeRule("read")).Validate())
+	assert.NoError(t, Not(NewHasScopeRule("read")).Validate())
+}
+
+func TestWalkRules(t *testing.T) {
+	rules := []Rule{
+		NewHasScopeRule("a"),
+		AllOf(AnyOf(NewHasScopeRule("b")), Not(NewHasScopeRule("c"))),
+	}
+
+	var types []RuleType
+	WalkRules(rules, func(rule Rule) {
+		types = append(types, rule.Type())
+	})
+	assert.Equal(t, []RuleType{HasScopeRuleType, AllOfRuleType, AnyOfRuleType, HasScopeRuleType, NotRuleType, HasScopeRuleType}, types)
+}
+
+// TestLoader_GroupRules loads nested groups and serializes them back unchanged
+func TestLoader_GroupRules(t *testing.T) {
+	policyJSON := `{
+		"path": "/api/vip",
+		"method": "GET",
+		"logic": "AND",
+		"rules": [{
+			"type": "all_of",
+			"rules": [
+				{"type": "any_of", "rules": [
+					{"type": "erc721_owner", "contract_address": "0xbc4ca0eda7647a8ab7c2061c2e118a18a936f13d", "token_id": "42", "chain_id": 1},
+					{"type": "erc20_min_balance", "contract_address": "0x1f9840a85d5af5bf1d1762f925bdaddc4201f984", "minimum_balance": "1000", "chain_id": 1}
+				]},
+				{"type": "in_allowlist", "addresses": ["0x742d35cc6634c0532925a3b844bc9e7595f0beb8"]},
+				{"type": "not", "rule": {"type": "in_allowlist", "addresses": ["0x2222222222222222222222222222222222222222"]}}
+			]
+		}]
+	}`
+
+	loader := NewPolicyLoader()
+	policy, err := loader.ParsePolicy([]byte(policyJSON))
+	require.NoError(t, err)
+
+	require.Len(t, policy.Rules, 1)
+	group, ok := policy.Rules[0].(*AllOfRule)
+	require.True(t, ok)
+	require.Len(t, group.Rules, 3)
+	assert.IsType(t, &AnyOfRule{}, group.Rules[0])
+	assert.IsType(t, &NotRule{}, group.Rules[2])
+	assert.True(t, HasWalletRules([]*Policy{policy}))
+
+	data, err := json.Marshal(policy)
+	require.NoError(t, err)
+	reloaded, err := loader.ParsePolicy(data)
+	require.NoError(t, err)
+	again, err := json.Marshal(reloaded)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(data), string(again))
+
+	// Snapshots serialize groups in the same format
+	snapshot, err := json.Marshal(group.Rules[2])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"not","rule":{"type":"in_allowlist","addresses":["0x2222222222222222222222222222222222222222"]}}`, string(snapshot))
+}
+
+func TestLoader_GroupRules_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+	}{
+		{"empty all_of", `{"type": "all_of", "rules": []}`},
+		{"empty any_of", `{"type": "any_of"}`},
+		{"not without rule", `{"type": "not"}`},
+		{"invalid nested rule", `{"type": "any_of", "rules": [{"type": "has_scope"}]}`},
+		{"unknown nested rule", `{"type": "not", "rule": {"type": "unknown"}}`},
+	}
+
+	loader := NewPolicyLoader()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loader.ParsePolicy([]byte(`{"path": "/api", "method": "GET", "logic": "AND", "rules": [` + tt.rule + `]}`))
+			assert.Error(t, err)
+		})
+	}
+}
+
+// TestManager_WiresNestedBlockchainRules sets the provider on rules inside groups
+func TestManager_WiresNestedBlockchainRules(t *testing.T) {
+	provider := &MockBlockchainProvider{}
+	manager := NewPolicyManager(provider, &MockCache{})
+
+	nested := NewERC20MinBalanceRule("0x1f9840a85d5af5bf1d1762f925bdaddc4201f984", big.NewInt(1000), 1)
+	manager.AddPolicy(NewPolicy("GET", "/api/data", "AND", []Rule{
+		AnyOf(NewHasScopeRule("vip"), Not(nested)),
+	}))
+
+	assert.Equal(t, provider, nested.provider)
+}
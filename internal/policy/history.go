@@ -0,0 +1,163 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"go.uber.org/zap"
+)
+
+// Change actions reported to a ChangeRecorder
+const (
+	PolicyCreated = "created"
+	PolicyUpdated = "updated"
+	PolicyDeleted = "deleted"
+)
+
+// ChangeRecorder records policy mutations so they can be reviewed later.
+// route identifies the changed route as "METHOD path"; before and after
+// hold the JSON of every policy on that route, nil when there were none.
+type ChangeRecorder interface {
+	RecordPolicyChange(ctx context.Context, route, action string, before, after []byte) error
+}
+
+// policyChange is a pending change to a single route
+type policyChange struct {
+	route  string
+	action string
+	before []byte
+	after  []byte
+}
+
+// ruleSnapshot is the recorded form of a rule
+type ruleSnapshot struct {
+	Type   RuleType `json:"type"`
+	Config Rule     `json:"config"`
+	Points int      `json:"points,omitempty"` // SCORE policies only
+	Tier   string   `json:"tier,omitempty"`   // TIER policies only
+}
+
+// policySnapshot is the recorded form of a policy
+type policySnapshot struct {
+	Method string         `json:"method"`
+	Path   string         `json:"path"`
+	Logic  string         `json:"logic"`
+	Shadow bool           `json:"shadow,omitempty"`
+	Rules  []ruleSnapshot `json:"rules"`
+	// Threshold of a SCORE policy
+	Threshold int `json:"threshold,omitempty"`
+	// Default tier of a TIER policy
+	DefaultTier string `json:"default_tier,omitempty"`
+}
+
+// snapshotRoutes serializes policies grouped by route
+func snapshotRoutes(policies []*Policy) map[string][]byte {
+	grouped := make(map[string][]policySnapshot)
+	for _, p := range policies {
+		if p == nil {
+			continue
+		}
+		snapshot := policySnapshot{
+			Method: p.Method,
+			Path:   p.Path,
+			Logic:  p.Logic,
+			Shadow: p.Shadow,
+			Rules:  make([]ruleSnapshot, 0, len(p.Rules)),
+		}
+		if p.Logic == "SCORE" {
+			snapshot.Threshold = p.Threshold
+		}
+		if p.Logic == "TIER" {
+			snapshot.DefaultTier = p.DefaultTier
+		}
+		for i, rule := range p.Rules {
+			recorded := ruleSnapshot{Type: rule.Type(), Config: rule}
+			if p.Logic == "SCORE" && i < len(p.Points) {
+				recorded.Points = p.Points[i]
+			}
+			if p.Logic == "TIER" && i < len(p.Tiers) {
+				recorded.Tier = p.Tiers[i]
+			}
+			snapshot.Rules = append(snapshot.Rules, recorded)
+		}
+		route := p.Method + " " + p.Path
+		grouped[route] = append(grouped[route], snapshot)
+	}
+
+	routes := make(map[string][]byte, len(grouped))
+	for route, snapshots := range grouped {
+		data, err := json.Marshal(snapshots)
+		if err != nil {
+			// Rule fields are plain values; fall back to recording the route only
+			data = []byte("null")
+		}
+		routes[route] = data
+	}
+	return routes
+}
+
+// diffRoutes compares two route snapshots and returns the changes between
+// them, ordered by route
+func diffRoutes(before, after map[string][]byte) []policyChange {
+	var changes []policyChange
+	for route, old := range before {
+		current, ok := after[route]
+		switch {
+		case !ok:
+			changes = append(changes, policyChange{route: route, action: PolicyDeleted, before: old})
+		case string(old) != string(current):
+			changes = append(changes, policyChange{route: route, action: PolicyUpdated, before: old, after: current})
+		}
+	}
+	for route, current := range after {
+		if _, ok := before[route]; !ok {
+			changes = append(changes, policyChange{route: route, action: PolicyCreated, after: current})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].route < changes[j].route
+	})
+	return changes
+}
+
+// SetChangeRecorder sets the recorder notified of every policy mutation
+func (pm *PolicyManager) SetChangeRecorder(recorder ChangeRecorder) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.recorder = recorder
+}
+
+// snapshotForRecorder snapshots the current policies when a recorder is set.
+// Callers must hold pm.mu.
+func (pm *PolicyManager) snapshotForRecorder() map[string][]byte {
+	if pm.recorder == nil {
+		return nil
+	}
+	return snapshotRoutes(pm.policies)
+}
+
+// pendingChanges diffs the policies against an earlier snapshot.
+// Callers must hold pm.mu.
+func (pm *PolicyManager) pendingChanges(before map[string][]byte) (ChangeRecorder, []policyChange) {
+	if pm.recorder == nil {
+		return nil, nil
+	}
+	return pm.recorder, diffRoutes(before, snapshotRoutes(pm.policies))
+}
+
+// recordChanges hands changes to the recorder with ctx, which identifies who made
+// them. It is called without holding pm.mu so a slow recorder never blocks
+// policy evaluation.
+func (pm *PolicyManager) recordChanges(ctx context.Context, recorder ChangeRecorder, changes []policyChange) {
+	for _, change := range changes {
+		if err := recorder.RecordPolicyChange(ctx, change.route, change.action, change.before, change.after); err != nil && pm.logger != nil {
+			pm.logger.Error("failed to record policy change",
+				zap.String("route", change.route),
+				zap.String("action", change.action),
+				zap.Error(err),
+			)
+		}
+	}
+}
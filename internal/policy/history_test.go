@@ -0,0 +1,108 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedChange struct {
+	route  string
+	action string
+	before string
+	after  string
+}
+
+type fakeChangeRecorder struct {
+	mu       sync.Mutex
+	changes  []recordedChange
+	contexts []context.Context
+	err      error
+}
+
+func (f *fakeChangeRecorder) RecordPolicyChange(ctx context.Context, route, action string, before, after []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.changes = append(f.changes, recordedChange{route: route, action: action, before: string(before), after: string(after)})
+	f.contexts = append(f.contexts, ctx)
+	return f.err
+}
+
+func TestManager_RecordsPolicyChanges(t *testing.T) {
+	manager := NewPolicyManager(nil, nil)
+	recorder := &fakeChangeRecorder{}
+	manager.SetChangeRecorder(recorder)
+
+	manager.AddPolicy(NewPolicy("GET", "/api/data", "AND", []Rule{NewHasScopeRule("read")}))
+	require.Len(t, recorder.changes, 1)
+	assert.Equal(t, "GET /api/data", recorder.changes[0].route)
+	assert.Equal(t, PolicyCreated, recorder.changes[0].action)
+	assert.Empty(t, recorder.changes[0].before)
+	assert.JSONEq(t, `[{"method":"GET","path":"/api/data","logic":"AND","rules":[{"type":"has_scope","config":{"Scope":"read"}}]}]`, recorder.changes[0].after)
+
+	err := manager.LoadFromJSON([]byte(`[
+		{"path": "/api/data", "method": "GET", "logic": "AND", "rules": [{"type": "has_scope", "scope": "admin"}]},
+		{"path": "/api/users", "method": "POST", "logic": "OR", "rules": [{"type": "has_scope", "scope": "write"}]}
+	]`))
+	require.NoError(t, err)
+	require.Len(t, recorder.changes, 3)
+	assert.Equal(t, "GET /api/data", recorder.changes[1].route)
+	assert.Equal(t, PolicyUpdated, recorder.changes[1].action)
+	assert.Contains(t, recorder.changes[1].before, `"Scope":"read"`)
+	assert.Contains(t, recorder.changes[1].after, `"Scope":"admin"`)
+	assert.Equal(t, "POST /api/users", recorder.changes[2].route)
+	assert.Equal(t, PolicyCreated, recorder.changes[2].action)
+
+	// Reloading identical policies records nothing
+	manager.ReloadPolicies(manager.GetAllPolicies())
+	assert.Len(t, recorder.changes, 3)
+
+	manager.Clear()
+	require.Len(t, recorder.changes, 5)
+	assert.Equal(t, PolicyDeleted, recorder.changes[3].action)
+	assert.Equal(t, PolicyDeleted, recorder.changes[4].action)
+	assert.Empty(t, recorder.changes[4].after)
+}
+
+func TestManager_RecorderErrorDoesNotBlockChanges(t *testing.T) {
+	manager := NewPolicyManager(nil, nil)
+	manager.SetChangeRecorder(&fakeChangeRecorder{err: errors.New("database unavailable")})
+
+	manager.AddPolicy(NewPolicy("GET", "/api/data", "AND", []Rule{NewHasScopeRule("read")}))
+	assert.True(t, manager.HasPolicy("/api/data", "GET"))
+}
+
+func TestDiffRoutes(t *testing.T) {
+	before := map[string][]byte{"GET /a": []byte(`1`), "GET /b": []byte(`2`)}
+	after := map[string][]byte{"GET /b": []byte(`3`), "GET /c": []byte(`4`)}
+
+	changes := diffRoutes(before, after)
+	require.Len(t, changes, 3)
+	assert.Equal(t, policyChange{route: "GET /a", action: PolicyDeleted, before: []byte(`1`)}, changes[0])
+	assert.Equal(t, policyChange{route: "GET /b", action: PolicyUpdated, before: []byte(`2`), after: []byte(`3`)}, changes[1])
+	assert.Equal(t, policyChange{route: "GET /c", action: PolicyCreated, after: []byte(`4`)}, changes[2])
+}
+
+// TestManager_RecordsChangesWithContext passes the caller's context, which carries
+// the actor, to the recorder
+func TestManager_RecordsChangesWithContext(t *testing.T) {
+	type actorKey struct{}
+	manager := NewPolicyManager(nil, nil)
+	recorder := &fakeChangeRecorder{}
+	manager.SetChangeRecorder(recorder)
+	ctx := context.WithValue(context.Background(), actorKey{}, "0xadmin")
+
+	policy := NewPolicy("GET", "/api/data", "AND", []Rule{NewHasScopeRule("read")})
+	require.NoError(t, manager.AddPolicyContext(ctx, policy))
+	require.NoError(t, manager.ReplacePolicy(ctx, policy.ID, NewPolicy("GET", "/api/data", "AND", []Rule{NewHasScopeRule("write")})))
+	require.NoError(t, manager.RemovePolicy(ctx, policy.ID))
+
+	require.Len(t, recorder.contexts, 3)
+	for _, recorded := range recorder.contexts {
+		assert.Equal(t, "0xadmin", recorded.Value(actorKey{}))
+	}
+}
@@ -0,0 +1,304 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/chain"
+	"go.uber.org/zap"
+)
+
+// TransferEventTopic is keccak256("Transfer(address,address,uint256)"), the
+// Transfer event of both ERC20 and ERC721 tokens
+const TransferEventTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+
+// HoldingDurationRule checks if user has held at least a minimum balance of a token
+// continuously for a number of days, so tokens borrowed or bought just to pass a
+// balance gate do not count. It reads the balance at the block the period started
+// and replays the Transfer events since then; a balance that dropped below the
+// minimum at any point fails the rule. Works for ERC20 tokens and ERC721
+// collections, and requires an RPC endpoint that serves historical state.
+type HoldingDurationRule struct {
+	ContractAddress string
+	MinimumBalance  *big.Int
+	MinimumDays     int
+	ChainID         uint64
+	cacheTTL        // Optional TTL of the rule's cached reads
+	// cache and provider will be set by manager
+	cache    CacheProvider
+	provider BlockchainProvider
+	logger   *zap.Logger
+}
+
+// NewHoldingDurationRule creates a new holding duration rule
+func NewHoldingDurationRule(contractAddress string, minimumBalance *big.Int, minimumDays int, chainID uint64) *HoldingDurationRule {
+	logger, _ := zap.NewProduction()
+	return &HoldingDurationRule{
+		ContractAddress: contractAddress,
+		MinimumBalance:  minimumBalance,
+		MinimumDays:     minimumDays,
+		ChainID:         chainID,
+		logger:          logger,
+	}
+}
+
+// Type returns the rule type
+func (r *HoldingDurationRule) Type() RuleType {
+	return HoldingDurationRuleType
+}
+
+// Validate checks if the rule parameters are valid
+func (r *HoldingDurationRule) Validate() error {
+	if !isValidAddress(r.ContractAddress) {
+		return fmt.Errorf("invalid contract address: %s", r.ContractAddress)
+	}
+	if r.MinimumBalance == nil || r.MinimumBalance.Sign() <= 0 {
+		return fmt.Errorf("minimum balance must be positive")
+	}
+	if r.MinimumDays <= 0 {
+		return fmt.Errorf("minimum days must be positive")
+	}
+	if r.ChainID == 0 {
+		return fmt.Errorf("chain ID cannot be zero")
+	}
+	return nil
+}
+
+// Evaluate checks the holding history (requires provider and cache to be set)
+// This implementation follows fail-closed security: on any error, return false
+func (r *HoldingDurationRule) Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	if !isValidAddress(address) {
+		r.logger.Error("invalid address format",
+			zap.String("address", address),
+			zap.String("rule", "HoldingDuration"))
+		return false, nil // Fail-closed
+	}
+
+	if r.provider == nil {
+		r.logger.Warn("no blockchain provider configured",
+			zap.String("rule", "HoldingDuration"))
+		return false, nil
+	}
+
+	// Generate cache key: "holding_duration:{chainID}:{token}:{minimum}:{days}:{address}"
+	normalizedAddr := strings.ToLower(address)
+	chainIDStr := strconv.FormatUint(r.ChainID, 10)
+	cacheKey := chain.CacheKey("holding_duration", chainIDStr, strings.ToLower(r.ContractAddress),
+		fmt.Sprintf("%s:%d:%s", r.MinimumBalance, r.MinimumDays, normalizedAddr))
+
+	if r.cache != nil && !refreshingCache(ctx) {
+		if cached, ok := r.cache.Get(cacheKey); ok {
+			if held, ok := cached.(bool); ok {
+				return held, nil
+			}
+		}
+	}
+
+	held, err := r.heldContinuously(ctx, normalizedAddr)
+	if err != nil {
+		logRPCFailure(r.logger, err, "RPC call failed for holding duration",
+			zap.String("token", r.ContractAddress),
+			zap.String("address", address),
+			zap.Uint64("chainID", r.ChainID))
+		return false, nil
+	}
+
+	if r.cache != nil {
+		r.cache.Set(cacheKey, held)
+	}
+
+	r.logger.Info("holding duration check completed",
+		zap.String("address", address),
+		zap.String("token", r.ContractAddress),
+		zap.String("minimum", r.MinimumBalance.String()),
+		zap.Int("days", r.MinimumDays),
+		zap.Bool("held", held))
+
+	return held, nil
+}
+
+// heldContinuously reports whether address held the minimum balance at every
+// point since the start of the holding period
+func (r *HoldingDurationRule) heldContinuously(ctx context.Context, address string) (bool, error) {
+	// Truncating to the hour lets evaluations within the hour share the block lookup
+	start := time.Now().Add(-time.Duration(r.MinimumDays) * 24 * time.Hour).Truncate(time.Hour)
+	startBlock, ok, err := r.blockAt(ctx, start.Unix())
+	if err != nil || !ok {
+		return false, err
+	}
+
+	startBlockHex := "0x" + strconv.FormatUint(startBlock, 16)
+	resultHex, err := ethCallAt(ctx, r.provider, r.ContractAddress, encodeERC20BalanceOfCall(r.ContractAddress, address), startBlockHex)
+	if err != nil {
+		return false, err
+	}
+	balance, err := decodeUint256(resultHex)
+	if err != nil {
+		return false, err
+	}
+	if balance.Cmp(r.MinimumBalance) < 0 {
+		return false, nil
+	}
+
+	transfers, err := r.transfersSince(ctx, address, startBlock+1)
+	if err != nil {
+		return false, err
+	}
+	for _, transfer := range transfers {
+		amount, err := transferAmount(transfer)
+		if err != nil {
+			return false, err
+		}
+		if strings.EqualFold(transfer.Topics[1], encodeAddress(address)) {
+			balance.Sub(balance, amount)
+			if balance.Cmp(r.MinimumBalance) < 0 {
+				return false, nil
+			}
+		} else {
+			balance.Add(balance, amount)
+		}
+	}
+	return true, nil
+}
+
+// transfersSince returns the transfers from and to address since fromBlock, in chain order.
+// Transfers from address to itself are left out as they do not change its balance.
+func (r *HoldingDurationRule) transfersSince(ctx context.Context, address string, fromBlock uint64) ([]ethLog, error) {
+	type position struct{ block, index uint64 }
+	topic := encodeAddress(address)
+	var transfers []ethLog
+	var positions []position
+	for _, topics := range [][]interface{}{
+		{TransferEventTopic, topic},
+		{TransferEventTopic, nil, topic},
+	} {
+		logs, err := ethGetLogs(ctx, r.provider, map[string]interface{}{
+			"address":   r.ContractAddress,
+			"topics":    topics,
+			"fromBlock": "0x" + strconv.FormatUint(fromBlock, 16),
+			"toBlock":   "latest",
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, log := range logs {
+			if len(log.Topics) < 3 || strings.EqualFold(log.Topics[1], log.Topics[2]) {
+				continue
+			}
+			block, err := parseQuantity(log.BlockNumber)
+			if err != nil {
+				return nil, err
+			}
+			index, err := parseQuantity(log.LogIndex)
+			if err != nil {
+				return nil, err
+			}
+			transfers = append(transfers, log)
+			positions = append(positions, position{block, index})
+		}
+	}
+
+	order := make([]int, len(transfers))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		a, b := positions[order[i]], positions[order[j]]
+		return a.block < b.block || (a.block == b.block && a.index < b.index)
+	})
+	sorted := make([]ethLog, len(order))
+	for i, k := range order {
+		sorted[i] = transfers[k]
+	}
+	return sorted, nil
+}
+
+// transferAmount returns the amount moved by a Transfer event: the value in the
+// data of an ERC20 transfer, or 1 for an ERC721 transfer, whose token ID is indexed
+func transferAmount(log ethLog) (*big.Int, error) {
+	if len(log.Topics) == 4 {
+		return big.NewInt(1), nil
+	}
+	return decodeUint256(log.Data)
+}
+
+// blockAt returns the last block mined at or before the Unix timestamp. It reports
+// false if the chain has no block that old.
+func (r *HoldingDurationRule) blockAt(ctx context.Context, timestamp int64) (uint64, bool, error) {
+	// Generate cache key: "block_at_time:{chainID}::{timestamp}"
+	chainIDStr := strconv.FormatUint(r.ChainID, 10)
+	cacheKey := chain.CacheKey("block_at_time", chainIDStr, "", strconv.FormatInt(timestamp, 10))
+	if r.cache != nil {
+		if cached, ok := r.cache.Get(cacheKey); ok {
+			if block, ok := cached.(uint64); ok {
+				return block, true, nil
+			}
+		}
+	}
+
+	blockTime := func(block string) (uint64, int64, error) {
+		header, err := ethGetBlock(ctx, r.provider, block)
+		if err != nil {
+			return 0, 0, err
+		}
+		number, err := parseQuantity(header.Number)
+		if err != nil {
+			return 0, 0, err
+		}
+		ts, err := parseQuantity(header.Timestamp)
+		return number, int64(ts), err
+	}
+
+	latest, latestTime, err := blockTime("latest")
+	if err != nil {
+		return 0, false, err
+	}
+	found := latest
+	if latestTime > timestamp {
+		if _, genesisTime, err := blockTime("0x0"); err != nil || genesisTime > timestamp {
+			return 0, false, err
+		}
+
+		// Binary search for the last block at or before timestamp
+		low, high := uint64(0), latest
+		for high-low > 1 {
+			mid := low + (high-low)/2
+			_, midTime, err := blockTime("0x" + strconv.FormatUint(mid, 16))
+			if err != nil {
+				return 0, false, err
+			}
+			if midTime <= timestamp {
+				low = mid
+			} else {
+				high = mid
+			}
+		}
+		found = low
+	}
+
+	if r.cache != nil {
+		r.cache.Set(cacheKey, found)
+	}
+	return found, true, nil
+}
+
+// SetProvider sets the blockchain provider for RPC calls
+func (r *HoldingDurationRule) SetProvider(provider BlockchainProvider) {
+	r.provider = provider
+}
+
+// SetCache sets the cache for storing results
+func (r *HoldingDurationRule) SetCache(cache CacheProvider) {
+	r.cache = withCacheTTL(cache, r.CacheTTL())
+}
+
+// SetLogger sets the logger for the rule
+func (r *HoldingDurationRule) SetLogger(logger *zap.Logger) {
+	r.logger = logger
+}
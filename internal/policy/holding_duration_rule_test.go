@@ -0,0 +1,270 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Blocks of the simulated chain: 1001 blocks, 10 a day, the latest mined now
+const (
+	historyBlocks      = 1000
+	historyBlockPeriod = int64(24*60*60) / 10
+)
+
+// testTransfer is a token transfer in the simulated chain history
+type testTransfer struct {
+	block    uint64
+	from, to string
+	amount   int64
+	nft      bool // ERC721 transfers index the token ID and carry no data
+}
+
+// historyProvider serves blocks, historical balances and transfer logs of one token
+type historyProvider struct {
+	genesis   int64
+	transfers []testTransfer
+	calls     int
+}
+
+func newHistoryProvider(transfers ...testTransfer) *historyProvider {
+	return &historyProvider{
+		genesis:   time.Now().Unix() - historyBlocks*historyBlockPeriod,
+		transfers: transfers,
+	}
+}
+
+// day returns the block mined the given number of days after genesis
+func day(n int) uint64 {
+	return uint64(n) * 10
+}
+
+func (p *historyProvider) Call(ctx context.Context, method string, params []interface{}) ([]byte, error) {
+	p.calls++
+	result := func(value interface{}) ([]byte, error) {
+		return json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": value})
+	}
+
+	switch method {
+	case "eth_getBlockByNumber":
+		block := uint64(historyBlocks)
+		if tag := params[0].(string); tag != "latest" {
+			block, _ = parseQuantity(tag)
+		}
+		if block > historyBlocks {
+			return result(nil)
+		}
+		return result(map[string]string{
+			"number":    fmt.Sprintf("0x%x", block),
+			"timestamp": fmt.Sprintf("0x%x", p.genesis+int64(block)*historyBlockPeriod),
+		})
+	case "eth_call":
+		block, _ := parseQuantity(params[1].(string))
+		holder := "0x" + params[0].(map[string]interface{})["data"].(string)[34:]
+		balance := int64(0)
+		for _, transfer := range p.transfers {
+			if transfer.block > block {
+				continue
+			}
+			if transfer.to == holder {
+				balance += transfer.amount
+			}
+			if transfer.from == holder {
+				balance -= transfer.amount
+			}
+		}
+		return rpcResult(fmt.Sprintf("%064x", balance)), nil
+	case "eth_getLogs":
+		filter := params[0].(map[string]interface{})
+		fromBlock, _ := parseQuantity(filter["fromBlock"].(string))
+		topics := filter["topics"].([]interface{})
+		logs := []map[string]interface{}{}
+		for i, transfer := range p.transfers {
+			log := map[string]interface{}{
+				"address":     filter["address"],
+				"topics":      []string{TransferEventTopic, encodeAddress(transfer.from), encodeAddress(transfer.to)},
+				"data":        fmt.Sprintf("0x%064x", transfer.amount),
+				"blockNumber": fmt.Sprintf("0x%x", transfer.block),
+				"logIndex":    "0x" + strconv.Itoa(i),
+			}
+			if transfer.nft {
+				log["topics"] = append(log["topics"].([]string), fmt.Sprintf("0x%064x", i))
+				log["data"] = "0x"
+			}
+			if transfer.block < fromBlock || !matchesTopics(log["topics"].([]string), topics) {
+				continue
+			}
+			logs = append(logs, log)
+		}
+		return result(logs)
+	}
+	return nil, fmt.Errorf("unexpected method %s", method)
+}
+
+func (p *historyProvider) HealthCheck(ctx context.Context) bool {
+	return true
+}
+
+// matchesTopics applies an eth_getLogs topic filter, where nil matches any topic
+func matchesTopics(topics []string, filter []interface{}) bool {
+	for i, want := range filter {
+		if want != nil && (i >= len(topics) || !strings.EqualFold(topics[i], want.(string))) {
+			return false
+		}
+	}
+	return true
+}
+
+// TestHoldingDurationRule_Validate validates rule parameters
+func TestHoldingDurationRule_Validate(t *testing.T) {
+	assert.NoError(t, NewHoldingDurationRule(testTokenAddr, big.NewInt(1), 30, 1).Validate())
+
+	assert.Error(t, NewHoldingDurationRule("0x1234", big.NewInt(1), 30, 1).Validate())
+	assert.Error(t, NewHoldingDurationRule(testTokenAddr, nil, 30, 1).Validate())
+	assert.Error(t, NewHoldingDurationRule(testTokenAddr, big.NewInt(0), 30, 1).Validate())
+	assert.Error(t, NewHoldingDurationRule(testTokenAddr, big.NewInt(1), 0, 1).Validate())
+	assert.Error(t, NewHoldingDurationRule(testTokenAddr, big.NewInt(1), 30, 0).Validate())
+}
+
+// TestHoldingDurationRule_Evaluate replays the transfers of the holding period.
+// The chain is 100 days old and the rule requires 100 tokens held for 30 days,
+// i.e. since day 70.
+func TestHoldingDurationRule_Evaluate(t *testing.T) {
+	const mint = "0x0000000000000000000000000000000000000000"
+	other := testUserAddr2
+
+	tests := []struct {
+		name      string
+		transfers []testTransfer
+		expected  bool
+	}{
+		{
+			name:      "held since before the period",
+			transfers: []testTransfer{{block: day(10), from: mint, to: testUserAddr, amount: 500}},
+			expected:  true,
+		},
+		{
+			name:      "bought during the period",
+			transfers: []testTransfer{{block: day(95), from: mint, to: testUserAddr, amount: 500}},
+			expected:  false,
+		},
+		{
+			name: "spent part, stayed above the minimum",
+			transfers: []testTransfer{
+				{block: day(10), from: mint, to: testUserAddr, amount: 500},
+				{block: day(80), from: testUserAddr, to: other, amount: 300},
+			},
+			expected: true,
+		},
+		{
+			name: "dropped below the minimum and bought back",
+			transfers: []testTransfer{
+				{block: day(10), from: mint, to: testUserAddr, amount: 500},
+				{block: day(80), from: testUserAddr, to: other, amount: 450},
+				{block: day(85), from: other, to: testUserAddr, amount: 450},
+			},
+			expected: false,
+		},
+		{
+			name: "received before sending in the same block",
+			transfers: []testTransfer{
+				{block: day(10), from: mint, to: testUserAddr, amount: 100},
+				{block: day(80), from: other, to: testUserAddr, amount: 50},
+				{block: day(80), from: testUserAddr, to: other, amount: 50},
+			},
+			expected: true,
+		},
+		{
+			name: "transfers to self",
+			transfers: []testTransfer{
+				{block: day(10), from: mint, to: testUserAddr, amount: 100},
+				{block: day(80), from: testUserAddr, to: testUserAddr, amount: 100},
+			},
+			expected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewHoldingDurationRule(testTokenAddr, big.NewInt(100), 30, 1)
+			rule.SetProvider(newHistoryProvider(tt.transfers...))
+
+			result, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+// TestHoldingDurationRule_Evaluate_ERC721 counts each NFT transfer as one token
+func TestHoldingDurationRule_Evaluate_ERC721(t *testing.T) {
+	const mint = "0x0000000000000000000000000000000000000000"
+	provider := newHistoryProvider(
+		testTransfer{block: day(10), from: mint, to: testUserAddr, amount: 1, nft: true},
+		testTransfer{block: day(20), from: mint, to: testUserAddr, amount: 1, nft: true},
+		testTransfer{block: day(80), from: testUserAddr, to: testUserAddr2, amount: 1, nft: true},
+	)
+
+	holdsOne := NewHoldingDurationRule(testNFTAddr, big.NewInt(1), 30, 1)
+	holdsOne.SetProvider(provider)
+	result, err := holdsOne.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.True(t, result)
+
+	holdsTwo := NewHoldingDurationRule(testNFTAddr, big.NewInt(2), 30, 1)
+	holdsTwo.SetProvider(provider)
+	result, err = holdsTwo.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, result)
+}
+
+// TestHoldingDurationRule_Evaluate_ChainTooYoung fails when the period starts before genesis
+func TestHoldingDurationRule_Evaluate_ChainTooYoung(t *testing.T) {
+	rule := NewHoldingDurationRule(testTokenAddr, big.NewInt(1), 200, 1)
+	rule.SetProvider(newHistoryProvider(testTransfer{block: 0, from: testUserAddr2, to: testUserAddr, amount: 1}))
+
+	result, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, result)
+}
+
+// TestHoldingDurationRule_Evaluate_Cache caches the result and the start block
+func TestHoldingDurationRule_Evaluate_Cache(t *testing.T) {
+	provider := newHistoryProvider(testTransfer{block: day(10), from: testUserAddr2, to: testUserAddr, amount: 100})
+	cache := &MockCache{}
+	rule := NewHoldingDurationRule(testTokenAddr, big.NewInt(100), 30, 1)
+	rule.SetProvider(provider)
+	rule.SetCache(cache)
+
+	result, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.True(t, result)
+	calls := provider.calls
+
+	// The second address reuses the start block found for the first
+	result, err = rule.Evaluate(context.Background(), testUserAddr2, nil)
+	require.NoError(t, err)
+	assert.False(t, result)
+	assert.Equal(t, calls+1, provider.calls, "only the historical balance is read")
+
+	result, err = rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.True(t, result)
+	assert.Equal(t, calls+1, provider.calls)
+}
+
+// TestHoldingDurationRule_Evaluate_NoProvider fails closed without a provider
+func TestHoldingDurationRule_Evaluate_NoProvider(t *testing.T) {
+	rule := NewHoldingDurationRule(testTokenAddr, big.NewInt(1), 30, 1)
+
+	result, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, result)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"time"
 )
 
 // PolicyLoader handles loading and validating policies from JSON
@@ -16,10 +17,21 @@ func NewPolicyLoader() *PolicyLoader {
 
 // policyConfig represents the JSON structure for a policy
 type policyConfig struct {
-	Path   string          `json:"path"`
-	Method string          `json:"method"`
-	Logic  string          `json:"logic"`
+	Path   string            `json:"path"`
+	Method string            `json:"method"`
+	Logic  string            `json:"logic"`
 	Rules  []json.RawMessage `json:"rules"`
+	Shadow bool              `json:"shadow,omitempty"` // Log-only: record the decision, never deny
+	// Enforce false is the same as shadow true, for configurations that name the flag
+	// after what it turns off
+	Enforce *bool `json:"enforce,omitempty"`
+	// Points a SCORE policy needs; each rule then sets the points it is worth
+	Threshold int `json:"threshold,omitempty"`
+	// Tier a TIER policy grants callers passing none of its rules, each of which
+	// sets the tier it grants; callers are denied without one
+	DefaultTier string `json:"default_tier,omitempty"`
+	// Go duration to cache the decision of the policy for an address, e.g. "5m"
+	CacheTTL string `json:"cache_ttl,omitempty"`
 }
 
 // ruleConfig represents the base structure for a rule
@@ -46,12 +58,25 @@ func (l *PolicyLoader) LoadFromJSON(data []byte) ([]*Policy, error) {
 	return policies, nil
 }
 
+// ParsePolicy parses and validates a single policy in the format of one element of
+// the array read by LoadFromJSON
+func (l *PolicyLoader) ParsePolicy(data []byte) (*Policy, error) {
+	var config policyConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	return l.loadPolicy(config, 0)
+}
+
 // loadPolicy validates and loads a single policy configuration
 func (l *PolicyLoader) loadPolicy(config policyConfig, index int) (*Policy, error) {
 	// Validate required fields
 	if config.Path == "" {
 		return nil, fmt.Errorf("policy %d: path is required", index)
 	}
+	if err := ValidateRoutePattern(config.Path); err != nil {
+		return nil, fmt.Errorf("policy %d: invalid path: %w", index, err)
+	}
 	if config.Method == "" {
 		return nil, fmt.Errorf("policy %d: method is required", index)
 	}
@@ -59,9 +84,28 @@ func (l *PolicyLoader) loadPolicy(config policyConfig, index int) (*Policy, erro
 		return nil, fmt.Errorf("policy %d: logic is required", index)
 	}
 
-	// Validate logic is AND or OR
-	if config.Logic != "AND" && config.Logic != "OR" {
-		return nil, fmt.Errorf("policy %d: logic must be 'AND' or 'OR', got '%s'", index, config.Logic)
+	// Validate logic is AND, OR, SCORE or TIER
+	if config.Logic != "AND" && config.Logic != "OR" && config.Logic != "SCORE" && config.Logic != "TIER" {
+		return nil, fmt.Errorf("policy %d: logic must be 'AND', 'OR', 'SCORE' or 'TIER', got '%s'", index, config.Logic)
+	}
+	if config.Logic == "SCORE" && config.Threshold <= 0 {
+		return nil, fmt.Errorf("policy %d: threshold must be positive for SCORE logic", index)
+	}
+	if config.Logic != "SCORE" && config.Threshold != 0 {
+		return nil, fmt.Errorf("policy %d: threshold is only allowed with SCORE logic", index)
+	}
+	if config.Logic != "TIER" && config.DefaultTier != "" {
+		return nil, fmt.Errorf("policy %d: default_tier is only allowed with TIER logic", index)
+	}
+	if len(config.DefaultTier) > maxTierLength {
+		return nil, fmt.Errorf("policy %d: default_tier must be at most %d characters", index, maxTierLength)
+	}
+	if config.Logic == "TIER" && config.CacheTTL != "" {
+		return nil, fmt.Errorf("policy %d: cache_ttl is not allowed with TIER logic, whose tier depends on the rule that passes", index)
+	}
+
+	if config.Enforce != nil && *config.Enforce && config.Shadow {
+		return nil, fmt.Errorf("policy %d: a shadow policy cannot be enforced", index)
 	}
 
 	// Validate rules exist
@@ -75,7 +119,80 @@ func (l *PolicyLoader) loadPolicy(config policyConfig, index int) (*Policy, erro
 		return nil, err
 	}
 
-	return NewPolicy(config.Method, config.Path, config.Logic, rules), nil
+	policy := NewPolicy(config.Method, config.Path, config.Logic, rules)
+	policy.Shadow = config.Shadow || (config.Enforce != nil && !*config.Enforce)
+	if config.CacheTTL != "" {
+		ttl, err := time.ParseDuration(config.CacheTTL)
+		if err != nil {
+			return nil, fmt.Errorf("policy %d: invalid cache_ttl format: %w", index, err)
+		}
+		if ttl <= 0 {
+			return nil, fmt.Errorf("policy %d: cache_ttl must be positive", index)
+		}
+		if err := checkDecisionCacheable(rules); err != nil {
+			return nil, fmt.Errorf("policy %d: cache_ttl is not allowed: %w", index, err)
+		}
+		policy.CacheTTL = ttl
+	}
+	if config.Logic == "SCORE" {
+		points, err := l.loadPoints(config.Rules, index)
+		if err != nil {
+			return nil, err
+		}
+		policy.Points = points
+		policy.Threshold = config.Threshold
+	}
+	if config.Logic == "TIER" {
+		tiers, err := l.loadTiers(config.Rules, index)
+		if err != nil {
+			return nil, err
+		}
+		policy.Tiers = tiers
+		policy.DefaultTier = config.DefaultTier
+	}
+	return policy, nil
+}
+
+// loadPoints reads the points each rule of a SCORE policy is worth
+func (l *PolicyLoader) loadPoints(rawRules []json.RawMessage, policyIndex int) ([]int, error) {
+	points := make([]int, len(rawRules))
+	for i, rawRule := range rawRules {
+		var config struct {
+			Points *int `json:"points"`
+		}
+		if err := json.Unmarshal(rawRule, &config); err != nil {
+			return nil, fmt.Errorf("policy %d rule %d: invalid points: %w", policyIndex, i, err)
+		}
+		if config.Points == nil || *config.Points <= 0 {
+			return nil, fmt.Errorf("policy %d rule %d: points must be positive for SCORE logic", policyIndex, i)
+		}
+		points[i] = *config.Points
+	}
+	return points, nil
+}
+
+// maxTierLength is the longest tier name, as stored with persisted policies
+const maxTierLength = 64
+
+// loadTiers reads the tier each rule of a TIER policy grants
+func (l *PolicyLoader) loadTiers(rawRules []json.RawMessage, policyIndex int) ([]string, error) {
+	tiers := make([]string, len(rawRules))
+	for i, rawRule := range rawRules {
+		var config struct {
+			Tier string `json:"tier"`
+		}
+		if err := json.Unmarshal(rawRule, &config); err != nil {
+			return nil, fmt.Errorf("policy %d rule %d: invalid tier: %w", policyIndex, i, err)
+		}
+		if config.Tier == "" {
+			return nil, fmt.Errorf("policy %d rule %d: tier is required for TIER logic", policyIndex, i)
+		}
+		if len(config.Tier) > maxTierLength {
+			return nil, fmt.Errorf("policy %d rule %d: tier must be at most %d characters", policyIndex, i, maxTierLength)
+		}
+		tiers[i] = config.Tier
+	}
+	return tiers, nil
 }
 
 // loadRules parses and validates rules
@@ -93,8 +210,40 @@ func (l *PolicyLoader) loadRules(rawRules []json.RawMessage, policyIndex int) ([
 	return rules, nil
 }
 
-// loadRule parses and validates a single rule
+// loadRule parses and validates a single rule, and the TTL of its cached reads
 func (l *PolicyLoader) loadRule(rawRule json.RawMessage, policyIndex, ruleIndex int) (Rule, error) {
+	rule, err := l.loadRuleOfType(rawRule, policyIndex, ruleIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	var config struct {
+		CacheTTL string `json:"cache_ttl"` // Go duration, e.g. "30s"
+	}
+	if err := json.Unmarshal(rawRule, &config); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: invalid cache_ttl: %w", policyIndex, ruleIndex, err)
+	}
+	if config.CacheTTL == "" {
+		return rule, nil
+	}
+
+	cached, ok := rule.(cachingRule)
+	if !ok {
+		return nil, fmt.Errorf("policy %d rule %d: cache_ttl is only allowed for rules that read the blockchain", policyIndex, ruleIndex)
+	}
+	ttl, err := time.ParseDuration(config.CacheTTL)
+	if err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: invalid cache_ttl format: %w", policyIndex, ruleIndex, err)
+	}
+	if ttl <= 0 {
+		return nil, fmt.Errorf("policy %d rule %d: cache_ttl must be positive", policyIndex, ruleIndex)
+	}
+	cached.SetCacheTTL(ttl)
+	return rule, nil
+}
+
+// loadRuleOfType parses and validates a single rule by its type
+func (l *PolicyLoader) loadRuleOfType(rawRule json.RawMessage, policyIndex, ruleIndex int) (Rule, error) {
 	// First, extract rule type
 	var baseConfig ruleConfig
 	if err := json.Unmarshal(rawRule, &baseConfig); err != nil {
@@ -115,11 +264,110 @@ func (l *PolicyLoader) loadRule(rawRule json.RawMessage, policyIndex, ruleIndex
 		return l.loadERC20MinBalanceRule(rawRule, policyIndex, ruleIndex)
 	case "erc721_owner":
 		return l.loadERC721OwnerRule(rawRule, policyIndex, ruleIndex)
+	case "erc721_min_balance":
+		return l.loadERC721MinBalanceRule(rawRule, policyIndex, ruleIndex)
+	case "erc721_trait":
+		return l.loadERC721TraitRule(rawRule, policyIndex, ruleIndex)
+	case "staked_balance":
+		return l.loadStakedBalanceRule(rawRule, policyIndex, ruleIndex)
+	case "voting_power":
+		return l.loadVotingPowerRule(rawRule, policyIndex, ruleIndex)
+	case "contract_call":
+		return l.loadContractCallRule(rawRule, policyIndex, ruleIndex)
+	case "lp_position":
+		return l.loadLPPositionRule(rawRule, policyIndex, ruleIndex)
+	case "holding_duration":
+		return l.loadHoldingDurationRule(rawRule, policyIndex, ruleIndex)
+	case "code_exists":
+		return l.loadCodeExistsRule(rawRule, policyIndex, ruleIndex)
+	case "contract_deployer":
+		return l.loadContractDeployerRule(rawRule, policyIndex, ruleIndex)
+	case "header_equals":
+		return l.loadHeaderEqualsRule(rawRule, policyIndex, ruleIndex)
+	case "header_matches":
+		return l.loadHeaderMatchesRule(rawRule, policyIndex, ruleIndex)
+	case "query_param_present":
+		return l.loadQueryParamPresentRule(rawRule, policyIndex, ruleIndex)
+	case "query_param_matches":
+		return l.loadQueryParamMatchesRule(rawRule, policyIndex, ruleIndex)
+	case "http_method":
+		return l.loadHTTPMethodRule(rawRule, policyIndex, ruleIndex)
+	case "geo_restriction":
+		return l.loadGeoRestrictionRule(rawRule, policyIndex, ruleIndex)
+	case "claim_match":
+		return l.loadClaimMatchRule(rawRule, policyIndex, ruleIndex)
+	case "quota":
+		return l.loadQuotaRule(rawRule, policyIndex, ruleIndex)
+	case "not_in_blocklist":
+		return NewNotInBlocklistRule(), nil
+	case "webhook":
+		return l.loadWebhookRule(rawRule, policyIndex, ruleIndex)
+	case "plugin":
+		return l.loadPluginRule(rawRule, policyIndex, ruleIndex)
+	case "all_of", "any_of":
+		return l.loadGroupRule(rawRule, baseConfig.Type, policyIndex, ruleIndex)
+	case "not":
+		return l.loadNotRule(rawRule, policyIndex, ruleIndex)
 	default:
 		return nil, fmt.Errorf("policy %d rule %d: unknown rule type '%s'", policyIndex, ruleIndex, baseConfig.Type)
 	}
 }
 
+// loadGroupRule parses an all_of or any_of rule. Errors in nested rules are
+// reported against the rule index of the group.
+func (l *PolicyLoader) loadGroupRule(rawRule json.RawMessage, ruleType string, policyIndex, ruleIndex int) (Rule, error) {
+	type groupConfig struct {
+		Type  string            `json:"type"`
+		Rules []json.RawMessage `json:"rules"`
+	}
+
+	var config groupConfig
+	if err := json.Unmarshal(rawRule, &config); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: invalid %s rule: %w", policyIndex, ruleIndex, ruleType, err)
+	}
+
+	if len(config.Rules) == 0 {
+		return nil, fmt.Errorf("policy %d rule %d: rules is required for %s rule", policyIndex, ruleIndex, ruleType)
+	}
+
+	rules := make([]Rule, 0, len(config.Rules))
+	for _, rawChild := range config.Rules {
+		child, err := l.loadRule(rawChild, policyIndex, ruleIndex)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, child)
+	}
+
+	if ruleType == "any_of" {
+		return AnyOf(rules...), nil
+	}
+	return AllOf(rules...), nil
+}
+
+// loadNotRule parses a not rule
+func (l *PolicyLoader) loadNotRule(rawRule json.RawMessage, policyIndex, ruleIndex int) (*NotRule, error) {
+	type notConfig struct {
+		Type string          `json:"type"`
+		Rule json.RawMessage `json:"rule"`
+	}
+
+	var config notConfig
+	if err := json.Unmarshal(rawRule, &config); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: invalid not rule: %w", policyIndex, ruleIndex, err)
+	}
+
+	if len(config.Rule) == 0 || string(config.Rule) == "null" {
+		return nil, fmt.Errorf("policy %d rule %d: rule is required for not rule", policyIndex, ruleIndex)
+	}
+
+	child, err := l.loadRule(config.Rule, policyIndex, ruleIndex)
+	if err != nil {
+		return nil, err
+	}
+	return Not(child), nil
+}
+
 // loadHasScopeRule parses a has_scope rule
 func (l *PolicyLoader) loadHasScopeRule(rawRule json.RawMessage, policyIndex, ruleIndex int) (*HasScopeRule, error) {
 	type hasScopeConfig struct {
@@ -158,6 +406,241 @@ func (l *PolicyLoader) loadInAllowlistRule(rawRule json.RawMessage, policyIndex,
 	return NewInAllowlistRule(config.Addresses), nil
 }
 
+// loadHeaderEqualsRule parses a header_equals rule
+func (l *PolicyLoader) loadHeaderEqualsRule(rawRule json.RawMessage, policyIndex, ruleIndex int) (*HeaderEqualsRule, error) {
+	type headerEqualsConfig struct {
+		Type   string `json:"type"`
+		Header string `json:"header"`
+		Value  string `json:"value"`
+	}
+
+	var config headerEqualsConfig
+	if err := json.Unmarshal(rawRule, &config); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: invalid header_equals rule: %w", policyIndex, ruleIndex, err)
+	}
+
+	if config.Header == "" {
+		return nil, fmt.Errorf("policy %d rule %d: header is required for header_equals rule", policyIndex, ruleIndex)
+	}
+
+	return NewHeaderEqualsRule(config.Header, config.Value), nil
+}
+
+// loadHeaderMatchesRule parses a header_matches rule
+func (l *PolicyLoader) loadHeaderMatchesRule(rawRule json.RawMessage, policyIndex, ruleIndex int) (*HeaderMatchesRule, error) {
+	type headerMatchesConfig struct {
+		Type    string `json:"type"`
+		Header  string `json:"header"`
+		Pattern string `json:"pattern"`
+	}
+
+	var config headerMatchesConfig
+	if err := json.Unmarshal(rawRule, &config); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: invalid header_matches rule: %w", policyIndex, ruleIndex, err)
+	}
+
+	rule := NewHeaderMatchesRule(config.Header, config.Pattern)
+	if err := rule.Validate(); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: %w", policyIndex, ruleIndex, err)
+	}
+	return rule, nil
+}
+
+// loadQueryParamPresentRule parses a query_param_present rule
+func (l *PolicyLoader) loadQueryParamPresentRule(rawRule json.RawMessage, policyIndex, ruleIndex int) (*QueryParamPresentRule, error) {
+	type queryParamConfig struct {
+		Type  string `json:"type"`
+		Param string `json:"param"`
+	}
+
+	var config queryParamConfig
+	if err := json.Unmarshal(rawRule, &config); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: invalid query_param_present rule: %w", policyIndex, ruleIndex, err)
+	}
+
+	if config.Param == "" {
+		return nil, fmt.Errorf("policy %d rule %d: param is required for query_param_present rule", policyIndex, ruleIndex)
+	}
+
+	return NewQueryParamPresentRule(config.Param), nil
+}
+
+// loadQueryParamMatchesRule parses a query_param_matches rule
+func (l *PolicyLoader) loadQueryParamMatchesRule(rawRule json.RawMessage, policyIndex, ruleIndex int) (*QueryParamMatchesRule, error) {
+	type queryParamMatchesConfig struct {
+		Type    string `json:"type"`
+		Param   string `json:"param"`
+		Pattern string `json:"pattern"`
+	}
+
+	var config queryParamMatchesConfig
+	if err := json.Unmarshal(rawRule, &config); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: invalid query_param_matches rule: %w", policyIndex, ruleIndex, err)
+	}
+
+	rule := NewQueryParamMatchesRule(config.Param, config.Pattern)
+	if err := rule.Validate(); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: %w", policyIndex, ruleIndex, err)
+	}
+	return rule, nil
+}
+
+// loadHTTPMethodRule parses an http_method rule
+func (l *PolicyLoader) loadHTTPMethodRule(rawRule json.RawMessage, policyIndex, ruleIndex int) (*HTTPMethodRule, error) {
+	type httpMethodConfig struct {
+		Type    string   `json:"type"`
+		Methods []string `json:"methods"`
+	}
+
+	var config httpMethodConfig
+	if err := json.Unmarshal(rawRule, &config); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: invalid http_method rule: %w", policyIndex, ruleIndex, err)
+	}
+
+	if len(config.Methods) == 0 {
+		return nil, fmt.Errorf("policy %d rule %d: methods is required for http_method rule", policyIndex, ruleIndex)
+	}
+
+	return NewHTTPMethodRule(config.Methods), nil
+}
+
+// loadQuotaRule parses a quota rule
+func (l *PolicyLoader) loadQuotaRule(rawRule json.RawMessage, policyIndex, ruleIndex int) (*QuotaRule, error) {
+	type quotaConfig struct {
+		Type   string `json:"type"`
+		Name   string `json:"name"`
+		Limit  int64  `json:"limit"`
+		Window string `json:"window"` // Go duration, e.g. "24h"
+	}
+
+	var config quotaConfig
+	if err := json.Unmarshal(rawRule, &config); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: invalid quota rule: %w", policyIndex, ruleIndex, err)
+	}
+
+	if config.Window == "" {
+		return nil, fmt.Errorf("policy %d rule %d: window is required for quota rule", policyIndex, ruleIndex)
+	}
+	window, err := time.ParseDuration(config.Window)
+	if err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: invalid window format: %w", policyIndex, ruleIndex, err)
+	}
+
+	rule := NewQuotaRule(config.Name, config.Limit, window)
+	if err := rule.Validate(); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: %w", policyIndex, ruleIndex, err)
+	}
+	return rule, nil
+}
+
+// loadWebhookRule parses a webhook rule
+func (l *PolicyLoader) loadWebhookRule(rawRule json.RawMessage, policyIndex, ruleIndex int) (*WebhookRule, error) {
+	type webhookConfig struct {
+		Type     string `json:"type"`
+		URL      string `json:"url"`
+		Timeout  string `json:"timeout"` // Go duration, defaults to 2s
+		Retries  int    `json:"retries"`
+		FailOpen bool   `json:"fail_open"`
+	}
+
+	var config webhookConfig
+	if err := json.Unmarshal(rawRule, &config); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: invalid webhook rule: %w", policyIndex, ruleIndex, err)
+	}
+
+	var timeout time.Duration
+	if config.Timeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(config.Timeout); err != nil {
+			return nil, fmt.Errorf("policy %d rule %d: invalid timeout format: %w", policyIndex, ruleIndex, err)
+		}
+	}
+
+	rule := NewWebhookRule(config.URL, timeout, config.Retries, config.FailOpen)
+	if err := rule.Validate(); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: %w", policyIndex, ruleIndex, err)
+	}
+	return rule, nil
+}
+
+// loadPluginRule parses a plugin rule
+func (l *PolicyLoader) loadPluginRule(rawRule json.RawMessage, policyIndex, ruleIndex int) (*PluginRule, error) {
+	type pluginConfig struct {
+		Type    string          `json:"type"`
+		Plugin  string          `json:"plugin"`
+		Params  json.RawMessage `json:"params"`
+		Timeout string          `json:"timeout"` // Go duration, defaults to 100ms
+	}
+
+	var config pluginConfig
+	if err := json.Unmarshal(rawRule, &config); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: invalid plugin rule: %w", policyIndex, ruleIndex, err)
+	}
+
+	var timeout time.Duration
+	if config.Timeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(config.Timeout); err != nil {
+			return nil, fmt.Errorf("policy %d rule %d: invalid timeout format: %w", policyIndex, ruleIndex, err)
+		}
+	}
+	if string(config.Params) == "null" {
+		config.Params = nil
+	}
+
+	rule := NewPluginRule(config.Plugin, config.Params, timeout)
+	if err := rule.Validate(); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: %w", policyIndex, ruleIndex, err)
+	}
+	return rule, nil
+}
+
+// loadClaimMatchRule parses a claim_match rule
+func (l *PolicyLoader) loadClaimMatchRule(rawRule json.RawMessage, policyIndex, ruleIndex int) (*ClaimMatchRule, error) {
+	type claimMatchConfig struct {
+		Type     string      `json:"type"`
+		Claim    string      `json:"claim"`
+		Operator string      `json:"operator"` // defaults to "eq"
+		Value    interface{} `json:"value"`
+	}
+
+	var config claimMatchConfig
+	if err := json.Unmarshal(rawRule, &config); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: invalid claim_match rule: %w", policyIndex, ruleIndex, err)
+	}
+
+	if config.Operator == "" {
+		config.Operator = ClaimOpEquals
+	}
+
+	rule := NewClaimMatchRule(config.Claim, config.Operator, config.Value)
+	if err := rule.Validate(); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: %w", policyIndex, ruleIndex, err)
+	}
+	return rule, nil
+}
+
+// loadGeoRestrictionRule parses a geo_restriction rule
+func (l *PolicyLoader) loadGeoRestrictionRule(rawRule json.RawMessage, policyIndex, ruleIndex int) (*GeoRestrictionRule, error) {
+	type geoRestrictionConfig struct {
+		Type      string   `json:"type"`
+		Mode      string   `json:"mode"` // "allow" or "deny"
+		Countries []string `json:"countries"`
+		ASNs      []uint32 `json:"asns"`
+	}
+
+	var config geoRestrictionConfig
+	if err := json.Unmarshal(rawRule, &config); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: invalid geo_restriction rule: %w", policyIndex, ruleIndex, err)
+	}
+
+	rule := NewGeoRestrictionRule(config.Mode, config.Countries, config.ASNs)
+	if err := rule.Validate(); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: %w", policyIndex, ruleIndex, err)
+	}
+	return rule, nil
+}
+
 // loadERC20MinBalanceRule parses an erc20_min_balance rule
 func (l *PolicyLoader) loadERC20MinBalanceRule(rawRule json.RawMessage, policyIndex, ruleIndex int) (*ERC20MinBalanceRule, error) {
 	type erc20Config struct {
@@ -227,3 +710,379 @@ func (l *PolicyLoader) loadERC721OwnerRule(rawRule json.RawMessage, policyIndex,
 
 	return NewERC721OwnerRule(config.ContractAddress, tokenID, config.ChainID), nil
 }
+
+// loadERC721MinBalanceRule parses an erc721_min_balance rule
+func (l *PolicyLoader) loadERC721MinBalanceRule(rawRule json.RawMessage, policyIndex, ruleIndex int) (*ERC721MinBalanceRule, error) {
+	type erc721BalanceConfig struct {
+		Type            string `json:"type"`
+		ContractAddress string `json:"contract_address"`
+		MinimumBalance  string `json:"minimum_balance"` // Default: 1
+		ChainID         uint64 `json:"chain_id"`
+	}
+
+	var config erc721BalanceConfig
+	if err := json.Unmarshal(rawRule, &config); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: invalid erc721_min_balance rule: %w", policyIndex, ruleIndex, err)
+	}
+
+	if config.ContractAddress == "" {
+		return nil, fmt.Errorf("policy %d rule %d: contract_address is required for erc721_min_balance rule", policyIndex, ruleIndex)
+	}
+
+	if config.MinimumBalance == "" {
+		config.MinimumBalance = "1"
+	}
+	minimumBalance := new(big.Int)
+	if _, ok := minimumBalance.SetString(config.MinimumBalance, 10); !ok {
+		return nil, fmt.Errorf("policy %d rule %d: invalid minimum_balance format", policyIndex, ruleIndex)
+	}
+
+	if config.ChainID == 0 {
+		return nil, fmt.Errorf("policy %d rule %d: chain_id is required for erc721_min_balance rule", policyIndex, ruleIndex)
+	}
+
+	rule := NewERC721MinBalanceRule(config.ContractAddress, minimumBalance, config.ChainID)
+	if err := rule.Validate(); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: %w", policyIndex, ruleIndex, err)
+	}
+	return rule, nil
+}
+
+// loadERC721TraitRule parses an erc721_trait rule
+func (l *PolicyLoader) loadERC721TraitRule(rawRule json.RawMessage, policyIndex, ruleIndex int) (*ERC721TraitRule, error) {
+	type erc721TraitConfig struct {
+		Type            string `json:"type"`
+		ContractAddress string `json:"contract_address"`
+		TokenID         string `json:"token_id"` // Optional: any held token when empty
+		ChainID         uint64 `json:"chain_id"`
+		TraitType       string `json:"trait_type"`
+		TraitValue      string `json:"trait_value"`
+	}
+
+	var config erc721TraitConfig
+	if err := json.Unmarshal(rawRule, &config); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: invalid erc721_trait rule: %w", policyIndex, ruleIndex, err)
+	}
+
+	if config.ContractAddress == "" {
+		return nil, fmt.Errorf("policy %d rule %d: contract_address is required for erc721_trait rule", policyIndex, ruleIndex)
+	}
+
+	var tokenID *big.Int
+	if config.TokenID != "" {
+		tokenID = new(big.Int)
+		if _, ok := tokenID.SetString(config.TokenID, 10); !ok {
+			return nil, fmt.Errorf("policy %d rule %d: invalid token_id format", policyIndex, ruleIndex)
+		}
+	}
+
+	if config.ChainID == 0 {
+		return nil, fmt.Errorf("policy %d rule %d: chain_id is required for erc721_trait rule", policyIndex, ruleIndex)
+	}
+
+	if config.TraitType == "" || config.TraitValue == "" {
+		return nil, fmt.Errorf("policy %d rule %d: trait_type and trait_value are required for erc721_trait rule", policyIndex, ruleIndex)
+	}
+
+	return NewERC721TraitRule(config.ContractAddress, tokenID, config.ChainID, config.TraitType, config.TraitValue), nil
+}
+
+// loadStakedBalanceRule parses a staked_balance rule
+func (l *PolicyLoader) loadStakedBalanceRule(rawRule json.RawMessage, policyIndex, ruleIndex int) (*StakedBalanceRule, error) {
+	type stakedBalanceConfig struct {
+		Type            string   `json:"type"`
+		ContractAddress string   `json:"contract_address"`
+		Function        string   `json:"function"`     // Default: balanceOf(address)
+		Args            []string `json:"args"`         // Default: the caller's address
+		ResultIndex     int      `json:"result_index"` // Default: first return value
+		MinimumBalance  string   `json:"minimum_balance"`
+		ChainID         uint64   `json:"chain_id"`
+	}
+
+	var config stakedBalanceConfig
+	if err := json.Unmarshal(rawRule, &config); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: invalid staked_balance rule: %w", policyIndex, ruleIndex, err)
+	}
+
+	if config.ContractAddress == "" {
+		return nil, fmt.Errorf("policy %d rule %d: contract_address is required for staked_balance rule", policyIndex, ruleIndex)
+	}
+
+	if config.MinimumBalance == "" {
+		return nil, fmt.Errorf("policy %d rule %d: minimum_balance is required for staked_balance rule", policyIndex, ruleIndex)
+	}
+
+	minimumBalance := new(big.Int)
+	if _, ok := minimumBalance.SetString(config.MinimumBalance, 10); !ok {
+		return nil, fmt.Errorf("policy %d rule %d: invalid minimum_balance format", policyIndex, ruleIndex)
+	}
+
+	if config.ChainID == 0 {
+		return nil, fmt.Errorf("policy %d rule %d: chain_id is required for staked_balance rule", policyIndex, ruleIndex)
+	}
+
+	if config.Function == "" {
+		config.Function = "balanceOf(address)"
+		if config.Args == nil {
+			config.Args = []string{CallerArgument}
+		}
+	}
+
+	rule := NewStakedBalanceRule(config.ContractAddress, config.Function, config.Args, config.ResultIndex, minimumBalance, config.ChainID)
+	if err := rule.Validate(); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: %w", policyIndex, ruleIndex, err)
+	}
+	return rule, nil
+}
+
+// loadVotingPowerRule parses a voting_power rule
+func (l *PolicyLoader) loadVotingPowerRule(rawRule json.RawMessage, policyIndex, ruleIndex int) (*VotingPowerRule, error) {
+	type votingPowerConfig struct {
+		Type            string `json:"type"`
+		ContractAddress string `json:"contract_address"`
+		MinimumVotes    string `json:"minimum_votes"`
+		BlockNumber     uint64 `json:"block_number"` // Default: current votes
+		ChainID         uint64 `json:"chain_id"`
+	}
+
+	var config votingPowerConfig
+	if err := json.Unmarshal(rawRule, &config); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: invalid voting_power rule: %w", policyIndex, ruleIndex, err)
+	}
+
+	if config.ContractAddress == "" {
+		return nil, fmt.Errorf("policy %d rule %d: contract_address is required for voting_power rule", policyIndex, ruleIndex)
+	}
+
+	if config.MinimumVotes == "" {
+		return nil, fmt.Errorf("policy %d rule %d: minimum_votes is required for voting_power rule", policyIndex, ruleIndex)
+	}
+
+	minimumVotes := new(big.Int)
+	if _, ok := minimumVotes.SetString(config.MinimumVotes, 10); !ok {
+		return nil, fmt.Errorf("policy %d rule %d: invalid minimum_votes format", policyIndex, ruleIndex)
+	}
+
+	if config.ChainID == 0 {
+		return nil, fmt.Errorf("policy %d rule %d: chain_id is required for voting_power rule", policyIndex, ruleIndex)
+	}
+
+	rule := NewVotingPowerRule(config.ContractAddress, minimumVotes, config.BlockNumber, config.ChainID)
+	if err := rule.Validate(); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: %w", policyIndex, ruleIndex, err)
+	}
+	return rule, nil
+}
+
+// loadContractCallRule parses a contract_call rule
+func (l *PolicyLoader) loadContractCallRule(rawRule json.RawMessage, policyIndex, ruleIndex int) (*ContractCallRule, error) {
+	type contractCallConfig struct {
+		Type            string   `json:"type"`
+		ContractAddress string   `json:"contract_address"`
+		Function        string   `json:"function"`     // Signature or 4-byte selector
+		Args            []string `json:"args"`         // Default: none
+		ResultIndex     int      `json:"result_index"` // Default: first return value
+		Comparison      string   `json:"comparison"`
+		Value           string   `json:"value"` // Omitted with comparison "true"
+		ChainID         uint64   `json:"chain_id"`
+	}
+
+	var config contractCallConfig
+	if err := json.Unmarshal(rawRule, &config); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: invalid contract_call rule: %w", policyIndex, ruleIndex, err)
+	}
+
+	if config.ContractAddress == "" {
+		return nil, fmt.Errorf("policy %d rule %d: contract_address is required for contract_call rule", policyIndex, ruleIndex)
+	}
+
+	if config.Function == "" {
+		return nil, fmt.Errorf("policy %d rule %d: function is required for contract_call rule", policyIndex, ruleIndex)
+	}
+
+	if config.Comparison == "" {
+		return nil, fmt.Errorf("policy %d rule %d: comparison is required for contract_call rule", policyIndex, ruleIndex)
+	}
+
+	var value *big.Int
+	if config.Value != "" {
+		value = new(big.Int)
+		if _, ok := value.SetString(config.Value, 10); !ok {
+			return nil, fmt.Errorf("policy %d rule %d: invalid value format", policyIndex, ruleIndex)
+		}
+	}
+
+	if config.ChainID == 0 {
+		return nil, fmt.Errorf("policy %d rule %d: chain_id is required for contract_call rule", policyIndex, ruleIndex)
+	}
+
+	rule := NewContractCallRule(config.ContractAddress, config.Function, config.Args, config.ResultIndex, config.Comparison, value, config.ChainID)
+	if err := rule.Validate(); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: %w", policyIndex, ruleIndex, err)
+	}
+	return rule, nil
+}
+
+// loadLPPositionRule parses an lp_position rule
+func (l *PolicyLoader) loadLPPositionRule(rawRule json.RawMessage, policyIndex, ruleIndex int) (*LPPositionRule, error) {
+	type lpPositionConfig struct {
+		Type             string `json:"type"`
+		Version          string `json:"version"` // "v2" or "v3"
+		PoolAddress      string `json:"pool_address"`
+		PositionManager  string `json:"position_manager"` // v3 only, defaults to Uniswap's
+		MinimumLiquidity string `json:"minimum_liquidity"`
+		ChainID          uint64 `json:"chain_id"`
+	}
+
+	var config lpPositionConfig
+	if err := json.Unmarshal(rawRule, &config); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: invalid lp_position rule: %w", policyIndex, ruleIndex, err)
+	}
+
+	if config.PoolAddress == "" {
+		return nil, fmt.Errorf("policy %d rule %d: pool_address is required for lp_position rule", policyIndex, ruleIndex)
+	}
+
+	if config.MinimumLiquidity == "" {
+		return nil, fmt.Errorf("policy %d rule %d: minimum_liquidity is required for lp_position rule", policyIndex, ruleIndex)
+	}
+
+	minimumLiquidity := new(big.Int)
+	if _, ok := minimumLiquidity.SetString(config.MinimumLiquidity, 10); !ok {
+		return nil, fmt.Errorf("policy %d rule %d: invalid minimum_liquidity format", policyIndex, ruleIndex)
+	}
+
+	if config.ChainID == 0 {
+		return nil, fmt.Errorf("policy %d rule %d: chain_id is required for lp_position rule", policyIndex, ruleIndex)
+	}
+
+	rule := NewLPPositionRule(config.Version, config.PoolAddress, config.PositionManager, minimumLiquidity, config.ChainID)
+	if err := rule.Validate(); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: %w", policyIndex, ruleIndex, err)
+	}
+	return rule, nil
+}
+
+// loadHoldingDurationRule parses a holding_duration rule
+func (l *PolicyLoader) loadHoldingDurationRule(rawRule json.RawMessage, policyIndex, ruleIndex int) (*HoldingDurationRule, error) {
+	type holdingDurationConfig struct {
+		Type            string `json:"type"`
+		ContractAddress string `json:"contract_address"`
+		MinimumBalance  string `json:"minimum_balance"` // Default: 1
+		MinimumDays     int    `json:"minimum_days"`
+		ChainID         uint64 `json:"chain_id"`
+	}
+
+	var config holdingDurationConfig
+	if err := json.Unmarshal(rawRule, &config); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: invalid holding_duration rule: %w", policyIndex, ruleIndex, err)
+	}
+
+	if config.ContractAddress == "" {
+		return nil, fmt.Errorf("policy %d rule %d: contract_address is required for holding_duration rule", policyIndex, ruleIndex)
+	}
+
+	if config.MinimumDays == 0 {
+		return nil, fmt.Errorf("policy %d rule %d: minimum_days is required for holding_duration rule", policyIndex, ruleIndex)
+	}
+
+	if config.MinimumBalance == "" {
+		config.MinimumBalance = "1"
+	}
+	minimumBalance := new(big.Int)
+	if _, ok := minimumBalance.SetString(config.MinimumBalance, 10); !ok {
+		return nil, fmt.Errorf("policy %d rule %d: invalid minimum_balance format", policyIndex, ruleIndex)
+	}
+
+	if config.ChainID == 0 {
+		return nil, fmt.Errorf("policy %d rule %d: chain_id is required for holding_duration rule", policyIndex, ruleIndex)
+	}
+
+	rule := NewHoldingDurationRule(config.ContractAddress, minimumBalance, config.MinimumDays, config.ChainID)
+	if err := rule.Validate(); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: %w", policyIndex, ruleIndex, err)
+	}
+	return rule, nil
+}
+
+// loadCodeExistsRule parses a code_exists rule
+func (l *PolicyLoader) loadCodeExistsRule(rawRule json.RawMessage, policyIndex, ruleIndex int) (*CodeExistsRule, error) {
+	type codeExistsConfig struct {
+		Type    string `json:"type"`
+		ChainID uint64 `json:"chain_id"`
+	}
+
+	var config codeExistsConfig
+	if err := json.Unmarshal(rawRule, &config); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: invalid code_exists rule: %w", policyIndex, ruleIndex, err)
+	}
+
+	if config.ChainID == 0 {
+		return nil, fmt.Errorf("policy %d rule %d: chain_id is required for code_exists rule", policyIndex, ruleIndex)
+	}
+
+	return NewCodeExistsRule(config.ChainID), nil
+}
+
+// loadContractDeployerRule parses a contract_deployer rule
+func (l *PolicyLoader) loadContractDeployerRule(rawRule json.RawMessage, policyIndex, ruleIndex int) (*ContractDeployerRule, error) {
+	type factoryConfig struct {
+		Address       string `json:"address"`
+		Event         string `json:"event"`
+		DeployerTopic int    `json:"deployer_topic"` // Default: 1
+		ContractTopic int    `json:"contract_topic"`
+		FromBlock     uint64 `json:"from_block"`
+	}
+	type registryConfig struct {
+		Address  string `json:"address"`  // Default: the contract address
+		Function string `json:"function"` // Default: deployerOf(address)
+	}
+	type contractDeployerConfig struct {
+		Type            string          `json:"type"`
+		ContractAddress string          `json:"contract_address"`
+		Factory         *factoryConfig  `json:"factory"`
+		Registry        *registryConfig `json:"registry"`
+		ChainID         uint64          `json:"chain_id"`
+	}
+
+	var config contractDeployerConfig
+	if err := json.Unmarshal(rawRule, &config); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: invalid contract_deployer rule: %w", policyIndex, ruleIndex, err)
+	}
+
+	if config.Factory == nil && config.Registry == nil {
+		return nil, fmt.Errorf("policy %d rule %d: factory or registry is required for contract_deployer rule", policyIndex, ruleIndex)
+	}
+
+	if config.ChainID == 0 {
+		return nil, fmt.Errorf("policy %d rule %d: chain_id is required for contract_deployer rule", policyIndex, ruleIndex)
+	}
+
+	var factory *DeployerFactory
+	if config.Factory != nil {
+		if config.Factory.DeployerTopic == 0 {
+			config.Factory.DeployerTopic = 1
+		}
+		factory = &DeployerFactory{
+			Address:       config.Factory.Address,
+			Event:         config.Factory.Event,
+			DeployerTopic: config.Factory.DeployerTopic,
+			ContractTopic: config.Factory.ContractTopic,
+			FromBlock:     config.Factory.FromBlock,
+		}
+	}
+
+	var registry *DeployerRegistry
+	if config.Registry != nil {
+		if config.Registry.Function == "" {
+			config.Registry.Function = "deployerOf(address)"
+		}
+		registry = &DeployerRegistry{Address: config.Registry.Address, Function: config.Registry.Function}
+	}
+
+	rule := NewContractDeployerRule(config.ContractAddress, factory, registry, config.ChainID)
+	if err := rule.Validate(); err != nil {
+		return nil, fmt.Errorf("policy %d rule %d: %w", policyIndex, ruleIndex, err)
+	}
+	return rule, nil
+}
@@ -1,7 +1,9 @@
 package policy
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -33,6 +35,71 @@ func TestLoader_ValidPolicyConfiguration(t *testing.T) {
 	assert.Equal(t, "AND", policies[0].Logic)
 }
 
+// TestLoader_ShadowPolicy loads the shadow flag, defaulting to enforcing
+func TestLoader_ShadowPolicy(t *testing.T) {
+	configJSON := `[
+		{
+			"path": "/api/data",
+			"method": "GET",
+			"logic": "AND",
+			"shadow": true,
+			"rules": [{"type": "has_scope", "scope": "read:data"}]
+		},
+		{
+			"path": "/api/admin",
+			"method": "GET",
+			"logic": "AND",
+			"rules": [{"type": "has_scope", "scope": "admin"}]
+		}
+	]`
+
+	loader := NewPolicyLoader()
+	policies, err := loader.LoadFromJSON([]byte(configJSON))
+
+	require.NoError(t, err)
+	require.Len(t, policies, 2)
+	assert.True(t, policies[0].Shadow)
+	assert.False(t, policies[1].Shadow)
+}
+
+// TestLoader_EnforceFlag loads enforce false as a shadow policy
+func TestLoader_EnforceFlag(t *testing.T) {
+	configJSON := `[
+		{
+			"path": "/api/data",
+			"method": "GET",
+			"logic": "AND",
+			"enforce": false,
+			"rules": [{"type": "has_scope", "scope": "read:data"}]
+		},
+		{
+			"path": "/api/admin",
+			"method": "GET",
+			"logic": "AND",
+			"enforce": true,
+			"rules": [{"type": "has_scope", "scope": "admin"}]
+		}
+	]`
+
+	loader := NewPolicyLoader()
+	policies, err := loader.LoadFromJSON([]byte(configJSON))
+
+	require.NoError(t, err)
+	require.Len(t, policies, 2)
+	assert.True(t, policies[0].Shadow)
+	assert.False(t, policies[1].Shadow)
+
+	_, err = loader.ParsePolicy([]byte(`{
+		"path": "/api/data",
+		"method": "GET",
+		"logic": "AND",
+		"shadow": true,
+		"enforce": true,
+		"rules": [{"type": "has_scope", "scope": "read:data"}]
+	}`))
+	assert.ErrorContains(t, err, "cannot be enforced")
+}
+
 // TestLoader_InvalidJSON returns error on malformed JSON
 func TestLoader_InvalidJSON(t *testing.T) {
 	configJSON := `invalid json {`
@@ -148,6 +215,124 @@ func TestLoader_InvalidLogic(t *testing.T) {
 	assert.Contains(t, err.Error(), "AND")
 }
 
+// TestLoader_ScorePolicy loads the threshold and the points of each rule
+func TestLoader_ScorePolicy(t *testing.T) {
+	configJSON := `[{
+		"path": "/api/data",
+		"method": "GET",
+		"logic": "SCORE",
+		"threshold": 60,
+		"rules": [
+			{"type": "has_scope", "scope": "nft", "points": 50},
+			{"type": "in_allowlist", "addresses": ["0x742d35cc6634c0532925a3b844bc9e7595f0beb8"], "points": 100}
+		]
+	}]`
+
+	policies, err := NewPolicyLoader().LoadFromJSON([]byte(configJSON))
+	require.NoError(t, err)
+	require.Len(t, policies, 1)
+	assert.Equal(t, "SCORE", policies[0].Logic)
+	assert.Equal(t, 60, policies[0].Threshold)
+	assert.Equal(t, []int{50, 100}, policies[0].Points)
+}
+
+// TestLoader_ScorePolicyInvalid requires a threshold and points only with SCORE logic
+func TestLoader_ScorePolicyInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		errMsg string
+	}{
+		{
+			name:   "missing threshold",
+			policy: `{"path": "/api/data", "method": "GET", "logic": "SCORE", "rules": [{"type": "has_scope", "scope": "a", "points": 10}]}`,
+			errMsg: "threshold must be positive",
+		},
+		{
+			name:   "missing points",
+			policy: `{"path": "/api/data", "method": "GET", "logic": "SCORE", "threshold": 10, "rules": [{"type": "has_scope", "scope": "a"}]}`,
+			errMsg: "points must be positive",
+		},
+		{
+			name:   "negative points",
+			policy: `{"path": "/api/data", "method": "GET", "logic": "SCORE", "threshold": 10, "rules": [{"type": "has_scope", "scope": "a", "points": -5}]}`,
+			errMsg: "points must be positive",
+		},
+		{
+			name:   "threshold without SCORE",
+			policy: `{"path": "/api/data", "method": "GET", "logic": "AND", "threshold": 10, "rules": [{"type": "has_scope", "scope": "a"}]}`,
+			errMsg: "only allowed with SCORE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPolicyLoader().ParsePolicy([]byte(tt.policy))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+// TestLoader_TierPolicy loads the default tier and the tier of each rule
+func TestLoader_TierPolicy(t *testing.T) {
+	configJSON := `[{
+		"path": "/api/data",
+		"method": "GET",
+		"logic": "TIER",
+		"default_tier": "bronze",
+		"rules": [
+			{"type": "has_scope", "scope": "whale", "tier": "gold"},
+			{"type": "has_scope", "scope": "holder", "tier": "silver"}
+		]
+	}]`
+
+	policies, err := NewPolicyLoader().LoadFromJSON([]byte(configJSON))
+	require.NoError(t, err)
+	require.Len(t, policies, 1)
+	assert.Equal(t, "TIER", policies[0].Logic)
+	assert.Equal(t, []string{"gold", "silver"}, policies[0].Tiers)
+	assert.Equal(t, "bronze", policies[0].DefaultTier)
+}
+
+// TestLoader_TierPolicyInvalid requires a tier on every rule and tiers only with TIER logic
+func TestLoader_TierPolicyInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		errMsg string
+	}{
+		{
+			name:   "missing tier",
+			policy: `{"path": "/api/data", "method": "GET", "logic": "TIER", "rules": [{"type": "has_scope", "scope": "a"}]}`,
+			errMsg: "tier is required",
+		},
+		{
+			name:   "default tier without TIER",
+			policy: `{"path": "/api/data", "method": "GET", "logic": "OR", "default_tier": "free", "rules": [{"type": "has_scope", "scope": "a"}]}`,
+			errMsg: "only allowed with TIER",
+		},
+		{
+			name:   "decision cache",
+			policy: `{"path": "/api/data", "method": "GET", "logic": "TIER", "cache_ttl": "5m", "rules": [{"type": "in_allowlist", "addresses": ["0x742d35cc6634c0532925a3b844bc9e7595f0beb8"], "tier": "gold"}]}`,
+			errMsg: "cache_ttl is not allowed",
+		},
+		{
+			name:   "tier too long",
+			policy: `{"path": "/api/data", "method": "GET", "logic": "TIER", "rules": [{"type": "has_scope", "scope": "a", "tier": "` + strings.Repeat("g", 65) + `"}]}`,
+			errMsg: "at most 64 characters",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPolicyLoader().ParsePolicy([]byte(tt.policy))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
 // TestLoader_UnknownRuleType validates rule type
 func TestLoader_UnknownRuleType(t *testing.T) {
 	configJSON := `[
@@ -319,3 +504,255 @@ func TestLoader_InAllowlistRuleCreation(t *testing.T) {
 	_, ok := rule.(*InAllowlistRule)
 	assert.True(t, ok, "Rule should be InAllowlistRule")
 }
+
+// TestLoader_ERC721TraitRuleCreation verifies erc721_trait rule is created
+func TestLoader_ERC721TraitRuleCreation(t *testing.T) {
+	configJSON := `[
+		{
+			"path": "/api/gold",
+			"method": "GET",
+			"logic": "AND",
+			"rules": [{
+				"type": "erc721_trait",
+				"contract_address": "0x2234567890123456789012345678901234567890",
+				"chain_id": 1,
+				"trait_type": "Tier",
+				"trait_value": "Gold"
+			}]
+		}
+	]`
+
+	loader := NewPolicyLoader()
+	policies, err := loader.LoadFromJSON([]byte(configJSON))
+
+	require.NoError(t, err)
+	rule, ok := policies[0].Rules[0].(*ERC721TraitRule)
+	require.True(t, ok, "Rule should be ERC721TraitRule")
+	assert.Nil(t, rule.TokenID)
+	assert.Equal(t, "Tier", rule.TraitType)
+	assert.Equal(t, "Gold", rule.TraitValue)
+
+	// trait_value is required
+	_, err = loader.LoadFromJSON([]byte(`[{"path": "/api/gold", "method": "GET", "logic": "AND", "rules": [{
+		"type": "erc721_trait", "contract_address": "0x2234567890123456789012345678901234567890", "chain_id": 1, "trait_type": "Tier"
+	}]}]`))
+	assert.Error(t, err)
+}
+
+// TestLoader_StakedBalanceRuleCreation verifies staked_balance rule is created with defaults
+func TestLoader_StakedBalanceRuleCreation(t *testing.T) {
+	configJSON := `[
+		{
+			"path": "/api/stakers",
+			"method": "GET",
+			"logic": "OR",
+			"rules": [
+				{
+					"type": "staked_balance",
+					"contract_address": "0x5234567890123456789012345678901234567890",
+					"minimum_balance": "1000",
+					"chain_id": 1
+				},
+				{
+					"type": "staked_balance",
+					"contract_address": "0x5234567890123456789012345678901234567890",
+					"function": "userInfo(uint256,address)",
+					"args": ["2", "$address"],
+					"minimum_balance": "1000",
+					"chain_id": 1
+				}
+			]
+		}
+	]`
+
+	loader := NewPolicyLoader()
+	policies, err := loader.LoadFromJSON([]byte(configJSON))
+
+	require.NoError(t, err)
+	rule, ok := policies[0].Rules[0].(*StakedBalanceRule)
+	require.True(t, ok, "Rule should be StakedBalanceRule")
+	assert.Equal(t, "balanceOf(address)", rule.Function)
+	assert.Equal(t, []string{CallerArgument}, rule.Args)
+
+	rule = policies[0].Rules[1].(*StakedBalanceRule)
+	assert.Equal(t, []string{"2", CallerArgument}, rule.Args)
+
+	// Arguments must match the function signature
+	_, err = loader.LoadFromJSON([]byte(`[{"path": "/api/stakers", "method": "GET", "logic": "AND", "rules": [{
+		"type": "staked_balance", "contract_address": "0x5234567890123456789012345678901234567890",
+		"function": "userInfo(uint256,address)", "args": ["$address"], "minimum_balance": "1", "chain_id": 1
+	}]}]`))
+	assert.Error(t, err)
+}
+
+// TestLoader_LPPositionRuleCreation verifies lp_position rule is created
+func TestLoader_LPPositionRuleCreation(t *testing.T) {
+	configJSON := `[
+		{
+			"path": "/api/lp",
+			"method": "GET",
+			"logic": "AND",
+			"rules": [{
+				"type": "lp_position",
+				"version": "v3",
+				"pool_address": "0x6234567890123456789012345678901234567890",
+				"minimum_liquidity": "1000000",
+				"chain_id": 1
+			}]
+		}
+	]`
+
+	loader := NewPolicyLoader()
+	policies, err := loader.LoadFromJSON([]byte(configJSON))
+
+	require.NoError(t, err)
+	rule, ok := policies[0].Rules[0].(*LPPositionRule)
+	require.True(t, ok, "Rule should be LPPositionRule")
+	assert.Equal(t, LPVersionV3, rule.Version)
+	assert.Equal(t, DefaultV3PositionManager, rule.PositionManager)
+
+	// version is required
+	_, err = loader.LoadFromJSON([]byte(`[{"path": "/api/lp", "method": "GET", "logic": "AND", "rules": [{
+		"type": "lp_position", "pool_address": "0x6234567890123456789012345678901234567890", "minimum_liquidity": "1", "chain_id": 1
+	}]}]`))
+	assert.Error(t, err)
+}
+
+// TestLoader_RequestRuleCreation verifies request rules are created and validated
+func TestLoader_RequestRuleCreation(t *testing.T) {
+	configJSON := `[
+		{
+			"path": "/api/data",
+			"method": "GET",
+			"logic": "AND",
+			"rules": [
+				{"type": "header_equals", "header": "X-Client", "value": "mobile"},
+				{"type": "query_param_present", "param": "preview"},
+				{"type": "http_method", "methods": ["GET", "HEAD"]},
+				{"type": "header_matches", "header": "User-Agent", "pattern": "Wallet/[0-9]+"},
+				{"type": "query_param_matches", "param": "chain", "pattern": "1|137"}
+			]
+		}
+	]`
+
+	loader := NewPolicyLoader()
+	policies, err := loader.LoadFromJSON([]byte(configJSON))
+
+	require.NoError(t, err)
+	assert.IsType(t, &HeaderEqualsRule{}, policies[0].Rules[0])
+	assert.IsType(t, &QueryParamPresentRule{}, policies[0].Rules[1])
+	assert.IsType(t, &HTTPMethodRule{}, policies[0].Rules[2])
+	assert.IsType(t, &HeaderMatchesRule{}, policies[0].Rules[3])
+	assert.IsType(t, &QueryParamMatchesRule{}, policies[0].Rules[4])
+
+	for _, rule := range []string{
+		`{"type": "header_equals", "value": "mobile"}`,
+		`{"type": "query_param_present"}`,
+		`{"type": "http_method", "methods": []}`,
+		`{"type": "header_matches", "pattern": "Wallet/.*"}`,
+		`{"type": "header_matches", "header": "User-Agent", "pattern": "Wallet/("}`,
+		`{"type": "query_param_matches", "pattern": "137"}`,
+	} {
+		_, err := loader.LoadFromJSON([]byte(`[{"path": "/api/data", "method": "GET", "logic": "AND", "rules": [` + rule + `]}]`))
+		assert.Error(t, err, rule)
+	}
+}
+
+func TestLoader_GeoRestrictionRuleCreation(t *testing.T) {
+	configJSON := `[
+		{
+			"path": "/api/trade",
+			"method": "POST",
+			"logic": "AND",
+			"rules": [
+				{"type": "geo_restriction", "mode": "deny", "countries": ["us", "KP"], "asns": [64500]}
+			]
+		}
+	]`
+
+	loader := NewPolicyLoader()
+	policies, err := loader.LoadFromJSON([]byte(configJSON))
+
+	require.NoError(t, err)
+	rule, ok := policies[0].Rules[0].(*GeoRestrictionRule)
+	require.True(t, ok)
+	assert.Equal(t, GeoModeDeny, rule.Mode)
+	assert.Equal(t, []string{"US", "KP"}, rule.Countries)
+	assert.Equal(t, []uint32{64500}, rule.ASNs)
+
+	for _, rule := range []string{
+		`{"type": "geo_restriction", "countries": ["US"]}`,
+		`{"type": "geo_restriction", "mode": "block", "countries": ["US"]}`,
+		`{"type": "geo_restriction", "mode": "allow"}`,
+		`{"type": "geo_restriction", "mode": "allow", "countries": ["USA"]}`,
+	} {
+		_, err := loader.LoadFromJSON([]byte(`[{"path": "/api/trade", "method": "POST", "logic": "AND", "rules": [` + rule + `]}]`))
+		assert.Error(t, err, rule)
+	}
+}
+
+func TestLoader_ClaimMatchRuleCreation(t *testing.T) {
+	configJSON := `[
+		{
+			"path": "/api/reports",
+			"method": "GET",
+			"logic": "AND",
+			"rules": [
+				{"type": "claim_match", "claim": "role", "value": "admin"},
+				{"type": "claim_match", "claim": "tier", "operator": "in", "value": ["gold", "platinum"]},
+				{"type": "claim_match", "claim": "level", "operator": "gte", "value": 3}
+			]
+		}
+	]`
+
+	loader := NewPolicyLoader()
+	policies, err := loader.LoadFromJSON([]byte(configJSON))
+
+	require.NoError(t, err)
+	require.Len(t, policies[0].Rules, 3)
+	assert.Equal(t, ClaimOpEquals, policies[0].Rules[0].(*ClaimMatchRule).Operator)
+	assert.Equal(t, []interface{}{"gold", "platinum"}, policies[0].Rules[1].(*ClaimMatchRule).Value)
+	assert.Equal(t, float64(3), policies[0].Rules[2].(*ClaimMatchRule).Value)
+
+	for _, rule := range []string{
+		`{"type": "claim_match", "value": "admin"}`,
+		`{"type": "claim_match", "claim": "role", "operator": "regex", "value": "adm.*"}`,
+		`{"type": "claim_match", "claim": "level", "operator": "gt", "value": "3"}`,
+	} {
+		_, err := loader.LoadFromJSON([]byte(`[{"path": "/api/reports", "method": "GET", "logic": "AND", "rules": [` + rule + `]}]`))
+		assert.Error(t, err, rule)
+	}
+}
+
+func TestLoader_QuotaRuleCreation(t *testing.T) {
+	configJSON := `[
+		{
+			"path": "/api/claim",
+			"method": "POST",
+			"logic": "AND",
+			"rules": [
+				{"type": "quota", "name": "free-claims", "limit": 3, "window": "24h"}
+			]
+		}
+	]`
+
+	loader := NewPolicyLoader()
+	policies, err := loader.LoadFromJSON([]byte(configJSON))
+
+	require.NoError(t, err)
+	rule, ok := policies[0].Rules[0].(*QuotaRule)
+	require.True(t, ok)
+	assert.Equal(t, "free-claims", rule.Name)
+	assert.Equal(t, int64(3), rule.Limit)
+	assert.Equal(t, 24*time.Hour, rule.Window)
+
+	for _, rule := range []string{
+		`{"type": "quota", "limit": 3, "window": "24h"}`,
+		`{"type": "quota", "name": "free-claims", "window": "24h"}`,
+		`{"type": "quota", "name": "free-claims", "limit": 3}`,
+		`{"type": "quota", "name": "free-claims", "limit": 3, "window": "1 day"}`,
+	} {
+		_, err := loader.LoadFromJSON([]byte(`[{"path": "/api/claim", "method": "POST", "logic": "AND", "rules": [` + rule + `]}]`))
+		assert.Error(t, err, rule)
+	}
+}
@@ -0,0 +1,312 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/chain"
+	"go.uber.org/zap"
+)
+
+// Uniswap-style pool versions supported by LPPositionRule
+const (
+	LPVersionV2 = "v2" // Fungible LP tokens issued by the pair contract
+	LPVersionV3 = "v3" // Position NFTs issued by a NonfungiblePositionManager
+)
+
+// DefaultV3PositionManager is Uniswap's NonfungiblePositionManager, deployed at the
+// same address on mainnet and most L2s
+const DefaultV3PositionManager = "0xC36442b4a4522E871399CD717aBDD847Ab11FE88"
+
+// Selectors for pool and position manager calls
+const (
+	PoolToken0Selector               = "0x0dfe1681" // token0()
+	PoolToken1Selector               = "0xd21220a7" // token1()
+	PoolFeeSelector                  = "0xddca3f43" // fee()
+	PositionManagerPositionsSelector = "0x99fbab88" // positions(uint256)
+)
+
+// Words of the positions() return data read by LPPositionRule
+const (
+	positionsToken0Word    = 2
+	positionsToken1Word    = 3
+	positionsFeeWord       = 4
+	positionsLiquidityWord = 7
+)
+
+// maxLPPositions bounds how many v3 position NFTs are inspected per holder
+const maxLPPositions = 50
+
+// LPPositionRule checks if user provides at least a minimum amount of liquidity to a
+// Uniswap-style pool. For v2 pools this is the user's LP token balance; for v3 pools
+// it is the summed liquidity of the user's position NFTs in that pool.
+type LPPositionRule struct {
+	Version          string
+	PoolAddress      string
+	PositionManager  string // v3 only
+	MinimumLiquidity *big.Int
+	ChainID          uint64
+	cacheTTL         // Optional TTL of the rule's cached reads
+	// cache and provider will be set by manager
+	cache    CacheProvider
+	provider BlockchainProvider
+	logger   *zap.Logger
+}
+
+// v3Pool identifies a v3 pool the way position NFTs record it
+type v3Pool struct {
+	token0 string
+	token1 string
+	fee    *big.Int
+}
+
+// NewLPPositionRule creates a new liquidity position rule. positionManager is only
+// used for v3 pools and defaults to DefaultV3PositionManager when empty.
+func NewLPPositionRule(version, poolAddress, positionManager string, minimumLiquidity *big.Int, chainID uint64) *LPPositionRule {
+	if version == LPVersionV3 && positionManager == "" {
+		positionManager = DefaultV3PositionManager
+	}
+	logger, _ := zap.NewProduction()
+	return &LPPositionRule{
+		Version:          version,
+		PoolAddress:      poolAddress,
+		PositionManager:  positionManager,
+		MinimumLiquidity: minimumLiquidity,
+		ChainID:          chainID,
+		logger:           logger,
+	}
+}
+
+// Type returns the rule type
+func (r *LPPositionRule) Type() RuleType {
+	return LPPositionRuleType
+}
+
+// Validate checks if the rule parameters are valid
+func (r *LPPositionRule) Validate() error {
+	if r.Version != LPVersionV2 && r.Version != LPVersionV3 {
+		return fmt.Errorf("version must be %s or %s, got %q", LPVersionV2, LPVersionV3, r.Version)
+	}
+	if !isValidAddress(r.PoolAddress) {
+		return fmt.Errorf("invalid pool address: %s", r.PoolAddress)
+	}
+	if r.Version == LPVersionV3 && !isValidAddress(r.PositionManager) {
+		return fmt.Errorf("invalid position manager address: %s", r.PositionManager)
+	}
+	if r.MinimumLiquidity == nil || r.MinimumLiquidity.Sign() <= 0 {
+		return fmt.Errorf("minimum liquidity must be positive")
+	}
+	if r.ChainID == 0 {
+		return fmt.Errorf("chain ID cannot be zero")
+	}
+	return nil
+}
+
+// Evaluate checks the user's liquidity in the pool (requires provider and cache to be set)
+// This implementation follows fail-closed security: on any error, return false
+func (r *LPPositionRule) Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	if !isValidAddress(address) {
+		r.logger.Error("invalid address format",
+			zap.String("address", address),
+			zap.String("rule", "LPPosition"))
+		return false, nil // Fail-closed
+	}
+
+	if r.provider == nil {
+		r.logger.Warn("no blockchain provider configured",
+			zap.String("rule", "LPPosition"))
+		return false, nil
+	}
+
+	// Generate cache key: "lp_liquidity:{chainID}:{pool}:{address}"
+	chainIDStr := strconv.FormatUint(r.ChainID, 10)
+	cacheKey := chain.CacheKey("lp_liquidity", chainIDStr, strings.ToLower(r.PoolAddress), strings.ToLower(address))
+
+	var liquidity *big.Int
+	if r.cache != nil && !refreshingCache(ctx) {
+		if cached, ok := r.cache.Get(cacheKey); ok {
+			liquidity, _ = cached.(*big.Int)
+		}
+	}
+
+	if liquidity == nil {
+		var err error
+		if r.Version == LPVersionV2 {
+			liquidity, err = r.v2Liquidity(ctx, address)
+		} else {
+			liquidity, err = r.v3Liquidity(ctx, address)
+		}
+		if err != nil {
+			logRPCFailure(r.logger, err, "failed to read liquidity position",
+				zap.String("pool", r.PoolAddress),
+				zap.String("version", r.Version),
+				zap.String("address", address),
+				zap.Uint64("chainID", r.ChainID))
+			return false, nil
+		}
+
+		if r.cache != nil {
+			r.cache.Set(cacheKey, liquidity)
+		}
+	}
+
+	hasLiquidity := liquidity.Cmp(r.MinimumLiquidity) >= 0
+
+	r.logger.Info("LP position check completed",
+		zap.String("address", address),
+		zap.String("pool", r.PoolAddress),
+		zap.String("version", r.Version),
+		zap.String("liquidity", liquidity.String()),
+		zap.String("minimum", r.MinimumLiquidity.String()),
+		zap.Bool("hasLiquidity", hasLiquidity))
+
+	return hasLiquidity, nil
+}
+
+// v2Liquidity returns the user's LP token balance of the pair
+func (r *LPPositionRule) v2Liquidity(ctx context.Context, address string) (*big.Int, error) {
+	result, err := ethCall(ctx, r.provider, r.PoolAddress, encodeERC20BalanceOfCall(r.PoolAddress, address))
+	if err != nil {
+		return nil, err
+	}
+	return decodeUint256(result)
+}
+
+// v3Liquidity sums the liquidity of the user's position NFTs in the pool
+func (r *LPPositionRule) v3Liquidity(ctx context.Context, address string) (*big.Int, error) {
+	pool, err := r.v3Pool(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pool: %w", err)
+	}
+
+	result, err := ethCall(ctx, r.provider, r.PositionManager, encodeERC721BalanceOfCall(address))
+	if err != nil {
+		return nil, err
+	}
+	balance, err := decodeUint256(result)
+	if err != nil {
+		return nil, err
+	}
+
+	count := int64(maxLPPositions)
+	if balance.IsInt64() && balance.Int64() < count {
+		count = balance.Int64()
+	}
+
+	total := new(big.Int)
+	for i := int64(0); i < count; i++ {
+		result, err := ethCall(ctx, r.provider, r.PositionManager, encodeERC721TokenOfOwnerByIndexCall(address, i))
+		if err != nil {
+			return nil, err
+		}
+		tokenID, err := decodeUint256(result)
+		if err != nil {
+			return nil, err
+		}
+
+		position, err := ethCall(ctx, r.provider, r.PositionManager, PositionManagerPositionsSelector+strings.TrimPrefix(encodeUint256(tokenID), "0x"))
+		if err != nil {
+			return nil, err
+		}
+		matches, liquidity, err := pool.positionLiquidity(position)
+		if err != nil {
+			return nil, fmt.Errorf("position %s: %w", tokenID, err)
+		}
+		if matches {
+			total.Add(total, liquidity)
+		}
+	}
+	return total, nil
+}
+
+// v3Pool reads the tokens and fee identifying the pool. They are immutable, so
+// they are cached under their own key.
+func (r *LPPositionRule) v3Pool(ctx context.Context) (*v3Pool, error) {
+	cacheKey := chain.CacheKey("v3_pool", strconv.FormatUint(r.ChainID, 10), strings.ToLower(r.PoolAddress), "")
+	if r.cache != nil {
+		if cached, ok := r.cache.Get(cacheKey); ok {
+			if pool, ok := cached.(*v3Pool); ok {
+				return pool, nil
+			}
+		}
+	}
+
+	words := make([]string, 3)
+	for i, selector := range []string{PoolToken0Selector, PoolToken1Selector, PoolFeeSelector} {
+		result, err := ethCall(ctx, r.provider, r.PoolAddress, selector)
+		if err != nil {
+			return nil, err
+		}
+		words[i] = result
+	}
+
+	token0, err := decodeAddress(words[0])
+	if err != nil {
+		return nil, err
+	}
+	token1, err := decodeAddress(words[1])
+	if err != nil {
+		return nil, err
+	}
+	fee, err := decodeUint256(words[2])
+	if err != nil {
+		return nil, err
+	}
+
+	pool := &v3Pool{token0: token0, token1: token1, fee: fee}
+	if r.cache != nil {
+		r.cache.Set(cacheKey, pool)
+	}
+	return pool, nil
+}
+
+// positionLiquidity decodes positions() return data, reporting whether the position
+// belongs to the pool and its liquidity
+func (p *v3Pool) positionLiquidity(position string) (bool, *big.Int, error) {
+	token0Word, err := abiWord(position, positionsToken0Word)
+	if err != nil {
+		return false, nil, err
+	}
+	token1Word, err := abiWord(position, positionsToken1Word)
+	if err != nil {
+		return false, nil, err
+	}
+	token0, err := decodeAddress(token0Word)
+	if err != nil {
+		return false, nil, err
+	}
+	token1, err := decodeAddress(token1Word)
+	if err != nil {
+		return false, nil, err
+	}
+	fee, err := decodeWord(position, positionsFeeWord)
+	if err != nil {
+		return false, nil, err
+	}
+	liquidity, err := decodeWord(position, positionsLiquidityWord)
+	if err != nil {
+		return false, nil, err
+	}
+
+	matches := strings.EqualFold(token0, p.token0) && strings.EqualFold(token1, p.token1) && fee.Cmp(p.fee) == 0
+	return matches, liquidity, nil
+}
+
+// SetProvider sets the blockchain provider for RPC calls
+func (r *LPPositionRule) SetProvider(provider BlockchainProvider) {
+	r.provider = provider
+}
+
+// SetCache sets the cache for storing results
+func (r *LPPositionRule) SetCache(cache CacheProvider) {
+	r.cache = withCacheTTL(cache, r.CacheTTL())
+}
+
+// SetLogger sets the logger for the rule
+func (r *LPPositionRule) SetLogger(logger *zap.Logger) {
+	r.logger = logger
+}
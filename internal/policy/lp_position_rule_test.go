@@ -0,0 +1,158 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPoolAddr    = "0x6234567890123456789012345678901234567890" // Mock pool
+	testManagerAddr = "0x7234567890123456789012345678901234567890" // Mock position manager
+	testToken0Addr  = "0x8234567890123456789012345678901234567890"
+	testToken1Addr  = "0x9234567890123456789012345678901234567890"
+)
+
+// testPosition is a v3 position NFT held by testUserAddr
+type testPosition struct {
+	token0, token1 string
+	fee            int64
+	liquidity      int64
+}
+
+// lpProvider mocks a v2 pair, a v3 pool and a v3 position manager
+type lpProvider struct {
+	lpBalance int64
+	positions []testPosition
+	calls     int
+}
+
+func (p *lpProvider) Call(ctx context.Context, method string, params []interface{}) ([]byte, error) {
+	p.calls++
+	callObj := params[0].(map[string]interface{})
+	to, data := callObj["to"].(string), callObj["data"].(string)
+	word := func(value interface{}) string {
+		if address, ok := value.(string); ok {
+			return strings.TrimPrefix(encodeAddress(address), "0x")
+		}
+		return fmt.Sprintf("%064x", value)
+	}
+
+	switch {
+	case to == testPoolAddr && data == PoolToken0Selector:
+		return rpcResult(word(testToken0Addr)), nil
+	case to == testPoolAddr && data == PoolToken1Selector:
+		return rpcResult(word(testToken1Addr)), nil
+	case to == testPoolAddr && data == PoolFeeSelector:
+		return rpcResult(word(3000)), nil
+	case to == testPoolAddr && strings.HasPrefix(data, ERC20BalanceOfSelector):
+		return rpcResult(word(p.lpBalance)), nil
+	case to == testManagerAddr && strings.HasPrefix(data, ERC721BalanceOfSelector):
+		return rpcResult(word(len(p.positions))), nil
+	case to == testManagerAddr && strings.HasPrefix(data, ERC721TokenOfOwnerByIndexSelector):
+		index, _ := decodeUint256(data[74:138])
+		return rpcResult(word(index.Int64() + 100)), nil
+	case to == testManagerAddr && strings.HasPrefix(data, PositionManagerPositionsSelector):
+		tokenID, _ := decodeUint256(data[10:74])
+		position := p.positions[tokenID.Int64()-100]
+		// nonce, operator, token0, token1, fee, tickLower, tickUpper, liquidity, ...
+		return rpcResult(word(0) + word(0) + word(position.token0) + word(position.token1) +
+			word(position.fee) + word(0) + word(0) + word(position.liquidity) + strings.Repeat(word(0), 4)), nil
+	}
+	return nil, fmt.Errorf("unexpected call to %s: %s", to, data)
+}
+
+func (p *lpProvider) HealthCheck(ctx context.Context) bool {
+	return true
+}
+
+// TestLPPositionRule_Validate validates rule parameters
+func TestLPPositionRule_Validate(t *testing.T) {
+	minimum := big.NewInt(1)
+
+	assert.NoError(t, NewLPPositionRule(LPVersionV2, testPoolAddr, "", minimum, 1).Validate())
+	assert.NoError(t, NewLPPositionRule(LPVersionV3, testPoolAddr, "", minimum, 1).Validate())
+	assert.Error(t, NewLPPositionRule("v4", testPoolAddr, "", minimum, 1).Validate())
+	assert.Error(t, NewLPPositionRule(LPVersionV2, "0x1234", "", minimum, 1).Validate())
+	assert.Error(t, NewLPPositionRule(LPVersionV3, testPoolAddr, "0x1234", minimum, 1).Validate())
+	assert.Error(t, NewLPPositionRule(LPVersionV2, testPoolAddr, "", big.NewInt(0), 1).Validate())
+	assert.Error(t, NewLPPositionRule(LPVersionV2, testPoolAddr, "", minimum, 0).Validate())
+
+	assert.Equal(t, DefaultV3PositionManager, NewLPPositionRule(LPVersionV3, testPoolAddr, "", minimum, 1).PositionManager)
+}
+
+// TestLPPositionRule_V2 compares the LP token balance with the minimum
+func TestLPPositionRule_V2(t *testing.T) {
+	provider := &lpProvider{lpBalance: 1000}
+
+	rule := NewLPPositionRule(LPVersionV2, testPoolAddr, "", big.NewInt(1000), 1)
+	rule.SetProvider(provider)
+	allowed, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	rule = NewLPPositionRule(LPVersionV2, testPoolAddr, "", big.NewInt(1001), 1)
+	rule.SetProvider(provider)
+	allowed, err = rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+// TestLPPositionRule_V3 sums liquidity of positions in the pool only
+func TestLPPositionRule_V3(t *testing.T) {
+	provider := &lpProvider{positions: []testPosition{
+		{token0: testToken0Addr, token1: testToken1Addr, fee: 3000, liquidity: 400},
+		{token0: testToken0Addr, token1: testToken1Addr, fee: 500, liquidity: 10000}, // Other fee tier
+		{token0: testToken0Addr, token1: testToken1Addr, fee: 3000, liquidity: 600},
+	}}
+
+	rule := NewLPPositionRule(LPVersionV3, testPoolAddr, testManagerAddr, big.NewInt(1000), 1)
+	rule.SetProvider(provider)
+	allowed, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	rule = NewLPPositionRule(LPVersionV3, testPoolAddr, testManagerAddr, big.NewInt(1001), 1)
+	rule.SetProvider(provider)
+	allowed, err = rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+// TestLPPositionRule_Cache caches the pool and the user's liquidity
+func TestLPPositionRule_Cache(t *testing.T) {
+	provider := &lpProvider{positions: []testPosition{
+		{token0: testToken0Addr, token1: testToken1Addr, fee: 3000, liquidity: 1000},
+	}}
+	rule := NewLPPositionRule(LPVersionV3, testPoolAddr, testManagerAddr, big.NewInt(1000), 1)
+	rule.SetProvider(provider)
+	rule.SetCache(&MockCache{})
+
+	for i := 0; i < 3; i++ {
+		allowed, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+	// token0, token1, fee, balanceOf, tokenOfOwnerByIndex, positions
+	assert.Equal(t, 6, provider.calls)
+}
+
+// TestLPPositionRule_FailClosed denies on missing provider or failed calls
+func TestLPPositionRule_FailClosed(t *testing.T) {
+	rule := NewLPPositionRule(LPVersionV2, testPoolAddr, "", big.NewInt(1), 1)
+	allowed, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	// The default position manager is not mocked, so every position call fails
+	rule = NewLPPositionRule(LPVersionV3, testPoolAddr, "", big.NewInt(1), 1)
+	rule.SetProvider(&lpProvider{})
+	allowed, err = rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
@@ -1,6 +1,7 @@
 package policy
 
 import (
+	"context"
 	"sync"
 
 	"go.uber.org/zap"
@@ -8,12 +9,21 @@ import (
 
 // PolicyManager manages a collection of policies and provides route matching
 type PolicyManager struct {
-	policies []*Policy
-	mu       sync.RWMutex
-	loader   *PolicyLoader
-	provider BlockchainProvider // For blockchain rules
-	cache    CacheProvider      // For caching blockchain results
-	logger   *zap.Logger
+	policies  []*Policy
+	mu        sync.RWMutex
+	loader    *PolicyLoader
+	provider  BlockchainProvider // For blockchain rules
+	cache     CacheProvider      // For caching blockchain results
+	resolver  MetadataResolver   // For NFT trait rules
+	geoip     GeoIPResolver      // For geo restriction rules
+	quotas    QuotaStore         // For quota rules
+	blocklist Blocklist          // For blocklist rules
+	plugins   *PluginRegistry    // For plugin rules
+	recorder  ChangeRecorder     // For change history
+	store     PolicyStore        // For persisting policies
+	writeMu   sync.Mutex         // Serializes persisted mutations, held without blocking evaluation
+	nextID    int64              // Last policy ID assigned
+	logger    *zap.Logger
 }
 
 // NewPolicyManager creates a new policy manager
@@ -29,25 +39,155 @@ func NewPolicyManager(provider BlockchainProvider, cache CacheProvider) *PolicyM
 	}
 }
 
-// AddPolicy adds a policy to the manager
+// AddPolicy adds a policy to the manager and assigns its ID
 // Automatically wires up blockchain rules with provider and cache
 func (pm *PolicyManager) AddPolicy(policy *Policy) {
+	if err := pm.AddPolicyContext(context.Background(), policy); err != nil && pm.logger != nil {
+		pm.logger.Error("failed to add policy", zap.Error(err))
+	}
+}
+
+// AddPolicyContext is AddPolicy writing the policy to the policy store, if one is
+// set, and recording the change with ctx, which carries the actor making it. With a
+// store the policy gets the ID the store assigns, and is not added if storing fails.
+func (pm *PolicyManager) AddPolicyContext(ctx context.Context, policy *Policy) error {
+	pm.writeMu.Lock()
+	defer pm.writeMu.Unlock()
+
+	id, err := pm.persistCreate(ctx, policy)
+	if err != nil {
+		return err
+	}
+
 	pm.mu.Lock()
-	defer pm.mu.Unlock()
+	before := pm.snapshotForRecorder()
 
 	// Wire up blockchain rules with provider and cache
 	pm.wireBlockchainRules(policy)
+	if id != 0 {
+		policy.ID = id
+		if id > pm.nextID {
+			pm.nextID = id
+		}
+	} else {
+		pm.assignID(policy)
+	}
 
 	pm.policies = append(pm.policies, policy)
+	recorder, changes := pm.pendingChanges(before)
+	pm.mu.Unlock()
+
+	pm.recordChanges(ctx, recorder, changes)
+	return nil
+}
+
+// GetPolicy returns the policy with the given ID, or nil
+func (pm *PolicyManager) GetPolicy(id int64) *Policy {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	for _, policy := range pm.policies {
+		if policy.ID == id {
+			return policy
+		}
+	}
+	return nil
+}
+
+// ReplacePolicy replaces the policy with the given ID, keeping its ID and its place
+// in evaluation order, and writes it to the policy store if one is set. Returns
+// ErrPolicyNotFound if no policy has the ID. The change is recorded with ctx, which
+// carries the actor making it.
+func (pm *PolicyManager) ReplacePolicy(ctx context.Context, id int64, policy *Policy) error {
+	pm.writeMu.Lock()
+	defer pm.writeMu.Unlock()
+
+	if pm.GetPolicy(id) == nil {
+		return ErrPolicyNotFound
+	}
+	policy.ID = id
+	if err := pm.persistUpdate(ctx, id, policy); err != nil {
+		return err
+	}
+
+	pm.mu.Lock()
+	index := pm.indexOf(id)
+	if index < 0 {
+		pm.mu.Unlock()
+		return ErrPolicyNotFound
+	}
+	before := pm.snapshotForRecorder()
+
+	pm.wireBlockchainRules(policy)
+
+	pm.policies[index] = policy
+	recorder, changes := pm.pendingChanges(before)
+	pm.mu.Unlock()
+
+	pm.recordChanges(ctx, recorder, changes)
+	return nil
+}
+
+// RemovePolicy removes the policy with the given ID, also from the policy store if
+// one is set. Returns ErrPolicyNotFound if no policy has the ID. The change is
+// recorded with ctx, which carries the actor making it.
+func (pm *PolicyManager) RemovePolicy(ctx context.Context, id int64) error {
+	pm.writeMu.Lock()
+	defer pm.writeMu.Unlock()
+
+	if pm.GetPolicy(id) == nil {
+		return ErrPolicyNotFound
+	}
+	if err := pm.persistDelete(ctx, id); err != nil {
+		return err
+	}
+
+	pm.mu.Lock()
+	index := pm.indexOf(id)
+	if index < 0 {
+		pm.mu.Unlock()
+		return ErrPolicyNotFound
+	}
+	before := pm.snapshotForRecorder()
+
+	pm.policies = append(pm.policies[:index], pm.policies[index+1:]...)
+	recorder, changes := pm.pendingChanges(before)
+	pm.mu.Unlock()
+
+	pm.recordChanges(ctx, recorder, changes)
+	return nil
+}
+
+// indexOf returns the position of the policy with the given ID, or -1.
+// Callers hold pm.mu.
+func (pm *PolicyManager) indexOf(id int64) int {
+	for i, policy := range pm.policies {
+		if policy.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// assignID gives policy the next policy ID. Callers hold pm.mu.
+func (pm *PolicyManager) assignID(policy *Policy) {
+	if policy == nil {
+		return
+	}
+	pm.nextID++
+	policy.ID = pm.nextID
 }
 
-// wireBlockchainRules sets provider and cache on blockchain rules
+// wireBlockchainRules sets provider and cache on blockchain rules, including those
+// nested in rule groups, and the decision cache of the policy
 func (pm *PolicyManager) wireBlockchainRules(policy *Policy) {
 	if policy == nil || policy.Rules == nil {
 		return
 	}
 
-	for _, rule := range policy.Rules {
+	policy.SetDecisionCache(pm.cache)
+
+	WalkRules(policy.Rules, func(rule Rule) {
 		switch r := rule.(type) {
 		case *ERC20MinBalanceRule:
 			r.SetProvider(pm.provider)
@@ -61,18 +201,119 @@ func (pm *PolicyManager) wireBlockchainRules(policy *Policy) {
 			if pm.logger != nil {
 				r.SetLogger(pm.logger)
 			}
+		case *ERC721MinBalanceRule:
+			r.SetProvider(pm.provider)
+			r.SetCache(pm.cache)
+			if pm.logger != nil {
+				r.SetLogger(pm.logger)
+			}
+		case *StakedBalanceRule:
+			r.SetProvider(pm.provider)
+			r.SetCache(pm.cache)
+			if pm.logger != nil {
+				r.SetLogger(pm.logger)
+			}
+		case *VotingPowerRule:
+			r.SetProvider(pm.provider)
+			r.SetCache(pm.cache)
+			if pm.logger != nil {
+				r.SetLogger(pm.logger)
+			}
+		case *ContractCallRule:
+			r.SetProvider(pm.provider)
+			r.SetCache(pm.cache)
+			if pm.logger != nil {
+				r.SetLogger(pm.logger)
+			}
+		case *LPPositionRule:
+			r.SetProvider(pm.provider)
+			r.SetCache(pm.cache)
+			if pm.logger != nil {
+				r.SetLogger(pm.logger)
+			}
+		case *HoldingDurationRule:
+			r.SetProvider(pm.provider)
+			r.SetCache(pm.cache)
+			if pm.logger != nil {
+				r.SetLogger(pm.logger)
+			}
+		case *CodeExistsRule:
+			r.SetProvider(pm.provider)
+			r.SetCache(pm.cache)
+			if pm.logger != nil {
+				r.SetLogger(pm.logger)
+			}
+		case *ContractDeployerRule:
+			r.SetProvider(pm.provider)
+			r.SetCache(pm.cache)
+			if pm.logger != nil {
+				r.SetLogger(pm.logger)
+			}
+		case *ERC721TraitRule:
+			r.SetProvider(pm.provider)
+			r.SetCache(pm.cache)
+			r.SetResolver(pm.resolver)
+			if pm.logger != nil {
+				r.SetLogger(pm.logger)
+			}
+		case *GeoRestrictionRule:
+			r.SetResolver(pm.geoip)
+			if pm.logger != nil {
+				r.SetLogger(pm.logger)
+			}
+		case *QuotaRule:
+			r.SetStore(pm.quotas)
+			if pm.logger != nil {
+				r.SetLogger(pm.logger)
+			}
+		case *NotInBlocklistRule:
+			r.SetBlocklist(pm.blocklist)
+			if pm.logger != nil {
+				r.SetLogger(pm.logger)
+			}
+		case *WebhookRule:
+			if pm.logger != nil {
+				r.SetLogger(pm.logger)
+			}
+		case *PluginRule:
+			r.SetPlugins(pm.plugins)
+			r.SetProvider(pm.provider)
+			if pm.logger != nil {
+				r.SetLogger(pm.logger)
+			}
 		}
-	}
+	})
 }
 
-// GetPoliciesForRoute returns all policies matching the given route and method
+// GetPoliciesForRoute returns the policies applying to the given path and method:
+// of the policies whose route pattern matches, the most specific enforced and the
+// most specific shadow policies, in the order they were added
 func (pm *PolicyManager) GetPoliciesForRoute(path string, method string) []*Policy {
 	pm.mu.RLock()
 	defer pm.mu.RUnlock()
 
+	var enforced, shadow []*Policy
+	for _, policy := range pm.policies {
+		if policy.Method != method || !matchRoute(policy.Path, path) {
+			continue
+		}
+		if policy.Shadow {
+			shadow = append(shadow, policy)
+		} else {
+			enforced = append(enforced, policy)
+		}
+	}
+	if len(shadow) == 0 {
+		return mostSpecificRoutes(enforced)
+	}
+
+	applying := make(map[*Policy]bool, len(enforced)+len(shadow))
+	for _, policy := range append(mostSpecificRoutes(enforced), mostSpecificRoutes(shadow)...) {
+		applying[policy] = true
+	}
 	var matching []*Policy
 	for _, policy := range pm.policies {
-		if policy.Path == path && policy.Method == method {
+		if applying[policy] {
 			matching = append(matching, policy)
 		}
 	}
@@ -87,8 +328,12 @@ func (pm *PolicyManager) HasPolicy(path string, method string) bool {
 // Clear removes all policies from the manager
 func (pm *PolicyManager) Clear() {
 	pm.mu.Lock()
-	defer pm.mu.Unlock()
+	before := pm.snapshotForRecorder()
 	pm.policies = make([]*Policy, 0)
+	recorder, changes := pm.pendingChanges(before)
+	pm.mu.Unlock()
+
+	pm.recordChanges(context.Background(), recorder, changes)
 }
 
 // LoadFromJSON loads policies from JSON configuration
@@ -99,14 +344,19 @@ func (pm *PolicyManager) LoadFromJSON(data []byte) error {
 	}
 
 	pm.mu.Lock()
-	defer pm.mu.Unlock()
+	before := pm.snapshotForRecorder()
 
 	// Wire up blockchain rules for all loaded policies
 	for _, policy := range policies {
 		pm.wireBlockchainRules(policy)
+		pm.assignID(policy)
 	}
 
 	pm.policies = policies
+	recorder, changes := pm.pendingChanges(before)
+	pm.mu.Unlock()
+
+	pm.recordChanges(context.Background(), recorder, changes)
 	return nil
 }
 
@@ -127,18 +377,83 @@ func (pm *PolicyManager) GetAllPolicies() []*Policy {
 	return result
 }
 
-// ReloadPolicies replaces all policies with new ones
+// ReloadPolicies replaces all policies with new ones, assigning new IDs
 func (pm *PolicyManager) ReloadPolicies(policies []*Policy) {
 	pm.mu.Lock()
-	defer pm.mu.Unlock()
+	before := pm.snapshotForRecorder()
 
 	// Wire up blockchain rules for all policies
 	for _, policy := range policies {
 		pm.wireBlockchainRules(policy)
+		pm.assignID(policy)
 	}
 
 	pm.policies = make([]*Policy, len(policies))
 	copy(pm.policies, policies)
+	recorder, changes := pm.pendingChanges(before)
+	pm.mu.Unlock()
+
+	pm.recordChanges(context.Background(), recorder, changes)
+}
+
+// SetMetadataResolver sets the token metadata resolver for NFT trait rules,
+// including those of policies already loaded
+func (pm *PolicyManager) SetMetadataResolver(resolver MetadataResolver) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pm.resolver = resolver
+	for _, policy := range pm.policies {
+		pm.wireBlockchainRules(policy)
+	}
+}
+
+// SetGeoIPResolver sets the GeoIP resolver for geo restriction rules,
+// including those of policies already loaded
+func (pm *PolicyManager) SetGeoIPResolver(resolver GeoIPResolver) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pm.geoip = resolver
+	for _, policy := range pm.policies {
+		pm.wireBlockchainRules(policy)
+	}
+}
+
+// SetQuotaStore sets the counter store for quota rules,
+// including those of policies already loaded
+func (pm *PolicyManager) SetQuotaStore(store QuotaStore) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pm.quotas = store
+	for _, policy := range pm.policies {
+		pm.wireBlockchainRules(policy)
+	}
+}
+
+// SetBlocklist sets the blocklist of not_in_blocklist rules, including those of
+// policies already loaded
+func (pm *PolicyManager) SetBlocklist(blocklist Blocklist) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pm.blocklist = blocklist
+	for _, policy := range pm.policies {
+		pm.wireBlockchainRules(policy)
+	}
+}
+
+// SetPlugins sets the WebAssembly plugins of plugin rules, including those of
+// policies already loaded
+func (pm *PolicyManager) SetPlugins(plugins *PluginRegistry) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pm.plugins = plugins
+	for _, policy := range pm.policies {
+		pm.wireBlockchainRules(policy)
+	}
 }
 
 // SetLogger sets the logger for the policy manager
@@ -1,7 +1,9 @@
 package policy
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -148,6 +150,33 @@ func TestManager_LoadFromJSON(t *testing.T) {
 	assert.True(t, manager.HasPolicy("/api/data", "GET"))
 }
 
+// TestManager_SetMetadataResolver wires the resolver into loaded trait rules
+func TestManager_SetMetadataResolver(t *testing.T) {
+	configJSON := `[
+		{
+			"path": "/api/gold",
+			"method": "GET",
+			"logic": "AND",
+			"rules": [{
+				"type": "erc721_trait",
+				"contract_address": "0x2234567890123456789012345678901234567890",
+				"chain_id": 1,
+				"trait_type": "Tier",
+				"trait_value": "Gold"
+			}]
+		}
+	]`
+
+	manager := NewPolicyManager(nil, nil)
+	require.NoError(t, manager.LoadFromJSON([]byte(configJSON)))
+
+	resolver := NewHTTPMetadataResolver("", time.Second)
+	manager.SetMetadataResolver(resolver)
+
+	rule := manager.GetAllPolicies()[0].Rules[0].(*ERC721TraitRule)
+	assert.Equal(t, resolver, rule.resolver)
+}
+
 // TestManager_LoadFromJSON_InvalidConfig returns error
 func TestManager_LoadFromJSON_InvalidConfig(t *testing.T) {
 	configJSON := `invalid`
@@ -219,3 +248,38 @@ func TestManager_ReloadPolicies(t *testing.T) {
 	assert.True(t, manager.HasPolicy("/api/admin", "GET"))
 	assert.False(t, manager.HasPolicy("/api/data", "GET"))
 }
+
+// TestManager_PolicyIDs assigns IDs and looks policies up, replaces and removes them by ID
+func TestManager_PolicyIDs(t *testing.T) {
+	manager := NewPolicyManager(nil, nil)
+	recorder := &fakeChangeRecorder{}
+	manager.SetChangeRecorder(recorder)
+
+	first := NewPolicy("GET", "/api/data", "AND", []Rule{NewHasScopeRule("read")})
+	second := NewPolicy("POST", "/api/data", "AND", []Rule{NewHasScopeRule("write")})
+	manager.AddPolicy(first)
+	manager.AddPolicy(second)
+	assert.Equal(t, int64(1), first.ID)
+	assert.Equal(t, int64(2), second.ID)
+	assert.Same(t, second, manager.GetPolicy(2))
+	assert.Nil(t, manager.GetPolicy(3))
+
+	replacement := NewPolicy("GET", "/api/data", "OR", []Rule{NewHasScopeRule("admin")})
+	require.NoError(t, manager.ReplacePolicy(context.Background(), 1, replacement))
+	assert.Equal(t, int64(1), replacement.ID)
+	assert.Same(t, replacement, manager.GetAllPolicies()[0])
+	assert.ErrorIs(t, manager.ReplacePolicy(context.Background(), 3, replacement), ErrPolicyNotFound)
+
+	require.NoError(t, manager.RemovePolicy(context.Background(), 1))
+	assert.ErrorIs(t, manager.RemovePolicy(context.Background(), 1), ErrPolicyNotFound)
+	assert.Equal(t, []*Policy{second}, manager.GetAllPolicies())
+
+	// IDs are not reused
+	third := NewPolicy("GET", "/api/other", "AND", []Rule{NewHasScopeRule("read")})
+	manager.AddPolicy(third)
+	assert.Equal(t, int64(3), third.ID)
+
+	require.Len(t, recorder.changes, 5)
+	assert.Equal(t, PolicyUpdated, recorder.changes[2].action)
+	assert.Equal(t, PolicyDeleted, recorder.changes[3].action)
+}
@@ -0,0 +1,194 @@
+package policy
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultIPFSGateway is the public gateway used to fetch ipfs:// metadata
+	DefaultIPFSGateway = "https://ipfs.io/ipfs/"
+
+	// maxMetadataSize bounds the size of a fetched metadata document
+	maxMetadataSize = 1 << 20
+)
+
+// TokenMetadata is the subset of the ERC-721 metadata JSON schema used by trait rules
+type TokenMetadata struct {
+	Name       string           `json:"name"`
+	Attributes []TokenAttribute `json:"attributes"`
+}
+
+// TokenAttribute is a single trait in the OpenSea metadata convention
+type TokenAttribute struct {
+	TraitType string      `json:"trait_type"`
+	Value     interface{} `json:"value"` // String or number
+}
+
+// HasTrait reports whether the metadata has a trait with the given type and value.
+// Both are compared case-insensitively; numeric values are compared in their JSON form.
+func (m *TokenMetadata) HasTrait(traitType, value string) bool {
+	for _, attribute := range m.Attributes {
+		if !strings.EqualFold(attribute.TraitType, traitType) {
+			continue
+		}
+		if strings.EqualFold(fmt.Sprint(attribute.Value), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// MetadataResolver fetches the metadata document a tokenURI points to
+type MetadataResolver interface {
+	Resolve(ctx context.Context, uri string) (*TokenMetadata, error)
+}
+
+// HTTPMetadataResolver resolves http(s), ipfs:// and data: token URIs.
+// ipfs:// URIs are fetched through the configured gateway. Fetched documents are
+// cached by URI when a cache is set.
+type HTTPMetadataResolver struct {
+	client  *http.Client
+	gateway string
+	cache   CacheProvider
+}
+
+// NewHTTPMetadataResolver creates a metadata resolver using the given IPFS gateway
+// (DefaultIPFSGateway if empty) and per-request timeout
+func NewHTTPMetadataResolver(ipfsGateway string, timeout time.Duration) *HTTPMetadataResolver {
+	if ipfsGateway == "" {
+		ipfsGateway = DefaultIPFSGateway
+	}
+	if !strings.HasSuffix(ipfsGateway, "/") {
+		ipfsGateway += "/"
+	}
+	return &HTTPMetadataResolver{
+		client:  &http.Client{Timeout: timeout},
+		gateway: ipfsGateway,
+	}
+}
+
+// SetCache sets the cache for storing fetched metadata
+func (r *HTTPMetadataResolver) SetCache(cache CacheProvider) {
+	r.cache = cache
+}
+
+// Resolve returns the metadata for a token URI
+func (r *HTTPMetadataResolver) Resolve(ctx context.Context, uri string) (*TokenMetadata, error) {
+	if strings.HasPrefix(uri, "data:") {
+		return decodeDataURI(uri)
+	}
+
+	cacheKey := "token_metadata:" + uri
+	if r.cache != nil {
+		if cached, ok := r.cache.Get(cacheKey); ok {
+			if metadata, ok := cached.(*TokenMetadata); ok {
+				return metadata, nil
+			}
+		}
+	}
+
+	if err := skipUncachedRead(ctx); err != nil {
+		return nil, err
+	}
+
+	fetchURL, err := r.fetchURL(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fetchURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metadata request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("metadata request returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxMetadataSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata: %w", err)
+	}
+	if len(body) > maxMetadataSize {
+		return nil, fmt.Errorf("metadata exceeds %d bytes", maxMetadataSize)
+	}
+
+	var metadata TokenMetadata
+	if err := json.Unmarshal(body, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata: %w", err)
+	}
+
+	if r.cache != nil {
+		r.cache.Set(cacheKey, &metadata)
+	}
+
+	return &metadata, nil
+}
+
+// fetchURL maps a token URI to the HTTP URL it is fetched from
+func (r *HTTPMetadataResolver) fetchURL(uri string) (string, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("invalid token URI: %w", err)
+	}
+
+	switch parsed.Scheme {
+	case "http", "https":
+		return uri, nil
+	case "ipfs":
+		// ipfs://<cid>/<path>, sometimes written ipfs://ipfs/<cid>/<path>
+		path := strings.TrimPrefix(strings.TrimPrefix(uri, "ipfs://"), "ipfs/")
+		if path == "" {
+			return "", fmt.Errorf("token URI %q has no IPFS content identifier", uri)
+		}
+		return r.gateway + path, nil
+	default:
+		return "", fmt.Errorf("unsupported token URI scheme %q", parsed.Scheme)
+	}
+}
+
+// decodeDataURI decodes on-chain metadata embedded in a data: URI
+// (e.g. "data:application/json;base64,eyJ...")
+func decodeDataURI(uri string) (*TokenMetadata, error) {
+	header, payload, ok := strings.Cut(strings.TrimPrefix(uri, "data:"), ",")
+	if !ok {
+		return nil, fmt.Errorf("malformed data URI")
+	}
+
+	var body []byte
+	if strings.HasSuffix(header, ";base64") {
+		decoded, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode data URI: %w", err)
+		}
+		body = decoded
+	} else {
+		unescaped, err := url.PathUnescape(payload)
+		if err != nil {
+			// Raw JSON is common in on-chain metadata and may contain a bare '%'
+			unescaped = payload
+		}
+		body = []byte(unescaped)
+	}
+
+	var metadata TokenMetadata
+	if err := json.Unmarshal(body, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata: %w", err)
+	}
+	return &metadata, nil
+}
@@ -0,0 +1,101 @@
+package policy
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const goldMetadata = `{"name":"Member #7","attributes":[{"trait_type":"Tier","value":"Gold"},{"trait_type":"Level","value":3}]}`
+
+// TestTokenMetadata_HasTrait matches trait types and values case-insensitively
+func TestTokenMetadata_HasTrait(t *testing.T) {
+	metadata, err := decodeDataURI("data:application/json," + goldMetadata)
+	require.NoError(t, err)
+
+	assert.True(t, metadata.HasTrait("Tier", "Gold"))
+	assert.True(t, metadata.HasTrait("tier", "gold"))
+	assert.True(t, metadata.HasTrait("Level", "3"))
+	assert.False(t, metadata.HasTrait("Tier", "Silver"))
+	assert.False(t, metadata.HasTrait("Rank", "Gold"))
+}
+
+// TestHTTPMetadataResolver_IPFSGateway fetches ipfs:// URIs through the gateway
+func TestHTTPMetadataResolver_IPFSGateway(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		w.Write([]byte(goldMetadata))
+	}))
+	defer server.Close()
+
+	resolver := NewHTTPMetadataResolver(server.URL+"/ipfs", time.Second)
+
+	for _, uri := range []string{"ipfs://QmCollection/7.json", "ipfs://ipfs/QmCollection/7.json"} {
+		metadata, err := resolver.Resolve(context.Background(), uri)
+		require.NoError(t, err)
+		assert.Equal(t, "Member #7", metadata.Name)
+	}
+	assert.Equal(t, []string{"/ipfs/QmCollection/7.json", "/ipfs/QmCollection/7.json"}, requested)
+}
+
+// TestHTTPMetadataResolver_Cache fetches a URI once when a cache is set
+func TestHTTPMetadataResolver_Cache(t *testing.T) {
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.Write([]byte(goldMetadata))
+	}))
+	defer server.Close()
+
+	resolver := NewHTTPMetadataResolver("", time.Second)
+	resolver.SetCache(&MockCache{})
+
+	for i := 0; i < 3; i++ {
+		metadata, err := resolver.Resolve(context.Background(), server.URL+"/7.json")
+		require.NoError(t, err)
+		assert.True(t, metadata.HasTrait("Tier", "Gold"))
+	}
+	assert.Equal(t, 1, fetches)
+}
+
+// TestHTTPMetadataResolver_DataURI decodes on-chain metadata without fetching
+func TestHTTPMetadataResolver_DataURI(t *testing.T) {
+	resolver := NewHTTPMetadataResolver("", time.Second)
+
+	uri := "data:application/json;base64," + base64.StdEncoding.EncodeToString([]byte(goldMetadata))
+	metadata, err := resolver.Resolve(context.Background(), uri)
+	require.NoError(t, err)
+	assert.True(t, metadata.HasTrait("Tier", "Gold"))
+}
+
+// TestHTTPMetadataResolver_Errors fails on bad status, oversized documents and unknown schemes
+func TestHTTPMetadataResolver_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing.json":
+			w.WriteHeader(http.StatusNotFound)
+		case "/large.json":
+			w.Write([]byte(`{"name":"` + strings.Repeat("x", maxMetadataSize) + `"}`))
+		}
+	}))
+	defer server.Close()
+
+	resolver := NewHTTPMetadataResolver("", time.Second)
+
+	_, err := resolver.Resolve(context.Background(), server.URL+"/missing.json")
+	assert.Error(t, err)
+
+	_, err = resolver.Resolve(context.Background(), server.URL+"/large.json")
+	assert.Error(t, err)
+
+	_, err = resolver.Resolve(context.Background(), "file:///etc/passwd")
+	assert.Error(t, err)
+}
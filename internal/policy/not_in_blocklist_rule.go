@@ -0,0 +1,81 @@
+package policy
+
+import (
+	"context"
+
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"go.uber.org/zap"
+)
+
+// NotInBlocklistRule denies addresses on the global blocklist, such as
+// compromised or sanctioned wallets. The caller is also denied if any wallet
+// linked to their account is blocked, so a blocked wallet cannot be laundered
+// through a clean one.
+type NotInBlocklistRule struct {
+	// blocklist will be set by manager
+	blocklist Blocklist
+	logger    *zap.Logger
+}
+
+// NewNotInBlocklistRule creates a new blocklist rule
+func NewNotInBlocklistRule() *NotInBlocklistRule {
+	logger, _ := zap.NewProduction()
+	return &NotInBlocklistRule{
+		logger: logger,
+	}
+}
+
+// Type returns the rule type
+func (r *NotInBlocklistRule) Type() RuleType {
+	return NotInBlocklistRuleType
+}
+
+// Validate checks if the rule parameters are valid
+func (r *NotInBlocklistRule) Validate() error {
+	return nil
+}
+
+// Evaluate checks that neither the address nor the wallets linked to it are blocked
+// This implementation follows fail-closed security: on any error, return false
+func (r *NotInBlocklistRule) Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	if r.blocklist == nil {
+		r.logger.Warn("no blocklist configured",
+			zap.String("rule", "NotInBlocklist"))
+		return false, nil
+	}
+
+	var addresses []string
+	if address != "" {
+		addresses = append(addresses, address)
+	}
+	if ec := EvaluationContextFromContext(ctx); ec != nil {
+		addresses = append(addresses, ec.Wallets...)
+	}
+	if len(addresses) == 0 {
+		return true, nil
+	}
+
+	blocked, err := r.blocklist.FindBlockedAddresses(ctx, addresses)
+	if err != nil {
+		r.logger.Error("blocklist check failed",
+			zap.Error(err),
+			zap.String("address", address))
+		return false, nil
+	}
+
+	r.logger.Info("blocklist check completed",
+		zap.String("address", address),
+		zap.Strings("blocked", blocked))
+
+	return len(blocked) == 0, nil
+}
+
+// SetBlocklist sets the blocklist to check addresses against
+func (r *NotInBlocklistRule) SetBlocklist(blocklist Blocklist) {
+	r.blocklist = blocklist
+}
+
+// SetLogger sets the logger for the rule
+func (r *NotInBlocklistRule) SetLogger(logger *zap.Logger) {
+	r.logger = logger
+}
@@ -0,0 +1,72 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingBlocklist fails every lookup
+type failingBlocklist struct{}
+
+func (failingBlocklist) FindBlockedAddresses(ctx context.Context, addresses []string) ([]string, error) {
+	return nil, fmt.Errorf("blocklist unavailable")
+}
+
+// TestNotInBlocklistRule_Evaluate denies blocked addresses, in any case
+func TestNotInBlocklistRule_Evaluate(t *testing.T) {
+	rule := NewNotInBlocklistRule()
+	rule.SetBlocklist(NewMemoryBlocklist("0x" + strings.ToUpper(testUserAddr[2:])))
+
+	allowed, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	allowed, err = rule.Evaluate(context.Background(), testUserAddr2, nil)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+// TestNotInBlocklistRule_LinkedWallets denies callers with a blocked linked wallet
+func TestNotInBlocklistRule_LinkedWallets(t *testing.T) {
+	rule := NewNotInBlocklistRule()
+	rule.SetBlocklist(NewMemoryBlocklist(testUserAddr2))
+
+	ctx := WithEvaluationContext(context.Background(), &EvaluationContext{Wallets: []string{testUserAddr2}})
+	allowed, err := rule.Evaluate(ctx, testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+// TestNotInBlocklistRule_FailClosed denies without a blocklist or when it fails
+func TestNotInBlocklistRule_FailClosed(t *testing.T) {
+	rule := NewNotInBlocklistRule()
+	allowed, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	rule.SetBlocklist(failingBlocklist{})
+	allowed, err = rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+// TestPolicyManager_SetBlocklist wires the blocklist into loaded policies
+func TestPolicyManager_SetBlocklist(t *testing.T) {
+	pm := NewPolicyManager(nil, nil)
+	require.NoError(t, pm.LoadFromJSON([]byte(`[{"path": "/api/data", "method": "GET", "logic": "AND", "rules": [{"type": "not_in_blocklist"}]}]`)))
+	pm.SetBlocklist(NewMemoryBlocklist(testUserAddr))
+
+	p := pm.GetPoliciesForRoute("/api/data", "GET")[0]
+	allowed, err := p.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	allowed, err = p.Evaluate(context.Background(), testUserAddr2, nil)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
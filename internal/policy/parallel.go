@@ -0,0 +1,113 @@
+package policy
+
+import (
+	"context"
+
+	"github.com/yourusername/gatekeeper/internal/auth"
+)
+
+// isBlockchainRule reports whether a rule reads the blockchain, costing an RPC
+// round-trip unless its result is cached
+func isBlockchainRule(rule Rule) bool {
+	_, ok := rule.(cachingRule)
+	return ok
+}
+
+// ruleOutcome is the outcome of a rule evaluated concurrently
+type ruleOutcome struct {
+	result     bool
+	err        error
+	readFailed bool // A read of the rule failed
+}
+
+// concurrentRules holds the outcomes of the blockchain rules of a group, which
+// are evaluated concurrently rather than one round-trip after another
+type concurrentRules struct {
+	outcomes []chan ruleOutcome // By rule; nil for rules evaluated in order
+	cancel   context.CancelFunc
+}
+
+// evaluateGroup evaluates rules in order until one evaluates to decisive, which is
+// then the outcome: false for AND logic, true for OR logic. If none does, the
+// outcome is the opposite. Blockchain rules are evaluated concurrently up front,
+// but their outcomes are taken in order, so the decision and any error are those
+// of evaluating one rule after another; reads still running once the outcome is
+// decided are cancelled.
+func evaluateGroup(ctx context.Context, rules []Rule, address string, claims *auth.Claims, decisive bool) (bool, error) {
+	index, err := firstDecisive(ctx, rules, address, claims, decisive)
+	if err != nil {
+		return false, err
+	}
+	if index >= 0 {
+		return decisive, nil
+	}
+	return !decisive, nil
+}
+
+// firstDecisive returns the index of the first rule evaluating to decisive, or -1
+// if none does, evaluating rules as evaluateGroup does
+func firstDecisive(ctx context.Context, rules []Rule, address string, claims *auth.Claims, decisive bool) (int, error) {
+	concurrent := startBlockchainRules(ctx, rules, address, claims)
+	if concurrent != nil {
+		defer concurrent.cancel()
+	}
+
+	for i, rule := range rules {
+		var result bool
+		var err error
+		if concurrent != nil && concurrent.outcomes[i] != nil {
+			result, err = concurrent.wait(ctx, i)
+		} else {
+			result, err = evaluateRule(ctx, rule, address, claims)
+		}
+		if err != nil {
+			return -1, err
+		}
+		if result == decisive {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+// startBlockchainRules starts evaluating the blockchain rules of a group, or
+// returns nil if fewer than two would gain from it
+func startBlockchainRules(ctx context.Context, rules []Rule, address string, claims *auth.Claims) *concurrentRules {
+	count := 0
+	for _, rule := range rules {
+		if isBlockchainRule(rule) {
+			count++
+		}
+	}
+	if count < 2 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	concurrent := &concurrentRules{outcomes: make([]chan ruleOutcome, len(rules)), cancel: cancel}
+	for i, rule := range rules {
+		if !isBlockchainRule(rule) {
+			continue
+		}
+		outcome := make(chan ruleOutcome, 1)
+		concurrent.outcomes[i] = outcome
+		go func() {
+			// Read failures are recorded per rule: a read cancelled because the
+			// outcome was decided without it must not mark the evaluation failed
+			ctx, failures := withReadFailures(ctx)
+			result, err := evaluateRule(ctx, rule, address, claims)
+			outcome <- ruleOutcome{result: result, err: err, readFailed: failures.failed.Load()}
+		}()
+	}
+	return concurrent
+}
+
+// wait returns the outcome of rule i once evaluated, recording a failed read of
+// the rule in ctx
+func (c *concurrentRules) wait(ctx context.Context, i int) (bool, error) {
+	outcome := <-c.outcomes[i]
+	if outcome.readFailed {
+		markReadFailed(ctx)
+	}
+	return outcome.result, outcome.err
+}
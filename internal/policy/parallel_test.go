@@ -0,0 +1,117 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/chain"
+)
+
+const (
+	fastToken = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+	slowToken = "0x1f9840a85d5af5bf1d1762f925bdaddc4201f984"
+)
+
+// slowProvider holds calls to slowToken until released or cancelled, counting the
+// calls in flight
+type slowProvider struct {
+	BlockchainProvider
+	release   chan struct{}
+	inFlight  atomic.Int32
+	cancelled atomic.Int32
+}
+
+func (p *slowProvider) Call(ctx context.Context, method string, params []interface{}) ([]byte, error) {
+	call, _ := params[0].(map[string]interface{})
+	if to, _ := call["to"].(string); strings.EqualFold(to, slowToken) {
+		p.inFlight.Add(1)
+		defer p.inFlight.Add(-1)
+		select {
+		case <-p.release:
+		case <-ctx.Done():
+			p.cancelled.Add(1)
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+			return nil, errors.New("never released")
+		}
+	}
+	return p.BlockchainProvider.Call(ctx, method, params)
+}
+
+func newSlowFixture(t *testing.T, p *Policy) *slowProvider {
+	t.Helper()
+	mock := &MockBlockchainProvider{balances: map[string]*big.Int{
+		"0x1234567890abcdef1234567890abcdef12345678": big.NewInt(5000),
+	}}
+	provider := &slowProvider{BlockchainProvider: mock, release: make(chan struct{})}
+	manager := NewPolicyManager(provider, chain.NewCache(time.Hour))
+	manager.AddPolicy(p)
+	return provider
+}
+
+func TestEvaluateGroup_ReadsConcurrently(t *testing.T) {
+	const address = "0x1234567890abcdef1234567890abcdef12345678"
+	p := NewPolicy("GET", "/api/data", "AND", []Rule{
+		NewERC20MinBalanceRule(slowToken, big.NewInt(1000), 1),
+		NewERC20MinBalanceRule(slowToken, big.NewInt(2000), 1),
+	})
+	provider := newSlowFixture(t, p)
+
+	// Both reads are in flight at once; evaluated in turn, the first would never be released
+	go func() {
+		for provider.inFlight.Load() < 2 {
+			time.Sleep(time.Millisecond)
+		}
+		close(provider.release)
+	}()
+	allowed, err := p.Evaluate(context.Background(), address, nil)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestEvaluateGroup_CancelsOnceDecided(t *testing.T) {
+	const address = "0x1234567890abcdef1234567890abcdef12345678"
+	p := NewPolicy("GET", "/api/data", "OR", []Rule{
+		NewERC20MinBalanceRule(fastToken, big.NewInt(1000), 1),
+		AllOf(NewERC20MinBalanceRule(slowToken, big.NewInt(1000), 1), NewHasScopeRule("read")),
+		NewERC20MinBalanceRule(slowToken, big.NewInt(1000), 1),
+	})
+	p.CacheTTL = time.Minute
+	provider := newSlowFixture(t, p)
+
+	allowed, err := p.Evaluate(context.Background(), address, nil)
+	require.NoError(t, err)
+	assert.True(t, allowed, "the first rule decided the policy")
+	require.Eventually(t, func() bool { return provider.cancelled.Load() == 1 }, time.Second, time.Millisecond,
+		"the read of the last rule was cancelled; the group after the first rule was never evaluated")
+
+	// The cancelled read did not keep the decision from being cached
+	p.Rules[0] = NewERC20MinBalanceRule(fastToken, big.NewInt(1_000_000), 1)
+	allowed, err = p.Evaluate(context.Background(), address, nil)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestEvaluateGroup_DecidesInOrder(t *testing.T) {
+	const address = "0x1234567890abcdef1234567890abcdef12345678"
+	failing := NewHasScopeRule("admin")
+	broken := &erroringRule{err: errors.New("rule error")}
+
+	// The failed rule comes first, so the later error is never reached, as in turn
+	allowed, err := evaluateGroup(context.Background(), []Rule{
+		NewERC20MinBalanceRule(fastToken, big.NewInt(1000), 1), failing, broken,
+		NewERC20MinBalanceRule(fastToken, big.NewInt(1000), 1),
+	}, address, nil, false)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	_, err = evaluateGroup(context.Background(), []Rule{broken, failing}, address, nil, false)
+	assert.EqualError(t, err, "rule error")
+}
@@ -0,0 +1,128 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrPolicyNotFound is returned when no policy has the requested ID
+var ErrPolicyNotFound = errors.New("policy not found")
+
+// PolicyStore persists policies so they survive restarts. Policies are exchanged
+// as JSON in the format read by PolicyLoader, with the ID the store assigned them.
+type PolicyStore interface {
+	ListPolicies(ctx context.Context) ([]json.RawMessage, error)
+	CreatePolicy(ctx context.Context, policy json.RawMessage) (int64, error)
+	UpdatePolicy(ctx context.Context, id int64, policy json.RawMessage) error
+	DeletePolicy(ctx context.Context, id int64) error
+}
+
+// SetPolicyStore sets the store that policies added, replaced and removed through
+// the manager are written to. Policies loaded with LoadFromJSON or ReloadPolicies
+// are not persisted.
+func (pm *PolicyManager) SetPolicyStore(store PolicyStore) {
+	pm.writeMu.Lock()
+	defer pm.writeMu.Unlock()
+	pm.store = store
+}
+
+// LoadFromStore replaces all policies with those in the policy store, keeping the
+// IDs the store assigned. The new policies are swapped in at once, so requests are
+// served throughout; if any stored policy is invalid, the current policies are kept.
+// The change is recorded with ctx, which carries the actor making it.
+func (pm *PolicyManager) LoadFromStore(ctx context.Context) error {
+	pm.writeMu.Lock()
+	defer pm.writeMu.Unlock()
+
+	if pm.store == nil {
+		return fmt.Errorf("no policy store set")
+	}
+
+	docs, err := pm.store.ListPolicies(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list policies: %w", err)
+	}
+
+	policies := make([]*Policy, 0, len(docs))
+	for _, doc := range docs {
+		var stored struct {
+			ID int64 `json:"id"`
+		}
+		if err := json.Unmarshal(doc, &stored); err != nil {
+			return fmt.Errorf("failed to parse stored policy: %w", err)
+		}
+		policy, err := pm.loader.ParsePolicy(doc)
+		if err != nil {
+			return fmt.Errorf("stored policy %d: %w", stored.ID, err)
+		}
+		policy.ID = stored.ID
+		policies = append(policies, policy)
+	}
+
+	pm.mu.Lock()
+	before := pm.snapshotForRecorder()
+
+	for _, policy := range policies {
+		pm.wireBlockchainRules(policy)
+		if policy.ID > pm.nextID {
+			pm.nextID = policy.ID
+		}
+	}
+
+	pm.policies = policies
+	recorder, changes := pm.pendingChanges(before)
+	pm.mu.Unlock()
+
+	pm.recordChanges(ctx, recorder, changes)
+	return nil
+}
+
+// persistCreate writes a new policy to the store, if one is set, and returns the
+// ID it assigned, or 0 without a store. Callers hold pm.writeMu.
+func (pm *PolicyManager) persistCreate(ctx context.Context, policy *Policy) (int64, error) {
+	if pm.store == nil {
+		return 0, nil
+	}
+
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode policy: %w", err)
+	}
+	id, err := pm.store.CreatePolicy(ctx, data)
+	if err != nil {
+		return 0, fmt.Errorf("failed to persist policy: %w", err)
+	}
+	return id, nil
+}
+
+// persistUpdate writes a replaced policy to the store, if one is set. Callers hold
+// pm.writeMu.
+func (pm *PolicyManager) persistUpdate(ctx context.Context, id int64, policy *Policy) error {
+	if pm.store == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return fmt.Errorf("failed to encode policy: %w", err)
+	}
+	if err := pm.store.UpdatePolicy(ctx, id, data); err != nil {
+		return fmt.Errorf("failed to persist policy: %w", err)
+	}
+	return nil
+}
+
+// persistDelete removes a policy from the store, if one is set. Callers hold
+// pm.writeMu.
+func (pm *PolicyManager) persistDelete(ctx context.Context, id int64) error {
+	if pm.store == nil {
+		return nil
+	}
+
+	if err := pm.store.DeletePolicy(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete persisted policy: %w", err)
+	}
+	return nil
+}
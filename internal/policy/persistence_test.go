@@ -0,0 +1,83 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/gatekeepertest"
+)
+
+// failingPolicyStore fails every write
+type failingPolicyStore struct {
+	PolicyStore
+}
+
+func (failingPolicyStore) CreatePolicy(ctx context.Context, policy json.RawMessage) (int64, error) {
+	return 0, errors.New("database unavailable")
+}
+
+func TestManager_PolicyStore(t *testing.T) {
+	ctx := context.Background()
+	policyStore := gatekeepertest.NewStore()
+
+	manager := NewPolicyManager(nil, nil)
+	manager.SetPolicyStore(policyStore)
+
+	first := NewPolicy("GET", "/api/data", "AND", []Rule{NewHasScopeRule("read")})
+	second := NewPolicy("POST", "/api/data", "OR", []Rule{NewHasScopeRule("write"), NewInAllowlistRule([]string{"0x742d35cc6634c0532925a3b844bc9e7595f0beb8"})})
+	require.NoError(t, manager.AddPolicyContext(ctx, first))
+	require.NoError(t, manager.AddPolicyContext(ctx, second))
+	require.NoError(t, manager.ReplacePolicy(ctx, first.ID, NewPolicy("GET", "/api/data", "AND", []Rule{NewHasScopeRule("admin")})))
+	require.NoError(t, manager.RemovePolicy(ctx, second.ID))
+	third := NewPolicy("DELETE", "/api/data", "AND", []Rule{NewHasScopeRule("admin")})
+	require.NoError(t, manager.AddPolicyContext(ctx, third))
+	assert.Equal(t, int64(3), third.ID)
+
+	// A new manager, as after a restart, loads what was persisted
+	restarted := NewPolicyManager(nil, nil)
+	restarted.SetPolicyStore(policyStore)
+	recorder := &fakeChangeRecorder{}
+	restarted.SetChangeRecorder(recorder)
+	require.NoError(t, restarted.LoadFromStore(ctx))
+
+	policies := restarted.GetAllPolicies()
+	require.Len(t, policies, 2)
+	assert.Equal(t, int64(1), policies[0].ID)
+	assert.Equal(t, "admin", policies[0].Rules[0].(*HasScopeRule).Scope)
+	assert.Equal(t, int64(3), policies[1].ID)
+	assert.Len(t, recorder.changes, 2)
+
+	// Memory-only additions do not reuse persisted IDs
+	restarted.SetPolicyStore(nil)
+	fourth := NewPolicy("PUT", "/api/data", "AND", []Rule{NewHasScopeRule("write")})
+	restarted.AddPolicy(fourth)
+	assert.Equal(t, int64(4), fourth.ID)
+}
+
+func TestManager_LoadFromStoreKeepsPoliciesOnError(t *testing.T) {
+	ctx := context.Background()
+	policyStore := gatekeepertest.NewStore()
+	_, err := policyStore.CreatePolicy(ctx, []byte(`{"path":"/api/data","method":"GET","logic":"AND","rules":[{"type":"unknown_rule"}]}`))
+	require.NoError(t, err)
+
+	manager := NewPolicyManager(nil, nil)
+	manager.AddPolicy(NewPolicy("GET", "/api/data", "AND", []Rule{NewHasScopeRule("read")}))
+	assert.Error(t, manager.LoadFromStore(ctx))
+
+	manager.SetPolicyStore(policyStore)
+	assert.Error(t, manager.LoadFromStore(ctx))
+	assert.Equal(t, 1, manager.GetPoliciesCount())
+}
+
+func TestManager_PolicyStoreFailure(t *testing.T) {
+	manager := NewPolicyManager(nil, nil)
+	manager.SetPolicyStore(failingPolicyStore{})
+
+	err := manager.AddPolicyContext(context.Background(), NewPolicy("GET", "/api/data", "AND", []Rule{NewHasScopeRule("read")}))
+	assert.Error(t, err)
+	assert.Zero(t, manager.GetPoliciesCount())
+}
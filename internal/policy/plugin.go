@@ -0,0 +1,282 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"go.uber.org/zap"
+)
+
+// Plugin runtime limits
+const (
+	DefaultPluginMemoryLimitMB = 16
+
+	// pluginHostModule is the module name plugins import host functions from
+	pluginHostModule = "gatekeeper"
+	// wasmPagesPerMB converts megabytes to 64 KiB WebAssembly memory pages
+	wasmPagesPerMB = 16
+)
+
+// pluginNamePattern restricts plugin names, the file names of their modules
+var pluginNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// pluginRPCMethods are the JSON-RPC methods plugins may call: reads of chain state
+var pluginRPCMethods = map[string]bool{
+	"eth_blockNumber":         true,
+	"eth_call":                true,
+	"eth_chainId":             true,
+	"eth_getBalance":          true,
+	"eth_getCode":             true,
+	"eth_getStorageAt":        true,
+	"eth_getTransactionCount": true,
+}
+
+// PluginRegistry holds the custom rules compiled to WebAssembly that plugin rules
+// run. Each evaluation runs in a fresh instance of the module, so plugins keep no
+// state between requests, and plugins reach nothing outside their sandbox but the
+// host functions of the "gatekeeper" module: no files, network or environment.
+//
+// A plugin module exports its memory and two functions:
+//
+//	alloc(size i32) i32         reserves size bytes of memory for the host to write to
+//	evaluate(ptr i32, len i32) i32  decides the JSON input at ptr: 1 passes, 0 fails
+//
+// and may import from "gatekeeper":
+//
+//	log(ptr i32, len i32)                          logs the message at ptr
+//	rpc_call(method_ptr, method_len, params_ptr, params_len i32) i64
+//	    makes a read-only JSON-RPC call with a JSON array of params, returning the
+//	    response allocated in the plugin's memory as ptr<<32|len, or 0 on failure
+//
+// WASI is available for modules built with WASI toolchains, without any files,
+// environment variables or clock.
+type PluginRegistry struct {
+	runtime wazero.Runtime
+	modules map[string]wazero.CompiledModule
+}
+
+// pluginHost is what the host functions of an evaluation act for
+type pluginHost struct {
+	plugin   string
+	provider BlockchainProvider
+	logger   *zap.Logger
+}
+
+// pluginHostKey is the context key of the pluginHost of an evaluation
+type pluginHostKey struct{}
+
+// LoadPlugins compiles every .wasm file in dir as a plugin named after the file,
+// e.g. "kyc.wasm" is the "kyc" plugin. Instances may grow their memory to
+// memoryLimitMB megabytes; zero uses DefaultPluginMemoryLimitMB.
+func LoadPlugins(ctx context.Context, dir string, memoryLimitMB int) (*PluginRegistry, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("failed to read plugins directory: %w", err)
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.wasm"))
+	if err != nil {
+		return nil, err
+	}
+	if memoryLimitMB == 0 {
+		memoryLimitMB = DefaultPluginMemoryLimitMB
+	}
+	if memoryLimitMB < 0 || memoryLimitMB > 4096 {
+		return nil, fmt.Errorf("plugin memory limit must be between 1 and 4096 MB")
+	}
+
+	config := wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(uint32(memoryLimitMB * wasmPagesPerMB)).
+		WithCloseOnContextDone(true)
+	registry := &PluginRegistry{
+		runtime: wazero.NewRuntimeWithConfig(ctx, config),
+		modules: make(map[string]wazero.CompiledModule, len(paths)),
+	}
+	if err := registry.instantiateHost(ctx); err != nil {
+		registry.Close(ctx)
+		return nil, err
+	}
+
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".wasm")
+		if !pluginNamePattern.MatchString(name) {
+			registry.Close(ctx)
+			return nil, fmt.Errorf("invalid plugin name %q: use lowercase letters, digits, '-' and '_'", name)
+		}
+		code, err := os.ReadFile(path)
+		if err != nil {
+			registry.Close(ctx)
+			return nil, fmt.Errorf("failed to read plugin %s: %w", name, err)
+		}
+		compiled, err := registry.runtime.CompileModule(ctx, code)
+		if err != nil {
+			registry.Close(ctx)
+			return nil, fmt.Errorf("failed to compile plugin %s: %w", name, err)
+		}
+		if err := checkPluginExports(compiled); err != nil {
+			registry.Close(ctx)
+			return nil, fmt.Errorf("plugin %s: %w", name, err)
+		}
+		registry.modules[name] = compiled
+	}
+	return registry, nil
+}
+
+// instantiateHost provides the host functions and WASI to plugins
+func (r *PluginRegistry) instantiateHost(ctx context.Context) error {
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, r.runtime); err != nil {
+		return fmt.Errorf("failed to instantiate WASI: %w", err)
+	}
+	_, err := r.runtime.NewHostModuleBuilder(pluginHostModule).
+		NewFunctionBuilder().WithFunc(pluginLog).Export("log").
+		NewFunctionBuilder().WithFunc(pluginRPCCall).Export("rpc_call").
+		Instantiate(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to instantiate plugin host functions: %w", err)
+	}
+	return nil
+}
+
+// checkPluginExports verifies a module exports the functions plugins must provide
+func checkPluginExports(compiled wazero.CompiledModule) error {
+	exports := compiled.ExportedFunctions()
+	for name, params := range map[string]int{"alloc": 1, "evaluate": 2} {
+		fn, ok := exports[name]
+		if !ok {
+			return fmt.Errorf("missing exported function %q", name)
+		}
+		if len(fn.ParamTypes()) != params || len(fn.ResultTypes()) != 1 {
+			return fmt.Errorf("exported function %q has the wrong signature", name)
+		}
+	}
+	if len(compiled.ExportedMemories()) == 0 {
+		return errors.New("missing exported memory")
+	}
+	return nil
+}
+
+// Has reports whether a plugin is loaded
+func (r *PluginRegistry) Has(name string) bool {
+	_, ok := r.modules[name]
+	return ok
+}
+
+// Names returns the names of the loaded plugins, sorted
+func (r *PluginRegistry) Names() []string {
+	names := make([]string, 0, len(r.modules))
+	for name := range r.modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Close releases the runtime and the compiled plugins
+func (r *PluginRegistry) Close(ctx context.Context) error {
+	return r.runtime.Close(ctx)
+}
+
+// evaluate runs a plugin on the JSON input in a fresh instance. Host functions act
+// for host; the evaluation is aborted when ctx is done.
+func (r *PluginRegistry) evaluate(ctx context.Context, name string, input []byte, host *pluginHost) (bool, error) {
+	compiled, ok := r.modules[name]
+	if !ok {
+		return false, fmt.Errorf("plugin %s is not loaded", name)
+	}
+
+	ctx = context.WithValue(ctx, pluginHostKey{}, host)
+	// Anonymous instances may run concurrently; _initialize sets up reactor modules
+	config := wazero.NewModuleConfig().WithName("").WithStartFunctions("_initialize")
+	module, err := r.runtime.InstantiateModule(ctx, compiled, config)
+	if err != nil {
+		return false, fmt.Errorf("failed to instantiate plugin: %w", err)
+	}
+	defer module.Close(context.WithoutCancel(ctx))
+
+	ptr, err := writeToPlugin(ctx, module, input)
+	if err != nil {
+		return false, err
+	}
+	results, err := module.ExportedFunction("evaluate").Call(ctx, uint64(ptr), uint64(len(input)))
+	if err != nil {
+		return false, fmt.Errorf("plugin failed: %w", err)
+	}
+	switch result := api.DecodeI32(results[0]); result {
+	case 0:
+		return false, nil
+	case 1:
+		return true, nil
+	default:
+		return false, fmt.Errorf("plugin returned %d", result)
+	}
+}
+
+// writeToPlugin copies data into memory the plugin allocates, returning its offset
+func writeToPlugin(ctx context.Context, module api.Module, data []byte) (uint32, error) {
+	results, err := module.ExportedFunction("alloc").Call(ctx, uint64(len(data)))
+	if err != nil {
+		return 0, fmt.Errorf("plugin failed to allocate memory: %w", err)
+	}
+	ptr := api.DecodeU32(results[0])
+	if !module.Memory().Write(ptr, data) {
+		return 0, fmt.Errorf("plugin allocated memory out of range")
+	}
+	return ptr, nil
+}
+
+// pluginLog is the log host function
+func pluginLog(ctx context.Context, module api.Module, ptr, length uint32) {
+	host, _ := ctx.Value(pluginHostKey{}).(*pluginHost)
+	message, ok := module.Memory().Read(ptr, length)
+	if host == nil || !ok {
+		return
+	}
+	host.logger.Info("plugin log", zap.String("plugin", host.plugin), zap.String("message", string(message)))
+}
+
+// pluginRPCCall is the rpc_call host function
+func pluginRPCCall(ctx context.Context, module api.Module, methodPtr, methodLen, paramsPtr, paramsLen uint32) uint64 {
+	host, _ := ctx.Value(pluginHostKey{}).(*pluginHost)
+	if host == nil {
+		return 0
+	}
+	method, ok := module.Memory().Read(methodPtr, methodLen)
+	if !ok || !pluginRPCMethods[string(method)] {
+		host.logger.Warn("plugin RPC method not allowed",
+			zap.String("plugin", host.plugin),
+			zap.ByteString("method", method))
+		return 0
+	}
+	rawParams, ok := module.Memory().Read(paramsPtr, paramsLen)
+	var params []interface{}
+	if !ok || json.Unmarshal(rawParams, &params) != nil {
+		host.logger.Warn("invalid plugin RPC params", zap.String("plugin", host.plugin), zap.String("method", string(method)))
+		return 0
+	}
+	if host.provider == nil {
+		host.logger.Warn("no blockchain provider configured", zap.String("plugin", host.plugin))
+		return 0
+	}
+
+	response, err := callProvider(ctx, host.provider, string(method), params)
+	if err != nil {
+		logRPCFailure(host.logger, err, "RPC call failed for plugin",
+			zap.String("plugin", host.plugin),
+			zap.String("method", string(method)))
+		return 0
+	}
+	ptr, err := writeToPlugin(ctx, module, response)
+	if err != nil {
+		host.logger.Warn("failed to return RPC response to plugin", zap.String("plugin", host.plugin), zap.Error(err))
+		return 0
+	}
+	return uint64(ptr)<<32 | uint64(len(response))
+}
@@ -0,0 +1,138 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"go.uber.org/zap"
+)
+
+// Plugin rule limits
+const (
+	DefaultPluginTimeout = 100 * time.Millisecond
+	MaxPluginTimeout     = 5 * time.Second
+)
+
+// PluginRule delegates the decision to a custom rule compiled to WebAssembly and
+// dropped into the plugins directory, so teams can add checks without forking
+// while the code stays sandboxed. The plugin receives the subject, the request and
+// the rule's params as JSON. A plugin that is not loaded, traps or runs past the
+// timeout fails the rule closed.
+type PluginRule struct {
+	Plugin  string          // Name of the plugin, its file name without .wasm
+	Params  json.RawMessage // JSON object passed to the plugin as is
+	Timeout time.Duration
+	// plugins and provider will be set by manager
+	plugins  *PluginRegistry
+	provider BlockchainProvider
+	logger   *zap.Logger
+}
+
+// pluginInput is the JSON document a plugin decides
+type pluginInput struct {
+	Address string          `json:"address"`
+	ChainID uint64          `json:"chain_id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Path    string          `json:"path,omitempty"`
+	Claims  *auth.Claims    `json:"claims,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// NewPluginRule creates a new plugin rule. A zero timeout uses DefaultPluginTimeout.
+func NewPluginRule(plugin string, params json.RawMessage, timeout time.Duration) *PluginRule {
+	logger, _ := zap.NewProduction()
+	if timeout == 0 {
+		timeout = DefaultPluginTimeout
+	}
+	return &PluginRule{
+		Plugin:  plugin,
+		Params:  params,
+		Timeout: timeout,
+		logger:  logger,
+	}
+}
+
+// Type returns the rule type
+func (r *PluginRule) Type() RuleType {
+	return PluginRuleType
+}
+
+// Validate checks if the rule parameters are valid
+func (r *PluginRule) Validate() error {
+	if !pluginNamePattern.MatchString(r.Plugin) {
+		return fmt.Errorf("invalid plugin name %q", r.Plugin)
+	}
+	if len(r.Params) > 0 {
+		var params map[string]json.RawMessage
+		if err := json.Unmarshal(r.Params, &params); err != nil {
+			return fmt.Errorf("params must be a JSON object")
+		}
+	}
+	if r.Timeout <= 0 || r.Timeout > MaxPluginTimeout {
+		return fmt.Errorf("timeout must be positive and at most %s", MaxPluginTimeout)
+	}
+	return nil
+}
+
+// Evaluate runs the plugin (requires plugins to be set)
+// This implementation follows fail-closed security: on any error, return false
+func (r *PluginRule) Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	if r.plugins == nil || !r.plugins.Has(r.Plugin) {
+		r.logger.Warn("plugin not loaded",
+			zap.String("rule", "Plugin"),
+			zap.String("plugin", r.Plugin))
+		return false, nil
+	}
+
+	input := pluginInput{
+		Address: strings.ToLower(address),
+		Claims:  claims,
+		Params:  r.Params,
+	}
+	if ec := EvaluationContextFromContext(ctx); ec != nil {
+		input.ChainID = ec.ChainID
+		input.Method = ec.Method
+		input.Path = ec.Path
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		r.logger.Error("failed to encode plugin input", zap.Error(err))
+		return false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+	allowed, err := r.plugins.evaluate(ctx, r.Plugin, data, &pluginHost{plugin: r.Plugin, provider: r.provider, logger: r.logger})
+	if err != nil {
+		r.logger.Error("plugin evaluation failed",
+			zap.String("plugin", r.Plugin),
+			zap.String("address", input.Address),
+			zap.Error(err))
+		return false, nil
+	}
+
+	r.logger.Info("plugin check completed",
+		zap.String("plugin", r.Plugin),
+		zap.String("address", input.Address),
+		zap.Bool("allowed", allowed))
+	return allowed, nil
+}
+
+// SetPlugins sets the registry of loaded plugins
+func (r *PluginRule) SetPlugins(plugins *PluginRegistry) {
+	r.plugins = plugins
+}
+
+// SetProvider sets the blockchain provider for the plugin's RPC calls
+func (r *PluginRule) SetProvider(provider BlockchainProvider) {
+	r.provider = provider
+}
+
+// SetLogger sets the logger for the rule
+func (r *PluginRule) SetLogger(logger *zap.Logger) {
+	r.logger = logger
+}
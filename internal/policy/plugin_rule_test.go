@@ -0,0 +1,231 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wasmSized prefixes contents with their size, which must be below 128 bytes
+func wasmSized(contents ...[]byte) []byte {
+	joined := []byte{}
+	for _, c := range contents {
+		joined = append(joined, c...)
+	}
+	return append([]byte{byte(len(joined))}, joined...)
+}
+
+// wasmName encodes a name of an import or export
+func wasmName(name string) []byte {
+	return wasmSized([]byte(name))
+}
+
+// testPluginModule is a hand-assembled plugin deciding by the first hex digit of
+// the address in its input, which starts with {"address":"0x:
+//
+//	'1' passes, 'f' spins forever, 'e' traps,
+//	'c' passes if rpc_call("eth_blockNumber", "[]") succeeds, anything else fails
+func testPluginModule() []byte {
+	const i32, i64 = 0x7f, 0x7e
+	module := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	sections := [][]byte{
+		// Types: log (i32 i32), rpc_call (i32 i32 i32 i32) i64, alloc (i32) i32, evaluate (i32 i32) i32
+		append([]byte{1}, wasmSized([]byte{4,
+			0x60, 2, i32, i32, 0,
+			0x60, 4, i32, i32, i32, i32, 1, i64,
+			0x60, 1, i32, 1, i32,
+			0x60, 2, i32, i32, 1, i32})...),
+		// Imports of the host functions
+		append([]byte{2}, wasmSized([]byte{2},
+			wasmName(pluginHostModule), wasmName("log"), []byte{0x00, 0},
+			wasmName(pluginHostModule), wasmName("rpc_call"), []byte{0x00, 1})...),
+		// Functions: alloc, evaluate
+		append([]byte{3}, wasmSized([]byte{2, 2, 3})...),
+		// One page of memory
+		append([]byte{5}, wasmSized([]byte{1, 0x00, 1})...),
+		// Mutable heap pointer starting at 1024
+		append([]byte{6}, wasmSized([]byte{1, i32, 1, 0x41, 0x80, 0x08, 0x0b})...),
+		// Exports
+		append([]byte{7}, wasmSized([]byte{3},
+			wasmName("memory"), []byte{0x02, 0},
+			wasmName("alloc"), []byte{0x00, 2},
+			wasmName("evaluate"), []byte{0x00, 3})...),
+		// Code
+		append([]byte{10}, wasmSized([]byte{2},
+			// alloc: return the heap pointer, advanced by size
+			wasmSized([]byte{0, 0x23, 0, 0x23, 0, 0x20, 0, 0x6a, 0x24, 0, 0x0b}),
+			// evaluate
+			wasmSized([]byte{1, 1, i32,
+				0x20, 0, 0x2d, 0, 14, 0x21, 2, // digit = load8_u(ptr + 14)
+				0x20, 2, 0x41, '1', 0x46, 0x04, 0x40, 0x41, 1, 0x0f, 0x0b, // '1': return 1
+				0x20, 2, 0x41, 0xe6, 0x00, 0x46, 0x04, 0x40, 0x03, 0x40, 0x0c, 0, 0x0b, 0x0b, // 'f': loop
+				0x20, 2, 0x41, 0xe5, 0x00, 0x46, 0x04, 0x40, 0x00, 0x0b, // 'e': unreachable
+				0x20, 2, 0x41, 0xe3, 0x00, 0x46, 0x04, 0x40, // 'c': rpc_call(16, 15, 32, 2) != 0
+				0x41, 16, 0x41, 15, 0x41, 32, 0x41, 2, 0x10, 1, 0x50,
+				0x04, i32, 0x41, 0, 0x05, 0x41, 1, 0x0b, 0x0f, 0x0b,
+				0x41, 0, 0x0b}))...),
+		// Data: the RPC method at 16 and its params at 32
+		append([]byte{11}, wasmSized([]byte{1, 0x00, 0x41, 16, 0x0b}, wasmSized([]byte("eth_blockNumber\x00[]")))...),
+	}
+	for _, section := range sections {
+		module = append(module, section...)
+	}
+	return module
+}
+
+// loadTestPlugins loads the test module as the "test" plugin
+func loadTestPlugins(t *testing.T) *PluginRegistry {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "test.wasm"), testPluginModule(), 0o600))
+	plugins, err := LoadPlugins(context.Background(), dir, 1)
+	require.NoError(t, err)
+	t.Cleanup(func() { plugins.Close(context.Background()) })
+	return plugins
+}
+
+// pluginProvider answers JSON-RPC calls, or fails them with err
+type pluginProvider struct {
+	err     error
+	methods []string
+}
+
+func (p *pluginProvider) Call(ctx context.Context, method string, params []interface{}) ([]byte, error) {
+	p.methods = append(p.methods, method)
+	if p.err != nil {
+		return nil, p.err
+	}
+	return []byte(`{"jsonrpc":"2.0","id":1,"result":"0x10"}`), nil
+}
+
+func (p *pluginProvider) HealthCheck(ctx context.Context) bool { return true }
+
+func TestLoadPlugins(t *testing.T) {
+	plugins := loadTestPlugins(t)
+	assert.Equal(t, []string{"test"}, plugins.Names())
+	assert.True(t, plugins.Has("test"))
+	assert.False(t, plugins.Has("kyc"))
+
+	t.Run("missing exports", func(t *testing.T) {
+		dir := t.TempDir()
+		empty := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "empty.wasm"), empty, 0o600))
+		_, err := LoadPlugins(context.Background(), dir, 0)
+		assert.ErrorContains(t, err, `missing exported function`)
+	})
+
+	t.Run("invalid module", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.wasm"), []byte("not wasm"), 0o600))
+		_, err := LoadPlugins(context.Background(), dir, 0)
+		assert.ErrorContains(t, err, "failed to compile plugin bad")
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "My Plugin.wasm"), testPluginModule(), 0o600))
+		_, err := LoadPlugins(context.Background(), dir, 0)
+		assert.ErrorContains(t, err, "invalid plugin name")
+	})
+
+	_, err := LoadPlugins(context.Background(), filepath.Join(t.TempDir(), "missing"), 0)
+	assert.Error(t, err)
+}
+
+func TestPluginRule_Evaluate(t *testing.T) {
+	rule := NewPluginRule("test", json.RawMessage(`{"min_score":10}`), 0)
+	require.NoError(t, rule.Validate())
+	rule.SetPlugins(loadTestPlugins(t))
+
+	allowed, err := rule.Evaluate(context.Background(), "0x1234567890123456789012345678901234567890", nil)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	// A trapping plugin fails closed
+	allowed, err = rule.Evaluate(context.Background(), "0xe234567890123456789012345678901234567890", nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+func TestPluginRule_Timeout(t *testing.T) {
+	rule := NewPluginRule("test", nil, 50*time.Millisecond)
+	rule.SetPlugins(loadTestPlugins(t))
+
+	start := time.Now()
+	allowed, err := rule.Evaluate(context.Background(), "0xf234567890123456789012345678901234567890", nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestPluginRule_RPCCall(t *testing.T) {
+	plugins := loadTestPlugins(t)
+	const address = "0xc234567890123456789012345678901234567890"
+
+	provider := &pluginProvider{}
+	rule := NewPluginRule("test", nil, time.Second)
+	rule.SetPlugins(plugins)
+	rule.SetProvider(provider)
+	allowed, err := rule.Evaluate(context.Background(), address, nil)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, []string{"eth_blockNumber"}, provider.methods)
+
+	// A failed call is reported to the plugin, which decides
+	rule.SetProvider(&pluginProvider{err: errors.New("connection refused")})
+	allowed, err = rule.Evaluate(context.Background(), address, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+func TestPluginRule_NotLoaded(t *testing.T) {
+	rule := NewPluginRule("kyc", nil, 0)
+	allowed, err := rule.Evaluate(context.Background(), "0x1234567890123456789012345678901234567890", nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	rule.SetPlugins(loadTestPlugins(t))
+	allowed, err = rule.Evaluate(context.Background(), "0x1234567890123456789012345678901234567890", nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+func TestLoader_PluginRule(t *testing.T) {
+	loader := NewPolicyLoader()
+	policies, err := loader.LoadFromJSON([]byte(`[{"path":"/api/data","method":"GET","logic":"AND","rules":[
+		{"type":"plugin","plugin":"kyc","params":{"level":2},"timeout":"250ms"}]}]`))
+	require.NoError(t, err)
+	rule, ok := policies[0].Rules[0].(*PluginRule)
+	require.True(t, ok)
+	assert.Equal(t, "kyc", rule.Plugin)
+	assert.JSONEq(t, `{"level":2}`, string(rule.Params))
+	assert.Equal(t, 250*time.Millisecond, rule.Timeout)
+
+	// The policy round-trips through its JSON form
+	data, err := json.Marshal(policies[0])
+	require.NoError(t, err)
+	reloaded, err := loader.ParsePolicy(data)
+	require.NoError(t, err)
+	assert.Equal(t, rule.Params, reloaded.Rules[0].(*PluginRule).Params)
+
+	for _, invalid := range []string{
+		`{"type":"plugin"}`,
+		`{"type":"plugin","plugin":"../kyc"}`,
+		`{"type":"plugin","plugin":"kyc","params":[1]}`,
+		`{"type":"plugin","plugin":"kyc","timeout":"1m"}`,
+	} {
+		_, err := loader.LoadFromJSON([]byte(`[{"path":"/api/data","method":"GET","logic":"AND","rules":[` + invalid + `]}]`))
+		assert.Error(t, err, invalid)
+	}
+}
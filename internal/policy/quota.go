@@ -0,0 +1,132 @@
+package policy
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// QuotaStore keeps the counters of quota rules. Implementations must reserve
+// atomically so concurrent requests cannot exceed the limit.
+type QuotaStore interface {
+	// Reserve increments the counter at key if it is below limit and reports whether
+	// it did. A new counter expires after ttl.
+	Reserve(ctx context.Context, key string, limit int64, ttl time.Duration) (bool, error)
+	// Release decrements the counter at key, returning a reservation
+	Release(ctx context.Context, key string) error
+}
+
+// quotaCounter is a counter of MemoryQuotaStore
+type quotaCounter struct {
+	count     int64
+	expiresAt time.Time
+}
+
+// MemoryQuotaStore is an in-process QuotaStore. Counters are lost on restart and
+// not shared between instances; use a shared store when running several.
+type MemoryQuotaStore struct {
+	mu       sync.Mutex
+	counters map[string]*quotaCounter
+}
+
+// NewMemoryQuotaStore creates an empty in-memory quota store
+func NewMemoryQuotaStore() *MemoryQuotaStore {
+	return &MemoryQuotaStore{counters: make(map[string]*quotaCounter)}
+}
+
+// Reserve increments the counter at key if it is below limit
+func (s *MemoryQuotaStore) Reserve(ctx context.Context, key string, limit int64, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	counter, ok := s.counters[key]
+	if !ok || now.After(counter.expiresAt) {
+		s.evictExpired(now)
+		counter = &quotaCounter{expiresAt: now.Add(ttl)}
+		s.counters[key] = counter
+	}
+
+	if counter.count >= limit {
+		return false, nil
+	}
+	counter.count++
+	return true, nil
+}
+
+// Release decrements the counter at key
+func (s *MemoryQuotaStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if counter, ok := s.counters[key]; ok && counter.count > 0 {
+		counter.count--
+	}
+	return nil
+}
+
+// evictExpired removes expired counters (caller must hold the lock)
+func (s *MemoryQuotaStore) evictExpired(now time.Time) {
+	for key, counter := range s.counters {
+		if now.After(counter.expiresAt) {
+			delete(s.counters, key)
+		}
+	}
+}
+
+// quotaReservation is a counter incremented while evaluating a request
+type quotaReservation struct {
+	store QuotaStore
+	key   string
+}
+
+// QuotaReservations collects the quota reserved while evaluating a request, so it
+// can be returned if the request is not granted or does not succeed
+type QuotaReservations struct {
+	mu           sync.Mutex
+	reservations []quotaReservation
+}
+
+// quotaReservationsContextKey carries the QuotaReservations of a request
+type quotaReservationsContextKey struct{}
+
+// WithQuotaReservations returns a context in which quota rules record their
+// reservations, and the collection they are recorded in
+func WithQuotaReservations(ctx context.Context) (context.Context, *QuotaReservations) {
+	reservations := &QuotaReservations{}
+	return context.WithValue(ctx, quotaReservationsContextKey{}, reservations), reservations
+}
+
+// recordQuotaReservation records a reservation in the context, if it collects them
+func recordQuotaReservation(ctx context.Context, store QuotaStore, key string) {
+	reservations, ok := ctx.Value(quotaReservationsContextKey{}).(*QuotaReservations)
+	if !ok {
+		return
+	}
+	reservations.mu.Lock()
+	defer reservations.mu.Unlock()
+	reservations.reservations = append(reservations.reservations, quotaReservation{store: store, key: key})
+}
+
+// Release returns all recorded reservations. Safe to call more than once.
+func (q *QuotaReservations) Release(ctx context.Context) error {
+	q.mu.Lock()
+	reservations := q.reservations
+	q.reservations = nil
+	q.mu.Unlock()
+
+	var firstErr error
+	for _, reservation := range reservations {
+		if err := reservation.store.Release(ctx, reservation.key); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Len returns the number of recorded reservations
+func (q *QuotaReservations) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.reservations)
+}
@@ -0,0 +1,117 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"go.uber.org/zap"
+)
+
+// QuotaRule allows each address a limited number of granted requests per fixed
+// window, e.g. "3 free claims per wallet per day". Unlike rate limiting, quota is
+// only consumed by requests that are granted and succeed: the policy middleware
+// returns the reservation when a request is denied or its handler fails.
+type QuotaRule struct {
+	Name   string // Counter name; rules with the same name share a quota
+	Limit  int64
+	Window time.Duration
+	// store will be set by manager
+	store  QuotaStore
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewQuotaRule creates a new quota rule
+func NewQuotaRule(name string, limit int64, window time.Duration) *QuotaRule {
+	logger, _ := zap.NewProduction()
+	return &QuotaRule{
+		Name:   name,
+		Limit:  limit,
+		Window: window,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Type returns the rule type
+func (r *QuotaRule) Type() RuleType {
+	return QuotaRuleType
+}
+
+// Validate checks if the rule parameters are valid
+func (r *QuotaRule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("quota name cannot be empty")
+	}
+	if r.Limit <= 0 {
+		return fmt.Errorf("limit must be positive")
+	}
+	if r.Window < time.Second {
+		return fmt.Errorf("window must be at least one second")
+	}
+	return nil
+}
+
+// Evaluate reserves one request of the caller's quota for the current window
+// This implementation follows fail-closed security: on any error, return false
+func (r *QuotaRule) Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	if r.store == nil {
+		r.logger.Warn("no quota store configured",
+			zap.String("rule", "Quota"))
+		return false, nil
+	}
+
+	subject := strings.ToLower(address)
+	if subject == "" && claims != nil {
+		subject = claims.Identity()
+	}
+	if subject == "" {
+		return false, nil
+	}
+
+	key, resetAt := r.windowKey(subject)
+	reserved, err := r.store.Reserve(ctx, key, r.Limit, resetAt.Sub(r.now()))
+	if err != nil {
+		r.logger.Error("quota reservation failed",
+			zap.Error(err),
+			zap.String("quota", r.Name),
+			zap.String("subject", subject))
+		return false, nil
+	}
+	if reserved {
+		recordQuotaReservation(ctx, r.store, key)
+	}
+
+	r.logger.Info("quota check completed",
+		zap.String("quota", r.Name),
+		zap.String("subject", subject),
+		zap.Int64("limit", r.Limit),
+		zap.Time("resetAt", resetAt),
+		zap.Bool("reserved", reserved))
+
+	return reserved, nil
+}
+
+// windowKey returns the counter key of subject for the current window and when
+// the window ends. Windows are aligned to the Unix epoch, so a one-day window
+// resets at midnight UTC.
+func (r *QuotaRule) windowKey(subject string) (string, time.Time) {
+	windowSeconds := int64(r.Window / time.Second)
+	start := r.now().Unix() / windowSeconds * windowSeconds
+	key := "quota:" + r.Name + ":" + subject + ":" + strconv.FormatInt(start, 10)
+	return key, time.Unix(start+windowSeconds, 0)
+}
+
+// SetStore sets the store holding quota counters
+func (r *QuotaRule) SetStore(store QuotaStore) {
+	r.store = store
+}
+
+// SetLogger sets the logger for the rule
+func (r *QuotaRule) SetLogger(logger *zap.Logger) {
+	r.logger = logger
+}
@@ -0,0 +1,122 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/auth"
+)
+
+// failingQuotaStore fails every reservation
+type failingQuotaStore struct{}
+
+func (failingQuotaStore) Reserve(ctx context.Context, key string, limit int64, ttl time.Duration) (bool, error) {
+	return false, fmt.Errorf("store unavailable")
+}
+
+func (failingQuotaStore) Release(ctx context.Context, key string) error {
+	return nil
+}
+
+// TestQuotaRule_Validate validates rule parameters
+func TestQuotaRule_Validate(t *testing.T) {
+	assert.NoError(t, NewQuotaRule("free-claims", 3, 24*time.Hour).Validate())
+	assert.Error(t, NewQuotaRule("", 3, 24*time.Hour).Validate())
+	assert.Error(t, NewQuotaRule("free-claims", 0, 24*time.Hour).Validate())
+	assert.Error(t, NewQuotaRule("free-claims", 3, time.Millisecond).Validate())
+}
+
+// TestQuotaRule_Evaluate allows the limit per address and window
+func TestQuotaRule_Evaluate(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	rule := NewQuotaRule("free-claims", 2, 24*time.Hour)
+	rule.SetStore(NewMemoryQuotaStore())
+	rule.now = func() time.Time { return now }
+
+	for _, expected := range []bool{true, true, false} {
+		allowed, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+		require.NoError(t, err)
+		assert.Equal(t, expected, allowed)
+	}
+
+	// Other addresses have their own quota
+	allowed, err := rule.Evaluate(context.Background(), testUserAddr2, nil)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	// The next window starts at midnight UTC
+	now = time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	allowed, err = rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+// TestQuotaRule_SharedName shares the counter between rules with the same name
+func TestQuotaRule_SharedName(t *testing.T) {
+	store := NewMemoryQuotaStore()
+	first := NewQuotaRule("free-claims", 1, time.Hour)
+	first.SetStore(store)
+	second := NewQuotaRule("free-claims", 1, time.Hour)
+	second.SetStore(store)
+
+	allowed, err := first.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = second.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+// TestQuotaRule_Reservations records reservations so they can be released
+func TestQuotaRule_Reservations(t *testing.T) {
+	rule := NewQuotaRule("free-claims", 1, time.Hour)
+	rule.SetStore(NewMemoryQuotaStore())
+
+	ctx, reservations := WithQuotaReservations(context.Background())
+	allowed, err := rule.Evaluate(ctx, testUserAddr, nil)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, 1, reservations.Len())
+
+	require.NoError(t, reservations.Release(context.Background()))
+	assert.Equal(t, 0, reservations.Len())
+
+	// The released request no longer counts
+	allowed, err = rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+// TestQuotaRule_ServiceAccount counts service accounts by subject
+func TestQuotaRule_ServiceAccount(t *testing.T) {
+	rule := NewQuotaRule("free-claims", 1, time.Hour)
+	rule.SetStore(NewMemoryQuotaStore())
+	claims := &auth.Claims{}
+	claims.Subject = "svc:42"
+
+	allowed, err := rule.Evaluate(context.Background(), "", claims)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = rule.Evaluate(context.Background(), "", claims)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+// TestQuotaRule_FailClosed denies without a store or when the store fails
+func TestQuotaRule_FailClosed(t *testing.T) {
+	rule := NewQuotaRule("free-claims", 1, time.Hour)
+	allowed, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	rule.SetStore(failingQuotaStore{})
+	allowed, err = rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
@@ -0,0 +1,233 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/yourusername/gatekeeper/internal/auth"
+)
+
+// requestContextKey carries the HTTP request being authorized to request rules
+type requestContextKey struct{}
+
+// WithRequest returns a context carrying the request for request rules to inspect.
+// The policy middleware calls this before evaluating policies.
+func WithRequest(ctx context.Context, r *http.Request) context.Context {
+	return context.WithValue(ctx, requestContextKey{}, r)
+}
+
+// RequestFromContext returns the request set by WithRequest, or nil
+func RequestFromContext(ctx context.Context) *http.Request {
+	r, _ := ctx.Value(requestContextKey{}).(*http.Request)
+	return r
+}
+
+// clientIPContextKey carries the resolved client IP to geo restriction rules
+type clientIPContextKey struct{}
+
+// WithClientIP returns a context carrying the client IP address, as resolved by the
+// HTTP layer from the connection or trusted forwarding headers
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPContextKey{}, ip)
+}
+
+// ClientIPFromContext returns the client IP of the evaluation context, or of
+// WithClientIP and WithRequest without one; "" if none is available.
+func ClientIPFromContext(ctx context.Context) string {
+	if ec := EvaluationContextFromContext(ctx); ec != nil {
+		return ec.ClientIP
+	}
+	if ip, ok := ctx.Value(clientIPContextKey{}).(string); ok {
+		return ip
+	}
+	return ""
+}
+
+// HeaderEqualsRule checks if a request header has a specific value
+type HeaderEqualsRule struct {
+	Header string
+	Value  string
+}
+
+// NewHeaderEqualsRule creates a new header rule
+func NewHeaderEqualsRule(header, value string) *HeaderEqualsRule {
+	return &HeaderEqualsRule{Header: header, Value: value}
+}
+
+// Type returns the rule type
+func (r *HeaderEqualsRule) Type() RuleType {
+	return HeaderEqualsRuleType
+}
+
+// Evaluate checks if any value of the header equals the expected value (exact match).
+// Evaluates to false when no request is available. Credential headers are never visible.
+func (r *HeaderEqualsRule) Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	ec := EvaluationContextFromContext(ctx)
+	if ec == nil {
+		return false, nil
+	}
+
+	for _, value := range ec.Headers.Values(r.Header) {
+		if value == r.Value {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// HeaderMatchesRule checks if a request header matches a regular expression
+type HeaderMatchesRule struct {
+	Header  string
+	Pattern string // Must match a whole value
+	re      *regexp.Regexp
+}
+
+// NewHeaderMatchesRule creates a new header pattern rule
+func NewHeaderMatchesRule(header, pattern string) *HeaderMatchesRule {
+	re, _ := compileWholeMatch(pattern)
+	return &HeaderMatchesRule{Header: header, Pattern: pattern, re: re}
+}
+
+// Type returns the rule type
+func (r *HeaderMatchesRule) Type() RuleType {
+	return HeaderMatchesRuleType
+}
+
+// Validate checks if the rule parameters are valid
+func (r *HeaderMatchesRule) Validate() error {
+	if r.Header == "" {
+		return fmt.Errorf("header cannot be empty")
+	}
+	_, err := compileWholeMatch(r.Pattern)
+	return err
+}
+
+// Evaluate checks if any value of the header matches the pattern as a whole.
+// Evaluates to false when no request is available. Credential headers are never visible.
+func (r *HeaderMatchesRule) Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	ec := EvaluationContextFromContext(ctx)
+	if ec == nil || r.re == nil {
+		return false, nil
+	}
+
+	return matchesAny(r.re, ec.Headers.Values(r.Header)), nil
+}
+
+// QueryParamPresentRule checks if a query parameter is present in the request URL
+type QueryParamPresentRule struct {
+	Param string
+}
+
+// NewQueryParamPresentRule creates a new query parameter rule
+func NewQueryParamPresentRule(param string) *QueryParamPresentRule {
+	return &QueryParamPresentRule{Param: param}
+}
+
+// Type returns the rule type
+func (r *QueryParamPresentRule) Type() RuleType {
+	return QueryParamPresentRuleType
+}
+
+// Evaluate checks if the query parameter is present, even with an empty value.
+// Evaluates to false when no request is available.
+func (r *QueryParamPresentRule) Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	ec := EvaluationContextFromContext(ctx)
+	if ec == nil {
+		return false, nil
+	}
+
+	return ec.Query.Has(r.Param), nil
+}
+
+// QueryParamMatchesRule checks if a query parameter matches a regular expression,
+// e.g. chain=137 when combined with a Polygon NFT rule
+type QueryParamMatchesRule struct {
+	Param   string
+	Pattern string // Must match a whole value
+	re      *regexp.Regexp
+}
+
+// NewQueryParamMatchesRule creates a new query parameter pattern rule
+func NewQueryParamMatchesRule(param, pattern string) *QueryParamMatchesRule {
+	re, _ := compileWholeMatch(pattern)
+	return &QueryParamMatchesRule{Param: param, Pattern: pattern, re: re}
+}
+
+// Type returns the rule type
+func (r *QueryParamMatchesRule) Type() RuleType {
+	return QueryParamMatchesRuleType
+}
+
+// Validate checks if the rule parameters are valid
+func (r *QueryParamMatchesRule) Validate() error {
+	if r.Param == "" {
+		return fmt.Errorf("param cannot be empty")
+	}
+	_, err := compileWholeMatch(r.Pattern)
+	return err
+}
+
+// Evaluate checks if any value of the query parameter matches the pattern as a
+// whole; an absent parameter never matches. Evaluates to false when no request is available.
+func (r *QueryParamMatchesRule) Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	ec := EvaluationContextFromContext(ctx)
+	if ec == nil || r.re == nil {
+		return false, nil
+	}
+
+	return matchesAny(r.re, ec.Query[r.Param]), nil
+}
+
+// compileWholeMatch compiles a pattern that must match a whole value, so "137"
+// does not match "1370"
+func compileWholeMatch(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(`^(?:` + pattern + `)$`)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	return re, nil
+}
+
+// matchesAny reports whether any of values matches re
+func matchesAny(re *regexp.Regexp, values []string) bool {
+	for _, value := range values {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// HTTPMethodRule checks if the request uses one of a set of HTTP methods
+type HTTPMethodRule struct {
+	Methods []string
+}
+
+// NewHTTPMethodRule creates a new HTTP method rule
+func NewHTTPMethodRule(methods []string) *HTTPMethodRule {
+	return &HTTPMethodRule{Methods: methods}
+}
+
+// Type returns the rule type
+func (r *HTTPMethodRule) Type() RuleType {
+	return HTTPMethodRuleType
+}
+
+// Evaluate checks if the request method is in the list (case-insensitive).
+// Evaluates to false when no request is available.
+func (r *HTTPMethodRule) Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	ec := EvaluationContextFromContext(ctx)
+	if ec == nil {
+		return false, nil
+	}
+
+	for _, method := range r.Methods {
+		if strings.EqualFold(method, ec.Method) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,167 @@
+package policy
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHeaderEqualsRule matches any value of the header exactly
+func TestHeaderEqualsRule(t *testing.T) {
+	rule := NewHeaderEqualsRule("X-Client", "mobile")
+
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.Header.Add("x-client", "web")
+	req.Header.Add("x-client", "mobile")
+	allowed, err := rule.Evaluate(WithRequest(context.Background(), req), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	req = httptest.NewRequest("GET", "/api/data", nil)
+	req.Header.Set("X-Client", "Mobile")
+	allowed, err = rule.Evaluate(WithRequest(context.Background(), req), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+// TestQueryParamPresentRule matches present parameters, even when empty
+func TestQueryParamPresentRule(t *testing.T) {
+	rule := NewQueryParamPresentRule("preview")
+
+	for target, expected := range map[string]bool{
+		"/api/data?preview=1": true,
+		"/api/data?preview":   true,
+		"/api/data?other=1":   false,
+		"/api/data":           false,
+	} {
+		req := httptest.NewRequest("GET", target, nil)
+		allowed, err := rule.Evaluate(WithRequest(context.Background(), req), testUserAddr, nil)
+		require.NoError(t, err)
+		assert.Equal(t, expected, allowed, target)
+	}
+}
+
+// TestHeaderMatchesRule matches any value of the header against the whole pattern
+func TestHeaderMatchesRule(t *testing.T) {
+	rule := NewHeaderMatchesRule("User-Agent", "Wallet/[0-9]+")
+	require.NoError(t, rule.Validate())
+
+	for agent, expected := range map[string]bool{
+		"Wallet/12":     true,
+		"Wallet/beta":   false,
+		"MyWallet/12":   false,
+		"Wallet/12 iOS": false,
+	} {
+		req := httptest.NewRequest("GET", "/api/data", nil)
+		req.Header.Set("User-Agent", agent)
+		allowed, err := rule.Evaluate(WithRequest(context.Background(), req), testUserAddr, nil)
+		require.NoError(t, err)
+		assert.Equal(t, expected, allowed, agent)
+	}
+
+	assert.ErrorContains(t, NewHeaderMatchesRule("User-Agent", "Wallet/(").Validate(), "invalid pattern")
+}
+
+// TestQueryParamMatchesRule matches any value of the parameter against the whole pattern
+func TestQueryParamMatchesRule(t *testing.T) {
+	rule := NewQueryParamMatchesRule("chain", "137")
+
+	for target, expected := range map[string]bool{
+		"/api/data?chain=137":         true,
+		"/api/data?chain=1&chain=137": true,
+		"/api/data?chain=1370":        false,
+		"/api/data?chain=":            false,
+		"/api/data":                   false,
+	} {
+		req := httptest.NewRequest("GET", target, nil)
+		allowed, err := rule.Evaluate(WithRequest(context.Background(), req), testUserAddr, nil)
+		require.NoError(t, err)
+		assert.Equal(t, expected, allowed, target)
+	}
+}
+
+// TestHTTPMethodRule matches methods case-insensitively
+func TestHTTPMethodRule(t *testing.T) {
+	rule := NewHTTPMethodRule([]string{"get", "HEAD"})
+
+	for method, expected := range map[string]bool{"GET": true, "HEAD": true, "POST": false} {
+		req := httptest.NewRequest(method, "/api/data", nil)
+		allowed, err := rule.Evaluate(WithRequest(context.Background(), req), testUserAddr, nil)
+		require.NoError(t, err)
+		assert.Equal(t, expected, allowed, method)
+	}
+}
+
+// TestRequestRules_NoRequest evaluates to false without a request in the context
+func TestRequestRules_NoRequest(t *testing.T) {
+	rules := []Rule{
+		NewHeaderEqualsRule("X-Client", ""),
+		NewHeaderMatchesRule("X-Client", ".*"),
+		NewQueryParamPresentRule("preview"),
+		NewQueryParamMatchesRule("preview", ".*"),
+		NewHTTPMethodRule([]string{"GET"}),
+	}
+
+	for _, rule := range rules {
+		allowed, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+		require.NoError(t, err)
+		assert.False(t, allowed, string(rule.Type()))
+	}
+}
+
+// TestRequestRules_CombinedWithScope combines request shaping with other rules
+func TestRequestRules_CombinedWithScope(t *testing.T) {
+	loader := NewPolicyLoader()
+	policies, err := loader.LoadFromJSON([]byte(`[
+		{
+			"path": "/api/data",
+			"method": "GET",
+			"logic": "OR",
+			"rules": [
+				{"type": "has_scope", "scope": "admin"},
+				{"type": "header_equals", "header": "X-Internal", "value": "true"}
+			]
+		}
+	]`))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	allowed, err := policies[0].Evaluate(WithRequest(context.Background(), req), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	req.Header.Set("X-Internal", "true")
+	allowed, err = policies[0].Evaluate(WithRequest(context.Background(), req), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+// TestRequestRules_CombinedWithOnChain allows chain=137 only to holders of a Polygon NFT
+func TestRequestRules_CombinedWithOnChain(t *testing.T) {
+	loader := NewPolicyLoader()
+	p, err := loader.ParsePolicy([]byte(`{
+		"path": "/api/data",
+		"method": "GET",
+		"logic": "OR",
+		"rules": [
+			{"type": "not", "rule": {"type": "query_param_matches", "param": "chain", "pattern": "137"}},
+			{"type": "erc721_min_balance", "contract_address": "0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D", "minimum_balance": "1", "chain_id": 137}
+		]
+	}`))
+	require.NoError(t, err)
+	NewPolicyManager(&MockBlockchainProvider{}, nil).AddPolicy(p)
+
+	for target, expected := range map[string]bool{
+		"/api/data?chain=1":   true,
+		"/api/data":           true,
+		"/api/data?chain=137": false,
+	} {
+		req := httptest.NewRequest("GET", target, nil)
+		allowed, err := p.Evaluate(WithRequest(context.Background(), req), testUserAddr, nil)
+		require.NoError(t, err)
+		assert.Equal(t, expected, allowed, target)
+	}
+}
@@ -0,0 +1,158 @@
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Policy paths are route patterns. Besides exact paths they may contain
+// parameters and a trailing wildcard:
+//
+//	/api/items          only /api/items
+//	/api/items/{id}     /api/items/42, but not /api/items or /api/items/42/tags
+//	/api/items/*        everything below /api/items: /api/items/42, /api/items/42/tags
+//
+// When policies with different patterns match a request, only the most specific
+// apply: patterns are compared segment by segment from the left, and at the first
+// difference a literal segment beats a parameter, which beats the wildcard. So a
+// policy on /api/items/{id} overrides one on /api/items/*, and a policy on
+// /api/items/special overrides both. Policies with equally specific patterns all
+// apply, as policies on the same exact path always have. Precedence is decided
+// separately for enforced and shadow policies, so adding a shadow policy never
+// changes what is enforced.
+
+// Segment kinds, ordered from least to most specific
+const (
+	segmentWildcard = iota
+	segmentParam
+	segmentLiteral
+)
+
+// routeParamName is the syntax of a parameter name
+var routeParamName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidateRoutePattern checks the syntax of a policy path: parameters must span a
+// whole segment and the wildcard may only be the last segment
+func ValidateRoutePattern(pattern string) error {
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		switch {
+		case segment == "*":
+			if i != len(segments)-1 {
+				return fmt.Errorf("wildcard must be the last segment of %q", pattern)
+			}
+		case strings.Contains(segment, "*"):
+			return fmt.Errorf("wildcard must be a whole segment in %q", pattern)
+		case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"):
+			if !routeParamName.MatchString(segment[1 : len(segment)-1]) {
+				return fmt.Errorf("invalid parameter %s in %q", segment, pattern)
+			}
+		case strings.ContainsAny(segment, "{}"):
+			return fmt.Errorf("parameter must be a whole segment in %q", pattern)
+		}
+	}
+	return nil
+}
+
+// isRoutePattern reports whether a path has parameters or a wildcard
+func isRoutePattern(pattern string) bool {
+	return strings.ContainsAny(pattern, "{*")
+}
+
+// segmentKind returns the kind of a pattern segment
+func segmentKind(segment string) int {
+	switch {
+	case segment == "*":
+		return segmentWildcard
+	case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"):
+		return segmentParam
+	default:
+		return segmentLiteral
+	}
+}
+
+// matchRoute reports whether path matches the route pattern
+func matchRoute(pattern, path string) bool {
+	if !isRoutePattern(pattern) {
+		return pattern == path
+	}
+
+	patternSegments := strings.Split(pattern, "/")
+	pathSegments := strings.Split(path, "/")
+	for i, segment := range patternSegments {
+		if i >= len(pathSegments) {
+			return false
+		}
+		switch segmentKind(segment) {
+		case segmentWildcard:
+			// Matches the rest of the path, which must not be empty
+			return i < len(pathSegments)-1 || pathSegments[i] != ""
+		case segmentParam:
+			if pathSegments[i] == "" {
+				return false
+			}
+		default:
+			if pathSegments[i] != segment {
+				return false
+			}
+		}
+	}
+	return len(patternSegments) == len(pathSegments)
+}
+
+// RouteParams returns the values of the parameters of a route pattern in a path it
+// matches, as the router would set them for the request
+func RouteParams(pattern, path string) map[string]string {
+	params := map[string]string{}
+	if !matchRoute(pattern, path) {
+		return params
+	}
+
+	pathSegments := strings.Split(path, "/")
+	for i, segment := range strings.Split(pattern, "/") {
+		if segmentKind(segment) == segmentParam {
+			params[segment[1:len(segment)-1]] = pathSegments[i]
+		}
+	}
+	return params
+}
+
+// compareRouteSpecificity returns a positive number if pattern a is more specific
+// than b, a negative number if it is less specific and 0 if they are equally specific
+func compareRouteSpecificity(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	aSegments := strings.Split(a, "/")
+	bSegments := strings.Split(b, "/")
+	for i := 0; i < len(aSegments) && i < len(bSegments); i++ {
+		if diff := segmentKind(aSegments[i]) - segmentKind(bSegments[i]); diff != 0 {
+			return diff
+		}
+	}
+	return len(aSegments) - len(bSegments)
+}
+
+// mostSpecificRoutes keeps the policies whose path is the most specific, in order
+func mostSpecificRoutes(policies []*Policy) []*Policy {
+	if len(policies) < 2 {
+		return policies
+	}
+
+	best := policies[0].Path
+	for _, p := range policies[1:] {
+		if compareRouteSpecificity(p.Path, best) > 0 {
+			best = p.Path
+		}
+	}
+
+	selected := make([]*Policy, 0, len(policies))
+	for _, p := range policies {
+		if compareRouteSpecificity(p.Path, best) == 0 {
+			selected = append(selected, p)
+		}
+	}
+	return selected
+}
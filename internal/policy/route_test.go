@@ -0,0 +1,104 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchRoute(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{"/api/items", "/api/items", true},
+		{"/api/items", "/api/items/1", false},
+		{"/api/items/{id}", "/api/items/42", true},
+		{"/api/items/{id}", "/api/items", false},
+		{"/api/items/{id}", "/api/items/", false},
+		{"/api/items/{id}", "/api/items/42/tags", false},
+		{"/api/items/{id}/tags", "/api/items/42/tags", true},
+		{"/api/items/*", "/api/items/42", true},
+		{"/api/items/*", "/api/items/42/tags", true},
+		{"/api/items/*", "/api/items", false},
+		{"/api/items/*", "/api/items/", false},
+		{"/api/items/*", "/api/other/42", false},
+		{"/api/{kind}/*", "/api/items/42/tags", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.expected, matchRoute(tt.pattern, tt.path))
+		})
+	}
+}
+
+func TestRouteParams(t *testing.T) {
+	assert.Equal(t, map[string]string{"kind": "items", "id": "42"}, RouteParams("/api/{kind}/{id}/*", "/api/items/42/tags"))
+	assert.Empty(t, RouteParams("/api/items", "/api/items"))
+	assert.Empty(t, RouteParams("/api/items/{id}", "/api/other/42"))
+}
+
+func TestValidateRoutePattern(t *testing.T) {
+	for _, valid := range []string{"/api/items", "/api/items/{id}", "/api/items/*", "/api/{kind}/{id}/*"} {
+		assert.NoError(t, ValidateRoutePattern(valid), valid)
+	}
+	for _, invalid := range []string{"/api/*/items", "/api/items*", "/api/{id", "/api/x{id}", "/api/{}", "/api/{1d}"} {
+		assert.Error(t, ValidateRoutePattern(invalid), invalid)
+	}
+}
+
+func TestCompareRouteSpecificity(t *testing.T) {
+	assert.Positive(t, compareRouteSpecificity("/api/items/special", "/api/items/{id}"))
+	assert.Positive(t, compareRouteSpecificity("/api/items/{id}", "/api/items/*"))
+	assert.Positive(t, compareRouteSpecificity("/api/items/*", "/api/*"))
+	assert.Positive(t, compareRouteSpecificity("/api/items/{id}", "/api/{kind}/{id}"))
+	assert.Negative(t, compareRouteSpecificity("/api/*", "/api/items/42"))
+	assert.Zero(t, compareRouteSpecificity("/api/items/{id}", "/api/items/{name}"))
+}
+
+// TestManager_GetPoliciesForRoute_Patterns applies the most specific matching policies
+func TestManager_GetPoliciesForRoute_Patterns(t *testing.T) {
+	pm := NewPolicyManager(nil, nil)
+	subtree := NewPolicy("GET", "/api/items/*", "AND", []Rule{NewHasScopeRule("items")})
+	byID := NewPolicy("GET", "/api/items/{id}", "AND", []Rule{NewHasScopeRule("item")})
+	byName := NewPolicy("GET", "/api/items/{name}", "AND", []Rule{NewHasScopeRule("named")})
+	special := NewPolicy("GET", "/api/items/special", "AND", []Rule{NewHasScopeRule("special")})
+	for _, p := range []*Policy{subtree, byID, byName, special} {
+		pm.AddPolicy(p)
+	}
+
+	assert.Equal(t, []*Policy{subtree}, pm.GetPoliciesForRoute("/api/items/42/tags", "GET"))
+	assert.Equal(t, []*Policy{byID, byName}, pm.GetPoliciesForRoute("/api/items/42", "GET"))
+	assert.Equal(t, []*Policy{special}, pm.GetPoliciesForRoute("/api/items/special", "GET"))
+	assert.Empty(t, pm.GetPoliciesForRoute("/api/items", "GET"))
+	assert.Empty(t, pm.GetPoliciesForRoute("/api/items/42", "POST"))
+}
+
+// TestManager_GetPoliciesForRoute_ShadowPrecedence keeps enforcement independent of shadow policies
+func TestManager_GetPoliciesForRoute_ShadowPrecedence(t *testing.T) {
+	pm := NewPolicyManager(nil, nil)
+	enforced := NewPolicy("GET", "/api/items/*", "AND", []Rule{NewHasScopeRule("items")})
+	shadowSubtree := NewPolicy("GET", "/api/*", "AND", []Rule{NewHasScopeRule("api")})
+	shadowSubtree.Shadow = true
+	shadowExact := NewPolicy("GET", "/api/items/42", "AND", []Rule{NewHasScopeRule("item")})
+	shadowExact.Shadow = true
+	pm.AddPolicy(enforced)
+	pm.AddPolicy(shadowSubtree)
+	pm.AddPolicy(shadowExact)
+
+	assert.Equal(t, []*Policy{enforced, shadowExact}, pm.GetPoliciesForRoute("/api/items/42", "GET"))
+	assert.Equal(t, []*Policy{enforced, shadowSubtree}, pm.GetPoliciesForRoute("/api/items/7", "GET"))
+}
+
+func TestLoader_InvalidRoutePattern(t *testing.T) {
+	_, err := NewPolicyLoader().ParsePolicy([]byte(`{"path": "/api/*/items", "method": "GET", "logic": "AND", "rules": [{"type": "has_scope", "scope": "read"}]}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid path")
+
+	policy, err := NewPolicyLoader().ParsePolicy([]byte(`{"path": "/api/items/{id}", "method": "GET", "logic": "AND", "rules": [{"type": "has_scope", "scope": "read"}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, "/api/items/{id}", policy.Path)
+}
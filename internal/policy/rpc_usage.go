@@ -0,0 +1,93 @@
+package policy
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RPCUsage counts the RPC calls made while evaluating a policy, by JSON-RPC method,
+// and the time spent waiting for them
+type RPCUsage struct {
+	mu       sync.Mutex
+	calls    map[string]int64
+	duration time.Duration
+}
+
+// rpcUsageContextKey carries the RPCUsage of an evaluation
+type rpcUsageContextKey struct{}
+
+// WithRPCUsage returns a context in which calls through a MeteredProvider are
+// counted, and the usage they are counted in
+func WithRPCUsage(ctx context.Context) (context.Context, *RPCUsage) {
+	usage := &RPCUsage{calls: make(map[string]int64)}
+	return context.WithValue(ctx, rpcUsageContextKey{}, usage), usage
+}
+
+// recordRPCCall counts a call that took elapsed in the context, if it counts them
+func recordRPCCall(ctx context.Context, method string, elapsed time.Duration) {
+	usage, ok := ctx.Value(rpcUsageContextKey{}).(*RPCUsage)
+	if !ok {
+		return
+	}
+	usage.mu.Lock()
+	defer usage.mu.Unlock()
+	usage.calls[method]++
+	usage.duration += elapsed
+}
+
+// Calls returns the number of calls made per method
+func (u *RPCUsage) Calls() map[string]int64 {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	calls := make(map[string]int64, len(u.calls))
+	for method, count := range u.calls {
+		calls[method] = count
+	}
+	return calls
+}
+
+// Total returns the number of calls made
+func (u *RPCUsage) Total() int64 {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	var total int64
+	for _, count := range u.calls {
+		total += count
+	}
+	return total
+}
+
+// Duration returns the time spent in calls. Calls made concurrently are each
+// counted in full, so it may exceed the wall time of the evaluation.
+func (u *RPCUsage) Duration() time.Duration {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.duration
+}
+
+// MeteredProvider is a BlockchainProvider that counts every call in the RPCUsage
+// of the context it is made with. Calls answered from the cache never reach it.
+type MeteredProvider struct {
+	provider BlockchainProvider
+}
+
+// NewMeteredProvider wraps a provider so its calls are counted
+func NewMeteredProvider(provider BlockchainProvider) *MeteredProvider {
+	return &MeteredProvider{provider: provider}
+}
+
+// Call forwards the call to the wrapped provider and counts it
+func (p *MeteredProvider) Call(ctx context.Context, method string, params []interface{}) ([]byte, error) {
+	start := time.Now()
+	result, err := p.provider.Call(ctx, method, params)
+	recordRPCCall(ctx, method, time.Since(start))
+	return result, err
+}
+
+// HealthCheck forwards to the wrapped provider; health probes are not counted
+func (p *MeteredProvider) HealthCheck(ctx context.Context) bool {
+	return p.provider.HealthCheck(ctx)
+}
@@ -0,0 +1,74 @@
+package policy
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMeteredProvider_CountsCalls counts the calls of an evaluation, but not cache hits
+func TestMeteredProvider_CountsCalls(t *testing.T) {
+	userAddr := "0x1234567890abcdef1234567890abcdef12345678"
+	provider := &MockBlockchainProvider{}
+	provider.SetBalance(userAddr, big.NewInt(2000))
+
+	rule := NewERC20MinBalanceRule("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", big.NewInt(1000), 1)
+	rule.SetProvider(NewMeteredProvider(provider))
+	rule.SetCache(&MockCache{})
+
+	ctx, usage := WithRPCUsage(context.Background())
+	result, err := rule.Evaluate(ctx, userAddr, nil)
+	require.NoError(t, err)
+	assert.True(t, result)
+	assert.Equal(t, map[string]int64{"eth_call": 1}, usage.Calls())
+	assert.Equal(t, int64(1), usage.Total())
+
+	ctx, usage = WithRPCUsage(context.Background())
+	_, err = rule.Evaluate(ctx, userAddr, nil)
+	require.NoError(t, err)
+	assert.Zero(t, usage.Total())
+}
+
+type delayedProvider struct {
+	BlockchainProvider
+	delay time.Duration
+}
+
+func (p *delayedProvider) Call(ctx context.Context, method string, params []interface{}) ([]byte, error) {
+	time.Sleep(p.delay)
+	return p.BlockchainProvider.Call(ctx, method, params)
+}
+
+// TestMeteredProvider_Duration adds up the time spent waiting for calls
+func TestMeteredProvider_Duration(t *testing.T) {
+	userAddr := "0x1234567890abcdef1234567890abcdef12345678"
+	provider := &MockBlockchainProvider{}
+	provider.SetBalance(userAddr, big.NewInt(2000))
+
+	rule := NewERC20MinBalanceRule("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", big.NewInt(1000), 1)
+	rule.SetProvider(NewMeteredProvider(&delayedProvider{BlockchainProvider: provider, delay: 10 * time.Millisecond}))
+
+	ctx, usage := WithRPCUsage(context.Background())
+	_, err := rule.Evaluate(ctx, userAddr, nil)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, usage.Duration(), 10*time.Millisecond)
+}
+
+// TestMeteredProvider_WithoutUsage forwards calls made outside an evaluation
+func TestMeteredProvider_WithoutUsage(t *testing.T) {
+	userAddr := "0x1234567890abcdef1234567890abcdef12345678"
+	provider := &MockBlockchainProvider{}
+	provider.SetBalance(userAddr, big.NewInt(2000))
+
+	rule := NewERC20MinBalanceRule("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", big.NewInt(1000), 1)
+	rule.SetProvider(NewMeteredProvider(provider))
+
+	result, err := rule.Evaluate(context.Background(), userAddr, nil)
+	require.NoError(t, err)
+	assert.True(t, result)
+	assert.True(t, NewMeteredProvider(provider).HealthCheck(context.Background()))
+}
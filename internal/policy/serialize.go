@@ -0,0 +1,249 @@
+package policy
+
+import (
+	"encoding/json"
+	"math/big"
+)
+
+// policyJSON is the serialized form of a policy. Rules use the configuration format
+// read by PolicyLoader, so a serialized policy can be loaded back unchanged.
+type policyJSON struct {
+	ID     int64         `json:"id,omitempty"`
+	Path   string        `json:"path"`
+	Method string        `json:"method"`
+	Logic  string        `json:"logic"`
+	Shadow bool          `json:"shadow,omitempty"`
+	Rules  []interface{} `json:"rules"`
+	// Threshold of a SCORE policy, whose rules also carry their points
+	Threshold int `json:"threshold,omitempty"`
+	// Default tier of a TIER policy, whose rules also carry their tiers
+	DefaultTier string `json:"default_tier,omitempty"`
+	CacheTTL    string `json:"cache_ttl,omitempty"`
+}
+
+// MarshalJSON serializes the policy in the format read by PolicyLoader, plus its ID
+func (p *Policy) MarshalJSON() ([]byte, error) {
+	out := policyJSON{
+		ID:     p.ID,
+		Path:   p.Path,
+		Method: p.Method,
+		Logic:  p.Logic,
+		Shadow: p.Shadow,
+		Rules:  rulesJSON(p.Rules),
+	}
+	if p.CacheTTL > 0 {
+		out.CacheTTL = p.CacheTTL.String()
+	}
+	if p.Logic == "SCORE" {
+		out.Threshold = p.Threshold
+		for i, config := range out.Rules {
+			if rule, ok := config.(map[string]interface{}); ok && i < len(p.Points) {
+				rule["points"] = p.Points[i]
+			}
+		}
+	}
+	if p.Logic == "TIER" {
+		out.DefaultTier = p.DefaultTier
+		for i, config := range out.Rules {
+			if rule, ok := config.(map[string]interface{}); ok && i < len(p.Tiers) {
+				rule["tier"] = p.Tiers[i]
+			}
+		}
+	}
+	return json.Marshal(out)
+}
+
+// ruleJSON returns the loader configuration of rule, with the TTL of its cached
+// reads. Rules of types the loader does not know are serialized with their type
+// and exported fields.
+func ruleJSON(rule Rule) interface{} {
+	config := ruleTypeJSON(rule)
+	if cached, ok := rule.(cachingRule); ok && cached.CacheTTL() > 0 {
+		if fields, ok := config.(map[string]interface{}); ok {
+			fields["cache_ttl"] = cached.CacheTTL().String()
+		}
+	}
+	return config
+}
+
+// ruleTypeJSON returns the configuration of rule specific to its type
+func ruleTypeJSON(rule Rule) interface{} {
+	switch r := rule.(type) {
+	case *HasScopeRule:
+		return map[string]interface{}{"type": r.Type(), "scope": r.Scope}
+	case *InAllowlistRule:
+		return map[string]interface{}{"type": r.Type(), "addresses": r.Addresses}
+	case *ERC20MinBalanceRule:
+		return map[string]interface{}{
+			"type":             r.Type(),
+			"contract_address": r.ContractAddress,
+			"minimum_balance":  bigString(r.MinimumBalance),
+			"chain_id":         r.ChainID,
+		}
+	case *ERC721OwnerRule:
+		return map[string]interface{}{
+			"type":             r.Type(),
+			"contract_address": r.ContractAddress,
+			"token_id":         bigString(r.TokenID),
+			"chain_id":         r.ChainID,
+		}
+	case *ERC721MinBalanceRule:
+		return map[string]interface{}{
+			"type":             r.Type(),
+			"contract_address": r.ContractAddress,
+			"minimum_balance":  bigString(r.MinimumBalance),
+			"chain_id":         r.ChainID,
+		}
+	case *ERC721TraitRule:
+		config := map[string]interface{}{
+			"type":             r.Type(),
+			"contract_address": r.ContractAddress,
+			"chain_id":         r.ChainID,
+			"trait_type":       r.TraitType,
+			"trait_value":      r.TraitValue,
+		}
+		if r.TokenID != nil {
+			config["token_id"] = r.TokenID.String()
+		}
+		return config
+	case *StakedBalanceRule:
+		return map[string]interface{}{
+			"type":             r.Type(),
+			"contract_address": r.ContractAddress,
+			"function":         r.Function,
+			"args":             r.Args,
+			"result_index":     r.ResultIndex,
+			"minimum_balance":  bigString(r.MinimumBalance),
+			"chain_id":         r.ChainID,
+		}
+	case *VotingPowerRule:
+		config := map[string]interface{}{
+			"type":             r.Type(),
+			"contract_address": r.ContractAddress,
+			"minimum_votes":    bigString(r.MinimumVotes),
+			"chain_id":         r.ChainID,
+		}
+		if r.BlockNumber > 0 {
+			config["block_number"] = r.BlockNumber
+		}
+		return config
+	case *ContractCallRule:
+		config := map[string]interface{}{
+			"type":             r.Type(),
+			"contract_address": r.ContractAddress,
+			"function":         r.Function,
+			"args":             r.Args,
+			"result_index":     r.ResultIndex,
+			"comparison":       r.Comparison,
+			"chain_id":         r.ChainID,
+		}
+		if r.Value != nil {
+			config["value"] = r.Value.String()
+		}
+		return config
+	case *LPPositionRule:
+		config := map[string]interface{}{
+			"type":              r.Type(),
+			"version":           r.Version,
+			"pool_address":      r.PoolAddress,
+			"minimum_liquidity": bigString(r.MinimumLiquidity),
+			"chain_id":          r.ChainID,
+		}
+		if r.PositionManager != "" {
+			config["position_manager"] = r.PositionManager
+		}
+		return config
+	case *HoldingDurationRule:
+		return map[string]interface{}{
+			"type":             r.Type(),
+			"contract_address": r.ContractAddress,
+			"minimum_balance":  bigString(r.MinimumBalance),
+			"minimum_days":     r.MinimumDays,
+			"chain_id":         r.ChainID,
+		}
+	case *CodeExistsRule:
+		return map[string]interface{}{"type": r.Type(), "chain_id": r.ChainID}
+	case *ContractDeployerRule:
+		config := map[string]interface{}{
+			"type":     r.Type(),
+			"chain_id": r.ChainID,
+		}
+		if r.ContractAddress != "" {
+			config["contract_address"] = r.ContractAddress
+		}
+		if r.Factory != nil {
+			config["factory"] = map[string]interface{}{
+				"address":        r.Factory.Address,
+				"event":          r.Factory.Event,
+				"deployer_topic": r.Factory.DeployerTopic,
+				"contract_topic": r.Factory.ContractTopic,
+				"from_block":     r.Factory.FromBlock,
+			}
+		}
+		if r.Registry != nil {
+			registry := map[string]interface{}{"function": r.Registry.Function}
+			if r.Registry.Address != "" {
+				registry["address"] = r.Registry.Address
+			}
+			config["registry"] = registry
+		}
+		return config
+	case *HeaderEqualsRule:
+		return map[string]interface{}{"type": r.Type(), "header": r.Header, "value": r.Value}
+	case *HeaderMatchesRule:
+		return map[string]interface{}{"type": r.Type(), "header": r.Header, "pattern": r.Pattern}
+	case *QueryParamPresentRule:
+		return map[string]interface{}{"type": r.Type(), "param": r.Param}
+	case *QueryParamMatchesRule:
+		return map[string]interface{}{"type": r.Type(), "param": r.Param, "pattern": r.Pattern}
+	case *HTTPMethodRule:
+		return map[string]interface{}{"type": r.Type(), "methods": r.Methods}
+	case *GeoRestrictionRule:
+		return map[string]interface{}{"type": r.Type(), "mode": r.Mode, "countries": r.Countries, "asns": r.ASNs}
+	case *ClaimMatchRule:
+		return map[string]interface{}{"type": r.Type(), "claim": r.Claim, "operator": r.Operator, "value": r.Value}
+	case *QuotaRule:
+		return map[string]interface{}{"type": r.Type(), "name": r.Name, "limit": r.Limit, "window": r.Window.String()}
+	case *NotInBlocklistRule:
+		return map[string]interface{}{"type": r.Type()}
+	case *WebhookRule:
+		return map[string]interface{}{
+			"type":      r.Type(),
+			"url":       r.URL,
+			"timeout":   r.Timeout.String(),
+			"retries":   r.Retries,
+			"fail_open": r.FailOpen,
+		}
+	case *PluginRule:
+		config := map[string]interface{}{"type": r.Type(), "plugin": r.Plugin, "timeout": r.Timeout.String()}
+		if len(r.Params) > 0 {
+			config["params"] = r.Params
+		}
+		return config
+	case *AllOfRule:
+		return map[string]interface{}{"type": r.Type(), "rules": rulesJSON(r.Rules)}
+	case *AnyOfRule:
+		return map[string]interface{}{"type": r.Type(), "rules": rulesJSON(r.Rules)}
+	case *NotRule:
+		return map[string]interface{}{"type": r.Type(), "rule": ruleJSON(r.Rule)}
+	default:
+		return ruleSnapshot{Type: rule.Type(), Config: rule}
+	}
+}
+
+// rulesJSON returns the loader configuration of each rule
+func rulesJSON(rules []Rule) []interface{} {
+	configs := make([]interface{}, 0, len(rules))
+	for _, rule := range rules {
+		configs = append(configs, ruleJSON(rule))
+	}
+	return configs
+}
+
+// bigString formats n in decimal, as the loader parses it
+func bigString(n *big.Int) string {
+	if n == nil {
+		return "0"
+	}
+	return n.String()
+}
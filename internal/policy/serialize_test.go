@@ -0,0 +1,109 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_MarshalJSON(t *testing.T) {
+	policy := NewPolicy("GET", "/api/data", "AND", []Rule{NewHasScopeRule("read")})
+	policy.ID = 7
+
+	data, err := json.Marshal(policy)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":7,"path":"/api/data","method":"GET","logic":"AND","rules":[{"type":"has_scope","scope":"read"}]}`, string(data))
+}
+
+// TestPolicy_MarshalJSON_RoundTrip loads serialized policies back unchanged
+func TestPolicy_MarshalJSON_RoundTrip(t *testing.T) {
+	configJSON := `[{
+		"path": "/api/vip",
+		"method": "POST",
+		"logic": "OR",
+		"shadow": true,
+		"rules": [
+			{"type": "has_scope", "scope": "vip"},
+			{"type": "in_allowlist", "addresses": ["0x742d35cc6634c0532925a3b844bc9e7595f0beb8"]},
+			{"type": "erc20_min_balance", "contract_address": "0x1f9840a85d5af5bf1d1762f925bdaddc4201f984", "minimum_balance": "1000000000000000000", "chain_id": 1},
+			{"type": "erc721_owner", "contract_address": "0xbc4ca0eda7647a8ab7c2061c2e118a18a936f13d", "token_id": "42", "chain_id": 1},
+			{"type": "erc721_min_balance", "contract_address": "0xbc4ca0eda7647a8ab7c2061c2e118a18a936f13d", "minimum_balance": "3", "chain_id": 1},
+			{"type": "erc721_trait", "contract_address": "0xbc4ca0eda7647a8ab7c2061c2e118a18a936f13d", "chain_id": 1, "trait_type": "Fur", "trait_value": "Gold"},
+			{"type": "staked_balance", "contract_address": "0x1f9840a85d5af5bf1d1762f925bdaddc4201f984", "minimum_balance": "5", "chain_id": 1},
+			{"type": "voting_power", "contract_address": "0xc00e94cb662c3520282e6f5717214004a7f26888", "minimum_votes": "400000", "block_number": 19000000, "chain_id": 1},
+			{"type": "contract_call", "contract_address": "0x1f9840a85d5af5bf1d1762f925bdaddc4201f984", "function": "getVotes(address)", "args": ["$address"], "comparison": ">=", "value": "1000", "chain_id": 1},
+			{"type": "contract_call", "contract_address": "0x1f9840a85d5af5bf1d1762f925bdaddc4201f984", "function": "0xa230c524", "args": ["$address"], "comparison": "true", "chain_id": 1},
+			{"type": "lp_position", "version": "v2", "pool_address": "0xb4e16d0168e52d35cacd2c6185b44281ec28c9dc", "minimum_liquidity": "1", "chain_id": 1},
+			{"type": "holding_duration", "contract_address": "0x1f9840a85d5af5bf1d1762f925bdaddc4201f984", "minimum_balance": "100", "minimum_days": 30, "chain_id": 1},
+			{"type": "code_exists", "chain_id": 1},
+			{"type": "contract_deployer", "contract_address": "0xb4e16d0168e52d35cacd2c6185b44281ec28c9dc", "chain_id": 1, "registry": {"function": "deployer()"}},
+			{"type": "contract_deployer", "chain_id": 1, "factory": {"address": "0x5c69bee701ef814a2b6a3edd4b1652cb9cc5aa6f", "event": "PairCreated(address,address,address,uint256)", "deployer_topic": 1}},
+			{"type": "header_equals", "header": "X-Tier", "value": "gold"},
+			{"type": "header_matches", "header": "User-Agent", "pattern": "Wallet/[0-9]+"},
+			{"type": "query_param_present", "param": "token"},
+			{"type": "query_param_matches", "param": "chain", "pattern": "137"},
+			{"type": "http_method", "methods": ["POST"]},
+			{"type": "geo_restriction", "mode": "deny", "countries": ["KP"]},
+			{"type": "claim_match", "claim": "tier", "operator": "in", "value": ["gold", "silver"]},
+			{"type": "quota", "name": "vip", "limit": 10, "window": "24h"},
+			{"type": "not_in_blocklist"},
+			{"type": "webhook", "url": "https://authz.example.com/check", "timeout": "1s", "retries": 2, "fail_open": true}
+		]
+	}]`
+
+	loader := NewPolicyLoader()
+	policies, err := loader.LoadFromJSON([]byte(configJSON))
+	require.NoError(t, err)
+
+	data, err := json.Marshal(policies[0])
+	require.NoError(t, err)
+	reloaded, err := loader.ParsePolicy(data)
+	require.NoError(t, err)
+
+	again, err := json.Marshal(reloaded)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(data), string(again))
+	assert.True(t, reloaded.Shadow)
+	assert.Len(t, reloaded.Rules, 25)
+}
+
+// TestPolicy_MarshalJSON_Score serializes the threshold and the points of each rule
+func TestPolicy_MarshalJSON_Score(t *testing.T) {
+	policy := NewScorePolicy("GET", "/api/data", []Rule{NewHasScopeRule("nft"), NewHasScopeRule("vip")}, []int{50, 100}, 60)
+
+	data, err := json.Marshal(policy)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"path":"/api/data","method":"GET","logic":"SCORE","threshold":60,"rules":[`+
+		`{"type":"has_scope","scope":"nft","points":50},{"type":"has_scope","scope":"vip","points":100}]}`, string(data))
+
+	reloaded, err := NewPolicyLoader().ParsePolicy(data)
+	require.NoError(t, err)
+	assert.Equal(t, policy.Points, reloaded.Points)
+	assert.Equal(t, policy.Threshold, reloaded.Threshold)
+}
+
+// TestPolicy_MarshalJSON_Tier serializes the default tier and the tier of each rule
+func TestPolicy_MarshalJSON_Tier(t *testing.T) {
+	policy := NewTierPolicy("GET", "/api/data", []Rule{NewHasScopeRule("whale"), NewHasScopeRule("holder")}, []string{"gold", "silver"}, "bronze")
+
+	data, err := json.Marshal(policy)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"path":"/api/data","method":"GET","logic":"TIER","default_tier":"bronze","rules":[`+
+		`{"type":"has_scope","scope":"whale","tier":"gold"},{"type":"has_scope","scope":"holder","tier":"silver"}]}`, string(data))
+
+	reloaded, err := NewPolicyLoader().ParsePolicy(data)
+	require.NoError(t, err)
+	assert.Equal(t, policy.Tiers, reloaded.Tiers)
+	assert.Equal(t, policy.DefaultTier, reloaded.DefaultTier)
+}
+
+func TestLoader_ParsePolicy_Invalid(t *testing.T) {
+	loader := NewPolicyLoader()
+
+	_, err := loader.ParsePolicy([]byte(`[]`))
+	assert.Error(t, err)
+	_, err = loader.ParsePolicy([]byte(`{"path":"/api/data","method":"GET","logic":"XOR","rules":[{"type":"has_scope","scope":"read"}]}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,263 @@
+package policy
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/chain"
+	"go.uber.org/zap"
+)
+
+// CallerArgument is the placeholder in StakedBalanceRule arguments replaced by the
+// address being evaluated
+const CallerArgument = "$address"
+
+// StakedBalanceRule checks if user has at least a minimum balance staked in a staking
+// contract. Tokens locked in staking do not show up in the token's balanceOf, so the
+// staking contract's own view function is called instead, e.g. "balanceOf(address)"
+// (Synthetix-style staking rewards) or "userInfo(uint256,address)" (MasterChef-style
+// pools, where the staked amount is the first return value).
+type StakedBalanceRule struct {
+	ContractAddress string
+	Function        string   // Solidity signature, e.g. "userInfo(uint256,address)"
+	Args            []string // CallerArgument, 0x-prefixed addresses or decimal integers
+	ResultIndex     int      // 32-byte word of the return data holding the staked amount
+	MinimumBalance  *big.Int
+	ChainID         uint64
+	cacheTTL        // Optional TTL of the rule's cached reads
+	// cache and provider will be set by manager
+	cache    CacheProvider
+	provider BlockchainProvider
+	logger   *zap.Logger
+}
+
+// NewStakedBalanceRule creates a new staked balance rule
+func NewStakedBalanceRule(contractAddress, function string, args []string, resultIndex int, minimumBalance *big.Int, chainID uint64) *StakedBalanceRule {
+	logger, _ := zap.NewProduction()
+	return &StakedBalanceRule{
+		ContractAddress: contractAddress,
+		Function:        function,
+		Args:            args,
+		ResultIndex:     resultIndex,
+		MinimumBalance:  minimumBalance,
+		ChainID:         chainID,
+		logger:          logger,
+	}
+}
+
+// Type returns the rule type
+func (r *StakedBalanceRule) Type() RuleType {
+	return StakedBalanceRuleType
+}
+
+// Validate checks if the rule parameters are valid
+func (r *StakedBalanceRule) Validate() error {
+	if !isValidAddress(r.ContractAddress) {
+		return fmt.Errorf("invalid contract address: %s", r.ContractAddress)
+	}
+
+	paramTypes, err := parseFunctionSignature(r.Function)
+	if err != nil {
+		return err
+	}
+	if len(paramTypes) != len(r.Args) {
+		return fmt.Errorf("function %s takes %d arguments, got %d", r.Function, len(paramTypes), len(r.Args))
+	}
+	for i, paramType := range paramTypes {
+		if err := validateArgument(paramType, r.Args[i]); err != nil {
+			return fmt.Errorf("argument %d: %w", i, err)
+		}
+	}
+
+	if r.ResultIndex < 0 {
+		return fmt.Errorf("result index cannot be negative")
+	}
+	if r.MinimumBalance == nil || r.MinimumBalance.Sign() < 0 {
+		return fmt.Errorf("minimum balance must be a non-negative number")
+	}
+	if r.ChainID == 0 {
+		return fmt.Errorf("chain ID cannot be zero")
+	}
+	return nil
+}
+
+// Evaluate checks the staked balance (requires provider and cache to be set)
+// This implementation follows fail-closed security: on any error, return false
+func (r *StakedBalanceRule) Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	if !isValidAddress(address) {
+		r.logger.Error("invalid address format",
+			zap.String("address", address),
+			zap.String("rule", "StakedBalance"))
+		return false, nil // Fail-closed
+	}
+
+	if r.provider == nil {
+		r.logger.Warn("no blockchain provider configured",
+			zap.String("rule", "StakedBalance"))
+		return false, nil
+	}
+
+	calldata, err := r.encodeCall(address)
+	if err != nil {
+		r.logger.Error("failed to encode staking call",
+			zap.Error(err),
+			zap.String("function", r.Function))
+		return false, nil
+	}
+
+	// Generate cache key: "staked_balance:{chainID}:{contract}:{calldata}"
+	// The calldata covers the function, its arguments and the user's address
+	chainIDStr := strconv.FormatUint(r.ChainID, 10)
+	cacheKey := chain.CacheKey("staked_balance", chainIDStr, strings.ToLower(r.ContractAddress), calldata)
+
+	var balance *big.Int
+	if r.cache != nil && !refreshingCache(ctx) {
+		if cached, ok := r.cache.Get(cacheKey); ok {
+			balance, _ = cached.(*big.Int)
+		}
+	}
+
+	if balance == nil {
+		resultHex, err := ethCall(ctx, r.provider, r.ContractAddress, calldata)
+		if err != nil {
+			logRPCFailure(r.logger, err, "RPC call failed for staked balance",
+				zap.String("contract", r.ContractAddress),
+				zap.String("function", r.Function),
+				zap.String("address", address),
+				zap.Uint64("chainID", r.ChainID))
+			return false, nil
+		}
+
+		balance, err = decodeWord(resultHex, r.ResultIndex)
+		if err != nil {
+			r.logger.Error("failed to decode staked balance",
+				zap.Error(err),
+				zap.String("resultHex", resultHex))
+			return false, nil
+		}
+
+		if r.cache != nil {
+			r.cache.Set(cacheKey, balance)
+		}
+	}
+
+	hasBalance := balance.Cmp(r.MinimumBalance) >= 0
+
+	r.logger.Info("staked balance check completed",
+		zap.String("address", address),
+		zap.String("contract", r.ContractAddress),
+		zap.String("function", r.Function),
+		zap.String("balance", balance.String()),
+		zap.String("minimum", r.MinimumBalance.String()),
+		zap.Bool("hasBalance", hasBalance))
+
+	return hasBalance, nil
+}
+
+// encodeCall encodes the configured function call for address
+func (r *StakedBalanceRule) encodeCall(address string) (string, error) {
+	if _, err := parseFunctionSignature(r.Function); err != nil {
+		return "", err
+	}
+	return encodeFunctionCall(r.Function, r.Args, address)
+}
+
+// SetProvider sets the blockchain provider for RPC calls
+func (r *StakedBalanceRule) SetProvider(provider BlockchainProvider) {
+	r.provider = provider
+}
+
+// SetCache sets the cache for storing results
+func (r *StakedBalanceRule) SetCache(cache CacheProvider) {
+	r.cache = withCacheTTL(cache, r.CacheTTL())
+}
+
+// SetLogger sets the logger for the rule
+func (r *StakedBalanceRule) SetLogger(logger *zap.Logger) {
+	r.logger = logger
+}
+
+// EncodeFunctionCall encodes a call to function, given by its Solidity signature or
+// its 4-byte selector, with 0x-prefixed address and decimal integer arguments, as
+// rules calling contracts encode it
+func EncodeFunctionCall(function string, args ...string) (string, error) {
+	return encodeFunctionCall(function, args, "")
+}
+
+// encodeFunctionCall encodes a call to function, given by its Solidity signature or
+// its 4-byte selector, with CallerArgument in args replaced by address
+func encodeFunctionCall(function string, args []string, address string) (string, error) {
+	calldata := strings.ToLower(function)
+	if !isFunctionSelector(function) {
+		calldata = "0x" + hex.EncodeToString(crypto.Keccak256([]byte(function))[:4])
+	}
+
+	for _, arg := range args {
+		if arg == CallerArgument {
+			arg = address
+		}
+		if strings.HasPrefix(arg, "0x") {
+			calldata += strings.TrimPrefix(encodeAddress(strings.ToLower(arg)), "0x")
+			continue
+		}
+		value, ok := new(big.Int).SetString(arg, 10)
+		if !ok || value.Sign() < 0 {
+			return "", fmt.Errorf("invalid integer argument %q", arg)
+		}
+		calldata += strings.TrimPrefix(encodeUint256(value), "0x")
+	}
+	return calldata, nil
+}
+
+// isFunctionSelector reports whether function is a 0x-prefixed 4-byte selector
+// rather than a signature
+func isFunctionSelector(function string) bool {
+	if len(function) != 10 || !strings.HasPrefix(function, "0x") {
+		return false
+	}
+	_, err := hex.DecodeString(function[2:])
+	return err == nil
+}
+
+// parseFunctionSignature returns the parameter types of a signature such as
+// "userInfo(uint256,address)". Only static address and uint types are supported.
+func parseFunctionSignature(signature string) ([]string, error) {
+	open := strings.Index(signature, "(")
+	if open <= 0 || !strings.HasSuffix(signature, ")") {
+		return nil, fmt.Errorf("invalid function signature %q", signature)
+	}
+
+	params := signature[open+1 : len(signature)-1]
+	if params == "" {
+		return nil, nil
+	}
+
+	paramTypes := strings.Split(params, ",")
+	for _, paramType := range paramTypes {
+		if paramType != "address" && !strings.HasPrefix(paramType, "uint") {
+			return nil, fmt.Errorf("unsupported parameter type %q in %q (use address or uint)", paramType, signature)
+		}
+	}
+	return paramTypes, nil
+}
+
+// validateArgument checks that arg can be passed as a parameter of paramType
+func validateArgument(paramType, arg string) error {
+	if paramType == "address" {
+		if arg != CallerArgument && !isValidAddress(arg) {
+			return fmt.Errorf("%q is not an address or %s", arg, CallerArgument)
+		}
+		return nil
+	}
+
+	if value, ok := new(big.Int).SetString(arg, 10); !ok || value.Sign() < 0 {
+		return fmt.Errorf("%q is not a non-negative integer", arg)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testStakingAddr = "0x5234567890123456789012345678901234567890" // Mock staking contract
+
+// stakingProvider returns fixed return data and records the calldata it was called with
+type stakingProvider struct {
+	result string // Hex return data without 0x
+	err    error
+	calls  []string
+}
+
+func (p *stakingProvider) Call(ctx context.Context, method string, params []interface{}) ([]byte, error) {
+	p.calls = append(p.calls, params[0].(map[string]interface{})["data"].(string))
+	if p.err != nil {
+		return nil, p.err
+	}
+	return rpcResult(p.result), nil
+}
+
+func (p *stakingProvider) HealthCheck(ctx context.Context) bool {
+	return true
+}
+
+// TestStakedBalanceRule_Validate validates rule parameters
+func TestStakedBalanceRule_Validate(t *testing.T) {
+	minimum := big.NewInt(100)
+	caller := []string{CallerArgument}
+
+	assert.NoError(t, NewStakedBalanceRule(testStakingAddr, "balanceOf(address)", caller, 0, minimum, 1).Validate())
+	assert.NoError(t, NewStakedBalanceRule(testStakingAddr, "userInfo(uint256,address)", []string{"3", CallerArgument}, 0, minimum, 1).Validate())
+	assert.NoError(t, NewStakedBalanceRule(testStakingAddr, "totalStaked()", nil, 0, minimum, 1).Validate())
+
+	assert.Error(t, NewStakedBalanceRule("0x1234", "balanceOf(address)", caller, 0, minimum, 1).Validate())
+	assert.Error(t, NewStakedBalanceRule(testStakingAddr, "balanceOf", caller, 0, minimum, 1).Validate())
+	assert.Error(t, NewStakedBalanceRule(testStakingAddr, "balanceOf(string)", caller, 0, minimum, 1).Validate())
+	assert.Error(t, NewStakedBalanceRule(testStakingAddr, "balanceOf(address)", nil, 0, minimum, 1).Validate())
+	assert.Error(t, NewStakedBalanceRule(testStakingAddr, "userInfo(uint256,address)", []string{CallerArgument, "3"}, 0, minimum, 1).Validate())
+	assert.Error(t, NewStakedBalanceRule(testStakingAddr, "balanceOf(address)", caller, -1, minimum, 1).Validate())
+	assert.Error(t, NewStakedBalanceRule(testStakingAddr, "balanceOf(address)", caller, 0, minimum, 0).Validate())
+}
+
+// TestStakedBalanceRule_EncodeCall computes the selector and substitutes the caller
+func TestStakedBalanceRule_EncodeCall(t *testing.T) {
+	rule := NewStakedBalanceRule(testStakingAddr, "userInfo(uint256,address)", []string{"3", CallerArgument}, 0, big.NewInt(1), 1)
+
+	calldata, err := rule.encodeCall(testUserAddr)
+	require.NoError(t, err)
+
+	assert.Equal(t, "0x93f1a40b", calldata[:10])
+	assert.Equal(t, fmt.Sprintf("%064x", 3), calldata[10:74])
+	assert.Equal(t, strings.Repeat("0", 24)+strings.TrimPrefix(testUserAddr, "0x"), calldata[74:])
+}
+
+// TestStakedBalanceRule_Evaluate compares the selected return word with the minimum
+func TestStakedBalanceRule_Evaluate(t *testing.T) {
+	// userInfo returns (amount, rewardDebt)
+	provider := &stakingProvider{result: fmt.Sprintf("%064x%064x", 500, 9999)}
+
+	tests := []struct {
+		name        string
+		resultIndex int
+		minimum     int64
+		expected    bool
+	}{
+		{"amount meets minimum", 0, 500, true},
+		{"amount below minimum", 0, 501, false},
+		{"second return value", 1, 9999, true},
+		{"missing return value", 2, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewStakedBalanceRule(testStakingAddr, "userInfo(uint256,address)", []string{"0", CallerArgument}, tt.resultIndex, big.NewInt(tt.minimum), 1)
+			rule.SetProvider(provider)
+
+			allowed, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, allowed)
+		})
+	}
+}
+
+// TestStakedBalanceRule_Cache caches the staked balance per user
+func TestStakedBalanceRule_Cache(t *testing.T) {
+	provider := &stakingProvider{result: fmt.Sprintf("%064x", 500)}
+	rule := NewStakedBalanceRule(testStakingAddr, "balanceOf(address)", []string{CallerArgument}, 0, big.NewInt(100), 1)
+	rule.SetProvider(provider)
+	rule.SetCache(&MockCache{})
+
+	for i := 0; i < 3; i++ {
+		allowed, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+	_, err := rule.Evaluate(context.Background(), testUserAddr2, nil)
+	require.NoError(t, err)
+
+	assert.Len(t, provider.calls, 2)
+}
+
+// TestStakedBalanceRule_FailClosed denies on missing provider or RPC errors
+func TestStakedBalanceRule_FailClosed(t *testing.T) {
+	rule := NewStakedBalanceRule(testStakingAddr, "balanceOf(address)", []string{CallerArgument}, 0, big.NewInt(0), 1)
+	allowed, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	rule.SetProvider(&stakingProvider{err: fmt.Errorf("execution reverted")})
+	allowed, err = rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
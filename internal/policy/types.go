@@ -3,6 +3,8 @@ package policy
 import (
 	"context"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/yourusername/gatekeeper/internal/auth"
 )
@@ -11,15 +13,39 @@ import (
 type RuleType string
 
 const (
-	HasScopeRuleType         RuleType = "has_scope"
-	InAllowlistRuleType      RuleType = "in_allowlist"
-	ERC20MinBalanceRuleType  RuleType = "erc20_min_balance"
-	ERC721OwnerRuleType      RuleType = "erc721_owner"
+	HasScopeRuleType          RuleType = "has_scope"
+	InAllowlistRuleType       RuleType = "in_allowlist"
+	ERC20MinBalanceRuleType   RuleType = "erc20_min_balance"
+	ERC721OwnerRuleType       RuleType = "erc721_owner"
+	ERC721MinBalanceRuleType  RuleType = "erc721_min_balance"
+	ERC721TraitRuleType       RuleType = "erc721_trait"
+	StakedBalanceRuleType     RuleType = "staked_balance"
+	VotingPowerRuleType       RuleType = "voting_power"
+	ContractCallRuleType      RuleType = "contract_call"
+	LPPositionRuleType        RuleType = "lp_position"
+	HoldingDurationRuleType   RuleType = "holding_duration"
+	CodeExistsRuleType        RuleType = "code_exists"
+	ContractDeployerRuleType  RuleType = "contract_deployer"
+	HeaderEqualsRuleType      RuleType = "header_equals"
+	HeaderMatchesRuleType     RuleType = "header_matches"
+	QueryParamPresentRuleType RuleType = "query_param_present"
+	QueryParamMatchesRuleType RuleType = "query_param_matches"
+	HTTPMethodRuleType        RuleType = "http_method"
+	GeoRestrictionRuleType    RuleType = "geo_restriction"
+	ClaimMatchRuleType        RuleType = "claim_match"
+	QuotaRuleType             RuleType = "quota"
+	NotInBlocklistRuleType    RuleType = "not_in_blocklist"
+	WebhookRuleType           RuleType = "webhook"
+	PluginRuleType            RuleType = "plugin"
+	AllOfRuleType             RuleType = "all_of"
+	AnyOfRuleType             RuleType = "any_of"
+	NotRuleType               RuleType = "not"
 )
 
 // Rule is the interface for all policy rules
 type Rule interface {
-	// Evaluate returns true if the rule passes for the given address and claims
+	// Evaluate returns true if the rule passes for the given address and claims.
+	// Details of the request are available from EvaluationContextFromContext(ctx).
 	Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error)
 	// Type returns the rule type
 	Type() RuleType
@@ -27,10 +53,31 @@ type Rule interface {
 
 // Policy represents an access control policy for a route
 type Policy struct {
+	ID     int64  // Assigned by the PolicyManager when the policy is added; 0 before
 	Path   string // Route pattern (e.g., "/api/data", "/api/*")
 	Method string // HTTP method (GET, POST, etc.)
-	Logic  string // "AND" or "OR" - how to combine rules
+	Logic  string // "AND", "OR", "SCORE" or "TIER" - how to combine rules
 	Rules  []Rule // List of rules to evaluate
+
+	// With SCORE logic, each passing rule earns the points at its index and the
+	// policy passes once the points reach Threshold
+	Points    []int
+	Threshold int
+
+	// With TIER logic, the first passing rule grants the tier at its index. Callers
+	// passing no rule are granted DefaultTier, or denied if it is empty.
+	Tiers       []string
+	DefaultTier string
+
+	// Shadow policies are evaluated and their would-be decision recorded,
+	// but they never deny a request
+	Shadow bool
+
+	// CacheTTL keeps the decision of the policy for an address that long, so
+	// repeated requests skip its rules. Only for policies whose rules depend on
+	// nothing but the address; zero disables the decision cache.
+	CacheTTL  time.Duration
+	decisions atomic.Pointer[decisionCache] // Set by SetDecisionCache
 }
 
 // NewPolicy creates a new policy with the given parameters
@@ -43,6 +90,23 @@ func NewPolicy(method, path, logic string, rules []Rule) *Policy {
 	}
 }
 
+// NewScorePolicy creates a policy with SCORE logic, where rules[i] is worth points[i]
+func NewScorePolicy(method, path string, rules []Rule, points []int, threshold int) *Policy {
+	policy := NewPolicy(method, path, "SCORE", rules)
+	policy.Points = points
+	policy.Threshold = threshold
+	return policy
+}
+
+// NewTierPolicy creates a policy with TIER logic, where rules[i] grants tiers[i].
+// Rules are tried in order, so the highest tier comes first.
+func NewTierPolicy(method, path string, rules []Rule, tiers []string, defaultTier string) *Policy {
+	policy := NewPolicy(method, path, "TIER", rules)
+	policy.Tiers = tiers
+	policy.DefaultTier = defaultTier
+	return policy
+}
+
 // HasScopeRule checks if user has a specific scope
 type HasScopeRule struct {
 	Scope string
@@ -58,19 +122,14 @@ func (r *HasScopeRule) Type() RuleType {
 	return HasScopeRuleType
 }
 
-// Evaluate checks if the user has the required scope
+// Evaluate checks if the user has the required scope.
+// Wildcard scopes in the claims (e.g. "admin:*") satisfy any scope beneath them.
 func (r *HasScopeRule) Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
 	if claims == nil {
 		return false, nil
 	}
 
-	for _, scope := range claims.Scopes {
-		if scope == r.Scope {
-			return true, nil
-		}
-	}
-
-	return false, nil
+	return auth.HasScope(claims.Scopes, r.Scope), nil
 }
 
 // InAllowlistRule checks if user address is in an allowlist
@@ -118,41 +177,149 @@ type CacheProvider interface {
 	GetOrSet(key string, fn func() interface{}) interface{}
 }
 
-// Evaluate evaluates the policy for the given address and claims
+// Evaluate evaluates the policy for the given address and claims. Policies with a
+// CacheTTL answer from their decision cache while the decision is fresh.
 func (p *Policy) Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	if decisions := p.decisions.Load(); decisions != nil {
+		return decisions.evaluate(ctx, address, func(ctx context.Context) (bool, error) {
+			return p.evaluate(ctx, address, claims)
+		})
+	}
+	return p.evaluate(ctx, address, claims)
+}
+
+// evaluate combines the results of the rules by the policy's logic
+func (p *Policy) evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
 	if p.Logic == "AND" {
 		return p.evaluateAND(ctx, address, claims)
 	} else if p.Logic == "OR" {
 		return p.evaluateOR(ctx, address, claims)
+	} else if p.Logic == "SCORE" {
+		return p.evaluateScore(ctx, address, claims)
+	} else if p.Logic == "TIER" {
+		tier, err := p.evaluateTier(ctx, address, claims)
+		return tier != "", err
 	}
 	return false, nil
 }
 
-// evaluateAND requires all rules to pass
+// EvaluateTier evaluates the policy like Evaluate, also returning the tier granted
+// to the caller by a TIER policy. Other policies grant no tier. The tier depends on
+// which rule passes, so TIER policies never answer from a decision cache.
+func (p *Policy) EvaluateTier(ctx context.Context, address string, claims *auth.Claims) (string, bool, error) {
+	if p.Logic != "TIER" {
+		allowed, err := p.Evaluate(ctx, address, claims)
+		return "", allowed, err
+	}
+	tier, err := p.evaluateTier(ctx, address, claims)
+	if err != nil {
+		return "", false, err
+	}
+	return tier, tier != "", nil
+}
+
+// evaluateAND requires all rules to pass, short-circuiting on the first failure
 func (p *Policy) evaluateAND(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
-	for _, rule := range p.Rules {
-		result, err := rule.Evaluate(ctx, address, claims)
+	return evaluateGroup(ctx, p.Rules, address, claims, false)
+}
+
+// evaluateOR requires any rule to pass, short-circuiting on the first success
+func (p *Policy) evaluateOR(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	return evaluateGroup(ctx, p.Rules, address, claims, true)
+}
+
+// evaluateScore requires the points of passing rules to reach the threshold
+func (p *Policy) evaluateScore(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	score := 0
+	for i, rule := range p.Rules {
+		if i >= len(p.Points) {
+			break
+		}
+		result, err := evaluateRule(ctx, rule, address, claims)
 		if err != nil {
 			return false, err
 		}
-		// Short-circuit on first failure
 		if !result {
-			return false, nil
+			continue
+		}
+		score += p.Points[i]
+		// Short-circuit once the threshold is reached
+		if score >= p.Threshold {
+			return true, nil
 		}
 	}
-	return true, nil
+	return false, nil
 }
 
-// evaluateOR requires any rule to pass
-func (p *Policy) evaluateOR(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
-	for _, rule := range p.Rules {
-		result, err := rule.Evaluate(ctx, address, claims)
-		if err != nil {
-			return false, err
-		}
-		// Short-circuit on first success
-		if result {
-			return true, nil
+// evaluateTier returns the tier of the first passing rule, or the default tier if
+// none passes
+func (p *Policy) evaluateTier(ctx context.Context, address string, claims *auth.Claims) (string, error) {
+	index, err := firstDecisive(ctx, p.Rules, address, claims, true)
+	if err != nil {
+		return "", err
+	}
+	if index >= 0 && index < len(p.Tiers) {
+		return p.Tiers[index], nil
+	}
+	return p.DefaultTier, nil
+}
+
+// isWalletRule reports whether the outcome of a rule depends only on the
+// address it is evaluated for, so any wallet of the caller may satisfy it
+func isWalletRule(rule Rule) bool {
+	switch rule.(type) {
+	case *InAllowlistRule, *ERC20MinBalanceRule, *ERC721OwnerRule, *ERC721MinBalanceRule, *ERC721TraitRule,
+		*StakedBalanceRule, *VotingPowerRule, *ContractCallRule, *LPPositionRule, *HoldingDurationRule, *CodeExistsRule, *ContractDeployerRule:
+		return true
+	}
+	return false
+}
+
+// HasWalletRules reports whether any of the policies has a rule, possibly nested in
+// a rule group, that linked wallets can satisfy or that checks them
+func HasWalletRules(policies []*Policy) bool {
+	found := false
+	for _, p := range policies {
+		WalkRules(p.Rules, func(rule Rule) {
+			_, checksWallets := rule.(*NotInBlocklistRule)
+			found = found || isWalletRule(rule) || checksWallets
+		})
+	}
+	return found
+}
+
+// HasTokenRules reports whether any of the policies has a rule, possibly nested in
+// a rule group, that checks the tokens an address holds
+func HasTokenRules(policies []*Policy) bool {
+	found := false
+	for _, p := range policies {
+		WalkRules(p.Rules, func(rule Rule) {
+			switch rule.(type) {
+			case *ERC20MinBalanceRule, *ERC721OwnerRule, *ERC721MinBalanceRule, *ERC721TraitRule,
+				*StakedBalanceRule, *VotingPowerRule, *LPPositionRule, *HoldingDurationRule:
+				found = true
+			}
+		})
+	}
+	return found
+}
+
+// evaluateRule evaluates a rule for the address. A wallet rule that fails is
+// retried for each wallet linked to the caller, passing if any of them passes.
+func evaluateRule(ctx context.Context, rule Rule, address string, claims *auth.Claims) (bool, error) {
+	result, err := rule.Evaluate(ctx, address, claims)
+	if err != nil || result || !isWalletRule(rule) {
+		return result, err
+	}
+
+	ec := EvaluationContextFromContext(ctx)
+	if ec == nil {
+		return false, nil
+	}
+	for _, wallet := range ec.Wallets {
+		result, err := rule.Evaluate(ctx, wallet, claims)
+		if err != nil || result {
+			return result, err
 		}
 	}
 	return false, nil
@@ -1,10 +1,12 @@
 package policy
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/auth"
 )
 
 // TestPolicy_NewPolicy creates a valid policy
@@ -46,6 +48,31 @@ func TestPolicy_SupportsMultipleRules(t *testing.T) {
 	assert.Len(t, policy.Rules, 2)
 }
 
+// TestHasScopeRule_WildcardScopes matches hierarchical scopes against wildcards
+func TestHasScopeRule_WildcardScopes(t *testing.T) {
+	rule := NewHasScopeRule("keys:read")
+
+	tests := []struct {
+		name   string
+		scopes []string
+		want   bool
+	}{
+		{"exact", []string{"keys:read"}, true},
+		{"namespace wildcard", []string{"keys:*"}, true},
+		{"global wildcard", []string{"*"}, true},
+		{"sibling scope", []string{"keys:write"}, false},
+		{"other namespace wildcard", []string{"admin:*"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			passed, err := rule.Evaluate(context.Background(), "0x1234", &auth.Claims{Scopes: tt.scopes})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, passed)
+		})
+	}
+}
+
 // TestHasScopeRule_Creation creates scope rule
 func TestHasScopeRule_Creation(t *testing.T) {
 	rule := NewHasScopeRule("read:data")
@@ -0,0 +1,173 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/chain"
+	"go.uber.org/zap"
+)
+
+// Functions of Governor/Comp-style vote tokens reporting delegated voting power
+const (
+	getVotesFunction      = "getVotes(address)"
+	getPriorVotesFunction = "getPriorVotes(address,uint256)"
+)
+
+// VotingPowerRule checks if user has at least a minimum voting power delegated to
+// them on a governance token. Voting power counts the votes delegated to the
+// address rather than the tokens it holds, so delegates qualify and holders who
+// delegated away do not. With a block number the votes at that block are checked,
+// pinning the gate to a snapshot such as the start of a proposal.
+type VotingPowerRule struct {
+	ContractAddress string
+	MinimumVotes    *big.Int
+	BlockNumber     uint64 // 0 checks the current votes
+	ChainID         uint64
+	cacheTTL        // Optional TTL of the rule's cached reads
+	// cache and provider will be set by manager
+	cache    CacheProvider
+	provider BlockchainProvider
+	logger   *zap.Logger
+}
+
+// NewVotingPowerRule creates a new voting power rule
+func NewVotingPowerRule(contractAddress string, minimumVotes *big.Int, blockNumber, chainID uint64) *VotingPowerRule {
+	logger, _ := zap.NewProduction()
+	return &VotingPowerRule{
+		ContractAddress: contractAddress,
+		MinimumVotes:    minimumVotes,
+		BlockNumber:     blockNumber,
+		ChainID:         chainID,
+		logger:          logger,
+	}
+}
+
+// Type returns the rule type
+func (r *VotingPowerRule) Type() RuleType {
+	return VotingPowerRuleType
+}
+
+// Validate checks if the rule parameters are valid
+func (r *VotingPowerRule) Validate() error {
+	if !isValidAddress(r.ContractAddress) {
+		return fmt.Errorf("invalid contract address: %s", r.ContractAddress)
+	}
+	if r.MinimumVotes == nil || r.MinimumVotes.Sign() < 0 {
+		return fmt.Errorf("minimum votes must be a non-negative number")
+	}
+	if r.ChainID == 0 {
+		return fmt.Errorf("chain ID cannot be zero")
+	}
+	return nil
+}
+
+// Function returns the signature of the function the rule calls: getVotes for the
+// current votes, getPriorVotes for the votes at a pinned block
+func (r *VotingPowerRule) Function() string {
+	if r.BlockNumber > 0 {
+		return getPriorVotesFunction
+	}
+	return getVotesFunction
+}
+
+// Evaluate checks the voting power (requires provider and cache to be set)
+// This implementation follows fail-closed security: on any error, return false
+func (r *VotingPowerRule) Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	if !isValidAddress(address) {
+		r.logger.Error("invalid address format",
+			zap.String("address", address),
+			zap.String("rule", "VotingPower"))
+		return false, nil // Fail-closed
+	}
+
+	if r.provider == nil {
+		r.logger.Warn("no blockchain provider configured",
+			zap.String("rule", "VotingPower"))
+		return false, nil
+	}
+
+	calldata, err := r.encodeCall(address)
+	if err != nil {
+		r.logger.Error("failed to encode votes call",
+			zap.Error(err),
+			zap.String("function", r.Function()))
+		return false, nil
+	}
+
+	// Generate cache key: "voting_power:{chainID}:{contract}:{calldata}"
+	// The calldata covers the user's address and the pinned block
+	chainIDStr := strconv.FormatUint(r.ChainID, 10)
+	cacheKey := chain.CacheKey("voting_power", chainIDStr, strings.ToLower(r.ContractAddress), calldata)
+
+	var votes *big.Int
+	if r.cache != nil && !refreshingCache(ctx) {
+		if cached, ok := r.cache.Get(cacheKey); ok {
+			votes, _ = cached.(*big.Int)
+		}
+	}
+
+	if votes == nil {
+		resultHex, err := ethCall(ctx, r.provider, r.ContractAddress, calldata)
+		if err != nil {
+			logRPCFailure(r.logger, err, "RPC call failed for voting power",
+				zap.String("contract", r.ContractAddress),
+				zap.String("function", r.Function()),
+				zap.String("address", address),
+				zap.Uint64("blockNumber", r.BlockNumber),
+				zap.Uint64("chainID", r.ChainID))
+			return false, nil
+		}
+
+		votes, err = decodeWord(resultHex, 0)
+		if err != nil {
+			r.logger.Error("failed to decode voting power",
+				zap.Error(err),
+				zap.String("resultHex", resultHex))
+			return false, nil
+		}
+
+		if r.cache != nil {
+			r.cache.Set(cacheKey, votes)
+		}
+	}
+
+	hasVotes := votes.Cmp(r.MinimumVotes) >= 0
+
+	r.logger.Info("voting power check completed",
+		zap.String("address", address),
+		zap.String("contract", r.ContractAddress),
+		zap.Uint64("blockNumber", r.BlockNumber),
+		zap.String("votes", votes.String()),
+		zap.String("minimum", r.MinimumVotes.String()),
+		zap.Bool("hasVotes", hasVotes))
+
+	return hasVotes, nil
+}
+
+// encodeCall encodes the votes call for address
+func (r *VotingPowerRule) encodeCall(address string) (string, error) {
+	if r.BlockNumber > 0 {
+		return encodeFunctionCall(getPriorVotesFunction, []string{CallerArgument, strconv.FormatUint(r.BlockNumber, 10)}, address)
+	}
+	return encodeFunctionCall(getVotesFunction, []string{CallerArgument}, address)
+}
+
+// SetProvider sets the blockchain provider for RPC calls
+func (r *VotingPowerRule) SetProvider(provider BlockchainProvider) {
+	r.provider = provider
+}
+
+// SetCache sets the cache for storing results
+func (r *VotingPowerRule) SetCache(cache CacheProvider) {
+	r.cache = withCacheTTL(cache, r.CacheTTL())
+}
+
+// SetLogger sets the logger for the rule
+func (r *VotingPowerRule) SetLogger(logger *zap.Logger) {
+	r.logger = logger
+}
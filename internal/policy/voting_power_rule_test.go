@@ -0,0 +1,111 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testVotesAddr = "0xc00e94cb662c3520282e6f5717214004a7f26888" // Mock governance token
+
+// TestVotingPowerRule_Validate validates rule parameters
+func TestVotingPowerRule_Validate(t *testing.T) {
+	assert.NoError(t, NewVotingPowerRule(testVotesAddr, big.NewInt(100), 0, 1).Validate())
+	assert.NoError(t, NewVotingPowerRule(testVotesAddr, big.NewInt(100), 19000000, 1).Validate())
+
+	assert.Error(t, NewVotingPowerRule("0x1234", big.NewInt(100), 0, 1).Validate())
+	assert.Error(t, NewVotingPowerRule(testVotesAddr, nil, 0, 1).Validate())
+	assert.Error(t, NewVotingPowerRule(testVotesAddr, big.NewInt(-1), 0, 1).Validate())
+	assert.Error(t, NewVotingPowerRule(testVotesAddr, big.NewInt(100), 0, 0).Validate())
+}
+
+// TestVotingPowerRule_EncodeCall calls getVotes, or getPriorVotes at a pinned block
+func TestVotingPowerRule_EncodeCall(t *testing.T) {
+	encodedUser := strings.Repeat("0", 24) + strings.TrimPrefix(testUserAddr, "0x")
+
+	current := NewVotingPowerRule(testVotesAddr, big.NewInt(1), 0, 1)
+	calldata, err := current.encodeCall(testUserAddr)
+	require.NoError(t, err)
+	assert.Equal(t, "0x9ab24eb0"+encodedUser, calldata)
+	assert.Equal(t, "getVotes(address)", current.Function())
+
+	pinned := NewVotingPowerRule(testVotesAddr, big.NewInt(1), 19000000, 1)
+	calldata, err = pinned.encodeCall(testUserAddr)
+	require.NoError(t, err)
+	assert.Equal(t, "0x782d6fe1"+encodedUser+fmt.Sprintf("%064x", 19000000), calldata)
+	assert.Equal(t, "getPriorVotes(address,uint256)", pinned.Function())
+}
+
+// TestVotingPowerRule_Evaluate compares the delegated votes with the minimum
+func TestVotingPowerRule_Evaluate(t *testing.T) {
+	tests := []struct {
+		name     string
+		votes    int64
+		minimum  int64
+		expected bool
+	}{
+		{"votes meet minimum", 400, 400, true},
+		{"votes above minimum", 1000, 400, true},
+		{"votes below minimum", 399, 400, false},
+		{"no votes delegated", 0, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewVotingPowerRule(testVotesAddr, big.NewInt(tt.minimum), 0, 1)
+			rule.SetProvider(&stakingProvider{result: fmt.Sprintf("%064x", tt.votes)})
+
+			allowed, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, allowed)
+		})
+	}
+}
+
+// TestVotingPowerRule_Cache caches the votes per user and block
+func TestVotingPowerRule_Cache(t *testing.T) {
+	provider := &stakingProvider{result: fmt.Sprintf("%064x", 500)}
+	cache := &MockCache{}
+
+	current := NewVotingPowerRule(testVotesAddr, big.NewInt(100), 0, 1)
+	current.SetProvider(provider)
+	current.SetCache(cache)
+	pinned := NewVotingPowerRule(testVotesAddr, big.NewInt(100), 19000000, 1)
+	pinned.SetProvider(provider)
+	pinned.SetCache(cache)
+
+	for i := 0; i < 3; i++ {
+		allowed, err := current.Evaluate(context.Background(), testUserAddr, nil)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+	allowed, err := pinned.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	assert.Len(t, provider.calls, 2)
+}
+
+// TestVotingPowerRule_FailClosed denies on missing provider, RPC errors or invalid addresses
+func TestVotingPowerRule_FailClosed(t *testing.T) {
+	rule := NewVotingPowerRule(testVotesAddr, big.NewInt(0), 19000000, 1)
+	allowed, err := rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	// getPriorVotes reverts for blocks that are not yet mined
+	rule.SetProvider(&stakingProvider{err: fmt.Errorf("execution reverted: not yet determined")})
+	allowed, err = rule.Evaluate(context.Background(), testUserAddr, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	rule.SetProvider(&stakingProvider{result: fmt.Sprintf("%064x", 1)})
+	allowed, err = rule.Evaluate(context.Background(), "0x1234", nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
@@ -0,0 +1,213 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"go.uber.org/zap"
+)
+
+// Webhook rule limits
+const (
+	DefaultWebhookTimeout = 2 * time.Second
+	MaxWebhookTimeout     = 10 * time.Second
+	MaxWebhookRetries     = 3
+
+	// maxWebhookResponse bounds the response body read from the endpoint
+	maxWebhookResponse = 64 << 10
+	// webhookRetryDelay is the pause before each retry, multiplied by the attempt
+	webhookRetryDelay = 100 * time.Millisecond
+)
+
+// WebhookRule delegates the decision to an external HTTPS endpoint, so teams can
+// plug in bespoke checks without forking. The endpoint receives the subject and
+// the request as JSON and allows it by answering 200, optionally with
+// {"allow": false} to deny; any other status denies. When the endpoint cannot be
+// reached, times out or answers 5xx after every retry, the rule fails closed
+// unless FailOpen is set.
+type WebhookRule struct {
+	URL      string
+	Timeout  time.Duration // Per attempt
+	Retries  int           // Additional attempts after an unavailable endpoint
+	FailOpen bool          // Pass instead of failing when the endpoint is unavailable
+	client   *http.Client
+	logger   *zap.Logger
+}
+
+// webhookRequest is the body POSTed to the endpoint
+type webhookRequest struct {
+	Address string       `json:"address"`
+	Method  string       `json:"method,omitempty"`
+	Path    string       `json:"path,omitempty"`
+	Claims  *auth.Claims `json:"claims,omitempty"`
+}
+
+// webhookResponse is the optional body of the endpoint's answer
+type webhookResponse struct {
+	Allow *bool `json:"allow"`
+}
+
+// NewWebhookRule creates a new webhook rule. A zero timeout uses DefaultWebhookTimeout.
+func NewWebhookRule(url string, timeout time.Duration, retries int, failOpen bool) *WebhookRule {
+	logger, _ := zap.NewProduction()
+	if timeout == 0 {
+		timeout = DefaultWebhookTimeout
+	}
+	return &WebhookRule{
+		URL:      url,
+		Timeout:  timeout,
+		Retries:  retries,
+		FailOpen: failOpen,
+		client: &http.Client{
+			// A redirect is answered as is, so it denies rather than being followed
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		logger: logger,
+	}
+}
+
+// Type returns the rule type
+func (r *WebhookRule) Type() RuleType {
+	return WebhookRuleType
+}
+
+// Validate checks if the rule parameters are valid
+func (r *WebhookRule) Validate() error {
+	endpoint, err := url.Parse(r.URL)
+	if err != nil || endpoint.Host == "" {
+		return fmt.Errorf("invalid webhook url %q", r.URL)
+	}
+	// The request carries the caller's claims, which must not travel in plaintext
+	if endpoint.Scheme != "https" {
+		return fmt.Errorf("webhook url must use https")
+	}
+	if endpoint.User != nil {
+		return fmt.Errorf("webhook url must not contain credentials")
+	}
+	if r.Timeout <= 0 || r.Timeout > MaxWebhookTimeout {
+		return fmt.Errorf("timeout must be positive and at most %s", MaxWebhookTimeout)
+	}
+	if r.Retries < 0 || r.Retries > MaxWebhookRetries {
+		return fmt.Errorf("retries must be between 0 and %d", MaxWebhookRetries)
+	}
+	return nil
+}
+
+// Evaluate asks the endpoint whether the request is allowed, retrying while it is
+// unavailable. An unavailable endpoint fails the rule unless FailOpen is set.
+// Eligibility checks never call the endpoint, so the rule's outcome is unknown.
+func (r *WebhookRule) Evaluate(ctx context.Context, address string, claims *auth.Claims) (bool, error) {
+	if err := skipUncachedRead(ctx); err != nil {
+		return false, nil
+	}
+
+	payload := webhookRequest{
+		Address: strings.ToLower(address),
+		Claims:  claims,
+	}
+	if ec := EvaluationContextFromContext(ctx); ec != nil {
+		payload.Method = ec.Method
+		payload.Path = ec.Path
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		r.logger.Error("failed to encode webhook request", zap.Error(err))
+		return false, nil
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= r.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return r.unavailable(ctx.Err())
+			case <-time.After(time.Duration(attempt) * webhookRetryDelay):
+			}
+		}
+
+		allowed, err := r.call(ctx, body)
+		if err == nil {
+			r.logger.Info("webhook check completed",
+				zap.String("url", r.URL),
+				zap.String("address", payload.Address),
+				zap.Bool("allowed", allowed))
+			return allowed, nil
+		}
+		lastErr = err
+		r.logger.Warn("webhook attempt failed",
+			zap.String("url", r.URL),
+			zap.Int("attempt", attempt+1),
+			zap.Error(err))
+	}
+	return r.unavailable(lastErr)
+}
+
+// call POSTs body to the endpoint once. It returns an error only when the endpoint
+// is unavailable: unreachable, timed out or answering 5xx.
+func (r *WebhookRule) call(ctx context.Context, body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return false, fmt.Errorf("webhook answered %d", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxWebhookResponse))
+	if err != nil {
+		return false, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return true, nil
+	}
+	var decision webhookResponse
+	if err := json.Unmarshal(data, &decision); err != nil {
+		// A 200 with a body that is not a decision is not an allow
+		r.logger.Warn("invalid webhook response", zap.String("url", r.URL), zap.Error(err))
+		return false, nil
+	}
+	return decision.Allow == nil || *decision.Allow, nil
+}
+
+// unavailable resolves the outcome when the endpoint could not answer
+func (r *WebhookRule) unavailable(err error) (bool, error) {
+	r.logger.Error("webhook unavailable",
+		zap.String("url", r.URL),
+		zap.Bool("failOpen", r.FailOpen),
+		zap.Error(err))
+	return r.FailOpen, nil
+}
+
+// SetHTTPClient sets the client calling the endpoint
+func (r *WebhookRule) SetHTTPClient(client *http.Client) {
+	r.client = client
+}
+
+// SetLogger sets the logger for the rule
+func (r *WebhookRule) SetLogger(logger *zap.Logger) {
+	r.logger = logger
+}
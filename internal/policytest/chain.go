@@ -0,0 +1,172 @@
+package policytest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/yourusername/gatekeeper/internal/policy"
+)
+
+// Chain mocks the on-chain state policies read. Reads it has no mock for fail,
+// which blockchain rules treat like an RPC outage: they do not pass.
+type Chain struct {
+	// Calls answers eth_call requests
+	Calls []CallMock `json:"calls,omitempty"`
+	// Contracts are the addresses with deployed code, for code_exists rules
+	Contracts []string `json:"contracts,omitempty"`
+}
+
+// CallMock is the result of a contract call
+type CallMock struct {
+	To       string   `json:"to"`
+	Function string   `json:"function"`       // Solidity signature, e.g. "balanceOf(address)", or 4-byte selector
+	Args     []string `json:"args,omitempty"` // Addresses, decimal integers or $address for the case's address
+	// Result is an address, a decimal integer, true or false, or 0x-prefixed raw return data
+	Result string `json:"result"`
+}
+
+// merge returns the mocks of c followed by those of base, so c takes precedence
+func (c Chain) merge(base Chain) Chain {
+	return Chain{
+		Calls:     append(append([]CallMock{}, c.Calls...), base.Calls...),
+		Contracts: append(append([]string{}, c.Contracts...), base.Contracts...),
+	}
+}
+
+// provider answers JSON-RPC calls from the mocks of a chain, recording the reads
+// it could not answer
+type provider struct {
+	results   map[string]string // "to|calldata" -> 32-byte word or raw return data
+	contracts map[string]bool
+
+	mu       sync.Mutex
+	unmocked []string
+	seen     map[string]bool
+}
+
+// newProvider encodes the mocks of chain, with $address arguments replaced by address
+func newProvider(chain Chain, address string) (*provider, error) {
+	p := &provider{
+		results:   make(map[string]string),
+		contracts: make(map[string]bool),
+		seen:      make(map[string]bool),
+	}
+	for i, call := range chain.Calls {
+		args := make([]string, len(call.Args))
+		for j, arg := range call.Args {
+			if arg == policy.CallerArgument {
+				arg = address
+			}
+			args[j] = arg
+		}
+		calldata, err := policy.EncodeFunctionCall(call.Function, args...)
+		if err != nil {
+			return nil, fmt.Errorf("call mock %d: %w", i, err)
+		}
+		result, err := encodeResult(call.Result)
+		if err != nil {
+			return nil, fmt.Errorf("call mock %d: %w", i, err)
+		}
+		key := callKey(call.To, calldata)
+		// Earlier mocks take precedence
+		if _, exists := p.results[key]; !exists {
+			p.results[key] = result
+		}
+	}
+	for _, contract := range chain.Contracts {
+		p.contracts[strings.ToLower(contract)] = true
+	}
+	return p, nil
+}
+
+// Call answers eth_call and eth_getCode from the mocks
+func (p *provider) Call(ctx context.Context, method string, params []interface{}) ([]byte, error) {
+	switch method {
+	case "eth_call":
+		if len(params) > 0 {
+			if call, ok := params[0].(map[string]interface{}); ok {
+				to, _ := call["to"].(string)
+				data, _ := call["data"].(string)
+				if result, ok := p.results[callKey(to, data)]; ok {
+					return rpcResult(result)
+				}
+				return nil, p.unanswered(fmt.Sprintf("eth_call to %s with data %s", strings.ToLower(to), strings.ToLower(data)))
+			}
+		}
+	case "eth_getCode":
+		if len(params) > 0 {
+			if address, ok := params[0].(string); ok {
+				if p.contracts[strings.ToLower(address)] {
+					return rpcResult("0x6080")
+				}
+				return rpcResult("0x")
+			}
+		}
+	}
+	return nil, p.unanswered(method)
+}
+
+// HealthCheck reports the mocked chain as healthy
+func (p *provider) HealthCheck(ctx context.Context) bool {
+	return true
+}
+
+// unanswered records a read without a mock and returns its error
+func (p *provider) unanswered(read string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.seen[read] {
+		p.seen[read] = true
+		p.unmocked = append(p.unmocked, read)
+	}
+	return fmt.Errorf("no mock for %s", read)
+}
+
+// Unmocked returns the reads the provider had no mock for, in the order first made
+func (p *provider) Unmocked() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.unmocked...)
+}
+
+// callKey identifies a contract call
+func callKey(to, calldata string) string {
+	return strings.ToLower(to) + "|" + strings.ToLower(calldata)
+}
+
+// encodeResult encodes a mocked result as return data
+func encodeResult(result string) (string, error) {
+	switch {
+	case result == "true":
+		return word(big.NewInt(1)), nil
+	case result == "false":
+		return word(big.NewInt(0)), nil
+	case strings.HasPrefix(result, "0x") && len(result) == 42:
+		value, ok := new(big.Int).SetString(result[2:], 16)
+		if !ok {
+			return "", fmt.Errorf("invalid address result %q", result)
+		}
+		return word(value), nil
+	case strings.HasPrefix(result, "0x"):
+		return strings.ToLower(result), nil
+	}
+	value, ok := new(big.Int).SetString(result, 10)
+	if !ok || value.Sign() < 0 {
+		return "", fmt.Errorf("invalid result %q: use an address, a decimal integer, true, false or 0x-prefixed data", result)
+	}
+	return word(value), nil
+}
+
+// word encodes value as a 32-byte ABI word
+func word(value *big.Int) string {
+	return fmt.Sprintf("0x%064x", value)
+}
+
+// rpcResult wraps result in a JSON-RPC response
+func rpcResult(result string) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": result})
+}
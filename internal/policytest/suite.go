@@ -0,0 +1,272 @@
+// Package policytest runs table-driven tests of gatekeeper policies. A suite
+// pairs a policy file with cases that give a request, the caller's claims and
+// mocked on-chain state, and expect the policies to allow or deny it.
+package policytest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/chain"
+	"github.com/yourusername/gatekeeper/internal/policy"
+	"go.uber.org/zap"
+)
+
+// Expected outcomes of a case
+const (
+	ExpectAllow = "allow"
+	ExpectDeny  = "deny"
+)
+
+// Suite is a file of policy test cases
+type Suite struct {
+	// Policies are the policies under test: an array of policies, an object with a
+	// "policies" array as the admin API lists them, or the path of a file holding
+	// either, relative to the suite file
+	Policies json.RawMessage `json:"policies"`
+	// ChainID is the chain requests concern when a case sets no X-Chain-ID header
+	ChainID uint64 `json:"chain_id,omitempty"`
+	// Chain mocks the on-chain state of every case
+	Chain Chain `json:"chain,omitempty"`
+	// Blocklist are the addresses denied on every gated route
+	Blocklist []string `json:"blocklist,omitempty"`
+	Cases     []Case   `json:"cases"`
+
+	dir string // Directory policy file paths are relative to
+}
+
+// Case is a request and the decision the policies are expected to make on it
+type Case struct {
+	Name   string `json:"name"`
+	Method string `json:"method,omitempty"` // Defaults to GET
+	Path   string `json:"path"`             // May carry a query string
+	// Address is the caller's wallet address; empty for callers with an API key
+	// bound to no wallet
+	Address string `json:"address,omitempty"`
+	// Claims are the caller's other token claims, e.g. scopes or custom claims
+	Claims   map[string]interface{} `json:"claims,omitempty"`
+	Headers  map[string]string      `json:"headers,omitempty"`
+	ClientIP string                 `json:"client_ip,omitempty"`
+	Wallets  []string               `json:"wallets,omitempty"` // Wallets linked to the caller's account
+	// Chain mocks on-chain state for this case, taking precedence over the suite's
+	Chain  Chain  `json:"chain,omitempty"`
+	Expect string `json:"expect"`
+}
+
+// Result is the outcome of a case
+type Result struct {
+	Case     Case
+	Got      string         // allow or deny
+	DeniedBy *policy.Policy // The policy that denied the request, if any
+	Reason   string         // Why the request was denied
+	Err      error          // Evaluation error, which denies the request
+	Unmocked []string       // Chain reads the case had no mock for
+}
+
+// Passed reports whether the policies made the expected decision
+func (r Result) Passed() bool {
+	return r.Got == r.Case.Expect
+}
+
+// LoadSuite reads a suite file
+func LoadSuite(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read suite: %w", err)
+	}
+	return ParseSuite(data, filepath.Dir(path))
+}
+
+// ParseSuite parses a suite, resolving policy file paths against dir
+func ParseSuite(data []byte, dir string) (*Suite, error) {
+	var suite Suite
+	if err := json.Unmarshal(data, &suite); err != nil {
+		return nil, fmt.Errorf("failed to parse suite: %w", err)
+	}
+	suite.dir = dir
+
+	if len(suite.Policies) == 0 {
+		return nil, fmt.Errorf("suite has no policies")
+	}
+	if len(suite.Cases) == 0 {
+		return nil, fmt.Errorf("suite has no cases")
+	}
+	for i, c := range suite.Cases {
+		if c.Name == "" {
+			return nil, fmt.Errorf("case %d: name is required", i)
+		}
+		if !strings.HasPrefix(c.Path, "/") {
+			return nil, fmt.Errorf("case %q: path must start with /", c.Name)
+		}
+		if c.Expect != ExpectAllow && c.Expect != ExpectDeny {
+			return nil, fmt.Errorf("case %q: expect must be %q or %q", c.Name, ExpectAllow, ExpectDeny)
+		}
+	}
+	return &suite, nil
+}
+
+// Run evaluates every case of the suite. It fails when the policies or mocks are
+// invalid; cases that do not get the expected decision are reported in the results.
+func (s *Suite) Run(ctx context.Context) ([]Result, error) {
+	policiesJSON, err := s.policiesJSON()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := policy.NewPolicyLoader().LoadFromJSON(policiesJSON); err != nil {
+		return nil, fmt.Errorf("invalid policies: %w", err)
+	}
+
+	results := make([]Result, 0, len(s.Cases))
+	for _, c := range s.Cases {
+		result, err := s.runCase(ctx, c, policiesJSON)
+		if err != nil {
+			return nil, fmt.Errorf("case %q: %w", c.Name, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// runCase evaluates a case the way the policy middleware evaluates a request: the
+// blocklist first, then every enforced policy of the route must pass. Each case
+// gets fresh policies, so quota and cache state does not leak between cases.
+func (s *Suite) runCase(ctx context.Context, c Case, policiesJSON []byte) (Result, error) {
+	result := Result{Case: c}
+
+	provider, err := newProvider(c.Chain.merge(s.Chain), c.Address)
+	if err != nil {
+		return result, err
+	}
+	claims, err := c.claims()
+	if err != nil {
+		return result, err
+	}
+	r := c.request()
+
+	blocklist := policy.NewMemoryBlocklist(s.Blocklist...)
+	pm := policy.NewPolicyManager(provider, chain.NewCache(time.Minute))
+	pm.SetLogger(zap.NewNop())
+	pm.SetQuotaStore(policy.NewMemoryQuotaStore())
+	pm.SetBlocklist(blocklist)
+	if err := pm.LoadFromJSON(policiesJSON); err != nil {
+		return result, err
+	}
+
+	if claims.Address != "" {
+		blocked, err := blocklist.FindBlockedAddresses(ctx, []string{claims.Address})
+		if err != nil {
+			return result, err
+		}
+		if len(blocked) > 0 {
+			result.Got = ExpectDeny
+			result.Reason = "address_blocked"
+			return result, nil
+		}
+	}
+
+	var enforced []*policy.Policy
+	for _, p := range pm.GetPoliciesForRoute(r.URL.Path, r.Method) {
+		if !p.Shadow {
+			enforced = append(enforced, p)
+		}
+	}
+	if len(enforced) == 0 {
+		result.Got = ExpectAllow
+		result.Reason = "no_policies"
+		return result, nil
+	}
+
+	ec := policy.NewEvaluationContext(r, c.ClientIP, claims)
+	ec.Wallets = c.Wallets
+	ec.Params = policy.RouteParams(enforced[0].Path, r.URL.Path)
+	if ec.ChainID == 0 {
+		ec.ChainID = s.ChainID
+	}
+	evalCtx, _ := policy.WithQuotaReservations(policy.WithEvaluationContext(policy.WithRequest(ctx, r), ec))
+
+	result.Got = ExpectAllow
+	for _, p := range enforced {
+		allowed, err := p.Evaluate(evalCtx, claims.Address, claims)
+		if err != nil || !allowed {
+			result.Got = ExpectDeny
+			result.DeniedBy = p
+			result.Err = err
+			result.Reason = "policy_failed"
+			if err != nil {
+				result.Reason = "evaluation_error"
+			}
+			break
+		}
+	}
+	result.Unmocked = provider.Unmocked()
+	return result, nil
+}
+
+// policiesJSON returns the policies under test as a JSON array
+func (s *Suite) policiesJSON() ([]byte, error) {
+	data := []byte(s.Policies)
+
+	var path string
+	if err := json.Unmarshal(data, &path); err == nil {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(s.dir, path)
+		}
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read policies: %w", err)
+		}
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		var listed struct {
+			Policies json.RawMessage `json:"policies"`
+		}
+		if err := json.Unmarshal(data, &listed); err != nil {
+			return nil, fmt.Errorf("failed to parse policies: %w", err)
+		}
+		data = listed.Policies
+	}
+	return data, nil
+}
+
+// claims returns the token claims of the caller
+func (c Case) claims() (*auth.Claims, error) {
+	fields := make(map[string]interface{}, len(c.Claims)+1)
+	for name, value := range c.Claims {
+		fields[name] = value
+	}
+	if c.Address != "" {
+		fields["address"] = c.Address
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("invalid claims: %w", err)
+	}
+	var claims auth.Claims
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return nil, fmt.Errorf("invalid claims: %w", err)
+	}
+	return &claims, nil
+}
+
+// request builds the request of the case
+func (c Case) request() *http.Request {
+	method := c.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	r := httptest.NewRequest(strings.ToUpper(method), c.Path, nil)
+	for name, value := range c.Headers {
+		r.Header.Set(name, value)
+	}
+	return r
+}
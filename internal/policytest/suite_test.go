@@ -0,0 +1,117 @@
+package policytest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	token  = "0x1234567890123456789012345678901234567890"
+	holder = "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	other  = "0xbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+)
+
+const testSuite = `{
+	"policies": [
+		{"path": "/api/admin", "method": "GET", "logic": "AND", "rules": [{"type": "has_scope", "scope": "admin"}]},
+		{"path": "/api/holders", "method": "GET", "logic": "AND", "rules": [
+			{"type": "erc20_min_balance", "contract_address": "` + token + `", "minimum_balance": "100", "chain_id": 1}
+		]}
+	],
+	"chain": {"calls": [{"to": "` + token + `", "function": "balanceOf(address)", "args": ["$address"], "result": "0"}]},
+	"blocklist": ["` + other + `"],
+	"cases": [
+		{"name": "admin scope", "path": "/api/admin", "address": "` + holder + `", "claims": {"scopes": ["admin"]}, "expect": "allow"},
+		{"name": "no admin scope", "path": "/api/admin", "address": "` + holder + `", "expect": "deny"},
+		{"name": "holder", "path": "/api/holders", "address": "` + holder + `", "expect": "allow",
+			"chain": {"calls": [{"to": "` + token + `", "function": "balanceOf(address)", "args": ["$address"], "result": "250"}]}},
+		{"name": "empty wallet", "path": "/api/holders", "address": "` + holder + `", "expect": "deny"},
+		{"name": "ungated route", "path": "/api/public", "address": "` + holder + `", "expect": "allow"},
+		{"name": "blocked caller", "path": "/api/public", "address": "` + other + `", "expect": "deny"}
+	]
+}`
+
+func TestSuiteRun(t *testing.T) {
+	suite, err := ParseSuite([]byte(testSuite), ".")
+	require.NoError(t, err)
+
+	results, err := suite.Run(context.Background())
+	require.NoError(t, err)
+	require.Len(t, results, 6)
+
+	for _, result := range results {
+		assert.True(t, result.Passed(), "case %q: expected %s, got %s", result.Case.Name, result.Case.Expect, result.Got)
+		assert.Empty(t, result.Unmocked, result.Case.Name)
+	}
+	assert.Equal(t, "/api/admin", results[1].DeniedBy.Path)
+	assert.Equal(t, "no_policies", results[4].Reason)
+	assert.Equal(t, "address_blocked", results[5].Reason)
+}
+
+func TestSuiteRun_ReportsFailedCasesAndUnmockedReads(t *testing.T) {
+	suite, err := ParseSuite([]byte(`{
+		"policies": [{"path": "/api/holders", "method": "GET", "logic": "AND", "rules": [
+			{"type": "erc20_min_balance", "contract_address": "`+token+`", "minimum_balance": "100", "chain_id": 1}
+		]}],
+		"cases": [{"name": "holder", "path": "/api/holders", "address": "`+holder+`", "expect": "allow"}]
+	}`), ".")
+	require.NoError(t, err)
+
+	results, err := suite.Run(context.Background())
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Passed())
+	assert.Equal(t, ExpectDeny, results[0].Got)
+	assert.NotEmpty(t, results[0].Unmocked)
+}
+
+func TestLoadSuite_PolicyFile(t *testing.T) {
+	dir := t.TempDir()
+	policies := `{"policies": [{"path": "/api/admin", "method": "GET", "logic": "AND", "rules": [{"type": "has_scope", "scope": "admin"}]}]}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "policies.json"), []byte(policies), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "suite.json"), []byte(`{
+		"policies": "policies.json",
+		"cases": [{"name": "no scope", "method": "get", "path": "/api/admin", "address": "`+holder+`", "expect": "deny"}]
+	}`), 0o600))
+
+	suite, err := LoadSuite(filepath.Join(dir, "suite.json"))
+	require.NoError(t, err)
+	results, err := suite.Run(context.Background())
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Passed())
+}
+
+func TestParseSuite_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		suite string
+	}{
+		{"no policies", `{"cases": [{"name": "a", "path": "/", "expect": "allow"}]}`},
+		{"no cases", `{"policies": []}`},
+		{"unnamed case", `{"policies": [], "cases": [{"path": "/", "expect": "allow"}]}`},
+		{"relative path", `{"policies": [], "cases": [{"name": "a", "path": "api", "expect": "allow"}]}`},
+		{"unknown expectation", `{"policies": [], "cases": [{"name": "a", "path": "/", "expect": "maybe"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSuite([]byte(tt.suite), ".")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSuiteRun_InvalidPolicies(t *testing.T) {
+	suite, err := ParseSuite([]byte(`{
+		"policies": [{"path": "/api", "method": "GET", "logic": "AND", "rules": [{"type": "unknown"}]}],
+		"cases": [{"name": "a", "path": "/api", "expect": "deny"}]
+	}`), ".")
+	require.NoError(t, err)
+	_, err = suite.Run(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,190 @@
+// Package securitylog keeps the security events of each wallet, taken from the
+// audit log, so users can review their own account activity: sign-ins, key and
+// credential changes and denied requests.
+package securitylog
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/gatekeeper/internal/audit"
+	"github.com/yourusername/gatekeeper/internal/store"
+	"go.uber.org/zap"
+)
+
+// Defaults of the recorder
+const (
+	bufferSize    = 1000
+	pruneInterval = time.Hour
+	writeTimeout  = 5 * time.Second
+)
+
+// recordedActions are the audit actions recorded for the wallet that performed
+// them. Successful authentications are only recorded for sign-ins, not for each
+// request made with an API key.
+var recordedActions = map[audit.ActionType]bool{
+	audit.ActionAuthSuccess:        true,
+	audit.ActionAuthFailure:        true,
+	audit.ActionSessionRevoked:     true,
+	audit.ActionAPIKeyCreated:      true,
+	audit.ActionAPIKeyRevoked:      true,
+	audit.ActionAPIKeysRevoked:     true,
+	audit.ActionAPIKeyTransferred:  true,
+	audit.ActionAPIKeysSunset:      true,
+	audit.ActionWalletLinked:       true,
+	audit.ActionWalletUnlinked:     true,
+	audit.ActionWebAuthnRegistered: true,
+	audit.ActionWebAuthnRemoved:    true,
+	audit.ActionAuthzDenied:        true,
+}
+
+// Recorder is an audit.Sink that stores the security events of wallets. Writes
+// only queue events; they are stored in the background, and events older than the
+// retention period are deleted periodically.
+type Recorder struct {
+	repo      store.SecurityEventRepositoryInterface
+	retention time.Duration // Zero keeps events forever
+	logger    *zap.Logger
+
+	events   chan store.SecurityEvent
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// Ensure Recorder implements audit.Sink
+var _ audit.Sink = (*Recorder)(nil)
+
+// NewRecorder creates a recorder storing events in repo and starts storing them
+func NewRecorder(repo store.SecurityEventRepositoryInterface, retention time.Duration, logger *zap.Logger) *Recorder {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	r := &Recorder{
+		repo:      repo,
+		retention: retention,
+		logger:    logger,
+		events:    make(chan store.SecurityEvent, bufferSize),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// Write queues the event if it is a security event of a wallet. Events are
+// dropped, with a warning, while the queue is full.
+func (r *Recorder) Write(event audit.AuditEvent) {
+	securityEvent, ok := SecurityEvent(event)
+	if !ok {
+		return
+	}
+	select {
+	case r.events <- securityEvent:
+	default:
+		r.logger.Warn("security event queue full, dropping event",
+			zap.String("action", securityEvent.Action))
+	}
+}
+
+// Close stores the events still queued and stops the recorder
+func (r *Recorder) Close(ctx context.Context) error {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run stores queued events and prunes old ones until the recorder is closed
+func (r *Recorder) run() {
+	defer close(r.done)
+
+	var prune <-chan time.Time
+	if r.retention > 0 {
+		ticker := time.NewTicker(pruneInterval)
+		defer ticker.Stop()
+		prune = ticker.C
+		r.prune()
+	}
+
+	for {
+		select {
+		case event := <-r.events:
+			r.save(event)
+		case <-prune:
+			r.prune()
+		case <-r.stop:
+			for {
+				select {
+				case event := <-r.events:
+					r.save(event)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// save stores one event; failures are logged, never retried
+func (r *Recorder) save(event store.SecurityEvent) {
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+
+	if err := r.repo.CreateSecurityEvent(ctx, &event); err != nil {
+		r.logger.Error("failed to record security event",
+			zap.String("action", event.Action),
+			zap.Error(err))
+	}
+}
+
+// prune deletes events older than the retention period
+func (r *Recorder) prune() {
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+
+	deleted, err := r.repo.DeleteSecurityEventsBefore(ctx, time.Now().Add(-r.retention))
+	if err != nil {
+		r.logger.Error("failed to prune security events", zap.Error(err))
+		return
+	}
+	if deleted > 0 {
+		r.logger.Info("pruned security events", zap.Int64("deleted", deleted))
+	}
+}
+
+// SecurityEvent returns the security event of the wallet that performed an audited
+// action, and false for actions that are not recorded or have no wallet
+func SecurityEvent(event audit.AuditEvent) (store.SecurityEvent, bool) {
+	if !recordedActions[event.Action] || event.UserAddr == "" {
+		return store.SecurityEvent{}, false
+	}
+	if event.Action == audit.ActionAuthSuccess && event.KeyID != 0 {
+		return store.SecurityEvent{}, false
+	}
+
+	securityEvent := store.SecurityEvent{
+		Subject:   strings.ToLower(event.UserAddr),
+		Action:    string(event.Action),
+		Result:    string(event.Result),
+		IPAddress: event.IPAddr,
+		UserAgent: event.UserAgent,
+		Method:    event.Method,
+		Endpoint:  event.Endpoint,
+		KeyName:   event.KeyName,
+		Error:     event.Error,
+		CreatedAt: event.Timestamp,
+	}
+	if event.KeyID != 0 {
+		keyID := event.KeyID
+		securityEvent.KeyID = &keyID
+	}
+	return securityEvent, true
+}
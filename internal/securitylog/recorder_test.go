@@ -0,0 +1,108 @@
+package securitylog
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/gatekeeper/internal/audit"
+	"github.com/yourusername/gatekeeper/internal/store"
+)
+
+// memoryRepository keeps security events in memory
+type memoryRepository struct {
+	mu      sync.Mutex
+	events  []store.SecurityEvent
+	cutoffs []time.Time
+}
+
+func (m *memoryRepository) CreateSecurityEvent(ctx context.Context, event *store.SecurityEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	event.ID = int64(len(m.events) + 1)
+	m.events = append(m.events, *event)
+	return nil
+}
+
+func (m *memoryRepository) ListSecurityEvents(ctx context.Context, subject string, beforeID int64, limit int) ([]store.SecurityEvent, error) {
+	return nil, nil
+}
+
+func (m *memoryRepository) DeleteSecurityEventsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cutoffs = append(m.cutoffs, cutoff)
+	return 0, nil
+}
+
+func TestSecurityEvent(t *testing.T) {
+	const address = "0x742D35CC6634C0532925A3B844BC9E7595F0BEB0"
+
+	tests := []struct {
+		name     string
+		event    audit.AuditEvent
+		recorded bool
+	}{
+		{"sign-in", audit.AuditEvent{Action: audit.ActionAuthSuccess, UserAddr: address}, true},
+		{"request with an API key", audit.AuditEvent{Action: audit.ActionAuthSuccess, UserAddr: address, KeyID: 3}, false},
+		{"key created", audit.AuditEvent{Action: audit.ActionAPIKeyCreated, UserAddr: address, KeyID: 3}, true},
+		{"denied request", audit.AuditEvent{Action: audit.ActionAuthzDenied, UserAddr: address}, true},
+		{"granted request", audit.AuditEvent{Action: audit.ActionAuthzGranted, UserAddr: address}, false},
+		{"no wallet", audit.AuditEvent{Action: audit.ActionAuthFailure, ServiceAccount: "indexer"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, recorded := SecurityEvent(tt.event)
+			assert.Equal(t, tt.recorded, recorded)
+		})
+	}
+
+	event, ok := SecurityEvent(audit.AuditEvent{
+		Action:    audit.ActionAPIKeyRevoked,
+		Result:    audit.ResultSuccess,
+		UserAddr:  address,
+		KeyID:     3,
+		KeyName:   "ci",
+		IPAddr:    "203.0.113.7",
+		UserAgent: "curl/8.0",
+		Method:    "DELETE",
+		Endpoint:  "/api/keys/3",
+	})
+	require.True(t, ok)
+	assert.Equal(t, "0x742d35cc6634c0532925a3b844bc9e7595f0beb0", event.Subject)
+	assert.Equal(t, "api_key_revoked", event.Action)
+	assert.Equal(t, "success", event.Result)
+	require.NotNil(t, event.KeyID)
+	assert.Equal(t, int64(3), *event.KeyID)
+	assert.Equal(t, "ci", event.KeyName)
+	assert.Equal(t, "/api/keys/3", event.Endpoint)
+}
+
+func TestRecorder_StoresQueuedEventsOnClose(t *testing.T) {
+	repo := &memoryRepository{}
+	recorder := NewRecorder(repo, 24*time.Hour, nil)
+
+	recorder.Write(audit.AuditEvent{Action: audit.ActionAuthSuccess, UserAddr: "0xabc"})
+	recorder.Write(audit.AuditEvent{Action: audit.ActionAPIKeyUsed, UserAddr: "0xabc"})
+	recorder.Write(audit.AuditEvent{Action: audit.ActionAuthzDenied, UserAddr: "0xabc", Endpoint: "/api/data"})
+	require.NoError(t, recorder.Close(context.Background()))
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	require.Len(t, repo.events, 2)
+	assert.Equal(t, "auth_success", repo.events[0].Action)
+	assert.Equal(t, "/api/data", repo.events[1].Endpoint)
+	require.Len(t, repo.cutoffs, 1, "old events are pruned on start")
+	assert.WithinDuration(t, time.Now().Add(-24*time.Hour), repo.cutoffs[0], time.Minute)
+}
+
+func TestRecorder_NoRetentionKeepsEvents(t *testing.T) {
+	repo := &memoryRepository{}
+	recorder := NewRecorder(repo, 0, nil)
+	require.NoError(t, recorder.Close(context.Background()))
+	assert.Empty(t, repo.cutoffs)
+}
@@ -0,0 +1,157 @@
+package soak
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/yourusername/gatekeeper/internal/audit"
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/chain"
+	httpserver "github.com/yourusername/gatekeeper/internal/http"
+	"github.com/yourusername/gatekeeper/internal/log"
+	"github.com/yourusername/gatekeeper/internal/policy"
+)
+
+const (
+	// dataPath is the gated route the traffic is sent to
+	dataPath = "/api/data"
+
+	// tokenContract is the ERC-20 contract of the synthetic policy
+	tokenContract = "0x1f9840a85d5af5bf1d1762f925bdaddc4201f984"
+
+	// minimumBalance is the balance the synthetic policy requires
+	minimumBalance = 1000
+)
+
+// newHandler assembles the production authentication, rate limiting and policy
+// middleware in front of the data route. Every request carries a JWT, counts
+// against the per-user API usage limit and is checked by an ERC-20 balance policy
+// answered by provider through cache.
+func newHandler(cfg Config, jwtService *auth.JWTService, provider policy.BlockchainProvider, cache policy.CacheProvider) http.Handler {
+	logger := log.NewNop()
+
+	policyManager := policy.NewPolicyManager(provider, cache)
+	policyManager.SetLogger(zap.NewNop())
+	policyManager.AddPolicy(policy.NewPolicy("GET", dataPath, "AND", []policy.Rule{
+		policy.NewERC20MinBalanceRule(tokenContract, big.NewInt(minimumBalance), 1),
+	}))
+
+	limiter := httpserver.NewInMemoryRateLimiter(cfg.RateLimit, time.Minute, cfg.RateBurst)
+	rateLimiter := httpserver.NewUserRateLimitMiddleware(limiter, logger, httpserver.WithLimitName("api_usage"))
+	policyMiddleware := httpserver.NewPolicyMiddleware(policyManager, logger, audit.NewAuditLogger(zap.NewNop()))
+
+	router := mux.NewRouter()
+	apiRouter := router.PathPrefix("/api").Subrouter()
+	apiRouter.Use(mux.MiddlewareFunc(httpserver.JWTMiddleware(jwtService)))
+	apiRouter.Use(mux.MiddlewareFunc(rateLimiter.Middleware()))
+	apiRouter.Use(mux.MiddlewareFunc(policyMiddleware.Middleware()))
+	apiRouter.HandleFunc("/data", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"message":"Access granted","address":"%s"}`, httpserver.ClaimsFromContext(r).Address)
+	}).Methods("GET")
+
+	return router
+}
+
+// clientAddress returns the address of the synthetic client i
+func clientAddress(i int) string {
+	return fmt.Sprintf("0x%040x", i+1)
+}
+
+// holdsTokens reports whether the synthetic client at address holds the minimum
+// balance: every other client does, so the policy both grants and denies
+func holdsTokens(address string) bool {
+	if address == "" {
+		return false
+	}
+	last, err := strconv.ParseUint(address[len(address)-1:], 16, 8)
+	return err == nil && last%2 == 1
+}
+
+// syntheticProvider answers balanceOf calls without a node, optionally after a
+// delay standing in for RPC latency, and counts the calls that reach it
+type syntheticProvider struct {
+	latency time.Duration
+	calls   atomic.Int64
+}
+
+// Call answers eth_call with the balance of the account in the calldata
+func (p *syntheticProvider) Call(ctx context.Context, method string, params []interface{}) ([]byte, error) {
+	p.calls.Add(1)
+	if p.latency > 0 {
+		select {
+		case <-time.After(p.latency):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if method != "eth_call" || len(params) == 0 {
+		return nil, fmt.Errorf("unsupported method: %s", method)
+	}
+	call, ok := params[0].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid call object")
+	}
+	data, _ := call["data"].(string)
+	if len(data) < 74 {
+		return nil, fmt.Errorf("invalid calldata")
+	}
+
+	balance := big.NewInt(0)
+	if holdsTokens("0x" + data[34:74]) {
+		balance.SetInt64(minimumBalance)
+	}
+	return []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"result":"0x%064x"}`, balance)), nil
+}
+
+// HealthCheck always reports the provider healthy
+func (p *syntheticProvider) HealthCheck(ctx context.Context) bool {
+	return true
+}
+
+// countingCache counts the hits and misses of the cache it wraps
+type countingCache struct {
+	cache  *chain.Cache
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// Get looks the key up, counting a hit or a miss
+func (c *countingCache) Get(key string) (interface{}, bool) {
+	value, ok := c.cache.Get(key)
+	if ok {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	return value, ok
+}
+
+// Set stores the value
+func (c *countingCache) Set(key string, value interface{}) {
+	c.cache.Set(key, value)
+}
+
+// SetWithTTL stores the value expiring after ttl
+func (c *countingCache) SetWithTTL(key string, value interface{}, ttl time.Duration) {
+	c.cache.SetWithTTL(key, value, ttl)
+}
+
+// GetOrSet returns the cached value, computing and storing it on a miss
+func (c *countingCache) GetOrSet(key string, fn func() interface{}) interface{} {
+	if value, ok := c.Get(key); ok {
+		return value
+	}
+	value := fn()
+	c.cache.Set(key, value)
+	return value
+}
@@ -0,0 +1,388 @@
+// Package soak generates synthetic authenticated traffic against an in-process
+// server, to validate rate limiter fairness, blockchain cache hit rates and memory
+// growth before a release. The server runs the production JWT, rate limiting and
+// policy middleware in front of an ERC-20 balance policy, answered by a synthetic
+// provider instead of a node, so a run needs no database or RPC endpoint.
+//
+// Run sends requests from Config.Clients wallets, spread evenly, for
+// Config.Duration and returns a Report; Report.Check compares it with release
+// thresholds.
+package soak
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yourusername/gatekeeper/internal/auth"
+	"github.com/yourusername/gatekeeper/internal/chain"
+)
+
+// Config configures a soak run
+type Config struct {
+	// Clients is the number of distinct wallets sending traffic
+	Clients int
+	// Concurrency is the number of requests in flight
+	Concurrency int
+	// Duration is how long traffic is sent
+	Duration time.Duration
+	// RateLimit and RateBurst are the per-user API usage limit, in requests per minute
+	RateLimit int
+	RateBurst int
+	// CacheTTL is the TTL of cached blockchain results
+	CacheTTL time.Duration
+	// RPCLatency delays every call that misses the cache
+	RPCLatency time.Duration
+	// SampleInterval is how often heap usage is sampled
+	SampleInterval time.Duration
+}
+
+// DefaultConfig returns a one-minute run of 100 clients, each offered more
+// traffic than the limit admits
+func DefaultConfig() Config {
+	return Config{
+		Clients:        100,
+		Concurrency:    32,
+		Duration:       time.Minute,
+		RateLimit:      600,
+		RateBurst:      20,
+		CacheTTL:       5 * time.Minute,
+		RPCLatency:     20 * time.Millisecond,
+		SampleInterval: time.Second,
+	}
+}
+
+// Validate checks the configuration can produce a run
+func (c Config) Validate() error {
+	if c.Clients <= 0 {
+		return fmt.Errorf("clients must be positive")
+	}
+	if c.Concurrency <= 0 {
+		return fmt.Errorf("concurrency must be positive")
+	}
+	if c.Duration <= 0 {
+		return fmt.Errorf("duration must be positive")
+	}
+	if c.RateLimit <= 0 || c.RateBurst <= 0 {
+		return fmt.Errorf("rate limit and burst must be positive")
+	}
+	if c.CacheTTL <= 0 {
+		return fmt.Errorf("cache TTL must be positive")
+	}
+	if c.SampleInterval <= 0 {
+		return fmt.Errorf("sample interval must be positive")
+	}
+	return nil
+}
+
+// Report is the outcome of a soak run
+type Report struct {
+	Duration time.Duration
+
+	// Requests counts the responses by outcome: Granted (2xx), Denied by the
+	// policy (403), Limited by the rate limiter (429), and Errors (anything else,
+	// including transport failures)
+	Requests int64
+	Granted  int64
+	Denied   int64
+	Limited  int64
+	Errors   int64
+
+	// Fairness is Jain's fairness index of the requests each client had admitted
+	// by the rate limiter: 1 when every client got the same share, 1/Clients when
+	// one client got everything. MinAdmitted and MaxAdmitted bound the shares.
+	Fairness    float64
+	MinAdmitted int64
+	MaxAdmitted int64
+
+	// CacheHits and CacheMisses count lookups of blockchain results; RPCCalls the
+	// calls that reached the provider
+	CacheHits   int64
+	CacheMisses int64
+	RPCCalls    int64
+
+	// HeapStart and HeapEnd are the live heap after a GC before and after the run,
+	// HeapPeak the largest heap sampled during it
+	HeapStart uint64
+	HeapEnd   uint64
+	HeapPeak  uint64
+
+	// GoroutinesStart and GoroutinesEnd count goroutines before and after the run
+	GoroutinesStart int
+	GoroutinesEnd   int
+}
+
+// CacheHitRate returns the share of cache lookups that hit, or 0 without lookups
+func (r *Report) CacheHitRate() float64 {
+	lookups := r.CacheHits + r.CacheMisses
+	if lookups == 0 {
+		return 0
+	}
+	return float64(r.CacheHits) / float64(lookups)
+}
+
+// HeapGrowth returns how much the live heap grew over the run, or 0 if it shrank
+func (r *Report) HeapGrowth() uint64 {
+	if r.HeapEnd <= r.HeapStart {
+		return 0
+	}
+	return r.HeapEnd - r.HeapStart
+}
+
+// Thresholds are the bounds a run must stay within to pass
+type Thresholds struct {
+	// MinFairness is the lowest acceptable fairness index
+	MinFairness float64
+	// MinCacheHitRate is the lowest acceptable cache hit rate
+	MinCacheHitRate float64
+	// MaxHeapGrowth is the largest acceptable heap growth in bytes; 0 disables the check
+	MaxHeapGrowth uint64
+}
+
+// DefaultThresholds returns the bounds for a release
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		MinFairness:     0.95,
+		MinCacheHitRate: 0.9,
+		MaxHeapGrowth:   64 << 20,
+	}
+}
+
+// Check returns an error naming every threshold the run violated
+func (r *Report) Check(t Thresholds) error {
+	var violations []string
+	if r.Fairness < t.MinFairness {
+		violations = append(violations, fmt.Sprintf("fairness %.3f below %.3f", r.Fairness, t.MinFairness))
+	}
+	if r.CacheHitRate() < t.MinCacheHitRate {
+		violations = append(violations, fmt.Sprintf("cache hit rate %.3f below %.3f", r.CacheHitRate(), t.MinCacheHitRate))
+	}
+	if t.MaxHeapGrowth > 0 && r.HeapGrowth() > t.MaxHeapGrowth {
+		violations = append(violations, fmt.Sprintf("heap grew %d bytes, above %d", r.HeapGrowth(), t.MaxHeapGrowth))
+	}
+	if r.Errors > 0 {
+		violations = append(violations, fmt.Sprintf("%d requests failed", r.Errors))
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return fmt.Errorf("soak thresholds violated: %v", violations)
+}
+
+// Print writes the report in a human-readable form
+func (r *Report) Print(w io.Writer) {
+	seconds := r.Duration.Seconds()
+	if seconds <= 0 {
+		seconds = 1
+	}
+	fmt.Fprintf(w, "duration:     %s\n", r.Duration.Round(time.Millisecond))
+	fmt.Fprintf(w, "requests:     %d (%.0f/s)\n", r.Requests, float64(r.Requests)/seconds)
+	fmt.Fprintf(w, "  granted:    %d\n", r.Granted)
+	fmt.Fprintf(w, "  denied:     %d\n", r.Denied)
+	fmt.Fprintf(w, "  limited:    %d\n", r.Limited)
+	fmt.Fprintf(w, "  errors:     %d\n", r.Errors)
+	fmt.Fprintf(w, "fairness:     %.3f (admitted per client %d..%d)\n", r.Fairness, r.MinAdmitted, r.MaxAdmitted)
+	fmt.Fprintf(w, "cache:        %.1f%% hits (%d hits, %d misses, %d RPC calls)\n", 100*r.CacheHitRate(), r.CacheHits, r.CacheMisses, r.RPCCalls)
+	fmt.Fprintf(w, "heap:         start %s, peak %s, end %s\n", formatBytes(r.HeapStart), formatBytes(r.HeapPeak), formatBytes(r.HeapEnd))
+	fmt.Fprintf(w, "goroutines:   start %d, end %d\n", r.GoroutinesStart, r.GoroutinesEnd)
+}
+
+// Run sends traffic to an in-process server for cfg.Duration, or until ctx is done,
+// and reports what happened
+func Run(ctx context.Context, cfg Config) (*Report, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate JWT secret: %w", err)
+	}
+	jwtService := auth.NewJWTService(secret, cfg.Duration+time.Hour)
+
+	tokens := make([]string, cfg.Clients)
+	for i := range tokens {
+		token, err := jwtService.GenerateToken(ctx, clientAddress(i), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to issue token: %w", err)
+		}
+		tokens[i] = token
+	}
+
+	provider := &syntheticProvider{latency: cfg.RPCLatency}
+	cache := &countingCache{cache: chain.NewCache(cfg.CacheTTL)}
+	server := httptest.NewServer(newHandler(cfg, jwtService, provider, cache))
+	transport := &http.Transport{MaxIdleConnsPerHost: cfg.Concurrency}
+	client := &http.Client{Transport: transport, Timeout: 30 * time.Second}
+
+	report := &Report{}
+	report.HeapStart, report.GoroutinesStart = liveHeap(), runtime.NumGoroutine()
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Duration)
+	defer cancel()
+
+	sampler := newHeapSampler(cfg.SampleInterval)
+	admitted := make([]atomic.Int64, cfg.Clients)
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	start := time.Now()
+	for w := 0; w < cfg.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				i := int(next.Add(1)-1) % cfg.Clients
+				status := send(ctx, client, server.URL+dataPath, tokens[i])
+				if ctx.Err() != nil && status == 0 {
+					// Cut off by the end of the run
+					return
+				}
+				report.record(status)
+				if status != http.StatusTooManyRequests && status != 0 {
+					admitted[i].Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	report.Duration = time.Since(start)
+	report.HeapPeak = sampler.stop()
+
+	server.Close()
+	transport.CloseIdleConnections()
+
+	counts := make([]int64, cfg.Clients)
+	for i := range admitted {
+		counts[i] = admitted[i].Load()
+	}
+	report.Fairness, report.MinAdmitted, report.MaxAdmitted = fairness(counts)
+	report.CacheHits, report.CacheMisses = cache.hits.Load(), cache.misses.Load()
+	report.RPCCalls = provider.calls.Load()
+	report.HeapEnd, report.GoroutinesEnd = liveHeap(), runtime.NumGoroutine()
+	return report, nil
+}
+
+// send performs one request and returns its status, or 0 if it failed
+func send(ctx context.Context, client *http.Client, url, token string) int {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return 0
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode
+}
+
+// record counts a response by its status
+func (r *Report) record(status int) {
+	atomic.AddInt64(&r.Requests, 1)
+	switch {
+	case status >= 200 && status < 300:
+		atomic.AddInt64(&r.Granted, 1)
+	case status == http.StatusForbidden:
+		atomic.AddInt64(&r.Denied, 1)
+	case status == http.StatusTooManyRequests:
+		atomic.AddInt64(&r.Limited, 1)
+	default:
+		atomic.AddInt64(&r.Errors, 1)
+	}
+}
+
+// fairness returns Jain's fairness index of counts with their minimum and maximum.
+// The index is 1 when nothing was admitted at all, since no client was favored.
+func fairness(counts []int64) (float64, int64, int64) {
+	if len(counts) == 0 {
+		return 1, 0, 0
+	}
+
+	var sum, sumSquares float64
+	min, max := int64(math.MaxInt64), int64(0)
+	for _, c := range counts {
+		sum += float64(c)
+		sumSquares += float64(c) * float64(c)
+		if c < min {
+			min = c
+		}
+		if c > max {
+			max = c
+		}
+	}
+	if sumSquares == 0 {
+		return 1, min, max
+	}
+	return sum * sum / (float64(len(counts)) * sumSquares), min, max
+}
+
+// liveHeap returns the heap in use after a garbage collection
+func liveHeap() uint64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+// heapSampler records the largest heap seen while it runs
+type heapSampler struct {
+	peak atomic.Uint64
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newHeapSampler samples the heap every interval until stop is called
+func newHeapSampler(interval time.Duration) *heapSampler {
+	s := &heapSampler{done: make(chan struct{})}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			s.sample()
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return s
+}
+
+// sample records the current heap if it is the largest so far
+func (s *heapSampler) sample() {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	for {
+		peak := s.peak.Load()
+		if stats.HeapAlloc <= peak || s.peak.CompareAndSwap(peak, stats.HeapAlloc) {
+			return
+		}
+	}
+}
+
+// stop takes a last sample and returns the peak
+func (s *heapSampler) stop() uint64 {
+	close(s.done)
+	s.wg.Wait()
+	s.sample()
+	return s.peak.Load()
+}
+
+// formatBytes formats a byte count in MiB
+func formatBytes(n uint64) string {
+	return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+}
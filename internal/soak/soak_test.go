@@ -0,0 +1,92 @@
+package soak
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Clients = 10
+	cfg.Concurrency = 4
+	cfg.Duration = 500 * time.Millisecond
+	cfg.RPCLatency = time.Millisecond
+	cfg.SampleInterval = 50 * time.Millisecond
+
+	report, err := Run(context.Background(), cfg)
+	require.NoError(t, err)
+
+	assert.Positive(t, report.Requests)
+	assert.Equal(t, report.Requests, report.Granted+report.Denied+report.Limited+report.Errors)
+	assert.Zero(t, report.Errors)
+	assert.Positive(t, report.Granted, "half of the clients hold the token")
+	assert.Positive(t, report.Denied, "the other half do not")
+	assert.Positive(t, report.Limited, "clients are offered more than the burst")
+	assert.Greater(t, report.Fairness, 0.9)
+	assert.Positive(t, report.CacheHits)
+	assert.LessOrEqual(t, report.RPCCalls, report.CacheMisses)
+	assert.Positive(t, report.HeapPeak)
+
+	var out bytes.Buffer
+	report.Print(&out)
+	assert.Contains(t, out.String(), "fairness:")
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, DefaultConfig().Validate())
+
+	cfg := DefaultConfig()
+	cfg.Clients = 0
+	assert.Error(t, cfg.Validate())
+
+	cfg = DefaultConfig()
+	cfg.Duration = 0
+	assert.Error(t, cfg.Validate())
+
+	_, err := Run(context.Background(), cfg)
+	assert.Error(t, err)
+}
+
+func TestFairness(t *testing.T) {
+	index, min, max := fairness([]int64{5, 5, 5, 5})
+	assert.InDelta(t, 1, index, 1e-9)
+	assert.Equal(t, int64(5), min)
+	assert.Equal(t, int64(5), max)
+
+	index, _, _ = fairness([]int64{8, 0, 0, 0})
+	assert.InDelta(t, 0.25, index, 1e-9, "one client got everything")
+
+	index, _, _ = fairness([]int64{0, 0})
+	assert.Equal(t, float64(1), index)
+}
+
+func TestReport_Check(t *testing.T) {
+	report := &Report{Fairness: 0.99, CacheHits: 95, CacheMisses: 5, HeapStart: 10 << 20, HeapEnd: 12 << 20}
+	assert.NoError(t, report.Check(DefaultThresholds()))
+
+	report.Fairness = 0.5
+	report.CacheHits = 10
+	report.HeapEnd = 200 << 20
+	report.Errors = 3
+	err := report.Check(DefaultThresholds())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "fairness")
+	assert.Contains(t, err.Error(), "cache hit rate")
+	assert.Contains(t, err.Error(), "heap grew")
+	assert.Contains(t, err.Error(), "3 requests failed")
+
+	report = &Report{HeapStart: 10, HeapEnd: 5}
+	assert.Zero(t, report.HeapGrowth())
+	assert.Zero(t, report.CacheHitRate())
+}
+
+func TestHoldsTokens(t *testing.T) {
+	assert.True(t, holdsTokens(clientAddress(0)))
+	assert.False(t, holdsTokens(clientAddress(1)))
+	assert.True(t, holdsTokens("0xABCDEF0000000000000000000000000000000003"))
+}
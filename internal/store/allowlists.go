@@ -3,7 +3,9 @@ package store
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/lib/pq"
@@ -32,7 +34,31 @@ type AllowlistWithCount struct {
 	EntryCount int64 `db:"entry_count"`
 }
 
-// AllowlistRepository provides methods for managing allowlists
+// allowlistState is an allowlist as recorded in the change history
+type allowlistState struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	EntryCount  *int64 `json:"entryCount,omitempty"` // Recorded when the allowlist is deleted
+}
+
+// addressesChange lists the addresses added to or removed from an allowlist in the
+// change history
+type addressesChange struct {
+	Addresses []string `json:"addresses"`
+}
+
+// AddressChange is a set of addresses added to or removed from an allowlist by one
+// mutation, read back from the change history
+type AddressChange struct {
+	ID        int64  // Change history ID, increasing in commit order per allowlist
+	Action    string // ChangeAddressesAdded or ChangeAddressRemoved
+	Addresses []string
+	CreatedAt time.Time
+}
+
+// AllowlistRepository provides methods for managing allowlists. Every mutation is
+// recorded in the change history, in the same transaction, as made by the actor
+// set with WithActor.
 type AllowlistRepository struct {
 	db *DB
 }
@@ -42,8 +68,14 @@ func NewAllowlistRepository(db *DB) *AllowlistRepository {
 	return &AllowlistRepository{db: db}
 }
 
+// Ensure AllowlistRepository implements AllowlistRepositoryInterface
+var _ AllowlistRepositoryInterface = (*AllowlistRepository)(nil)
+
 // CreateAllowlist creates a new allowlist
 func (r *AllowlistRepository) CreateAllowlist(ctx context.Context, name, description string) (*Allowlist, error) {
+	ctx, cancel := r.db.startQuery(ctx, "allowlists.create")
+	defer cancel()
+
 	if name == "" {
 		return nil, fmt.Errorf("name is required")
 	}
@@ -53,13 +85,19 @@ func (r *AllowlistRepository) CreateAllowlist(ctx context.Context, name, descrip
 		Description: description,
 	}
 
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
 		INSERT INTO allowlists (name, description, created_at, updated_at)
 		VALUES ($1, $2, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
 		RETURNING id, name, description, created_at, updated_at
 	`
 
-	err := r.db.QueryRowxContext(ctx, query, name, description).StructScan(allowlist)
+	err = tx.QueryRowxContext(ctx, query, name, description).StructScan(allowlist)
 	if err != nil {
 		// Check for duplicate key violation
 		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
@@ -72,11 +110,23 @@ func (r *AllowlistRepository) CreateAllowlist(ctx context.Context, name, descrip
 		return nil, fmt.Errorf("failed to create allowlist: %w", err)
 	}
 
+	after := allowlistState{Name: allowlist.Name, Description: allowlist.Description}
+	if err := recordChange(ctx, tx, ResourceAllowlist, strconv.FormatInt(allowlist.ID, 10), ChangeCreated, nil, after); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return allowlist, nil
 }
 
 // GetAllowlist retrieves an allowlist by ID
 func (r *AllowlistRepository) GetAllowlist(ctx context.Context, id int64) (*Allowlist, error) {
+	ctx, cancel := r.db.startQuery(ctx, "allowlists.get")
+	defer cancel()
+
 	var allowlist Allowlist
 	query := `
 		SELECT id, name, description, created_at, updated_at
@@ -100,6 +150,9 @@ func (r *AllowlistRepository) GetAllowlist(ctx context.Context, id int64) (*Allo
 
 // ListAllowlists returns all allowlists with entry counts, ordered by created_at DESC
 func (r *AllowlistRepository) ListAllowlists(ctx context.Context) ([]AllowlistWithCount, error) {
+	ctx, cancel := r.db.startQuery(ctx, "allowlists.list")
+	defer cancel()
+
 	var allowlists []AllowlistWithCount
 	query := `
 		SELECT
@@ -130,6 +183,9 @@ func (r *AllowlistRepository) ListAllowlists(ctx context.Context) ([]AllowlistWi
 
 // UpdateAllowlist updates an allowlist's name and/or description
 func (r *AllowlistRepository) UpdateAllowlist(ctx context.Context, allowlist *Allowlist) error {
+	ctx, cancel := r.db.startQuery(ctx, "allowlists.update")
+	defer cancel()
+
 	if allowlist == nil {
 		return fmt.Errorf("allowlist cannot be nil")
 	}
@@ -137,14 +193,15 @@ func (r *AllowlistRepository) UpdateAllowlist(ctx context.Context, allowlist *Al
 		return fmt.Errorf("name is required")
 	}
 
-	query := `
-		UPDATE allowlists
-		SET name = $1, description = $2, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $3
-		RETURNING updated_at
-	`
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	err := r.db.QueryRowxContext(ctx, query, allowlist.Name, allowlist.Description, allowlist.ID).Scan(&allowlist.UpdatedAt)
+	var before allowlistState
+	err = tx.QueryRowxContext(ctx, `SELECT name, description FROM allowlists WHERE id = $1 FOR UPDATE`, allowlist.ID).
+		Scan(&before.Name, &before.Description)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return &NotFoundError{
@@ -152,6 +209,18 @@ func (r *AllowlistRepository) UpdateAllowlist(ctx context.Context, allowlist *Al
 				ID:       allowlist.ID,
 			}
 		}
+		return fmt.Errorf("failed to get allowlist: %w", err)
+	}
+
+	query := `
+		UPDATE allowlists
+		SET name = $1, description = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+		RETURNING updated_at
+	`
+
+	err = tx.QueryRowxContext(ctx, query, allowlist.Name, allowlist.Description, allowlist.ID).Scan(&allowlist.UpdatedAt)
+	if err != nil {
 		// Check for duplicate key violation
 		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
 			return &DuplicateError{
@@ -163,11 +232,23 @@ func (r *AllowlistRepository) UpdateAllowlist(ctx context.Context, allowlist *Al
 		return fmt.Errorf("failed to update allowlist: %w", err)
 	}
 
+	after := allowlistState{Name: allowlist.Name, Description: allowlist.Description}
+	if err := recordChange(ctx, tx, ResourceAllowlist, strconv.FormatInt(allowlist.ID, 10), ChangeUpdated, before, after); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
 
 // DeleteAllowlist deletes an allowlist and all its entries (cascade delete)
 func (r *AllowlistRepository) DeleteAllowlist(ctx context.Context, id int64) error {
+	ctx, cancel := r.db.startBulkQuery(ctx, "allowlists.delete")
+	defer cancel()
+
 	// Begin transaction
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
@@ -175,6 +256,25 @@ func (r *AllowlistRepository) DeleteAllowlist(ctx context.Context, id int64) err
 	}
 	defer tx.Rollback()
 
+	var before allowlistState
+	var entryCount int64
+	err = tx.QueryRowxContext(ctx, `
+		SELECT name, description, (SELECT COUNT(*) FROM allowlist_entries WHERE allowlist_id = $1)
+		FROM allowlists
+		WHERE id = $1
+		FOR UPDATE
+	`, id).Scan(&before.Name, &before.Description, &entryCount)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return &NotFoundError{
+				Resource: "allowlist",
+				ID:       id,
+			}
+		}
+		return fmt.Errorf("failed to get allowlist: %w", err)
+	}
+	before.EntryCount = &entryCount
+
 	// Delete allowlist entries first
 	_, err = tx.ExecContext(ctx, `DELETE FROM allowlist_entries WHERE allowlist_id = $1`, id)
 	if err != nil {
@@ -199,6 +299,10 @@ func (r *AllowlistRepository) DeleteAllowlist(ctx context.Context, id int64) err
 		}
 	}
 
+	if err := recordChange(ctx, tx, ResourceAllowlist, strconv.FormatInt(id, 10), ChangeDeleted, before, nil); err != nil {
+		return err
+	}
+
 	// Commit transaction
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
@@ -209,6 +313,9 @@ func (r *AllowlistRepository) DeleteAllowlist(ctx context.Context, id int64) err
 
 // AddAddress adds a single address to an allowlist
 func (r *AllowlistRepository) AddAddress(ctx context.Context, allowlistID int64, address string) error {
+	ctx, cancel := r.db.startQuery(ctx, "allowlists.add_address")
+	defer cancel()
+
 	// Validate and normalize address using canonical function
 	normalizedAddress, err := validateAddress(address)
 	if err != nil {
@@ -229,7 +336,7 @@ func (r *AllowlistRepository) AddAddress(ctx context.Context, allowlistID int64,
 		ON CONFLICT (allowlist_id, address) DO NOTHING
 	`
 
-	_, err = tx.ExecContext(ctx, insertQuery, allowlistID, normalizedAddress)
+	result, err := tx.ExecContext(ctx, insertQuery, allowlistID, normalizedAddress)
 	if err != nil {
 		// Check for foreign key violation (allowlist doesn't exist)
 		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
@@ -241,13 +348,26 @@ func (r *AllowlistRepository) AddAddress(ctx context.Context, allowlistID int64,
 		return fmt.Errorf("failed to add address to allowlist: %w", err)
 	}
 
-	// Update allowlist's updated_at timestamp
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	// Update allowlist's updated_at timestamp. This locks the allowlist row before
+	// the change is recorded, so changes to one allowlist commit in ID order and a
+	// change cursor never skips one.
 	updateQuery := `UPDATE allowlists SET updated_at = CURRENT_TIMESTAMP WHERE id = $1`
 	_, err = tx.ExecContext(ctx, updateQuery, allowlistID)
 	if err != nil {
 		return fmt.Errorf("failed to update allowlist timestamp: %w", err)
 	}
 
+	if inserted > 0 {
+		after := addressesChange{Addresses: []string{normalizedAddress}}
+		if err := recordChange(ctx, tx, ResourceAllowlist, strconv.FormatInt(allowlistID, 10), ChangeAddressesAdded, nil, after); err != nil {
+			return err
+		}
+	}
+
 	// Commit transaction
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
@@ -258,6 +378,9 @@ func (r *AllowlistRepository) AddAddress(ctx context.Context, allowlistID int64,
 
 // RemoveAddress removes an address from an allowlist
 func (r *AllowlistRepository) RemoveAddress(ctx context.Context, allowlistID int64, address string) error {
+	ctx, cancel := r.db.startQuery(ctx, "allowlists.remove_address")
+	defer cancel()
+
 	// Validate and normalize address
 	normalizedAddress, err := validateAddress(address)
 	if err != nil {
@@ -290,13 +413,20 @@ func (r *AllowlistRepository) RemoveAddress(ctx context.Context, allowlistID int
 		}
 	}
 
-	// Update allowlist's updated_at timestamp
+	// Update allowlist's updated_at timestamp. This locks the allowlist row before
+	// the change is recorded, so changes to one allowlist commit in ID order and a
+	// change cursor never skips one.
 	updateQuery := `UPDATE allowlists SET updated_at = CURRENT_TIMESTAMP WHERE id = $1`
 	_, err = tx.ExecContext(ctx, updateQuery, allowlistID)
 	if err != nil {
 		return fmt.Errorf("failed to update allowlist timestamp: %w", err)
 	}
 
+	before := addressesChange{Addresses: []string{normalizedAddress}}
+	if err := recordChange(ctx, tx, ResourceAllowlist, strconv.FormatInt(allowlistID, 10), ChangeAddressRemoved, before, nil); err != nil {
+		return err
+	}
+
 	// Commit transaction
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
@@ -307,6 +437,9 @@ func (r *AllowlistRepository) RemoveAddress(ctx context.Context, allowlistID int
 
 // AddAddresses batch adds multiple addresses to an allowlist
 func (r *AllowlistRepository) AddAddresses(ctx context.Context, allowlistID int64, addresses []string) error {
+	ctx, cancel := r.db.startBulkQuery(ctx, "allowlists.add_addresses")
+	defer cancel()
+
 	if len(addresses) == 0 {
 		return nil
 	}
@@ -341,8 +474,9 @@ func (r *AllowlistRepository) AddAddresses(ctx context.Context, allowlistID int6
 	}
 	defer stmt.Close()
 
+	var added []string
 	for _, addr := range normalizedAddresses {
-		_, err = stmt.ExecContext(ctx, allowlistID, addr)
+		result, err := stmt.ExecContext(ctx, allowlistID, addr)
 		if err != nil {
 			// Check for foreign key violation (allowlist doesn't exist)
 			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
@@ -353,15 +487,27 @@ func (r *AllowlistRepository) AddAddresses(ctx context.Context, allowlistID int6
 			}
 			return fmt.Errorf("failed to insert address %s: %w", addr, err)
 		}
+		if inserted, err := result.RowsAffected(); err == nil && inserted > 0 {
+			added = append(added, addr)
+		}
 	}
 
-	// Update allowlist's updated_at timestamp
+	// Update allowlist's updated_at timestamp. This locks the allowlist row before
+	// the change is recorded, so changes to one allowlist commit in ID order and a
+	// change cursor never skips one.
 	updateQuery := `UPDATE allowlists SET updated_at = CURRENT_TIMESTAMP WHERE id = $1`
 	_, err = tx.ExecContext(ctx, updateQuery, allowlistID)
 	if err != nil {
 		return fmt.Errorf("failed to update allowlist timestamp: %w", err)
 	}
 
+	if len(added) > 0 {
+		after := addressesChange{Addresses: added}
+		if err := recordChange(ctx, tx, ResourceAllowlist, strconv.FormatInt(allowlistID, 10), ChangeAddressesAdded, nil, after); err != nil {
+			return err
+		}
+	}
+
 	// Commit transaction
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
@@ -372,6 +518,9 @@ func (r *AllowlistRepository) AddAddresses(ctx context.Context, allowlistID int6
 
 // CheckAddress checks if an address exists in an allowlist (fast query)
 func (r *AllowlistRepository) CheckAddress(ctx context.Context, allowlistID int64, address string) (bool, error) {
+	ctx, cancel := r.db.startQuery(ctx, "allowlists.check_address")
+	defer cancel()
+
 	// Validate and normalize address
 	normalizedAddress, err := validateAddress(address)
 	if err != nil {
@@ -396,8 +545,49 @@ func (r *AllowlistRepository) CheckAddress(ctx context.Context, allowlistID int6
 	return exists, nil
 }
 
+// FindAddresses returns which of the given addresses are in an allowlist, normalized
+func (r *AllowlistRepository) FindAddresses(ctx context.Context, allowlistID int64, addresses []string) ([]string, error) {
+	ctx, cancel := r.db.startQuery(ctx, "allowlists.find_addresses")
+	defer cancel()
+
+	if len(addresses) == 0 {
+		return []string{}, nil
+	}
+
+	normalizedAddresses := make([]string, len(addresses))
+	for i, addr := range addresses {
+		normalized, err := validateAddress(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address at index %d: %w", i, err)
+		}
+		normalizedAddresses[i] = normalized
+	}
+
+	var found []string
+	query := `
+		SELECT address
+		FROM allowlist_entries
+		WHERE allowlist_id = $1 AND address = ANY($2)
+		ORDER BY address ASC
+	`
+
+	err := r.db.SelectContext(ctx, &found, query, allowlistID, pq.Array(normalizedAddresses))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find addresses in allowlist: %w", err)
+	}
+
+	if found == nil {
+		found = []string{}
+	}
+
+	return found, nil
+}
+
 // GetAddresses returns all addresses in an allowlist, sorted alphabetically
 func (r *AllowlistRepository) GetAddresses(ctx context.Context, allowlistID int64) ([]string, error) {
+	ctx, cancel := r.db.startQuery(ctx, "allowlists.get_addresses")
+	defer cancel()
+
 	var addresses []string
 	query := `
 		SELECT address
@@ -418,3 +608,111 @@ func (r *AllowlistRepository) GetAddresses(ctx context.Context, allowlistID int6
 
 	return addresses, nil
 }
+
+// StreamEntries calls fn for each entry of an allowlist, sorted by address,
+// reading rows from the database as fn consumes them so large allowlists are
+// never held in memory. Iteration stops at the first error returned by fn.
+func (r *AllowlistRepository) StreamEntries(ctx context.Context, allowlistID int64, fn func(AllowlistEntry) error) error {
+	ctx, cancel := r.db.startBulkQuery(ctx, "allowlists.stream_entries")
+	defer cancel()
+
+	query := `
+		SELECT id, allowlist_id, address, added_at
+		FROM allowlist_entries
+		WHERE allowlist_id = $1
+		ORDER BY address ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, allowlistID)
+	if err != nil {
+		return fmt.Errorf("failed to query allowlist entries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry AllowlistEntry
+		if err := rows.Scan(&entry.ID, &entry.AllowlistID, &entry.Address, &entry.AddedAt); err != nil {
+			return fmt.Errorf("failed to scan allowlist entry: %w", err)
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return nil
+}
+
+// ListAddressChanges returns up to limit address changes of an allowlist recorded
+// after the change with ID afterID, oldest first. Pass 0 for the whole history.
+func (r *AllowlistRepository) ListAddressChanges(ctx context.Context, allowlistID, afterID int64, limit int) ([]AddressChange, error) {
+	ctx, cancel := r.db.startQuery(ctx, "allowlists.list_address_changes")
+	defer cancel()
+
+	query := `
+		SELECT id, action, before, after, created_at
+		FROM change_history
+		WHERE resource_type = $1 AND resource_id = $2 AND action IN ($3, $4) AND id > $5
+		ORDER BY id ASC
+		LIMIT $6
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, ResourceAllowlist, strconv.FormatInt(allowlistID, 10),
+		ChangeAddressesAdded, ChangeAddressRemoved, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query address changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []AddressChange{}
+	for rows.Next() {
+		var change AddressChange
+		var before, after []byte
+		if err := rows.Scan(&change.ID, &change.Action, &before, &after, &change.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan address change: %w", err)
+		}
+
+		// Added addresses are recorded as the state after the change, removed ones
+		// as the state before it
+		state := after
+		if change.Action == ChangeAddressRemoved {
+			state = before
+		}
+		var payload addressesChange
+		if err := json.Unmarshal(state, &payload); err != nil {
+			return nil, fmt.Errorf("failed to decode address change %d: %w", change.ID, err)
+		}
+		change.Addresses = payload.Addresses
+		changes = append(changes, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return changes, nil
+}
+
+// LatestAddressChangeID returns the ID of the latest address change of an
+// allowlist, or 0 if its addresses were never changed
+func (r *AllowlistRepository) LatestAddressChangeID(ctx context.Context, allowlistID int64) (int64, error) {
+	ctx, cancel := r.db.startQuery(ctx, "allowlists.latest_address_change")
+	defer cancel()
+
+	query := `
+		SELECT COALESCE(MAX(id), 0)
+		FROM change_history
+		WHERE resource_type = $1 AND resource_id = $2 AND action IN ($3, $4)
+	`
+
+	var id int64
+	err := r.db.QueryRowContext(ctx, query, ResourceAllowlist, strconv.FormatInt(allowlistID, 10),
+		ChangeAddressesAdded, ChangeAddressRemoved).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest address change: %w", err)
+	}
+	return id, nil
+}
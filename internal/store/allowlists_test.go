@@ -532,3 +532,127 @@ func TestAllowlistRepository_GetAddresses(t *testing.T) {
 		assert.Len(t, addresses, 0)
 	})
 }
+
+func TestAllowlistRepository_FindAddresses(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewAllowlistRepository(db)
+	ctx := context.Background()
+
+	allowlist := createTestAllowlist(t, db, "Find", "Test")
+	addTestAddresses(t, db, allowlist.ID, []string{
+		"0x1111111111111111111111111111111111111111",
+		"0x2222222222222222222222222222222222222222",
+	})
+
+	t.Run("returns the addresses already present", func(t *testing.T) {
+		found, err := repo.FindAddresses(ctx, allowlist.ID, []string{
+			"0x2222222222222222222222222222222222222222",
+			"0x3333333333333333333333333333333333333333",
+			"0x1111111111111111111111111111111111111111",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"0x1111111111111111111111111111111111111111",
+			"0x2222222222222222222222222222222222222222",
+		}, found)
+	})
+
+	t.Run("returns empty slice for no addresses", func(t *testing.T) {
+		found, err := repo.FindAddresses(ctx, allowlist.ID, nil)
+		require.NoError(t, err)
+		assert.Empty(t, found)
+	})
+
+	t.Run("rejects invalid addresses", func(t *testing.T) {
+		_, err := repo.FindAddresses(ctx, allowlist.ID, []string{"not-an-address"})
+		assert.Error(t, err)
+	})
+}
+
+func TestAllowlistRepository_StreamEntries(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewAllowlistRepository(db)
+	ctx := context.Background()
+
+	allowlist := createTestAllowlist(t, db, "Stream", "Test")
+	addTestAddresses(t, db, allowlist.ID, []string{
+		"0x2222222222222222222222222222222222222222",
+		"0x1111111111111111111111111111111111111111",
+	})
+
+	t.Run("visits entries sorted by address", func(t *testing.T) {
+		var addresses []string
+		err := repo.StreamEntries(ctx, allowlist.ID, func(entry AllowlistEntry) error {
+			assert.Equal(t, allowlist.ID, entry.AllowlistID)
+			assert.False(t, entry.AddedAt.IsZero())
+			addresses = append(addresses, entry.Address)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"0x1111111111111111111111111111111111111111",
+			"0x2222222222222222222222222222222222222222",
+		}, addresses)
+	})
+
+	t.Run("stops at the first callback error", func(t *testing.T) {
+		stop := errors.New("client went away")
+		calls := 0
+		err := repo.StreamEntries(ctx, allowlist.ID, func(entry AllowlistEntry) error {
+			calls++
+			return stop
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestAllowlistRepository_ListAddressChanges(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewAllowlistRepository(db)
+	ctx := context.Background()
+
+	allowlist := createTestAllowlist(t, db, "Sync", "Test")
+	latest, err := repo.LatestAddressChangeID(ctx, allowlist.ID)
+	require.NoError(t, err)
+	assert.Zero(t, latest)
+
+	require.NoError(t, repo.AddAddresses(ctx, allowlist.ID, []string{
+		"0x1111111111111111111111111111111111111111",
+		"0x2222222222222222222222222222222222222222",
+	}))
+	require.NoError(t, repo.RemoveAddress(ctx, allowlist.ID, "0x1111111111111111111111111111111111111111"))
+	require.NoError(t, repo.UpdateAllowlist(ctx, &Allowlist{ID: allowlist.ID, Name: "Sync", Description: "Renamed"}))
+
+	t.Run("returns address changes oldest first", func(t *testing.T) {
+		changes, err := repo.ListAddressChanges(ctx, allowlist.ID, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, changes, 2, "other changes of the allowlist are left out")
+		assert.Equal(t, ChangeAddressesAdded, changes[0].Action)
+		assert.Equal(t, []string{
+			"0x1111111111111111111111111111111111111111",
+			"0x2222222222222222222222222222222222222222",
+		}, changes[0].Addresses)
+		assert.Equal(t, ChangeAddressRemoved, changes[1].Action)
+		assert.Equal(t, []string{"0x1111111111111111111111111111111111111111"}, changes[1].Addresses)
+		assert.Greater(t, changes[1].ID, changes[0].ID)
+
+		latest, err := repo.LatestAddressChangeID(ctx, allowlist.ID)
+		require.NoError(t, err)
+		assert.Equal(t, changes[1].ID, latest)
+	})
+
+	t.Run("resumes after a cursor", func(t *testing.T) {
+		first, err := repo.ListAddressChanges(ctx, allowlist.ID, 0, 1)
+		require.NoError(t, err)
+		require.Len(t, first, 1)
+
+		rest, err := repo.ListAddressChanges(ctx, allowlist.ID, first[0].ID, 10)
+		require.NoError(t, err)
+		require.Len(t, rest, 1)
+		assert.Equal(t, ChangeAddressRemoved, rest[0].Action)
+	})
+}
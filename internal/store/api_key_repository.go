@@ -7,6 +7,8 @@ import (
 	"database/sql"
 	"encoding/hex"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/lib/pq"
@@ -53,8 +55,13 @@ func NewAPIKeyRepository(db *DB) *APIKeyRepository {
 	return &APIKeyRepository{db: db}
 }
 
-// Ensure APIKeyRepository implements APIKeyRepositoryInterface
+// Ensure APIKeyRepository implements APIKeyRepositoryInterface, KeyExpiryRepositoryInterface,
+// KeyRevocationRepositoryInterface, KeyTransferRepositoryInterface and KeyEscrowRepositoryInterface
 var _ APIKeyRepositoryInterface = (*APIKeyRepository)(nil)
+var _ KeyExpiryRepositoryInterface = (*APIKeyRepository)(nil)
+var _ KeyRevocationRepositoryInterface = (*APIKeyRepository)(nil)
+var _ KeyTransferRepositoryInterface = (*APIKeyRepository)(nil)
+var _ KeyEscrowRepositoryInterface = (*APIKeyRepository)(nil)
 
 // GenerateAPIKey generates a new cryptographically secure API key
 // Returns the raw key (hex-encoded, 64 characters)
@@ -77,6 +84,9 @@ func HashAPIKey(key string) string {
 
 // CreateAPIKey generates a new API key, hashes it, and stores it in the database
 func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, req APIKeyCreateRequest) (string, *APIKeyResponse, error) {
+	ctx, cancel := r.db.startQuery(ctx, "api_keys.create")
+	defer cancel()
+
 	// Validate request
 	if req.UserID == 0 {
 		return "", nil, fmt.Errorf("user_id is required")
@@ -154,6 +164,9 @@ func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, req APIKeyCreateReq
 
 // ValidateAPIKey verifies an API key and returns the associated key metadata
 func (r *APIKeyRepository) ValidateAPIKey(ctx context.Context, rawKey string) (*APIKey, error) {
+	ctx, cancel := r.db.startQuery(ctx, "api_keys.validate")
+	defer cancel()
+
 	if rawKey == "" {
 		return nil, fmt.Errorf("API key cannot be empty")
 	}
@@ -204,6 +217,9 @@ func (r *APIKeyRepository) ValidateAPIKey(ctx context.Context, rawKey string) (*
 
 // GetAPIKey retrieves an API key by ID (metadata only, no raw key)
 func (r *APIKeyRepository) GetAPIKey(ctx context.Context, id int64) (*APIKey, error) {
+	ctx, cancel := r.db.startQuery(ctx, "api_keys.get")
+	defer cancel()
+
 	var apiKey APIKey
 	query := `
 		SELECT id, user_id, key_hash, name, scopes, last_used_at, expires_at, created_at, updated_at
@@ -237,6 +253,9 @@ func (r *APIKeyRepository) GetAPIKey(ctx context.Context, id int64) (*APIKey, er
 
 // ListAPIKeys returns all API keys for a user
 func (r *APIKeyRepository) ListAPIKeys(ctx context.Context, userID int64) ([]APIKey, error) {
+	ctx, cancel := r.db.startQuery(ctx, "api_keys.list")
+	defer cancel()
+
 	query := `
 		SELECT id, user_id, key_hash, name, scopes, last_used_at, expires_at, created_at, updated_at
 		FROM api_keys
@@ -284,6 +303,9 @@ func (r *APIKeyRepository) ListAPIKeys(ctx context.Context, userID int64) ([]API
 
 // GetAPIKeyByID retrieves an API key by its ID
 func (r *APIKeyRepository) GetAPIKeyByID(ctx context.Context, id int64) (*APIKey, error) {
+	ctx, cancel := r.db.startQuery(ctx, "api_keys.get_by_id")
+	defer cancel()
+
 	query := `
 		SELECT id, user_id, key_hash, name, scopes, last_used_at, expires_at, created_at, updated_at
 		FROM api_keys
@@ -317,6 +339,9 @@ func (r *APIKeyRepository) GetAPIKeyByID(ctx context.Context, id int64) (*APIKey
 
 // DeleteAPIKey deletes an API key (revokes it)
 func (r *APIKeyRepository) DeleteAPIKey(ctx context.Context, id int64) error {
+	ctx, cancel := r.db.startQuery(ctx, "api_keys.delete")
+	defer cancel()
+
 	query := `DELETE FROM api_keys WHERE id = $1`
 
 	result, err := r.db.ExecContext(ctx, query, id)
@@ -341,6 +366,9 @@ func (r *APIKeyRepository) DeleteAPIKey(ctx context.Context, id int64) error {
 
 // UpdateLastUsed updates the last_used_at timestamp for an API key
 func (r *APIKeyRepository) UpdateLastUsed(ctx context.Context, keyHash string) error {
+	ctx, cancel := r.db.startQuery(ctx, "api_keys.update_last_used")
+	defer cancel()
+
 	query := `
 		UPDATE api_keys
 		SET last_used_at = CURRENT_TIMESTAMP
@@ -369,6 +397,9 @@ func (r *APIKeyRepository) UpdateLastUsed(ctx context.Context, keyHash string) e
 
 // RevokeExpiredKeys deletes all expired API keys and returns the count
 func (r *APIKeyRepository) RevokeExpiredKeys(ctx context.Context) (int, error) {
+	ctx, cancel := r.db.startBulkQuery(ctx, "api_keys.revoke_expired")
+	defer cancel()
+
 	query := `
 		DELETE FROM api_keys
 		WHERE expires_at IS NOT NULL AND expires_at < CURRENT_TIMESTAMP
@@ -386,3 +417,393 @@ func (r *APIKeyRepository) RevokeExpiredKeys(ctx context.Context) (int, error) {
 
 	return int(rowsAffected), nil
 }
+
+// ExpiringAPIKey is an API key about to expire, with the wallet to notify: the
+// key's user, or the owner of the service account the key belongs to
+type ExpiringAPIKey struct {
+	ID           int64     `db:"id"`
+	UserID       int64     `db:"user_id"`
+	Name         string    `db:"name"`
+	ExpiresAt    time.Time `db:"expires_at"`
+	OwnerAddress string    `db:"owner_address"`
+}
+
+// ListExpiringKeys returns keys expiring before the given time whose owner has not
+// been notified yet, soonest first. Keys that already expired are not returned.
+func (r *APIKeyRepository) ListExpiringKeys(ctx context.Context, before time.Time) ([]ExpiringAPIKey, error) {
+	ctx, cancel := r.db.startQuery(ctx, "api_keys.list_expiring")
+	defer cancel()
+
+	query := `
+		SELECT k.id, k.user_id, k.name, k.expires_at, COALESCE(u.address, o.address, '') AS owner_address
+		FROM api_keys k
+		JOIN users u ON u.id = k.user_id
+		LEFT JOIN users o ON o.id = u.owner_id
+		WHERE k.expires_at IS NOT NULL
+			AND k.expires_at > CURRENT_TIMESTAMP
+			AND k.expires_at <= $1
+			AND k.expiry_notified_at IS NULL
+		ORDER BY k.expires_at
+	`
+
+	keys := []ExpiringAPIKey{}
+	if err := r.db.SelectContext(ctx, &keys, query, before); err != nil {
+		return nil, fmt.Errorf("failed to query expiring API keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// MarkExpiryNotified records that the owner of a key was told it is about to expire
+func (r *APIKeyRepository) MarkExpiryNotified(ctx context.Context, id int64) error {
+	ctx, cancel := r.db.startQuery(ctx, "api_keys.mark_expiry_notified")
+	defer cancel()
+
+	query := `UPDATE api_keys SET expiry_notified_at = CURRENT_TIMESTAMP WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark API key expiry notified: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return &NotFoundError{
+			Resource: "api_key",
+			ID:       id,
+		}
+	}
+
+	return nil
+}
+
+// KeyRevocationFilter selects API keys for bulk revocation. A key must match every
+// criterion that is set; at least one must be.
+type KeyRevocationFilter struct {
+	Scope         string     // Keys granted this scope
+	CreatedAfter  *time.Time // Keys created at or after this time
+	CreatedBefore *time.Time // Keys created before this time
+	KeyPrefix     string     // Keys whose hash starts with this lowercase hex prefix, as shown in key listings
+}
+
+// IsEmpty reports whether the filter has no criteria, which would match every key
+func (f KeyRevocationFilter) IsEmpty() bool {
+	return f.Scope == "" && f.CreatedAfter == nil && f.CreatedBefore == nil && f.KeyPrefix == ""
+}
+
+// Matches reports whether key meets every criterion of the filter, as the WHERE
+// clause of a bulk revocation would select it
+func (f KeyRevocationFilter) Matches(key APIKey) bool {
+	if f.Scope != "" {
+		granted := false
+		for _, scope := range key.Scopes {
+			granted = granted || scope == f.Scope
+		}
+		if !granted {
+			return false
+		}
+	}
+	if f.CreatedAfter != nil && key.CreatedAt.Before(*f.CreatedAfter) {
+		return false
+	}
+	if f.CreatedBefore != nil && !key.CreatedAt.Before(*f.CreatedBefore) {
+		return false
+	}
+	return strings.HasPrefix(key.KeyHash, f.KeyPrefix)
+}
+
+// where returns the WHERE clause selecting the filter's keys and its arguments
+func (f KeyRevocationFilter) where() (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if f.Scope != "" {
+		add("$%d = ANY(scopes)", f.Scope)
+	}
+	if f.CreatedAfter != nil {
+		add("created_at >= $%d", *f.CreatedAfter)
+	}
+	if f.CreatedBefore != nil {
+		add("created_at < $%d", *f.CreatedBefore)
+	}
+	if f.KeyPrefix != "" {
+		add("key_hash LIKE $%d || '%%'", f.KeyPrefix)
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// ListKeysMatching returns the keys a bulk revocation with filter would revoke,
+// oldest first
+func (r *APIKeyRepository) ListKeysMatching(ctx context.Context, filter KeyRevocationFilter) ([]APIKey, error) {
+	if filter.IsEmpty() {
+		return nil, fmt.Errorf("at least one revocation criterion is required")
+	}
+
+	ctx, cancel := r.db.startBulkQuery(ctx, "api_keys.list_matching")
+	defer cancel()
+
+	where, args := filter.where()
+	query := `
+		SELECT id, user_id, key_hash, name, scopes, last_used_at, expires_at, created_at, updated_at
+		FROM api_keys
+		` + where + `
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query matching API keys: %w", err)
+	}
+	return scanAPIKeys(rows)
+}
+
+// RevokeKeysMatching deletes every key matching filter in one statement and
+// returns the revoked keys, oldest first
+func (r *APIKeyRepository) RevokeKeysMatching(ctx context.Context, filter KeyRevocationFilter) ([]APIKey, error) {
+	if filter.IsEmpty() {
+		return nil, fmt.Errorf("at least one revocation criterion is required")
+	}
+
+	ctx, cancel := r.db.startBulkQuery(ctx, "api_keys.revoke_matching")
+	defer cancel()
+
+	where, args := filter.where()
+	query := `
+		DELETE FROM api_keys
+		` + where + `
+		RETURNING id, user_id, key_hash, name, scopes, last_used_at, expires_at, created_at, updated_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke matching API keys: %w", err)
+	}
+	keys, err := scanAPIKeys(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// DELETE ... RETURNING has no ORDER BY
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].ID < keys[j].ID
+		}
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+// TransferAPIKey reassigns key id to toUserID and returns the updated key and
+// the previous owner's ID. The key hash, scopes, expiry and last use are kept,
+// so the raw key keeps working for its new owner.
+func (r *APIKeyRepository) TransferAPIKey(ctx context.Context, id, toUserID int64) (*APIKey, int64, error) {
+	ctx, cancel := r.db.startQuery(ctx, "api_keys.transfer")
+	defer cancel()
+
+	// The sub-select locks the row so the previous owner read matches the update
+	query := `
+		UPDATE api_keys k
+		SET user_id = $2, updated_at = CURRENT_TIMESTAMP
+		FROM (SELECT id, user_id FROM api_keys WHERE id = $1 FOR UPDATE) previous
+		WHERE k.id = previous.id
+		RETURNING previous.user_id, k.id, k.user_id, k.key_hash, k.name, k.scopes, k.last_used_at, k.expires_at, k.created_at, k.updated_at
+	`
+
+	var previousUserID int64
+	var apiKey APIKey
+	err := r.db.QueryRowContext(ctx, query, id, toUserID).Scan(
+		&previousUserID,
+		&apiKey.ID,
+		&apiKey.UserID,
+		&apiKey.KeyHash,
+		&apiKey.Name,
+		pq.Array(&apiKey.Scopes),
+		&apiKey.LastUsedAt,
+		&apiKey.ExpiresAt,
+		&apiKey.CreatedAt,
+		&apiKey.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, 0, &NotFoundError{
+				Resource: "api_key",
+				ID:       id,
+			}
+		}
+		// Check for foreign key violation (user doesn't exist)
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return nil, 0, &NotFoundError{
+				Resource: "user",
+				ID:       toUserID,
+			}
+		}
+		return nil, 0, fmt.Errorf("failed to transfer API key: %w", err)
+	}
+
+	return &apiKey, previousUserID, nil
+}
+
+// EscrowedAPIKey is an API key as kept in the key escrow: its hash and metadata,
+// with the owner identified by wallet address rather than database ID so the key
+// can be restored into an empty database
+type EscrowedAPIKey struct {
+	KeyHash        string     `json:"keyHash"`
+	Name           string     `json:"name"`
+	Scopes         []string   `json:"scopes"`
+	ExpiresAt      *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	OwnerAddress   string     `json:"ownerAddress"`             // The owner's wallet, or that of the service account's owner
+	ServiceAccount string     `json:"serviceAccount,omitempty"` // Name of the owning service account
+}
+
+// ListEscrowedAPIKeys returns every API key with its owner, oldest first
+func (r *APIKeyRepository) ListEscrowedAPIKeys(ctx context.Context) ([]EscrowedAPIKey, error) {
+	ctx, cancel := r.db.startBulkQuery(ctx, "api_keys.list_escrowed")
+	defer cancel()
+
+	query := `
+		SELECT k.key_hash, k.name, k.scopes, k.expires_at, k.created_at,
+			COALESCE(u.address, o.address, ''),
+			CASE WHEN u.account_type = '` + AccountTypeService + `' THEN COALESCE(u.name, '') ELSE '' END
+		FROM api_keys k
+		JOIN users u ON u.id = k.user_id
+		LEFT JOIN users o ON o.id = u.owner_id
+		ORDER BY k.created_at, k.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query API keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []EscrowedAPIKey{}
+	for rows.Next() {
+		var key EscrowedAPIKey
+		err := rows.Scan(
+			&key.KeyHash,
+			&key.Name,
+			pq.Array(&key.Scopes),
+			&key.ExpiresAt,
+			&key.CreatedAt,
+			&key.OwnerAddress,
+			&key.ServiceAccount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return keys, nil
+}
+
+// RestoreAPIKey inserts an escrowed API key, creating its owner and service
+// account if they do not exist. It reports false, changing nothing, if a key with
+// the same hash already exists.
+func (r *APIKeyRepository) RestoreAPIKey(ctx context.Context, key EscrowedAPIKey) (bool, error) {
+	ctx, cancel := r.db.startQuery(ctx, "api_keys.restore")
+	defer cancel()
+
+	ownerAddress, err := validateAddress(key.OwnerAddress)
+	if err != nil {
+		return false, err
+	}
+	if key.Scopes == nil {
+		key.Scopes = []string{}
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// The no-op update makes RETURNING yield the existing row
+	var userID int64
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO users (address, created_at, updated_at)
+		VALUES ($1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT (address) DO UPDATE SET address = EXCLUDED.address
+		RETURNING id
+	`, ownerAddress).Scan(&userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to restore key owner: %w", err)
+	}
+
+	if key.ServiceAccount != "" {
+		err = tx.QueryRowxContext(ctx, `
+			INSERT INTO users (address, account_type, name, owner_id, created_at, updated_at)
+			VALUES (NULL, '`+AccountTypeService+`', $1, $2, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+			ON CONFLICT (owner_id, name) WHERE account_type = '`+AccountTypeService+`' DO UPDATE SET name = EXCLUDED.name
+			RETURNING id
+		`, key.ServiceAccount, userID).Scan(&userID)
+		if err != nil {
+			return false, fmt.Errorf("failed to restore service account: %w", err)
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO api_keys (user_id, key_hash, name, scopes, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP)
+		ON CONFLICT (key_hash) DO NOTHING
+	`, userID, key.KeyHash, key.Name, pq.Array(key.Scopes), key.ExpiresAt, key.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to restore API key: %w", err)
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if inserted == 0 {
+		return false, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// scanAPIKeys reads all API keys from rows and closes them
+func scanAPIKeys(rows *sql.Rows) ([]APIKey, error) {
+	defer rows.Close()
+
+	keys := []APIKey{}
+	for rows.Next() {
+		var key APIKey
+		err := rows.Scan(
+			&key.ID,
+			&key.UserID,
+			&key.KeyHash,
+			&key.Name,
+			pq.Array(&key.Scopes),
+			&key.LastUsedAt,
+			&key.ExpiresAt,
+			&key.CreatedAt,
+			&key.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return keys, nil
+}
@@ -462,6 +462,180 @@ func TestAPIKeyRepository_RevokeExpiredKeys(t *testing.T) {
 	})
 }
 
+func TestAPIKeyRepository_ListExpiringKeys(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	userRepo := NewUserRepository(db)
+	repo := NewAPIKeyRepository(db)
+	ctx := context.Background()
+
+	user, err := userRepo.CreateUser(ctx, "0x742d35cc6634c0532925a3b844bc9e7595f0beb8")
+	require.NoError(t, err)
+	service, err := userRepo.CreateServiceAccount(ctx, "ci-bot", user.ID)
+	require.NoError(t, err)
+
+	createKey := func(userID int64, name string, expiresIn *time.Duration) int64 {
+		_, key, err := repo.CreateAPIKey(ctx, APIKeyCreateRequest{
+			UserID:    userID,
+			Name:      name,
+			Scopes:    []string{"read"},
+			ExpiresIn: expiresIn,
+		})
+		require.NoError(t, err)
+		return key.ID
+	}
+	soon, later, past := 24*time.Hour, 30*24*time.Hour, -time.Hour
+
+	soonID := createKey(user.ID, "Soon", &soon)
+	serviceID := createKey(service.ID, "Service Soon", &soon)
+	createKey(user.ID, "Later", &later)
+	createKey(user.ID, "Expired", &past)
+	createKey(user.ID, "Never", nil)
+
+	keys, err := repo.ListExpiringKeys(ctx, time.Now().Add(72*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.ElementsMatch(t, []int64{soonID, serviceID}, []int64{keys[0].ID, keys[1].ID})
+	for _, key := range keys {
+		// Service account keys are reported to the owning wallet
+		assert.Equal(t, user.Address, key.OwnerAddress)
+	}
+
+	require.NoError(t, repo.MarkExpiryNotified(ctx, soonID))
+	keys, err = repo.ListExpiringKeys(ctx, time.Now().Add(72*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, serviceID, keys[0].ID)
+	assert.Equal(t, "Service Soon", keys[0].Name)
+
+	var notFoundErr *NotFoundError
+	assert.ErrorAs(t, repo.MarkExpiryNotified(ctx, 99999), &notFoundErr)
+}
+
+func TestAPIKeyRepository_RevokeKeysMatching(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	userRepo := NewUserRepository(db)
+	repo := NewAPIKeyRepository(db)
+	ctx := context.Background()
+
+	user, err := userRepo.CreateUser(ctx, "0x742d35cc6634c0532925a3b844bc9e7595f0beb8")
+	require.NoError(t, err)
+
+	createKey := func(name string, scopes []string) *APIKeyResponse {
+		_, key, err := repo.CreateAPIKey(ctx, APIKeyCreateRequest{UserID: user.ID, Name: name, Scopes: scopes})
+		require.NoError(t, err)
+		return key
+	}
+	adminKey := createKey("Admin", []string{"read", "admin"})
+	readKey := createKey("Reader", []string{"read"})
+	otherAdminKey := createKey("Other Admin", []string{"admin"})
+
+	_, err = repo.ListKeysMatching(ctx, KeyRevocationFilter{})
+	assert.Error(t, err, "an empty filter must not match every key")
+
+	preview, err := repo.ListKeysMatching(ctx, KeyRevocationFilter{Scope: "admin"})
+	require.NoError(t, err)
+	require.Len(t, preview, 2)
+	assert.Equal(t, adminKey.ID, preview[0].ID)
+	assert.Equal(t, otherAdminKey.ID, preview[1].ID)
+
+	preview, err = repo.ListKeysMatching(ctx, KeyRevocationFilter{Scope: "admin", KeyPrefix: adminKey.KeyHash[:8]})
+	require.NoError(t, err)
+	require.Len(t, preview, 1)
+	assert.Equal(t, adminKey.ID, preview[0].ID)
+
+	future := time.Now().Add(time.Hour)
+	preview, err = repo.ListKeysMatching(ctx, KeyRevocationFilter{CreatedAfter: &future})
+	require.NoError(t, err)
+	assert.Empty(t, preview)
+
+	revoked, err := repo.RevokeKeysMatching(ctx, KeyRevocationFilter{Scope: "admin"})
+	require.NoError(t, err)
+	require.Len(t, revoked, 2)
+	assert.Equal(t, adminKey.ID, revoked[0].ID)
+
+	remaining, err := repo.ListAPIKeys(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, readKey.ID, remaining[0].ID)
+}
+
+func TestAPIKeyRepository_TransferAPIKey(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	userRepo := NewUserRepository(db)
+	repo := NewAPIKeyRepository(db)
+	ctx := context.Background()
+
+	leaver, err := userRepo.CreateUser(ctx, "0x742d35cc6634c0532925a3b844bc9e7595f0beb8")
+	require.NoError(t, err)
+	successor, err := userRepo.CreateUser(ctx, "0x8ba1f109551bd432803012645ac136ddd64dba72")
+	require.NoError(t, err)
+
+	rawKey, created, err := repo.CreateAPIKey(ctx, APIKeyCreateRequest{UserID: leaver.ID, Name: "Deploy", Scopes: []string{"read", "write"}})
+	require.NoError(t, err)
+	require.NoError(t, repo.UpdateLastUsed(ctx, created.KeyHash))
+
+	transferred, previousUserID, err := repo.TransferAPIKey(ctx, created.ID, successor.ID)
+	require.NoError(t, err)
+	assert.Equal(t, leaver.ID, previousUserID)
+	assert.Equal(t, successor.ID, transferred.UserID)
+	assert.Equal(t, created.KeyHash, transferred.KeyHash)
+	assert.Equal(t, []string{"read", "write"}, transferred.Scopes)
+	assert.NotNil(t, transferred.LastUsedAt, "usage history must survive the transfer")
+
+	validated, err := repo.ValidateAPIKey(ctx, rawKey)
+	require.NoError(t, err)
+	assert.Equal(t, successor.ID, validated.UserID)
+
+	leaverKeys, err := repo.ListAPIKeys(ctx, leaver.ID)
+	require.NoError(t, err)
+	assert.Empty(t, leaverKeys)
+
+	_, _, err = repo.TransferAPIKey(ctx, created.ID+1000, successor.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, _, err = repo.TransferAPIKey(ctx, created.ID, successor.ID+1000)
+	var notFound *NotFoundError
+	require.ErrorAs(t, err, &notFound)
+	assert.Equal(t, "user", notFound.Resource)
+}
+
+func TestKeyRevocationFilter_Where(t *testing.T) {
+	after := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	filter := KeyRevocationFilter{Scope: "admin", CreatedAfter: &after, CreatedBefore: &before, KeyPrefix: "ab12cd34"}
+
+	where, args := filter.where()
+	assert.Equal(t, "WHERE $1 = ANY(scopes) AND created_at >= $2 AND created_at < $3 AND key_hash LIKE $4 || '%'", where)
+	assert.Equal(t, []interface{}{"admin", after, before, "ab12cd34"}, args)
+
+	assert.True(t, KeyRevocationFilter{}.IsEmpty())
+	assert.False(t, KeyRevocationFilter{KeyPrefix: "ab"}.IsEmpty())
+}
+
+func TestKeyRevocationFilter_Matches(t *testing.T) {
+	after := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	filter := KeyRevocationFilter{Scope: "admin", CreatedAfter: &after, CreatedBefore: &before, KeyPrefix: "ab12"}
+	key := APIKey{KeyHash: "ab12cd34", Scopes: []string{"read", "admin"}, CreatedAt: after}
+
+	assert.True(t, filter.Matches(key))
+
+	other := key
+	other.Scopes = []string{"read"}
+	assert.False(t, filter.Matches(other))
+
+	other = key
+	other.CreatedAt = before
+	assert.False(t, filter.Matches(other), "createdBefore is exclusive")
+
+	other = key
+	other.KeyHash = "ff12cd34"
+	assert.False(t, filter.Matches(other))
+}
+
 func TestHashAPIKey(t *testing.T) {
 	t.Run("generates consistent hash", func(t *testing.T) {
 		key := "test_api_key_12345"
@@ -497,3 +671,45 @@ func TestGenerateAPIKey(t *testing.T) {
 		}
 	})
 }
+
+func TestAPIKeyRepository_EscrowRoundTrip(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	userRepo := NewUserRepository(db)
+	repo := NewAPIKeyRepository(db)
+	ctx := context.Background()
+
+	owner, err := userRepo.CreateUser(ctx, "0x742d35cc6634c0532925a3b844bc9e7595f0beb0")
+	require.NoError(t, err)
+	service, err := userRepo.CreateServiceAccount(ctx, "deployer", owner.ID)
+	require.NoError(t, err)
+	_, _, err = repo.CreateAPIKey(ctx, APIKeyCreateRequest{UserID: owner.ID, Name: "ci", Scopes: []string{"read"}})
+	require.NoError(t, err)
+	_, _, err = repo.CreateAPIKey(ctx, APIKeyCreateRequest{UserID: service.ID, Name: "deploy"})
+	require.NoError(t, err)
+
+	escrowed, err := repo.ListEscrowedAPIKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, escrowed, 2)
+	assert.Equal(t, owner.Address, escrowed[0].OwnerAddress)
+	assert.Empty(t, escrowed[0].ServiceAccount)
+	assert.Equal(t, owner.Address, escrowed[1].OwnerAddress)
+	assert.Equal(t, "deployer", escrowed[1].ServiceAccount)
+
+	// Restoring into an empty database recreates owners, service accounts and keys
+	cleanupTestDB(t, db)
+	for _, key := range escrowed {
+		restored, err := repo.RestoreAPIKey(ctx, key)
+		require.NoError(t, err)
+		assert.True(t, restored)
+	}
+	restored, err := repo.RestoreAPIKey(ctx, escrowed[0])
+	require.NoError(t, err)
+	assert.False(t, restored, "keys already present are kept")
+
+	again, err := repo.ListEscrowedAPIKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, again, 2)
+	assert.Equal(t, escrowed[1].KeyHash, again[1].KeyHash)
+	assert.Equal(t, "deployer", again[1].ServiceAccount)
+}
@@ -0,0 +1,170 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// BlockedAddress is an address on the global blocklist
+type BlockedAddress struct {
+	ID        int64     `db:"id"`
+	Address   string    `db:"address"`
+	Reason    string    `db:"reason"`
+	CreatedBy string    `db:"created_by"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// blockedAddressState is a blocked address as recorded in the change history
+type blockedAddressState struct {
+	Reason string `json:"reason"`
+}
+
+// BlocklistRepository manages the global blocklist. Every mutation is recorded in
+// the change history, in the same transaction, as made by the actor set with
+// WithActor.
+type BlocklistRepository struct {
+	db *DB
+}
+
+// NewBlocklistRepository creates a new blocklist repository
+func NewBlocklistRepository(db *DB) *BlocklistRepository {
+	return &BlocklistRepository{db: db}
+}
+
+// Ensure BlocklistRepository implements BlocklistRepositoryInterface
+var _ BlocklistRepositoryInterface = (*BlocklistRepository)(nil)
+
+// BlockAddress adds an address to the blocklist
+func (r *BlocklistRepository) BlockAddress(ctx context.Context, address, reason string) (*BlockedAddress, error) {
+	ctx, cancel := r.db.startQuery(ctx, "blocklist.block")
+	defer cancel()
+
+	normalizedAddress, err := validateAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO blocked_addresses (address, reason, created_by, created_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		RETURNING id, address, reason, created_by, created_at
+	`
+
+	var blocked BlockedAddress
+	err = tx.QueryRowxContext(ctx, query, normalizedAddress, reason, actorFromContext(ctx)).StructScan(&blocked)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return nil, &DuplicateError{
+				Resource: "blocked_address",
+				Field:    "address",
+				Value:    normalizedAddress,
+			}
+		}
+		return nil, fmt.Errorf("failed to block address: %w", err)
+	}
+
+	after := blockedAddressState{Reason: reason}
+	if err := recordChange(ctx, tx, ResourceBlocklist, normalizedAddress, ChangeCreated, nil, after); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &blocked, nil
+}
+
+// UnblockAddress removes an address from the blocklist
+func (r *BlocklistRepository) UnblockAddress(ctx context.Context, address string) error {
+	ctx, cancel := r.db.startQuery(ctx, "blocklist.unblock")
+	defer cancel()
+
+	normalizedAddress, err := validateAddress(address)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var reason string
+	query := `DELETE FROM blocked_addresses WHERE address = $1 RETURNING reason`
+	if err := tx.QueryRowxContext(ctx, query, normalizedAddress).Scan(&reason); err != nil {
+		if err == sql.ErrNoRows {
+			return &NotFoundError{
+				Resource: "blocked_address",
+				ID:       normalizedAddress,
+			}
+		}
+		return fmt.Errorf("failed to unblock address: %w", err)
+	}
+
+	before := blockedAddressState{Reason: reason}
+	if err := recordChange(ctx, tx, ResourceBlocklist, normalizedAddress, ChangeDeleted, before, nil); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ListBlockedAddresses returns the blocklist, most recently blocked first
+func (r *BlocklistRepository) ListBlockedAddresses(ctx context.Context) ([]BlockedAddress, error) {
+	ctx, cancel := r.db.startQuery(ctx, "blocklist.list")
+	defer cancel()
+
+	query := `
+		SELECT id, address, reason, created_by, created_at
+		FROM blocked_addresses
+		ORDER BY created_at DESC, id DESC
+	`
+
+	blocked := []BlockedAddress{}
+	if err := r.db.SelectContext(ctx, &blocked, query); err != nil {
+		return nil, fmt.Errorf("failed to list blocked addresses: %w", err)
+	}
+
+	return blocked, nil
+}
+
+// FindBlockedAddresses returns which of the given addresses are blocked,
+// normalized. Invalid addresses are never blocked and are skipped.
+func (r *BlocklistRepository) FindBlockedAddresses(ctx context.Context, addresses []string) ([]string, error) {
+	ctx, cancel := r.db.startQuery(ctx, "blocklist.find")
+	defer cancel()
+
+	normalizedAddresses := make([]string, 0, len(addresses))
+	for _, addr := range addresses {
+		if normalized, err := validateAddress(addr); err == nil {
+			normalizedAddresses = append(normalizedAddresses, normalized)
+		}
+	}
+	if len(normalizedAddresses) == 0 {
+		return []string{}, nil
+	}
+
+	found := []string{}
+	query := `SELECT address FROM blocked_addresses WHERE address = ANY($1) ORDER BY address ASC`
+	if err := r.db.SelectContext(ctx, &found, query, pq.Array(normalizedAddresses)); err != nil {
+		return nil, fmt.Errorf("failed to find blocked addresses: %w", err)
+	}
+
+	return found, nil
+}
@@ -0,0 +1,44 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlocklistRepository(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewBlocklistRepository(db)
+	ctx := WithActor(context.Background(), "0x742d35cc6634c0532925a3b844bc9e7595f0beb0")
+	address := "0xAbCdEf0123456789abcdef0123456789ABCDEF01"
+	normalized := "0xabcdef0123456789abcdef0123456789abcdef01"
+
+	blocked, err := repo.BlockAddress(ctx, address, "compromised")
+	require.NoError(t, err)
+	assert.Equal(t, normalized, blocked.Address)
+	assert.Equal(t, "compromised", blocked.Reason)
+	assert.Equal(t, "0x742d35cc6634c0532925a3b844bc9e7595f0beb0", blocked.CreatedBy)
+
+	_, err = repo.BlockAddress(ctx, normalized, "again")
+	assert.True(t, errors.Is(err, ErrDuplicate))
+
+	found, err := repo.FindBlockedAddresses(ctx, []string{address, "0x1111111111111111111111111111111111111111", "not-an-address"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{normalized}, found)
+
+	list, err := repo.ListBlockedAddresses(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	require.NoError(t, repo.UnblockAddress(ctx, address))
+	assert.True(t, errors.Is(repo.UnblockAddress(ctx, address), ErrNotFound))
+
+	changes, err := NewChangeHistoryRepository(db).ListChanges(ctx, ChangeFilter{ResourceType: ResourceBlocklist, ResourceID: normalized})
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, ChangeDeleted, changes[0].Action)
+	assert.Equal(t, ChangeCreated, changes[1].Action)
+}
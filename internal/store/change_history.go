@@ -0,0 +1,178 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Resource types in the change history
+const (
+	ResourceAllowlist = "allowlist"
+	ResourcePolicy    = "policy"
+	ResourceBlocklist = "blocklist"
+)
+
+// Change history actions
+const (
+	ChangeCreated        = "created"
+	ChangeUpdated        = "updated"
+	ChangeDeleted        = "deleted"
+	ChangeAddressesAdded = "addresses_added"
+	ChangeAddressRemoved = "address_removed"
+)
+
+// SystemActor is recorded for changes made without an authenticated actor, such
+// as policies loaded at startup
+const SystemActor = "system"
+
+// Change is one entry of the change history: who changed what, with the state
+// before and after. Before is nil for creations and After for deletions.
+type Change struct {
+	ID           int64     `db:"id"`
+	ResourceType string    `db:"resource_type"`
+	ResourceID   string    `db:"resource_id"`
+	Action       string    `db:"action"`
+	Actor        string    `db:"actor"`
+	Before       []byte    `db:"before"` // JSON
+	After        []byte    `db:"after"`  // JSON
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+// ChangeFilter selects change history entries. Zero fields match everything.
+type ChangeFilter struct {
+	ResourceType string
+	ResourceID   string
+	BeforeID     int64 // Only entries older than this ID, for paging
+	Limit        int
+}
+
+// actorKey carries the actor recorded for changes made with a context
+type actorKey struct{}
+
+// WithActor records actor as the author of the changes made with ctx
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// actorFromContext returns the actor set by WithActor, or SystemActor
+func actorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return SystemActor
+}
+
+// execer runs statements on a DB, a single connection or in a transaction
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// recordChange appends a change made by the actor of ctx. Pass the transaction of
+// the mutation so the change is recorded if and only if the mutation commits.
+func recordChange(ctx context.Context, exec execer, resourceType, resourceID, action string, before, after interface{}) error {
+	beforeJSON, err := changeJSON(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := changeJSON(after)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO change_history (resource_type, resource_id, action, actor, before, after, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP)
+	`
+
+	_, err = exec.ExecContext(ctx, query, resourceType, resourceID, action, actorFromContext(ctx), beforeJSON, afterJSON)
+	if err != nil {
+		return fmt.Errorf("failed to record change: %w", err)
+	}
+	return nil
+}
+
+// changeJSON encodes a state for the change history, keeping nil as NULL
+func changeJSON(state interface{}) (interface{}, error) {
+	switch s := state.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		if s == nil {
+			return nil, nil
+		}
+		return string(s), nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode change: %w", err)
+	}
+	return string(data), nil
+}
+
+// ChangeHistoryRepository handles database operations for the change history
+type ChangeHistoryRepository struct {
+	db *DB
+}
+
+// NewChangeHistoryRepository creates a new change history repository
+func NewChangeHistoryRepository(db *DB) *ChangeHistoryRepository {
+	return &ChangeHistoryRepository{db: db}
+}
+
+// Ensure ChangeHistoryRepository implements ChangeHistoryRepositoryInterface
+var _ ChangeHistoryRepositoryInterface = (*ChangeHistoryRepository)(nil)
+
+// RecordPolicyChange records a change to the policies of a route, given as
+// "METHOD path", with their JSON before and after the change
+func (r *ChangeHistoryRepository) RecordPolicyChange(ctx context.Context, route, action string, before, after []byte) error {
+	ctx, cancel := r.db.startQuery(ctx, "change_history.record")
+	defer cancel()
+
+	return recordChange(ctx, r.db, ResourcePolicy, route, action, before, after)
+}
+
+// ListChanges returns the changes matching filter, newest first
+func (r *ChangeHistoryRepository) ListChanges(ctx context.Context, filter ChangeFilter) ([]Change, error) {
+	ctx, cancel := r.db.startQuery(ctx, "change_history.list")
+	defer cancel()
+
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.ResourceType != "" {
+		add("resource_type = $%d", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		add("resource_id = $%d", filter.ResourceID)
+	}
+	if filter.BeforeID > 0 {
+		add("id < $%d", filter.BeforeID)
+	}
+
+	query := `
+		SELECT id, resource_type, resource_id, action, actor, before, after, created_at
+		FROM change_history
+	`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY id DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	changes := []Change{}
+	if err := r.db.SelectContext(ctx, &changes, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list changes: %w", err)
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangeHistory_RecordsAllowlistMutations(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewAllowlistRepository(db)
+	history := NewChangeHistoryRepository(db)
+	ctx := WithActor(context.Background(), "0x742d35cc6634c0532925a3b844bc9e7595f0beb8")
+
+	allowlist, err := repo.CreateAllowlist(ctx, "Holders", "Token holders")
+	require.NoError(t, err)
+	allowlist.Description = "Verified token holders"
+	require.NoError(t, repo.UpdateAllowlist(ctx, allowlist))
+	require.NoError(t, repo.AddAddresses(ctx, allowlist.ID, []string{
+		"0x1111111111111111111111111111111111111111",
+		"0x2222222222222222222222222222222222222222",
+	}))
+	// Re-adding an address changes nothing and is not recorded
+	require.NoError(t, repo.AddAddress(ctx, allowlist.ID, "0x1111111111111111111111111111111111111111"))
+	require.NoError(t, repo.RemoveAddress(ctx, allowlist.ID, "0x2222222222222222222222222222222222222222"))
+	require.NoError(t, repo.DeleteAllowlist(context.Background(), allowlist.ID))
+
+	changes, err := history.ListChanges(context.Background(), ChangeFilter{
+		ResourceType: ResourceAllowlist,
+		ResourceID:   strconv.FormatInt(allowlist.ID, 10),
+	})
+	require.NoError(t, err)
+	require.Len(t, changes, 5)
+
+	// Newest first
+	assert.Equal(t, ChangeDeleted, changes[0].Action)
+	assert.Equal(t, SystemActor, changes[0].Actor)
+	assert.JSONEq(t, `{"name":"Holders","description":"Verified token holders","entryCount":1}`, string(changes[0].Before))
+	assert.Nil(t, changes[0].After)
+
+	assert.Equal(t, ChangeAddressRemoved, changes[1].Action)
+	assert.JSONEq(t, `{"addresses":["0x2222222222222222222222222222222222222222"]}`, string(changes[1].Before))
+
+	assert.Equal(t, ChangeAddressesAdded, changes[2].Action)
+	assert.JSONEq(t, `{"addresses":["0x1111111111111111111111111111111111111111","0x2222222222222222222222222222222222222222"]}`, string(changes[2].After))
+
+	assert.Equal(t, ChangeUpdated, changes[3].Action)
+	assert.Equal(t, "0x742d35cc6634c0532925a3b844bc9e7595f0beb8", changes[3].Actor)
+	assert.JSONEq(t, `{"name":"Holders","description":"Token holders"}`, string(changes[3].Before))
+	assert.JSONEq(t, `{"name":"Holders","description":"Verified token holders"}`, string(changes[3].After))
+
+	assert.Equal(t, ChangeCreated, changes[4].Action)
+	assert.Nil(t, changes[4].Before)
+
+	page, err := history.ListChanges(context.Background(), ChangeFilter{BeforeID: changes[1].ID, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Equal(t, changes[2].ID, page[0].ID)
+}
+
+func TestChangeHistory_RecordPolicyChange(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	history := NewChangeHistoryRepository(db)
+	ctx := context.Background()
+
+	require.NoError(t, history.RecordPolicyChange(ctx, "GET /api/data", ChangeCreated, nil, []byte(`[{"method":"GET","path":"/api/data"}]`)))
+
+	changes, err := history.ListChanges(ctx, ChangeFilter{ResourceType: ResourcePolicy})
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "GET /api/data", changes[0].ResourceID)
+	assert.Equal(t, SystemActor, changes[0].Actor)
+	assert.Nil(t, changes[0].Before)
+}
+
+func TestChangeJSON(t *testing.T) {
+	value, err := changeJSON(nil)
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	value, err = changeJSON([]byte(nil))
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	value, err = changeJSON(addressesChange{Addresses: []string{"0x1"}})
+	require.NoError(t, err)
+	assert.Equal(t, `{"addresses":["0x1"]}`, value)
+
+	assert.Equal(t, SystemActor, actorFromContext(context.Background()))
+	assert.Equal(t, "admin", actorFromContext(WithActor(context.Background(), "admin")))
+}
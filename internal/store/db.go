@@ -8,11 +8,26 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	"go.uber.org/zap"
 )
 
-// DB wraps a sqlx database connection with connection pooling
+// Default caps on the time a repository operation may take
+const (
+	DefaultQueryTimeout     = 5 * time.Second
+	DefaultBulkQueryTimeout = time.Minute // Imports and cleanup jobs
+)
+
+// DB wraps a sqlx database connection with connection pooling. Statements run
+// through its context methods are reported once Instrument is called.
 type DB struct {
 	*sqlx.DB
+
+	observer           QueryObserver
+	slowQueryThreshold time.Duration
+	logger             *zap.Logger
+
+	queryTimeout     time.Duration
+	bulkQueryTimeout time.Duration
 }
 
 // PoolConfig holds database connection pool configuration
@@ -41,7 +56,40 @@ func Connect(ctx context.Context, databaseURL string, poolCfg PoolConfig) (*DB,
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &DB{db}, nil
+	return &DB{
+		DB:               db,
+		queryTimeout:     DefaultQueryTimeout,
+		bulkQueryTimeout: DefaultBulkQueryTimeout,
+	}, nil
+}
+
+// SetQueryTimeouts caps the time repository operations may take, so a stuck
+// connection cannot hold a request until the server's write timeout. bulk applies
+// to imports and cleanup jobs. 0 leaves operations bounded by the caller's context
+// only. Call before the DB is shared.
+func (d *DB) SetQueryTimeouts(query, bulk time.Duration) {
+	d.queryTimeout = query
+	d.bulkQueryTimeout = bulk
+}
+
+// startQuery derives the context of a repository operation from the caller's:
+// statements are reported under name and cancelled after the query timeout, or
+// at the caller's deadline if that comes first. The cancel func must be called.
+func (d *DB) startQuery(ctx context.Context, name string) (context.Context, context.CancelFunc) {
+	return withOperationTimeout(withQueryName(ctx, name), d.queryTimeout)
+}
+
+// startBulkQuery is startQuery for operations bounded by the bulk query timeout
+func (d *DB) startBulkQuery(ctx context.Context, name string) (context.Context, context.CancelFunc) {
+	return withOperationTimeout(withQueryName(ctx, name), d.bulkQueryTimeout)
+}
+
+// withOperationTimeout caps ctx at timeout; 0 leaves ctx unchanged
+func withOperationTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
 }
 
 // Close closes the database connection
@@ -68,9 +116,9 @@ type PoolStats struct {
 	Idle               int // The number of idle connections
 }
 
-// RunMigrations runs all database migrations
+// RunMigrations runs all database migrations, one process at a time
 func (d *DB) RunMigrations() error {
-	return Migrate(context.Background(), d.DB)
+	return MigrateLocked(context.Background(), d.DB)
 }
 
 // CheckDatabaseHealth verifies database connectivity with a simple query.
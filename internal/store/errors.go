@@ -20,6 +20,9 @@ var (
 
 	// ErrInvalidInput is returned when input validation fails
 	ErrInvalidInput = errors.New("invalid input")
+
+	// ErrRevoked is returned when a resource has been revoked
+	ErrRevoked = errors.New("resource has been revoked")
 )
 
 // NotFoundError wraps ErrNotFound with additional context
@@ -81,3 +84,17 @@ func (e *ExpiredError) Error() string {
 func (e *ExpiredError) Unwrap() error {
 	return ErrExpired
 }
+
+// RevokedError wraps ErrRevoked with additional context
+type RevokedError struct {
+	Resource string
+	ID       interface{}
+}
+
+func (e *RevokedError) Error() string {
+	return fmt.Sprintf("%s has been revoked: %v", e.Resource, e.ID)
+}
+
+func (e *RevokedError) Unwrap() error {
+	return ErrRevoked
+}
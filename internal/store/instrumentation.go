@@ -0,0 +1,230 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// Error classes reported for failed statements
+const (
+	ErrorClassTimeout       = "timeout"       // Statement exceeded its context deadline
+	ErrorClassCanceled      = "canceled"      // Caller went away
+	ErrorClassConnection    = "connection"    // Connection lost or refused
+	ErrorClassConstraint    = "constraint"    // Unique, foreign key or check violation
+	ErrorClassSerialization = "serialization" // Serialization failure or deadlock
+	ErrorClassQuery         = "query"         // Syntax, undefined column and other statement errors
+	ErrorClassOther         = "other"
+)
+
+// unnamedQuery is reported for statements run without withQueryName
+const unnamedQuery = "unnamed"
+
+// QueryObserver receives the outcome of every repository statement. errorClass is
+// empty for statements that succeeded, including queries that found no rows.
+type QueryObserver interface {
+	ObserveQuery(name string, duration time.Duration, errorClass string)
+}
+
+// queryNameKey carries the name statements are reported under
+type queryNameKey struct{}
+
+// withQueryName names the statements run with ctx in metrics and slow query logs.
+// Repositories name their operations "<resource>.<operation>".
+func withQueryName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, queryNameKey{}, name)
+}
+
+// queryName returns the name set by withQueryName
+func queryName(ctx context.Context) string {
+	if name, ok := ctx.Value(queryNameKey{}).(string); ok {
+		return name
+	}
+	return unnamedQuery
+}
+
+// Instrument reports every statement to observer and logs statements slower than
+// slowQueryThreshold (0 disables the log). Call before the DB is shared.
+func (d *DB) Instrument(observer QueryObserver, slowQueryThreshold time.Duration, logger *zap.Logger) {
+	d.observer = observer
+	d.slowQueryThreshold = slowQueryThreshold
+	d.logger = logger
+}
+
+// observe reports a statement that started at start
+func (d *DB) observe(ctx context.Context, query string, start time.Time, err error) {
+	if d.observer == nil && d.logger == nil {
+		return
+	}
+
+	duration := time.Since(start)
+	name := queryName(ctx)
+	class := ClassifyError(err)
+
+	if d.observer != nil {
+		d.observer.ObserveQuery(name, duration, class)
+	}
+	if d.logger != nil && d.slowQueryThreshold > 0 && duration >= d.slowQueryThreshold {
+		// Arguments are never logged: they include addresses and key hashes
+		d.logger.Warn("slow query",
+			zap.String("query_name", name),
+			zap.Duration("duration", duration),
+			zap.Duration("threshold", d.slowQueryThreshold),
+			zap.String("error_class", class),
+			zap.String("statement", query))
+	}
+}
+
+// ClassifyError returns the class of a statement error, or "" for nil and
+// sql.ErrNoRows, which is an expected outcome rather than a failure
+func ClassifyError(err error) string {
+	if err == nil || errors.Is(err, sql.ErrNoRows) {
+		return ""
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorClassTimeout
+	}
+	if errors.Is(err, context.Canceled) {
+		return ErrorClassCanceled
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return ErrorClassConnection
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Class() {
+		case "08", "57": // Connection exception, operator intervention (e.g. shutdown)
+			if pqErr.Code == "57014" { // query_canceled, e.g. statement_timeout
+				return ErrorClassTimeout
+			}
+			return ErrorClassConnection
+		case "23":
+			return ErrorClassConstraint
+		case "40":
+			return ErrorClassSerialization
+		case "42", "22":
+			return ErrorClassQuery
+		}
+		return ErrorClassOther
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ErrorClassTimeout
+		}
+		return ErrorClassConnection
+	}
+	return ErrorClassOther
+}
+
+// QueryRowContext runs a query expected to return at most one row, reporting it
+func (d *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := d.DB.QueryRowContext(ctx, query, args...)
+	d.observe(ctx, query, start, row.Err())
+	return row
+}
+
+// QueryRowxContext runs a query expected to return at most one row, reporting it
+func (d *DB) QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row {
+	start := time.Now()
+	row := d.DB.QueryRowxContext(ctx, query, args...)
+	d.observe(ctx, query, start, row.Err())
+	return row
+}
+
+// QueryContext runs a query returning rows, reporting it
+func (d *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := d.DB.QueryContext(ctx, query, args...)
+	d.observe(ctx, query, start, err)
+	return rows, err
+}
+
+// ExecContext runs a statement without returning rows, reporting it
+func (d *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	result, err := d.DB.ExecContext(ctx, query, args...)
+	d.observe(ctx, query, start, err)
+	return result, err
+}
+
+// GetContext scans a single row into dest, reporting the query
+func (d *DB) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	start := time.Now()
+	err := d.DB.GetContext(ctx, dest, query, args...)
+	d.observe(ctx, query, start, err)
+	return err
+}
+
+// SelectContext scans all rows into dest, reporting the query
+func (d *DB) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	start := time.Now()
+	err := d.DB.SelectContext(ctx, dest, query, args...)
+	d.observe(ctx, query, start, err)
+	return err
+}
+
+// Tx wraps a transaction so its statements are reported like those of the DB
+type Tx struct {
+	*sqlx.Tx
+	db *DB
+}
+
+// BeginTxx begins a transaction whose statements are reported
+func (d *DB) BeginTxx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := d.DB.BeginTxx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx, db: d}, nil
+}
+
+// ExecContext runs a statement in the transaction, reporting it
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	result, err := tx.Tx.ExecContext(ctx, query, args...)
+	tx.db.observe(ctx, query, start, err)
+	return result, err
+}
+
+// QueryRowxContext runs a query in the transaction expected to return at most one row, reporting it
+func (tx *Tx) QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row {
+	start := time.Now()
+	row := tx.Tx.QueryRowxContext(ctx, query, args...)
+	tx.db.observe(ctx, query, start, row.Err())
+	return row
+}
+
+// PrepareContext prepares a statement in the transaction whose executions are reported
+func (tx *Tx) PrepareContext(ctx context.Context, query string) (*Stmt, error) {
+	stmt, err := tx.Tx.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &Stmt{Stmt: stmt, db: tx.db, query: query}, nil
+}
+
+// Stmt wraps a prepared statement so its executions are reported
+type Stmt struct {
+	*sql.Stmt
+	db    *DB
+	query string
+}
+
+// ExecContext executes the prepared statement, reporting it
+func (s *Stmt) ExecContext(ctx context.Context, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	result, err := s.Stmt.ExecContext(ctx, args...)
+	s.db.observe(ctx, s.query, start, err)
+	return result, err
+}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// recordingObserver records observed statements
+type recordingObserver struct {
+	names   []string
+	classes []string
+}
+
+func (o *recordingObserver) ObserveQuery(name string, duration time.Duration, errorClass string) {
+	o.names = append(o.names, name)
+	o.classes = append(o.classes, errorClass)
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, ""},
+		{"no rows", sql.ErrNoRows, ""},
+		{"wrapped no rows", fmt.Errorf("get user: %w", sql.ErrNoRows), ""},
+		{"deadline", context.DeadlineExceeded, ErrorClassTimeout},
+		{"canceled", context.Canceled, ErrorClassCanceled},
+		{"bad connection", driver.ErrBadConn, ErrorClassConnection},
+		{"statement timeout", &pq.Error{Code: "57014"}, ErrorClassTimeout},
+		{"admin shutdown", &pq.Error{Code: "57P01"}, ErrorClassConnection},
+		{"connection failure", &pq.Error{Code: "08006"}, ErrorClassConnection},
+		{"unique violation", &pq.Error{Code: "23505"}, ErrorClassConstraint},
+		{"foreign key violation", fmt.Errorf("insert: %w", &pq.Error{Code: "23503"}), ErrorClassConstraint},
+		{"deadlock", &pq.Error{Code: "40P01"}, ErrorClassSerialization},
+		{"undefined column", &pq.Error{Code: "42703"}, ErrorClassQuery},
+		{"disk full", &pq.Error{Code: "53100"}, ErrorClassOther},
+		{"unknown", errors.New("boom"), ErrorClassOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ClassifyError(tt.err))
+		})
+	}
+}
+
+func TestDB_ObserveReportsNamedQueries(t *testing.T) {
+	obs := &recordingObserver{}
+	db := &DB{}
+	db.Instrument(obs, 0, nil)
+
+	ctx := withQueryName(context.Background(), "users.get_by_address")
+	db.observe(ctx, "SELECT 1", time.Now(), nil)
+	db.observe(context.Background(), "SELECT 1", time.Now(), &pq.Error{Code: "23505"})
+
+	assert.Equal(t, []string{"users.get_by_address", unnamedQuery}, obs.names)
+	assert.Equal(t, []string{"", ErrorClassConstraint}, obs.classes)
+}
+
+func TestDB_ObserveLogsSlowQueries(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	db := &DB{}
+	db.Instrument(nil, 50*time.Millisecond, zap.New(core))
+
+	ctx := withQueryName(context.Background(), "api_keys.validate")
+	db.observe(ctx, "SELECT fast", time.Now(), nil)
+	db.observe(ctx, "SELECT slow", time.Now().Add(-time.Second), context.DeadlineExceeded)
+
+	require.Equal(t, 1, logs.Len())
+	entry := logs.All()[0]
+	assert.Equal(t, "slow query", entry.Message)
+	fields := entry.ContextMap()
+	assert.Equal(t, "api_keys.validate", fields["query_name"])
+	assert.Equal(t, "SELECT slow", fields["statement"])
+	assert.Equal(t, ErrorClassTimeout, fields["error_class"])
+}
+
+func TestDB_ObserveWithoutThresholdDoesNotLog(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	db := &DB{}
+	db.Instrument(nil, 0, zap.New(core))
+
+	db.observe(context.Background(), "SELECT slow", time.Now().Add(-time.Hour), nil)
+
+	assert.Equal(t, 0, logs.Len())
+}
@@ -2,6 +2,8 @@ package store
 
 import (
 	"context"
+	"encoding/json"
+	"time"
 )
 
 // APIKeyRepositoryInterface defines the contract for API key storage operations
@@ -14,19 +16,151 @@ type APIKeyRepositoryInterface interface {
 	UpdateLastUsed(ctx context.Context, keyHash string) error
 }
 
+// KeyExpiryRepositoryInterface defines the contract for finding API keys about to expire
+type KeyExpiryRepositoryInterface interface {
+	ListExpiringKeys(ctx context.Context, before time.Time) ([]ExpiringAPIKey, error)
+	MarkExpiryNotified(ctx context.Context, id int64) error
+}
+
+// KeyRevocationRepositoryInterface defines the contract for revoking API keys in bulk
+type KeyRevocationRepositoryInterface interface {
+	ListKeysMatching(ctx context.Context, filter KeyRevocationFilter) ([]APIKey, error)
+	RevokeKeysMatching(ctx context.Context, filter KeyRevocationFilter) ([]APIKey, error)
+}
+
+// KeyDeprecationRepositoryInterface defines the contract for deprecating API keys
+// ahead of their revocation at a sunset date
+type KeyDeprecationRepositoryInterface interface {
+	CreateKeyDeprecation(ctx context.Context, filter KeyRevocationFilter, message string, sunsetAt time.Time) (*KeyDeprecation, error)
+	GetKeyDeprecation(ctx context.Context, id int64) (*KeyDeprecation, error)
+	ListKeyDeprecations(ctx context.Context) ([]KeyDeprecation, error)
+	DeleteKeyDeprecation(ctx context.Context, id int64) error
+}
+
+// KeyTransferRepositoryInterface defines the contract for reassigning API keys
+// to another owner
+type KeyTransferRepositoryInterface interface {
+	GetAPIKey(ctx context.Context, id int64) (*APIKey, error)
+	TransferAPIKey(ctx context.Context, id, toUserID int64) (*APIKey, int64, error)
+}
+
+// KeyEscrowRepositoryInterface defines the contract for escrowing API keys and
+// restoring them from the escrow
+type KeyEscrowRepositoryInterface interface {
+	ListEscrowedAPIKeys(ctx context.Context) ([]EscrowedAPIKey, error)
+	RestoreAPIKey(ctx context.Context, key EscrowedAPIKey) (bool, error)
+}
+
 // UserRepositoryInterface defines the contract for user storage operations
 type UserRepositoryInterface interface {
 	GetOrCreateUserByAddress(ctx context.Context, address string) (*User, error)
 	GetUserByAddress(ctx context.Context, address string) (*User, error)
 	GetUserByID(ctx context.Context, id int64) (*User, error)
+	RecordLogin(ctx context.Context, id int64) error
+}
+
+// RoleRepositoryInterface defines the contract for managing administrative roles
+type RoleRepositoryInterface interface {
+	GetOrCreateUserByAddress(ctx context.Context, address string) (*User, error)
+	SetUserRole(ctx context.Context, id int64, role string) (*User, error)
+	ListUsersWithRoles(ctx context.Context) ([]User, error)
+	CountUsersWithRole(ctx context.Context, role string) (int, error)
+}
+
+// WalletRepositoryInterface defines the contract for wallets linked to a user
+type WalletRepositoryInterface interface {
+	LinkWallet(ctx context.Context, userID int64, address string) (*Wallet, error)
+	UnlinkWallet(ctx context.Context, userID int64, address string) error
+	ListWallets(ctx context.Context, userID int64) ([]Wallet, error)
+	GetWalletOwner(ctx context.Context, address string) (int64, error)
+	ListLinkedAddresses(ctx context.Context, address string) ([]string, error)
+}
+
+// WebAuthnRepositoryInterface defines the contract for passkeys registered for step-up verification
+type WebAuthnRepositoryInterface interface {
+	CreateCredential(ctx context.Context, credential *WebAuthnCredential) (*WebAuthnCredential, error)
+	ListCredentials(ctx context.Context, userID int64) ([]WebAuthnCredential, error)
+	GetCredential(ctx context.Context, userID int64, credentialID []byte) (*WebAuthnCredential, error)
+	RecordUse(ctx context.Context, id int64, signCount uint32) error
+	DeleteCredential(ctx context.Context, userID int64, id int64) error
+}
+
+// ServiceAccountRepositoryInterface defines the contract for service account operations
+type ServiceAccountRepositoryInterface interface {
+	CreateServiceAccount(ctx context.Context, name string, ownerID int64) (*User, error)
+	ListServiceAccounts(ctx context.Context, ownerID int64) ([]User, error)
 }
 
 // AllowlistRepositoryInterface defines the contract for allowlist operations
 type AllowlistRepositoryInterface interface {
-	CreateAllowlist(ctx context.Context, userID int64, name, description string) (int64, error)
-	ListAllowlists(ctx context.Context, userID int64) ([]Allowlist, error)
-	AddAddressesToAllowlist(ctx context.Context, allowlistID int64, addresses []string) error
-	RemoveAddressFromAllowlist(ctx context.Context, allowlistID int64, address string) error
-	IsAddressInAllowlist(ctx context.Context, allowlistID int64, address string) (bool, error)
+	CreateAllowlist(ctx context.Context, name, description string) (*Allowlist, error)
+	GetAllowlist(ctx context.Context, id int64) (*Allowlist, error)
+	ListAllowlists(ctx context.Context) ([]AllowlistWithCount, error)
+	UpdateAllowlist(ctx context.Context, allowlist *Allowlist) error
 	DeleteAllowlist(ctx context.Context, id int64) error
+	AddAddress(ctx context.Context, allowlistID int64, address string) error
+	RemoveAddress(ctx context.Context, allowlistID int64, address string) error
+	AddAddresses(ctx context.Context, allowlistID int64, addresses []string) error
+	CheckAddress(ctx context.Context, allowlistID int64, address string) (bool, error)
+	FindAddresses(ctx context.Context, allowlistID int64, addresses []string) ([]string, error)
+	GetAddresses(ctx context.Context, allowlistID int64) ([]string, error)
+	StreamEntries(ctx context.Context, allowlistID int64, fn func(AllowlistEntry) error) error
+	ListAddressChanges(ctx context.Context, allowlistID, afterID int64, limit int) ([]AddressChange, error)
+	LatestAddressChangeID(ctx context.Context, allowlistID int64) (int64, error)
+}
+
+// ChangeHistoryRepositoryInterface defines the contract for the access-control change history
+type ChangeHistoryRepositoryInterface interface {
+	RecordPolicyChange(ctx context.Context, route, action string, before, after []byte) error
+	ListChanges(ctx context.Context, filter ChangeFilter) ([]Change, error)
+}
+
+// SessionRepositoryInterface defines the contract for refresh token sessions
+type SessionRepositoryInterface interface {
+	CreateSession(ctx context.Context, userID int64, address string, ttl time.Duration) (string, *Session, error)
+	RotateSession(ctx context.Context, rawToken string, ttl time.Duration) (string, *Session, error)
+	RevokeSession(ctx context.Context, rawToken string) error
+}
+
+// RevokedTokenRepositoryInterface defines the contract for the access token revocation list
+type RevokedTokenRepositoryInterface interface {
+	RevokeToken(ctx context.Context, id string, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, id string) (bool, error)
+}
+
+// QuotaRepositoryInterface defines the contract for shared policy quota counters
+type QuotaRepositoryInterface interface {
+	Reserve(ctx context.Context, key string, limit int64, ttl time.Duration) (bool, error)
+	Release(ctx context.Context, key string) error
+	DeleteExpiredQuotas(ctx context.Context) (int64, error)
+}
+
+// BlocklistRepositoryInterface defines the contract for the global blocklist
+type BlocklistRepositoryInterface interface {
+	BlockAddress(ctx context.Context, address, reason string) (*BlockedAddress, error)
+	UnblockAddress(ctx context.Context, address string) error
+	ListBlockedAddresses(ctx context.Context) ([]BlockedAddress, error)
+	FindBlockedAddresses(ctx context.Context, addresses []string) ([]string, error)
+}
+
+// PolicyRepositoryInterface defines the contract for persisted access control policies
+type PolicyRepositoryInterface interface {
+	ListPolicies(ctx context.Context) ([]json.RawMessage, error)
+	CreatePolicy(ctx context.Context, policy json.RawMessage) (int64, error)
+	UpdatePolicy(ctx context.Context, id int64, policy json.RawMessage) error
+	DeletePolicy(ctx context.Context, id int64) error
+}
+
+// LoginRepositoryInterface defines the contract for login history operations
+type LoginRepositoryInterface interface {
+	CreateLogin(ctx context.Context, login *Login) error
+	ListLogins(ctx context.Context, userID int64, limit int) ([]Login, error)
+}
+
+// SecurityEventRepositoryInterface defines the contract for the security events of
+// each wallet
+type SecurityEventRepositoryInterface interface {
+	CreateSecurityEvent(ctx context.Context, event *SecurityEvent) error
+	ListSecurityEvents(ctx context.Context, subject string, beforeID int64, limit int) ([]SecurityEvent, error)
+	DeleteSecurityEventsBefore(ctx context.Context, cutoff time.Time) (int64, error)
 }
@@ -0,0 +1,142 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// KeyDeprecation announces that the API keys matching its filter are deprecated
+// and will be revoked at SunsetAt, so integrators can migrate to new keys first.
+// It stays in effect after the sunset: keys matching it keep being revoked.
+type KeyDeprecation struct {
+	ID            int64      `db:"id"`
+	Scope         string     `db:"scope"`
+	CreatedAfter  *time.Time `db:"created_after"`
+	CreatedBefore *time.Time `db:"created_before"`
+	KeyPrefix     string     `db:"key_prefix"`
+	Message       string     `db:"message"`
+	SunsetAt      time.Time  `db:"sunset_at"`
+	CreatedBy     string     `db:"created_by"`
+	CreatedAt     time.Time  `db:"created_at"`
+}
+
+// Filter returns the criteria selecting the deprecated keys
+func (d KeyDeprecation) Filter() KeyRevocationFilter {
+	return KeyRevocationFilter{
+		Scope:         d.Scope,
+		CreatedAfter:  d.CreatedAfter,
+		CreatedBefore: d.CreatedBefore,
+		KeyPrefix:     d.KeyPrefix,
+	}
+}
+
+// KeyDeprecationRepository manages API key deprecations
+type KeyDeprecationRepository struct {
+	db *DB
+}
+
+// NewKeyDeprecationRepository creates a new key deprecation repository
+func NewKeyDeprecationRepository(db *DB) *KeyDeprecationRepository {
+	return &KeyDeprecationRepository{db: db}
+}
+
+// Ensure KeyDeprecationRepository implements KeyDeprecationRepositoryInterface
+var _ KeyDeprecationRepositoryInterface = (*KeyDeprecationRepository)(nil)
+
+// CreateKeyDeprecation deprecates the keys matching filter until sunsetAt, as
+// made by the actor set with WithActor
+func (r *KeyDeprecationRepository) CreateKeyDeprecation(ctx context.Context, filter KeyRevocationFilter, message string, sunsetAt time.Time) (*KeyDeprecation, error) {
+	if filter.IsEmpty() {
+		return nil, fmt.Errorf("at least one deprecation criterion is required")
+	}
+
+	ctx, cancel := r.db.startQuery(ctx, "key_deprecations.create")
+	defer cancel()
+
+	query := `
+		INSERT INTO key_deprecations (scope, created_after, created_before, key_prefix, message, sunset_at, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, CURRENT_TIMESTAMP)
+		RETURNING id, scope, created_after, created_before, key_prefix, message, sunset_at, created_by, created_at
+	`
+
+	var deprecation KeyDeprecation
+	err := r.db.QueryRowxContext(ctx, query,
+		filter.Scope, filter.CreatedAfter, filter.CreatedBefore, filter.KeyPrefix,
+		message, sunsetAt, actorFromContext(ctx),
+	).StructScan(&deprecation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key deprecation: %w", err)
+	}
+
+	return &deprecation, nil
+}
+
+// GetKeyDeprecation returns a key deprecation by ID
+func (r *KeyDeprecationRepository) GetKeyDeprecation(ctx context.Context, id int64) (*KeyDeprecation, error) {
+	ctx, cancel := r.db.startQuery(ctx, "key_deprecations.get")
+	defer cancel()
+
+	query := `
+		SELECT id, scope, created_after, created_before, key_prefix, message, sunset_at, created_by, created_at
+		FROM key_deprecations
+		WHERE id = $1
+	`
+
+	var deprecation KeyDeprecation
+	if err := r.db.GetContext(ctx, &deprecation, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &NotFoundError{
+				Resource: "key_deprecation",
+				ID:       id,
+			}
+		}
+		return nil, fmt.Errorf("failed to get key deprecation: %w", err)
+	}
+
+	return &deprecation, nil
+}
+
+// ListKeyDeprecations returns every key deprecation, earliest sunset first
+func (r *KeyDeprecationRepository) ListKeyDeprecations(ctx context.Context) ([]KeyDeprecation, error) {
+	ctx, cancel := r.db.startQuery(ctx, "key_deprecations.list")
+	defer cancel()
+
+	query := `
+		SELECT id, scope, created_after, created_before, key_prefix, message, sunset_at, created_by, created_at
+		FROM key_deprecations
+		ORDER BY sunset_at ASC, id ASC
+	`
+
+	deprecations := []KeyDeprecation{}
+	if err := r.db.SelectContext(ctx, &deprecations, query); err != nil {
+		return nil, fmt.Errorf("failed to list key deprecations: %w", err)
+	}
+
+	return deprecations, nil
+}
+
+// DeleteKeyDeprecation cancels a key deprecation. Keys already revoked at its
+// sunset stay revoked.
+func (r *KeyDeprecationRepository) DeleteKeyDeprecation(ctx context.Context, id int64) error {
+	ctx, cancel := r.db.startQuery(ctx, "key_deprecations.delete")
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM key_deprecations WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete key deprecation: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return &NotFoundError{
+			Resource: "key_deprecation",
+			ID:       id,
+		}
+	}
+
+	return nil
+}
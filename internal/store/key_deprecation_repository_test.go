@@ -0,0 +1,47 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyDeprecationRepository(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewKeyDeprecationRepository(db)
+	ctx := WithActor(context.Background(), "0x742d35cc6634c0532925a3b844bc9e7595f0beb0")
+
+	_, err := repo.CreateKeyDeprecation(ctx, KeyRevocationFilter{}, "everything", time.Now().Add(time.Hour))
+	assert.Error(t, err, "an empty filter must not deprecate every key")
+
+	before := time.Now().Truncate(time.Second)
+	later, err := repo.CreateKeyDeprecation(ctx, KeyRevocationFilter{Scope: "legacy"}, "use v2 scopes", time.Now().Add(48*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, "legacy", later.Scope)
+	assert.Equal(t, "0x742d35cc6634c0532925a3b844bc9e7595f0beb0", later.CreatedBy)
+
+	sooner, err := repo.CreateKeyDeprecation(ctx, KeyRevocationFilter{CreatedBefore: &before}, "rotate old keys", time.Now().Add(24*time.Hour))
+	require.NoError(t, err)
+	require.NotNil(t, sooner.CreatedBefore)
+	assert.True(t, sooner.CreatedBefore.Equal(before))
+	assert.Equal(t, KeyRevocationFilter{CreatedBefore: sooner.CreatedBefore}, sooner.Filter())
+
+	list, err := repo.ListKeyDeprecations(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, sooner.ID, list[0].ID, "earliest sunset first")
+
+	got, err := repo.GetKeyDeprecation(ctx, later.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "use v2 scopes", got.Message)
+
+	require.NoError(t, repo.DeleteKeyDeprecation(ctx, later.ID))
+	assert.True(t, errors.Is(repo.DeleteKeyDeprecation(ctx, later.ID), ErrNotFound))
+	_, err = repo.GetKeyDeprecation(ctx, later.ID)
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
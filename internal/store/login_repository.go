@@ -0,0 +1,97 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Login represents a successful sign-in of a user
+type Login struct {
+	ID        int64     `db:"id"`
+	UserID    int64     `db:"user_id"`
+	IPAddress string    `db:"ip_address"`
+	UserAgent string    `db:"user_agent"`
+	ChainID   *int64    `db:"chain_id"` // nil if the sign-in message had no chain ID
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// LoginRepository handles database operations for the login history
+type LoginRepository struct {
+	db *DB
+}
+
+// NewLoginRepository creates a new login repository
+func NewLoginRepository(db *DB) *LoginRepository {
+	return &LoginRepository{db: db}
+}
+
+// Ensure LoginRepository implements LoginRepositoryInterface
+var _ LoginRepositoryInterface = (*LoginRepository)(nil)
+
+// CreateLogin records a sign-in. ID and CreatedAt are set on the given login.
+func (r *LoginRepository) CreateLogin(ctx context.Context, login *Login) error {
+	ctx, cancel := r.db.startQuery(ctx, "logins.create")
+	defer cancel()
+
+	if login == nil {
+		return fmt.Errorf("login cannot be nil")
+	}
+	if login.UserID == 0 {
+		return fmt.Errorf("user_id is required")
+	}
+
+	query := `
+		INSERT INTO logins (user_id, ip_address, user_agent, chain_id, created_at)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, CURRENT_TIMESTAMP)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, login.UserID, login.IPAddress, login.UserAgent, login.ChainID).
+		Scan(&login.ID, &login.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record login: %w", err)
+	}
+
+	return nil
+}
+
+// ListLogins returns the most recent logins of a user, newest first
+func (r *LoginRepository) ListLogins(ctx context.Context, userID int64, limit int) ([]Login, error) {
+	ctx, cancel := r.db.startQuery(ctx, "logins.list")
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, COALESCE(ip_address, ''), COALESCE(user_agent, ''), chain_id, created_at
+		FROM logins
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query logins: %w", err)
+	}
+	defer rows.Close()
+
+	logins := []Login{}
+	for rows.Next() {
+		var login Login
+		var chainID sql.NullInt64
+		if err := rows.Scan(&login.ID, &login.UserID, &login.IPAddress, &login.UserAgent, &chainID, &login.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan login: %w", err)
+		}
+		if chainID.Valid {
+			login.ChainID = &chainID.Int64
+		}
+		logins = append(logins, login)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return logins, nil
+}
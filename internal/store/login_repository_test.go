@@ -0,0 +1,51 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginRepository_CreateAndList(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewLoginRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, db, "0x742d35cc6634c0532925a3b844bc9e7595f0beb0")
+	other := createTestUser(t, db, "0x742d35cc6634c0532925a3b844bc9e7595f0beb1")
+
+	t.Run("records a login", func(t *testing.T) {
+		chainID := int64(1)
+		login := &Login{UserID: user.ID, IPAddress: "203.0.113.7", UserAgent: "curl/8.0", ChainID: &chainID}
+		require.NoError(t, repo.CreateLogin(ctx, login))
+		assert.NotZero(t, login.ID)
+		assert.False(t, login.CreatedAt.IsZero())
+	})
+
+	t.Run("records a login without optional fields", func(t *testing.T) {
+		require.NoError(t, repo.CreateLogin(ctx, &Login{UserID: user.ID}))
+		require.NoError(t, repo.CreateLogin(ctx, &Login{UserID: other.ID}))
+	})
+
+	t.Run("lists the user's logins newest first", func(t *testing.T) {
+		logins, err := repo.ListLogins(ctx, user.ID, 10)
+		require.NoError(t, err)
+		require.Len(t, logins, 2)
+
+		assert.Empty(t, logins[0].IPAddress)
+		assert.Nil(t, logins[0].ChainID)
+		assert.Equal(t, "203.0.113.7", logins[1].IPAddress)
+		assert.Equal(t, "curl/8.0", logins[1].UserAgent)
+		require.NotNil(t, logins[1].ChainID)
+		assert.Equal(t, int64(1), *logins[1].ChainID)
+	})
+
+	t.Run("respects the limit", func(t *testing.T) {
+		logins, err := repo.ListLogins(ctx, user.ID, 1)
+		require.NoError(t, err)
+		assert.Len(t, logins, 1)
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io/fs"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/jmoiron/sqlx"
@@ -14,8 +15,36 @@ import (
 //go:embed migrations/*.sql
 var migrations embed.FS
 
+// migrationLockKey identifies the Postgres advisory lock held while migrating
+const migrationLockKey int64 = 0x6761746b6d6967 // "gatkmig"
+
 // Migrate runs all migration SQL files in order
 func Migrate(ctx context.Context, db *sqlx.DB) error {
+	return migrate(ctx, db)
+}
+
+// MigrateLocked runs all migration SQL files in order while holding a Postgres
+// advisory lock, so replicas starting together apply them one at a time instead
+// of racing on schema changes. A replica waits for the lock while another
+// migrates, then finds the migrations applied; the lock is released when the
+// connection holding it closes, should the process die midway.
+func MigrateLocked(ctx context.Context, db *sqlx.DB) error {
+	conn, err := db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection for migrations: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+
+	return migrate(ctx, conn)
+}
+
+// migrate runs all migration SQL files in order on db
+func migrate(ctx context.Context, db execer) error {
 	migrationDir := "migrations"
 
 	// Read migration directory
@@ -53,3 +82,65 @@ func Migrate(ctx context.Context, db *sqlx.DB) error {
 
 	return nil
 }
+
+// MigrationState reports whether the schema objects a migration creates exist
+type MigrationState struct {
+	Name    string   `json:"name"`
+	Applied bool     `json:"applied"`
+	Missing []string `json:"missing,omitempty"` // Tables and table.column pairs not found
+}
+
+var (
+	createTablePattern = regexp.MustCompile(`(?i)CREATE TABLE (?:IF NOT EXISTS )?(\w+)`)
+	addColumnPattern   = regexp.MustCompile(`(?i)ALTER TABLE (\w+) ADD COLUMN (?:IF NOT EXISTS )?(\w+)`)
+)
+
+// MigrationStatus checks each embedded migration against the database schema.
+// Migrations carry no tracking table, so a migration counts as applied when
+// the tables and columns it creates exist.
+func (d *DB) MigrationStatus(ctx context.Context) ([]MigrationState, error) {
+	ctx, cancel := d.startQuery(ctx, "migrations.status")
+	defer cancel()
+
+	entries, err := fs.ReadDir(migrations, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	var states []MigrationState
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		content, err := fs.ReadFile(migrations, filepath.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		state := MigrationState{Name: entry.Name()}
+		for _, match := range createTablePattern.FindAllStringSubmatch(string(content), -1) {
+			var exists bool
+			if err := d.GetContext(ctx, &exists, `SELECT to_regclass($1) IS NOT NULL`, match[1]); err != nil {
+				return nil, fmt.Errorf("failed to check table %s: %w", match[1], err)
+			}
+			if !exists {
+				state.Missing = append(state.Missing, match[1])
+			}
+		}
+		for _, match := range addColumnPattern.FindAllStringSubmatch(string(content), -1) {
+			var exists bool
+			query := `SELECT EXISTS (SELECT 1 FROM information_schema.columns
+				WHERE table_schema = current_schema() AND table_name = $1 AND column_name = $2)`
+			if err := d.GetContext(ctx, &exists, query, match[1], match[2]); err != nil {
+				return nil, fmt.Errorf("failed to check column %s.%s: %w", match[1], match[2], err)
+			}
+			if !exists {
+				state.Missing = append(state.Missing, match[1]+"."+match[2])
+			}
+		}
+		state.Applied = len(state.Missing) == 0
+		states = append(states, state)
+	}
+
+	return states, nil
+}
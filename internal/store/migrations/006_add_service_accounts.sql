@@ -0,0 +1,9 @@
+-- Allow users without a wallet address (service accounts owned by a wallet user)
+ALTER TABLE users ALTER COLUMN address DROP NOT NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS account_type VARCHAR(16) NOT NULL DEFAULT 'wallet';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS name VARCHAR(255);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS owner_id BIGINT REFERENCES users(id) ON DELETE CASCADE;
+
+-- Service account names are unique per owner
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_owner_name ON users(owner_id, name) WHERE account_type = 'service';
+CREATE INDEX IF NOT EXISTS idx_users_owner_id ON users(owner_id);
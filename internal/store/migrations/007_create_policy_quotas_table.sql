@@ -0,0 +1,9 @@
+-- Create policy_quotas table for quota rule counters (one row per quota, subject and window)
+CREATE TABLE IF NOT EXISTS policy_quotas (
+    key VARCHAR(512) PRIMARY KEY,
+    count BIGINT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Create index for expired counter cleanup
+CREATE INDEX IF NOT EXISTS idx_policy_quotas_expires_at ON policy_quotas(expires_at);
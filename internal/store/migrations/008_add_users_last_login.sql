@@ -0,0 +1,2 @@
+-- Record when wallet users last signed in with SIWE
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMP WITH TIME ZONE;
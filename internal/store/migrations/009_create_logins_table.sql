@@ -0,0 +1,12 @@
+-- Create logins table recording each successful SIWE sign-in
+CREATE TABLE IF NOT EXISTS logins (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    ip_address VARCHAR(45), -- IPv4 or IPv6
+    user_agent TEXT,
+    chain_id BIGINT, -- NULL if the message had no chain ID
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index for listing a user's most recent logins
+CREATE INDEX IF NOT EXISTS idx_logins_user_id_created_at ON logins(user_id, created_at DESC);
@@ -0,0 +1,6 @@
+-- Record when the owner of an API key was told it is about to expire
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS expiry_notified_at TIMESTAMP WITH TIME ZONE;
+
+-- Create index for finding keys that expire soon and have not been notified
+CREATE INDEX IF NOT EXISTS idx_api_keys_expiry_pending ON api_keys(expires_at)
+    WHERE expires_at IS NOT NULL AND expiry_notified_at IS NULL;
@@ -0,0 +1,15 @@
+-- Create change_history table recording every mutation of access-control data.
+-- No foreign keys: the history of a deleted allowlist must outlive it.
+CREATE TABLE IF NOT EXISTS change_history (
+    id BIGSERIAL PRIMARY KEY,
+    resource_type VARCHAR(32) NOT NULL, -- allowlist or policy
+    resource_id VARCHAR(255) NOT NULL, -- Allowlist ID, or "METHOD path" of a policy route
+    action VARCHAR(32) NOT NULL,
+    actor VARCHAR(255) NOT NULL, -- Wallet address, service account, or "system"
+    before JSONB, -- NULL for creations
+    after JSONB, -- NULL for deletions
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index for listing the history of a resource, newest first
+CREATE INDEX IF NOT EXISTS idx_change_history_resource ON change_history(resource_type, resource_id, id DESC);
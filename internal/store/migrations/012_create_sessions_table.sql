@@ -0,0 +1,18 @@
+-- Create sessions table holding refresh tokens for JWT sessions. Only the
+-- SHA256 hash of each token is stored. Rotating a token replaces its row with
+-- a new one in the same family; presenting a rotated token again revokes the
+-- whole family.
+CREATE TABLE IF NOT EXISTS sessions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    address VARCHAR(42) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    family_id VARCHAR(32) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    rotated_at TIMESTAMP WITH TIME ZONE, -- NULL until the token is exchanged
+    revoked_at TIMESTAMP WITH TIME ZONE, -- NULL until logout or reuse detection
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index for revoking every token of a session family
+CREATE INDEX IF NOT EXISTS idx_sessions_family_id ON sessions(family_id);
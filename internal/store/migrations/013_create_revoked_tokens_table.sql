@@ -0,0 +1,10 @@
+-- Create revoked_tokens table: the IDs (jti) of access tokens revoked before
+-- they expire, e.g. on logout. Rows are only needed until the token expires.
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti VARCHAR(64) PRIMARY KEY,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index for deleting entries of expired tokens
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens(expires_at);
@@ -0,0 +1,12 @@
+-- Create user_wallets table linking additional Ethereum addresses to a user.
+-- The user's own address stays in users.address; each linked wallet belongs to
+-- exactly one user and can sign in as that user.
+CREATE TABLE IF NOT EXISTS user_wallets (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    address VARCHAR(42) NOT NULL UNIQUE, -- Ethereum address, lowercase
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index for listing a user's wallets
+CREATE INDEX IF NOT EXISTS idx_user_wallets_user_id ON user_wallets(user_id);
@@ -0,0 +1,15 @@
+-- Create webauthn_credentials table holding the passkeys users register for
+-- step-up verification of sensitive operations such as API key management.
+CREATE TABLE IF NOT EXISTS webauthn_credentials (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    credential_id BYTEA NOT NULL UNIQUE, -- Chosen by the authenticator
+    public_key BYTEA NOT NULL, -- COSE_Key
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create index for listing a user's credentials
+CREATE INDEX IF NOT EXISTS idx_webauthn_credentials_user_id ON webauthn_credentials(user_id);
@@ -0,0 +1,5 @@
+-- Administrative role of wallet users (NULL for regular users)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(16)
+    CHECK (role IN ('viewer', 'operator', 'admin'));
+
+CREATE INDEX IF NOT EXISTS idx_users_role ON users(role) WHERE role IS NOT NULL;
@@ -0,0 +1,24 @@
+-- Create policies table holding the access control policies managed through the
+-- admin API, so they survive restarts. Policies are evaluated in id order.
+CREATE TABLE IF NOT EXISTS policies (
+    id BIGSERIAL PRIMARY KEY,
+    method VARCHAR(16) NOT NULL,
+    path TEXT NOT NULL,
+    logic VARCHAR(8) NOT NULL, -- AND or OR
+    shadow BOOLEAN NOT NULL DEFAULT FALSE, -- Evaluated and logged but not enforced
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create policy_rules table holding the rules of each policy
+CREATE TABLE IF NOT EXISTS policy_rules (
+    id BIGSERIAL PRIMARY KEY,
+    policy_id BIGINT NOT NULL REFERENCES policies(id) ON DELETE CASCADE,
+    position INT NOT NULL, -- Order of the rule within its policy
+    type VARCHAR(64) NOT NULL,
+    config JSONB NOT NULL, -- The rule as written in the policy configuration file
+    UNIQUE (policy_id, position)
+);
+
+-- Create index for finding policies by route
+CREATE INDEX IF NOT EXISTS idx_policies_route ON policies(method, path);
@@ -0,0 +1,3 @@
+-- Points a SCORE policy needs to pass; each of its rules stores the points it is
+-- worth in its config. 0 for AND and OR policies.
+ALTER TABLE policies ADD COLUMN IF NOT EXISTS threshold INT NOT NULL DEFAULT 0;
@@ -0,0 +1,10 @@
+-- Create blocked_addresses table holding the global blocklist: addresses denied
+-- on every gated route even if they pass the route's policies, such as
+-- compromised or sanctioned wallets
+CREATE TABLE IF NOT EXISTS blocked_addresses (
+    id BIGSERIAL PRIMARY KEY,
+    address VARCHAR(42) NOT NULL UNIQUE,
+    reason TEXT NOT NULL DEFAULT '',
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
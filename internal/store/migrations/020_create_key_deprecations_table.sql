@@ -0,0 +1,16 @@
+-- Create key_deprecations table: announcements that the API keys matching a
+-- filter, such as a scope or a range of creation dates, will be revoked at a
+-- sunset date, giving integrators time to migrate to new keys
+CREATE TABLE IF NOT EXISTS key_deprecations (
+    id BIGSERIAL PRIMARY KEY,
+    scope TEXT NOT NULL DEFAULT '',
+    created_after TIMESTAMP WITH TIME ZONE,
+    created_before TIMESTAMP WITH TIME ZONE,
+    key_prefix TEXT NOT NULL DEFAULT '',
+    message TEXT NOT NULL DEFAULT '',
+    sunset_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_key_deprecations_sunset_at ON key_deprecations(sunset_at);
@@ -0,0 +1,20 @@
+-- Create security_events table: the security-relevant audit events of each
+-- wallet (sign-ins, key changes, denials), kept so users can review their own
+-- account activity
+CREATE TABLE IF NOT EXISTS security_events (
+    id BIGSERIAL PRIMARY KEY,
+    subject TEXT NOT NULL,
+    action TEXT NOT NULL,
+    result TEXT NOT NULL DEFAULT '',
+    ip_address TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    method TEXT NOT NULL DEFAULT '',
+    endpoint TEXT NOT NULL DEFAULT '',
+    key_id BIGINT,
+    key_name TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_security_events_subject_id ON security_events(subject, id DESC);
+CREATE INDEX IF NOT EXISTS idx_security_events_created_at ON security_events(created_at);
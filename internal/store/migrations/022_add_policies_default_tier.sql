@@ -0,0 +1,4 @@
+-- Tier a TIER policy grants callers passing none of its rules; each of its rules
+-- stores the tier it grants in its config. Empty for other policies, and for TIER
+-- policies denying such callers.
+ALTER TABLE policies ADD COLUMN IF NOT EXISTS default_tier VARCHAR(64) NOT NULL DEFAULT '';
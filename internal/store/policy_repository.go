@@ -0,0 +1,265 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// PolicyDocument is a policy in the format of the policy configuration file, plus
+// the ID the database assigned it. Rules are kept as written, so the store does not
+// need to know every rule type.
+type PolicyDocument struct {
+	ID     int64             `json:"id,omitempty"`
+	Path   string            `json:"path"`
+	Method string            `json:"method"`
+	Logic  string            `json:"logic"`
+	Shadow bool              `json:"shadow,omitempty"`
+	Rules  []json.RawMessage `json:"rules"`
+	// Threshold of a SCORE policy; 0 for other logic
+	Threshold int `json:"threshold,omitempty"`
+	// Default tier of a TIER policy; "" for other logic
+	DefaultTier string `json:"default_tier,omitempty"`
+}
+
+// policyRow is a row of the policies table
+type policyRow struct {
+	ID          int64     `db:"id"`
+	Method      string    `db:"method"`
+	Path        string    `db:"path"`
+	Logic       string    `db:"logic"`
+	Shadow      bool      `db:"shadow"`
+	Threshold   int       `db:"threshold"`
+	DefaultTier string    `db:"default_tier"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+// policyRuleRow is a row of the policy_rules table
+type policyRuleRow struct {
+	PolicyID int64  `db:"policy_id"`
+	Config   []byte `db:"config"`
+}
+
+// PolicyRepository persists access control policies, so policies managed at
+// runtime survive restarts. Policies are exchanged as JSON documents in the format
+// of the policy configuration file; validating them is up to the policy package.
+// Changes are not recorded in the change history here: the policy manager records
+// them once it applies them.
+type PolicyRepository struct {
+	db *DB
+}
+
+// NewPolicyRepository creates a new PolicyRepository
+func NewPolicyRepository(db *DB) *PolicyRepository {
+	return &PolicyRepository{db: db}
+}
+
+// Ensure PolicyRepository implements PolicyRepositoryInterface
+var _ PolicyRepositoryInterface = (*PolicyRepository)(nil)
+
+// ListPolicies returns every stored policy in evaluation order, each as a
+// PolicyDocument in JSON
+func (r *PolicyRepository) ListPolicies(ctx context.Context) ([]json.RawMessage, error) {
+	ctx, cancel := r.db.startQuery(ctx, "policies.list")
+	defer cancel()
+
+	var rows []policyRow
+	query := `
+		SELECT id, method, path, logic, shadow, threshold, default_tier, created_at, updated_at
+		FROM policies
+		ORDER BY id
+	`
+	if err := r.db.SelectContext(ctx, &rows, query); err != nil {
+		return nil, fmt.Errorf("failed to list policies: %w", err)
+	}
+
+	var rules []policyRuleRow
+	query = `
+		SELECT policy_id, config
+		FROM policy_rules
+		ORDER BY policy_id, position
+	`
+	if err := r.db.SelectContext(ctx, &rules, query); err != nil {
+		return nil, fmt.Errorf("failed to list policy rules: %w", err)
+	}
+
+	rulesByPolicy := make(map[int64][]json.RawMessage, len(rows))
+	for _, rule := range rules {
+		rulesByPolicy[rule.PolicyID] = append(rulesByPolicy[rule.PolicyID], json.RawMessage(rule.Config))
+	}
+
+	policies := make([]json.RawMessage, 0, len(rows))
+	for _, row := range rows {
+		doc := PolicyDocument{
+			ID:          row.ID,
+			Path:        row.Path,
+			Method:      row.Method,
+			Logic:       row.Logic,
+			Shadow:      row.Shadow,
+			Rules:       rulesByPolicy[row.ID],
+			Threshold:   row.Threshold,
+			DefaultTier: row.DefaultTier,
+		}
+		if doc.Rules == nil {
+			doc.Rules = []json.RawMessage{}
+		}
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode policy %d: %w", row.ID, err)
+		}
+		policies = append(policies, data)
+	}
+
+	return policies, nil
+}
+
+// CreatePolicy stores a policy given as a PolicyDocument in JSON and returns its
+// new ID. Any ID in the document is ignored.
+func (r *PolicyRepository) CreatePolicy(ctx context.Context, policy json.RawMessage) (int64, error) {
+	ctx, cancel := r.db.startQuery(ctx, "policies.create")
+	defer cancel()
+
+	doc, err := parsePolicyDocument(policy)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO policies (method, path, logic, shadow, threshold, default_tier, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		RETURNING id
+	`
+
+	var id int64
+	if err := tx.QueryRowxContext(ctx, query, doc.Method, doc.Path, doc.Logic, doc.Shadow, doc.Threshold, doc.DefaultTier).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to create policy: %w", err)
+	}
+
+	if err := insertPolicyRules(ctx, tx, id, doc.Rules); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdatePolicy replaces the policy with the given ID, including all its rules, with
+// a policy given as a PolicyDocument in JSON
+func (r *PolicyRepository) UpdatePolicy(ctx context.Context, id int64, policy json.RawMessage) error {
+	ctx, cancel := r.db.startQuery(ctx, "policies.update")
+	defer cancel()
+
+	doc, err := parsePolicyDocument(policy)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE policies
+		SET method = $2, path = $3, logic = $4, shadow = $5, threshold = $6, default_tier = $7, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+
+	result, err := tx.ExecContext(ctx, query, id, doc.Method, doc.Path, doc.Logic, doc.Shadow, doc.Threshold, doc.DefaultTier)
+	if err != nil {
+		return fmt.Errorf("failed to update policy: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return &NotFoundError{
+			Resource: "policy",
+			ID:       id,
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM policy_rules WHERE policy_id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete policy rules: %w", err)
+	}
+	if err := insertPolicyRules(ctx, tx, id, doc.Rules); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// DeletePolicy deletes the policy with the given ID and its rules
+func (r *PolicyRepository) DeletePolicy(ctx context.Context, id int64) error {
+	ctx, cancel := r.db.startQuery(ctx, "policies.delete")
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM policies WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete policy: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return &NotFoundError{
+			Resource: "policy",
+			ID:       id,
+		}
+	}
+
+	return nil
+}
+
+// parsePolicyDocument decodes a policy document, checking the fields the schema
+// requires
+func parsePolicyDocument(data json.RawMessage) (*PolicyDocument, error) {
+	var doc PolicyDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: invalid policy: %v", ErrInvalidInput, err)
+	}
+	if doc.Method == "" || doc.Path == "" || doc.Logic == "" {
+		return nil, fmt.Errorf("%w: policy method, path and logic are required", ErrInvalidInput)
+	}
+	return &doc, nil
+}
+
+// insertPolicyRules stores the rules of a policy in order
+func insertPolicyRules(ctx context.Context, exec execer, policyID int64, rules []json.RawMessage) error {
+	query := `
+		INSERT INTO policy_rules (policy_id, position, type, config)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	for i, rule := range rules {
+		var header struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(rule, &header); err != nil || header.Type == "" {
+			return fmt.Errorf("%w: rule %d has no type", ErrInvalidInput, i)
+		}
+		if _, err := exec.ExecContext(ctx, query, policyID, i, header.Type, []byte(rule)); err != nil {
+			return fmt.Errorf("failed to create policy rule: %w", err)
+		}
+	}
+	return nil
+}
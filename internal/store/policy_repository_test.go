@@ -0,0 +1,97 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyRepository(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewPolicyRepository(db)
+	ctx := context.Background()
+
+	first := json.RawMessage(`{"path":"/api/data","method":"GET","logic":"OR","rules":[{"type":"has_scope","scope":"read"},{"type":"in_allowlist","addresses":["0x742d35cc6634c0532925a3b844bc9e7595f0beb8"]}]}`)
+	second := json.RawMessage(`{"path":"/api/data","method":"POST","logic":"AND","shadow":true,"rules":[]}`)
+
+	firstID, err := repo.CreatePolicy(ctx, first)
+	require.NoError(t, err)
+	secondID, err := repo.CreatePolicy(ctx, second)
+	require.NoError(t, err)
+	assert.Greater(t, secondID, firstID)
+
+	t.Run("lists policies in order with their rules", func(t *testing.T) {
+		policies, err := repo.ListPolicies(ctx)
+		require.NoError(t, err)
+		require.Len(t, policies, 2)
+
+		var doc PolicyDocument
+		require.NoError(t, json.Unmarshal(policies[0], &doc))
+		assert.Equal(t, firstID, doc.ID)
+		assert.Equal(t, "GET", doc.Method)
+		require.Len(t, doc.Rules, 2)
+		assert.JSONEq(t, `{"type":"has_scope","scope":"read"}`, string(doc.Rules[0]))
+
+		require.NoError(t, json.Unmarshal(policies[1], &doc))
+		assert.True(t, doc.Shadow)
+		assert.Empty(t, doc.Rules)
+	})
+
+	t.Run("update replaces the rules", func(t *testing.T) {
+		updated := json.RawMessage(`{"path":"/api/data","method":"GET","logic":"AND","rules":[{"type":"has_scope","scope":"write"}]}`)
+		require.NoError(t, repo.UpdatePolicy(ctx, firstID, updated))
+
+		policies, err := repo.ListPolicies(ctx)
+		require.NoError(t, err)
+		var doc PolicyDocument
+		require.NoError(t, json.Unmarshal(policies[0], &doc))
+		assert.Equal(t, firstID, doc.ID)
+		assert.Equal(t, "AND", doc.Logic)
+		require.Len(t, doc.Rules, 1)
+		assert.JSONEq(t, `{"type":"has_scope","scope":"write"}`, string(doc.Rules[0]))
+
+		scored := json.RawMessage(`{"path":"/api/data","method":"GET","logic":"SCORE","threshold":60,"rules":[{"type":"has_scope","scope":"write","points":50}]}`)
+		require.NoError(t, repo.UpdatePolicy(ctx, firstID, scored))
+		policies, err = repo.ListPolicies(ctx)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(policies[0], &doc))
+		assert.Equal(t, 60, doc.Threshold)
+		assert.JSONEq(t, `{"type":"has_scope","scope":"write","points":50}`, string(doc.Rules[0]))
+
+		tiered := json.RawMessage(`{"path":"/api/data","method":"GET","logic":"TIER","default_tier":"bronze","rules":[{"type":"has_scope","scope":"write","tier":"gold"}]}`)
+		require.NoError(t, repo.UpdatePolicy(ctx, firstID, tiered))
+		policies, err = repo.ListPolicies(ctx)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(policies[0], &doc))
+		assert.Equal(t, "bronze", doc.DefaultTier)
+		assert.JSONEq(t, `{"type":"has_scope","scope":"write","tier":"gold"}`, string(doc.Rules[0]))
+
+		err = repo.UpdatePolicy(ctx, 999, updated)
+		assert.True(t, errors.Is(err, ErrNotFound))
+	})
+
+	t.Run("rejects invalid documents", func(t *testing.T) {
+		_, err := repo.CreatePolicy(ctx, json.RawMessage(`{"path":"/api/data"}`))
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+		_, err = repo.CreatePolicy(ctx, json.RawMessage(`{"path":"/api/data","method":"GET","logic":"OR","rules":[{"scope":"read"}]}`))
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+	})
+
+	t.Run("delete removes the policy and its rules", func(t *testing.T) {
+		require.NoError(t, repo.DeletePolicy(ctx, firstID))
+		assert.True(t, errors.Is(repo.DeletePolicy(ctx, firstID), ErrNotFound))
+
+		policies, err := repo.ListPolicies(ctx)
+		require.NoError(t, err)
+		assert.Len(t, policies, 1)
+
+		var rules int
+		require.NoError(t, db.GetContext(ctx, &rules, `SELECT COUNT(*) FROM policy_rules WHERE policy_id = $1`, firstID))
+		assert.Zero(t, rules)
+	})
+}
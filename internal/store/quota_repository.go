@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// QuotaRepository stores policy quota counters in the database, so quotas are
+// shared by all instances and survive restarts
+type QuotaRepository struct {
+	db *DB
+}
+
+// NewQuotaRepository creates a new quota repository
+func NewQuotaRepository(db *DB) *QuotaRepository {
+	return &QuotaRepository{db: db}
+}
+
+// Ensure QuotaRepository implements QuotaRepositoryInterface
+var _ QuotaRepositoryInterface = (*QuotaRepository)(nil)
+
+// Reserve increments the counter at key if it is below limit and reports whether it did.
+// The check and increment are a single statement, so concurrent requests cannot
+// exceed the limit. Expired counters restart from zero.
+func (r *QuotaRepository) Reserve(ctx context.Context, key string, limit int64, ttl time.Duration) (bool, error) {
+	ctx, cancel := r.db.startQuery(ctx, "quotas.reserve")
+	defer cancel()
+
+	if limit <= 0 {
+		return false, nil
+	}
+
+	query := `
+		INSERT INTO policy_quotas (key, count, expires_at)
+		VALUES ($1, 1, $3)
+		ON CONFLICT (key) DO UPDATE SET
+			count = CASE WHEN policy_quotas.expires_at < CURRENT_TIMESTAMP THEN 1 ELSE policy_quotas.count + 1 END,
+			expires_at = CASE WHEN policy_quotas.expires_at < CURRENT_TIMESTAMP THEN EXCLUDED.expires_at ELSE policy_quotas.expires_at END
+		WHERE policy_quotas.count < $2 OR policy_quotas.expires_at < CURRENT_TIMESTAMP
+		RETURNING count
+	`
+
+	var count int64
+	err := r.db.QueryRowContext(ctx, query, key, limit, time.Now().Add(ttl)).Scan(&count)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve quota: %w", err)
+	}
+	return true, nil
+}
+
+// Release decrements the counter at key
+func (r *QuotaRepository) Release(ctx context.Context, key string) error {
+	ctx, cancel := r.db.startQuery(ctx, "quotas.release")
+	defer cancel()
+
+	query := `UPDATE policy_quotas SET count = count - 1 WHERE key = $1 AND count > 0`
+
+	if _, err := r.db.ExecContext(ctx, query, key); err != nil {
+		return fmt.Errorf("failed to release quota: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpiredQuotas removes counters of past windows
+// Returns the number of counters deleted
+func (r *QuotaRepository) DeleteExpiredQuotas(ctx context.Context) (int64, error) {
+	ctx, cancel := r.db.startBulkQuery(ctx, "quotas.delete_expired")
+	defer cancel()
+
+	query := `DELETE FROM policy_quotas WHERE expires_at < CURRENT_TIMESTAMP`
+
+	result, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired quotas: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return deleted, nil
+}
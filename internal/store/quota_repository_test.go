@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotaRepository_Reserve(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewQuotaRepository(db)
+	ctx := context.Background()
+
+	t.Run("reserves up to the limit", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			reserved, err := repo.Reserve(ctx, "quota:claims:0xabc:0", 3, time.Hour)
+			require.NoError(t, err)
+			assert.True(t, reserved)
+		}
+
+		reserved, err := repo.Reserve(ctx, "quota:claims:0xabc:0", 3, time.Hour)
+		require.NoError(t, err)
+		assert.False(t, reserved)
+	})
+
+	t.Run("release returns a reservation", func(t *testing.T) {
+		require.NoError(t, repo.Release(ctx, "quota:claims:0xabc:0"))
+
+		reserved, err := repo.Reserve(ctx, "quota:claims:0xabc:0", 3, time.Hour)
+		require.NoError(t, err)
+		assert.True(t, reserved)
+	})
+
+	t.Run("expired counter restarts", func(t *testing.T) {
+		reserved, err := repo.Reserve(ctx, "quota:claims:0xdef:0", 1, -time.Second)
+		require.NoError(t, err)
+		assert.True(t, reserved)
+
+		reserved, err = repo.Reserve(ctx, "quota:claims:0xdef:0", 1, time.Hour)
+		require.NoError(t, err)
+		assert.True(t, reserved)
+
+		reserved, err = repo.Reserve(ctx, "quota:claims:0xdef:0", 1, time.Hour)
+		require.NoError(t, err)
+		assert.False(t, reserved)
+	})
+
+	t.Run("concurrent reservations respect the limit", func(t *testing.T) {
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		granted := 0
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				reserved, err := repo.Reserve(ctx, "quota:claims:0x123:0", 3, time.Hour)
+				if err == nil && reserved {
+					mu.Lock()
+					granted++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 3, granted)
+	})
+}
+
+func TestQuotaRepository_DeleteExpiredQuotas(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewQuotaRepository(db)
+	ctx := context.Background()
+
+	_, err := repo.Reserve(ctx, "quota:claims:0xabc:0", 1, -time.Second)
+	require.NoError(t, err)
+	_, err = repo.Reserve(ctx, "quota:claims:0xabc:86400", 1, time.Hour)
+	require.NoError(t, err)
+
+	deleted, err := repo.DeleteExpiredQuotas(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
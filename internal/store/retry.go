@@ -0,0 +1,145 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RetryConfig bounds the retries of the startup database connection
+type RetryConfig struct {
+	MaxAttempts  int           // Connection attempts before giving up (at least 1)
+	InitialDelay time.Duration // Delay after the first failed attempt
+	MaxDelay     time.Duration // Cap on the delay between attempts
+}
+
+// backoff returns the delay after the given failed attempt (1-based): exponential
+// growth from InitialDelay capped at MaxDelay, with full jitter so replicas started
+// together do not retry in lockstep
+func (c RetryConfig) backoff(attempt int, jitter func(int64) int64) time.Duration {
+	delay := c.InitialDelay
+	for i := 1; i < attempt && delay < c.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > c.MaxDelay {
+		delay = c.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return time.Duration(jitter(int64(delay)) + 1)
+}
+
+// ConnectWithRetry connects like Connect, retrying failed attempts with exponential
+// backoff and jitter. onRetry, if not nil, is called before each wait with the failed
+// attempt, the delay and the error. Gives up after MaxAttempts or when ctx is done.
+func ConnectWithRetry(ctx context.Context, databaseURL string, poolCfg PoolConfig, retryCfg RetryConfig, onRetry func(attempt int, delay time.Duration, err error)) (*DB, error) {
+	var db *DB
+	err := retry(ctx, retryCfg, rand.Int63n, func(ctx context.Context) error {
+		var err error
+		db, err = Connect(ctx, databaseURL, poolCfg)
+		return err
+	}, onRetry)
+	return db, err
+}
+
+// retry calls op until it succeeds, MaxAttempts is reached or ctx is done
+func retry(ctx context.Context, cfg RetryConfig, jitter func(int64) int64, op func(context.Context) error, onRetry func(int, time.Duration, error)) error {
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := op(ctx)
+		if err == nil {
+			return nil
+		}
+		if attempt >= maxAttempts {
+			return fmt.Errorf("gave up after %d attempts: %w", attempt, err)
+		}
+
+		delay := cfg.backoff(attempt, jitter)
+		if onRetry != nil {
+			onRetry(attempt, delay, err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("gave up after %d attempts: %w", attempt, err)
+		case <-timer.C:
+		}
+	}
+}
+
+// HealthMonitor periodically checks the database so readiness reflects its
+// availability without querying it on every probe. database/sql replaces broken
+// connections on use, so each successful check after an outage is a reconnection.
+type HealthMonitor struct {
+	db        *DB
+	interval  time.Duration
+	available atomic.Bool
+	onChange  func(available bool, err error)
+	check     func(context.Context) error
+	stopOnce  sync.Once
+	stop      chan struct{}
+}
+
+// NewHealthMonitor creates a monitor checking db every interval. The database is
+// considered available until a check fails. onChange, if not nil, is called when
+// availability changes, with the failed check's error when it becomes unavailable.
+func NewHealthMonitor(db *DB, interval time.Duration, onChange func(available bool, err error)) *HealthMonitor {
+	m := &HealthMonitor{
+		db:       db,
+		interval: interval,
+		onChange: onChange,
+		stop:     make(chan struct{}),
+	}
+	m.check = func(ctx context.Context) error {
+		return CheckDatabaseHealth(ctx, m.db.DB.DB)
+	}
+	m.available.Store(true)
+	return m
+}
+
+// Available reports whether the last check succeeded
+func (m *HealthMonitor) Available() bool {
+	return m.available.Load()
+}
+
+// Start runs the checks in the background until Stop is called
+func (m *HealthMonitor) Start() {
+	go func() {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+				m.runCheck()
+			}
+		}
+	}()
+}
+
+// Stop stops the checks
+func (m *HealthMonitor) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+}
+
+// runCheck checks the database once and records the result
+func (m *HealthMonitor) runCheck() {
+	err := m.check(context.Background())
+	available := err == nil
+	if m.available.Swap(available) != available && m.onChange != nil {
+		m.onChange(available, err)
+	}
+}
@@ -0,0 +1,106 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// noJitter returns the largest delay, making backoff deterministic
+func noJitter(n int64) int64 {
+	return n - 1
+}
+
+func TestRetryConfig_Backoff(t *testing.T) {
+	cfg := RetryConfig{InitialDelay: time.Second, MaxDelay: 5 * time.Second}
+
+	assert.Equal(t, time.Second, cfg.backoff(1, noJitter))
+	assert.Equal(t, 2*time.Second, cfg.backoff(2, noJitter))
+	assert.Equal(t, 4*time.Second, cfg.backoff(3, noJitter))
+	assert.Equal(t, 5*time.Second, cfg.backoff(4, noJitter))
+	assert.Equal(t, 5*time.Second, cfg.backoff(50, noJitter))
+
+	// Jitter spreads the delay over (0, delay]
+	assert.Equal(t, time.Duration(1), cfg.backoff(3, func(int64) int64 { return 0 }))
+}
+
+func TestRetry_SucceedsAfterFailures(t *testing.T) {
+	cfg := RetryConfig{MaxAttempts: 5, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+	calls := 0
+	var retried []int
+	err := retry(context.Background(), cfg, noJitter, func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("connection refused")
+		}
+		return nil
+	}, func(attempt int, delay time.Duration, err error) {
+		retried = append(retried, attempt)
+		assert.Equal(t, time.Millisecond, delay)
+		assert.EqualError(t, err, "connection refused")
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, []int{1, 2}, retried)
+}
+
+func TestRetry_GivesUpAfterMaxAttempts(t *testing.T) {
+	cfg := RetryConfig{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	refused := errors.New("connection refused")
+
+	calls := 0
+	err := retry(context.Background(), cfg, noJitter, func(context.Context) error {
+		calls++
+		return refused
+	}, nil)
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, refused)
+	assert.Contains(t, err.Error(), "gave up after 3 attempts")
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetry_StopsWhenContextDone(t *testing.T) {
+	cfg := RetryConfig{MaxAttempts: 10, InitialDelay: time.Hour, MaxDelay: time.Hour}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	calls := 0
+	err := retry(ctx, cfg, noJitter, func(context.Context) error {
+		calls++
+		return errors.New("connection refused")
+	}, func(int, time.Duration, error) {
+		cancel()
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestHealthMonitor_TracksAvailability(t *testing.T) {
+	var changes []bool
+	m := NewHealthMonitor(nil, time.Hour, func(available bool, err error) {
+		changes = append(changes, available)
+	})
+	checkErr := errors.New("connection reset")
+	m.check = func(context.Context) error { return checkErr }
+
+	assert.True(t, m.Available())
+
+	m.runCheck()
+	assert.False(t, m.Available())
+	m.runCheck()
+	assert.False(t, m.Available())
+
+	checkErr = nil
+	m.runCheck()
+	assert.True(t, m.Available())
+
+	// Only changes are reported
+	assert.Equal(t, []bool{false, true}, changes)
+}
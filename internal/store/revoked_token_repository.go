@@ -0,0 +1,72 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RevokedTokenRepository stores the IDs of revoked access tokens in the
+// database, so a logout is honoured by every instance and survives restarts
+type RevokedTokenRepository struct {
+	db *DB
+}
+
+// NewRevokedTokenRepository creates a new revoked token repository
+func NewRevokedTokenRepository(db *DB) *RevokedTokenRepository {
+	return &RevokedTokenRepository{db: db}
+}
+
+// Ensure RevokedTokenRepository implements RevokedTokenRepositoryInterface
+var _ RevokedTokenRepositoryInterface = (*RevokedTokenRepository)(nil)
+
+// RevokeToken records the token ID until expiresAt. Revoking a token twice is a no-op.
+func (r *RevokedTokenRepository) RevokeToken(ctx context.Context, id string, expiresAt time.Time) error {
+	ctx, cancel := r.db.startQuery(ctx, "revoked_tokens.revoke")
+	defer cancel()
+
+	query := `
+		INSERT INTO revoked_tokens (jti, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (jti) DO NOTHING
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, expiresAt); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	return nil
+}
+
+// IsTokenRevoked reports whether the token ID is revoked and not yet expired
+func (r *RevokedTokenRepository) IsTokenRevoked(ctx context.Context, id string) (bool, error) {
+	ctx, cancel := r.db.startQuery(ctx, "revoked_tokens.is_revoked")
+	defer cancel()
+
+	query := `SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1 AND expires_at > CURRENT_TIMESTAMP)`
+
+	var revoked bool
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(&revoked); err != nil {
+		return false, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	return revoked, nil
+}
+
+// DeleteExpiredRevocations removes entries of tokens that have expired anyway
+// Returns the number of entries deleted
+func (r *RevokedTokenRepository) DeleteExpiredRevocations(ctx context.Context) (int64, error) {
+	ctx, cancel := r.db.startBulkQuery(ctx, "revoked_tokens.delete_expired")
+	defer cancel()
+
+	query := `DELETE FROM revoked_tokens WHERE expires_at <= CURRENT_TIMESTAMP`
+
+	result, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired revocations: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,37 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevokedTokenRepository(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewRevokedTokenRepository(db)
+	ctx := context.Background()
+
+	revoked, err := repo.IsTokenRevoked(ctx, "token-1")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	require.NoError(t, repo.RevokeToken(ctx, "token-1", time.Now().Add(time.Hour)))
+	require.NoError(t, repo.RevokeToken(ctx, "token-1", time.Now().Add(time.Hour)), "revoking twice is a no-op")
+	revoked, err = repo.IsTokenRevoked(ctx, "token-1")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	// Entries of expired tokens no longer count and are deleted
+	require.NoError(t, repo.RevokeToken(ctx, "token-2", time.Now().Add(-time.Minute)))
+	revoked, err = repo.IsTokenRevoked(ctx, "token-2")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	deleted, err := repo.DeleteExpiredRevocations(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
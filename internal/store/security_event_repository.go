@@ -0,0 +1,133 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SecurityEvent is a security-relevant event of a wallet, such as a sign-in, a
+// key change or a denied request
+type SecurityEvent struct {
+	ID        int64     `db:"id"`
+	Subject   string    `db:"subject"` // Lowercase wallet address
+	Action    string    `db:"action"`
+	Result    string    `db:"result"`
+	IPAddress string    `db:"ip_address"`
+	UserAgent string    `db:"user_agent"`
+	Method    string    `db:"method"`
+	Endpoint  string    `db:"endpoint"`
+	KeyID     *int64    `db:"key_id"` // nil unless the event concerns an API key
+	KeyName   string    `db:"key_name"`
+	Error     string    `db:"error"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// SecurityEventRepository handles database operations for security events
+type SecurityEventRepository struct {
+	db *DB
+}
+
+// NewSecurityEventRepository creates a new security event repository
+func NewSecurityEventRepository(db *DB) *SecurityEventRepository {
+	return &SecurityEventRepository{db: db}
+}
+
+// Ensure SecurityEventRepository implements SecurityEventRepositoryInterface
+var _ SecurityEventRepositoryInterface = (*SecurityEventRepository)(nil)
+
+// CreateSecurityEvent records an event. ID is set on the given event, and so is
+// CreatedAt unless already set.
+func (r *SecurityEventRepository) CreateSecurityEvent(ctx context.Context, event *SecurityEvent) error {
+	ctx, cancel := r.db.startQuery(ctx, "security_events.create")
+	defer cancel()
+
+	if event == nil {
+		return fmt.Errorf("event cannot be nil")
+	}
+	if event.Subject == "" {
+		return fmt.Errorf("subject is required")
+	}
+	if event.Action == "" {
+		return fmt.Errorf("action is required")
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO security_events (subject, action, result, ip_address, user_agent, method, endpoint, key_id, key_name, error, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query, strings.ToLower(event.Subject), event.Action, event.Result,
+		event.IPAddress, event.UserAgent, event.Method, event.Endpoint, event.KeyID, event.KeyName, event.Error, event.CreatedAt).
+		Scan(&event.ID)
+	if err != nil {
+		return fmt.Errorf("failed to record security event: %w", err)
+	}
+
+	return nil
+}
+
+// ListSecurityEvents returns the most recent events of a subject, newest first.
+// A positive beforeID only returns events older than that event, for paging.
+func (r *SecurityEventRepository) ListSecurityEvents(ctx context.Context, subject string, beforeID int64, limit int) ([]SecurityEvent, error) {
+	ctx, cancel := r.db.startQuery(ctx, "security_events.list")
+	defer cancel()
+
+	query := `
+		SELECT id, subject, action, result, ip_address, user_agent, method, endpoint, key_id, key_name, error, created_at
+		FROM security_events
+		WHERE subject = $1 AND ($2 <= 0 OR id < $2)
+		ORDER BY id DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, strings.ToLower(subject), beforeID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query security events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []SecurityEvent{}
+	for rows.Next() {
+		var event SecurityEvent
+		var keyID sql.NullInt64
+		if err := rows.Scan(&event.ID, &event.Subject, &event.Action, &event.Result, &event.IPAddress, &event.UserAgent,
+			&event.Method, &event.Endpoint, &keyID, &event.KeyName, &event.Error, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan security event: %w", err)
+		}
+		if keyID.Valid {
+			event.KeyID = &keyID.Int64
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return events, nil
+}
+
+// DeleteSecurityEventsBefore removes events recorded before cutoff
+// Returns the number of events deleted
+func (r *SecurityEventRepository) DeleteSecurityEventsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	ctx, cancel := r.db.startBulkQuery(ctx, "security_events.delete_before")
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM security_events WHERE created_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete security events: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return deleted, nil
+}
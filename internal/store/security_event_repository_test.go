@@ -0,0 +1,72 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecurityEventRepository_CreateAndList(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSecurityEventRepository(db)
+	ctx := context.Background()
+
+	subject := "0x742d35cc6634c0532925a3b844bc9e7595f0beb0"
+	other := "0x742d35cc6634c0532925a3b844bc9e7595f0beb1"
+
+	t.Run("validates events", func(t *testing.T) {
+		assert.Error(t, repo.CreateSecurityEvent(ctx, nil))
+		assert.Error(t, repo.CreateSecurityEvent(ctx, &SecurityEvent{Action: "auth_success"}))
+		assert.Error(t, repo.CreateSecurityEvent(ctx, &SecurityEvent{Subject: subject}))
+	})
+
+	keyID := int64(7)
+	old := &SecurityEvent{Subject: subject, Action: "auth_success", Result: "success", CreatedAt: time.Now().Add(-48 * time.Hour)}
+	require.NoError(t, repo.CreateSecurityEvent(ctx, old))
+	require.NoError(t, repo.CreateSecurityEvent(ctx, &SecurityEvent{
+		Subject: "0x742D35CC6634C0532925A3B844BC9E7595F0BEB0", Action: "api_key_created", Result: "success",
+		IPAddress: "203.0.113.7", KeyID: &keyID, KeyName: "ci",
+	}))
+	require.NoError(t, repo.CreateSecurityEvent(ctx, &SecurityEvent{Subject: subject, Action: "authz_denied", Result: "denied", Method: "GET", Endpoint: "/api/data"}))
+	require.NoError(t, repo.CreateSecurityEvent(ctx, &SecurityEvent{Subject: other, Action: "auth_success", Result: "success"}))
+
+	t.Run("lists the subject's events newest first", func(t *testing.T) {
+		events, err := repo.ListSecurityEvents(ctx, subject, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, events, 3)
+
+		assert.Equal(t, "authz_denied", events[0].Action)
+		assert.Equal(t, "/api/data", events[0].Endpoint)
+		assert.Nil(t, events[0].KeyID)
+		assert.Equal(t, "api_key_created", events[1].Action)
+		require.NotNil(t, events[1].KeyID)
+		assert.Equal(t, keyID, *events[1].KeyID)
+		assert.Equal(t, subject, events[1].Subject)
+		assert.Equal(t, old.ID, events[2].ID)
+	})
+
+	t.Run("pages with beforeID", func(t *testing.T) {
+		first, err := repo.ListSecurityEvents(ctx, subject, 0, 2)
+		require.NoError(t, err)
+		require.Len(t, first, 2)
+
+		rest, err := repo.ListSecurityEvents(ctx, subject, first[1].ID, 2)
+		require.NoError(t, err)
+		require.Len(t, rest, 1)
+		assert.Equal(t, old.ID, rest[0].ID)
+	})
+
+	t.Run("deletes events before the cutoff", func(t *testing.T) {
+		deleted, err := repo.DeleteSecurityEventsBefore(ctx, time.Now().Add(-24*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		events, err := repo.ListSecurityEvents(ctx, subject, 0, 10)
+		require.NoError(t, err)
+		assert.Len(t, events, 2)
+	})
+}
@@ -0,0 +1,179 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Session is a refresh token of a signed-in user. Every rotation replaces the
+// token with a new session in the same family.
+type Session struct {
+	ID        int64      `db:"id"`
+	UserID    int64      `db:"user_id"`
+	Address   string     `db:"address"`
+	TokenHash string     `db:"token_hash"`
+	FamilyID  string     `db:"family_id"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	RotatedAt *time.Time `db:"rotated_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+// SessionRepository handles database operations for refresh token sessions
+type SessionRepository struct {
+	db *DB
+}
+
+// NewSessionRepository creates a new session repository
+func NewSessionRepository(db *DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// Ensure SessionRepository implements SessionRepositoryInterface
+var _ SessionRepositoryInterface = (*SessionRepository)(nil)
+
+// CreateSession starts a session family for a user and returns its refresh
+// token. The raw token is only returned here; just its hash is stored.
+func (r *SessionRepository) CreateSession(ctx context.Context, userID int64, address string, ttl time.Duration) (string, *Session, error) {
+	ctx, cancel := r.db.startQuery(ctx, "sessions.create")
+	defer cancel()
+
+	if userID == 0 {
+		return "", nil, fmt.Errorf("user_id is required")
+	}
+
+	familyBytes := make([]byte, 16)
+	if _, err := rand.Read(familyBytes); err != nil {
+		return "", nil, fmt.Errorf("failed to generate session family: %w", err)
+	}
+
+	return r.insertSession(ctx, r.db, userID, address, hex.EncodeToString(familyBytes), ttl)
+}
+
+// RotateSession exchanges a refresh token for a new one in the same family.
+// Unknown tokens return a NotFoundError and expired ones an ExpiredError.
+// Presenting a token that was already rotated or revoked returns a
+// RevokedError and revokes the whole family, since the token has leaked.
+func (r *SessionRepository) RotateSession(ctx context.Context, rawToken string, ttl time.Duration) (string, *Session, error) {
+	ctx, cancel := r.db.startQuery(ctx, "sessions.rotate")
+	defer cancel()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var current Session
+	err = tx.QueryRowxContext(ctx, `
+		SELECT id, user_id, address, token_hash, family_id, expires_at, rotated_at, revoked_at, created_at
+		FROM sessions
+		WHERE token_hash = $1
+		FOR UPDATE
+	`, HashAPIKey(rawToken)).StructScan(&current)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil, &NotFoundError{Resource: "session", ID: "refresh token"}
+		}
+		return "", nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	if current.RotatedAt != nil || current.RevokedAt != nil {
+		if err := revokeFamily(ctx, tx, current.FamilyID); err != nil {
+			return "", nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return "", nil, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return "", nil, &RevokedError{Resource: "session", ID: current.ID}
+	}
+	if !current.ExpiresAt.After(time.Now()) {
+		return "", nil, &ExpiredError{Resource: "session", ID: current.ID}
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE sessions SET rotated_at = CURRENT_TIMESTAMP WHERE id = $1`, current.ID); err != nil {
+		return "", nil, fmt.Errorf("failed to rotate session: %w", err)
+	}
+
+	rawNext, next, err := r.insertSession(ctx, tx, current.UserID, current.Address, current.FamilyID, ttl)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return rawNext, next, nil
+}
+
+// RevokeSession revokes the family of a refresh token, ending the session on
+// every token issued from it. Unknown tokens return a NotFoundError.
+func (r *SessionRepository) RevokeSession(ctx context.Context, rawToken string) error {
+	ctx, cancel := r.db.startQuery(ctx, "sessions.revoke")
+	defer cancel()
+
+	var familyID string
+	err := r.db.QueryRowContext(ctx, `SELECT family_id FROM sessions WHERE token_hash = $1`, HashAPIKey(rawToken)).Scan(&familyID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return &NotFoundError{Resource: "session", ID: "refresh token"}
+		}
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return revokeFamily(ctx, r.db, familyID)
+}
+
+// rowQueryer runs single-row queries on a DB or in a transaction
+type rowQueryer interface {
+	QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row
+}
+
+// insertSession stores a new refresh token in a session family
+func (r *SessionRepository) insertSession(ctx context.Context, exec rowQueryer, userID int64, address, familyID string, ttl time.Duration) (string, *Session, error) {
+	rawToken, err := GenerateAPIKey()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	session := Session{
+		UserID:    userID,
+		Address:   address,
+		TokenHash: HashAPIKey(rawToken),
+		FamilyID:  familyID,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+
+	query := `
+		INSERT INTO sessions (user_id, address, token_hash, family_id, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+		RETURNING id, created_at
+	`
+
+	err = exec.QueryRowxContext(ctx, query, session.UserID, session.Address, session.TokenHash, session.FamilyID, session.ExpiresAt).
+		Scan(&session.ID, &session.CreatedAt)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return rawToken, &session, nil
+}
+
+// revokeFamily revokes every live token of a session family
+func revokeFamily(ctx context.Context, exec execer, familyID string) error {
+	_, err := exec.ExecContext(ctx, `
+		UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP
+		WHERE family_id = $1 AND revoked_at IS NULL
+	`, familyID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionRepository_Rotation(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+
+	address := "0x742d35cc6634c0532925a3b844bc9e7595f0beb0"
+	user := createTestUser(t, db, address)
+
+	first, session, err := repo.CreateSession(ctx, user.ID, address, time.Hour)
+	require.NoError(t, err)
+	assert.Len(t, first, 64)
+	assert.Equal(t, HashAPIKey(first), session.TokenHash)
+	assert.NotEmpty(t, session.FamilyID)
+
+	t.Run("rotation issues a new token in the same family", func(t *testing.T) {
+		second, next, err := repo.RotateSession(ctx, first, time.Hour)
+		require.NoError(t, err)
+		assert.NotEqual(t, first, second)
+		assert.Equal(t, session.FamilyID, next.FamilyID)
+		assert.Equal(t, address, next.Address)
+		assert.Equal(t, user.ID, next.UserID)
+
+		t.Run("reusing a rotated token revokes the family", func(t *testing.T) {
+			_, _, err := repo.RotateSession(ctx, first, time.Hour)
+			assert.True(t, errors.Is(err, ErrRevoked))
+
+			_, _, err = repo.RotateSession(ctx, second, time.Hour)
+			assert.True(t, errors.Is(err, ErrRevoked))
+		})
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		_, _, err := repo.RotateSession(ctx, "not-a-token", time.Hour)
+		assert.True(t, errors.Is(err, ErrNotFound))
+		assert.True(t, errors.Is(repo.RevokeSession(ctx, "not-a-token"), ErrNotFound))
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		expired, _, err := repo.CreateSession(ctx, user.ID, address, -time.Minute)
+		require.NoError(t, err)
+
+		_, _, err = repo.RotateSession(ctx, expired, time.Hour)
+		assert.True(t, errors.Is(err, ErrExpired))
+	})
+
+	t.Run("logout revokes the session", func(t *testing.T) {
+		token, _, err := repo.CreateSession(ctx, user.ID, address, time.Hour)
+		require.NoError(t, err)
+		require.NoError(t, repo.RevokeSession(ctx, token))
+
+		_, _, err = repo.RotateSession(ctx, token, time.Hour)
+		assert.True(t, errors.Is(err, ErrRevoked))
+	})
+}
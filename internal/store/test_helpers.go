@@ -62,11 +62,23 @@ func cleanupTestDB(t *testing.T, db *DB) {
 
 	// Truncate tables in reverse dependency order
 	tables := []string{
+		"logins",
+		"sessions",
+		"user_wallets",
+		"webauthn_credentials",
+		"change_history",
+		"revoked_tokens",
 		"allowlist_entries",
 		"allowlists",
 		"api_keys",
 		"nonces",
 		"users",
+		"policy_quotas",
+		"policy_rules",
+		"policies",
+		"blocked_addresses",
+		"key_deprecations",
+		"security_events",
 	}
 
 	for _, table := range tables {
@@ -11,12 +11,63 @@ import (
 	"github.com/yourusername/gatekeeper/internal/common"
 )
 
-// User represents a user in the database
+// Account types stored in users.account_type
+const (
+	AccountTypeWallet  = "wallet"
+	AccountTypeService = "service"
+)
+
+// User represents a user in the database.
+// Wallet users are identified by their Ethereum address; service accounts
+// have no address and are identified by name and owning user instead.
 type User struct {
-	ID        int64     `db:"id"`
-	Address   string    `db:"address"`
-	CreatedAt time.Time `db:"created_at"`
-	UpdatedAt time.Time `db:"updated_at"`
+	ID          int64      `db:"id"`
+	Address     string     `db:"address"`
+	AccountType string     `db:"account_type"`
+	Name        string     `db:"name"`
+	OwnerID     *int64     `db:"owner_id"`
+	Role        string     `db:"role"`          // Administrative role; empty for regular users
+	LastLoginAt *time.Time `db:"last_login_at"` // nil until the first SIWE sign-in
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at"`
+}
+
+// IsServiceAccount reports whether the user is a service account
+func (u *User) IsServiceAccount() bool {
+	return u.AccountType == AccountTypeService
+}
+
+// Identity returns a stable identifier for the user suitable for claims,
+// rate limiting and audit logs: the wallet address, or "service_account:<id>"
+func (u *User) Identity() string {
+	if u.IsServiceAccount() {
+		return fmt.Sprintf("service_account:%d", u.ID)
+	}
+	return u.Address
+}
+
+// userColumns is the column list selected for every user query.
+// Nullable columns are coalesced so they scan into plain strings.
+const userColumns = `id, COALESCE(address, ''), account_type, COALESCE(name, ''), owner_id, COALESCE(role, ''), last_login_at, created_at, updated_at`
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanUser scans a row selected with userColumns into a User
+func scanUser(row rowScanner, user *User) error {
+	return row.Scan(
+		&user.ID,
+		&user.Address,
+		&user.AccountType,
+		&user.Name,
+		&user.OwnerID,
+		&user.Role,
+		&user.LastLoginAt,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
 }
 
 // UserRepository handles database operations for users
@@ -32,6 +83,12 @@ func NewUserRepository(db *DB) *UserRepository {
 // Ensure UserRepository implements UserRepositoryInterface
 var _ UserRepositoryInterface = (*UserRepository)(nil)
 
+// Ensure UserRepository implements ServiceAccountRepositoryInterface
+var _ ServiceAccountRepositoryInterface = (*UserRepository)(nil)
+
+// Ensure UserRepository implements RoleRepositoryInterface
+var _ RoleRepositoryInterface = (*UserRepository)(nil)
+
 // validateAddress validates and normalizes an Ethereum address
 // This is a wrapper around common.NormalizeAddress that converts
 // common.AddressError to InvalidAddressError for backward compatibility
@@ -78,6 +135,9 @@ func (r *UserRepository) GetOrCreateUserByAddress(ctx context.Context, address s
 
 // GetUserByAddress retrieves a user by their Ethereum address
 func (r *UserRepository) GetUserByAddress(ctx context.Context, address string) (*User, error) {
+	ctx, cancel := r.db.startQuery(ctx, "users.get_by_address")
+	defer cancel()
+
 	// Validate and normalize address
 	normalizedAddress, err := validateAddress(address)
 	if err != nil {
@@ -85,18 +145,13 @@ func (r *UserRepository) GetUserByAddress(ctx context.Context, address string) (
 	}
 
 	query := `
-		SELECT id, address, created_at, updated_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE address = $1
 	`
 
 	user := &User{}
-	err = r.db.QueryRowContext(ctx, query, normalizedAddress).Scan(
-		&user.ID,
-		&user.Address,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	err = scanUser(r.db.QueryRowContext(ctx, query, normalizedAddress), user)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, &NotFoundError{
@@ -112,19 +167,17 @@ func (r *UserRepository) GetUserByAddress(ctx context.Context, address string) (
 
 // GetUserByID retrieves a user by their ID
 func (r *UserRepository) GetUserByID(ctx context.Context, id int64) (*User, error) {
+	ctx, cancel := r.db.startQuery(ctx, "users.get_by_id")
+	defer cancel()
+
 	query := `
-		SELECT id, address, created_at, updated_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE id = $1
 	`
 
 	user := &User{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&user.ID,
-		&user.Address,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	err := scanUser(r.db.QueryRowContext(ctx, query, id), user)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, &NotFoundError{
@@ -140,6 +193,9 @@ func (r *UserRepository) GetUserByID(ctx context.Context, id int64) (*User, erro
 
 // CreateUser creates a new user with the given address
 func (r *UserRepository) CreateUser(ctx context.Context, address string) (*User, error) {
+	ctx, cancel := r.db.startQuery(ctx, "users.create")
+	defer cancel()
+
 	// Validate and normalize address
 	normalizedAddress, err := validateAddress(address)
 	if err != nil {
@@ -147,18 +203,13 @@ func (r *UserRepository) CreateUser(ctx context.Context, address string) (*User,
 	}
 
 	query := `
-		INSERT INTO users (address, created_at, updated_at)
-		VALUES ($1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-		RETURNING id, address, created_at, updated_at
+		INSERT INTO users (address, account_type, created_at, updated_at)
+		VALUES ($1, '` + AccountTypeWallet + `', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		RETURNING ` + userColumns + `
 	`
 
 	user := &User{}
-	err = r.db.QueryRowContext(ctx, query, normalizedAddress).Scan(
-		&user.ID,
-		&user.Address,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	err = scanUser(r.db.QueryRowContext(ctx, query, normalizedAddress), user)
 	if err != nil {
 		// Check for duplicate key violation
 		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
@@ -176,6 +227,9 @@ func (r *UserRepository) CreateUser(ctx context.Context, address string) (*User,
 
 // UpdateUser updates an existing user
 func (r *UserRepository) UpdateUser(ctx context.Context, user *User) error {
+	ctx, cancel := r.db.startQuery(ctx, "users.update")
+	defer cancel()
+
 	if user == nil {
 		return fmt.Errorf("user cannot be nil")
 	}
@@ -216,8 +270,38 @@ func (r *UserRepository) UpdateUser(ctx context.Context, user *User) error {
 	return nil
 }
 
+// RecordLogin sets the user's last_login_at to the current time
+func (r *UserRepository) RecordLogin(ctx context.Context, id int64) error {
+	ctx, cancel := r.db.startQuery(ctx, "users.record_login")
+	defer cancel()
+
+	query := `UPDATE users SET last_login_at = CURRENT_TIMESTAMP WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to record login: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return &NotFoundError{
+			Resource: "user",
+			ID:       id,
+		}
+	}
+
+	return nil
+}
+
 // DeleteUser deletes a user by ID
 func (r *UserRepository) DeleteUser(ctx context.Context, id int64) error {
+	ctx, cancel := r.db.startQuery(ctx, "users.delete")
+	defer cancel()
+
 	query := `DELETE FROM users WHERE id = $1`
 
 	result, err := r.db.ExecContext(ctx, query, id)
@@ -239,3 +323,188 @@ func (r *UserRepository) DeleteUser(ctx context.Context, id int64) error {
 
 	return nil
 }
+
+// CreateServiceAccount creates a service account owned by the given user.
+// Service accounts have no wallet address and can only hold API keys.
+func (r *UserRepository) CreateServiceAccount(ctx context.Context, name string, ownerID int64) (*User, error) {
+	ctx, cancel := r.db.startQuery(ctx, "users.create_service_account")
+	defer cancel()
+
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if ownerID == 0 {
+		return nil, fmt.Errorf("owner_id is required")
+	}
+
+	query := `
+		INSERT INTO users (address, account_type, name, owner_id, created_at, updated_at)
+		VALUES (NULL, '` + AccountTypeService + `', $1, $2, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		RETURNING ` + userColumns + `
+	`
+
+	user := &User{}
+	err := scanUser(r.db.QueryRowContext(ctx, query, name, ownerID), user)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code {
+			case "23503":
+				return nil, &NotFoundError{
+					Resource: "user",
+					ID:       ownerID,
+				}
+			case "23505":
+				return nil, &DuplicateError{
+					Resource: "service_account",
+					Field:    "name",
+					Value:    name,
+				}
+			}
+		}
+		return nil, fmt.Errorf("failed to create service account: %w", err)
+	}
+
+	return user, nil
+}
+
+// ListServiceAccounts returns all service accounts owned by the given user
+func (r *UserRepository) ListServiceAccounts(ctx context.Context, ownerID int64) ([]User, error) {
+	ctx, cancel := r.db.startQuery(ctx, "users.list_service_accounts")
+	defer cancel()
+
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE account_type = '` + AccountTypeService + `' AND owner_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query service accounts: %w", err)
+	}
+	defer rows.Close()
+
+	accounts := []User{}
+	for rows.Next() {
+		var user User
+		if err := scanUser(rows, &user); err != nil {
+			return nil, fmt.Errorf("failed to scan service account: %w", err)
+		}
+		accounts = append(accounts, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return accounts, nil
+}
+
+// SetUserRole sets the administrative role of a wallet user; an empty role
+// removes it. Service accounts cannot hold roles.
+func (r *UserRepository) SetUserRole(ctx context.Context, id int64, role string) (*User, error) {
+	ctx, cancel := r.db.startQuery(ctx, "users.set_role")
+	defer cancel()
+
+	query := `
+		UPDATE users
+		SET role = NULLIF($2, ''), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND account_type = '` + AccountTypeWallet + `'
+		RETURNING ` + userColumns + `
+	`
+
+	user := &User{}
+	err := scanUser(r.db.QueryRowContext(ctx, query, id, role), user)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &NotFoundError{
+				Resource: "user",
+				ID:       id,
+			}
+		}
+		// Check for violation of the role check constraint
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23514" {
+			return nil, fmt.Errorf("%w: unknown role %q", ErrInvalidInput, role)
+		}
+		return nil, fmt.Errorf("failed to set user role: %w", err)
+	}
+
+	return user, nil
+}
+
+// ListUsersWithRoles returns every user holding an administrative role, by address
+func (r *UserRepository) ListUsersWithRoles(ctx context.Context) ([]User, error) {
+	ctx, cancel := r.db.startQuery(ctx, "users.list_with_roles")
+	defer cancel()
+
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE role IS NOT NULL
+		ORDER BY address
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users with roles: %w", err)
+	}
+	defer rows.Close()
+
+	users := []User{}
+	for rows.Next() {
+		var user User
+		if err := scanUser(rows, &user); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return users, nil
+}
+
+// CountUsersWithRole returns how many users hold the given role
+func (r *UserRepository) CountUsersWithRole(ctx context.Context, role string) (int, error) {
+	ctx, cancel := r.db.startQuery(ctx, "users.count_with_role")
+	defer cancel()
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE role = $1`, role).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count users with role: %w", err)
+	}
+	return count, nil
+}
+
+// BootstrapAdmin makes address an admin, creating its user if needed, unless some
+// user already is an admin. Reports whether the address was promoted.
+func (r *UserRepository) BootstrapAdmin(ctx context.Context, address string) (bool, error) {
+	ctx, cancel := r.db.startQuery(ctx, "users.bootstrap_admin")
+	defer cancel()
+
+	normalizedAddress, err := validateAddress(address)
+	if err != nil {
+		return false, err
+	}
+
+	query := `
+		INSERT INTO users (address, role, created_at, updated_at)
+		SELECT $1, 'admin', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+		WHERE NOT EXISTS (SELECT 1 FROM users WHERE role = 'admin')
+		ON CONFLICT (address) DO UPDATE SET role = 'admin', updated_at = CURRENT_TIMESTAMP
+	`
+
+	result, err := r.db.ExecContext(ctx, query, normalizedAddress)
+	if err != nil {
+		return false, fmt.Errorf("failed to bootstrap admin: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -227,6 +228,31 @@ func TestUserRepository_UpdateUser(t *testing.T) {
 	})
 }
 
+func TestUserRepository_RecordLogin(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	t.Run("sets last login time", func(t *testing.T) {
+		user := createTestUser(t, db, "0x742d35cc6634c0532925a3b844bc9e7595f0beb0")
+		assert.Nil(t, user.LastLoginAt)
+
+		require.NoError(t, repo.RecordLogin(ctx, user.ID))
+
+		updated, err := repo.GetUserByID(ctx, user.ID)
+		require.NoError(t, err)
+		require.NotNil(t, updated.LastLoginAt)
+		assert.WithinDuration(t, time.Now(), *updated.LastLoginAt, time.Minute)
+	})
+
+	t.Run("returns not found for missing user", func(t *testing.T) {
+		err := repo.RecordLogin(ctx, 999999)
+		var notFoundErr *NotFoundError
+		assert.True(t, errors.As(err, &notFoundErr))
+	})
+}
+
 func TestUserRepository_DeleteUser(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -329,3 +355,110 @@ func TestValidateAddress(t *testing.T) {
 		})
 	}
 }
+
+func TestUserRepository_ServiceAccounts(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	owner, err := repo.CreateUser(ctx, "0x742d35cc6634c0532925a3b844bc9e7595f0beb0")
+	require.NoError(t, err)
+	assert.Equal(t, AccountTypeWallet, owner.AccountType)
+	assert.False(t, owner.IsServiceAccount())
+
+	t.Run("creates service account without address", func(t *testing.T) {
+		account, err := repo.CreateServiceAccount(ctx, "nightly-sync", owner.ID)
+
+		require.NoError(t, err)
+		assert.NotZero(t, account.ID)
+		assert.Empty(t, account.Address)
+		assert.Equal(t, "nightly-sync", account.Name)
+		assert.True(t, account.IsServiceAccount())
+		require.NotNil(t, account.OwnerID)
+		assert.Equal(t, owner.ID, *account.OwnerID)
+		assert.Equal(t, fmt.Sprintf("service_account:%d", account.ID), account.Identity())
+
+		fetched, err := repo.GetUserByID(ctx, account.ID)
+		require.NoError(t, err)
+		assert.Equal(t, account.Name, fetched.Name)
+	})
+
+	t.Run("rejects duplicate name for same owner", func(t *testing.T) {
+		_, err := repo.CreateServiceAccount(ctx, "dup-job", owner.ID)
+		require.NoError(t, err)
+
+		_, err = repo.CreateServiceAccount(ctx, "dup-job", owner.ID)
+		var dupErr *DuplicateError
+		assert.True(t, errors.As(err, &dupErr))
+	})
+
+	t.Run("lists service accounts by owner", func(t *testing.T) {
+		accounts, err := repo.ListServiceAccounts(ctx, owner.ID)
+		require.NoError(t, err)
+		assert.Len(t, accounts, 2)
+
+		other, err := repo.ListServiceAccounts(ctx, owner.ID+1000)
+		require.NoError(t, err)
+		assert.Empty(t, other)
+	})
+
+	t.Run("can hold API keys", func(t *testing.T) {
+		account, err := repo.CreateServiceAccount(ctx, "key-holder", owner.ID)
+		require.NoError(t, err)
+
+		rawKey, _ := createTestAPIKey(t, db, account.ID, "job key", []string{"read"})
+		key, err := NewAPIKeyRepository(db).ValidateAPIKey(ctx, rawKey)
+		require.NoError(t, err)
+		assert.Equal(t, account.ID, key.UserID)
+	})
+}
+
+func TestUserRepository_Roles(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	promoted, err := repo.BootstrapAdmin(ctx, "0x742D35CC6634C0532925A3B844BC9E7595F0BEB0")
+	require.NoError(t, err)
+	assert.True(t, promoted)
+
+	admin, err := repo.GetUserByAddress(ctx, "0x742d35cc6634c0532925a3b844bc9e7595f0beb0")
+	require.NoError(t, err)
+	assert.Equal(t, "admin", admin.Role)
+
+	// Once an admin exists, bootstrapping is a no-op
+	promoted, err = repo.BootstrapAdmin(ctx, "0x8ba1f109551bd432803012645ac136ddd64dba72")
+	require.NoError(t, err)
+	assert.False(t, promoted)
+
+	viewer, err := repo.CreateUser(ctx, "0x8ba1f109551bd432803012645ac136ddd64dba72")
+	require.NoError(t, err)
+	assert.Empty(t, viewer.Role)
+
+	viewer, err = repo.SetUserRole(ctx, viewer.ID, "viewer")
+	require.NoError(t, err)
+	assert.Equal(t, "viewer", viewer.Role)
+
+	_, err = repo.SetUserRole(ctx, viewer.ID, "root")
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	users, err := repo.ListUsersWithRoles(ctx)
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.Equal(t, admin.ID, users[0].ID)
+
+	count, err := repo.CountUsersWithRole(ctx, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	viewer, err = repo.SetUserRole(ctx, viewer.ID, "")
+	require.NoError(t, err)
+	assert.Empty(t, viewer.Role)
+
+	account, err := repo.CreateServiceAccount(ctx, "ci", admin.ID)
+	require.NoError(t, err)
+	_, err = repo.SetUserRole(ctx, account.ID, "viewer")
+	assert.ErrorIs(t, err, ErrNotFound, "service accounts cannot hold roles")
+}
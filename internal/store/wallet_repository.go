@@ -0,0 +1,163 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Wallet is an Ethereum address linked to a user in addition to the user's own
+// address. A linked wallet signs in as the user it belongs to.
+type Wallet struct {
+	ID        int64     `db:"id"`
+	UserID    int64     `db:"user_id"`
+	Address   string    `db:"address"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// WalletRepository handles database operations for linked wallets
+type WalletRepository struct {
+	db *DB
+}
+
+// NewWalletRepository creates a new wallet repository
+func NewWalletRepository(db *DB) *WalletRepository {
+	return &WalletRepository{db: db}
+}
+
+// Ensure WalletRepository implements WalletRepositoryInterface
+var _ WalletRepositoryInterface = (*WalletRepository)(nil)
+
+// LinkWallet links an address to a user. It returns a DuplicateError when the
+// address is already linked to any user or is the own address of a user.
+func (r *WalletRepository) LinkWallet(ctx context.Context, userID int64, address string) (*Wallet, error) {
+	ctx, cancel := r.db.startQuery(ctx, "user_wallets.link")
+	defer cancel()
+
+	normalizedAddress, err := validateAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	// Addresses of existing users cannot be linked: they already sign in as themselves
+	query := `
+		INSERT INTO user_wallets (user_id, address, created_at)
+		SELECT $1, $2, CURRENT_TIMESTAMP
+		WHERE NOT EXISTS (SELECT 1 FROM users WHERE address = $2)
+		RETURNING id, user_id, address, created_at
+	`
+
+	wallet := &Wallet{}
+	err = r.db.QueryRowxContext(ctx, query, userID, normalizedAddress).StructScan(wallet)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &DuplicateError{Resource: "wallet", Field: "address", Value: normalizedAddress}
+		}
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return nil, &DuplicateError{Resource: "wallet", Field: "address", Value: normalizedAddress}
+		}
+		return nil, fmt.Errorf("failed to link wallet: %w", err)
+	}
+
+	return wallet, nil
+}
+
+// UnlinkWallet removes a wallet from a user. It returns a NotFoundError when
+// the address is not linked to that user.
+func (r *WalletRepository) UnlinkWallet(ctx context.Context, userID int64, address string) error {
+	ctx, cancel := r.db.startQuery(ctx, "user_wallets.unlink")
+	defer cancel()
+
+	normalizedAddress, err := validateAddress(address)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM user_wallets WHERE user_id = $1 AND address = $2`, userID, normalizedAddress)
+	if err != nil {
+		return fmt.Errorf("failed to unlink wallet: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return &NotFoundError{Resource: "wallet", ID: normalizedAddress}
+	}
+
+	return nil
+}
+
+// ListWallets returns the wallets linked to a user, oldest first
+func (r *WalletRepository) ListWallets(ctx context.Context, userID int64) ([]Wallet, error) {
+	ctx, cancel := r.db.startQuery(ctx, "user_wallets.list")
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, address, created_at
+		FROM user_wallets
+		WHERE user_id = $1
+		ORDER BY created_at, id
+	`
+
+	wallets := []Wallet{}
+	if err := r.db.SelectContext(ctx, &wallets, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to query wallets: %w", err)
+	}
+
+	return wallets, nil
+}
+
+// GetWalletOwner returns the ID of the user a wallet is linked to, or a
+// NotFoundError when the address is not a linked wallet
+func (r *WalletRepository) GetWalletOwner(ctx context.Context, address string) (int64, error) {
+	ctx, cancel := r.db.startQuery(ctx, "user_wallets.get_owner")
+	defer cancel()
+
+	normalizedAddress, err := validateAddress(address)
+	if err != nil {
+		return 0, err
+	}
+
+	var userID int64
+	err = r.db.QueryRowContext(ctx, `SELECT user_id FROM user_wallets WHERE address = $1`, normalizedAddress).Scan(&userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, &NotFoundError{Resource: "wallet", ID: normalizedAddress}
+		}
+		return 0, fmt.Errorf("failed to query wallet owner: %w", err)
+	}
+
+	return userID, nil
+}
+
+// ListLinkedAddresses returns the wallets linked to the user with the given own
+// address. Unknown users have no linked wallets.
+func (r *WalletRepository) ListLinkedAddresses(ctx context.Context, address string) ([]string, error) {
+	ctx, cancel := r.db.startQuery(ctx, "user_wallets.list_by_user_address")
+	defer cancel()
+
+	normalizedAddress, err := validateAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT w.address
+		FROM user_wallets w
+		JOIN users u ON u.id = w.user_id
+		WHERE u.address = $1
+		ORDER BY w.created_at, w.id
+	`
+
+	addresses := []string{}
+	if err := r.db.SelectContext(ctx, &addresses, query, normalizedAddress); err != nil {
+		return nil, fmt.Errorf("failed to query linked wallets: %w", err)
+	}
+
+	return addresses, nil
+}
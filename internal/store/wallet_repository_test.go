@@ -0,0 +1,66 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalletRepository_LinkAndUnlink(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewWalletRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, db, "0x742d35cc6634c0532925a3b844bc9e7595f0beb0")
+	other := createTestUser(t, db, "0x742d35cc6634c0532925a3b844bc9e7595f0beb1")
+	wallet := "0x742d35cc6634c0532925a3b844bc9e7595f0beb2"
+
+	t.Run("links a wallet", func(t *testing.T) {
+		linked, err := repo.LinkWallet(ctx, user.ID, "0x742D35CC6634C0532925A3B844BC9E7595F0BEB2")
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, linked.UserID)
+		assert.Equal(t, wallet, linked.Address)
+
+		owner, err := repo.GetWalletOwner(ctx, wallet)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, owner)
+	})
+
+	t.Run("rejects a wallet linked to another user", func(t *testing.T) {
+		_, err := repo.LinkWallet(ctx, other.ID, wallet)
+		assert.True(t, errors.Is(err, ErrDuplicate))
+	})
+
+	t.Run("rejects the address of an existing user", func(t *testing.T) {
+		_, err := repo.LinkWallet(ctx, user.ID, other.Address)
+		assert.True(t, errors.Is(err, ErrDuplicate))
+	})
+
+	t.Run("lists linked wallets", func(t *testing.T) {
+		wallets, err := repo.ListWallets(ctx, user.ID)
+		require.NoError(t, err)
+		require.Len(t, wallets, 1)
+		assert.Equal(t, wallet, wallets[0].Address)
+
+		addresses, err := repo.ListLinkedAddresses(ctx, user.Address)
+		require.NoError(t, err)
+		assert.Equal(t, []string{wallet}, addresses)
+
+		addresses, err = repo.ListLinkedAddresses(ctx, other.Address)
+		require.NoError(t, err)
+		assert.Empty(t, addresses)
+	})
+
+	t.Run("only the owner can unlink", func(t *testing.T) {
+		err := repo.UnlinkWallet(ctx, other.ID, wallet)
+		assert.True(t, errors.Is(err, ErrNotFound))
+
+		require.NoError(t, repo.UnlinkWallet(ctx, user.ID, wallet))
+		_, err = repo.GetWalletOwner(ctx, wallet)
+		assert.True(t, errors.Is(err, ErrNotFound))
+	})
+}
@@ -0,0 +1,140 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// WebAuthnCredential is a passkey registered by a user for step-up verification
+type WebAuthnCredential struct {
+	ID           int64      `db:"id"`
+	UserID       int64      `db:"user_id"`
+	CredentialID []byte     `db:"credential_id"`
+	PublicKey    []byte     `db:"public_key"` // COSE_Key
+	SignCount    uint32     `db:"sign_count"`
+	Name         string     `db:"name"`
+	CreatedAt    time.Time  `db:"created_at"`
+	LastUsedAt   *time.Time `db:"last_used_at"`
+}
+
+// WebAuthnRepository handles database operations for WebAuthn credentials
+type WebAuthnRepository struct {
+	db *DB
+}
+
+// NewWebAuthnRepository creates a new WebAuthn credential repository
+func NewWebAuthnRepository(db *DB) *WebAuthnRepository {
+	return &WebAuthnRepository{db: db}
+}
+
+// Ensure WebAuthnRepository implements WebAuthnRepositoryInterface
+var _ WebAuthnRepositoryInterface = (*WebAuthnRepository)(nil)
+
+// CreateCredential stores a newly registered credential. It returns a
+// DuplicateError when the credential ID is already registered.
+func (r *WebAuthnRepository) CreateCredential(ctx context.Context, credential *WebAuthnCredential) (*WebAuthnCredential, error) {
+	ctx, cancel := r.db.startQuery(ctx, "webauthn_credentials.create")
+	defer cancel()
+
+	query := `
+		INSERT INTO webauthn_credentials (user_id, credential_id, public_key, sign_count, name, created_at)
+		VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+		RETURNING id, user_id, credential_id, public_key, sign_count, name, created_at, last_used_at
+	`
+
+	created := &WebAuthnCredential{}
+	err := r.db.QueryRowxContext(ctx, query,
+		credential.UserID, credential.CredentialID, credential.PublicKey, int64(credential.SignCount), credential.Name,
+	).StructScan(created)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return nil, &DuplicateError{Resource: "webauthn credential", Field: "credential_id", Value: base64.RawURLEncoding.EncodeToString(credential.CredentialID)}
+		}
+		return nil, fmt.Errorf("failed to create webauthn credential: %w", err)
+	}
+
+	return created, nil
+}
+
+// ListCredentials returns the credentials of a user, oldest first
+func (r *WebAuthnRepository) ListCredentials(ctx context.Context, userID int64) ([]WebAuthnCredential, error) {
+	ctx, cancel := r.db.startQuery(ctx, "webauthn_credentials.list")
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, credential_id, public_key, sign_count, name, created_at, last_used_at
+		FROM webauthn_credentials
+		WHERE user_id = $1
+		ORDER BY created_at, id
+	`
+
+	credentials := []WebAuthnCredential{}
+	if err := r.db.SelectContext(ctx, &credentials, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to query webauthn credentials: %w", err)
+	}
+
+	return credentials, nil
+}
+
+// GetCredential returns a credential of a user by its credential ID, or a
+// NotFoundError when the user has no such credential
+func (r *WebAuthnRepository) GetCredential(ctx context.Context, userID int64, credentialID []byte) (*WebAuthnCredential, error) {
+	ctx, cancel := r.db.startQuery(ctx, "webauthn_credentials.get")
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, credential_id, public_key, sign_count, name, created_at, last_used_at
+		FROM webauthn_credentials
+		WHERE user_id = $1 AND credential_id = $2
+	`
+
+	credential := &WebAuthnCredential{}
+	if err := r.db.GetContext(ctx, credential, query, userID, credentialID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &NotFoundError{Resource: "webauthn credential", ID: base64.RawURLEncoding.EncodeToString(credentialID)}
+		}
+		return nil, fmt.Errorf("failed to query webauthn credential: %w", err)
+	}
+
+	return credential, nil
+}
+
+// RecordUse stores the signature counter of a successful assertion
+func (r *WebAuthnRepository) RecordUse(ctx context.Context, id int64, signCount uint32) error {
+	ctx, cancel := r.db.startQuery(ctx, "webauthn_credentials.record_use")
+	defer cancel()
+
+	query := `UPDATE webauthn_credentials SET sign_count = $2, last_used_at = CURRENT_TIMESTAMP WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, id, int64(signCount)); err != nil {
+		return fmt.Errorf("failed to record webauthn credential use: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCredential removes a credential of a user. It returns a NotFoundError
+// when the user has no credential with that ID.
+func (r *WebAuthnRepository) DeleteCredential(ctx context.Context, userID int64, id int64) error {
+	ctx, cancel := r.db.startQuery(ctx, "webauthn_credentials.delete")
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webauthn_credentials WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webauthn credential: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return &NotFoundError{Resource: "webauthn credential", ID: fmt.Sprintf("%d", id)}
+	}
+
+	return nil
+}
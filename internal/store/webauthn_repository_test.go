@@ -0,0 +1,66 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebAuthnRepository_Lifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewWebAuthnRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, db, "0x742d35cc6634c0532925a3b844bc9e7595f0beb0")
+	other := createTestUser(t, db, "0x742d35cc6634c0532925a3b844bc9e7595f0beb1")
+
+	created, err := repo.CreateCredential(ctx, &WebAuthnCredential{
+		UserID:       user.ID,
+		CredentialID: []byte("credential-1"),
+		PublicKey:    []byte{0xa5, 0x01},
+		SignCount:    3,
+		Name:         "Laptop",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(3), created.SignCount)
+	assert.Nil(t, created.LastUsedAt)
+
+	t.Run("rejects a credential ID registered before", func(t *testing.T) {
+		_, err := repo.CreateCredential(ctx, &WebAuthnCredential{UserID: other.ID, CredentialID: []byte("credential-1"), PublicKey: []byte{0xa0}, Name: "Copy"})
+		assert.True(t, errors.Is(err, ErrDuplicate))
+	})
+
+	t.Run("looks up credentials of their owner only", func(t *testing.T) {
+		credential, err := repo.GetCredential(ctx, user.ID, []byte("credential-1"))
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, credential.ID)
+		assert.Equal(t, []byte{0xa5, 0x01}, credential.PublicKey)
+
+		_, err = repo.GetCredential(ctx, other.ID, []byte("credential-1"))
+		assert.True(t, errors.Is(err, ErrNotFound))
+	})
+
+	t.Run("records use", func(t *testing.T) {
+		require.NoError(t, repo.RecordUse(ctx, created.ID, 4))
+
+		credentials, err := repo.ListCredentials(ctx, user.ID)
+		require.NoError(t, err)
+		require.Len(t, credentials, 1)
+		assert.Equal(t, uint32(4), credentials[0].SignCount)
+		assert.NotNil(t, credentials[0].LastUsedAt)
+	})
+
+	t.Run("only the owner can delete", func(t *testing.T) {
+		err := repo.DeleteCredential(ctx, other.ID, created.ID)
+		assert.True(t, errors.Is(err, ErrNotFound))
+
+		require.NoError(t, repo.DeleteCredential(ctx, user.ID, created.ID))
+		credentials, err := repo.ListCredentials(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Empty(t, credentials)
+	})
+}
@@ -0,0 +1,113 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// COSE algorithms accepted for credentials, in order of preference
+const (
+	AlgES256 int64 = -7
+	AlgEdDSA int64 = -8
+	AlgRS256 int64 = -257
+)
+
+// SupportedAlgorithms lists the COSE algorithms offered at registration
+var SupportedAlgorithms = []int64{AlgES256, AlgEdDSA, AlgRS256}
+
+// COSE key parameters (RFC 9053)
+const (
+	coseKeyType  int64 = 1
+	coseKeyAlg   int64 = 3
+	coseKeyCrvN  int64 = -1 // EC2/OKP curve, RSA modulus
+	coseKeyXE    int64 = -2 // EC2/OKP x coordinate, RSA exponent
+	coseKeyY     int64 = -3 // EC2 y coordinate
+	coseKtyOKP   int64 = 1
+	coseKtyEC2   int64 = 2
+	coseKtyRSA   int64 = 3
+	coseCrvP256  int64 = 1
+	coseCrvEd255 int64 = 6
+)
+
+// publicKey is a credential public key with the algorithm it signs with
+type publicKey struct {
+	alg int64
+	key crypto.PublicKey
+}
+
+// parsePublicKey parses a COSE_Key as stored with a credential
+func parsePublicKey(coseKey []byte) (*publicKey, error) {
+	decoded, rest, err := decodeCBOR(coseKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("invalid public key: trailing data")
+	}
+	return publicKeyFromCOSE(decoded)
+}
+
+// publicKeyFromCOSE converts a decoded COSE_Key map
+func publicKeyFromCOSE(decoded interface{}) (*publicKey, error) {
+	params, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("invalid public key: not a map")
+	}
+	kty, _ := params[coseKeyType].(int64)
+	alg, _ := params[coseKeyAlg].(int64)
+
+	switch {
+	case kty == coseKtyEC2 && alg == AlgES256:
+		crv, _ := params[coseKeyCrvN].(int64)
+		x, _ := params[coseKeyXE].([]byte)
+		y, _ := params[coseKeyY].([]byte)
+		if crv != coseCrvP256 || len(x) != 32 || len(y) != 32 {
+			return nil, errors.New("invalid public key: ES256 requires a P-256 point")
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, errors.New("invalid public key: point is not on P-256")
+		}
+		return &publicKey{alg: alg, key: key}, nil
+	case kty == coseKtyOKP && alg == AlgEdDSA:
+		crv, _ := params[coseKeyCrvN].(int64)
+		x, _ := params[coseKeyXE].([]byte)
+		if crv != coseCrvEd255 || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid public key: EdDSA requires an Ed25519 key")
+		}
+		return &publicKey{alg: alg, key: ed25519.PublicKey(x)}, nil
+	case kty == coseKtyRSA && alg == AlgRS256:
+		n, _ := params[coseKeyCrvN].([]byte)
+		e, _ := params[coseKeyXE].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid public key: RS256 requires a 2048-bit or larger modulus")
+		}
+		exponent := new(big.Int).SetBytes(e)
+		return &publicKey{alg: alg, key: &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key: key type %d, algorithm %d", kty, alg)
+	}
+}
+
+// verify checks a signature over data
+func (k *publicKey) verify(data, signature []byte) bool {
+	switch key := k.key.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(data)
+		return ecdsa.VerifyASN1(key, digest[:], signature)
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, data, signature)
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(data)
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	default:
+		return false
+	}
+}
@@ -0,0 +1,286 @@
+// Package webauthn verifies passkey (WebAuthn) registrations and assertions for
+// step-up verification. Attestation statements are not verified: credentials
+// are registered with attestation "none" and trusted because the registering
+// session is already authenticated.
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Challenge purposes; a challenge only completes the ceremony it was issued for
+const (
+	PurposeRegistration = "registration"
+	PurposeAssertion    = "assertion"
+)
+
+// Authenticator data flags
+const (
+	flagUserPresent  = 0x01
+	flagAttestedData = 0x40
+)
+
+// ErrVerification is returned when a registration or assertion does not verify
+var ErrVerification = errors.New("webauthn verification failed")
+
+// Credential is a registered passkey
+type Credential struct {
+	ID        []byte // Credential ID chosen by the authenticator
+	PublicKey []byte // COSE_Key
+	SignCount uint32
+}
+
+// challenge is an issued, not yet used challenge
+type challenge struct {
+	userID    int64
+	purpose   string
+	expiresAt time.Time
+}
+
+// Service issues challenges and verifies WebAuthn ceremonies for one relying party.
+// Challenges are kept in memory, like SIWE nonces, and are single-use.
+type Service struct {
+	rpID         string
+	rpIDHash     [32]byte
+	origins      []string
+	challengeTTL time.Duration
+
+	mu         sync.Mutex
+	challenges map[string]*challenge // Keyed by the base64url challenge
+	now        func() time.Time
+}
+
+// NewService creates a WebAuthn service for the relying party rpID (a domain,
+// e.g. "example.com"). Client data must come from one of origins.
+func NewService(rpID string, origins []string, challengeTTL time.Duration) *Service {
+	return &Service{
+		rpID:         rpID,
+		rpIDHash:     sha256.Sum256([]byte(rpID)),
+		origins:      origins,
+		challengeTTL: challengeTTL,
+		challenges:   make(map[string]*challenge),
+		now:          time.Now,
+	}
+}
+
+// RPID returns the relying party ID
+func (s *Service) RPID() string {
+	return s.rpID
+}
+
+// ChallengeTTL returns how long an issued challenge stays valid
+func (s *Service) ChallengeTTL() time.Duration {
+	return s.challengeTTL
+}
+
+// NewChallenge issues a single-use challenge for a ceremony of the user,
+// returned base64url encoded as clients pass it to the authenticator
+func (s *Service) NewChallenge(userID int64, purpose string) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate challenge: %w", err)
+	}
+	value := base64.RawURLEncoding.EncodeToString(raw)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for key, c := range s.challenges {
+		if now.After(c.expiresAt) {
+			delete(s.challenges, key)
+		}
+	}
+	s.challenges[value] = &challenge{userID: userID, purpose: purpose, expiresAt: now.Add(s.challengeTTL)}
+	return value, nil
+}
+
+// consumeChallenge removes the challenge, reporting whether it was issued to
+// the user for the purpose and has not expired
+func (s *Service) consumeChallenge(value string, userID int64, purpose string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.challenges[value]
+	if !ok {
+		return false
+	}
+	delete(s.challenges, value)
+	return c.userID == userID && c.purpose == purpose && !s.now().After(c.expiresAt)
+}
+
+// clientData is the collected client data signed by the authenticator
+type clientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// verifyClientData checks the client data of a ceremony and consumes its challenge
+func (s *Service) verifyClientData(clientDataJSON []byte, ceremony string, userID int64, purpose string) error {
+	var data clientData
+	if err := json.Unmarshal(clientDataJSON, &data); err != nil {
+		return fmt.Errorf("%w: invalid client data: %v", ErrVerification, err)
+	}
+	if data.Type != ceremony {
+		return fmt.Errorf("%w: client data type is %q, expected %q", ErrVerification, data.Type, ceremony)
+	}
+	if !s.allowedOrigin(data.Origin) || data.CrossOrigin {
+		return fmt.Errorf("%w: origin %q is not allowed", ErrVerification, data.Origin)
+	}
+	if !s.consumeChallenge(data.Challenge, userID, purpose) {
+		return fmt.Errorf("%w: unknown, used or expired challenge", ErrVerification)
+	}
+	return nil
+}
+
+// allowedOrigin reports whether client data may come from origin
+func (s *Service) allowedOrigin(origin string) bool {
+	for _, allowed := range s.origins {
+		if origin == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticatorData is the parsed authenticator data of a ceremony
+type authenticatorData struct {
+	rpIDHash     []byte
+	flags        byte
+	signCount    uint32
+	credentialID []byte // Registration only
+	publicKey    []byte // Registration only, COSE_Key
+}
+
+// parseAuthenticatorData parses authenticator data, including the attested
+// credential of a registration
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < 37 {
+		return nil, errors.New("authenticator data too short")
+	}
+	parsed := &authenticatorData{
+		rpIDHash:  data[:32],
+		flags:     data[32],
+		signCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	if parsed.flags&flagAttestedData == 0 {
+		return parsed, nil
+	}
+
+	// AAGUID (16 bytes), credential ID length (2 bytes), credential ID, COSE_Key
+	rest := data[37:]
+	if len(rest) < 18 {
+		return nil, errors.New("attested credential data too short")
+	}
+	idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if len(rest) < idLength {
+		return nil, errors.New("credential ID truncated")
+	}
+	parsed.credentialID = rest[:idLength]
+	rest = rest[idLength:]
+
+	_, after, err := decodeCBOR(rest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid credential public key: %w", err)
+	}
+	parsed.publicKey = rest[:len(rest)-len(after)]
+	return parsed, nil
+}
+
+// checkFlags verifies the relying party and that the user was present
+func (s *Service) checkFlags(data *authenticatorData) error {
+	if !bytes.Equal(data.rpIDHash, s.rpIDHash[:]) {
+		return fmt.Errorf("%w: credential belongs to another relying party", ErrVerification)
+	}
+	if data.flags&flagUserPresent == 0 {
+		return fmt.Errorf("%w: user presence was not verified", ErrVerification)
+	}
+	return nil
+}
+
+// VerifyRegistration verifies the response to navigator.credentials.create for
+// a registration challenge issued to the user and returns the new credential
+func (s *Service) VerifyRegistration(userID int64, clientDataJSON, attestationObject []byte) (*Credential, error) {
+	if err := s.verifyClientData(clientDataJSON, "webauthn.create", userID, PurposeRegistration); err != nil {
+		return nil, err
+	}
+
+	decoded, _, err := decodeCBOR(attestationObject)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid attestation object: %v", ErrVerification, err)
+	}
+	attestation, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid attestation object", ErrVerification)
+	}
+	rawAuthData, ok := attestation["authData"].([]byte)
+	if !ok {
+		return nil, fmt.Errorf("%w: attestation object has no authenticator data", ErrVerification)
+	}
+
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrVerification, err)
+	}
+	if err := s.checkFlags(authData); err != nil {
+		return nil, err
+	}
+	if authData.credentialID == nil {
+		return nil, fmt.Errorf("%w: no attested credential", ErrVerification)
+	}
+	if _, err := parsePublicKey(authData.publicKey); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrVerification, err)
+	}
+
+	return &Credential{
+		ID:        append([]byte(nil), authData.credentialID...),
+		PublicKey: append([]byte(nil), authData.publicKey...),
+		SignCount: authData.signCount,
+	}, nil
+}
+
+// VerifyAssertion verifies the response to navigator.credentials.get for an
+// assertion challenge issued to the user, signed by credential. It returns the
+// authenticator's new signature counter, to be stored with the credential.
+func (s *Service) VerifyAssertion(userID int64, credential *Credential, clientDataJSON, rawAuthData, signature []byte) (uint32, error) {
+	if err := s.verifyClientData(clientDataJSON, "webauthn.get", userID, PurposeAssertion); err != nil {
+		return 0, err
+	}
+
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrVerification, err)
+	}
+	if err := s.checkFlags(authData); err != nil {
+		return 0, err
+	}
+
+	key, err := parsePublicKey(credential.PublicKey)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrVerification, err)
+	}
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte(nil), rawAuthData...), clientDataHash[:]...)
+	if !key.verify(signed, signature) {
+		return 0, fmt.Errorf("%w: invalid signature", ErrVerification)
+	}
+
+	// Authenticators that count signatures must always increase the counter;
+	// a counter that does not suggests a cloned authenticator
+	if (authData.signCount != 0 || credential.SignCount != 0) && authData.signCount <= credential.SignCount {
+		return 0, fmt.Errorf("%w: signature counter did not increase", ErrVerification)
+	}
+	return authData.signCount, nil
+}
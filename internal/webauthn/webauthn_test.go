@@ -0,0 +1,187 @@
+package webauthn
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/gatekeeper/internal/webauthn/webauthntest"
+)
+
+const testOrigin = "https://app.example.com"
+
+func newTestAuthenticator() *webauthntest.Authenticator {
+	return webauthntest.NewAuthenticator("example.com", testOrigin)
+}
+
+func newTestService() *Service {
+	return NewService("example.com", []string{testOrigin}, 5*time.Minute)
+}
+
+func TestService_RegisterAndAssert(t *testing.T) {
+	service := newTestService()
+	authenticator := newTestAuthenticator()
+
+	challenge, err := service.NewChallenge(7, PurposeRegistration)
+	require.NoError(t, err)
+	clientData, attestation := authenticator.Register(challenge)
+
+	credential, err := service.VerifyRegistration(7, clientData, attestation)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("credential-1"), credential.ID)
+	assert.Equal(t, authenticator.COSEKey(), credential.PublicKey)
+
+	challenge, err = service.NewChallenge(7, PurposeAssertion)
+	require.NoError(t, err)
+	clientData, authData, signature := authenticator.Assert(challenge)
+
+	signCount, err := service.VerifyAssertion(7, credential, clientData, authData, signature)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), signCount)
+
+	// Challenges are single-use
+	_, err = service.VerifyAssertion(7, credential, clientData, authData, signature)
+	assert.True(t, errors.Is(err, ErrVerification))
+}
+
+func TestService_VerifyAssertion_Rejections(t *testing.T) {
+	authenticator := newTestAuthenticator()
+	credential := &Credential{ID: authenticator.CredentialID, PublicKey: authenticator.COSEKey(), SignCount: 5}
+
+	tests := []struct {
+		name   string
+		userID int64
+		modify func(s *Service, clientData, authData, signature []byte) ([]byte, []byte, []byte)
+		setup  func(a *webauthntest.Authenticator)
+	}{
+		{
+			name:   "challenge issued to another user",
+			userID: 8,
+		},
+		{
+			name:   "wrong origin",
+			userID: 7,
+			modify: func(s *Service, clientData, authData, signature []byte) ([]byte, []byte, []byte) {
+				var data map[string]interface{}
+				json.Unmarshal(clientData, &data)
+				return webauthntest.ClientDataJSON("webauthn.get", data["challenge"].(string), "https://evil.example"), authData, signature
+			},
+		},
+		{
+			name:   "tampered authenticator data",
+			userID: 7,
+			modify: func(s *Service, clientData, authData, signature []byte) ([]byte, []byte, []byte) {
+				authData = append([]byte(nil), authData...)
+				authData[36]++
+				return clientData, authData, signature
+			},
+		},
+		{
+			name:   "counter did not increase",
+			userID: 7,
+			setup:  func(a *webauthntest.Authenticator) { a.SignCount = 1 },
+		},
+		{
+			name:   "user not present",
+			userID: 7,
+			setup:  func(a *webauthntest.Authenticator) { a.Flags = 0 },
+		},
+		{
+			name:   "other relying party",
+			userID: 7,
+			setup:  func(a *webauthntest.Authenticator) { a.RPID = "evil.example" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestService()
+			a := *authenticator
+			a.SignCount = 10
+			if tt.setup != nil {
+				tt.setup(&a)
+			}
+
+			challenge, err := service.NewChallenge(7, PurposeAssertion)
+			require.NoError(t, err)
+			clientData, authData, signature := a.Assert(challenge)
+			if tt.modify != nil {
+				clientData, authData, signature = tt.modify(service, clientData, authData, signature)
+			}
+
+			_, err = service.VerifyAssertion(tt.userID, credential, clientData, authData, signature)
+			assert.True(t, errors.Is(err, ErrVerification), "got %v", err)
+		})
+	}
+}
+
+func TestService_ChallengePurposeAndExpiry(t *testing.T) {
+	service := newTestService()
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	authenticator := newTestAuthenticator()
+
+	// A registration challenge cannot complete an assertion
+	challenge, err := service.NewChallenge(7, PurposeRegistration)
+	require.NoError(t, err)
+	clientData, authData, signature := authenticator.Assert(challenge)
+	credential := &Credential{PublicKey: authenticator.COSEKey()}
+	_, err = service.VerifyAssertion(7, credential, clientData, authData, signature)
+	assert.Error(t, err)
+
+	challenge, err = service.NewChallenge(7, PurposeAssertion)
+	require.NoError(t, err)
+	now = now.Add(6 * time.Minute)
+	clientData, authData, signature = authenticator.Assert(challenge)
+	_, err = service.VerifyAssertion(7, credential, clientData, authData, signature)
+	assert.Error(t, err)
+}
+
+func TestParsePublicKey_EdDSA(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	key, err := parsePublicKey(webauthntest.EncodeCBOR(map[interface{}]interface{}{
+		coseKeyType: coseKtyOKP,
+		coseKeyAlg:  AlgEdDSA,
+		coseKeyCrvN: coseCrvEd255,
+		coseKeyXE:   []byte(public),
+	}))
+	require.NoError(t, err)
+
+	assert.True(t, key.verify([]byte("data"), ed25519.Sign(private, []byte("data"))))
+	assert.False(t, key.verify([]byte("other"), ed25519.Sign(private, []byte("data"))))
+}
+
+func TestParsePublicKey_Unsupported(t *testing.T) {
+	_, err := parsePublicKey(webauthntest.EncodeCBOR(map[interface{}]interface{}{coseKeyType: coseKtyEC2, coseKeyAlg: int64(-35)}))
+	assert.Error(t, err)
+
+	_, err = parsePublicKey([]byte{0xa1})
+	assert.Error(t, err)
+}
+
+func TestDecodeCBOR(t *testing.T) {
+	value, rest, err := decodeCBOR(append(webauthntest.EncodeCBOR(map[interface{}]interface{}{
+		"a":       []interface{}{int64(1), int64(-300), "x", true, nil},
+		int64(-7): []byte{1, 2},
+	}), 0xff))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xff}, rest)
+	assert.Equal(t, map[interface{}]interface{}{
+		"a":       []interface{}{int64(1), int64(-300), "x", true, nil},
+		int64(-7): []byte{1, 2},
+	}, value)
+
+	// Truncated, indefinite length and absurd lengths are rejected
+	for _, input := range [][]byte{{0x62, 'a'}, {0x5f}, {0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}} {
+		_, _, err := decodeCBOR(input)
+		assert.Error(t, err, "%x", input)
+	}
+}
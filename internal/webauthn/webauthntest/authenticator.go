@@ -0,0 +1,175 @@
+// Package webauthntest provides a software passkey for testing WebAuthn
+// verification without a browser.
+package webauthntest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"sort"
+)
+
+// Authenticator data flags
+const (
+	FlagUserPresent  = 0x01
+	FlagAttestedData = 0x40
+)
+
+// Authenticator is a software passkey with an ES256 key. Its fields may be
+// changed to produce invalid responses.
+type Authenticator struct {
+	Key          *ecdsa.PrivateKey
+	CredentialID []byte
+	RPID         string // Relying party the authenticator data is scoped to
+	Origin       string // Origin reported in client data
+	SignCount    uint32 // Incremented by every assertion
+	Flags        byte
+}
+
+// NewAuthenticator creates an authenticator for the relying party rpID used from origin
+func NewAuthenticator(rpID, origin string) *Authenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return &Authenticator{
+		Key:          key,
+		CredentialID: []byte("credential-1"),
+		RPID:         rpID,
+		Origin:       origin,
+		Flags:        FlagUserPresent,
+	}
+}
+
+// COSEKey returns the public key as a COSE_Key
+func (a *Authenticator) COSEKey() []byte {
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+	a.Key.X.FillBytes(x)
+	a.Key.Y.FillBytes(y)
+	return EncodeCBOR(map[interface{}]interface{}{
+		int64(1):  int64(2),  // kty: EC2
+		int64(3):  int64(-7), // alg: ES256
+		int64(-1): int64(1),  // crv: P-256
+		int64(-2): x,
+		int64(-3): y,
+	})
+}
+
+// Register answers a registration challenge with attestation "none"
+func (a *Authenticator) Register(challenge string) (clientDataJSON, attestationObject []byte) {
+	attestationObject = EncodeCBOR(map[interface{}]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[interface{}]interface{}{},
+		"authData": a.AuthenticatorData(true),
+	})
+	return ClientDataJSON("webauthn.create", challenge, a.Origin), attestationObject
+}
+
+// Assert signs an assertion for the challenge, incrementing the signature counter
+func (a *Authenticator) Assert(challenge string) (clientDataJSON, authenticatorData, signature []byte) {
+	a.SignCount++
+	clientDataJSON = ClientDataJSON("webauthn.get", challenge, a.Origin)
+	authenticatorData = a.AuthenticatorData(false)
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte(nil), authenticatorData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.Key, digest[:])
+	if err != nil {
+		panic(err)
+	}
+	return clientDataJSON, authenticatorData, signature
+}
+
+// AuthenticatorData returns authenticator data, with the attested credential
+// of a registration if attested is set
+func (a *Authenticator) AuthenticatorData(attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(a.RPID))
+	data := append([]byte(nil), rpIDHash[:]...)
+	flags := a.Flags
+	if attested {
+		flags |= FlagAttestedData
+	}
+	data = append(data, flags, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(data[33:], a.SignCount)
+	if attested {
+		data = append(data, make([]byte, 16)...) // AAGUID
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.CredentialID)))
+		data = append(data, a.CredentialID...)
+		data = append(data, a.COSEKey()...)
+	}
+	return data
+}
+
+// ClientDataJSON returns the collected client data of a ceremony
+func ClientDataJSON(ceremony, challenge, origin string) []byte {
+	data, _ := json.Marshal(map[string]interface{}{"type": ceremony, "challenge": challenge, "origin": origin})
+	return data
+}
+
+// EncodeCBOR encodes integers (int or int64), byte and text strings, arrays
+// ([]interface{}), maps (map[interface{}]interface{}), booleans and nil
+func EncodeCBOR(value interface{}) []byte {
+	switch v := value.(type) {
+	case int:
+		return EncodeCBOR(int64(v))
+	case int64:
+		if v < 0 {
+			return cborHead(1, uint64(-1-v))
+		}
+		return cborHead(0, uint64(v))
+	case []byte:
+		return append(cborHead(2, uint64(len(v))), v...)
+	case string:
+		return append(cborHead(3, uint64(len(v))), v...)
+	case []interface{}:
+		out := cborHead(4, uint64(len(v)))
+		for _, item := range v {
+			out = append(out, EncodeCBOR(item)...)
+		}
+		return out
+	case map[interface{}]interface{}:
+		// Canonical order: sorted by encoded key
+		keys := make([]string, 0, len(v))
+		items := make(map[string][]byte, len(v))
+		for key, item := range v {
+			encoded := string(EncodeCBOR(key))
+			keys = append(keys, encoded)
+			items[encoded] = EncodeCBOR(item)
+		}
+		sort.Strings(keys)
+		out := cborHead(5, uint64(len(v)))
+		for _, key := range keys {
+			out = append(append(out, key...), items[key]...)
+		}
+		return out
+	case bool:
+		if v {
+			return []byte{0xf5}
+		}
+		return []byte{0xf4}
+	case nil:
+		return []byte{0xf6}
+	default:
+		panic("webauthntest: unsupported CBOR value")
+	}
+}
+
+// cborHead encodes the initial byte and argument of a CBOR item
+func cborHead(major byte, arg uint64) []byte {
+	switch {
+	case arg < 24:
+		return []byte{major<<5 | byte(arg)}
+	case arg < 1<<8:
+		return []byte{major<<5 | 24, byte(arg)}
+	case arg < 1<<16:
+		return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(arg))
+	case arg < 1<<32:
+		return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(arg))
+	default:
+		return binary.BigEndian.AppendUint64([]byte{major<<5 | 27}, arg)
+	}
+}